@@ -10,13 +10,14 @@ import (
 	"go.dedis.ch/dela/cli"
 	"go.dedis.ch/dela/cli/ucli"
 	bls "go.dedis.ch/dela/crypto/bls/command"
+	dilithium "go.dedis.ch/dela/crypto/dilithium/command"
 )
 
 var builder cli.Builder = ucli.NewBuilder("crypto", nil)
 var printer io.Writer = os.Stderr
 
 func main() {
-	err := run(os.Args, bls.Initializer{})
+	err := run(os.Args, bls.Initializer{}, dilithium.Initializer{})
 	if err != nil {
 		fmt.Fprintf(printer, "%+v\n", err)
 	}
@@ -0,0 +1,193 @@
+// This file contains the authentication of the clients of the daemon.
+//
+// The daemon writes two random tokens to the config folder when it starts, and
+// a client sends one of them before its command. The administrator token can
+// only be read by the owner of the daemon, and the user token by its group as
+// well, so that the members of the group can run the commands that don't
+// modify the node.
+
+package node
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// tokenSize is the number of bytes of a token.
+	tokenSize = 32
+
+	adminTokenFile = "daemon.admin.token"
+	userTokenFile  = "daemon.user.token"
+
+	adminTokenMode os.FileMode = 0600
+	userTokenMode  os.FileMode = 0640
+)
+
+// role is the authorization of a client of the daemon.
+type role int
+
+const (
+	roleNone role = iota
+	roleUser
+	roleAdmin
+)
+
+func (r role) String() string {
+	switch r {
+	case roleUser:
+		return "user"
+	case roleAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// tokens are the secrets of the daemon that authenticate the clients.
+type tokens struct {
+	admin []byte
+	user  []byte
+}
+
+// newTokens generates the tokens of the daemon and writes them to the config
+// folder, replacing the ones of a previous run.
+func newTokens(config string) (tokens, error) {
+	t := tokens{
+		admin: make([]byte, tokenSize),
+		user:  make([]byte, tokenSize),
+	}
+
+	for _, token := range [][]byte{t.admin, t.user} {
+		_, err := rand.Read(token)
+		if err != nil {
+			return t, xerrors.Errorf("failed to generate token: %v", err)
+		}
+	}
+
+	err := writeToken(filepath.Join(config, adminTokenFile), t.admin, adminTokenMode)
+	if err != nil {
+		return t, xerrors.Errorf("admin: %v", err)
+	}
+
+	err = writeToken(filepath.Join(config, userTokenFile), t.user, userTokenMode)
+	if err != nil {
+		return t, xerrors.Errorf("user: %v", err)
+	}
+
+	return t, nil
+}
+
+// authenticate returns the role of the token, or none if it is unknown.
+func (t tokens) authenticate(token []byte) role {
+	if subtle.ConstantTimeCompare(token, t.admin) == 1 {
+		return roleAdmin
+	}
+
+	if subtle.ConstantTimeCompare(token, t.user) == 1 {
+		return roleUser
+	}
+
+	return roleNone
+}
+
+// removeTokens deletes the tokens of the config folder.
+func removeTokens(config string) {
+	os.Remove(filepath.Join(config, adminTokenFile))
+	os.Remove(filepath.Join(config, userTokenFile))
+}
+
+// readToken returns the administrator token of the config folder if the
+// client can read it, otherwise the user token.
+func readToken(config string) ([]byte, error) {
+	token, err := readTokenFile(filepath.Join(config, adminTokenFile))
+	if err == nil {
+		return token, nil
+	}
+
+	token, err = readTokenFile(filepath.Join(config, userTokenFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// writeToken writes the token to a new file, so that the permissions are not
+// inherited from a previous one, and sets its permissions regardless of the
+// umask.
+func writeToken(path string, token []byte, mode os.FileMode) error {
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return xerrors.Errorf("failed to remove: %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return xerrors.Errorf("failed to create: %v", err)
+	}
+
+	_, err = file.WriteString(hex.EncodeToString(token))
+	if err != nil {
+		file.Close()
+		return xerrors.Errorf("failed to write: %v", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return xerrors.Errorf("failed to close: %v", err)
+	}
+
+	err = os.Chmod(path, mode)
+	if err != nil {
+		return xerrors.Errorf("failed to set permissions: %v", err)
+	}
+
+	return nil
+}
+
+func readTokenFile(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read: %v", err)
+	}
+
+	token, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decode '%s': %v", path, err)
+	}
+
+	if len(token) != tokenSize {
+		return nil, xerrors.Errorf("invalid token of %d bytes in '%s'", len(token), path)
+	}
+
+	return token, nil
+}
+
+// Destructive returns an action template that can only be executed by the
+// clients with the administrator token of the daemon. It protects the actions
+// that modify the node or its chain.
+func Destructive(tmpl ActionTemplate) ActionTemplate {
+	return destructiveAction{ActionTemplate: tmpl}
+}
+
+// destructiveAction is an action template that requires the administrator
+// token.
+//
+// - implements node.ActionTemplate
+type destructiveAction struct {
+	ActionTemplate
+}
+
+// isDestructive returns true if the action requires the administrator token.
+func isDestructive(tmpl ActionTemplate) bool {
+	_, ok := tmpl.(destructiveAction)
+	return ok
+}
@@ -0,0 +1,85 @@
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokens_Authenticate(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	tokens, err := newTokens(dir)
+	require.NoError(t, err)
+
+	token, err := readToken(dir)
+	require.NoError(t, err)
+	require.Equal(t, roleAdmin, tokens.authenticate(token))
+
+	require.NoError(t, os.Remove(filepath.Join(dir, adminTokenFile)))
+
+	token, err = readToken(dir)
+	require.NoError(t, err)
+	require.Equal(t, roleUser, tokens.authenticate(token))
+
+	require.Equal(t, roleNone, tokens.authenticate(make([]byte, tokenSize)))
+	require.Equal(t, roleNone, tokens.authenticate(nil))
+
+	// The tokens of a new run replace the previous ones.
+	other, err := newTokens(dir)
+	require.NoError(t, err)
+	require.Equal(t, roleNone, other.authenticate(token))
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dir, adminTokenFile))
+		require.NoError(t, err)
+		require.Equal(t, adminTokenMode, info.Mode().Perm())
+
+		info, err = os.Stat(filepath.Join(dir, userTokenFile))
+		require.NoError(t, err)
+		require.Equal(t, userTokenMode, info.Mode().Perm())
+	}
+
+	removeTokens(dir)
+
+	_, err = readToken(dir)
+	require.Error(t, err)
+}
+
+func TestTokens_FailWrite(t *testing.T) {
+	_, err := newTokens(filepath.Join(os.TempDir(), "dela-missing"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "admin: failed to create: ")
+}
+
+func TestReadToken_Invalid(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, userTokenFile)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("abc"), 0600))
+
+	_, err = readToken(dir)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to decode '"+path+"': ")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("abcd\n"), 0600))
+
+	_, err = readToken(dir)
+	require.EqualError(t, err, "invalid token of 2 bytes in '"+path+"'")
+}
+
+func TestDestructive(t *testing.T) {
+	require.False(t, isDestructive(fakeAction{}))
+	require.True(t, isDestructive(Destructive(fakeAction{})))
+}
@@ -64,9 +64,10 @@ func NewBuilderWithCfg(sigs chan os.Signal, out io.Writer, inits ...Initializer)
 	actions := &actionMap{}
 
 	factory := socketFactory{
-		injector: injector,
-		actions:  actions,
-		out:      out,
+		injector:   injector,
+		operations: NewOperations(),
+		actions:    actions,
+		out:        out,
 	}
 
 	// We are using urfave cli builder
@@ -171,6 +172,22 @@ func (b *CLIBuilder) Build() cli.Application {
 	cmd.SetFlags(b.startFlags...)
 	cmd.SetAction(b.start)
 
+	cmd = b.SetCommand("operation")
+	cmd.SetDescription("follow the long operations of the daemon")
+
+	sub := cmd.SetSubCommand("list")
+	sub.SetDescription("print the running and recently finished operations")
+	sub.SetAction(b.MakeAction(operationListAction{}))
+
+	sub = cmd.SetSubCommand("attach")
+	sub.SetDescription("follow an operation until it is finished")
+	sub.SetFlags(cli.StringFlag{
+		Name:     "id",
+		Usage:    "identifier of the operation",
+		Required: true,
+	})
+	sub.SetAction(b.MakeAction(operationAttachAction{}))
+
 	return b.Builder.Build()
 }
 
@@ -132,9 +132,10 @@ func TestCliBuilder_Build(t *testing.T) {
 		return nil
 	})
 
-	// Build will add the start command, which is why we are expecting 5.
+	// Build will add the start and the operation commands, which is why we are
+	// expecting 6.
 	app := builder.Build().(*urfave.App)
-	require.Len(t, app.Commands, 5)
+	require.Len(t, app.Commands, 6)
 }
 
 func TestCliBuilder_UnknownType_BuildFlags(t *testing.T) {
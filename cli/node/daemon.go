@@ -1,5 +1,5 @@
 // This file contains the implementation of a client and a daemon talking
-// through a UNIX socket.
+// through a UNIX socket, or a named pipe on Windows.
 //
 // Documentation Last Review: 13.10.2020
 //
@@ -12,7 +12,7 @@ import (
 	"fmt"
 	"io"
 	"net"
-	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -24,34 +24,46 @@ import (
 
 const ioTimeout = 30 * time.Second
 
+// progressWidth is the number of characters of the progress bar.
+const progressWidth = 30
+
 // event is the structure sent over the connection between the client and the
-// daemon and vice-versa using a JSON encoding.
+// daemon and vice-versa using a JSON encoding. An event with a progress
+// updates the progress bar of an operation.
 type event struct {
-	Err   bool
-	Value string
+	Err      bool
+	Value    string
+	Progress *progress `json:",omitempty"`
 }
 
-// SocketClient opens a connection to a unix socket daemon to send commands.
+// SocketClient opens a connection to a daemon to send commands.
 //
 // - implements node.Client
 type socketClient struct {
 	socketpath  string
+	config      string
 	out         io.Writer
 	dialTimeout time.Duration
 	dialFn      func(network, addr string, timeout time.Duration) (net.Conn, error)
 }
 
 // Send implements node.Client. It opens a connection and sends the data to the
-// daemon. It writes the result of the command to the output.
+// daemon after the token of the config folder. It writes the result of the
+// command to the output.
 func (c socketClient) Send(data []byte) error {
-	conn, err := c.dialFn("unix", c.socketpath, c.dialTimeout)
+	token, err := readToken(c.config)
+	if err != nil {
+		return xerrors.Errorf("couldn't read token: %v", err)
+	}
+
+	conn, err := c.dialFn(ipcNetwork, c.socketpath, c.dialTimeout)
 	if err != nil {
 		return xerrors.Errorf("couldn't open connection: %v", err)
 	}
 
 	defer conn.Close()
 
-	_, err = conn.Write(data)
+	_, err = conn.Write(append(token, data...))
 	if err != nil {
 		return xerrors.Errorf("couldn't write to daemon: %v", err)
 	}
@@ -59,9 +71,22 @@ func (c socketClient) Send(data []byte) error {
 	// The client will now wait for incoming messages from the daemon, either
 	// results of the command, or an error if something goes wrong.
 	dec := json.NewDecoder(conn)
-	var evt event
+
+	// The progress bar is drawn over the same line, which must be ended before
+	// anything else is written.
+	bar := false
+	endBar := func() {
+		if bar {
+			fmt.Fprintln(c.out)
+			bar = false
+		}
+	}
+
+	defer endBar()
 
 	for {
+		var evt event
+
 		err = dec.Decode(&evt)
 		if err == io.EOF {
 			return nil
@@ -70,6 +95,14 @@ func (c socketClient) Send(data []byte) error {
 			return xerrors.Errorf("fail to decode event: %v", err)
 		}
 
+		if evt.Progress != nil {
+			renderProgress(c.out, *evt.Progress)
+			bar = true
+			continue
+		}
+
+		endBar()
+
 		if evt.Err {
 			return xerrors.New(evt.Value)
 		}
@@ -78,9 +111,31 @@ func (c socketClient) Send(data []byte) error {
 	}
 }
 
+// renderProgress draws the progress bar of the operation over the current
+// line, or only the number of steps done when the total is unknown.
+func renderProgress(out io.Writer, p progress) {
+	if p.Total == 0 {
+		fmt.Fprintf(out, "\r%s %d", p.Name, p.Done)
+		return
+	}
+
+	done := p.Done
+	if done > p.Total {
+		done = p.Total
+	}
+
+	filled := int(done * progressWidth / p.Total)
+
+	fmt.Fprintf(out, "\r%s [%s%s] %d/%d", p.Name, strings.Repeat("=", filled),
+		strings.Repeat(" ", progressWidth-filled), p.Done, p.Total)
+}
+
 // SocketDaemon is a daemon using UNIX socket. This allows the permissions to be
 // managed by the filesystem. A user must have read/write access to send a
-// command to the daemon.
+// command to the daemon. On Windows, it uses a named pipe that only accepts the
+// local clients. In both cases, a client must send one of the tokens written to
+// the config folder, and the destructive commands require the administrator
+// one.
 //
 // - implements node.Daemon
 type socketDaemon struct {
@@ -88,18 +143,33 @@ type socketDaemon struct {
 
 	logger      zerolog.Logger
 	socketpath  string
+	config      string
+	tokens      tokens
 	injector    Injector
+	operations  Operations
 	actions     *actionMap
 	closing     chan struct{}
 	readTimeout time.Duration
 	listenFn    func(network, addr string) (net.Listener, error)
 }
 
-// Listen implements node.Daemon. It starts the daemon by creating the unix
-// socket file to the path.
+// Listen implements node.Daemon. It starts the daemon by writing the tokens to
+// the config folder, and by creating the unix socket file to the path, or the
+// named pipe on Windows.
 func (d *socketDaemon) Listen() error {
-	socket, err := d.listenFn("unix", d.socketpath)
+	err := ipcCheckConfig(d.config)
 	if err != nil {
+		return xerrors.Errorf("insecure config: %v", err)
+	}
+
+	d.tokens, err = newTokens(d.config)
+	if err != nil {
+		return xerrors.Errorf("couldn't write tokens: %v", err)
+	}
+
+	socket, err := d.listenFn(ipcNetwork, d.socketpath)
+	if err != nil {
+		removeTokens(d.config)
 		return xerrors.Errorf("couldn't bind socket: %v", err)
 	}
 
@@ -138,12 +208,11 @@ func (d *socketDaemon) handleConn(conn net.Conn) {
 
 	d.logger.Trace().Msg("daemon is handling a connection")
 
-	// Read the first two bytes that will be converted into the action ID.
-	buffer := make([]byte, 2)
-
 	conn.SetReadDeadline(time.Now().Add(d.readTimeout))
 
-	_, err := conn.Read(buffer)
+	token := make([]byte, tokenSize)
+
+	_, err := io.ReadFull(conn, token)
 	if err == io.EOF {
 		// Connection closed upfront so it does not need further handling. This
 		// happens for instance when testing the connectivity of the daemon.
@@ -154,6 +223,22 @@ func (d *socketDaemon) handleConn(conn net.Conn) {
 		return
 	}
 
+	role := d.tokens.authenticate(token)
+	if role == roleNone {
+		d.logger.Warn().Msg("client failed to authenticate")
+		d.sendError(conn, xerrors.New("authentication failed"))
+		return
+	}
+
+	// Read the next two bytes that will be converted into the action ID.
+	buffer := make([]byte, 2)
+
+	_, err = conn.Read(buffer)
+	if err != nil {
+		d.sendError(conn, xerrors.Errorf("stream corrupted: %v", err))
+		return
+	}
+
 	dec := json.NewDecoder(conn)
 
 	fset := make(FlagSet)
@@ -165,6 +250,7 @@ func (d *socketDaemon) handleConn(conn net.Conn) {
 
 	d.logger.Debug().
 		Hex("command", buffer).
+		Stringer("role", role).
 		Str("flags", fmt.Sprintf("%v", fset)).
 		Msg("received command on the daemon")
 
@@ -176,10 +262,16 @@ func (d *socketDaemon) handleConn(conn net.Conn) {
 		return
 	}
 
+	if isDestructive(action) && role != roleAdmin {
+		d.sendError(conn, xerrors.Errorf("command '%d' requires the administrator token", id))
+		return
+	}
+
 	actx := Context{
-		Injector: d.injector,
-		Flags:    fset,
-		Out:      newClientWriter(conn),
+		Injector:   d.injector,
+		Flags:      fset,
+		Out:        newClientWriter(conn),
+		Operations: d.operations,
 	}
 
 	err = action.Execute(actx)
@@ -208,6 +300,8 @@ func (d *socketDaemon) Close() error {
 	close(d.closing)
 	d.Wait()
 
+	removeTokens(d.config)
+
 	return nil
 }
 
@@ -215,6 +309,7 @@ func (d *socketDaemon) Close() error {
 // using a JSON message wrapper.
 //
 // - implements io.Writer
+// - implements node.progressWriter
 type clientWriter struct {
 	enc *json.Encoder
 }
@@ -237,14 +332,26 @@ func (w *clientWriter) Write(data []byte) (int, error) {
 	return len(data), nil
 }
 
+// writeProgress implements node.progressWriter. It sends the progress of an
+// operation to the client.
+func (w *clientWriter) writeProgress(p progress) error {
+	err := w.enc.Encode(event{Progress: &p})
+	if err != nil {
+		return xerrors.Errorf("while packing progress: %v", err)
+	}
+
+	return nil
+}
+
 // SocketFactory provides primitives to create a daemon and clients from a CLI
 // context.
 //
 // - implements node.DaemonFactory
 type socketFactory struct {
-	injector Injector
-	actions  *actionMap
-	out      io.Writer
+	injector   Injector
+	operations Operations
+	actions    *actionMap
+	out        io.Writer
 }
 
 // ClientFromContext implements node.DaemonFactory. It creates a client based on
@@ -252,9 +359,10 @@ type socketFactory struct {
 func (f socketFactory) ClientFromContext(ctx cli.Flags) (Client, error) {
 	client := socketClient{
 		socketpath:  f.getSocketPath(ctx),
+		config:      ctx.Path("config"),
 		out:         f.out,
 		dialTimeout: ioTimeout,
-		dialFn:      net.DialTimeout,
+		dialFn:      ipcDial,
 	}
 
 	return client, nil
@@ -268,16 +376,31 @@ func (f socketFactory) DaemonFromContext(ctx cli.Flags) (Daemon, error) {
 	daemon := &socketDaemon{
 		logger:      dela.Logger.With().Str("daemon", socketpath).Logger(),
 		socketpath:  socketpath,
+		config:      ctx.Path("config"),
 		injector:    f.injector,
+		operations:  f.operations,
 		actions:     f.actions,
 		closing:     make(chan struct{}),
 		readTimeout: ioTimeout,
-		listenFn:    net.Listen,
+		listenFn:    ipcListen,
 	}
 
 	return daemon, nil
 }
 
 func (f socketFactory) getSocketPath(ctx cli.Flags) string {
-	return filepath.Join(ctx.Path("config"), "daemon.sock")
+	return ipcPath(ctx.Path("config"))
+}
+
+// DialDaemon opens a connection to the daemon of the config folder. It can be
+// used to check that the daemon is running, as the daemon closes the
+// connections that are closed upfront.
+func DialDaemon(config string, timeout time.Duration) (net.Conn, error) {
+	return ipcDial(ipcNetwork, ipcPath(config), timeout)
+}
+
+// ListenDaemon listens to the channel of the daemon of the config folder. It
+// allows a test to stand in for a daemon.
+func ListenDaemon(config string) (net.Listener, error) {
+	return ipcListen(ipcNetwork, ipcPath(config))
 }
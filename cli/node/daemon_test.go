@@ -3,6 +3,7 @@ package node
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net"
 	"os"
@@ -22,12 +23,16 @@ func TestSocketClient_Send(t *testing.T) {
 
 	defer os.RemoveAll(dir)
 
+	_, err = newTokens(dir)
+	require.NoError(t, err)
+
 	out := new(bytes.Buffer)
 
 	client := socketClient{
-		socketpath: filepath.Join(dir, "daemon.sock"),
+		socketpath: ipcPath(dir),
+		config:     dir,
 		out:        out,
-		dialFn:     net.DialTimeout,
+		dialFn:     ipcDial,
 	}
 
 	listen(t, client.socketpath)
@@ -37,9 +42,64 @@ func TestSocketClient_Send(t *testing.T) {
 	require.Equal(t, "deadbeef\n", out.String())
 }
 
+func TestSocketClient_Progress_Send(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	_, err = newTokens(dir)
+	require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+
+	client := socketClient{
+		socketpath: ipcPath(dir),
+		config:     dir,
+		out:        out,
+		dialFn:     ipcDial,
+	}
+
+	listen(t, client.socketpath,
+		event{Progress: &progress{Name: "test", Done: 1, Total: 3}},
+		event{Progress: &progress{Name: "test", Done: 3, Total: 3}},
+		event{Value: "done"},
+		event{Progress: &progress{Name: "test", Done: 5}},
+	)
+
+	err = client.Send([]byte("deadbeef"))
+	require.NoError(t, err)
+	require.Equal(t, "deadbeef\n"+
+		"\rtest [==========                    ] 1/3"+
+		"\rtest [==============================] 3/3\n"+
+		"done\n"+
+		"\rtest 5\n", out.String())
+}
+
+func TestRenderProgress(t *testing.T) {
+	out := new(bytes.Buffer)
+
+	renderProgress(out, progress{Name: "test", Done: 5, Total: 2})
+	require.Equal(t, "\rtest [==============================] 5/2", out.String())
+}
+
+func TestSocketClient_FailToken_Send(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	client := socketClient{config: dir}
+
+	err = client.Send(nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "couldn't read token: failed to read: ")
+}
+
 func TestSocketClient_FailDial_Send(t *testing.T) {
 	client := socketClient{
 		socketpath: "",
+		config:     makeConfig(t),
 		dialFn: func(network, addr string, timeout time.Duration) (net.Conn, error) {
 			return nil, fake.GetError()
 		},
@@ -51,6 +111,7 @@ func TestSocketClient_FailDial_Send(t *testing.T) {
 
 func TestSocketClient_BadOutConn_Send(t *testing.T) {
 	client := socketClient{
+		config: makeConfig(t),
 		dialFn: func(network, addr string, timeout time.Duration) (net.Conn, error) {
 			return badConn{}, nil
 		},
@@ -62,6 +123,7 @@ func TestSocketClient_BadOutConn_Send(t *testing.T) {
 
 func TestSocketClient_BadInConn_Send(t *testing.T) {
 	client := socketClient{
+		config: makeConfig(t),
 		dialFn: func(network, addr string, timeout time.Duration) (net.Conn, error) {
 			return badConn{counter: fake.NewCounter(1)}, nil
 		},
@@ -89,12 +151,15 @@ func TestSocketDaemon_Listen(t *testing.T) {
 	}) // id 0
 	actions.Set(fakeAction{err: fake.GetError()}) // id 1
 
+	actions.Set(Destructive(fakeAction{})) // id 2
+
 	daemon := &socketDaemon{
-		socketpath:  filepath.Join(dir, "daemon.sock"),
+		socketpath:  ipcPath(dir),
+		config:      dir,
 		actions:     actions,
 		closing:     make(chan struct{}),
 		readTimeout: 50 * time.Millisecond,
-		listenFn:    net.Listen,
+		listenFn:    ipcListen,
 	}
 
 	err = daemon.Listen()
@@ -105,9 +170,10 @@ func TestSocketDaemon_Listen(t *testing.T) {
 	out := new(bytes.Buffer)
 	client := socketClient{
 		socketpath:  daemon.socketpath,
+		config:      dir,
 		out:         out,
 		dialTimeout: time.Second,
-		dialFn:      net.DialTimeout,
+		dialFn:      ipcDial,
 	}
 
 	err = client.Send(append([]byte{0x0, 0x0}, buf...))
@@ -118,7 +184,10 @@ func TestSocketDaemon_Listen(t *testing.T) {
 	require.EqualError(t, err, fake.Err("command error"))
 
 	err = client.Send(append([]byte{0x2, 0x0}, []byte("{}")...))
-	require.EqualError(t, err, "unknown command '2'")
+	require.NoError(t, err)
+
+	err = client.Send(append([]byte{0x3, 0x0}, []byte("{}")...))
+	require.EqualError(t, err, "unknown command '3'")
 
 	err = client.Send([]byte{0x0, 0x0, 0x0})
 	require.Error(t, err)
@@ -129,6 +198,48 @@ func TestSocketDaemon_Listen(t *testing.T) {
 	require.Contains(t, err.Error(), "stream corrupted: ")
 }
 
+func TestSocketDaemon_Operation_Listen(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	actions := &actionMap{}
+	actions.Set(fakeOperationAction{})
+
+	daemon := &socketDaemon{
+		socketpath:  ipcPath(dir),
+		config:      dir,
+		operations:  NewOperations(),
+		actions:     actions,
+		closing:     make(chan struct{}),
+		readTimeout: 50 * time.Millisecond,
+		listenFn:    ipcListen,
+	}
+
+	err = daemon.Listen()
+	require.NoError(t, err)
+
+	defer daemon.Close()
+
+	out := new(bytes.Buffer)
+	client := socketClient{
+		socketpath:  daemon.socketpath,
+		config:      dir,
+		out:         out,
+		dialTimeout: time.Second,
+		dialFn:      ipcDial,
+	}
+
+	err = client.Send(append([]byte{0x0, 0x0}, []byte("{}")...))
+	require.NoError(t, err)
+
+	statuses := daemon.operations.List()
+	require.Len(t, statuses, 1)
+	require.Regexp(t, "^operation "+statuses[0].ID+" started\n"+
+		"(\rtest \\[=+ *\\] 1/2)?\rtest \\[=+\\] 2/2\ndone\n$", out.String())
+}
+
 func TestSocketDaemon_ConnectivityTest_Listen(t *testing.T) {
 	dir, err := ioutil.TempDir(os.TempDir(), "dela")
 	require.NoError(t, err)
@@ -136,11 +247,12 @@ func TestSocketDaemon_ConnectivityTest_Listen(t *testing.T) {
 	defer os.RemoveAll(dir)
 
 	daemon := &socketDaemon{
-		socketpath:  filepath.Join(dir, "daemon.sock"),
+		socketpath:  ipcPath(dir),
+		config:      dir,
 		actions:     &actionMap{},
 		closing:     make(chan struct{}),
 		readTimeout: 50 * time.Millisecond,
-		listenFn:    net.Listen,
+		listenFn:    ipcListen,
 	}
 
 	err = daemon.Listen()
@@ -148,20 +260,91 @@ func TestSocketDaemon_ConnectivityTest_Listen(t *testing.T) {
 
 	defer daemon.Close()
 
-	conn, err := net.DialTimeout("unix", daemon.socketpath, 1*time.Second)
+	conn, err := DialDaemon(dir, time.Second)
 	require.NoError(t, err)
 	require.NoError(t, conn.Close())
 }
 
+func TestSocketDaemon_Auth_Listen(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	actions := &actionMap{}
+	actions.Set(fakeAction{})              // id 0
+	actions.Set(Destructive(fakeAction{})) // id 1
+
+	daemon := &socketDaemon{
+		socketpath:  ipcPath(dir),
+		config:      dir,
+		actions:     actions,
+		closing:     make(chan struct{}),
+		readTimeout: 50 * time.Millisecond,
+		listenFn:    ipcListen,
+	}
+
+	err = daemon.Listen()
+	require.NoError(t, err)
+
+	defer daemon.Close()
+
+	// A member of the group can only read the user token.
+	require.NoError(t, os.Remove(filepath.Join(dir, adminTokenFile)))
+
+	out := new(bytes.Buffer)
+	client := socketClient{
+		socketpath:  daemon.socketpath,
+		config:      dir,
+		out:         out,
+		dialTimeout: time.Second,
+		dialFn:      ipcDial,
+	}
+
+	err = client.Send([]byte("\x00\x00{}"))
+	require.NoError(t, err)
+	require.Equal(t, "deadbeef\n", out.String())
+
+	err = client.Send([]byte("\x01\x00{}"))
+	require.EqualError(t, err, "command '1' requires the administrator token")
+
+	// A client without a valid token is refused.
+	_, err = newTokens(dir)
+	require.NoError(t, err)
+
+	err = client.Send([]byte("\x00\x00{}"))
+	require.EqualError(t, err, "authentication failed")
+}
+
 func TestSocketDaemon_FailBindSocket_Listen(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
 	daemon := &socketDaemon{
+		config: dir,
 		listenFn: func(network, addr string) (net.Listener, error) {
 			return nil, fake.GetError()
 		},
 	}
 
-	err := daemon.Listen()
+	err = daemon.Listen()
 	require.EqualError(t, err, fake.Err("couldn't bind socket"))
+
+	// The tokens are removed when the daemon fails to start.
+	_, err = readToken(dir)
+	require.Error(t, err)
+}
+
+func TestSocketDaemon_FailTokens_Listen(t *testing.T) {
+	daemon := &socketDaemon{
+		config: filepath.Join(os.TempDir(), "dela-missing"),
+	}
+
+	err := daemon.Listen()
+	require.Error(t, err)
+	require.Regexp(t, "^(insecure config|couldn't write tokens): ", err.Error())
 }
 
 func TestSocketDaemon_ConnClosedFromClient_HandleConn(t *testing.T) {
@@ -197,21 +380,85 @@ func TestClientWriter_BadWriter_Write(t *testing.T) {
 	require.EqualError(t, err, fake.Err("while packing data"))
 }
 
+func TestClientWriter_WriteProgress(t *testing.T) {
+	buffer := new(bytes.Buffer)
+
+	w := newClientWriter(buffer)
+
+	err := w.writeProgress(progress{Name: "test", Done: 1, Total: 2})
+	require.NoError(t, err)
+
+	var evt event
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &evt))
+	require.Equal(t, progress{Name: "test", Done: 1, Total: 2}, *evt.Progress)
+
+	w = newClientWriter(fake.NewBadHash())
+
+	err = w.writeProgress(progress{})
+	require.EqualError(t, err, fake.Err("while packing progress"))
+}
+
 func TestSocketFactory_ClientFromContext(t *testing.T) {
 	factory := socketFactory{}
 
 	client, err := factory.ClientFromContext(fakeContext{path: "cfgdir"})
 	require.NoError(t, err)
 	require.NotNil(t, client)
-	require.Equal(t, filepath.Join("cfgdir", "daemon.sock"),
-		client.(socketClient).socketpath)
+	require.Equal(t, ipcPath("cfgdir"), client.(socketClient).socketpath)
+	require.Equal(t, "cfgdir", client.(socketClient).config)
+}
+
+func TestListenDaemon(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	_, err = DialDaemon(dir, time.Second)
+	require.Error(t, err)
+
+	socket, err := ListenDaemon(dir)
+	require.NoError(t, err)
+
+	defer socket.Close()
+
+	go func() {
+		conn, err := socket.Accept()
+		if err == nil {
+			conn.Write([]byte("pong"))
+			conn.Close()
+		}
+	}()
+
+	conn, err := DialDaemon(dir, time.Second)
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	data, err := ioutil.ReadAll(conn)
+	require.NoError(t, err)
+	require.Equal(t, "pong", string(data))
 }
 
 // -----------------------------------------------------------------------------
 // Utility functions
 
-func listen(t *testing.T, path string) {
-	socket, err := net.Listen("unix", path)
+// makeConfig returns a config folder with the tokens of a daemon, which is
+// removed at the end of the test.
+func makeConfig(t *testing.T) string {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	_, err = newTokens(dir)
+	require.NoError(t, err)
+
+	return dir
+}
+
+func listen(t *testing.T, path string, events ...event) {
+	socket, err := ipcListen(ipcNetwork, path)
 	require.NoError(t, err)
 
 	go func() {
@@ -221,6 +468,10 @@ func listen(t *testing.T, path string) {
 		defer conn.Close()
 		defer socket.Close()
 
+		token := make([]byte, tokenSize)
+		_, err = io.ReadFull(conn, token)
+		require.NoError(t, err)
+
 		buffer := make([]byte, 100)
 		n, err := conn.Read(buffer)
 		require.NoError(t, err)
@@ -228,6 +479,11 @@ func listen(t *testing.T, path string) {
 		enc := json.NewEncoder(conn)
 		err = enc.Encode(event{Value: string(buffer[:n])})
 		require.NoError(t, err)
+
+		for _, evt := range events {
+			err = enc.Encode(evt)
+			require.NoError(t, err)
+		}
 	}()
 }
 
@@ -303,6 +559,17 @@ func (a fakeAction) Execute(req Context) error {
 	return nil
 }
 
+type fakeOperationAction struct{}
+
+func (fakeOperationAction) Execute(req Context) error {
+	return req.Operations.Run(req, "test", func(r Reporter) (string, error) {
+		r.Report(1, 2)
+		r.Report(2, 2)
+
+		return "done", nil
+	})
+}
+
 type fakeContext struct {
 	cli.Flags
 	path string
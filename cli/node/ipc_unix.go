@@ -0,0 +1,67 @@
+//go:build !windows
+// +build !windows
+
+package node
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+)
+
+// ipcNetwork is the network of the channel between the CLI and the daemon,
+// which is a UNIX socket whose permissions are managed by the filesystem.
+const ipcNetwork = "unix"
+
+// ipcMode is the permissions of the socket, which only the owner and the group
+// of the daemon can connect to.
+const ipcMode os.FileMode = 0660
+
+var (
+	ipcListen = listenUnix
+	ipcDial   = net.DialTimeout
+)
+
+// ipcPath returns the path of the socket of the daemon in the config folder.
+func ipcPath(config string) string {
+	return filepath.Join(config, "daemon.sock")
+}
+
+// ipcCheckConfig returns an error if the config folder can be written by
+// anyone, who could then replace the socket or the tokens of the daemon.
+func ipcCheckConfig(config string) error {
+	// An empty folder is the working directory, like for the paths of the
+	// files.
+	if config == "" {
+		config = "."
+	}
+
+	info, err := os.Stat(config)
+	if err != nil {
+		return xerrors.Errorf("failed to stat: %v", err)
+	}
+
+	if info.Mode().Perm()&0002 != 0 {
+		return xerrors.Errorf("config folder '%s' is writable by others", config)
+	}
+
+	return nil
+}
+
+// listenUnix listens to the socket and restricts its permissions.
+func listenUnix(network, addr string) (net.Listener, error) {
+	socket, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Chmod(addr, ipcMode)
+	if err != nil {
+		socket.Close()
+		return nil, xerrors.Errorf("failed to set permissions: %v", err)
+	}
+
+	return socket, nil
+}
@@ -0,0 +1,50 @@
+//go:build !windows
+// +build !windows
+
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPCCheckConfig(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ipcCheckConfig(dir))
+	require.NoError(t, ipcCheckConfig(""))
+
+	require.NoError(t, os.Chmod(dir, 0777))
+
+	err = ipcCheckConfig(dir)
+	require.EqualError(t, err, "config folder '"+dir+"' is writable by others")
+
+	err = ipcCheckConfig(dir + "-missing")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to stat: ")
+}
+
+func TestListenUnix(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	socket, err := listenUnix(ipcNetwork, ipcPath(dir))
+	require.NoError(t, err)
+
+	defer socket.Close()
+
+	info, err := os.Stat(ipcPath(dir))
+	require.NoError(t, err)
+	require.Equal(t, ipcMode, info.Mode().Perm())
+
+	_, err = listenUnix(ipcNetwork, ipcPath(dir))
+	require.Error(t, err)
+}
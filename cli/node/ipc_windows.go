@@ -0,0 +1,357 @@
+// This file contains the implementation of the channel between the CLI and
+// the daemon with a named pipe, as the UNIX sockets are not supported by every
+// version of Windows. The pipes are opened in synchronous mode, which means
+// that the deadlines of the connections are ignored.
+
+package node
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/xerrors"
+)
+
+// ipcNetwork is the network of the channel between the CLI and the daemon.
+const ipcNetwork = "pipe"
+
+const (
+	pipeAccessDuplex          = 0x3
+	pipeRejectRemoteClients   = 0x8
+	pipeUnlimitedInstances    = 255
+	pipeBufferSize            = 4096
+	fileFlagFirstPipeInstance = 0x00080000
+
+	errorPipeBusy      syscall.Errno = 231
+	errorPipeConnected syscall.Errno = 535
+)
+
+// pipeBusyDelay is the time to wait before a new attempt when every instance
+// of the pipe is busy.
+const pipeBusyDelay = 10 * time.Millisecond
+
+var (
+	ipcListen = listenPipe
+	ipcDial   = dialPipe
+
+	errListenerClosed = xerrors.New("listener closed")
+
+	kernel32             = syscall.NewLazyDLL("kernel32.dll")
+	procCreateNamedPipeW = kernel32.NewProc("CreateNamedPipeW")
+	procConnectNamedPipe = kernel32.NewProc("ConnectNamedPipe")
+)
+
+// ipcPath returns the name of the pipe of the daemon of the config folder. The
+// pipes don't live in the filesystem, therefore the name is derived from the
+// absolute path of the folder, which is case-insensitive on Windows.
+func ipcPath(config string) string {
+	abs, err := filepath.Abs(config)
+	if err != nil {
+		abs = config
+	}
+
+	digest := sha256.Sum256([]byte(strings.ToLower(abs)))
+
+	return `\\.\pipe\dela-` + hex.EncodeToString(digest[:8])
+}
+
+// ipcCheckConfig returns nil as the permissions of the config folder are
+// managed by the access control lists of Windows, which the daemon doesn't
+// inspect.
+func ipcCheckConfig(config string) error {
+	return nil
+}
+
+// pipeAddr is the address of a named pipe.
+//
+// - implements net.Addr
+type pipeAddr string
+
+// Network implements net.Addr. It returns the name of the network.
+func (pipeAddr) Network() string {
+	return ipcNetwork
+}
+
+// String implements net.Addr. It returns the name of the pipe.
+func (a pipeAddr) String() string {
+	return string(a)
+}
+
+// pipeListener is a listener of the clients of a named pipe. An instance of
+// the pipe is always waiting for the next client, so that a client never finds
+// the pipe missing while the daemon is running.
+//
+// - implements net.Listener
+type pipeListener struct {
+	sync.Mutex
+
+	path   string
+	next   syscall.Handle
+	closed bool
+}
+
+func listenPipe(network, path string) (net.Listener, error) {
+	// The first instance fails if the pipe already exists, which happens when
+	// another daemon uses the same config folder.
+	h, err := createPipe(path, true)
+	if err != nil {
+		return nil, &os.PathError{Op: "listen", Path: path, Err: err}
+	}
+
+	l := &pipeListener{
+		path: path,
+		next: h,
+	}
+
+	return l, nil
+}
+
+// Accept implements net.Listener. It waits for the next client of the pipe.
+func (l *pipeListener) Accept() (net.Conn, error) {
+	h, err := l.take()
+	if err != nil {
+		return nil, err
+	}
+
+	err = connectPipe(h)
+
+	l.Lock()
+	closed := l.closed
+	l.Unlock()
+
+	if closed {
+		syscall.CloseHandle(h)
+		return nil, errListenerClosed
+	}
+
+	if err != nil {
+		syscall.CloseHandle(h)
+		return nil, xerrors.Errorf("failed to connect: %v", err)
+	}
+
+	l.prepare()
+
+	return newPipeConn(h, l.path, true), nil
+}
+
+// Close implements net.Listener. It closes the instance waiting for a client,
+// and releases a pending Accept.
+func (l *pipeListener) Close() error {
+	l.Lock()
+
+	if l.closed {
+		l.Unlock()
+		return nil
+	}
+
+	l.closed = true
+	next := l.next
+	l.next = syscall.InvalidHandle
+
+	l.Unlock()
+
+	if next != syscall.InvalidHandle {
+		return syscall.CloseHandle(next)
+	}
+
+	// A pending Accept waits until a client connects, therefore the listener
+	// connects to itself to release it.
+	conn, err := dialPipe(ipcNetwork, l.path, 0)
+	if err == nil {
+		conn.Close()
+	}
+
+	return nil
+}
+
+// Addr implements net.Listener. It returns the name of the pipe.
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr(l.path)
+}
+
+// take returns the instance waiting for a client, or a new one.
+func (l *pipeListener) take() (syscall.Handle, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.closed {
+		return syscall.InvalidHandle, errListenerClosed
+	}
+
+	h := l.next
+	l.next = syscall.InvalidHandle
+
+	if h != syscall.InvalidHandle {
+		return h, nil
+	}
+
+	h, err := createPipe(l.path, false)
+	if err != nil {
+		return h, xerrors.Errorf("failed to create pipe: %v", err)
+	}
+
+	return h, nil
+}
+
+// prepare creates the instance for the next client. A failure is ignored, as
+// the next Accept tries again.
+func (l *pipeListener) prepare() {
+	h, err := createPipe(l.path, false)
+	if err != nil {
+		return
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	if l.closed || l.next != syscall.InvalidHandle {
+		syscall.CloseHandle(h)
+		return
+	}
+
+	l.next = h
+}
+
+// pipeConn is a connection to a named pipe.
+//
+// - implements net.Conn
+type pipeConn struct {
+	*os.File
+
+	addr   pipeAddr
+	server bool
+}
+
+func newPipeConn(h syscall.Handle, path string, server bool) *pipeConn {
+	return &pipeConn{
+		File:   os.NewFile(uintptr(h), path),
+		addr:   pipeAddr(path),
+		server: server,
+	}
+}
+
+// Read implements net.Conn. It reads from the pipe, and returns io.EOF when
+// the other side has closed it.
+func (c *pipeConn) Read(data []byte) (int, error) {
+	n, err := c.File.Read(data)
+	if xerrors.Is(err, syscall.ERROR_BROKEN_PIPE) {
+		return n, io.EOF
+	}
+
+	return n, err
+}
+
+// Close implements net.Conn. The daemon waits for the client to read the data
+// before it closes the pipe, which would otherwise discard it.
+func (c *pipeConn) Close() error {
+	if c.server {
+		syscall.FlushFileBuffers(syscall.Handle(c.File.Fd()))
+	}
+
+	return c.File.Close()
+}
+
+// LocalAddr implements net.Conn. It returns the name of the pipe.
+func (c *pipeConn) LocalAddr() net.Addr {
+	return c.addr
+}
+
+// RemoteAddr implements net.Conn. It returns the name of the pipe.
+func (c *pipeConn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+// SetDeadline implements net.Conn. It does nothing as the synchronous pipes
+// don't support deadlines.
+func (c *pipeConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+// SetReadDeadline implements net.Conn. It does nothing as the synchronous
+// pipes don't support deadlines.
+func (c *pipeConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline implements net.Conn. It does nothing as the synchronous
+// pipes don't support deadlines.
+func (c *pipeConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+func dialPipe(network, path string, timeout time.Duration) (net.Conn, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, &os.PathError{Op: "dial", Path: path, Err: err}
+	}
+
+	deadline := time.Now().Add(timeout)
+
+	for {
+		h, err := syscall.CreateFile(name, syscall.GENERIC_READ|syscall.GENERIC_WRITE,
+			0, nil, syscall.OPEN_EXISTING, 0, 0)
+		if err == nil {
+			return newPipeConn(h, path, false), nil
+		}
+
+		// Every instance is busy until the daemon accepts the next client.
+		if err != errorPipeBusy || time.Now().After(deadline) {
+			return nil, &os.PathError{Op: "dial", Path: path, Err: err}
+		}
+
+		time.Sleep(pipeBusyDelay)
+	}
+}
+
+func createPipe(path string, first bool) (syscall.Handle, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return syscall.InvalidHandle, err
+	}
+
+	mode := uint32(pipeAccessDuplex)
+	if first {
+		mode |= fileFlagFirstPipeInstance
+	}
+
+	// The pipe is opened in byte mode, blocking, and for the local clients
+	// only.
+	r, _, err := procCreateNamedPipeW.Call(
+		uintptr(unsafe.Pointer(name)),
+		uintptr(mode),
+		uintptr(pipeRejectRemoteClients),
+		uintptr(pipeUnlimitedInstances),
+		uintptr(pipeBufferSize),
+		uintptr(pipeBufferSize),
+		0,
+		0,
+	)
+
+	h := syscall.Handle(r)
+	if h == syscall.InvalidHandle {
+		return h, err
+	}
+
+	return h, nil
+}
+
+func connectPipe(h syscall.Handle) error {
+	r, _, err := procConnectNamedPipe.Call(uintptr(h), 0)
+
+	// A client that connected between the creation of the instance and the
+	// call is already connected.
+	if r == 0 && err != errorPipeConnected {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPCPath(t *testing.T) {
+	require.Equal(t, ipcPath(`C:\Dela\Node1`), ipcPath(`c:\dela\node1`))
+	require.NotEqual(t, ipcPath(`C:\Dela\Node1`), ipcPath(`C:\Dela\Node2`))
+	require.Regexp(t, `^\\\\\.\\pipe\\dela-[0-9a-f]{16}$`, ipcPath("node1"))
+}
+
+func TestPipeListener_Accept(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := ipcPath(dir)
+
+	l, err := listenPipe(ipcNetwork, path)
+	require.NoError(t, err)
+	require.Equal(t, path, l.Addr().String())
+	require.Equal(t, ipcNetwork, l.Addr().Network())
+
+	// Only one daemon can use the pipe.
+	_, err = listenPipe(ipcNetwork, path)
+	require.Error(t, err)
+
+	done := make(chan error, 1)
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			conn, err := l.Accept()
+			if err != nil {
+				done <- err
+				return
+			}
+
+			buffer := make([]byte, 4)
+			_, err = conn.Read(buffer)
+			if err == nil {
+				_, err = conn.Write(buffer)
+			}
+
+			conn.Close()
+
+			if err != nil {
+				done <- err
+				return
+			}
+		}
+
+		done <- nil
+	}()
+
+	for i := 0; i < 3; i++ {
+		conn, err := dialPipe(ipcNetwork, path, time.Second)
+		require.NoError(t, err)
+
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+
+		data, err := ioutil.ReadAll(conn)
+		require.NoError(t, err)
+		require.Equal(t, "ping", string(data))
+
+		require.NoError(t, conn.Close())
+	}
+
+	require.NoError(t, <-done)
+	require.NoError(t, l.Close())
+	require.NoError(t, l.Close())
+
+	_, err = l.Accept()
+	require.Equal(t, errListenerClosed, err)
+
+	_, err = dialPipe(ipcNetwork, path, time.Second)
+	require.Error(t, err)
+}
+
+func TestPipeListener_Close(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	l, err := listenPipe(ipcNetwork, ipcPath(dir))
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := l.Accept()
+		done <- err
+	}()
+
+	// Wait for the Accept to take the instance of the pipe.
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(t, l.Close())
+
+	select {
+	case err := <-done:
+		require.Equal(t, errListenerClosed, err)
+	case <-time.After(time.Second):
+		t.Fatal("accept not released")
+	}
+}
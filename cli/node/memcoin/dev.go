@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/contracts/coin"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/crypto/bls"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// devWaitTimeout is the maximum amount of time the dev command waits for
+	// the node to start and for the accounts to be funded.
+	devWaitTimeout = 30 * time.Second
+
+	devWaitStep = 100 * time.Millisecond
+)
+
+// devController is an initializer with the command to run a local chain for
+// the development of applications.
+//
+// - implements node.Initializer
+type devController struct {
+	cfg config
+}
+
+// SetCommands implements node.Initializer. It sets the dev command.
+func (c devController) SetCommands(builder node.Builder) {
+	cmd := builder.SetCommand("dev")
+	cmd.SetDescription("run a single-node chain with funded accounts for development")
+	cmd.SetFlags(
+		cli.IntFlag{
+			Name:  "port",
+			Usage: "the port of the node",
+			Value: 2000,
+		},
+		cli.StringFlag{
+			Name:  "http",
+			Usage: "the address of the proxy serving the endpoints",
+			Value: "127.0.0.1:8080",
+		},
+		cli.IntFlag{
+			Name:  "accounts",
+			Usage: "the number of accounts funded on the coin contract",
+			Value: 5,
+		},
+		cli.IntFlag{
+			Name:  "balance",
+			Usage: "the number of tokens of each account",
+			Value: 1000000,
+		},
+	)
+	cmd.SetAction(c.run)
+}
+
+// OnStart implements node.Initializer. It does nothing.
+func (devController) OnStart(flags cli.Flags, inj node.Injector) error {
+	return nil
+}
+
+// OnStop implements node.Initializer. It does nothing.
+func (devController) OnStop(inj node.Injector) error {
+	return nil
+}
+
+// run starts a node in a temporary folder that is removed when the node stops,
+// so that nothing is persisted between two runs. The chain has the node as
+// its only member, hence the blocks are created as soon as transactions
+// arrive in the pool. Every endpoint is registered on the proxy and the
+// accounts are funded with the faucet of the coin contract.
+func (c devController) run(flags cli.Flags) error {
+	accounts := flags.Int("accounts")
+	if accounts < 0 {
+		return xerrors.Errorf("invalid number of accounts: %d", accounts)
+	}
+
+	balance := flags.Int("balance")
+	if balance <= 0 {
+		return xerrors.Errorf("invalid balance: %d", balance)
+	}
+
+	dir, err := ioutil.TempDir(os.TempDir(), "memcoin-dev")
+	if err != nil {
+		return xerrors.Errorf("failed to create folder: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	stop, closeStop := c.makeStop()
+
+	maxClaims := accounts
+	if maxClaims < int(coin.DefaultFaucet.MaxClaims) {
+		maxClaims = int(coin.DefaultFaucet.MaxClaims)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- runWithCfg([]string{
+			os.Args[0], "--config", dir, "start",
+			"--port", strconv.Itoa(flags.Int("port")),
+			"--coin-faucet",
+			"--coin-faucet-amount", strconv.Itoa(balance),
+			"--coin-faucet-max-claims", strconv.Itoa(maxClaims),
+		}, config{Channel: stop, Writer: c.cfg.Writer})
+	}()
+
+	err = c.setup(dir, flags.String("http"), accounts, done)
+	if err != nil {
+		closeStop()
+		<-done
+
+		return xerrors.Errorf("failed to setup: %v", err)
+	}
+
+	err = <-done
+	if err != nil {
+		return xerrors.Errorf("node failed: %v", err)
+	}
+
+	return nil
+}
+
+// makeStop returns the channel that stops the node, which is closed when the
+// configured channel is, or when an interruption is received otherwise, and
+// the function to close it earlier.
+func (c devController) makeStop() (chan os.Signal, func()) {
+	stop := make(chan os.Signal)
+	once := sync.Once{}
+
+	closeStop := func() {
+		once.Do(func() { close(stop) })
+	}
+
+	sigs := c.cfg.Channel
+	if sigs == nil {
+		sigs = make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	}
+
+	go func() {
+		<-sigs
+		signal.Stop(sigs)
+		closeStop()
+	}()
+
+	return stop, closeStop
+}
+
+func (c devController) setup(dir, addr string, accounts int, done chan error) error {
+	err := waitSocket(dir, done)
+	if err != nil {
+		return err
+	}
+
+	member, err := c.call(dir, "ordering", "export")
+	if err != nil {
+		return err
+	}
+
+	_, err = c.call(dir, "ordering", "setup", "--member", member)
+	if err != nil {
+		return err
+	}
+
+	endpoints := [][]string{
+		{"proxy", "start", "--clientaddr", addr},
+		{"pool", "register"},
+		{"events", "register"},
+		{"graphql", "register"},
+		{"coin", "faucet"},
+	}
+
+	for _, args := range endpoints {
+		_, err = c.call(dir, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	identities := make([]string, accounts)
+	keys := make([]string, accounts)
+
+	for i := range identities {
+		identities[i], keys[i], err = createAccount(dir, i)
+		if err != nil {
+			return xerrors.Errorf("failed to create account: %v", err)
+		}
+
+		// The account is new so that its first transaction has the nonce
+		// zero.
+		_, err = c.call(dir, "pool", "add", "--key", keys[i], "--nonce", "0",
+			"--args", native.ContractArg, "--args", coin.ContractName,
+			"--args", coin.CmdArg, "--args", coin.CmdFaucet)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, identity := range identities {
+		err = c.waitBalance(dir, identity)
+		if err != nil {
+			return err
+		}
+	}
+
+	w := c.cfg.Writer
+
+	fmt.Fprintf(w, "Development chain running with the config %s\n", dir)
+	fmt.Fprintf(w, "Endpoints on http://%s: /transactions /events /graphql /faucet\n", addr)
+	fmt.Fprintf(w, "Accounts:\n")
+
+	for i, identity := range identities {
+		fmt.Fprintf(w, "  %s (key: %s)\n", identity, keys[i])
+	}
+
+	return nil
+}
+
+// call runs the command against the node and returns its output.
+func (c devController) call(dir string, args ...string) (string, error) {
+	out := new(bytes.Buffer)
+
+	err := runWithCfg(append([]string{os.Args[0], "--config", dir}, args...),
+		config{Writer: out})
+	if err != nil {
+		return "", xerrors.Errorf("command '%s' failed: %v", strings.Join(args[:2], " "), err)
+	}
+
+	return strings.TrimSpace(out.String()), nil
+}
+
+func (c devController) waitBalance(dir, identity string) error {
+	for start := time.Now(); time.Since(start) < devWaitTimeout; time.Sleep(devWaitStep) {
+		out, err := c.call(dir, "coin", "balance", "--identity", identity)
+		if err != nil {
+			return err
+		}
+
+		if out != "0" {
+			return nil
+		}
+	}
+
+	return xerrors.Errorf("account '%s' not funded after timeout", identity)
+}
+
+// waitSocket waits for the daemon of the node to be reachable.
+func waitSocket(dir string, done chan error) error {
+	for start := time.Now(); time.Since(start) < devWaitTimeout; time.Sleep(devWaitStep) {
+		select {
+		case err := <-done:
+			done <- err
+			return xerrors.Errorf("node stopped: %v", err)
+		default:
+		}
+
+		conn, err := node.DialDaemon(dir, devWaitStep)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+	}
+
+	return xerrors.New("node not started after timeout")
+}
+
+// createAccount writes the key of a new signer in the folder and returns the
+// identity in its text form and the path to the key.
+func createAccount(dir string, index int) (string, string, error) {
+	signer := bls.NewSigner()
+
+	data, err := signer.MarshalBinary()
+	if err != nil {
+		return "", "", xerrors.Errorf("failed to marshal signer: %v", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("account%d.key", index))
+
+	err = ioutil.WriteFile(path, data, 0600)
+	if err != nil {
+		return "", "", xerrors.Errorf("failed to write key: %v", err)
+	}
+
+	identity, err := signer.GetPublicKey().MarshalText()
+	if err != nil {
+		return "", "", xerrors.Errorf("failed to marshal identity: %v", err)
+	}
+
+	return string(identity), path, nil
+}
@@ -24,6 +24,12 @@
 //  memcoin --config /tmp/node1 ordering roster add\
 //    --member $(memcoin --config /tmp/node3 ordering export)
 //
+//  # Or run a disposable single-node chain with funded accounts.
+//  memcoin dev --accounts 3
+//
+//  # Or run the steps of a scenario against a local chain.
+//  memcoin scenario --file scenario.yaml
+//
 package main
 
 import (
@@ -33,7 +39,15 @@ import (
 
 	"go.dedis.ch/dela/cli/node"
 	access "go.dedis.ch/dela/contracts/access/controller"
+	coin "go.dedis.ch/dela/contracts/coin/controller"
+	grant "go.dedis.ch/dela/contracts/grant/controller"
+	memory "go.dedis.ch/dela/core/memory/controller"
+	audit "go.dedis.ch/dela/core/ordering/cosipbft/audit/controller"
 	cosipbft "go.dedis.ch/dela/core/ordering/cosipbft/controller"
+	bridge "go.dedis.ch/dela/core/ordering/cosipbft/events/bridge/controller"
+	events "go.dedis.ch/dela/core/ordering/cosipbft/events/controller"
+	graphql "go.dedis.ch/dela/core/ordering/cosipbft/graphql/controller"
+	state "go.dedis.ch/dela/core/ordering/cosipbft/statediff/controller"
 	db "go.dedis.ch/dela/core/store/kv/controller"
 	pool "go.dedis.ch/dela/core/txn/pool/controller"
 	signed "go.dedis.ch/dela/core/txn/signed/controller"
@@ -62,12 +76,22 @@ func runWithCfg(args []string, cfg config) error {
 		cfg.Channel,
 		cfg.Writer,
 		db.NewController(),
+		memory.NewController(),
 		mino.NewController(),
 		cosipbft.NewController(),
+		coin.NewController(),
+		grant.NewController(),
+		audit.NewController(),
+		bridge.NewController(),
 		signed.NewManagerController(),
 		pool.NewController(),
 		access.NewController(),
 		proxy.NewController(),
+		events.NewController(),
+		graphql.NewController(),
+		state.NewController(),
+		devController{cfg: cfg},
+		scenarioController{cfg: cfg},
 	)
 
 	app := builder.Build()
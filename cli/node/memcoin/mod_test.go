@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
-	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -14,6 +14,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/ordering/cosipbft/statediff"
 )
 
 func TestMemcoin_Main(t *testing.T) {
@@ -99,17 +101,121 @@ func TestMemcoin_Scenario_SetupAndTransactions(t *testing.T) {
 	// Run a few transactions.
 	for i := 0; i < 5; i++ {
 		err = runWithCfg(args, config{})
-		require.EqualError(t, err, "command error: transaction refused: duplicate in roster: 127.0.0.1:2115")
+		require.EqualError(t, err, "command error: while preparing tx: invalid change: "+
+			"invalid addition: address '127.0.0.1:2115' already in the authority")
 	}
 
 	// Test a timeout waiting for a transaction.
-	args[7] = "1ns"
+	args = []string{
+		os.Args[0],
+		"--config", node1, "ordering", "budget", "set",
+		"--limit", "0", "--wait", "1ns",
+	}
 	err = runWithCfg(args, config{})
 	require.EqualError(t, err, "command error: transaction not found after timeout")
 
 	// Test a bad command.
-	err = runWithCfg([]string{os.Args[0], "ordering", "setup"}, cfg)
-	require.EqualError(t, err, `Required flag "member" not set`)
+	err = runWithCfg([]string{os.Args[0], "ordering", "budget", "set"}, cfg)
+	require.EqualError(t, err, `Required flag "limit" not set`)
+}
+
+// This test runs the dev command and checks that the accounts are funded and
+// the endpoints are served until the chain is stopped.
+func TestMemcoin_Dev(t *testing.T) {
+	sigs := make(chan os.Signal)
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+
+	go func() {
+		done <- runWithCfg([]string{
+			os.Args[0], "dev", "--port", "2410", "--http", "127.0.0.1:2411",
+			"--accounts", "2", "--balance", "50",
+		}, config{Channel: sigs, Writer: out})
+	}()
+
+	require.Eventually(t, func() bool {
+		return strings.Count(out.String(), "(key: ") == 2
+	}, 60*time.Second, 100*time.Millisecond)
+
+	lines := strings.Split(out.String(), "\n")
+	dir := strings.TrimPrefix(lines[0], "Development chain running with the config ")
+	identity := strings.Fields(lines[3])[0]
+
+	buffer := new(bytes.Buffer)
+	err := runWithCfg([]string{os.Args[0], "--config", dir, "coin", "balance",
+		"--identity", identity}, config{Writer: buffer})
+	require.NoError(t, err)
+	require.Equal(t, "50\n", buffer.String())
+
+	res, err := http.Post("http://127.0.0.1:2411/faucet", "application/json",
+		strings.NewReader(`{"identity":"bls:alice"}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, res.StatusCode)
+	res.Body.Close()
+
+	close(sigs)
+	require.NoError(t, <-done)
+
+	_, err = os.Stat(dir)
+	require.True(t, os.IsNotExist(err))
+
+	err = runWithCfg([]string{os.Args[0], "dev", "--accounts", "-1"}, config{})
+	require.EqualError(t, err, "invalid number of accounts: -1")
+}
+
+// This test runs a scenario where an account claims coins on the first node,
+// and the balance is verified on the second one.
+func TestMemcoin_ScenarioFile(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "memcoin-scenario")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	identity, key, err := createAccount(dir, 0)
+	require.NoError(t, err)
+
+	file := filepath.Join(dir, "scenario.yaml")
+	exportA := filepath.Join(dir, "a.json")
+	exportB := filepath.Join(dir, "b.json")
+
+	err = ioutil.WriteFile(file, []byte(fmt.Sprintf(`
+nodes: 2
+port: 2420
+flags: [--coin-faucet, --coin-faucet-amount, "50"]
+steps:
+  - node: 1
+    run: [pool, add, --key, %s, --nonce, "0",
+      --args, go.dedis.ch/dela.ContractArg, --args, go.dedis.ch/dela.Coin,
+      --args, coin:command, --args, FAUCET]
+  - at: 1
+    node: 2
+    run: [coin, balance, --identity, %s]
+    expect: "50"
+  - node: 1
+    run: [state, export, --path, %s]
+  - node: 2
+    run: [state, export, --path, %s]
+`, key, identity, exportA, exportB)), 0600)
+	require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+
+	err = runWithCfg([]string{os.Args[0], "scenario", "--file", file}, config{Writer: out})
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "scenario passed")
+
+	// Both nodes have the same state after the transaction.
+	a, err := statediff.Load(exportA)
+	require.NoError(t, err)
+	require.NotEmpty(t, a.Entries)
+
+	b, err := statediff.Load(exportB)
+	require.NoError(t, err)
+	require.Empty(t, statediff.Compare(a, b))
+
+	err = runWithCfg([]string{os.Args[0], "scenario", "--file", dir}, config{Writer: out})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to load scenario: ")
 }
 
 // This test creates a chain with two nodes, then gracefully close them. It
@@ -155,7 +261,8 @@ func TestMemcoin_Scenario_RestartNode(t *testing.T) {
 	)
 
 	err = run(args)
-	require.EqualError(t, err, "command error: transaction refused: duplicate in roster: 127.0.0.1:2210")
+	require.EqualError(t, err, "command error: while preparing tx: invalid change: "+
+		"invalid addition: address '127.0.0.1:2210' already in the authority")
 }
 
 // -----------------------------------------------------------------------------
@@ -207,18 +314,11 @@ func waitDaemon(t *testing.T, daemons []string) bool {
 	num := 50
 
 	for _, daemon := range daemons {
-		path := filepath.Join(daemon, "daemon.sock")
-
 		for i := 0; i < num; i++ {
-			// Windows: we have to check the file as Dial on Windows creates the
-			// file and prevent to listen.
-			_, err := os.Stat(path)
-			if !os.IsNotExist(err) {
-				conn, err := net.DialTimeout("unix", path, testDialTimeout)
-				if err == nil {
-					conn.Close()
-					break
-				}
+			conn, err := node.DialDaemon(daemon, testDialTimeout)
+			if err == nil {
+				conn.Close()
+				break
 			}
 
 			time.Sleep(100 * time.Millisecond)
@@ -274,3 +374,23 @@ func getExport(t *testing.T, path string) []string {
 
 	return strings.Split(buffer.String(), " ")
 }
+
+// syncBuffer is a buffer that can be written and read concurrently.
+type syncBuffer struct {
+	sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.buffer.String()
+}
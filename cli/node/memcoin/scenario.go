@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/cli/node/scenario"
+	"golang.org/x/xerrors"
+)
+
+// scenarioController is an initializer with the command to run a scenario
+// against a chain of memcoin nodes.
+//
+// - implements node.Initializer
+type scenarioController struct {
+	cfg config
+}
+
+// SetCommands implements node.Initializer. It sets the scenario command.
+func (c scenarioController) SetCommands(builder node.Builder) {
+	cmd := builder.SetCommand("scenario")
+	cmd.SetDescription("run the scenario of a file against a local chain")
+	cmd.SetFlags(
+		cli.StringFlag{
+			Name:     "file",
+			Usage:    "path to the scenario in YAML",
+			Required: true,
+		},
+		cli.DurationFlag{
+			Name: "timeout",
+			Usage: "maximum amount of time to wait for the nodes to start, or " +
+				"for a step to be reached",
+			Value: scenario.DefaultTimeout,
+		},
+	)
+	cmd.SetAction(c.run)
+}
+
+// OnStart implements node.Initializer. It does nothing.
+func (scenarioController) OnStart(flags cli.Flags, inj node.Injector) error {
+	return nil
+}
+
+// OnStop implements node.Initializer. It does nothing.
+func (scenarioController) OnStop(inj node.Injector) error {
+	return nil
+}
+
+func (c scenarioController) run(flags cli.Flags) error {
+	s, err := scenario.Load(flags.Path("file"))
+	if err != nil {
+		return xerrors.Errorf("failed to load scenario: %v", err)
+	}
+
+	r := scenario.NewRunner(launch,
+		scenario.WithOutput(c.cfg.Writer),
+		scenario.WithTimeout(flags.Duration("timeout")))
+
+	err = r.Run(s)
+	if err != nil {
+		return xerrors.Errorf("scenario failed: %v", err)
+	}
+
+	fmt.Fprintln(c.cfg.Writer, "scenario passed")
+
+	return nil
+}
+
+// launch runs memcoin for a node of a scenario.
+func launch(args []string, stop chan os.Signal, out io.Writer) error {
+	return runWithCfg(args, config{Channel: stop, Writer: out})
+}
@@ -5,6 +5,11 @@
 // function to create actions that will eventually be executed on the running
 // node. See the example.
 //
+// A long operation of an action is run with the operations of the context,
+// which send its progress to the CLI. The operation continues when the command
+// is interrupted, and the "operation attach" command follows it again with its
+// identifier.
+//
 // Document Last Review: 13.10.2020
 //
 package node
@@ -40,9 +45,49 @@ type ActionTemplate interface {
 // Context is the context available to the action when being invoked. It
 // provides the dependency injector alongside with the input and output.
 type Context struct {
-	Injector Injector
-	Flags    cli.Flags
-	Out      io.Writer
+	Injector   Injector
+	Flags      cli.Flags
+	Out        io.Writer
+	Operations Operations
+}
+
+// Reporter is the interface to report the progress of a long operation.
+type Reporter interface {
+	// Report sets the number of steps done out of the total. A total of zero
+	// means that it is unknown.
+	Report(done, total uint64)
+}
+
+// OperationFunc is the function of a long operation. It returns the result
+// that is written to the commands that follow the operation.
+type OperationFunc func(Reporter) (string, error)
+
+// OperationStatus is the status of a long operation.
+type OperationStatus struct {
+	ID       string
+	Name     string
+	Done     uint64
+	Total    uint64
+	Finished bool
+	Error    string `json:",omitempty"`
+}
+
+// Operations is the interface to run long operations on the daemon. An
+// operation continues when the command that started it is interrupted, and it
+// can be re-attached with its identifier.
+type Operations interface {
+	// Run starts the function in the background and follows its progress on
+	// the output of the context until it is finished. It returns the error of
+	// the function, if any.
+	Run(ctx Context, name string, fn OperationFunc) error
+
+	// Attach follows the progress of the operation with the identifier until
+	// it is finished, and returns its error, if any.
+	Attach(ctx Context, id string) error
+
+	// List returns the status of the running and the recently finished
+	// operations.
+	List() []OperationStatus
 }
 
 // Injector is a dependency injection abstraction.
@@ -0,0 +1,315 @@
+// This file contains the implementation of the long operations of the daemon.
+//
+// An operation runs in the background of the daemon and reports its progress
+// to the commands that follow it. The identifier of an operation is written
+// when it starts, so that a command interrupted on the CLI can be re-attached
+// to the operation that continues on the daemon.
+
+package node
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// operationHistory is the number of finished operations that are kept so that
+// a command can still be re-attached to read their result.
+const operationHistory = 32
+
+// progress is the state of an operation sent to the client.
+type progress struct {
+	Operation string
+	Name      string
+	Done      uint64
+	Total     uint64
+}
+
+// progressWriter is implemented by the outputs that can send the progress of
+// an operation to the client.
+type progressWriter interface {
+	writeProgress(progress) error
+}
+
+// operation is a function running in the background of the daemon.
+//
+// - implements node.Reporter
+type operation struct {
+	sync.Mutex
+
+	id       string
+	name     string
+	index    uint64
+	done     uint64
+	total    uint64
+	finished bool
+	result   string
+	err      error
+	changed  chan struct{}
+}
+
+// Report implements node.Reporter. It updates the progress of the operation
+// and wakes up the commands that follow it.
+func (op *operation) Report(done, total uint64) {
+	op.Lock()
+	op.done = done
+	op.total = total
+	op.notify()
+	op.Unlock()
+}
+
+func (op *operation) finish(result string, err error) {
+	op.Lock()
+	op.finished = true
+	op.result = result
+	op.err = err
+	op.notify()
+	op.Unlock()
+}
+
+// notify must be called with the lock held.
+func (op *operation) notify() {
+	close(op.changed)
+	op.changed = make(chan struct{})
+}
+
+func (op *operation) getStatus() OperationStatus {
+	op.Lock()
+	defer op.Unlock()
+
+	status := OperationStatus{
+		ID:       op.id,
+		Name:     op.name,
+		Done:     op.done,
+		Total:    op.total,
+		Finished: op.finished,
+	}
+
+	if op.err != nil {
+		status.Error = op.err.Error()
+	}
+
+	return status
+}
+
+// follow writes the progress of the operation to the output until it is
+// finished, and then writes its result or returns its error. It returns early
+// if the output fails, which happens when the client disconnects.
+func (op *operation) follow(out io.Writer) error {
+	// The identifier and the name never change, therefore the progress is
+	// written only when something was done.
+	last := progress{Operation: op.id, Name: op.name}
+
+	for {
+		op.Lock()
+		current := progress{
+			Operation: op.id,
+			Name:      op.name,
+			Done:      op.done,
+			Total:     op.total,
+		}
+		finished := op.finished
+		result := op.result
+		err := op.err
+		changed := op.changed
+		op.Unlock()
+
+		// The updates in between are skipped when the client is slower than
+		// the operation, as only the latest progress matters.
+		if current != last {
+			werr := writeProgress(out, current)
+			if werr != nil {
+				return xerrors.Errorf("failed to write progress: %v", werr)
+			}
+
+			last = current
+		}
+
+		if finished {
+			if err != nil {
+				return err
+			}
+
+			if result != "" {
+				fmt.Fprint(out, result)
+			}
+
+			return nil
+		}
+
+		<-changed
+	}
+}
+
+func writeProgress(out io.Writer, p progress) error {
+	pw, ok := out.(progressWriter)
+	if ok {
+		return pw.writeProgress(p)
+	}
+
+	_, err := fmt.Fprintf(out, "%s: %d/%d\n", p.Name, p.Done, p.Total)
+
+	return err
+}
+
+// operationStore is the store of the operations of the daemon.
+//
+// - implements node.Operations
+type operationStore struct {
+	sync.Mutex
+
+	ops     map[string]*operation
+	counter uint64
+}
+
+// NewOperations returns an empty store of operations.
+func NewOperations() Operations {
+	return &operationStore{
+		ops: make(map[string]*operation),
+	}
+}
+
+// Run implements node.Operations. It starts the function in the background
+// and follows it on the output of the context. The identifier of the operation
+// is written first, so that the command can be re-attached if it is
+// interrupted.
+func (s *operationStore) Run(ctx Context, name string, fn OperationFunc) error {
+	op, err := s.start(name)
+	if err != nil {
+		return xerrors.Errorf("failed to start: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "operation %s started", op.id)
+
+	go func() {
+		result, err := fn(op)
+		op.finish(result, err)
+	}()
+
+	return op.follow(ctx.Out)
+}
+
+// Attach implements node.Operations. It follows the operation with the
+// identifier on the output of the context.
+func (s *operationStore) Attach(ctx Context, id string) error {
+	s.Lock()
+	op := s.ops[id]
+	s.Unlock()
+
+	if op == nil {
+		return xerrors.Errorf("unknown operation '%s'", id)
+	}
+
+	return op.follow(ctx.Out)
+}
+
+// List implements node.Operations. It returns the status of the operations in
+// the order they started.
+func (s *operationStore) List() []OperationStatus {
+	s.Lock()
+	ops := s.sorted()
+	s.Unlock()
+
+	statuses := make([]OperationStatus, len(ops))
+	for i, op := range ops {
+		statuses[i] = op.getStatus()
+	}
+
+	return statuses
+}
+
+func (s *operationStore) start(name string) (*operation, error) {
+	buffer := make([]byte, 8)
+
+	_, err := rand.Read(buffer)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to generate identifier: %v", err)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.counter++
+
+	op := &operation{
+		id:      hex.EncodeToString(buffer),
+		name:    name,
+		index:   s.counter,
+		changed: make(chan struct{}),
+	}
+
+	s.ops[op.id] = op
+
+	s.evict()
+
+	return op, nil
+}
+
+// evict discards the oldest finished operations above the history. It must be
+// called with the lock held.
+func (s *operationStore) evict() {
+	finished := []*operation{}
+
+	for _, op := range s.sorted() {
+		op.Lock()
+		if op.finished {
+			finished = append(finished, op)
+		}
+		op.Unlock()
+	}
+
+	for i := 0; i < len(finished)-operationHistory; i++ {
+		delete(s.ops, finished[i].id)
+	}
+}
+
+// sorted returns the operations in the order they started. It must be called
+// with the lock held.
+func (s *operationStore) sorted() []*operation {
+	ops := make([]*operation, 0, len(s.ops))
+	for _, op := range s.ops {
+		ops = append(ops, op)
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].index < ops[j].index
+	})
+
+	return ops
+}
+
+// operationListAction is an action to print the operations of the daemon.
+//
+// - implements node.ActionTemplate
+type operationListAction struct{}
+
+// Execute implements node.ActionTemplate. It prints the status of the
+// operations in JSON.
+func (operationListAction) Execute(ctx Context) error {
+	data, err := json.MarshalIndent(ctx.Operations.List(), "", "  ")
+	if err != nil {
+		return xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	fmt.Fprint(ctx.Out, string(data))
+
+	return nil
+}
+
+// operationAttachAction is an action to follow an operation that was started
+// by an interrupted command.
+//
+// - implements node.ActionTemplate
+type operationAttachAction struct{}
+
+// Execute implements node.ActionTemplate. It follows the operation until it is
+// finished.
+func (operationAttachAction) Execute(ctx Context) error {
+	return ctx.Operations.Attach(ctx, ctx.Flags.String("id"))
+}
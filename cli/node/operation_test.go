@@ -0,0 +1,140 @@
+package node
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestOperations_Run(t *testing.T) {
+	ops := NewOperations()
+
+	buffer := new(bytes.Buffer)
+	ctx := Context{Out: buffer}
+
+	err := ops.Run(ctx, "test", func(r Reporter) (string, error) {
+		r.Report(1, 2)
+		r.Report(2, 2)
+
+		return "done", nil
+	})
+	require.NoError(t, err)
+
+	statuses := ops.List()
+	require.Len(t, statuses, 1)
+	require.Equal(t, "test", statuses[0].Name)
+	require.Equal(t, uint64(2), statuses[0].Done)
+	require.Equal(t, uint64(2), statuses[0].Total)
+	require.True(t, statuses[0].Finished)
+
+	// The updates can be coalesced when the operation is faster than the
+	// output, but the last one is always written.
+	require.Regexp(t, "^operation "+statuses[0].ID+" started(test: 1/2\n)?test: 2/2\ndone$",
+		buffer.String())
+
+	err = ops.Run(ctx, "test", func(r Reporter) (string, error) {
+		return "", fake.GetError()
+	})
+	require.EqualError(t, err, fake.GetError().Error())
+
+	statuses = ops.List()
+	require.Len(t, statuses, 2)
+	require.Equal(t, fake.GetError().Error(), statuses[1].Error)
+
+	ctx.Out = fake.NewBadHash()
+	err = ops.Run(ctx, "test", func(r Reporter) (string, error) {
+		r.Report(1, 1)
+		return "", nil
+	})
+	require.EqualError(t, err, fake.Err("failed to write progress"))
+}
+
+func TestOperations_Attach(t *testing.T) {
+	ops := NewOperations()
+
+	wait := make(chan struct{})
+	started := make(chan struct{})
+
+	go func() {
+		ops.Run(Context{Out: new(bytes.Buffer)}, "test", func(r Reporter) (string, error) {
+			r.Report(1, 0)
+			close(started)
+			<-wait
+
+			return "done", nil
+		})
+	}()
+
+	<-started
+
+	statuses := ops.List()
+	require.Len(t, statuses, 1)
+	require.False(t, statuses[0].Finished)
+
+	close(wait)
+
+	buffer := new(bytes.Buffer)
+	err := ops.Attach(Context{Out: buffer}, statuses[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, "test: 1/0\ndone", buffer.String())
+
+	err = ops.Attach(Context{Out: buffer}, "deadbeef")
+	require.EqualError(t, err, "unknown operation 'deadbeef'")
+}
+
+func TestOperations_Evict(t *testing.T) {
+	ops := NewOperations()
+	ctx := Context{Out: new(bytes.Buffer)}
+
+	for i := 0; i < operationHistory+5; i++ {
+		err := ops.Run(ctx, fmt.Sprintf("op-%d", i), func(Reporter) (string, error) {
+			return "", nil
+		})
+		require.NoError(t, err)
+	}
+
+	// The last operation is not finished yet when the store evicts the others.
+	statuses := ops.List()
+	require.Len(t, statuses, operationHistory+1)
+	require.Equal(t, "op-4", statuses[0].Name)
+	require.Equal(t, fmt.Sprintf("op-%d", operationHistory+4), statuses[operationHistory].Name)
+}
+
+func TestOperationListAction_Execute(t *testing.T) {
+	ops := NewOperations()
+
+	buffer := new(bytes.Buffer)
+	ctx := Context{Out: buffer, Operations: ops}
+
+	err := operationListAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "[]", buffer.String())
+
+	err = ops.Run(ctx, "test", func(Reporter) (string, error) {
+		return "", fake.GetError()
+	})
+	require.Error(t, err)
+
+	buffer.Reset()
+	err = operationListAction{}.Execute(ctx)
+	require.NoError(t, err)
+
+	var statuses []OperationStatus
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &statuses))
+	require.Equal(t, ops.List(), statuses)
+}
+
+func TestOperationAttachAction_Execute(t *testing.T) {
+	ctx := Context{
+		Flags:      FlagSet{"id": "deadbeef"},
+		Out:        new(bytes.Buffer),
+		Operations: NewOperations(),
+	}
+
+	err := operationAttachAction{}.Execute(ctx)
+	require.EqualError(t, err, "unknown operation 'deadbeef'")
+}
@@ -0,0 +1,449 @@
+// Package scenario implements a runner of integration scenarios against a
+// chain of nodes started in the same process.
+//
+// A scenario starts a number of nodes, sets up a chain with all of them, and
+// runs steps in order. A step waits for the chain to reach a height and then
+// either runs a command on a node, optionally comparing its output, or stops
+// the node. It can be written in YAML:
+//
+//  nodes: 3
+//  flags: [--coin-faucet]
+//  steps:
+//    - node: 1
+//      run: [pool, add, --key, alice.key, --args, ...]
+//    - at: 10
+//      node: 2
+//      kill: true
+//    - at: 20
+//      node: 1
+//      run: [coin, balance, --identity, bls:...]
+//      expect: "1000"
+//
+// or with the equivalent functions in Go:
+//
+//  s := scenario.New(3).WithFlags("--coin-faucet").
+//      Run(1, "pool", "add", "--key", "alice.key", "--args", ...).
+//      At(10).Kill(2).
+//      At(20).Expect(1, "1000", "coin", "balance", "--identity", "bls:...")
+//
+// The nodes are run by a launcher, which is usually the CLI of the
+// application, e.g. memcoin, so that the scenario uses the same components and
+// flags as a deployment.
+package scenario
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	clinode "go.dedis.ch/dela/cli/node"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// DefaultPort is the port of the first node when the scenario doesn't
+	// define one. The other nodes use the next ports.
+	DefaultPort = 2000
+
+	// DefaultTimeout is the maximum amount of time to wait for the nodes to
+	// start, or for the chain to reach the height of a step.
+	DefaultTimeout = 60 * time.Second
+
+	waitStep = 100 * time.Millisecond
+)
+
+// Scenario is the definition of the nodes and the steps of a scenario.
+type Scenario struct {
+	// Nodes is the number of nodes, and members of the chain.
+	Nodes int `yaml:"nodes"`
+
+	// Port is the port of the first node.
+	Port int `yaml:"port"`
+
+	// Flags are the additional flags to start the nodes.
+	Flags []string `yaml:"flags"`
+
+	// Steps are run in order.
+	Steps []Step `yaml:"steps"`
+
+	at uint64
+}
+
+// Step is an action on a node of the scenario.
+type Step struct {
+	// At is the number of blocks the chain must have on the node before the
+	// step runs.
+	At uint64 `yaml:"at"`
+
+	// Node is the index of the node, starting at one.
+	Node int `yaml:"node"`
+
+	// Run is the command to run on the node.
+	Run []string `yaml:"run"`
+
+	// Expect is the expected output of the command when it is set.
+	Expect *string `yaml:"expect"`
+
+	// Kill stops the node.
+	Kill bool `yaml:"kill"`
+}
+
+// New creates an empty scenario with the given number of nodes.
+func New(nodes int) *Scenario {
+	return &Scenario{
+		Nodes: nodes,
+		Port:  DefaultPort,
+	}
+}
+
+// Load reads the scenario in YAML from the file.
+func Load(path string) (*Scenario, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read file: %v", err)
+	}
+
+	s := &Scenario{}
+
+	err = yaml.UnmarshalStrict(data, s)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decode: %v", err)
+	}
+
+	return s, nil
+}
+
+// WithPort sets the port of the first node.
+func (s *Scenario) WithPort(port int) *Scenario {
+	s.Port = port
+	return s
+}
+
+// WithFlags adds flags to start the nodes.
+func (s *Scenario) WithFlags(flags ...string) *Scenario {
+	s.Flags = append(s.Flags, flags...)
+	return s
+}
+
+// At sets the height at which the next steps run.
+func (s *Scenario) At(height uint64) *Scenario {
+	s.at = height
+	return s
+}
+
+// Run adds a step that runs the command on the node.
+func (s *Scenario) Run(node int, args ...string) *Scenario {
+	s.Steps = append(s.Steps, Step{At: s.at, Node: node, Run: args})
+	return s
+}
+
+// Expect adds a step that runs the command on the node and compares its
+// output, without the surrounding spaces, to the expected one.
+func (s *Scenario) Expect(node int, output string, args ...string) *Scenario {
+	s.Steps = append(s.Steps, Step{At: s.at, Node: node, Run: args, Expect: &output})
+	return s
+}
+
+// Kill adds a step that stops the node.
+func (s *Scenario) Kill(node int) *Scenario {
+	s.Steps = append(s.Steps, Step{At: s.at, Node: node, Kill: true})
+	return s
+}
+
+// Validate returns an error if the scenario cannot be run.
+func (s *Scenario) Validate() error {
+	if s.Nodes < 1 {
+		return xerrors.Errorf("invalid number of nodes: %d", s.Nodes)
+	}
+
+	for i, step := range s.Steps {
+		if step.Node < 1 || step.Node > s.Nodes {
+			return xerrors.Errorf("step %d: unknown node %d", i+1, step.Node)
+		}
+
+		if step.Kill == (len(step.Run) > 0) {
+			return xerrors.Errorf("step %d: either a command or a kill is expected", i+1)
+		}
+
+		if step.Kill && step.Expect != nil {
+			return xerrors.Errorf("step %d: nothing to expect from a kill", i+1)
+		}
+	}
+
+	return nil
+}
+
+// Launcher is the function that runs the command line of a node. The node
+// started by the command stops when the channel is closed.
+type Launcher func(args []string, stop chan os.Signal, out io.Writer) error
+
+// Runner is the runner of the scenarios.
+type Runner struct {
+	launch  Launcher
+	out     io.Writer
+	timeout time.Duration
+}
+
+// RunnerOption is the type of option to create a runner.
+type RunnerOption func(*Runner)
+
+// WithOutput is an option to set the writer of the progress of the scenario.
+func WithOutput(out io.Writer) RunnerOption {
+	return func(r *Runner) {
+		r.out = out
+	}
+}
+
+// WithTimeout is an option to set the maximum amount of time to wait for the
+// nodes to start, or for the chain to reach the height of a step.
+func WithTimeout(timeout time.Duration) RunnerOption {
+	return func(r *Runner) {
+		r.timeout = timeout
+	}
+}
+
+// NewRunner creates a runner that starts the nodes with the launcher.
+func NewRunner(launch Launcher, opts ...RunnerOption) Runner {
+	r := Runner{
+		launch:  launch,
+		out:     ioutil.Discard,
+		timeout: DefaultTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(&r)
+	}
+
+	return r
+}
+
+// node is a node started by the runner.
+type node struct {
+	index int
+	dir   string
+	port  int
+	stop  chan os.Signal
+	done  chan error
+}
+
+// Run runs the scenario. The nodes are stored in a temporary folder that is
+// removed when the scenario ends, and they are stopped even if a step fails.
+func (r Runner) Run(s *Scenario) error {
+	err := s.Validate()
+	if err != nil {
+		return xerrors.Errorf("invalid scenario: %v", err)
+	}
+
+	dir, err := ioutil.TempDir(os.TempDir(), "dela-scenario")
+	if err != nil {
+		return xerrors.Errorf("failed to create folder: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	port := s.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+
+	nodes := make([]*node, s.Nodes)
+	for i := range nodes {
+		nodes[i] = r.start(dir, i+1, port+i, s.Flags)
+	}
+
+	defer func() {
+		for _, n := range nodes {
+			r.kill(n)
+		}
+	}()
+
+	err = r.setup(nodes)
+	if err != nil {
+		return xerrors.Errorf("failed to setup: %v", err)
+	}
+
+	for i, step := range s.Steps {
+		err = r.runStep(nodes[step.Node-1], step)
+		if err != nil {
+			return xerrors.Errorf("step %d: %v", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (r Runner) start(dir string, index, port int, flags []string) *node {
+	n := &node{
+		index: index,
+		dir:   filepath.Join(dir, fmt.Sprintf("node%d", index)),
+		port:  port,
+		stop:  make(chan os.Signal),
+		done:  make(chan error, 1),
+	}
+
+	args := append([]string{os.Args[0], "--config", n.dir, "start",
+		"--port", strconv.Itoa(port)}, flags...)
+
+	go func() {
+		n.done <- r.launch(args, n.stop, ioutil.Discard)
+	}()
+
+	return n
+}
+
+// kill stops the node if it is running and waits for it to be done.
+func (r Runner) kill(n *node) {
+	if n.stop == nil {
+		return
+	}
+
+	close(n.stop)
+	n.stop = nil
+
+	<-n.done
+}
+
+// setup waits for the nodes to start, shares the certificate of the first node
+// with the others, and creates a chain with all of them.
+func (r Runner) setup(nodes []*node) error {
+	for _, n := range nodes {
+		err := r.waitDaemon(n)
+		if err != nil {
+			return xerrors.Errorf("node %d: %v", n.index, err)
+		}
+	}
+
+	first := nodes[0]
+	addr := fmt.Sprintf("127.0.0.1:%d", first.port)
+
+	members := []string{}
+
+	for _, n := range nodes {
+		if n != first {
+			token, err := r.call(first, "minogrpc", "token")
+			if err != nil {
+				return err
+			}
+
+			args := append([]string{"minogrpc", "join", "--address", addr},
+				strings.Fields(token)...)
+
+			_, err = r.call(n, args...)
+			if err != nil {
+				return err
+			}
+		}
+
+		export, err := r.call(n, "ordering", "export")
+		if err != nil {
+			return err
+		}
+
+		members = append(members, "--member", export)
+	}
+
+	_, err := r.call(first, append([]string{"ordering", "setup"}, members...)...)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(r.out, "chain created with %d nodes\n", len(nodes))
+
+	return nil
+}
+
+func (r Runner) runStep(n *node, step Step) error {
+	if n.stop == nil {
+		return xerrors.Errorf("node %d is stopped", n.index)
+	}
+
+	err := r.waitHeight(n, step.At)
+	if err != nil {
+		return xerrors.Errorf("node %d: %v", n.index, err)
+	}
+
+	if step.Kill {
+		r.kill(n)
+
+		fmt.Fprintf(r.out, "node %d stopped at block %d\n", n.index, step.At)
+
+		return nil
+	}
+
+	out, err := r.call(n, step.Run...)
+	if err != nil {
+		return err
+	}
+
+	if step.Expect != nil && out != strings.TrimSpace(*step.Expect) {
+		return xerrors.Errorf("expected '%s' but got '%s'", *step.Expect, out)
+	}
+
+	fmt.Fprintf(r.out, "node %d ran '%s' at block %d\n",
+		n.index, strings.Join(step.Run, " "), step.At)
+
+	return nil
+}
+
+// call runs the command against the node and returns its output without the
+// surrounding spaces.
+func (r Runner) call(n *node, args ...string) (string, error) {
+	out := new(bytes.Buffer)
+
+	err := r.launch(append([]string{os.Args[0], "--config", n.dir}, args...), nil, out)
+	if err != nil {
+		return "", xerrors.Errorf("command '%s' failed on node %d: %v",
+			strings.Join(args, " "), n.index, err)
+	}
+
+	return strings.TrimSpace(out.String()), nil
+}
+
+// waitDaemon waits for the daemon of the node to be reachable.
+func (r Runner) waitDaemon(n *node) error {
+	for start := time.Now(); time.Since(start) < r.timeout; time.Sleep(waitStep) {
+		select {
+		case err := <-n.done:
+			n.done <- err
+			return xerrors.Errorf("stopped: %v", err)
+		default:
+		}
+
+		conn, err := clinode.DialDaemon(n.dir, waitStep)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+	}
+
+	return xerrors.New("not started after timeout")
+}
+
+// waitHeight waits for the chain of the node to have at least the given number
+// of blocks.
+func (r Runner) waitHeight(n *node, height uint64) error {
+	for start := time.Now(); time.Since(start) < r.timeout; time.Sleep(waitStep) {
+		out, err := r.call(n, "ordering", "height")
+		if err != nil {
+			return err
+		}
+
+		current, err := strconv.ParseUint(out, 10, 64)
+		if err != nil {
+			return xerrors.Errorf("invalid height '%s': %v", out, err)
+		}
+
+		if current >= height {
+			return nil
+		}
+	}
+
+	return xerrors.Errorf("block %d not reached after timeout", height)
+}
@@ -0,0 +1,283 @@
+package scenario
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	clinode "go.dedis.ch/dela/cli/node"
+	"golang.org/x/xerrors"
+)
+
+func TestScenario_Build(t *testing.T) {
+	s := New(3).WithPort(3000).WithFlags("--coin-faucet").
+		Run(1, "pool", "add").
+		At(10).Kill(2).
+		At(20).Expect(1, "1000", "coin", "balance")
+
+	output := "1000"
+
+	require.Equal(t, &Scenario{
+		Nodes: 3,
+		Port:  3000,
+		Flags: []string{"--coin-faucet"},
+		Steps: []Step{
+			{Node: 1, Run: []string{"pool", "add"}},
+			{At: 10, Node: 2, Kill: true},
+			{At: 20, Node: 1, Run: []string{"coin", "balance"}, Expect: &output},
+		},
+		at: 20,
+	}, s)
+}
+
+func TestScenario_Load(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela-scenario")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "scenario.yaml")
+
+	err = ioutil.WriteFile(path, []byte(`
+nodes: 3
+flags: [--coin-faucet]
+steps:
+  - node: 1
+    run: [pool, add]
+  - at: 10
+    node: 2
+    kill: true
+  - at: 20
+    node: 1
+    run: [coin, balance]
+    expect: "1000"
+`), 0600)
+	require.NoError(t, err)
+
+	s, err := Load(path)
+	require.NoError(t, err)
+
+	expected := New(3).WithPort(0).WithFlags("--coin-faucet").
+		Run(1, "pool", "add").
+		At(10).Kill(2).
+		At(20).Expect(1, "1000", "coin", "balance")
+	expected.at = 0
+
+	require.Equal(t, expected, s)
+
+	err = ioutil.WriteFile(path, []byte("nodes: 3\nunknown: 2"), 0600)
+	require.NoError(t, err)
+
+	_, err = Load(path)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to decode: ")
+
+	_, err = Load(filepath.Join(dir, "unknown.yaml"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read file: ")
+}
+
+func TestScenario_Validate(t *testing.T) {
+	require.NoError(t, New(2).Run(1, "pool", "add").Kill(2).Validate())
+
+	err := New(0).Validate()
+	require.EqualError(t, err, "invalid number of nodes: 0")
+
+	err = New(2).Kill(3).Validate()
+	require.EqualError(t, err, "step 1: unknown node 3")
+
+	err = New(2).Run(1).Validate()
+	require.EqualError(t, err, "step 1: either a command or a kill is expected")
+
+	s := New(2)
+	s.Steps = []Step{{Node: 1, Kill: true, Run: []string{"pool"}}}
+
+	err = s.Validate()
+	require.EqualError(t, err, "step 1: either a command or a kill is expected")
+
+	output := ""
+	s.Steps = []Step{{Node: 1, Kill: true, Expect: &output}}
+
+	err = s.Validate()
+	require.EqualError(t, err, "step 1: nothing to expect from a kill")
+}
+
+func TestRunner_Run(t *testing.T) {
+	app := newFakeApp()
+	out := new(bytes.Buffer)
+
+	r := NewRunner(app.launch, WithOutput(out))
+
+	s := New(3).
+		Run(1, "pool", "add").
+		At(2).Kill(2).
+		At(4).Expect(3, "node3", "whoami")
+
+	err := r.Run(s)
+	require.NoError(t, err)
+	require.Equal(t, "chain created with 3 nodes\n"+
+		"node 1 ran 'pool add' at block 0\n"+
+		"node 2 stopped at block 2\n"+
+		"node 3 ran 'whoami' at block 4\n", out.String())
+
+	require.Equal(t, 2, app.count("minogrpc join --address 127.0.0.1:2000 --token abc"))
+	require.Equal(t, 1, app.count("ordering setup --member node1 --member node2 --member node3"))
+	require.Equal(t, 0, app.running())
+
+	err = r.Run(New(2).Expect(2, "node1", "whoami"))
+	require.EqualError(t, err, "step 1: expected 'node1' but got 'node2'")
+	require.Equal(t, 0, app.running())
+
+	err = r.Run(New(2).Kill(2).Run(2, "whoami"))
+	require.EqualError(t, err, "step 2: node 2 is stopped")
+
+	err = r.Run(New(2).Run(1, "fail"))
+	require.EqualError(t, err, "step 1: command 'fail' failed on node 1: oops")
+
+	err = r.Run(New(0))
+	require.EqualError(t, err, "invalid scenario: invalid number of nodes: 0")
+}
+
+func TestRunner_Wait(t *testing.T) {
+	app := newFakeApp()
+
+	r := NewRunner(app.launch, WithTimeout(500*time.Millisecond))
+
+	err := r.Run(New(1).At(100).Kill(1))
+	require.EqualError(t, err, "step 1: node 1: block 100 not reached after timeout")
+
+	app.height = "abc"
+
+	err = r.Run(New(1).At(1).Kill(1))
+	require.EqualError(t, err,
+		`step 1: node 1: invalid height 'abc': strconv.ParseUint: parsing "abc": invalid syntax`)
+
+	app.errStart = xerrors.New("oops")
+
+	err = r.Run(New(1))
+	require.EqualError(t, err, "failed to setup: node 1: stopped: oops")
+
+	app.errStart = nil
+	app.noSocket = true
+
+	err = r.Run(New(1))
+	require.EqualError(t, err, "failed to setup: node 1: not started after timeout")
+	require.Equal(t, 0, app.running())
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// fakeApp is an application where the nodes listen on the socket of the daemon
+// and the chain grows by one block each time the height is read.
+type fakeApp struct {
+	sync.Mutex
+
+	calls    []string
+	heights  map[string]int
+	nodes    int
+	height   string
+	errStart error
+	noSocket bool
+}
+
+func newFakeApp() *fakeApp {
+	return &fakeApp{
+		heights: make(map[string]int),
+	}
+}
+
+func (a *fakeApp) launch(args []string, stop chan os.Signal, out io.Writer) error {
+	dir := args[2]
+	cmd := strings.Join(args[3:], " ")
+
+	if args[3] == "start" {
+		return a.start(dir, stop)
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	a.calls = append(a.calls, cmd)
+
+	switch cmd {
+	case "minogrpc token":
+		fmt.Fprintln(out, "--token abc")
+	case "ordering export", "whoami":
+		fmt.Fprint(out, filepath.Base(dir))
+	case "ordering height":
+		if a.height != "" {
+			fmt.Fprint(out, a.height)
+		} else {
+			fmt.Fprint(out, a.heights[dir])
+			a.heights[dir]++
+		}
+	case "fail":
+		return xerrors.New("oops")
+	}
+
+	return nil
+}
+
+func (a *fakeApp) start(dir string, stop chan os.Signal) error {
+	if a.errStart != nil {
+		return a.errStart
+	}
+
+	a.Lock()
+	a.nodes++
+	a.Unlock()
+
+	defer func() {
+		a.Lock()
+		a.nodes--
+		a.Unlock()
+	}()
+
+	if !a.noSocket {
+		err := os.MkdirAll(dir, 0700)
+		if err != nil {
+			return err
+		}
+
+		socket, err := clinode.ListenDaemon(dir)
+		if err != nil {
+			return err
+		}
+
+		defer socket.Close()
+	}
+
+	<-stop
+
+	return nil
+}
+
+func (a *fakeApp) count(call string) int {
+	a.Lock()
+	defer a.Unlock()
+
+	num := 0
+	for _, c := range a.calls {
+		if c == call {
+			num++
+		}
+	}
+
+	return num
+}
+
+func (a *fakeApp) running() int {
+	a.Lock()
+	defer a.Unlock()
+
+	return a.nodes
+}
@@ -13,7 +13,9 @@ import (
 	accessContract "go.dedis.ch/dela/contracts/access"
 	"go.dedis.ch/dela/core/access"
 	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/dilithium"
 	"golang.org/x/xerrors"
 )
 
@@ -68,7 +70,7 @@ func parseIdentities(idsStr []string) ([]access.Identity, error) {
 			return nil, xerrors.Errorf("failed to decode pub key '%s': %v", id, err)
 		}
 
-		pk, err := bls.NewPublicKey(idBuf)
+		pk, err := parsePublicKey(idBuf)
 		if err != nil {
 			return nil, xerrors.Errorf("failed to unmarshal identity '%s': %v", id, err)
 		}
@@ -78,3 +80,14 @@ func parseIdentities(idsStr []string) ([]access.Identity, error) {
 
 	return identities, nil
 }
+
+// parsePublicKey returns the public key of the identity. The algorithm is
+// deduced from the size of the key as ML-DSA keys are much larger than BLS
+// ones.
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	if len(data) == dilithium.PublicKeySize {
+		return dilithium.NewPublicKey(data)
+	}
+
+	return bls.NewPublicKey(data)
+}
@@ -10,6 +10,7 @@ import (
 	"go.dedis.ch/dela/cli/node"
 	"go.dedis.ch/dela/core/execution/native"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/dilithium"
 	"go.dedis.ch/dela/internal/testing/fake"
 )
 
@@ -71,6 +72,25 @@ func TestAddAction_Execute(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestParseIdentities(t *testing.T) {
+	blsSigner := bls.NewSigner()
+	blsBuf, err := blsSigner.GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	pqSigner := dilithium.NewSigner()
+	pqBuf, err := pqSigner.GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	ids, err := parseIdentities([]string{
+		base64.StdEncoding.EncodeToString(blsBuf),
+		base64.StdEncoding.EncodeToString(pqBuf),
+	})
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+	require.True(t, blsSigner.GetPublicKey().Equal(ids[0]))
+	require.True(t, pqSigner.GetPublicKey().Equal(ids[1]))
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
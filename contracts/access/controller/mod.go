@@ -47,7 +47,7 @@ func (miniController) SetCommands(builder node.Builder) {
 		Usage:    "identity to add, in the form of bls public keys",
 		Required: true,
 	})
-	sub.SetAction(builder.MakeAction(addAction{}))
+	sub.SetAction(builder.MakeAction(node.Destructive(addAction{})))
 }
 
 // OnStart implements node.Initializer. It registers the access contract.
@@ -172,6 +172,9 @@ func (c Contract) grant(snap store.Snapshot, step execution.Step) error {
 		return xerrors.Errorf("failed to grant: %v", err)
 	}
 
+	step.Emit(ContractName, "grant", "id", string(idHex), "contract", string(contractName),
+		"command", string(commandName), "identities", string(step.Current.GetArg(IdentityArg)))
+
 	dela.Logger.Info().Str("contract", "access").Msgf("granted %x-%s-%s to %s",
 		id, contractName, commandName, identities)
 
@@ -78,11 +78,17 @@ func TestGrant(t *testing.T) {
 	buf, err := signer.GetPublicKey().MarshalBinary()
 	require.NoError(t, err)
 	id := base64.StdEncoding.EncodeToString(buf)
-	err = contract.grant(fakeStore{}, makeStep(t, GrantIDArg, "deadbeef",
+	step := makeStep(t, GrantIDArg, "deadbeef",
 		GrantContractArg, "fake contract",
 		GrantCommandArg, "fake command",
-		IdentityArg, id))
+		IdentityArg, id)
+	step.Log = execution.NewLog()
+
+	err = contract.grant(fakeStore{}, step)
 	require.NoError(t, err)
+	require.Equal(t, []execution.Event{execution.NewEvent(ContractName, "grant",
+		"id", "deadbeef", "contract", "fake contract", "command", "fake command",
+		"identities", id)}, step.Log.GetEvents())
 
 	contract = NewContract([]byte{}, fakeAccess{err: fake.GetError()}, fakeStore{})
 	err = contract.grant(fakeStore{}, makeStep(t, GrantIDArg, "deadbeef",
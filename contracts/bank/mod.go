@@ -0,0 +1,325 @@
+// Package bank implements a native contract that holds the balances that pay
+// for the gas of the transactions.
+//
+// The tokens of the coin contract are deposited to the bank by their owner, and
+// can be withdrawn back. The fee of a transaction is its gas multiplied by the
+// price stored in the state, which is zero until it is set by an authorized
+// identity, so that the identities can fund their balance before the fees are
+// charged.
+package bank
+
+import (
+	"go.dedis.ch/dela/contracts/coin"
+	"go.dedis.ch/dela/contracts/sdk"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// ContractName is the name of the contract.
+	ContractName = "go.dedis.ch/dela.Bank"
+
+	// CmdArg is the argument's name to indicate the kind of command we want to
+	// run on the contract.
+	CmdArg = "bank:command"
+
+	// AmountArg is the argument's name in the transaction that contains the
+	// number of tokens to deposit or to withdraw.
+	AmountArg = "bank:amount"
+
+	// PriceArg is the argument's name in the transaction that contains the
+	// price of a unit of gas.
+	PriceArg = "bank:price"
+
+	// CmdDeposit defines the command to move coins of the author to its
+	// balance in the bank.
+	CmdDeposit = "DEPOSIT"
+
+	// CmdWithdraw defines the command to move tokens of the balance of the
+	// author in the bank back to its coins.
+	CmdWithdraw = "WITHDRAW"
+
+	// CmdPrice defines the command to set the price of a unit of gas.
+	CmdPrice = "PRICE"
+
+	balancePrefix = "bank:balance"
+	pricePrefix   = "bank:price"
+	feesPrefix    = "bank:fees"
+)
+
+// NewContract creates a new bank contract. The price can only be set by the
+// identities allowed by the access service for the key.
+func NewContract(accessKey []byte, srvc access.Service) *sdk.Contract {
+	c := sdk.NewContract(ContractName, CmdArg)
+
+	c.Handle(CmdDeposit, deposit, native.Arg{Name: AmountArg, Required: true})
+	c.Handle(CmdWithdraw, withdraw, native.Arg{Name: AmountArg, Required: true})
+
+	c.Handle(CmdPrice, func(ctx *sdk.Context) error {
+		err := sdk.CheckAccess(srvc, ctx, accessKey, ContractName, CmdPrice, ctx.Step)
+		if err != nil {
+			return err
+		}
+
+		return setPrice(ctx)
+	}, native.Arg{Name: PriceArg, Required: true})
+
+	return c
+}
+
+// RegisterContract registers the bank contract to the given execution service
+// alongside the schema of its arguments.
+func RegisterContract(exec *native.Service, c *sdk.Contract) {
+	c.Register(exec)
+}
+
+// BalanceOf returns the number of tokens of the identity, given in its text
+// form, in the bank.
+func BalanceOf(snap store.Readable, identity []byte) (uint64, error) {
+	var balance uint64
+
+	_, err := sdk.GetJSON(snap, sdk.NewKey(balancePrefix, identity), &balance)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to read balance: %v", err)
+	}
+
+	return balance, nil
+}
+
+// ReadPrice returns the price of a unit of gas.
+func ReadPrice(snap store.Readable) (uint64, error) {
+	var price uint64
+
+	_, err := sdk.GetJSON(snap, sdk.NewKey(pricePrefix), &price)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to read price: %v", err)
+	}
+
+	return price, nil
+}
+
+// ReadFees returns the number of tokens collected by the fees.
+func ReadFees(snap store.Readable) (uint64, error) {
+	var fees uint64
+
+	_, err := sdk.GetJSON(snap, sdk.NewKey(feesPrefix), &fees)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to read fees: %v", err)
+	}
+
+	return fees, nil
+}
+
+// Payer pays for the gas of the transactions with the balance of their author
+// in the bank.
+//
+// - implements simple.Payer
+type Payer struct{}
+
+// NewPayer returns a new payer.
+func NewPayer() Payer {
+	return Payer{}
+}
+
+// Check implements simple.Payer. It returns nil if the balance of the author
+// covers the fee of the amount of gas.
+func (Payer) Check(snap store.Readable, tx txn.Transaction, gas uint64) error {
+	identity, fee, err := readFee(snap, tx, gas)
+	if err != nil {
+		return err
+	}
+
+	balance, err := BalanceOf(snap, identity)
+	if err != nil {
+		return err
+	}
+
+	if balance < fee {
+		return xerrors.Errorf("insufficient balance: %d < %d", balance, fee)
+	}
+
+	return nil
+}
+
+// Charge implements simple.Payer. It moves the fee of the amount of gas from
+// the balance of the author to the fees.
+func (Payer) Charge(snap store.Snapshot, tx txn.Transaction, gas uint64) error {
+	identity, fee, err := readFee(snap, tx, gas)
+	if err != nil {
+		return err
+	}
+
+	if fee == 0 {
+		return nil
+	}
+
+	fees, err := ReadFees(snap)
+	if err != nil {
+		return err
+	}
+
+	if fees+fee < fees {
+		return xerrors.New("fees overflow")
+	}
+
+	err = debit(snap, identity, fee)
+	if err != nil {
+		return err
+	}
+
+	return sdk.SetJSON(snap, sdk.NewKey(feesPrefix), fees+fee)
+}
+
+// Refund implements simple.Payer. It moves the fee of the amount of gas from
+// the fees back to the balance of the author.
+func (Payer) Refund(snap store.Snapshot, tx txn.Transaction, gas uint64) error {
+	identity, fee, err := readFee(snap, tx, gas)
+	if err != nil {
+		return err
+	}
+
+	if fee == 0 {
+		return nil
+	}
+
+	fees, err := ReadFees(snap)
+	if err != nil {
+		return err
+	}
+
+	if fees < fee {
+		return xerrors.Errorf("refund above the fees: %d < %d", fees, fee)
+	}
+
+	err = credit(snap, identity, fee)
+	if err != nil {
+		return err
+	}
+
+	return sdk.SetJSON(snap, sdk.NewKey(feesPrefix), fees-fee)
+}
+
+// readFee returns the text form of the author of the transaction and the fee
+// of the amount of gas at the current price.
+func readFee(snap store.Readable, tx txn.Transaction, gas uint64) ([]byte, uint64, error) {
+	identity, err := tx.GetIdentity().MarshalText()
+	if err != nil {
+		return nil, 0, xerrors.Errorf("failed to marshal identity: %v", err)
+	}
+
+	price, err := ReadPrice(snap)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if price > 0 && gas > ^uint64(0)/price {
+		return nil, 0, xerrors.Errorf("fee overflow: %d x %d", gas, price)
+	}
+
+	return identity, gas * price, nil
+}
+
+func deposit(ctx *sdk.Context) error {
+	identity, amount, err := readAmount(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = coin.Debit(ctx, identity, amount)
+	if err != nil {
+		return err
+	}
+
+	err = credit(ctx, identity, amount)
+	if err != nil {
+		return err
+	}
+
+	ctx.Emit("deposit", "identity", string(identity))
+
+	return nil
+}
+
+func withdraw(ctx *sdk.Context) error {
+	identity, amount, err := readAmount(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = debit(ctx, identity, amount)
+	if err != nil {
+		return err
+	}
+
+	err = coin.Credit(ctx, identity, amount)
+	if err != nil {
+		return err
+	}
+
+	ctx.Emit("withdraw", "identity", string(identity))
+
+	return nil
+}
+
+func setPrice(ctx *sdk.Context) error {
+	price, err := ctx.Args.Uint64(PriceArg)
+	if err != nil {
+		return err
+	}
+
+	err = sdk.SetJSON(ctx, sdk.NewKey(pricePrefix), price)
+	if err != nil {
+		return err
+	}
+
+	ctx.Emit("price")
+
+	return nil
+}
+
+func readAmount(ctx *sdk.Context) ([]byte, uint64, error) {
+	identity, err := ctx.GetIdentity().MarshalText()
+	if err != nil {
+		return nil, 0, xerrors.Errorf("failed to marshal identity: %v", err)
+	}
+
+	amount, err := ctx.Args.Uint64(AmountArg)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if amount == 0 {
+		return nil, 0, xerrors.New("amount must be positive")
+	}
+
+	return identity, amount, nil
+}
+
+func debit(snap store.Snapshot, identity []byte, amount uint64) error {
+	balance, err := BalanceOf(snap, identity)
+	if err != nil {
+		return err
+	}
+
+	if balance < amount {
+		return xerrors.Errorf("insufficient balance: %d < %d", balance, amount)
+	}
+
+	return sdk.SetJSON(snap, sdk.NewKey(balancePrefix, identity), balance-amount)
+}
+
+func credit(snap store.Snapshot, identity []byte, amount uint64) error {
+	balance, err := BalanceOf(snap, identity)
+	if err != nil {
+		return err
+	}
+
+	if balance+amount < balance {
+		return xerrors.New("balance overflow")
+	}
+
+	return sdk.SetJSON(snap, sdk.NewKey(balancePrefix, identity), balance+amount)
+}
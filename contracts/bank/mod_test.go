@@ -0,0 +1,211 @@
+package bank
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/contracts/sdk"
+	"go.dedis.ch/dela/contracts/sdk/sdktest"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+// Payer must be usable by the validation service.
+var _ simple.Payer = Payer{}
+
+func TestContract_Deposit(t *testing.T) {
+	alice := bls.Generate()
+	aliceID := textOf(t, alice.GetPublicKey())
+
+	sdktest.Run(t, NewContract(nil, fakeAccess{}), []sdktest.TestCase{
+		{
+			Name:    "deposit",
+			Signer:  alice,
+			Initial: map[string][]byte{coinKey(aliceID): []byte("10")},
+			Args:    map[string]string{CmdArg: CmdDeposit, AmountArg: "4"},
+			Expected: map[string][]byte{
+				coinKey(aliceID):    []byte("6"),
+				balanceKey(aliceID): []byte("4"),
+			},
+			Events: []string{"deposit"},
+		},
+		{
+			Name:    "insufficient coins",
+			Signer:  alice,
+			Initial: map[string][]byte{coinKey(aliceID): []byte("3")},
+			Args:    map[string]string{CmdArg: CmdDeposit, AmountArg: "4"},
+			Err:     "failed to DEPOSIT: insufficient balance: 3 < 4",
+		},
+		{
+			Name: "zero amount",
+			Args: map[string]string{CmdArg: CmdDeposit, AmountArg: "0"},
+			Err:  "failed to DEPOSIT: amount must be positive",
+		},
+	})
+}
+
+func TestContract_Withdraw(t *testing.T) {
+	alice := bls.Generate()
+	aliceID := textOf(t, alice.GetPublicKey())
+
+	sdktest.Run(t, NewContract(nil, fakeAccess{}), []sdktest.TestCase{
+		{
+			Name:    "withdraw",
+			Signer:  alice,
+			Initial: map[string][]byte{balanceKey(aliceID): []byte("10")},
+			Args:    map[string]string{CmdArg: CmdWithdraw, AmountArg: "4"},
+			Expected: map[string][]byte{
+				coinKey(aliceID):    []byte("4"),
+				balanceKey(aliceID): []byte("6"),
+			},
+			Events: []string{"withdraw"},
+		},
+		{
+			Name:    "insufficient balance",
+			Signer:  alice,
+			Initial: map[string][]byte{balanceKey(aliceID): []byte("3")},
+			Args:    map[string]string{CmdArg: CmdWithdraw, AmountArg: "4"},
+			Err:     "failed to WITHDRAW: insufficient balance: 3 < 4",
+		},
+	})
+}
+
+func TestContract_Price(t *testing.T) {
+	sdktest.Run(t, NewContract(nil, fakeAccess{}), []sdktest.TestCase{
+		{
+			Name: "set price",
+			Args: map[string]string{CmdArg: CmdPrice, PriceArg: "3"},
+			Expected: map[string][]byte{
+				string(sdk.NewKey(pricePrefix)): []byte("3"),
+			},
+			Events: []string{"price"},
+		},
+		{
+			Name: "bad price",
+			Args: map[string]string{CmdArg: CmdPrice, PriceArg: "abc"},
+			Err: "failed to PRICE: 'bank:price' is not an unsigned integer: " +
+				"strconv.ParseUint: parsing \"abc\": invalid syntax",
+		},
+	})
+
+	tx, err := signed.NewTransaction(0, bls.Generate().GetPublicKey(),
+		signed.WithArg(CmdArg, []byte(CmdPrice)),
+		signed.WithArg(PriceArg, []byte("3")))
+	require.NoError(t, err)
+
+	contract := NewContract(nil, fakeAccess{err: fake.GetError()})
+
+	err = contract.Execute(fake.NewSnapshot(), execution.Step{Current: tx})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "identity not authorized: ")
+}
+
+func TestRegisterContract(t *testing.T) {
+	exec := native.NewExecution()
+
+	RegisterContract(exec, NewContract(nil, fakeAccess{}))
+
+	require.NoError(t, exec.IsServed(ContractName))
+}
+
+func TestPayer_Charge(t *testing.T) {
+	tx := makeTx(t)
+	id := textOf(t, tx.GetIdentity())
+
+	snap := fake.NewSnapshot()
+	snap.Set(sdk.NewKey(pricePrefix), []byte("2"))
+	snap.Set([]byte(balanceKey(id)), []byte("10"))
+
+	payer := NewPayer()
+
+	require.NoError(t, payer.Check(snap, tx, 5))
+	require.EqualError(t, payer.Check(snap, tx, 6), "insufficient balance: 10 < 12")
+
+	require.NoError(t, payer.Charge(snap, tx, 4))
+	requireBalance(t, snap, id, 2, 8)
+
+	require.NoError(t, payer.Refund(snap, tx, 1))
+	requireBalance(t, snap, id, 4, 6)
+
+	err := payer.Charge(snap, tx, 5)
+	require.EqualError(t, err, "insufficient balance: 4 < 10")
+	requireBalance(t, snap, id, 4, 6)
+
+	err = payer.Refund(snap, tx, 4)
+	require.EqualError(t, err, "refund above the fees: 6 < 8")
+
+	err = payer.Check(snap, tx, ^uint64(0))
+	require.EqualError(t, err, "fee overflow: 18446744073709551615 x 2")
+
+	err = payer.Charge(fake.NewBadSnapshot(), tx, 1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read price: ")
+}
+
+func TestPayer_Free(t *testing.T) {
+	tx := makeTx(t)
+
+	// The price is zero until it is set, so that any transaction is free.
+	snap := fake.NewSnapshot()
+
+	payer := NewPayer()
+	require.NoError(t, payer.Check(snap, tx, 100))
+	require.NoError(t, payer.Charge(snap, tx, 100))
+	require.NoError(t, payer.Refund(snap, tx, 100))
+
+	fees, err := ReadFees(snap)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), fees)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func textOf(t *testing.T, pk interface{ MarshalText() ([]byte, error) }) string {
+	text, err := pk.MarshalText()
+	require.NoError(t, err)
+
+	return string(text)
+}
+
+func balanceKey(identity string) string {
+	return string(sdk.NewKey(balancePrefix, []byte(identity)))
+}
+
+func coinKey(identity string) string {
+	return string(sdk.NewKey("coin:balance", []byte(identity)))
+}
+
+func makeTx(t *testing.T) txn.Transaction {
+	tx, err := signed.NewTransaction(0, bls.Generate().GetPublicKey())
+	require.NoError(t, err)
+
+	return tx
+}
+
+func requireBalance(t *testing.T, snap store.Readable, id string, balance, fees uint64) {
+	actual, err := BalanceOf(snap, []byte(id))
+	require.NoError(t, err)
+	require.Equal(t, balance, actual)
+
+	actual, err = ReadFees(snap)
+	require.NoError(t, err)
+	require.Equal(t, fees, actual)
+}
+
+type fakeAccess struct {
+	access.Service
+
+	err error
+}
+
+func (srvc fakeAccess) Match(store.Readable, access.Credential, ...access.Identity) error {
+	return srvc.err
+}
@@ -0,0 +1,248 @@
+// Package controller implements a CLI controller to register the coin contract,
+// to register the endpoint of the faucet and to read the balances.
+package controller
+
+import (
+	"fmt"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/contracts/coin"
+	"go.dedis.ch/dela/contracts/coin/faucet"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/pool"
+	"go.dedis.ch/dela/mino/proxy"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// faucetFlag is the flag name to enable the faucet.
+	faucetFlag = "coin-faucet"
+
+	// amountFlag is the flag name of the number of tokens of a claim.
+	amountFlag = "coin-faucet-amount"
+
+	// intervalFlag is the flag name of the number of blocks between two claims
+	// of an identity.
+	intervalFlag = "coin-faucet-interval"
+
+	// windowFlag is the flag name of the number of blocks of the global limit.
+	windowFlag = "coin-faucet-window"
+
+	// maxClaimsFlag is the flag name of the maximum number of claims in a
+	// window.
+	maxClaimsFlag = "coin-faucet-max-claims"
+
+	defaultPath = "/faucet"
+)
+
+// miniController is a CLI initializer to register the coin contract.
+//
+// - implements node.Initializer
+type miniController struct{}
+
+// NewController creates a new minimal controller for the coin contract.
+func NewController() node.Initializer {
+	return miniController{}
+}
+
+// SetCommands implements node.Initializer. It sets the flags of the faucet,
+// which must be the same on every node, and the commands to register the
+// endpoint of the faucet and to read a balance.
+func (miniController) SetCommands(builder node.Builder) {
+	builder.SetStartFlags(
+		cli.BoolFlag{
+			Name:  faucetFlag,
+			Usage: "enable the faucet of the coin contract, for test networks only",
+		},
+		cli.IntFlag{
+			Name:  amountFlag,
+			Usage: "number of tokens dispensed by a claim of the faucet",
+			Value: int(coin.DefaultFaucet.Amount),
+		},
+		cli.IntFlag{
+			Name:  intervalFlag,
+			Usage: "number of blocks an identity waits between two claims",
+			Value: int(coin.DefaultFaucet.Interval),
+		},
+		cli.IntFlag{
+			Name:  windowFlag,
+			Usage: "number of blocks of the window of the global limit",
+			Value: int(coin.DefaultFaucet.Window),
+		},
+		cli.IntFlag{
+			Name:  maxClaimsFlag,
+			Usage: "maximum number of claims in a window",
+			Value: int(coin.DefaultFaucet.MaxClaims),
+		},
+	)
+
+	cmd := builder.SetCommand("coin")
+	cmd.SetDescription("test tokens")
+
+	sub := cmd.SetSubCommand("faucet")
+	sub.SetDescription("register the endpoint of the faucet on the proxy")
+	sub.SetFlags(cli.StringFlag{
+		Name:  "path",
+		Usage: "the path of the endpoint",
+		Value: defaultPath,
+	})
+	sub.SetAction(builder.MakeAction(faucetAction{}))
+
+	sub = cmd.SetSubCommand("balance")
+	sub.SetDescription("print the balance of an identity")
+	sub.SetFlags(cli.StringFlag{
+		Name:  "identity",
+		Usage: "the text form of the identity",
+	})
+	sub.SetAction(builder.MakeAction(balanceAction{}))
+}
+
+// OnStart implements node.Initializer. It registers the coin contract, with the
+// faucet if it is enabled.
+func (miniController) OnStart(flags cli.Flags, inj node.Injector) error {
+	var exec *native.Service
+	err := inj.Resolve(&exec)
+	if err != nil {
+		return xerrors.Errorf("failed to resolve native service: %v", err)
+	}
+
+	opts := []coin.Option{}
+
+	if flags.Bool(faucetFlag) {
+		params, err := parseFaucet(flags)
+		if err != nil {
+			return xerrors.Errorf("invalid faucet: %v", err)
+		}
+
+		opts = append(opts, coin.WithFaucet(params))
+
+		inj.Inject(params)
+	}
+
+	coin.RegisterContract(exec, coin.NewContract(opts...))
+
+	return nil
+}
+
+// OnStop implements node.Initializer.
+func (miniController) OnStop(inj node.Injector) error {
+	return nil
+}
+
+// faucetAction is an action to register the endpoint of the faucet on the
+// proxy.
+//
+// - implements node.ActionTemplate
+type faucetAction struct{}
+
+// Execute implements node.ActionTemplate. It registers the endpoint that submits
+// the claims of the faucet signed by the node.
+func (faucetAction) Execute(ctx node.Context) error {
+	var params coin.Faucet
+	err := ctx.Injector.Resolve(&params)
+	if err != nil {
+		return xerrors.Errorf("faucet is disabled: %v", err)
+	}
+
+	var p proxy.Proxy
+	err = ctx.Injector.Resolve(&p)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var mgr txn.Manager
+	err = ctx.Injector.Resolve(&mgr)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var pl pool.Pool
+	err = ctx.Injector.Resolve(&pl)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var srvc ordering.Service
+	err = ctx.Injector.Resolve(&srvc)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var blocks blockstore.BlockStore
+	err = ctx.Injector.Resolve(&blocks)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	path := ctx.Flags.String("path")
+
+	err = p.RegisterHandler(path, faucet.NewHandler(mgr, pl, srvc, blocks, params))
+	if err != nil {
+		return xerrors.Errorf("failed to register handler: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "faucet endpoint registered on %s", path)
+
+	return nil
+}
+
+// balanceAction is an action to print the balance of an identity against the
+// latest state.
+//
+// - implements node.ActionTemplate
+type balanceAction struct{}
+
+// Execute implements node.ActionTemplate. It prints the balance of the identity.
+func (balanceAction) Execute(ctx node.Context) error {
+	var srvc ordering.Service
+	err := ctx.Injector.Resolve(&srvc)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	identity := ctx.Flags.String("identity")
+	if identity == "" {
+		return xerrors.New("identity is missing")
+	}
+
+	balance, err := coin.BalanceOf(srvc.GetStore(), []byte(identity))
+	if err != nil {
+		return xerrors.Errorf("couldn't get balance: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "%d", balance)
+
+	return nil
+}
+
+func parseFaucet(flags cli.Flags) (coin.Faucet, error) {
+	values := []int{
+		flags.Int(amountFlag),
+		flags.Int(intervalFlag),
+		flags.Int(windowFlag),
+		flags.Int(maxClaimsFlag),
+	}
+
+	for _, value := range values {
+		if value < 0 {
+			return coin.Faucet{}, xerrors.Errorf("negative value %d", value)
+		}
+	}
+
+	params := coin.Faucet{
+		Amount:    uint64(values[0]),
+		Interval:  uint64(values[1]),
+		Window:    uint64(values[2]),
+		MaxClaims: uint64(values[3]),
+	}
+
+	if params.Amount == 0 {
+		return params, xerrors.New("amount must be positive")
+	}
+
+	return params, nil
+}
@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/contracts/coin"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn/pool/mem"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino/proxy"
+)
+
+func TestMiniController_SetCommands(t *testing.T) {
+	ctrl := NewController()
+
+	ctrl.SetCommands(node.NewBuilder())
+}
+
+func TestMiniController_OnStart(t *testing.T) {
+	ctrl := NewController()
+
+	injector := node.NewInjector()
+	err := ctrl.OnStart(node.FlagSet{}, injector)
+	require.EqualError(t, err,
+		"failed to resolve native service: couldn't find dependency for '*native.Service'")
+
+	exec := native.NewExecution()
+	injector.Inject(exec)
+
+	err = ctrl.OnStart(node.FlagSet{}, injector)
+	require.NoError(t, err)
+	require.NoError(t, exec.IsServed(coin.ContractName))
+
+	var params coin.Faucet
+	require.Error(t, injector.Resolve(&params))
+
+	flags := node.FlagSet{
+		faucetFlag:    true,
+		amountFlag:    5,
+		intervalFlag:  2,
+		windowFlag:    3,
+		maxClaimsFlag: 4,
+	}
+
+	err = ctrl.OnStart(flags, injector)
+	require.NoError(t, err)
+	require.NoError(t, injector.Resolve(&params))
+	require.Equal(t, coin.Faucet{Amount: 5, Interval: 2, Window: 3, MaxClaims: 4}, params)
+
+	flags[windowFlag] = -1
+
+	err = ctrl.OnStart(flags, injector)
+	require.EqualError(t, err, "invalid faucet: negative value -1")
+
+	err = ctrl.OnStart(node.FlagSet{faucetFlag: true}, injector)
+	require.EqualError(t, err, "invalid faucet: amount must be positive")
+}
+
+func TestMiniController_OnStop(t *testing.T) {
+	err := NewController().OnStop(nil)
+	require.NoError(t, err)
+}
+
+func TestFaucetAction_Execute(t *testing.T) {
+	out := new(bytes.Buffer)
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Flags:    node.FlagSet{"path": "/faucet"},
+		Out:      out,
+	}
+
+	err := faucetAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"faucet is disabled: couldn't find dependency for 'coin.Faucet'")
+
+	ctx.Injector.Inject(coin.DefaultFaucet)
+
+	err = faucetAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'proxy.Proxy'")
+
+	px := &fakeProxy{}
+	ctx.Injector.Inject(px)
+
+	err = faucetAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'txn.Manager'")
+
+	ctx.Injector.Inject(signed.NewManager(fake.NewSigner(), nil))
+
+	err = faucetAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'pool.Pool'")
+
+	ctx.Injector.Inject(mem.NewPool())
+
+	err = faucetAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'ordering.Service'")
+
+	ctx.Injector.Inject(fakeService{snap: fake.NewSnapshot()})
+
+	err = faucetAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'blockstore.BlockStore'")
+
+	ctx.Injector.Inject(blockstore.NewInMemory())
+
+	err = faucetAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "/faucet", px.path)
+	require.Equal(t, "faucet endpoint registered on /faucet", out.String())
+
+	px.err = fake.GetError()
+	err = faucetAction{}.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to register handler"))
+}
+
+func TestBalanceAction_Execute(t *testing.T) {
+	out := new(bytes.Buffer)
+	flags := node.FlagSet{}
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Flags:    flags,
+		Out:      out,
+	}
+
+	err := balanceAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'ordering.Service'")
+
+	snap := fake.NewSnapshot()
+	ctx.Injector.Inject(fakeService{snap: snap})
+
+	err = balanceAction{}.Execute(ctx)
+	require.EqualError(t, err, "identity is missing")
+
+	flags["identity"] = "alice"
+
+	tx, err := signed.NewTransaction(0, fake.PublicKey{},
+		signed.WithArg(coin.CmdArg, []byte(coin.CmdFaucet)),
+		signed.WithArg(coin.ToArg, []byte("alice")))
+	require.NoError(t, err)
+
+	c := coin.NewContract(coin.WithFaucet(coin.DefaultFaucet))
+	err = c.Execute(snap, execution.Step{Current: tx})
+	require.NoError(t, err)
+
+	err = balanceAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "1000", out.String())
+
+	ctx.Injector.Inject(fakeService{snap: fake.NewBadSnapshot()})
+
+	err = balanceAction{}.Execute(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "couldn't get balance: ")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeService struct {
+	ordering.Service
+
+	snap store.Snapshot
+}
+
+func (s fakeService) GetStore() store.Readable {
+	return s.snap
+}
+
+type fakeProxy struct {
+	proxy.Proxy
+
+	path string
+	err  error
+}
+
+func (p *fakeProxy) RegisterHandler(path string, _ func(http.ResponseWriter, *http.Request)) error {
+	p.path = path
+
+	return p.err
+}
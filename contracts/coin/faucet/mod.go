@@ -0,0 +1,194 @@
+// Package faucet implements an HTTP endpoint to dispense test tokens of the coin
+// contract on the development networks.
+//
+// The body of a POST request is a JSON document with the text form of the
+// identity to credit. The node signs a claim of the faucet on behalf of the
+// identity and adds it to the pool. A request is refused before a transaction
+// is created if the claim would be rejected by the contract at the next block,
+// or if a claim was already submitted by the node for the identity, or too many
+// claims in the current window, but is not yet committed.
+package faucet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"go.dedis.ch/dela/contracts/coin"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/pool"
+	"golang.org/x/xerrors"
+)
+
+// MaxBodySize is the maximum number of bytes of a request.
+const MaxBodySize = 1 << 12
+
+// Request is the JSON document expected by the endpoint.
+type Request struct {
+	Identity string `json:"identity"`
+}
+
+// Response is the JSON document returned by the endpoint.
+type Response struct {
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// handler is the state of the endpoint shared by the requests.
+type handler struct {
+	sync.Mutex
+
+	mgr    txn.Manager
+	pool   pool.Pool
+	srvc   ordering.Service
+	blocks blockstore.BlockStore
+	params coin.Faucet
+
+	synced bool
+	// pending maps the identities to the height of the claims submitted by
+	// the node.
+	pending map[string]uint64
+}
+
+// NewHandler returns an HTTP handler that submits a claim of the faucet for
+// the identity of a request. The transactions are created by the manager and
+// added to the pool.
+func NewHandler(mgr txn.Manager, p pool.Pool, srvc ordering.Service,
+	blocks blockstore.BlockStore, params coin.Faucet) func(http.ResponseWriter, *http.Request) {
+
+	h := &handler{
+		mgr:     mgr,
+		pool:    p,
+		srvc:    srvc,
+		blocks:  blocks,
+		params:  params,
+		pending: make(map[string]uint64),
+	}
+
+	return h.serve
+}
+
+func (h *handler) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed,
+			xerrors.Errorf("method '%s' not allowed", r.Method))
+		return
+	}
+
+	var req Request
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodySize)).Decode(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, xerrors.Errorf("invalid body: %v", err))
+		return
+	}
+
+	if req.Identity == "" {
+		writeError(w, http.StatusBadRequest, xerrors.New("identity is missing"))
+		return
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	// The claim is executed at best in the next block.
+	height := h.blocks.Len()
+
+	err = h.checkPending(req.Identity, height)
+	if err != nil {
+		writeError(w, http.StatusTooManyRequests, err)
+		return
+	}
+
+	err = coin.CheckClaim(h.srvc.GetStore(), h.params, []byte(req.Identity), height)
+	if err != nil {
+		writeError(w, http.StatusTooManyRequests, err)
+		return
+	}
+
+	tx, err := h.submit(req.Identity)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.pending[req.Identity] = height
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(Response{ID: hex.EncodeToString(tx.GetID())})
+}
+
+// checkPending returns an error if the claims submitted by the node, that
+// could still be waiting for a block, exceed the limits of the faucet. The
+// claims that are old enough to be committed are forgotten.
+func (h *handler) checkPending(identity string, height uint64) error {
+	claims := uint64(0)
+
+	for id, last := range h.pending {
+		inInterval := height < last+h.params.Interval
+		inWindow := height < last+h.params.Window
+
+		if !inInterval && !inWindow {
+			delete(h.pending, id)
+			continue
+		}
+
+		if inInterval && id == identity {
+			return xerrors.Errorf("identity '%s' cannot claim before block %d",
+				identity, last+h.params.Interval)
+		}
+
+		if inWindow {
+			claims++
+		}
+	}
+
+	if claims >= h.params.MaxClaims {
+		return xerrors.New("faucet exhausted, try again later")
+	}
+
+	return nil
+}
+
+// submit creates the claim and adds it to the pool. The manager keeps track of
+// the nonce of the pending transactions, so it is only synchronized with the
+// chain the first time and after a failure.
+func (h *handler) submit(identity string) (txn.Transaction, error) {
+	if !h.synced {
+		err := h.mgr.Sync()
+		if err != nil {
+			return nil, xerrors.Errorf("failed to sync manager: %v", err)
+		}
+
+		h.synced = true
+	}
+
+	tx, err := h.mgr.Make(
+		txn.Arg{Key: native.ContractArg, Value: []byte(coin.ContractName)},
+		txn.Arg{Key: coin.CmdArg, Value: []byte(coin.CmdFaucet)},
+		txn.Arg{Key: coin.ToArg, Value: []byte(identity)},
+	)
+	if err != nil {
+		h.synced = false
+		return nil, xerrors.Errorf("failed to create transaction: %v", err)
+	}
+
+	err = h.pool.Add(tx)
+	if err != nil {
+		h.synced = false
+		return nil, xerrors.Errorf("failed to add transaction: %v", err)
+	}
+
+	return tx, nil
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(Response{Error: err.Error()})
+}
@@ -0,0 +1,206 @@
+package faucet
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/contracts/coin"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/pool"
+	"go.dedis.ch/dela/core/txn/pool/mem"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"golang.org/x/xerrors"
+)
+
+func TestHandler(t *testing.T) {
+	params := coin.Faucet{Amount: 10, Interval: 5, Window: 5, MaxClaims: 2}
+
+	snap := fake.NewSnapshot()
+	mgr := &fakeManager{}
+	p := mem.NewPool()
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		NewHandler(mgr, p, fakeService{snap: snap}, blockstore.NewInMemory(), params)))
+	defer srv.Close()
+
+	resp := post(t, srv.URL, `{"identity":"alice"}`)
+	require.Equal(t, http.StatusAccepted, resp.status)
+	require.NotEmpty(t, resp.ID)
+	require.Equal(t, 1, p.Len())
+	require.Equal(t, 1, mgr.syncs)
+
+	// The claim is pending so a second one is refused before it reaches the
+	// contract.
+	resp = post(t, srv.URL, `{"identity":"alice"}`)
+	require.Equal(t, http.StatusTooManyRequests, resp.status)
+	require.Equal(t, "identity 'alice' cannot claim before block 5", resp.Error)
+
+	resp = post(t, srv.URL, `{"identity":"bob"}`)
+	require.Equal(t, http.StatusAccepted, resp.status)
+	require.Equal(t, 2, p.Len())
+	require.Equal(t, 1, mgr.syncs)
+
+	resp = post(t, srv.URL, `{"identity":"carol"}`)
+	require.Equal(t, http.StatusTooManyRequests, resp.status)
+	require.Equal(t, "faucet exhausted, try again later", resp.Error)
+
+	resp = post(t, srv.URL, `{}`)
+	require.Equal(t, http.StatusBadRequest, resp.status)
+	require.Equal(t, "identity is missing", resp.Error)
+
+	resp = post(t, srv.URL, `{`)
+	require.Equal(t, http.StatusBadRequest, resp.status)
+	require.Equal(t, "invalid body: unexpected EOF", resp.Error)
+
+	res, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
+
+func TestHandler_CheckClaim(t *testing.T) {
+	params := coin.Faucet{Amount: 10, Interval: 5, Window: 5, MaxClaims: 2}
+
+	snap := fake.NewSnapshot()
+
+	// A claim of the identity is already committed.
+	c := coin.NewContract(coin.WithFaucet(params))
+	tx, err := signed.NewTransaction(0, fake.PublicKey{},
+		signed.WithArg(coin.CmdArg, []byte(coin.CmdFaucet)),
+		signed.WithArg(coin.ToArg, []byte("alice")))
+	require.NoError(t, err)
+	require.NoError(t, c.Execute(snap, execution.Step{Current: tx}))
+
+	mgr := &fakeManager{}
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		NewHandler(mgr, mem.NewPool(), fakeService{snap: snap}, blockstore.NewInMemory(), params)))
+	defer srv.Close()
+
+	resp := post(t, srv.URL, `{"identity":"alice"}`)
+	require.Equal(t, http.StatusTooManyRequests, resp.status)
+	require.Equal(t, "identity 'alice' cannot claim before block 5", resp.Error)
+	require.Equal(t, 0, mgr.syncs)
+}
+
+func TestHandler_Submit(t *testing.T) {
+	mgr := &fakeManager{err: xerrors.New("oops")}
+	p := &fakePool{Pool: mem.NewPool()}
+
+	srv := httptest.NewServer(http.HandlerFunc(NewHandler(mgr, p,
+		fakeService{snap: fake.NewSnapshot()}, blockstore.NewInMemory(), coin.DefaultFaucet)))
+	defer srv.Close()
+
+	resp := post(t, srv.URL, `{"identity":"alice"}`)
+	require.Equal(t, http.StatusInternalServerError, resp.status)
+	require.Equal(t, "failed to sync manager: oops", resp.Error)
+
+	mgr.err = nil
+	mgr.errMake = xerrors.New("oops")
+
+	resp = post(t, srv.URL, `{"identity":"alice"}`)
+	require.Equal(t, http.StatusInternalServerError, resp.status)
+	require.Equal(t, "failed to create transaction: oops", resp.Error)
+
+	mgr.errMake = nil
+	p.err = xerrors.New("oops")
+
+	resp = post(t, srv.URL, `{"identity":"alice"}`)
+	require.Equal(t, http.StatusInternalServerError, resp.status)
+	require.Equal(t, "failed to add transaction: oops", resp.Error)
+
+	// The manager is synchronized again after each failure.
+	p.err = nil
+
+	resp = post(t, srv.URL, `{"identity":"alice"}`)
+	require.Equal(t, http.StatusAccepted, resp.status)
+	require.Equal(t, 4, mgr.syncs)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type response struct {
+	Response
+
+	status int
+}
+
+func post(t *testing.T, url, body string) response {
+	res, err := http.Post(url, "application/json", bytes.NewBufferString(body))
+	require.NoError(t, err)
+
+	defer res.Body.Close()
+
+	resp := response{status: res.StatusCode}
+
+	err = json.NewDecoder(res.Body).Decode(&resp.Response)
+	require.NoError(t, err)
+
+	return resp
+}
+
+type fakeManager struct {
+	nonce   uint64
+	syncs   int
+	err     error
+	errMake error
+}
+
+func (m *fakeManager) Make(args ...txn.Arg) (txn.Transaction, error) {
+	if m.errMake != nil {
+		return nil, m.errMake
+	}
+
+	opts := []signed.TransactionOption{}
+	for _, arg := range args {
+		opts = append(opts, signed.WithArg(arg.Key, arg.Value))
+	}
+
+	tx, err := signed.NewTransaction(m.nonce, fake.PublicKey{}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m.nonce++
+
+	return tx, nil
+}
+
+func (m *fakeManager) Sync() error {
+	m.syncs++
+
+	return m.err
+}
+
+type fakePool struct {
+	pool.Pool
+
+	err error
+}
+
+func (p *fakePool) Add(tx txn.Transaction) error {
+	if p.err != nil {
+		return p.err
+	}
+
+	return p.Pool.Add(tx)
+}
+
+type fakeService struct {
+	ordering.Service
+
+	snap store.Snapshot
+}
+
+func (s fakeService) GetStore() store.Readable {
+	return s.snap
+}
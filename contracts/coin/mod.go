@@ -0,0 +1,293 @@
+// Package coin implements a native contract that holds the balances of test
+// tokens, for the development networks where the applications need a currency
+// without an issuance policy.
+//
+// The tokens are created by the faucet, which credits an identity with a fixed
+// amount when it is enabled, and they can then be transferred between
+// identities. The faucet is rate limited per identity, which can only claim
+// once in a number of blocks, and globally with a maximum number of claims in
+// a window of blocks. The limits are counted in blocks rather than in time so
+// that every node comes to the same decision.
+//
+// The contract must be created with the same faucet parameters on every node.
+package coin
+
+import (
+	"go.dedis.ch/dela/contracts/sdk"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// ContractName is the name of the contract.
+	ContractName = "go.dedis.ch/dela.Coin"
+
+	// CmdArg is the argument's name to indicate the kind of command we want to
+	// run on the contract.
+	CmdArg = "coin:command"
+
+	// ToArg is the argument's name in the transaction that contains the text
+	// form of the identity receiving the tokens.
+	ToArg = "coin:to"
+
+	// AmountArg is the argument's name in the transaction that contains the
+	// number of tokens to transfer.
+	AmountArg = "coin:amount"
+
+	// CmdTransfer defines the command to send tokens to another identity.
+	CmdTransfer = "TRANSFER"
+
+	// CmdFaucet defines the command to claim tokens from the faucet. The
+	// tokens are credited to the identity of the argument if it is set,
+	// otherwise to the author of the transaction.
+	CmdFaucet = "FAUCET"
+
+	balancePrefix = "coin:balance"
+	claimPrefix   = "coin:claim"
+	windowPrefix  = "coin:window"
+)
+
+// Faucet is the set of parameters of the faucet.
+type Faucet struct {
+	// Amount is the number of tokens credited by a claim.
+	Amount uint64
+
+	// Interval is the number of blocks an identity waits between two claims.
+	Interval uint64
+
+	// Window is the number of blocks of the global limit.
+	Window uint64
+
+	// MaxClaims is the maximum number of claims in a window.
+	MaxClaims uint64
+}
+
+// DefaultFaucet is the default set of parameters of the faucet.
+var DefaultFaucet = Faucet{
+	Amount:    1000,
+	Interval:  100,
+	Window:    10,
+	MaxClaims: 20,
+}
+
+// window is the state of the global limit of the faucet.
+type window struct {
+	Start  uint64
+	Claims uint64
+}
+
+// Option is the type of option to create the contract.
+type Option func(*options)
+
+type options struct {
+	faucet *Faucet
+}
+
+// WithFaucet enables the faucet with the parameters.
+func WithFaucet(f Faucet) Option {
+	return func(opts *options) {
+		opts.faucet = &f
+	}
+}
+
+// NewContract creates a new coin contract. The faucet is disabled by default.
+func NewContract(opts ...Option) *sdk.Contract {
+	tmpl := options{}
+	for _, opt := range opts {
+		opt(&tmpl)
+	}
+
+	c := sdk.NewContract(ContractName, CmdArg)
+
+	c.Handle(CmdTransfer, transfer,
+		native.Arg{Name: ToArg, Required: true},
+		native.Arg{Name: AmountArg, Required: true})
+
+	if tmpl.faucet != nil {
+		faucet := *tmpl.faucet
+
+		c.Handle(CmdFaucet, func(ctx *sdk.Context) error {
+			return claim(ctx, faucet)
+		}, native.Arg{Name: ToArg})
+	}
+
+	return c
+}
+
+// RegisterContract registers the coin contract to the given execution service
+// alongside the schema of its arguments.
+func RegisterContract(exec *native.Service, c *sdk.Contract) {
+	c.Register(exec)
+}
+
+// BalanceOf returns the number of tokens of the identity, given in its text
+// form.
+func BalanceOf(snap store.Readable, identity []byte) (uint64, error) {
+	var balance uint64
+
+	_, err := sdk.GetJSON(snap, sdk.NewKey(balancePrefix, identity), &balance)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to read balance: %v", err)
+	}
+
+	return balance, nil
+}
+
+// Debit removes the number of tokens from the balance of the identity, given
+// in its text form. The tokens are not credited to anyone, which lets other
+// contracts charge fees.
+func Debit(snap store.Snapshot, identity []byte, amount uint64) error {
+	balance, err := BalanceOf(snap, identity)
+	if err != nil {
+		return err
+	}
+
+	if balance < amount {
+		return xerrors.Errorf("insufficient balance: %d < %d", balance, amount)
+	}
+
+	return sdk.SetJSON(snap, sdk.NewKey(balancePrefix, identity), balance-amount)
+}
+
+// CheckClaim returns nil if the identity, given in its text form, can claim
+// tokens from the faucet at the height, otherwise an error that tells when it
+// can claim again.
+func CheckClaim(snap store.Readable, f Faucet, identity []byte, height uint64) error {
+	var last uint64
+
+	found, err := sdk.GetJSON(snap, sdk.NewKey(claimPrefix, identity), &last)
+	if err != nil {
+		return xerrors.Errorf("failed to read claim: %v", err)
+	}
+
+	if found && height < last+f.Interval {
+		return xerrors.Errorf("identity '%s' cannot claim before block %d",
+			identity, last+f.Interval)
+	}
+
+	w, err := readWindow(snap, f, height)
+	if err != nil {
+		return err
+	}
+
+	if w.Claims >= f.MaxClaims {
+		return xerrors.Errorf("faucet exhausted until block %d", w.Start+f.Window)
+	}
+
+	return nil
+}
+
+func claim(ctx *sdk.Context, f Faucet) error {
+	identity, err := ctx.GetIdentity().MarshalText()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal identity: %v", err)
+	}
+
+	if ctx.Args.Has(ToArg) {
+		identity, err = ctx.Args.Bytes(ToArg)
+		if err != nil {
+			return err
+		}
+	}
+
+	height := ctx.Step.Index
+
+	err = CheckClaim(ctx, f, identity, height)
+	if err != nil {
+		return err
+	}
+
+	err = Credit(ctx, identity, f.Amount)
+	if err != nil {
+		return err
+	}
+
+	err = sdk.SetJSON(ctx, sdk.NewKey(claimPrefix, identity), height)
+	if err != nil {
+		return err
+	}
+
+	w, err := readWindow(ctx, f, height)
+	if err != nil {
+		return err
+	}
+
+	w.Claims++
+
+	err = sdk.SetJSON(ctx, sdk.NewKey(windowPrefix), w)
+	if err != nil {
+		return err
+	}
+
+	ctx.Emit("claim", "to", string(identity))
+
+	return nil
+}
+
+func transfer(ctx *sdk.Context) error {
+	from, err := ctx.GetIdentity().MarshalText()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal identity: %v", err)
+	}
+
+	to, err := ctx.Args.Bytes(ToArg)
+	if err != nil {
+		return err
+	}
+
+	amount, err := ctx.Args.Uint64(AmountArg)
+	if err != nil {
+		return err
+	}
+
+	if amount == 0 {
+		return xerrors.New("amount must be positive")
+	}
+
+	err = Debit(ctx, from, amount)
+	if err != nil {
+		return err
+	}
+
+	err = Credit(ctx, to, amount)
+	if err != nil {
+		return err
+	}
+
+	ctx.Emit("transfer", "from", string(from), "to", string(to))
+
+	return nil
+}
+
+// Credit adds the number of tokens to the balance of the identity, given in
+// its text form, which lets other contracts pay back tokens they debited.
+func Credit(snap store.Snapshot, identity []byte, amount uint64) error {
+	balance, err := BalanceOf(snap, identity)
+	if err != nil {
+		return err
+	}
+
+	if balance+amount < balance {
+		return xerrors.New("balance overflow")
+	}
+
+	return sdk.SetJSON(snap, sdk.NewKey(balancePrefix, identity), balance+amount)
+}
+
+// readWindow returns the window of the global limit at the height, which is a
+// new one when the previous has ended.
+func readWindow(snap store.Readable, f Faucet, height uint64) (window, error) {
+	var w window
+
+	found, err := sdk.GetJSON(snap, sdk.NewKey(windowPrefix), &w)
+	if err != nil {
+		return w, xerrors.Errorf("failed to read window: %v", err)
+	}
+
+	if !found || height >= w.Start+f.Window {
+		return window{Start: height}, nil
+	}
+
+	return w, nil
+}
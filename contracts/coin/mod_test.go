@@ -0,0 +1,226 @@
+package coin
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/contracts/sdk"
+	"go.dedis.ch/dela/contracts/sdk/sdktest"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestContract_Transfer(t *testing.T) {
+	alice := bls.Generate()
+	aliceID := textOf(t, alice.GetPublicKey())
+
+	sdktest.Run(t, NewContract(), []sdktest.TestCase{
+		{
+			Name:    "transfer",
+			Signer:  alice,
+			Initial: map[string][]byte{balanceKey(aliceID): []byte("10")},
+			Args:    map[string]string{CmdArg: CmdTransfer, ToArg: "bob", AmountArg: "4"},
+			Expected: map[string][]byte{
+				balanceKey(aliceID): []byte("6"),
+				balanceKey("bob"):   []byte("4"),
+			},
+			Events: []string{"transfer"},
+		},
+		{
+			Name:    "insufficient balance",
+			Signer:  alice,
+			Initial: map[string][]byte{balanceKey(aliceID): []byte("3")},
+			Args:    map[string]string{CmdArg: CmdTransfer, ToArg: "bob", AmountArg: "4"},
+			Err:     "failed to TRANSFER: insufficient balance: 3 < 4",
+		},
+		{
+			Name: "zero amount",
+			Args: map[string]string{CmdArg: CmdTransfer, ToArg: "bob", AmountArg: "0"},
+			Err:  "failed to TRANSFER: amount must be positive",
+		},
+		{
+			Name:   "overflow",
+			Signer: alice,
+			Initial: map[string][]byte{
+				balanceKey(aliceID): []byte("1"),
+				balanceKey("bob"):   []byte("18446744073709551615"),
+			},
+			Args: map[string]string{CmdArg: CmdTransfer, ToArg: "bob", AmountArg: "1"},
+			Err:  "failed to TRANSFER: balance overflow",
+		},
+		{
+			Name: "faucet disabled",
+			Args: map[string]string{CmdArg: CmdFaucet},
+			Err:  "unknown command: FAUCET",
+		},
+	})
+}
+
+func TestContract_Faucet(t *testing.T) {
+	params := Faucet{Amount: 100, Interval: 10, Window: 5, MaxClaims: 1}
+
+	alice := bls.Generate()
+	aliceID := textOf(t, alice.GetPublicKey())
+
+	sdktest.Run(t, NewContract(WithFaucet(params)), []sdktest.TestCase{
+		{
+			Name:   "claim for the author",
+			Signer: alice,
+			Height: 3,
+			Args:   map[string]string{CmdArg: CmdFaucet},
+			Expected: map[string][]byte{
+				balanceKey(aliceID): []byte("100"),
+				string(sdk.NewKey(claimPrefix, []byte(aliceID))): []byte("3"),
+				string(sdk.NewKey(windowPrefix)):                 []byte(`{"Start":3,"Claims":1}`),
+			},
+			Events: []string{"claim"},
+		},
+		{
+			Name:    "claim for another identity",
+			Initial: map[string][]byte{balanceKey("bob"): []byte("1")},
+			Args:    map[string]string{CmdArg: CmdFaucet, ToArg: "bob"},
+			Expected: map[string][]byte{
+				balanceKey("bob"): []byte("101"),
+			},
+		},
+		{
+			Name:   "too early",
+			Height: 12,
+			Initial: map[string][]byte{
+				string(sdk.NewKey(claimPrefix, []byte("bob"))): []byte("3"),
+			},
+			Args: map[string]string{CmdArg: CmdFaucet, ToArg: "bob"},
+			Err:  "failed to FAUCET: identity 'bob' cannot claim before block 13",
+		},
+		{
+			Name:   "exhausted",
+			Height: 7,
+			Initial: map[string][]byte{
+				string(sdk.NewKey(windowPrefix)): []byte(`{"Start":3,"Claims":1}`),
+			},
+			Args: map[string]string{CmdArg: CmdFaucet, ToArg: "bob"},
+			Err:  "failed to FAUCET: faucet exhausted until block 8",
+		},
+		{
+			Name:   "new window",
+			Height: 8,
+			Initial: map[string][]byte{
+				string(sdk.NewKey(windowPrefix)): []byte(`{"Start":3,"Claims":1}`),
+			},
+			Args: map[string]string{CmdArg: CmdFaucet, ToArg: "bob"},
+			Expected: map[string][]byte{
+				string(sdk.NewKey(windowPrefix)): []byte(`{"Start":8,"Claims":1}`),
+			},
+		},
+		{
+			Name: "bad state",
+			Initial: map[string][]byte{
+				balanceKey("bob"): []byte("{"),
+			},
+			Args: map[string]string{CmdArg: CmdFaucet, ToArg: "bob"},
+			Err: "failed to FAUCET: failed to read balance: failed to decode key '" +
+				balanceKeyHex("bob") + "': unexpected end of JSON input",
+		},
+	})
+}
+
+func TestRegisterContract(t *testing.T) {
+	exec := native.NewExecution()
+
+	RegisterContract(exec, NewContract())
+
+	require.NoError(t, exec.IsServed(ContractName))
+}
+
+func TestBalanceOf(t *testing.T) {
+	snap := fake.NewSnapshot()
+
+	balance, err := BalanceOf(snap, []byte("bob"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), balance)
+
+	snap.Set([]byte(balanceKey("bob")), []byte("5"))
+
+	balance, err = BalanceOf(snap, []byte("bob"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), balance)
+
+	_, err = BalanceOf(fake.NewBadSnapshot(), []byte("bob"))
+	require.Error(t, err)
+}
+
+func TestDebit(t *testing.T) {
+	snap := fake.NewSnapshot()
+	snap.Set([]byte(balanceKey("bob")), []byte("5"))
+
+	require.NoError(t, Debit(snap, []byte("bob"), 3))
+
+	balance, err := BalanceOf(snap, []byte("bob"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), balance)
+
+	err = Debit(snap, []byte("bob"), 3)
+	require.EqualError(t, err, "insufficient balance: 2 < 3")
+
+	err = Debit(fake.NewBadSnapshot(), []byte("bob"), 3)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read balance: ")
+}
+
+func TestCredit(t *testing.T) {
+	snap := fake.NewSnapshot()
+
+	require.NoError(t, Credit(snap, []byte("bob"), 3))
+
+	balance, err := BalanceOf(snap, []byte("bob"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), balance)
+
+	err = Credit(snap, []byte("bob"), ^uint64(0))
+	require.EqualError(t, err, "balance overflow")
+
+	err = Credit(fake.NewBadSnapshot(), []byte("bob"), 3)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read balance: ")
+}
+
+func TestCheckClaim(t *testing.T) {
+	params := Faucet{Interval: 10, Window: 5, MaxClaims: 2}
+
+	snap := fake.NewSnapshot()
+	require.NoError(t, CheckClaim(snap, params, []byte("bob"), 0))
+
+	snap.Set(sdk.NewKey(claimPrefix, []byte("bob")), []byte("2"))
+	require.EqualError(t, CheckClaim(snap, params, []byte("bob"), 11),
+		"identity 'bob' cannot claim before block 12")
+	require.NoError(t, CheckClaim(snap, params, []byte("bob"), 12))
+
+	snap.Set(sdk.NewKey(windowPrefix), []byte(`{"Start":10,"Claims":2}`))
+	require.EqualError(t, CheckClaim(snap, params, []byte("bob"), 14),
+		"faucet exhausted until block 15")
+	require.NoError(t, CheckClaim(snap, params, []byte("bob"), 15))
+
+	err := CheckClaim(fake.NewBadSnapshot(), params, []byte("bob"), 0)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read claim: ")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func textOf(t *testing.T, pk interface{ MarshalText() ([]byte, error) }) string {
+	text, err := pk.MarshalText()
+	require.NoError(t, err)
+
+	return string(text)
+}
+
+func balanceKey(identity string) string {
+	return string(sdk.NewKey(balancePrefix, []byte(identity)))
+}
+
+func balanceKeyHex(identity string) string {
+	return fmt.Sprintf("%x", sdk.NewKey(balancePrefix, []byte(identity)))
+}
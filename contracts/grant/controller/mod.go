@@ -0,0 +1,121 @@
+// Package controller implements a CLI controller to verify the grants of the
+// off-chain services with a proof of the chain.
+package controller
+
+import (
+	"fmt"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/contracts/grant"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/cosi"
+	"golang.org/x/xerrors"
+)
+
+// miniController is a CLI initializer with the command to verify a grant. The
+// contract itself is registered by the ordering controller.
+//
+// - implements node.Initializer
+type miniController struct{}
+
+// NewController creates a new minimal controller for the grant contract.
+func NewController() node.Initializer {
+	return miniController{}
+}
+
+// SetCommands implements node.Initializer. It sets the command to verify a
+// grant.
+func (miniController) SetCommands(builder node.Builder) {
+	cmd := builder.SetCommand("grant")
+	cmd.SetDescription("capabilities of the off-chain services")
+
+	sub := cmd.SetSubCommand("verify")
+	sub.SetDescription("verify the proof of a grant as an off-chain service would")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name:     "service",
+			Usage:    "the name of the service",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     "scope",
+			Usage:    "the scope of the service",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     "subject",
+			Usage:    "the user of the service",
+			Required: true,
+		},
+	)
+	sub.SetAction(builder.MakeAction(verifyAction{}))
+}
+
+// OnStart implements node.Initializer.
+func (miniController) OnStart(flags cli.Flags, inj node.Injector) error {
+	return nil
+}
+
+// OnStop implements node.Initializer.
+func (miniController) OnStop(inj node.Injector) error {
+	return nil
+}
+
+// verifyAction is an action to verify a grant with the proof of the latest
+// block.
+//
+// - implements node.ActionTemplate
+type verifyAction struct{}
+
+// Execute implements node.ActionTemplate. It prints the grant if the proof is
+// valid and shows that the grant is not expired.
+func (verifyAction) Execute(ctx node.Context) error {
+	var srvc ordering.Service
+	err := ctx.Injector.Resolve(&srvc)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var genesis blockstore.GenesisStore
+	err = ctx.Injector.Resolve(&genesis)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var c cosi.CollectiveSigning
+	err = ctx.Injector.Resolve(&c)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	service := ctx.Flags.String("service")
+	scope := ctx.Flags.String("scope")
+	subject := ctx.Flags.String("subject")
+
+	proof, err := srvc.GetProof(grant.MakeKey(service, scope, subject))
+	if err != nil {
+		return xerrors.Errorf("failed to get proof: %v", err)
+	}
+
+	p, ok := proof.(grant.Proof)
+	if !ok {
+		return xerrors.Errorf("unsupported proof '%T'", proof)
+	}
+
+	gen, err := genesis.Get()
+	if err != nil {
+		return xerrors.Errorf("failed to read genesis: %v", err)
+	}
+
+	g, err := grant.Verify(p, gen, c.GetVerifierFactory(), service, scope, subject)
+	if err != nil {
+		return xerrors.Errorf("failed to verify: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "%s is granted %s/%s by %s until block %d\n",
+		g.Subject, g.Service, g.Scope, g.Issuer, g.Expiry)
+
+	return nil
+}
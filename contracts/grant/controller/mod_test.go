@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/contracts/grant"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestMiniController_SetCommands(t *testing.T) {
+	ctrl := NewController()
+
+	ctrl.SetCommands(node.NewBuilder())
+}
+
+func TestMiniController_OnStart(t *testing.T) {
+	err := NewController().OnStart(node.FlagSet{}, node.NewInjector())
+	require.NoError(t, err)
+}
+
+func TestMiniController_OnStop(t *testing.T) {
+	err := NewController().OnStop(nil)
+	require.NoError(t, err)
+}
+
+func TestVerifyAction_Execute(t *testing.T) {
+	out := new(bytes.Buffer)
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Flags:    node.FlagSet{"service": "api", "scope": "read", "subject": "alice"},
+		Out:      out,
+	}
+
+	err := verifyAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'ordering.Service'")
+
+	srvc := &fakeService{err: fake.GetError()}
+	ctx.Injector.Inject(srvc)
+
+	err = verifyAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'blockstore.GenesisStore'")
+
+	genesis := blockstore.NewGenesisStore()
+	ctx.Injector.Inject(genesis)
+
+	err = verifyAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'cosi.CollectiveSigning'")
+
+	ctx.Injector.Inject(fakeCosi{})
+
+	err = verifyAction{}.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to get proof"))
+
+	srvc.err = nil
+	srvc.proof = fakeOrderingProof{}
+
+	err = verifyAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"unsupported proof 'controller.fakeOrderingProof'")
+
+	srvc.proof = makeProof(t, 1, grant.Grant{
+		Service: "api",
+		Scope:   "read",
+		Subject: "alice",
+		Expiry:  2,
+		Issuer:  []byte("bls:aa"),
+	})
+
+	err = verifyAction{}.Execute(ctx)
+	require.EqualError(t, err, "failed to read genesis: missing genesis block")
+
+	gen, err := types.NewGenesis(authority.FromAuthority(fake.NewAuthority(1, fake.NewSigner)))
+	require.NoError(t, err)
+	require.NoError(t, genesis.Set(gen))
+
+	err = verifyAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "alice is granted api/read by bls:aa until block 2\n", out.String())
+
+	srvc.proof = makeProof(t, 2, grant.Grant{Service: "api", Scope: "read",
+		Subject: "alice", Expiry: 2})
+
+	err = verifyAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"failed to verify: grant of 'alice' on 'api/read' expired at block 2")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeService struct {
+	ordering.Service
+
+	proof ordering.Proof
+	err   error
+}
+
+func (s *fakeService) GetProof(key []byte) (ordering.Proof, error) {
+	return s.proof, s.err
+}
+
+type fakeCosi struct {
+	cosi.CollectiveSigning
+}
+
+func (fakeCosi) GetVerifierFactory() crypto.VerifierFactory {
+	return fake.NewVerifierFactory(fake.Verifier{})
+}
+
+type fakeOrderingProof struct {
+	ordering.Proof
+}
+
+func makeProof(t *testing.T, index uint64, g grant.Grant) fakeProof {
+	block, err := types.NewBlock(simple.NewResult(nil), types.WithIndex(index))
+	require.NoError(t, err)
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+
+	return fakeProof{
+		key:   grant.MakeKey(g.Service, g.Scope, g.Subject),
+		value: data,
+		block: block,
+	}
+}
+
+type fakeProof struct {
+	key   []byte
+	value []byte
+	block types.Block
+}
+
+func (p fakeProof) GetKey() []byte {
+	return p.key
+}
+
+func (p fakeProof) GetValue() []byte {
+	return p.value
+}
+
+func (p fakeProof) GetBlock() types.Block {
+	return p.block
+}
+
+func (p fakeProof) Verify(types.Genesis, crypto.VerifierFactory) error {
+	return nil
+}
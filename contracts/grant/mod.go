@@ -0,0 +1,298 @@
+// Package grant implements a native contract that records the capabilities
+// given to the users of off-chain services.
+//
+// A grant allows a subject to use a scope of a service until a block index.
+// Only the identities authorized by the access control service can issue or
+// revoke grants. An off-chain service does not need to trust a node to read a
+// grant: it asks for the proof of the key of the grant and verifies it against
+// the genesis, which checks the collective signatures of the chain and the
+// Merkle root of the latest block.
+//
+// As the expiry is checked against the block of the proof, a service should
+// also refuse proofs of blocks that are too old, otherwise a revoked grant can
+// still be proven with an earlier block.
+package grant
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"strconv"
+
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/crypto"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// ContractName is the name of the contract.
+	ContractName = "go.dedis.ch/dela.Grant"
+
+	// CmdArg is the argument's name to indicate the kind of command we want to
+	// run on the contract. Should be one of the Command type.
+	CmdArg = "grant:command"
+
+	// ServiceArg is the argument's name in the transaction that contains the
+	// name of the off-chain service.
+	ServiceArg = "grant:service"
+
+	// ScopeArg is the argument's name in the transaction that contains the
+	// scope of the service that is granted.
+	ScopeArg = "grant:scope"
+
+	// SubjectArg is the argument's name in the transaction that contains the
+	// identifier of the user of the service receiving the grant.
+	SubjectArg = "grant:subject"
+
+	// ExpiryArg is the argument's name in the transaction that contains the
+	// index of the block from which the grant is not valid anymore.
+	ExpiryArg = "grant:expiry"
+
+	// credentialAllCommand defines the credential command that is allowed to
+	// perform all commands.
+	credentialAllCommand = "all"
+
+	keyPrefix = "grant:"
+)
+
+// Command defines a type of command for the grant contract.
+type Command string
+
+const (
+	// CmdIssue defines the command to issue or renew a grant.
+	CmdIssue Command = "ISSUE"
+
+	// CmdRevoke defines the command to remove a grant before it expires.
+	CmdRevoke Command = "REVOKE"
+)
+
+// Grant is the entry stored for a capability given to a subject.
+type Grant struct {
+	Service string
+	Scope   string
+	Subject string
+	Expiry  uint64
+	Issuer  []byte
+}
+
+// Expired returns true if the grant is not valid anymore at the given index.
+func (g Grant) Expired(index uint64) bool {
+	return index >= g.Expiry
+}
+
+// Proof is the proof of a key of the state at the latest block of a chain.
+type Proof interface {
+	// GetKey returns the key of the proof.
+	GetKey() []byte
+
+	// GetValue returns the value of the key, or nil if it doesn't exist.
+	GetValue() []byte
+
+	// GetBlock returns the block the proof is made for.
+	GetBlock() types.Block
+
+	// Verify verifies the chain from the genesis and the Merkle root of the
+	// block.
+	Verify(genesis types.Genesis, fac crypto.VerifierFactory) error
+}
+
+// NewCreds creates new credentials for a grant contract execution.
+func NewCreds(id []byte) access.Credential {
+	return access.NewContractCreds(id, ContractName, credentialAllCommand)
+}
+
+// RegisterContract registers the grant contract to the given execution
+// service alongside the schema of its arguments.
+func RegisterContract(exec *native.Service, c Contract) {
+	exec.Set(ContractName, c)
+	exec.SetSchema(ContractName, NewSchema())
+}
+
+// NewSchema returns the schema of the arguments expected by each command of
+// the grant contract.
+func NewSchema() native.Schema {
+	service := native.Arg{Name: ServiceArg, Required: true}
+	scope := native.Arg{Name: ScopeArg, Required: true}
+	subject := native.Arg{Name: SubjectArg, Required: true}
+	expiry := native.Arg{Name: ExpiryArg, Required: true}
+
+	return native.NewSwitchSchema(CmdArg, map[string]native.Schema{
+		string(CmdIssue):  native.NewArgSchema(service, scope, subject, expiry),
+		string(CmdRevoke): native.NewArgSchema(service, scope, subject),
+	})
+}
+
+// Contract is the grant contract. The identities allowed to issue grants are
+// defined by the access control service.
+//
+// - implements native.Contract
+type Contract struct {
+	// access is the access control service managing this smart contract
+	access access.Service
+
+	// accessKey is the access identifier allowed to use this smart contract
+	accessKey []byte
+}
+
+// NewContract creates a new grant contract.
+func NewContract(aKey []byte, srvc access.Service) Contract {
+	return Contract{
+		access:    srvc,
+		accessKey: aKey,
+	}
+}
+
+// Execute implements native.Contract. It runs the appropriate command.
+func (c Contract) Execute(snap store.Snapshot, step execution.Step) error {
+	err := c.access.Match(snap, NewCreds(c.accessKey), step.Current.GetIdentity())
+	if err != nil {
+		return xerrors.Errorf("identity not authorized: %v (%v)",
+			step.Current.GetIdentity(), err)
+	}
+
+	cmd := step.Current.GetArg(CmdArg)
+	if len(cmd) == 0 {
+		return xerrors.Errorf("'%s' not found in tx arg", CmdArg)
+	}
+
+	service := string(step.Current.GetArg(ServiceArg))
+	scope := string(step.Current.GetArg(ScopeArg))
+	subject := string(step.Current.GetArg(SubjectArg))
+
+	if service == "" || scope == "" || subject == "" {
+		return xerrors.Errorf("'%s', '%s' and '%s' must not be empty",
+			ServiceArg, ScopeArg, SubjectArg)
+	}
+
+	key := MakeKey(service, scope, subject)
+
+	switch Command(cmd) {
+	case CmdIssue:
+		expiry, err := strconv.ParseUint(string(step.Current.GetArg(ExpiryArg)), 10, 64)
+		if err != nil {
+			return xerrors.Errorf("failed to ISSUE: invalid expiry: %v", err)
+		}
+
+		if expiry <= step.Index {
+			return xerrors.Errorf("failed to ISSUE: expiry %d is not after block %d",
+				expiry, step.Index)
+		}
+
+		issuer, err := step.Current.GetIdentity().MarshalText()
+		if err != nil {
+			return xerrors.Errorf("failed to marshal identity: %v", err)
+		}
+
+		grant := Grant{
+			Service: service,
+			Scope:   scope,
+			Subject: subject,
+			Expiry:  expiry,
+			Issuer:  issuer,
+		}
+
+		data, err := json.Marshal(grant)
+		if err != nil {
+			return xerrors.Errorf("failed to encode grant: %v", err)
+		}
+
+		err = snap.Set(key, data)
+		if err != nil {
+			return xerrors.Errorf("failed to write grant: %v", err)
+		}
+	case CmdRevoke:
+		data, err := snap.Get(key)
+		if err != nil {
+			return xerrors.Errorf("failed to read grant: %v", err)
+		}
+
+		if len(data) == 0 {
+			return xerrors.Errorf("grant of '%s' on '%s/%s' not found", subject, service, scope)
+		}
+
+		err = snap.Delete(key)
+		if err != nil {
+			return xerrors.Errorf("failed to delete grant: %v", err)
+		}
+	default:
+		return xerrors.Errorf("unknown command: %s", cmd)
+	}
+
+	return nil
+}
+
+// MakeKey returns the key of the state where the grant of the scope of the
+// service to the subject is stored. This is the key an off-chain service asks
+// the proof of.
+func MakeKey(service, scope, subject string) []byte {
+	h := sha256.New()
+
+	// The lengths are written so that the fields can't be shifted from one to
+	// another.
+	for _, field := range []string{service, scope, subject} {
+		h.Write([]byte(keyPrefix))
+		h.Write([]byte(strconv.Itoa(len(field))))
+		h.Write([]byte(field))
+	}
+
+	return h.Sum(nil)
+}
+
+// Lookup returns the grant of the scope of the service to the subject if it
+// exists and is not expired at the index.
+func Lookup(snap store.Readable, service, scope, subject string, index uint64) (Grant, error) {
+	data, err := snap.Get(MakeKey(service, scope, subject))
+	if err != nil {
+		return Grant{}, xerrors.Errorf("failed to read grant: %v", err)
+	}
+
+	return decode(data, service, scope, subject, index)
+}
+
+// Verify verifies the proof with the genesis of the chain and returns the
+// grant of the scope of the service to the subject if the proof shows it
+// exists and is not expired at the block of the proof.
+func Verify(proof Proof, genesis types.Genesis, fac crypto.VerifierFactory,
+	service, scope, subject string) (Grant, error) {
+
+	err := proof.Verify(genesis, fac)
+	if err != nil {
+		return Grant{}, xerrors.Errorf("invalid proof: %v", err)
+	}
+
+	if !bytes.Equal(proof.GetKey(), MakeKey(service, scope, subject)) {
+		return Grant{}, xerrors.Errorf("proof of key %#x is not the grant of '%s' on '%s/%s'",
+			proof.GetKey(), subject, service, scope)
+	}
+
+	return decode(proof.GetValue(), service, scope, subject, proof.GetBlock().GetIndex())
+}
+
+func decode(data []byte, service, scope, subject string, index uint64) (Grant, error) {
+	if len(data) == 0 {
+		return Grant{}, xerrors.Errorf("grant of '%s' on '%s/%s' not found", subject, service, scope)
+	}
+
+	var grant Grant
+
+	err := json.Unmarshal(data, &grant)
+	if err != nil {
+		return Grant{}, xerrors.Errorf("failed to decode: %v", err)
+	}
+
+	if grant.Service != service || grant.Scope != scope || grant.Subject != subject {
+		return Grant{}, xerrors.Errorf("mismatching grant of '%s' on '%s/%s'",
+			grant.Subject, grant.Service, grant.Scope)
+	}
+
+	if grant.Expired(index) {
+		return Grant{}, xerrors.Errorf("grant of '%s' on '%s/%s' expired at block %d",
+			subject, service, scope, grant.Expiry)
+	}
+
+	return grant, nil
+}
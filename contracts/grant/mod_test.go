@@ -0,0 +1,270 @@
+package grant
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestRegisterContract(t *testing.T) {
+	exec := native.NewExecution()
+
+	RegisterContract(exec, NewContract([]byte{}, fakeAccess{}))
+}
+
+func TestNewSchema(t *testing.T) {
+	schema := NewSchema()
+
+	err := schema.Validate(makeTx(t, CmdArg, "REVOKE", ServiceArg, "api",
+		ScopeArg, "read", SubjectArg, "alice"))
+	require.NoError(t, err)
+
+	err = schema.Validate(makeTx(t, CmdArg, "ISSUE", ServiceArg, "api",
+		ScopeArg, "read", SubjectArg, "alice"))
+	require.Error(t, err)
+}
+
+func TestContract_Issue(t *testing.T) {
+	snap := fake.NewSnapshot()
+	contract := NewContract([]byte{}, fakeAccess{})
+
+	err := contract.Execute(snap, makeStep(t, 0, CmdArg, "ISSUE", ServiceArg, "api",
+		ScopeArg, "read", SubjectArg, "alice", ExpiryArg, "10"))
+	require.NoError(t, err)
+
+	grant, err := Lookup(snap, "api", "read", "alice", 9)
+	require.NoError(t, err)
+	require.Equal(t, "api", grant.Service)
+	require.Equal(t, "read", grant.Scope)
+	require.Equal(t, "alice", grant.Subject)
+	require.Equal(t, uint64(10), grant.Expiry)
+	require.Equal(t, []byte("PK"), grant.Issuer)
+
+	_, err = Lookup(snap, "api", "read", "alice", 10)
+	require.EqualError(t, err, "grant of 'alice' on 'api/read' expired at block 10")
+
+	_, err = Lookup(snap, "api", "write", "alice", 0)
+	require.EqualError(t, err, "grant of 'alice' on 'api/write' not found")
+
+	// Issuing the grant again renews it.
+	err = contract.Execute(snap, makeStep(t, 9, CmdArg, "ISSUE", ServiceArg, "api",
+		ScopeArg, "read", SubjectArg, "alice", ExpiryArg, "20"))
+	require.NoError(t, err)
+
+	_, err = Lookup(snap, "api", "read", "alice", 10)
+	require.NoError(t, err)
+
+	err = contract.Execute(snap, makeStep(t, 20, CmdArg, "ISSUE", ServiceArg, "api",
+		ScopeArg, "read", SubjectArg, "alice", ExpiryArg, "20"))
+	require.EqualError(t, err, "failed to ISSUE: expiry 20 is not after block 20")
+
+	err = contract.Execute(snap, makeStep(t, 0, CmdArg, "ISSUE", ServiceArg, "api",
+		ScopeArg, "read", SubjectArg, "alice", ExpiryArg, "abc"))
+	require.EqualError(t, err, "failed to ISSUE: invalid expiry: "+
+		"strconv.ParseUint: parsing \"abc\": invalid syntax")
+
+	err = contract.Execute(fake.NewBadSnapshot(), makeStep(t, 0, CmdArg, "ISSUE",
+		ServiceArg, "api", ScopeArg, "read", SubjectArg, "alice", ExpiryArg, "10"))
+	require.EqualError(t, err, fake.Err("failed to write grant"))
+
+	_, err = Lookup(fake.NewBadSnapshot(), "api", "read", "alice", 0)
+	require.EqualError(t, err, fake.Err("failed to read grant"))
+}
+
+func TestContract_Revoke(t *testing.T) {
+	snap := fake.NewSnapshot()
+	contract := NewContract([]byte{}, fakeAccess{})
+
+	err := contract.Execute(snap, makeStep(t, 0, CmdArg, "REVOKE", ServiceArg, "api",
+		ScopeArg, "read", SubjectArg, "alice"))
+	require.EqualError(t, err, "grant of 'alice' on 'api/read' not found")
+
+	err = contract.Execute(snap, makeStep(t, 0, CmdArg, "ISSUE", ServiceArg, "api",
+		ScopeArg, "read", SubjectArg, "alice", ExpiryArg, "10"))
+	require.NoError(t, err)
+
+	err = contract.Execute(snap, makeStep(t, 1, CmdArg, "REVOKE", ServiceArg, "api",
+		ScopeArg, "read", SubjectArg, "alice"))
+	require.NoError(t, err)
+
+	_, err = Lookup(snap, "api", "read", "alice", 1)
+	require.EqualError(t, err, "grant of 'alice' on 'api/read' not found")
+
+	err = contract.Execute(fake.NewBadSnapshot(), makeStep(t, 0, CmdArg, "REVOKE",
+		ServiceArg, "api", ScopeArg, "read", SubjectArg, "alice"))
+	require.EqualError(t, err, fake.Err("failed to read grant"))
+
+	snap = fake.NewSnapshot(fake.WithValues(map[string][]byte{
+		string(MakeKey("api", "read", "alice")): []byte("{}"),
+	}))
+	snap.ErrDelete = fake.GetError()
+
+	err = contract.Execute(snap, makeStep(t, 0, CmdArg, "REVOKE", ServiceArg, "api",
+		ScopeArg, "read", SubjectArg, "alice"))
+	require.EqualError(t, err, fake.Err("failed to delete grant"))
+}
+
+func TestContract_Execute(t *testing.T) {
+	contract := NewContract([]byte{}, fakeAccess{err: fake.GetError()})
+
+	err := contract.Execute(fake.NewSnapshot(), makeStep(t, 0))
+	require.EqualError(t, err,
+		"identity not authorized: fake.PublicKey ("+fake.GetError().Error()+")")
+
+	contract = NewContract([]byte{}, fakeAccess{})
+
+	err = contract.Execute(fake.NewSnapshot(), makeStep(t, 0))
+	require.EqualError(t, err, "'grant:command' not found in tx arg")
+
+	err = contract.Execute(fake.NewSnapshot(), makeStep(t, 0, CmdArg, "ISSUE",
+		ServiceArg, "api", ScopeArg, "read"))
+	require.EqualError(t, err,
+		"'grant:service', 'grant:scope' and 'grant:subject' must not be empty")
+
+	err = contract.Execute(fake.NewSnapshot(), makeStep(t, 0, CmdArg, "UNKNOWN",
+		ServiceArg, "api", ScopeArg, "read", SubjectArg, "alice"))
+	require.EqualError(t, err, "unknown command: UNKNOWN")
+
+	step := execution.Step{Current: fakeTx{}}
+
+	err = contract.Execute(fake.NewSnapshot(), step)
+	require.EqualError(t, err, fake.Err("failed to marshal identity"))
+}
+
+func TestMakeKey(t *testing.T) {
+	require.Len(t, MakeKey("api", "read", "alice"), 32)
+	require.Equal(t, MakeKey("api", "read", "alice"), MakeKey("api", "read", "alice"))
+	require.NotEqual(t, MakeKey("api", "read", "alice"), MakeKey("api", "rea", "dalice"))
+	require.NotEqual(t, MakeKey("api", "read", "alice"), MakeKey("api", "read", "bob"))
+}
+
+func TestVerify(t *testing.T) {
+	proof := makeProof(t, 5, Grant{Service: "api", Scope: "read", Subject: "alice", Expiry: 10})
+
+	grant, err := Verify(proof, types.Genesis{}, fake.VerifierFactory{}, "api", "read", "alice")
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), grant.Expiry)
+
+	_, err = Verify(proof, types.Genesis{}, fake.VerifierFactory{}, "api", "write", "alice")
+	require.Error(t, err)
+	require.Regexp(t, "^proof of key 0x[0-9a-f]+ is not the grant of 'alice' on 'api/write'$",
+		err.Error())
+
+	proof.err = fake.GetError()
+	_, err = Verify(proof, types.Genesis{}, fake.VerifierFactory{}, "api", "read", "alice")
+	require.EqualError(t, err, fake.Err("invalid proof"))
+
+	proof = makeProof(t, 10, Grant{Service: "api", Scope: "read", Subject: "alice", Expiry: 10})
+	_, err = Verify(proof, types.Genesis{}, fake.VerifierFactory{}, "api", "read", "alice")
+	require.EqualError(t, err, "grant of 'alice' on 'api/read' expired at block 10")
+
+	// A grant stored under the key of another one is refused.
+	proof = makeProof(t, 0, Grant{Service: "api", Scope: "write", Subject: "alice", Expiry: 10})
+	proof.key = MakeKey("api", "read", "alice")
+	_, err = Verify(proof, types.Genesis{}, fake.VerifierFactory{}, "api", "read", "alice")
+	require.EqualError(t, err, "mismatching grant of 'alice' on 'api/write'")
+
+	proof.value = nil
+	_, err = Verify(proof, types.Genesis{}, fake.VerifierFactory{}, "api", "read", "alice")
+	require.EqualError(t, err, "grant of 'alice' on 'api/read' not found")
+
+	proof.value = []byte("{")
+	_, err = Verify(proof, types.Genesis{}, fake.VerifierFactory{}, "api", "read", "alice")
+	require.EqualError(t, err, "failed to decode: unexpected end of JSON input")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeStep(t *testing.T, index uint64, args ...string) execution.Step {
+	return execution.Step{Current: makeTx(t, args...), Index: index}
+}
+
+func makeTx(t *testing.T, args ...string) txn.Transaction {
+	options := []signed.TransactionOption{}
+	for i := 0; i < len(args)-1; i += 2 {
+		options = append(options, signed.WithArg(args[i], []byte(args[i+1])))
+	}
+
+	tx, err := signed.NewTransaction(0, fake.PublicKey{}, options...)
+	require.NoError(t, err)
+
+	return tx
+}
+
+func makeProof(t *testing.T, index uint64, grant Grant) fakeProof {
+	block, err := types.NewBlock(simple.NewResult(nil), types.WithIndex(index))
+	require.NoError(t, err)
+
+	data, err := json.Marshal(grant)
+	require.NoError(t, err)
+
+	return fakeProof{
+		key:   MakeKey(grant.Service, grant.Scope, grant.Subject),
+		value: data,
+		block: block,
+	}
+}
+
+type fakeProof struct {
+	key   []byte
+	value []byte
+	block types.Block
+	err   error
+}
+
+func (p fakeProof) GetKey() []byte {
+	return p.key
+}
+
+func (p fakeProof) GetValue() []byte {
+	return p.value
+}
+
+func (p fakeProof) GetBlock() types.Block {
+	return p.block
+}
+
+func (p fakeProof) Verify(types.Genesis, crypto.VerifierFactory) error {
+	return p.err
+}
+
+type fakeAccess struct {
+	access.Service
+
+	err error
+}
+
+func (srvc fakeAccess) Match(store.Readable, access.Credential, ...access.Identity) error {
+	return srvc.err
+}
+
+type fakeTx struct {
+	txn.Transaction
+}
+
+func (fakeTx) GetIdentity() access.Identity {
+	return fake.NewBadPublicKey()
+}
+
+func (fakeTx) GetArg(key string) []byte {
+	switch key {
+	case CmdArg:
+		return []byte(CmdIssue)
+	case ExpiryArg:
+		return []byte("10")
+	default:
+		return []byte("a")
+	}
+}
@@ -0,0 +1,93 @@
+// Package controller implements a CLI controller to register the naming
+// contract and to resolve names.
+package controller
+
+import (
+	"fmt"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/contracts/naming"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"golang.org/x/xerrors"
+)
+
+// miniController is a CLI initializer to register the naming contract.
+//
+// - implements node.Initializer
+type miniController struct{}
+
+// NewController creates a new minimal controller for the naming contract.
+func NewController() node.Initializer {
+	return miniController{}
+}
+
+// SetCommands implements node.Initializer. It sets the command to resolve a
+// name.
+func (miniController) SetCommands(builder node.Builder) {
+	cmd := builder.SetCommand("naming")
+	cmd.SetDescription("human-readable names")
+
+	sub := cmd.SetSubCommand("resolve")
+	sub.SetDescription("print the target of a name")
+	sub.SetFlags(cli.StringFlag{
+		Name:  "name",
+		Usage: "the name to resolve",
+	})
+	sub.SetAction(builder.MakeAction(resolveAction{}))
+}
+
+// OnStart implements node.Initializer. It registers the naming contract.
+func (miniController) OnStart(flags cli.Flags, inj node.Injector) error {
+	var exec *native.Service
+	err := inj.Resolve(&exec)
+	if err != nil {
+		return xerrors.Errorf("failed to resolve native service: %v", err)
+	}
+
+	naming.RegisterContract(exec, naming.NewContract())
+
+	return nil
+}
+
+// OnStop implements node.Initializer.
+func (miniController) OnStop(inj node.Injector) error {
+	return nil
+}
+
+// resolveAction is an action to resolve a name against the latest state, at the
+// index of the latest block.
+//
+// - implements node.ActionTemplate
+type resolveAction struct{}
+
+// Execute implements node.ActionTemplate. It prints the target of the name.
+func (resolveAction) Execute(ctx node.Context) error {
+	var srvc ordering.Service
+	err := ctx.Injector.Resolve(&srvc)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var blocks blockstore.BlockStore
+	err = ctx.Injector.Resolve(&blocks)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	height := uint64(0)
+	if blocks.Len() > 0 {
+		height = blocks.Len() - 1
+	}
+
+	target, err := naming.Resolve(srvc.GetStore(), ctx.Flags.String("name"), height)
+	if err != nil {
+		return xerrors.Errorf("failed to resolve: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "%s", target)
+
+	return nil
+}
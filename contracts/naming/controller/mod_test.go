@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/contracts/naming"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestMiniController_SetCommands(t *testing.T) {
+	ctrl := NewController()
+
+	ctrl.SetCommands(node.NewBuilder())
+}
+
+func TestMiniController_OnStart(t *testing.T) {
+	ctrl := NewController()
+
+	injector := node.NewInjector()
+	err := ctrl.OnStart(node.FlagSet{}, injector)
+	require.EqualError(t, err,
+		"failed to resolve native service: couldn't find dependency for '*native.Service'")
+
+	injector.Inject(native.NewExecution())
+
+	err = ctrl.OnStart(node.FlagSet{}, injector)
+	require.NoError(t, err)
+}
+
+func TestMiniController_OnStop(t *testing.T) {
+	err := NewController().OnStop(nil)
+	require.NoError(t, err)
+}
+
+func TestResolveAction_Execute(t *testing.T) {
+	out := new(bytes.Buffer)
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Flags:    node.FlagSet{"name": "alice"},
+		Out:      out,
+	}
+
+	err := resolveAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'ordering.Service'")
+
+	snap := fake.NewSnapshot()
+	ctx.Injector.Inject(fakeService{snap: snap})
+
+	err = resolveAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'blockstore.BlockStore'")
+
+	ctx.Injector.Inject(blockstore.NewInMemory())
+
+	err = resolveAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"failed to resolve: couldn't resolve: name 'alice' is not registered")
+
+	tx, err := signed.NewTransaction(0, fake.PublicKey{},
+		signed.WithArg(naming.CmdArg, []byte(naming.CmdRegister)),
+		signed.WithArg(naming.NameArg, []byte("alice")),
+		signed.WithArg(naming.TargetArg, []byte("A")),
+		signed.WithArg(naming.DurationArg, []byte("1")))
+	require.NoError(t, err)
+
+	err = naming.NewContract().Execute(snap, execution.Step{Current: tx})
+	require.NoError(t, err)
+
+	err = resolveAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "A", out.String())
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeService struct {
+	ordering.Service
+
+	snap store.Snapshot
+}
+
+func (s fakeService) GetStore() store.Readable {
+	return s.snap
+}
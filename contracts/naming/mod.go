@@ -0,0 +1,296 @@
+// Package naming implements a native contract that maps human-readable names to
+// targets like identities, addresses or contract keys.
+//
+// A name is owned by the identity that registered it for a number of blocks.
+// The owner can update the target, transfer the ownership, renew the
+// registration or release the name. Once expired, anyone can register the name
+// again.
+package naming
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"regexp"
+	"strconv"
+
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// ContractName is the name of the contract.
+	ContractName = "go.dedis.ch/dela.Naming"
+
+	// CmdArg is the argument's name to indicate the kind of command we want to
+	// run on the contract. Should be one of the Command type.
+	CmdArg = "naming:command"
+
+	// NameArg is the argument's name in the transaction that contains the
+	// human-readable name.
+	NameArg = "naming:name"
+
+	// TargetArg is the argument's name in the transaction that contains the
+	// value the name resolves to.
+	TargetArg = "naming:target"
+
+	// OwnerArg is the argument's name in the transaction that contains the text
+	// form of the identity receiving the name.
+	OwnerArg = "naming:owner"
+
+	// DurationArg is the argument's name in the transaction that contains the
+	// number of blocks the registration lasts.
+	DurationArg = "naming:duration"
+
+	// MaxDuration is the maximum number of blocks a registration can last
+	// without being renewed.
+	MaxDuration = 1 << 20
+
+	keyPrefix = "naming:"
+)
+
+// Command defines a type of command for the naming contract.
+type Command string
+
+const (
+	// CmdRegister defines the command to register a free or expired name.
+	CmdRegister Command = "REGISTER"
+
+	// CmdUpdate defines the command to change the target of a name.
+	CmdUpdate Command = "UPDATE"
+
+	// CmdTransfer defines the command to give a name to another identity.
+	CmdTransfer Command = "TRANSFER"
+
+	// CmdRenew defines the command to extend the registration of a name.
+	CmdRenew Command = "RENEW"
+
+	// CmdRelease defines the command to free a name before it expires.
+	CmdRelease Command = "RELEASE"
+)
+
+// nameRegexp defines the accepted names: lower case letters, digits, dashes
+// and dots, starting with a letter or a digit.
+var nameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{0,62}$`)
+
+// Record is the entry stored for a name.
+type Record struct {
+	Name   string
+	Target []byte
+	Owner  []byte
+	Expiry uint64
+}
+
+// Expired returns true if the record is not valid anymore at the given height.
+func (r Record) Expired(height uint64) bool {
+	return height >= r.Expiry
+}
+
+// RegisterContract registers the naming contract to the given execution
+// service alongside the schema of its arguments.
+func RegisterContract(exec *native.Service, c Contract) {
+	exec.Set(ContractName, c)
+	exec.SetSchema(ContractName, NewSchema())
+}
+
+// NewSchema returns the schema of the arguments expected by each command of
+// the naming contract.
+func NewSchema() native.Schema {
+	name := native.Arg{Name: NameArg, Required: true}
+	target := native.Arg{Name: TargetArg, Required: true}
+	owner := native.Arg{Name: OwnerArg, Required: true}
+	duration := native.Arg{Name: DurationArg, Required: true}
+
+	return native.NewSwitchSchema(CmdArg, map[string]native.Schema{
+		string(CmdRegister): native.NewArgSchema(name, target, duration),
+		string(CmdUpdate):   native.NewArgSchema(name, target),
+		string(CmdTransfer): native.NewArgSchema(name, owner),
+		string(CmdRenew):    native.NewArgSchema(name, duration),
+		string(CmdRelease):  native.NewArgSchema(name),
+	})
+}
+
+// Contract is the naming contract. It does not rely on the access control
+// service as anyone can register a name, but only the owner of a name can
+// modify it.
+//
+// - implements native.Contract
+type Contract struct{}
+
+// NewContract creates a new naming contract.
+func NewContract() Contract {
+	return Contract{}
+}
+
+// Execute implements native.Contract. It runs the appropriate command.
+func (c Contract) Execute(snap store.Snapshot, step execution.Step) error {
+	cmd := step.Current.GetArg(CmdArg)
+	if len(cmd) == 0 {
+		return xerrors.Errorf("'%s' not found in tx arg", CmdArg)
+	}
+
+	name := string(step.Current.GetArg(NameArg))
+	if !nameRegexp.MatchString(name) {
+		return xerrors.Errorf("invalid name '%s'", name)
+	}
+
+	height := step.Index
+
+	caller, err := step.Current.GetIdentity().MarshalText()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal identity: %v", err)
+	}
+
+	record, found, err := readRecord(snap, name)
+	if err != nil {
+		return xerrors.Errorf("failed to read record: %v", err)
+	}
+
+	if found && record.Expired(height) {
+		found = false
+	}
+
+	if Command(cmd) == CmdRegister {
+		if found {
+			return xerrors.Errorf("name '%s' is already registered", name)
+		}
+
+		duration, err := parseDuration(step.Current.GetArg(DurationArg))
+		if err != nil {
+			return xerrors.Errorf("failed to REGISTER: %v", err)
+		}
+
+		record = Record{
+			Name:   name,
+			Target: step.Current.GetArg(TargetArg),
+			Owner:  caller,
+			Expiry: height + duration,
+		}
+
+		return writeRecord(snap, record)
+	}
+
+	if !found {
+		return xerrors.Errorf("name '%s' is not registered", name)
+	}
+
+	if !bytes.Equal(record.Owner, caller) {
+		return xerrors.Errorf("identity '%s' does not own '%s'", caller, name)
+	}
+
+	switch Command(cmd) {
+	case CmdUpdate:
+		record.Target = step.Current.GetArg(TargetArg)
+	case CmdTransfer:
+		owner := step.Current.GetArg(OwnerArg)
+		if len(owner) == 0 {
+			return xerrors.Errorf("'%s' not found in tx arg", OwnerArg)
+		}
+
+		record.Owner = owner
+	case CmdRenew:
+		duration, err := parseDuration(step.Current.GetArg(DurationArg))
+		if err != nil {
+			return xerrors.Errorf("failed to RENEW: %v", err)
+		}
+
+		if record.Expiry-height+duration > MaxDuration {
+			return xerrors.Errorf("failed to RENEW: registration would exceed %d blocks",
+				MaxDuration)
+		}
+
+		record.Expiry += duration
+	case CmdRelease:
+		err := snap.Delete(makeKey(name))
+		if err != nil {
+			return xerrors.Errorf("failed to delete record: %v", err)
+		}
+
+		return nil
+	default:
+		return xerrors.Errorf("unknown command: %s", cmd)
+	}
+
+	return writeRecord(snap, record)
+}
+
+// Lookup returns the record of the name if it is registered and not expired
+// at the height.
+func Lookup(snap store.Readable, name string, height uint64) (Record, error) {
+	record, found, err := readRecord(snap, name)
+	if err != nil {
+		return Record{}, xerrors.Errorf("failed to read record: %v", err)
+	}
+
+	if !found || record.Expired(height) {
+		return Record{}, xerrors.Errorf("name '%s' is not registered", name)
+	}
+
+	return record, nil
+}
+
+// Resolve returns the target of the name if it is registered and not expired
+// at the height.
+func Resolve(snap store.Readable, name string, height uint64) ([]byte, error) {
+	record, err := Lookup(snap, name, height)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't resolve: %v", err)
+	}
+
+	return record.Target, nil
+}
+
+func makeKey(name string) []byte {
+	h := sha256.Sum256([]byte(keyPrefix + name))
+
+	return h[:]
+}
+
+func readRecord(snap store.Readable, name string) (Record, bool, error) {
+	data, err := snap.Get(makeKey(name))
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	if len(data) == 0 {
+		return Record{}, false, nil
+	}
+
+	var record Record
+
+	err = json.Unmarshal(data, &record)
+	if err != nil {
+		return Record{}, false, xerrors.Errorf("failed to decode: %v", err)
+	}
+
+	return record, true, nil
+}
+
+func writeRecord(snap store.Snapshot, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return xerrors.Errorf("failed to encode record: %v", err)
+	}
+
+	err = snap.Set(makeKey(record.Name), data)
+	if err != nil {
+		return xerrors.Errorf("failed to write record: %v", err)
+	}
+
+	return nil
+}
+
+func parseDuration(arg []byte) (uint64, error) {
+	duration, err := strconv.ParseUint(string(arg), 10, 64)
+	if err != nil {
+		return 0, xerrors.Errorf("invalid duration: %v", err)
+	}
+
+	if duration == 0 || duration > MaxDuration {
+		return 0, xerrors.Errorf("duration must be between 1 and %d", MaxDuration)
+	}
+
+	return duration, nil
+}
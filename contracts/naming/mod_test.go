@@ -0,0 +1,225 @@
+package naming
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestContract_Register(t *testing.T) {
+	snap := fake.NewSnapshot()
+	contract := NewContract()
+
+	err := contract.Execute(snap, makeStep(t, fake.PublicKey{},
+		CmdArg, "REGISTER", NameArg, "alice", TargetArg, "A", DurationArg, "10"))
+	require.NoError(t, err)
+
+	target, err := Resolve(snap, "alice", 9)
+	require.NoError(t, err)
+	require.Equal(t, []byte("A"), target)
+
+	err = contract.Execute(snap, makeStep(t, bls.Generate().GetPublicKey(),
+		CmdArg, "REGISTER", NameArg, "alice", TargetArg, "B", DurationArg, "10"))
+	require.EqualError(t, err, "name 'alice' is already registered")
+
+	// Once expired, the name is free to be taken by another identity.
+	_, err = Resolve(snap, "alice", 10)
+	require.EqualError(t, err, "couldn't resolve: name 'alice' is not registered")
+
+	other := bls.Generate().GetPublicKey()
+
+	step := makeStep(t, other,
+		CmdArg, "REGISTER", NameArg, "alice", TargetArg, "B", DurationArg, "10")
+	step.Index = 10
+
+	err = contract.Execute(snap, step)
+	require.NoError(t, err)
+
+	record, err := Lookup(snap, "alice", 10)
+	require.NoError(t, err)
+	require.Equal(t, []byte("B"), record.Target)
+	require.Equal(t, uint64(20), record.Expiry)
+
+	text, err := other.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, text, record.Owner)
+}
+
+func TestContract_Owner(t *testing.T) {
+	snap := fake.NewSnapshot()
+	contract := NewContract()
+
+	err := contract.Execute(snap, makeStep(t, fake.PublicKey{},
+		CmdArg, "REGISTER", NameArg, "alice", TargetArg, "A", DurationArg, "10"))
+	require.NoError(t, err)
+
+	other := bls.Generate().GetPublicKey()
+
+	err = contract.Execute(snap, makeStep(t, other,
+		CmdArg, "UPDATE", NameArg, "alice", TargetArg, "B"))
+	require.Error(t, err)
+	require.Regexp(t, "^identity 'bls:[0-9a-f]+' does not own 'alice'$", err.Error())
+
+	err = contract.Execute(snap, makeStep(t, fake.PublicKey{},
+		CmdArg, "UPDATE", NameArg, "alice", TargetArg, "B"))
+	require.NoError(t, err)
+
+	text, err := other.MarshalText()
+	require.NoError(t, err)
+
+	err = contract.Execute(snap, makeStep(t, fake.PublicKey{},
+		CmdArg, "TRANSFER", NameArg, "alice"))
+	require.EqualError(t, err, "'naming:owner' not found in tx arg")
+
+	err = contract.Execute(snap, makeStep(t, fake.PublicKey{},
+		CmdArg, "TRANSFER", NameArg, "alice", OwnerArg, string(text)))
+	require.NoError(t, err)
+
+	err = contract.Execute(snap, makeStep(t, other,
+		CmdArg, "RENEW", NameArg, "alice", DurationArg, "5"))
+	require.NoError(t, err)
+
+	record, err := Lookup(snap, "alice", 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte("B"), record.Target)
+	require.Equal(t, text, record.Owner)
+	require.Equal(t, uint64(15), record.Expiry)
+
+	err = contract.Execute(snap, makeStep(t, other,
+		CmdArg, "RENEW", NameArg, "alice", DurationArg, "1048576"))
+	require.EqualError(t, err,
+		"failed to RENEW: registration would exceed 1048576 blocks")
+
+	err = contract.Execute(snap, makeStep(t, other,
+		CmdArg, "RELEASE", NameArg, "alice"))
+	require.NoError(t, err)
+
+	_, err = Lookup(snap, "alice", 0)
+	require.EqualError(t, err, "name 'alice' is not registered")
+
+	err = contract.Execute(snap, makeStep(t, other,
+		CmdArg, "RELEASE", NameArg, "alice"))
+	require.EqualError(t, err, "name 'alice' is not registered")
+}
+
+func TestContract_Execute(t *testing.T) {
+	contract := NewContract()
+
+	err := contract.Execute(fake.NewSnapshot(), makeStep(t, fake.PublicKey{}))
+	require.EqualError(t, err, "'naming:command' not found in tx arg")
+
+	err = contract.Execute(fake.NewSnapshot(), makeStep(t, fake.PublicKey{},
+		CmdArg, "REGISTER", NameArg, "Not A Name"))
+	require.EqualError(t, err, "invalid name 'Not A Name'")
+
+	err = contract.Execute(fake.NewBadSnapshot(), makeStep(t, fake.PublicKey{},
+		CmdArg, "REGISTER", NameArg, "alice"))
+	require.EqualError(t, err, fake.Err("failed to read record"))
+
+	snap := fake.NewSnapshot()
+	snap.Set(makeKey("alice"), []byte("{"))
+
+	err = contract.Execute(snap, makeStep(t, fake.PublicKey{},
+		CmdArg, "REGISTER", NameArg, "alice"))
+	require.EqualError(t, err,
+		"failed to read record: failed to decode: unexpected end of JSON input")
+
+	snap = fake.NewSnapshot(fake.WithKeyError(makeKey("alice"), fake.GetError()))
+
+	err = contract.Execute(snap, makeStep(t, fake.PublicKey{},
+		CmdArg, "REGISTER", NameArg, "alice"))
+	require.EqualError(t, err, fake.Err("failed to read record"))
+
+	err = contract.Execute(fake.NewSnapshot(), makeStep(t, fake.PublicKey{},
+		CmdArg, "REGISTER", NameArg, "alice", DurationArg, "abc"))
+	require.EqualError(t, err, "failed to REGISTER: invalid duration: "+
+		"strconv.ParseUint: parsing \"abc\": invalid syntax")
+
+	err = contract.Execute(fake.NewSnapshot(), makeStep(t, fake.PublicKey{},
+		CmdArg, "REGISTER", NameArg, "alice", DurationArg, "0"))
+	require.EqualError(t, err, "failed to REGISTER: duration must be between 1 and 1048576")
+
+	snap = fake.NewSnapshot()
+	snap.ErrWrite = fake.GetError()
+
+	err = contract.Execute(snap, makeStep(t, fake.PublicKey{},
+		CmdArg, "REGISTER", NameArg, "alice", DurationArg, "1"))
+	require.EqualError(t, err, fake.Err("failed to write record"))
+
+	snap = fake.NewSnapshot()
+
+	err = contract.Execute(snap, makeStep(t, fake.PublicKey{},
+		CmdArg, "REGISTER", NameArg, "alice", DurationArg, "1"))
+	require.NoError(t, err)
+
+	err = contract.Execute(snap, makeStep(t, fake.PublicKey{},
+		CmdArg, "RENEW", NameArg, "alice", DurationArg, "0"))
+	require.EqualError(t, err, "failed to RENEW: duration must be between 1 and 1048576")
+
+	err = contract.Execute(snap, makeStep(t, fake.PublicKey{},
+		CmdArg, "fake", NameArg, "alice"))
+	require.EqualError(t, err, "unknown command: fake")
+
+	snap.ErrDelete = fake.GetError()
+
+	err = contract.Execute(snap, makeStep(t, fake.PublicKey{},
+		CmdArg, "RELEASE", NameArg, "alice"))
+	require.EqualError(t, err, fake.Err("failed to delete record"))
+}
+
+func TestLookup(t *testing.T) {
+	_, err := Lookup(fake.NewBadSnapshot(), "alice", 0)
+	require.EqualError(t, err, fake.Err("failed to read record"))
+
+	snap := fake.NewSnapshot()
+	snap.Set(makeKey("alice"), []byte("{"))
+
+	_, err = Lookup(snap, "alice", 0)
+	require.EqualError(t, err,
+		"failed to read record: failed to decode: unexpected end of JSON input")
+}
+
+func TestRegisterContract(t *testing.T) {
+	RegisterContract(native.NewExecution(), NewContract())
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema := NewSchema()
+
+	err := schema.Validate(makeTx(t, fake.PublicKey{},
+		CmdArg, "REGISTER", NameArg, "alice", TargetArg, "A", DurationArg, "1"))
+	require.NoError(t, err)
+
+	err = schema.Validate(makeTx(t, fake.PublicKey{}, CmdArg, "TRANSFER", NameArg, "alice"))
+	require.EqualError(t, err, "TRANSFER: invalid arguments: 'naming:owner' is missing")
+
+	err = schema.Validate(makeTx(t, fake.PublicKey{}, CmdArg, "fake"))
+	require.EqualError(t, err, "invalid arguments: 'naming:command' must be one of "+
+		"[REGISTER, RELEASE, RENEW, TRANSFER, UPDATE]")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeStep(t *testing.T, pk crypto.PublicKey, args ...string) execution.Step {
+	return execution.Step{Current: makeTx(t, pk, args...)}
+}
+
+func makeTx(t *testing.T, pk crypto.PublicKey, args ...string) txn.Transaction {
+	options := []signed.TransactionOption{}
+	for i := 0; i < len(args)-1; i += 2 {
+		options = append(options, signed.WithArg(args[i], []byte(args[i+1])))
+	}
+
+	tx, err := signed.NewTransaction(0, pk, options...)
+	require.NoError(t, err)
+
+	return tx
+}
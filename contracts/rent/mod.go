@@ -0,0 +1,320 @@
+// Package rent implements an optional storage rent that bounds the size of the
+// state.
+//
+// A contract that opts in tracks the keys it writes with a policy. Each entry
+// is paid until a block and is scheduled in a bucket of that block. The sweep,
+// which runs at the beginning of every block, visits only the bucket of the
+// block: the entries due are first marked as expired, and deleted once the
+// grace period is over. Anyone can top up the rent of an entry with the rent
+// contract, which brings it back if it has expired but not yet been deleted.
+//
+// The sweep is deterministic and only depends on the state, so that every node
+// deletes the same entries at the same block. The policy must be the same on
+// every node.
+package rent
+
+import (
+	"bytes"
+	"encoding/hex"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/contracts/coin"
+	"go.dedis.ch/dela/contracts/sdk"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// ContractName is the name of the contract.
+	ContractName = "go.dedis.ch/dela.Rent"
+
+	// CmdArg is the argument's name to indicate the kind of command we want to
+	// run on the contract.
+	CmdArg = "rent:command"
+
+	// KeyArg is the argument's name in the transaction that contains the key
+	// of the entry.
+	KeyArg = "rent:key"
+
+	// BlocksArg is the argument's name in the transaction that contains the
+	// number of blocks to pay for.
+	BlocksArg = "rent:blocks"
+
+	// CmdTopUp defines the command to extend the rent of an entry. The cost is
+	// taken from the coin balance of the author of the transaction.
+	CmdTopUp = "TOPUP"
+
+	entryPrefix = "rent:entry"
+	duePrefix   = "rent:due"
+)
+
+// Policy is the set of parameters of the rent.
+type Policy struct {
+	// Price is the number of coins paid per block.
+	Price uint64
+
+	// Initial is the number of blocks an entry is paid for when it is
+	// created. It is at least one block.
+	Initial uint64
+
+	// MaxBlocks is the maximum number of blocks an entry can be paid in
+	// advance, or zero for no limit.
+	MaxBlocks uint64
+
+	// Grace is the number of blocks an expired entry is kept before it is
+	// deleted.
+	Grace uint64
+}
+
+// DefaultPolicy is the default set of parameters of the rent.
+var DefaultPolicy = Policy{
+	Price:     1,
+	Initial:   1000,
+	MaxBlocks: 1 << 20,
+	Grace:     100,
+}
+
+// Entry is the rent record of a key.
+type Entry struct {
+	PaidUntil uint64
+	Expired   bool
+}
+
+// due returns the block when the sweep visits the entry.
+func (e Entry) due(p Policy) uint64 {
+	if e.Expired {
+		return e.PaidUntil + p.Grace
+	}
+
+	return e.PaidUntil
+}
+
+// Option is the type of option to create the contract.
+type Option func(*Policy)
+
+// WithPolicy sets the parameters of the rent.
+func WithPolicy(p Policy) Option {
+	return func(policy *Policy) {
+		*policy = p
+	}
+}
+
+// NewContract creates a new rent contract. It uses the default policy unless
+// specified otherwise.
+func NewContract(opts ...Option) *sdk.Contract {
+	policy := DefaultPolicy
+	for _, opt := range opts {
+		opt(&policy)
+	}
+
+	c := sdk.NewContract(ContractName, CmdArg)
+
+	c.Handle(CmdTopUp, policy.topUp,
+		native.Arg{Name: KeyArg, Required: true},
+		native.Arg{Name: BlocksArg, Required: true})
+
+	return c
+}
+
+// RegisterContract registers the rent contract to the given execution service
+// alongside the schema of its arguments.
+func RegisterContract(exec *native.Service, c *sdk.Contract) {
+	c.Register(exec)
+}
+
+// GetEntry returns the rent record of the key, or false if the key is not
+// tracked.
+func GetEntry(snap store.Readable, key []byte) (Entry, bool, error) {
+	var e Entry
+
+	found, err := sdk.GetJSON(snap, sdk.NewKey(entryPrefix, key), &e)
+	if err != nil {
+		return e, false, xerrors.Errorf("failed to read entry: %v", err)
+	}
+
+	return e, found, nil
+}
+
+// Track starts the rent of the key at the height, with the initial number of
+// blocks of the policy. It does nothing if the key is already tracked.
+func (p Policy) Track(snap store.Snapshot, key []byte, height uint64) error {
+	_, found, err := GetEntry(snap, key)
+	if err != nil {
+		return err
+	}
+
+	if found {
+		return nil
+	}
+
+	initial := p.Initial
+	if initial == 0 {
+		initial = 1
+	}
+
+	return p.schedule(snap, key, Entry{PaidUntil: height + initial})
+}
+
+// Untrack stops the rent of the key. The bucket of the entry is left untouched
+// as the sweep ignores the keys without a record.
+func (p Policy) Untrack(snap store.Snapshot, key []byte) error {
+	err := snap.Delete(sdk.NewKey(entryPrefix, key))
+	if err != nil {
+		return xerrors.Errorf("failed to delete entry: %v", err)
+	}
+
+	return nil
+}
+
+// Sweep visits the entries due at the height. The entries still paid are
+// marked as expired for the grace period, and deleted with their key after
+// that. It is meant to be called at the beginning of every block.
+func (p Policy) Sweep(snap store.Snapshot, height uint64) error {
+	bucket := sdk.NewKey(duePrefix, sdk.Uint64Part(height))
+
+	var keys [][]byte
+
+	_, err := sdk.GetJSON(snap, bucket, &keys)
+	if err != nil {
+		return xerrors.Errorf("failed to read bucket: %v", err)
+	}
+
+	for _, key := range keys {
+		e, found, err := GetEntry(snap, key)
+		if err != nil {
+			return err
+		}
+
+		// The entry has been untracked or topped up since it was scheduled.
+		if !found || e.due(p) != height {
+			continue
+		}
+
+		if !e.Expired && p.Grace > 0 {
+			e.Expired = true
+
+			err = p.schedule(snap, key, e)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err = snap.Delete(key)
+		if err != nil {
+			return xerrors.Errorf("failed to delete key '%x': %v", key, err)
+		}
+
+		err = p.Untrack(snap, key)
+		if err != nil {
+			return err
+		}
+
+		dela.Logger.Debug().Hex("key", key).Uint64("height", height).Msg("rent expired")
+	}
+
+	err = snap.Delete(bucket)
+	if err != nil {
+		return xerrors.Errorf("failed to delete bucket: %v", err)
+	}
+
+	return nil
+}
+
+func (p Policy) topUp(ctx *sdk.Context) error {
+	key, err := ctx.Args.Bytes(KeyArg)
+	if err != nil {
+		return err
+	}
+
+	blocks, err := ctx.Args.Uint64(BlocksArg)
+	if err != nil {
+		return err
+	}
+
+	if blocks == 0 {
+		return xerrors.New("blocks must be positive")
+	}
+
+	e, found, err := GetEntry(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return xerrors.Errorf("key '%x' is not rented", key)
+	}
+
+	height := ctx.Step.Index
+
+	start := e.PaidUntil
+	if start < height {
+		start = height
+	}
+
+	if start+blocks < start {
+		return xerrors.New("paid-until overflow")
+	}
+
+	if p.MaxBlocks > 0 && start+blocks-height > p.MaxBlocks {
+		return xerrors.Errorf("cannot pay more than %d blocks in advance", p.MaxBlocks)
+	}
+
+	if p.Price > 0 && blocks > ^uint64(0)/p.Price {
+		return xerrors.New("cost overflow")
+	}
+
+	cost := blocks * p.Price
+
+	if cost > 0 {
+		payer, err := ctx.GetIdentity().MarshalText()
+		if err != nil {
+			return xerrors.Errorf("failed to marshal identity: %v", err)
+		}
+
+		err = coin.Debit(ctx, payer, cost)
+		if err != nil {
+			return err
+		}
+	}
+
+	e.PaidUntil = start + blocks
+	e.Expired = false
+
+	err = p.schedule(ctx, key, e)
+	if err != nil {
+		return err
+	}
+
+	ctx.Emit("topup", "key", hex.EncodeToString(key))
+
+	return nil
+}
+
+// schedule writes the entry and adds the key to the bucket of the block when
+// the sweep must visit it.
+func (p Policy) schedule(snap store.Snapshot, key []byte, e Entry) error {
+	err := sdk.SetJSON(snap, sdk.NewKey(entryPrefix, key), e)
+	if err != nil {
+		return err
+	}
+
+	bucket := sdk.NewKey(duePrefix, sdk.Uint64Part(e.due(p)))
+
+	var keys [][]byte
+
+	_, err = sdk.GetJSON(snap, bucket, &keys)
+	if err != nil {
+		return xerrors.Errorf("failed to read bucket: %v", err)
+	}
+
+	for _, k := range keys {
+		if bytes.Equal(k, key) {
+			return nil
+		}
+	}
+
+	return sdk.SetJSON(snap, bucket, append(keys, key))
+}
@@ -0,0 +1,251 @@
+package rent
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/contracts/sdk"
+	"go.dedis.ch/dela/contracts/sdk/sdktest"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestContract_TopUp(t *testing.T) {
+	policy := Policy{Price: 2, Initial: 10, MaxBlocks: 50, Grace: 5}
+
+	alice := bls.Generate()
+	aliceID, err := alice.GetPublicKey().MarshalText()
+	require.NoError(t, err)
+
+	sdktest.Run(t, NewContract(WithPolicy(policy)), []sdktest.TestCase{
+		{
+			Name:   "extend a paid entry",
+			Signer: alice,
+			Height: 4,
+			Initial: map[string][]byte{
+				entryKey("A"):         []byte(`{"PaidUntil":10,"Expired":false}`),
+				balanceKey(aliceID):   []byte("100"),
+				string(bucketKey(10)): []byte(`["QQ=="]`),
+			},
+			Args: map[string]string{CmdArg: CmdTopUp, KeyArg: "A", BlocksArg: "20"},
+			Expected: map[string][]byte{
+				entryKey("A"):         []byte(`{"PaidUntil":30,"Expired":false}`),
+				balanceKey(aliceID):   []byte("60"),
+				string(bucketKey(30)): []byte(`["QQ=="]`),
+			},
+			Events: []string{"topup"},
+		},
+		{
+			Name:   "restore an expired entry",
+			Signer: alice,
+			Height: 12,
+			Initial: map[string][]byte{
+				entryKey("A"):       []byte(`{"PaidUntil":10,"Expired":true}`),
+				balanceKey(aliceID): []byte("2"),
+			},
+			Args: map[string]string{CmdArg: CmdTopUp, KeyArg: "A", BlocksArg: "1"},
+			Expected: map[string][]byte{
+				entryKey("A"):       []byte(`{"PaidUntil":13,"Expired":false}`),
+				balanceKey(aliceID): []byte("0"),
+			},
+		},
+		{
+			Name:   "too far in advance",
+			Height: 4,
+			Initial: map[string][]byte{
+				entryKey("A"): []byte(`{"PaidUntil":10,"Expired":false}`),
+			},
+			Args: map[string]string{CmdArg: CmdTopUp, KeyArg: "A", BlocksArg: "45"},
+			Err:  "failed to TOPUP: cannot pay more than 50 blocks in advance",
+		},
+		{
+			Name: "insufficient balance",
+			Initial: map[string][]byte{
+				entryKey("A"): []byte(`{"PaidUntil":10,"Expired":false}`),
+			},
+			Args: map[string]string{CmdArg: CmdTopUp, KeyArg: "A", BlocksArg: "5"},
+			Err:  "failed to TOPUP: insufficient balance: 0 < 10",
+		},
+		{
+			Name: "not rented",
+			Args: map[string]string{CmdArg: CmdTopUp, KeyArg: "A", BlocksArg: "5"},
+			Err:  "failed to TOPUP: key '41' is not rented",
+		},
+		{
+			Name: "no blocks",
+			Args: map[string]string{CmdArg: CmdTopUp, KeyArg: "A", BlocksArg: "0"},
+			Err:  "failed to TOPUP: blocks must be positive",
+		},
+		{
+			Name: "bad blocks",
+			Args: map[string]string{CmdArg: CmdTopUp, KeyArg: "A", BlocksArg: "abc"},
+			Err: "failed to TOPUP: 'rent:blocks' is not an unsigned integer: " +
+				"strconv.ParseUint: parsing \"abc\": invalid syntax",
+		},
+		{
+			Name: "bad entry",
+			Initial: map[string][]byte{
+				entryKey("A"): []byte("{"),
+			},
+			Args: map[string]string{CmdArg: CmdTopUp, KeyArg: "A", BlocksArg: "5"},
+			Err: fmt.Sprintf("failed to TOPUP: failed to read entry: "+
+				"failed to decode key '%x': unexpected end of JSON input", entryKey("A")),
+		},
+	})
+}
+
+func TestContract_TopUp_Overflow(t *testing.T) {
+	sdktest.Run(t, NewContract(WithPolicy(Policy{Price: 2})), []sdktest.TestCase{
+		{
+			Name: "paid-until",
+			Initial: map[string][]byte{
+				entryKey("A"): []byte(`{"PaidUntil":10,"Expired":false}`),
+			},
+			Args: map[string]string{CmdArg: CmdTopUp, KeyArg: "A", BlocksArg: "18446744073709551615"},
+			Err:  "failed to TOPUP: paid-until overflow",
+		},
+		{
+			Name: "cost",
+			Initial: map[string][]byte{
+				entryKey("A"): []byte(`{"PaidUntil":10,"Expired":false}`),
+			},
+			Args: map[string]string{CmdArg: CmdTopUp, KeyArg: "A", BlocksArg: "9223372036854775808"},
+			Err:  "failed to TOPUP: cost overflow",
+		},
+	})
+}
+
+func TestRegisterContract(t *testing.T) {
+	exec := native.NewExecution()
+
+	RegisterContract(exec, NewContract())
+
+	require.NoError(t, exec.IsServed(ContractName))
+}
+
+func TestPolicy_Track(t *testing.T) {
+	policy := Policy{}
+	snap := fake.NewSnapshot()
+
+	require.NoError(t, policy.Track(snap, []byte("A"), 5))
+	requireEntry(t, snap, "A", Entry{PaidUntil: 6})
+
+	// Tracking again doesn't reset the entry.
+	require.NoError(t, policy.Track(snap, []byte("A"), 8))
+	requireEntry(t, snap, "A", Entry{PaidUntil: 6})
+
+	require.NoError(t, policy.Untrack(snap, []byte("A")))
+
+	_, found, err := GetEntry(snap, []byte("A"))
+	require.NoError(t, err)
+	require.False(t, found)
+
+	// The key is not added twice to the same bucket.
+	require.NoError(t, policy.Track(snap, []byte("A"), 5))
+
+	data, err := snap.Get(bucketKey(6))
+	require.NoError(t, err)
+	require.Equal(t, `["QQ=="]`, string(data))
+
+	err = policy.Track(fake.NewBadSnapshot(), []byte("A"), 5)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read entry: ")
+
+	err = policy.Untrack(fake.NewBadSnapshot(), []byte("A"))
+	require.EqualError(t, err, fake.Err("failed to delete entry"))
+}
+
+func TestPolicy_Sweep(t *testing.T) {
+	policy := Policy{Initial: 10, Grace: 5}
+	snap := fake.NewSnapshot()
+
+	for _, key := range []string{"A", "B", "C"} {
+		snap.Set([]byte(key), []byte("value"))
+		require.NoError(t, policy.Track(snap, []byte(key), 0))
+	}
+
+	// B is untracked by its contract and C is paid for longer.
+	require.NoError(t, policy.Untrack(snap, []byte("B")))
+	require.NoError(t, policy.schedule(snap, []byte("C"), Entry{PaidUntil: 20}))
+
+	require.NoError(t, policy.Sweep(snap, 9))
+	requireEntry(t, snap, "A", Entry{PaidUntil: 10})
+
+	require.NoError(t, policy.Sweep(snap, 10))
+	requireEntry(t, snap, "A", Entry{PaidUntil: 10, Expired: true})
+	requireEntry(t, snap, "C", Entry{PaidUntil: 20})
+	requireValue(t, snap, "B", "value")
+	requireValue(t, snap, "A", "value")
+	requireValue(t, snap, string(bucketKey(10)), "")
+
+	require.NoError(t, policy.Sweep(snap, 15))
+	requireValue(t, snap, "A", "")
+
+	_, found, err := GetEntry(snap, []byte("A"))
+	require.NoError(t, err)
+	require.False(t, found)
+
+	// Without grace, the entry is deleted as soon as it is due.
+	policy.Grace = 0
+
+	require.NoError(t, policy.Sweep(snap, 20))
+	requireValue(t, snap, "C", "")
+}
+
+func TestPolicy_Sweep_Failures(t *testing.T) {
+	policy := Policy{}
+
+	err := policy.Sweep(fake.NewBadSnapshot(), 0)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read bucket: ")
+
+	snap := fake.NewSnapshot()
+	snap.Set(bucketKey(1), []byte(`["QQ=="]`))
+	snap.Set(sdk.NewKey(entryPrefix, []byte("A")), []byte("{"))
+
+	err = policy.Sweep(snap, 1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read entry: ")
+
+	snap = fake.NewSnapshot()
+	require.NoError(t, policy.Track(snap, []byte("A"), 0))
+
+	snap.ErrDelete = fake.GetError()
+
+	err = policy.Sweep(snap, 1)
+	require.EqualError(t, err, fake.Err("failed to delete key '41'"))
+
+	err = policy.Sweep(snap, 0)
+	require.EqualError(t, err, fake.Err("failed to delete bucket"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func entryKey(key string) string {
+	return string(sdk.NewKey(entryPrefix, []byte(key)))
+}
+
+func bucketKey(height uint64) []byte {
+	return sdk.NewKey(duePrefix, sdk.Uint64Part(height))
+}
+
+func balanceKey(identity []byte) string {
+	return string(sdk.NewKey("coin:balance", identity))
+}
+
+func requireEntry(t *testing.T, snap store.Readable, key string, expected Entry) {
+	e, found, err := GetEntry(snap, []byte(key))
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, expected, e)
+}
+
+func requireValue(t *testing.T, snap store.Readable, key, expected string) {
+	value, err := snap.Get([]byte(key))
+	require.NoError(t, err)
+	require.Equal(t, expected, string(value))
+}
@@ -0,0 +1,111 @@
+package sdk
+
+import (
+	"strconv"
+
+	"go.dedis.ch/dela/core/txn"
+	"golang.org/x/xerrors"
+)
+
+// Args gives typed access to the arguments of a transaction. The getters
+// return an error when the argument is missing, while the Optional variants
+// return the default value instead.
+type Args struct {
+	tx txn.Transaction
+}
+
+// NewArgs returns the arguments of the transaction.
+func NewArgs(tx txn.Transaction) Args {
+	return Args{tx: tx}
+}
+
+// Has returns true if the argument is set and not empty.
+func (a Args) Has(name string) bool {
+	return len(a.tx.GetArg(name)) > 0
+}
+
+// Bytes returns the raw value of the argument.
+func (a Args) Bytes(name string) ([]byte, error) {
+	value := a.tx.GetArg(name)
+	if len(value) == 0 {
+		return nil, xerrors.Errorf("'%s' not found in tx arg", name)
+	}
+
+	return value, nil
+}
+
+// String returns the value of the argument as a string.
+func (a Args) String(name string) (string, error) {
+	value, err := a.Bytes(name)
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
+
+// Uint64 returns the value of the argument parsed as a decimal unsigned
+// integer.
+func (a Args) Uint64(name string) (uint64, error) {
+	value, err := a.Bytes(name)
+	if err != nil {
+		return 0, err
+	}
+
+	num, err := strconv.ParseUint(string(value), 10, 64)
+	if err != nil {
+		return 0, xerrors.Errorf("'%s' is not an unsigned integer: %v", name, err)
+	}
+
+	return num, nil
+}
+
+// Int64 returns the value of the argument parsed as a decimal integer.
+func (a Args) Int64(name string) (int64, error) {
+	value, err := a.Bytes(name)
+	if err != nil {
+		return 0, err
+	}
+
+	num, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return 0, xerrors.Errorf("'%s' is not an integer: %v", name, err)
+	}
+
+	return num, nil
+}
+
+// Bool returns the value of the argument parsed as a boolean.
+func (a Args) Bool(name string) (bool, error) {
+	value, err := a.Bytes(name)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := strconv.ParseBool(string(value))
+	if err != nil {
+		return false, xerrors.Errorf("'%s' is not a boolean: %v", name, err)
+	}
+
+	return b, nil
+}
+
+// OptionalString returns the value of the argument as a string, or the
+// default value if it is missing.
+func (a Args) OptionalString(name, def string) string {
+	if !a.Has(name) {
+		return def
+	}
+
+	return string(a.tx.GetArg(name))
+}
+
+// OptionalUint64 returns the value of the argument parsed as a decimal
+// unsigned integer, or the default value if it is missing.
+func (a Args) OptionalUint64(name string, def uint64) (uint64, error) {
+	if !a.Has(name) {
+		return def, nil
+	}
+
+	return a.Uint64(name)
+}
@@ -0,0 +1,68 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgs_Getters(t *testing.T) {
+	args := NewArgs(makeTx(t, "s", "abc", "u", "42", "i", "-1", "b", "true", "x", "x"))
+
+	require.True(t, args.Has("s"))
+	require.False(t, args.Has("missing"))
+
+	_, err := args.Bytes("missing")
+	require.EqualError(t, err, "'missing' not found in tx arg")
+
+	s, err := args.String("s")
+	require.NoError(t, err)
+	require.Equal(t, "abc", s)
+
+	_, err = args.String("missing")
+	require.EqualError(t, err, "'missing' not found in tx arg")
+
+	u, err := args.Uint64("u")
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), u)
+
+	_, err = args.Uint64("missing")
+	require.Error(t, err)
+
+	_, err = args.Uint64("x")
+	require.EqualError(t, err,
+		"'x' is not an unsigned integer: strconv.ParseUint: parsing \"x\": invalid syntax")
+
+	i, err := args.Int64("i")
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), i)
+
+	_, err = args.Int64("missing")
+	require.Error(t, err)
+
+	_, err = args.Int64("x")
+	require.EqualError(t, err,
+		"'x' is not an integer: strconv.ParseInt: parsing \"x\": invalid syntax")
+
+	b, err := args.Bool("b")
+	require.NoError(t, err)
+	require.True(t, b)
+
+	_, err = args.Bool("missing")
+	require.Error(t, err)
+
+	_, err = args.Bool("x")
+	require.EqualError(t, err,
+		"'x' is not a boolean: strconv.ParseBool: parsing \"x\": invalid syntax")
+
+	require.Equal(t, "abc", args.OptionalString("s", "def"))
+	require.Equal(t, "def", args.OptionalString("missing", "def"))
+
+	u, err = args.OptionalUint64("u", 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), u)
+
+	u, err = args.OptionalUint64("missing", 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), u)
+}
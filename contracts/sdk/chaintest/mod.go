@@ -0,0 +1,211 @@
+// Package chaintest provides an in-process chain to test contracts against the
+// real storage and validation, without the consensus and the network.
+//
+// Every call to Block stages the transactions on the Merkle tree with the
+// simple validation service and the native execution, then commits the tree
+// like a block would. The roots of the tree after each block can be compared
+// to a golden file, so that any change in the state produced by a contract is
+// caught by the regression tests. The golden files are rewritten when the
+// environment variable of UpdateEnv is set.
+package chaintest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/hashtree"
+	"go.dedis.ch/dela/core/store/hashtree/binprefix"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+)
+
+// UpdateEnv is the environment variable that makes AssertGolden write the
+// golden files instead of comparing them.
+const UpdateEnv = "DELA_UPDATE_GOLDEN"
+
+// Chain is an in-process chain made of a Merkle tree, the native execution and
+// the simple validation.
+type Chain struct {
+	t       *testing.T
+	exec    *native.Service
+	val     simple.Service
+	tree    hashtree.Tree
+	signers map[string]crypto.Signer
+	nonces  map[string]uint64
+	roots   [][]byte
+}
+
+// New creates a fresh chain backed by a temporary database that is removed at
+// the end of the test.
+func New(t *testing.T) *Chain {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela-chaintest")
+	require.NoError(t, err)
+
+	db, err := kv.New(filepath.Join(dir, "chain.db"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.Close()
+		os.RemoveAll(dir)
+	})
+
+	exec := native.NewExecution()
+
+	return &Chain{
+		t:       t,
+		exec:    exec,
+		val:     simple.NewService(exec, signed.NewTransactionFactory()),
+		tree:    binprefix.NewMerkleTree(db, binprefix.Nonce{}),
+		signers: make(map[string]crypto.Signer),
+		nonces:  make(map[string]uint64),
+	}
+}
+
+// GetExecution returns the native execution service of the chain, which can be
+// used to set policies or schemas.
+func (c *Chain) GetExecution() *native.Service {
+	return c.exec
+}
+
+// Register sets the contract to the execution service.
+func (c *Chain) Register(name string, contract native.Contract) {
+	c.exec.Set(name, contract)
+}
+
+// Signer returns the signer associated to the name. The key is derived from the
+// name so that the state, and therefore the roots, are the same from one run
+// to another.
+func (c *Chain) Signer(name string) crypto.Signer {
+	signer, found := c.signers[name]
+	if found {
+		return signer
+	}
+
+	seed := sha256.Sum256([]byte("chaintest:" + name))
+	// The first byte is cleared so that the scalar is below the order of the
+	// group.
+	seed[0] = 0
+
+	signer, err := bls.NewSignerFromBytes(seed[:])
+	require.NoError(c.t, err)
+
+	c.signers[name] = signer
+
+	return signer
+}
+
+// Tx returns a new transaction signed by the named signer, with the next nonce
+// of the signer and the arguments given as pairs of name and value.
+func (c *Chain) Tx(signer string, args ...string) txn.Transaction {
+	opts := []signed.TransactionOption{}
+	for i := 0; i+1 < len(args); i += 2 {
+		opts = append(opts, signed.WithArg(args[i], []byte(args[i+1])))
+	}
+
+	nonce := c.nonces[signer]
+	c.nonces[signer] = nonce + 1
+
+	tx, err := signed.NewTransaction(nonce, c.Signer(signer).GetPublicKey(), opts...)
+	require.NoError(c.t, err)
+
+	err = tx.Sign(c.Signer(signer))
+	require.NoError(c.t, err)
+
+	return tx
+}
+
+// Block executes the transactions as a new block and commits the resulting
+// state. It returns the result of the validation.
+func (c *Chain) Block(txs ...txn.Transaction) validation.Result {
+	var res validation.Result
+
+	index := uint64(len(c.roots))
+
+	stage, err := c.tree.Stage(func(snap store.Snapshot) error {
+		var err error
+		res, err = c.val.Validate(snap, index, txs)
+		return err
+	})
+	require.NoError(c.t, err)
+
+	err = stage.Commit()
+	require.NoError(c.t, err)
+
+	c.tree = stage
+	c.roots = append(c.roots, stage.GetRoot())
+
+	return res
+}
+
+// Exec executes a single transaction of the named signer in its own block and
+// returns true if it is accepted, alongside the reason of the refusal.
+func (c *Chain) Exec(signer string, args ...string) (bool, string) {
+	res := c.Block(c.Tx(signer, args...))
+
+	status, reason := res.GetTransactionResults()[0].GetStatus()
+
+	return status, reason
+}
+
+// MustExec executes a single transaction like Exec and fails the test if it is
+// refused.
+func (c *Chain) MustExec(signer string, args ...string) {
+	accepted, reason := c.Exec(signer, args...)
+	require.True(c.t, accepted, "transaction refused: %s", reason)
+}
+
+// Get returns the value of the key in the latest state.
+func (c *Chain) Get(key []byte) []byte {
+	value, err := c.tree.Get(key)
+	require.NoError(c.t, err)
+
+	return value
+}
+
+// GetStore returns the latest state.
+func (c *Chain) GetStore() store.Readable {
+	return c.tree
+}
+
+// GetRoots returns the roots of the tree after each block.
+func (c *Chain) GetRoots() [][]byte {
+	return append([][]byte{}, c.roots...)
+}
+
+// AssertGolden compares the roots of the blocks executed so far with the
+// golden file, or writes them if the environment variable of UpdateEnv is set.
+func (c *Chain) AssertGolden(path string) {
+	buffer := new(bytes.Buffer)
+	for i, root := range c.roots {
+		fmt.Fprintf(buffer, "%d %x\n", i, root)
+	}
+
+	if os.Getenv(UpdateEnv) != "" {
+		err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+		require.NoError(c.t, err)
+
+		err = ioutil.WriteFile(path, buffer.Bytes(), 0644)
+		require.NoError(c.t, err)
+
+		return
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	require.NoError(c.t, err, "run with %s=1 to create the golden file", UpdateEnv)
+
+	require.Equal(c.t, string(expected), buffer.String(),
+		"roots differ from %s, run with %s=1 to update it", path, UpdateEnv)
+}
@@ -0,0 +1,74 @@
+package chaintest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/contracts/naming"
+	"go.dedis.ch/dela/core/execution/native"
+)
+
+func TestChain_Naming(t *testing.T) {
+	chain := New(t)
+	chain.Register(naming.ContractName, naming.NewContract())
+
+	chain.MustExec("alice", native.ContractArg, naming.ContractName,
+		naming.CmdArg, "REGISTER", naming.NameArg, "alice",
+		naming.TargetArg, "A", naming.DurationArg, "10")
+
+	accepted, reason := chain.Exec("bob", native.ContractArg, naming.ContractName,
+		naming.CmdArg, "REGISTER", naming.NameArg, "alice",
+		naming.TargetArg, "B", naming.DurationArg, "10")
+	require.False(t, accepted)
+	require.Contains(t, reason, "name 'alice' is already registered")
+
+	target, err := naming.Resolve(chain.GetStore(), "alice", 1)
+	require.NoError(t, err)
+	require.Equal(t, []byte("A"), target)
+
+	require.Len(t, chain.GetRoots(), 2)
+	require.NotEqual(t, chain.GetRoots()[0], chain.GetRoots()[1])
+}
+
+func TestChain_Deterministic(t *testing.T) {
+	run := func() *Chain {
+		chain := New(t)
+		chain.Register(naming.ContractName, naming.NewContract())
+
+		chain.Block(
+			chain.Tx("alice", native.ContractArg, naming.ContractName,
+				naming.CmdArg, "REGISTER", naming.NameArg, "alice",
+				naming.TargetArg, "A", naming.DurationArg, "10"),
+			chain.Tx("alice", native.ContractArg, naming.ContractName,
+				naming.CmdArg, "UPDATE", naming.NameArg, "alice", naming.TargetArg, "B"),
+		)
+
+		return chain
+	}
+
+	first := run()
+	second := run()
+
+	require.Equal(t, first.GetRoots(), second.GetRoots())
+	require.Equal(t, first.Signer("alice").GetPublicKey(), second.Signer("alice").GetPublicKey())
+
+	dir, err := ioutil.TempDir(os.TempDir(), "dela-chaintest")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "testdata", "naming.golden")
+
+	os.Setenv(UpdateEnv, "1")
+	first.AssertGolden(path)
+	os.Unsetenv(UpdateEnv)
+
+	second.AssertGolden(path)
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Regexp(t, "^0 [0-9a-f]{64}\n$", string(data))
+}
@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"go.dedis.ch/dela/core/store"
+	"golang.org/x/xerrors"
+)
+
+// KeySize is the size of the keys produced by NewKey, which fits the limit of
+// the store keys.
+const KeySize = sha256.Size
+
+// NewKey returns a key for the store that is unique to the prefix and the
+// parts. The parts are length-prefixed so that different splits of the same
+// bytes produce different keys.
+func NewKey(prefix string, parts ...[]byte) []byte {
+	h := sha256.New()
+	writePart(h, []byte(prefix))
+
+	for _, part := range parts {
+		writePart(h, part)
+	}
+
+	return h.Sum(nil)
+}
+
+// Uint64Part encodes the integer so that it can be used as part of a key.
+func Uint64Part(v uint64) []byte {
+	buffer := make([]byte, 8)
+	binary.BigEndian.PutUint64(buffer, v)
+
+	return buffer
+}
+
+// GetJSON reads the value at the key and decodes it into the given value. It
+// returns false if the key is not set.
+func GetJSON(snap store.Readable, key []byte, v interface{}) (bool, error) {
+	data, err := snap.Get(key)
+	if err != nil {
+		return false, xerrors.Errorf("failed to read key '%x': %v", key, err)
+	}
+
+	if len(data) == 0 {
+		return false, nil
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return false, xerrors.Errorf("failed to decode key '%x': %v", key, err)
+	}
+
+	return true, nil
+}
+
+// SetJSON encodes the value and writes it at the key.
+func SetJSON(snap store.Writable, key []byte, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return xerrors.Errorf("failed to encode key '%x': %v", key, err)
+	}
+
+	err = snap.Set(key, data)
+	if err != nil {
+		return xerrors.Errorf("failed to write key '%x': %v", key, err)
+	}
+
+	return nil
+}
+
+func writePart(h io.Writer, part []byte) {
+	h.Write(Uint64Part(uint64(len(part))))
+	h.Write(part)
+}
@@ -0,0 +1,51 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestNewKey(t *testing.T) {
+	key := NewKey("prefix", []byte("ab"), []byte("c"))
+	require.Len(t, key, KeySize)
+
+	require.Equal(t, key, NewKey("prefix", []byte("ab"), []byte("c")))
+	require.NotEqual(t, key, NewKey("prefix", []byte("a"), []byte("bc")))
+	require.NotEqual(t, key, NewKey("other", []byte("ab"), []byte("c")))
+	require.NotEqual(t, NewKey("a", Uint64Part(1)), NewKey("a", Uint64Part(2)))
+}
+
+func TestJSON(t *testing.T) {
+	snap := fake.NewSnapshot()
+
+	var value []string
+
+	found, err := GetJSON(snap, []byte("key"), &value)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	err = SetJSON(snap, []byte("key"), []string{"a", "b"})
+	require.NoError(t, err)
+
+	found, err = GetJSON(snap, []byte("key"), &value)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, []string{"a", "b"}, value)
+
+	_, err = GetJSON(fake.NewBadSnapshot(), []byte("A"), &value)
+	require.EqualError(t, err, fake.Err("failed to read key '41'"))
+
+	snap.Set([]byte("A"), []byte("{"))
+	_, err = GetJSON(snap, []byte("A"), &value)
+	require.EqualError(t, err,
+		"failed to decode key '41': unexpected end of JSON input")
+
+	err = SetJSON(snap, []byte("A"), make(chan int))
+	require.EqualError(t, err,
+		"failed to encode key '41': json: unsupported type: chan int")
+
+	err = SetJSON(fake.NewBadSnapshot(), []byte("A"), 1)
+	require.EqualError(t, err, fake.Err("failed to write key '41'"))
+}
@@ -0,0 +1,270 @@
+// Package sdk provides helpers to write native contracts with less
+// boilerplate.
+//
+// A contract is built by registering a handler per command. The handlers
+// receive a context that gives typed access to the arguments of the
+// transaction, to the snapshot and to an event emitter. Access checks are
+// performed by the contract before the handler is called when an access service
+// is provided.
+//
+//   c := sdk.NewContract("example", "example:command",
+//       sdk.WithAccess(srvc, accessKey))
+//
+//   c.Handle("SET", func(ctx *sdk.Context) error {
+//       key, err := ctx.Args.String("example:key")
+//       ...
+//       return ctx.Set(sdk.NewKey("example", []byte(key)), value)
+//   })
+//
+package sdk
+
+import (
+	"sort"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn"
+	"golang.org/x/xerrors"
+)
+
+// HandlerFunc is the function called to execute a command of a contract.
+type HandlerFunc func(ctx *Context) error
+
+// Event is a notification emitted by a contract during the execution of a
+// transaction.
+type Event struct {
+	Contract   string
+	Name       string
+	Attributes map[string]string
+}
+
+// EventHandler is the function called for every event emitted by a contract.
+type EventHandler func(Event)
+
+// Context is the context of execution of a command. It gives access to the
+// snapshot, the step and the arguments of the current transaction.
+type Context struct {
+	store.Snapshot
+
+	Step execution.Step
+	Args Args
+
+	contract string
+	exec     *native.Service
+	events   []Event
+	onEvent  EventHandler
+}
+
+// GetIdentity returns the identity of the author of the current transaction.
+func (ctx *Context) GetIdentity() access.Identity {
+	return ctx.Step.Current.GetIdentity()
+}
+
+// ConsumeGas reports the units of gas consumed by the command besides the
+// accesses to the snapshot, which are metered by the execution service. It
+// returns an error when the transaction runs out of gas, which must stop the
+// command.
+func (ctx *Context) ConsumeGas(units uint64) error {
+	return ctx.Step.Gas.Consume(units)
+}
+
+// Emit records an event with the given name and attributes, given by pairs of
+// key and value. The event is also recorded in the log of the step so that it
+// is part of the result of the transaction.
+func (ctx *Context) Emit(name string, attrs ...string) {
+	ctx.Step.Emit(ctx.contract, name, attrs...)
+
+	event := Event{
+		Contract:   ctx.contract,
+		Name:       name,
+		Attributes: make(map[string]string),
+	}
+
+	for i := 0; i+1 < len(attrs); i += 2 {
+		event.Attributes[attrs[i]] = attrs[i+1]
+	}
+
+	ctx.events = append(ctx.events, event)
+
+	if ctx.onEvent != nil {
+		ctx.onEvent(event)
+	}
+}
+
+// Call executes the contract with the given name within the current
+// transaction, for instance to debit a fee, and returns its error. The
+// contract reads the arguments given by pairs of key and value instead of the
+// ones of the transaction. The writes of the contract are kept only if it
+// succeeds. The calling contract must be registered with Register.
+func (ctx *Context) Call(contract string, args ...string) error {
+	if ctx.exec == nil {
+		return xerrors.Errorf("contract '%s' is not registered", ctx.contract)
+	}
+
+	txArgs := make([]txn.Arg, 0, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		txArgs = append(txArgs, txn.Arg{Key: args[i], Value: []byte(args[i+1])})
+	}
+
+	return ctx.exec.Call(contract, ctx.Snapshot, ctx.Step, txArgs...)
+}
+
+// GetEvents returns the events emitted so far.
+func (ctx *Context) GetEvents() []Event {
+	return append([]Event{}, ctx.events...)
+}
+
+// ContractOption is the type of option to configure a contract.
+type ContractOption func(*Contract)
+
+// WithAccess is an option to check that the author of a transaction is
+// allowed to run the command against the access service, using the given
+// access key.
+func WithAccess(srvc access.Service, key []byte) ContractOption {
+	return func(c *Contract) {
+		c.access = srvc
+		c.accessKey = key
+	}
+}
+
+// WithEventHandler is an option to set the function called for each event
+// emitted by the contract. By default, the events are logged.
+func WithEventHandler(fn EventHandler) ContractOption {
+	return func(c *Contract) {
+		c.onEvent = fn
+	}
+}
+
+// Contract is a native contract that dispatches the transactions to the
+// handler of their command.
+//
+// - implements native.Contract
+type Contract struct {
+	name      string
+	cmdArg    string
+	handlers  map[string]HandlerFunc
+	schemas   map[string]native.Schema
+	access    access.Service
+	accessKey []byte
+	onEvent   EventHandler
+	exec      *native.Service
+}
+
+// NewContract creates a new contract with the given name. The command of a
+// transaction is read from the argument cmdArg.
+func NewContract(name, cmdArg string, opts ...ContractOption) *Contract {
+	c := &Contract{
+		name:     name,
+		cmdArg:   cmdArg,
+		handlers: make(map[string]HandlerFunc),
+		schemas:  make(map[string]native.Schema),
+		onEvent:  logEvent,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Handle sets the handler of the command. The arguments, if any, are used to
+// build the schema of the command.
+func (c *Contract) Handle(cmd string, fn HandlerFunc, args ...native.Arg) {
+	c.handlers[cmd] = fn
+
+	if len(args) > 0 {
+		c.schemas[cmd] = native.NewArgSchema(args...)
+	} else {
+		c.schemas[cmd] = nil
+	}
+}
+
+// GetCommands returns the sorted list of commands handled by the contract.
+func (c *Contract) GetCommands() []string {
+	cmds := make([]string, 0, len(c.handlers))
+	for cmd := range c.handlers {
+		cmds = append(cmds, cmd)
+	}
+
+	sort.Strings(cmds)
+
+	return cmds
+}
+
+// Schema returns the schema of the arguments of the commands.
+func (c *Contract) Schema() native.Schema {
+	return native.NewSwitchSchema(c.cmdArg, c.schemas)
+}
+
+// Register registers the contract and its schema to the execution service,
+// which the handlers use to call the other contracts.
+func (c *Contract) Register(exec *native.Service) {
+	c.exec = exec
+
+	exec.Set(c.name, c)
+	exec.SetSchema(c.name, c.Schema())
+}
+
+// Execute implements native.Contract. It checks the access if necessary, and
+// then runs the handler of the command.
+func (c *Contract) Execute(snap store.Snapshot, step execution.Step) error {
+	cmd := string(step.Current.GetArg(c.cmdArg))
+	if cmd == "" {
+		return xerrors.Errorf("'%s' not found in tx arg", c.cmdArg)
+	}
+
+	handler, found := c.handlers[cmd]
+	if !found {
+		return xerrors.Errorf("unknown command: %s", cmd)
+	}
+
+	if c.access != nil {
+		err := CheckAccess(c.access, snap, c.accessKey, c.name, cmd, step)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx := &Context{
+		Snapshot: snap,
+		Step:     step,
+		Args:     NewArgs(step.Current),
+		contract: c.name,
+		exec:     c.exec,
+		onEvent:  c.onEvent,
+	}
+
+	err := handler(ctx)
+	if err != nil {
+		return xerrors.Errorf("failed to %s: %v", cmd, err)
+	}
+
+	return nil
+}
+
+// CheckAccess returns nil if the author of the current transaction is allowed
+// to run the command of the contract.
+func CheckAccess(srvc access.Service, snap store.Readable, key []byte,
+	contract, cmd string, step execution.Step) error {
+
+	creds := access.NewContractCreds(key, contract, cmd)
+
+	err := srvc.Match(snap, creds, step.Current.GetIdentity())
+	if err != nil {
+		return xerrors.Errorf("identity not authorized: %v (%v)",
+			step.Current.GetIdentity(), err)
+	}
+
+	return nil
+}
+
+func logEvent(event Event) {
+	dela.Logger.Info().
+		Str("contract", event.Contract).
+		Interface("attributes", event.Attributes).
+		Msgf("event %s", event.Name)
+}
@@ -0,0 +1,204 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"golang.org/x/xerrors"
+)
+
+func TestContract_Execute(t *testing.T) {
+	events := []Event{}
+
+	c := NewContract("example", "cmd", WithEventHandler(func(e Event) {
+		events = append(events, e)
+	}))
+
+	c.Handle("SET", func(ctx *Context) error {
+		value, err := ctx.Args.String("value")
+		if err != nil {
+			return err
+		}
+
+		ctx.Emit("set", "value", value)
+
+		return ctx.Set([]byte("key"), []byte(value))
+	}, native.Arg{Name: "value", Required: true})
+
+	c.Handle("FAIL", func(ctx *Context) error {
+		return fake.GetError()
+	})
+
+	snap := fake.NewSnapshot()
+
+	err := c.Execute(snap, makeStep(t))
+	require.EqualError(t, err, "'cmd' not found in tx arg")
+
+	err = c.Execute(snap, makeStep(t, "cmd", "UNKNOWN"))
+	require.EqualError(t, err, "unknown command: UNKNOWN")
+
+	err = c.Execute(snap, makeStep(t, "cmd", "SET"))
+	require.EqualError(t, err, "failed to SET: 'value' not found in tx arg")
+
+	err = c.Execute(snap, makeStep(t, "cmd", "FAIL"))
+	require.EqualError(t, err, fake.Err("failed to FAIL"))
+
+	err = c.Execute(snap, makeStep(t, "cmd", "SET", "value", "abc"))
+	require.NoError(t, err)
+
+	value, err := snap.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("abc"), value)
+
+	require.Len(t, events, 1)
+	require.Equal(t, "example", events[0].Contract)
+	require.Equal(t, "set", events[0].Name)
+	require.Equal(t, map[string]string{"value": "abc"}, events[0].Attributes)
+
+	require.Equal(t, []string{"FAIL", "SET"}, c.GetCommands())
+}
+
+func TestContract_Access(t *testing.T) {
+	c := NewContract("example", "cmd", WithAccess(fakeAccess{err: fake.GetError()}, nil))
+	c.Handle("NOOP", func(ctx *Context) error { return nil })
+
+	err := c.Execute(fake.NewSnapshot(), makeStep(t, "cmd", "NOOP"))
+	require.EqualError(t, err,
+		"identity not authorized: fake.PublicKey ("+fake.GetError().Error()+")")
+
+	c = NewContract("example", "cmd", WithAccess(fakeAccess{}, nil))
+	c.Handle("NOOP", func(ctx *Context) error {
+		require.Equal(t, fake.PublicKey{}, ctx.GetIdentity())
+		return nil
+	})
+
+	err = c.Execute(fake.NewSnapshot(), makeStep(t, "cmd", "NOOP"))
+	require.NoError(t, err)
+}
+
+func TestContext_Call(t *testing.T) {
+	exec := native.NewExecution()
+
+	fee := NewContract("fee", "cmd")
+	fee.Handle("PAY", func(ctx *Context) error {
+		if ctx.Step.GetCaller() != "example" {
+			return fake.GetError()
+		}
+
+		amount, err := ctx.Args.String("amount")
+		if err != nil {
+			return err
+		}
+
+		return ctx.Set([]byte("fee"), []byte(amount))
+	})
+	fee.Register(exec)
+
+	c := NewContract("example", "cmd")
+	c.Handle("PAY", func(ctx *Context) error {
+		return ctx.Call("fee", "cmd", "PAY", "amount", "5")
+	})
+	c.Register(exec)
+
+	snap := fake.NewSnapshot()
+
+	// The fee contract reads its own amount and not the one of the
+	// transaction.
+	res, err := exec.Execute(snap, makeStep(t, native.ContractArg, "example", "cmd", "PAY",
+		"amount", "100"))
+	require.NoError(t, err)
+	require.True(t, res.Accepted, res.Message)
+
+	value, err := snap.Get([]byte("fee"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("5"), value)
+
+	res, err = exec.Execute(snap, makeStep(t, native.ContractArg, "fee", "cmd", "PAY"))
+	require.NoError(t, err)
+	require.Equal(t, fake.Err("failed to PAY"), res.Message)
+
+	err = (&Context{contract: "example"}).Call("fee")
+	require.EqualError(t, err, "contract 'example' is not registered")
+}
+
+func TestContract_Schema(t *testing.T) {
+	c := NewContract("example", "cmd")
+	c.Handle("SET", nil, native.Arg{Name: "value", Required: true})
+	c.Handle("LIST", nil)
+
+	exec := native.NewExecution()
+	c.Register(exec)
+
+	err := c.Schema().Validate(makeTx(t, "cmd", "LIST"))
+	require.NoError(t, err)
+
+	err = c.Schema().Validate(makeTx(t, "cmd", "SET"))
+	require.EqualError(t, err, "SET: invalid arguments: 'value' is missing")
+}
+
+func TestContext_Emit(t *testing.T) {
+	ctx := &Context{contract: "example"}
+
+	ctx.Emit("a", "key")
+	ctx.Emit("b", "key", "value")
+
+	events := ctx.GetEvents()
+	require.Len(t, events, 2)
+	require.Empty(t, events[0].Attributes)
+	require.Equal(t, "value", events[1].Attributes["key"])
+
+	logEvent(events[1])
+
+	// The events are recorded in the log of the step.
+	ctx.Step.Log = execution.NewLog()
+	ctx.Emit("c", "key", "value")
+	require.Equal(t, []execution.Event{execution.NewEvent("example", "c", "key", "value")},
+		ctx.Step.Log.GetEvents())
+}
+
+func TestContext_ConsumeGas(t *testing.T) {
+	ctx := &Context{}
+
+	require.NoError(t, ctx.ConsumeGas(10))
+
+	ctx.Step.Gas = execution.NewMeter(10)
+
+	require.NoError(t, ctx.ConsumeGas(10))
+	require.True(t, xerrors.Is(ctx.ConsumeGas(1), execution.ErrOutOfGas))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeStep(t *testing.T, args ...string) execution.Step {
+	return execution.Step{Current: makeTx(t, args...)}
+}
+
+func makeTx(t *testing.T, args ...string) txn.Transaction {
+	options := []signed.TransactionOption{}
+	for i := 0; i < len(args)-1; i += 2 {
+		options = append(options, signed.WithArg(args[i], []byte(args[i+1])))
+	}
+
+	tx, err := signed.NewTransaction(0, fake.PublicKey{}, options...)
+	require.NoError(t, err)
+
+	return tx
+}
+
+type fakeAccess struct {
+	access.Service
+
+	err error
+}
+
+func (srvc fakeAccess) Match(store.Readable, access.Credential, ...access.Identity) error {
+	return srvc.err
+}
@@ -0,0 +1,115 @@
+// Package sdktest provides a table-driven harness to test the contracts built
+// with the SDK.
+//
+// Each case executes a single transaction against a fresh snapshot populated
+// with the initial state, then compares the state, the error and the events
+// with the expectations.
+package sdktest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/contracts/sdk"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+// TestCase is a case of the table-driven harness. A fresh snapshot is
+// populated with the initial state, then a transaction with the arguments is
+// executed and the outcome is compared against the expectations.
+type TestCase struct {
+	Name string
+
+	// Signer is the author of the transaction. A random one is used by
+	// default.
+	Signer crypto.Signer
+
+	// Height is the index of the block during the execution.
+	Height uint64
+
+	// Initial is the state before the execution.
+	Initial map[string][]byte
+
+	// Args are the arguments of the transaction.
+	Args map[string]string
+
+	// Err is the expected error message, or empty if the execution succeeds.
+	Err string
+
+	// Expected maps keys to their expected values after the execution. A nil
+	// value means the key must not be set.
+	Expected map[string][]byte
+
+	// Events is the list of names of the events expected to be emitted.
+	Events []string
+
+	// Check is an optional function called with the snapshot after the
+	// execution.
+	Check func(t *testing.T, snap store.Snapshot)
+}
+
+// Run executes the test cases against the contract.
+func Run(t *testing.T, contract *sdk.Contract, cases []TestCase) {
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			runCase(t, contract, tc)
+		})
+	}
+}
+
+func runCase(t *testing.T, contract *sdk.Contract, tc TestCase) {
+	snap := fake.NewSnapshot()
+
+	for key, value := range tc.Initial {
+		snap.Set([]byte(key), value)
+	}
+
+	signer := tc.Signer
+	if signer == nil {
+		signer = bls.Generate()
+	}
+
+	opts := []signed.TransactionOption{}
+	for name, value := range tc.Args {
+		opts = append(opts, signed.WithArg(name, []byte(value)))
+	}
+
+	tx, err := signed.NewTransaction(0, signer.GetPublicKey(), opts...)
+	require.NoError(t, err)
+
+	events := []string{}
+
+	c := *contract
+	sdk.WithEventHandler(func(event sdk.Event) {
+		events = append(events, event.Name)
+	})(&c)
+
+	err = c.Execute(snap, execution.Step{Index: tc.Height, Current: tx})
+	if tc.Err != "" {
+		require.EqualError(t, err, tc.Err)
+		return
+	}
+
+	require.NoError(t, err)
+
+	for key, value := range tc.Expected {
+		actual, err := snap.Get([]byte(key))
+		require.NoError(t, err)
+		require.Equal(t, value, actual, "value of key '%x'", key)
+	}
+
+	if tc.Events != nil {
+		require.Equal(t, tc.Events, events)
+	}
+
+	if tc.Check != nil {
+		tc.Check(t, snap)
+	}
+}
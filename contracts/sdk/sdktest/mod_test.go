@@ -0,0 +1,58 @@
+package sdktest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/contracts/sdk"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestRun(t *testing.T) {
+	c := sdk.NewContract("counter", "cmd")
+
+	c.Handle("INC", func(ctx *sdk.Context) error {
+		var count uint64
+
+		_, err := sdk.GetJSON(ctx, []byte("count"), &count)
+		if err != nil {
+			return err
+		}
+
+		ctx.Emit("incremented")
+
+		return sdk.SetJSON(ctx, []byte("count"), count+ctx.Step.Index)
+	})
+
+	c.Handle("RESET", func(ctx *sdk.Context) error {
+		return ctx.Delete([]byte("count"))
+	}, native.Arg{Name: "confirm", Required: true})
+
+	Run(t, c, []TestCase{
+		{
+			Name:     "increment",
+			Height:   2,
+			Initial:  map[string][]byte{"count": []byte("1")},
+			Args:     map[string]string{"cmd": "INC"},
+			Expected: map[string][]byte{"count": []byte("3")},
+			Events:   []string{"incremented"},
+		},
+		{
+			Name:     "reset",
+			Initial:  map[string][]byte{"count": []byte("1")},
+			Args:     map[string]string{"cmd": "RESET", "confirm": "yes"},
+			Expected: map[string][]byte{"count": nil},
+			Events:   []string{},
+			Check: func(t *testing.T, snap store.Snapshot) {
+				require.Equal(t, 0, snap.(*fake.InMemorySnapshot).Len())
+			},
+		},
+		{
+			Name: "unknown",
+			Args: map[string]string{"cmd": "UNKNOWN"},
+			Err:  "unknown command: UNKNOWN",
+		},
+	})
+}
@@ -3,16 +3,19 @@
 package value
 
 import (
+	"encoding/hex"
 	"fmt"
 	"io"
 	"sort"
 	"strings"
 
 	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/contracts/rent"
 	"go.dedis.ch/dela/core/access"
 	"go.dedis.ch/dela/core/execution"
 	"go.dedis.ch/dela/core/execution/native"
 	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/internal/collection"
 	"golang.org/x/xerrors"
 )
 
@@ -70,9 +73,25 @@ func NewCreds(id []byte) access.Credential {
 	return access.NewContractCreds(id, ContractName, credentialAllCommand)
 }
 
-// RegisterContract registers the value contract to the given execution service.
+// RegisterContract registers the value contract to the given execution service
+// alongside the schema of its arguments.
 func RegisterContract(exec *native.Service, c Contract) {
 	exec.Set(ContractName, c)
+	exec.SetSchema(ContractName, NewSchema())
+}
+
+// NewSchema returns the schema of the arguments expected by each command of
+// the value contract.
+func NewSchema() native.Schema {
+	key := native.Arg{Name: KeyArg, Required: true}
+	value := native.Arg{Name: ValueArg, Required: true}
+
+	return native.NewSwitchSchema(CmdArg, map[string]native.Schema{
+		string(CmdWrite):  native.NewArgSchema(key, value),
+		string(CmdRead):   native.NewArgSchema(key),
+		string(CmdDelete): native.NewArgSchema(key),
+		string(CmdList):   nil,
+	})
 }
 
 // Contract is a simple smart contract that allows one to handle the storage by
@@ -81,7 +100,7 @@ func RegisterContract(exec *native.Service, c Contract) {
 // - implements native.Contract
 type Contract struct {
 	// index contains all the keys set (and not delete) by this contract so far
-	index map[string]struct{}
+	index *collection.OrderedSet
 
 	// access is the access control service managing this smart contract
 	access access.Service
@@ -94,17 +113,37 @@ type Contract struct {
 
 	// printer is the output used by the READ and LIST commands
 	printer io.Writer
+
+	// rent is the storage rent policy of the keys, or nil if the keys never
+	// expire
+	rent *rent.Policy
+}
+
+// Option is the type of option to create the contract.
+type Option func(*Contract)
+
+// WithRent is an option to track the keys written by the contract with the
+// storage rent policy. The keys are then deleted by the sweep of the policy
+// when their rent is not topped up.
+func WithRent(p rent.Policy) Option {
+	return func(c *Contract) {
+		c.rent = &p
+	}
 }
 
 // NewContract creates a new Value contract
-func NewContract(aKey []byte, srvc access.Service) Contract {
+func NewContract(aKey []byte, srvc access.Service, opts ...Option) Contract {
 	contract := Contract{
-		index:     map[string]struct{}{},
+		index:     collection.NewOrderedSet(),
 		access:    srvc,
 		accessKey: aKey,
 		printer:   infoLog{},
 	}
 
+	for _, opt := range opts {
+		opt(&contract)
+	}
+
 	contract.cmd = valueCommand{Contract: &contract}
 
 	return contract
@@ -177,7 +216,16 @@ func (c valueCommand) write(snap store.Snapshot, step execution.Step) error {
 		return xerrors.Errorf("failed to set value: %v", err)
 	}
 
-	c.index[string(key)] = struct{}{}
+	if c.rent != nil {
+		err = c.rent.Track(snap, key, step.Index)
+		if err != nil {
+			return xerrors.Errorf("failed to track rent: %v", err)
+		}
+	}
+
+	c.index.Add(string(key))
+
+	step.Emit(ContractName, "write", "key", hex.EncodeToString(key))
 
 	dela.Logger.Info().Str("contract", ContractName).Msgf("setting %x=%s", key, value)
 
@@ -213,7 +261,16 @@ func (c valueCommand) delete(snap store.Snapshot, step execution.Step) error {
 		return xerrors.Errorf("failed to delete key '%x': %v", key, err)
 	}
 
-	delete(c.index, string(key))
+	if c.rent != nil {
+		err = c.rent.Untrack(snap, key)
+		if err != nil {
+			return xerrors.Errorf("failed to untrack rent: %v", err)
+		}
+	}
+
+	c.index.Remove(string(key))
+
+	step.Emit(ContractName, "delete", "key", hex.EncodeToString(key))
 
 	return nil
 }
@@ -222,12 +279,17 @@ func (c valueCommand) delete(snap store.Snapshot, step execution.Step) error {
 func (c valueCommand) list(snap store.Snapshot) error {
 	res := []string{}
 
-	for k := range c.index {
+	for _, k := range c.index.Items() {
 		v, err := snap.Get([]byte(k))
 		if err != nil {
 			return xerrors.Errorf("failed to get key '%s': %v", k, err)
 		}
 
+		// The key might have been deleted by the sweep of the rent.
+		if len(v) == 0 {
+			continue
+		}
+
 		res = append(res, fmt.Sprintf("%x=%s", k, v))
 	}
 
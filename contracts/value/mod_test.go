@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/contracts/rent"
+	"go.dedis.ch/dela/contracts/sdk"
 	"go.dedis.ch/dela/core/access"
 	"go.dedis.ch/dela/core/execution"
 	"go.dedis.ch/dela/core/execution/native"
@@ -66,20 +68,60 @@ func TestCommand_Write(t *testing.T) {
 
 	snap := fake.NewSnapshot()
 
-	_, found := contract.index["dummy"]
-	require.False(t, found)
+	require.False(t, contract.index.Has("dummy"))
+
+	step := makeStep(t, KeyArg, "dummy", ValueArg, "value")
+	step.Log = execution.NewLog()
 
-	err = cmd.write(snap, makeStep(t, KeyArg, "dummy", ValueArg, "value"))
+	err = cmd.write(snap, step)
 	require.NoError(t, err)
 
-	_, found = contract.index["dummy"]
-	require.True(t, found)
+	require.True(t, contract.index.Has("dummy"))
+	require.Equal(t, []execution.Event{execution.NewEvent(ContractName, "write",
+		"key", hex.EncodeToString([]byte("dummy")))}, step.Log.GetEvents())
 
 	res, err := snap.Get([]byte("dummy"))
 	require.NoError(t, err)
 	require.Equal(t, "value", string(res))
 }
 
+func TestCommand_Write_Rent(t *testing.T) {
+	contract := NewContract([]byte{}, fakeAccess{}, WithRent(rent.Policy{Initial: 10}))
+
+	cmd := valueCommand{
+		Contract: &contract,
+	}
+
+	snap := fake.NewSnapshot()
+
+	step := makeStep(t, KeyArg, "dummy", ValueArg, "value")
+	step.Index = 5
+
+	err := cmd.write(snap, step)
+	require.NoError(t, err)
+
+	entry, found, err := rent.GetEntry(snap, []byte("dummy"))
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(15), entry.PaidUntil)
+
+	err = cmd.delete(snap, makeStep(t, KeyArg, "dummy"))
+	require.NoError(t, err)
+
+	_, found, err = rent.GetEntry(snap, []byte("dummy"))
+	require.NoError(t, err)
+	require.False(t, found)
+
+	snap = fake.NewSnapshot(fake.WithKeyError(rentKey("dummy"), fake.GetError()))
+
+	err = cmd.write(snap, step)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to track rent: ")
+
+	err = cmd.delete(snap, step)
+	require.EqualError(t, err, fake.Err("failed to untrack rent: failed to delete entry"))
+}
+
 func TestCommand_Read(t *testing.T) {
 	contract := NewContract([]byte{}, fakeAccess{})
 
@@ -127,17 +169,21 @@ func TestCommand_Delete(t *testing.T) {
 
 	snap := fake.NewSnapshot()
 	snap.Set(key, []byte("value"))
-	contract.index[keyStr] = struct{}{}
+	contract.index.Add(keyStr)
+
+	step := makeStep(t, KeyArg, keyStr)
+	step.Log = execution.NewLog()
 
-	err = cmd.delete(snap, makeStep(t, KeyArg, keyStr))
+	err = cmd.delete(snap, step)
 	require.NoError(t, err)
+	require.Equal(t, []execution.Event{execution.NewEvent(ContractName, "delete",
+		"key", keyHex)}, step.Log.GetEvents())
 
 	res, err := snap.Get(key)
 	require.Nil(t, err)
 	require.Nil(t, res)
 
-	_, found := contract.index[keyStr]
-	require.False(t, found)
+	require.False(t, contract.index.Has(keyStr))
 }
 
 func TestCommand_List(t *testing.T) {
@@ -146,8 +192,8 @@ func TestCommand_List(t *testing.T) {
 	key1 := "key1"
 	key2 := "key2"
 
-	contract.index[key1] = struct{}{}
-	contract.index[key2] = struct{}{}
+	contract.index.Add(key2)
+	contract.index.Add(key1)
 
 	buf := &bytes.Buffer{}
 	contract.printer = buf
@@ -165,9 +211,16 @@ func TestCommand_List(t *testing.T) {
 
 	require.Equal(t, fmt.Sprintf("%x=value1,%x=value2", key1, key2), buf.String())
 
+	// Keys deleted by the rent are skipped.
+	snap.Delete([]byte(key1))
+	buf.Reset()
+
+	err = cmd.list(snap)
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("%x=value2", key2), buf.String())
+
 	err = cmd.list(fake.NewBadSnapshot())
-	// we can't assume an order from the map
-	require.Regexp(t, "^failed to get key", err.Error())
+	require.EqualError(t, err, fake.Err(fmt.Sprintf("failed to get key '%s'", key1)))
 }
 
 func TestInfoLog(t *testing.T) {
@@ -182,9 +235,33 @@ func TestRegisterContract(t *testing.T) {
 	RegisterContract(native.NewExecution(), Contract{})
 }
 
+func TestSchema_Validate(t *testing.T) {
+	schema := NewSchema()
+
+	err := schema.Validate(makeTx(t, CmdArg, "LIST"))
+	require.NoError(t, err)
+
+	err = schema.Validate(makeTx(t, CmdArg, "WRITE", KeyArg, "dummy", ValueArg, "value"))
+	require.NoError(t, err)
+
+	err = schema.Validate(makeTx(t, CmdArg, "WRITE", KeyArg, "dummy"))
+	require.EqualError(t, err, "WRITE: invalid arguments: 'value:value' is missing")
+
+	err = schema.Validate(makeTx(t, CmdArg, "READ"))
+	require.EqualError(t, err, "READ: invalid arguments: 'value:key' is missing")
+
+	err = schema.Validate(makeTx(t, CmdArg, "fake"))
+	require.EqualError(t, err,
+		"invalid arguments: 'value:command' must be one of [DELETE, LIST, READ, WRITE]")
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
+func rentKey(key string) []byte {
+	return sdk.NewKey("rent:entry", []byte(key))
+}
+
 func makeStep(t *testing.T, args ...string) execution.Step {
 	return execution.Step{Current: makeTx(t, args...)}
 }
@@ -0,0 +1,157 @@
+// This file contains the events that the contracts emit during the execution
+// of a transaction.
+
+package execution
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"sort"
+
+	"golang.org/x/xerrors"
+)
+
+// EventsPrefix is the domain of the keys of the state that commit to the events
+// of the transactions, so that they can be proven with the value of the key.
+const EventsPrefix = "go.dedis.ch/dela.Events:"
+
+// Attribute is a named value of an event.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Event is a typed notification emitted by a contract. The type is the name of
+// the event in the scope of its contract.
+type Event struct {
+	Contract   string
+	Name       string
+	Attributes []Attribute
+}
+
+// NewEvent returns an event with the attributes given by pairs of key and
+// value. The attributes are sorted by key so that the event is deterministic.
+func NewEvent(contract, name string, attrs ...string) Event {
+	e := Event{
+		Contract:   contract,
+		Name:       name,
+		Attributes: make([]Attribute, 0, len(attrs)/2),
+	}
+
+	for i := 0; i+1 < len(attrs); i += 2 {
+		e.Attributes = append(e.Attributes, Attribute{Key: attrs[i], Value: attrs[i+1]})
+	}
+
+	sort.SliceStable(e.Attributes, func(i, j int) bool {
+		return e.Attributes[i].Key < e.Attributes[j].Key
+	})
+
+	return e
+}
+
+// Get returns the value of the attribute, or an empty string if it is not
+// set.
+func (e Event) Get(key string) string {
+	for _, attr := range e.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+
+	return ""
+}
+
+// Fingerprint writes a deterministic binary representation of the event. Each
+// field is prefixed with its length so that two events never share the same
+// representation.
+func (e Event) Fingerprint(w io.Writer) error {
+	fields := []string{e.Contract, e.Name}
+	for _, attr := range e.Attributes {
+		fields = append(fields, attr.Key, attr.Value)
+	}
+
+	err := writeUint(w, uint64(len(e.Attributes)))
+	if err != nil {
+		return xerrors.Errorf("couldn't write attributes: %v", err)
+	}
+
+	for _, field := range fields {
+		err = writeUint(w, uint64(len(field)))
+		if err != nil {
+			return xerrors.Errorf("couldn't write length: %v", err)
+		}
+
+		_, err = w.Write([]byte(field))
+		if err != nil {
+			return xerrors.Errorf("couldn't write field: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Log is the list of the events emitted during the execution of a
+// transaction. A nil log discards the events.
+type Log struct {
+	events []Event
+}
+
+// NewLog returns a new empty log.
+func NewLog() *Log {
+	return &Log{}
+}
+
+// Emit appends the event to the log.
+func (l *Log) Emit(e Event) {
+	if l == nil {
+		return
+	}
+
+	l.events = append(l.events, e)
+}
+
+// GetEvents returns the events in the order they were emitted.
+func (l *Log) GetEvents() []Event {
+	if l == nil {
+		return nil
+	}
+
+	return append([]Event{}, l.events...)
+}
+
+// EventsKey returns the key of the state that commits to the events of the
+// transaction. It is the hash of the domain and the identifier so that it fits
+// the size of the keys of the store.
+func EventsKey(txID []byte) []byte {
+	h := sha256.Sum256(append([]byte(EventsPrefix), txID...))
+	return h[:]
+}
+
+// EventsDigest returns the digest of the events of a transaction, which is the
+// value of its key in the state.
+func EventsDigest(events []Event) ([]byte, error) {
+	h := sha256.New()
+
+	err := writeUint(h, uint64(len(events)))
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't write length: %v", err)
+	}
+
+	for i, e := range events {
+		err = e.Fingerprint(h)
+		if err != nil {
+			return nil, xerrors.Errorf("event %d: %v", i, err)
+		}
+	}
+
+	return h.Sum(nil), nil
+}
+
+func writeUint(w io.Writer, value uint64) error {
+	buffer := make([]byte, 8)
+	binary.BigEndian.PutUint64(buffer, value)
+
+	_, err := w.Write(buffer)
+	return err
+}
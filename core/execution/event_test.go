@@ -0,0 +1,70 @@
+package execution
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestNewEvent(t *testing.T) {
+	e := NewEvent("contract", "name", "b", "2", "a", "1", "c")
+
+	require.Equal(t, []Attribute{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}, e.Attributes)
+	require.Equal(t, "1", e.Get("a"))
+	require.Equal(t, "", e.Get("c"))
+}
+
+func TestEvent_Fingerprint(t *testing.T) {
+	buffer := new(bytes.Buffer)
+
+	err := NewEvent("ab", "c").Fingerprint(buffer)
+	require.NoError(t, err)
+
+	other := new(bytes.Buffer)
+
+	err = NewEvent("a", "bc").Fingerprint(other)
+	require.NoError(t, err)
+	require.NotEqual(t, buffer.Bytes(), other.Bytes())
+
+	err = NewEvent("a", "b", "c", "d").Fingerprint(fake.NewBadHash())
+	require.EqualError(t, err, fake.Err("couldn't write attributes"))
+
+	err = NewEvent("a", "b").Fingerprint(fake.NewBadHashWithDelay(1))
+	require.EqualError(t, err, fake.Err("couldn't write length"))
+
+	err = NewEvent("a", "b").Fingerprint(fake.NewBadHashWithDelay(2))
+	require.EqualError(t, err, fake.Err("couldn't write field"))
+}
+
+func TestLog_Emit(t *testing.T) {
+	log := NewLog()
+
+	step := Step{Log: log}
+	step.Emit("contract", "a")
+	step.Emit("contract", "b", "key", "value")
+
+	require.Equal(t, []Event{
+		NewEvent("contract", "a"),
+		NewEvent("contract", "b", "key", "value"),
+	}, log.GetEvents())
+
+	// A step without a log discards the events.
+	step = Step{}
+	step.Emit("contract", "a")
+	require.Nil(t, step.Log.GetEvents())
+}
+
+func TestEventsDigest(t *testing.T) {
+	a, err := EventsDigest([]Event{NewEvent("contract", "a")})
+	require.NoError(t, err)
+	require.Len(t, a, 32)
+
+	b, err := EventsDigest([]Event{NewEvent("contract", "a"), NewEvent("contract", "a")})
+	require.NoError(t, err)
+	require.NotEqual(t, a, b)
+
+	require.Len(t, EventsKey([]byte{1}), 32)
+	require.NotEqual(t, EventsKey([]byte{1}), EventsKey([]byte{2}))
+}
@@ -0,0 +1,103 @@
+package execution
+
+import (
+	"encoding/binary"
+
+	"go.dedis.ch/dela/core/txn"
+	"golang.org/x/xerrors"
+)
+
+// GasLimitArg is the argument key in the transaction that contains the maximum
+// amount of gas it can consume, as an 8-byte big-endian number.
+const GasLimitArg = "go.dedis.ch/dela.GasLimit"
+
+// ErrOutOfGas is the error returned when an execution consumes more gas than
+// its limit.
+var ErrOutOfGas = xerrors.New("out of gas")
+
+// Meter counts the units of gas consumed by the execution of a transaction. A
+// nil meter never runs out of gas, so that the contracts can report their
+// consumption whether the execution is metered or not.
+type Meter struct {
+	limit     uint64
+	used      uint64
+	exhausted bool
+}
+
+// NewMeter returns a meter that allows the given amount of gas.
+func NewMeter(limit uint64) *Meter {
+	return &Meter{limit: limit}
+}
+
+// Consume adds the units to the gas used, or returns an error that wraps
+// ErrOutOfGas if it goes above the limit. The gas used is then set to the
+// limit, as the execution must stop.
+func (m *Meter) Consume(units uint64) error {
+	if m == nil {
+		return nil
+	}
+
+	// The sum is checked for an overflow before the limit.
+	if m.used+units < m.used || m.used+units > m.limit {
+		m.used = m.limit
+		m.exhausted = true
+
+		return xerrors.Errorf("consuming %d unit(s) exceeds the limit of %d: %w",
+			units, m.limit, ErrOutOfGas)
+	}
+
+	m.used += units
+
+	return nil
+}
+
+// GetUsed returns the amount of gas consumed so far.
+func (m *Meter) GetUsed() uint64 {
+	if m == nil {
+		return 0
+	}
+
+	return m.used
+}
+
+// IsExhausted returns true if the meter ran out of gas, even if the error was
+// not returned by the execution.
+func (m *Meter) IsExhausted() bool {
+	if m == nil {
+		return false
+	}
+
+	return m.exhausted
+}
+
+// GetLimit returns the maximum amount of gas.
+func (m *Meter) GetLimit() uint64 {
+	if m == nil {
+		return 0
+	}
+
+	return m.limit
+}
+
+// GasLimit returns the gas limit of the transaction, and false if it doesn't
+// declare one.
+func GasLimit(tx txn.Transaction) (uint64, bool, error) {
+	value := tx.GetArg(GasLimitArg)
+	if value == nil {
+		return 0, false, nil
+	}
+
+	if len(value) != 8 {
+		return 0, false, xerrors.Errorf("invalid gas limit of %d bytes", len(value))
+	}
+
+	return binary.BigEndian.Uint64(value), true, nil
+}
+
+// MakeGasLimitArg returns the argument of a gas limit.
+func MakeGasLimitArg(limit uint64) txn.Arg {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, limit)
+
+	return txn.Arg{Key: GasLimitArg, Value: value}
+}
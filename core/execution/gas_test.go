@@ -0,0 +1,67 @@
+package execution
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/txn"
+	"golang.org/x/xerrors"
+)
+
+func TestMeter_Consume(t *testing.T) {
+	meter := NewMeter(10)
+
+	require.NoError(t, meter.Consume(4))
+	require.NoError(t, meter.Consume(6))
+	require.Equal(t, uint64(10), meter.GetUsed())
+	require.Equal(t, uint64(10), meter.GetLimit())
+	require.False(t, meter.IsExhausted())
+
+	err := meter.Consume(1)
+	require.EqualError(t, err, "consuming 1 unit(s) exceeds the limit of 10: out of gas")
+	require.True(t, xerrors.Is(err, ErrOutOfGas))
+	require.True(t, meter.IsExhausted())
+
+	meter = NewMeter(10)
+	require.NoError(t, meter.Consume(5))
+
+	err = meter.Consume(^uint64(0))
+	require.True(t, xerrors.Is(err, ErrOutOfGas))
+	require.Equal(t, uint64(10), meter.GetUsed())
+
+	meter = nil
+	require.NoError(t, meter.Consume(^uint64(0)))
+	require.Equal(t, uint64(0), meter.GetUsed())
+	require.Equal(t, uint64(0), meter.GetLimit())
+	require.False(t, meter.IsExhausted())
+}
+
+func TestGasLimit(t *testing.T) {
+	limit, found, err := GasLimit(fakeTx{})
+	require.NoError(t, err)
+	require.False(t, found)
+	require.Equal(t, uint64(0), limit)
+
+	arg := MakeGasLimitArg(42)
+
+	limit, found, err = GasLimit(fakeTx{value: arg.Value})
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(42), limit)
+
+	_, _, err = GasLimit(fakeTx{value: []byte{1}})
+	require.EqualError(t, err, "invalid gas limit of 1 bytes")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeTx struct {
+	txn.Transaction
+
+	value []byte
+}
+
+func (tx fakeTx) GetArg(key string) []byte {
+	return tx.value
+}
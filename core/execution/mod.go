@@ -13,9 +13,42 @@ import (
 // Step is a context of execution. It allows for example a smart contract to
 // execute a given transaction knowing what previous transactions have already
 // been accepted and executed in a block.
+//
+// The index of the block being executed gives the contracts a deterministic
+// clock that does not live in the state.
+//
+// The meter, when it is set, limits the gas of the current transaction. The
+// contracts report the units they consume to it.
+//
+// The log, when it is set, records the events emitted by the contracts for the
+// current transaction.
+//
+// The calls are the names of the contracts that execute the current
+// transaction, from the one of the transaction to the current one, when a
+// contract calls another.
 type Step struct {
+	Index    uint64
 	Previous []txn.Transaction
 	Current  txn.Transaction
+	Gas      *Meter
+	Log      *Log
+	Calls    []string
+}
+
+// GetCaller returns the name of the contract that called the current one, or
+// an empty string when the current contract is the one of the transaction.
+func (s Step) GetCaller() string {
+	if len(s.Calls) < 2 {
+		return ""
+	}
+
+	return s.Calls[len(s.Calls)-2]
+}
+
+// Emit records an event of the contract with the attributes given by pairs of
+// key and value. It does nothing when the step has no log.
+func (s Step) Emit(contract, name string, attrs ...string) {
+	s.Log.Emit(NewEvent(contract, name, attrs...))
 }
 
 // Result is the result of a transaction execution.
@@ -26,6 +59,12 @@ type Result struct {
 	// Message gives a change to the execution to explain why a transaction has
 	// failed.
 	Message string
+
+	// GasUsed is the amount of gas consumed by a metered execution.
+	GasUsed uint64
+
+	// Events are the events emitted by an accepted transaction.
+	Events []Event
 }
 
 // Service is the execution service that defines the primitives to execute a
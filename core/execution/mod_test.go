@@ -0,0 +1,18 @@
+package execution
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStep_GetCaller(t *testing.T) {
+	step := Step{}
+	require.Equal(t, "", step.GetCaller())
+
+	step.Calls = []string{"a"}
+	require.Equal(t, "", step.GetCaller())
+
+	step.Calls = []string{"a", "b", "c"}
+	require.Equal(t, "b", step.GetCaller())
+}
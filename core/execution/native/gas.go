@@ -0,0 +1,132 @@
+// This file contains the metering of the accesses of the contracts to the
+// snapshot.
+
+package native
+
+import (
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/store"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// ReadGas is the gas consumed by a read of the snapshot.
+	ReadGas = 100
+
+	// WriteGas is the gas consumed by a write or a deletion in the snapshot.
+	WriteGas = 1000
+
+	// ByteGas is the gas consumed by each byte of a key or a value read or
+	// written.
+	ByteGas = 1
+)
+
+// meteredSnapshot is a snapshot that charges the accesses of a contract to a
+// meter and buffers the writes, so that they can be discarded when the
+// execution fails or runs out of gas. Without a meter, it only buffers the
+// writes.
+//
+// - implements store.Snapshot
+type meteredSnapshot struct {
+	snap   store.Snapshot
+	meter  *execution.Meter
+	keys   []string
+	writes map[string]*bufferedWrite
+}
+
+type bufferedWrite struct {
+	value   []byte
+	deleted bool
+}
+
+func newMeteredSnapshot(snap store.Snapshot, meter *execution.Meter) *meteredSnapshot {
+	return &meteredSnapshot{
+		snap:   snap,
+		meter:  meter,
+		writes: make(map[string]*bufferedWrite),
+	}
+}
+
+// Get implements store.Readable. It returns the buffered value of the key if
+// it is written, otherwise the one of the snapshot.
+func (s *meteredSnapshot) Get(key []byte) ([]byte, error) {
+	err := s.meter.Consume(ReadGas + uint64(len(key))*ByteGas)
+	if err != nil {
+		return nil, err
+	}
+
+	var value []byte
+
+	w, found := s.writes[string(key)]
+	if found {
+		if !w.deleted {
+			value = append([]byte{}, w.value...)
+		}
+	} else {
+		value, err = s.snap.Get(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.meter.Consume(uint64(len(value)) * ByteGas)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// Set implements store.Writable. It buffers the value of the key.
+func (s *meteredSnapshot) Set(key []byte, value []byte) error {
+	err := s.meter.Consume(WriteGas + uint64(len(key)+len(value))*ByteGas)
+	if err != nil {
+		return err
+	}
+
+	s.buffer(key, &bufferedWrite{value: append([]byte{}, value...)})
+
+	return nil
+}
+
+// Delete implements store.Writable. It buffers the deletion of the key.
+func (s *meteredSnapshot) Delete(key []byte) error {
+	err := s.meter.Consume(WriteGas + uint64(len(key))*ByteGas)
+	if err != nil {
+		return err
+	}
+
+	s.buffer(key, &bufferedWrite{deleted: true})
+
+	return nil
+}
+
+func (s *meteredSnapshot) buffer(key []byte, w *bufferedWrite) {
+	_, found := s.writes[string(key)]
+	if !found {
+		s.keys = append(s.keys, string(key))
+	}
+
+	s.writes[string(key)] = w
+}
+
+// apply writes the buffered values to the snapshot in the order the keys were
+// first written, so that every node applies them the same way.
+func (s *meteredSnapshot) apply() error {
+	for _, key := range s.keys {
+		w := s.writes[key]
+
+		var err error
+		if w.deleted {
+			err = s.snap.Delete([]byte(key))
+		} else {
+			err = s.snap.Set([]byte(key), w.value)
+		}
+
+		if err != nil {
+			return xerrors.Errorf("failed to write key %#x: %v", key, err)
+		}
+	}
+
+	return nil
+}
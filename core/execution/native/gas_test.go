@@ -0,0 +1,94 @@
+package native
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"golang.org/x/xerrors"
+)
+
+func TestMeteredSnapshot_Get(t *testing.T) {
+	snap := fake.NewSnapshot()
+	snap.Set([]byte("A"), []byte("abc"))
+
+	meter := execution.NewMeter(1000)
+	metered := newMeteredSnapshot(snap, meter)
+
+	value, err := metered.Get([]byte("A"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("abc"), value)
+	require.Equal(t, uint64(ReadGas+4*ByteGas), meter.GetUsed())
+
+	value, err = metered.Get([]byte("B"))
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	metered = newMeteredSnapshot(snap, execution.NewMeter(ReadGas+1))
+
+	_, err = metered.Get([]byte("A"))
+	require.True(t, xerrors.Is(err, execution.ErrOutOfGas))
+
+	metered = newMeteredSnapshot(snap, execution.NewMeter(ReadGas))
+
+	_, err = metered.Get([]byte("A"))
+	require.True(t, xerrors.Is(err, execution.ErrOutOfGas))
+
+	metered = newMeteredSnapshot(fake.NewBadSnapshot(), execution.NewMeter(1000))
+
+	_, err = metered.Get([]byte("A"))
+	require.EqualError(t, err, fake.GetError().Error())
+}
+
+func TestMeteredSnapshot_Write(t *testing.T) {
+	snap := fake.NewSnapshot()
+	snap.Set([]byte("A"), []byte("abc"))
+
+	meter := execution.NewMeter(10000)
+	metered := newMeteredSnapshot(snap, meter)
+
+	require.NoError(t, metered.Set([]byte("B"), []byte("def")))
+	require.NoError(t, metered.Delete([]byte("A")))
+	require.Equal(t, uint64(2*WriteGas+5*ByteGas), meter.GetUsed())
+
+	// The writes are visible to the contract but not yet to the snapshot.
+	value, err := metered.Get([]byte("A"))
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	value, err = metered.Get([]byte("B"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("def"), value)
+
+	value, err = snap.Get([]byte("A"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("abc"), value)
+
+	require.NoError(t, metered.Set([]byte("A"), []byte("ghi")))
+	require.Len(t, metered.keys, 2)
+
+	require.NoError(t, metered.apply())
+
+	value, err = snap.Get([]byte("A"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("ghi"), value)
+
+	value, err = snap.Get([]byte("B"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("def"), value)
+
+	metered = newMeteredSnapshot(snap, execution.NewMeter(WriteGas))
+
+	err = metered.Set([]byte("A"), nil)
+	require.True(t, xerrors.Is(err, execution.ErrOutOfGas))
+
+	err = metered.Delete([]byte("A"))
+	require.True(t, xerrors.Is(err, execution.ErrOutOfGas))
+
+	metered = newMeteredSnapshot(fake.NewBadSnapshot(), execution.NewMeter(10000))
+	require.NoError(t, metered.Delete([]byte("A")))
+
+	err = metered.apply()
+	require.EqualError(t, err, fake.Err("failed to write key 0x41"))
+}
@@ -9,12 +9,17 @@ package native
 import (
 	"go.dedis.ch/dela/core/execution"
 	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn"
 	"golang.org/x/xerrors"
 )
 
 const (
 	// ContractArg is the argument key in the transaction to look up a contract.
 	ContractArg = "go.dedis.ch/dela.ContractArg"
+
+	// MaxCallDepth is the maximum number of contracts that execute a
+	// transaction by calling each other, including the one of the transaction.
+	MaxCallDepth = 8
 )
 
 // Contract is the interface to implement to register a smart contract that will
@@ -29,6 +34,8 @@ type Contract interface {
 // - implements execution.Service
 type Service struct {
 	contracts map[string]Contract
+	schemas   map[string]Schema
+	policy    Policy
 }
 
 // NewExecution returns a new native execution. The given service will be
@@ -36,6 +43,7 @@ type Service struct {
 func NewExecution() *Service {
 	return &Service{
 		contracts: map[string]Contract{},
+		schemas:   map[string]Schema{},
 	}
 }
 
@@ -45,8 +53,70 @@ func (ns *Service) Set(name string, contract Contract) {
 	ns.contracts[name] = contract
 }
 
+// SetSchema stores the schema of the arguments of the contract with the given
+// name. The schema is optional and is used to reject malformed transactions
+// before they are executed.
+func (ns *Service) SetSchema(name string, schema Schema) {
+	ns.schemas[name] = schema
+}
+
+// CheckArgs returns nil if the transaction targets a known contract and its
+// arguments comply with the schema of the contract, if any.
+func (ns *Service) CheckArgs(tx txn.Transaction) error {
+	name := string(tx.GetArg(ContractArg))
+
+	if ns.contracts[name] == nil {
+		return xerrors.Errorf("unknown contract '%s'", name)
+	}
+
+	schema := ns.schemas[name]
+	if schema == nil {
+		return nil
+	}
+
+	err := schema.Validate(tx)
+	if err != nil {
+		return xerrors.Errorf("contract '%s': %v", name, err)
+	}
+
+	return nil
+}
+
+// SetPolicy sets the local policy of the node. The policy never applies to the
+// execution of transactions so that the result stays the same across the
+// participants.
+func (ns *Service) SetPolicy(policy Policy) {
+	ns.policy = policy
+}
+
+// GetPolicy returns the local policy of the node.
+func (ns *Service) GetPolicy() Policy {
+	return ns.policy
+}
+
+// IsServed returns nil if the contract is known and allowed by the local
+// policy to be served by the node, otherwise an error.
+func (ns *Service) IsServed(name string) error {
+	if ns.contracts[name] == nil {
+		return xerrors.Errorf("unknown contract '%s'", name)
+	}
+
+	err := ns.policy.Check(name)
+	if err != nil {
+		return xerrors.Errorf("policy: %v", err)
+	}
+
+	return nil
+}
+
 // Execute implements execution.Service. It uses the executor to process the
-// incoming transaction and return the result.
+// incoming transaction and return the result. The local policy is ignored.
+//
+// When the step has a meter, the accesses of the contract to the snapshot
+// consume gas and its writes are applied only if it succeeds within the limit.
+//
+// The events emitted by an accepted transaction are returned in the result,
+// and their digest is written in the state so that they can be proven.
 func (ns *Service) Execute(snap store.Snapshot, step execution.Step) (execution.Result, error) {
 	name := string(step.Current.GetArg(ContractArg))
 
@@ -55,6 +125,13 @@ func (ns *Service) Execute(snap store.Snapshot, step execution.Step) (execution.
 		return execution.Result{}, xerrors.Errorf("unknown contract '%s'", name)
 	}
 
+	step.Log = execution.NewLog()
+	step.Calls = []string{name}
+
+	if step.Gas != nil {
+		return ns.executeMetered(contract, snap, step)
+	}
+
 	res := execution.Result{
 		Accepted: true,
 	}
@@ -63,7 +140,159 @@ func (ns *Service) Execute(snap store.Snapshot, step execution.Step) (execution.
 	if err != nil {
 		res.Accepted = false
 		res.Message = err.Error()
+
+		return res, nil
+	}
+
+	res.Events, err = commitEvents(snap, step)
+	if err != nil {
+		return execution.Result{}, err
 	}
 
 	return res, nil
 }
+
+// Call executes the contract with the given name within the execution of the
+// step by another contract. The callee reads the arguments given by the caller
+// instead of the ones of the transaction, but it has the same identifier and
+// author. It shares the snapshot, the meter and the log of the caller, and it
+// checks the access of the author of the transaction like it does when it is
+// the contract of the transaction. Its writes and its events are kept only if
+// it succeeds, so that the caller can recover from a failed call. A contract
+// cannot be called while it is already executing the transaction.
+func (ns *Service) Call(name string, snap store.Snapshot, step execution.Step,
+	args ...txn.Arg) error {
+
+	contract := ns.contracts[name]
+	if contract == nil {
+		return xerrors.Errorf("unknown contract '%s'", name)
+	}
+
+	for _, caller := range step.Calls {
+		if caller == name {
+			return xerrors.Errorf("reentrant call to '%s'", name)
+		}
+	}
+
+	if len(step.Calls) >= MaxCallDepth {
+		return xerrors.Errorf("call to '%s' exceeds the depth of %d", name, MaxCallDepth)
+	}
+
+	log := step.Log
+
+	step.Calls = append(append([]string{}, step.Calls...), name)
+	step.Log = execution.NewLog()
+	step.Current = newCallTransaction(step.Current, name, args)
+
+	// The accesses are metered by the snapshot of the caller, therefore the
+	// buffer of the call doesn't have a meter.
+	buffer := newMeteredSnapshot(snap, nil)
+
+	err := contract.Execute(buffer, step)
+	if err == nil && step.Gas.IsExhausted() {
+		err = execution.ErrOutOfGas
+	}
+
+	if err != nil {
+		return xerrors.Errorf("contract '%s': %w", name, err)
+	}
+
+	err = buffer.apply()
+	if err != nil {
+		return xerrors.Errorf("failed to apply: %v", err)
+	}
+
+	for _, event := range step.Log.GetEvents() {
+		log.Emit(event)
+	}
+
+	return nil
+}
+
+// callTransaction is the view of the transaction that a contract receives when
+// another contract calls it.
+//
+// - implements txn.Transaction
+type callTransaction struct {
+	txn.Transaction
+
+	args map[string][]byte
+}
+
+func newCallTransaction(tx txn.Transaction, name string, args []txn.Arg) callTransaction {
+	call := callTransaction{
+		Transaction: tx,
+		args:        map[string][]byte{ContractArg: []byte(name)},
+	}
+
+	for _, arg := range args {
+		if arg.Key != ContractArg {
+			call.args[arg.Key] = arg.Value
+		}
+	}
+
+	return call
+}
+
+// GetArg implements txn.Transaction. It returns the argument given by the
+// caller, or nil if it is not set.
+func (tx callTransaction) GetArg(key string) []byte {
+	return tx.args[key]
+}
+
+func (ns *Service) executeMetered(contract Contract, snap store.Snapshot,
+	step execution.Step) (execution.Result, error) {
+
+	metered := newMeteredSnapshot(snap, step.Gas)
+
+	err := contract.Execute(metered, step)
+	if err == nil && step.Gas.IsExhausted() {
+		// The contract must not ignore the error of the meter.
+		err = execution.ErrOutOfGas
+	}
+
+	res := execution.Result{
+		GasUsed: step.Gas.GetUsed(),
+	}
+
+	if err != nil {
+		res.Message = err.Error()
+
+		return res, nil
+	}
+
+	err = metered.apply()
+	if err != nil {
+		return execution.Result{}, xerrors.Errorf("failed to apply: %v", err)
+	}
+
+	res.Events, err = commitEvents(snap, step)
+	if err != nil {
+		return execution.Result{}, err
+	}
+
+	res.Accepted = true
+
+	return res, nil
+}
+
+// commitEvents writes the digest of the events of the step, if any, at the key
+// of the transaction and returns them.
+func commitEvents(snap store.Snapshot, step execution.Step) ([]execution.Event, error) {
+	events := step.Log.GetEvents()
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	digest, err := execution.EventsDigest(events)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to digest events: %v", err)
+	}
+
+	err = snap.Set(execution.EventsKey(step.Current.GetID()), digest)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to commit events: %v", err)
+	}
+
+	return events, nil
+}
@@ -8,6 +8,7 @@ import (
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/internal/testing/fake"
+	"golang.org/x/xerrors"
 )
 
 func TestService_Execute(t *testing.T) {
@@ -30,24 +31,283 @@ func TestService_Execute(t *testing.T) {
 	step.Current = fakeTx{contract: "none"}
 	_, err = srvc.Execute(nil, step)
 	require.EqualError(t, err, "unknown contract 'none'")
+
+	srvc.SetPolicy(NewDenyList("bad"))
+
+	step.Current = fakeTx{contract: "bad"}
+	res, err = srvc.Execute(nil, step)
+	require.NoError(t, err)
+	require.Equal(t, execution.Result{Message: fake.GetError().Error()}, res)
+}
+
+func TestService_Execute_Metered(t *testing.T) {
+	srvc := NewExecution()
+	srvc.Set("abc", fakeExec{key: []byte("A")})
+	srvc.Set("bad", fakeExec{key: []byte("A"), err: fake.GetError()})
+	srvc.Set("greedy", fakeExec{key: []byte("A"), ignore: true})
+
+	snap := fake.NewSnapshot()
+
+	step := execution.Step{Gas: execution.NewMeter(10000)}
+	step.Current = fakeTx{contract: "abc"}
+
+	res, err := srvc.Execute(snap, step)
+	require.NoError(t, err)
+	require.Equal(t, execution.Result{Accepted: true, GasUsed: WriteGas + 2}, res)
+
+	value, err := snap.Get([]byte("A"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("A"), value)
+
+	// The writes of a failed execution are discarded, but the gas is used.
+	snap = fake.NewSnapshot()
+	step.Gas = execution.NewMeter(10000)
+	step.Current = fakeTx{contract: "bad"}
+
+	res, err = srvc.Execute(snap, step)
+	require.NoError(t, err)
+	require.Equal(t, execution.Result{Message: fake.GetError().Error(), GasUsed: WriteGas + 2}, res)
+	require.Equal(t, 0, snap.Len())
+
+	// A contract cannot ignore the meter.
+	step.Gas = execution.NewMeter(10)
+	step.Current = fakeTx{contract: "greedy"}
+
+	res, err = srvc.Execute(snap, step)
+	require.NoError(t, err)
+	require.Equal(t, execution.Result{Message: "out of gas", GasUsed: 10}, res)
+	require.Equal(t, 0, snap.Len())
+
+	step.Gas = execution.NewMeter(10000)
+	step.Current = fakeTx{contract: "abc"}
+
+	_, err = srvc.Execute(fake.NewBadSnapshot(), step)
+	require.EqualError(t, err, fake.Err("failed to apply: failed to write key 0x41"))
+}
+
+func TestService_Execute_Events(t *testing.T) {
+	srvc := NewExecution()
+	srvc.Set("abc", fakeExec{event: "set"})
+	srvc.Set("bad", fakeExec{event: "set", err: fake.GetError()})
+
+	snap := fake.NewSnapshot()
+
+	step := execution.Step{}
+	step.Current = fakeTx{contract: "abc"}
+
+	expected := []execution.Event{execution.NewEvent("fake", "set", "key", "value")}
+
+	res, err := srvc.Execute(snap, step)
+	require.NoError(t, err)
+	require.True(t, res.Accepted)
+	require.Equal(t, expected, res.Events)
+
+	digest, err := execution.EventsDigest(expected)
+	require.NoError(t, err)
+
+	value, err := snap.Get(execution.EventsKey([]byte{0xaa}))
+	require.NoError(t, err)
+	require.Equal(t, digest, value)
+
+	// The events of a refused transaction are discarded.
+	snap = fake.NewSnapshot()
+	step.Current = fakeTx{contract: "bad"}
+
+	res, err = srvc.Execute(snap, step)
+	require.NoError(t, err)
+	require.Nil(t, res.Events)
+	require.Equal(t, 0, snap.Len())
+
+	step.Gas = execution.NewMeter(10000)
+	step.Current = fakeTx{contract: "abc"}
+
+	res, err = srvc.Execute(snap, step)
+	require.NoError(t, err)
+	require.Equal(t, expected, res.Events)
+	require.Equal(t, 1, snap.Len())
+
+	_, err = srvc.Execute(fake.NewBadSnapshot(), step)
+	require.EqualError(t, err, fake.Err("failed to commit events"))
+
+	step.Gas = nil
+
+	_, err = srvc.Execute(fake.NewBadSnapshot(), step)
+	require.EqualError(t, err, fake.Err("failed to commit events"))
+}
+
+func TestService_Call(t *testing.T) {
+	calls := []string{}
+
+	srvc := NewExecution()
+	srvc.Set("abc", fakeExec{key: []byte("A"), event: "set", calls: &calls})
+	srvc.Set("bad", fakeExec{key: []byte("A"), event: "set", err: fake.GetError()})
+	srvc.Set("caller", callerExec{srvc: srvc, callee: "abc"})
+	srvc.Set("tolerant", callerExec{srvc: srvc, callee: "bad", tolerate: true})
+	srvc.Set("reentrant", callerExec{srvc: srvc, callee: "reentrant"})
+	srvc.Set("writer", writerExec{})
+	srvc.Set("forwarder", callerExec{srvc: srvc, callee: "writer", args: []txn.Arg{
+		{Key: "key", Value: []byte("B")},
+		{Key: "value", Value: []byte("b")},
+		{Key: ContractArg, Value: []byte("forwarder")},
+	}})
+
+	snap := fake.NewSnapshot()
+
+	step := execution.Step{}
+	step.Current = fakeTx{contract: "caller"}
+
+	res, err := srvc.Execute(snap, step)
+	require.NoError(t, err)
+	require.True(t, res.Accepted)
+	require.Equal(t, []execution.Event{execution.NewEvent("fake", "set", "key", "value")}, res.Events)
+	require.Equal(t, []string{"caller", "abc"}, calls)
+
+	value, err := snap.Get([]byte("A"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("A"), value)
+
+	// The writes and the events of a failed call are discarded.
+	snap = fake.NewSnapshot()
+	step.Current = fakeTx{contract: "tolerant"}
+
+	res, err = srvc.Execute(snap, step)
+	require.NoError(t, err)
+	require.True(t, res.Accepted)
+	require.Empty(t, res.Events)
+	require.Equal(t, 1, snap.Len())
+
+	// The callee reads the arguments of the caller instead of the ones of the
+	// transaction.
+	snap = fake.NewSnapshot()
+	step.Current = fakeTx{contract: "forwarder"}
+
+	res, err = srvc.Execute(snap, step)
+	require.NoError(t, err)
+	require.True(t, res.Accepted, res.Message)
+
+	value, err = snap.Get([]byte("B"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("b"), value)
+
+	step.Current = fakeTx{contract: "caller"}
+	err = srvc.Call("writer", snap, step)
+	require.EqualError(t, err, "contract 'writer': missing key")
+
+	step.Current = fakeTx{contract: "reentrant"}
+
+	res, err = srvc.Execute(snap, step)
+	require.NoError(t, err)
+	require.Equal(t, "reentrant call to 'reentrant'", res.Message)
+
+	step.Calls = []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	err = srvc.Call("abc", snap, step)
+	require.EqualError(t, err, "call to 'abc' exceeds the depth of 8")
+
+	err = srvc.Call("none", snap, step)
+	require.EqualError(t, err, "unknown contract 'none'")
+
+	// A callee cannot ignore the meter.
+	step.Calls = nil
+	step.Gas = execution.NewMeter(0)
+	require.Error(t, step.Gas.Consume(1))
+
+	err = srvc.Call("abc", snap, step)
+	require.True(t, xerrors.Is(err, execution.ErrOutOfGas))
+
+	step.Gas = nil
+	err = srvc.Call("abc", fake.NewBadSnapshot(), step)
+	require.EqualError(t, err, fake.Err("failed to apply: failed to write key 0x41"))
+}
+
+func TestService_IsServed(t *testing.T) {
+	srvc := NewExecution()
+	srvc.Set("abc", fakeExec{})
+	srvc.Set("def", fakeExec{})
+
+	require.NoError(t, srvc.IsServed("abc"))
+	require.EqualError(t, srvc.IsServed("none"), "unknown contract 'none'")
+
+	srvc.SetPolicy(NewAllowList("abc"))
+	require.NoError(t, srvc.IsServed("abc"))
+	require.EqualError(t, srvc.IsServed("def"),
+		"policy: contract 'def' is not in the allow list")
 }
 
 // -----------------------------------------------------------------------------
 // Utility functions
 
 type fakeExec struct {
-	err error
+	key    []byte
+	ignore bool
+	event  string
+	calls  *[]string
+	err    error
 }
 
-func (e fakeExec) Execute(store.Snapshot, execution.Step) error {
+func (e fakeExec) Execute(snap store.Snapshot, step execution.Step) error {
+	if e.calls != nil {
+		*e.calls = step.Calls
+	}
+
+	if e.event != "" {
+		step.Emit("fake", e.event, "key", "value")
+	}
+
+	if e.key != nil {
+		err := snap.Set(e.key, e.key)
+		if err != nil && !e.ignore {
+			return err
+		}
+	}
+
 	return e.err
 }
 
+type callerExec struct {
+	srvc     *Service
+	callee   string
+	args     []txn.Arg
+	tolerate bool
+}
+
+func (e callerExec) Execute(snap store.Snapshot, step execution.Step) error {
+	err := snap.Set([]byte("C"), []byte("C"))
+	if err != nil {
+		return err
+	}
+
+	err = e.srvc.Call(e.callee, snap, step, e.args...)
+	if err != nil && !e.tolerate {
+		return err
+	}
+
+	return nil
+}
+
+type writerExec struct{}
+
+func (writerExec) Execute(snap store.Snapshot, step execution.Step) error {
+	if string(step.Current.GetArg(ContractArg)) != "writer" {
+		return xerrors.New("wrong contract")
+	}
+
+	key := step.Current.GetArg("key")
+	if key == nil {
+		return xerrors.New("missing key")
+	}
+
+	return snap.Set(key, step.Current.GetArg("value"))
+}
+
 type fakeTx struct {
 	txn.Transaction
 	contract string
 }
 
+func (tx fakeTx) GetID() []byte {
+	return []byte{0xaa}
+}
+
 func (tx fakeTx) GetArg(key string) []byte {
 	return []byte(tx.contract)
 }
@@ -0,0 +1,95 @@
+// This file contains the implementation of the local policy that a node
+// operator can use to restrict the contracts served by the node.
+//
+// The policy is a strictly local concern: it never changes the outcome of an
+// execution, which must stay deterministic across the participants, but it
+// allows a node to refuse to serve the queries and the submissions of the
+// transactions of some contracts. It is applied by the endpoints of the proxy
+// and never by the pool, as a node that refuses a transaction would otherwise
+// censor it when it becomes the leader.
+
+package native
+
+import (
+	"sort"
+
+	"golang.org/x/xerrors"
+)
+
+// PolicyMode defines how the list of contracts of a policy is interpreted.
+type PolicyMode int
+
+const (
+	// DenyMode serves every contract except the ones in the list.
+	DenyMode PolicyMode = iota
+
+	// AllowMode serves only the contracts in the list.
+	AllowMode
+)
+
+// Policy is a local allow or deny list of contracts. The zero value serves
+// every contract.
+type Policy struct {
+	mode  PolicyMode
+	names map[string]struct{}
+}
+
+// NewDenyList returns a policy that serves every contract except the ones
+// provided.
+func NewDenyList(names ...string) Policy {
+	return newPolicy(DenyMode, names)
+}
+
+// NewAllowList returns a policy that serves only the contracts provided.
+func NewAllowList(names ...string) Policy {
+	return newPolicy(AllowMode, names)
+}
+
+func newPolicy(mode PolicyMode, names []string) Policy {
+	p := Policy{
+		mode:  mode,
+		names: make(map[string]struct{}, len(names)),
+	}
+
+	for _, name := range names {
+		p.names[name] = struct{}{}
+	}
+
+	return p
+}
+
+// GetMode returns the mode of the policy.
+func (p Policy) GetMode() PolicyMode {
+	return p.mode
+}
+
+// GetNames returns the sorted list of contracts of the policy.
+func (p Policy) GetNames() []string {
+	names := make([]string, 0, len(p.names))
+	for name := range p.names {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Check returns nil if the contract is served according to the policy,
+// otherwise an error explaining why it is not.
+func (p Policy) Check(name string) error {
+	_, found := p.names[name]
+
+	switch p.mode {
+	case AllowMode:
+		if !found {
+			return xerrors.Errorf("contract '%s' is not in the allow list", name)
+		}
+	default:
+		if found {
+			return xerrors.Errorf("contract '%s' is in the deny list", name)
+		}
+	}
+
+	return nil
+}
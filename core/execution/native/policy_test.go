@@ -0,0 +1,23 @@
+package native
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	policy := Policy{}
+	require.NoError(t, policy.Check("abc"))
+
+	policy = NewDenyList("abc", "def")
+	require.Equal(t, DenyMode, policy.GetMode())
+	require.Equal(t, []string{"abc", "def"}, policy.GetNames())
+	require.NoError(t, policy.Check("ghi"))
+	require.EqualError(t, policy.Check("abc"), "contract 'abc' is in the deny list")
+
+	policy = NewAllowList("abc")
+	require.Equal(t, AllowMode, policy.GetMode())
+	require.NoError(t, policy.Check("abc"))
+	require.EqualError(t, policy.Check("def"), "contract 'def' is not in the allow list")
+}
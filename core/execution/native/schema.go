@@ -0,0 +1,173 @@
+// This file contains the implementation of the argument schemas that can be
+// registered alongside a contract so that malformed transactions are rejected
+// before reaching the execution.
+
+package native
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/validation"
+	"golang.org/x/xerrors"
+)
+
+// Schema is the interface to implement to validate the arguments of a
+// transaction before it is admitted.
+type Schema interface {
+	// Validate returns nil if the arguments of the transaction are well-formed,
+	// otherwise an error describing the problems.
+	Validate(tx txn.Transaction) error
+}
+
+// Arg is the description of a single argument of a transaction.
+type Arg struct {
+	// Name is the key of the argument in the transaction.
+	Name string
+
+	// Required indicates that the argument must be present and not empty.
+	Required bool
+
+	// Values is the list of the allowed values for the argument. An empty list
+	// allows any value.
+	Values []string
+
+	// MaxSize is the maximum number of bytes of the value. Zero means that the
+	// size is not limited.
+	MaxSize int
+}
+
+// ArgSchema is a schema that validates a list of arguments.
+//
+// - implements native.Schema
+type ArgSchema struct {
+	args []Arg
+}
+
+// NewArgSchema creates a new schema for the list of arguments.
+func NewArgSchema(args ...Arg) ArgSchema {
+	return ArgSchema{
+		args: args,
+	}
+}
+
+// Validate implements native.Schema. It checks every argument of the schema
+// and returns an error listing all the problems found.
+func (s ArgSchema) Validate(tx txn.Transaction) error {
+	problems := []string{}
+
+	for _, arg := range s.args {
+		value := tx.GetArg(arg.Name)
+
+		if len(value) == 0 {
+			if arg.Required {
+				problems = append(problems, fmt.Sprintf("'%s' is missing", arg.Name))
+			}
+
+			continue
+		}
+
+		if arg.MaxSize > 0 && len(value) > arg.MaxSize {
+			problems = append(problems, fmt.Sprintf("'%s' is too big: %d > %d",
+				arg.Name, len(value), arg.MaxSize))
+		}
+
+		if len(arg.Values) > 0 && !contains(arg.Values, string(value)) {
+			problems = append(problems, fmt.Sprintf("'%s' must be one of [%s]",
+				arg.Name, strings.Join(arg.Values, ", ")))
+		}
+	}
+
+	if len(problems) > 0 {
+		return xerrors.Errorf("invalid arguments: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// SwitchSchema is a schema that selects the schema to apply according to the
+// value of an argument, which is typically the command of a contract.
+//
+// - implements native.Schema
+type SwitchSchema struct {
+	arg   string
+	cases map[string]Schema
+}
+
+// NewSwitchSchema creates a new schema that applies the schema of the case
+// matching the value of the argument.
+func NewSwitchSchema(arg string, cases map[string]Schema) SwitchSchema {
+	return SwitchSchema{
+		arg:   arg,
+		cases: cases,
+	}
+}
+
+// Validate implements native.Schema. It applies the schema associated to the
+// value of the argument, or returns an error if none is found.
+func (s SwitchSchema) Validate(tx txn.Transaction) error {
+	value := string(tx.GetArg(s.arg))
+
+	schema, found := s.cases[value]
+	if !found {
+		keys := make([]string, 0, len(s.cases))
+		for key := range s.cases {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		return xerrors.Errorf("invalid arguments: '%s' must be one of [%s]",
+			s.arg, strings.Join(keys, ", "))
+	}
+
+	if schema == nil {
+		return nil
+	}
+
+	err := schema.Validate(tx)
+	if err != nil {
+		return xerrors.Errorf("%s: %v", value, err)
+	}
+
+	return nil
+}
+
+// SchemaFilter is a pool filter that drops the transactions with arguments that
+// do not comply with the schema of the contract.
+//
+// - implements pool.Filter
+type SchemaFilter struct {
+	srvc *Service
+}
+
+// NewSchemaFilter creates a new pool filter that checks the transactions
+// against the schemas registered in the service.
+func NewSchemaFilter(srvc *Service) SchemaFilter {
+	return SchemaFilter{
+		srvc: srvc,
+	}
+}
+
+// Accept implements pool.Filter. It returns an error if the arguments of the
+// transaction are malformed.
+func (f SchemaFilter) Accept(tx txn.Transaction, leeway validation.Leeway) error {
+	err := f.srvc.CheckArgs(tx)
+	if err != nil {
+		return xerrors.Errorf("schema: %v", err)
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
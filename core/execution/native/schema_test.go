@@ -0,0 +1,87 @@
+package native
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/validation"
+)
+
+func TestArgSchema_Validate(t *testing.T) {
+	schema := NewArgSchema(
+		Arg{Name: "a", Required: true},
+		Arg{Name: "b", Values: []string{"x", "y"}},
+		Arg{Name: "c", MaxSize: 2},
+	)
+
+	err := schema.Validate(fakeArgsTx{args: map[string]string{"a": "1"}})
+	require.NoError(t, err)
+
+	err = schema.Validate(fakeArgsTx{args: map[string]string{"a": "1", "b": "x", "c": "12"}})
+	require.NoError(t, err)
+
+	err = schema.Validate(fakeArgsTx{args: map[string]string{"b": "z", "c": "123"}})
+	require.EqualError(t, err, "invalid arguments: 'a' is missing; "+
+		"'b' must be one of [x, y]; 'c' is too big: 3 > 2")
+}
+
+func TestSwitchSchema_Validate(t *testing.T) {
+	schema := NewSwitchSchema("cmd", map[string]Schema{
+		"A": NewArgSchema(Arg{Name: "a", Required: true}),
+		"B": nil,
+	})
+
+	err := schema.Validate(fakeArgsTx{args: map[string]string{"cmd": "B"}})
+	require.NoError(t, err)
+
+	err = schema.Validate(fakeArgsTx{args: map[string]string{"cmd": "A", "a": "1"}})
+	require.NoError(t, err)
+
+	err = schema.Validate(fakeArgsTx{args: map[string]string{"cmd": "A"}})
+	require.EqualError(t, err, "A: invalid arguments: 'a' is missing")
+
+	err = schema.Validate(fakeArgsTx{})
+	require.EqualError(t, err, "invalid arguments: 'cmd' must be one of [A, B]")
+}
+
+func TestSchemaFilter_Accept(t *testing.T) {
+	srvc := NewExecution()
+	srvc.Set("abc", fakeExec{})
+	srvc.Set("def", fakeExec{})
+	srvc.SetSchema("abc", NewArgSchema(Arg{Name: "a", Required: true}))
+
+	filter := NewSchemaFilter(srvc)
+
+	tx := fakeArgsTx{args: map[string]string{ContractArg: "abc", "a": "1"}}
+	require.NoError(t, filter.Accept(tx, validation.Leeway{}))
+
+	tx = fakeArgsTx{args: map[string]string{ContractArg: "def"}}
+	require.NoError(t, filter.Accept(tx, validation.Leeway{}))
+
+	tx = fakeArgsTx{args: map[string]string{ContractArg: "abc"}}
+	err := filter.Accept(tx, validation.Leeway{})
+	require.EqualError(t, err,
+		"schema: contract 'abc': invalid arguments: 'a' is missing")
+
+	tx = fakeArgsTx{args: map[string]string{ContractArg: "none"}}
+	err = filter.Accept(tx, validation.Leeway{})
+	require.EqualError(t, err, "schema: unknown contract 'none'")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeArgsTx struct {
+	txn.Transaction
+	args map[string]string
+}
+
+func (tx fakeArgsTx) GetArg(key string) []byte {
+	value, found := tx.args[key]
+	if !found {
+		return nil
+	}
+
+	return []byte(value)
+}
@@ -0,0 +1,163 @@
+// Package controller implements a CLI controller to set the memory budgets of
+// the modules of a node and to read their usage.
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/memory"
+	"go.dedis.ch/dela/mino/proxy"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// poolFlag is the flag name of the cap of the transaction pool.
+	poolFlag = "memory-pool"
+
+	// trieFlag is the flag name of the cap of the in-memory nodes of the trie.
+	trieFlag = "memory-trie"
+
+	// overlayFlag is the flag name of the cap of the buffers of the overlay.
+	overlayFlag = "memory-overlay"
+
+	megabyte = 1 << 20
+)
+
+// NewController returns a new controller that creates the accountant of the
+// memory budgets.
+func NewController() node.Initializer {
+	return minimal{}
+}
+
+// minimal is an initializer with the commands to read the memory usage.
+//
+// - implements node.Initializer
+type minimal struct{}
+
+// SetCommands implements node.Initializer. It sets the flags of the caps of
+// the budgets, and the commands to read their usage.
+func (minimal) SetCommands(builder node.Builder) {
+	builder.SetStartFlags(
+		cli.IntFlag{
+			Name:  poolFlag,
+			Usage: "cap in megabytes of the transaction pool, zero for no limit",
+		},
+		cli.IntFlag{
+			Name:  trieFlag,
+			Usage: "cap in megabytes of the in-memory nodes of the state trie, zero for no limit",
+		},
+		cli.IntFlag{
+			Name:  overlayFlag,
+			Usage: "cap in megabytes of the buffers of the overlay, zero for no limit",
+		},
+	)
+
+	cmd := builder.SetCommand("memory")
+	cmd.SetDescription("Memory used by the modules of the node")
+
+	sub := cmd.SetSubCommand("status")
+	sub.SetDescription("prints the usage of the budget of each module")
+	sub.SetAction(builder.MakeAction(statusAction{}))
+
+	sub = cmd.SetSubCommand("metrics")
+	sub.SetDescription("register the endpoint of the memory metrics for " +
+		"Prometheus on the proxy, which must be started beforehand")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name:  "path",
+			Usage: "the path of the endpoint",
+			Value: "/memory",
+		},
+	)
+	sub.SetAction(builder.MakeAction(metricsAction{}))
+}
+
+// OnStart implements node.Initializer. It creates the accountant with the caps
+// of the flags and injects it, so that it must be started before the modules
+// that use a budget.
+func (minimal) OnStart(flags cli.Flags, inj node.Injector) error {
+	acc := memory.NewAccountant()
+
+	limits := map[string]string{
+		memory.PoolModule:    poolFlag,
+		memory.TrieModule:    trieFlag,
+		memory.OverlayModule: overlayFlag,
+	}
+
+	for module, flag := range limits {
+		limit := flags.Int(flag)
+		if limit < 0 {
+			return xerrors.Errorf("invalid cap for %s: %d", module, limit)
+		}
+
+		acc.GetBudget(module).SetLimit(int64(limit) * megabyte)
+	}
+
+	inj.Inject(acc)
+
+	return nil
+}
+
+// OnStop implements node.Initializer.
+func (minimal) OnStop(node.Injector) error {
+	return nil
+}
+
+// statusAction is an action to print the usage of the budgets.
+//
+// - implements node.ActionTemplate
+type statusAction struct{}
+
+// Execute implements node.ActionTemplate. It prints the usage as a JSON
+// document.
+func (statusAction) Execute(ctx node.Context) error {
+	var acc *memory.Accountant
+	err := ctx.Injector.Resolve(&acc)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	data, err := json.MarshalIndent(acc.GetUsage(), "", "  ")
+	if err != nil {
+		return xerrors.Errorf("failed to encode: %v", err)
+	}
+
+	fmt.Fprintln(ctx.Out, string(data))
+
+	return nil
+}
+
+// metricsAction is an action to expose the usage of the budgets to Prometheus.
+//
+// - implements node.ActionTemplate
+type metricsAction struct{}
+
+// Execute implements node.ActionTemplate. It registers the endpoint of the
+// metrics on the proxy.
+func (metricsAction) Execute(ctx node.Context) error {
+	var acc *memory.Accountant
+	err := ctx.Injector.Resolve(&acc)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var p proxy.Proxy
+	err = ctx.Injector.Resolve(&p)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	path := ctx.Flags.String("path")
+
+	err = p.RegisterHandler(path, memory.NewCollector(acc).ServeHTTP)
+	if err != nil {
+		return xerrors.Errorf("failed to register handler: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "metrics endpoint registered on %s\n", path)
+
+	return nil
+}
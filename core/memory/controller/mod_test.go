@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/memory"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino/proxy"
+)
+
+func TestMinimal_SetCommands(t *testing.T) {
+	m := NewController()
+
+	b := node.NewBuilder()
+	m.SetCommands(b)
+}
+
+func TestMinimal_OnStart(t *testing.T) {
+	m := NewController()
+
+	inj := node.NewInjector()
+
+	flags := node.FlagSet{
+		poolFlag:    2,
+		overlayFlag: float64(1),
+	}
+
+	err := m.OnStart(flags, inj)
+	require.NoError(t, err)
+
+	var acc *memory.Accountant
+	require.NoError(t, inj.Resolve(&acc))
+	require.Equal(t, int64(2*megabyte), acc.GetBudget(memory.PoolModule).GetLimit())
+	require.Equal(t, int64(0), acc.GetBudget(memory.TrieModule).GetLimit())
+	require.Equal(t, int64(megabyte), acc.GetBudget(memory.OverlayModule).GetLimit())
+
+	flags[trieFlag] = -1
+	err = m.OnStart(flags, inj)
+	require.EqualError(t, err, "invalid cap for trie: -1")
+}
+
+func TestMinimal_OnStop(t *testing.T) {
+	err := NewController().OnStop(node.NewInjector())
+	require.NoError(t, err)
+}
+
+func TestStatusAction_Execute(t *testing.T) {
+	out := new(bytes.Buffer)
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Out:      out,
+	}
+
+	err := statusAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for '*memory.Accountant'")
+
+	acc := memory.NewAccountant()
+	require.NoError(t, acc.GetBudget(memory.PoolModule).Reserve(10))
+
+	ctx.Injector.Inject(acc)
+
+	err = statusAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), `"Module": "pool"`)
+	require.Contains(t, out.String(), `"Used": 10`)
+}
+
+func TestMetricsAction_Execute(t *testing.T) {
+	flags := make(node.FlagSet)
+	flags["path"] = "/memory"
+
+	out := new(bytes.Buffer)
+	ctx := node.Context{
+		Out:      out,
+		Flags:    flags,
+		Injector: node.NewInjector(),
+	}
+
+	err := metricsAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for '*memory.Accountant'")
+
+	acc := memory.NewAccountant()
+	require.NoError(t, acc.GetBudget(memory.TrieModule).Reserve(5))
+
+	ctx.Injector.Inject(acc)
+
+	err = metricsAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'proxy.Proxy'")
+
+	px := &fakeProxy{}
+	ctx.Injector.Inject(px)
+
+	err = metricsAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "metrics endpoint registered on /memory\n", out.String())
+	require.Equal(t, "/memory", px.path)
+
+	rec := httptest.NewRecorder()
+	px.handler(rec, httptest.NewRequest(http.MethodGet, "/memory", nil))
+	require.Contains(t, rec.Body.String(), "dela_memory_used_bytes{module=\"trie\"} 5\n")
+
+	px.err = fake.GetError()
+	err = metricsAction{}.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to register handler"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeProxy struct {
+	proxy.Proxy
+
+	path    string
+	handler func(http.ResponseWriter, *http.Request)
+	err     error
+}
+
+func (p *fakeProxy) RegisterHandler(path string, h func(http.ResponseWriter, *http.Request)) error {
+	p.path = path
+	p.handler = h
+
+	return p.err
+}
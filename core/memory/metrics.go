@@ -0,0 +1,108 @@
+// This file contains the exposition of the budgets to Prometheus.
+
+package memory
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.dedis.ch/dela"
+)
+
+// DefaultNamespace is the prefix of the names of the metrics.
+const DefaultNamespace = "dela_memory"
+
+// contentType is the content type of the text format of the exposition.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// family is a family of metrics with the same name.
+type family struct {
+	name string
+	help string
+	kind string
+	get  func(Usage) interface{}
+}
+
+var families = []family{
+	{
+		name: "used_bytes",
+		help: "Number of bytes used by the module.",
+		kind: "gauge",
+		get:  func(u Usage) interface{} { return u.Used },
+	},
+	{
+		name: "limit_bytes",
+		help: "Cap of the module in bytes, or zero when unlimited.",
+		kind: "gauge",
+		get:  func(u Usage) interface{} { return u.Limit },
+	},
+	{
+		name: "peak_bytes",
+		help: "Highest number of bytes used by the module.",
+		kind: "gauge",
+		get:  func(u Usage) interface{} { return u.Peak },
+	},
+	{
+		name: "rejections_total",
+		help: "Number of reservations refused because of the cap.",
+		kind: "counter",
+		get:  func(u Usage) interface{} { return u.Rejections },
+	},
+	{
+		name: "evicted_bytes_total",
+		help: "Number of bytes evicted to fit the cap.",
+		kind: "counter",
+		get:  func(u Usage) interface{} { return u.Evictions },
+	},
+}
+
+// Collector exposes the budgets of an accountant.
+//
+// - implements http.Handler
+type Collector struct {
+	accountant *Accountant
+	namespace  string
+}
+
+// NewCollector returns a new collector for the budgets of the accountant.
+func NewCollector(a *Accountant) Collector {
+	return Collector{
+		accountant: a,
+		namespace:  DefaultNamespace,
+	}
+}
+
+// Collect writes the current usage of the budgets to the writer in the text
+// format of the exposition.
+func (c Collector) Collect(w io.Writer) error {
+	usage := c.accountant.GetUsage()
+
+	for _, f := range families {
+		name := c.namespace + "_" + f.name
+
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, f.help, name, f.kind)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range usage {
+			_, err = fmt.Fprintf(w, "%s{module=\"%s\"} %d\n", name, u.Module, f.get(u))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP implements http.Handler. It replies with the metrics.
+func (c Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+
+	err := c.Collect(w)
+	if err != nil {
+		dela.Logger.Warn().Err(err).Msg("failed to write the metrics")
+	}
+}
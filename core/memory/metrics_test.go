@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestCollector_Collect(t *testing.T) {
+	a := NewAccountant()
+	a.GetBudget(PoolModule).SetLimit(10)
+	a.GetBudget(PoolModule).Reserve(4)
+	a.GetBudget(TrieModule).Evict(20)
+
+	c := NewCollector(a)
+
+	out := new(bytes.Buffer)
+	err := c.Collect(out)
+	require.NoError(t, err)
+	require.Contains(t, out.String(),
+		"# HELP dela_memory_used_bytes Number of bytes used by the module.\n"+
+			"# TYPE dela_memory_used_bytes gauge\n")
+	require.Contains(t, out.String(), "dela_memory_used_bytes{module=\"pool\"} 4\n")
+	require.Contains(t, out.String(), "dela_memory_limit_bytes{module=\"pool\"} 10\n")
+	require.Contains(t, out.String(), "# TYPE dela_memory_evicted_bytes_total counter\n")
+	require.Contains(t, out.String(), "dela_memory_evicted_bytes_total{module=\"trie\"} 20\n")
+
+	err = c.Collect(badWriter{})
+	require.EqualError(t, err, fake.GetError().Error())
+
+	out.Reset()
+	err = c.Collect(&limitedWriter{n: 2})
+	require.EqualError(t, err, fake.GetError().Error())
+}
+
+func TestCollector_ServeHTTP(t *testing.T) {
+	a := NewAccountant()
+	a.GetBudget(OverlayModule)
+
+	c := NewCollector(a)
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/memory", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, contentType, rec.Header().Get("Content-Type"))
+	require.Contains(t, rec.Body.String(), "dela_memory_peak_bytes{module=\"overlay\"} 0\n")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type badWriter struct{}
+
+func (badWriter) Write([]byte) (int, error) {
+	return 0, fake.GetError()
+}
+
+// limitedWriter fails after the number of writes.
+type limitedWriter struct {
+	n int
+}
+
+func (w *limitedWriter) Write(data []byte) (int, error) {
+	if w.n == 0 {
+		return 0, fake.GetError()
+	}
+
+	w.n--
+
+	return len(data), nil
+}
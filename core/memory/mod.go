@@ -0,0 +1,223 @@
+// Package memory implements the accounting of the memory used by the modules
+// of a node.
+//
+// Each module that can buffer an amount of data that depends on its peers, like
+// the transaction pool, the in-memory nodes of the state trie or the buffers of
+// the overlay, tracks the memory it uses with a budget. A budget can have a cap
+// so that the module degrades gracefully when it is reached, by evicting data
+// or rejecting new data, instead of letting the process be killed when it runs
+// out of memory. The budgets only count the data they are told about, so they
+// are estimates of the memory actually allocated.
+//
+// The budgets of a node are created by an accountant, which gives the usage of
+// each module to the metrics and to the administration commands.
+package memory
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// PoolModule is the name of the budget of the transaction pool.
+	PoolModule = "pool"
+
+	// TrieModule is the name of the budget of the in-memory nodes of the state
+	// trie.
+	TrieModule = "trie"
+
+	// OverlayModule is the name of the budget of the buffers of the overlay.
+	OverlayModule = "overlay"
+)
+
+// ErrExhausted is the error returned when a reservation would exceed the cap of
+// a budget.
+var ErrExhausted = xerrors.New("memory budget exhausted")
+
+// Usage is the state of the budget of a module.
+type Usage struct {
+	Module string
+
+	// Used is the number of bytes currently accounted for.
+	Used int64
+
+	// Limit is the cap of the budget, or zero when it is unlimited.
+	Limit int64
+
+	// Peak is the highest number of bytes used since the budget exists.
+	Peak int64
+
+	// Rejections is the number of reservations refused because of the cap.
+	Rejections uint64
+
+	// Evictions is the number of bytes released by the module to fit the cap.
+	Evictions uint64
+}
+
+// Budget tracks the memory used by a module against its cap. It is safe for
+// concurrent use, and a nil budget accepts every reservation without counting
+// them so that the modules can use it unconditionally.
+type Budget struct {
+	module string
+
+	limit      int64
+	used       int64
+	peak       int64
+	rejections uint64
+	evictions  uint64
+}
+
+// NewBudget returns a new budget for the module with the cap in bytes. A cap
+// of zero means no limit.
+func NewBudget(module string, limit int64) *Budget {
+	return &Budget{
+		module: module,
+		limit:  limit,
+	}
+}
+
+// GetModule returns the name of the module of the budget.
+func (b *Budget) GetModule() string {
+	return b.module
+}
+
+// Reserve accounts for the number of bytes if the cap allows it, otherwise it
+// returns an error that wraps ErrExhausted and nothing is accounted for.
+func (b *Budget) Reserve(n int) error {
+	if b == nil {
+		return nil
+	}
+
+	for {
+		used := atomic.LoadInt64(&b.used)
+		next := used + int64(n)
+
+		limit := atomic.LoadInt64(&b.limit)
+		if limit > 0 && next > limit {
+			atomic.AddUint64(&b.rejections, 1)
+
+			return xerrors.Errorf("%s needs %d bytes with %d out of %d used: %w",
+				b.module, n, used, limit, ErrExhausted)
+		}
+
+		if atomic.CompareAndSwapInt64(&b.used, used, next) {
+			b.updatePeak(next)
+			return nil
+		}
+	}
+}
+
+// Release gives back the number of bytes previously reserved.
+func (b *Budget) Release(n int) {
+	if b == nil {
+		return
+	}
+
+	atomic.AddInt64(&b.used, -int64(n))
+}
+
+// Set replaces the number of bytes accounted for, which allows a module that
+// measures its usage to report it, whatever the cap.
+func (b *Budget) Set(n int64) {
+	if b == nil {
+		return
+	}
+
+	atomic.StoreInt64(&b.used, n)
+	b.updatePeak(n)
+}
+
+// Evict records that the module has released the number of bytes to fit the
+// cap.
+func (b *Budget) Evict(n int) {
+	if b == nil {
+		return
+	}
+
+	atomic.AddUint64(&b.evictions, uint64(n))
+}
+
+// GetLimit returns the cap of the budget in bytes, or zero if it is unlimited.
+func (b *Budget) GetLimit() int64 {
+	if b == nil {
+		return 0
+	}
+
+	return atomic.LoadInt64(&b.limit)
+}
+
+// SetLimit changes the cap of the budget. The memory already used is kept even
+// if it exceeds the new cap.
+func (b *Budget) SetLimit(limit int64) {
+	atomic.StoreInt64(&b.limit, limit)
+}
+
+// GetUsage returns the current state of the budget.
+func (b *Budget) GetUsage() Usage {
+	return Usage{
+		Module:     b.module,
+		Used:       atomic.LoadInt64(&b.used),
+		Limit:      atomic.LoadInt64(&b.limit),
+		Peak:       atomic.LoadInt64(&b.peak),
+		Rejections: atomic.LoadUint64(&b.rejections),
+		Evictions:  atomic.LoadUint64(&b.evictions),
+	}
+}
+
+func (b *Budget) updatePeak(used int64) {
+	for {
+		peak := atomic.LoadInt64(&b.peak)
+		if used <= peak || atomic.CompareAndSwapInt64(&b.peak, peak, used) {
+			return
+		}
+	}
+}
+
+// Accountant holds the budgets of the modules of a node.
+type Accountant struct {
+	sync.Mutex
+
+	budgets map[string]*Budget
+}
+
+// NewAccountant returns a new accountant without any budget.
+func NewAccountant() *Accountant {
+	return &Accountant{
+		budgets: make(map[string]*Budget),
+	}
+}
+
+// GetBudget returns the budget of the module, which is created without a cap
+// if it doesn't exist yet.
+func (a *Accountant) GetBudget(module string) *Budget {
+	a.Lock()
+	defer a.Unlock()
+
+	b, found := a.budgets[module]
+	if !found {
+		b = NewBudget(module, 0)
+		a.budgets[module] = b
+	}
+
+	return b
+}
+
+// GetUsage returns the usage of every budget, sorted by module.
+func (a *Accountant) GetUsage() []Usage {
+	a.Lock()
+	defer a.Unlock()
+
+	usage := make([]Usage, 0, len(a.budgets))
+	for _, b := range a.budgets {
+		usage = append(usage, b.GetUsage())
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Module < usage[j].Module
+	})
+
+	return usage
+}
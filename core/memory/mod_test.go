@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestBudget_Reserve(t *testing.T) {
+	b := NewBudget("test", 10)
+	require.Equal(t, "test", b.GetModule())
+	require.Equal(t, int64(10), b.GetLimit())
+
+	require.NoError(t, b.Reserve(6))
+	require.NoError(t, b.Reserve(4))
+
+	err := b.Reserve(1)
+	require.True(t, xerrors.Is(err, ErrExhausted))
+	require.EqualError(t, err, "test needs 1 bytes with 10 out of 10 used: memory budget exhausted")
+
+	b.Release(6)
+	b.Evict(6)
+
+	require.Equal(t, Usage{
+		Module:     "test",
+		Used:       4,
+		Limit:      10,
+		Peak:       10,
+		Rejections: 1,
+		Evictions:  6,
+	}, b.GetUsage())
+
+	b.SetLimit(0)
+	require.NoError(t, b.Reserve(100))
+
+	b.Set(2)
+	require.Equal(t, int64(2), b.GetUsage().Used)
+	require.Equal(t, int64(104), b.GetUsage().Peak)
+}
+
+func TestBudget_Nil(t *testing.T) {
+	var b *Budget
+
+	require.NoError(t, b.Reserve(10))
+	b.Release(10)
+	b.Set(10)
+	b.Evict(10)
+	require.Equal(t, int64(0), b.GetLimit())
+}
+
+func TestBudget_Concurrent(t *testing.T) {
+	b := NewBudget("test", 50)
+
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 100)
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			errs <- b.Reserve(1)
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	num := 0
+	for err := range errs {
+		if err != nil {
+			num++
+		}
+	}
+
+	require.Equal(t, 50, num)
+	require.Equal(t, int64(50), b.GetUsage().Used)
+	require.Equal(t, uint64(50), b.GetUsage().Rejections)
+}
+
+func TestAccountant_GetUsage(t *testing.T) {
+	a := NewAccountant()
+
+	b := a.GetBudget(PoolModule)
+	require.Same(t, b, a.GetBudget(PoolModule))
+
+	a.GetBudget(OverlayModule).SetLimit(5)
+	require.NoError(t, b.Reserve(3))
+
+	usage := a.GetUsage()
+	require.Len(t, usage, 2)
+	require.Equal(t, Usage{Module: OverlayModule, Limit: 5}, usage[0])
+	require.Equal(t, Usage{Module: PoolModule, Used: 3, Peak: 3}, usage[1])
+}
@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// WebhookAlerter is an alerter that posts the discrepancies as JSON documents
+// to an URL.
+//
+// - implements audit.Alerter
+type WebhookAlerter struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookAlerter creates a new alerter that posts to the URL.
+func NewWebhookAlerter(url string) WebhookAlerter {
+	return WebhookAlerter{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Alert implements audit.Alerter. It posts the discrepancy to the URL and
+// expects a successful status code.
+func (a WebhookAlerter) Alert(d Discrepancy) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return xerrors.Errorf("failed to encode: %v", err)
+	}
+
+	resp, err := a.client.Post(a.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return xerrors.Errorf("failed to post: %v", err)
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return xerrors.Errorf("unexpected status '%s'", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookAlerter_Alert(t *testing.T) {
+	var received Discrepancy
+	status := http.StatusOK
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	alerter := NewWebhookAlerter(srv.URL)
+
+	err := alerter.Alert(Discrepancy{Kind: StateKind, Index: 2, Reason: "oops"})
+	require.NoError(t, err)
+	require.Equal(t, StateKind, received.Kind)
+	require.Equal(t, uint64(2), received.Index)
+	require.Equal(t, "oops", received.Reason)
+
+	status = http.StatusInternalServerError
+	err = alerter.Alert(Discrepancy{})
+	require.EqualError(t, err, "unexpected status '500 Internal Server Error'")
+
+	alerter = NewWebhookAlerter("http://127.0.0.1:0")
+	err = alerter.Alert(Discrepancy{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to post: ")
+}
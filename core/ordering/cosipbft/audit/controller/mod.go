@@ -0,0 +1,186 @@
+// Package controller implements a CLI controller to run the audit of the chain
+// in the background of a node.
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/ordering/cosipbft/audit"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/cosi"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// intervalFlag is the flag name of the amount of time between two passes.
+	intervalFlag = "audit-interval"
+
+	// webhookFlag is the flag name of the URLs notified of the discrepancies.
+	webhookFlag = "audit-webhook"
+
+	// sampleFlag is the flag name of the number of blocks whose signatures are
+	// verified at each pass.
+	sampleFlag = "audit-sample"
+)
+
+// NewController returns a new controller that starts the auditor when the
+// interval is set.
+func NewController() node.Initializer {
+	return minimal{}
+}
+
+// minimal is an initializer with the commands to control the auditor.
+//
+// - implements node.Initializer
+type minimal struct{}
+
+// SetCommands implements node.Initializer. It sets the flags to start the
+// auditor, and the commands to read its status or request a pass.
+func (minimal) SetCommands(builder node.Builder) {
+	builder.SetStartFlags(
+		cli.DurationFlag{
+			Name:  intervalFlag,
+			Usage: "amount of time between two audits of the chain, zero to disable",
+		},
+		cli.StringSliceFlag{
+			Name:  webhookFlag,
+			Usage: "URL notified of the discrepancies found by the audit",
+		},
+		cli.IntFlag{
+			Name:  sampleFlag,
+			Usage: "number of blocks whose signatures are verified by each audit, zero for all",
+		},
+	)
+
+	cmd := builder.SetCommand("audit")
+	cmd.SetDescription("Re-verification of the chain stored on the disk")
+
+	sub := cmd.SetSubCommand("status")
+	sub.SetDescription("prints the metrics of the audit")
+	sub.SetAction(builder.MakeAction(statusAction{}))
+
+	sub = cmd.SetSubCommand("run")
+	sub.SetDescription("performs a pass immediately and prints the discrepancies")
+	sub.SetAction(builder.MakeAction(runAction{}))
+}
+
+// OnStart implements node.Initializer. It starts the auditor if the interval
+// is set. It expects the ordering service to be started beforehand.
+func (minimal) OnStart(flags cli.Flags, inj node.Injector) error {
+	interval := flags.Duration(intervalFlag)
+	if interval <= 0 {
+		return nil
+	}
+
+	var genesis blockstore.GenesisStore
+	err := inj.Resolve(&genesis)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var blocks blockstore.BlockStore
+	err = inj.Resolve(&blocks)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var c cosi.CollectiveSigning
+	err = inj.Resolve(&c)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	opts := []audit.Option{
+		audit.WithInterval(interval),
+		audit.WithSampling(flags.Int(sampleFlag)),
+	}
+
+	var tree audit.StateTree
+	err = inj.Resolve(&tree)
+	if err == nil {
+		opts = append(opts, audit.WithStateTree(tree))
+	}
+
+	for _, url := range flags.StringSlice(webhookFlag) {
+		opts = append(opts, audit.WithAlerter(audit.NewWebhookAlerter(url)))
+	}
+
+	auditor := audit.NewAuditor(genesis, blocks, c.GetVerifierFactory(), opts...)
+	auditor.Start()
+
+	inj.Inject(auditor)
+
+	return nil
+}
+
+// OnStop implements node.Initializer. It stops the auditor if it is running.
+func (minimal) OnStop(inj node.Injector) error {
+	var auditor *audit.Auditor
+	err := inj.Resolve(&auditor)
+	if err != nil {
+		// The auditor is disabled.
+		return nil
+	}
+
+	auditor.Stop()
+
+	return nil
+}
+
+// statusAction is an action to print the metrics of the auditor.
+//
+// - implements node.ActionTemplate
+type statusAction struct{}
+
+// Execute implements node.ActionTemplate. It prints the metrics as a JSON
+// document.
+func (statusAction) Execute(ctx node.Context) error {
+	var auditor *audit.Auditor
+	err := ctx.Injector.Resolve(&auditor)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	data, err := json.MarshalIndent(auditor.GetStats(), "", "  ")
+	if err != nil {
+		return xerrors.Errorf("failed to encode: %v", err)
+	}
+
+	fmt.Fprintln(ctx.Out, string(data))
+
+	return nil
+}
+
+// runAction is an action to perform a pass immediately.
+//
+// - implements node.ActionTemplate
+type runAction struct{}
+
+// Execute implements node.ActionTemplate. It performs a pass and prints the
+// discrepancies, if any.
+func (runAction) Execute(ctx node.Context) error {
+	var auditor *audit.Auditor
+	err := ctx.Injector.Resolve(&auditor)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	start := time.Now()
+
+	found, err := auditor.Audit()
+	if err != nil {
+		return xerrors.Errorf("audit failed: %v", err)
+	}
+
+	for _, d := range found {
+		fmt.Fprintf(ctx.Out, "block %d: %s: %s\n", d.Index, d.Kind, d.Reason)
+	}
+
+	fmt.Fprintf(ctx.Out, "%d discrepancies found in %v\n", len(found), time.Since(start))
+
+	return nil
+}
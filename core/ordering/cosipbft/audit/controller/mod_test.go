@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/ordering/cosipbft/audit"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestMinimal_SetCommands(t *testing.T) {
+	m := NewController()
+
+	b := node.NewBuilder()
+	m.SetCommands(b)
+}
+
+func TestMinimal_OnStart(t *testing.T) {
+	m := NewController()
+
+	inj := node.NewInjector()
+
+	// The auditor is disabled by default.
+	err := m.OnStart(make(node.FlagSet), inj)
+	require.NoError(t, err)
+
+	flags := node.FlagSet{
+		intervalFlag: float64(time.Hour),
+		webhookFlag:  []interface{}{"http://127.0.0.1:0"},
+		sampleFlag:   10,
+	}
+
+	err = m.OnStart(flags, inj)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'blockstore.GenesisStore'")
+
+	inj.Inject(blockstore.NewGenesisStore())
+	err = m.OnStart(flags, inj)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'blockstore.BlockStore'")
+
+	inj.Inject(blockstore.NewInMemory())
+	err = m.OnStart(flags, inj)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'cosi.CollectiveSigning'")
+
+	inj.Inject(fakeCosi{})
+	err = m.OnStart(flags, inj)
+	require.NoError(t, err)
+
+	var auditor *audit.Auditor
+	require.NoError(t, inj.Resolve(&auditor))
+
+	err = m.OnStop(inj)
+	require.NoError(t, err)
+}
+
+func TestMinimal_OnStop(t *testing.T) {
+	err := NewController().OnStop(node.NewInjector())
+	require.NoError(t, err)
+}
+
+func TestStatusAction_Execute(t *testing.T) {
+	out := new(bytes.Buffer)
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Out:      out,
+	}
+
+	err := statusAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for '*audit.Auditor'")
+
+	ctx.Injector.Inject(makeAuditor())
+
+	err = statusAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), `"passes": 0`)
+}
+
+func TestRunAction_Execute(t *testing.T) {
+	out := new(bytes.Buffer)
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Out:      out,
+	}
+
+	err := runAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for '*audit.Auditor'")
+
+	ctx.Injector.Inject(makeAuditor())
+
+	err = runAction{}.Execute(ctx)
+	require.EqualError(t, err, "audit failed: genesis: missing genesis block")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeAuditor() *audit.Auditor {
+	return audit.NewAuditor(blockstore.NewGenesisStore(), blockstore.NewInMemory(),
+		fake.NewVerifierFactory(fake.Verifier{}))
+}
+
+type fakeCosi struct {
+	cosi.CollectiveSigning
+}
+
+func (fakeCosi) GetVerifierFactory() crypto.VerifierFactory {
+	return fake.NewVerifierFactory(fake.Verifier{})
+}
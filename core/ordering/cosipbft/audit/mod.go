@@ -0,0 +1,428 @@
+// Package audit implements a background service that continuously re-verifies
+// the chain stored on the disk of the node.
+//
+// Each pass reads every block from the block store and checks that the links
+// follow each other and that the collective signatures are valid for the
+// roster at that height. Only the forward links of the pruned blocks are
+// verified. The root of the state is then calculated from the leafs stored on
+// the disk and compared to the one of the latest block. Any discrepancy, caused
+// by a bit rot or a tampering, is logged and forwarded to the alerters.
+//
+// The signatures are the expensive part of a pass, therefore a long chain can
+// be audited with a sample of the blocks at each pass, drawn so that a sample
+// is reproduced from the digest of the latest block and the number of the pass.
+package audit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/mino"
+	"golang.org/x/xerrors"
+)
+
+// DefaultInterval is the default amount of time between two passes.
+const DefaultInterval = 10 * time.Minute
+
+// Kind is the kind of a discrepancy.
+type Kind string
+
+const (
+	// StorageKind is the kind of a discrepancy when a block cannot be read from
+	// the disk.
+	StorageKind Kind = "storage"
+
+	// ChainKind is the kind of a discrepancy when a block does not follow the
+	// previous one.
+	ChainKind Kind = "chain"
+
+	// SignatureKind is the kind of a discrepancy when the collective signatures
+	// of a block are invalid.
+	SignatureKind Kind = "signature"
+
+	// StateKind is the kind of a discrepancy when the state on the disk does
+	// not match the root of the latest block.
+	StateKind Kind = "state"
+)
+
+// Discrepancy is the description of a problem found during a pass.
+type Discrepancy struct {
+	Kind   Kind      `json:"kind"`
+	Index  uint64    `json:"index"`
+	Reason string    `json:"reason"`
+	Time   time.Time `json:"time"`
+}
+
+// Alerter is the interface to forward the discrepancies found by the auditor.
+type Alerter interface {
+	// Alert must notify about the discrepancy.
+	Alert(Discrepancy) error
+}
+
+// StateTree is the interface of the tree that can calculate its root from the
+// disk.
+type StateTree interface {
+	// CalculateRoot must calculate the root from the data on the disk without
+	// relying on any digest that has been computed previously.
+	CalculateRoot() ([]byte, error)
+}
+
+// Stats are the metrics of the auditor.
+type Stats struct {
+	// Passes is the number of passes completed.
+	Passes int `json:"passes"`
+
+	// Blocks is the number of blocks verified during the last pass.
+	Blocks uint64 `json:"blocks"`
+
+	// Signatures is the number of blocks whose signatures have been verified
+	// during the last pass, which is less than the number of blocks when the
+	// pass is sampled.
+	Signatures uint64 `json:"signatures"`
+
+	// Discrepancies is the total number of discrepancies found.
+	Discrepancies int `json:"discrepancies"`
+
+	// LastPass is the time at which the last pass completed.
+	LastPass time.Time `json:"lastPass"`
+
+	// Last is the list of discrepancies found during the last pass.
+	Last []Discrepancy `json:"last"`
+}
+
+// Option is the type of option to configure the auditor.
+type Option func(*Auditor)
+
+// WithInterval sets the amount of time between two passes.
+func WithInterval(d time.Duration) Option {
+	return func(a *Auditor) {
+		a.interval = d
+	}
+}
+
+// WithStateTree sets the tree of the state so that its root is verified at
+// each pass. The state is not verified otherwise.
+func WithStateTree(tree StateTree) Option {
+	return func(a *Auditor) {
+		a.tree = tree
+	}
+}
+
+// WithSampling sets the number of blocks whose signatures are verified at each
+// pass. The blocks are drawn from the digest of the latest block and the number
+// of the pass, so that the passes cover different blocks and a sample can be
+// reproduced. The links between the blocks are still verified for all of them.
+// The signatures of every block are verified otherwise.
+func WithSampling(size int) Option {
+	return func(a *Auditor) {
+		a.sampling = size
+	}
+}
+
+// WithAlerter adds an alerter notified of the discrepancies.
+func WithAlerter(alerter Alerter) Option {
+	return func(a *Auditor) {
+		a.alerters = append(a.alerters, alerter)
+	}
+}
+
+// Auditor is a service that re-verifies the chain stored on the disk.
+type Auditor struct {
+	sync.Mutex
+
+	genesis  blockstore.GenesisStore
+	blocks   blockstore.BlockStore
+	fac      crypto.VerifierFactory
+	tree     StateTree
+	interval time.Duration
+	sampling int
+	alerters []Alerter
+	logger   zerolog.Logger
+	stats    Stats
+	now      func() time.Time
+	closing  chan struct{}
+	done     chan struct{}
+}
+
+// NewAuditor creates a new auditor of the blocks. The verifier factory must be
+// the one of the collective signing of the chain.
+func NewAuditor(genesis blockstore.GenesisStore, blocks blockstore.BlockStore,
+	fac crypto.VerifierFactory, opts ...Option) *Auditor {
+
+	a := &Auditor{
+		genesis:  genesis,
+		blocks:   blocks,
+		fac:      fac,
+		interval: DefaultInterval,
+		logger:   dela.Logger.With().Str("service", "audit").Logger(),
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Start starts the passes in the background. The first one is done
+// immediately.
+func (a *Auditor) Start() {
+	a.Lock()
+	defer a.Unlock()
+
+	if a.closing != nil {
+		return
+	}
+
+	a.closing = make(chan struct{})
+	a.done = make(chan struct{})
+
+	go a.run(a.closing, a.done)
+}
+
+// Stop stops the passes and waits for the current one to complete.
+func (a *Auditor) Stop() {
+	a.Lock()
+	closing, done := a.closing, a.done
+	a.closing = nil
+	a.Unlock()
+
+	if closing == nil {
+		return
+	}
+
+	close(closing)
+	<-done
+}
+
+// GetStats returns the metrics of the auditor.
+func (a *Auditor) GetStats() Stats {
+	a.Lock()
+	defer a.Unlock()
+
+	stats := a.stats
+	stats.Last = append([]Discrepancy{}, a.stats.Last...)
+
+	return stats
+}
+
+// Audit performs a pass and returns the discrepancies found. It returns an
+// error if the pass cannot be performed, for instance when the chain is not
+// yet set up.
+func (a *Auditor) Audit() ([]Discrepancy, error) {
+	genesis, err := a.genesis.Get()
+	if err != nil {
+		return nil, xerrors.Errorf("genesis: %v", err)
+	}
+
+	// The length is read before and after the calculation of the root so that
+	// a block committed in between doesn't raise a false alarm.
+	length := a.blocks.Len()
+
+	var root []byte
+	var rootErr error
+	if a.tree != nil {
+		root, rootErr = a.tree.CalculateRoot()
+	}
+
+	stable := length == a.blocks.Len()
+
+	a.Lock()
+	pass := a.stats.Passes
+	a.Unlock()
+
+	sample := a.sample(length, pass)
+
+	var found []Discrepancy
+	var signatures uint64
+
+	report := func(kind Kind, index uint64, format string, args ...interface{}) {
+		found = append(found, Discrepancy{
+			Kind:   kind,
+			Index:  index,
+			Reason: xerrors.Errorf(format, args...).Error(),
+			Time:   a.now(),
+		})
+	}
+
+	roster := genesis.GetRoster()
+	prev := genesis.GetHash()
+	expected := genesis.GetRoot()
+
+	// The bodies of the pruned blocks are gone, but their forward links are
+	// enough to verify the chain of rosters.
+	var pruned uint64
+	pruner, ok := a.blocks.(blockstore.Pruner)
+	if ok {
+		pruned = pruner.GetPruned()
+	}
+
+	for index := uint64(0); index < length; index++ {
+		var link types.Link
+
+		if index < pruned {
+			link, err = pruner.GetLinkByIndex(index)
+		} else {
+			var blink types.BlockLink
+			blink, err = a.blocks.GetByIndex(index)
+			if err == nil {
+				if blink.GetBlock().GetIndex() != index {
+					report(ChainKind, index, "mismatch index %d", blink.GetBlock().GetIndex())
+				}
+
+				expected = blink.GetBlock().GetTreeRoot()
+				link = blink
+			}
+		}
+
+		if err != nil {
+			// The following blocks cannot be verified without the previous
+			// one, as the roster might have changed.
+			report(StorageKind, index, "failed to read block: %v", err)
+			stable = false
+			break
+		}
+
+		if index == 0 && genesis.Match(link.GetFrom()) {
+			prev = link.GetFrom()
+		}
+
+		if link.GetFrom() != prev {
+			report(ChainKind, index, "mismatch from: '%v' != '%v'", link.GetFrom(), prev)
+		}
+
+		_, sampled := sample[index]
+		if sample == nil || sampled {
+			signatures++
+
+			err = a.verifyLink(roster, link)
+			if err != nil {
+				report(SignatureKind, index, "%v", err)
+			}
+		}
+
+		prev = link.GetTo()
+		roster = roster.Apply(link.GetChangeSet())
+	}
+
+	if a.tree != nil && stable {
+		switch {
+		case rootErr != nil:
+			report(StateKind, length, "failed to calculate root: %v", rootErr)
+		case !bytes.Equal(root, expected[:]):
+			report(StateKind, length, "mismatch tree root: '%x' != '%v'", root, expected)
+		}
+	}
+
+	a.Lock()
+	a.stats.Passes++
+	a.stats.Blocks = length
+	a.stats.Signatures = signatures
+	a.stats.Discrepancies += len(found)
+	a.stats.LastPass = a.now()
+	a.stats.Last = found
+	a.Unlock()
+
+	for _, d := range found {
+		a.logger.Error().
+			Str("kind", string(d.Kind)).
+			Uint64("index", d.Index).
+			Str("reason", d.Reason).
+			Msg("discrepancy found on disk")
+
+		for _, alerter := range a.alerters {
+			err := alerter.Alert(d)
+			if err != nil {
+				a.logger.Warn().Err(err).Msg("alert failed")
+			}
+		}
+	}
+
+	return found, nil
+}
+
+// sample returns the indices of the blocks whose signatures are verified during
+// the pass, or nil if all of them are.
+func (a *Auditor) sample(length uint64, pass int) map[uint64]struct{} {
+	if a.sampling <= 0 || uint64(a.sampling) >= length {
+		return nil
+	}
+
+	last, err := a.blocks.Last()
+	if err != nil {
+		// The pass verifies every block and reports the failure.
+		return nil
+	}
+
+	seed := make([]byte, len(last.GetTo())+8)
+	copy(seed, last.GetTo().Bytes())
+	binary.LittleEndian.PutUint64(seed[len(last.GetTo()):], uint64(pass))
+
+	weights := make([]uint64, length)
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	sample := make(map[uint64]struct{})
+	for _, index := range mino.SampleIndices(seed, weights, a.sampling) {
+		sample[uint64(index)] = struct{}{}
+	}
+
+	return sample
+}
+
+func (a *Auditor) run(closing, done chan struct{}) {
+	defer close(done)
+
+	for {
+		_, err := a.Audit()
+		if err != nil {
+			a.logger.Debug().Err(err).Msg("audit skipped")
+		}
+
+		select {
+		case <-closing:
+			return
+		case <-time.After(a.interval):
+		}
+	}
+}
+
+// verifyLink verifies the prepare and the commit signatures of the link with
+// the roster effective at its height.
+func (a *Auditor) verifyLink(roster authority.Authority, link types.Link) error {
+	verifier, err := a.fac.FromAuthority(roster)
+	if err != nil {
+		return xerrors.Errorf("verifier factory failed: %v", err)
+	}
+
+	if link.GetPrepareSignature() == nil || link.GetCommitSignature() == nil {
+		return xerrors.New("missing signature")
+	}
+
+	err = verifier.Verify(link.GetHash().Bytes(), link.GetPrepareSignature())
+	if err != nil {
+		return xerrors.Errorf("invalid prepare signature: %v", err)
+	}
+
+	msg, err := link.GetPrepareSignature().MarshalBinary()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal signature: %v", err)
+	}
+
+	err = verifier.Verify(msg, link.GetCommitSignature())
+	if err != nil {
+		return xerrors.Errorf("invalid commit signature: %v", err)
+	}
+
+	return nil
+}
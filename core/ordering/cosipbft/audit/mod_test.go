@@ -0,0 +1,331 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestAuditor_Audit(t *testing.T) {
+	genesis, blocks := makeChain(t, 3)
+
+	tree := &fakeTree{root: []byte{3}}
+	alerter := &fakeAlerter{}
+
+	a := NewAuditor(genesis, blocks, fake.NewVerifierFactory(fake.Verifier{}),
+		WithStateTree(tree), WithAlerter(alerter))
+
+	found, err := a.Audit()
+	require.NoError(t, err)
+	require.Empty(t, found)
+	require.Empty(t, alerter.alerts)
+
+	stats := a.GetStats()
+	require.Equal(t, 1, stats.Passes)
+	require.Equal(t, uint64(3), stats.Blocks)
+	require.Equal(t, 0, stats.Discrepancies)
+
+	tree.root = []byte{2}
+	found, err = a.Audit()
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, StateKind, found[0].Kind)
+	require.Equal(t, uint64(3), found[0].Index)
+	require.Len(t, alerter.alerts, 1)
+
+	tree.err = fake.GetError()
+	found, err = a.Audit()
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, fake.Err("failed to calculate root"), found[0].Reason)
+
+	a.fac = fake.NewVerifierFactory(fake.NewBadVerifier())
+	a.tree = nil
+	found, err = a.Audit()
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+	require.Equal(t, SignatureKind, found[0].Kind)
+	require.Equal(t, fake.Err("invalid prepare signature"), found[0].Reason)
+
+	a.fac = fake.NewBadVerifierFactory()
+	found, err = a.Audit()
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+	require.Equal(t, fake.Err("verifier factory failed"), found[0].Reason)
+
+	require.Equal(t, 5, a.GetStats().Passes)
+	require.Equal(t, 8, a.GetStats().Discrepancies)
+
+	a.genesis = blockstore.NewGenesisStore()
+	_, err = a.Audit()
+	require.EqualError(t, err, "genesis: missing genesis block")
+}
+
+func TestAuditor_Sampling_Audit(t *testing.T) {
+	genesis, blocks := makeChain(t, 10)
+
+	a := NewAuditor(genesis, blocks, fake.NewVerifierFactory(fake.NewBadVerifier()),
+		WithSampling(3))
+
+	found, err := a.Audit()
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+	require.Equal(t, uint64(10), a.GetStats().Blocks)
+	require.Equal(t, uint64(3), a.GetStats().Signatures)
+
+	// Another auditor verifies the same sample for the same pass.
+	other := NewAuditor(genesis, blocks, fake.NewVerifierFactory(fake.NewBadVerifier()),
+		WithSampling(3))
+
+	found2, err := other.Audit()
+	require.NoError(t, err)
+	require.Equal(t, getIndices(found), getIndices(found2))
+
+	found2, err = other.Audit()
+	require.NoError(t, err)
+	require.Len(t, found2, 3)
+	require.NotEqual(t, getIndices(found), getIndices(found2))
+
+	// The whole chain is verified when the sample is larger.
+	a.sampling = 10
+	found, err = a.Audit()
+	require.NoError(t, err)
+	require.Len(t, found, 10)
+	require.Equal(t, uint64(10), a.GetStats().Signatures)
+
+	a.sampling = 3
+	a.blocks = blockstore.NewInMemory()
+	require.Nil(t, a.sample(10, 0))
+}
+
+func TestAuditor_BrokenChain_Audit(t *testing.T) {
+	genesis, _ := makeChain(t, 0)
+
+	blocks := &fakeBlocks{
+		BlockStore: blockstore.NewInMemory(),
+		links:      []types.BlockLink{makeLink(t, types.Digest{}, 1, types.Digest{})},
+	}
+
+	a := NewAuditor(genesis, blocks, fake.NewVerifierFactory(fake.Verifier{}),
+		WithStateTree(&fakeTree{}))
+
+	found, err := a.Audit()
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	require.Equal(t, ChainKind, found[0].Kind)
+	require.Equal(t, "mismatch index 1", found[0].Reason)
+	require.Equal(t, ChainKind, found[1].Kind)
+
+	blocks.err = fake.GetError()
+	found, err = a.Audit()
+	require.NoError(t, err)
+	// The state is not verified when a block is missing.
+	require.Len(t, found, 1)
+	require.Equal(t, StorageKind, found[0].Kind)
+	require.Equal(t, fake.Err("failed to read block"), found[0].Reason)
+}
+
+func TestAuditor_Pruned_Audit(t *testing.T) {
+	genesis, store := makeChain(t, 3)
+
+	blocks := &fakePruner{BlockStore: store, pruned: 2}
+
+	a := NewAuditor(genesis, blocks, fake.NewVerifierFactory(fake.Verifier{}),
+		WithStateTree(&fakeTree{root: []byte{3}}))
+
+	found, err := a.Audit()
+	require.NoError(t, err)
+	require.Empty(t, found)
+
+	a.fac = fake.NewVerifierFactory(fake.NewBadVerifier())
+	found, err = a.Audit()
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+	require.Equal(t, SignatureKind, found[0].Kind)
+
+	a.fac = fake.NewVerifierFactory(fake.Verifier{})
+	blocks.err = fake.GetError()
+	found, err = a.Audit()
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, StorageKind, found[0].Kind)
+	require.Equal(t, uint64(0), found[0].Index)
+	require.Equal(t, fake.Err("failed to read block"), found[0].Reason)
+}
+
+func TestAuditor_Fingerprint_Audit(t *testing.T) {
+	genesis, blocks := makeChain(t, 2)
+
+	// The chain was created with the other version of the fingerprint of the
+	// roster of the genesis block.
+	block, err := genesis.Get()
+	require.NoError(t, err)
+
+	other, err := types.NewGenesis(block.GetRoster(),
+		types.WithGenesisFingerprint(authority.FingerprintV2))
+	require.NoError(t, err)
+
+	genstore := blockstore.NewGenesisStore()
+	require.NoError(t, genstore.Set(other))
+
+	a := NewAuditor(genstore, blocks, fake.NewVerifierFactory(fake.Verifier{}))
+
+	found, err := a.Audit()
+	require.NoError(t, err)
+	require.Empty(t, found)
+}
+
+func TestAuditor_Start(t *testing.T) {
+	genesis, blocks := makeChain(t, 2)
+
+	a := NewAuditor(genesis, blocks, fake.NewVerifierFactory(fake.Verifier{}),
+		WithInterval(time.Millisecond))
+
+	a.Start()
+	// A second start is ignored.
+	a.Start()
+
+	require.Eventually(t, func() bool {
+		return a.GetStats().Passes > 2
+	}, time.Second, time.Millisecond)
+
+	a.Stop()
+	a.Stop()
+
+	passes := a.GetStats().Passes
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, passes, a.GetStats().Passes)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func getIndices(found []Discrepancy) []uint64 {
+	indices := make([]uint64, len(found))
+	for i, d := range found {
+		indices[i] = d.Index
+	}
+
+	return indices
+}
+
+func makeChain(t *testing.T, n int) (blockstore.GenesisStore, blockstore.BlockStore) {
+	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+
+	genesis, err := types.NewGenesis(ro)
+	require.NoError(t, err)
+
+	genstore := blockstore.NewGenesisStore()
+	require.NoError(t, genstore.Set(genesis))
+
+	blocks := blockstore.NewInMemory()
+
+	prev := genesis.GetHash()
+	for i := 0; i < n; i++ {
+		link := makeLink(t, prev, uint64(i), types.Digest{byte(i + 1)})
+
+		require.NoError(t, blocks.Store(link))
+
+		prev = link.GetTo()
+	}
+
+	return genstore, blocks
+}
+
+func makeLink(t *testing.T, from types.Digest, index uint64, root types.Digest) types.BlockLink {
+	block, err := types.NewBlock(simple.NewResult(nil),
+		types.WithIndex(index), types.WithTreeRoot(root))
+	require.NoError(t, err)
+
+	link, err := types.NewBlockLink(from, block,
+		types.WithSignatures(fake.Signature{}, fake.Signature{}))
+	require.NoError(t, err)
+
+	return link
+}
+
+type fakeTree struct {
+	root []byte
+	err  error
+}
+
+func (t *fakeTree) CalculateRoot() ([]byte, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	root := types.Digest{}
+	copy(root[:], t.root)
+
+	return root[:], nil
+}
+
+type fakeAlerter struct {
+	alerts []Discrepancy
+}
+
+func (a *fakeAlerter) Alert(d Discrepancy) error {
+	a.alerts = append(a.alerts, d)
+	return nil
+}
+
+type fakeBlocks struct {
+	blockstore.BlockStore
+
+	links []types.BlockLink
+	err   error
+}
+
+func (b *fakeBlocks) Len() uint64 {
+	return uint64(len(b.links))
+}
+
+func (b *fakeBlocks) GetByIndex(index uint64) (types.BlockLink, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	return b.links[index], nil
+}
+
+type fakePruner struct {
+	blockstore.BlockStore
+
+	pruned uint64
+	err    error
+}
+
+func (b *fakePruner) GetByIndex(index uint64) (types.BlockLink, error) {
+	if index < b.pruned {
+		return nil, blockstore.ErrPruned
+	}
+
+	return b.BlockStore.GetByIndex(index)
+}
+
+func (b *fakePruner) Prune(uint64) (uint64, error) {
+	return 0, nil
+}
+
+func (b *fakePruner) GetPruned() uint64 {
+	return b.pruned
+}
+
+func (b *fakePruner) GetLinkByIndex(index uint64) (types.Link, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	link, err := b.BlockStore.GetByIndex(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return link.Reduce(), nil
+}
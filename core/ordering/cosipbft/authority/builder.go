@@ -0,0 +1,222 @@
+// This file contains the implementation of a builder of change sets.
+//
+// Documentation Last Review: 13.10.2020
+//
+
+package authority
+
+import (
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/vrf"
+	"go.dedis.ch/dela/internal/collection"
+	"go.dedis.ch/dela/mino"
+	"golang.org/x/xerrors"
+)
+
+// ChangeSetBuilder builds a change set for an authority from the addresses of
+// the participants instead of their indices. Each change is validated against
+// the authority and the previous changes, and the first error is returned when
+// the change set is built.
+type ChangeSetBuilder struct {
+	current Authority
+	replace map[uint]crypto.PublicKey
+	remove  *collection.IndexSet
+	added   []participant
+	err     error
+}
+
+// NewChangeSetBuilder creates a new builder of a change set for the authority.
+func NewChangeSetBuilder(current Authority) *ChangeSetBuilder {
+	return &ChangeSetBuilder{
+		current: current,
+		replace: make(map[uint]crypto.PublicKey),
+		remove:  collection.NewIndexSet(),
+	}
+}
+
+// Remove removes the participant with the address. The participant must be in
+// the authority and not already removed.
+func (b *ChangeSetBuilder) Remove(addr mino.Address) *ChangeSetBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	index, err := b.indexOf(addr)
+	if err != nil {
+		b.err = xerrors.Errorf("invalid removal: %v", err)
+		return b
+	}
+
+	if b.replace[index] != nil {
+		b.err = xerrors.Errorf("invalid removal: address '%v' has a replacement", addr)
+		return b
+	}
+
+	b.remove.Add(index)
+
+	return b
+}
+
+// Replace replaces the public key of the participant with the address. The
+// participant must be in the authority, and it can be replaced only once.
+func (b *ChangeSetBuilder) Replace(addr mino.Address, pubkey crypto.PublicKey) *ChangeSetBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	index, err := b.indexOf(addr)
+	if err != nil {
+		b.err = xerrors.Errorf("invalid replacement: %v", err)
+		return b
+	}
+
+	if b.replace[index] != nil {
+		b.err = xerrors.Errorf("invalid replacement: address '%v' already replaced", addr)
+		return b
+	}
+
+	if b.hasPublicKey(pubkey) {
+		b.err = xerrors.New("invalid replacement: public key already in use")
+		return b
+	}
+
+	b.replace[index] = pubkey
+
+	return b
+}
+
+// Add adds a participant with a weight of one.
+func (b *ChangeSetBuilder) Add(addr mino.Address, pubkey crypto.PublicKey) *ChangeSetBuilder {
+	return b.AddWithKeys(addr, pubkey, 1, vrf.PublicKey{}, nil)
+}
+
+// AddWeighted adds a participant with the weight.
+func (b *ChangeSetBuilder) AddWeighted(addr mino.Address, pubkey crypto.PublicKey,
+	weight uint64) *ChangeSetBuilder {
+
+	return b.AddWithKeys(addr, pubkey, weight, vrf.PublicKey{}, nil)
+}
+
+// AddWithKeys adds a participant with the weight, the key of the verifiable
+// random function and the transaction identity, which are optional. The
+// address and the public key must not be in use by another participant, unless
+// it is removed, and the weight must be positive.
+func (b *ChangeSetBuilder) AddWithKeys(addr mino.Address, pubkey crypto.PublicKey,
+	weight uint64, vrfkey vrf.PublicKey, txkey crypto.PublicKey) *ChangeSetBuilder {
+
+	if b.err != nil {
+		return b
+	}
+
+	if weight == 0 {
+		b.err = xerrors.Errorf("invalid addition: weight of '%v' must be positive", addr)
+		return b
+	}
+
+	_, index := b.current.GetPublicKey(addr)
+	if index >= 0 && !b.remove.Has(uint(index)) {
+		b.err = xerrors.Errorf("invalid addition: address '%v' already in the authority", addr)
+		return b
+	}
+
+	for _, p := range b.added {
+		if p.addr.Equal(addr) {
+			b.err = xerrors.Errorf("invalid addition: address '%v' already added", addr)
+			return b
+		}
+	}
+
+	if b.hasPublicKey(pubkey) {
+		b.err = xerrors.New("invalid addition: public key already in use")
+		return b
+	}
+
+	b.added = append(b.added, participant{
+		addr:   addr,
+		pubkey: pubkey,
+		weight: weight,
+		vrfkey: vrfkey,
+		txkey:  txkey,
+	})
+
+	return b
+}
+
+// Build returns the change set, or the first error of the changes. The
+// replacements and the removals are sorted by index, and the additions keep
+// their order, so that the same changes always produce the same change set.
+func (b *ChangeSetBuilder) Build() (*RosterChangeSet, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	cset := NewChangeSet()
+
+	for _, index := range collection.NewIndexSet(b.replaceIndices()...).Ascending() {
+		cset.Replace(index, b.replace[index])
+	}
+
+	for _, index := range b.remove.Ascending() {
+		cset.Remove(index)
+	}
+
+	for _, p := range b.added {
+		cset.AddWithKeys(p.addr, p.pubkey, p.weight, p.vrfkey, p.txkey)
+	}
+
+	return cset, nil
+}
+
+// indexOf returns the index of the participant with the address if it is in
+// the authority and not removed, otherwise an error.
+func (b *ChangeSetBuilder) indexOf(addr mino.Address) (uint, error) {
+	_, index := b.current.GetPublicKey(addr)
+	if index < 0 {
+		return 0, xerrors.Errorf("address '%v' not found", addr)
+	}
+
+	if b.remove.Has(uint(index)) {
+		return 0, xerrors.Errorf("address '%v' already removed", addr)
+	}
+
+	return uint(index), nil
+}
+
+// hasPublicKey returns true if a participant that stays in the authority, or a
+// new one, uses the public key.
+func (b *ChangeSetBuilder) hasPublicKey(pubkey crypto.PublicKey) bool {
+	iter := b.current.PublicKeyIterator()
+	for i := uint(0); iter.HasNext(); i++ {
+		current := iter.GetNext()
+
+		if b.remove.Has(i) {
+			continue
+		}
+
+		replacement, found := b.replace[i]
+		if found {
+			current = replacement
+		}
+
+		if current.Equal(pubkey) {
+			return true
+		}
+	}
+
+	for _, p := range b.added {
+		if p.pubkey.Equal(pubkey) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *ChangeSetBuilder) replaceIndices() []uint {
+	indices := make([]uint, 0, len(b.replace))
+	for index := range b.replace {
+		indices = append(indices, index)
+	}
+
+	return indices
+}
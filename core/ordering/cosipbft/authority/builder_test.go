@@ -0,0 +1,125 @@
+package authority
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/vrf"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestChangeSetBuilder_Build(t *testing.T) {
+	roster := FromAuthority(fake.NewAuthority(4, bls.Generate))
+
+	newKey := bls.NewSigner().GetPublicKey()
+	addKey := bls.NewSigner().GetPublicKey()
+	vrfkey := vrf.NewSigner().GetPublicKey()
+
+	cset, err := NewChangeSetBuilder(roster).
+		Remove(fake.NewAddress(3)).
+		Replace(fake.NewAddress(2), newKey).
+		Remove(fake.NewAddress(0)).
+		AddWithKeys(fake.NewAddress(5), addKey, 2, vrfkey, nil).
+		// A removed participant can be added back with new values.
+		Add(fake.NewAddress(0), roster.pubkeys[0]).
+		Build()
+	require.NoError(t, err)
+	require.Equal(t, []uint{0, 3}, cset.GetRemoveIndices())
+	require.Equal(t, []Replace{{Index: 2, NewPublicKey: newKey}}, cset.GetReplacements())
+	require.Equal(t, []uint64{2, 1}, cset.GetWeights())
+	require.Len(t, cset.GetNewAddresses(), 2)
+
+	next := roster.Apply(cset).(Roster)
+	require.Equal(t, 4, next.Len())
+	require.Equal(t, fake.NewAddress(1), next.addrs[0])
+	require.Equal(t, newKey, next.pubkeys[1])
+	require.Equal(t, fake.NewAddress(5), next.addrs[2])
+	require.Equal(t, fake.NewAddress(0), next.addrs[3])
+
+	// The order of the changes doesn't matter.
+	other, err := NewChangeSetBuilder(roster).
+		Remove(fake.NewAddress(0)).
+		Replace(fake.NewAddress(2), newKey).
+		Remove(fake.NewAddress(3)).
+		AddWithKeys(fake.NewAddress(5), addKey, 2, vrfkey, nil).
+		Add(fake.NewAddress(0), roster.pubkeys[0]).
+		Build()
+	require.NoError(t, err)
+	require.Equal(t, cset, other)
+
+	cset, err = NewChangeSetBuilder(roster).
+		AddWeighted(fake.NewAddress(5), addKey, 3).
+		Build()
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3}, cset.GetWeights())
+}
+
+func TestChangeSetBuilder_Invalid_Build(t *testing.T) {
+	roster := FromAuthority(fake.NewAuthority(3, bls.Generate))
+	pubkey := bls.NewSigner().GetPublicKey()
+
+	_, err := NewChangeSetBuilder(roster).Remove(fake.NewAddress(5)).Build()
+	require.EqualError(t, err, "invalid removal: address 'fake.Address[5]' not found")
+
+	_, err = NewChangeSetBuilder(roster).
+		Remove(fake.NewAddress(1)).
+		Remove(fake.NewAddress(1)).
+		Build()
+	require.EqualError(t, err,
+		"invalid removal: address 'fake.Address[1]' already removed")
+
+	_, err = NewChangeSetBuilder(roster).
+		Replace(fake.NewAddress(1), pubkey).
+		Remove(fake.NewAddress(1)).
+		Build()
+	require.EqualError(t, err,
+		"invalid removal: address 'fake.Address[1]' has a replacement")
+
+	_, err = NewChangeSetBuilder(roster).Replace(fake.NewAddress(5), pubkey).Build()
+	require.EqualError(t, err, "invalid replacement: address 'fake.Address[5]' not found")
+
+	_, err = NewChangeSetBuilder(roster).
+		Replace(fake.NewAddress(1), pubkey).
+		Replace(fake.NewAddress(1), bls.NewSigner().GetPublicKey()).
+		Build()
+	require.EqualError(t, err,
+		"invalid replacement: address 'fake.Address[1]' already replaced")
+
+	_, err = NewChangeSetBuilder(roster).
+		Replace(fake.NewAddress(1), roster.pubkeys[0]).
+		Build()
+	require.EqualError(t, err, "invalid replacement: public key already in use")
+
+	_, err = NewChangeSetBuilder(roster).
+		AddWeighted(fake.NewAddress(5), pubkey, 0).
+		Build()
+	require.EqualError(t, err,
+		"invalid addition: weight of 'fake.Address[5]' must be positive")
+
+	_, err = NewChangeSetBuilder(roster).Add(fake.NewAddress(0), pubkey).Build()
+	require.EqualError(t, err,
+		"invalid addition: address 'fake.Address[0]' already in the authority")
+
+	_, err = NewChangeSetBuilder(roster).
+		Add(fake.NewAddress(5), pubkey).
+		Add(fake.NewAddress(5), bls.NewSigner().GetPublicKey()).
+		Build()
+	require.EqualError(t, err,
+		"invalid addition: address 'fake.Address[5]' already added")
+
+	_, err = NewChangeSetBuilder(roster).
+		Add(fake.NewAddress(5), pubkey).
+		Add(fake.NewAddress(6), pubkey).
+		Build()
+	require.EqualError(t, err, "invalid addition: public key already in use")
+
+	// The changes after the first error are ignored.
+	_, err = NewChangeSetBuilder(roster).
+		Remove(fake.NewAddress(5)).
+		Remove(fake.NewAddress(6)).
+		Replace(fake.NewAddress(6), pubkey).
+		Add(fake.NewAddress(0), pubkey).
+		Build()
+	require.EqualError(t, err, "invalid removal: address 'fake.Address[5]' not found")
+}
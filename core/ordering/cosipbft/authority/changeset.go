@@ -7,6 +7,8 @@ package authority
 
 import (
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/common"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/dela/serde/registry"
@@ -20,13 +22,33 @@ func RegisterChangeSetFormat(c serde.Format, f serde.FormatEngine) {
 	csetFormats.Register(c, f)
 }
 
+// Replace is the replacement of the public key of a participant, which keeps
+// its address, its weight and its other keys so that it can rotate its key
+// without leaving the authority.
+type Replace struct {
+	// Index is the index of the participant in the authority before the
+	// change set is applied.
+	Index uint
+
+	// NewPublicKey is the public key that replaces the one of the participant.
+	NewPublicKey crypto.PublicKey
+}
+
 // RosterChangeSet is the smallest data model to update an authority to another.
 //
 // - implements authority.ChangeSet
 type RosterChangeSet struct {
+	replace []Replace
 	remove  []uint
 	addrs   []mino.Address
 	pubkeys []crypto.PublicKey
+	// weights is nil when every new participant has a weight of one.
+	weights []uint64
+	// vrfkeys is nil when no new participant has a key of the verifiable
+	// random function.
+	vrfkeys []vrf.PublicKey
+	// txkeys is nil when no new participant has a transaction identity.
+	txkeys []crypto.PublicKey
 }
 
 // NewChangeSet creates a new empty change set.
@@ -45,6 +67,67 @@ func (set *RosterChangeSet) GetNewAddresses() []mino.Address {
 	return append([]mino.Address{}, set.addrs...)
 }
 
+// GetWeights returns the list of weights of the new participants.
+func (set *RosterChangeSet) GetWeights() []uint64 {
+	weights := make([]uint64, len(set.addrs))
+	for i := range weights {
+		weights[i] = 1
+
+		if i < len(set.weights) {
+			weights[i] = set.weights[i]
+		}
+	}
+
+	return weights
+}
+
+// IsWeighted returns true if at least one new participant has a weight
+// different from one.
+func (set *RosterChangeSet) IsWeighted() bool {
+	return set.weights != nil
+}
+
+// GetVRFKeys returns the list of keys of the verifiable random function of the
+// new participants. A participant without a key has an empty one.
+func (set *RosterChangeSet) GetVRFKeys() []vrf.PublicKey {
+	keys := make([]vrf.PublicKey, len(set.addrs))
+	copy(keys, set.vrfkeys)
+
+	return keys
+}
+
+// HasVRFKeys returns true if at least one new participant has a key of the
+// verifiable random function.
+func (set *RosterChangeSet) HasVRFKeys() bool {
+	return set.vrfkeys != nil
+}
+
+// GetTxKeys returns the list of transaction identities of the new
+// participants. A participant without one has a nil key.
+func (set *RosterChangeSet) GetTxKeys() []crypto.PublicKey {
+	keys := make([]crypto.PublicKey, len(set.addrs))
+	copy(keys, set.txkeys)
+
+	return keys
+}
+
+// HasTxKeys returns true if at least one new participant has a transaction
+// identity.
+func (set *RosterChangeSet) HasTxKeys() bool {
+	return set.txkeys != nil
+}
+
+// GetReplacements returns the list of public keys to replace in the authority.
+func (set *RosterChangeSet) GetReplacements() []Replace {
+	return append([]Replace{}, set.replace...)
+}
+
+// Replace appends the replacement of the public key of the participant at the
+// index.
+func (set *RosterChangeSet) Replace(index uint, pubkey crypto.PublicKey) {
+	set.replace = append(set.replace, Replace{Index: index, NewPublicKey: pubkey})
+}
+
 // GetRemoveIndices returns the list of indices to remove from the authority.
 func (set *RosterChangeSet) GetRemoveIndices() []uint {
 	return append([]uint{}, set.remove...)
@@ -56,7 +139,45 @@ func (set *RosterChangeSet) Remove(index uint) {
 }
 
 // Add appends the address and the public key to the list of new participants.
+// The participant has a weight of one.
 func (set *RosterChangeSet) Add(addr mino.Address, pubkey crypto.PublicKey) {
+	set.AddWeighted(addr, pubkey, 1)
+}
+
+// AddWeighted appends the address, the public key and the weight to the list
+// of new participants.
+func (set *RosterChangeSet) AddWeighted(addr mino.Address, pubkey crypto.PublicKey, weight uint64) {
+	set.AddWithVRFKey(addr, pubkey, weight, vrf.PublicKey{})
+}
+
+// AddWithVRFKey appends the address, the public key, the weight and the key of
+// the verifiable random function to the list of new participants. An empty key
+// means the participant does not have one.
+func (set *RosterChangeSet) AddWithVRFKey(addr mino.Address, pubkey crypto.PublicKey,
+	weight uint64, vrfkey vrf.PublicKey) {
+
+	set.AddWithKeys(addr, pubkey, weight, vrfkey, nil)
+}
+
+// AddWithKeys appends the address, the public key, the weight, the key of the
+// verifiable random function and the transaction identity to the list of new
+// participants. An empty key of the function, or a nil transaction identity,
+// means the participant does not have one.
+func (set *RosterChangeSet) AddWithKeys(addr mino.Address, pubkey crypto.PublicKey,
+	weight uint64, vrfkey vrf.PublicKey, txkey crypto.PublicKey) {
+
+	if set.weights != nil || weight != 1 {
+		set.weights = append(set.GetWeights(), weight)
+	}
+
+	if set.vrfkeys != nil || hasVRFKey(vrfkey) {
+		set.vrfkeys = append(set.GetVRFKeys(), vrfkey)
+	}
+
+	if set.txkeys != nil || txkey != nil {
+		set.txkeys = append(set.GetTxKeys(), txkey)
+	}
+
 	set.addrs = append(set.addrs, addr)
 	set.pubkeys = append(set.pubkeys, pubkey)
 }
@@ -64,7 +185,7 @@ func (set *RosterChangeSet) Add(addr mino.Address, pubkey crypto.PublicKey) {
 // NumChanges implements authority.ChangeSet. It returns the number of changes
 // that is applied with the change set.
 func (set *RosterChangeSet) NumChanges() int {
-	return len(set.remove) + len(set.addrs)
+	return len(set.replace) + len(set.remove) + len(set.addrs)
 }
 
 // Serialize implements serde.Message. It returns the serialized data for this
@@ -86,19 +207,25 @@ type PubKeyFac struct{}
 // AddrKeyFac is the key for the address factory.
 type AddrKeyFac struct{}
 
+// TxKeyFac is the key for the factory of the transaction identities.
+type TxKeyFac struct{}
+
 // SimpleChangeSetFactory is a message factory to deserialize a change set.
 //
 // - roster.ChangeSetFactory
 type SimpleChangeSetFactory struct {
 	addrFactory   mino.AddressFactory
 	pubkeyFactory crypto.PublicKeyFactory
+	txkeyFactory  crypto.PublicKeyFactory
 }
 
-// NewChangeSetFactory returns a new change set factory.
+// NewChangeSetFactory returns a new change set factory. The transaction
+// identities are deserialized with the common public key factory.
 func NewChangeSetFactory(af mino.AddressFactory, pkf crypto.PublicKeyFactory) ChangeSetFactory {
 	return SimpleChangeSetFactory{
 		addrFactory:   af,
 		pubkeyFactory: pkf,
+		txkeyFactory:  common.NewPublicKeyFactory(),
 	}
 }
 
@@ -115,6 +242,7 @@ func (f SimpleChangeSetFactory) ChangeSetOf(ctx serde.Context, data []byte) (Cha
 
 	ctx = serde.WithFactory(ctx, PubKeyFac{}, f.pubkeyFactory)
 	ctx = serde.WithFactory(ctx, AddrKeyFac{}, f.addrFactory)
+	ctx = serde.WithFactory(ctx, TxKeyFac{}, f.txkeyFactory)
 
 	msg, err := format.Decode(ctx, data)
 	if err != nil {
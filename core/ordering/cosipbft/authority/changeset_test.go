@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/serde"
 )
@@ -30,6 +32,61 @@ func TestChangeSet_GetNewAddresses(t *testing.T) {
 	require.Len(t, cset.GetNewAddresses(), 1)
 }
 
+func TestChangeSet_GetWeights(t *testing.T) {
+	cset := NewChangeSet()
+	require.Len(t, cset.GetWeights(), 0)
+	require.False(t, cset.IsWeighted())
+
+	cset.Add(fake.NewAddress(0), fake.PublicKey{})
+	require.Equal(t, []uint64{1}, cset.GetWeights())
+	require.False(t, cset.IsWeighted())
+
+	cset.AddWeighted(fake.NewAddress(1), fake.PublicKey{}, 3)
+	cset.Add(fake.NewAddress(2), fake.PublicKey{})
+	require.Equal(t, []uint64{1, 3, 1}, cset.GetWeights())
+	require.True(t, cset.IsWeighted())
+}
+
+func TestChangeSet_GetVRFKeys(t *testing.T) {
+	cset := NewChangeSet()
+	require.Len(t, cset.GetVRFKeys(), 0)
+	require.False(t, cset.HasVRFKeys())
+
+	cset.Add(fake.NewAddress(0), fake.PublicKey{})
+	require.Equal(t, []vrf.PublicKey{{}}, cset.GetVRFKeys())
+	require.False(t, cset.HasVRFKeys())
+
+	key := vrf.NewSigner().GetPublicKey()
+
+	cset.AddWithVRFKey(fake.NewAddress(1), fake.PublicKey{}, 1, key)
+	cset.Add(fake.NewAddress(2), fake.PublicKey{})
+	require.Equal(t, []vrf.PublicKey{{}, key, {}}, cset.GetVRFKeys())
+	require.True(t, cset.HasVRFKeys())
+}
+
+func TestChangeSet_GetTxKeys(t *testing.T) {
+	cset := NewChangeSet()
+	require.Len(t, cset.GetTxKeys(), 0)
+	require.False(t, cset.HasTxKeys())
+
+	cset.Add(fake.NewAddress(0), fake.PublicKey{})
+	require.Equal(t, []crypto.PublicKey{nil}, cset.GetTxKeys())
+	require.False(t, cset.HasTxKeys())
+
+	cset.AddWithKeys(fake.NewAddress(1), fake.PublicKey{}, 1, vrf.PublicKey{}, fake.PublicKey{})
+	cset.Add(fake.NewAddress(2), fake.PublicKey{})
+	require.Equal(t, []crypto.PublicKey{nil, fake.PublicKey{}, nil}, cset.GetTxKeys())
+	require.True(t, cset.HasTxKeys())
+}
+
+func TestChangeSet_GetReplacements(t *testing.T) {
+	cset := NewChangeSet()
+	require.Len(t, cset.GetReplacements(), 0)
+
+	cset.Replace(2, fake.PublicKey{})
+	require.Equal(t, []Replace{{Index: 2, NewPublicKey: fake.PublicKey{}}}, cset.GetReplacements())
+}
+
 func TestChangeSet_GetRemoveIndices(t *testing.T) {
 	cset := NewChangeSet()
 	require.Len(t, cset.GetRemoveIndices(), 0)
@@ -47,6 +104,9 @@ func TestChangeSet_NumChanges(t *testing.T) {
 
 	cset.Add(fake.NewAddress(0), fake.PublicKey{})
 	require.Equal(t, 2, cset.NumChanges())
+
+	cset.Replace(0, fake.PublicKey{})
+	require.Equal(t, 3, cset.NumChanges())
 }
 
 func TestChangeSet_Serialize(t *testing.T) {
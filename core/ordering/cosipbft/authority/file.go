@@ -0,0 +1,232 @@
+// This file contains the implementation of a loader that builds a roster from a
+// list of members stored in a file or served by a key server.
+//
+// Documentation Last Review: 13.10.2020
+//
+
+package authority
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/common"
+	"go.dedis.ch/dela/crypto/vrf"
+	"go.dedis.ch/dela/mino"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v2"
+)
+
+// Member is the description of a participant in a list of members. The address
+// is in its text form and the keys are encoded in base64. The weight is one
+// when it is omitted, and the key of the verifiable random function and the
+// transaction identity are optional.
+//
+// A list of members can be written in YAML or in JSON, as the latter is a
+// subset of the former:
+//
+//   - address: 127.0.0.1:2000
+//     publicKey: RUQyNTUxOS...
+//     weight: 2
+type Member struct {
+	Address   string  `yaml:"address" json:"address"`
+	PublicKey string  `yaml:"publicKey" json:"publicKey"`
+	Weight    *uint64 `yaml:"weight,omitempty" json:"weight,omitempty"`
+	VRFKey    string  `yaml:"vrfKey,omitempty" json:"vrfKey,omitempty"`
+	TxKey     string  `yaml:"txKey,omitempty" json:"txKey,omitempty"`
+}
+
+// Loader builds rosters from lists of members, which are validated so that
+// every participant has a unique address and a unique public key.
+type Loader struct {
+	addrFac   mino.AddressFactory
+	pubkeyFac crypto.PublicKeyFactory
+	client    *http.Client
+}
+
+// NewLoader creates a new loader that decodes the addresses and the public keys
+// with the factories.
+func NewLoader(af mino.AddressFactory, pf crypto.PublicKeyFactory) Loader {
+	return Loader{
+		addrFac:   af,
+		pubkeyFac: pf,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// FromFile reads the list of members in the file and returns the roster.
+func (l Loader) FromFile(path string) (Roster, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Roster{}, xerrors.Errorf("failed to read file: %v", err)
+	}
+
+	roster, err := l.FromData(data)
+	if err != nil {
+		return Roster{}, xerrors.Errorf("file '%s': %v", path, err)
+	}
+
+	return roster, nil
+}
+
+// FromURL fetches the list of members from a key server and returns the
+// roster. It expects a successful status code.
+func (l Loader) FromURL(ctx context.Context, url string) (Roster, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return Roster{}, xerrors.Errorf("invalid request: %v", err)
+	}
+
+	resp, err := l.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return Roster{}, xerrors.Errorf("failed to fetch: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return Roster{}, xerrors.Errorf("unexpected status '%s'", resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Roster{}, xerrors.Errorf("failed to read body: %v", err)
+	}
+
+	roster, err := l.FromData(data)
+	if err != nil {
+		return Roster{}, xerrors.Errorf("server '%s': %v", url, err)
+	}
+
+	return roster, nil
+}
+
+// FromData decodes the list of members in YAML or JSON and returns the roster.
+func (l Loader) FromData(data []byte) (Roster, error) {
+	var members []Member
+
+	err := yaml.UnmarshalStrict(data, &members)
+	if err != nil {
+		return Roster{}, xerrors.Errorf("failed to decode: %v", err)
+	}
+
+	return l.FromMembers(members)
+}
+
+// FromMembers returns the roster of the list of members, or an error if a
+// member is invalid or if two of them share an address or a public key.
+func (l Loader) FromMembers(members []Member) (Roster, error) {
+	if len(members) == 0 {
+		return Roster{}, xerrors.New("roster is empty")
+	}
+
+	addrs := make([]mino.Address, len(members))
+	pubkeys := make([]crypto.PublicKey, len(members))
+	weights := make([]uint64, len(members))
+	vrfkeys := make([]vrf.PublicKey, len(members))
+	txkeys := make([]crypto.PublicKey, len(members))
+
+	seenAddrs := make(map[string]struct{})
+	seenKeys := make(map[string]struct{})
+
+	for i, m := range members {
+		if m.Address == "" {
+			return Roster{}, xerrors.Errorf("member %d: missing address", i)
+		}
+
+		_, found := seenAddrs[m.Address]
+		if found {
+			return Roster{}, xerrors.Errorf("member %d: duplicate address '%s'", i, m.Address)
+		}
+
+		seenAddrs[m.Address] = struct{}{}
+
+		_, found = seenKeys[m.PublicKey]
+		if found {
+			return Roster{}, xerrors.Errorf("member %d: duplicate public key", i)
+		}
+
+		seenKeys[m.PublicKey] = struct{}{}
+
+		p, err := l.decodeMember(m)
+		if err != nil {
+			return Roster{}, xerrors.Errorf("member %d: %v", i, err)
+		}
+
+		addrs[i] = p.addr
+		pubkeys[i] = p.pubkey
+		weights[i] = p.weight
+		vrfkeys[i] = p.vrfkey
+		txkeys[i] = p.txkey
+	}
+
+	roster := NewWeighted(addrs, pubkeys, weights).
+		WithVRFKeys(vrfkeys).
+		WithTxKeys(txkeys)
+
+	return roster, nil
+}
+
+// participant is the decoded description of a member.
+type participant struct {
+	addr   mino.Address
+	pubkey crypto.PublicKey
+	weight uint64
+	vrfkey vrf.PublicKey
+	txkey  crypto.PublicKey
+}
+
+func (l Loader) decodeMember(m Member) (participant, error) {
+	p := participant{
+		addr:   l.addrFac.FromText([]byte(m.Address)),
+		weight: 1,
+	}
+
+	buf, err := base64.StdEncoding.DecodeString(m.PublicKey)
+	if err != nil {
+		return p, xerrors.Errorf("base64 public key: %v", err)
+	}
+
+	p.pubkey, err = l.pubkeyFac.FromBytes(buf)
+	if err != nil {
+		return p, xerrors.Errorf("failed to decode public key: %v", err)
+	}
+
+	if m.Weight != nil {
+		if *m.Weight == 0 {
+			return p, xerrors.New("weight must be positive")
+		}
+
+		p.weight = *m.Weight
+	}
+
+	if m.VRFKey != "" {
+		buf, err = base64.StdEncoding.DecodeString(m.VRFKey)
+		if err != nil {
+			return p, xerrors.Errorf("base64 vrf key: %v", err)
+		}
+
+		p.vrfkey, err = vrf.NewPublicKey(buf)
+		if err != nil {
+			return p, xerrors.Errorf("failed to decode vrf key: %v", err)
+		}
+	}
+
+	if m.TxKey != "" {
+		buf, err = base64.StdEncoding.DecodeString(m.TxKey)
+		if err != nil {
+			return p, xerrors.Errorf("base64 tx key: %v", err)
+		}
+
+		p.txkey, err = common.NewPublicKeyFactory().FromBytes(buf)
+		if err != nil {
+			return p, xerrors.Errorf("failed to decode tx key: %v", err)
+		}
+	}
+
+	return p, nil
+}
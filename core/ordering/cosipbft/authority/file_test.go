@@ -0,0 +1,201 @@
+package authority
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/vrf"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+)
+
+func TestLoader_FromFile(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela-roster")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	a, b := makeMember(t), makeMember(t)
+
+	data := fmt.Sprintf(`
+- address: A
+  publicKey: %s
+  weight: 2
+  vrfKey: %s
+  txKey: %s
+- address: B
+  publicKey: %s
+`, a.PublicKey, a.VRFKey, a.TxKey, b.PublicKey)
+
+	path := filepath.Join(dir, "roster.yaml")
+	err = ioutil.WriteFile(path, []byte(data), os.ModePerm)
+	require.NoError(t, err)
+
+	loader := NewLoader(textAddressFactory{}, bls.NewPublicKeyFactory())
+
+	roster, err := loader.FromFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, roster.Len())
+	require.Equal(t, fake.NewAddress('A'), roster.addrs[0])
+	require.Equal(t, uint64(2), roster.GetWeight(0))
+	require.Equal(t, uint64(1), roster.GetWeight(1))
+
+	_, found := roster.GetVRFKey(0)
+	require.True(t, found)
+
+	_, found = roster.GetVRFKey(1)
+	require.False(t, found)
+
+	_, found = roster.GetTxKey(0)
+	require.True(t, found)
+
+	_, err = loader.FromFile(filepath.Join(dir, "unknown.yaml"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read file: ")
+
+	err = ioutil.WriteFile(path, []byte("- unknown: A"), os.ModePerm)
+	require.NoError(t, err)
+
+	_, err = loader.FromFile(path)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), fmt.Sprintf("file '%s': failed to decode: ", path))
+}
+
+func TestLoader_FromURL(t *testing.T) {
+	a := makeMember(t)
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/roster", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"address": "A", "publicKey": "%s"}]`, a.PublicKey)
+	})
+	handler.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+
+	srv := httptest.NewServer(handler)
+
+	loader := NewLoader(textAddressFactory{}, bls.NewPublicKeyFactory())
+
+	roster, err := loader.FromURL(context.Background(), srv.URL+"/roster")
+	require.NoError(t, err)
+	require.Equal(t, 1, roster.Len())
+
+	_, err = loader.FromURL(context.Background(), srv.URL+"/empty")
+	require.EqualError(t, err, fmt.Sprintf("server '%s/empty': roster is empty", srv.URL))
+
+	_, err = loader.FromURL(context.Background(), srv.URL+"/unknown")
+	require.EqualError(t, err, "unexpected status '404 Not Found'")
+
+	_, err = loader.FromURL(context.Background(), "\x7f")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid request: ")
+
+	srv.Close()
+
+	_, err = loader.FromURL(context.Background(), srv.URL+"/roster")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to fetch: ")
+}
+
+func TestLoader_FromMembers(t *testing.T) {
+	loader := NewLoader(textAddressFactory{}, bls.NewPublicKeyFactory())
+
+	a, b := makeMember(t), makeMember(t)
+	b.Address = "B"
+
+	roster, err := loader.FromMembers([]Member{a, b})
+	require.NoError(t, err)
+	require.Equal(t, 2, roster.Len())
+	require.False(t, roster.IsWeighted())
+
+	_, err = loader.FromMembers(nil)
+	require.EqualError(t, err, "roster is empty")
+
+	_, err = loader.FromMembers([]Member{{}})
+	require.EqualError(t, err, "member 0: missing address")
+
+	_, err = loader.FromMembers([]Member{a, a})
+	require.EqualError(t, err, "member 1: duplicate address 'A'")
+
+	b.PublicKey = a.PublicKey
+	_, err = loader.FromMembers([]Member{a, b})
+	require.EqualError(t, err, "member 1: duplicate public key")
+
+	bad := a
+	bad.PublicKey = "?"
+	_, err = loader.FromMembers([]Member{bad})
+	require.EqualError(t, err,
+		"member 0: base64 public key: illegal base64 data at input byte 0")
+
+	badLoader := NewLoader(textAddressFactory{}, fake.NewBadPublicKeyFactory())
+	_, err = badLoader.FromMembers([]Member{a})
+	require.EqualError(t, err, fake.Err("member 0: failed to decode public key"))
+
+	zero := uint64(0)
+	bad = a
+	bad.Weight = &zero
+	_, err = loader.FromMembers([]Member{bad})
+	require.EqualError(t, err, "member 0: weight must be positive")
+
+	bad = a
+	bad.VRFKey = "?"
+	_, err = loader.FromMembers([]Member{bad})
+	require.EqualError(t, err,
+		"member 0: base64 vrf key: illegal base64 data at input byte 0")
+
+	bad.VRFKey = base64.StdEncoding.EncodeToString([]byte{1})
+	_, err = loader.FromMembers([]Member{bad})
+	require.EqualError(t, err,
+		"member 0: failed to decode vrf key: invalid public key size 1 != 32")
+
+	bad = a
+	bad.TxKey = "?"
+	_, err = loader.FromMembers([]Member{bad})
+	require.EqualError(t, err,
+		"member 0: base64 tx key: illegal base64 data at input byte 0")
+
+	bad.TxKey = base64.StdEncoding.EncodeToString([]byte{1})
+	_, err = loader.FromMembers([]Member{bad})
+	require.EqualError(t, err,
+		"member 0: failed to decode tx key: no algorithm matches the data")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeMember(t *testing.T) Member {
+	pubkey, err := bls.NewSigner().GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	vrfkey, err := vrf.NewSigner().GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	txkey, err := bls.NewSigner().GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	return Member{
+		Address:   "A",
+		PublicKey: base64.StdEncoding.EncodeToString(pubkey),
+		VRFKey:    base64.StdEncoding.EncodeToString(vrfkey),
+		TxKey:     base64.StdEncoding.EncodeToString(txkey),
+	}
+}
+
+// textAddressFactory is an address factory that uses the first character of the
+// text as the index of the fake address.
+type textAddressFactory struct {
+	mino.AddressFactory
+}
+
+func (textAddressFactory) FromText(text []byte) mino.Address {
+	return fake.NewAddress(int(text[0]))
+}
@@ -0,0 +1,140 @@
+// This file contains the versions of the fingerprint of an authority.
+
+package authority
+
+import (
+	"encoding/binary"
+	"io"
+
+	"go.dedis.ch/dela/crypto"
+	"golang.org/x/xerrors"
+)
+
+// FingerprintVersion is the version of the fingerprint of an authority.
+type FingerprintVersion uint32
+
+const (
+	// FingerprintV1 is the fingerprint of the authority itself, which
+	// concatenates the fields of the participants.
+	FingerprintV1 FingerprintVersion = 1
+
+	// FingerprintV2 is the fingerprint that starts with a domain separator,
+	// followed by the number of participants and their fields, each prefixed
+	// with its length, so that two different authorities cannot be ambiguous.
+	FingerprintV2 FingerprintVersion = 2
+)
+
+// domainV2 is the domain separator of the second version of the fingerprint.
+const domainV2 = "dela:roster:v2"
+
+// IsKnown returns true if the version is supported.
+func (v FingerprintVersion) IsKnown() bool {
+	return v == FingerprintV1 || v == FingerprintV2
+}
+
+// Fingerprint writes the fingerprint of the authority in the given version
+// into the writer.
+func Fingerprint(w io.Writer, ro Authority, version FingerprintVersion) error {
+	switch version {
+	case FingerprintV1:
+		return ro.Fingerprint(w)
+	case FingerprintV2:
+		return fingerprintV2(w, ro)
+	default:
+		return xerrors.Errorf("unknown fingerprint version %d", version)
+	}
+}
+
+func fingerprintV2(w io.Writer, ro Authority) error {
+	fw := fingerprintWriter{w: w}
+
+	fw.writeField("domain", []byte(domainV2))
+	fw.writeUint32("length", uint32(ro.Len()))
+
+	addrs := ro.AddressIterator()
+	pubkeys := ro.PublicKeyIterator()
+
+	for i := 0; addrs.HasNext() && pubkeys.HasNext(); i++ {
+		data, err := addrs.GetNext().MarshalText()
+		if err != nil {
+			return xerrors.Errorf("couldn't marshal address: %v", err)
+		}
+
+		fw.writeField("address", data)
+
+		data, err = pubkeys.GetNext().MarshalBinary()
+		if err != nil {
+			return xerrors.Errorf("couldn't marshal public key: %v", err)
+		}
+
+		fw.writeField("public key", data)
+		fw.writeUint64("weight", ro.GetWeight(i))
+
+		data = nil
+
+		vrfkey, found := ro.GetVRFKey(i)
+		if found {
+			data, err = vrfkey.MarshalBinary()
+			if err != nil {
+				return xerrors.Errorf("couldn't marshal vrf key: %v", err)
+			}
+		}
+
+		fw.writeField("vrf key", data)
+
+		data, err = marshalKey(ro.GetTxKey(i))
+		if err != nil {
+			return xerrors.Errorf("couldn't marshal tx key: %v", err)
+		}
+
+		fw.writeField("tx key", data)
+	}
+
+	return fw.err
+}
+
+// marshalKey returns the data of the key if it is found, otherwise nil.
+func marshalKey(key crypto.PublicKey, found bool) ([]byte, error) {
+	if !found {
+		return nil, nil
+	}
+
+	return key.MarshalBinary()
+}
+
+// fingerprintWriter is a writer that keeps the first error so that the fields
+// are written without checking each of them.
+type fingerprintWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (fw *fingerprintWriter) writeField(name string, data []byte) {
+	fw.writeUint32(name, uint32(len(data)))
+	fw.write(name, data)
+}
+
+func (fw *fingerprintWriter) writeUint32(name string, value uint32) {
+	buffer := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buffer, value)
+
+	fw.write(name, buffer)
+}
+
+func (fw *fingerprintWriter) writeUint64(name string, value uint64) {
+	buffer := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buffer, value)
+
+	fw.write(name, buffer)
+}
+
+func (fw *fingerprintWriter) write(name string, data []byte) {
+	if fw.err != nil {
+		return
+	}
+
+	_, err := fw.w.Write(data)
+	if err != nil {
+		fw.err = xerrors.Errorf("couldn't write %s: %v", name, err)
+	}
+}
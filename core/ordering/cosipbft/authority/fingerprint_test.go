@@ -0,0 +1,105 @@
+package authority
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/vrf"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestFingerprintVersion_IsKnown(t *testing.T) {
+	require.True(t, FingerprintV1.IsKnown())
+	require.True(t, FingerprintV2.IsKnown())
+	require.False(t, FingerprintVersion(0).IsKnown())
+	require.False(t, FingerprintVersion(3).IsKnown())
+}
+
+func TestFingerprint_V1(t *testing.T) {
+	roster := FromAuthority(fake.NewAuthority(2, fake.NewSigner))
+
+	expected := new(bytes.Buffer)
+	require.NoError(t, roster.Fingerprint(expected))
+
+	out := new(bytes.Buffer)
+	err := Fingerprint(out, roster, FingerprintV1)
+	require.NoError(t, err)
+	require.Equal(t, expected.String(), out.String())
+}
+
+func TestFingerprint_V2(t *testing.T) {
+	roster := FromAuthority(fake.NewAuthority(1, fake.NewSigner))
+
+	out := new(bytes.Buffer)
+	err := Fingerprint(out, roster, FingerprintV2)
+	require.NoError(t, err)
+	require.Equal(t, "\x0e\x00\x00\x00dela:roster:v2\x01\x00\x00\x00"+
+		"\x04\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00PK"+
+		"\x01\x00\x00\x00\x00\x00\x00\x00"+
+		"\x00\x00\x00\x00\x00\x00\x00\x00", out.String())
+
+	// The optional fields are always written, so that the weight of one is
+	// the same as no weight.
+	weighted := roster
+	weighted.weights = []uint64{1}
+
+	other := new(bytes.Buffer)
+	require.NoError(t, Fingerprint(other, weighted, FingerprintV2))
+	require.Equal(t, out.String(), other.String())
+
+	key := vrf.NewSigner().GetPublicKey()
+	keyData, err := key.MarshalBinary()
+	require.NoError(t, err)
+
+	roster = roster.WithVRFKeys([]vrf.PublicKey{key}).WithTxKeys([]crypto.PublicKey{fake.PublicKey{}})
+
+	out.Reset()
+	err = Fingerprint(out, roster, FingerprintV2)
+	require.NoError(t, err)
+	require.True(t, bytes.HasSuffix(out.Bytes(),
+		append(append([]byte{byte(len(keyData)), 0, 0, 0}, keyData...), "\x02\x00\x00\x00PK"...)))
+}
+
+func TestFingerprint_Unknown(t *testing.T) {
+	roster := FromAuthority(fake.NewAuthority(1, fake.NewSigner))
+
+	err := Fingerprint(new(bytes.Buffer), roster, FingerprintVersion(3))
+	require.EqualError(t, err, "unknown fingerprint version 3")
+}
+
+func TestFingerprint_Failures_V2(t *testing.T) {
+	roster := FromAuthority(fake.NewAuthority(1, fake.NewSigner)).
+		WithVRFKeys([]vrf.PublicKey{vrf.NewSigner().GetPublicKey()}).
+		WithTxKeys([]crypto.PublicKey{fake.PublicKey{}})
+
+	writes := map[int]string{
+		0:  "domain",
+		2:  "length",
+		3:  "address",
+		5:  "public key",
+		7:  "weight",
+		8:  "vrf key",
+		10: "tx key",
+	}
+
+	for delay, name := range writes {
+		err := Fingerprint(fake.NewBadHashWithDelay(delay), roster, FingerprintV2)
+		require.EqualError(t, err, fake.Err("couldn't write "+name))
+	}
+
+	roster.addrs[0] = fake.NewBadAddress()
+	err := Fingerprint(new(bytes.Buffer), roster, FingerprintV2)
+	require.EqualError(t, err, fake.Err("couldn't marshal address"))
+
+	roster.addrs[0] = fake.NewAddress(0)
+	roster.pubkeys[0] = fake.NewBadPublicKey()
+	err = Fingerprint(new(bytes.Buffer), roster, FingerprintV2)
+	require.EqualError(t, err, fake.Err("couldn't marshal public key"))
+
+	roster.pubkeys[0] = fake.PublicKey{}
+	roster = roster.WithTxKeys([]crypto.PublicKey{fake.NewBadPublicKey()})
+	err = Fingerprint(new(bytes.Buffer), roster, FingerprintV2)
+	require.EqualError(t, err, fake.Err("couldn't marshal tx key"))
+}
@@ -5,6 +5,7 @@ import (
 
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 	"golang.org/x/xerrors"
@@ -16,17 +17,33 @@ func init() {
 }
 
 // Player is a JSON message that contains the address and the public key of a
-// new participant.
+// new participant. The weight is omitted when the roster is not weighted, and
+// the key of the verifiable random function and the transaction identity when
+// the participant has none.
 type Player struct {
 	Address   []byte
 	PublicKey json.RawMessage
+	Weight    *uint64 `json:",omitempty"`
+	VRFKey    []byte  `json:",omitempty"`
+	TxKey     []byte  `json:",omitempty"`
 }
 
-// ChangeSet is a JSON message of the change set of an authority.
+// Replacement is a JSON message of the new public key of a participant.
+type Replacement struct {
+	Index     uint
+	PublicKey json.RawMessage
+}
+
+// ChangeSet is a JSON message of the change set of an authority. The
+// replacements are omitted when the change set has none.
 type ChangeSet struct {
+	Replace    []Replacement `json:",omitempty"`
 	Remove     []uint
 	Addresses  [][]byte
 	PublicKeys []json.RawMessage
+	Weights    []uint64 `json:",omitempty"`
+	VRFKeys    [][]byte `json:",omitempty"`
+	TxKeys     [][]byte `json:",omitempty"`
 }
 
 // Address is a JSON message for an address.
@@ -75,6 +92,32 @@ func (f changeSetFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, e
 		PublicKeys: pubkeys,
 	}
 
+	for _, r := range cset.GetReplacements() {
+		raw, err := r.NewPublicKey.Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't serialize new public key: %v", err)
+		}
+
+		m.Replace = append(m.Replace, Replacement{Index: r.Index, PublicKey: raw})
+	}
+
+	if cset.IsWeighted() {
+		m.Weights = cset.GetWeights()
+	}
+
+	if cset.HasVRFKeys() {
+		m.VRFKeys = encodeVRFKeys(cset.GetVRFKeys())
+	}
+
+	if cset.HasTxKeys() {
+		txkeys, err := encodeTxKeys(ctx, cset.GetTxKeys())
+		if err != nil {
+			return nil, err
+		}
+
+		m.TxKeys = txkeys
+	}
+
 	data, err := ctx.Marshal(m)
 	if err != nil {
 		return nil, xerrors.Errorf("couldn't marshal: %v", err)
@@ -106,8 +149,32 @@ func (f changeSetFormat) Decode(ctx serde.Context, data []byte) (serde.Message,
 		return nil, xerrors.Errorf("invalid address factory of type '%T'", factory)
 	}
 
+	if m.Weights != nil && len(m.Weights) != len(m.Addresses) {
+		return nil, xerrors.Errorf("mismatch weights length %d != %d",
+			len(m.Weights), len(m.Addresses))
+	}
+
+	if m.VRFKeys != nil && len(m.VRFKeys) != len(m.Addresses) {
+		return nil, xerrors.Errorf("mismatch vrf keys length %d != %d",
+			len(m.VRFKeys), len(m.Addresses))
+	}
+
+	if m.TxKeys != nil && len(m.TxKeys) != len(m.Addresses) {
+		return nil, xerrors.Errorf("mismatch tx keys length %d != %d",
+			len(m.TxKeys), len(m.Addresses))
+	}
+
 	cset := authority.NewChangeSet()
 
+	for _, r := range m.Replace {
+		pubkey, err := pkFac.PublicKeyOf(ctx, r.PublicKey)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't deserialize new public key: %v", err)
+		}
+
+		cset.Replace(r.Index, pubkey)
+	}
+
 	for _, index := range m.Remove {
 		cset.Remove(index)
 	}
@@ -120,7 +187,28 @@ func (f changeSetFormat) Decode(ctx serde.Context, data []byte) (serde.Message,
 			return nil, xerrors.Errorf("couldn't deserialize public key: %v", err)
 		}
 
-		cset.Add(addr, pubkey)
+		weight := uint64(1)
+		if m.Weights != nil {
+			weight = m.Weights[i]
+		}
+
+		vrfkey := vrf.PublicKey{}
+		if m.VRFKeys != nil {
+			vrfkey, err = decodeVRFKey(m.VRFKeys[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var txkey crypto.PublicKey
+		if m.TxKeys != nil {
+			txkey, err = decodeTxKey(ctx, m.TxKeys[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		cset.AddWithKeys(addr, pubkey, weight, vrfkey, txkey)
 	}
 
 	return cset, nil
@@ -159,6 +247,27 @@ func (f rosterFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, erro
 			Address:   addr,
 			PublicKey: pubkey,
 		}
+
+		if roster.IsWeighted() {
+			weight := roster.GetWeight(i)
+			players[i].Weight = &weight
+		}
+
+		vrfkey, found := roster.GetVRFKey(i)
+		if found {
+			players[i].VRFKey, err = vrfkey.MarshalBinary()
+			if err != nil {
+				return nil, xerrors.Errorf("couldn't marshal vrf key: %v", err)
+			}
+		}
+
+		txkey, found := roster.GetTxKey(i)
+		if found {
+			players[i].TxKey, err = txkey.Serialize(ctx)
+			if err != nil {
+				return nil, xerrors.Errorf("couldn't serialize tx key: %v", err)
+			}
+		}
 	}
 
 	m := Roster(players)
@@ -196,6 +305,9 @@ func (f rosterFormat) Decode(ctx serde.Context, data []byte) (serde.Message, err
 
 	addrs := make([]mino.Address, len(m))
 	pubkeys := make([]crypto.PublicKey, len(m))
+	weights := make([]uint64, len(m))
+	vrfkeys := make([]vrf.PublicKey, len(m))
+	txkeys := make([]crypto.PublicKey, len(m))
 
 	for i, player := range m {
 		addrs[i] = addrFac.FromText(player.Address)
@@ -206,7 +318,96 @@ func (f rosterFormat) Decode(ctx serde.Context, data []byte) (serde.Message, err
 		}
 
 		pubkeys[i] = pubkey
+
+		weights[i] = 1
+		if player.Weight != nil {
+			weights[i] = *player.Weight
+		}
+
+		vrfkeys[i], err = decodeVRFKey(player.VRFKey)
+		if err != nil {
+			return nil, err
+		}
+
+		txkeys[i], err = decodeTxKey(ctx, player.TxKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	roster := authority.NewWeighted(addrs, pubkeys, weights).
+		WithVRFKeys(vrfkeys).
+		WithTxKeys(txkeys)
+
+	return roster, nil
+}
+
+// encodeVRFKeys returns the data of the keys, where an empty key has no data.
+func encodeVRFKeys(keys []vrf.PublicKey) [][]byte {
+	data := make([][]byte, len(keys))
+
+	for i, key := range keys {
+		// The only error is for an empty key which is then left without data.
+		data[i], _ = key.MarshalBinary()
+	}
+
+	return data
+}
+
+// decodeVRFKey returns the key of the data, or an empty key if there is no
+// data.
+func decodeVRFKey(data []byte) (vrf.PublicKey, error) {
+	if len(data) == 0 {
+		return vrf.PublicKey{}, nil
+	}
+
+	key, err := vrf.NewPublicKey(data)
+	if err != nil {
+		return key, xerrors.Errorf("invalid vrf key: %v", err)
+	}
+
+	return key, nil
+}
+
+// encodeTxKeys returns the serialized data of the transaction identities, where
+// a missing identity has no data.
+func encodeTxKeys(ctx serde.Context, keys []crypto.PublicKey) ([][]byte, error) {
+	data := make([][]byte, len(keys))
+
+	for i, key := range keys {
+		if key == nil {
+			continue
+		}
+
+		raw, err := key.Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't serialize tx key: %v", err)
+		}
+
+		data[i] = raw
+	}
+
+	return data, nil
+}
+
+// decodeTxKey returns the transaction identity of the data, or nil if there is
+// no data.
+func decodeTxKey(ctx serde.Context, data []byte) (crypto.PublicKey, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	factory := ctx.GetFactory(authority.TxKeyFac{})
+
+	txFac, ok := factory.(crypto.PublicKeyFactory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid tx key factory of type '%T'", factory)
+	}
+
+	key, err := txFac.PublicKeyOf(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't deserialize tx key: %v", err)
 	}
 
-	return authority.New(addrs, pubkeys), nil
+	return key, nil
 }
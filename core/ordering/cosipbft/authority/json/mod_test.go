@@ -2,11 +2,20 @@ package json
 
 import (
 	"testing"
+	"testing/quick"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	_ "go.dedis.ch/dela/crypto/bls/json"
+	"go.dedis.ch/dela/crypto/common"
+	_ "go.dedis.ch/dela/crypto/common/json"
+	"go.dedis.ch/dela/crypto/ed25519"
+	_ "go.dedis.ch/dela/crypto/ed25519/json"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/internal/testing/gen"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 )
@@ -24,6 +33,14 @@ func TestChangeSetFormat_Encode(t *testing.T) {
 	expected := `{"Remove":[42],"Addresses":["AgAAAA=="],"PublicKeys":[{}]}`
 	require.Equal(t, expected, string(data))
 
+	cset.AddWeighted(fake.NewAddress(3), fake.PublicKey{}, 3)
+
+	data, err = format.Encode(ctx, cset)
+	require.NoError(t, err)
+	expected = `{"Remove":[42],"Addresses":["AgAAAA==","AwAAAA=="],` +
+		`"PublicKeys":[{},{}],"Weights":[1,3]}`
+	require.Equal(t, expected, string(data))
+
 	_, err = format.Encode(ctx, fake.Message{})
 	require.EqualError(t, err, "unsupported message of type 'fake.Message'")
 
@@ -63,6 +80,16 @@ func TestChangeSetFormat_Decode(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, cset, msg)
 
+	cset = authority.NewChangeSet()
+	cset.AddWeighted(fake.NewAddress(0), fake.PublicKey{}, 3)
+
+	msg, err = format.Decode(ctx, []byte(`{"Addresses":[[]],"PublicKeys":[{}],"Weights":[3]}`))
+	require.NoError(t, err)
+	require.Equal(t, cset, msg)
+
+	_, err = format.Decode(ctx, []byte(`{"Addresses":[[]],"PublicKeys":[{}],"Weights":[]}`))
+	require.EqualError(t, err, "mismatch weights length 0 != 1")
+
 	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
 	require.EqualError(t, err, fake.Err("couldn't deserialize change set"))
 
@@ -89,6 +116,14 @@ func TestRosterFormat_Encode(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, `[{"Address":"AAAAAA==","PublicKey":{}}]`, string(data))
 
+	weighted := authority.NewWeighted([]mino.Address{fake.NewAddress(0), fake.NewAddress(1)},
+		[]crypto.PublicKey{fake.PublicKey{}, fake.PublicKey{}}, []uint64{2, 1})
+
+	data, err = format.Encode(ctx, weighted)
+	require.NoError(t, err)
+	require.Equal(t, `[{"Address":"AAAAAA==","PublicKey":{},"Weight":2},`+
+		`{"Address":"AQAAAA==","PublicKey":{},"Weight":1}]`, string(data))
+
 	_, err = format.Encode(fake.NewContext(), fake.Message{})
 	require.EqualError(t, err, "unsupported message of type 'fake.Message'")
 
@@ -114,6 +149,11 @@ func TestRosterFormat_Decode(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, authority.FromAuthority(fake.NewAuthority(1, fake.NewSigner)), ro)
 
+	ro, err = format.Decode(ctx, []byte(`[{"Weight":0},{}]`))
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), ro.(authority.Roster).GetWeight(0))
+	require.Equal(t, uint64(1), ro.(authority.Roster).GetWeight(1))
+
 	_, err = format.Decode(fake.NewBadContext(), []byte(`[]`))
 	require.EqualError(t, err, fake.Err("couldn't deserialize roster"))
 
@@ -129,3 +169,221 @@ func TestRosterFormat_Decode(t *testing.T) {
 	_, err = format.Decode(badCtx, []byte(`[{}]`))
 	require.EqualError(t, err, "invalid public key factory of type '<nil>'")
 }
+
+func TestRosterFormat_MixedPublicKeys(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+	fac := authority.NewFactory(fake.AddressFactory{}, common.NewPublicKeyFactory())
+
+	addrs := []mino.Address{fake.NewAddress(0), fake.NewAddress(1)}
+	pubkeys := []crypto.PublicKey{
+		bls.NewSigner().GetPublicKey(),
+		ed25519.NewSigner().GetPublicKey(),
+	}
+
+	roster := authority.New(addrs, pubkeys)
+
+	data, err := roster.Serialize(ctx)
+	require.NoError(t, err)
+
+	decoded, err := fac.AuthorityOf(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, 2, decoded.Len())
+
+	iter := decoded.PublicKeyIterator()
+	for i := 0; iter.HasNext(); i++ {
+		require.True(t, pubkeys[i].Equal(iter.GetNext()))
+	}
+}
+
+func TestRosterFormat_Weighted_RoundTrip(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+	fac := authority.NewFactory(fake.AddressFactory{}, common.NewPublicKeyFactory())
+
+	addrs := []mino.Address{fake.NewAddress(0), fake.NewAddress(1)}
+	pubkeys := []crypto.PublicKey{
+		bls.NewSigner().GetPublicKey(),
+		bls.NewSigner().GetPublicKey(),
+	}
+
+	roster := authority.NewWeighted(addrs, pubkeys, []uint64{3, 1})
+
+	data, err := roster.Serialize(ctx)
+	require.NoError(t, err)
+
+	decoded, err := fac.AuthorityOf(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), decoded.GetWeight(0))
+	require.Equal(t, uint64(1), decoded.GetWeight(1))
+	require.Equal(t, uint64(4), decoded.TotalWeight())
+}
+
+func TestFormats_VRFKeys_RoundTrip(t *testing.T) {
+	ctx := serde.NewContext(fake.ContextEngine{})
+	ctx = serde.WithFactory(ctx, authority.AddrKeyFac{}, fake.AddressFactory{})
+	ctx = serde.WithFactory(ctx, authority.PubKeyFac{}, fake.PublicKeyFactory{})
+
+	key := vrf.NewSigner().GetPublicKey()
+
+	roster := authority.FromAuthority(fake.NewAuthority(2, fake.NewSigner))
+	roster = roster.WithVRFKeys([]vrf.PublicKey{{}, key})
+
+	data, err := rosterFormat{}.Encode(ctx, roster)
+	require.NoError(t, err)
+
+	msg, err := rosterFormat{}.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, roster, msg)
+
+	cset := authority.NewChangeSet()
+	cset.Add(fake.NewAddress(0), fake.PublicKey{})
+	cset.AddWithVRFKey(fake.NewAddress(1), fake.PublicKey{}, 1, key)
+
+	data, err = changeSetFormat{}.Encode(ctx, cset)
+	require.NoError(t, err)
+
+	msg, err = changeSetFormat{}.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, cset.GetVRFKeys(), msg.(*authority.RosterChangeSet).GetVRFKeys())
+
+	_, err = rosterFormat{}.Decode(ctx, []byte(`[{"VRFKey":"AA=="}]`))
+	require.EqualError(t, err, "invalid vrf key: invalid public key size 1 != 32")
+
+	_, err = changeSetFormat{}.Decode(ctx, []byte(`{"Addresses":[""],"PublicKeys":["e30="],"VRFKeys":["AA==","AA=="]}`))
+	require.EqualError(t, err, "mismatch vrf keys length 2 != 1")
+}
+
+func TestFormats_TxKeys_RoundTrip(t *testing.T) {
+	ctx := serde.NewContext(fake.ContextEngine{})
+	ctx = serde.WithFactory(ctx, authority.AddrKeyFac{}, fake.AddressFactory{})
+	ctx = serde.WithFactory(ctx, authority.PubKeyFac{}, fake.PublicKeyFactory{})
+	ctx = serde.WithFactory(ctx, authority.TxKeyFac{}, fake.PublicKeyFactory{})
+
+	roster := authority.FromAuthority(fake.NewAuthority(2, fake.NewSigner))
+	roster = roster.WithTxKeys([]crypto.PublicKey{nil, fake.PublicKey{}})
+
+	data, err := rosterFormat{}.Encode(ctx, roster)
+	require.NoError(t, err)
+
+	msg, err := rosterFormat{}.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, roster, msg)
+
+	cset := authority.NewChangeSet()
+	cset.Add(fake.NewAddress(0), fake.PublicKey{})
+	cset.AddWithKeys(fake.NewAddress(1), fake.PublicKey{}, 1, vrf.PublicKey{}, fake.PublicKey{})
+
+	data, err = changeSetFormat{}.Encode(ctx, cset)
+	require.NoError(t, err)
+
+	msg, err = changeSetFormat{}.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, cset.GetTxKeys(), msg.(*authority.RosterChangeSet).GetTxKeys())
+
+	badCtx := serde.WithFactory(ctx, authority.TxKeyFac{}, fake.NewBadPublicKeyFactory())
+	_, err = rosterFormat{}.Decode(badCtx, []byte(`[{"TxKey":"AA=="}]`))
+	require.EqualError(t, err, fake.Err("couldn't deserialize tx key"))
+
+	badCtx = serde.WithFactory(ctx, authority.TxKeyFac{}, nil)
+	_, err = rosterFormat{}.Decode(badCtx, []byte(`[{"TxKey":"AA=="}]`))
+	require.EqualError(t, err, "invalid tx key factory of type '<nil>'")
+
+	_, err = changeSetFormat{}.Decode(ctx, []byte(`{"Addresses":[""],"PublicKeys":["e30="],"TxKeys":["AA==","AA=="]}`))
+	require.EqualError(t, err, "mismatch tx keys length 2 != 1")
+
+	roster = roster.WithTxKeys([]crypto.PublicKey{fake.NewBadPublicKey(), nil})
+	_, err = rosterFormat{}.Encode(ctx, roster)
+	require.EqualError(t, err, fake.Err("couldn't serialize tx key"))
+
+	cset = authority.NewChangeSet()
+	cset.AddWithKeys(fake.NewAddress(0), fake.PublicKey{}, 1, vrf.PublicKey{}, fake.NewBadPublicKey())
+	_, err = changeSetFormat{}.Encode(ctx, cset)
+	require.EqualError(t, err, fake.Err("couldn't serialize tx key"))
+}
+
+func TestFormats_Replace_RoundTrip(t *testing.T) {
+	ctx := serde.NewContext(fake.ContextEngine{})
+	ctx = serde.WithFactory(ctx, authority.AddrKeyFac{}, fake.AddressFactory{})
+	ctx = serde.WithFactory(ctx, authority.PubKeyFac{}, fake.PublicKeyFactory{})
+
+	cset := authority.NewChangeSet()
+	cset.Replace(1, fake.PublicKey{})
+	cset.Remove(2)
+
+	data, err := changeSetFormat{}.Encode(ctx, cset)
+	require.NoError(t, err)
+
+	msg, err := changeSetFormat{}.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, cset, msg)
+
+	badCtx := serde.WithFactory(ctx, authority.PubKeyFac{}, fake.NewBadPublicKeyFactory())
+	_, err = changeSetFormat{}.Decode(badCtx, []byte(`{"Replace":[{"Index":1,"PublicKey":{}}]}`))
+	require.EqualError(t, err, fake.Err("couldn't deserialize new public key"))
+
+	cset = authority.NewChangeSet()
+	cset.Replace(0, fake.NewBadPublicKey())
+	_, err = changeSetFormat{}.Encode(ctx, cset)
+	require.EqualError(t, err, fake.Err("couldn't serialize new public key"))
+}
+
+func TestChangeSetFormat_Quick_RoundTrip(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+	fac := authority.NewChangeSetFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+
+	f := func(cset gen.ChangeSet) bool {
+		data, err := cset.Serialize(ctx)
+		require.NoError(t, err)
+
+		decoded, err := fac.ChangeSetOf(ctx, data)
+		require.NoError(t, err)
+		require.Equal(t, cset.NumChanges(), decoded.NumChanges())
+
+		again, err := decoded.Serialize(ctx)
+		require.NoError(t, err)
+		require.Equal(t, string(data), string(again))
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 20})
+	require.NoError(t, err)
+}
+
+func TestRosterFormat_Quick_RoundTrip(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+	fac := authority.NewFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+
+	f := func(roster gen.Roster) bool {
+		data, err := roster.Serialize(ctx)
+		require.NoError(t, err)
+
+		decoded, err := fac.AuthorityOf(ctx, data)
+		require.NoError(t, err)
+		require.Equal(t, roster.Len(), decoded.Len())
+
+		again, err := decoded.Serialize(ctx)
+		require.NoError(t, err)
+		require.Equal(t, string(data), string(again))
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 20})
+	require.NoError(t, err)
+}
+
+func TestFormats_Quick_Malformed(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+	rosterFac := authority.NewFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+	csetFac := authority.NewChangeSetFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+
+	f := func(input gen.Malformed) bool {
+		require.NotPanics(t, func() { rosterFac.AuthorityOf(ctx, input.Data) })
+		require.NotPanics(t, func() { csetFac.ChangeSetOf(ctx, input.Data) })
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 200})
+	require.NoError(t, err)
+}
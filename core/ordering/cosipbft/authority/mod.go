@@ -3,7 +3,16 @@
 // The package also contains an implementation of a roster and the related
 // change set. A roster is a list of participants where each of them has an Mino
 // address and a corresponding public key that supports aggregation for the
-// collective signing.
+// collective signing. Each participant can optionally be given a weight that
+// defines its voting rights, which is one by default, the public key of a
+// verifiable random function that proves its election as a leader, and the
+// public key of the identity that signs its transactions so that the key of
+// the consensus is reserved to it. A change set can replace the public key of a
+// participant so that it rotates its key without leaving the roster. A change
+// set can be built from the addresses of the participants with a builder that
+// validates the changes against the roster. A roster can be built from a list
+// of members in a file or served by a key server. The fingerprint of an
+// authority has two versions, the second one being unambiguous.
 //
 // Documentation Last Review: 13.10.2020
 //
@@ -11,6 +20,7 @@ package authority
 
 import (
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 )
@@ -39,14 +49,23 @@ type ChangeSetFactory interface {
 type Authority interface {
 	serde.Message
 	serde.Fingerprinter
-	crypto.CollectiveAuthority
+	crypto.WeightedAuthority
 
 	// Apply must apply the change set to the collective authority. It should
-	// first remove, then add the new players.
+	// first replace the public keys, then remove, then add the new players.
 	Apply(ChangeSet) Authority
 
 	// Diff should return the change set to apply to get the given authority.
 	Diff(Authority) ChangeSet
+
+	// GetVRFKey should return the key of the verifiable random function of the
+	// participant at the given index and true if it has one, otherwise false.
+	GetVRFKey(index int) (vrf.PublicKey, bool)
+
+	// GetTxKey should return the public key of the identity that signs the
+	// transactions of the participant at the given index and true if it has
+	// one, otherwise false.
+	GetTxKey(index int) (crypto.PublicKey, bool)
 }
 
 // Factory is the factory to deserialize authorities.
@@ -0,0 +1,462 @@
+package proto
+
+import (
+	protobuf "github.com/golang/protobuf/proto"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/vrf"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	authority.RegisterChangeSetFormat(serde.FormatProtobuf, changeSetFormat{})
+	authority.RegisterRosterFormat(serde.FormatProtobuf, rosterFormat{})
+}
+
+// Player is a protobuf message that contains the address and the public key of
+// a participant, and its optional key of the verifiable random function and
+// transaction identity.
+type Player struct {
+	Address   []byte `protobuf:"bytes,1,opt,name=address,proto3"`
+	PublicKey []byte `protobuf:"bytes,2,opt,name=public_key,json=publicKey,proto3"`
+	VrfKey    []byte `protobuf:"bytes,3,opt,name=vrf_key,json=vrfKey,proto3" json:",omitempty"`
+	TxKey     []byte `protobuf:"bytes,4,opt,name=tx_key,json=txKey,proto3" json:",omitempty"`
+}
+
+// Reset implements proto.Message.
+func (m *Player) Reset() { *m = Player{} }
+
+// String implements proto.Message.
+func (m *Player) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*Player) ProtoMessage() {}
+
+// Roster is a protobuf message for an authority.
+type Roster struct {
+	Players []*Player `protobuf:"bytes,1,rep,name=players,proto3"`
+	Weights []uint64  `protobuf:"varint,2,rep,packed,name=weights,proto3" json:",omitempty"`
+}
+
+// Reset implements proto.Message.
+func (m *Roster) Reset() { *m = Roster{} }
+
+// String implements proto.Message.
+func (m *Roster) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*Roster) ProtoMessage() {}
+
+// Replacement is a protobuf message of the new public key of a participant.
+type Replacement struct {
+	Index     uint32 `protobuf:"varint,1,opt,name=index,proto3"`
+	PublicKey []byte `protobuf:"bytes,2,opt,name=public_key,json=publicKey,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *Replacement) Reset() { *m = Replacement{} }
+
+// String implements proto.Message.
+func (m *Replacement) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*Replacement) ProtoMessage() {}
+
+// ChangeSet is a protobuf message of the change set of an authority.
+type ChangeSet struct {
+	Remove     []uint32       `protobuf:"varint,1,rep,packed,name=remove,proto3"`
+	Addresses  [][]byte       `protobuf:"bytes,2,rep,name=addresses,proto3"`
+	PublicKeys [][]byte       `protobuf:"bytes,3,rep,name=public_keys,json=publicKeys,proto3"`
+	Weights    []uint64       `protobuf:"varint,4,rep,packed,name=weights,proto3" json:",omitempty"`
+	VrfKeys    [][]byte       `protobuf:"bytes,5,rep,name=vrf_keys,json=vrfKeys,proto3" json:",omitempty"`
+	TxKeys     [][]byte       `protobuf:"bytes,6,rep,name=tx_keys,json=txKeys,proto3" json:",omitempty"`
+	Replace    []*Replacement `protobuf:"bytes,7,rep,name=replace,proto3" json:",omitempty"`
+}
+
+// Reset implements proto.Message.
+func (m *ChangeSet) Reset() { *m = ChangeSet{} }
+
+// String implements proto.Message.
+func (m *ChangeSet) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*ChangeSet) ProtoMessage() {}
+
+// ChangeSetFormat is the engine to encode and decode change set messages in
+// protobuf format.
+//
+// - implements serde.FormatEngine
+type changeSetFormat struct{}
+
+// Encode implements serde.FormatEngine. It returns the data serialized for the
+// change set message if appropriate, otherwise an error.
+func (f changeSetFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	cset, ok := msg.(*authority.RosterChangeSet)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message of type '%T'", msg)
+	}
+
+	remove := make([]uint32, 0)
+	for _, index := range cset.GetRemoveIndices() {
+		remove = append(remove, uint32(index))
+	}
+
+	addrs := make([][]byte, 0)
+	for _, addr := range cset.GetNewAddresses() {
+		raw, err := addr.MarshalText()
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't serialize address: %v", err)
+		}
+
+		addrs = append(addrs, raw)
+	}
+
+	pubkeys := make([][]byte, 0)
+	for _, pubkey := range cset.GetPublicKeys() {
+		raw, err := pubkey.Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't serialize public key: %v", err)
+		}
+
+		pubkeys = append(pubkeys, raw)
+	}
+
+	m := &ChangeSet{
+		Remove:     remove,
+		Addresses:  addrs,
+		PublicKeys: pubkeys,
+	}
+
+	for _, r := range cset.GetReplacements() {
+		raw, err := r.NewPublicKey.Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't serialize new public key: %v", err)
+		}
+
+		m.Replace = append(m.Replace, &Replacement{Index: uint32(r.Index), PublicKey: raw})
+	}
+
+	if cset.IsWeighted() {
+		m.Weights = cset.GetWeights()
+	}
+
+	if cset.HasVRFKeys() {
+		m.VrfKeys = encodeVRFKeys(cset.GetVRFKeys())
+	}
+
+	if cset.HasTxKeys() {
+		txkeys, err := encodeTxKeys(ctx, cset.GetTxKeys())
+		if err != nil {
+			return nil, err
+		}
+
+		m.TxKeys = txkeys
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the message with the
+// protobuf data if appropriate, otherwise it returns an error.
+func (f changeSetFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := &ChangeSet{}
+	err := ctx.Unmarshal(data, m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't deserialize change set: %v", err)
+	}
+
+	if len(m.Addresses) != len(m.PublicKeys) {
+		return nil, xerrors.Errorf("mismatch addresses and public keys: %d != %d",
+			len(m.Addresses), len(m.PublicKeys))
+	}
+
+	if len(m.Weights) > 0 && len(m.Weights) != len(m.Addresses) {
+		return nil, xerrors.Errorf("mismatch addresses and weights: %d != %d",
+			len(m.Addresses), len(m.Weights))
+	}
+
+	if len(m.VrfKeys) > 0 && len(m.VrfKeys) != len(m.Addresses) {
+		return nil, xerrors.Errorf("mismatch addresses and vrf keys: %d != %d",
+			len(m.Addresses), len(m.VrfKeys))
+	}
+
+	if len(m.TxKeys) > 0 && len(m.TxKeys) != len(m.Addresses) {
+		return nil, xerrors.Errorf("mismatch addresses and tx keys: %d != %d",
+			len(m.Addresses), len(m.TxKeys))
+	}
+
+	factory := ctx.GetFactory(authority.PubKeyFac{})
+
+	pkFac, ok := factory.(crypto.PublicKeyFactory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid public key factory of type '%T'", factory)
+	}
+
+	factory = ctx.GetFactory(authority.AddrKeyFac{})
+
+	addrFac, ok := factory.(mino.AddressFactory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid address factory of type '%T'", factory)
+	}
+
+	cset := authority.NewChangeSet()
+
+	for _, r := range m.Replace {
+		pubkey, err := pkFac.PublicKeyOf(ctx, r.PublicKey)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't deserialize new public key: %v", err)
+		}
+
+		cset.Replace(uint(r.Index), pubkey)
+	}
+
+	for _, index := range m.Remove {
+		cset.Remove(uint(index))
+	}
+
+	for i, rawAddr := range m.Addresses {
+		addr := addrFac.FromText(rawAddr)
+
+		pubkey, err := pkFac.PublicKeyOf(ctx, m.PublicKeys[i])
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't deserialize public key: %v", err)
+		}
+
+		weight := uint64(1)
+		if len(m.Weights) > 0 {
+			weight = m.Weights[i]
+		}
+
+		vrfkey := vrf.PublicKey{}
+		if len(m.VrfKeys) > 0 {
+			vrfkey, err = decodeVRFKey(m.VrfKeys[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var txkey crypto.PublicKey
+		if len(m.TxKeys) > 0 {
+			txkey, err = decodeTxKey(ctx, m.TxKeys[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		cset.AddWithKeys(addr, pubkey, weight, vrfkey, txkey)
+	}
+
+	return cset, nil
+}
+
+// RosterFormat is the engine to encode and decode roster messages in protobuf
+// format.
+//
+// - implements serde.FormatEngine
+type rosterFormat struct{}
+
+// Encode implements serde.FormatEngine. It returns the data serialized for the
+// roster message if appropriate, otherwise an error.
+func (f rosterFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	roster, ok := msg.(authority.Roster)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message of type '%T'", msg)
+	}
+
+	players := make([]*Player, roster.Len())
+
+	addrIter := roster.AddressIterator()
+	pkIter := roster.PublicKeyIterator()
+	for i := 0; addrIter.HasNext() && pkIter.HasNext(); i++ {
+		addr, err := addrIter.GetNext().MarshalText()
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't marshal address: %v", err)
+		}
+
+		pubkey, err := pkIter.GetNext().Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't serialize public key: %v", err)
+		}
+
+		players[i] = &Player{
+			Address:   addr,
+			PublicKey: pubkey,
+		}
+
+		vrfkey, found := roster.GetVRFKey(i)
+		if found {
+			players[i].VrfKey, err = vrfkey.MarshalBinary()
+			if err != nil {
+				return nil, xerrors.Errorf("couldn't marshal vrf key: %v", err)
+			}
+		}
+
+		txkey, found := roster.GetTxKey(i)
+		if found {
+			players[i].TxKey, err = txkey.Serialize(ctx)
+			if err != nil {
+				return nil, xerrors.Errorf("couldn't serialize tx key: %v", err)
+			}
+		}
+	}
+
+	m := &Roster{
+		Players: players,
+	}
+
+	if roster.IsWeighted() {
+		m.Weights = make([]uint64, roster.Len())
+		for i := range m.Weights {
+			m.Weights[i] = roster.GetWeight(i)
+		}
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the roster with the
+// protobuf data if appropriate, otherwise it returns an error.
+func (f rosterFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := &Roster{}
+	err := ctx.Unmarshal(data, m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't deserialize roster: %v", err)
+	}
+
+	if len(m.Weights) > 0 && len(m.Weights) != len(m.Players) {
+		return nil, xerrors.Errorf("mismatch players and weights: %d != %d",
+			len(m.Players), len(m.Weights))
+	}
+
+	factory := ctx.GetFactory(authority.PubKeyFac{})
+
+	pkFac, ok := factory.(crypto.PublicKeyFactory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid public key factory of type '%T'", factory)
+	}
+
+	factory = ctx.GetFactory(authority.AddrKeyFac{})
+
+	addrFac, ok := factory.(mino.AddressFactory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid address factory of type '%T'", factory)
+	}
+
+	addrs := make([]mino.Address, len(m.Players))
+	pubkeys := make([]crypto.PublicKey, len(m.Players))
+	vrfkeys := make([]vrf.PublicKey, len(m.Players))
+	txkeys := make([]crypto.PublicKey, len(m.Players))
+
+	for i, player := range m.Players {
+		if player == nil {
+			return nil, xerrors.Errorf("missing player at index %d", i)
+		}
+
+		addrs[i] = addrFac.FromText(player.Address)
+
+		pubkey, err := pkFac.PublicKeyOf(ctx, player.PublicKey)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't deserialize public key: %v", err)
+		}
+
+		pubkeys[i] = pubkey
+
+		vrfkeys[i], err = decodeVRFKey(player.VrfKey)
+		if err != nil {
+			return nil, err
+		}
+
+		txkeys[i], err = decodeTxKey(ctx, player.TxKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	roster := authority.NewWeighted(addrs, pubkeys, m.Weights).
+		WithVRFKeys(vrfkeys).
+		WithTxKeys(txkeys)
+
+	return roster, nil
+}
+
+// encodeVRFKeys returns the data of the keys, where an empty key has no data.
+func encodeVRFKeys(keys []vrf.PublicKey) [][]byte {
+	data := make([][]byte, len(keys))
+
+	for i, key := range keys {
+		// The only error is for an empty key which is then left without data.
+		data[i], _ = key.MarshalBinary()
+	}
+
+	return data
+}
+
+// decodeVRFKey returns the key of the data, or an empty key if there is no
+// data.
+func decodeVRFKey(data []byte) (vrf.PublicKey, error) {
+	if len(data) == 0 {
+		return vrf.PublicKey{}, nil
+	}
+
+	key, err := vrf.NewPublicKey(data)
+	if err != nil {
+		return key, xerrors.Errorf("invalid vrf key: %v", err)
+	}
+
+	return key, nil
+}
+
+// encodeTxKeys returns the serialized data of the transaction identities, where
+// a missing identity has no data.
+func encodeTxKeys(ctx serde.Context, keys []crypto.PublicKey) ([][]byte, error) {
+	data := make([][]byte, len(keys))
+
+	for i, key := range keys {
+		if key == nil {
+			continue
+		}
+
+		raw, err := key.Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't serialize tx key: %v", err)
+		}
+
+		data[i] = raw
+	}
+
+	return data, nil
+}
+
+// decodeTxKey returns the transaction identity of the data, or nil if there is
+// no data.
+func decodeTxKey(ctx serde.Context, data []byte) (crypto.PublicKey, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	factory := ctx.GetFactory(authority.TxKeyFac{})
+
+	txFac, ok := factory.(crypto.PublicKeyFactory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid tx key factory of type '%T'", factory)
+	}
+
+	key, err := txFac.PublicKeyOf(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't deserialize tx key: %v", err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,347 @@
+package proto
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	_ "go.dedis.ch/dela/crypto/bls/proto"
+	"go.dedis.ch/dela/crypto/vrf"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/internal/testing/gen"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestChangeSetFormat_Encode(t *testing.T) {
+	cset := authority.NewChangeSet()
+	cset.Remove(42)
+	cset.Add(fake.NewAddress(2), fake.PublicKey{})
+
+	format := changeSetFormat{}
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	data, err := format.Encode(ctx, cset)
+	require.NoError(t, err)
+	expected := `{"Remove":[42],"Addresses":["AgAAAA=="],"PublicKeys":["e30="]}`
+	require.Equal(t, expected, string(data))
+
+	cset.AddWeighted(fake.NewAddress(3), fake.PublicKey{}, 3)
+
+	data, err = format.Encode(ctx, cset)
+	require.NoError(t, err)
+	expected = `{"Remove":[42],"Addresses":["AgAAAA==","AwAAAA=="],` +
+		`"PublicKeys":["e30=","e30="],"Weights":[1,3]}`
+	require.Equal(t, expected, string(data))
+
+	_, err = format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message of type 'fake.Message'")
+
+	_, err = format.Encode(fake.NewBadContext(), cset)
+	require.EqualError(t, err, fake.Err("couldn't marshal"))
+
+	cset = authority.NewChangeSet()
+	cset.Add(fake.NewAddress(0), fake.NewBadPublicKey())
+	_, err = format.Encode(ctx, cset)
+	require.EqualError(t, err, fake.Err("couldn't serialize public key"))
+
+	cset = authority.NewChangeSet()
+	cset.Add(fake.NewBadAddress(), fake.PublicKey{})
+	_, err = format.Encode(ctx, cset)
+	require.EqualError(t, err, fake.Err("couldn't serialize address"))
+}
+
+func TestChangeSetFormat_Decode(t *testing.T) {
+	format := changeSetFormat{}
+	ctx := serde.NewContext(fake.ContextEngine{})
+	ctx = serde.WithFactory(ctx, authority.AddrKeyFac{}, fake.AddressFactory{})
+	ctx = serde.WithFactory(ctx, authority.PubKeyFac{}, fake.PublicKeyFactory{})
+
+	cset := authority.NewChangeSet()
+	cset.Add(fake.NewAddress(0), fake.PublicKey{})
+
+	msg, err := format.Decode(ctx, []byte(`{"Addresses":[""],"PublicKeys":["e30="]}`))
+	require.NoError(t, err)
+	require.Equal(t, cset, msg)
+
+	cset = authority.NewChangeSet()
+	cset.Remove(1)
+	cset.Remove(2)
+	cset.Remove(3)
+
+	msg, err = format.Decode(ctx, []byte(`{"Remove":[1,2,3]}`))
+	require.NoError(t, err)
+	require.Equal(t, cset, msg)
+
+	_, err = format.Decode(ctx, []byte(`{"Addresses":[""]}`))
+	require.EqualError(t, err, "mismatch addresses and public keys: 1 != 0")
+
+	cset = authority.NewChangeSet()
+	cset.AddWeighted(fake.NewAddress(0), fake.PublicKey{}, 3)
+
+	msg, err = format.Decode(ctx, []byte(`{"Addresses":[""],"PublicKeys":["e30="],"Weights":[3]}`))
+	require.NoError(t, err)
+	require.Equal(t, cset, msg)
+
+	_, err = format.Decode(ctx, []byte(`{"Addresses":[""],"PublicKeys":["e30="],"Weights":[1,2]}`))
+	require.EqualError(t, err, "mismatch addresses and weights: 1 != 2")
+
+	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("couldn't deserialize change set"))
+
+	badCtx := serde.WithFactory(ctx, authority.PubKeyFac{}, fake.NewBadPublicKeyFactory())
+	_, err = format.Decode(badCtx, []byte(`{"Addresses":[""],"PublicKeys":["e30="]}`))
+	require.EqualError(t, err, fake.Err("couldn't deserialize public key"))
+
+	badCtx = serde.WithFactory(ctx, authority.AddrKeyFac{}, nil)
+	_, err = format.Decode(badCtx, []byte(`{"Addresses":[""],"PublicKeys":["e30="]}`))
+	require.EqualError(t, err, "invalid address factory of type '<nil>'")
+
+	badCtx = serde.WithFactory(ctx, authority.PubKeyFac{}, nil)
+	_, err = format.Decode(badCtx, []byte(`{"Addresses":[""],"PublicKeys":["e30="]}`))
+	require.EqualError(t, err, "invalid public key factory of type '<nil>'")
+}
+
+func TestRosterFormat_Encode(t *testing.T) {
+	ro := authority.FromAuthority(fake.NewAuthority(1, fake.NewSigner))
+
+	format := rosterFormat{}
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	data, err := format.Encode(ctx, ro)
+	require.NoError(t, err)
+	require.Equal(t, `{"Players":[{"Address":"AAAAAA==","PublicKey":"e30="}]}`, string(data))
+
+	weighted := authority.NewWeighted([]mino.Address{fake.NewAddress(0)},
+		[]crypto.PublicKey{fake.PublicKey{}}, []uint64{2})
+
+	data, err = format.Encode(ctx, weighted)
+	require.NoError(t, err)
+	require.Equal(t, `{"Players":[{"Address":"AAAAAA==","PublicKey":"e30="}],"Weights":[2]}`,
+		string(data))
+
+	_, err = format.Encode(fake.NewContext(), fake.Message{})
+	require.EqualError(t, err, "unsupported message of type 'fake.Message'")
+
+	_, err = format.Encode(fake.NewBadContext(), ro)
+	require.EqualError(t, err, fake.Err("couldn't marshal"))
+
+	ro = authority.New([]mino.Address{fake.NewBadAddress()}, nil)
+	_, err = format.Encode(ctx, ro)
+	require.EqualError(t, err, fake.Err("couldn't marshal address"))
+
+	ro = authority.New([]mino.Address{fake.NewAddress(0)}, []crypto.PublicKey{fake.NewBadPublicKey()})
+	_, err = format.Encode(ctx, ro)
+	require.EqualError(t, err, fake.Err("couldn't serialize public key"))
+}
+
+func TestRosterFormat_Decode(t *testing.T) {
+	format := rosterFormat{}
+	ctx := serde.NewContext(fake.ContextEngine{})
+	ctx = serde.WithFactory(ctx, authority.AddrKeyFac{}, fake.AddressFactory{})
+	ctx = serde.WithFactory(ctx, authority.PubKeyFac{}, fake.PublicKeyFactory{})
+
+	ro, err := format.Decode(ctx, []byte(`{"Players":[{}]}`))
+	require.NoError(t, err)
+	require.Equal(t, authority.FromAuthority(fake.NewAuthority(1, fake.NewSigner)), ro)
+
+	ro, err = format.Decode(ctx, []byte(`{"Players":[{},{}],"Weights":[2,0]}`))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), ro.(authority.Roster).GetWeight(0))
+	require.Equal(t, uint64(0), ro.(authority.Roster).GetWeight(1))
+
+	_, err = format.Decode(ctx, []byte(`{"Players":[{}],"Weights":[2,0]}`))
+	require.EqualError(t, err, "mismatch players and weights: 1 != 2")
+
+	_, err = format.Decode(ctx, []byte(`{"Players":[null]}`))
+	require.EqualError(t, err, "missing player at index 0")
+
+	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("couldn't deserialize roster"))
+
+	badCtx := serde.WithFactory(ctx, authority.PubKeyFac{}, fake.NewBadPublicKeyFactory())
+	_, err = format.Decode(badCtx, []byte(`{"Players":[{}]}`))
+	require.EqualError(t, err, fake.Err("couldn't deserialize public key"))
+
+	badCtx = serde.WithFactory(ctx, authority.AddrKeyFac{}, nil)
+	_, err = format.Decode(badCtx, []byte(`{"Players":[{}]}`))
+	require.EqualError(t, err, "invalid address factory of type '<nil>'")
+
+	badCtx = serde.WithFactory(ctx, authority.PubKeyFac{}, nil)
+	_, err = format.Decode(badCtx, []byte(`{"Players":[{}]}`))
+	require.EqualError(t, err, "invalid public key factory of type '<nil>'")
+}
+
+func TestFormats_VRFKeys_RoundTrip(t *testing.T) {
+	ctx := serde.NewContext(fake.ContextEngine{})
+	ctx = serde.WithFactory(ctx, authority.AddrKeyFac{}, fake.AddressFactory{})
+	ctx = serde.WithFactory(ctx, authority.PubKeyFac{}, fake.PublicKeyFactory{})
+
+	key := vrf.NewSigner().GetPublicKey()
+
+	roster := authority.FromAuthority(fake.NewAuthority(2, fake.NewSigner))
+	roster = roster.WithVRFKeys([]vrf.PublicKey{{}, key})
+
+	data, err := rosterFormat{}.Encode(ctx, roster)
+	require.NoError(t, err)
+
+	msg, err := rosterFormat{}.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, roster, msg)
+
+	cset := authority.NewChangeSet()
+	cset.Add(fake.NewAddress(0), fake.PublicKey{})
+	cset.AddWithVRFKey(fake.NewAddress(1), fake.PublicKey{}, 1, key)
+
+	data, err = changeSetFormat{}.Encode(ctx, cset)
+	require.NoError(t, err)
+
+	msg, err = changeSetFormat{}.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, cset.GetVRFKeys(), msg.(*authority.RosterChangeSet).GetVRFKeys())
+
+	_, err = rosterFormat{}.Decode(ctx, []byte(`{"Players":[{"VrfKey":"AA=="}]}`))
+	require.EqualError(t, err, "invalid vrf key: invalid public key size 1 != 32")
+
+	_, err = changeSetFormat{}.Decode(ctx, []byte(`{"Addresses":[""],"PublicKeys":["e30="],"VrfKeys":["AA==","AA=="]}`))
+	require.EqualError(t, err, "mismatch addresses and vrf keys: 1 != 2")
+}
+
+func TestFormats_TxKeys_RoundTrip(t *testing.T) {
+	ctx := serde.NewContext(fake.ContextEngine{})
+	ctx = serde.WithFactory(ctx, authority.AddrKeyFac{}, fake.AddressFactory{})
+	ctx = serde.WithFactory(ctx, authority.PubKeyFac{}, fake.PublicKeyFactory{})
+	ctx = serde.WithFactory(ctx, authority.TxKeyFac{}, fake.PublicKeyFactory{})
+
+	roster := authority.FromAuthority(fake.NewAuthority(2, fake.NewSigner))
+	roster = roster.WithTxKeys([]crypto.PublicKey{nil, fake.PublicKey{}})
+
+	data, err := rosterFormat{}.Encode(ctx, roster)
+	require.NoError(t, err)
+
+	msg, err := rosterFormat{}.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, roster, msg)
+
+	cset := authority.NewChangeSet()
+	cset.Add(fake.NewAddress(0), fake.PublicKey{})
+	cset.AddWithKeys(fake.NewAddress(1), fake.PublicKey{}, 1, vrf.PublicKey{}, fake.PublicKey{})
+
+	data, err = changeSetFormat{}.Encode(ctx, cset)
+	require.NoError(t, err)
+
+	msg, err = changeSetFormat{}.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, cset.GetTxKeys(), msg.(*authority.RosterChangeSet).GetTxKeys())
+
+	badCtx := serde.WithFactory(ctx, authority.TxKeyFac{}, fake.NewBadPublicKeyFactory())
+	_, err = rosterFormat{}.Decode(badCtx, []byte(`{"Players":[{"TxKey":"AA=="}]}`))
+	require.EqualError(t, err, fake.Err("couldn't deserialize tx key"))
+
+	badCtx = serde.WithFactory(ctx, authority.TxKeyFac{}, nil)
+	_, err = rosterFormat{}.Decode(badCtx, []byte(`{"Players":[{"TxKey":"AA=="}]}`))
+	require.EqualError(t, err, "invalid tx key factory of type '<nil>'")
+
+	_, err = changeSetFormat{}.Decode(ctx, []byte(`{"Addresses":[""],"PublicKeys":["e30="],"TxKeys":["AA==","AA=="]}`))
+	require.EqualError(t, err, "mismatch addresses and tx keys: 1 != 2")
+
+	roster = roster.WithTxKeys([]crypto.PublicKey{fake.NewBadPublicKey(), nil})
+	_, err = rosterFormat{}.Encode(ctx, roster)
+	require.EqualError(t, err, fake.Err("couldn't serialize tx key"))
+
+	cset = authority.NewChangeSet()
+	cset.AddWithKeys(fake.NewAddress(0), fake.PublicKey{}, 1, vrf.PublicKey{}, fake.NewBadPublicKey())
+	_, err = changeSetFormat{}.Encode(ctx, cset)
+	require.EqualError(t, err, fake.Err("couldn't serialize tx key"))
+}
+
+func TestFormats_Replace_RoundTrip(t *testing.T) {
+	ctx := serde.NewContext(fake.ContextEngine{})
+	ctx = serde.WithFactory(ctx, authority.AddrKeyFac{}, fake.AddressFactory{})
+	ctx = serde.WithFactory(ctx, authority.PubKeyFac{}, fake.PublicKeyFactory{})
+
+	cset := authority.NewChangeSet()
+	cset.Replace(1, fake.PublicKey{})
+	cset.Remove(2)
+
+	data, err := changeSetFormat{}.Encode(ctx, cset)
+	require.NoError(t, err)
+
+	msg, err := changeSetFormat{}.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, cset, msg)
+
+	badCtx := serde.WithFactory(ctx, authority.PubKeyFac{}, fake.NewBadPublicKeyFactory())
+	_, err = changeSetFormat{}.Decode(badCtx, []byte(`{"Replace":[{"Index":1,"PublicKey":"e30="}]}`))
+	require.EqualError(t, err, fake.Err("couldn't deserialize new public key"))
+
+	cset = authority.NewChangeSet()
+	cset.Replace(0, fake.NewBadPublicKey())
+	_, err = changeSetFormat{}.Encode(ctx, cset)
+	require.EqualError(t, err, fake.Err("couldn't serialize new public key"))
+}
+
+func TestChangeSetFormat_Quick_RoundTrip(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatProtobuf)
+	fac := authority.NewChangeSetFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+
+	f := func(cset gen.ChangeSet) bool {
+		data, err := cset.Serialize(ctx)
+		require.NoError(t, err)
+
+		decoded, err := fac.ChangeSetOf(ctx, data)
+		require.NoError(t, err)
+		require.Equal(t, cset.NumChanges(), decoded.NumChanges())
+
+		again, err := decoded.Serialize(ctx)
+		require.NoError(t, err)
+		require.Equal(t, string(data), string(again))
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 20})
+	require.NoError(t, err)
+}
+
+func TestRosterFormat_Quick_RoundTrip(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatProtobuf)
+	fac := authority.NewFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+
+	f := func(roster gen.Roster) bool {
+		data, err := roster.Serialize(ctx)
+		require.NoError(t, err)
+
+		decoded, err := fac.AuthorityOf(ctx, data)
+		require.NoError(t, err)
+		require.Equal(t, roster.Len(), decoded.Len())
+
+		again, err := decoded.Serialize(ctx)
+		require.NoError(t, err)
+		require.Equal(t, string(data), string(again))
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 20})
+	require.NoError(t, err)
+}
+
+func TestFormats_Quick_Malformed(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatProtobuf)
+	rosterFac := authority.NewFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+	csetFac := authority.NewChangeSetFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+
+	f := func(input gen.Malformed) bool {
+		require.NotPanics(t, func() { rosterFac.AuthorityOf(ctx, input.Data) })
+		require.NotPanics(t, func() { csetFac.ChangeSetOf(ctx, input.Data) })
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 200})
+	require.NoError(t, err)
+}
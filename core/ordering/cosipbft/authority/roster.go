@@ -6,10 +6,14 @@
 package authority
 
 import (
+	"encoding/binary"
 	"io"
 
 	"go.dedis.ch/dela"
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/common"
+	"go.dedis.ch/dela/crypto/vrf"
+	"go.dedis.ch/dela/internal/collection"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/dela/serde/registry"
@@ -74,11 +78,24 @@ func (i *publicKeyIterator) GetNext() crypto.PublicKey {
 }
 
 // Roster contains a list of participants with their addresses and public keys.
+// The participants can optionally have a weight that defines their voting
+// rights, otherwise each of them has one vote. They can also have the public
+// key of a verifiable random function that proves the election of the leader
+// of a round, and the public key of the identity that signs their transactions
+// when it is not the key of the consensus.
 //
 // - implements authority.Authority
 type Roster struct {
 	addrs   []mino.Address
 	pubkeys []crypto.PublicKey
+	// weights is nil when every participant has a weight of one.
+	weights []uint64
+	// vrfkeys is nil when no participant has a key of the verifiable random
+	// function, otherwise a participant without a key has an empty one.
+	vrfkeys []vrf.PublicKey
+	// txkeys is nil when no participant has a transaction identity, otherwise
+	// a participant without one has a nil key.
+	txkeys []crypto.PublicKey
 }
 
 // New creates a new roster from the list of addresses and public keys.
@@ -89,8 +106,43 @@ func New(addrs []mino.Address, pubkeys []crypto.PublicKey) Roster {
 	}
 }
 
-// FromAuthority returns a viewchange roster from a collective authority.
+// NewWeighted creates a new roster from the list of addresses and public keys
+// where each participant has the voting rights of the weight at the same index.
+func NewWeighted(addrs []mino.Address, pubkeys []crypto.PublicKey, weights []uint64) Roster {
+	return Roster{
+		addrs:   addrs,
+		pubkeys: pubkeys,
+		weights: compactWeights(weights),
+	}
+}
+
+// WithVRFKeys returns a copy of the roster where each participant has the key
+// of the verifiable random function at the same index. An empty key means the
+// participant does not have one.
+func (r Roster) WithVRFKeys(keys []vrf.PublicKey) Roster {
+	r.vrfkeys = compactVRFKeys(keys)
+
+	return r
+}
+
+// WithTxKeys returns a copy of the roster where each participant has the
+// transaction identity at the same index. A nil key means the participant does
+// not have one.
+func (r Roster) WithTxKeys(keys []crypto.PublicKey) Roster {
+	r.txkeys = compactTxKeys(keys)
+
+	return r
+}
+
+// FromAuthority returns a viewchange roster from a collective authority. A
+// roster is returned as is so that the weights and the additional keys of the
+// participants are kept.
 func FromAuthority(authority crypto.CollectiveAuthority) Roster {
+	roster, ok := authority.(Roster)
+	if ok {
+		return roster
+	}
+
 	addrs := make([]mino.Address, authority.Len())
 	pubkeys := make([]crypto.PublicKey, authority.Len())
 
@@ -105,8 +157,15 @@ func FromAuthority(authority crypto.CollectiveAuthority) Roster {
 }
 
 // Fingerprint implements serde.Fingerprinter. It marshals the roster and writes
-// the result in the given writer.
+// the result in the given writer. The weights are only written when at least
+// one participant has a weight different from one, and the keys of the
+// verifiable random function and the transaction identities when at least one
+// participant has one, so that the fingerprint of a roster without them stays
+// the same.
 func (r Roster) Fingerprint(w io.Writer) error {
+	buffer := make([]byte, 8)
+	empty := make([]byte, vrf.PublicKeySize)
+
 	for i, addr := range r.addrs {
 		data, err := addr.MarshalText()
 		if err != nil {
@@ -127,6 +186,65 @@ func (r Roster) Fingerprint(w io.Writer) error {
 		if err != nil {
 			return xerrors.Errorf("couldn't write public key: %v", err)
 		}
+
+		if r.weights != nil {
+			binary.LittleEndian.PutUint64(buffer, r.GetWeight(i))
+
+			_, err = w.Write(buffer)
+			if err != nil {
+				return xerrors.Errorf("couldn't write weight: %v", err)
+			}
+		}
+
+		if r.vrfkeys != nil {
+			data = empty
+
+			key, found := r.GetVRFKey(i)
+			if found {
+				data, err = key.MarshalBinary()
+				if err != nil {
+					return xerrors.Errorf("couldn't marshal vrf key: %v", err)
+				}
+			}
+
+			_, err = w.Write(data)
+			if err != nil {
+				return xerrors.Errorf("couldn't write vrf key: %v", err)
+			}
+		}
+
+		if r.txkeys != nil {
+			err = r.fingerprintTxKey(w, i)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// fingerprintTxKey writes the length of the transaction identity of the
+// participant followed by its data, or a length of zero when it has none.
+func (r Roster) fingerprintTxKey(w io.Writer, index int) error {
+	var data []byte
+
+	key, found := r.GetTxKey(index)
+	if found {
+		var err error
+		data, err = key.MarshalBinary()
+		if err != nil {
+			return xerrors.Errorf("couldn't marshal tx key: %v", err)
+		}
+	}
+
+	buffer := make([]byte, 4+len(data))
+	binary.LittleEndian.PutUint32(buffer, uint32(len(data)))
+	copy(buffer[4:], data)
+
+	_, err := w.Write(buffer)
+	if err != nil {
+		return xerrors.Errorf("couldn't write tx key: %v", err)
 	}
 
 	return nil
@@ -141,17 +259,29 @@ func (r Roster) Take(updaters ...mino.FilterUpdater) mino.Players {
 		pubkeys: make([]crypto.PublicKey, len(filter.Indices)),
 	}
 
+	weights := make([]uint64, len(filter.Indices))
+	vrfkeys := make([]vrf.PublicKey, len(filter.Indices))
+	txkeys := make([]crypto.PublicKey, len(filter.Indices))
+
 	for i, k := range filter.Indices {
 		newRoster.addrs[i] = r.addrs[k]
 		newRoster.pubkeys[i] = r.pubkeys[k]
+		weights[i] = r.GetWeight(k)
+		vrfkeys[i], _ = r.GetVRFKey(k)
+		txkeys[i], _ = r.GetTxKey(k)
 	}
 
+	newRoster.weights = compactWeights(weights)
+	newRoster.vrfkeys = compactVRFKeys(vrfkeys)
+	newRoster.txkeys = compactTxKeys(txkeys)
+
 	return newRoster
 }
 
 // Apply implements authority.Authority. It returns a new authority after
-// applying the change set. The removals must be sorted by descending order and
-// unique or the behaviour will be undefined.
+// applying the change set. The public keys are replaced before the removals,
+// which are applied from the highest index so that the result doesn't depend
+// on their order in the change set.
 func (r Roster) Apply(in ChangeSet) Authority {
 	changeset, ok := in.(*RosterChangeSet)
 	if !ok {
@@ -161,29 +291,50 @@ func (r Roster) Apply(in ChangeSet) Authority {
 
 	addrs := make([]mino.Address, r.Len())
 	pubkeys := make([]crypto.PublicKey, r.Len())
+	weights := make([]uint64, r.Len())
+	vrfkeys := make([]vrf.PublicKey, r.Len())
+	txkeys := make([]crypto.PublicKey, r.Len())
 
 	for i, addr := range r.addrs {
 		addrs[i] = addr
 		pubkeys[i] = r.pubkeys[i]
+		weights[i] = r.GetWeight(i)
+		vrfkeys[i], _ = r.GetVRFKey(i)
+		txkeys[i], _ = r.GetTxKey(i)
+	}
+
+	for _, r := range changeset.replace {
+		if int(r.Index) < len(pubkeys) {
+			pubkeys[r.Index] = r.NewPublicKey
+		}
 	}
 
-	for _, i := range changeset.remove {
+	for _, i := range collection.NewIndexSet(changeset.remove...).Descending() {
 		if int(i) < len(addrs) {
 			addrs = append(addrs[:i], addrs[i+1:]...)
 			pubkeys = append(pubkeys[:i], pubkeys[i+1:]...)
+			weights = append(weights[:i], weights[i+1:]...)
+			vrfkeys = append(vrfkeys[:i], vrfkeys[i+1:]...)
+			txkeys = append(txkeys[:i], txkeys[i+1:]...)
 		}
 	}
 
 	roster := Roster{
 		addrs:   append(addrs, changeset.addrs...),
 		pubkeys: append(pubkeys, changeset.pubkeys...),
+		weights: compactWeights(append(weights, changeset.GetWeights()...)),
+		vrfkeys: compactVRFKeys(append(vrfkeys, changeset.GetVRFKeys()...)),
+		txkeys:  compactTxKeys(append(txkeys, changeset.GetTxKeys()...)),
 	}
 
 	return roster
 }
 
 // Diff implements authority.Authority. It returns the change set that must be
-// applied to the current authority to get the given one.
+// applied to the current authority to get the given one. A participant whose
+// weight, key of the verifiable random function or transaction identity
+// differs is removed and added back with the new values, while a participant
+// whose public key only differs has it replaced.
 func (r Roster) Diff(o Authority) ChangeSet {
 	changeset := NewChangeSet()
 
@@ -196,7 +347,13 @@ func (r Roster) Diff(o Authority) ChangeSet {
 	k := 0
 	for i < len(r.addrs) || k < len(other.addrs) {
 		if i < len(r.addrs) && k < len(other.addrs) {
-			if r.addrs[i].Equal(other.addrs[k]) {
+			if r.addrs[i].Equal(other.addrs[k]) && r.GetWeight(i) == other.GetWeight(k) &&
+				r.sameVRFKey(i, other, k) && r.sameTxKey(i, other, k) {
+
+				if !r.pubkeys[i].Equal(other.pubkeys[k]) {
+					changeset.Replace(uint(i), other.pubkeys[k])
+				}
+
 				i++
 				k++
 			} else {
@@ -207,8 +364,9 @@ func (r Roster) Diff(o Authority) ChangeSet {
 			changeset.remove = append(changeset.remove, uint(i))
 			i++
 		} else {
-			changeset.addrs = append(changeset.addrs, other.addrs[k])
-			changeset.pubkeys = append(changeset.pubkeys, other.pubkeys[k])
+			vrfkey, _ := other.GetVRFKey(k)
+			txkey, _ := other.GetTxKey(k)
+			changeset.AddWithKeys(other.addrs[k], other.pubkeys[k], other.GetWeight(k), vrfkey, txkey)
 			k++
 		}
 	}
@@ -221,6 +379,94 @@ func (r Roster) Len() int {
 	return len(r.addrs)
 }
 
+// GetWeight implements crypto.WeightedAuthority. It returns the voting rights
+// of the participant at the given index, or zero if the index is out of range.
+func (r Roster) GetWeight(index int) uint64 {
+	if index < 0 || index >= len(r.addrs) {
+		return 0
+	}
+
+	if index >= len(r.weights) {
+		// Participants without an explicit weight have one vote.
+		return 1
+	}
+
+	return r.weights[index]
+}
+
+// IsWeighted returns true if at least one participant has a weight different
+// from one.
+func (r Roster) IsWeighted() bool {
+	return r.weights != nil
+}
+
+// GetVRFKey implements authority.Authority. It returns the key of the
+// verifiable random function of the participant at the given index and true if
+// it has one, otherwise it returns false.
+func (r Roster) GetVRFKey(index int) (vrf.PublicKey, bool) {
+	if index < 0 || index >= len(r.vrfkeys) || !hasVRFKey(r.vrfkeys[index]) {
+		return vrf.PublicKey{}, false
+	}
+
+	return r.vrfkeys[index], true
+}
+
+// HasVRFKeys returns true if at least one participant has a key of the
+// verifiable random function.
+func (r Roster) HasVRFKeys() bool {
+	return r.vrfkeys != nil
+}
+
+func (r Roster) sameVRFKey(index int, other Roster, k int) bool {
+	key, found := r.GetVRFKey(index)
+	otherKey, otherFound := other.GetVRFKey(k)
+
+	if found != otherFound {
+		return false
+	}
+
+	return !found || key.Equal(otherKey)
+}
+
+// GetTxKey implements authority.Authority. It returns the transaction identity
+// of the participant at the given index and true if it has one, otherwise it
+// returns false.
+func (r Roster) GetTxKey(index int) (crypto.PublicKey, bool) {
+	if index < 0 || index >= len(r.txkeys) || r.txkeys[index] == nil {
+		return nil, false
+	}
+
+	return r.txkeys[index], true
+}
+
+// HasTxKeys returns true if at least one participant has a transaction
+// identity.
+func (r Roster) HasTxKeys() bool {
+	return r.txkeys != nil
+}
+
+func (r Roster) sameTxKey(index int, other Roster, k int) bool {
+	key, found := r.GetTxKey(index)
+	otherKey, otherFound := other.GetTxKey(k)
+
+	if found != otherFound {
+		return false
+	}
+
+	return !found || key.Equal(otherKey)
+}
+
+// TotalWeight implements crypto.WeightedAuthority. It returns the sum of the
+// voting rights of the participants.
+func (r Roster) TotalWeight() uint64 {
+	total := uint64(0)
+	for i := range r.addrs {
+		total += r.GetWeight(i)
+	}
+
+	return total
+}
+
 // GetPublicKey implements crypto.CollectiveAuthority. It returns the public key
 // of the address if it exists, nil otherwise. The second return is the index of
 // the public key in the authority.
@@ -265,13 +511,16 @@ func (r Roster) Serialize(ctx serde.Context) ([]byte, error) {
 type rosterFac struct {
 	addrFactory   mino.AddressFactory
 	pubkeyFactory crypto.PublicKeyFactory
+	txkeyFactory  crypto.PublicKeyFactory
 }
 
-// NewFactory creates a new instance of the authority factory.
+// NewFactory creates a new instance of the authority factory. The transaction
+// identities are deserialized with the common public key factory.
 func NewFactory(af mino.AddressFactory, pf crypto.PublicKeyFactory) Factory {
 	return rosterFac{
 		addrFactory:   af,
 		pubkeyFactory: pf,
+		txkeyFactory:  common.NewPublicKeyFactory(),
 	}
 }
 
@@ -288,6 +537,7 @@ func (f rosterFac) AuthorityOf(ctx serde.Context, data []byte) (Authority, error
 
 	ctx = serde.WithFactory(ctx, PubKeyFac{}, f.pubkeyFactory)
 	ctx = serde.WithFactory(ctx, AddrKeyFac{}, f.addrFactory)
+	ctx = serde.WithFactory(ctx, TxKeyFac{}, f.txkeyFactory)
 
 	msg, err := format.Decode(ctx, data)
 	if err != nil {
@@ -301,3 +551,49 @@ func (f rosterFac) AuthorityOf(ctx serde.Context, data []byte) (Authority, error
 
 	return roster, nil
 }
+
+// compactWeights returns nil if every weight is equal to one so that a roster
+// without weights always has the same representation, otherwise it returns the
+// weights.
+func compactWeights(weights []uint64) []uint64 {
+	for _, weight := range weights {
+		if weight != 1 {
+			return weights
+		}
+	}
+
+	return nil
+}
+
+// compactVRFKeys returns nil if no participant has a key so that a roster
+// without keys always has the same representation, otherwise it returns the
+// keys.
+func compactVRFKeys(keys []vrf.PublicKey) []vrf.PublicKey {
+	for _, key := range keys {
+		if hasVRFKey(key) {
+			return keys
+		}
+	}
+
+	return nil
+}
+
+// hasVRFKey returns true if the key is not empty.
+func hasVRFKey(key vrf.PublicKey) bool {
+	_, err := key.MarshalBinary()
+
+	return err == nil
+}
+
+// compactTxKeys returns nil if no participant has a transaction identity so
+// that a roster without them always has the same representation, otherwise it
+// returns the keys.
+func compactTxKeys(keys []crypto.PublicKey) []crypto.PublicKey {
+	for _, key := range keys {
+		if key != nil {
+			return keys
+		}
+	}
+
+	return nil
+}
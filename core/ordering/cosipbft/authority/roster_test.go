@@ -5,7 +5,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
@@ -117,6 +119,107 @@ func TestRoster_Fingerprint(t *testing.T) {
 
 	err = roster.Fingerprint(fake.NewBadHashWithDelay(1))
 	require.EqualError(t, err, fake.Err("couldn't write public key"))
+
+	roster.weights = []uint64{2, 1}
+	out.Reset()
+	err = roster.Fingerprint(out)
+	require.NoError(t, err)
+	require.Equal(t, "\x00\x00\x00\x00PK\x02\x00\x00\x00\x00\x00\x00\x00"+
+		"\x01\x00\x00\x00PK\x01\x00\x00\x00\x00\x00\x00\x00", out.String())
+
+	err = roster.Fingerprint(fake.NewBadHashWithDelay(2))
+	require.EqualError(t, err, fake.Err("couldn't write weight"))
+
+	roster.weights = nil
+	roster = roster.WithVRFKeys([]vrf.PublicKey{{}, vrf.NewSigner().GetPublicKey()})
+	out.Reset()
+	err = roster.Fingerprint(out)
+	require.NoError(t, err)
+	require.Equal(t, 2*(len("\x00\x00\x00\x00PK")+vrf.PublicKeySize), out.Len())
+	require.Contains(t, out.String(), string(make([]byte, vrf.PublicKeySize)))
+
+	err = roster.Fingerprint(fake.NewBadHashWithDelay(2))
+	require.EqualError(t, err, fake.Err("couldn't write vrf key"))
+
+	roster = roster.WithVRFKeys(nil).WithTxKeys([]crypto.PublicKey{nil, fake.PublicKey{}})
+	out.Reset()
+	err = roster.Fingerprint(out)
+	require.NoError(t, err)
+	require.Equal(t, "\x00\x00\x00\x00PK\x00\x00\x00\x00"+
+		"\x01\x00\x00\x00PK\x02\x00\x00\x00PK", out.String())
+
+	err = roster.Fingerprint(fake.NewBadHashWithDelay(2))
+	require.EqualError(t, err, fake.Err("couldn't write tx key"))
+
+	roster = roster.WithTxKeys([]crypto.PublicKey{fake.NewBadPublicKey(), nil})
+	err = roster.Fingerprint(out)
+	require.EqualError(t, err, fake.Err("couldn't marshal tx key"))
+}
+
+func TestRoster_WithVRFKeys(t *testing.T) {
+	roster := FromAuthority(fake.NewAuthority(2, fake.NewSigner))
+	require.False(t, roster.HasVRFKeys())
+
+	_, found := roster.GetVRFKey(0)
+	require.False(t, found)
+
+	key := vrf.NewSigner().GetPublicKey()
+
+	roster = roster.WithVRFKeys([]vrf.PublicKey{{}, key})
+	require.True(t, roster.HasVRFKeys())
+
+	_, found = roster.GetVRFKey(0)
+	require.False(t, found)
+
+	other, found := roster.GetVRFKey(1)
+	require.True(t, found)
+	require.True(t, key.Equal(other))
+
+	_, found = roster.GetVRFKey(2)
+	require.False(t, found)
+
+	roster = roster.WithVRFKeys([]vrf.PublicKey{{}, {}})
+	require.False(t, roster.HasVRFKeys())
+}
+
+func TestRoster_WithTxKeys(t *testing.T) {
+	roster := FromAuthority(fake.NewAuthority(2, fake.NewSigner))
+	require.False(t, roster.HasTxKeys())
+
+	_, found := roster.GetTxKey(0)
+	require.False(t, found)
+
+	key := fake.NewSigner().GetPublicKey()
+
+	roster = roster.WithTxKeys([]crypto.PublicKey{nil, key})
+	require.True(t, roster.HasTxKeys())
+
+	_, found = roster.GetTxKey(0)
+	require.False(t, found)
+
+	other, found := roster.GetTxKey(1)
+	require.True(t, found)
+	require.True(t, key.Equal(other))
+
+	_, found = roster.GetTxKey(2)
+	require.False(t, found)
+
+	roster = roster.WithTxKeys([]crypto.PublicKey{nil, nil})
+	require.False(t, roster.HasTxKeys())
+}
+
+func TestRoster_NewWeighted(t *testing.T) {
+	authority := fake.NewAuthority(2, fake.NewSigner)
+	addrs := []mino.Address{authority.GetAddress(0), authority.GetAddress(1)}
+	pubkeys := []crypto.PublicKey{authority.GetSigner(0).GetPublicKey(), authority.GetSigner(1).GetPublicKey()}
+
+	roster := NewWeighted(addrs, pubkeys, []uint64{3, 1})
+	require.True(t, roster.IsWeighted())
+	require.Equal(t, []uint64{3, 1}, roster.weights)
+
+	roster = NewWeighted(addrs, pubkeys, []uint64{1, 1})
+	require.False(t, roster.IsWeighted())
+	require.Equal(t, New(addrs, pubkeys), roster)
 }
 
 func TestRoster_Take(t *testing.T) {
@@ -127,6 +230,28 @@ func TestRoster_Take(t *testing.T) {
 
 	roster2 = roster.Take(mino.RangeFilter(1, 3))
 	require.Equal(t, 2, roster2.Len())
+
+	roster.weights = []uint64{1, 2, 1}
+	roster2 = roster.Take(mino.RangeFilter(1, 3))
+	require.Equal(t, uint64(2), roster2.(Roster).GetWeight(0))
+	require.Equal(t, uint64(1), roster2.(Roster).GetWeight(1))
+
+	roster2 = roster.Take(mino.IndexFilter(0))
+	require.False(t, roster2.(Roster).IsWeighted())
+
+	roster = roster.WithVRFKeys([]vrf.PublicKey{{}, {}, vrf.NewSigner().GetPublicKey()})
+	roster2 = roster.Take(mino.IndexFilter(2))
+	require.True(t, roster2.(Roster).HasVRFKeys())
+
+	roster2 = roster.Take(mino.IndexFilter(0))
+	require.False(t, roster2.(Roster).HasVRFKeys())
+
+	roster = roster.WithTxKeys([]crypto.PublicKey{nil, fake.PublicKey{}, nil})
+	roster2 = roster.Take(mino.IndexFilter(1))
+	require.True(t, roster2.(Roster).HasTxKeys())
+
+	roster2 = roster.Take(mino.IndexFilter(2))
+	require.False(t, roster2.(Roster).HasTxKeys())
 }
 
 func TestRoster_Apply(t *testing.T) {
@@ -146,6 +271,41 @@ func TestRoster_Apply(t *testing.T) {
 
 	roster3 := roster2.Apply(cset)
 	require.Equal(t, roster.Len()-1, roster3.Len())
+	require.False(t, roster3.(Roster).IsWeighted())
+
+	roster.weights = []uint64{2, 3, 4}
+
+	cset = NewChangeSet()
+	cset.Remove(1)
+	cset.AddWeighted(fake.NewAddress(5), fake.PublicKey{}, 5)
+
+	roster4 := roster.Apply(cset).(Roster)
+	require.Equal(t, []uint64{2, 4, 5}, roster4.weights)
+	require.Equal(t, uint64(11), roster4.TotalWeight())
+
+	// The indices of the replacements are the ones before the removals.
+	pubkey := bls.NewSigner().GetPublicKey()
+
+	cset = NewChangeSet()
+	cset.Replace(2, pubkey)
+	cset.Replace(5, pubkey)
+	cset.Remove(0)
+
+	roster5 := roster.Apply(cset).(Roster)
+	require.Equal(t, 2, roster5.Len())
+	require.Equal(t, pubkey, roster5.pubkeys[1])
+	require.Equal(t, []uint64{3, 4}, roster5.weights)
+
+	// The order of the removals doesn't matter, and duplicates are ignored.
+	cset = NewChangeSet()
+	cset.Remove(0)
+	cset.Remove(2)
+	cset.Remove(0)
+
+	roster6 := roster.Apply(cset).(Roster)
+	require.Equal(t, 1, roster6.Len())
+	require.Equal(t, roster.addrs[1], roster6.addrs[0])
+	require.Equal(t, []uint64{3}, roster6.weights)
 }
 
 func TestRoster_Diff(t *testing.T) {
@@ -167,6 +327,39 @@ func TestRoster_Diff(t *testing.T) {
 	require.Len(t, diff.addrs, 2)
 	require.Len(t, diff.pubkeys, 2)
 
+	roster5 := FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+	roster5.weights = []uint64{1, 1, 2}
+	diff = roster1.Diff(roster5).(*RosterChangeSet)
+	require.Equal(t, []uint{2}, diff.remove)
+	require.Equal(t, []uint64{2}, diff.GetWeights())
+	require.Equal(t, roster5, roster1.Apply(diff))
+
+	key := vrf.NewSigner().GetPublicKey()
+	roster6 := FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+	roster6 = roster6.WithVRFKeys([]vrf.PublicKey{{}, {}, key})
+	diff = roster1.Diff(roster6).(*RosterChangeSet)
+	require.Equal(t, []uint{2}, diff.remove)
+	require.True(t, diff.HasVRFKeys())
+	require.Equal(t, roster6, roster1.Apply(diff))
+	require.Equal(t, 0, roster6.Diff(roster6).NumChanges())
+
+	txkey := fake.NewSigner().GetPublicKey()
+	roster7 := FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+	roster7 = roster7.WithTxKeys([]crypto.PublicKey{nil, nil, txkey})
+	diff = roster1.Diff(roster7).(*RosterChangeSet)
+	require.Equal(t, []uint{2}, diff.remove)
+	require.True(t, diff.HasTxKeys())
+	require.Equal(t, roster7, roster1.Apply(diff))
+	require.Equal(t, 0, roster7.Diff(roster7).NumChanges())
+
+	// A participant that only rotates its public key has it replaced.
+	roster8 := FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+	roster8.pubkeys[1] = bls.NewSigner().GetPublicKey()
+	diff = roster1.Diff(roster8).(*RosterChangeSet)
+	require.Equal(t, 1, diff.NumChanges())
+	require.Equal(t, []Replace{{Index: 1, NewPublicKey: roster8.pubkeys[1]}}, diff.GetReplacements())
+	require.Equal(t, roster8, roster1.Apply(diff))
+
 	diff = roster1.Diff((Authority)(nil)).(*RosterChangeSet)
 	require.Equal(t, NewChangeSet(), diff)
 }
@@ -176,6 +369,29 @@ func TestRoster_Len(t *testing.T) {
 	require.Equal(t, 3, roster.Len())
 }
 
+func TestRoster_GetWeight(t *testing.T) {
+	roster := FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+	require.Equal(t, uint64(1), roster.GetWeight(0))
+	require.Equal(t, uint64(1), roster.GetWeight(2))
+	require.Equal(t, uint64(0), roster.GetWeight(3))
+	require.Equal(t, uint64(0), roster.GetWeight(-1))
+
+	roster.weights = []uint64{5, 0, 2}
+	require.Equal(t, uint64(5), roster.GetWeight(0))
+	require.Equal(t, uint64(0), roster.GetWeight(1))
+	require.Equal(t, uint64(2), roster.GetWeight(2))
+}
+
+func TestRoster_TotalWeight(t *testing.T) {
+	roster := FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+	require.Equal(t, uint64(3), roster.TotalWeight())
+
+	roster.weights = []uint64{5, 0, 2}
+	require.Equal(t, uint64(7), roster.TotalWeight())
+
+	require.Equal(t, uint64(0), New(nil, nil).TotalWeight())
+}
+
 func TestRoster_GetPublicKey(t *testing.T) {
 	authority := fake.NewAuthority(3, fake.NewSigner)
 	roster := FromAuthority(authority)
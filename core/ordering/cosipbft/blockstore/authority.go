@@ -0,0 +1,366 @@
+// This file contains the implementations of an authority store. An in-memory
+// and a persistent implementation are available.
+
+package blockstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"sync"
+
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/json"
+	"golang.org/x/xerrors"
+)
+
+var authorityBucket = []byte("blockstore-authorities")
+
+type snapshot struct {
+	index     uint64
+	authority authority.Authority
+	digest    []byte
+}
+
+// snapshots is the list of snapshots shared by the stores created for a
+// transaction.
+type snapshots struct {
+	sync.Mutex
+
+	list []snapshot
+}
+
+// InMemoryAuthorities is a store that keeps the snapshots of the authority in
+// memory. A snapshot is only created when the authority changes.
+//
+// - implements blockstore.AuthorityStore
+type InMemoryAuthorities struct {
+	*snapshots
+
+	txn store.Transaction
+}
+
+// NewAuthorityStore returns a new empty in-memory authority store.
+func NewAuthorityStore() *InMemoryAuthorities {
+	return &InMemoryAuthorities{
+		snapshots: &snapshots{},
+	}
+}
+
+// Len implements blockstore.AuthorityStore. It returns the number of snapshots
+// in the store.
+func (s *InMemoryAuthorities) Len() int {
+	s.Lock()
+	defer s.Unlock()
+
+	return len(s.list)
+}
+
+// Store implements blockstore.AuthorityStore. It creates a snapshot of the
+// authority for the index if it differs from the latest one. The snapshot is
+// only applied when the transaction is committed, if any.
+func (s *InMemoryAuthorities) Store(index uint64, roster authority.Authority) error {
+	snap, changed, err := s.prepare(index, roster)
+	if err != nil {
+		return xerrors.Errorf("snapshot failed: %v", err)
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if s.txn != nil {
+		s.txn.OnCommit(func() {
+			s.commit(snap)
+		})
+	} else {
+		s.commit(snap)
+	}
+
+	return nil
+}
+
+// GetByIndex implements blockstore.AuthorityStore. It returns the authority
+// effective for the block at the index.
+func (s *InMemoryAuthorities) GetByIndex(index uint64) (authority.Authority, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	// Look for the first snapshot after the index, which means the previous
+	// one is the effective authority.
+	i := sort.Search(len(s.list), func(i int) bool {
+		return s.list[i].index > index
+	})
+
+	if i == 0 {
+		return nil, xerrors.Errorf("authority at index %d not found", index)
+	}
+
+	return s.list[i-1].authority, nil
+}
+
+// GetSnapshot implements blockstore.AuthorityStore. It returns the index and
+// the authority of the snapshot at the position.
+func (s *InMemoryAuthorities) GetSnapshot(pos int) (uint64, authority.Authority, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if pos < 0 || pos >= len(s.list) {
+		return 0, nil, xerrors.Errorf("snapshot %d not found", pos)
+	}
+
+	return s.list[pos].index, s.list[pos].authority, nil
+}
+
+// Diff implements blockstore.AuthorityStore. It returns the change set to apply
+// to the authority at the first index to get the one at the second index.
+func (s *InMemoryAuthorities) Diff(from, to uint64) (authority.ChangeSet, error) {
+	prev, err := s.GetByIndex(from)
+	if err != nil {
+		return nil, xerrors.Errorf("from: %v", err)
+	}
+
+	next, err := s.GetByIndex(to)
+	if err != nil {
+		return nil, xerrors.Errorf("to: %v", err)
+	}
+
+	return prev.Diff(next), nil
+}
+
+// WithTx implements blockstore.AuthorityStore. It returns a store that applies
+// the snapshots when the transaction is committed.
+func (s *InMemoryAuthorities) WithTx(txn store.Transaction) AuthorityStore {
+	return &InMemoryAuthorities{
+		snapshots: s.snapshots,
+		txn:       txn,
+	}
+}
+
+// prepare returns the snapshot of the authority for the index and true if it
+// differs from the latest one, otherwise false.
+func (s *InMemoryAuthorities) prepare(index uint64, roster authority.Authority) (snapshot, bool, error) {
+	digest, err := fingerprint(roster)
+	if err != nil {
+		return snapshot{}, false, err
+	}
+
+	snap := snapshot{
+		index:     index,
+		authority: roster,
+		digest:    digest,
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if len(s.list) == 0 {
+		return snap, true, nil
+	}
+
+	last := s.list[len(s.list)-1]
+
+	if index < last.index {
+		return snap, false, xerrors.Errorf("index %d is below the latest %d", index, last.index)
+	}
+
+	return snap, !bytes.Equal(last.digest, digest), nil
+}
+
+// commit appends the snapshot, or replaces the latest one when the index is the
+// same.
+func (s *InMemoryAuthorities) commit(snap snapshot) {
+	s.Lock()
+	defer s.Unlock()
+
+	last := len(s.list) - 1
+
+	if last >= 0 && s.list[last].index == snap.index {
+		s.list[last] = snap
+		return
+	}
+
+	s.list = append(s.list, snap)
+}
+
+// add appends the snapshot if the authority has changed.
+func (s *InMemoryAuthorities) add(index uint64, roster authority.Authority) error {
+	snap, changed, err := s.prepare(index, roster)
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		s.commit(snap)
+	}
+
+	return nil
+}
+
+// AuthorityDiskStore is a persistent authority store. The snapshots are
+// written in the database and kept in memory for fast access.
+//
+// - implements blockstore.AuthorityStore
+type AuthorityDiskStore struct {
+	*InMemoryAuthorities
+
+	db      kv.DB
+	context serde.Context
+	fac     authority.Factory
+	txn     store.Transaction
+}
+
+// NewAuthorityDiskStore creates a new persistent authority store using the
+// database.
+func NewAuthorityDiskStore(db kv.DB, fac authority.Factory) AuthorityDiskStore {
+	return AuthorityDiskStore{
+		InMemoryAuthorities: NewAuthorityStore(),
+		db:                  db,
+		context:             json.NewContext(),
+		fac:                 fac,
+	}
+}
+
+// Load reads the snapshots in the database and populates the memory.
+func (s AuthorityDiskStore) Load() error {
+	return s.db.View(func(tx kv.ReadableTx) error {
+		bucket := tx.GetBucket(authorityBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.Scan([]byte{}, func(key, value []byte) error {
+			roster, err := s.fac.AuthorityOf(s.context, value)
+			if err != nil {
+				return xerrors.Errorf("malformed authority: %v", err)
+			}
+
+			err = s.add(binary.BigEndian.Uint64(key), roster)
+			if err != nil {
+				return xerrors.Errorf("snapshot failed: %v", err)
+			}
+
+			return nil
+		})
+	})
+}
+
+// Store implements blockstore.AuthorityStore. It creates a snapshot of the
+// authority if it differs from the latest one, and writes it in the database.
+func (s AuthorityDiskStore) Store(index uint64, roster authority.Authority) error {
+	data, err := roster.Serialize(s.context)
+	if err != nil {
+		return xerrors.Errorf("failed to serialize: %v", err)
+	}
+
+	err = s.doUpdate(func(tx kv.WritableTx) error {
+		bucket, err := tx.GetBucketOrCreate(authorityBucket)
+		if err != nil {
+			return xerrors.Errorf("bucket: %v", err)
+		}
+
+		snap, changed, err := s.prepare(index, roster)
+		if err != nil {
+			return xerrors.Errorf("snapshot failed: %v", err)
+		}
+
+		if !changed {
+			return nil
+		}
+
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, index)
+
+		err = bucket.Set(key, data)
+		if err != nil {
+			return xerrors.Errorf("while writing: %v", err)
+		}
+
+		tx.OnCommit(func() {
+			s.commit(snap)
+		})
+
+		return nil
+	})
+
+	if err != nil {
+		return xerrors.Errorf("store failed: %v", err)
+	}
+
+	return nil
+}
+
+// WithTx implements blockstore.AuthorityStore. It returns a store that will use
+// the transaction for the operations on the database.
+func (s AuthorityDiskStore) WithTx(txn store.Transaction) AuthorityStore {
+	s.txn = txn
+
+	return s
+}
+
+func (s AuthorityDiskStore) doUpdate(fn func(tx kv.WritableTx) error) error {
+	if s.txn != nil {
+		tx, ok := s.txn.(kv.WritableTx)
+		if !ok {
+			return xerrors.Errorf("transaction '%T' is not writable", s.txn)
+		}
+
+		return fn(tx)
+	}
+
+	return s.db.Update(fn)
+}
+
+// BackfillAuthorities populates an empty authority store with the snapshots of
+// the chain, starting from the roster of the genesis block and applying the
+// change set of each block. It does nothing if the genesis block is not set
+// or if the store already has snapshots.
+func BackfillAuthorities(store AuthorityStore, genesis GenesisStore, blocks BlockStore) error {
+	if store.Len() > 0 || !genesis.Exists() {
+		return nil
+	}
+
+	block, err := genesis.Get()
+	if err != nil {
+		return xerrors.Errorf("genesis: %v", err)
+	}
+
+	roster := block.GetRoster()
+
+	err = store.Store(0, roster)
+	if err != nil {
+		return xerrors.Errorf("store: %v", err)
+	}
+
+	for index := uint64(0); index < blocks.Len(); index++ {
+		link, err := blocks.GetByIndex(index)
+		if err != nil {
+			return xerrors.Errorf("block %d: %v", index, err)
+		}
+
+		roster = roster.Apply(link.GetChangeSet())
+
+		err = store.Store(index+1, roster)
+		if err != nil {
+			return xerrors.Errorf("store: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func fingerprint(roster authority.Authority) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+
+	// The fields are prefixed with their length so that two different
+	// rosters are never mistaken for the same one.
+	err := authority.Fingerprint(buffer, roster, authority.FingerprintV2)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to fingerprint: %v", err)
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,291 @@
+package blockstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestInMemoryAuthorities_Store(t *testing.T) {
+	store := NewAuthorityStore()
+
+	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+
+	require.NoError(t, store.Store(0, ro))
+	require.NoError(t, store.Store(1, ro))
+	require.Equal(t, 1, store.Len())
+
+	require.NoError(t, store.Store(5, ro.Take(mino.RangeFilter(0, 2)).(authority.Authority)))
+	require.Equal(t, 2, store.Len())
+
+	// Same index is replaced by the latest authority.
+	require.NoError(t, store.Store(5, ro.Take(mino.RangeFilter(0, 1)).(authority.Authority)))
+	require.Equal(t, 2, store.Len())
+
+	err := store.Store(2, ro)
+	require.EqualError(t, err, "snapshot failed: index 2 is below the latest 5")
+
+	err = store.Store(6, badAuthority{Authority: ro})
+	require.EqualError(t, err, fake.Err("snapshot failed: failed to fingerprint: "+
+		"couldn't marshal public key"))
+}
+
+func TestInMemoryAuthorities_GetByIndex(t *testing.T) {
+	store := NewAuthorityStore()
+
+	_, err := store.GetByIndex(0)
+	require.EqualError(t, err, "authority at index 0 not found")
+
+	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+	next := ro.Take(mino.RangeFilter(0, 2)).(authority.Authority)
+
+	require.NoError(t, store.Store(2, ro))
+	require.NoError(t, store.Store(5, next))
+
+	_, err = store.GetByIndex(1)
+	require.EqualError(t, err, "authority at index 1 not found")
+
+	roster, err := store.GetByIndex(2)
+	require.NoError(t, err)
+	require.Equal(t, 3, roster.Len())
+
+	roster, err = store.GetByIndex(4)
+	require.NoError(t, err)
+	require.Equal(t, 3, roster.Len())
+
+	roster, err = store.GetByIndex(10)
+	require.NoError(t, err)
+	require.Equal(t, 2, roster.Len())
+}
+
+func TestInMemoryAuthorities_GetSnapshot(t *testing.T) {
+	store := NewAuthorityStore()
+
+	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+
+	require.NoError(t, store.Store(2, ro))
+	require.NoError(t, store.Store(5, ro.Take(mino.RangeFilter(0, 2)).(authority.Authority)))
+
+	index, roster, err := store.GetSnapshot(1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), index)
+	require.Equal(t, 2, roster.Len())
+
+	_, _, err = store.GetSnapshot(2)
+	require.EqualError(t, err, "snapshot 2 not found")
+
+	_, _, err = store.GetSnapshot(-1)
+	require.EqualError(t, err, "snapshot -1 not found")
+}
+
+func TestInMemoryAuthorities_Diff(t *testing.T) {
+	store := NewAuthorityStore()
+
+	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+
+	require.NoError(t, store.Store(0, ro))
+	require.NoError(t, store.Store(5, ro.Take(mino.RangeFilter(0, 2)).(authority.Authority)))
+
+	cs, err := store.Diff(0, 5)
+	require.NoError(t, err)
+	require.Equal(t, 1, cs.NumChanges())
+
+	cs, err = store.Diff(1, 4)
+	require.NoError(t, err)
+	require.Equal(t, 0, cs.NumChanges())
+
+	store = NewAuthorityStore()
+	_, err = store.Diff(0, 1)
+	require.EqualError(t, err, "from: authority at index 0 not found")
+
+	require.NoError(t, store.Store(2, ro))
+	_, err = store.Diff(2, 1)
+	require.EqualError(t, err, "to: authority at index 1 not found")
+}
+
+func TestAuthorityDiskStore_Load(t *testing.T) {
+	db, clean := makeDB(t)
+	defer clean()
+
+	fac := authority.NewFactory(fake.AddressFactory{}, fake.PublicKeyFactory{})
+
+	store := NewAuthorityDiskStore(db, fac)
+	require.NoError(t, store.Load())
+
+	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+
+	require.NoError(t, store.Store(0, ro))
+	require.NoError(t, store.Store(1, ro))
+	require.NoError(t, store.Store(3, ro.Take(mino.RangeFilter(0, 2)).(authority.Authority)))
+	require.Equal(t, 2, store.Len())
+
+	store = NewAuthorityDiskStore(db, fac)
+	require.NoError(t, store.Load())
+	require.Equal(t, 2, store.Len())
+
+	roster, err := store.GetByIndex(2)
+	require.NoError(t, err)
+	require.Equal(t, 3, roster.Len())
+
+	store = NewAuthorityDiskStore(db, badAuthorityFac{})
+	err = store.Load()
+	require.EqualError(t, err, fake.Err("malformed authority"))
+}
+
+func TestAuthorityDiskStore_Store(t *testing.T) {
+	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+
+	store := NewAuthorityDiskStore(badDB{}, nil)
+
+	err := store.Store(0, ro)
+	require.EqualError(t, err, fake.Err("store failed: bucket"))
+	require.Equal(t, 0, store.Len())
+
+	store.context = fake.NewBadContext()
+	err = store.Store(0, ro)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to serialize: ")
+}
+
+func TestInMemoryAuthorities_WithTx(t *testing.T) {
+	store := NewAuthorityStore()
+
+	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+
+	tx := &fakeTx{}
+	require.NoError(t, store.WithTx(tx).Store(0, ro))
+	require.Equal(t, 0, store.Len())
+
+	tx.fn()
+	require.Equal(t, 1, store.Len())
+}
+
+func TestAuthorityDiskStore_WithTx(t *testing.T) {
+	db, clean := makeDB(t)
+	defer clean()
+
+	fac := authority.NewFactory(fake.AddressFactory{}, fake.PublicKeyFactory{})
+	store := NewAuthorityDiskStore(db, fac)
+
+	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+
+	err := db.Update(func(tx kv.WritableTx) error {
+		require.NoError(t, store.WithTx(tx).Store(0, ro))
+		require.Equal(t, 0, store.Len())
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, store.Len())
+
+	// The snapshot is dropped when the transaction is aborted.
+	err = db.Update(func(tx kv.WritableTx) error {
+		require.NoError(t, store.WithTx(tx).Store(1, ro.Take(mino.RangeFilter(0, 2)).(authority.Authority)))
+
+		return fake.GetError()
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, store.Len())
+
+	store = NewAuthorityDiskStore(db, fac)
+	require.NoError(t, store.Load())
+	require.Equal(t, 1, store.Len())
+
+	err = store.WithTx(dummyTx{}).Store(1, ro)
+	require.EqualError(t, err,
+		"store failed: transaction 'blockstore.dummyTx' is not writable")
+}
+
+func TestBackfillAuthorities(t *testing.T) {
+	store := NewAuthorityStore()
+	genesis := NewGenesisStore()
+	blocks := NewInMemory()
+
+	// Nothing happens without a genesis block.
+	require.NoError(t, BackfillAuthorities(store, genesis, blocks))
+	require.Equal(t, 0, store.Len())
+
+	require.NoError(t, genesis.Set(makeGenesis(t)))
+
+	cs := authority.NewChangeSet()
+	cs.Add(fake.NewAddress(1), fake.PublicKey{})
+
+	first := makeLinkWithChangeSet(t, types.Digest{}, authority.NewChangeSet())
+	require.NoError(t, blocks.Store(first))
+	require.NoError(t, blocks.Store(makeLinkWithChangeSet(t, first.GetTo(), cs)))
+
+	require.NoError(t, BackfillAuthorities(store, genesis, blocks))
+	require.Equal(t, 2, store.Len())
+
+	roster, err := store.GetByIndex(1)
+	require.NoError(t, err)
+	require.Equal(t, 1, roster.Len())
+
+	roster, err = store.GetByIndex(2)
+	require.NoError(t, err)
+	require.Equal(t, 2, roster.Len())
+
+	// The store is left untouched when it is already populated.
+	require.NoError(t, BackfillAuthorities(badAuthorityStore{store}, genesis, blocks))
+
+	err = BackfillAuthorities(badAuthorityStore{NewAuthorityStore()}, genesis, blocks)
+	require.EqualError(t, err, fake.Err("store"))
+
+	err = BackfillAuthorities(NewAuthorityStore(), genesis, badBlockStore{BlockStore: blocks})
+	require.EqualError(t, err, fake.Err("block 0"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeLinkWithChangeSet(t *testing.T, from types.Digest, cs authority.ChangeSet) types.BlockLink {
+	to, err := types.NewBlock(simple.NewResult(nil))
+	require.NoError(t, err)
+
+	link, err := types.NewBlockLink(from, to,
+		types.WithSignatures(fake.Signature{}, fake.Signature{}), types.WithChangeSet(cs))
+	require.NoError(t, err)
+
+	return link
+}
+
+type badAuthorityStore struct {
+	AuthorityStore
+}
+
+func (badAuthorityStore) Store(uint64, authority.Authority) error {
+	return fake.GetError()
+}
+
+type badBlockStore struct {
+	BlockStore
+}
+
+func (badBlockStore) GetByIndex(uint64) (types.BlockLink, error) {
+	return nil, fake.GetError()
+}
+
+type badAuthority struct {
+	authority.Authority
+}
+
+func (badAuthority) PublicKeyIterator() crypto.PublicKeyIterator {
+	return authority.New([]mino.Address{fake.NewAddress(0)},
+		[]crypto.PublicKey{fake.NewBadPublicKey()}).PublicKeyIterator()
+}
+
+type badAuthorityFac struct {
+	authority.Factory
+}
+
+func (badAuthorityFac) AuthorityOf(serde.Context, []byte) (authority.Authority, error) {
+	return nil, fake.GetError()
+}
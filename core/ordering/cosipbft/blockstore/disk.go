@@ -24,18 +24,22 @@ type cachedData struct {
 	sync.Mutex
 
 	length  uint64
+	pruned  uint64
 	last    types.BlockLink
 	indices map[types.Digest]uint64
 }
 
-// InDisk is a persistent storage implementation for the blocks.
+// InDisk is a persistent storage implementation for the blocks. The forward
+// links of the pruned blocks are moved to a second bucket.
 //
 // - implements blockstore.BlockStore
+// - implements blockstore.Pruner
 type InDisk struct {
 	*cachedData
 
 	db      kv.DB
 	bucket  []byte
+	headers []byte
 	context serde.Context
 	fac     types.LinkFactory
 	watcher core.Observable
@@ -48,7 +52,8 @@ func NewDiskStore(db kv.DB, fac types.LinkFactory) *InDisk {
 	return &InDisk{
 		db:      db,
 		bucket:  []byte("blocks"),
-		context: json.NewContext(),
+		headers: []byte("block-headers"),
+		context: serde.WithCompression(json.NewContext(), serde.FlateCompressor{}),
 		fac:     fac,
 		watcher: core.NewWatcher(),
 		cachedData: &cachedData{
@@ -72,13 +77,33 @@ func (s *InDisk) Load() error {
 	defer s.Unlock()
 
 	return s.doView(func(tx kv.ReadableTx) error {
+		headers := tx.GetBucket(s.headers)
+		if headers != nil {
+			err := headers.Scan([]byte{}, func(key, value []byte) error {
+				link, err := s.linkOf(value)
+				if err != nil {
+					return xerrors.Errorf("malformed link: %v", err)
+				}
+
+				s.length++
+				s.pruned++
+				s.indices[link.GetTo()] = binary.LittleEndian.Uint64(key)
+
+				return nil
+			})
+
+			if err != nil {
+				return xerrors.Errorf("while scanning headers: %v", err)
+			}
+		}
+
 		bucket := tx.GetBucket(s.bucket)
 		if bucket == nil {
 			return nil
 		}
 
 		err := bucket.Scan([]byte{}, func(key, value []byte) error {
-			link, err := s.fac.BlockLinkOf(s.context, value)
+			link, err := s.blockLinkOf(value)
 			if err != nil {
 				return xerrors.Errorf("malformed block: %v", err)
 			}
@@ -115,6 +140,11 @@ func (s *InDisk) Store(link types.BlockLink) error {
 		return xerrors.Errorf("failed to serialize: %v", err)
 	}
 
+	data, err = serde.Compress(s.context, data)
+	if err != nil {
+		return xerrors.Errorf("failed to compress: %v", err)
+	}
+
 	return s.doUpdate(func(tx kv.WritableTx) error {
 		bucket, err := tx.GetBucketOrCreate(s.bucket)
 		if err != nil {
@@ -163,6 +193,10 @@ func (s *InDisk) Get(id types.Digest) (types.BlockLink, error) {
 // GetByIndex implements blockstore.BlockStore. It returns the block associated
 // to the index if it exists, otherwise it returns an error.
 func (s *InDisk) GetByIndex(index uint64) (link types.BlockLink, err error) {
+	if index < s.GetPruned() {
+		return nil, xerrors.Errorf("index %d: %w", index, ErrPruned)
+	}
+
 	key := s.makeKey(index)
 
 	err = s.doView(func(tx kv.ReadableTx) error {
@@ -175,7 +209,7 @@ func (s *InDisk) GetByIndex(index uint64) (link types.BlockLink, err error) {
 		}
 
 		var err error
-		link, err = s.fac.BlockLinkOf(s.context, value)
+		link, err = s.blockLinkOf(value)
 		if err != nil {
 			return xerrors.Errorf("malformed block: %v", err)
 		}
@@ -187,10 +221,11 @@ func (s *InDisk) GetByIndex(index uint64) (link types.BlockLink, err error) {
 }
 
 // GetChain implements blockstore.Blockstore. It returns a chain to the latest
-// block.
+// block, which uses the forward links of the pruned blocks.
 func (s *InDisk) GetChain() (types.Chain, error) {
 	s.Lock()
 	length := s.length
+	pruned := s.pruned
 	s.Unlock()
 
 	if length == 0 {
@@ -202,11 +237,22 @@ func (s *InDisk) GetChain() (types.Chain, error) {
 	var chain types.Chain
 
 	err := s.doView(func(tx kv.ReadableTx) error {
+		// The pruned blocks are replaced by their forward links, which are
+		// enough to verify the chain.
+		for i := uint64(0); i < pruned; i++ {
+			link, err := s.readLink(tx, i)
+			if err != nil {
+				return xerrors.Errorf("while reading headers: %v", err)
+			}
+
+			prevs[i] = link
+		}
+
 		bucket := tx.GetBucket(s.bucket)
 
-		i := uint64(0)
+		i := pruned
 		err := bucket.Scan([]byte{}, func(key, value []byte) error {
-			link, err := s.fac.BlockLinkOf(s.context, value)
+			link, err := s.blockLinkOf(value)
 			if err != nil {
 				return xerrors.Errorf("block malformed: %v", err)
 			}
@@ -257,12 +303,117 @@ func (s *InDisk) Watch(ctx context.Context) <-chan types.BlockLink {
 	return obs.ch
 }
 
+// Prune implements blockstore.Pruner. It replaces the blocks before the index
+// by their forward links, and returns the number of blocks pruned.
+func (s *InDisk) Prune(before uint64) (uint64, error) {
+	s.Lock()
+	length := s.length
+	pruned := s.pruned
+	s.Unlock()
+
+	if before >= length {
+		return 0, xerrors.Errorf("cannot prune the latest block: %d >= %d", before, length)
+	}
+
+	if before <= pruned {
+		return 0, nil
+	}
+
+	err := s.doUpdate(func(tx kv.WritableTx) error {
+		bucket, err := tx.GetBucketOrCreate(s.bucket)
+		if err != nil {
+			return xerrors.Errorf("bucket failed: %v", err)
+		}
+
+		headers, err := tx.GetBucketOrCreate(s.headers)
+		if err != nil {
+			return xerrors.Errorf("bucket failed: %v", err)
+		}
+
+		for index := pruned; index < before; index++ {
+			key := s.makeKey(index)
+
+			link, err := s.blockLinkOf(bucket.Get(key))
+			if err != nil {
+				return xerrors.Errorf("malformed block %d: %v", index, err)
+			}
+
+			data, err := link.Reduce().Serialize(s.context)
+			if err != nil {
+				return xerrors.Errorf("failed to serialize link: %v", err)
+			}
+
+			err = headers.Set(key, data)
+			if err != nil {
+				return xerrors.Errorf("while writing: %v", err)
+			}
+
+			err = bucket.Delete(key)
+			if err != nil {
+				return xerrors.Errorf("while deleting: %v", err)
+			}
+		}
+
+		tx.OnCommit(func() {
+			s.Lock()
+			s.pruned = before
+			s.Unlock()
+		})
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, xerrors.Errorf("while pruning: %v", err)
+	}
+
+	return before - pruned, nil
+}
+
+// GetPruned implements blockstore.Pruner. It returns the index of the first
+// block that is not pruned.
+func (s *InDisk) GetPruned() uint64 {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.pruned
+}
+
+// GetLinkByIndex implements blockstore.Pruner. It returns the forward link to
+// the block at the index if it exists, otherwise it returns an error.
+func (s *InDisk) GetLinkByIndex(index uint64) (types.Link, error) {
+	if index >= s.GetPruned() {
+		link, err := s.GetByIndex(index)
+		if err != nil {
+			return nil, xerrors.Errorf("block: %w", err)
+		}
+
+		return link.Reduce(), nil
+	}
+
+	var link types.Link
+
+	err := s.doView(func(tx kv.ReadableTx) error {
+		var err error
+		link, err = s.readLink(tx, index)
+
+		return err
+	})
+
+	if err != nil {
+		return nil, xerrors.Errorf("while reading database: %v", err)
+	}
+
+	return link, nil
+}
+
 // WithTx implements blockstore.BlockStore. It returns a store that will use the
 // transaction for the operations on the database.
 func (s *InDisk) WithTx(txn store.Transaction) BlockStore {
 	store := &InDisk{
 		db:         s.db,
 		bucket:     s.bucket,
+		headers:    s.headers,
 		context:    s.context,
 		fac:        s.fac,
 		watcher:    s.watcher,
@@ -299,6 +450,48 @@ func (s *InDisk) doView(fn func(tx kv.ReadableTx) error) error {
 	return s.db.View(fn)
 }
 
+// readLink reads the forward link of a pruned block.
+func (s *InDisk) readLink(tx kv.ReadableTx, index uint64) (types.Link, error) {
+	headers := tx.GetBucket(s.headers)
+	if headers == nil {
+		return nil, xerrors.Errorf("index %d not found: %w", index, ErrNoBlock)
+	}
+
+	value := headers.Get(s.makeKey(index))
+	if len(value) == 0 {
+		return nil, xerrors.Errorf("index %d not found: %w", index, ErrNoBlock)
+	}
+
+	link, err := s.linkOf(value)
+	if err != nil {
+		return nil, xerrors.Errorf("malformed link: %v", err)
+	}
+
+	return link, nil
+}
+
+// blockLinkOf returns the block link of the data, which is decompressed if
+// necessary so that the blocks stored before the compression are still read.
+func (s *InDisk) blockLinkOf(data []byte) (types.BlockLink, error) {
+	data, err := serde.Decompress(s.context, data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decompress: %v", err)
+	}
+
+	return s.fac.BlockLinkOf(s.context, data)
+}
+
+// linkOf returns the forward link of the data, which is decompressed if
+// necessary.
+func (s *InDisk) linkOf(data []byte) (types.Link, error) {
+	data, err := serde.Decompress(s.context, data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decompress: %v", err)
+	}
+
+	return s.fac.LinkOf(s.context, data)
+}
+
 func (s *InDisk) makeKey(index uint64) []byte {
 	key := make([]byte, 8)
 	binary.LittleEndian.PutUint64(key, index)
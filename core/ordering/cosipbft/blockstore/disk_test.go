@@ -1,7 +1,9 @@
 package blockstore
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -83,6 +85,37 @@ func TestInDisk_Store(t *testing.T) {
 	require.EqualError(t, err, fake.Err("while writing"))
 }
 
+func TestInDisk_Compression(t *testing.T) {
+	store := NewDiskStore(nil, makeBlockFac())
+
+	link := makeLink(t, types.Digest{}, types.WithIndex(3))
+
+	data, err := link.Serialize(store.context)
+	require.NoError(t, err)
+
+	// The blocks stored before the compression are read as is.
+	res, err := store.blockLinkOf(data)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), res.GetBlock().GetIndex())
+
+	res, err = store.blockLinkOf(compress(t, data))
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), res.GetBlock().GetIndex())
+
+	_, err = store.blockLinkOf([]byte{0})
+	require.EqualError(t, err, "failed to decompress: missing algorithm")
+
+	data, err = link.Reduce().Serialize(store.context)
+	require.NoError(t, err)
+
+	other, err := store.linkOf(compress(t, data))
+	require.NoError(t, err)
+	require.Equal(t, link.GetTo(), other.GetTo())
+
+	_, err = store.linkOf([]byte{0})
+	require.EqualError(t, err, "failed to decompress: missing algorithm")
+}
+
 func TestInDisk_Get(t *testing.T) {
 	db, clean := makeDB(t)
 	defer clean()
@@ -149,6 +182,87 @@ func TestInDisk_GetChain(t *testing.T) {
 	require.EqualError(t, err, fake.Err("while reading database: while scanning: block malformed"))
 }
 
+func TestInDisk_Prune(t *testing.T) {
+	db, clean := makeDB(t)
+	defer clean()
+
+	store := NewDiskStore(db, makeBlockFac())
+
+	links := make([]types.BlockLink, 4)
+	for i := range links {
+		from := types.Digest{}
+		if i > 0 {
+			from = links[i-1].GetTo()
+		}
+
+		links[i] = makeLink(t, from, types.WithIndex(uint64(i)))
+
+		err := store.Store(links[i])
+		require.NoError(t, err)
+	}
+
+	n, err := store.Prune(2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), n)
+	require.Equal(t, uint64(2), store.GetPruned())
+
+	_, err = store.GetByIndex(1)
+	require.EqualError(t, err, "index 1: block pruned")
+	require.True(t, errors.Is(err, ErrPruned))
+
+	_, err = store.Get(links[0].GetTo())
+	require.EqualError(t, err, "index 0: block pruned")
+
+	link, err := store.GetLinkByIndex(1)
+	require.NoError(t, err)
+	require.Equal(t, links[1].GetHash(), link.GetHash())
+
+	link, err = store.GetLinkByIndex(3)
+	require.NoError(t, err)
+	require.Equal(t, links[3].GetHash(), link.GetHash())
+
+	chain, err := store.GetChain()
+	require.NoError(t, err)
+	require.Len(t, chain.GetLinks(), 4)
+	require.Equal(t, links[0].GetHash(), chain.GetLinks()[0].GetHash())
+	require.Equal(t, uint64(3), chain.GetBlock().GetIndex())
+
+	// Nothing to prune.
+	n, err = store.Prune(1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), n)
+
+	_, err = store.Prune(4)
+	require.EqualError(t, err, "cannot prune the latest block: 4 >= 4")
+
+	// The pruned blocks are known after a restart.
+	newStore := NewDiskStore(db, makeBlockFac())
+
+	err = newStore.Load()
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), newStore.Len())
+	require.Equal(t, uint64(2), newStore.GetPruned())
+
+	_, err = newStore.Get(links[1].GetTo())
+	require.EqualError(t, err, "index 1: block pruned")
+
+	_, err = newStore.GetLinkByIndex(5)
+	require.EqualError(t, err, "block: index 5 not found: no block")
+
+	newStore.fac = badLinkFac{}
+	_, err = newStore.Prune(3)
+	require.EqualError(t, err, fake.Err("while pruning: malformed block 2"))
+
+	_, err = newStore.GetChain()
+	require.EqualError(t, err, fake.Err("while reading database: while reading headers: malformed link"))
+
+	_, err = newStore.GetLinkByIndex(0)
+	require.EqualError(t, err, fake.Err("while reading database: malformed link"))
+
+	err = newStore.Load()
+	require.EqualError(t, err, fake.Err("while scanning headers: malformed link"))
+}
+
 func TestInDisk_Last(t *testing.T) {
 	db, clean := makeDB(t)
 	defer clean()
@@ -235,6 +349,20 @@ func makeDB(t *testing.T) (kv.DB, func()) {
 	return db, clean
 }
 
+// compress returns the data with the header of the DEFLATE algorithm, whatever
+// its size.
+func compress(t *testing.T, data []byte) []byte {
+	buffer := bytes.NewBuffer([]byte{0, byte(serde.AlgorithmFlate)})
+
+	w := serde.FlateCompressor{}.NewWriter(buffer)
+
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buffer.Bytes()
+}
+
 func makeBlockFac() types.LinkFactory {
 	blockFac := types.NewBlockFactory(fakeResultFac{})
 
@@ -265,6 +393,10 @@ func (badLinkFac) BlockLinkOf(serde.Context, []byte) (types.BlockLink, error) {
 	return nil, fake.GetError()
 }
 
+func (badLinkFac) LinkOf(serde.Context, []byte) (types.Link, error) {
+	return nil, fake.GetError()
+}
+
 type badLink struct {
 	types.BlockLink
 }
@@ -12,6 +12,13 @@
 // The genesis store allows to set a definitive genesis block and persist it so
 // that it can be reloaded later on.
 //
+// The authority store keeps a snapshot of the authority each time it changes
+// so that it can be read at any height of the chain.
+//
+// A block store can also be a pruner, which discards the old blocks but keeps
+// their forward links so that the chain can still be proven from the genesis
+// block.
+//
 // Documentation Last Review: 13.10.2020
 //
 package blockstore
@@ -20,6 +27,7 @@ import (
 	"context"
 	"errors"
 
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/core/ordering/cosipbft/types"
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/store/hashtree"
@@ -28,6 +36,9 @@ import (
 // ErrNoBlock is the error message returned when the block is unknown.
 var ErrNoBlock = errors.New("no block")
 
+// ErrPruned is the error message returned when the block has been pruned.
+var ErrPruned = errors.New("block pruned")
+
 // TreeCache is a cache to store a tree that needs to be accessed in different
 // places.
 type TreeCache interface {
@@ -93,3 +104,45 @@ type BlockStore interface {
 	// operations on the database.
 	WithTx(store.Transaction) BlockStore
 }
+
+// AuthorityStore is the interface to store the authority of the chain at the
+// different heights.
+type AuthorityStore interface {
+	// Len must return the number of snapshots in the store.
+	Len() int
+
+	// Store must store the authority effective from the block at the index.
+	Store(index uint64, roster authority.Authority) error
+
+	// GetByIndex must return the authority effective for the block at the
+	// index, or an error if it is unknown.
+	GetByIndex(index uint64) (authority.Authority, error)
+
+	// GetSnapshot must return the index of the block and the authority of the
+	// snapshot at the position, the oldest being at zero.
+	GetSnapshot(pos int) (uint64, authority.Authority, error)
+
+	// Diff must return the change set to apply to the authority of the first
+	// index to get the authority of the second index.
+	Diff(from, to uint64) (authority.ChangeSet, error)
+
+	// WithTx returns a store that will use the transaction to store the
+	// snapshots.
+	WithTx(store.Transaction) AuthorityStore
+}
+
+// Pruner is the interface of a block store that can discard the old blocks.
+// The forward link of a pruned block is kept so that the chains, and therefore
+// the proofs, can still be built from the genesis block.
+type Pruner interface {
+	// Prune must discard the blocks before the index, which cannot include
+	// the latest block, and return the number of blocks discarded.
+	Prune(before uint64) (uint64, error)
+
+	// GetPruned must return the index of the first block that is not pruned.
+	GetPruned() uint64
+
+	// GetLinkByIndex must return the forward link to the block at the index,
+	// even if the block has been pruned, or an error.
+	GetLinkByIndex(index uint64) (types.Link, error)
+}
@@ -19,6 +19,7 @@ import (
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/internal/tracing"
 	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
 	"golang.org/x/xerrors"
 )
 
@@ -28,6 +29,10 @@ var (
 	protocolName = "blocksync"
 )
 
+// DefaultRangeSize is the number of blocks requested to a peer at once when a
+// node catches up from several peers in parallel.
+const DefaultRangeSize = 100
+
 // DefaultSync is a block synchronizer that allow soft and hard synchronization
 // of the participants. A soft threshold means that a given number of
 // participants have updated the latest index, whereas a hard one means that
@@ -65,17 +70,29 @@ func NewSynchronizer(param SyncParam) Synchronizer {
 		latest:      &latest,
 		catchUpLock: new(sync.Mutex),
 		logger:      logger,
+		me:          param.Mino.GetAddress(),
 		genesis:     param.Genesis,
 		blocks:      param.Blocks,
 		pbftsm:      param.PBFT,
 		verifierFac: param.VerifierFactory,
+		rangeSize:   DefaultRangeSize,
 	}
 
 	fac := types.NewMessageFactory(param.LinkFactory, param.ChainFactory)
 
+	// The blocks are transferred in the background and must not delay the
+	// consensus. They are compressed as a catch-up can carry a large number of
+	// them.
+	rpc := mino.MustCreateRPC(param.Mino, "blocksync", mino.WithCompression(
+		mino.NewClassifiedHandler(h, mino.ClassBulk), mino.CompressionGzip), fac)
+
+	// The handler requests the ranges of blocks to the peers with the same
+	// RPC.
+	h.rpc = rpc
+
 	s := defaultSync{
 		logger:      logger,
-		rpc:         mino.MustCreateRPC(param.Mino, "blocksync", h, fac),
+		rpc:         rpc,
 		pbftsm:      param.PBFT,
 		blocks:      param.Blocks,
 		latest:      &latest,
@@ -209,10 +226,45 @@ type handler struct {
 	catchUpLock *sync.Mutex
 
 	logger      zerolog.Logger
+	me          mino.Address
+	rpc         mino.RPC
 	blocks      blockstore.BlockStore
 	genesis     blockstore.GenesisStore
 	pbftsm      pbft.StateMachine
 	verifierFac crypto.VerifierFactory
+
+	// rangeSize is the number of blocks of a range request, or zero to catch
+	// up only from the orchestrator.
+	rangeSize uint64
+}
+
+// Process implements mino.Handler. It returns the blocks of the range that
+// the node has, in order, so that a peer can catch up in parallel.
+func (h *handler) Process(req mino.Request) (serde.Message, error) {
+	in, ok := req.Message.(types.RangeRequest)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message '%T'", req.Message)
+	}
+
+	to := in.GetTo()
+	if h.rangeSize > 0 && to > in.GetFrom()+h.rangeSize {
+		// The range is limited so that a peer cannot request the whole chain
+		// at once.
+		to = in.GetFrom() + h.rangeSize
+	}
+
+	links := []otypes.BlockLink{}
+
+	for i := in.GetFrom(); i < to && i < h.blocks.Len(); i++ {
+		link, err := h.blocks.GetByIndex(i)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to read block %d: %v", i, err)
+		}
+
+		links = append(links, link)
+	}
+
+	return types.NewRangeReply(links), nil
 }
 
 // Stream implements mino.Handler. It waits for an announcement message and then
@@ -258,6 +310,19 @@ func (h *handler) Stream(out mino.Sender, in mino.Receiver) error {
 		*h.latest = m.GetLatestIndex()
 	}
 
+	err = h.catchUpRanges(m.GetChain(), orch)
+	if err != nil {
+		return xerrors.Errorf("parallel catch up failed: %v", err)
+	}
+
+	if h.blocks.Len() > m.GetLatestIndex() {
+		h.logger.Debug().Msg("catch up done")
+
+		return h.ack(out, orch)
+	}
+
+	// The blocks that could not be fetched from the peers are streamed by the
+	// orchestrator.
 	err = <-out.Send(types.NewSyncRequest(h.blocks.Len()), orch)
 	if err != nil {
 		return xerrors.Errorf("sending request failed: %v", err)
@@ -287,6 +352,171 @@ func (h *handler) Stream(out mino.Sender, in mino.Receiver) error {
 	return h.ack(out, orch)
 }
 
+// catchUpRanges splits the missing blocks in ranges that are requested to the
+// members of the roster in parallel. The blocks are verified against the links
+// of the chain when they arrive, and then caught up in order until a range is
+// missing, in which case the orchestrator streams the remaining ones.
+func (h *handler) catchUpRanges(chain otypes.Chain, orch mino.Address) error {
+	from := h.blocks.Len()
+	to := chain.GetBlock().GetIndex() + 1
+
+	if h.rangeSize == 0 || to-from <= h.rangeSize {
+		// A single range is faster to stream from the orchestrator.
+		return nil
+	}
+
+	genesis, err := h.genesis.Get()
+	if err != nil {
+		return xerrors.Errorf("reading genesis: %v", err)
+	}
+
+	// The members of the roster after the latest block are the most likely to
+	// have the blocks.
+	roster := genesis.GetRoster()
+	for _, link := range chain.GetLinks() {
+		roster = roster.Apply(link.GetChangeSet())
+	}
+
+	peers := []mino.Address{orch}
+
+	iter := roster.AddressIterator()
+	for iter.HasNext() {
+		addr := iter.GetNext()
+		if !addr.Equal(h.me) && !addr.Equal(orch) {
+			peers = append(peers, addr)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The ranges are pulled by the peers, and a range that failed is put back
+	// for the others.
+	starts := make(chan uint64, (to-from)/h.rangeSize+1)
+	slots := make(map[uint64]chan []otypes.BlockLink)
+
+	for start := from; start < to; start += h.rangeSize {
+		starts <- start
+		slots[start] = make(chan []otypes.BlockLink, 1)
+	}
+
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(len(peers))
+
+	for _, peer := range peers {
+		go func(peer mino.Address) {
+			defer wg.Done()
+
+			for {
+				var start uint64
+
+				select {
+				case <-ctx.Done():
+					return
+				case start = <-starts:
+				}
+
+				end := start + h.rangeSize
+				if end > to {
+					end = to
+				}
+
+				links, err := h.requestRange(ctx, peer, chain.GetLinks(), start, end)
+				if err != nil {
+					h.logger.Debug().Err(err).Stringer("peer", peer).Msg("range failed")
+
+					// The peer stops to take ranges, and the range is left to
+					// the other ones, or to the orchestrator when none is left.
+					starts <- start
+					return
+				}
+
+				slots[start] <- links
+			}
+		}(peer)
+	}
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for start := from; start < to; start += h.rangeSize {
+		var links []otypes.BlockLink
+
+		select {
+		case links = <-slots[start]:
+		case <-done:
+			select {
+			case links = <-slots[start]:
+			default:
+			}
+		}
+
+		if len(links) == 0 {
+			return nil
+		}
+
+		for _, link := range links {
+			h.logger.Debug().
+				Uint64("index", link.GetBlock().GetIndex()).
+				Msg("catch up block")
+
+			err := h.pbftsm.CatchUp(link)
+			if err != nil {
+				return xerrors.Errorf("pbft catch up failed: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// requestRange requests the blocks of the range to the peer, and verifies that
+// they match the links of the chain.
+func (h *handler) requestRange(ctx context.Context, peer mino.Address,
+	chain []otypes.Link, from, to uint64) ([]otypes.BlockLink, error) {
+
+	resps, err := h.rpc.Call(ctx, types.NewRangeRequest(from, to), mino.NewAddresses(peer))
+	if err != nil {
+		return nil, xerrors.Errorf("call failed: %v", err)
+	}
+
+	resp, more := <-resps
+	if !more {
+		return nil, xerrors.New("no reply")
+	}
+
+	msg, err := resp.GetMessageOrError()
+	if err != nil {
+		return nil, xerrors.Errorf("peer failed: %v", err)
+	}
+
+	reply, ok := msg.(types.RangeReply)
+	if !ok {
+		return nil, xerrors.Errorf("unexpected reply '%T'", msg)
+	}
+
+	links := reply.GetLinks()
+	if uint64(len(links)) != to-from {
+		return nil, xerrors.Errorf("missing blocks: %d != %d", len(links), to-from)
+	}
+
+	for i, link := range links {
+		index := from + uint64(i)
+		expected := chain[index]
+
+		if link.GetBlock().GetIndex() != index || link.GetTo() != expected.GetTo() ||
+			link.GetFrom() != expected.GetFrom() {
+
+			return nil, xerrors.Errorf("block %d does not match the chain", index)
+		}
+	}
+
+	return links, nil
+}
+
 func (h *handler) waitAnnounce(ctx context.Context,
 	in mino.Receiver) (*types.SyncMessage, mino.Address, error) {
 
@@ -20,6 +20,7 @@ import (
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/minoch"
+	"go.dedis.ch/dela/serde"
 )
 
 func TestDefaultSync_Basic(t *testing.T) {
@@ -248,6 +249,179 @@ func TestHandler_Stream(t *testing.T) {
 	require.EqualError(t, err, fake.Err("sending ack failed"))
 }
 
+func TestHandler_Parallel_Stream(t *testing.T) {
+	genesis, source := makeSource(t, 10)
+
+	chain, err := source.GetChain()
+	require.NoError(t, err)
+
+	blocks := blockstore.NewInMemory()
+
+	handler := &handler{
+		latest:      new(uint64),
+		catchUpLock: new(sync.Mutex),
+		me:          fake.NewAddress(2),
+		rpc:         makeRangeRPC(source, nil),
+		genesis:     blockstore.NewGenesisStore(),
+		blocks:      blocks,
+		pbftsm:      testSM{blocks: blocks},
+		verifierFac: fake.VerifierFactory{},
+		rangeSize:   3,
+	}
+	handler.genesis.Set(genesis)
+
+	recv := fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewSyncMessage(chain)),
+	)
+
+	// Every block is fetched from the peers, so that no request is sent to the
+	// orchestrator before the acknowledgement.
+	sender := &recordSender{}
+
+	err = handler.Stream(sender, recv)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), blocks.Len())
+	require.Len(t, sender.msgs, 1)
+	require.IsType(t, types.SyncAck{}, sender.msgs[0])
+
+	handler.blocks = blockstore.NewInMemory()
+	handler.pbftsm = testSM{blocks: badBlockStore{}}
+	err = handler.Stream(fake.Sender{}, fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), types.NewSyncMessage(chain)),
+	))
+	require.EqualError(t, err, fake.Err("parallel catch up failed: pbft catch up failed"))
+}
+
+func TestHandler_CatchUpRanges(t *testing.T) {
+	genesis, source := makeSource(t, 10)
+
+	chain, err := source.GetChain()
+	require.NoError(t, err)
+
+	blocks := blockstore.NewInMemory()
+
+	handler := &handler{
+		me:        fake.NewAddress(2),
+		genesis:   blockstore.NewGenesisStore(),
+		blocks:    blocks,
+		pbftsm:    testSM{blocks: blocks},
+		rangeSize: 3,
+	}
+	handler.genesis.Set(genesis)
+
+	// The orchestrator fails, so that the ranges are fetched from the other
+	// peer.
+	handler.rpc = makeRangeRPC(source, func(addr mino.Address) bool {
+		return addr.Equal(fake.NewAddress(0))
+	})
+
+	err = handler.catchUpRanges(chain, fake.NewAddress(0))
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), blocks.Len())
+
+	// When every peer fails, the blocks are left to the orchestrator.
+	handler.blocks = blockstore.NewInMemory()
+	handler.pbftsm = testSM{blocks: handler.blocks}
+	handler.rpc = makeRangeRPC(source, func(mino.Address) bool { return true })
+
+	err = handler.catchUpRanges(chain, fake.NewAddress(0))
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), handler.blocks.Len())
+
+	// A single range is streamed by the orchestrator.
+	handler.rangeSize = 10
+	err = handler.catchUpRanges(chain, fake.NewAddress(0))
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), handler.blocks.Len())
+
+	handler.rangeSize = 3
+	handler.genesis = blockstore.NewGenesisStore()
+	err = handler.catchUpRanges(chain, fake.NewAddress(0))
+	require.EqualError(t, err, "reading genesis: missing genesis block")
+}
+
+func TestHandler_RequestRange(t *testing.T) {
+	genesis, source := makeSource(t, 5)
+
+	other := blockstore.NewInMemory()
+	storeBlocks(t, other, 5, 0xaa)
+
+	chain, err := source.GetChain()
+	require.NoError(t, err)
+
+	handler := &handler{
+		genesis:   blockstore.NewGenesisStore(),
+		rangeSize: 3,
+	}
+	handler.genesis.Set(genesis)
+
+	handler.rpc = makeRangeRPC(source, nil)
+	links, err := handler.requestRange(context.Background(), fake.NewAddress(0), chain.GetLinks(), 1, 4)
+	require.NoError(t, err)
+	require.Len(t, links, 3)
+	require.Equal(t, uint64(1), links[0].GetBlock().GetIndex())
+
+	// The range is limited by the peer.
+	_, err = handler.requestRange(context.Background(), fake.NewAddress(0), chain.GetLinks(), 0, 5)
+	require.EqualError(t, err, "missing blocks: 3 != 5")
+
+	// The blocks of another chain are refused.
+	otherBlocks, err := other.GetByIndex(0)
+	require.NoError(t, err)
+
+	handler.rpc = fake.NewPlayerRPC(func(mino.Address, serde.Message) fake.Reply {
+		return fake.Reply{Message: types.NewRangeReply([]otypes.BlockLink{otherBlocks})}
+	})
+	_, err = handler.requestRange(context.Background(), fake.NewAddress(0), chain.GetLinks(), 0, 1)
+	require.EqualError(t, err, "block 0 does not match the chain")
+
+	handler.rpc = fake.NewPlayerRPC(func(mino.Address, serde.Message) fake.Reply {
+		return fake.Reply{Message: fake.Message{}}
+	})
+	_, err = handler.requestRange(context.Background(), fake.NewAddress(0), chain.GetLinks(), 2, 3)
+	require.EqualError(t, err, "unexpected reply 'fake.Message'")
+
+	handler.rpc = fake.NewPlayerRPC(func(mino.Address, serde.Message) fake.Reply {
+		return fake.Reply{Err: fake.GetError()}
+	})
+	_, err = handler.requestRange(context.Background(), fake.NewAddress(0), chain.GetLinks(), 2, 3)
+	require.EqualError(t, err, fake.Err("peer failed"))
+
+	rpc := fake.NewRPC()
+	rpc.Done()
+	handler.rpc = rpc
+	_, err = handler.requestRange(context.Background(), fake.NewAddress(0), chain.GetLinks(), 2, 3)
+	require.EqualError(t, err, "no reply")
+
+	handler.rpc = fake.NewBadRPC()
+	_, err = handler.requestRange(context.Background(), fake.NewAddress(0), chain.GetLinks(), 2, 3)
+	require.EqualError(t, err, fake.Err("call failed"))
+}
+
+func TestHandler_Process(t *testing.T) {
+	_, source := makeSource(t, 5)
+
+	handler := &handler{
+		blocks:    source,
+		rangeSize: 3,
+	}
+
+	msg, err := handler.Process(mino.Request{Message: types.NewRangeRequest(3, 10)})
+	require.NoError(t, err)
+	require.Len(t, msg.(types.RangeReply).GetLinks(), 2)
+
+	msg, err = handler.Process(mino.Request{Message: types.NewRangeRequest(0, 10)})
+	require.NoError(t, err)
+	require.Len(t, msg.(types.RangeReply).GetLinks(), 3)
+
+	_, err = handler.Process(mino.Request{Message: fake.Message{}})
+	require.EqualError(t, err, "unsupported message 'fake.Message'")
+
+	handler.blocks = badBlockStore{}
+	_, err = handler.Process(mino.Request{Message: types.NewRangeRequest(0, 1)})
+	require.EqualError(t, err, fake.Err("failed to read block 0"))
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
@@ -350,6 +524,10 @@ func (s badBlockStore) GetByIndex(index uint64) (otypes.BlockLink, error) {
 	return nil, fake.GetError()
 }
 
+func (s badBlockStore) Store(otypes.BlockLink) error {
+	return fake.GetError()
+}
+
 type fakeChain struct {
 	otypes.Chain
 
@@ -364,3 +542,46 @@ func (c fakeChain) GetBlock() otypes.Block {
 func (c fakeChain) Verify(otypes.Genesis, crypto.VerifierFactory) error {
 	return c.err
 }
+
+// makeSource returns a genesis block and a store with a chain of blocks that
+// follows it.
+func makeSource(t *testing.T, n int) (otypes.Genesis, blockstore.BlockStore) {
+	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+
+	genesis, err := otypes.NewGenesis(ro)
+	require.NoError(t, err)
+
+	blocks := blockstore.NewInMemory()
+	storeBlocks(t, blocks, n, genesis.GetHash().Bytes()...)
+
+	return genesis, blocks
+}
+
+// makeRangeRPC returns an RPC where the peers reply to the range requests with
+// the blocks of the store, unless they fail.
+func makeRangeRPC(blocks blockstore.BlockStore, fails func(mino.Address) bool) mino.RPC {
+	peer := &handler{blocks: blocks, rangeSize: 3}
+
+	return fake.NewPlayerRPC(func(addr mino.Address, req serde.Message) fake.Reply {
+		if fails != nil && fails(addr) {
+			return fake.Reply{Err: fake.GetError()}
+		}
+
+		msg, err := peer.Process(mino.Request{Address: addr, Message: req})
+
+		return fake.Reply{Message: msg, Err: err}
+	})
+}
+
+// recordSender is a sender that records the messages.
+type recordSender struct {
+	fake.Sender
+
+	msgs []serde.Message
+}
+
+func (s *recordSender) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	s.msgs = append(s.msgs, msg)
+
+	return s.Sender.Send(msg, addrs...)
+}
@@ -31,12 +31,26 @@ type SyncReplyJSON struct {
 // SyncAckJSON is the JSON representation of a sync acknowledgement.
 type SyncAckJSON struct{}
 
+// RangeRequestJSON is the JSON representation of a range request.
+type RangeRequestJSON struct {
+	From uint64
+	To   uint64
+}
+
+// RangeReplyJSON is the JSON representation of a range reply.
+type RangeReplyJSON struct {
+	Links []json.RawMessage
+}
+
 // MessageJSON is the JSON representation of a sync message.
 type MessageJSON struct {
 	Message *SyncMessageJSON `json:",omitempty"`
 	Request *SyncRequestJSON `json:",omitempty"`
 	Reply   *SyncReplyJSON   `json:",omitempty"`
 	Ack     *SyncAckJSON     `json:",omitempty"`
+
+	RangeRequest *RangeRequestJSON `json:",omitempty"`
+	RangeReply   *RangeReplyJSON   `json:",omitempty"`
 }
 
 // MsgFormat is the format engine to encode and decode sync messages.
@@ -80,6 +94,25 @@ func (fmt msgFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error
 		m.Reply = &reply
 	case types.SyncAck:
 		m.Ack = &SyncAckJSON{}
+	case types.RangeRequest:
+		req := RangeRequestJSON{
+			From: in.GetFrom(),
+			To:   in.GetTo(),
+		}
+
+		m.RangeRequest = &req
+	case types.RangeReply:
+		links := make([]json.RawMessage, len(in.GetLinks()))
+		for i, link := range in.GetLinks() {
+			data, err := link.Serialize(ctx)
+			if err != nil {
+				return nil, xerrors.Errorf("link serialization failed: %v", err)
+			}
+
+			links[i] = data
+		}
+
+		m.RangeReply = &RangeReplyJSON{Links: links}
 	default:
 		return nil, xerrors.Errorf("unsupported message '%T'", msg)
 	}
@@ -141,5 +174,30 @@ func (fmt msgFormat) Decode(ctx serde.Context, data []byte) (serde.Message, erro
 		return types.NewSyncAck(), nil
 	}
 
+	if m.RangeRequest != nil {
+		return types.NewRangeRequest(m.RangeRequest.From, m.RangeRequest.To), nil
+	}
+
+	if m.RangeReply != nil {
+		fac := ctx.GetFactory(types.LinkKey{})
+
+		factory, ok := fac.(otypes.LinkFactory)
+		if !ok {
+			return nil, xerrors.Errorf("invalid link factory '%T'", fac)
+		}
+
+		links := make([]otypes.BlockLink, len(m.RangeReply.Links))
+		for i, data := range m.RangeReply.Links {
+			link, err := factory.BlockLinkOf(ctx, data)
+			if err != nil {
+				return nil, xerrors.Errorf("couldn't decode link: %v", err)
+			}
+
+			links[i] = link
+		}
+
+		return types.NewRangeReply(links), nil
+	}
+
 	return nil, xerrors.New("message is empty")
 }
@@ -31,6 +31,14 @@ func TestMsgFormat_Encode(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, `{"Ack":{}}`, string(data))
 
+	data, err = format.Encode(ctx, types.NewRangeRequest(2, 5))
+	require.NoError(t, err)
+	require.Equal(t, `{"RangeRequest":{"From":2,"To":5}}`, string(data))
+
+	data, err = format.Encode(ctx, types.NewRangeReply([]otypes.BlockLink{fakeLink{}, fakeLink{}}))
+	require.NoError(t, err)
+	require.Equal(t, `{"RangeReply":{"Links":[{},{}]}}`, string(data))
+
 	_, err = format.Encode(ctx, fake.Message{})
 	require.EqualError(t, err, "unsupported message 'fake.Message'")
 
@@ -40,6 +48,9 @@ func TestMsgFormat_Encode(t *testing.T) {
 	_, err = format.Encode(ctx, types.NewSyncReply(fakeLink{err: fake.GetError()}))
 	require.EqualError(t, err, fake.Err("link serialization failed"))
 
+	_, err = format.Encode(ctx, types.NewRangeReply([]otypes.BlockLink{fakeLink{err: fake.GetError()}}))
+	require.EqualError(t, err, fake.Err("link serialization failed"))
+
 	_, err = format.Encode(fake.NewBadContext(), types.NewSyncAck())
 	require.EqualError(t, err, fake.Err("marshal failed"))
 }
@@ -67,6 +78,14 @@ func TestMsgFormat_Decode(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, types.NewSyncAck(), msg)
 
+	msg, err = format.Decode(ctx, []byte(`{"RangeRequest":{"From":2,"To":5}}`))
+	require.NoError(t, err)
+	require.Equal(t, types.NewRangeRequest(2, 5), msg)
+
+	msg, err = format.Decode(ctx, []byte(`{"RangeReply":{"Links":[{}]}}`))
+	require.NoError(t, err)
+	require.Equal(t, types.NewRangeReply([]otypes.BlockLink{fakeLink{}}), msg)
+
 	_, err = format.Decode(ctx, []byte(`{}`))
 	require.EqualError(t, err, "message is empty")
 
@@ -85,9 +104,15 @@ func TestMsgFormat_Decode(t *testing.T) {
 	_, err = format.Decode(ctx, []byte(`{"Reply":{"Link":{}}}`))
 	require.EqualError(t, err, fake.Err("couldn't decode link"))
 
+	_, err = format.Decode(ctx, []byte(`{"RangeReply":{"Links":[{}]}}`))
+	require.EqualError(t, err, fake.Err("couldn't decode link"))
+
 	ctx = serde.WithFactory(ctx, types.LinkKey{}, fake.MessageFactory{})
 	_, err = format.Decode(ctx, []byte(`{"Reply":{"Link":{}}}`))
 	require.EqualError(t, err, "invalid link factory 'fake.MessageFactory'")
+
+	_, err = format.Decode(ctx, []byte(`{"RangeReply":{}}`))
+	require.EqualError(t, err, "invalid link factory 'fake.MessageFactory'")
 }
 
 // -----------------------------------------------------------------------------
@@ -2,7 +2,9 @@
 //
 // The package also implements a default synchronizer that will send an
 // announcement with the latest known block, and share the chain to the nodes
-// that have fallen behind.
+// that have fallen behind. A node that is far behind requests disjoint ranges of
+// blocks from several members of the roster in parallel, and verifies them
+// against the chain of the announcement before applying them in order.
 //
 // Documentation Last Review: 13.10.2020
 //
@@ -124,6 +124,79 @@ func (m SyncReply) Serialize(ctx serde.Context) ([]byte, error) {
 	return data, nil
 }
 
+// RangeRequest is a message to request the blocks of a range from a peer,
+// which lets a node catch up from several peers in parallel.
+//
+// - implements serde.Message
+type RangeRequest struct {
+	from uint64
+	to   uint64
+}
+
+// NewRangeRequest creates a new request for the blocks from the index, included,
+// to the index, excluded.
+func NewRangeRequest(from, to uint64) RangeRequest {
+	return RangeRequest{
+		from: from,
+		to:   to,
+	}
+}
+
+// GetFrom returns the index of the first block of the range.
+func (m RangeRequest) GetFrom() uint64 {
+	return m.from
+}
+
+// GetTo returns the index after the last block of the range.
+func (m RangeRequest) GetTo() uint64 {
+	return m.to
+}
+
+// Serialize implements serde.Message. It returns the serialized data for this
+// message.
+func (m RangeRequest) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, m)
+	if err != nil {
+		return nil, xerrors.Errorf("encoding failed: %v", err)
+	}
+
+	return data, nil
+}
+
+// RangeReply is a message to send the blocks of a range to a participant.
+//
+// - implements serde.Message
+type RangeReply struct {
+	links []types.BlockLink
+}
+
+// NewRangeReply creates a new range reply.
+func NewRangeReply(links []types.BlockLink) RangeReply {
+	return RangeReply{
+		links: links,
+	}
+}
+
+// GetLinks returns the links to the blocks of the range, in order.
+func (m RangeReply) GetLinks() []types.BlockLink {
+	return append([]types.BlockLink{}, m.links...)
+}
+
+// Serialize implements serde.Message. It returns the serialized data for this
+// message.
+func (m RangeReply) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, m)
+	if err != nil {
+		return nil, xerrors.Errorf("encoding failed: %v", err)
+	}
+
+	return data, nil
+}
+
 // SyncAck is a message sent to confirm a hard synchronization, which is when
 // the node has all the blocks.
 //
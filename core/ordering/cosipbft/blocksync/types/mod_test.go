@@ -80,6 +80,44 @@ func TestSyncReply_Serialize(t *testing.T) {
 	require.EqualError(t, err, fake.Err("encoding failed"))
 }
 
+func TestRangeRequest_Getters(t *testing.T) {
+	m := NewRangeRequest(2, 5)
+
+	require.Equal(t, uint64(2), m.GetFrom())
+	require.Equal(t, uint64(5), m.GetTo())
+}
+
+func TestRangeRequest_Serialize(t *testing.T) {
+	m := NewRangeRequest(2, 5)
+
+	data, err := m.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = m.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("encoding failed"))
+}
+
+func TestRangeReply_GetLinks(t *testing.T) {
+	link, err := types.NewBlockLink(types.Digest{1}, types.Block{})
+	require.NoError(t, err)
+
+	m := NewRangeReply([]types.BlockLink{link})
+
+	require.Equal(t, []types.BlockLink{link}, m.GetLinks())
+}
+
+func TestRangeReply_Serialize(t *testing.T) {
+	m := NewRangeReply(nil)
+
+	data, err := m.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = m.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("encoding failed"))
+}
+
 func TestSyncAck_Serialize(t *testing.T) {
 	m := NewSyncAck()
 
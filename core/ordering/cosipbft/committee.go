@@ -0,0 +1,137 @@
+// This file contains the implementation of the rotation of the committee that
+// signs the blocks at the end of each epoch.
+
+package cosipbft
+
+import (
+	"go.dedis.ch/dela/core/ordering/cosipbft/epoch"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/validation"
+	"golang.org/x/xerrors"
+)
+
+// epochValidation is a validation service that samples the committee of the
+// next epoch after the transactions of the last block of an epoch, so that the
+// leader, the followers and the nodes catching up store the same committee in
+// the state.
+//
+// - implements validation.Service
+type epochValidation struct {
+	validation.Service
+
+	proc *processor
+}
+
+// Validate implements validation.Service. It validates the transactions and
+// then updates the committee when the block ends an epoch. The committee is
+// removed when the epochs are disabled.
+func (v epochValidation) Validate(snap store.Snapshot, index uint64,
+	txs []txn.Transaction) (validation.Result, error) {
+
+	res, err := v.Service.Validate(snap, index, txs)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := epoch.Read(snap, keyEpoch[:])
+	if err != nil {
+		return nil, xerrors.Errorf("epoch: %v", err)
+	}
+
+	if !cfg.IsEnabled() {
+		err = v.clearCommittee(snap)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to clear committee: %v", err)
+		}
+
+		return res, nil
+	}
+
+	if !cfg.IsBoundary(index) {
+		return res, nil
+	}
+
+	err = v.sampleCommittee(snap, index, cfg)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to sample committee: %v", err)
+	}
+
+	return res, nil
+}
+
+func (v epochValidation) clearCommittee(snap store.Snapshot) error {
+	value, err := snap.Get(keyCommittee[:])
+	if err != nil {
+		return xerrors.Errorf("read: %v", err)
+	}
+
+	if len(value) == 0 {
+		return nil
+	}
+
+	err = snap.Delete(keyCommittee[:])
+	if err != nil {
+		return xerrors.Errorf("delete: %v", err)
+	}
+
+	return nil
+}
+
+// sampleCommittee samples the committee from the roster after the
+// transactions of the block, with the seed of the block before it.
+func (v epochValidation) sampleCommittee(snap store.Snapshot, index uint64, cfg epoch.Config) error {
+	data, err := snap.Get(keyRoster[:])
+	if err != nil {
+		return xerrors.Errorf("read roster: %v", err)
+	}
+
+	roster, err := v.proc.rosterFac.AuthorityOf(v.proc.context, data)
+	if err != nil {
+		return xerrors.Errorf("decode roster: %v", err)
+	}
+
+	seed, err := v.readSeed(index)
+	if err != nil {
+		return xerrors.Errorf("seed: %v", err)
+	}
+
+	committee := epoch.Sample(roster, seed, int(cfg.Size))
+
+	value, err := committee.Serialize(v.proc.context)
+	if err != nil {
+		return xerrors.Errorf("serialize: %v", err)
+	}
+
+	err = snap.Set(keyCommittee[:], value)
+	if err != nil {
+		return xerrors.Errorf("store: %v", err)
+	}
+
+	return nil
+}
+
+// readSeed returns the seed of the block before the index, or the digest of
+// the genesis block for the first one.
+func (v epochValidation) readSeed(index uint64) ([]byte, error) {
+	if index == 0 {
+		genesis, err := v.proc.genesis.Get()
+		if err != nil {
+			return nil, xerrors.Errorf("read genesis: %v", err)
+		}
+
+		return genesis.GetHash().Bytes(), nil
+	}
+
+	link, err := v.proc.blocks.GetByIndex(index - 1)
+	if err != nil {
+		return nil, xerrors.Errorf("read block: %v", err)
+	}
+
+	seed, err := epoch.Seed(link)
+	if err != nil {
+		return nil, xerrors.Errorf("block %d: %v", index-1, err)
+	}
+
+	return seed, nil
+}
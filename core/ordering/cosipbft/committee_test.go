@@ -0,0 +1,189 @@
+package cosipbft
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/epoch"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto/vrf"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/serde/json"
+)
+
+func TestEpochValidation_Validate(t *testing.T) {
+	val, roster := makeEpochValidation(t)
+
+	// Epochs are disabled.
+	snap := makeEpochSnapshot(t, roster, epoch.Config{})
+
+	_, err := val.Validate(snap, 1, nil)
+	require.NoError(t, err)
+	require.Nil(t, getValue(t, snap, keyCommittee[:]))
+
+	// The block does not end the epoch.
+	snap = makeEpochSnapshot(t, roster, epoch.Config{Length: 2, Size: 4})
+
+	_, err = val.Validate(snap, 0, nil)
+	require.NoError(t, err)
+	require.Nil(t, getValue(t, snap, keyCommittee[:]))
+
+	// The block ends the epoch and the committee is sampled with the seed of
+	// the previous block.
+	_, err = val.Validate(snap, 1, nil)
+	require.NoError(t, err)
+
+	link, err := val.proc.blocks.GetByIndex(0)
+	require.NoError(t, err)
+
+	seed, err := epoch.Seed(link)
+	require.NoError(t, err)
+
+	committee := readCommittee(t, val, snap)
+	require.Equal(t, 4, committee.Len())
+	require.NoError(t, epoch.Verify(roster, seed, 4, committee))
+
+	// The first block ends the epoch and uses the genesis as the seed.
+	snap = makeEpochSnapshot(t, roster, epoch.Config{Length: 1, Size: 4})
+
+	_, err = val.Validate(snap, 0, nil)
+	require.NoError(t, err)
+
+	genesis, err := val.proc.genesis.Get()
+	require.NoError(t, err)
+
+	committee = readCommittee(t, val, snap)
+	require.NoError(t, epoch.Verify(roster, genesis.GetHash().Bytes(), 4, committee))
+
+	// The committee is removed when the epochs are disabled.
+	err = snap.Set(keyEpoch[:], epoch.Config{}.Encode())
+	require.NoError(t, err)
+
+	_, err = val.Validate(snap, 1, nil)
+	require.NoError(t, err)
+	require.Nil(t, getValue(t, snap, keyCommittee[:]))
+}
+
+func TestEpochValidation_Failures_Validate(t *testing.T) {
+	val, roster := makeEpochValidation(t)
+
+	enabled := epoch.Config{Length: 2, Size: 4}
+
+	val.Service = fakeValidation{err: fake.GetError()}
+	_, err := val.Validate(makeEpochSnapshot(t, roster, enabled), 1, nil)
+	require.EqualError(t, err, fake.GetError().Error())
+
+	val.Service = fakeValidation{}
+
+	snap := makeEpochSnapshot(t, roster, enabled, fake.WithKeyError(keyEpoch[:], fake.GetError()))
+	_, err = val.Validate(snap, 1, nil)
+	require.EqualError(t, err, fake.Err("epoch: failed to read config"))
+
+	snap = makeEpochSnapshot(t, roster, epoch.Config{},
+		fake.WithKeyError(keyCommittee[:], fake.GetError()))
+	_, err = val.Validate(snap, 1, nil)
+	require.EqualError(t, err, fake.Err("failed to clear committee: read"))
+
+	snap = makeEpochSnapshot(t, roster, epoch.Config{})
+	snap.ErrDelete = fake.GetError()
+	require.NoError(t, snap.Set(keyCommittee[:], []byte("[]")))
+	_, err = val.Validate(snap, 1, nil)
+	require.EqualError(t, err, fake.Err("failed to clear committee: delete"))
+
+	snap = makeEpochSnapshot(t, roster, enabled, fake.WithKeyError(keyRoster[:], fake.GetError()))
+	_, err = val.Validate(snap, 1, nil)
+	require.EqualError(t, err, fake.Err("failed to sample committee: read roster"))
+
+	val.proc.rosterFac = badRosterFac{}
+	_, err = val.Validate(makeEpochSnapshot(t, roster, enabled), 1, nil)
+	require.EqualError(t, err, fake.Err("failed to sample committee: decode roster"))
+
+	val.proc.rosterFac = authority.NewFactory(fake.AddressFactory{}, fake.PublicKeyFactory{})
+
+	_, err = val.Validate(makeEpochSnapshot(t, roster, enabled), 3, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to sample committee: seed: read block: ")
+
+	proof, err := vrf.NewProof(bytes.Repeat([]byte{0xff}, vrf.ProofSize))
+	require.NoError(t, err)
+
+	block, err := types.NewBlock(simple.NewResult(nil), types.WithIndex(1), types.WithProof(proof))
+	require.NoError(t, err)
+
+	prev, err := val.proc.blocks.Last()
+	require.NoError(t, err)
+
+	link, err := types.NewBlockLink(prev.GetTo(), block)
+	require.NoError(t, err)
+	require.NoError(t, val.proc.blocks.Store(link))
+
+	_, err = val.Validate(makeEpochSnapshot(t, roster, epoch.Config{Length: 1, Size: 4}), 2, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to sample committee: seed: block 1: invalid proof: ")
+
+	val.proc.genesis = blockstore.NewGenesisStore()
+	_, err = val.Validate(makeEpochSnapshot(t, roster, epoch.Config{Length: 1, Size: 4}), 0, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to sample committee: seed: read genesis: ")
+
+	snap = makeEpochSnapshot(t, roster, enabled, fake.WithKeyError(keyCommittee[:], fake.GetError()))
+	_, err = val.Validate(snap, 1, nil)
+	require.EqualError(t, err, fake.Err("failed to sample committee: store"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeEpochValidation(t *testing.T) (epochValidation, authority.Authority) {
+	roster := authority.FromAuthority(fake.NewAuthority(6, fake.NewSigner))
+
+	proc := newProcessor()
+	proc.rosterFac = authority.NewFactory(fake.AddressFactory{}, fake.PublicKeyFactory{})
+	proc.genesis = blockstore.NewGenesisStore()
+	proc.blocks = blockstore.NewInMemory()
+
+	genesis, err := types.NewGenesis(roster)
+	require.NoError(t, err)
+	require.NoError(t, proc.genesis.Set(genesis))
+
+	block, err := types.NewBlock(simple.NewResult(nil))
+	require.NoError(t, err)
+
+	link, err := types.NewBlockLink(genesis.GetHash(), block)
+	require.NoError(t, err)
+	require.NoError(t, proc.blocks.Store(link))
+
+	return epochValidation{Service: fakeValidation{}, proc: proc}, roster
+}
+
+func makeEpochSnapshot(t *testing.T, roster authority.Authority, cfg epoch.Config,
+	opts ...fake.SnapshotOption) *fake.InMemorySnapshot {
+
+	value, err := roster.Serialize(json.NewContext())
+	require.NoError(t, err)
+
+	values := map[string][]byte{
+		string(keyRoster[:]): value,
+		string(keyEpoch[:]):  cfg.Encode(),
+	}
+
+	return fake.NewSnapshot(append([]fake.SnapshotOption{fake.WithValues(values)}, opts...)...)
+}
+
+func getValue(t *testing.T, snap *fake.InMemorySnapshot, key []byte) []byte {
+	value, err := snap.Get(key)
+	require.NoError(t, err)
+
+	return value
+}
+
+func readCommittee(t *testing.T, val epochValidation, snap *fake.InMemorySnapshot) authority.Authority {
+	committee, err := val.proc.rosterFac.AuthorityOf(val.proc.context, getValue(t, snap, keyCommittee[:]))
+	require.NoError(t, err)
+
+	return committee
+}
@@ -0,0 +1,175 @@
+// Package budget implements a native smart contract to set the resource budget
+// of the blocks of a chain.
+//
+// The budget bounds the total cost of the transactions of a block, so that the
+// time to validate a block stays bounded even on slow nodes. It is a parameter
+// of the chain stored in the state, therefore every node agrees on it, and it
+// is updated by the members of the roster. A budget of zero, which is the
+// initial value, does not limit the blocks.
+package budget
+
+import (
+	"encoding/binary"
+	"strconv"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// ContractName is the name of the contract.
+	ContractName = "go.dedis.ch/dela.Budget"
+
+	// LimitArg is the key of the argument for the new budget of a block.
+	LimitArg = "budget:limit"
+
+	// BaseCost is the cost of any transaction, which accounts for its
+	// execution. The size of the transaction is added to it.
+	BaseCost = 1000
+
+	// MinLimit is the minimum budget of a block when it is limited, so that a
+	// block can always hold the transaction that updates the budget.
+	MinLimit = 16 * BaseCost
+
+	messageArgInvalid     = "invalid budget in transaction"
+	messageStorageFailure = "storage failure"
+	messageUnauthorized   = "unauthorized identity"
+)
+
+// RegisterContract registers the budget contract to the given execution
+// service.
+func RegisterContract(exec *native.Service, c Contract) {
+	exec.Set(ContractName, c)
+}
+
+// Cost returns the deterministic cost of a transaction, which is the base cost
+// and the size of its fingerprint.
+func Cost(tx txn.Transaction) uint64 {
+	counter := &byteCounter{}
+
+	err := tx.Fingerprint(counter)
+	if err != nil {
+		// The transaction cannot be accepted anyway.
+		return ^uint64(0)
+	}
+
+	return BaseCost + counter.n
+}
+
+// Read returns the budget stored at the key, or zero if it has never been set.
+func Read(snap store.Readable, key []byte) (uint64, error) {
+	value, err := snap.Get(key)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to read budget: %v", err)
+	}
+
+	if len(value) != 8 {
+		return 0, nil
+	}
+
+	return binary.LittleEndian.Uint64(value), nil
+}
+
+// Manager is an extension of a normal transaction manager to help creating
+// budget ones.
+type Manager struct {
+	manager txn.Manager
+}
+
+// NewManager returns a budget manager from the transaction manager.
+func NewManager(mgr txn.Manager) Manager {
+	return Manager{
+		manager: mgr,
+	}
+}
+
+// Make creates a new transaction using the provided manager. It contains the
+// new budget that the transaction should apply.
+func (mgr Manager) Make(limit uint64) (txn.Transaction, error) {
+	tx, err := mgr.manager.Make(
+		txn.Arg{Key: native.ContractArg, Value: []byte(ContractName)},
+		txn.Arg{Key: LimitArg, Value: []byte(strconv.FormatUint(limit, 10))},
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("creating transaction: %v", err)
+	}
+
+	return tx, nil
+}
+
+// Contract is a contract to update the budget at a given key in the storage.
+// The identities allowed to update it are the ones matching the credential,
+// e.g. the members allowed to change the roster.
+//
+// - implements native.Contract
+type Contract struct {
+	key    []byte
+	creds  access.Credential
+	access access.Service
+}
+
+// NewContract creates a new budget contract.
+func NewContract(key []byte, creds access.Credential, srvc access.Service) Contract {
+	return Contract{
+		key:    key,
+		creds:  creds,
+		access: srvc,
+	}
+}
+
+// Execute implements native.Contract. It updates the budget of the next blocks
+// if the identity of the transaction is allowed to.
+func (c Contract) Execute(snap store.Snapshot, step execution.Step) error {
+	limit, err := strconv.ParseUint(string(step.Current.GetArg(LimitArg)), 10, 64)
+	if err != nil {
+		return xerrors.New(messageArgInvalid)
+	}
+
+	if limit > 0 && limit < MinLimit {
+		return xerrors.Errorf("budget below the minimum: %d < %d", limit, MinLimit)
+	}
+
+	err = c.access.Match(snap, c.creds, step.Current.GetIdentity())
+	if err != nil {
+		reportErr(step.Current, xerrors.Errorf("access control: %v", err))
+
+		return xerrors.Errorf("%s: %v", messageUnauthorized, step.Current.GetIdentity())
+	}
+
+	buffer := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buffer, limit)
+
+	err = snap.Set(c.key, buffer)
+	if err != nil {
+		reportErr(step.Current, xerrors.Errorf("writing store: %v", err))
+
+		return xerrors.New(messageStorageFailure)
+	}
+
+	return nil
+}
+
+// reportErr prints a log with the actual error while the transaction will
+// contain a simplified explanation.
+func reportErr(tx txn.Transaction, err error) {
+	dela.Logger.Warn().
+		Hex("ID", tx.GetID()).
+		Err(err).
+		Msg("transaction refused")
+}
+
+// byteCounter is a writer that counts the bytes written.
+type byteCounter struct {
+	n uint64
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	c.n += uint64(len(p))
+
+	return len(p), nil
+}
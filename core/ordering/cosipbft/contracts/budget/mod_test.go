@@ -0,0 +1,132 @@
+package budget
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestRegisterContract(t *testing.T) {
+	srvc := native.NewExecution()
+
+	RegisterContract(srvc, Contract{})
+
+	require.NoError(t, srvc.IsServed(ContractName))
+}
+
+func TestNewTransaction(t *testing.T) {
+	mgr := NewManager(signed.NewManager(fake.NewSigner(), nil))
+
+	tx, err := mgr.Make(20000)
+	require.NoError(t, err)
+	require.Equal(t, "20000", string(tx.GetArg(LimitArg)))
+
+	mgr.manager = badManager{}
+	_, err = mgr.Make(20000)
+	require.EqualError(t, err, fake.Err("creating transaction"))
+}
+
+func TestCost(t *testing.T) {
+	tx := makeTx(t, "20000")
+
+	cost := Cost(tx)
+	require.Greater(t, cost, uint64(BaseCost))
+
+	// The cost grows with the size of the transaction.
+	require.Equal(t, cost+3, Cost(makeTx(t, "20000000")))
+
+	require.Equal(t, ^uint64(0), Cost(badTx{Transaction: tx}))
+}
+
+func TestRead(t *testing.T) {
+	snap := fake.NewSnapshot()
+
+	limit, err := Read(snap, []byte("budget"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), limit)
+
+	contract := NewContract([]byte("budget"), nil, fakeAccess{})
+
+	err = contract.Execute(snap, makeStep(t, "20000"))
+	require.NoError(t, err)
+
+	limit, err = Read(snap, []byte("budget"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(20000), limit)
+
+	_, err = Read(fake.NewBadSnapshot(), []byte("budget"))
+	require.EqualError(t, err, fake.Err("failed to read budget"))
+}
+
+func TestContract_Execute(t *testing.T) {
+	contract := NewContract([]byte("budget"), nil, fakeAccess{})
+
+	err := contract.Execute(fake.NewSnapshot(), makeStep(t, "0"))
+	require.NoError(t, err)
+
+	err = contract.Execute(fake.NewSnapshot(), makeStep(t, "abc"))
+	require.EqualError(t, err, messageArgInvalid)
+
+	err = contract.Execute(fake.NewSnapshot(), makeStep(t, "10"))
+	require.EqualError(t, err, "budget below the minimum: 10 < 16000")
+
+	err = contract.Execute(fake.NewBadSnapshot(), makeStep(t, "20000"))
+	require.EqualError(t, err, messageStorageFailure)
+
+	contract.access = fakeAccess{err: fake.GetError()}
+	err = contract.Execute(fake.NewSnapshot(), makeStep(t, "20000"))
+	require.EqualError(t, err, "unauthorized identity: fake.PublicKey")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeStep(t *testing.T, arg string) execution.Step {
+	return execution.Step{Current: makeTx(t, arg)}
+}
+
+func makeTx(t *testing.T, arg string) txn.Transaction {
+	args := []signed.TransactionOption{
+		signed.WithArg(LimitArg, []byte(arg)),
+		signed.WithArg(native.ContractArg, []byte(ContractName)),
+	}
+
+	tx, err := signed.NewTransaction(0, fake.PublicKey{}, args...)
+	require.NoError(t, err)
+
+	return tx
+}
+
+type badTx struct {
+	txn.Transaction
+}
+
+func (badTx) Fingerprint(io.Writer) error {
+	return fake.GetError()
+}
+
+type badManager struct {
+	txn.Manager
+}
+
+func (badManager) Make(opts ...txn.Arg) (txn.Transaction, error) {
+	return nil, fake.GetError()
+}
+
+type fakeAccess struct {
+	access.Service
+
+	err error
+}
+
+func (srvc fakeAccess) Match(store.Readable, access.Credential, ...access.Identity) error {
+	return srvc.err
+}
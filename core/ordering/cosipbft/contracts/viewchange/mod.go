@@ -6,11 +6,14 @@
 package viewchange
 
 import (
+	"strconv"
+
 	"go.dedis.ch/dela"
 	"go.dedis.ch/dela/core/access"
 	"go.dedis.ch/dela/core/execution"
 	"go.dedis.ch/dela/core/execution/native"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/epoch"
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/serde"
@@ -25,6 +28,14 @@ const (
 	// AuthorityArg is the key of the argument for the new authority.
 	AuthorityArg = "viewchange:authority"
 
+	// EpochLengthArg is the key of the argument for the number of blocks of an
+	// epoch.
+	EpochLengthArg = "viewchange:epoch_length"
+
+	// EpochSizeArg is the key of the argument for the size of the committee of
+	// an epoch.
+	EpochSizeArg = "viewchange:epoch_size"
+
 	messageOnlyOne          = "only one view change per block is allowed"
 	messageArgMissing       = "authority not found in transaction"
 	messageStorageEmpty     = "authority not found in storage"
@@ -33,6 +44,8 @@ const (
 	messageStorageFailure   = "storage failure"
 	messageDuplicate        = "duplicate in roster"
 	messageUnauthorized     = "unauthorized identity"
+	messageEpochInvalid     = "invalid epoch in transaction"
+	messageEpochDisabled    = "epochs are not supported"
 )
 
 // RegisterContract registers the view change contract to the given execution
@@ -80,14 +93,31 @@ func (mgr Manager) Make(roster authority.Authority) (txn.Transaction, error) {
 	return tx, nil
 }
 
+// MakeEpoch creates a new transaction using the provided manager. It contains
+// the configuration of the epochs that the transaction should apply.
+func (mgr Manager) MakeEpoch(cfg epoch.Config) (txn.Transaction, error) {
+	tx, err := mgr.manager.Make(
+		txn.Arg{Key: native.ContractArg, Value: []byte(ContractName)},
+		txn.Arg{Key: EpochLengthArg, Value: []byte(strconv.FormatUint(cfg.Length, 10))},
+		txn.Arg{Key: EpochSizeArg, Value: []byte(strconv.FormatUint(uint64(cfg.Size), 10))},
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("creating transaction: %v", err)
+	}
+
+	return tx, nil
+}
+
 // Contract is a contract to update the roster at a given key in the storage. It
-// only allows one member change per transaction.
+// only allows one member change per transaction. It also updates the
+// configuration of the epochs when a key is set for it.
 //
 // - implements native.Contract
 type Contract struct {
 	rosterKey []byte
 	rosterFac authority.Factory
 	accessKey []byte
+	epochKey  []byte
 	access    access.Service
 	context   serde.Context
 }
@@ -103,14 +133,27 @@ func NewContract(rKey, aKey []byte, rFac authority.Factory, srvc access.Service)
 	}
 }
 
+// WithEpochs returns a copy of the contract that stores the configuration of
+// the epochs at the given key.
+func (c Contract) WithEpochs(key []byte) Contract {
+	c.epochKey = key
+
+	return c
+}
+
 // Execute implements native.Contract. It looks for the roster in the
 // transaction and updates the storage if there is at most one membership
-// change.
+// change. The replacement of the public key of a member counts as one change.
+// A transaction with the configuration of the epochs updates it instead.
 func (c Contract) Execute(snap store.Snapshot, step execution.Step) error {
+	if step.Current.GetArg(EpochLengthArg) != nil {
+		return c.executeEpoch(snap, step)
+	}
+
 	for _, tx := range step.Previous {
 		// Only one view change transaction is allowed per block to prevent
 		// malicious peers to reach the threshold.
-		if string(tx.GetArg(native.ContractArg)) == ContractName {
+		if string(tx.GetArg(native.ContractArg)) == ContractName && tx.GetArg(AuthorityArg) != nil {
 			return xerrors.New(messageOnlyOne)
 		}
 	}
@@ -168,6 +211,49 @@ func (c Contract) Execute(snap store.Snapshot, step execution.Step) error {
 	return nil
 }
 
+// executeEpoch updates the configuration of the epochs, which is used to sample
+// the next committee. A length and a size of zero disable them.
+func (c Contract) executeEpoch(snap store.Snapshot, step execution.Step) error {
+	if c.epochKey == nil {
+		return xerrors.New(messageEpochDisabled)
+	}
+
+	length, err := strconv.ParseUint(string(step.Current.GetArg(EpochLengthArg)), 10, 64)
+	if err != nil {
+		return xerrors.New(messageEpochInvalid)
+	}
+
+	size, err := strconv.ParseUint(string(step.Current.GetArg(EpochSizeArg)), 10, 32)
+	if err != nil {
+		return xerrors.New(messageEpochInvalid)
+	}
+
+	if (length == 0) != (size == 0) {
+		return xerrors.Errorf("%s: length and size must be both zero or positive",
+			messageEpochInvalid)
+	}
+
+	creds := NewCreds(c.accessKey)
+
+	err = c.access.Match(snap, creds, step.Current.GetIdentity())
+	if err != nil {
+		reportErr(step.Current, xerrors.Errorf("access control: %v", err))
+
+		return xerrors.Errorf("%s: %v", messageUnauthorized, step.Current.GetIdentity())
+	}
+
+	cfg := epoch.Config{Length: length, Size: uint32(size)}
+
+	err = snap.Set(c.epochKey, cfg.Encode())
+	if err != nil {
+		reportErr(step.Current, xerrors.Errorf("writing store: %v", err))
+
+		return xerrors.New(messageStorageFailure)
+	}
+
+	return nil
+}
+
 // reportErr prints a log with the actual error while the transaction will
 // contain a simplified explanation.
 func reportErr(tx txn.Transaction, err error) {
@@ -8,6 +8,7 @@ import (
 	"go.dedis.ch/dela/core/execution"
 	"go.dedis.ch/dela/core/execution/native"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/epoch"
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/txn/signed"
@@ -37,6 +38,19 @@ func TestNewTransaction(t *testing.T) {
 	require.EqualError(t, err, fake.Err("creating transaction"))
 }
 
+func TestNewTransaction_Epoch(t *testing.T) {
+	mgr := NewManager(signed.NewManager(fake.NewSigner(), nil))
+
+	tx, err := mgr.MakeEpoch(epoch.Config{Length: 100, Size: 7})
+	require.NoError(t, err)
+	require.Equal(t, "100", string(tx.GetArg(EpochLengthArg)))
+	require.Equal(t, "7", string(tx.GetArg(EpochSizeArg)))
+
+	mgr.manager = badManager{}
+	_, err = mgr.MakeEpoch(epoch.Config{})
+	require.EqualError(t, err, fake.Err("creating transaction"))
+}
+
 func TestContract_Execute(t *testing.T) {
 	fac := authority.NewFactory(fake.AddressFactory{}, fake.PublicKeyFactory{})
 
@@ -45,7 +59,9 @@ func TestContract_Execute(t *testing.T) {
 	err := contract.Execute(fakeStore{}, makeStep(t, "[]"))
 	require.NoError(t, err)
 
-	err = contract.Execute(fakeStore{}, execution.Step{Previous: []txn.Transaction{makeTx(t, "")}})
+	step := makeStep(t, "[]")
+	step.Previous = []txn.Transaction{makeTx(t, "")}
+	err = contract.Execute(fakeStore{}, step)
 	require.EqualError(t, err, "only one view change per block is allowed")
 
 	contract.rosterFac = badRosterFac{}
@@ -75,9 +91,64 @@ func TestContract_Execute(t *testing.T) {
 	require.EqualError(t, err, "unauthorized identity: fake.PublicKey")
 }
 
+func TestContract_Epoch_Execute(t *testing.T) {
+	fac := authority.NewFactory(fake.AddressFactory{}, fake.PublicKeyFactory{})
+
+	contract := NewContract([]byte("roster"), []byte("access"), fac, fakeAccess{})
+
+	err := contract.Execute(fakeStore{}, makeEpochStep(t, "10", "4"))
+	require.EqualError(t, err, messageEpochDisabled)
+
+	contract = contract.WithEpochs([]byte("epoch"))
+
+	snap := &recordStore{}
+	err = contract.Execute(snap, makeEpochStep(t, "10", "4"))
+	require.NoError(t, err)
+	require.Equal(t, epoch.Config{Length: 10, Size: 4}.Encode(), snap.value)
+
+	// A roster change is still allowed after the configuration.
+	step := makeStep(t, "[]")
+	step.Previous = []txn.Transaction{makeEpochStep(t, "10", "4").Current}
+	err = contract.Execute(fakeStore{}, step)
+	require.NoError(t, err)
+
+	err = contract.Execute(fakeStore{}, makeEpochStep(t, "0", "0"))
+	require.NoError(t, err)
+
+	err = contract.Execute(fakeStore{}, makeEpochStep(t, "abc", "4"))
+	require.EqualError(t, err, messageEpochInvalid)
+
+	err = contract.Execute(fakeStore{}, makeEpochStep(t, "10", "-1"))
+	require.EqualError(t, err, messageEpochInvalid)
+
+	err = contract.Execute(fakeStore{}, makeEpochStep(t, "10", "0"))
+	require.EqualError(t, err,
+		"invalid epoch in transaction: length and size must be both zero or positive")
+
+	err = contract.Execute(fakeStore{errSet: fake.GetError()}, makeEpochStep(t, "10", "4"))
+	require.EqualError(t, err, messageStorageFailure)
+
+	contract.access = fakeAccess{err: fake.GetError()}
+	err = contract.Execute(fakeStore{}, makeEpochStep(t, "10", "4"))
+	require.EqualError(t, err, "unauthorized identity: fake.PublicKey")
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
+func makeEpochStep(t *testing.T, length, size string) execution.Step {
+	args := []signed.TransactionOption{
+		signed.WithArg(EpochLengthArg, []byte(length)),
+		signed.WithArg(EpochSizeArg, []byte(size)),
+		signed.WithArg(native.ContractArg, []byte(ContractName)),
+	}
+
+	tx, err := signed.NewTransaction(0, fake.PublicKey{}, args...)
+	require.NoError(t, err)
+
+	return execution.Step{Current: tx}
+}
+
 func makeStep(t *testing.T, arg string) execution.Step {
 	return execution.Step{Current: makeTx(t, arg)}
 }
@@ -148,3 +219,15 @@ type fakeAccess struct {
 func (srvc fakeAccess) Match(store.Readable, access.Credential, ...access.Identity) error {
 	return srvc.err
 }
+
+type recordStore struct {
+	fakeStore
+
+	value []byte
+}
+
+func (snap *recordStore) Set(key, value []byte) error {
+	snap.value = value
+
+	return nil
+}
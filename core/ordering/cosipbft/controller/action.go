@@ -10,17 +10,24 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"math"
 	"strings"
 
 	"go.dedis.ch/dela"
 	"go.dedis.ch/dela/cli/node"
 	"go.dedis.ch/dela/core/ordering"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/budget"
 	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/viewchange"
+	"go.dedis.ch/dela/core/ordering/cosipbft/epoch"
+	"go.dedis.ch/dela/core/ordering/cosipbft/scaling"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/txn/pool"
 	"go.dedis.ch/dela/cosi"
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/common"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/mino"
 	"golang.org/x/xerrors"
 )
@@ -72,20 +79,65 @@ func (a setupAction) Execute(ctx node.Context) error {
 func (a setupAction) readMembers(ctx node.Context) (authority.Authority, error) {
 	members := ctx.Flags.StringSlice("member")
 
+	source := ctx.Flags.String("roster")
+	if source != "" {
+		if len(members) > 0 {
+			return nil, xerrors.New("flags 'member' and 'roster' are exclusive")
+		}
+
+		return a.loadRoster(ctx, source)
+	}
+
 	addrs := make([]mino.Address, len(members))
 	pubkeys := make([]crypto.PublicKey, len(members))
+	vrfkeys := make([]vrf.PublicKey, len(members))
+	txkeys := make([]crypto.PublicKey, len(members))
 
-	for i, member := range members {
-		addr, pubkey, err := decodeMember(ctx, member)
+	for i, str := range members {
+		m, err := decodeMember(ctx, str)
 		if err != nil {
 			return nil, xerrors.Errorf("failed to decode: %v", err)
 		}
 
-		addrs[i] = addr
-		pubkeys[i] = pubkey
+		addrs[i] = m.addr
+		pubkeys[i] = m.pubkey
+		vrfkeys[i] = m.vrfkey
+		txkeys[i] = m.txkey
 	}
 
-	return authority.New(addrs, pubkeys), nil
+	return authority.New(addrs, pubkeys).WithVRFKeys(vrfkeys).WithTxKeys(txkeys), nil
+}
+
+// loadRoster reads the list of members from the file, or fetches it when the
+// source is the URL of a key server.
+func (a setupAction) loadRoster(ctx node.Context, source string) (authority.Authority, error) {
+	var m mino.Mino
+	err := ctx.Injector.Resolve(&m)
+	if err != nil {
+		return nil, xerrors.Errorf("injector: %v", err)
+	}
+
+	var c cosi.CollectiveSigning
+	err = ctx.Injector.Resolve(&c)
+	if err != nil {
+		return nil, xerrors.Errorf("injector: %v", err)
+	}
+
+	loader := authority.NewLoader(m.GetAddressFactory(), c.GetPublicKeyFactory())
+
+	var roster authority.Roster
+
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		roster, err = loader.FromURL(context.Background(), source)
+	} else {
+		roster, err = loader.FromFile(source)
+	}
+
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load: %v", err)
+	}
+
+	return roster, nil
 }
 
 // ExportAction is an action to display a base64 string describing the node. It
@@ -94,8 +146,9 @@ func (a setupAction) readMembers(ctx node.Context) (authority.Authority, error)
 // - implements node.ActionTemplate
 type exportAction struct{}
 
-// Execute implements node.ActionTemplate. It looks for the node address and
-// public key and prints "$ADDR_BASE64:$PUBLIC_KEY_BASE64".
+// Execute implements node.ActionTemplate. It looks for the node address, the
+// public key, the key of the leader election and the key of the transactions,
+// and prints "$ADDR_BASE64:$PUBLIC_KEY_BASE64:$VRF_KEY_BASE64:$TX_KEY_BASE64".
 func (a exportAction) Execute(ctx node.Context) error {
 	var m mino.Mino
 	err := ctx.Injector.Resolve(&m)
@@ -119,14 +172,119 @@ func (a exportAction) Execute(ctx node.Context) error {
 		return xerrors.Errorf("failed to marshal public key: %v", err)
 	}
 
+	var vrfSigner vrf.Signer
+	err = ctx.Injector.Resolve(&vrfSigner)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	vrfkey, err := vrfSigner.GetPublicKey().MarshalBinary()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal vrf key: %v", err)
+	}
+
+	var txSigner crypto.TransactionSigner
+	err = ctx.Injector.Resolve(&txSigner)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	txkey, err := txSigner.GetPublicKey().MarshalBinary()
+	if err != nil {
+		return xerrors.Errorf("failed to marshal tx key: %v", err)
+	}
+
 	desc := base64.StdEncoding.EncodeToString(addr) + separator +
-		base64.StdEncoding.EncodeToString(pubkey)
+		base64.StdEncoding.EncodeToString(pubkey) + separator +
+		base64.StdEncoding.EncodeToString(vrfkey) + separator +
+		base64.StdEncoding.EncodeToString(txkey)
 
 	fmt.Fprint(ctx.Out, desc)
 
 	return nil
 }
 
+// heightAction is an action to print the height of the chain.
+//
+// - implements node.ActionTemplate
+type heightAction struct{}
+
+// Execute implements node.ActionTemplate. It prints the number of blocks of the
+// chain known by the node.
+func (heightAction) Execute(ctx node.Context) error {
+	var blocks blockstore.BlockStore
+	err := ctx.Injector.Resolve(&blocks)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "%d", blocks.Len())
+
+	return nil
+}
+
+// pruneBatch is the number of blocks pruned at once, so that the progress of
+// a long pruning is reported, and so that an interrupted one keeps the blocks
+// pruned so far.
+const pruneBatch = 1000
+
+// pruneAction is an action to discard the old blocks of the chain while
+// keeping their forward links.
+//
+// - implements node.ActionTemplate
+type pruneAction struct{}
+
+// Execute implements node.ActionTemplate. It prunes the blocks of the node
+// except the given number of latest ones. The blocks are pruned by batches in
+// an operation that reports the number of blocks pruned so far.
+func (pruneAction) Execute(ctx node.Context) error {
+	var blocks blockstore.BlockStore
+	err := ctx.Injector.Resolve(&blocks)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	pruner, ok := blocks.(blockstore.Pruner)
+	if !ok {
+		return xerrors.Errorf("block store '%T' does not support pruning", blocks)
+	}
+
+	keep := ctx.Flags.Int("keep")
+	if keep < 1 {
+		return xerrors.Errorf("invalid number of blocks to keep: %d", keep)
+	}
+
+	return ctx.Operations.Run(ctx, "prune", func(r node.Reporter) (string, error) {
+		length := blocks.Len()
+
+		start := pruner.GetPruned()
+		before := start
+		if length > uint64(keep) && length-uint64(keep) > start {
+			before = length - uint64(keep)
+		}
+
+		total := uint64(0)
+
+		for index := start; index < before; index += pruneBatch {
+			end := index + pruneBatch
+			if end > before {
+				end = before
+			}
+
+			n, err := pruner.Prune(end)
+			if err != nil {
+				return "", xerrors.Errorf("failed to prune: %v", err)
+			}
+
+			total += n
+			r.Report(end-start, before-start)
+		}
+
+		return fmt.Sprintf("%d block(s) pruned, the chain starts at block %d", total,
+			pruner.GetPruned()), nil
+	})
+}
+
 // RosterAddAction is an action to require a roster change in the change by
 // adding a new member.
 //
@@ -147,8 +305,229 @@ func (rosterAddAction) Execute(ctx node.Context) error {
 		return xerrors.Errorf("while preparing tx: %v", err)
 	}
 
+	return submit(ctx, srvc, tx)
+}
+
+// budgetSetAction is an action to require a change of the budget of the
+// blocks.
+//
+// - implements node.ActionTemplate
+type budgetSetAction struct{}
+
+// Execute implements node.ActionTemplate. It reads the new budget and sends a
+// transaction to require the change.
+func (budgetSetAction) Execute(ctx node.Context) error {
+	var srvc Service
+	err := ctx.Injector.Resolve(&srvc)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	limit := ctx.Flags.Int("limit")
+	if limit < 0 {
+		return xerrors.Errorf("negative limit: %d", limit)
+	}
+
+	mgr, err := makeManager(ctx)
+	if err != nil {
+		return xerrors.Errorf("txn manager: %v", err)
+	}
+
+	tx, err := budget.NewManager(mgr).Make(uint64(limit))
+	if err != nil {
+		return xerrors.Errorf("transaction: %v", err)
+	}
+
+	return submit(ctx, srvc, tx)
+}
+
+// epochSetAction is an action to update the configuration of the epochs of the
+// chain.
+//
+// - implements node.ActionTemplate
+type epochSetAction struct{}
+
+// Execute implements node.ActionTemplate. It reads the new configuration and
+// sends a transaction to require the change.
+func (epochSetAction) Execute(ctx node.Context) error {
+	var srvc Service
+	err := ctx.Injector.Resolve(&srvc)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	length := ctx.Flags.Int("length")
+	size := ctx.Flags.Int("size")
+
+	if length < 0 || size < 0 || uint64(size) > math.MaxUint32 {
+		return xerrors.Errorf("invalid epoch: length %d, size %d", length, size)
+	}
+
+	mgr, err := makeManager(ctx)
+	if err != nil {
+		return xerrors.Errorf("txn manager: %v", err)
+	}
+
+	cfg := epoch.Config{Length: uint64(length), Size: uint32(size)}
+
+	tx, err := viewchange.NewManager(mgr).MakeEpoch(cfg)
+	if err != nil {
+		return xerrors.Errorf("transaction: %v", err)
+	}
+
+	return submit(ctx, srvc, tx)
+}
+
+// scalingCandidateAction is an action to register a candidate that the scaling
+// policy can propose to add to the roster.
+//
+// - implements node.ActionTemplate
+type scalingCandidateAction struct{}
+
+// Execute implements node.ActionTemplate. It reads the member and registers it
+// as a candidate.
+func (scalingCandidateAction) Execute(ctx node.Context) error {
+	var policy *scaling.Policy
+	err := ctx.Injector.Resolve(&policy)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	m, err := decodeMember(ctx, ctx.Flags.String("member"))
+	if err != nil {
+		return xerrors.Errorf("failed to decode member: %v", err)
+	}
+
+	err = policy.Register(scaling.Candidate{
+		Address:   m.addr,
+		PublicKey: m.pubkey,
+		VRFKey:    m.vrfkey,
+		TxKey:     m.txkey,
+	})
+	if err != nil {
+		return xerrors.Errorf("failed to register: %v", err)
+	}
+
+	return nil
+}
+
+// scalingListAction is an action to print the availability of the members,
+// the candidates and the pending proposals of the scaling policy.
+//
+// - implements node.ActionTemplate
+type scalingListAction struct{}
+
+// Execute implements node.ActionTemplate. It prints one line per member, per
+// candidate and per proposal.
+func (scalingListAction) Execute(ctx node.Context) error {
+	var policy *scaling.Policy
+	err := ctx.Injector.Resolve(&policy)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var srvc Service
+	err = ctx.Injector.Resolve(&srvc)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	roster, err := srvc.GetRoster()
+	if err != nil {
+		return xerrors.Errorf("failed to read roster: %v", err)
+	}
+
+	for _, m := range policy.GetMembers(roster) {
+		fmt.Fprintf(ctx.Out, "member %v: availability %.2f over %d blocks\n",
+			m.Address, m.Availability, m.Samples)
+	}
+
+	for _, c := range policy.GetCandidates() {
+		fmt.Fprintf(ctx.Out, "candidate %v\n", c.Address)
+	}
+
+	for _, p := range policy.GetProposals() {
+		fmt.Fprintf(ctx.Out, "proposal %v\n", p)
+	}
+
+	return nil
+}
+
+// scalingApproveAction is an action to approve a proposal of the scaling
+// policy, which sends the transaction of the roster change.
+//
+// - implements node.ActionTemplate
+type scalingApproveAction struct{}
+
+// Execute implements node.ActionTemplate. It approves the proposal and sends a
+// transaction to apply it to the current roster.
+func (scalingApproveAction) Execute(ctx node.Context) error {
+	var policy *scaling.Policy
+	err := ctx.Injector.Resolve(&policy)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var srvc Service
+	err = ctx.Injector.Resolve(&srvc)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	roster, err := srvc.GetRoster()
+	if err != nil {
+		return xerrors.Errorf("failed to read roster: %v", err)
+	}
+
+	prop, err := policy.Approve(uint64(ctx.Flags.Int("id")))
+	if err != nil {
+		return xerrors.Errorf("failed to approve: %v", err)
+	}
+
+	cset, err := prop.ChangeSet(roster)
+	if err != nil {
+		return xerrors.Errorf("stale proposal: %v", err)
+	}
+
+	mgr, err := makeManager(ctx)
+	if err != nil {
+		return xerrors.Errorf("txn manager: %v", err)
+	}
+
+	tx, err := viewchange.NewManager(mgr).Make(roster.Apply(cset))
+	if err != nil {
+		return xerrors.Errorf("transaction: %v", err)
+	}
+
+	return submit(ctx, srvc, tx)
+}
+
+// scalingRejectAction is an action to reject a proposal of the scaling policy.
+//
+// - implements node.ActionTemplate
+type scalingRejectAction struct{}
+
+// Execute implements node.ActionTemplate. It discards the proposal.
+func (scalingRejectAction) Execute(ctx node.Context) error {
+	var policy *scaling.Policy
+	err := ctx.Injector.Resolve(&policy)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	err = policy.Reject(uint64(ctx.Flags.Int("id")))
+	if err != nil {
+		return xerrors.Errorf("failed to reject: %v", err)
+	}
+
+	return nil
+}
+
+// submit adds the transaction to the pool and, if asked to, waits for it to be
+// included in a block.
+func submit(ctx node.Context, srvc Service, tx txn.Transaction) error {
 	var p pool.Pool
-	err = ctx.Injector.Resolve(&p)
+	err := ctx.Injector.Resolve(&p)
 	if err != nil {
 		return xerrors.Errorf("injector: %v", err)
 	}
@@ -203,13 +582,17 @@ func prepareRosterTx(ctx node.Context, srvc Service) (txn.Transaction, error) {
 		return nil, xerrors.Errorf("failed to read roster: %v", err)
 	}
 
-	addr, pubkey, err := decodeMember(ctx, ctx.Flags.String("member"))
+	m, err := decodeMember(ctx, ctx.Flags.String("member"))
 	if err != nil {
 		return nil, xerrors.Errorf("failed to decode member: %v", err)
 	}
 
-	cset := authority.NewChangeSet()
-	cset.Add(addr, pubkey)
+	cset, err := authority.NewChangeSetBuilder(roster).
+		AddWithKeys(m.addr, m.pubkey, 1, m.vrfkey, m.txkey).
+		Build()
+	if err != nil {
+		return nil, xerrors.Errorf("invalid change: %v", err)
+	}
 
 	mgr, err := makeManager(ctx)
 	if err != nil {
@@ -241,22 +624,31 @@ func makeManager(ctx node.Context) (txn.Manager, error) {
 	return mgr, nil
 }
 
-func decodeMember(ctx node.Context, str string) (mino.Address, crypto.PublicKey, error) {
+// member is the description of a participant of the chain. The key of the
+// leader election and the key of the transactions are optional.
+type member struct {
+	addr   mino.Address
+	pubkey crypto.PublicKey
+	vrfkey vrf.PublicKey
+	txkey  crypto.PublicKey
+}
+
+func decodeMember(ctx node.Context, str string) (member, error) {
 	parts := strings.Split(str, separator)
-	if len(parts) != 2 {
-		return nil, nil, xerrors.New("invalid member base64 string")
+	if len(parts) < 2 || len(parts) > 4 {
+		return member{}, xerrors.New("invalid member base64 string")
 	}
 
 	// 1. Deserialize the address.
 	var m mino.Mino
 	err := ctx.Injector.Resolve(&m)
 	if err != nil {
-		return nil, nil, xerrors.Errorf("injector: %v", err)
+		return member{}, xerrors.Errorf("injector: %v", err)
 	}
 
 	addrBuf, err := base64.StdEncoding.DecodeString(parts[0])
 	if err != nil {
-		return nil, nil, xerrors.Errorf("base64 address: %v", err)
+		return member{}, xerrors.Errorf("base64 address: %v", err)
 	}
 
 	addr := m.GetAddressFactory().FromText(addrBuf)
@@ -265,18 +657,53 @@ func decodeMember(ctx node.Context, str string) (mino.Address, crypto.PublicKey,
 	var c cosi.CollectiveSigning
 	err = ctx.Injector.Resolve(&c)
 	if err != nil {
-		return nil, nil, xerrors.Errorf("injector: %v", err)
+		return member{}, xerrors.Errorf("injector: %v", err)
 	}
 
 	pubkeyBuf, err := base64.StdEncoding.DecodeString(parts[1])
 	if err != nil {
-		return nil, nil, xerrors.Errorf("base64 public key: %v", err)
+		return member{}, xerrors.Errorf("base64 public key: %v", err)
 	}
 
 	pubkey, err := c.GetPublicKeyFactory().FromBytes(pubkeyBuf)
 	if err != nil {
-		return nil, nil, xerrors.Errorf("failed to decode public key: %v", err)
+		return member{}, xerrors.Errorf("failed to decode public key: %v", err)
+	}
+
+	desc := member{
+		addr:   addr,
+		pubkey: pubkey,
+	}
+
+	if len(parts) == 2 {
+		return desc, nil
+	}
+
+	// 3. Deserialize the key of the leader election.
+	vrfkeyBuf, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return member{}, xerrors.Errorf("base64 vrf key: %v", err)
+	}
+
+	desc.vrfkey, err = vrf.NewPublicKey(vrfkeyBuf)
+	if err != nil {
+		return member{}, xerrors.Errorf("failed to decode vrf key: %v", err)
+	}
+
+	if len(parts) == 3 {
+		return desc, nil
+	}
+
+	// 4. Deserialize the key of the transactions.
+	txkeyBuf, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return member{}, xerrors.Errorf("base64 tx key: %v", err)
+	}
+
+	desc.txkey, err = common.NewPublicKeyFactory().FromBytes(txkeyBuf)
+	if err != nil {
+		return member{}, xerrors.Errorf("failed to decode tx key: %v", err)
 	}
 
-	return addr, pubkey, nil
+	return desc, nil
 }
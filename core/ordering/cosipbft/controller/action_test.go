@@ -3,7 +3,12 @@ package controller
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -12,12 +17,17 @@ import (
 	"go.dedis.ch/dela/core/access"
 	"go.dedis.ch/dela/core/ordering"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/scaling"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/txn/pool"
 	"go.dedis.ch/dela/core/txn/pool/mem"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/cosi"
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/mino"
 )
@@ -34,6 +44,29 @@ func TestSetupAction_Execute(t *testing.T) {
 	require.Equal(t, 1, calls.Len())
 	require.Equal(t, 2, calls.Get(0, 1).(mino.Players).Len())
 
+	vrfkey := base64.StdEncoding.EncodeToString(makeVRFKey(t))
+	txkey := base64.StdEncoding.EncodeToString(makeTxKey(t, ctx))
+	ctx.Flags.(node.FlagSet)["member"] = []interface{}{
+		"YQ==:YQ==",
+		"YQ==:YQ==:" + vrfkey,
+		"YQ==:YQ==:" + vrfkey + ":" + txkey,
+	}
+
+	calls.Clear()
+	err = action.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls.Len())
+
+	roster := calls.Get(0, 1).(authority.Authority)
+	_, found := roster.GetVRFKey(0)
+	require.False(t, found)
+	_, found = roster.GetVRFKey(1)
+	require.True(t, found)
+	_, found = roster.GetTxKey(1)
+	require.False(t, found)
+	_, found = roster.GetTxKey(2)
+	require.True(t, found)
+
 	ctx.Flags.(node.FlagSet)["member"] = []interface{}{""}
 	err = action.Execute(ctx)
 	require.EqualError(t, err, "failed to read roster: failed to decode: invalid member base64 string")
@@ -48,6 +81,66 @@ func TestSetupAction_Execute(t *testing.T) {
 	require.EqualError(t, err, fake.Err("failed to setup"))
 }
 
+func TestSetupAction_Roster_Execute(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela-setup")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "roster.yaml")
+	err = ioutil.WriteFile(path, []byte(`
+- address: AAAA
+  publicKey: YQ==
+- address: BBBB
+  publicKey: Yg==
+`), os.ModePerm)
+	require.NoError(t, err)
+
+	action := setupAction{}
+
+	calls := &fake.Call{}
+	ctx := prepContext(calls)
+	ctx.Flags.(node.FlagSet)["roster"] = path
+
+	err = action.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls.Len())
+	require.Equal(t, 2, calls.Get(0, 1).(mino.Players).Len())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, path)
+	}))
+
+	defer srv.Close()
+
+	calls.Clear()
+	ctx.Flags.(node.FlagSet)["roster"] = srv.URL
+	err = action.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls.Get(0, 1).(mino.Players).Len())
+
+	ctx.Flags.(node.FlagSet)["roster"] = filepath.Join(dir, "unknown.yaml")
+	err = action.Execute(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read roster: failed to load: ")
+
+	ctx.Flags.(node.FlagSet)["member"] = []interface{}{"YQ==:YQ=="}
+	err = action.Execute(ctx)
+	require.EqualError(t, err,
+		"failed to read roster: flags 'member' and 'roster' are exclusive")
+
+	ctx.Flags = node.FlagSet{"roster": path}
+	ctx.Injector = node.NewInjector()
+	err = action.Execute(ctx)
+	require.EqualError(t, err,
+		"failed to read roster: injector: couldn't find dependency for 'mino.Mino'")
+
+	ctx.Injector.Inject(fake.Mino{})
+	err = action.Execute(ctx)
+	require.EqualError(t, err,
+		"failed to read roster: injector: couldn't find dependency for 'cosi.CollectiveSigning'")
+}
+
 func TestExportAction_Execute(t *testing.T) {
 	action := exportAction{}
 
@@ -58,7 +151,8 @@ func TestExportAction_Execute(t *testing.T) {
 
 	err := action.Execute(ctx)
 	require.NoError(t, err)
-	require.Equal(t, "AAAAAA==:UEs=", buffer.String())
+	require.Equal(t, "AAAAAA==:UEs=:"+base64.StdEncoding.EncodeToString(makeVRFKey(t))+
+		":"+base64.StdEncoding.EncodeToString(makeTxKey(t, ctx)), buffer.String())
 
 	ctx.Injector = node.NewInjector()
 	err = action.Execute(ctx)
@@ -75,6 +169,89 @@ func TestExportAction_Execute(t *testing.T) {
 	ctx.Injector.Inject(fakeCosi{err: true})
 	err = action.Execute(ctx)
 	require.EqualError(t, err, fake.Err("failed to marshal public key"))
+
+	ctx.Injector.Inject(fakeCosi{})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'vrf.Signer'")
+
+	ctx.Injector.Inject(makeVRFSigner())
+	err = action.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'crypto.TransactionSigner'")
+}
+
+func TestHeightAction_Execute(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Out:      buffer,
+	}
+
+	err := heightAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'blockstore.BlockStore'")
+
+	ctx.Injector.Inject(blockstore.NewInMemory())
+
+	err = heightAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "0", buffer.String())
+}
+
+func TestPruneAction_Execute(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	ctx := node.Context{
+		Injector:   node.NewInjector(),
+		Flags:      node.FlagSet{"keep": 2},
+		Out:        buffer,
+		Operations: node.NewOperations(),
+	}
+
+	err := pruneAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'blockstore.BlockStore'")
+
+	ctx.Injector.Inject(blockstore.NewInMemory())
+
+	err = pruneAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"block store '*blockstore.InMemory' does not support pruning")
+
+	blocks := &fakePruner{length: 5}
+	ctx.Injector = node.NewInjector()
+	ctx.Injector.Inject(blocks)
+
+	err = pruneAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), blocks.pruned)
+	require.Regexp(t, "^operation [0-9a-f]{16} started"+
+		"prune: 3/3\n3 block\\(s\\) pruned, the chain starts at block 3$", buffer.String())
+
+	// The blocks are pruned by batches.
+	buffer.Reset()
+	blocks.length = 2*pruneBatch + 10
+	blocks.befores = nil
+	err = pruneAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{pruneBatch + 3, 2*pruneBatch + 3, 2*pruneBatch + 8}, blocks.befores)
+	require.Contains(t, buffer.String(), "prune: 2005/2005\n")
+	require.Contains(t, buffer.String(), "2005 block(s) pruned, the chain starts at block 2008")
+
+	// Nothing to prune when the chain is too short.
+	buffer.Reset()
+	ctx.Flags.(node.FlagSet)["keep"] = 10000
+	err = pruneAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Contains(t, buffer.String(), "0 block(s) pruned, the chain starts at block 2008")
+
+	ctx.Flags.(node.FlagSet)["keep"] = 0
+	err = pruneAction{}.Execute(ctx)
+	require.EqualError(t, err, "invalid number of blocks to keep: 0")
+
+	ctx.Flags.(node.FlagSet)["keep"] = 1
+	blocks.err = fake.GetError()
+	err = pruneAction{}.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to prune"))
 }
 
 func TestRosterAddAction_Execute(t *testing.T) {
@@ -96,6 +273,11 @@ func TestRosterAddAction_Execute(t *testing.T) {
 	require.NoError(t, ctx.Injector.Resolve(&p))
 	require.Equal(t, 1, p.Len())
 
+	ctx.Injector.Inject(fakeService{roster: makeRoster(1)})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "while preparing tx: invalid change: "+
+		"invalid addition: address 'fake.Address[0]' already in the authority")
+
 	ctx.Injector = node.NewInjector()
 	err = action.Execute(ctx)
 	require.EqualError(t, err, "injector: couldn't find dependency for 'controller.Service'")
@@ -146,22 +328,270 @@ func TestRosterAddAction_Execute(t *testing.T) {
 	require.EqualError(t, err, "transaction not found after timeout")
 }
 
+func TestBudgetSetAction_Execute(t *testing.T) {
+	action := budgetSetAction{}
+
+	ctx := prepContext(nil)
+	ctx.Flags.(node.FlagSet)["limit"] = 20000
+
+	err := action.Execute(ctx)
+	require.NoError(t, err)
+
+	var p pool.Pool
+	require.NoError(t, ctx.Injector.Resolve(&p))
+	require.Equal(t, 1, p.Len())
+
+	ctx.Flags.(node.FlagSet)["limit"] = -1
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "negative limit: -1")
+
+	ctx.Flags.(node.FlagSet)["limit"] = 0
+	ctx.Injector.Inject(fakeTxManager{errMake: fake.GetError()})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, fake.Err("transaction: creating transaction"))
+
+	ctx.Injector.Inject(fakeTxManager{errSync: fake.GetError()})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, fake.Err("txn manager: sync"))
+
+	ctx.Injector = node.NewInjector()
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'controller.Service'")
+}
+
+func TestEpochSetAction_Execute(t *testing.T) {
+	action := epochSetAction{}
+
+	ctx := prepContext(nil)
+	ctx.Flags.(node.FlagSet)["length"] = 100
+	ctx.Flags.(node.FlagSet)["size"] = 7
+
+	err := action.Execute(ctx)
+	require.NoError(t, err)
+
+	var p pool.Pool
+	require.NoError(t, ctx.Injector.Resolve(&p))
+	require.Equal(t, 1, p.Len())
+
+	ctx.Flags.(node.FlagSet)["size"] = -1
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "invalid epoch: length 100, size -1")
+
+	ctx.Flags.(node.FlagSet)["size"] = 0
+	ctx.Flags.(node.FlagSet)["length"] = -2
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "invalid epoch: length -2, size 0")
+
+	ctx.Flags.(node.FlagSet)["length"] = 0
+	ctx.Injector.Inject(fakeTxManager{errMake: fake.GetError()})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, fake.Err("transaction: creating transaction"))
+
+	ctx.Injector.Inject(fakeTxManager{errSync: fake.GetError()})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, fake.Err("txn manager: sync"))
+
+	ctx.Injector = node.NewInjector()
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'controller.Service'")
+}
+
+func TestScalingCandidateAction_Execute(t *testing.T) {
+	action := scalingCandidateAction{}
+
+	ctx := prepContext(nil)
+	ctx.Flags.(node.FlagSet)["member"] = "YQ==:YQ=="
+
+	err := action.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for '*scaling.Policy'")
+
+	policy := scaling.NewPolicy(nil, nil, 1)
+	ctx.Injector.Inject(policy)
+
+	err = action.Execute(ctx)
+	require.NoError(t, err)
+	require.Len(t, policy.GetCandidates(), 1)
+
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "failed to register: candidate fake.Address[0] already registered")
+
+	ctx.Flags.(node.FlagSet)["member"] = "YQ=="
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "failed to decode member: invalid member base64 string")
+}
+
+func TestScalingListAction_Execute(t *testing.T) {
+	action := scalingListAction{}
+
+	out := new(bytes.Buffer)
+
+	ctx := prepContext(nil)
+	ctx.Out = out
+
+	err := action.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for '*scaling.Policy'")
+
+	policy := scaling.NewPolicy(nil, nil, 1)
+	require.NoError(t, policy.Register(scaling.Candidate{Address: fake.NewAddress(1), PublicKey: fake.PublicKey{}}))
+	ctx.Injector.Inject(policy)
+
+	ctx.Injector.Inject(fakeService{roster: makeRoster(1)})
+	policy.Evaluate(makeRoster(1))
+
+	err = action.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "member fake.Address[0]: availability 0.00 over 0 blocks\n"+
+		"candidate fake.Address[1]\n"+
+		"proposal #1 expand fake.Address[1]: 1 members with 0 faulty tolerate 0 faults out of 1\n",
+		out.String())
+
+	ctx.Injector.Inject(fakeService{err: fake.GetError()})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to read roster"))
+
+	ctx.Injector = node.NewInjector()
+	ctx.Injector.Inject(policy)
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'controller.Service'")
+}
+
+func TestScalingApproveAction_Execute(t *testing.T) {
+	action := scalingApproveAction{}
+
+	ctx := prepContext(nil)
+	ctx.Flags.(node.FlagSet)["id"] = 1
+
+	err := action.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for '*scaling.Policy'")
+
+	roster := makeRoster(1)
+
+	policy := scaling.NewPolicy(nil, nil, 1)
+	require.NoError(t, policy.Register(scaling.Candidate{Address: fake.NewAddress(1), PublicKey: fake.PublicKey{}}))
+	ctx.Injector.Inject(policy)
+
+	ctx.Injector.Inject(fakeService{err: fake.GetError()})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to read roster"))
+
+	ctx.Injector.Inject(fakeService{roster: roster})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "failed to approve: proposal #1 not found")
+
+	policy.Evaluate(roster)
+
+	err = action.Execute(ctx)
+	require.NoError(t, err)
+
+	var p pool.Pool
+	require.NoError(t, ctx.Injector.Resolve(&p))
+	require.Equal(t, 1, p.Len())
+
+	// The roster has changed since the proposal.
+	policy = makePolicyWithProposal(t, roster)
+	ctx.Injector.Inject(policy)
+	ctx.Injector.Inject(fakeService{roster: makeRoster(2)})
+
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "stale proposal: fake.Address[1] is already a member")
+
+	policy = makePolicyWithProposal(t, roster)
+	ctx.Injector.Inject(policy)
+	ctx.Injector.Inject(fakeService{roster: roster})
+	ctx.Injector.Inject(fakeTxManager{errSync: fake.GetError()})
+
+	err = action.Execute(ctx)
+	require.EqualError(t, err, fake.Err("txn manager: sync"))
+
+	policy = makePolicyWithProposal(t, roster)
+	ctx.Injector.Inject(policy)
+	ctx.Injector.Inject(fakeTxManager{errMake: fake.GetError()})
+
+	err = action.Execute(ctx)
+	require.EqualError(t, err, fake.Err("transaction: creating transaction"))
+
+	ctx.Injector = node.NewInjector()
+	ctx.Injector.Inject(policy)
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'controller.Service'")
+}
+
+func TestScalingRejectAction_Execute(t *testing.T) {
+	action := scalingRejectAction{}
+
+	ctx := prepContext(nil)
+	ctx.Flags.(node.FlagSet)["id"] = 1
+
+	err := action.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for '*scaling.Policy'")
+
+	policy := scaling.NewPolicy(nil, nil, 1)
+	require.NoError(t, policy.Register(scaling.Candidate{Address: fake.NewAddress(1), PublicKey: fake.PublicKey{}}))
+	ctx.Injector.Inject(policy)
+
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "failed to reject: proposal #1 not found")
+
+	policy.Evaluate(makeRoster(1))
+
+	err = action.Execute(ctx)
+	require.NoError(t, err)
+	require.Empty(t, policy.GetProposals())
+}
+
 func TestDecodeMember(t *testing.T) {
 	ctx := prepContext(nil)
 
-	_, _, err := decodeMember(ctx, "a:a")
+	vrfkey := makeVRFKey(t)
+
+	m, err := decodeMember(ctx, "YQ==:YQ==:"+base64.StdEncoding.EncodeToString(vrfkey))
+	require.NoError(t, err)
+	require.NotNil(t, m.addr)
+	require.NotNil(t, m.pubkey)
+
+	data, err := m.vrfkey.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, vrfkey, data)
+	require.Nil(t, m.txkey)
+
+	txkey := makeTxKey(t, ctx)
+	prefix := "YQ==:YQ==:" + base64.StdEncoding.EncodeToString(vrfkey) + ":"
+
+	m, err = decodeMember(ctx, prefix+base64.StdEncoding.EncodeToString(txkey))
+	require.NoError(t, err)
+
+	data, err = m.txkey.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, txkey, data)
+
+	_, err = decodeMember(ctx, prefix+"a")
+	require.EqualError(t, err, "base64 tx key: illegal base64 data at input byte 0")
+
+	_, err = decodeMember(ctx, prefix+"YQ==")
+	require.EqualError(t, err, "failed to decode tx key: no algorithm matches the data")
+
+	_, err = decodeMember(ctx, "a:a:a:a:a")
+	require.EqualError(t, err, "invalid member base64 string")
+
+	_, err = decodeMember(ctx, "a:a")
 	require.EqualError(t, err, "base64 address: illegal base64 data at input byte 0")
 
-	_, _, err = decodeMember(ctx, ":a")
+	_, err = decodeMember(ctx, ":a")
 	require.EqualError(t, err, "base64 public key: illegal base64 data at input byte 0")
 
+	_, err = decodeMember(ctx, "::a")
+	require.EqualError(t, err, "base64 vrf key: illegal base64 data at input byte 0")
+
+	_, err = decodeMember(ctx, "::YQ==")
+	require.EqualError(t, err, "failed to decode vrf key: invalid public key size 1 != 32")
+
 	ctx.Injector = node.NewInjector()
 	ctx.Injector.Inject(fake.Mino{})
-	_, _, err = decodeMember(ctx, ":")
+	_, err = decodeMember(ctx, ":")
 	require.EqualError(t, err, "injector: couldn't find dependency for 'cosi.CollectiveSigning'")
 
 	ctx.Injector.Inject(fakeCosi{err: true})
-	_, _, err = decodeMember(ctx, ":")
+	_, err = decodeMember(ctx, ":")
 	require.EqualError(t, err, fake.Err("failed to decode public key"))
 }
 
@@ -184,18 +614,78 @@ func prepContext(calls *fake.Call) node.Context {
 	ctx.Injector.Inject(fakeService{calls: calls, events: events})
 	ctx.Injector.Inject(mem.NewPool())
 	ctx.Injector.Inject(fakeTxManager{})
+	ctx.Injector.Inject(makeVRFSigner())
+	ctx.Injector.Inject(makeTxSigner())
 
 	return ctx
 }
 
+func makeRoster(n int) authority.Authority {
+	return authority.FromAuthority(fake.NewAuthority(n, fake.NewSigner))
+}
+
+// makePolicyWithProposal returns a scaling policy with a pending proposal to
+// add a candidate to the roster.
+func makePolicyWithProposal(t *testing.T, roster authority.Authority) *scaling.Policy {
+	policy := scaling.NewPolicy(nil, nil, 1)
+
+	err := policy.Register(scaling.Candidate{Address: fake.NewAddress(1), PublicKey: fake.PublicKey{}})
+	require.NoError(t, err)
+
+	_, found := policy.Evaluate(roster)
+	require.True(t, found)
+
+	return policy
+}
+
+func makeVRFSigner() vrf.Signer {
+	signer, err := vrf.NewSignerFromBytes(make([]byte, vrf.SeedSize))
+	if err != nil {
+		panic(err)
+	}
+
+	return signer
+}
+
+func makeVRFKey(t *testing.T) []byte {
+	data, err := makeVRFSigner().GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	return data
+}
+
+func makeTxSigner() crypto.TransactionSigner {
+	signer, err := crypto.NewTransactionSigner(bls.NewSigner())
+	if err != nil {
+		panic(err)
+	}
+
+	return signer
+}
+
+func makeTxKey(t *testing.T, ctx node.Context) []byte {
+	var signer crypto.TransactionSigner
+	require.NoError(t, ctx.Injector.Resolve(&signer))
+
+	data, err := signer.GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	return data
+}
+
 type fakeService struct {
 	ordering.Service
 	calls  *fake.Call
 	events []ordering.Event
+	roster authority.Authority
 	err    error
 }
 
 func (s fakeService) GetRoster() (authority.Authority, error) {
+	if s.roster != nil {
+		return s.roster, s.err
+	}
+
 	return authority.New(nil, nil), s.err
 }
 
@@ -251,6 +741,10 @@ func (fakeTx) GetIdentity() access.Identity {
 	return fake.PublicKey{}
 }
 
+func (fakeTx) GetArg(string) []byte {
+	return nil
+}
+
 func (fakeTx) GetID() []byte {
 	return []byte{0xaa}
 }
@@ -289,3 +783,36 @@ type badPool struct {
 func (p badPool) Add(txn.Transaction) error {
 	return fake.GetError()
 }
+
+type fakePruner struct {
+	blockstore.BlockStore
+
+	length  uint64
+	pruned  uint64
+	befores []uint64
+	err     error
+}
+
+func (b *fakePruner) Len() uint64 {
+	return b.length
+}
+
+func (b *fakePruner) Prune(before uint64) (uint64, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	n := before - b.pruned
+	b.pruned = before
+	b.befores = append(b.befores, before)
+
+	return n, nil
+}
+
+func (b *fakePruner) GetPruned() uint64 {
+	return b.pruned
+}
+
+func (b *fakePruner) GetLinkByIndex(uint64) (types.Link, error) {
+	return nil, nil
+}
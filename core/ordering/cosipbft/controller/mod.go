@@ -6,9 +6,15 @@ package controller
 
 import (
 	"encoding"
+	"fmt"
 	"path/filepath"
 	"time"
 
+	accessContract "go.dedis.ch/dela/contracts/access"
+	"go.dedis.ch/dela/contracts/bank"
+	"go.dedis.ch/dela/contracts/grant"
+	"go.dedis.ch/dela/contracts/naming"
+	"go.dedis.ch/dela/contracts/rent"
 	"go.dedis.ch/dela/contracts/value"
 	"go.dedis.ch/dela/crypto"
 
@@ -16,11 +22,16 @@ import (
 	"go.dedis.ch/dela/cli/node"
 	"go.dedis.ch/dela/core/access/darc"
 	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/memory"
 	"go.dedis.ch/dela/core/ordering"
 	"go.dedis.ch/dela/core/ordering/cosipbft"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/budget"
+	"go.dedis.ch/dela/core/ordering/cosipbft/scaling"
 	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/ordering/raft"
+	"go.dedis.ch/dela/core/store/diff"
 	"go.dedis.ch/dela/core/store/hashtree/binprefix"
 	"go.dedis.ch/dela/core/store/kv"
 	"go.dedis.ch/dela/core/txn/pool"
@@ -29,40 +40,232 @@ import (
 	"go.dedis.ch/dela/core/validation/simple"
 	"go.dedis.ch/dela/cosi/threshold"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/hybrid"
 	"go.dedis.ch/dela/crypto/loader"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/gossip"
+	"go.dedis.ch/dela/mino/heartbeat"
 	"go.dedis.ch/dela/serde/json"
 	"golang.org/x/xerrors"
 )
 
-const privateKeyFile = "private.key"
+const (
+	privateKeyFile = "private.key"
+
+	// vrfKeyFile is the name of the file of the private key that proves the
+	// leader elections.
+	vrfKeyFile = "vrf.key"
+
+	// txKeyFile is the name of the file of the private key that signs the
+	// transactions of the node.
+	txKeyFile = "tx.key"
+
+	// denyContractFlag is the flag name of the contracts the node refuses to
+	// serve.
+	denyContractFlag = "deny-contract"
+
+	// allowContractFlag is the flag name of the only contracts the node
+	// accepts to serve.
+	allowContractFlag = "allow-contract"
+
+	// maxBlockTxsFlag is the flag name of the maximum number of transactions
+	// in a block.
+	maxBlockTxsFlag = "max-block-txs"
+
+	// reservedTxsFlag is the flag name of the number of transactions of a
+	// block reserved to the system contracts.
+	reservedTxsFlag = "reserved-system-txs"
+
+	// hybridFlag is the flag name to sign the forward links with both the
+	// classical and the post-quantum schemes.
+	hybridFlag = "hybrid"
+
+	// rentFlag is the flag name to enable the storage rent of the values.
+	rentFlag = "rent"
+
+	// rentPriceFlag is the flag name of the number of coins paid per block.
+	rentPriceFlag = "rent-price"
+
+	// rentInitialFlag is the flag name of the number of blocks a new value is
+	// paid for.
+	rentInitialFlag = "rent-initial"
+
+	// rentGraceFlag is the flag name of the number of blocks an expired value
+	// is kept before it is deleted.
+	rentGraceFlag = "rent-grace"
+
+	// scalingFaultsFlag is the flag name of the number of faults the roster
+	// must tolerate, which enables the scaling proposals.
+	scalingFaultsFlag = "scaling-faults"
+
+	// scalingAvailabilityFlag is the flag name of the percentage of the blocks
+	// a member must sign to be counted as available.
+	scalingAvailabilityFlag = "scaling-availability"
+
+	// fingerprintFlag is the flag name of the version of the fingerprint of
+	// the roster of the chains created by the node.
+	fingerprintFlag = "fingerprint"
+
+	// orderingFlag is the flag name of the ordering service of the node.
+	orderingFlag = "ordering"
+
+	// persistPoolFlag is the flag name to keep the pending transactions of the
+	// pool across the restarts of the node.
+	persistPoolFlag = "persist-pool"
+
+	// poolIdentityLimitFlag is the flag name of the maximum number of pending
+	// transactions of an identity.
+	poolIdentityLimitFlag = "pool-identity-limit"
+
+	// poolSourceLimitFlag is the flag name of the maximum number of pending
+	// transactions submitted from a client.
+	poolSourceLimitFlag = "pool-source-limit"
+
+	// gasLimitFlag is the flag name of the maximum amount of gas of a
+	// transaction, which enables the metering of the executions.
+	gasLimitFlag = "gas-limit"
+
+	cosipbftOrdering = "cosipbft"
+
+	// raftOrdering is the name of the ordering service for the committees
+	// whose members trust each other, which tolerates crash faults only.
+	raftOrdering = "raft"
+)
 
 // valueAccessKey is the access key used for the value contract.
 var valueAccessKey = [32]byte{2}
 
+// grantAccessKey is the access key used for the grant contract.
+var grantAccessKey = [32]byte{3}
+
+// bankAccessKey is the access key used for the bank contract.
+var bankAccessKey = [32]byte{4}
+
 func blsSigner() encoding.BinaryMarshaler {
 	return bls.NewSigner()
 }
 
+func hybridSigner() encoding.BinaryMarshaler {
+	return hybrid.NewSigner()
+}
+
+func vrfSigner() encoding.BinaryMarshaler {
+	return vrf.NewSigner()
+}
+
 // miniController is a CLI initializer to inject an ordering service that is
 // using collective signatures and PBFT for the consensus.
 //
 // - implements node.Initializer
 type miniController struct {
 	signerFn func() encoding.BinaryMarshaler
+	hybridFn func() encoding.BinaryMarshaler
+	vrfFn    func() encoding.BinaryMarshaler
+	txFn     func() encoding.BinaryMarshaler
 }
 
 // NewController creates a new minimal controller for cosipbft.
 func NewController() node.Initializer {
 	return miniController{
 		signerFn: blsSigner,
+		hybridFn: hybridSigner,
+		vrfFn:    vrfSigner,
+		txFn:     blsSigner,
 	}
 }
 
 // SetCommands implements node.Initializer. It sets the command to control the
 // service.
 func (miniController) SetCommands(builder node.Builder) {
+	builder.SetStartFlags(
+		cli.StringSliceFlag{
+			Name:  denyContractFlag,
+			Usage: "contract that the node refuses to serve, but still executes",
+		},
+		cli.StringSliceFlag{
+			Name:  allowContractFlag,
+			Usage: "contract that the node accepts to serve, others are refused",
+		},
+		cli.IntFlag{
+			Name:  maxBlockTxsFlag,
+			Usage: "maximum number of transactions in a block, zero for no limit",
+		},
+		cli.IntFlag{
+			Name:  reservedTxsFlag,
+			Usage: "number of transactions of a block reserved to system contracts",
+		},
+		cli.BoolFlag{
+			Name: hybridFlag,
+			Usage: "sign the forward links with both BLS and ML-DSA, which " +
+				"must be enabled for every member of the chain",
+		},
+		cli.BoolFlag{
+			Name: rentFlag,
+			Usage: "delete the values of the value contract whose rent is not " +
+				"topped up, which must be enabled for every member of the chain",
+		},
+		cli.IntFlag{
+			Name:  rentPriceFlag,
+			Usage: "number of coins paid per block of rent",
+			Value: int(rent.DefaultPolicy.Price),
+		},
+		cli.IntFlag{
+			Name:  rentInitialFlag,
+			Usage: "number of blocks a new value is paid for",
+			Value: int(rent.DefaultPolicy.Initial),
+		},
+		cli.IntFlag{
+			Name:  rentGraceFlag,
+			Usage: "number of blocks an expired value is kept before it is deleted",
+			Value: int(rent.DefaultPolicy.Grace),
+		},
+		cli.IntFlag{
+			Name: scalingFaultsFlag,
+			Usage: "number of faulty members the roster must tolerate, which " +
+				"enables the proposals to expand or contract it, zero to disable",
+		},
+		cli.IntFlag{
+			Name:  scalingAvailabilityFlag,
+			Usage: "percentage of the blocks a member must sign to be available",
+			Value: int(scaling.DefaultMinAvailability * 100),
+		},
+		cli.IntFlag{
+			Name: fingerprintFlag,
+			Usage: "version of the fingerprint of the roster that defines the " +
+				"digest of the genesis block of a new chain, 1 or 2",
+			Value: int(authority.FingerprintV1),
+		},
+		cli.StringFlag{
+			Name: orderingFlag,
+			Usage: fmt.Sprintf("ordering service of the node, either '%s' or "+
+				"'%s' for a committee that only tolerates crash faults, which "+
+				"must be the same for every member of the chain", cosipbftOrdering, raftOrdering),
+			Value: cosipbftOrdering,
+		},
+		cli.BoolFlag{
+			Name: persistPoolFlag,
+			Usage: "write the pending transactions of the pool to the database " +
+				"so that they are not lost when the node restarts",
+		},
+		cli.IntFlag{
+			Name: poolIdentityLimitFlag,
+			Usage: "maximum number of pending transactions of an identity in the " +
+				"pool, or zero for no limit",
+		},
+		cli.IntFlag{
+			Name: poolSourceLimitFlag,
+			Usage: "maximum number of pending transactions submitted from a " +
+				"client to the pool, or zero for no limit",
+		},
+		cli.IntFlag{
+			Name: gasLimitFlag,
+			Usage: "maximum amount of gas of a transaction, paid with the " +
+				"balances of the bank contract, or zero to disable the metering, " +
+				"which must be the same for every member of the chain",
+		},
+	)
+
 	cmd := builder.SetCommand("ordering")
 	cmd.SetDescription("Ordering service administration")
 
@@ -75,17 +278,37 @@ func (miniController) SetCommands(builder node.Builder) {
 			Value: 20 * time.Second,
 		},
 		cli.StringSliceFlag{
-			Name:     "member",
-			Required: true,
-			Usage:    "one or several member of the new chain",
+			Name:  "member",
+			Usage: "one or several member of the new chain",
+		},
+		cli.StringFlag{
+			Name: "roster",
+			Usage: "file or URL of a key server with the list of members " +
+				"in YAML or JSON, instead of the member flags",
 		},
 	)
-	sub.SetAction(builder.MakeAction(setupAction{}))
+	sub.SetAction(builder.MakeAction(node.Destructive(setupAction{})))
 
 	sub = cmd.SetSubCommand("export")
 	sub.SetDescription("Export the node information")
 	sub.SetAction(builder.MakeAction(exportAction{}))
 
+	sub = cmd.SetSubCommand("height")
+	sub.SetDescription("Print the number of blocks of the chain")
+	sub.SetAction(builder.MakeAction(heightAction{}))
+
+	sub = cmd.SetSubCommand("prune")
+	sub.SetDescription("Discard the old blocks of the node while keeping " +
+		"their forward links")
+	sub.SetFlags(
+		cli.IntFlag{
+			Name:     "keep",
+			Required: true,
+			Usage:    "number of latest blocks to keep, at least one",
+		},
+	)
+	sub.SetAction(builder.MakeAction(node.Destructive(pruneAction{})))
+
 	sub = cmd.SetSubCommand("roster")
 	sub.SetDescription("Roster administration")
 
@@ -102,7 +325,95 @@ func (miniController) SetCommands(builder node.Builder) {
 			Usage: "wait for the transaction to be processed",
 		},
 	)
-	sub.SetAction(builder.MakeAction(rosterAddAction{}))
+	sub.SetAction(builder.MakeAction(node.Destructive(rosterAddAction{})))
+
+	sub = cmd.SetSubCommand("budget")
+	sub.SetDescription("Budget administration")
+
+	sub = sub.SetSubCommand("set")
+	sub.SetDescription("Set the budget of the blocks")
+	sub.SetFlags(
+		cli.IntFlag{
+			Name:     "limit",
+			Required: true,
+			Usage: fmt.Sprintf("maximum cost of the transactions of a block, "+
+				"at least %d, or zero for no limit", budget.MinLimit),
+		},
+		cli.DurationFlag{
+			Name:  "wait",
+			Usage: "wait for the transaction to be processed",
+		},
+	)
+	sub.SetAction(builder.MakeAction(node.Destructive(budgetSetAction{})))
+
+	sub = cmd.SetSubCommand("epoch")
+	sub.SetDescription("Epoch administration")
+
+	sub = sub.SetSubCommand("set")
+	sub.SetDescription("Set the length of the epochs and the size of the " +
+		"committee that signs their blocks")
+	sub.SetFlags(
+		cli.IntFlag{
+			Name:     "length",
+			Required: true,
+			Usage:    "number of blocks of an epoch, or zero to disable the epochs",
+		},
+		cli.IntFlag{
+			Name:     "size",
+			Required: true,
+			Usage:    "number of members of a committee, or zero to disable the epochs",
+		},
+		cli.DurationFlag{
+			Name:  "wait",
+			Usage: "wait for the transaction to be processed",
+		},
+	)
+	sub.SetAction(builder.MakeAction(node.Destructive(epochSetAction{})))
+
+	scalingCmd := cmd.SetSubCommand("scaling")
+	scalingCmd.SetDescription("Proposals to expand or contract the roster")
+
+	sub = scalingCmd.SetSubCommand("candidate")
+	sub.SetDescription("Register a candidate that can be proposed to join the roster")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name:     "member",
+			Required: true,
+			Usage:    "base64 description of the candidate",
+		},
+	)
+	sub.SetAction(builder.MakeAction(node.Destructive(scalingCandidateAction{})))
+
+	sub = scalingCmd.SetSubCommand("list")
+	sub.SetDescription("Print the availability of the members, the candidates " +
+		"and the pending proposals")
+	sub.SetAction(builder.MakeAction(scalingListAction{}))
+
+	sub = scalingCmd.SetSubCommand("approve")
+	sub.SetDescription("Approve a proposal and send the roster change")
+	sub.SetFlags(
+		cli.IntFlag{
+			Name:     "id",
+			Required: true,
+			Usage:    "identifier of the proposal",
+		},
+		cli.DurationFlag{
+			Name:  "wait",
+			Usage: "wait for the transaction to be processed",
+		},
+	)
+	sub.SetAction(builder.MakeAction(node.Destructive(scalingApproveAction{})))
+
+	sub = scalingCmd.SetSubCommand("reject")
+	sub.SetDescription("Reject a proposal")
+	sub.SetFlags(
+		cli.IntFlag{
+			Name:     "id",
+			Required: true,
+			Usage:    "identifier of the proposal",
+		},
+	)
+	sub.SetAction(builder.MakeAction(node.Destructive(scalingRejectAction{})))
 }
 
 // OnStart implements node.Initializer. It starts the ordering components and
@@ -119,6 +430,51 @@ func (m miniController) OnStart(flags cli.Flags, inj node.Injector) error {
 		return xerrors.Errorf("signer: %v", err)
 	}
 
+	vrfSigner, err := m.getVRFSigner(flags)
+	if err != nil {
+		return xerrors.Errorf("vrf signer: %v", err)
+	}
+
+	txSigner, err := m.getTxSigner(flags)
+	if err != nil {
+		return xerrors.Errorf("tx signer: %v", err)
+	}
+
+	policy, err := makePolicy(flags)
+	if err != nil {
+		return xerrors.Errorf("policy: %v", err)
+	}
+
+	lanes := cosipbft.DefaultLanes()
+	lanes.MaxTxs = flags.Int(maxBlockTxsFlag)
+	lanes.Reserved = flags.Int(reservedTxsFlag)
+	lanes.SystemContracts = append(lanes.SystemContracts, accessContract.ContractName)
+
+	err = lanes.Validate()
+	if err != nil {
+		return xerrors.Errorf("lanes: %v", err)
+	}
+
+	availability := flags.Int(scalingAvailabilityFlag)
+	if availability < 0 || availability > 100 {
+		return xerrors.Errorf("invalid scaling availability: %d%%", availability)
+	}
+
+	fingerprint := authority.FingerprintVersion(flags.Int(fingerprintFlag))
+	if !fingerprint.IsKnown() {
+		return xerrors.Errorf("invalid fingerprint version: %d", fingerprint)
+	}
+
+	kind := flags.String(orderingFlag)
+	if kind != "" && kind != cosipbftOrdering && kind != raftOrdering {
+		return xerrors.Errorf("unknown ordering service '%s'", kind)
+	}
+
+	gasLimit := flags.Int(gasLimitFlag)
+	if gasLimit < 0 {
+		return xerrors.Errorf("invalid gas limit: %d", gasLimit)
+	}
+
 	cosi := threshold.NewThreshold(onet.WithSegment("cosi"), signer)
 	cosi.SetThreshold(threshold.ByzantineThreshold)
 
@@ -127,24 +483,95 @@ func (m miniController) OnStart(flags cli.Flags, inj node.Injector) error {
 
 	rosterFac := authority.NewFactory(onet.GetAddressFactory(), cosi.GetPublicKeyFactory())
 	cosipbft.RegisterRosterContract(exec, rosterFac, access)
+	cosipbft.RegisterBudgetContract(exec, access)
+
+	valueOpts := []value.Option{}
+
+	// The blocks that exceed the budget of the chain are refused.
+	vsOpts := []simple.Option{simple.WithBudget(cosipbft.ReadBudget, budget.Cost)}
 
-	value.RegisterContract(exec, value.NewContract(valueAccessKey[:], access))
+	if flags.Bool(rentFlag) {
+		rentPolicy, err := makeRentPolicy(flags)
+		if err != nil {
+			return xerrors.Errorf("rent: %v", err)
+		}
+
+		rent.RegisterContract(exec, rent.NewContract(rent.WithPolicy(rentPolicy)))
+
+		valueOpts = append(valueOpts, value.WithRent(rentPolicy))
+
+		// The expired values are swept at the beginning of each block.
+		vsOpts = append(vsOpts, simple.WithBlockHook(rentPolicy.Sweep))
+	}
+
+	if gasLimit > 0 {
+		bank.RegisterContract(exec, bank.NewContract(bankAccessKey[:], access))
+
+		// The intrinsic cost of a transaction is the same as its cost in the
+		// budget of the blocks.
+		vsOpts = append(vsOpts, simple.WithGas(uint64(gasLimit), budget.Cost, bank.NewPayer()))
+	}
+
+	value.RegisterContract(exec, value.NewContract(valueAccessKey[:], access, valueOpts...))
+	naming.RegisterContract(exec, naming.NewContract())
+	grant.RegisterContract(exec, grant.NewContract(grantAccessKey[:], access))
+
+	exec.SetPolicy(policy)
 
 	txFac := signed.NewTransactionFactory()
-	vs := simple.NewService(exec, txFac)
 
-	pool, err := poolimpl.NewPool(gossip.NewFlat(onet.WithSegment("pool"), txFac))
-	if err != nil {
-		return xerrors.Errorf("pool: %v", err)
+	// The signatures of the transactions of a block are verified in a batch
+	// by the ordering service.
+	vs := simple.NewService(exec,
+		signed.NewTransactionFactory(signed.WithDeferredVerification()), vsOpts...)
+
+	poolOpts := []pool.GathererOption{}
+	treeOpts := []binprefix.Option{}
+
+	// The memory of the pool and of the trie is accounted for when the budgets
+	// are available.
+	var acc *memory.Accountant
+	err = inj.Resolve(&acc)
+	if err == nil {
+		poolOpts = append(poolOpts, pool.WithMemoryBudget(acc.GetBudget(memory.PoolModule)))
+		treeOpts = append(treeOpts, binprefix.WithMemoryBudget(acc.GetBudget(memory.TrieModule)))
 	}
 
+	// A single client cannot flood the ordering service with transactions.
+	admission := pool.NewAdmission(
+		pool.WithIdentityLimit(flags.Int(poolIdentityLimitFlag)),
+		pool.WithSourceLimit(flags.Int(poolSourceLimitFlag)),
+	)
+
+	poolOpts = append(poolOpts, pool.WithAdmission(admission))
+
 	var db kv.DB
 	err = inj.Resolve(&db)
 	if err != nil {
 		return xerrors.Errorf("injector: %v", err)
 	}
 
-	tree := binprefix.NewMerkleTree(db, binprefix.Nonce{})
+	gossiper := gossip.NewFlat(onet.WithSegment("pool"), txFac)
+
+	var pool *poolimpl.Pool
+	if flags.Bool(persistPoolFlag) {
+		pool, err = poolimpl.NewPersistentPool(gossiper, db, txFac, poolOpts...)
+	} else {
+		pool, err = poolimpl.NewPool(gossiper, poolOpts...)
+	}
+	if err != nil {
+		return xerrors.Errorf("pool: %v", err)
+	}
+
+	// Malformed transactions are rejected before reaching the execution.
+	pool.AddFilter(native.NewSchemaFilter(exec))
+
+	tree := binprefix.NewMerkleTree(db, binprefix.Nonce{}, treeOpts...)
+
+	// The journal records the changes of the state at each block so that
+	// downstream replicas can stream them.
+	journal := diff.NewJournal(db)
+	tree.AddHook(journal)
 
 	param := cosipbft.ServiceParam{
 		Mino:       onet,
@@ -161,6 +588,44 @@ func (m miniController) OnStart(flags cli.Flags, inj node.Injector) error {
 		return xerrors.Errorf("failed to load tree: %v", err)
 	}
 
+	inj.Inject(cosi)
+	inj.Inject(vrfSigner)
+	inj.Inject(txSigner)
+	inj.Inject(pool)
+	inj.Inject(admission)
+	inj.Inject(vs)
+	inj.Inject(exec)
+	inj.Inject(&access)
+	inj.Inject(journal)
+	inj.Inject(tree)
+
+	// The Raft service uses the same components, but the blocks are not
+	// signed and the roster is fixed when the log is set up.
+	if kind == raftOrdering {
+		srvc, err := raft.NewService(raft.ServiceParam{
+			Mino:          onet,
+			Validation:    vs,
+			Pool:          pool,
+			Tree:          tree,
+			DB:            db,
+			RosterFactory: rosterFac,
+		})
+		if err != nil {
+			return xerrors.Errorf("raft: %v", err)
+		}
+
+		inj.Inject(srvc)
+
+		// The transactions of a previous run go through the filter of the
+		// service before they are added back.
+		err = pool.Load()
+		if err != nil {
+			return xerrors.Errorf("failed to load pool: %v", err)
+		}
+
+		return nil
+	}
+
 	genstore := blockstore.NewGenesisDiskStore(db, types.NewGenesisFactory(rosterFac))
 
 	err = genstore.Load()
@@ -179,26 +644,90 @@ func (m miniController) OnStart(flags cli.Flags, inj node.Injector) error {
 		return xerrors.Errorf("failed to load blocks: %v", err)
 	}
 
-	srvc, err := cosipbft.NewService(param, cosipbft.WithGenesisStore(genstore), cosipbft.WithBlockStore(blocks))
+	authorities := blockstore.NewAuthorityDiskStore(db, rosterFac)
+
+	err = authorities.Load()
+	if err != nil {
+		return xerrors.Errorf("failed to load authorities: %v", err)
+	}
+
+	var srvc *cosipbft.Service
+
+	// The heartbeats follow the current roster so that the service waits less
+	// for a leader that doesn't answer them.
+	hb, err := heartbeat.NewService(onet, heartbeat.WithPeerSource(func() ([]mino.Address, error) {
+		roster, err := srvc.GetRoster()
+		if err != nil {
+			return nil, err
+		}
+
+		addrs := make([]mino.Address, 0, roster.Len())
+
+		iter := roster.AddressIterator()
+		for iter.HasNext() {
+			addrs = append(addrs, iter.GetNext())
+		}
+
+		return addrs, nil
+	}))
+	if err != nil {
+		return xerrors.Errorf("heartbeat: %v", err)
+	}
+
+	srvc, err = cosipbft.NewService(param,
+		cosipbft.WithPeerStatus(hb),
+		cosipbft.WithGenesisStore(genstore),
+		cosipbft.WithBlockStore(blocks),
+		cosipbft.WithAuthorityStore(authorities),
+		cosipbft.WithLanes(lanes),
+		cosipbft.WithVRFSigner(vrfSigner),
+		cosipbft.WithFingerprint(fingerprint))
 	if err != nil {
 		return xerrors.Errorf("service: %v", err)
 	}
 
 	inj.Inject(srvc)
-	inj.Inject(cosi)
-	inj.Inject(pool)
-	inj.Inject(vs)
-	inj.Inject(exec)
-	inj.Inject(&access)
+	inj.Inject(blocks)
+	inj.Inject(genstore)
+	inj.Inject(authorities)
+
+	err = pool.Load()
+	if err != nil {
+		return xerrors.Errorf("failed to load pool: %v", err)
+	}
+
+	hb.Start()
+	inj.Inject(hb)
+
+	faults := flags.Int(scalingFaultsFlag)
+	if faults > 0 {
+		policy := scaling.NewPolicy(srvc, blocks, faults,
+			scaling.WithMinAvailability(float64(availability)/100))
+		policy.Start()
+
+		inj.Inject(policy)
+	}
 
 	return nil
 }
 
-// OnStop implements node.Initializer. It stops the service and the transaction
-// pool.
+// OnStop implements node.Initializer. It stops the scaling policy, the
+// heartbeats, the service and the transaction pool.
 func (miniController) OnStop(inj node.Injector) error {
+	var policy *scaling.Policy
+	err := inj.Resolve(&policy)
+	if err == nil {
+		policy.Stop()
+	}
+
+	var hb *heartbeat.Service
+	err = inj.Resolve(&hb)
+	if err == nil {
+		hb.Stop()
+	}
+
 	var srvc ordering.Service
-	err := inj.Resolve(&srvc)
+	err = inj.Resolve(&srvc)
 	if err != nil {
 		return xerrors.Errorf("injector: %v", err)
 	}
@@ -223,21 +752,120 @@ func (miniController) OnStop(inj node.Injector) error {
 }
 
 func (m miniController) getSigner(flags cli.Flags) (crypto.AggregateSigner, error) {
+	newFn := m.signerFn
+	fromBytes := bls.NewSignerFromBytes
+
+	// A hybrid signer produces collective signatures that are valid only if
+	// both the BLS and the ML-DSA parts are, so that the chain can still be
+	// verified if one of the schemes gets broken.
+	if flags.Bool(hybridFlag) {
+		newFn = m.hybridFn
+		fromBytes = hybrid.NewSignerFromBytes
+	}
+
 	loader := loader.NewFileLoader(filepath.Join(flags.Path("config"), privateKeyFile))
 
-	signerdata, err := loader.LoadOrCreate(generator{newFn: m.signerFn})
+	signerdata, err := loader.LoadOrCreate(generator{newFn: newFn})
 	if err != nil {
 		return nil, xerrors.Errorf("while loading: %v", err)
 	}
 
-	signer, err := bls.NewSignerFromBytes(signerdata)
+	signer, err := fromBytes(signerdata)
 	if err != nil {
 		return nil, xerrors.Errorf("while unmarshaling: %v", err)
 	}
 
+	// The key of the node is reserved for the consensus so that it is rejected
+	// by the signing paths of the other protocols.
+	consensus, err := crypto.NewConsensusSigner(signer)
+	if err != nil {
+		return nil, xerrors.Errorf("while reserving: %v", err)
+	}
+
+	return consensus, nil
+}
+
+// getTxSigner returns the private key of the node for its transactions, which
+// is created the first time. It is distinct from the key of the consensus.
+func (m miniController) getTxSigner(flags cli.Flags) (crypto.TransactionSigner, error) {
+	loader := loader.NewFileLoader(filepath.Join(flags.Path("config"), txKeyFile))
+
+	signerdata, err := loader.LoadOrCreate(generator{newFn: m.txFn})
+	if err != nil {
+		return crypto.TransactionSigner{}, xerrors.Errorf("while loading: %v", err)
+	}
+
+	signer, err := bls.NewSignerFromBytes(signerdata)
+	if err != nil {
+		return crypto.TransactionSigner{}, xerrors.Errorf("while unmarshaling: %v", err)
+	}
+
+	txSigner, err := crypto.NewTransactionSigner(signer)
+	if err != nil {
+		return crypto.TransactionSigner{}, xerrors.Errorf("while reserving: %v", err)
+	}
+
+	return txSigner, nil
+}
+
+// getVRFSigner returns the private key of the node for the leader elections,
+// which is created the first time.
+func (m miniController) getVRFSigner(flags cli.Flags) (vrf.Signer, error) {
+	loader := loader.NewFileLoader(filepath.Join(flags.Path("config"), vrfKeyFile))
+
+	seed, err := loader.LoadOrCreate(generator{newFn: m.vrfFn})
+	if err != nil {
+		return vrf.Signer{}, xerrors.Errorf("while loading: %v", err)
+	}
+
+	signer, err := vrf.NewSignerFromBytes(seed)
+	if err != nil {
+		return vrf.Signer{}, xerrors.Errorf("while unmarshaling: %v", err)
+	}
+
 	return signer, nil
 }
 
+// makePolicy returns the local execution policy defined by the flags. Only one
+// of the deny and the allow lists can be set.
+func makePolicy(flags cli.Flags) (native.Policy, error) {
+	deny := flags.StringSlice(denyContractFlag)
+	allow := flags.StringSlice(allowContractFlag)
+
+	if len(deny) > 0 && len(allow) > 0 {
+		return native.Policy{}, xerrors.Errorf("flags '%s' and '%s' are exclusive",
+			denyContractFlag, allowContractFlag)
+	}
+
+	if len(allow) > 0 {
+		return native.NewAllowList(allow...), nil
+	}
+
+	return native.NewDenyList(deny...), nil
+}
+
+// makeRentPolicy returns the storage rent policy defined by the flags.
+func makeRentPolicy(flags cli.Flags) (rent.Policy, error) {
+	values := []int{
+		flags.Int(rentPriceFlag),
+		flags.Int(rentInitialFlag),
+		flags.Int(rentGraceFlag),
+	}
+
+	for _, value := range values {
+		if value < 0 {
+			return rent.Policy{}, xerrors.Errorf("negative value %d", value)
+		}
+	}
+
+	p := rent.DefaultPolicy
+	p.Price = uint64(values[0])
+	p.Initial = uint64(values[1])
+	p.Grace = uint64(values[2])
+
+	return p, nil
+}
+
 // generator is an implementation to generate a private key.
 //
 // - implements loader.Generator
@@ -10,9 +10,22 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cli"
 	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/contracts/bank"
+	"go.dedis.ch/dela/contracts/rent"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/memory"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/budget"
+	"go.dedis.ch/dela/core/ordering/cosipbft/scaling"
+	"go.dedis.ch/dela/core/ordering/raft"
 	"go.dedis.ch/dela/core/store/kv"
 	"go.dedis.ch/dela/core/txn/pool"
+	"go.dedis.ch/dela/cosi/threshold"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/hybrid"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino/heartbeat"
 )
 
 func TestMinimal_SetCommands(t *testing.T) {
@@ -35,8 +48,288 @@ func TestMinimal_OnStart(t *testing.T) {
 	inj.Inject(fake.Mino{})
 	inj.Inject(db)
 
+	acc := memory.NewAccountant()
+	inj.Inject(acc)
+
+	err = m.OnStart(flags, inj)
+	require.NoError(t, err)
+
+	// The budgets of the pool and of the trie are created by the components.
+	usage := acc.GetUsage()
+	require.Len(t, usage, 2)
+	require.Equal(t, memory.PoolModule, usage[0].Module)
+	require.Equal(t, memory.TrieModule, usage[1].Module)
+
+	var admission *pool.Admission
+	err = inj.Resolve(&admission)
+	require.NoError(t, err)
+
+	var vrfSigner vrf.Signer
+	err = inj.Resolve(&vrfSigner)
+	require.NoError(t, err)
+
+	// The key of the leader election is loaded from the file on restart.
+	other, err := m.getVRFSigner(flags)
+	require.NoError(t, err)
+	require.True(t, other.GetPublicKey().Equal(vrfSigner.GetPublicKey()))
+
+	var txSigner crypto.TransactionSigner
+	err = inj.Resolve(&txSigner)
+	require.NoError(t, err)
+	require.Equal(t, crypto.TransactionUsage, crypto.GetUsage(txSigner))
+
+	// The transactions are signed with a key distinct from the consensus.
+	var c *threshold.Threshold
+	err = inj.Resolve(&c)
+	require.NoError(t, err)
+	require.False(t, txSigner.GetPublicKey().Equal(c.GetSigner().GetPublicKey()))
+
+	otherTx, err := m.getTxSigner(flags)
+	require.NoError(t, err)
+	require.True(t, otherTx.GetPublicKey().Equal(txSigner.GetPublicKey()))
+
+	var exec *native.Service
+	err = inj.Resolve(&exec)
+	require.NoError(t, err)
+	require.NoError(t, exec.IsServed(budget.ContractName))
+}
+
+func TestMinimal_BadPolicy_OnStart(t *testing.T) {
+	flags, _, clean := makeFlags(t)
+	defer clean()
+
+	fset := flags.(node.FlagSet)
+	fset[denyContractFlag] = []interface{}{"a"}
+	fset[allowContractFlag] = []interface{}{"b"}
+
+	m := NewController().(miniController)
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+
+	err := m.OnStart(flags, inj)
+	require.EqualError(t, err,
+		"policy: flags 'deny-contract' and 'allow-contract' are exclusive")
+}
+
+func TestMinimal_BadLanes_OnStart(t *testing.T) {
+	flags, _, clean := makeFlags(t)
+	defer clean()
+
+	fset := flags.(node.FlagSet)
+	fset[maxBlockTxsFlag] = 10
+	fset[reservedTxsFlag] = 10
+
+	m := NewController().(miniController)
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+
+	err := m.OnStart(flags, inj)
+	require.EqualError(t, err,
+		"lanes: reserved transactions must be less than the maximum: 10 >= 10")
+}
+
+func TestMakePolicy(t *testing.T) {
+	fset := make(node.FlagSet)
+
+	policy, err := makePolicy(fset)
+	require.NoError(t, err)
+	require.Equal(t, native.DenyMode, policy.GetMode())
+	require.Empty(t, policy.GetNames())
+
+	fset[allowContractFlag] = []interface{}{"a", "b"}
+
+	policy, err = makePolicy(fset)
+	require.NoError(t, err)
+	require.Equal(t, native.AllowMode, policy.GetMode())
+	require.Equal(t, []string{"a", "b"}, policy.GetNames())
+}
+
+func TestMinimal_BadRent_OnStart(t *testing.T) {
+	flags, _, clean := makeFlags(t)
+	defer clean()
+
+	fset := flags.(node.FlagSet)
+	fset[rentFlag] = true
+	fset[rentGraceFlag] = -1
+
+	m := NewController().(miniController)
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+
+	err := m.OnStart(flags, inj)
+	require.EqualError(t, err, "rent: negative value -1")
+}
+
+func TestMinimal_BadScaling_OnStart(t *testing.T) {
+	flags, _, clean := makeFlags(t)
+	defer clean()
+
+	fset := flags.(node.FlagSet)
+	fset[scalingAvailabilityFlag] = 101
+
+	m := NewController().(miniController)
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+
+	err := m.OnStart(flags, inj)
+	require.EqualError(t, err, "invalid scaling availability: 101%")
+}
+
+func TestMinimal_BadFingerprint_OnStart(t *testing.T) {
+	flags, _, clean := makeFlags(t)
+	defer clean()
+
+	fset := flags.(node.FlagSet)
+	fset[fingerprintFlag] = 3
+
+	m := NewController().(miniController)
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+
+	err := m.OnStart(flags, inj)
+	require.EqualError(t, err, "invalid fingerprint version: 3")
+}
+
+func TestMinimal_BadGasLimit_OnStart(t *testing.T) {
+	flags, _, clean := makeFlags(t)
+	defer clean()
+
+	fset := flags.(node.FlagSet)
+	fset[gasLimitFlag] = -1
+
+	m := NewController().(miniController)
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+
+	err := m.OnStart(flags, inj)
+	require.EqualError(t, err, "invalid gas limit: -1")
+}
+
+func TestMinimal_Raft_OnStart(t *testing.T) {
+	flags, dir, clean := makeFlags(t)
+	defer clean()
+
+	flags.(node.FlagSet)[orderingFlag] = raftOrdering
+
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	m := NewController().(miniController)
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+	inj.Inject(db)
+
+	err = m.OnStart(flags, inj)
+	require.NoError(t, err)
+
+	var srvc Service
+	require.NoError(t, inj.Resolve(&srvc))
+	require.IsType(t, &raft.Service{}, srvc)
+
+	var hb *heartbeat.Service
+	require.Error(t, inj.Resolve(&hb))
+
+	err = m.OnStop(inj)
+	require.NoError(t, err)
+}
+
+func TestMinimal_PersistPool_OnStart(t *testing.T) {
+	flags, dir, clean := makeFlags(t)
+	defer clean()
+
+	flags.(node.FlagSet)[persistPoolFlag] = true
+
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	m := NewController().(miniController)
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+	inj.Inject(db)
+
+	err = m.OnStart(flags, inj)
+	require.NoError(t, err)
+
+	var p pool.Pool
+	require.NoError(t, inj.Resolve(&p))
+	require.Equal(t, 0, p.Len())
+
+	err = m.OnStop(inj)
+	require.NoError(t, err)
+}
+
+func TestMinimal_Gas_OnStart(t *testing.T) {
+	flags, dir, clean := makeFlags(t)
+	defer clean()
+
+	flags.(node.FlagSet)[gasLimitFlag] = 100000
+
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	m := NewController().(miniController)
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+	inj.Inject(db)
+
 	err = m.OnStart(flags, inj)
 	require.NoError(t, err)
+
+	var exec *native.Service
+	require.NoError(t, inj.Resolve(&exec))
+	require.NoError(t, exec.IsServed(bank.ContractName))
+
+	err = m.OnStop(inj)
+	require.NoError(t, err)
+}
+
+func TestMinimal_BadOrdering_OnStart(t *testing.T) {
+	flags, _, clean := makeFlags(t)
+	defer clean()
+
+	flags.(node.FlagSet)[orderingFlag] = "abc"
+
+	m := NewController().(miniController)
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+
+	err := m.OnStart(flags, inj)
+	require.EqualError(t, err, "unknown ordering service 'abc'")
+}
+
+func TestMakeRentPolicy(t *testing.T) {
+	fset := make(node.FlagSet)
+	fset[rentPriceFlag] = 2
+	fset[rentInitialFlag] = 10
+	fset[rentGraceFlag] = 5
+
+	p, err := makeRentPolicy(fset)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), p.Price)
+	require.Equal(t, uint64(10), p.Initial)
+	require.Equal(t, uint64(5), p.Grace)
+	require.Equal(t, rent.DefaultPolicy.MaxBlocks, p.MaxBlocks)
+
+	fset[rentPriceFlag] = -1
+
+	_, err = makeRentPolicy(fset)
+	require.EqualError(t, err, "negative value -1")
 }
 
 func TestMinimal_MissingMino_OnStart(t *testing.T) {
@@ -64,6 +357,67 @@ func TestMinimal_FailLoadKey_OnStart(t *testing.T) {
 		fake.Err("signer: while loading: generator failed: failed to marshal signer"))
 }
 
+func TestMinimal_FailLoadVRFKey_OnStart(t *testing.T) {
+	flags, _, clean := makeFlags(t)
+	defer clean()
+
+	m := NewController().(miniController)
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+	inj.Inject(fake.NewInMemoryDB())
+
+	m.vrfFn = badFn
+
+	err := m.OnStart(flags, inj)
+	require.EqualError(t, err,
+		fake.Err("vrf signer: while loading: generator failed: failed to marshal signer"))
+}
+
+func TestMinimal_MalformedVRFKey_OnStart(t *testing.T) {
+	flags, dir, clean := makeFlags(t)
+	defer clean()
+
+	m := NewController().(miniController)
+
+	err := ioutil.WriteFile(filepath.Join(dir, vrfKeyFile), []byte{1}, os.ModePerm)
+	require.NoError(t, err)
+
+	_, err = m.getVRFSigner(flags)
+	require.EqualError(t, err, "while unmarshaling: invalid seed size 1 != 32")
+}
+
+func TestMinimal_FailLoadTxKey_OnStart(t *testing.T) {
+	flags, _, clean := makeFlags(t)
+	defer clean()
+
+	m := NewController().(miniController)
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+	inj.Inject(fake.NewInMemoryDB())
+
+	m.txFn = badFn
+
+	err := m.OnStart(flags, inj)
+	require.EqualError(t, err,
+		fake.Err("tx signer: while loading: generator failed: failed to marshal signer"))
+}
+
+func TestMinimal_MalformedTxKey_OnStart(t *testing.T) {
+	flags, dir, clean := makeFlags(t)
+	defer clean()
+
+	m := NewController().(miniController)
+
+	err := ioutil.WriteFile(filepath.Join(dir, txKeyFile), []byte{1}, os.ModePerm)
+	require.NoError(t, err)
+
+	_, err = m.getTxSigner(flags)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "while unmarshaling: ")
+}
+
 func TestMinimal_MissingDB_OnStart(t *testing.T) {
 	flags, _, clean := makeFlags(t)
 	defer clean()
@@ -97,6 +451,38 @@ func TestMinimal_MalformedKey_OnStart(t *testing.T) {
 	require.Contains(t, err.Error(), "signer: while unmarshaling: ")
 }
 
+func TestMinimal_Hybrid_OnStart(t *testing.T) {
+	flags, dir, clean := makeFlags(t)
+	defer clean()
+
+	flags.(node.FlagSet)[hybridFlag] = true
+
+	m := NewController().(miniController)
+
+	inj := node.NewInjector()
+	inj.Inject(fake.Mino{})
+	inj.Inject(fake.NewInMemoryDB())
+
+	err := m.OnStart(flags, inj)
+	require.NoError(t, err)
+
+	var c *threshold.Threshold
+	err = inj.Resolve(&c)
+	require.NoError(t, err)
+	require.IsType(t, hybrid.PublicKey{}, c.GetSigner().GetPublicKey())
+	require.Equal(t, crypto.ConsensusUsage, crypto.GetUsage(c.GetSigner()))
+
+	// A node created with a BLS key cannot be restarted as hybrid.
+	data, err := blsSigner().MarshalBinary()
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(filepath.Join(dir, privateKeyFile), data, os.ModePerm)
+	require.NoError(t, err)
+
+	_, err = m.getSigner(flags)
+	require.EqualError(t, err, "while unmarshaling: data is too short: 32")
+}
+
 func TestMinimal_OnStop(t *testing.T) {
 	dir, err := ioutil.TempDir(os.TempDir(), "dela-test-")
 	require.NoError(t, err)
@@ -110,6 +496,9 @@ func TestMinimal_OnStop(t *testing.T) {
 
 	fset := make(node.FlagSet)
 	fset["config"] = dir
+	fset[scalingFaultsFlag] = 1
+	fset[scalingAvailabilityFlag] = 50
+	fset[fingerprintFlag] = int(authority.FingerprintV2)
 
 	inj := node.NewInjector()
 	inj.Inject(fake.Mino{})
@@ -118,6 +507,12 @@ func TestMinimal_OnStop(t *testing.T) {
 	err = m.OnStart(fset, inj)
 	require.NoError(t, err)
 
+	var policy *scaling.Policy
+	require.NoError(t, inj.Resolve(&policy))
+
+	var hb *heartbeat.Service
+	require.NoError(t, inj.Resolve(&hb))
+
 	err = m.OnStop(inj)
 	require.NoError(t, err)
 
@@ -149,6 +544,7 @@ func makeFlags(t *testing.T) (cli.Flags, string, func()) {
 
 	fset := make(node.FlagSet)
 	fset["config"] = dir
+	fset[fingerprintFlag] = int(authority.FingerprintV1)
 
 	return fset, dir, func() { os.RemoveAll(dir) }
 }
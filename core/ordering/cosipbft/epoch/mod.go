@@ -0,0 +1,118 @@
+// Package epoch implements the rotation of the signing committee of a chain.
+//
+// The blocks of a chain are grouped in epochs of a fixed number of blocks. The
+// committee that signs the blocks of an epoch is sampled from the full roster
+// when the last block of the previous epoch is executed, using a seed derived
+// from the block before it: the output of the verifiable random function of its
+// proposer when it has one, otherwise its digest. The committee is stored in
+// the state, and the forward link of the last block of an epoch holds the
+// change set from one committee to the next, so that a light client follows
+// the committees with the links and can verify a sampling with the roster and
+// the previous block. The committee of the first epoch is the roster of the
+// genesis block.
+//
+// The configuration of the epochs is a parameter of the chain stored in the
+// state, and a length or a size of zero disables the rotation, in which case
+// the full roster signs the blocks.
+package epoch
+
+import (
+	"encoding/binary"
+
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/mino"
+	"golang.org/x/xerrors"
+)
+
+// Config is the configuration of the epochs of a chain.
+type Config struct {
+	// Length is the number of blocks of an epoch.
+	Length uint64
+
+	// Size is the number of members of a committee. The whole roster is the
+	// committee when it has fewer members.
+	Size uint32
+}
+
+// IsEnabled returns true if the committee rotates.
+func (c Config) IsEnabled() bool {
+	return c.Length > 0 && c.Size > 0
+}
+
+// IsBoundary returns true if the block at the index is the last one of an
+// epoch, which samples the committee of the next epoch.
+func (c Config) IsBoundary(index uint64) bool {
+	return c.IsEnabled() && (index+1)%c.Length == 0
+}
+
+// Encode returns the binary representation of the configuration.
+func (c Config) Encode() []byte {
+	buffer := make([]byte, 12)
+	binary.LittleEndian.PutUint64(buffer, c.Length)
+	binary.LittleEndian.PutUint32(buffer[8:], c.Size)
+
+	return buffer
+}
+
+// Read returns the configuration stored at the key, or the disabled one if it
+// has never been set.
+func Read(snap store.Readable, key []byte) (Config, error) {
+	value, err := snap.Get(key)
+	if err != nil {
+		return Config{}, xerrors.Errorf("failed to read config: %v", err)
+	}
+
+	if len(value) != 12 {
+		return Config{}, nil
+	}
+
+	cfg := Config{
+		Length: binary.LittleEndian.Uint64(value),
+		Size:   binary.LittleEndian.Uint32(value[8:]),
+	}
+
+	return cfg, nil
+}
+
+// Seed returns the seed of the sampling that follows the block, which is the
+// output of the verifiable random function of the block when it holds a proof,
+// otherwise its digest.
+func Seed(link types.BlockLink) ([]byte, error) {
+	proof, found := link.GetBlock().GetProof()
+	if !found {
+		return link.GetTo().Bytes(), nil
+	}
+
+	seed, err := proof.Hash()
+	if err != nil {
+		return nil, xerrors.Errorf("invalid proof: %v", err)
+	}
+
+	return seed, nil
+}
+
+// Sample returns the committee of the given size sampled from the roster with
+// the seed. The members are drawn with a probability proportional to their
+// weight, and they keep their order, their weight and their keys in the
+// committee.
+func Sample(roster authority.Authority, seed []byte, size int) authority.Authority {
+	if size >= roster.Len() {
+		return roster
+	}
+
+	return mino.Sample(roster, seed, size).(authority.Authority)
+}
+
+// Verify returns nil if the committee is the sampling of the roster with the
+// seed, otherwise an error.
+func Verify(roster authority.Authority, seed []byte, size int, committee authority.Authority) error {
+	expected := Sample(roster, seed, size)
+
+	if expected.Diff(committee).NumChanges() > 0 {
+		return xerrors.New("committee does not match the sampling")
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package epoch
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/vrf"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+)
+
+func TestConfig_IsEnabled(t *testing.T) {
+	require.True(t, Config{Length: 10, Size: 4}.IsEnabled())
+	require.False(t, Config{}.IsEnabled())
+	require.False(t, Config{Length: 10}.IsEnabled())
+	require.False(t, Config{Size: 4}.IsEnabled())
+}
+
+func TestConfig_IsBoundary(t *testing.T) {
+	cfg := Config{Length: 10, Size: 4}
+
+	require.True(t, cfg.IsBoundary(9))
+	require.True(t, cfg.IsBoundary(19))
+	require.False(t, cfg.IsBoundary(0))
+	require.False(t, cfg.IsBoundary(10))
+	require.False(t, Config{}.IsBoundary(9))
+}
+
+func TestRead(t *testing.T) {
+	snap := fakeSnapshot{value: Config{Length: 10, Size: 4}.Encode()}
+
+	cfg, err := Read(snap, []byte("epoch"))
+	require.NoError(t, err)
+	require.Equal(t, Config{Length: 10, Size: 4}, cfg)
+
+	cfg, err = Read(fakeSnapshot{}, []byte("epoch"))
+	require.NoError(t, err)
+	require.False(t, cfg.IsEnabled())
+
+	_, err = Read(fakeSnapshot{err: fake.GetError()}, []byte("epoch"))
+	require.EqualError(t, err, fake.Err("failed to read config"))
+}
+
+func TestSeed(t *testing.T) {
+	link := makeLink(t)
+
+	seed, err := Seed(link)
+	require.NoError(t, err)
+	require.Equal(t, link.GetTo().Bytes(), seed)
+
+	signer := vrf.NewSigner()
+	proof, err := signer.Prove([]byte("ping"))
+	require.NoError(t, err)
+
+	link = makeLink(t, types.WithProof(proof))
+
+	seed, err = Seed(link)
+	require.NoError(t, err)
+
+	expected, err := proof.Hash()
+	require.NoError(t, err)
+	require.Equal(t, expected, seed)
+
+	proof, err = vrf.NewProof(bytes.Repeat([]byte{0xff}, vrf.ProofSize))
+	require.NoError(t, err)
+
+	_, err = Seed(makeLink(t, types.WithProof(proof)))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid proof: ")
+}
+
+func TestSample(t *testing.T) {
+	roster := authority.FromAuthority(fake.NewAuthority(10, bls.Generate))
+
+	committee := Sample(roster, []byte{1}, 4)
+	require.Equal(t, 4, committee.Len())
+	require.Equal(t, committee, Sample(roster, []byte{1}, 4))
+
+	// The members keep the order of the roster.
+	prev := -1
+	iter := committee.AddressIterator()
+	for iter.HasNext() {
+		_, index := roster.GetPublicKey(iter.GetNext())
+		require.Greater(t, index, prev)
+		prev = index
+	}
+
+	other := Sample(roster, []byte{2}, 4)
+	require.NotEqual(t, committee, other)
+
+	require.Equal(t, roster, Sample(roster, []byte{1}, 10))
+	require.Equal(t, roster, Sample(roster, []byte{1}, 20))
+
+	// A member without voting rights is never part of the committee.
+	ca := fake.NewAuthority(3, bls.Generate)
+
+	addrs := []mino.Address{}
+	pubkeys := []crypto.PublicKey{}
+	for i := 0; i < ca.Len(); i++ {
+		addrs = append(addrs, ca.GetAddress(i))
+		pubkeys = append(pubkeys, ca.GetSigner(i).GetPublicKey())
+	}
+
+	weighted := authority.NewWeighted(addrs, pubkeys, []uint64{0, 1, 1})
+
+	for i := byte(0); i < 10; i++ {
+		committee = Sample(weighted, []byte{i}, 2)
+		require.Equal(t, 2, committee.Len())
+		require.Equal(t, uint64(2), committee.TotalWeight())
+	}
+}
+
+func TestVerify(t *testing.T) {
+	roster := authority.FromAuthority(fake.NewAuthority(10, bls.Generate))
+
+	err := Verify(roster, []byte{1}, 4, Sample(roster, []byte{1}, 4))
+	require.NoError(t, err)
+
+	err = Verify(roster, []byte{1}, 4, Sample(roster, []byte{2}, 4))
+	require.EqualError(t, err, "committee does not match the sampling")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeLink(t *testing.T, opts ...types.BlockOption) types.BlockLink {
+	block, err := types.NewBlock(simple.NewResult(nil), opts...)
+	require.NoError(t, err)
+
+	link, err := types.NewBlockLink(types.Digest{}, block)
+	require.NoError(t, err)
+
+	return link
+}
+
+type fakeSnapshot struct {
+	store.Readable
+
+	value []byte
+	err   error
+}
+
+func (snap fakeSnapshot) Get(key []byte) ([]byte, error) {
+	return snap.value, snap.err
+}
@@ -0,0 +1,140 @@
+// Package controller implements a CLI controller to publish the events of the
+// chain to a message broker in the background of a node.
+package controller
+
+import (
+	"strings"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/events/bridge"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// urlFlag is the flag name of the URL of the broker.
+	urlFlag = "bridge-url"
+
+	// blocksFlag is the flag name of the topic of the blocks.
+	blocksFlag = "bridge-blocks"
+
+	// transactionsFlag is the flag name of the default topic of the
+	// transactions.
+	transactionsFlag = "bridge-transactions"
+
+	// topicFlag is the flag name of the topics of specific contracts.
+	topicFlag = "bridge-topic"
+)
+
+// NewController returns a new controller that starts the bridge when the URL
+// of the broker is set.
+func NewController() node.Initializer {
+	return minimal{}
+}
+
+// minimal is an initializer with the flags to start the bridge.
+//
+// - implements node.Initializer
+type minimal struct{}
+
+// SetCommands implements node.Initializer. It sets the flags to start the
+// bridge.
+func (minimal) SetCommands(builder node.Builder) {
+	builder.SetStartFlags(
+		cli.StringFlag{
+			Name:  urlFlag,
+			Usage: "URL of the MQTT (mqtt://) or NATS (nats://) broker the events are published to",
+		},
+		cli.StringFlag{
+			Name:  blocksFlag,
+			Usage: "topic of the events of the blocks, empty to skip them",
+		},
+		cli.StringFlag{
+			Name:  transactionsFlag,
+			Usage: "topic of the events of the transactions, empty to skip them",
+		},
+		cli.StringSliceFlag{
+			Name:  topicFlag,
+			Usage: "topic of the transactions of a contract as 'contract=topic'",
+		},
+	)
+}
+
+// OnStart implements node.Initializer. It starts the bridge if the URL is set.
+// It expects the ordering service to be started beforehand.
+func (minimal) OnStart(flags cli.Flags, inj node.Injector) error {
+	rawURL := flags.String(urlFlag)
+	if rawURL == "" {
+		return nil
+	}
+
+	topics, err := parseTopics(flags)
+	if err != nil {
+		return xerrors.Errorf("invalid topics: %v", err)
+	}
+
+	var blocks blockstore.BlockStore
+	err = inj.Resolve(&blocks)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	publisher, err := bridge.NewPublisher(rawURL)
+	if err != nil {
+		return xerrors.Errorf("publisher: %v", err)
+	}
+
+	opts := []bridge.Option{}
+
+	// The local policy is applied when the execution is native.
+	var exec *native.Service
+	err = inj.Resolve(&exec)
+	if err == nil {
+		opts = append(opts, bridge.WithPolicy(exec.GetPolicy()))
+	}
+
+	b := bridge.NewBridge(blocks, publisher, topics, opts...)
+	b.Start()
+
+	inj.Inject(b)
+
+	return nil
+}
+
+// OnStop implements node.Initializer. It stops the bridge if it is running.
+func (minimal) OnStop(inj node.Injector) error {
+	var b *bridge.Bridge
+	err := inj.Resolve(&b)
+	if err != nil {
+		// The bridge is disabled.
+		return nil
+	}
+
+	err = b.Stop()
+	if err != nil {
+		return xerrors.Errorf("failed to stop bridge: %v", err)
+	}
+
+	return nil
+}
+
+func parseTopics(flags cli.Flags) (bridge.Topics, error) {
+	topics := bridge.Topics{
+		Blocks:       flags.String(blocksFlag),
+		Transactions: flags.String(transactionsFlag),
+		Contracts:    make(map[string]string),
+	}
+
+	for _, value := range flags.StringSlice(topicFlag) {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return topics, xerrors.Errorf("malformed topic '%s'", value)
+		}
+
+		topics.Contracts[parts[0]] = parts[1]
+	}
+
+	return topics, nil
+}
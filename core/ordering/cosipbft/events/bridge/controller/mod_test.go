@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/events/bridge"
+)
+
+func TestMinimal_SetCommands(t *testing.T) {
+	m := NewController()
+
+	b := node.NewBuilder()
+	m.SetCommands(b)
+}
+
+func TestMinimal_OnStart(t *testing.T) {
+	m := NewController()
+
+	inj := node.NewInjector()
+
+	// The bridge is disabled by default.
+	err := m.OnStart(make(node.FlagSet), inj)
+	require.NoError(t, err)
+
+	flags := node.FlagSet{
+		urlFlag:          "mqtt://127.0.0.1:0",
+		blocksFlag:       "dela/blocks",
+		transactionsFlag: "dela/txs",
+		topicFlag:        []interface{}{"value=dela/value", "secret="},
+	}
+
+	err = m.OnStart(flags, inj)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'blockstore.BlockStore'")
+
+	inj.Inject(blockstore.NewInMemory())
+
+	err = m.OnStart(node.FlagSet{urlFlag: "amqp://127.0.0.1"}, inj)
+	require.EqualError(t, err, "publisher: unsupported scheme 'amqp'")
+
+	err = m.OnStart(node.FlagSet{urlFlag: "mqtt://127.0.0.1", topicFlag: []interface{}{"value"}}, inj)
+	require.EqualError(t, err, "invalid topics: malformed topic 'value'")
+
+	err = m.OnStart(flags, inj)
+	require.NoError(t, err)
+
+	var b *bridge.Bridge
+	require.NoError(t, inj.Resolve(&b))
+
+	err = m.OnStop(inj)
+	require.NoError(t, err)
+}
+
+func TestMinimal_OnStop(t *testing.T) {
+	err := NewController().OnStop(node.NewInjector())
+	require.NoError(t, err)
+}
+
+func TestParseTopics(t *testing.T) {
+	topics, err := parseTopics(node.FlagSet{
+		blocksFlag: "blocks",
+		topicFlag:  []interface{}{"value=dela/value", "secret="},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "blocks", topics.Blocks)
+	require.Equal(t, "", topics.Transactions)
+	require.Equal(t, map[string]string{"value": "dela/value", "secret": ""}, topics.Contracts)
+
+	_, err = parseTopics(node.FlagSet{topicFlag: []interface{}{"=topic"}})
+	require.EqualError(t, err, "malformed topic '=topic'")
+}
@@ -0,0 +1,212 @@
+// Package bridge implements a background service that publishes the events of
+// the chain to a message broker, for the consumers that already speak MQTT or
+// NATS rather than the Go watch API or the WebSocket endpoint.
+//
+// The events are the JSON documents of the WebSocket endpoint. The events of
+// the blocks are published to a single topic, and the events of the
+// transactions to the topic of their contract, or to a default topic. An event
+// without a topic is not published.
+//
+// The bridge only publishes the blocks committed after it starts, and it drops
+// the events when the broker is not reachable or doesn't read them in time, so
+// that the node never waits on it longer than the timeouts.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/events"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"golang.org/x/xerrors"
+)
+
+// Publisher is the interface of a client of a message broker.
+type Publisher interface {
+	// Publish sends the payload to the topic.
+	Publish(topic string, payload []byte) error
+
+	// Close closes the connection to the broker.
+	Close() error
+}
+
+// NewPublisher returns the publisher of the broker of the URL, which has
+// either the 'mqtt' or the 'nats' scheme. The credentials of the URL are used
+// to authenticate to the broker.
+func NewPublisher(rawURL string) (Publisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid url: %v", err)
+	}
+
+	switch u.Scheme {
+	case "mqtt":
+		return NewMQTTPublisher(u), nil
+	case "nats":
+		return NewNATSPublisher(u), nil
+	default:
+		return nil, xerrors.Errorf("unsupported scheme '%s'", u.Scheme)
+	}
+}
+
+// Topics are the topics of the events.
+type Topics struct {
+	// Blocks is the topic of the events of the blocks.
+	Blocks string
+
+	// Transactions is the topic of the events of the transactions of the
+	// contracts without a topic of their own.
+	Transactions string
+
+	// Contracts are the topics of the events of the transactions of specific
+	// contracts.
+	Contracts map[string]string
+}
+
+// TopicOf returns the topic of the event, or an empty string if it is not
+// published.
+func (t Topics) TopicOf(event events.Event) string {
+	if event.Block != nil {
+		return t.Blocks
+	}
+
+	if event.Transaction == nil {
+		return ""
+	}
+
+	topic, found := t.Contracts[event.Transaction.Contract]
+	if found {
+		return topic
+	}
+
+	return t.Transactions
+}
+
+// Option is the type of option to configure the bridge.
+type Option func(*Bridge)
+
+// WithPolicy sets the local policy of the node. The events of the transactions
+// of the contracts it does not serve are not published.
+func WithPolicy(policy native.Policy) Option {
+	return func(b *Bridge) {
+		b.policy = policy
+	}
+}
+
+// Bridge is a service that publishes the events of the new blocks.
+type Bridge struct {
+	sync.Mutex
+
+	blocks    blockstore.BlockStore
+	publisher Publisher
+	topics    Topics
+	policy    native.Policy
+	logger    zerolog.Logger
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewBridge creates a new bridge that publishes the events of the blocks of
+// the store with the publisher.
+func NewBridge(blocks blockstore.BlockStore, publisher Publisher, topics Topics, opts ...Option) *Bridge {
+	b := &Bridge{
+		blocks:    blocks,
+		publisher: publisher,
+		topics:    topics,
+		logger:    dela.Logger.With().Str("service", "bridge").Logger(),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Start starts to publish the events of the blocks committed from now on.
+func (b *Bridge) Start() {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	b.cancel = cancel
+	b.done = make(chan struct{})
+
+	go b.run(b.blocks.Watch(ctx), b.done)
+}
+
+// Stop stops to publish the events and closes the publisher.
+func (b *Bridge) Stop() error {
+	b.Lock()
+	cancel, done := b.cancel, b.done
+	b.cancel = nil
+	b.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+
+	err := b.publisher.Close()
+	if err != nil {
+		return xerrors.Errorf("failed to close publisher: %v", err)
+	}
+
+	return nil
+}
+
+// Publish publishes the events of the block. It returns the first error, but
+// still tries to publish the other events.
+func (b *Bridge) Publish(block types.Block) error {
+	filter := events.Filter{
+		Blocks: b.topics.Blocks != "",
+		Txs:    true,
+		Policy: b.policy,
+	}
+
+	var first error
+
+	for _, event := range filter.Events(block) {
+		topic := b.topics.TopicOf(event)
+		if topic == "" {
+			continue
+		}
+
+		payload, err := json.Marshal(event)
+		if err == nil {
+			err = b.publisher.Publish(topic, payload)
+		}
+
+		if err != nil && first == nil {
+			first = xerrors.Errorf("failed to publish to '%s': %v", topic, err)
+		}
+	}
+
+	return first
+}
+
+func (b *Bridge) run(watch <-chan types.BlockLink, done chan struct{}) {
+	defer close(done)
+
+	for link := range watch {
+		err := b.Publish(link.GetBlock())
+		if err != nil {
+			b.logger.Warn().Err(err).
+				Uint64("index", link.GetBlock().GetIndex()).
+				Msg("events dropped")
+		}
+	}
+}
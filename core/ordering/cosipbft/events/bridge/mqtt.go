@@ -0,0 +1,264 @@
+package bridge
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// DefaultMQTTPort is the port of the broker when the URL has none.
+	DefaultMQTTPort = "1883"
+
+	// DialTimeout is the maximum amount of time to connect to a broker.
+	DialTimeout = 5 * time.Second
+
+	// WriteTimeout is the maximum amount of time to send a message to a
+	// broker, after which the connection is closed and the message dropped.
+	WriteTimeout = 5 * time.Second
+
+	mqttConnect    = 0x10
+	mqttConnack    = 0x20
+	mqttPublish    = 0x30
+	mqttDisconnect = 0xe0
+
+	mqttProtocolLevel = 4
+	mqttCleanSession  = 0x02
+	mqttPasswordFlag  = 0x40
+	mqttUsernameFlag  = 0x80
+
+	// mqttMaxLength is the maximum remaining length of a packet.
+	mqttMaxLength = 268435455
+)
+
+// MQTTPublisher is a client of an MQTT 3.1.1 broker that publishes the messages
+// with the quality of service 0, which means at most once. The connection is
+// opened on the first message and opened again after a failure.
+//
+// - implements bridge.Publisher
+type MQTTPublisher struct {
+	sync.Mutex
+
+	addr     string
+	username string
+	password *string
+	conn     net.Conn
+	timeout  time.Duration
+}
+
+// NewMQTTPublisher returns a new publisher to the broker of the URL.
+func NewMQTTPublisher(u *url.URL) *MQTTPublisher {
+	pub := &MQTTPublisher{
+		addr:    hostWithPort(u, DefaultMQTTPort),
+		timeout: WriteTimeout,
+	}
+
+	if u.User != nil {
+		pub.username = u.User.Username()
+
+		password, set := u.User.Password()
+		if set {
+			pub.password = &password
+		}
+	}
+
+	return pub
+}
+
+// Publish implements bridge.Publisher. It sends a PUBLISH packet to the broker.
+// The connection is closed when the broker doesn't read the packet in time, so
+// that a stalled broker never blocks the publisher.
+func (p *MQTTPublisher) Publish(topic string, payload []byte) error {
+	if topic == "" || len(topic) > 0xffff {
+		return xerrors.Errorf("invalid topic '%s'", topic)
+	}
+
+	body := new(bytes.Buffer)
+	writeMQTTString(body, topic)
+	body.Write(payload)
+
+	packet, err := makeMQTTPacket(mqttPublish, body.Bytes())
+	if err != nil {
+		return xerrors.Errorf("packet: %v", err)
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if p.conn == nil {
+		err = p.connect()
+		if err != nil {
+			return xerrors.Errorf("failed to connect: %v", err)
+		}
+	}
+
+	err = writeWithTimeout(p.conn, packet, p.timeout)
+	if err != nil {
+		p.conn.Close()
+		p.conn = nil
+
+		return xerrors.Errorf("failed to write: %v", err)
+	}
+
+	return nil
+}
+
+// Close implements bridge.Publisher. It sends a DISCONNECT packet and closes
+// the connection if it is opened.
+func (p *MQTTPublisher) Close() error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+
+	conn := p.conn
+	p.conn = nil
+
+	// The broker closes the connection anyway, so the error is ignored.
+	writeWithTimeout(conn, []byte{mqttDisconnect, 0}, p.timeout)
+
+	err := conn.Close()
+	if err != nil {
+		return xerrors.Errorf("failed to close: %v", err)
+	}
+
+	return nil
+}
+
+// connect opens the connection and waits for the acknowledgement of the
+// broker. The client identifier is left empty so that the broker assigns a
+// unique one to each node.
+func (p *MQTTPublisher) connect() error {
+	flags := byte(mqttCleanSession)
+	if p.username != "" {
+		flags |= mqttUsernameFlag
+	}
+	if p.password != nil {
+		flags |= mqttPasswordFlag
+	}
+
+	body := new(bytes.Buffer)
+	writeMQTTString(body, "MQTT")
+	body.WriteByte(mqttProtocolLevel)
+	body.WriteByte(flags)
+	// The keep alive is disabled as the publisher stays silent between the
+	// blocks.
+	body.Write([]byte{0, 0})
+	// Client identifier
+	writeMQTTString(body, "")
+
+	if p.username != "" {
+		writeMQTTString(body, p.username)
+	}
+	if p.password != nil {
+		writeMQTTString(body, *p.password)
+	}
+
+	packet, err := makeMQTTPacket(mqttConnect, body.Bytes())
+	if err != nil {
+		return xerrors.Errorf("packet: %v", err)
+	}
+
+	conn, err := net.DialTimeout("tcp", p.addr, DialTimeout)
+	if err != nil {
+		return xerrors.Errorf("dial: %v", err)
+	}
+
+	conn.SetDeadline(time.Now().Add(DialTimeout))
+
+	_, err = conn.Write(packet)
+	if err != nil {
+		conn.Close()
+		return xerrors.Errorf("failed to write: %v", err)
+	}
+
+	ack := make([]byte, 4)
+	_, err = io.ReadFull(conn, ack)
+	if err != nil {
+		conn.Close()
+		return xerrors.Errorf("failed to read: %v", err)
+	}
+
+	if ack[0] != mqttConnack || ack[1] != 2 {
+		conn.Close()
+		return xerrors.Errorf("unexpected packet %#x", ack[:2])
+	}
+
+	if ack[3] != 0 {
+		conn.Close()
+		return xerrors.Errorf("connection refused with code %d", ack[3])
+	}
+
+	conn.SetDeadline(time.Time{})
+
+	p.conn = conn
+
+	return nil
+}
+
+func makeMQTTPacket(header byte, body []byte) ([]byte, error) {
+	if len(body) > mqttMaxLength {
+		return nil, xerrors.Errorf("packet too large: %d", len(body))
+	}
+
+	packet := []byte{header}
+
+	// The remaining length is encoded with 7 bits per byte and the most
+	// significant bit tells if another byte follows.
+	length := len(body)
+	for {
+		digit := byte(length % 128)
+		length /= 128
+
+		if length > 0 {
+			digit |= 0x80
+		}
+
+		packet = append(packet, digit)
+
+		if length == 0 {
+			break
+		}
+	}
+
+	return append(packet, body...), nil
+}
+
+func writeMQTTString(buffer *bytes.Buffer, value string) {
+	size := make([]byte, 2)
+	binary.BigEndian.PutUint16(size, uint16(len(value)))
+
+	buffer.Write(size)
+	buffer.WriteString(value)
+}
+
+// writeWithTimeout writes the data to the connection with a deadline.
+func writeWithTimeout(conn net.Conn, data []byte, timeout time.Duration) error {
+	err := conn.SetWriteDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return xerrors.Errorf("deadline: %v", err)
+	}
+
+	_, err = conn.Write(data)
+	if err != nil {
+		return err
+	}
+
+	return conn.SetWriteDeadline(time.Time{})
+}
+
+func hostWithPort(u *url.URL, port string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+
+	return net.JoinHostPort(u.Hostname(), port)
+}
@@ -0,0 +1,194 @@
+package bridge
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMQTTPublisher_Publish(t *testing.T) {
+	broker := newFakeMQTTBroker(t, 0)
+	defer broker.Close()
+
+	pub := NewMQTTPublisher(&url.URL{
+		Host: broker.Addr().String(),
+		User: url.UserPassword("user", "secret"),
+	})
+
+	err := pub.Publish("dela/blocks", []byte("{}"))
+	require.NoError(t, err)
+
+	connect := <-broker.packets
+	require.Equal(t, byte(mqttConnect), connect.header)
+	require.Equal(t, []byte{0, 4, 'M', 'Q', 'T', 'T', 4, 0xc2, 0, 0, 0, 0,
+		0, 4, 'u', 's', 'e', 'r', 0, 6, 's', 'e', 'c', 'r', 'e', 't'}, connect.body)
+
+	publish := <-broker.packets
+	require.Equal(t, byte(mqttPublish), publish.header)
+	require.Equal(t, append([]byte{0, 11}, "dela/blocks{}"...), publish.body)
+
+	// The connection is reused.
+	err = pub.Publish("dela/txs", make([]byte, 200))
+	require.NoError(t, err)
+
+	publish = <-broker.packets
+	require.Equal(t, byte(mqttPublish), publish.header)
+	require.Len(t, publish.body, 210)
+
+	require.NoError(t, pub.Close())
+	require.NoError(t, pub.Close())
+
+	disconnect := <-broker.packets
+	require.Equal(t, byte(mqttDisconnect), disconnect.header)
+
+	err = pub.Publish("", nil)
+	require.EqualError(t, err, "invalid topic ''")
+}
+
+func TestMQTTPublisher_Refused_Publish(t *testing.T) {
+	broker := newFakeMQTTBroker(t, 5)
+	defer broker.Close()
+
+	pub := NewMQTTPublisher(&url.URL{Host: broker.Addr().String()})
+
+	err := pub.Publish("dela/blocks", nil)
+	require.EqualError(t, err, "failed to connect: connection refused with code 5")
+
+	connect := <-broker.packets
+	require.Equal(t, byte(0x02), connect.body[7])
+
+	broker.Close()
+
+	err = pub.Publish("dela/blocks", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to connect: dial: ")
+}
+
+func TestMQTTPublisher_Stalled_Publish(t *testing.T) {
+	broker := newFakeMQTTBroker(t, 0)
+	broker.stall = make(chan struct{})
+	defer close(broker.stall)
+	defer broker.Close()
+
+	pub := NewMQTTPublisher(&url.URL{Host: broker.Addr().String()})
+	pub.timeout = 50 * time.Millisecond
+
+	// The broker never reads the packets, therefore the publisher gives up
+	// when the buffers of the connection are full.
+	err := publishUntilError(func() error {
+		return pub.Publish("dela/blocks", make([]byte, 1<<20))
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to write: ")
+	require.Contains(t, err.Error(), "i/o timeout")
+	require.Nil(t, pub.conn)
+}
+
+func TestMakeMQTTPacket(t *testing.T) {
+	packet, err := makeMQTTPacket(mqttPublish, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte{mqttPublish, 0}, packet)
+
+	packet, err = makeMQTTPacket(mqttPublish, make([]byte, 16384))
+	require.NoError(t, err)
+	require.Equal(t, []byte{mqttPublish, 0x80, 0x80, 0x01}, packet[:4])
+
+	_, err = makeMQTTPacket(mqttPublish, make([]byte, mqttMaxLength+1))
+	require.EqualError(t, err, "packet too large: 268435456")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// publishUntilError publishes until an error is returned, or gives up after a
+// number of attempts.
+func publishUntilError(publish func() error) error {
+	for i := 0; i < 100; i++ {
+		err := publish()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type fakeMQTTPacket struct {
+	header byte
+	body   []byte
+}
+
+// fakeMQTTBroker is a broker that accepts a connection at a time, answers the
+// CONNECT packet with the return code and forwards every packet it reads. A
+// stalled broker stops to read after the CONNECT packet until the channel is
+// closed.
+type fakeMQTTBroker struct {
+	net.Listener
+
+	packets chan fakeMQTTPacket
+	stall   chan struct{}
+}
+
+func newFakeMQTTBroker(t *testing.T, code byte) *fakeMQTTBroker {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	broker := &fakeMQTTBroker{
+		Listener: lis,
+		packets:  make(chan fakeMQTTPacket, 10),
+	}
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+
+			go broker.serve(conn, code)
+		}
+	}()
+
+	return broker
+}
+
+func (b *fakeMQTTBroker) serve(conn net.Conn, code byte) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+
+	for {
+		header, err := reader.ReadByte()
+		if err != nil {
+			return
+		}
+
+		length, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return
+		}
+
+		body := make([]byte, length)
+		_, err = io.ReadFull(reader, body)
+		if err != nil {
+			return
+		}
+
+		b.packets <- fakeMQTTPacket{header: header, body: body}
+
+		if header == mqttConnect {
+			conn.Write([]byte{mqttConnack, 2, 0, code})
+
+			if b.stall != nil {
+				<-b.stall
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package bridge
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.dedis.ch/dela"
+	"golang.org/x/xerrors"
+)
+
+// DefaultNATSPort is the port of the server when the URL has none.
+const DefaultNATSPort = "4222"
+
+// natsInfo is the part of the INFO message of the server that the publisher
+// needs.
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+}
+
+// natsConnect is the CONNECT message of the publisher.
+type natsConnect struct {
+	Verbose   bool   `json:"verbose"`
+	Pedantic  bool   `json:"pedantic"`
+	Name      string `json:"name"`
+	Lang      string `json:"lang"`
+	User      string `json:"user,omitempty"`
+	Pass      string `json:"pass,omitempty"`
+	AuthToken string `json:"auth_token,omitempty"`
+}
+
+// NATSPublisher is a client of a NATS server that publishes the messages to
+// subjects. The connection is opened on the first message and opened again
+// after a failure.
+//
+// - implements bridge.Publisher
+type NATSPublisher struct {
+	sync.Mutex
+
+	addr    string
+	connect natsConnect
+	conn    net.Conn
+	timeout time.Duration
+	logger  zerolog.Logger
+}
+
+// NewNATSPublisher returns a new publisher to the server of the URL. A URL with
+// only a user name uses it as the authentication token.
+func NewNATSPublisher(u *url.URL) *NATSPublisher {
+	pub := &NATSPublisher{
+		addr:    hostWithPort(u, DefaultNATSPort),
+		timeout: WriteTimeout,
+		connect: natsConnect{
+			Name: "dela",
+			Lang: "go",
+		},
+		logger: dela.Logger.With().Str("service", "bridge").Logger(),
+	}
+
+	if u.User != nil {
+		password, set := u.User.Password()
+		if set {
+			pub.connect.User = u.User.Username()
+			pub.connect.Pass = password
+		} else {
+			pub.connect.AuthToken = u.User.Username()
+		}
+	}
+
+	return pub
+}
+
+// Publish implements bridge.Publisher. It sends a PUB message to the server.
+// The connection is closed when the server doesn't read the message in time, so
+// that a stalled server never blocks the publisher.
+func (p *NATSPublisher) Publish(subject string, payload []byte) error {
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return xerrors.Errorf("invalid subject '%s'", subject)
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if p.conn == nil {
+		err := p.open()
+		if err != nil {
+			return xerrors.Errorf("failed to connect: %v", err)
+		}
+	}
+
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\n", subject, len(payload), payload)
+
+	err := writeWithTimeout(p.conn, []byte(msg), p.timeout)
+	if err != nil {
+		p.conn.Close()
+		p.conn = nil
+
+		return xerrors.Errorf("failed to write: %v", err)
+	}
+
+	return nil
+}
+
+// Close implements bridge.Publisher. It closes the connection if it is opened.
+func (p *NATSPublisher) Close() error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+
+	conn := p.conn
+	p.conn = nil
+
+	err := conn.Close()
+	if err != nil {
+		return xerrors.Errorf("failed to close: %v", err)
+	}
+
+	return nil
+}
+
+// open opens the connection and waits for the server to answer a PING so that
+// an authentication failure is reported to the caller. It then listens for the
+// messages of the server in the background.
+func (p *NATSPublisher) open() error {
+	conn, err := net.DialTimeout("tcp", p.addr, DialTimeout)
+	if err != nil {
+		return xerrors.Errorf("dial: %v", err)
+	}
+
+	conn.SetDeadline(time.Now().Add(DialTimeout))
+
+	reader := bufio.NewReader(conn)
+
+	err = p.handshake(conn, reader)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	conn.SetDeadline(time.Time{})
+
+	p.conn = conn
+
+	go p.listen(conn, reader)
+
+	return nil
+}
+
+func (p *NATSPublisher) handshake(conn net.Conn, reader *bufio.Reader) error {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return xerrors.Errorf("failed to read: %v", err)
+	}
+
+	if !strings.HasPrefix(line, "INFO ") {
+		return xerrors.Errorf("unexpected message '%s'", strings.TrimSpace(line))
+	}
+
+	var info natsInfo
+	err = json.Unmarshal([]byte(line[len("INFO "):]), &info)
+	if err != nil {
+		return xerrors.Errorf("invalid info: %v", err)
+	}
+
+	if info.TLSRequired {
+		return xerrors.New("server requires TLS")
+	}
+
+	data, err := json.Marshal(p.connect)
+	if err != nil {
+		return xerrors.Errorf("failed to encode: %v", err)
+	}
+
+	_, err = fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", data)
+	if err != nil {
+		return xerrors.Errorf("failed to write: %v", err)
+	}
+
+	line, err = reader.ReadString('\n')
+	if err != nil {
+		return xerrors.Errorf("failed to read: %v", err)
+	}
+
+	line = strings.TrimSpace(line)
+	if line != "PONG" {
+		return xerrors.Errorf("connection refused: %s", line)
+	}
+
+	return nil
+}
+
+// listen answers the PING messages of the server so that it keeps the
+// connection, and forgets the connection when it is closed.
+func (p *NATSPublisher) listen(conn net.Conn, reader *bufio.Reader) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			p.Lock()
+			if p.conn == conn {
+				conn.Close()
+				p.conn = nil
+			}
+			p.Unlock()
+
+			return
+		}
+
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "PING":
+			p.Lock()
+			writeWithTimeout(conn, []byte("PONG\r\n"), p.timeout)
+			p.Unlock()
+		case strings.HasPrefix(line, "-ERR"):
+			p.logger.Warn().Str("error", line).Msg("server error")
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package bridge
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNATSPublisher_Publish(t *testing.T) {
+	server := newFakeNATSServer(t, "{}", "PONG")
+	defer server.Close()
+
+	pub := NewNATSPublisher(&url.URL{
+		Host: server.Addr().String(),
+		User: url.UserPassword("user", "secret"),
+	})
+
+	err := pub.Publish("dela.blocks", []byte("{}"))
+	require.NoError(t, err)
+
+	require.Equal(t, `CONNECT {"verbose":false,"pedantic":false,"name":"dela",`+
+		`"lang":"go","user":"user","pass":"secret"}`, <-server.lines)
+	require.Equal(t, "PING", <-server.lines)
+	require.Equal(t, "PUB dela.blocks 2", <-server.lines)
+	require.Equal(t, "{}", <-server.lines)
+
+	// The publisher answers the PING of the server.
+	server.send("PING")
+	require.Equal(t, "PONG", <-server.lines)
+
+	err = pub.Publish("dela.txs", nil)
+	require.NoError(t, err)
+
+	require.Equal(t, "PUB dela.txs 0", <-server.lines)
+	require.Equal(t, "", <-server.lines)
+
+	require.NoError(t, pub.Close())
+	require.NoError(t, pub.Close())
+
+	err = pub.Publish("dela blocks", nil)
+	require.EqualError(t, err, "invalid subject 'dela blocks'")
+}
+
+func TestNATSPublisher_Reconnect_Publish(t *testing.T) {
+	server := newFakeNATSServer(t, "{}", "PONG")
+	defer server.Close()
+
+	pub := NewNATSPublisher(&url.URL{Host: server.Addr().String()})
+
+	err := pub.Publish("dela.blocks", nil)
+	require.NoError(t, err)
+
+	// The publisher forgets the connection closed by the server and opens a
+	// new one for the next message.
+	server.send("-ERR 'Stale Connection'")
+	server.disconnect()
+
+	require.Eventually(t, func() bool {
+		pub.Lock()
+		defer pub.Unlock()
+
+		return pub.conn == nil
+	}, time.Second, 10*time.Millisecond)
+
+	err = pub.Publish("dela.blocks", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, pub.Close())
+}
+
+func TestNATSPublisher_Refused_Publish(t *testing.T) {
+	server := newFakeNATSServer(t, "{}", "-ERR 'Authorization Violation'")
+	defer server.Close()
+
+	pub := NewNATSPublisher(&url.URL{Host: server.Addr().String(), User: url.User("token")})
+
+	err := pub.Publish("dela.blocks", nil)
+	require.EqualError(t, err,
+		"failed to connect: connection refused: -ERR 'Authorization Violation'")
+
+	require.Equal(t, `CONNECT {"verbose":false,"pedantic":false,"name":"dela",`+
+		`"lang":"go","auth_token":"token"}`, <-server.lines)
+
+	server = newFakeNATSServer(t, `{"tls_required":true}`, "PONG")
+	defer server.Close()
+
+	pub = NewNATSPublisher(&url.URL{Host: server.Addr().String()})
+
+	err = pub.Publish("dela.blocks", nil)
+	require.EqualError(t, err, "failed to connect: server requires TLS")
+
+	server = newFakeNATSServer(t, "{", "PONG")
+	defer server.Close()
+
+	pub = NewNATSPublisher(&url.URL{Host: server.Addr().String()})
+
+	err = pub.Publish("dela.blocks", nil)
+	require.EqualError(t, err,
+		"failed to connect: invalid info: unexpected end of JSON input")
+}
+
+func TestNATSPublisher_Stalled_Publish(t *testing.T) {
+	server := newFakeNATSServer(t, "{}", "PONG")
+	server.stall = make(chan struct{})
+	defer close(server.stall)
+	defer server.Close()
+
+	pub := NewNATSPublisher(&url.URL{Host: server.Addr().String()})
+	pub.timeout = 50 * time.Millisecond
+
+	// The server never reads the messages, therefore the publisher gives up
+	// when the buffers of the connection are full.
+	err := publishUntilError(func() error {
+		return pub.Publish("dela.blocks", make([]byte, 1<<20))
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to write: ")
+	require.Contains(t, err.Error(), "i/o timeout")
+	require.Nil(t, pub.conn)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// fakeNATSServer is a server that sends the INFO message to a new connection,
+// answers the first PING with the reply and forwards every line it reads. A
+// stalled server stops to read after the first PING until the channel is
+// closed.
+type fakeNATSServer struct {
+	net.Listener
+
+	lines chan string
+	conns chan net.Conn
+	stall chan struct{}
+}
+
+func newFakeNATSServer(t *testing.T, info, reply string) *fakeNATSServer {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeNATSServer{
+		Listener: lis,
+		lines:    make(chan string, 20),
+		conns:    make(chan net.Conn, 2),
+	}
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+
+			server.conns <- conn
+
+			go server.serve(conn, info, reply)
+		}
+	}()
+
+	return server
+}
+
+func (s *fakeNATSServer) serve(conn net.Conn, info, reply string) {
+	fmt.Fprintf(conn, "INFO %s\r\n", info)
+
+	reader := bufio.NewReader(conn)
+	answered := false
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimSuffix(line, "\r\n")
+
+		s.lines <- line
+
+		if line == "PING" && !answered {
+			answered = true
+			fmt.Fprintf(conn, "%s\r\n", reply)
+
+			if s.stall != nil {
+				<-s.stall
+				return
+			}
+		}
+	}
+}
+
+// send writes the message to the latest connection.
+func (s *fakeNATSServer) send(msg string) {
+	conn := <-s.conns
+	fmt.Fprintf(conn, "%s\r\n", msg)
+	s.conns <- conn
+}
+
+// disconnect closes the latest connection.
+func (s *fakeNATSServer) disconnect() {
+	conn := <-s.conns
+	conn.Close()
+}
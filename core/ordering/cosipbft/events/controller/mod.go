@@ -0,0 +1,104 @@
+// Package controller implements a CLI controller to register the WebSocket
+// endpoint of the events on the proxy.
+package controller
+
+import (
+	"fmt"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/events"
+	"go.dedis.ch/dela/mino/proxy"
+	"golang.org/x/xerrors"
+)
+
+const defaultPath = "/events"
+
+// NewController returns a new controller that provides the command to register
+// the WebSocket endpoint.
+func NewController() node.Initializer {
+	return minimal{}
+}
+
+// minimal is an initializer with the command to register the endpoint.
+//
+// - implements node.Initializer
+type minimal struct{}
+
+// SetCommands implements node.Initializer. It sets the command to register the
+// endpoint on the proxy, which must be started beforehand.
+func (minimal) SetCommands(builder node.Builder) {
+	cmd := builder.SetCommand("events")
+	cmd.SetDescription("WebSocket endpoint pushing the events of the chain")
+
+	sub := cmd.SetSubCommand("register")
+	sub.SetDescription("register the endpoint on the proxy")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name:  "path",
+			Usage: "the path of the endpoint",
+			Value: defaultPath,
+		},
+		cli.StringSliceFlag{
+			Name:  "origins",
+			Usage: "origins of the browsers allowed in addition to the proxy, or '*' for any",
+		},
+	)
+	sub.SetAction(builder.MakeAction(registerAction{}))
+}
+
+// OnStart implements node.Initializer. It does nothing.
+func (minimal) OnStart(flags cli.Flags, inj node.Injector) error {
+	return nil
+}
+
+// OnStop implements node.Initializer. It does nothing.
+func (minimal) OnStop(inj node.Injector) error {
+	return nil
+}
+
+// registerAction is an action to register the endpoint on the proxy.
+//
+// - implements node.ActionTemplate
+type registerAction struct{}
+
+// Execute implements node.ActionTemplate. It registers the endpoint that pushes
+// the events of the block store.
+func (registerAction) Execute(ctx node.Context) error {
+	var p proxy.Proxy
+	err := ctx.Injector.Resolve(&p)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var blocks blockstore.BlockStore
+	err = ctx.Injector.Resolve(&blocks)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	// The local policy is applied when the execution is native.
+	policy := native.Policy{}
+
+	var exec *native.Service
+	err = ctx.Injector.Resolve(&exec)
+	if err == nil {
+		policy = exec.GetPolicy()
+	}
+
+	path := ctx.Flags.String("path")
+
+	handler := events.NewHandler(blocks, policy,
+		events.WithOrigins(ctx.Flags.StringSlice("origins")...))
+
+	err = p.RegisterHandler(path, handler)
+	if err != nil {
+		return xerrors.Errorf("failed to register handler: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "events endpoint registered on %s", path)
+
+	return nil
+}
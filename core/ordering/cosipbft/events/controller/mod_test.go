@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino/proxy"
+)
+
+func TestMinimal_SetCommands(t *testing.T) {
+	m := NewController()
+
+	b := node.NewBuilder()
+	m.SetCommands(b)
+}
+
+func TestMinimal_OnStart(t *testing.T) {
+	err := NewController().OnStart(make(node.FlagSet), node.NewInjector())
+	require.NoError(t, err)
+}
+
+func TestMinimal_OnStop(t *testing.T) {
+	err := NewController().OnStop(node.NewInjector())
+	require.NoError(t, err)
+}
+
+func TestRegisterAction_Execute(t *testing.T) {
+	p := &fakeProxy{}
+
+	out := new(bytes.Buffer)
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Flags:    node.FlagSet{"path": "/events", "origins": []interface{}{"*"}},
+		Out:      out,
+	}
+
+	err := registerAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'proxy.Proxy'")
+
+	ctx.Injector.Inject(p)
+	err = registerAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'blockstore.BlockStore'")
+
+	ctx.Injector.Inject(blockstore.NewInMemory())
+	err = registerAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "/events", p.path)
+	require.Equal(t, "events endpoint registered on /events", out.String())
+
+	p.err = fake.GetError()
+	err = registerAction{}.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to register handler"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeProxy struct {
+	proxy.Proxy
+
+	path string
+	err  error
+}
+
+func (p *fakeProxy) RegisterHandler(path string, handler func(http.ResponseWriter, *http.Request)) error {
+	p.path = path
+
+	return p.err
+}
@@ -0,0 +1,349 @@
+// Package events implements a WebSocket endpoint that pushes the events of the
+// chain to browser clients, which cannot easily consume the Go watch API or
+// gRPC streams.
+//
+// A client connects to the endpoint with the filters in the query string:
+//   - from: the index of the first block to push, which allows a client to
+//     resume from the cursor of the last event it received plus one.
+//   - types: a comma-separated list of the types of event, either 'block' or
+//     'transaction'. Both are pushed by default.
+//   - contract: only pushes the transactions of the contract.
+//   - identity: only pushes the transactions of the identity.
+//   - accepted: only pushes the transactions with the status.
+//
+// Each event is a JSON text message with a cursor, which is the index of the
+// block the event belongs to.
+package events
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"golang.org/x/net/websocket"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// BlockType is the type of the events of a new block.
+	BlockType = "block"
+
+	// TransactionType is the type of the events of a transaction.
+	TransactionType = "transaction"
+)
+
+// Event is the message pushed to the clients. Only the field of the type of
+// the event is set.
+type Event struct {
+	Type        string            `json:"type"`
+	Cursor      uint64            `json:"cursor"`
+	Block       *BlockEvent       `json:"block,omitempty"`
+	Transaction *TransactionEvent `json:"transaction,omitempty"`
+}
+
+// BlockEvent is the content of the event of a new block.
+type BlockEvent struct {
+	Index uint64 `json:"index"`
+	Hash  string `json:"hash"`
+	Size  int    `json:"size"`
+}
+
+// TransactionEvent is the content of the event of a transaction.
+type TransactionEvent struct {
+	Block    uint64 `json:"block"`
+	Position int    `json:"position"`
+	ID       string `json:"id"`
+	Nonce    uint64 `json:"nonce"`
+	Identity string `json:"identity"`
+	Contract string `json:"contract"`
+	Accepted bool   `json:"accepted"`
+	Reason   string `json:"reason"`
+}
+
+// Filter is the set of conditions of the events pushed to a client.
+type Filter struct {
+	From     uint64
+	Blocks   bool
+	Txs      bool
+	Contract string
+	Identity string
+	Accepted *bool
+
+	// Policy is the local policy of the node. The transactions of the
+	// contracts it does not serve are never pushed.
+	Policy native.Policy
+}
+
+// ParseFilter parses the filter from the query string of a request.
+func ParseFilter(r *http.Request) (Filter, error) {
+	query := r.URL.Query()
+
+	filter := Filter{
+		Blocks:   true,
+		Txs:      true,
+		Contract: query.Get("contract"),
+		Identity: query.Get("identity"),
+	}
+
+	from := query.Get("from")
+	if from != "" {
+		index, err := strconv.ParseUint(from, 10, 64)
+		if err != nil {
+			return filter, xerrors.Errorf("invalid cursor '%s'", from)
+		}
+
+		filter.From = index
+	}
+
+	kinds := query.Get("types")
+	if kinds != "" {
+		filter.Blocks = false
+		filter.Txs = false
+
+		for _, kind := range strings.Split(kinds, ",") {
+			switch kind {
+			case BlockType:
+				filter.Blocks = true
+			case TransactionType:
+				filter.Txs = true
+			default:
+				return filter, xerrors.Errorf("unknown type '%s'", kind)
+			}
+		}
+	}
+
+	accepted := query.Get("accepted")
+	if accepted != "" {
+		value, err := strconv.ParseBool(accepted)
+		if err != nil {
+			return filter, xerrors.Errorf("invalid status '%s'", accepted)
+		}
+
+		filter.Accepted = &value
+	}
+
+	return filter, nil
+}
+
+// Events returns the events of the block that match the filter.
+func (f Filter) Events(block types.Block) []Event {
+	events := []Event{}
+
+	if f.Blocks {
+		events = append(events, Event{
+			Type:   BlockType,
+			Cursor: block.GetIndex(),
+			Block: &BlockEvent{
+				Index: block.GetIndex(),
+				Hash:  hex.EncodeToString(block.GetHash().Bytes()),
+				Size:  len(block.GetData().GetTransactionResults()),
+			},
+		})
+	}
+
+	if !f.Txs {
+		return events
+	}
+
+	for i, res := range block.GetData().GetTransactionResults() {
+		tx := res.GetTransaction()
+		accepted, reason := res.GetStatus()
+
+		identity, err := tx.GetIdentity().MarshalText()
+		if err != nil {
+			continue
+		}
+
+		contract := string(tx.GetArg(native.ContractArg))
+
+		if f.Contract != "" && f.Contract != contract {
+			continue
+		}
+
+		if f.Policy.Check(contract) != nil {
+			continue
+		}
+
+		if f.Identity != "" && f.Identity != string(identity) {
+			continue
+		}
+
+		if f.Accepted != nil && *f.Accepted != accepted {
+			continue
+		}
+
+		events = append(events, Event{
+			Type:   TransactionType,
+			Cursor: block.GetIndex(),
+			Transaction: &TransactionEvent{
+				Block:    block.GetIndex(),
+				Position: i,
+				ID:       hex.EncodeToString(tx.GetID()),
+				Nonce:    tx.GetNonce(),
+				Identity: string(identity),
+				Contract: contract,
+				Accepted: accepted,
+				Reason:   reason,
+			},
+		})
+	}
+
+	return events
+}
+
+// HandlerOption is the type of option to configure the handler.
+type HandlerOption func(*handlerTemplate)
+
+type handlerTemplate struct {
+	origins []string
+}
+
+// WithOrigins sets the origins of the browsers allowed to connect to the
+// endpoint, in addition to the origin of the endpoint itself. The wildcard '*'
+// allows any origin.
+func WithOrigins(origins ...string) HandlerOption {
+	return func(tmpl *handlerTemplate) {
+		tmpl.origins = append(tmpl.origins, origins...)
+	}
+}
+
+// NewHandler returns an HTTP handler that upgrades the connections to
+// WebSocket and pushes the events of the blocks until the client leaves. The
+// transactions of the contracts not served by the policy are left out.
+//
+// A browser always announces the page that opens the connection, so that the
+// handshake is refused when the origin is neither the one of the endpoint nor
+// one of the allowed origins. Clients that are not browsers may omit it.
+func NewHandler(blocks blockstore.BlockStore, policy native.Policy,
+	opts ...HandlerOption) func(http.ResponseWriter, *http.Request) {
+
+	tmpl := handlerTemplate{}
+	for _, opt := range opts {
+		opt(&tmpl)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := ParseFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		filter.Policy = policy
+
+		srv := websocket.Server{
+			Handshake: func(cfg *websocket.Config, r *http.Request) error {
+				origin, err := websocket.Origin(cfg, r)
+				if err != nil {
+					return err
+				}
+
+				return checkOrigin(origin, r.Host, tmpl.origins)
+			},
+			Handler: func(ws *websocket.Conn) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				go func() {
+					// The client is not expected to send anything, but the
+					// reads handle the control frames until it leaves.
+					var msg []byte
+					for {
+						err := websocket.Message.Receive(ws, &msg)
+						if err != nil {
+							cancel()
+							return
+						}
+					}
+				}()
+
+				err := push(ctx, ws, blocks, filter)
+				if err != nil {
+					dela.Logger.Debug().Err(err).Msg("websocket client stopped")
+				}
+			},
+		}
+
+		srv.ServeHTTP(w, r)
+	}
+}
+
+// checkOrigin returns nil if the origin is missing, is the one of the host or
+// is allowed, otherwise an error.
+func checkOrigin(origin *url.URL, host string, allowed []string) error {
+	if origin == nil || origin.Host == host {
+		return nil
+	}
+
+	value := origin.Scheme + "://" + origin.Host
+
+	for _, o := range allowed {
+		if o == "*" || o == value {
+			return nil
+		}
+	}
+
+	return xerrors.Errorf("origin '%s' not allowed", value)
+}
+
+// push sends the events of the blocks from the index of the filter, and then
+// the events of the new blocks. The watch starts before the replay so that no
+// block is missed in between.
+func push(ctx context.Context, c *websocket.Conn, blocks blockstore.BlockStore, filter Filter) error {
+	watch := blocks.Watch(ctx)
+
+	next := filter.From
+
+	for ; next < blocks.Len(); next++ {
+		link, err := blocks.GetByIndex(next)
+		if err != nil {
+			return xerrors.Errorf("block %d: %v", next, err)
+		}
+
+		err = send(c, filter.Events(link.GetBlock()))
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case link, more := <-watch:
+			if !more {
+				return nil
+			}
+
+			index := link.GetBlock().GetIndex()
+			if index < next {
+				continue
+			}
+
+			err := send(c, filter.Events(link.GetBlock()))
+			if err != nil {
+				return err
+			}
+
+			next = index + 1
+		}
+	}
+}
+
+func send(c *websocket.Conn, events []Event) error {
+	for _, event := range events {
+		err := websocket.JSON.Send(c, event)
+		if err != nil {
+			return xerrors.Errorf("failed to send event: %v", err)
+		}
+	}
+
+	return nil
+}
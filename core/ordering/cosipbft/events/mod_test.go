@@ -0,0 +1,158 @@
+package events
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"golang.org/x/net/websocket"
+)
+
+func TestParseFilter(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/events?from=3&types=transaction&contract=value&accepted=true", nil)
+
+	filter, err := ParseFilter(req)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), filter.From)
+	require.False(t, filter.Blocks)
+	require.True(t, filter.Txs)
+	require.Equal(t, "value", filter.Contract)
+	require.True(t, *filter.Accepted)
+
+	filter, err = ParseFilter(httptest.NewRequest(http.MethodGet, "/events", nil))
+	require.NoError(t, err)
+	require.True(t, filter.Blocks)
+	require.True(t, filter.Txs)
+	require.Nil(t, filter.Accepted)
+
+	_, err = ParseFilter(httptest.NewRequest(http.MethodGet, "/events?from=a", nil))
+	require.EqualError(t, err, "invalid cursor 'a'")
+
+	_, err = ParseFilter(httptest.NewRequest(http.MethodGet, "/events?types=a", nil))
+	require.EqualError(t, err, "unknown type 'a'")
+
+	_, err = ParseFilter(httptest.NewRequest(http.MethodGet, "/events?accepted=a", nil))
+	require.EqualError(t, err, "invalid status 'a'")
+}
+
+func TestFilter_Events(t *testing.T) {
+	link := makeLink(t, types.Digest{}, 0)
+
+	events := Filter{Blocks: true, Txs: true}.Events(link.GetBlock())
+	require.Len(t, events, 3)
+	require.Equal(t, BlockType, events[0].Type)
+	require.Equal(t, 2, events[0].Block.Size)
+	require.Equal(t, TransactionType, events[1].Type)
+	require.Equal(t, "value", events[1].Transaction.Contract)
+	require.Equal(t, "PK", events[1].Transaction.Identity)
+
+	accepted := false
+	events = Filter{Txs: true, Accepted: &accepted}.Events(link.GetBlock())
+	require.Len(t, events, 1)
+	require.Equal(t, 1, events[0].Transaction.Position)
+
+	events = Filter{Txs: true, Contract: "unknown"}.Events(link.GetBlock())
+	require.Empty(t, events)
+
+	events = Filter{Txs: true, Identity: "unknown"}.Events(link.GetBlock())
+	require.Empty(t, events)
+
+	events = Filter{Txs: true, Policy: native.NewDenyList("value")}.Events(link.GetBlock())
+	require.Empty(t, events)
+}
+
+func TestHandler(t *testing.T) {
+	blocks := blockstore.NewInMemory()
+
+	first := makeLink(t, types.Digest{}, 0)
+	require.NoError(t, blocks.Store(first))
+
+	second := makeLink(t, first.GetTo(), 1)
+	require.NoError(t, blocks.Store(second))
+
+	handler := NewHandler(blocks, native.Policy{}, WithOrigins("http://dashboard"))
+
+	srv := httptest.NewServer(http.HandlerFunc(handler))
+	defer srv.Close()
+
+	addr := srv.Listener.Addr().String()
+
+	ws, err := websocket.Dial("ws://"+addr+"/?from=1&types=block", "", "http://"+addr)
+	require.NoError(t, err)
+
+	// The replay starts from the cursor.
+	var evt Event
+	require.NoError(t, websocket.JSON.Receive(ws, &evt))
+	require.Equal(t, BlockType, evt.Type)
+	require.Equal(t, uint64(1), evt.Cursor)
+
+	// The new blocks are pushed.
+	require.NoError(t, blocks.Store(makeLink(t, second.GetTo(), 2)))
+
+	require.NoError(t, websocket.JSON.Receive(ws, &evt))
+	require.Equal(t, uint64(2), evt.Cursor)
+
+	require.NoError(t, ws.Close())
+
+	ws, err = websocket.Dial("ws://"+addr+"/", "", "http://dashboard")
+	require.NoError(t, err)
+	require.NoError(t, ws.Close())
+
+	_, err = websocket.Dial("ws://"+addr+"/", "", "http://evil")
+	require.EqualError(t, err, "websocket.Dial ws://"+addr+"/: bad status")
+
+	resp, err := http.Get(srv.URL + "/?from=a")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestCheckOrigin(t *testing.T) {
+	err := checkOrigin(nil, "node:8080", nil)
+	require.NoError(t, err)
+
+	err = checkOrigin(&url.URL{Scheme: "http", Host: "node:8080"}, "node:8080", nil)
+	require.NoError(t, err)
+
+	err = checkOrigin(&url.URL{Scheme: "https", Host: "app"}, "node:8080", []string{"*"})
+	require.NoError(t, err)
+
+	err = checkOrigin(&url.URL{Scheme: "https", Host: "app"}, "node:8080", []string{"http://app"})
+	require.EqualError(t, err, "origin 'https://app' not allowed")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// makeLink creates a block with two transactions of the value contract, the
+// second one being refused.
+func makeLink(t *testing.T, prev types.Digest, index uint64) types.BlockLink {
+	results := []simple.TransactionResult{}
+
+	for i := 0; i < 2; i++ {
+		tx, err := signed.NewTransaction(index*2+uint64(i), fake.PublicKey{},
+			signed.WithArg(native.ContractArg, []byte("value")))
+		require.NoError(t, err)
+
+		results = append(results, simple.NewTransactionResult(tx, i == 0, ""))
+	}
+
+	block, err := types.NewBlock(simple.NewResult(results), types.WithIndex(index))
+	require.NoError(t, err)
+
+	link, err := types.NewBlockLink(prev, block)
+	require.NoError(t, err)
+
+	return link
+}
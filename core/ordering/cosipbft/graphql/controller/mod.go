@@ -0,0 +1,109 @@
+// Package controller implements a CLI controller to register the GraphQL
+// endpoint on the proxy.
+package controller
+
+import (
+	"fmt"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/graphql"
+	"go.dedis.ch/dela/mino/proxy"
+	"golang.org/x/xerrors"
+)
+
+const defaultPath = "/graphql"
+
+// NewController returns a new controller that provides the command to register
+// the GraphQL endpoint.
+func NewController() node.Initializer {
+	return minimal{}
+}
+
+// minimal is an initializer with the command to register the endpoint.
+//
+// - implements node.Initializer
+type minimal struct{}
+
+// SetCommands implements node.Initializer. It sets the command to register the
+// endpoint on the proxy, which must be started beforehand.
+func (minimal) SetCommands(builder node.Builder) {
+	cmd := builder.SetCommand("graphql")
+	cmd.SetDescription("GraphQL endpoint for the chain data")
+
+	sub := cmd.SetSubCommand("register")
+	sub.SetDescription("register the endpoint on the proxy")
+	sub.SetFlags(cli.StringFlag{
+		Name:  "path",
+		Usage: "the path of the endpoint",
+		Value: defaultPath,
+	})
+	sub.SetAction(builder.MakeAction(registerAction{}))
+}
+
+// OnStart implements node.Initializer. It does nothing.
+func (minimal) OnStart(flags cli.Flags, inj node.Injector) error {
+	return nil
+}
+
+// OnStop implements node.Initializer. It does nothing.
+func (minimal) OnStop(inj node.Injector) error {
+	return nil
+}
+
+// registerAction is an action to register the endpoint on the proxy.
+//
+// - implements node.ActionTemplate
+type registerAction struct{}
+
+// Execute implements node.ActionTemplate. It registers the endpoint with the
+// schema over the block store and the ordering service.
+func (registerAction) Execute(ctx node.Context) error {
+	var p proxy.Proxy
+	err := ctx.Injector.Resolve(&p)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var blocks blockstore.BlockStore
+	err = ctx.Injector.Resolve(&blocks)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var srvc ordering.Service
+	err = ctx.Injector.Resolve(&srvc)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	opts := []graphql.SchemaOption{}
+
+	// The local policy is applied when the execution is native.
+	var exec *native.Service
+	err = ctx.Injector.Resolve(&exec)
+	if err == nil {
+		opts = append(opts, graphql.WithPolicy(exec.GetPolicy()))
+	}
+
+	var authorities blockstore.AuthorityStore
+	err = ctx.Injector.Resolve(&authorities)
+	if err == nil {
+		opts = append(opts, graphql.WithAuthorities(authorities))
+	}
+
+	schema := graphql.NewSchema(blocks, srvc, opts...)
+	path := ctx.Flags.String("path")
+
+	err = p.RegisterHandler(path, graphql.NewHandler(schema.Query()))
+	if err != nil {
+		return xerrors.Errorf("failed to register handler: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "graphql endpoint registered on %s", path)
+
+	return nil
+}
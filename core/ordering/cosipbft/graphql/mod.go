@@ -0,0 +1,258 @@
+// Package graphql implements a GraphQL endpoint that exposes the blocks, the
+// transactions and the state of a chain, so that dashboards can query the data
+// they need in a single request.
+//
+// The endpoint supports a subset of the language: queries with nested
+// selection sets, aliases and scalar arguments. The schema is made of objects
+// whose fields are resolved by functions over the existing stores.
+//
+// The size of the body, the depth of the selection sets and the estimated cost
+// of a query are bounded so that a single request cannot exhaust the node.
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// MaxBodySize is the maximum number of bytes of the body of a request.
+	MaxBodySize = 1 << 16
+
+	// MaxCost is the maximum estimated cost of a query.
+	MaxCost = 10000
+)
+
+// Resolver is a function that returns the value of a field according to the
+// arguments. The value is either a scalar, an object, a list of objects or nil.
+type Resolver func(args map[string]interface{}) (interface{}, error)
+
+// Object is the list of resolvers of the fields of an object.
+type Object map[string]Resolver
+
+// Result is the result of a selection set. It preserves the order of the
+// fields when encoded in JSON.
+type Result struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// Get returns the value of the field, or nil if it does not exist.
+func (r *Result) Get(key string) interface{} {
+	return r.values[key]
+}
+
+// MarshalJSON implements json.Marshaler. It encodes the fields in the order of
+// the selection set.
+func (r *Result) MarshalJSON() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	buffer.WriteByte('{')
+
+	for i, key := range r.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := json.Marshal(r.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(k)
+		buffer.WriteByte(':')
+		buffer.Write(v)
+	}
+
+	buffer.WriteByte('}')
+
+	return buffer.Bytes(), nil
+}
+
+// Execute resolves the selection set over the object and returns the result.
+func Execute(obj Object, fields []Field) (*Result, error) {
+	result := &Result{values: map[string]interface{}{}}
+
+	for _, field := range fields {
+		resolver, found := obj[field.Name]
+		if !found {
+			return nil, xerrors.Errorf("unknown field '%s'", field.Name)
+		}
+
+		args := field.Args
+		if args == nil {
+			args = map[string]interface{}{}
+		}
+
+		value, err := resolver(args)
+		if err != nil {
+			return nil, xerrors.Errorf("%s: %v", field.Alias, err)
+		}
+
+		value, err = complete(value, field)
+		if err != nil {
+			return nil, xerrors.Errorf("%s: %v", field.Alias, err)
+		}
+
+		_, found = result.values[field.Alias]
+		if !found {
+			result.keys = append(result.keys, field.Alias)
+		}
+
+		result.values[field.Alias] = value
+	}
+
+	return result, nil
+}
+
+// complete applies the selection set of the field to the value when it is an
+// object or a list of objects.
+func complete(value interface{}, field Field) (interface{}, error) {
+	switch v := value.(type) {
+	case Object:
+		if len(field.Selections) == 0 {
+			return nil, xerrors.New("missing selection set")
+		}
+
+		return Execute(v, field.Selections)
+	case []Object:
+		if len(field.Selections) == 0 {
+			return nil, xerrors.New("missing selection set")
+		}
+
+		list := make([]*Result, len(v))
+		for i, obj := range v {
+			res, err := Execute(obj, field.Selections)
+			if err != nil {
+				return nil, err
+			}
+
+			list[i] = res
+		}
+
+		return list, nil
+	case nil:
+		// A nullable object is null whatever its selection set.
+		return nil, nil
+	default:
+		if len(field.Selections) > 0 {
+			return nil, xerrors.New("selection set on a scalar")
+		}
+
+		return value, nil
+	}
+}
+
+// Cost returns an estimation of the number of fields a query resolves. The
+// selection set of a field is counted as many times as the page size it asks
+// for, or the default one, as the field may be a list.
+func Cost(fields []Field) int {
+	cost := 0
+
+	for _, field := range fields {
+		cost++
+
+		if len(field.Selections) == 0 {
+			continue
+		}
+
+		size := DefaultPageSize
+
+		first, ok := field.Args["first"].(int64)
+		if ok && first > 0 && first <= MaxPageSize {
+			size = int(first)
+		}
+
+		// Stop early as the cost grows exponentially with the depth.
+		sub := Cost(field.Selections)
+		if sub > MaxCost {
+			return sub
+		}
+
+		cost += size * sub
+		if cost > MaxCost {
+			return cost
+		}
+	}
+
+	return cost
+}
+
+// request is the format of the body of a POST request.
+type request struct {
+	Query string `json:"query"`
+}
+
+// response is the format of the response of the endpoint.
+type response struct {
+	Data   *Result    `json:"data"`
+	Errors []gqlError `json:"errors,omitempty"`
+}
+
+type gqlError struct {
+	Message string `json:"message"`
+}
+
+// NewHandler returns an HTTP handler that executes the queries over the root
+// object. The query is read from the 'query' parameter of a GET request, or
+// from the JSON body of a POST request.
+func NewHandler(root Object) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var query string
+
+		switch r.Method {
+		case http.MethodGet:
+			query = r.URL.Query().Get("query")
+		case http.MethodPost:
+			var req request
+			err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodySize)).Decode(&req)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, xerrors.Errorf("invalid body: %v", err))
+				return
+			}
+
+			query = req.Query
+		default:
+			writeError(w, http.StatusMethodNotAllowed, xerrors.Errorf("method '%s' not allowed", r.Method))
+			return
+		}
+
+		fields, err := Parse(query)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, xerrors.Errorf("invalid query: %v", err))
+			return
+		}
+
+		cost := Cost(fields)
+		if cost > MaxCost {
+			writeError(w, http.StatusBadRequest,
+				xerrors.Errorf("query cost %d exceeds the maximum of %d", cost, MaxCost))
+			return
+		}
+
+		data, err := Execute(root, fields)
+		if err != nil {
+			writeError(w, http.StatusOK, err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(response{Data: data})
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(response{
+		Errors: []gqlError{{Message: err.Error()}},
+	})
+}
@@ -0,0 +1,109 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestExecute(t *testing.T) {
+	root := Object{
+		"name": constant("dela"),
+		"items": constant([]Object{
+			{"id": constant(1)},
+			{"id": constant(2)},
+		}),
+		"item": constant(Object{"id": constant(3)}),
+		"none": constant(nil),
+		"bad": func(map[string]interface{}) (interface{}, error) {
+			return nil, fake.GetError()
+		},
+	}
+
+	fields, err := Parse("{ item { id } items { id } n: name none { id } }")
+	require.NoError(t, err)
+
+	res, err := Execute(root, fields)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(res)
+	require.NoError(t, err)
+	require.Equal(t, `{"item":{"id":3},"items":[{"id":1},{"id":2}],"n":"dela","none":null}`, string(data))
+
+	_, err = Execute(root, []Field{{Name: "unknown"}})
+	require.EqualError(t, err, "unknown field 'unknown'")
+
+	_, err = Execute(root, []Field{{Alias: "bad", Name: "bad"}})
+	require.EqualError(t, err, fake.Err("bad"))
+
+	_, err = Execute(root, []Field{{Alias: "item", Name: "item"}})
+	require.EqualError(t, err, "item: missing selection set")
+
+	_, err = Execute(root, []Field{{Alias: "items", Name: "items"}})
+	require.EqualError(t, err, "items: missing selection set")
+
+	_, err = Execute(root, []Field{{Alias: "name", Name: "name", Selections: []Field{{Name: "a"}}}})
+	require.EqualError(t, err, "name: selection set on a scalar")
+}
+
+func TestCost(t *testing.T) {
+	fields, err := Parse("{ a b(first: 2) { c d(index: 1) { e } } }")
+	require.NoError(t, err)
+	require.Equal(t, 1+1+2*(1+1+DefaultPageSize*1), Cost(fields))
+
+	// The estimation stops as soon as the maximum is exceeded.
+	fields, err = Parse("{ a(first: 100) { b(first: 100) { c(first: 100) { d } } } }")
+	require.NoError(t, err)
+	require.Equal(t, 10101, Cost(fields))
+}
+
+func TestHandler(t *testing.T) {
+	handler := NewHandler(Object{"name": constant("dela")})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape("{name}"), nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, `{"data":{"name":"dela"}}`+"\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	body := strings.NewReader(`{"query": "{ name }"}`)
+	handler(rec, httptest.NewRequest(http.MethodPost, "/graphql", body))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, `{"data":{"name":"dela"}}`+"\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("{")))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodDelete, "/graphql", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape("{"), nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	body = strings.NewReader(`{"query": "` + strings.Repeat(" ", MaxBodySize) + `"}`)
+	handler(rec, httptest.NewRequest(http.MethodPost, "/graphql", body))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	query := "{ a(first: 100) { b(first: 100) { c } } }"
+	handler(rec, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(query), nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, `{"data":null,"errors":[{"message":"query cost 10101 exceeds the maximum of 10000"}]}`+"\n",
+		rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape("{unknown}"), nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, `{"data":null,"errors":[{"message":"unknown field 'unknown'"}]}`+"\n",
+		rec.Body.String())
+}
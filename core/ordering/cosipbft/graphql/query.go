@@ -0,0 +1,325 @@
+// This file contains the parser of the subset of the GraphQL query language
+// supported by the endpoint.
+
+package graphql
+
+import (
+	"encoding/json"
+	"strconv"
+	"unicode"
+
+	"golang.org/x/xerrors"
+)
+
+// MaxDepth is the maximum number of nested selection sets of a query.
+const MaxDepth = 8
+
+// Field is a field of a selection set, with its arguments and its own
+// selection set when the field is an object.
+type Field struct {
+	Alias      string
+	Name       string
+	Args       map[string]interface{}
+	Selections []Field
+}
+
+// Parse parses a query document. It supports an optional 'query' keyword with
+// an optional name, followed by a selection set of fields with aliases,
+// arguments of scalar types and nested selection sets up to MaxDepth levels.
+// Variables, fragments and directives are not supported.
+func Parse(query string) ([]Field, error) {
+	p := &parser{input: []rune(query)}
+
+	if p.peekName() == "query" {
+		p.readName()
+
+		if p.peekName() != "" {
+			p.readName()
+		}
+	}
+
+	fields, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipIgnored()
+
+	if p.pos < len(p.input) {
+		return nil, xerrors.Errorf("unexpected '%c' at %d", p.input[p.pos], p.pos)
+	}
+
+	return fields, nil
+}
+
+type parser struct {
+	input []rune
+	pos   int
+	depth int
+}
+
+func (p *parser) parseSelectionSet() ([]Field, error) {
+	err := p.expect('{')
+	if err != nil {
+		return nil, err
+	}
+
+	p.depth++
+	defer func() { p.depth-- }()
+
+	if p.depth > MaxDepth {
+		return nil, xerrors.Errorf("maximum depth of %d exceeded at %d", MaxDepth, p.pos)
+	}
+
+	fields := []Field{}
+
+	for {
+		p.skipIgnored()
+
+		if p.pos >= len(p.input) {
+			return nil, xerrors.New("unexpected end of query")
+		}
+
+		if p.input[p.pos] == '}' {
+			p.pos++
+			break
+		}
+
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, field)
+	}
+
+	if len(fields) == 0 {
+		return nil, xerrors.Errorf("empty selection set at %d", p.pos)
+	}
+
+	return fields, nil
+}
+
+func (p *parser) parseField() (Field, error) {
+	name := p.readName()
+	if name == "" {
+		return Field{}, p.unexpected()
+	}
+
+	field := Field{Alias: name, Name: name}
+
+	if p.peek() == ':' {
+		p.pos++
+
+		field.Name = p.readName()
+		if field.Name == "" {
+			return Field{}, p.unexpected()
+		}
+	}
+
+	if p.peek() == '(' {
+		args, err := p.parseArguments()
+		if err != nil {
+			return Field{}, err
+		}
+
+		field.Args = args
+	}
+
+	if p.peek() == '{' {
+		selections, err := p.parseSelectionSet()
+		if err != nil {
+			return Field{}, err
+		}
+
+		field.Selections = selections
+	}
+
+	return field, nil
+}
+
+func (p *parser) parseArguments() (map[string]interface{}, error) {
+	err := p.expect('(')
+	if err != nil {
+		return nil, err
+	}
+
+	args := map[string]interface{}{}
+
+	for p.peek() != ')' {
+		name := p.readName()
+		if name == "" {
+			return nil, p.unexpected()
+		}
+
+		err = p.expect(':')
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		args[name] = value
+	}
+
+	p.pos++
+
+	return args, nil
+}
+
+func (p *parser) parseValue() (interface{}, error) {
+	p.skipIgnored()
+
+	if p.pos >= len(p.input) {
+		return nil, xerrors.New("unexpected end of query")
+	}
+
+	r := p.input[p.pos]
+
+	switch {
+	case r == '"':
+		return p.readString()
+	case r == '-' || unicode.IsDigit(r):
+		return p.readNumber()
+	}
+
+	switch p.readName() {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+
+	return nil, xerrors.Errorf("invalid value at %d", p.pos)
+}
+
+func (p *parser) readString() (string, error) {
+	start := p.pos
+	p.pos++
+
+	for p.pos < len(p.input) && p.input[p.pos] != '"' {
+		if p.input[p.pos] == '\\' {
+			p.pos++
+		}
+
+		p.pos++
+	}
+
+	if p.pos >= len(p.input) {
+		return "", xerrors.New("unterminated string")
+	}
+
+	p.pos++
+
+	var value string
+	err := json.Unmarshal([]byte(string(p.input[start:p.pos])), &value)
+	if err != nil {
+		return "", xerrors.Errorf("invalid string: %v", err)
+	}
+
+	return value, nil
+}
+
+func (p *parser) readNumber() (interface{}, error) {
+	start := p.pos
+	p.pos++
+
+	for p.pos < len(p.input) && (unicode.IsDigit(p.input[p.pos]) ||
+		p.input[p.pos] == '.' || p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+
+		p.pos++
+	}
+
+	text := string(p.input[start:p.pos])
+
+	integer, err := strconv.ParseInt(text, 10, 64)
+	if err == nil {
+		return integer, nil
+	}
+
+	float, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid number '%s'", text)
+	}
+
+	return float, nil
+}
+
+// peekName returns the name at the current position without consuming it.
+func (p *parser) peekName() string {
+	pos := p.pos
+	name := p.readName()
+	p.pos = pos
+
+	return name
+}
+
+func (p *parser) readName() string {
+	p.skipIgnored()
+
+	start := p.pos
+	for p.pos < len(p.input) && isNameRune(p.input[p.pos], p.pos == start) {
+		p.pos++
+	}
+
+	return string(p.input[start:p.pos])
+}
+
+func (p *parser) peek() rune {
+	p.skipIgnored()
+
+	if p.pos >= len(p.input) {
+		return 0
+	}
+
+	return p.input[p.pos]
+}
+
+func (p *parser) expect(r rune) error {
+	if p.peek() != r {
+		return p.unexpected()
+	}
+
+	p.pos++
+
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	if p.pos >= len(p.input) {
+		return xerrors.New("unexpected end of query")
+	}
+
+	return xerrors.Errorf("unexpected '%c' at %d", p.input[p.pos], p.pos)
+}
+
+// skipIgnored skips the white spaces, the commas and the comments.
+func (p *parser) skipIgnored() {
+	for p.pos < len(p.input) {
+		r := p.input[p.pos]
+
+		switch {
+		case unicode.IsSpace(r) || r == ',':
+			p.pos++
+		case r == '#':
+			for p.pos < len(p.input) && p.input[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func isNameRune(r rune, first bool) bool {
+	if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+		return true
+	}
+
+	return !first && r >= '0' && r <= '9'
+}
@@ -0,0 +1,73 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	query := `query Dashboard {
+		# the latest blocks
+		last: blocks(after: 2, first: 10) { index hash }
+		value(key: "a\"b", ratio: -1.5, ok: true, none: null)
+	}`
+
+	fields, err := Parse(query)
+	require.NoError(t, err)
+
+	expected := []Field{
+		{
+			Alias: "last",
+			Name:  "blocks",
+			Args:  map[string]interface{}{"after": int64(2), "first": int64(10)},
+			Selections: []Field{
+				{Alias: "index", Name: "index"},
+				{Alias: "hash", Name: "hash"},
+			},
+		},
+		{
+			Alias: "value",
+			Name:  "value",
+			Args: map[string]interface{}{
+				"key":   `a"b`,
+				"ratio": -1.5,
+				"ok":    true,
+				"none":  nil,
+			},
+		},
+	}
+	require.Equal(t, expected, fields)
+
+	fields, err = Parse("{ blocks { index } }")
+	require.NoError(t, err)
+	require.Len(t, fields, 1)
+
+	_, err = Parse("")
+	require.EqualError(t, err, "unexpected end of query")
+
+	_, err = Parse("{}")
+	require.EqualError(t, err, "empty selection set at 2")
+
+	_, err = Parse("{ a } }")
+	require.EqualError(t, err, "unexpected '}' at 6")
+
+	_, err = Parse("{ a(b: ) }")
+	require.EqualError(t, err, "invalid value at 7")
+
+	_, err = Parse(`{ a(b: "c) }`)
+	require.EqualError(t, err, "unterminated string")
+
+	_, err = Parse("{ a(b: 1.2.3) }")
+	require.EqualError(t, err, "invalid number '1.2.3'")
+
+	_, err = Parse("{ a: }")
+	require.EqualError(t, err, "unexpected '}' at 5")
+
+	_, err = Parse("{ a { b }")
+	require.EqualError(t, err, "unexpected end of query")
+
+	_, err = Parse(strings.Repeat("{ a ", MaxDepth) + "{ b }" + strings.Repeat("}", MaxDepth))
+	require.EqualError(t, err, "maximum depth of 8 exceeded at 33")
+}
@@ -0,0 +1,637 @@
+// This file contains the schema of the chain data exposed by the endpoint.
+
+package graphql
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/events"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/pagination"
+	"go.dedis.ch/dela/core/validation"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// DefaultPageSize is the number of items returned by a list when the
+	// argument 'first' is not provided.
+	DefaultPageSize = pagination.DefaultLimit
+
+	// MaxPageSize is the maximum number of items returned by a list.
+	MaxPageSize = pagination.MaxLimit
+)
+
+// Schema provides the root object of the queries over the stores of a chain.
+//
+// The root object has the following fields:
+//   - blocks(after: Int, first: Int, order: String): [Block]
+//   - block(index: Int!): Block
+//   - transactions(after: String, first: Int, order: String, contract: String,
+//     accepted: Boolean, identity: String): [Transaction]
+//   - events(after: String, first: Int, order: String, types: String,
+//     contract: String, accepted: Boolean, identity: String): [Event]
+//   - rosters(after: Int, first: Int, order: String): [Roster]
+//   - value(key: String!): String
+//
+// A block has the fields index, hash, root, size and transactions(after: Int,
+// first: Int, order: String, contract: String, accepted: Boolean, identity:
+// String). A transaction has the fields id, nonce, identity, contract,
+// accepted, reason, block, cursor and arg(key: String!). An event has the
+// fields type, cursor, block and transaction, the last two being null when the
+// event is of the other type. The types of an event list are comma-separated
+// as for the WebSocket endpoint. A roster has the fields cursor, index, which
+// is the block it is effective from, size and members.
+//
+// The lists follow the conventions of the pagination package: the argument
+// 'after' is the cursor of the last item of the previous page, 'first' is the
+// size of the page and 'order' is either 'asc' or 'desc'.
+//
+// The transactions of the contracts that are not served by the local policy of
+// the node are left out of the lists.
+type Schema struct {
+	blocks      blockstore.BlockStore
+	srvc        ordering.Service
+	policy      native.Policy
+	authorities blockstore.AuthorityStore
+}
+
+// SchemaOption is the type of option to configure the schema.
+type SchemaOption func(*Schema)
+
+// WithPolicy sets the local policy that defines the contracts whose
+// transactions are served.
+func WithPolicy(policy native.Policy) SchemaOption {
+	return func(s *Schema) {
+		s.policy = policy
+	}
+}
+
+// WithAuthorities sets the store of the roster history.
+func WithAuthorities(store blockstore.AuthorityStore) SchemaOption {
+	return func(s *Schema) {
+		s.authorities = store
+	}
+}
+
+// NewSchema creates a new schema over the block store and the store of the
+// ordering service.
+func NewSchema(blocks blockstore.BlockStore, srvc ordering.Service, opts ...SchemaOption) Schema {
+	s := Schema{
+		blocks: blocks,
+		srvc:   srvc,
+	}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return s
+}
+
+// Query returns the root object of the queries.
+func (s Schema) Query() Object {
+	return Object{
+		"blocks":       s.resolveBlocks,
+		"block":        s.resolveBlock,
+		"transactions": s.resolveTransactions,
+		"events":       s.resolveEvents,
+		"rosters":      s.resolveRosters,
+		"value":        s.resolveValue,
+	}
+}
+
+func (s Schema) resolveBlocks(args map[string]interface{}) (interface{}, error) {
+	req, err := getIndexRequest(args)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := []Object{}
+
+	_, err = req.WalkIndex(s.blocks.Len(), func(index uint64) (bool, error) {
+		link, err := s.blocks.GetByIndex(index)
+		if err != nil {
+			return false, xerrors.Errorf("block %d: %v", index, err)
+		}
+
+		blocks = append(blocks, s.makeBlock(link.GetBlock()))
+
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+func (s Schema) resolveBlock(args map[string]interface{}) (interface{}, error) {
+	index, found, err := getInt(args, "index")
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, xerrors.New("missing argument 'index'")
+	}
+
+	if uint64(index) >= s.blocks.Len() {
+		return nil, nil
+	}
+
+	link, err := s.blocks.GetByIndex(uint64(index))
+	if err != nil {
+		return nil, xerrors.Errorf("block %d: %v", index, err)
+	}
+
+	return s.makeBlock(link.GetBlock()), nil
+}
+
+// resolveTransactions returns the transactions of every block that match the
+// filter. The cursor of a transaction is the index of the block and its
+// position in the block.
+func (s Schema) resolveTransactions(args map[string]interface{}) (interface{}, error) {
+	req, err := getRequest(args)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := makeFilter(args, s.policy)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []validation.TransactionResult
+
+	size := func(index uint64) (int, error) {
+		link, err := s.blocks.GetByIndex(index)
+		if err != nil {
+			return 0, xerrors.Errorf("block %d: %v", index, err)
+		}
+
+		results = link.GetBlock().GetData().GetTransactionResults()
+
+		return len(results), nil
+	}
+
+	txs := []Object{}
+
+	_, err = req.Walk(s.blocks.Len(), size, func(c pagination.Cursor) (bool, error) {
+		if !filter.match(results[c.Position]) {
+			return false, nil
+		}
+
+		txs = append(txs, makeTransaction(c.Index, c.Position, results[c.Position]))
+
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// resolveEvents returns the events of every block that match the filter. The
+// cursor of an event is the index of the block and its position in the list of
+// events of the block.
+func (s Schema) resolveEvents(args map[string]interface{}) (interface{}, error) {
+	req, err := getRequest(args)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := makeEventFilter(args, s.policy)
+	if err != nil {
+		return nil, err
+	}
+
+	var evts []events.Event
+
+	size := func(index uint64) (int, error) {
+		link, err := s.blocks.GetByIndex(index)
+		if err != nil {
+			return 0, xerrors.Errorf("block %d: %v", index, err)
+		}
+
+		evts = filter.Events(link.GetBlock())
+
+		return len(evts), nil
+	}
+
+	list := []Object{}
+
+	_, err = req.Walk(s.blocks.Len(), size, func(c pagination.Cursor) (bool, error) {
+		list = append(list, makeEvent(c.Index, c.Position, evts[c.Position]))
+
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// resolveRosters returns the history of the roster, which has an entry every
+// time the roster changes. The cursor of a roster is its position in the
+// history.
+func (s Schema) resolveRosters(args map[string]interface{}) (interface{}, error) {
+	if s.authorities == nil {
+		return nil, xerrors.New("roster history is not available")
+	}
+
+	req, err := getIndexRequest(args)
+	if err != nil {
+		return nil, err
+	}
+
+	rosters := []Object{}
+
+	n := uint64(s.authorities.Len())
+
+	_, err = req.WalkIndex(n, func(pos uint64) (bool, error) {
+		index, roster, err := s.authorities.GetSnapshot(int(pos))
+		if err != nil {
+			return false, xerrors.Errorf("roster %d: %v", pos, err)
+		}
+
+		rosters = append(rosters, makeRoster(pos, index, roster))
+
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rosters, nil
+}
+
+func (s Schema) resolveValue(args map[string]interface{}) (interface{}, error) {
+	key, found, err := getString(args, "key")
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, xerrors.New("missing argument 'key'")
+	}
+
+	value, err := s.srvc.GetStore().Get([]byte(key))
+	if err != nil {
+		return nil, xerrors.Errorf("store: %v", err)
+	}
+
+	if value == nil {
+		return nil, nil
+	}
+
+	return string(value), nil
+}
+
+func (s Schema) makeBlock(block types.Block) Object {
+	results := block.GetData().GetTransactionResults()
+
+	return Object{
+		"index": constant(block.GetIndex()),
+		"hash":  constant(hex.EncodeToString(block.GetHash().Bytes())),
+		"root":  constant(hex.EncodeToString(block.GetTreeRoot().Bytes())),
+		"size":  constant(len(results)),
+		"transactions": func(args map[string]interface{}) (interface{}, error) {
+			req, err := getIndexRequest(args)
+			if err != nil {
+				return nil, err
+			}
+
+			filter, err := makeFilter(args, s.policy)
+			if err != nil {
+				return nil, err
+			}
+
+			txs := []Object{}
+
+			_, err = req.WalkIndex(uint64(len(results)), func(pos uint64) (bool, error) {
+				if !filter.match(results[pos]) {
+					return false, nil
+				}
+
+				txs = append(txs, makeTransaction(block.GetIndex(), int(pos), results[pos]))
+
+				return true, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return txs, nil
+		},
+	}
+}
+
+func makeTransaction(index uint64, pos int, res validation.TransactionResult) Object {
+	tx := res.GetTransaction()
+	accepted, reason := res.GetStatus()
+
+	var identity interface{}
+
+	text, err := tx.GetIdentity().MarshalText()
+	if err == nil {
+		identity = string(text)
+	}
+
+	return Object{
+		"id":       constant(hex.EncodeToString(tx.GetID())),
+		"nonce":    constant(tx.GetNonce()),
+		"identity": constant(identity),
+		"contract": constant(string(tx.GetArg(native.ContractArg))),
+		"accepted": constant(accepted),
+		"reason":   constant(reason),
+		"block":    constant(index),
+		"cursor":   constant(pagination.Cursor{Index: index, Position: pos}.String()),
+		"arg": func(args map[string]interface{}) (interface{}, error) {
+			key, found, err := getString(args, "key")
+			if err != nil {
+				return nil, err
+			}
+
+			if !found {
+				return nil, xerrors.New("missing argument 'key'")
+			}
+
+			value := tx.GetArg(key)
+			if value == nil {
+				return nil, nil
+			}
+
+			return string(value), nil
+		},
+	}
+}
+
+func makeRoster(pos, index uint64, roster authority.Authority) Object {
+	members := make([]interface{}, 0, roster.Len())
+
+	iter := roster.AddressIterator()
+	for iter.HasNext() {
+		members = append(members, iter.GetNext().String())
+	}
+
+	return Object{
+		"cursor":  constant(pos),
+		"index":   constant(index),
+		"size":    constant(roster.Len()),
+		"members": constant(members),
+	}
+}
+
+func makeEvent(index uint64, pos int, event events.Event) Object {
+	var block, tx interface{}
+
+	if event.Block != nil {
+		block = Object{
+			"index": constant(event.Block.Index),
+			"hash":  constant(event.Block.Hash),
+			"size":  constant(event.Block.Size),
+		}
+	}
+
+	if event.Transaction != nil {
+		tx = Object{
+			"block":    constant(event.Transaction.Block),
+			"position": constant(event.Transaction.Position),
+			"id":       constant(event.Transaction.ID),
+			"nonce":    constant(event.Transaction.Nonce),
+			"identity": constant(event.Transaction.Identity),
+			"contract": constant(event.Transaction.Contract),
+			"accepted": constant(event.Transaction.Accepted),
+			"reason":   constant(event.Transaction.Reason),
+		}
+	}
+
+	return Object{
+		"type":        constant(event.Type),
+		"cursor":      constant(pagination.Cursor{Index: index, Position: pos}.String()),
+		"block":       constant(block),
+		"transaction": constant(tx),
+	}
+}
+
+// makeEventFilter builds the filter of the events from the arguments.
+func makeEventFilter(args map[string]interface{}, policy native.Policy) (events.Filter, error) {
+	f := events.Filter{
+		Blocks: true,
+		Txs:    true,
+		Policy: policy,
+	}
+
+	kinds, found, err := getString(args, "types")
+	if err != nil {
+		return f, err
+	}
+
+	if found {
+		f.Blocks = false
+		f.Txs = false
+
+		for _, kind := range strings.Split(kinds, ",") {
+			switch strings.TrimSpace(kind) {
+			case events.BlockType:
+				f.Blocks = true
+			case events.TransactionType:
+				f.Txs = true
+			default:
+				return f, xerrors.Errorf("unknown type '%s'", kind)
+			}
+		}
+	}
+
+	txf, err := makeFilter(args, policy)
+	if err != nil {
+		return f, err
+	}
+
+	if txf.contract != nil {
+		f.Contract = *txf.contract
+	}
+
+	if txf.identity != nil {
+		f.Identity = *txf.identity
+	}
+
+	f.Accepted = txf.accepted
+
+	return f, nil
+}
+
+// filter is the set of conditions a transaction must fulfil to be listed.
+type filter struct {
+	policy   native.Policy
+	contract *string
+	accepted *bool
+	identity *string
+}
+
+func makeFilter(args map[string]interface{}, policy native.Policy) (filter, error) {
+	f := filter{policy: policy}
+
+	contract, found, err := getString(args, "contract")
+	if err != nil {
+		return f, err
+	}
+
+	if found {
+		f.contract = &contract
+	}
+
+	identity, found, err := getString(args, "identity")
+	if err != nil {
+		return f, err
+	}
+
+	if found {
+		f.identity = &identity
+	}
+
+	value, found := args["accepted"]
+	if found && value != nil {
+		accepted, ok := value.(bool)
+		if !ok {
+			return f, xerrors.New("argument 'accepted' must be a boolean")
+		}
+
+		f.accepted = &accepted
+	}
+
+	return f, nil
+}
+
+func (f filter) match(res validation.TransactionResult) bool {
+	tx := res.GetTransaction()
+	contract := string(tx.GetArg(native.ContractArg))
+
+	if f.policy.Check(contract) != nil {
+		return false
+	}
+
+	if f.contract != nil && contract != *f.contract {
+		return false
+	}
+
+	if f.accepted != nil {
+		accepted, _ := res.GetStatus()
+		if accepted != *f.accepted {
+			return false
+		}
+	}
+
+	if f.identity != nil {
+		text, err := tx.GetIdentity().MarshalText()
+		if err != nil || string(text) != *f.identity {
+			return false
+		}
+	}
+
+	return true
+}
+
+func constant(value interface{}) Resolver {
+	return func(map[string]interface{}) (interface{}, error) {
+		return value, nil
+	}
+}
+
+func getFirst(args map[string]interface{}) (int, error) {
+	first, found, err := getInt(args, "first")
+	if err != nil {
+		return 0, err
+	}
+
+	if !found {
+		return DefaultPageSize, nil
+	}
+
+	if first <= 0 || first > MaxPageSize {
+		return 0, xerrors.Errorf("argument 'first' must be between 1 and %d", MaxPageSize)
+	}
+
+	return int(first), nil
+}
+
+// getRequest returns the request of a page of a list whose cursor is a string.
+func getRequest(args map[string]interface{}) (pagination.Request, error) {
+	after, _, err := getString(args, "after")
+	if err != nil {
+		return pagination.Request{}, err
+	}
+
+	return makeRequest(args, after)
+}
+
+// getIndexRequest returns the request of a page of a list whose cursor is an
+// index.
+func getIndexRequest(args map[string]interface{}) (pagination.Request, error) {
+	after, found, err := getInt(args, "after")
+	if err != nil {
+		return pagination.Request{}, err
+	}
+
+	cursor := ""
+	if found {
+		cursor = strconv.FormatInt(after, 10)
+	}
+
+	return makeRequest(args, cursor)
+}
+
+func makeRequest(args map[string]interface{}, after string) (pagination.Request, error) {
+	first, err := getFirst(args)
+	if err != nil {
+		return pagination.Request{}, err
+	}
+
+	order, _, err := getString(args, "order")
+	if err != nil {
+		return pagination.Request{}, err
+	}
+
+	req, err := pagination.NewRequest(after, first, order)
+	if err != nil {
+		return req, xerrors.Errorf("argument 'order': %v", err)
+	}
+
+	return req, nil
+}
+
+func getInt(args map[string]interface{}, name string) (int64, bool, error) {
+	value, found := args[name]
+	if !found || value == nil {
+		return 0, false, nil
+	}
+
+	num, ok := value.(int64)
+	if !ok || num < 0 {
+		return 0, false, xerrors.Errorf("argument '%s' must be a positive integer", name)
+	}
+
+	return num, true, nil
+}
+
+func getString(args map[string]interface{}, name string) (string, bool, error) {
+	value, found := args[name]
+	if !found || value == nil {
+		return "", false, nil
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return "", false, xerrors.Errorf("argument '%s' must be a string", name)
+	}
+
+	return str, true, nil
+}
@@ -0,0 +1,233 @@
+package graphql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+)
+
+func TestSchema_Blocks(t *testing.T) {
+	schema := NewSchema(makeBlocks(t, 3), fakeService{})
+
+	res := query(t, schema, "{ blocks(after: 0, first: 1) { index size } }")
+	require.Equal(t, `{"blocks":[{"index":1,"size":2}]}`, res)
+
+	res = query(t, schema, "{ block(index: 2) { index transactions(after: 0) { nonce } } }")
+	require.Equal(t, `{"block":{"index":2,"transactions":[{"nonce":5}]}}`, res)
+
+	res = query(t, schema, "{ block(index: 5) { index } }")
+	require.Equal(t, `{"block":null}`, res)
+
+	_, err := execute(schema, "{ block { index } }")
+	require.EqualError(t, err, "block: missing argument 'index'")
+
+	_, err = execute(schema, "{ blocks(first: 1000) { index } }")
+	require.EqualError(t, err, "blocks: argument 'first' must be between 1 and 100")
+
+	_, err = execute(schema, `{ blocks(after: "a") { index } }`)
+	require.EqualError(t, err, "blocks: argument 'after' must be a positive integer")
+
+	_, err = execute(schema, `{ blocks(order: "random") { index } }`)
+	require.EqualError(t, err, "blocks: argument 'order': unknown order 'random'")
+}
+
+func TestSchema_Order(t *testing.T) {
+	schema := NewSchema(makeBlocks(t, 3), fakeService{})
+
+	res := query(t, schema, `{ blocks(after: 2, order: "desc") { index } }`)
+	require.Equal(t, `{"blocks":[{"index":1},{"index":0}]}`, res)
+
+	res = query(t, schema, `{ transactions(first: 3, order: "desc") { cursor } }`)
+	require.Equal(t, `{"transactions":[{"cursor":"2:1"},{"cursor":"2:0"},{"cursor":"1:1"}]}`, res)
+
+	res = query(t, schema, `{ transactions(after: "1:0", order: "desc") { cursor } }`)
+	require.Equal(t, `{"transactions":[{"cursor":"0:1"},{"cursor":"0:0"}]}`, res)
+
+	res = query(t, schema, `{ events(first: 2, after: "1:0", order: "desc") { cursor } }`)
+	require.Equal(t, `{"events":[{"cursor":"0:2"},{"cursor":"0:1"}]}`, res)
+
+	res = query(t, schema, `{ block(index: 1) { transactions(order: "desc") { nonce } } }`)
+	require.Equal(t, `{"block":{"transactions":[{"nonce":3},{"nonce":2}]}}`, res)
+}
+
+func TestSchema_Rosters(t *testing.T) {
+	authorities := blockstore.NewAuthorityStore()
+
+	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+	require.NoError(t, authorities.Store(0, ro))
+	require.NoError(t, authorities.Store(4, ro.Take(mino.RangeFilter(0, 2)).(authority.Authority)))
+
+	schema := NewSchema(blockstore.NewInMemory(), fakeService{}, WithAuthorities(authorities))
+
+	res := query(t, schema, `{ rosters { cursor index size members } }`)
+	require.Equal(t, `{"rosters":[{"cursor":0,"index":0,"size":3,"members":`+
+		`["fake.Address[0]","fake.Address[1]","fake.Address[2]"]},`+
+		`{"cursor":1,"index":4,"size":2,"members":["fake.Address[0]","fake.Address[1]"]}]}`, res)
+
+	res = query(t, schema, `{ rosters(first: 1, order: "desc") { index } }`)
+	require.Equal(t, `{"rosters":[{"index":4}]}`, res)
+
+	schema = NewSchema(blockstore.NewInMemory(), fakeService{})
+
+	_, err := execute(schema, `{ rosters { index } }`)
+	require.EqualError(t, err, "rosters: roster history is not available")
+}
+
+func TestSchema_Transactions(t *testing.T) {
+	schema := NewSchema(makeBlocks(t, 3), fakeService{})
+
+	res := query(t, schema, `{ transactions(first: 2, after: "0:1") { cursor nonce } }`)
+	require.Equal(t, `{"transactions":[{"cursor":"1:0","nonce":2},{"cursor":"1:1","nonce":3}]}`, res)
+
+	res = query(t, schema, `{ transactions(contract: "value", accepted: false) {
+		block contract accepted reason identity arg(key: "value:command") } }`)
+	require.Equal(t, `{"transactions":[{"block":0,"contract":"value","accepted":false,`+
+		`"reason":"oops","identity":"PK","arg":"WRITE"}]}`, res)
+
+	res = query(t, schema, `{ transactions(identity: "unknown") { id } }`)
+	require.Equal(t, `{"transactions":[]}`, res)
+
+	_, err := execute(schema, `{ transactions(after: "abc") { id } }`)
+	require.EqualError(t, err, "transactions: invalid cursor 'abc'")
+
+	_, err = execute(schema, `{ transactions(accepted: 1) { id } }`)
+	require.EqualError(t, err, "transactions: argument 'accepted' must be a boolean")
+
+	// The transactions of a contract that is not served are left out.
+	schema = NewSchema(makeBlocks(t, 3), fakeService{}, WithPolicy(native.NewDenyList("value")))
+
+	res = query(t, schema, `{ transactions { id } block(index: 0) { size transactions { id } } }`)
+	require.Equal(t, `{"transactions":[],"block":{"size":2,"transactions":[]}}`, res)
+}
+
+func TestSchema_Events(t *testing.T) {
+	schema := NewSchema(makeBlocks(t, 3), fakeService{})
+
+	res := query(t, schema, `{ events(first: 2, after: "0:1") {
+		type cursor block { index size } transaction { position nonce } } }`)
+	require.Equal(t, `{"events":[{"type":"transaction","cursor":"0:2","block":null,`+
+		`"transaction":{"position":1,"nonce":1}},{"type":"block","cursor":"1:0",`+
+		`"block":{"index":1,"size":2},"transaction":null}]}`, res)
+
+	res = query(t, schema, `{ events(types: "transaction", accepted: false) {
+		cursor transaction { block contract reason } } }`)
+	require.Equal(t, `{"events":[{"cursor":"0:0","transaction":`+
+		`{"block":0,"contract":"value","reason":"oops"}}]}`, res)
+
+	res = query(t, schema, `{ events(types: "block", after: "2:0") { cursor } }`)
+	require.Equal(t, `{"events":[]}`, res)
+
+	_, err := execute(schema, `{ events(types: "abc") { cursor } }`)
+	require.EqualError(t, err, "events: unknown type 'abc'")
+
+	_, err = execute(schema, `{ events(after: "abc") { cursor } }`)
+	require.EqualError(t, err, "events: invalid cursor 'abc'")
+
+	_, err = execute(schema, `{ events(accepted: 1) { cursor } }`)
+	require.EqualError(t, err, "events: argument 'accepted' must be a boolean")
+
+	schema = NewSchema(makeBlocks(t, 1), fakeService{}, WithPolicy(native.NewDenyList("value")))
+
+	res = query(t, schema, `{ events { type } }`)
+	require.Equal(t, `{"events":[{"type":"block"}]}`, res)
+}
+
+func TestSchema_Value(t *testing.T) {
+	snap := fake.NewSnapshot()
+	snap.Set([]byte("ping"), []byte("pong"))
+
+	schema := NewSchema(blockstore.NewInMemory(), fakeService{store: snap})
+
+	res := query(t, schema, `{ a: value(key: "ping") b: value(key: "pong") }`)
+	require.Equal(t, `{"a":"pong","b":null}`, res)
+
+	_, err := execute(schema, `{ value }`)
+	require.EqualError(t, err, "value: missing argument 'key'")
+
+	schema.srvc = fakeService{store: fake.NewBadSnapshot()}
+	_, err = execute(schema, `{ value(key: "ping") }`)
+	require.EqualError(t, err, fake.Err("value: store"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// makeBlocks creates a store with the number of blocks, each of them with two
+// transactions. The first transaction of the first block is refused.
+func makeBlocks(t *testing.T, num int) blockstore.BlockStore {
+	blocks := blockstore.NewInMemory()
+	prev := types.Digest{}
+	nonce := uint64(0)
+
+	for i := 0; i < num; i++ {
+		results := []simple.TransactionResult{}
+
+		for j := 0; j < 2; j++ {
+			tx, err := signed.NewTransaction(nonce, fake.PublicKey{},
+				signed.WithArg(native.ContractArg, []byte("value")),
+				signed.WithArg("value:command", []byte("WRITE")))
+			require.NoError(t, err)
+
+			accepted := i != 0 || j != 0
+			reason := ""
+			if !accepted {
+				reason = "oops"
+			}
+
+			results = append(results, simple.NewTransactionResult(tx, accepted, reason))
+			nonce++
+		}
+
+		block, err := types.NewBlock(simple.NewResult(results), types.WithIndex(uint64(i)))
+		require.NoError(t, err)
+
+		link, err := types.NewBlockLink(prev, block)
+		require.NoError(t, err)
+
+		require.NoError(t, blocks.Store(link))
+
+		prev = block.GetHash()
+	}
+
+	return blocks
+}
+
+func execute(schema Schema, q string) (*Result, error) {
+	fields, err := Parse(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return Execute(schema.Query(), fields)
+}
+
+func query(t *testing.T, schema Schema, q string) string {
+	res, err := execute(schema, q)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(res)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+type fakeService struct {
+	ordering.Service
+
+	store store.Readable
+}
+
+func (s fakeService) GetStore() store.Readable {
+	return s.store
+}
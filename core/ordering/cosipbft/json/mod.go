@@ -7,6 +7,7 @@ import (
 	"go.dedis.ch/dela/core/ordering/cosipbft/types"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 	"golang.org/x/xerrors"
@@ -22,8 +23,9 @@ func init() {
 
 // GenesisJSON is the JSON message for a genesis block.
 type GenesisJSON struct {
-	Roster   json.RawMessage
-	TreeRoot []byte
+	Roster      json.RawMessage
+	TreeRoot    []byte
+	Fingerprint uint32 `json:",omitempty"`
 }
 
 // BlockJSON is the JSON message for a block.
@@ -31,6 +33,7 @@ type BlockJSON struct {
 	Index    uint64
 	TreeRoot []byte
 	Data     json.RawMessage
+	Proof    []byte `json:",omitempty"`
 }
 
 // LinkJSON is the JSON message for a link.
@@ -113,6 +116,12 @@ func (f genesisFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, err
 		TreeRoot: genesis.GetRoot().Bytes(),
 	}
 
+	// The version is omitted by default so that the genesis block is decoded
+	// by the participants that only know the first one.
+	if genesis.GetFingerprintVersion() != authority.FingerprintV1 {
+		m.Fingerprint = uint32(genesis.GetFingerprintVersion())
+	}
+
 	data, err := ctx.Marshal(m)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to marshal: %v", err)
@@ -147,6 +156,10 @@ func (f genesisFormat) Decode(ctx serde.Context, data []byte) (serde.Message, er
 
 	opts := []types.GenesisOption{types.WithGenesisRoot(root)}
 
+	if m.Fingerprint != 0 {
+		opts = append(opts, types.WithGenesisFingerprint(authority.FingerprintVersion(m.Fingerprint)))
+	}
+
 	if f.hashFac != nil {
 		opts = append(opts, types.WithGenesisHashFactory(f.hashFac))
 	}
@@ -185,6 +198,14 @@ func (f blockFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error
 		Data:     blockdata,
 	}
 
+	proof, found := block.GetProof()
+	if found {
+		m.Proof, err = proof.MarshalBinary()
+		if err != nil {
+			return nil, xerrors.Errorf("failed to marshal proof: %v", err)
+		}
+	}
+
 	data, err := ctx.Marshal(m)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to marshal: %v", err)
@@ -222,6 +243,15 @@ func (f blockFormat) Decode(ctx serde.Context, data []byte) (serde.Message, erro
 		types.WithIndex(m.Index),
 	}
 
+	if len(m.Proof) > 0 {
+		proof, err := vrf.NewProof(m.Proof)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid proof: %v", err)
+		}
+
+		opts = append(opts, types.WithProof(proof))
+	}
+
 	if f.hashFac != nil {
 		opts = append(opts, types.WithHashFactory(f.hashFac))
 	}
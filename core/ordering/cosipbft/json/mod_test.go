@@ -3,11 +3,21 @@ package json
 import (
 	"io"
 	"testing"
+	"testing/quick"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/signed"
+	_ "go.dedis.ch/dela/core/txn/signed/json"
+	"go.dedis.ch/dela/core/validation/simple"
+	_ "go.dedis.ch/dela/core/validation/simple/json"
+	"go.dedis.ch/dela/crypto"
+	_ "go.dedis.ch/dela/crypto/bls/json"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/internal/testing/gen"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 )
@@ -40,6 +50,13 @@ func TestGenesisFormat_Encode(t *testing.T) {
 
 	_, err = format.Encode(ctx, genesis)
 	require.EqualError(t, err, fake.Err("failed to serialize roster"))
+
+	genesis, err = types.NewGenesis(fakeRoster{}, types.WithGenesisFingerprint(authority.FingerprintV2))
+	require.NoError(t, err)
+
+	data, err = format.Encode(ctx, genesis)
+	require.NoError(t, err)
+	require.Regexp(t, `"Fingerprint":2}$`, string(data))
 }
 
 func TestGenesisFormat_Decode(t *testing.T) {
@@ -55,6 +72,14 @@ func TestGenesisFormat_Decode(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, msg, genesis)
 
+	msg, err = format.Decode(ctx, []byte(`{"Fingerprint":2}`))
+	require.NoError(t, err)
+	require.Equal(t, authority.FingerprintV2, msg.(types.Genesis).GetFingerprintVersion())
+
+	_, err = format.Decode(ctx, []byte(`{"Fingerprint":3}`))
+	require.EqualError(t, err, "creating genesis: fingerprint failed: "+
+		"roster fingerprint failed: unknown fingerprint version 3")
+
 	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
 	require.EqualError(t, err, fake.Err("failed to unmarshal"))
 
@@ -113,6 +138,22 @@ func TestBlockFormat_Decode(t *testing.T) {
 	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
 	require.EqualError(t, err, fake.Err("failed to unmarshal"))
 
+	proof, err := vrf.NewSigner().Prove([]byte{1})
+	require.NoError(t, err)
+
+	block, err = types.NewBlock(fakeResult{}, types.WithProof(proof))
+	require.NoError(t, err)
+
+	data, err := blockFormat{}.Encode(ctx, block)
+	require.NoError(t, err)
+
+	msg, err = format.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, block.GetHash(), msg.(types.Block).GetHash())
+
+	_, err = format.Decode(ctx, []byte(`{"Proof":"AA=="}`))
+	require.EqualError(t, err, "invalid proof: invalid proof size 1 != 80")
+
 	badCtx := serde.WithFactory(ctx, types.DataKey{}, nil)
 	_, err = format.Decode(badCtx, []byte(`{}`))
 	require.EqualError(t, err, "invalid data factory '<nil>'")
@@ -127,6 +168,34 @@ func TestBlockFormat_Decode(t *testing.T) {
 	require.Contains(t, err.Error(), "creating block: fingerprint failed: ")
 }
 
+func TestBlockFormat_Lenient_Decode(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+	fac := types.NewBlockFactory(simple.NewResultFactory(signed.NewTransactionFactory()))
+
+	data := []byte(`{"Index":1,"TreeRoot":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",` +
+		`"Data":{"Results":[{"Transaction":{"Unknown":1},"Accepted":true,"Reason":""}]}}`)
+
+	_, err := fac.Deserialize(ctx, data)
+	require.Error(t, err)
+
+	msg, err := fac.Deserialize(serde.WithLenient(ctx), data)
+	require.NoError(t, err)
+
+	block := msg.(types.Block)
+	require.Equal(t, uint64(1), block.GetIndex())
+	require.Len(t, block.GetTransactions(), 1)
+
+	tx, ok := block.GetTransactions()[0].(txn.OpaqueTransaction)
+	require.True(t, ok)
+	require.Equal(t, []byte(`{"Unknown":1}`), tx.GetData())
+	require.Error(t, tx.GetError())
+
+	// The opaque transaction is written back as is.
+	res, err := block.Serialize(ctx)
+	require.NoError(t, err)
+	require.Equal(t, data, res)
+}
+
 func TestMsgFormat_Encode(t *testing.T) {
 	format := msgFormat{}
 
@@ -271,6 +340,42 @@ func TestMsgFormat_Decode(t *testing.T) {
 	require.EqualError(t, err, "message is empty")
 }
 
+func TestBlockFormat_Quick_RoundTrip(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+	fac := types.NewBlockFactory(simple.NewResultFactory(signed.NewTransactionFactory()))
+
+	f := func(block gen.Block) bool {
+		data, err := block.Serialize(ctx)
+		require.NoError(t, err)
+
+		msg, err := fac.Deserialize(ctx, data)
+		require.NoError(t, err)
+
+		decoded := msg.(types.Block)
+		require.Equal(t, block.GetHash(), decoded.GetHash())
+		require.Equal(t, block.GetIndex(), decoded.GetIndex())
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 10})
+	require.NoError(t, err)
+}
+
+func TestBlockFormat_Quick_Malformed(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+	fac := types.NewBlockFactory(simple.NewResultFactory(signed.NewTransactionFactory()))
+
+	f := func(input gen.Malformed) bool {
+		require.NotPanics(t, func() { fac.Deserialize(ctx, input.Data) })
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 200})
+	require.NoError(t, err)
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
@@ -288,6 +393,18 @@ func (fakeRoster) Fingerprint(io.Writer) error {
 	return nil
 }
 
+func (fakeRoster) Len() int {
+	return 0
+}
+
+func (fakeRoster) AddressIterator() mino.AddressIterator {
+	return mino.NewAddresses().AddressIterator()
+}
+
+func (fakeRoster) PublicKeyIterator() crypto.PublicKeyIterator {
+	return authority.New(nil, nil).PublicKeyIterator()
+}
+
 type fakeRosterFac struct {
 	authority.Factory
 
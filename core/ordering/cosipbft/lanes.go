@@ -0,0 +1,186 @@
+// This file contains the implementation of the priority lanes used by the
+// leader to fill a block.
+
+package cosipbft
+
+import (
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/budget"
+	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/viewchange"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/pool"
+	"golang.org/x/xerrors"
+)
+
+// Lanes is the configuration of the capacity of a block. A part of the
+// capacity can be reserved to the transactions of the system contracts, like
+// the roster or the governance ones, so that they always make progress even
+// when the pool is flooded with user transactions.
+type Lanes struct {
+	// MaxTxs is the maximum number of transactions in a block. Zero means that
+	// the number is not limited.
+	MaxTxs int
+
+	// Reserved is the number of transactions of a block that are reserved to
+	// the system contracts. User transactions never fill those slots.
+	Reserved int
+
+	// SystemContracts is the list of contracts that are considered as system
+	// contracts.
+	SystemContracts []string
+}
+
+// DefaultLanes returns the default configuration, which does not limit the
+// size of a block and only considers the roster and the budget contracts as
+// system contracts.
+func DefaultLanes() Lanes {
+	return Lanes{
+		SystemContracts: []string{viewchange.ContractName, budget.ContractName},
+	}
+}
+
+// Validate returns nil if the configuration is consistent, otherwise an error
+// describing the problem.
+func (l Lanes) Validate() error {
+	if l.MaxTxs < 0 {
+		return xerrors.Errorf("maximum number of transactions is negative: %d", l.MaxTxs)
+	}
+
+	if l.Reserved < 0 {
+		return xerrors.Errorf("reserved number of transactions is negative: %d", l.Reserved)
+	}
+
+	if l.Reserved > 0 && l.MaxTxs == 0 {
+		return xerrors.New("reserved transactions require a maximum number of transactions")
+	}
+
+	if l.Reserved > 0 && l.Reserved >= l.MaxTxs {
+		return xerrors.Errorf("reserved transactions must be less than the maximum: %d >= %d",
+			l.Reserved, l.MaxTxs)
+	}
+
+	return nil
+}
+
+// IsSystem returns true if the transaction targets a system contract.
+func (l Lanes) IsSystem(tx txn.Transaction) bool {
+	name := string(tx.GetArg(native.ContractArg))
+
+	for _, contract := range l.SystemContracts {
+		if contract == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Classify implements pool.Classifier. It returns the governance class for the
+// transactions of the system contracts, so that the pool gives them first to
+// the leader.
+func (l Lanes) Classify(tx txn.Transaction) pool.Priority {
+	if l.IsSystem(tx) {
+		return pool.PriorityGovernance
+	}
+
+	return pool.PriorityUser
+}
+
+// Select returns the transactions that fit in a block. System transactions are
+// picked first, then user transactions fill the capacity left without using
+// the reserved slots. The order of the input is preserved, and when a
+// transaction of an identity is left out, the following ones of the same
+// identity are left out too so that the nonces stay in sequence.
+func (l Lanes) Select(txs []txn.Transaction) []txn.Transaction {
+	if l.MaxTxs <= 0 || len(txs) <= l.MaxTxs {
+		return txs
+	}
+
+	numSystem := 0
+	for _, tx := range txs {
+		if l.IsSystem(tx) {
+			numSystem++
+		}
+	}
+
+	systemCap := min(numSystem, l.MaxTxs)
+	userCap := min(l.MaxTxs-l.Reserved, l.MaxTxs-systemCap)
+
+	selected := make([]txn.Transaction, 0, l.MaxTxs)
+	skipped := map[string]struct{}{}
+
+	for _, tx := range txs {
+		key, err := tx.GetIdentity().MarshalText()
+		if err != nil {
+			continue
+		}
+
+		_, found := skipped[string(key)]
+		if found {
+			continue
+		}
+
+		if l.IsSystem(tx) && systemCap > 0 {
+			systemCap--
+			selected = append(selected, tx)
+		} else if !l.IsSystem(tx) && userCap > 0 {
+			userCap--
+			selected = append(selected, tx)
+		} else {
+			skipped[string(key)] = struct{}{}
+		}
+	}
+
+	return selected
+}
+
+// Fill returns the transactions that fit in the budget of a block, in order,
+// and stops at the first one that exceeds what is left. The budget is not
+// limited when it is zero. A transaction that costs more than the budget alone
+// is left out with the following ones of the same identity, as it can never
+// fit in a block.
+func (l Lanes) Fill(txs []txn.Transaction, limit uint64) []txn.Transaction {
+	if limit == 0 {
+		return txs
+	}
+
+	selected := make([]txn.Transaction, 0, len(txs))
+	skipped := map[string]struct{}{}
+	left := limit
+
+	for _, tx := range txs {
+		key, err := tx.GetIdentity().MarshalText()
+		if err != nil {
+			continue
+		}
+
+		_, found := skipped[string(key)]
+		if found {
+			continue
+		}
+
+		cost := budget.Cost(tx)
+
+		if cost > limit {
+			skipped[string(key)] = struct{}{}
+			continue
+		}
+
+		if cost > left {
+			break
+		}
+
+		left -= cost
+		selected = append(selected, tx)
+	}
+
+	return selected
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,128 @@
+package cosipbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/budget"
+	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/viewchange"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/pool"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+)
+
+func TestLanes_Validate(t *testing.T) {
+	require.NoError(t, DefaultLanes().Validate())
+	require.NoError(t, Lanes{MaxTxs: 10, Reserved: 2}.Validate())
+
+	err := Lanes{MaxTxs: -1}.Validate()
+	require.EqualError(t, err, "maximum number of transactions is negative: -1")
+
+	err = Lanes{Reserved: -1}.Validate()
+	require.EqualError(t, err, "reserved number of transactions is negative: -1")
+
+	err = Lanes{Reserved: 2}.Validate()
+	require.EqualError(t, err, "reserved transactions require a maximum number of transactions")
+
+	err = Lanes{MaxTxs: 2, Reserved: 3}.Validate()
+	require.EqualError(t, err, "reserved transactions must be less than the maximum: 3 >= 2")
+}
+
+func TestLanes_IsSystem(t *testing.T) {
+	lanes := DefaultLanes()
+	signer := bls.NewSigner()
+
+	require.False(t, lanes.IsSystem(makeTx(t, 0, signer)))
+	require.True(t, lanes.IsSystem(makeSystemTx(t, 0, signer)))
+}
+
+func TestLanes_Classify(t *testing.T) {
+	lanes := DefaultLanes()
+	signer := bls.NewSigner()
+
+	require.Equal(t, pool.PriorityUser, lanes.Classify(makeTx(t, 0, signer)))
+	require.Equal(t, pool.PriorityGovernance, lanes.Classify(makeSystemTx(t, 0, signer)))
+}
+
+func TestLanes_Select(t *testing.T) {
+	alice := bls.NewSigner()
+	bob := bls.NewSigner()
+
+	txs := []txn.Transaction{
+		makeTx(t, 0, alice),
+		makeTx(t, 1, alice),
+		makeTx(t, 2, alice),
+		makeSystemTx(t, 0, bob),
+	}
+
+	lanes := DefaultLanes()
+	require.Equal(t, txs, lanes.Select(txs))
+
+	lanes.MaxTxs = 2
+	lanes.Reserved = 1
+	require.Equal(t, []txn.Transaction{txs[0], txs[3]}, lanes.Select(txs))
+
+	lanes.Reserved = 0
+	require.Equal(t, []txn.Transaction{txs[0], txs[3]}, lanes.Select(txs))
+
+	// Without system transactions, the reserved slots are left empty.
+	lanes.Reserved = 1
+	require.Equal(t, txs[:1], lanes.Select(txs[:3]))
+
+	// The following transactions of an identity are left out to keep the
+	// nonces in sequence.
+	txs = []txn.Transaction{
+		makeTx(t, 0, alice),
+		makeTx(t, 1, alice),
+		makeTx(t, 0, bob),
+	}
+
+	lanes.MaxTxs = 1
+	lanes.Reserved = 0
+	require.Equal(t, txs[:1], lanes.Select(txs))
+}
+
+func TestLanes_Fill(t *testing.T) {
+	alice := bls.NewSigner()
+	bob := bls.NewSigner()
+
+	txs := []txn.Transaction{
+		makeTx(t, 0, alice),
+		makeTx(t, 1, alice),
+		makeTx(t, 0, bob),
+	}
+
+	cost := budget.Cost(txs[0])
+
+	lanes := DefaultLanes()
+	require.Equal(t, txs, lanes.Fill(txs, 0))
+	require.Equal(t, txs, lanes.Fill(txs, 3*cost))
+
+	// The transactions are carried over from the first one that doesn't fit.
+	require.Equal(t, txs[:2], lanes.Fill(txs, 3*cost-1))
+	require.Equal(t, txs[:1], lanes.Fill(txs, cost))
+
+	// A transaction that can never fit is left out with the following ones of
+	// the same identity.
+	big := makeSystemTx(t, 0, alice)
+	txs = []txn.Transaction{big, makeTx(t, 1, alice), makeTx(t, 0, bob)}
+
+	require.Equal(t, txs[2:], lanes.Fill(txs, budget.Cost(big)-1))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeSystemTx(t *testing.T, nonce uint64, signer crypto.Signer) txn.Transaction {
+	opts := []signed.TransactionOption{
+		signed.WithArg(native.ContractArg, []byte(viewchange.ContractName)),
+	}
+
+	tx, err := signed.NewTransaction(nonce, signer.GetPublicKey(), opts...)
+	require.NoError(t, err)
+
+	return tx
+}
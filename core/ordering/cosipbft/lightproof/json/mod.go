@@ -0,0 +1,131 @@
+package json
+
+import (
+	"encoding/json"
+
+	"go.dedis.ch/dela/core/ordering/cosipbft/lightproof"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store/hashtree/binprefix"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	lightproof.RegisterProofFormat(serde.FormatJSON, newProofFormat())
+}
+
+// PathJSON is the JSON message of a Merkle path. The root is not sent as the
+// client computes it from the other fields.
+type PathJSON struct {
+	Nonce     []byte
+	Key       []byte
+	Value     []byte
+	Interiors [][]byte
+}
+
+// ProofJSON is the JSON message of a proof.
+type ProofJSON struct {
+	Genesis json.RawMessage
+	Chain   json.RawMessage
+	Path    PathJSON
+}
+
+// ProofFormat is the format engine to encode and decode the proofs.
+//
+// - implements serde.FormatEngine
+type proofFormat struct {
+	hashFac crypto.HashFactory
+}
+
+func newProofFormat() proofFormat {
+	return proofFormat{
+		hashFac: crypto.NewSha256Factory(),
+	}
+}
+
+// Encode implements serde.FormatEngine. It returns the data of the proof if
+// appropriate, otherwise an error.
+func (f proofFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	proof, ok := msg.(lightproof.Proof)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message '%T'", msg)
+	}
+
+	genesis, err := proof.GetGenesis().Serialize(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to serialize genesis: %v", err)
+	}
+
+	chain, err := proof.GetChain().Serialize(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to serialize chain: %v", err)
+	}
+
+	path := proof.GetPath()
+
+	m := ProofJSON{
+		Genesis: genesis,
+		Chain:   chain,
+		Path: PathJSON{
+			Nonce:     path.GetNonce(),
+			Key:       path.GetKey(),
+			Value:     path.GetValue(),
+			Interiors: path.GetInteriors(),
+		},
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It returns the proof of the data if
+// appropriate, otherwise an error.
+func (f proofFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := ProofJSON{}
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	factory := ctx.GetFactory(lightproof.GenesisKey{})
+	if factory == nil {
+		return nil, xerrors.New("missing genesis factory")
+	}
+
+	msg, err := factory.Deserialize(ctx, m.Genesis)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decode genesis: %v", err)
+	}
+
+	genesis, ok := msg.(types.Genesis)
+	if !ok {
+		return nil, xerrors.Errorf("invalid genesis '%T'", msg)
+	}
+
+	fac := ctx.GetFactory(lightproof.ChainKey{})
+
+	chainFac, ok := fac.(types.ChainFactory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid chain factory '%T'", fac)
+	}
+
+	chain, err := chainFac.ChainOf(ctx, m.Chain)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decode chain: %v", err)
+	}
+
+	// The root of the path is computed so that it is compared with the root of
+	// the latest block when the proof is verified.
+	path, err := binprefix.NewPath(f.hashFac, m.Path.Nonce, m.Path.Key,
+		m.Path.Value, m.Path.Interiors)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decode path: %v", err)
+	}
+
+	return lightproof.New(genesis, chain, path), nil
+}
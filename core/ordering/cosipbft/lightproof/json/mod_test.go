@@ -0,0 +1,199 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/lightproof"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/hashtree/binprefix"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/serde"
+	sjson "go.dedis.ch/dela/serde/json"
+)
+
+func init() {
+	types.RegisterGenesisFormat(fake.GoodFormat, fakeGenesisFormat{})
+	types.RegisterGenesisFormat(fake.BadFormat, fakeGenesisFormat{})
+}
+
+func TestProofFormat_Roundtrip(t *testing.T) {
+	proof := makeProof(t)
+
+	ctx := sjson.NewContext()
+
+	data, err := proof.Serialize(ctx)
+	require.NoError(t, err)
+
+	fac := makeFactory()
+
+	decoded, err := fac.ProofOf(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, proof.GetKey(), decoded.GetKey())
+	require.Equal(t, proof.GetValue(), decoded.GetValue())
+	require.Equal(t, proof.GetPath().GetRoot(), decoded.GetPath().GetRoot())
+
+	roster := decoded.GetGenesis().GetRoster()
+
+	err = lightproof.VerifyProof(decoded, roster, bls.NewVerifierFactory(nil))
+	require.NoError(t, err)
+}
+
+func TestProofFormat_Encode(t *testing.T) {
+	format := newProofFormat()
+
+	ctx := fake.NewContext()
+
+	_, err := format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message 'fake.Message'")
+
+	proof := lightproof.New(types.Genesis{}, fakeChain{}, binprefix.Path{})
+
+	data, err := format.Encode(ctx, proof)
+	require.NoError(t, err)
+	require.Equal(t, `{"Genesis":{},"Chain":{},"Path":{"Nonce":null,"Key":null,"Value":null,"Interiors":null}}`,
+		string(data))
+
+	_, err = format.Encode(fake.NewContextWithFormat(fake.MsgFormat), proof)
+	require.EqualError(t, err,
+		"failed to serialize genesis: encoding failed: format 'FakeMsg' is not implemented")
+
+	bad := lightproof.New(types.Genesis{}, fakeChain{err: fake.GetError()}, binprefix.Path{})
+	_, err = format.Encode(ctx, bad)
+	require.EqualError(t, err, fake.Err("failed to serialize chain"))
+
+	_, err = format.Encode(fake.NewBadContext(), proof)
+	require.EqualError(t, err, fake.Err("failed to marshal"))
+}
+
+func TestProofFormat_Decode(t *testing.T) {
+	format := newProofFormat()
+
+	ctx := fake.NewContext()
+	ctx = serde.WithFactory(ctx, lightproof.GenesisKey{}, types.NewGenesisFactory(nil))
+	ctx = serde.WithFactory(ctx, lightproof.ChainKey{}, fakeChainFac{})
+
+	data := []byte(`{"Genesis":{},"Chain":{},"Path":{"Key":"QQ=="}}`)
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, []byte("A"), msg.(lightproof.Proof).GetKey())
+
+	_, err = format.Decode(fake.NewBadContext(), data)
+	require.EqualError(t, err, fake.Err("failed to unmarshal"))
+
+	_, err = format.Decode(fake.NewContext(), data)
+	require.EqualError(t, err, "missing genesis factory")
+
+	badCtx := serde.WithFactory(ctx, lightproof.GenesisKey{}, fake.NewBadMessageFactory())
+	_, err = format.Decode(badCtx, data)
+	require.EqualError(t, err, fake.Err("failed to decode genesis"))
+
+	badCtx = serde.WithFactory(ctx, lightproof.GenesisKey{}, fake.MessageFactory{})
+	_, err = format.Decode(badCtx, data)
+	require.EqualError(t, err, "invalid genesis 'fake.Message'")
+
+	badCtx = serde.WithFactory(ctx, lightproof.ChainKey{}, nil)
+	_, err = format.Decode(badCtx, data)
+	require.EqualError(t, err, "invalid chain factory '<nil>'")
+
+	badCtx = serde.WithFactory(ctx, lightproof.ChainKey{}, fakeChainFac{err: fake.GetError()})
+	_, err = format.Decode(badCtx, data)
+	require.EqualError(t, err, fake.Err("failed to decode chain"))
+
+	format.hashFac = fake.NewHashFactory(fake.NewBadHash())
+	_, err = format.Decode(ctx, data)
+	require.EqualError(t, err,
+		fake.Err("failed to decode path: failed to compute root: while preparing: empty node failed"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeProof(t *testing.T) lightproof.Proof {
+	ca := fake.NewAuthority(1, bls.Generate)
+	signer := ca.GetSigner(0)
+
+	roster := authority.FromAuthority(ca)
+
+	tree := binprefix.NewMerkleTree(fake.NewInMemoryDB(), binprefix.Nonce{})
+
+	staged, err := tree.Stage(func(snap store.Snapshot) error {
+		return snap.Set([]byte("A"), []byte("value"))
+	})
+	require.NoError(t, err)
+
+	path, err := staged.GetPath([]byte("A"))
+	require.NoError(t, err)
+
+	genesis, err := types.NewGenesis(roster)
+	require.NoError(t, err)
+
+	root := types.Digest{}
+	copy(root[:], staged.GetRoot())
+
+	block, err := types.NewBlock(simple.NewResult(nil), types.WithTreeRoot(root))
+	require.NoError(t, err)
+
+	link, err := types.NewBlockLink(genesis.GetHash(), block)
+	require.NoError(t, err)
+
+	prepare, err := signer.Sign(link.GetHash().Bytes())
+	require.NoError(t, err)
+
+	msg, err := prepare.MarshalBinary()
+	require.NoError(t, err)
+
+	commit, err := signer.Sign(msg)
+	require.NoError(t, err)
+
+	link, err = types.NewBlockLink(genesis.GetHash(), block, types.WithSignatures(prepare, commit))
+	require.NoError(t, err)
+
+	return lightproof.New(genesis, types.NewChain(link, nil), path.(binprefix.Path))
+}
+
+func makeFactory() lightproof.Factory {
+	rosterFac := authority.NewFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+	csFac := authority.NewChangeSetFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+
+	blockFac := types.NewBlockFactory(simple.NewResultFactory(signed.NewTransactionFactory()))
+	linkFac := types.NewLinkFactory(blockFac, bls.NewSignatureFactory(), csFac)
+
+	return lightproof.NewFactory(types.NewGenesisFactory(rosterFac), types.NewChainFactory(linkFac))
+}
+
+type fakeChain struct {
+	types.Chain
+
+	err error
+}
+
+func (c fakeChain) Serialize(serde.Context) ([]byte, error) {
+	return []byte("{}"), c.err
+}
+
+type fakeChainFac struct {
+	types.ChainFactory
+
+	err error
+}
+
+func (fac fakeChainFac) ChainOf(serde.Context, []byte) (types.Chain, error) {
+	return fakeChain{}, fac.err
+}
+
+type fakeGenesisFormat struct{}
+
+func (fakeGenesisFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	return []byte("{}"), nil
+}
+
+func (fakeGenesisFormat) Decode(serde.Context, []byte) (serde.Message, error) {
+	return types.Genesis{}, nil
+}
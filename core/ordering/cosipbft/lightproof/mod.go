@@ -0,0 +1,207 @@
+// Package lightproof implements the proofs of the finality of a value that a
+// client verifies without a node.
+//
+// A proof bundles the genesis block, the chain of forward links up to the
+// latest block and the Merkle path of a key in the state of that block. It is
+// self-contained so that a stateless client that only knows the roster of the
+// genesis block verifies the inclusion, or the absence, of the key. The JSON
+// format of the proofs is registered by importing the json subpackage.
+package lightproof
+
+import (
+	"bytes"
+
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store/hashtree/binprefix"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
+	"golang.org/x/xerrors"
+)
+
+var proofFormats = registry.NewSimpleRegistry()
+
+// RegisterProofFormat registers the engine for the given format.
+func RegisterProofFormat(f serde.Format, e serde.FormatEngine) {
+	proofFormats.Register(f, e)
+}
+
+// Proof is a proof of the value of a key in the latest block of a chain.
+//
+// - implements serde.Message
+type Proof struct {
+	genesis types.Genesis
+	chain   types.Chain
+	path    binprefix.Path
+}
+
+// New creates a proof from the genesis block, the chain up to the latest block
+// and the path of the key in its state.
+func New(genesis types.Genesis, chain types.Chain, path binprefix.Path) Proof {
+	return Proof{
+		genesis: genesis,
+		chain:   chain,
+		path:    path,
+	}
+}
+
+// GetGenesis returns the genesis block of the chain.
+func (p Proof) GetGenesis() types.Genesis {
+	return p.genesis
+}
+
+// GetChain returns the chain of forward links up to the latest block.
+func (p Proof) GetChain() types.Chain {
+	return p.chain
+}
+
+// GetPath returns the path of the key in the state of the latest block.
+func (p Proof) GetPath() binprefix.Path {
+	return p.path
+}
+
+// GetKey returns the key of the proof.
+func (p Proof) GetKey() []byte {
+	return p.path.GetKey()
+}
+
+// GetValue returns the value of the key, or nil if the proof is a proof of
+// absence.
+func (p Proof) GetValue() []byte {
+	return p.path.GetValue()
+}
+
+// GetBlock returns the latest block of the chain, which is the block the
+// proof is made for.
+func (p Proof) GetBlock() types.Block {
+	return p.chain.GetBlock()
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// proof.
+func (p Proof) Serialize(ctx serde.Context) ([]byte, error) {
+	format := proofFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, p)
+	if err != nil {
+		return nil, xerrors.Errorf("encoding failed: %v", err)
+	}
+
+	return data, nil
+}
+
+// VerifyProof verifies the proof against the roster of the genesis block known
+// by the client. The genesis block of the proof must have the same roster, the
+// chain must be signed from it up to the latest block, and the path must lead
+// to the tree root of that block. It does not need a node, nor the blocks in
+// between.
+func VerifyProof(p Proof, roster authority.Authority, fac crypto.VerifierFactory) error {
+	expected, err := fingerprint(roster)
+	if err != nil {
+		return xerrors.Errorf("roster: %v", err)
+	}
+
+	actual, err := fingerprint(p.genesis.GetRoster())
+	if err != nil {
+		return xerrors.Errorf("genesis roster: %v", err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		return xerrors.New("mismatch genesis roster")
+	}
+
+	err = p.chain.Verify(p.genesis, fac)
+	if err != nil {
+		return xerrors.Errorf("invalid chain: %v", err)
+	}
+
+	root := types.Digest{}
+	copy(root[:], p.path.GetRoot())
+
+	last := p.chain.GetBlock().GetTreeRoot()
+	if last != root {
+		return xerrors.Errorf("mismatch tree root: '%v' != '%v'", last, root)
+	}
+
+	return nil
+}
+
+// VerifyEvents verifies that the proof is the proof of the events of the
+// transaction. The proof itself must be verified with VerifyProof.
+func VerifyEvents(p Proof, txID []byte, events []execution.Event) error {
+	if !bytes.Equal(p.GetKey(), execution.EventsKey(txID)) {
+		return xerrors.Errorf("mismatch key '%#x'", p.GetKey())
+	}
+
+	digest, err := execution.EventsDigest(events)
+	if err != nil {
+		return xerrors.Errorf("failed to digest events: %v", err)
+	}
+
+	if !bytes.Equal(p.GetValue(), digest) {
+		return xerrors.New("mismatch events")
+	}
+
+	return nil
+}
+
+func fingerprint(roster authority.Authority) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+
+	err := authority.Fingerprint(buffer, roster, authority.FingerprintV2)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to fingerprint: %v", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// GenesisKey is the key of the genesis factory.
+type GenesisKey struct{}
+
+// ChainKey is the key of the chain factory.
+type ChainKey struct{}
+
+// Factory is the factory to deserialize the proofs.
+//
+// - implements serde.Factory
+type Factory struct {
+	genesisFac serde.Factory
+	chainFac   types.ChainFactory
+}
+
+// NewFactory creates a new factory of proofs.
+func NewFactory(genesisFac serde.Factory, chainFac types.ChainFactory) Factory {
+	return Factory{
+		genesisFac: genesisFac,
+		chainFac:   chainFac,
+	}
+}
+
+// Deserialize implements serde.Factory. It returns the proof from the data if
+// appropriate, otherwise an error.
+func (f Factory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	return f.ProofOf(ctx, data)
+}
+
+// ProofOf returns the proof from the data if appropriate, otherwise an error.
+func (f Factory) ProofOf(ctx serde.Context, data []byte) (Proof, error) {
+	format := proofFormats.Get(ctx.GetFormat())
+
+	ctx = serde.WithFactory(ctx, GenesisKey{}, f.genesisFac)
+	ctx = serde.WithFactory(ctx, ChainKey{}, f.chainFac)
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return Proof{}, xerrors.Errorf("decoding failed: %v", err)
+	}
+
+	proof, ok := msg.(Proof)
+	if !ok {
+		return Proof{}, xerrors.Errorf("invalid proof '%T'", msg)
+	}
+
+	return proof, nil
+}
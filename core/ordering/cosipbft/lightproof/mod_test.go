@@ -0,0 +1,147 @@
+package lightproof
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/hashtree/binprefix"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+)
+
+func init() {
+	RegisterProofFormat(fake.GoodFormat, fake.Format{Msg: Proof{}})
+	RegisterProofFormat(fake.BadFormat, fake.NewBadFormat())
+	RegisterProofFormat(fake.MsgFormat, fake.NewMsgFormat())
+}
+
+func TestProof_Getters(t *testing.T) {
+	proof, _ := makeProof(t, fake.NewAuthority(3, fake.NewSigner))
+
+	require.Equal(t, []byte("A"), proof.GetKey())
+	require.Equal(t, []byte("value"), proof.GetValue())
+	require.Equal(t, uint64(0), proof.GetBlock().GetIndex())
+	require.Equal(t, 3, proof.GetGenesis().GetRoster().Len())
+	require.Len(t, proof.GetChain().GetLinks(), 1)
+	require.Equal(t, []byte("A"), proof.GetPath().GetKey())
+}
+
+func TestProof_Serialize(t *testing.T) {
+	proof := Proof{}
+
+	data, err := proof.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = proof.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("encoding failed"))
+}
+
+func TestVerifyProof(t *testing.T) {
+	ca := fake.NewAuthority(3, fake.NewSigner)
+
+	proof, roster := makeProof(t, ca)
+
+	err := VerifyProof(proof, roster, fake.VerifierFactory{})
+	require.NoError(t, err)
+
+	// The client only needs the roster, not the genesis block.
+	err = VerifyProof(proof, authority.FromAuthority(ca), fake.VerifierFactory{})
+	require.NoError(t, err)
+
+	err = VerifyProof(proof, roster.Take(mino.RangeFilter(0, 2)).(authority.Authority),
+		fake.VerifierFactory{})
+	require.EqualError(t, err, "mismatch genesis roster")
+
+	err = VerifyProof(proof, roster, fake.NewVerifierFactory(fake.NewBadVerifier()))
+	require.EqualError(t, err, fake.Err("invalid chain: invalid prepare signature"))
+
+	bad := authority.New([]mino.Address{fake.NewBadAddress()}, []crypto.PublicKey{fake.PublicKey{}})
+	err = VerifyProof(proof, bad, fake.VerifierFactory{})
+	require.EqualError(t, err, fake.Err("roster: failed to fingerprint: couldn't marshal address"))
+
+	// The path does not lead to the root of the block.
+	other, _ := makeProof(t, ca)
+	other.chain = proof.chain
+	other.path, err = binprefix.NewPath(crypto.NewSha256Factory(), nil, []byte("A"), nil, nil)
+	require.NoError(t, err)
+
+	err = VerifyProof(other, roster, fake.VerifierFactory{})
+	require.Error(t, err)
+	require.Regexp(t, "^mismatch tree root: ", err.Error())
+}
+
+func TestVerifyEvents(t *testing.T) {
+	events := []execution.Event{execution.NewEvent("contract", "a")}
+
+	digest, err := execution.EventsDigest(events)
+	require.NoError(t, err)
+
+	path, err := binprefix.NewPath(crypto.NewSha256Factory(), nil,
+		execution.EventsKey([]byte{1}), digest, nil)
+	require.NoError(t, err)
+
+	proof := New(types.Genesis{}, nil, path)
+
+	err = VerifyEvents(proof, []byte{1}, events)
+	require.NoError(t, err)
+
+	err = VerifyEvents(proof, []byte{2}, events)
+	require.Error(t, err)
+	require.Regexp(t, "^mismatch key ", err.Error())
+
+	err = VerifyEvents(proof, []byte{1}, nil)
+	require.EqualError(t, err, "mismatch events")
+}
+
+func TestFactory_Deserialize(t *testing.T) {
+	fac := NewFactory(nil, nil)
+
+	msg, err := fac.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.IsType(t, Proof{}, msg)
+
+	_, err = fac.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("decoding failed"))
+
+	_, err = fac.Deserialize(fake.NewContextWithFormat(fake.MsgFormat), nil)
+	require.EqualError(t, err, "invalid proof 'fake.Message'")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeProof(t *testing.T, ca crypto.CollectiveAuthority) (Proof, authority.Authority) {
+	roster := authority.FromAuthority(ca)
+
+	tree := binprefix.NewMerkleTree(fake.NewInMemoryDB(), binprefix.Nonce{})
+
+	staged, err := tree.Stage(func(snap store.Snapshot) error {
+		return snap.Set([]byte("A"), []byte("value"))
+	})
+	require.NoError(t, err)
+
+	path, err := staged.GetPath([]byte("A"))
+	require.NoError(t, err)
+
+	genesis, err := types.NewGenesis(roster)
+	require.NoError(t, err)
+
+	root := types.Digest{}
+	copy(root[:], staged.GetRoot())
+
+	block, err := types.NewBlock(simple.NewResult(nil), types.WithTreeRoot(root))
+	require.NoError(t, err)
+
+	link, err := types.NewBlockLink(genesis.GetHash(), block,
+		types.WithSignatures(fake.Signature{}, fake.Signature{}))
+	require.NoError(t, err)
+
+	return New(genesis, types.NewChain(link, nil), path.(binprefix.Path)), roster
+}
@@ -32,22 +32,28 @@ package cosipbft
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"math"
 	"time"
 
 	"go.dedis.ch/dela"
 	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution"
 	"go.dedis.ch/dela/core/execution/native"
 	"go.dedis.ch/dela/core/ordering"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
 	"go.dedis.ch/dela/core/ordering/cosipbft/blocksync"
+	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/budget"
 	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/viewchange"
+	"go.dedis.ch/dela/core/ordering/cosipbft/epoch"
+	"go.dedis.ch/dela/core/ordering/cosipbft/lightproof"
 	"go.dedis.ch/dela/core/ordering/cosipbft/pbft"
 	"go.dedis.ch/dela/core/ordering/cosipbft/types"
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/store/hashtree"
+	"go.dedis.ch/dela/core/store/hashtree/binprefix"
 	"go.dedis.ch/dela/core/store/kv"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/txn/pool"
@@ -55,6 +61,7 @@ import (
 	"go.dedis.ch/dela/cosi"
 	"go.dedis.ch/dela/cosi/threshold"
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/mino"
 	"golang.org/x/xerrors"
 )
@@ -64,6 +71,10 @@ const (
 	// happen.
 	RoundTimeout = 10 * time.Second
 
+	// UnresponsiveRoundTimeout is the maximum of time the service waits for a
+	// leader that doesn't answer the heartbeats of the overlay.
+	UnresponsiveRoundTimeout = 2 * time.Second
+
 	// RoundWait is the constant value of the exponential backoff use between
 	// round failures.
 	RoundWait = 5 * time.Millisecond
@@ -75,13 +86,62 @@ const (
 )
 
 // RegisterRosterContract registers the native smart contract to update the
-// roster to the given service.
+// roster and the configuration of the epochs to the given service.
 func RegisterRosterContract(exec *native.Service, rFac authority.Factory, srvc access.Service) {
-	contract := viewchange.NewContract(keyRoster[:], keyAccess[:], rFac, srvc)
+	contract := viewchange.NewContract(keyRoster[:], keyAccess[:], rFac, srvc).
+		WithEpochs(keyEpoch[:])
 
 	viewchange.RegisterContract(exec, contract)
 }
 
+// RegisterBudgetContract registers the native smart contract to update the
+// budget of the blocks to the given service. The members allowed to update the
+// roster are allowed to update the budget.
+func RegisterBudgetContract(exec *native.Service, srvc access.Service) {
+	contract := budget.NewContract(keyBudget[:], viewchange.NewCreds(keyAccess[:]), srvc)
+
+	budget.RegisterContract(exec, contract)
+}
+
+// ReadBudget returns the budget of the blocks stored in the state, or zero when
+// the blocks are not limited.
+func ReadBudget(snap store.Readable) (uint64, error) {
+	return budget.Read(snap, keyBudget[:])
+}
+
+// ReadEpochs returns the configuration of the epochs stored in the state, which
+// is disabled when the committee does not rotate.
+func ReadEpochs(snap store.Readable) (epoch.Config, error) {
+	return epoch.Read(snap, keyEpoch[:])
+}
+
+// ReadFingerprint returns the version of the fingerprint of the roster that
+// defines the digest of the genesis block, which is a parameter of the chain
+// stored in the state.
+func ReadFingerprint(snap store.Readable) (authority.FingerprintVersion, error) {
+	value, err := snap.Get(keyFingerprint[:])
+	if err != nil {
+		return 0, xerrors.Errorf("failed to read version: %v", err)
+	}
+
+	if len(value) == 0 {
+		return authority.FingerprintV1, nil
+	}
+
+	if len(value) != 4 {
+		return 0, xerrors.Errorf("invalid version '%x'", value)
+	}
+
+	return authority.FingerprintVersion(binary.LittleEndian.Uint32(value)), nil
+}
+
+func encodeFingerprint(version authority.FingerprintVersion) []byte {
+	buffer := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buffer, uint32(version))
+
+	return buffer
+}
+
 // Service is an ordering service using collective signatures combined with PBFT
 // to create a chain of blocks.
 //
@@ -94,9 +154,13 @@ type Service struct {
 	actor       cosi.Actor
 	val         validation.Service
 	verifierFac crypto.VerifierFactory
+	lanes       Lanes
+	vrfSigner   *vrf.Signer
+	peers       mino.PeerStatus
 
 	timeoutRound             time.Duration
 	timeoutRoundAfterFailure time.Duration
+	timeoutUnresponsive      time.Duration
 	timeoutViewchange        time.Duration
 
 	events      chan ordering.Event
@@ -106,9 +170,14 @@ type Service struct {
 }
 
 type serviceTemplate struct {
-	hashFac crypto.HashFactory
-	blocks  blockstore.BlockStore
-	genesis blockstore.GenesisStore
+	hashFac     crypto.HashFactory
+	blocks      blockstore.BlockStore
+	genesis     blockstore.GenesisStore
+	authorities blockstore.AuthorityStore
+	lanes       Lanes
+	vrfSigner   *vrf.Signer
+	peers       mino.PeerStatus
+	fingerprint authority.FingerprintVersion
 }
 
 // ServiceOption is the type of option to set some fields of the service.
@@ -128,6 +197,13 @@ func WithBlockStore(store blockstore.BlockStore) ServiceOption {
 	}
 }
 
+// WithAuthorityStore is an option to set the store of the authority snapshots.
+func WithAuthorityStore(store blockstore.AuthorityStore) ServiceOption {
+	return func(tmpl *serviceTemplate) {
+		tmpl.authorities = store
+	}
+}
+
 // WithHashFactory is an option to set the hash factory used by the service.
 func WithHashFactory(fac crypto.HashFactory) ServiceOption {
 	return func(tmpl *serviceTemplate) {
@@ -135,6 +211,44 @@ func WithHashFactory(fac crypto.HashFactory) ServiceOption {
 	}
 }
 
+// WithLanes is an option to set the capacity of the blocks and the slots
+// reserved to the system transactions.
+func WithLanes(lanes Lanes) ServiceOption {
+	return func(tmpl *serviceTemplate) {
+		tmpl.lanes = lanes
+	}
+}
+
+// WithVRFSigner is an option to set the private key of the verifiable random
+// function of the node. When the roster has the public key for the node, the
+// blocks it proposes as a leader hold the proof of the election of the next
+// leader. By default, the leader only changes after a view change.
+func WithVRFSigner(signer vrf.Signer) ServiceOption {
+	return func(tmpl *serviceTemplate) {
+		tmpl.vrfSigner = &signer
+	}
+}
+
+// WithPeerStatus is an option to set the liveness of the peers observed by the
+// overlay. A node which is not the leader waits for a shorter round when the
+// leader is unresponsive, so that the view change happens sooner. By default,
+// every leader is considered responsive.
+func WithPeerStatus(status mino.PeerStatus) ServiceOption {
+	return func(tmpl *serviceTemplate) {
+		tmpl.peers = status
+	}
+}
+
+// WithFingerprint is an option to set the version of the fingerprint of the
+// roster of the genesis block when the node creates a chain. The participants
+// adopt the version of the genesis block they receive. By default, the first
+// version is used.
+func WithFingerprint(version authority.FingerprintVersion) ServiceOption {
+	return func(tmpl *serviceTemplate) {
+		tmpl.fingerprint = version
+	}
+}
+
 // ServiceParam is the different components to provide to the service. All the
 // fields are mandatory and it will panic if any is nil.
 type ServiceParam struct {
@@ -150,35 +264,52 @@ type ServiceParam struct {
 // NewService starts a new ordering service.
 func NewService(param ServiceParam, opts ...ServiceOption) (*Service, error) {
 	tmpl := serviceTemplate{
-		hashFac: crypto.NewSha256Factory(),
-		genesis: blockstore.NewGenesisStore(),
-		blocks:  blockstore.NewInMemory(),
+		hashFac:     crypto.NewSha256Factory(),
+		genesis:     blockstore.NewGenesisStore(),
+		blocks:      blockstore.NewInMemory(),
+		authorities: blockstore.NewAuthorityStore(),
+		lanes:       DefaultLanes(),
+		fingerprint: authority.FingerprintV1,
 	}
 
 	for _, opt := range opts {
 		opt(&tmpl)
 	}
 
+	// The snapshots of a chain created before the authority store existed are
+	// rebuilt from the change sets of the blocks.
+	err := blockstore.BackfillAuthorities(tmpl.authorities, tmpl.genesis, tmpl.blocks)
+	if err != nil {
+		return nil, xerrors.Errorf("authorities: %v", err)
+	}
+
 	proc := newProcessor()
 	proc.hashFactory = tmpl.hashFac
 	proc.blocks = tmpl.blocks
 	proc.genesis = tmpl.genesis
+	proc.authorities = tmpl.authorities
 	proc.pool = param.Pool
 	proc.rosterFac = authority.NewFactory(param.Mino.GetAddressFactory(), param.Cosi.GetPublicKeyFactory())
 	proc.tree = blockstore.NewTreeCache(param.Tree)
 	proc.access = param.Access
+	proc.fingerprint = tmpl.fingerprint
 	proc.logger = dela.Logger.With().Str("addr", param.Mino.GetAddress().String()).Logger()
 
+	// The committee of the next epoch is sampled in the state by the
+	// validation of the blocks, whoever executes them.
+	val := epochValidation{Service: param.Validation, proc: proc}
+
 	pcparam := pbft.StateMachineParam{
 		Logger:          proc.logger,
-		Validation:      param.Validation,
+		Validation:      val,
 		Signer:          param.Cosi.GetSigner(),
 		VerifierFactory: param.Cosi.GetVerifierFactory(),
 		Blocks:          tmpl.blocks,
 		Genesis:         tmpl.genesis,
 		Tree:            proc.tree,
-		AuthorityReader: proc.readRoster,
+		AuthorityReader: proc.readAuthority,
 		DB:              param.DB,
+		Authorities:     tmpl.authorities,
 	}
 
 	proc.pbftsm = pbft.NewStateMachine(pcparam)
@@ -217,15 +348,21 @@ func NewService(param ServiceParam, opts ...ServiceOption) (*Service, error) {
 		return nil, xerrors.Errorf("creating cosi failed: %v", err)
 	}
 
+	h := mino.WithPolicy(mino.NewClassifiedHandler(proc, mino.ClassConsensus), mino.ProtocolPolicy)
+
 	s := &Service{
 		processor:                proc,
 		me:                       param.Mino.GetAddress(),
-		rpc:                      mino.MustCreateRPC(param.Mino, rpcName, proc, fac),
+		rpc:                      mino.MustCreateRPC(param.Mino, rpcName, h, fac),
 		actor:                    actor,
-		val:                      param.Validation,
+		val:                      val,
 		verifierFac:              param.Cosi.GetVerifierFactory(),
+		lanes:                    tmpl.lanes,
+		vrfSigner:                tmpl.vrfSigner,
+		peers:                    tmpl.peers,
 		timeoutRound:             RoundTimeout,
 		timeoutRoundAfterFailure: RoundTimeout,
+		timeoutUnresponsive:      UnresponsiveRoundTimeout,
 		timeoutViewchange:        RoundTimeout,
 		events:                   make(chan ordering.Event, 1),
 		closing:                  make(chan struct{}),
@@ -251,7 +388,7 @@ func NewService(param ServiceParam, opts ...ServiceOption) (*Service, error) {
 
 // Setup creates a genesis block and sends it to the collective authority.
 func (s *Service) Setup(ctx context.Context, ca crypto.CollectiveAuthority) error {
-	err := s.storeGenesis(authority.FromAuthority(ca), nil)
+	err := s.storeGenesis(authority.FromAuthority(ca), s.fingerprint, nil)
 	if err != nil {
 		return xerrors.Errorf("creating genesis: %v", err)
 	}
@@ -305,6 +442,36 @@ func (s *Service) GetProof(key []byte) (ordering.Proof, error) {
 	return newProof(path, chain), nil
 }
 
+// GetLightProof returns the proof of absence or inclusion of the key for the
+// latest block, bundled with the genesis block so that a client verifies it
+// with the roster of the genesis block only.
+func (s *Service) GetLightProof(key []byte) (lightproof.Proof, error) {
+	proof, err := s.GetProof(key)
+	if err != nil {
+		return lightproof.Proof{}, err
+	}
+
+	p := proof.(Proof)
+
+	path, ok := p.path.(binprefix.Path)
+	if !ok {
+		return lightproof.Proof{}, xerrors.Errorf("unsupported path '%T'", p.path)
+	}
+
+	genesis, err := s.genesis.Get()
+	if err != nil {
+		return lightproof.Proof{}, xerrors.Errorf("reading genesis: %v", err)
+	}
+
+	return lightproof.New(genesis, p.chain, path), nil
+}
+
+// GetEventsProof returns the light proof of the events emitted by the
+// transaction, which is verified with lightproof.VerifyEvents.
+func (s *Service) GetEventsProof(txID []byte) (lightproof.Proof, error) {
+	return s.GetLightProof(execution.EventsKey(txID))
+}
+
 // GetStore implements ordering.Service. It returns the current tree as a
 // read-only storage.
 func (s *Service) GetStore() store.Readable {
@@ -316,6 +483,34 @@ func (s *Service) GetRoster() (authority.Authority, error) {
 	return s.getCurrentRoster()
 }
 
+// GetCommittee returns the authority that signs the next block, which is the
+// committee of the current epoch, or the whole roster when the epochs are
+// disabled.
+func (s *Service) GetCommittee() (authority.Authority, error) {
+	return s.getCurrentAuthority()
+}
+
+// GetRosterAt returns the authority that signs the block at the given index.
+func (s *Service) GetRosterAt(index uint64) (authority.Authority, error) {
+	roster, err := s.authorities.GetByIndex(index)
+	if err != nil {
+		return nil, xerrors.Errorf("authority store: %v", err)
+	}
+
+	return roster, nil
+}
+
+// GetRosterDiff returns the change set to apply to the authority of the block
+// at the first index to get the authority of the block at the second index.
+func (s *Service) GetRosterDiff(from, to uint64) (authority.ChangeSet, error) {
+	cs, err := s.authorities.Diff(from, to)
+	if err != nil {
+		return nil, xerrors.Errorf("authority store: %v", err)
+	}
+
+	return cs, nil
+}
+
 // Watch implements ordering.Service. It returns a channel that will be
 // populated with new incoming blocks and some information about them. The
 // channel must be listened at all time and the context must be closed when
@@ -334,6 +529,40 @@ func (s *Service) Watch(ctx context.Context) <-chan ordering.Event {
 	return obs.ch
 }
 
+// WatchFrom implements ordering.Service. It returns a channel populated with
+// the events of the blocks from the index, followed by the events of the new
+// blocks. The replay starts at the oldest block kept by the node when the
+// index has been pruned. The context must be closed when done.
+func (s *Service) WatchFrom(ctx context.Context, index uint64) <-chan ordering.Event {
+	live := s.Watch(ctx)
+
+	pruner, ok := s.blocks.(blockstore.Pruner)
+	if ok && index < pruner.GetPruned() {
+		index = pruner.GetPruned()
+	}
+
+	history := func(index uint64) (ordering.Event, bool) {
+		if index >= s.blocks.Len() {
+			return ordering.Event{}, false
+		}
+
+		link, err := s.blocks.GetByIndex(index)
+		if err != nil {
+			s.logger.Warn().Err(err).Uint64("index", index).Msg("replay stopped")
+			return ordering.Event{}, false
+		}
+
+		event := ordering.Event{
+			Index:        link.GetBlock().GetIndex(),
+			Transactions: link.GetBlock().GetData().GetTransactionResults(),
+		}
+
+		return event, true
+	}
+
+	return ordering.Replay(ctx, index, live, history)
+}
+
 // Close implements ordering.Service. It gracefully closes the service. It will
 // announce the closing request and wait for the current to end before
 // returning.
@@ -388,6 +617,7 @@ func (s *Service) watchBlocks() {
 	}
 }
 
+// refreshRoster updates the participants of the pool with the current roster.
 func (s *Service) refreshRoster() error {
 	roster, err := s.getCurrentRoster()
 	if err != nil {
@@ -466,6 +696,11 @@ func (s *Service) doRound(ctx context.Context) error {
 		return xerrors.Errorf("reading roster: %v", err)
 	}
 
+	committee, err := s.getCurrentAuthority()
+	if err != nil {
+		return xerrors.Errorf("reading committee: %v", err)
+	}
+
 	leader, err := s.pbftsm.GetLeader()
 	if err != nil {
 		return xerrors.Errorf("reading leader: %v", err)
@@ -476,6 +711,12 @@ func (s *Service) doRound(ctx context.Context) error {
 		timeout = s.timeoutRoundAfterFailure
 	}
 
+	if !s.me.Equal(leader) && timeout > s.timeoutUnresponsive && !s.isResponsive(leader) {
+		s.logger.Info().Stringer("leader", leader).Msg("leader is unresponsive")
+
+		timeout = s.timeoutUnresponsive
+	}
+
 	for !s.me.Equal(leader) {
 		// Only enters the loop if the node is not the leader. It has to wait
 		// for the new block, or the round timeout, to proceed.
@@ -488,6 +729,13 @@ func (s *Service) doRound(ctx context.Context) error {
 				return nil
 			}
 
+			_, index := committee.GetPublicKey(s.me)
+			if index < 0 {
+				// A node outside of the committee of the epoch cannot vote
+				// for a view change and waits for the next round.
+				return nil
+			}
+
 			s.logger.Warn().Msg("round reached the timeout")
 
 			// Mark that the view change happened during this round.
@@ -503,7 +751,7 @@ func (s *Service) doRound(ctx context.Context) error {
 
 			viewMsg := types.NewViewMessage(view.GetID(), view.GetLeader(), view.GetSignature())
 
-			resps, err := s.rpc.Call(ctx, viewMsg, roster)
+			resps, err := s.rpc.Call(ctx, viewMsg, committee)
 			if err != nil {
 				cancel()
 				return xerrors.Errorf("rpc failed to send views: %v", err)
@@ -571,6 +819,16 @@ func (s *Service) doRound(ctx context.Context) error {
 	return nil
 }
 
+// isResponsive returns true if the peer answers the heartbeats of the overlay,
+// or if the liveness of the peers is unknown.
+func (s *Service) isResponsive(addr mino.Address) bool {
+	if s.peers == nil {
+		return true
+	}
+
+	return mino.IsResponsive(s.peers, addr)
+}
+
 func (s *Service) doPBFT(ctx context.Context) error {
 	var id types.Digest
 	var block types.Block
@@ -580,13 +838,32 @@ func (s *Service) doPBFT(ctx context.Context) error {
 		// have accepted, but somehow the finalization failed.
 		id, block = s.pbftsm.GetCommit()
 	} else {
-		txs := s.pool.Gather(ctx, pool.Config{Min: 1})
+		// The pool drains the system transactions first, so that they are not
+		// left behind the user ones when the budget is exhausted.
+		txs := s.pool.Gather(ctx, pool.Config{Min: 1, Classifier: s.lanes})
 		if len(txs) == 0 {
 			s.logger.Debug().Msg("no transaction in pool")
 
 			return nil
 		}
 
+		// System transactions are prioritized and the remaining ones stay in
+		// the pool for the next rounds, like the ones that exceed the budget
+		// of the block.
+		txs = s.lanes.Select(txs)
+
+		limit, err := ReadBudget(s.tree.Get())
+		if err != nil {
+			return xerrors.Errorf("read budget failed: %v", err)
+		}
+
+		txs = s.lanes.Fill(txs, limit)
+		if len(txs) == 0 {
+			s.logger.Debug().Msg("no transaction within the budget")
+
+			return nil
+		}
+
 		s.logger.Debug().
 			Int("num", len(txs)).
 			Msg("transactions have been found")
@@ -596,17 +873,25 @@ func (s *Service) doPBFT(ctx context.Context) error {
 			return ctx.Err()
 		}
 
-		data, root, err := s.prepareData(txs)
+		index := s.blocks.Len()
+
+		data, root, err := s.prepareData(txs, index)
 		if err != nil {
 			return xerrors.Errorf("failed to prepare data: %v", err)
 		}
 
-		block, err = types.NewBlock(
-			data,
+		opts := []types.BlockOption{
 			types.WithTreeRoot(root),
-			types.WithIndex(uint64(s.blocks.Len())),
-			types.WithHashFactory(s.hashFactory))
+			types.WithIndex(index),
+			types.WithHashFactory(s.hashFactory),
+		}
 
+		proofOpts, err := s.prepareProof()
+		if err != nil {
+			return xerrors.Errorf("failed to prove election: %v", err)
+		}
+
+		block, err = types.NewBlock(data, append(opts, proofOpts...)...)
 		if err != nil {
 			return xerrors.Errorf("creating block failed: %v", err)
 		}
@@ -622,10 +907,17 @@ func (s *Service) doPBFT(ctx context.Context) error {
 		return xerrors.Errorf("read roster failed: %v", err)
 	}
 
+	// Only the committee of the epoch signs the block, while the rest of the
+	// roster follows the chain with the synchronizations.
+	committee, err := s.getCurrentAuthority()
+	if err != nil {
+		return xerrors.Errorf("read committee failed: %v", err)
+	}
+
 	// 1. Prepare phase
 	req := types.NewBlockMessage(block, s.prepareViews())
 
-	sig, err := s.actor.Sign(ctx, req, roster)
+	sig, err := s.actor.Sign(ctx, req, committee)
 	if err != nil {
 		return xerrors.Errorf("prepare signature failed: %v", err)
 	}
@@ -635,7 +927,7 @@ func (s *Service) doPBFT(ctx context.Context) error {
 	// 2. Commit phase
 	commit := types.NewCommit(id, sig)
 
-	sig, err = s.actor.Sign(ctx, commit, roster)
+	sig, err = s.actor.Sign(ctx, commit, committee)
 	if err != nil {
 		return xerrors.Errorf("commit signature failed: %v", err)
 	}
@@ -645,7 +937,7 @@ func (s *Service) doPBFT(ctx context.Context) error {
 	// 3. Propagation phase
 	done := types.NewDone(id, sig)
 
-	resps, err := s.rpc.Call(ctx, done, roster)
+	resps, err := s.rpc.Call(ctx, done, committee)
 	if err != nil {
 		return xerrors.Errorf("propagation failed: %v", err)
 	}
@@ -677,11 +969,62 @@ func (s *Service) prepareViews() map[mino.Address]types.ViewMessage {
 	return msgs
 }
 
-func (s *Service) prepareData(txs []txn.Transaction) (data validation.Result, id types.Digest, err error) {
+// prepareProof returns the option to set the proof of the election of the next
+// leader when the committee has the key of the verifiable random function of
+// the node, otherwise no option is returned.
+func (s *Service) prepareProof() ([]types.BlockOption, error) {
+	if s.vrfSigner == nil {
+		return nil, nil
+	}
+
+	roster, err := s.getCurrentAuthority()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read roster: %v", err)
+	}
+
+	_, index := roster.GetPublicKey(s.me)
+
+	pubkey, found := roster.GetVRFKey(index)
+	if !found || !pubkey.Equal(s.vrfSigner.GetPublicKey()) {
+		return nil, nil
+	}
+
+	latestID, err := s.getLatestID()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read latest id: %v", err)
+	}
+
+	proof, err := pbft.ProveLeader(*s.vrfSigner, latestID)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to prove: %v", err)
+	}
+
+	return []types.BlockOption{types.WithProof(proof)}, nil
+}
+
+func (s *Service) getLatestID() (types.Digest, error) {
+	if s.blocks.Len() == 0 {
+		genesis, err := s.genesis.Get()
+		if err != nil {
+			return types.Digest{}, err
+		}
+
+		return genesis.GetHash(), nil
+	}
+
+	last, err := s.blocks.Last()
+	if err != nil {
+		return types.Digest{}, err
+	}
+
+	return last.GetTo(), nil
+}
+
+func (s *Service) prepareData(txs []txn.Transaction, index uint64) (data validation.Result, id types.Digest, err error) {
 	var stageTree hashtree.StagingTree
 
 	stageTree, err = s.tree.Get().Stage(func(snap store.Snapshot) error {
-		data, err = s.val.Validate(snap, txs)
+		data, err = s.val.Validate(snap, index, txs)
 		if err != nil {
 			return xerrors.Errorf("validation failed: %v", err)
 		}
@@ -749,7 +1092,7 @@ type poolFilter struct {
 }
 
 // Accept implements pool.Filter. It returns an error if the transaction exists
-// already or the nonce is invalid.
+// already, the nonce is invalid, or it costs more than the budget of a block.
 func (f poolFilter) Accept(tx txn.Transaction, leeway validation.Leeway) error {
 	store := f.tree.Get()
 
@@ -758,5 +1101,16 @@ func (f poolFilter) Accept(tx txn.Transaction, leeway validation.Leeway) error {
 		return xerrors.Errorf("unacceptable transaction: %v", err)
 	}
 
+	limit, err := ReadBudget(store)
+	if err != nil {
+		return xerrors.Errorf("read budget failed: %v", err)
+	}
+
+	// A transaction that doesn't fit in a block would never be ordered.
+	cost := budget.Cost(tx)
+	if limit > 0 && cost > limit {
+		return xerrors.Errorf("transaction exceeds the budget: %d > %d", cost, limit)
+	}
+
 	return nil
 }
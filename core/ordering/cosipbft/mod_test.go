@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 
@@ -17,7 +18,10 @@ import (
 	"go.dedis.ch/dela/core/ordering"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/budget"
 	"go.dedis.ch/dela/core/ordering/cosipbft/contracts/viewchange"
+	"go.dedis.ch/dela/core/ordering/cosipbft/epoch"
+	"go.dedis.ch/dela/core/ordering/cosipbft/lightproof"
 	"go.dedis.ch/dela/core/ordering/cosipbft/pbft"
 	"go.dedis.ch/dela/core/ordering/cosipbft/types"
 	"go.dedis.ch/dela/core/store"
@@ -35,6 +39,7 @@ import (
 	"go.dedis.ch/dela/cosi/threshold"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/gossip"
@@ -93,6 +98,271 @@ func TestService_Scenario_Basic(t *testing.T) {
 	require.NotNil(t, proof.GetValue())
 
 	checkProof(t, proof.(Proof), nodes[0].service)
+
+	// A client verifies the light proof with the initial roster only.
+	lproof, err := nodes[0].service.GetLightProof(keyRoster[:])
+	require.NoError(t, err)
+
+	err = lightproof.VerifyProof(lproof, authority.FromAuthority(initial), nodes[0].service.verifierFac)
+	require.NoError(t, err)
+}
+
+func TestService_Scenario_LeaderElection(t *testing.T) {
+	nodes, ro, clean := makeElectedAuthority(t, 4)
+	defer clean()
+
+	signer := nodes[0].signer
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := nodes[0].service.Setup(ctx, ro)
+	require.NoError(t, err)
+
+	events := nodes[1].service.Watch(ctx)
+
+	for i := 0; i < 5; i++ {
+		err = nodes[i%4].pool.Add(makeTx(t, uint64(i), signer))
+		require.NoError(t, err)
+
+		evt := waitEvent(t, events)
+		require.Equal(t, uint64(i), evt.Index)
+	}
+
+	prev, err := nodes[1].service.genesis.Get()
+	require.NoError(t, err)
+
+	prevID := prev.GetHash()
+
+	// Every block holds the proof of its proposer, which elects the leader of
+	// the next round.
+	for i := uint64(0); i < 5; i++ {
+		link, err := nodes[1].service.blocks.GetByIndex(i)
+		require.NoError(t, err)
+
+		proof, found := link.GetBlock().GetProof()
+		require.True(t, found)
+
+		verified := false
+		for k := 0; k < ro.Len(); k++ {
+			vrfkey, _ := ro.GetVRFKey(k)
+
+			_, err = pbft.VerifyLeader(vrfkey, prevID, proof, ro.Len())
+			verified = verified || err == nil
+		}
+
+		require.True(t, verified)
+
+		prevID = link.GetTo()
+	}
+}
+
+func TestService_Scenario_Weighted(t *testing.T) {
+	nodes, ro, clean := makeAuthority(t, 4)
+	defer clean()
+
+	addrs := make([]mino.Address, 0, ro.Len())
+	for iter := ro.AddressIterator(); iter.HasNext(); {
+		addrs = append(addrs, iter.GetNext())
+	}
+
+	pubkeys := make([]crypto.PublicKey, 0, ro.Len())
+	for iter := ro.PublicKeyIterator(); iter.HasNext(); {
+		pubkeys = append(pubkeys, iter.GetNext())
+	}
+
+	// The first participant holds more than half of the voting rights so that
+	// the signatures only need one of the others.
+	weighted := authority.NewWeighted(addrs, pubkeys, []uint64{5, 1, 1, 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := nodes[0].service.Setup(ctx, weighted)
+	require.NoError(t, err)
+
+	events := nodes[1].service.Watch(ctx)
+
+	for i := 0; i < 2; i++ {
+		err = nodes[0].pool.Add(makeTx(t, uint64(i), nodes[0].signer))
+		require.NoError(t, err)
+
+		evt := waitEvent(t, events)
+		require.Equal(t, uint64(i), evt.Index)
+	}
+
+	proof, err := nodes[1].service.GetProof(keyRoster[:])
+	require.NoError(t, err)
+
+	checkProof(t, proof.(Proof), nodes[1].service)
+
+	link, err := nodes[1].service.blocks.GetByIndex(1)
+	require.NoError(t, err)
+
+	verifier, err := nodes[1].service.verifierFac.FromAuthority(weighted)
+	require.NoError(t, err)
+	require.NoError(t, verifier.Verify(link.GetHash().Bytes(), link.GetPrepareSignature()))
+}
+
+func TestService_Scenario_Epochs(t *testing.T) {
+	nodes, ro, clean := makeAuthority(t, 6)
+	defer clean()
+
+	signer := nodes[0].signer
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := nodes[0].service.Setup(ctx, ro)
+	require.NoError(t, err)
+
+	events := nodes[0].service.Watch(ctx)
+
+	err = nodes[0].pool.Add(makeEpochTx(t, 0, epoch.Config{Length: 2, Size: 4}, signer))
+	require.NoError(t, err)
+
+	evt := waitEvent(t, events)
+	require.Equal(t, uint64(0), evt.Index)
+
+	err = nodes[0].pool.Add(makeTx(t, 1, signer))
+	require.NoError(t, err)
+
+	evt = waitEvent(t, events)
+	require.Equal(t, uint64(1), evt.Index)
+
+	// The block 1 ends the first epoch and samples the committee of the next
+	// one from the roster and the block 0.
+	committee, err := nodes[0].service.GetCommittee()
+	require.NoError(t, err)
+	require.Equal(t, 4, committee.Len())
+
+	link, err := nodes[0].service.blocks.GetByIndex(0)
+	require.NoError(t, err)
+
+	seed, err := epoch.Seed(link)
+	require.NoError(t, err)
+	require.NoError(t, epoch.Verify(ro, seed, 4, committee))
+
+	link, err = nodes[0].service.blocks.GetByIndex(1)
+	require.NoError(t, err)
+	require.Equal(t, 2, link.GetChangeSet().NumChanges())
+
+	roster, err := nodes[0].service.GetRoster()
+	require.NoError(t, err)
+	require.Equal(t, 6, roster.Len())
+
+	// The next blocks are followed on a member of the committee.
+	var member *Service
+	for _, node := range nodes {
+		_, index := committee.GetPublicKey(node.service.me)
+		if index >= 0 {
+			member = node.service
+		}
+	}
+
+	events = member.Watch(ctx)
+
+	for i := 2; i < 4; i++ {
+		err = nodes[0].pool.Add(makeTx(t, uint64(i), signer))
+		require.NoError(t, err)
+
+		// The member may notify the previous block after the watch started.
+		evt = waitEvent(t, events)
+		for evt.Index < uint64(i) {
+			evt = waitEvent(t, events)
+		}
+
+		require.Equal(t, uint64(i), evt.Index)
+	}
+
+	link, err = member.blocks.GetByIndex(2)
+	require.NoError(t, err)
+
+	verifier, err := member.verifierFac.FromAuthority(committee)
+	require.NoError(t, err)
+	require.NoError(t, verifier.Verify(link.GetHash().Bytes(), link.GetPrepareSignature()))
+
+	ca, err := member.GetRosterAt(2)
+	require.NoError(t, err)
+	require.Equal(t, 4, ca.Len())
+
+	// A light client follows the committees with the forward links.
+	proof, err := member.GetProof(keyRoster[:])
+	require.NoError(t, err)
+
+	checkProof(t, proof.(Proof), member)
+}
+
+func TestService_Scenario_Fingerprint(t *testing.T) {
+	nodes, ro, clean := makeAuthority(t, 3)
+	defer clean()
+
+	nodes[0].service.fingerprint = authority.FingerprintV2
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := nodes[0].service.Setup(ctx, ro)
+	require.NoError(t, err)
+
+	events := nodes[2].service.Watch(ctx)
+
+	err = nodes[0].pool.Add(makeTx(t, 0, nodes[0].signer))
+	require.NoError(t, err)
+
+	evt := waitEvent(t, events)
+	require.Equal(t, uint64(0), evt.Index)
+
+	// Every participant adopts the version of the genesis block of the
+	// leader, which is stored in the state.
+	for _, node := range nodes {
+		genesis, err := node.service.genesis.Get()
+		require.NoError(t, err)
+		require.Equal(t, authority.FingerprintV2, genesis.GetFingerprintVersion())
+
+		version, err := ReadFingerprint(node.service.tree.Get())
+		require.NoError(t, err)
+		require.Equal(t, authority.FingerprintV2, version)
+	}
+
+	proof, err := nodes[2].service.GetProof(keyRoster[:])
+	require.NoError(t, err)
+
+	checkProof(t, proof.(Proof), nodes[2].service)
+
+	// A light client that only knows the first version still verifies the
+	// chain during the transition.
+	genesis, err := nodes[2].service.genesis.Get()
+	require.NoError(t, err)
+
+	legacy, err := types.NewGenesis(genesis.GetRoster(), types.WithGenesisRoot(genesis.GetRoot()))
+	require.NoError(t, err)
+	require.NotEqual(t, genesis.GetHash(), legacy.GetHash())
+
+	err = proof.(Proof).Verify(legacy, nodes[2].service.verifierFac)
+	require.NoError(t, err)
+}
+
+func TestReadFingerprint(t *testing.T) {
+	version, err := ReadFingerprint(fake.NewSnapshot())
+	require.NoError(t, err)
+	require.Equal(t, authority.FingerprintV1, version)
+
+	values := map[string][]byte{
+		string(keyFingerprint[:]): encodeFingerprint(authority.FingerprintV2),
+	}
+
+	version, err = ReadFingerprint(fake.NewSnapshot(fake.WithValues(values)))
+	require.NoError(t, err)
+	require.Equal(t, authority.FingerprintV2, version)
+
+	values[string(keyFingerprint[:])] = []byte{2}
+
+	_, err = ReadFingerprint(fake.NewSnapshot(fake.WithValues(values)))
+	require.EqualError(t, err, "invalid version '02'")
+
+	_, err = ReadFingerprint(fake.NewSnapshot(fake.WithKeyError(keyFingerprint[:], fake.GetError())))
+	require.EqualError(t, err, fake.Err("failed to read version"))
 }
 
 func TestService_Scenario_ViewChange(t *testing.T) {
@@ -179,18 +449,26 @@ func TestService_New(t *testing.T) {
 		Pool:       badPool{},
 	}
 
+	ro := authority.FromAuthority(fake.NewAuthority(1, fake.NewSigner))
+
+	gen, err := types.NewGenesis(ro)
+	require.NoError(t, err)
+
 	genesis := blockstore.NewGenesisStore()
-	genesis.Set(types.Genesis{})
+	genesis.Set(gen)
 
 	opts := []ServiceOption{
 		WithHashFactory(fake.NewHashFactory(&fake.Hash{})),
 		WithGenesisStore(genesis),
 		WithBlockStore(blockstore.NewInMemory()),
+		WithVRFSigner(vrf.NewSigner()),
 	}
 
 	srvc, err := NewService(param, opts...)
 	require.NoError(t, err)
 	require.NotNil(t, srvc)
+	// The snapshot of the genesis is backfilled.
+	require.Equal(t, 1, srvc.authorities.Len())
 
 	<-srvc.closed
 
@@ -245,6 +523,22 @@ func TestService_AlreadySet_Setup(t *testing.T) {
 		"creating genesis: set genesis failed: genesis block is already set")
 }
 
+func TestService_FailStoreAuthority_Setup(t *testing.T) {
+	srvc := &Service{
+		processor: newProcessor(),
+	}
+
+	srvc.tree = blockstore.NewTreeCache(fakeTree{})
+	srvc.access = fakeAccess{}
+	srvc.genesis = blockstore.NewGenesisStore()
+	srvc.authorities = badAuthorityStore{}
+
+	err := srvc.Setup(context.Background(), fake.NewAuthority(3, fake.NewSigner))
+	require.EqualError(t, err,
+		fake.Err("creating genesis: store authority failed"))
+	require.False(t, srvc.genesis.Exists())
+}
+
 func TestService_FailReadGenesis_Setup(t *testing.T) {
 	srvc := &Service{
 		processor: newProcessor(),
@@ -343,6 +637,35 @@ func TestService_Main(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestService_GetRosterAt(t *testing.T) {
+	srvc := &Service{processor: newProcessor()}
+
+	_, err := srvc.GetRosterAt(0)
+	require.EqualError(t, err, "authority store: authority at index 0 not found")
+
+	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+	require.NoError(t, srvc.authorities.Store(0, ro))
+
+	roster, err := srvc.GetRosterAt(2)
+	require.NoError(t, err)
+	require.Equal(t, 3, roster.Len())
+}
+
+func TestService_GetRosterDiff(t *testing.T) {
+	srvc := &Service{processor: newProcessor()}
+
+	_, err := srvc.GetRosterDiff(0, 1)
+	require.EqualError(t, err, "authority store: from: authority at index 0 not found")
+
+	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+	require.NoError(t, srvc.authorities.Store(0, ro))
+	require.NoError(t, srvc.authorities.Store(1, ro.Take(mino.RangeFilter(0, 2)).(authority.Authority)))
+
+	cs, err := srvc.GetRosterDiff(0, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, cs.NumChanges())
+}
+
 func TestService_DoRound(t *testing.T) {
 	rpc := fake.NewRPC()
 	ch := make(chan pbft.State)
@@ -387,6 +710,48 @@ func TestService_DoRound(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestService_UnresponsiveLeader_DoRound(t *testing.T) {
+	srvc := &Service{
+		processor:                newProcessor(),
+		me:                       fake.NewAddress(1),
+		timeoutRound:             RoundTimeout,
+		timeoutRoundAfterFailure: RoundTimeout,
+		timeoutUnresponsive:      time.Millisecond,
+		closing:                  make(chan struct{}),
+	}
+	srvc.pool = mem.NewPool()
+	srvc.tree = blockstore.NewTreeCache(fakeTree{})
+	srvc.rosterFac = authority.NewFactory(fake.AddressFactory{}, fake.PublicKeyFactory{})
+	srvc.pbftsm = fakeSM{}
+
+	srvc.peers = fakePeerStatus{
+		fake.NewAddress(0).String(): {Missed: mino.MaxMissedHeartbeats},
+	}
+
+	// The round aborts at the short timeout as the pool is empty.
+	done := make(chan error, 1)
+	go func() {
+		done <- srvc.doRound(context.Background())
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(RoundTimeout / 2):
+		t.Fatal("round should have used the short timeout")
+	}
+
+	// A responsive leader is waited for the regular timeout.
+	srvc.peers = fakePeerStatus{
+		fake.NewAddress(0).String(): {},
+	}
+	require.True(t, srvc.isResponsive(fake.NewAddress(0)))
+	require.True(t, srvc.isResponsive(fake.NewAddress(5)))
+
+	srvc.peers = nil
+	require.True(t, srvc.isResponsive(fake.NewAddress(0)))
+}
+
 func TestService_ViewchangeFailed_DoRound(t *testing.T) {
 	pbftsm := fakeSM{
 		state: pbft.ViewChangeState,
@@ -408,7 +773,7 @@ func TestService_ViewchangeFailed_DoRound(t *testing.T) {
 
 	srvc.blocks = blockstore.NewInMemory()
 	srvc.pool = mem.NewPool()
-	srvc.tree = blockstore.NewTreeCache(fakeTree{})
+	srvc.tree = blockstore.NewTreeCache(fakeTree{committee: makeCommittee(t, 3)})
 	srvc.rosterFac = authority.NewFactory(fake.AddressFactory{}, fake.PublicKeyFactory{})
 	srvc.pbftsm = pbftsm
 
@@ -435,7 +800,7 @@ func TestService_FailPBFTExpire_DoRound(t *testing.T) {
 
 	srvc.blocks = blockstore.NewInMemory()
 	srvc.pool = mem.NewPool()
-	srvc.tree = blockstore.NewTreeCache(fakeTree{})
+	srvc.tree = blockstore.NewTreeCache(fakeTree{committee: makeCommittee(t, 3)})
 	srvc.rosterFac = authority.NewFactory(fake.AddressFactory{}, fake.PublicKeyFactory{})
 	srvc.pbftsm = fakeSM{
 		err:   fake.GetError(),
@@ -462,7 +827,7 @@ func TestService_FailSendViews_DoRound(t *testing.T) {
 
 	srvc.blocks = blockstore.NewInMemory()
 	srvc.pool = mem.NewPool()
-	srvc.tree = blockstore.NewTreeCache(fakeTree{})
+	srvc.tree = blockstore.NewTreeCache(fakeTree{committee: makeCommittee(t, 3)})
 	srvc.rosterFac = authority.NewFactory(fake.AddressFactory{}, fake.PublicKeyFactory{})
 	srvc.pbftsm = fakeSM{}
 
@@ -475,6 +840,41 @@ func TestService_FailSendViews_DoRound(t *testing.T) {
 	require.EqualError(t, err, fake.Err("rpc failed to send views"))
 }
 
+func TestService_NotInCommittee_DoRound(t *testing.T) {
+	srvc := &Service{
+		processor:                newProcessor(),
+		me:                       fake.NewAddress(5),
+		rpc:                      fake.NewBadRPC(),
+		timeoutRound:             time.Millisecond,
+		timeoutRoundAfterFailure: time.Millisecond,
+	}
+
+	srvc.blocks = blockstore.NewInMemory()
+	srvc.pool = mem.NewPool()
+	srvc.tree = blockstore.NewTreeCache(fakeTree{committee: makeCommittee(t, 3)})
+	srvc.rosterFac = authority.NewFactory(fake.AddressFactory{}, fake.PublicKeyFactory{})
+	srvc.pbftsm = fakeSM{err: fake.GetError()}
+
+	srvc.pool.Add(makeTx(t, 0, fake.NewSigner()))
+
+	// The node is not allowed to expire the round of the committee.
+	err := srvc.doRound(context.Background())
+	require.NoError(t, err)
+}
+
+func TestService_FailReadCommittee_DoRound(t *testing.T) {
+	srvc := &Service{
+		processor: newProcessor(),
+		me:        fake.NewAddress(1),
+	}
+
+	srvc.tree = blockstore.NewTreeCache(fakeTree{committee: []byte("[]")})
+	srvc.rosterFac = badRosterFac{counter: fake.NewCounter(1)}
+
+	err := srvc.doRound(context.Background())
+	require.EqualError(t, err, fake.Err("reading committee: decode failed"))
+}
+
 func TestService_FailReadRoster_DoRound(t *testing.T) {
 	srvc := &Service{
 		processor:                newProcessor(),
@@ -614,6 +1014,7 @@ func TestService_FailValidation_DoPBFT(t *testing.T) {
 	srvc.tree = blockstore.NewTreeCache(fakeTree{})
 	srvc.pbftsm = fakeSM{}
 	srvc.pool = mem.NewPool()
+	srvc.blocks = blockstore.NewInMemory()
 
 	srvc.pool.Add(makeTx(t, 0, fake.NewSigner()))
 
@@ -681,6 +1082,32 @@ func TestService_FailReadRoster_DoPBFT(t *testing.T) {
 	require.EqualError(t, err, fake.Err("read roster failed: read from tree"))
 }
 
+func TestService_FailReadBudget_DoPBFT(t *testing.T) {
+	srvc := &Service{processor: newProcessor()}
+	srvc.tree = blockstore.NewTreeCache(fakeTree{errBudget: fake.GetError()})
+	srvc.pbftsm = fakeSM{}
+	srvc.pool = mem.NewPool()
+
+	srvc.pool.Add(makeTx(t, 0, fake.NewSigner()))
+
+	err := srvc.doPBFT(context.Background())
+	require.EqualError(t, err, fake.Err("read budget failed: failed to read budget"))
+}
+
+func TestService_OverBudget_DoPBFT(t *testing.T) {
+	srvc := &Service{processor: newProcessor()}
+	srvc.tree = blockstore.NewTreeCache(fakeTree{budget: []byte{1, 0, 0, 0, 0, 0, 0, 0}})
+	srvc.pbftsm = fakeSM{}
+	srvc.pool = mem.NewPool()
+
+	srvc.pool.Add(makeTx(t, 0, fake.NewSigner()))
+
+	// The transaction is left in the pool as it doesn't fit in a block.
+	err := srvc.doPBFT(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, srvc.pool.Len())
+}
+
 func TestService_FailPrepareSig_DoPBFT(t *testing.T) {
 	srvc := &Service{processor: newProcessor()}
 	srvc.val = fakeValidation{}
@@ -819,6 +1246,52 @@ func TestService_GetProof(t *testing.T) {
 	require.EqualError(t, err, "reading chain: store is empty")
 }
 
+func TestService_GetLightProof(t *testing.T) {
+	path, err := binprefix.NewPath(crypto.NewSha256Factory(), nil, []byte("A"), nil, nil)
+	require.NoError(t, err)
+
+	srvc := &Service{processor: newProcessor()}
+	srvc.tree = blockstore.NewTreeCache(fakeTree{path: path})
+	srvc.blocks = blockstore.NewInMemory()
+	srvc.blocks.Store(makeBlock(t, types.Digest{}))
+	srvc.genesis = blockstore.NewGenesisStore()
+	srvc.genesis.Set(types.Genesis{})
+
+	proof, err := srvc.GetLightProof([]byte("A"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("A"), proof.GetKey())
+
+	srvc.genesis = fakeGenesisStore{errGet: fake.GetError()}
+	_, err = srvc.GetLightProof([]byte("A"))
+	require.EqualError(t, err, fake.Err("reading genesis"))
+
+	srvc.tree.Set(fakeTree{})
+	_, err = srvc.GetLightProof([]byte("A"))
+	require.EqualError(t, err, "unsupported path '<nil>'")
+
+	srvc.tree.Set(fakeTree{err: fake.GetError()})
+	_, err = srvc.GetLightProof([]byte("A"))
+	require.EqualError(t, err, fake.Err("reading path"))
+}
+
+func TestService_GetEventsProof(t *testing.T) {
+	key := execution.EventsKey([]byte{1})
+
+	path, err := binprefix.NewPath(crypto.NewSha256Factory(), nil, key, nil, nil)
+	require.NoError(t, err)
+
+	srvc := &Service{processor: newProcessor()}
+	srvc.tree = blockstore.NewTreeCache(fakeTree{path: path})
+	srvc.blocks = blockstore.NewInMemory()
+	srvc.blocks.Store(makeBlock(t, types.Digest{}))
+	srvc.genesis = blockstore.NewGenesisStore()
+	srvc.genesis.Set(types.Genesis{})
+
+	proof, err := srvc.GetEventsProof([]byte{1})
+	require.NoError(t, err)
+	require.Equal(t, key, proof.GetKey())
+}
+
 func TestService_GetStore(t *testing.T) {
 	srvc := &Service{processor: newProcessor()}
 	srvc.tree = blockstore.NewTreeCache(fakeTree{})
@@ -826,6 +1299,46 @@ func TestService_GetStore(t *testing.T) {
 	require.IsType(t, fakeTree{}, srvc.GetStore())
 }
 
+func TestService_WatchFrom(t *testing.T) {
+	srvc := &Service{processor: newProcessor()}
+	srvc.blocks = blockstore.NewInMemory()
+
+	prev := types.Digest{}
+	for i := uint64(0); i < 3; i++ {
+		block, err := types.NewBlock(simple.NewResult(nil), types.WithIndex(i))
+		require.NoError(t, err)
+
+		link, err := types.NewBlockLink(prev, block)
+		require.NoError(t, err)
+		require.NoError(t, srvc.blocks.Store(link))
+
+		prev = link.GetTo()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := srvc.WatchFrom(ctx, 1)
+	require.Equal(t, uint64(1), waitEvent(t, events).Index)
+	require.Equal(t, uint64(2), waitEvent(t, events).Index)
+
+	srvc.watcher.Notify(ordering.Event{Index: 3})
+	require.Equal(t, uint64(3), waitEvent(t, events).Index)
+
+	// The replay starts at the oldest block that is not pruned.
+	srvc.blocks = prunedStore{BlockStore: srvc.blocks, pruned: 2}
+
+	events = srvc.WatchFrom(ctx, 0)
+	require.Equal(t, uint64(2), waitEvent(t, events).Index)
+
+	srvc.blocks = badBlockStore{}
+
+	events = srvc.WatchFrom(ctx, 0)
+
+	srvc.watcher.Notify(ordering.Event{Index: 3})
+	require.Equal(t, uint64(3), waitEvent(t, events).Index)
+}
+
 func TestService_GetRoster(t *testing.T) {
 	srvc := &Service{processor: newProcessor()}
 	srvc.tree = blockstore.NewTreeCache(fakeTree{})
@@ -848,11 +1361,31 @@ func TestService_PoolFilter(t *testing.T) {
 	filter.srvc = fakeValidation{err: fake.GetError()}
 	err = filter.Accept(makeTx(t, 0, fake.NewSigner()), validation.Leeway{})
 	require.EqualError(t, err, fake.Err("unacceptable transaction"))
+
+	filter.srvc = fakeValidation{}
+	filter.tree = blockstore.NewTreeCache(fakeTree{errBudget: fake.GetError()})
+	err = filter.Accept(makeTx(t, 0, fake.NewSigner()), validation.Leeway{})
+	require.EqualError(t, err, fake.Err("read budget failed: failed to read budget"))
+
+	tx := makeTx(t, 0, fake.NewSigner())
+	filter.tree = blockstore.NewTreeCache(fakeTree{budget: []byte{1, 0, 0, 0, 0, 0, 0, 0}})
+	err = filter.Accept(tx, validation.Leeway{})
+	require.EqualError(t, err,
+		fmt.Sprintf("transaction exceeds the budget: %d > 1", budget.Cost(tx)))
 }
 
 // -----------------------------------------------------------------------------
 // Utility functions
 
+func makeCommittee(t *testing.T, n int) []byte {
+	ca := authority.FromAuthority(fake.NewAuthority(n, fake.NewSigner))
+
+	data, err := ca.Serialize(json.NewContext())
+	require.NoError(t, err)
+
+	return data
+}
+
 func checkProof(t *testing.T, p Proof, s *Service) {
 	genesis, err := s.genesis.Get()
 	require.NoError(t, err)
@@ -910,6 +1443,21 @@ func makeRosterTx(t *testing.T, nonce uint64, roster authority.Authority, signer
 	return tx
 }
 
+func makeEpochTx(t *testing.T, nonce uint64, cfg epoch.Config, signer crypto.Signer) txn.Transaction {
+	tx, err := signed.NewTransaction(
+		nonce,
+		signer.GetPublicKey(),
+		signed.WithArg(native.ContractArg, []byte(viewchange.ContractName)),
+		signed.WithArg(viewchange.EpochLengthArg, []byte(strconv.FormatUint(cfg.Length, 10))),
+		signed.WithArg(viewchange.EpochSizeArg, []byte(strconv.FormatUint(uint64(cfg.Size), 10))),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, tx.Sign(signer))
+
+	return tx
+}
+
 func waitEvent(t *testing.T, events <-chan ordering.Event) ordering.Event {
 	select {
 	case <-time.After(15 * time.Second):
@@ -921,10 +1469,21 @@ func waitEvent(t *testing.T, events <-chan ordering.Event) ordering.Event {
 }
 
 func makeAuthority(t *testing.T, n int) ([]testNode, authority.Authority, func()) {
+	return makeNodes(t, n, false)
+}
+
+// makeElectedAuthority returns nodes that have a key of the verifiable random
+// function in the roster so that the leader is elected after each block.
+func makeElectedAuthority(t *testing.T, n int) ([]testNode, authority.Authority, func()) {
+	return makeNodes(t, n, true)
+}
+
+func makeNodes(t *testing.T, n int, elected bool) ([]testNode, authority.Authority, func()) {
 	manager := minoch.NewManager()
 
 	addrs := make([]mino.Address, n)
 	pubkeys := make([]crypto.PublicKey, n)
+	vrfkeys := make([]vrf.PublicKey, n)
 	nodes := make([]testNode, n)
 
 	for i := 0; i < n; i++ {
@@ -971,7 +1530,15 @@ func makeAuthority(t *testing.T, n int) ([]testNode, authority.Authority, func()
 			DB:         db,
 		}
 
-		srv, err := NewService(param)
+		opts := []ServiceOption{}
+		if elected {
+			vrfSigner := vrf.NewSigner()
+			vrfkeys[i] = vrfSigner.GetPublicKey()
+
+			opts = append(opts, WithVRFSigner(vrfSigner))
+		}
+
+		srv, err := NewService(param, opts...)
 		require.NoError(t, err)
 
 		nodes[i] = testNode{
@@ -984,7 +1551,7 @@ func makeAuthority(t *testing.T, n int) ([]testNode, authority.Authority, func()
 		}
 	}
 
-	ro := authority.New(addrs, pubkeys)
+	ro := authority.New(addrs, pubkeys).WithVRFKeys(vrfkeys)
 
 	clean := func() {
 		for _, node := range nodes {
@@ -999,9 +1566,46 @@ func makeAuthority(t *testing.T, n int) ([]testNode, authority.Authority, func()
 
 type badRosterFac struct {
 	authority.Factory
+
+	counter *fake.Counter
 }
 
 func (fac badRosterFac) AuthorityOf(serde.Context, []byte) (authority.Authority, error) {
+	if !fac.counter.Done() {
+		fac.counter.Decrease()
+		return authority.New(nil, nil), nil
+	}
+
+	return nil, fake.GetError()
+}
+
+type prunedStore struct {
+	blockstore.BlockStore
+
+	pruned uint64
+}
+
+func (s prunedStore) GetPruned() uint64 {
+	return s.pruned
+}
+
+func (s prunedStore) Prune(uint64) (uint64, error) {
+	return 0, nil
+}
+
+func (s prunedStore) GetLinkByIndex(uint64) (types.Link, error) {
+	return nil, nil
+}
+
+type badBlockStore struct {
+	blockstore.BlockStore
+}
+
+func (badBlockStore) Len() uint64 {
+	return 3
+}
+
+func (badBlockStore) GetByIndex(uint64) (types.BlockLink, error) {
 	return nil, fake.GetError()
 }
 
@@ -1049,7 +1653,7 @@ func (val fakeValidation) Accept(store.Readable, txn.Transaction, validation.Lee
 	return val.err
 }
 
-func (val fakeValidation) Validate(store.Snapshot, []txn.Transaction) (validation.Result, error) {
+func (val fakeValidation) Validate(store.Snapshot, uint64, []txn.Transaction) (validation.Result, error) {
 	return simple.NewResult(nil), val.err
 }
 
@@ -1079,12 +1683,30 @@ func (fakeRosterFac) AuthorityOf(serde.Context, []byte) (authority.Authority, er
 	return authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner)), nil
 }
 
+type badAuthorityStore struct {
+	blockstore.AuthorityStore
+}
+
+func (badAuthorityStore) Store(uint64, authority.Authority) error {
+	return fake.GetError()
+}
+
 type fakeAccess struct {
 	access.Service
 
-	err error
+	calls *fake.Call
+	err   error
 }
 
-func (srvc fakeAccess) Grant(store.Snapshot, access.Credential, ...access.Identity) error {
+func (srvc fakeAccess) Grant(_ store.Snapshot, _ access.Credential, idents ...access.Identity) error {
+	srvc.calls.Add(idents)
+
 	return srvc.err
 }
+
+type fakePeerStatus map[string]mino.Liveness
+
+func (s fakePeerStatus) GetPeerStatus(addr mino.Address) (mino.Liveness, bool) {
+	l, found := s[addr.String()]
+	return l, found
+}
@@ -0,0 +1,67 @@
+package pbft
+
+import (
+	"encoding/binary"
+
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/crypto/vrf"
+	"golang.org/x/xerrors"
+)
+
+// The leader of a round can be elected with a verifiable random function. The
+// participants of the roster that have a key of the function must include in
+// the blocks they propose the proof of the evaluation of the function on the
+// identifier of the previous block, using their own private key. The output of
+// the proof elects the leader of the round that follows the block, and the
+// view changes then move to the next participant in the roster from the
+// elected leader.
+//
+// The election is unpredictable until the leader publishes its proof, and it
+// cannot be influenced by the leader as the proof is deterministic for a given
+// key and block identifier.
+
+// ProveLeader returns the proof of the election that follows the block
+// identifier with the private key of the signer.
+func ProveLeader(signer vrf.Signer, id types.Digest) (vrf.Proof, error) {
+	proof, err := signer.Prove(id[:])
+	if err != nil {
+		return proof, xerrors.Errorf("couldn't prove: %v", err)
+	}
+
+	return proof, nil
+}
+
+// VerifyLeader verifies the proof of an election for the block identifier and
+// returns the index of the elected leader for a roster of n participants.
+func VerifyLeader(pubkey vrf.PublicKey, id types.Digest, proof vrf.Proof, n int) (uint16, error) {
+	if n <= 0 {
+		return 0, xerrors.Errorf("invalid roster length %d", n)
+	}
+
+	output, err := pubkey.Verify(id[:], proof)
+	if err != nil {
+		return 0, xerrors.Errorf("invalid proof: %v", err)
+	}
+
+	return electLeader(output, n), nil
+}
+
+// ElectLeader returns the index of the leader elected by the proof for a
+// roster of n participants. The proof must be verified beforehand, which is
+// the case for the proof of a block that has been collectively signed.
+func ElectLeader(proof vrf.Proof, n int) (uint16, error) {
+	if n <= 0 {
+		return 0, xerrors.Errorf("invalid roster length %d", n)
+	}
+
+	output, err := proof.Hash()
+	if err != nil {
+		return 0, xerrors.Errorf("invalid proof: %v", err)
+	}
+
+	return electLeader(output, n), nil
+}
+
+func electLeader(output []byte, n int) uint16 {
+	return uint16(binary.BigEndian.Uint64(output) % uint64(n))
+}
@@ -0,0 +1,74 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/crypto/vrf"
+)
+
+func TestProveLeader(t *testing.T) {
+	signer := vrf.NewSigner()
+
+	leaders := make(map[uint16]struct{})
+
+	for i := byte(0); i < 50; i++ {
+		proof, err := ProveLeader(signer, types.Digest{i})
+		require.NoError(t, err)
+
+		leader, err := VerifyLeader(signer.GetPublicKey(), types.Digest{i}, proof, 5)
+		require.NoError(t, err)
+		require.Less(t, int(leader), 5)
+
+		leaders[leader] = struct{}{}
+
+		same, err := ProveLeader(signer, types.Digest{i})
+		require.NoError(t, err)
+		require.True(t, proof.Equal(same))
+	}
+
+	// The leaders must not always be the same over different blocks.
+	require.Greater(t, len(leaders), 1)
+}
+
+func TestVerifyLeader(t *testing.T) {
+	signer := vrf.NewSigner()
+
+	proof, err := ProveLeader(signer, types.Digest{1})
+	require.NoError(t, err)
+
+	leader, err := VerifyLeader(signer.GetPublicKey(), types.Digest{1}, proof, 3)
+	require.NoError(t, err)
+	require.Less(t, int(leader), 3)
+
+	_, err = VerifyLeader(signer.GetPublicKey(), types.Digest{1}, proof, 0)
+	require.EqualError(t, err, "invalid roster length 0")
+
+	_, err = VerifyLeader(signer.GetPublicKey(), types.Digest{2}, proof, 3)
+	require.EqualError(t, err, "invalid proof: mismatch challenge")
+
+	_, err = VerifyLeader(vrf.NewSigner().GetPublicKey(), types.Digest{1}, proof, 3)
+	require.EqualError(t, err, "invalid proof: mismatch challenge")
+}
+
+func TestElectLeader(t *testing.T) {
+	signer := vrf.NewSigner()
+
+	proof, err := ProveLeader(signer, types.Digest{1})
+	require.NoError(t, err)
+
+	expected, err := VerifyLeader(signer.GetPublicKey(), types.Digest{1}, proof, 7)
+	require.NoError(t, err)
+
+	leader, err := ElectLeader(proof, 7)
+	require.NoError(t, err)
+	require.Equal(t, expected, leader)
+
+	_, err = ElectLeader(proof, 0)
+	require.EqualError(t, err, "invalid roster length 0")
+
+	_, err = ElectLeader(vrf.Proof{}, 7)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid proof: ")
+}
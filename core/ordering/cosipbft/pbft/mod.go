@@ -26,6 +26,7 @@ import (
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/store/hashtree"
 	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/core/txn/signed"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/mino"
@@ -155,6 +156,9 @@ type pbftsm struct {
 	tree       blockstore.TreeCache
 	authReader AuthorityReader
 	db         kv.DB
+	// authorities stores the snapshot of the authority of each block, when it
+	// is defined.
+	authorities blockstore.AuthorityStore
 
 	// verifierFac creates a verifier for the aggregated signature.
 	verifierFac crypto.VerifierFactory
@@ -177,6 +181,9 @@ type StateMachineParam struct {
 	Tree            blockstore.TreeCache
 	AuthorityReader AuthorityReader
 	DB              kv.DB
+	// Authorities is optional and stores the snapshots of the authority in the
+	// same transaction as the blocks.
+	Authorities blockstore.AuthorityStore
 }
 
 // NewStateMachine returns a new state machine.
@@ -194,6 +201,7 @@ func NewStateMachine(param StateMachineParam) StateMachine {
 		db:          param.DB,
 		state:       NoneState,
 		authReader:  param.AuthorityReader,
+		authorities: param.Authorities,
 	}
 }
 
@@ -217,6 +225,13 @@ func (m *pbftsm) GetLeader() (mino.Address, error) {
 		return nil, xerrors.Errorf("failed to read roster: %v", err)
 	}
 
+	if m.state == NoneState {
+		err = m.electLeader(roster)
+		if err != nil {
+			return nil, xerrors.Errorf("leader election failed: %v", err)
+		}
+	}
+
 	iter := roster.AddressIterator()
 	iter.Seek(int(m.round.leader))
 
@@ -268,6 +283,13 @@ func (m *pbftsm) Prepare(from mino.Address, block types.Block) (types.Digest, er
 		return id, xerrors.Errorf("failed to read roster: %v", err)
 	}
 
+	if m.state == NoneState {
+		err = m.electLeader(roster)
+		if err != nil {
+			return id, xerrors.Errorf("leader election failed: %v", err)
+		}
+	}
+
 	_, index := roster.GetPublicKey(from)
 
 	if uint16(index) != m.round.leader {
@@ -282,7 +304,12 @@ func (m *pbftsm) Prepare(from mino.Address, block types.Block) (types.Digest, er
 		return id, nil
 	}
 
-	m.round.threshold = calculateThreshold(roster.Len())
+	err = m.verifyProof(block, roster)
+	if err != nil {
+		return id, xerrors.Errorf("leader election: %v", err)
+	}
+
+	m.round.threshold = calculateThreshold(roster.TotalWeight())
 
 	err = m.verifyPrepare(m.tree.Get(), block, &m.round, roster)
 	if err != nil {
@@ -346,6 +373,11 @@ func (m *pbftsm) Finalize(id types.Digest, sig crypto.Signature) error {
 		return err
 	}
 
+	err = m.electNextLeader()
+	if err != nil {
+		return xerrors.Errorf("leader election failed: %v", err)
+	}
+
 	m.round.prevViews = nil
 	m.round.views = nil
 	m.round.committed = false
@@ -361,7 +393,7 @@ func (m *pbftsm) Accept(view View) error {
 	m.Lock()
 	defer m.Unlock()
 
-	_, err := m.init()
+	roster, err := m.init()
 	if err != nil {
 		return xerrors.Errorf("init: %v", err)
 	}
@@ -387,26 +419,32 @@ func (m *pbftsm) Accept(view View) error {
 
 	m.round.views[view.from] = view
 
-	m.checkViewChange(view)
+	m.checkViewChange(roster, view)
 
 	return nil
 }
 
 // AcceptAll implements pbft.StateMachine. It accepts a list of views which
-// allows a node falling behind to catch up. The list must contain enough views
-// to reach the threshold, otherwise it will be ignored.
+// allows a node falling behind to catch up. The list must contain views with
+// enough voting rights to reach the threshold, otherwise it will be ignored.
 func (m *pbftsm) AcceptAll(views []View) error {
 	m.Lock()
 	defer m.Unlock()
 
-	_, err := m.init()
+	roster, err := m.init()
 	if err != nil {
 		return xerrors.Errorf("init: %v", err)
 	}
 
-	if len(views) <= m.round.threshold {
+	set := make(map[mino.Address]View)
+	for _, view := range views {
+		set[view.from] = view
+	}
+
+	weight := calculateWeight(roster, set)
+	if weight <= m.round.threshold {
 		return xerrors.Errorf("not enough views: %d <= %d",
-			len(views), m.round.threshold)
+			weight, m.round.threshold)
 	}
 
 	if views[0].leader == m.round.leader {
@@ -419,14 +457,9 @@ func (m *pbftsm) AcceptAll(views []View) error {
 		return xerrors.Errorf("invalid view: %v", err)
 	}
 
-	set := make(map[mino.Address]View)
-	for _, view := range views {
-		set[view.from] = view
-	}
-
 	m.round.views = set
 	m.state = ViewChangeState
-	m.checkViewChange(views[0])
+	m.checkViewChange(roster, views[0])
 
 	return nil
 }
@@ -510,7 +543,7 @@ func (m *pbftsm) Expire(addr mino.Address) (View, error) {
 
 	m.round.views[addr] = view
 
-	m.checkViewChange(view)
+	m.checkViewChange(roster, view)
 
 	return view, nil
 }
@@ -549,6 +582,11 @@ func (m *pbftsm) CatchUp(link types.BlockLink) error {
 		return xerrors.Errorf("finalize failed: %v", err)
 	}
 
+	err = m.electNextLeader()
+	if err != nil {
+		return xerrors.Errorf("leader election failed: %v", err)
+	}
+
 	m.round.views = nil
 	m.round.prevViews = nil
 	m.setState(InitialState)
@@ -574,8 +612,15 @@ func (m *pbftsm) Watch(ctx context.Context) <-chan State {
 }
 
 func (m *pbftsm) verifyPrepare(tree hashtree.Tree, block types.Block, r *round, ro authority.Authority) error {
+	// The signatures of the transactions are verified in a single batch
+	// rather than one by one when the block is deserialized.
+	err := signed.VerifyTransactions(block.GetTransactions())
+	if err != nil {
+		return xerrors.Errorf("invalid transactions: %v", err)
+	}
+
 	stageTree, err := tree.Stage(func(snap store.Snapshot) error {
-		res, err := m.val.Validate(snap, block.GetTransactions())
+		res, err := m.val.Validate(snap, block.GetIndex(), block.GetTransactions())
 		if err != nil {
 			return xerrors.Errorf("validation failed: %v", err)
 		}
@@ -673,6 +718,13 @@ func (m *pbftsm) verifyFinalize(r *round, sig crypto.Signature, ro authority.Aut
 		return xerrors.Errorf("couldn't get latest digest: %v", err)
 	}
 
+	// The authority effective from the next block is read in the tree of the
+	// block before it is committed.
+	next, err := m.authReader(r.tree)
+	if err != nil {
+		return xerrors.Errorf("failed to read next roster: %v", err)
+	}
+
 	// Persist to the database in a transaction so that it can revert to the
 	// previous state for either the tree or the block if something goes wrong.
 	err = m.db.Update(func(txn kv.WritableTx) error {
@@ -707,6 +759,14 @@ func (m *pbftsm) verifyFinalize(r *round, sig crypto.Signature, ro authority.Aut
 			return xerrors.Errorf("store block: %v", err)
 		}
 
+		// 3. Persist the authority effective from the next block.
+		if m.authorities != nil {
+			err = m.authorities.WithTx(txn).Store(r.block.GetIndex()+1, next)
+			if err != nil {
+				return xerrors.Errorf("store authority: %v", err)
+			}
+		}
+
 		// Only release the tree cache at the very end of the transaction, so
 		// that a call to get the tree will hold until the block is stored.
 		txn.OnCommit(func() {
@@ -733,20 +793,96 @@ func (m *pbftsm) init() (authority.Authority, error) {
 		return roster, nil
 	}
 
-	m.round.threshold = calculateThreshold(roster.Len())
+	m.round.threshold = calculateThreshold(roster.TotalWeight())
+
+	err = m.electLeader(roster)
+	if err != nil {
+		return nil, xerrors.Errorf("leader election failed: %v", err)
+	}
 
 	m.setState(InitialState)
 
 	return roster, nil
 }
 
+// verifyProof verifies that a block proposed by a leader that has a key of the
+// verifiable random function holds the proof of the election of the next
+// leader, and that the block of a leader without a key does not hold any.
+func (m *pbftsm) verifyProof(block types.Block, roster authority.Authority) error {
+	proof, found := block.GetProof()
+
+	pubkey, hasKey := roster.GetVRFKey(int(m.round.leader))
+	if !hasKey {
+		if found {
+			return xerrors.New("unexpected proof from a leader without a key")
+		}
+
+		return nil
+	}
+
+	if !found {
+		return xerrors.New("missing proof of the leader")
+	}
+
+	latestID, err := m.getLatestID()
+	if err != nil {
+		return xerrors.Errorf("failed to read latest id: %v", err)
+	}
+
+	_, err = VerifyLeader(pubkey, latestID, proof, roster.Len())
+	if err != nil {
+		return xerrors.Errorf("failed to verify: %v", err)
+	}
+
+	return nil
+}
+
+// electNextLeader elects the leader of the round following a new block, using
+// the roster of the latest tree.
+func (m *pbftsm) electNextLeader() error {
+	roster, err := m.authReader(m.tree.Get())
+	if err != nil {
+		return xerrors.Errorf("failed to read roster: %v", err)
+	}
+
+	return m.electLeader(roster)
+}
+
+// electLeader sets the leader of the round elected by the proof of the latest
+// block when it has one, otherwise it leaves the current leader.
+func (m *pbftsm) electLeader(roster authority.Authority) error {
+	if m.blocks.Len() == 0 {
+		return nil
+	}
+
+	link, err := m.blocks.Last()
+	if err != nil {
+		return xerrors.Errorf("failed to read latest block: %v", err)
+	}
+
+	proof, found := link.GetBlock().GetProof()
+	if !found {
+		return nil
+	}
+
+	// The proof has been verified by the participants that signed the block.
+	leader, err := ElectLeader(proof, roster.Len())
+	if err != nil {
+		return xerrors.Errorf("failed to elect: %v", err)
+	}
+
+	m.round.leader = leader
+
+	return nil
+}
+
 func (m *pbftsm) setState(s State) {
 	m.state = s
 	m.watcher.Notify(s)
 }
 
-func (m *pbftsm) checkViewChange(view View) {
-	if m.state == ViewChangeState && len(m.round.views) > m.round.threshold {
+func (m *pbftsm) checkViewChange(roster authority.Authority, view View) {
+	if m.state == ViewChangeState && calculateWeight(roster, m.round.views) > m.round.threshold {
 		m.round.prevViews = m.round.views
 		m.round.views = nil
 		m.round.leader = view.leader
@@ -785,10 +921,27 @@ func (obs observer) NotifyCallback(event interface{}) {
 	obs.ch <- event.(State)
 }
 
-// CalculateThreshold returns the number of messages that a node needs to
-// receive before confirming the view change. The threshold is 2*f where f can
-// be found with n = 3*f+1 where n is the number of participants.
-func calculateThreshold(n int) int {
+// CalculateThreshold returns the voting rights that a node needs to receive
+// before confirming the view change. The threshold is 2*f where f can be found
+// with n = 3*f+1 where n is the total weight of the participants, which is the
+// number of participants when the roster is not weighted.
+func calculateThreshold(n uint64) int {
+	if n == 0 {
+		return 0
+	}
+
 	f := (n - 1) / 3
-	return 2 * f
+	return int(2 * f)
+}
+
+// calculateWeight returns the sum of the voting rights of the participants that
+// sent the views. Views from unknown participants have no weight.
+func calculateWeight(roster authority.Authority, views map[mino.Address]View) int {
+	weight := uint64(0)
+	for from := range views {
+		_, index := roster.GetPublicKey(from)
+		weight += roster.GetWeight(index)
+	}
+
+	return int(weight)
 }
@@ -18,10 +18,13 @@ import (
 	"go.dedis.ch/dela/core/store/hashtree/binprefix"
 	"go.dedis.ch/dela/core/store/kv"
 	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/signed"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/core/validation/simple"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/ed25519"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/mino"
 )
@@ -49,7 +52,8 @@ func TestStateMachine_GetLeader(t *testing.T) {
 	roster := fake.NewAuthority(3, fake.NewSigner)
 
 	sm := &pbftsm{
-		tree: blockstore.NewTreeCache(badTree{}),
+		tree:   blockstore.NewTreeCache(badTree{}),
+		blocks: blockstore.NewInMemory(),
 		authReader: func(hashtree.Tree) (authority.Authority, error) {
 			return authority.FromAuthority(roster), nil
 		},
@@ -181,6 +185,36 @@ func TestStateMachine_FailedValidation_Prepare(t *testing.T) {
 	require.EqualError(t, err, fake.Err("while updating tree: callback failed: validation failed"))
 }
 
+func TestStateMachine_InvalidTransaction_Prepare(t *testing.T) {
+	tree, db, clean := makeTree(t)
+	defer clean()
+
+	sm := &pbftsm{
+		state:      InitialState,
+		val:        simple.NewService(fakeExec{}, nil),
+		tree:       blockstore.NewTreeCache(tree),
+		db:         db,
+		authReader: goodReader,
+	}
+
+	signer := ed25519.NewSigner()
+
+	// The signature is not verified when the transaction is created, as when
+	// the block is deserialized.
+	tx, err := signed.NewTransaction(0, signer.GetPublicKey(),
+		signed.WithUncheckedSignature(ed25519.NewSignature(make([]byte, 64))))
+	require.NoError(t, err)
+
+	res := simple.NewResult([]simple.TransactionResult{simple.NewTransactionResult(tx, true, "")})
+
+	block, err := types.NewBlock(res)
+	require.NoError(t, err)
+
+	_, err = sm.Prepare(fake.NewAddress(0), block)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid transactions: batch: signature 0: ")
+}
+
 func TestStateMachine_MismatchTreeRoot_Prepare(t *testing.T) {
 	tree, db, clean := makeTree(t)
 	defer clean()
@@ -407,7 +441,8 @@ func TestStateMachine_Finalize(t *testing.T) {
 		AuthorityReader: func(hashtree.Tree) (authority.Authority, error) {
 			return ro, nil
 		},
-		DB: db,
+		DB:          db,
+		Authorities: blockstore.NewAuthorityStore(),
 	}
 
 	param.Genesis.Set(types.Genesis{})
@@ -419,6 +454,11 @@ func TestStateMachine_Finalize(t *testing.T) {
 
 	err := sm.Finalize(types.Digest{1}, fake.Signature{})
 	require.NoError(t, err)
+
+	// The authority of the next block is stored with the block.
+	roster, err := param.Authorities.GetByIndex(1)
+	require.NoError(t, err)
+	require.Equal(t, 3, roster.Len())
 }
 
 func TestStateMachine_NotCommitted_Finalize(t *testing.T) {
@@ -577,6 +617,33 @@ func TestStateMachine_FailStoreBlock_Finalize(t *testing.T) {
 	require.EqualError(t, err, fake.Err("database failed: store block"))
 }
 
+func TestStateMachine_FailStoreAuthority_Finalize(t *testing.T) {
+	tree, db, clean := makeTree(t)
+	defer clean()
+
+	sm := &pbftsm{
+		state:       CommitState,
+		tree:        blockstore.NewTreeCache(tree),
+		authReader:  goodReader,
+		verifierFac: fake.NewVerifierFactory(fake.Verifier{}),
+		genesis:     blockstore.NewGenesisStore(),
+		blocks:      blockstore.NewInMemory(),
+		authorities: badAuthorityStore{},
+		db:          db,
+		hashFac:     crypto.NewSha256Factory(),
+		round: round{
+			prepareSig: fake.Signature{},
+			tree:       tree.(hashtree.StagingTree),
+		},
+	}
+
+	sm.genesis.Set(types.Genesis{})
+
+	err := sm.Finalize(types.Digest{1}, fake.Signature{})
+	require.EqualError(t, err, fake.Err("database failed: store authority"))
+	require.Equal(t, uint64(0), sm.blocks.Len())
+}
+
 func TestStateMachine_Accept(t *testing.T) {
 	ro := authority.FromAuthority(fake.NewAuthority(4, fake.NewSigner))
 
@@ -651,6 +718,52 @@ func TestStateMachine_Accept(t *testing.T) {
 	require.EqualError(t, err, fake.Err("invalid view: invalid signature: verify"))
 }
 
+func TestStateMachine_Weighted_Accept(t *testing.T) {
+	ro := authority.NewWeighted(
+		[]mino.Address{
+			fake.NewAddress(0), fake.NewAddress(1), fake.NewAddress(2), fake.NewAddress(3),
+		},
+		[]crypto.PublicKey{
+			fake.PublicKey{}, fake.PublicKey{}, fake.PublicKey{}, fake.PublicKey{},
+		},
+		[]uint64{5, 1, 1, 1},
+	)
+
+	sm := &pbftsm{
+		state:   ViewChangeState,
+		blocks:  blockstore.NewInMemory(),
+		genesis: blockstore.NewGenesisStore(),
+		watcher: core.NewWatcher(),
+		signer:  fake.NewSigner(),
+		tree:    blockstore.NewTreeCache(badTree{}),
+		authReader: func(hashtree.Tree) (authority.Authority, error) {
+			return ro, nil
+		},
+	}
+
+	sm.genesis.Set(types.Genesis{})
+	sm.round.threshold = calculateThreshold(ro.TotalWeight())
+	require.Equal(t, 4, sm.round.threshold)
+
+	// The three lightest participants do not have enough voting rights.
+	for i := 1; i < 4; i++ {
+		err := sm.Accept(View{from: fake.NewAddress(i), leader: 1})
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, ViewChangeState, sm.state)
+	require.Len(t, sm.round.views, 3)
+
+	err := sm.Accept(View{from: fake.NewAddress(0), leader: 1})
+	require.NoError(t, err)
+	require.Equal(t, InitialState, sm.state)
+	require.Equal(t, uint16(1), sm.round.leader)
+
+	sm.round.threshold = 5
+	err = sm.AcceptAll([]View{{from: fake.NewAddress(0), leader: 2}})
+	require.EqualError(t, err, "not enough views: 5 <= 5")
+}
+
 func TestStateMachine_verifyViews(t *testing.T) {
 	sm := &pbftsm{
 		tree:       blockstore.NewTreeCache(badTree{}),
@@ -690,14 +803,17 @@ func TestStateMachine_AcceptAll(t *testing.T) {
 	require.Len(t, sm.round.prevViews, 3)
 
 	sm.round.threshold = 0
-	err = sm.AcceptAll([]View{{leader: 5}})
+	err = sm.AcceptAll([]View{{from: fake.NewAddress(0), leader: 5}})
 	require.NoError(t, err)
 
 	// Only accept if there are enough views.
 	err = sm.AcceptAll([]View{})
 	require.EqualError(t, err, "not enough views: 0 <= 0")
 
-	err = sm.AcceptAll([]View{{from: fake.NewAddress(4), leader: 6}})
+	err = sm.AcceptAll([]View{
+		{from: fake.NewAddress(0), leader: 6},
+		{from: fake.NewAddress(4), leader: 6},
+	})
 	require.EqualError(t, err, "invalid view: unknown peer: fake.Address[4]")
 
 	sm.state = NoneState
@@ -833,6 +949,106 @@ func TestStateMachine_Watch(t *testing.T) {
 	require.False(t, more)
 }
 
+func TestStateMachine_LeaderElection(t *testing.T) {
+	tree, db, clean := makeTree(t)
+	defer clean()
+
+	signers := []vrf.Signer{vrf.NewSigner(), vrf.NewSigner(), vrf.NewSigner()}
+	vrfkeys := make([]vrf.PublicKey, len(signers))
+	for i, signer := range signers {
+		vrfkeys[i] = signer.GetPublicKey()
+	}
+
+	roster := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner)).WithVRFKeys(vrfkeys)
+
+	param := StateMachineParam{
+		VerifierFactory: fake.NewVerifierFactory(fake.Verifier{}),
+		Blocks:          blockstore.NewInMemory(),
+		Genesis:         blockstore.NewGenesisStore(),
+		Tree:            blockstore.NewTreeCache(tree),
+		AuthorityReader: func(hashtree.Tree) (authority.Authority, error) {
+			return roster, nil
+		},
+		DB: db,
+	}
+
+	genesis, err := types.NewGenesis(roster)
+	require.NoError(t, err)
+
+	param.Genesis.Set(genesis)
+
+	sm := NewStateMachine(param).(*pbftsm)
+
+	// No block has been proposed yet, so the first participant is the leader.
+	leader, err := sm.GetLeader()
+	require.NoError(t, err)
+	require.Equal(t, roster.AddressIterator().GetNext(), leader)
+
+	block, err := types.NewBlock(simple.NewResult(nil))
+	require.NoError(t, err)
+
+	err = sm.verifyProof(block, roster)
+	require.EqualError(t, err, "missing proof of the leader")
+
+	proof, err := ProveLeader(signers[1], genesis.GetHash())
+	require.NoError(t, err)
+
+	block, err = types.NewBlock(simple.NewResult(nil), types.WithProof(proof))
+	require.NoError(t, err)
+
+	err = sm.verifyProof(block, roster)
+	require.EqualError(t, err, "failed to verify: invalid proof: mismatch challenge")
+
+	_, err = sm.Prepare(roster.AddressIterator().GetNext(), block)
+	require.EqualError(t, err,
+		"leader election: failed to verify: invalid proof: mismatch challenge")
+
+	proof, err = ProveLeader(signers[0], genesis.GetHash())
+	require.NoError(t, err)
+
+	block, err = types.NewBlock(simple.NewResult(nil), types.WithProof(proof))
+	require.NoError(t, err)
+
+	err = sm.verifyProof(block, roster)
+	require.NoError(t, err)
+
+	err = sm.verifyProof(block, authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner)))
+	require.EqualError(t, err, "unexpected proof from a leader without a key")
+
+	// The block is finalized and the leader of the next round is the one
+	// elected by its proof.
+	sm.state = CommitState
+	sm.round.tree = tree.(hashtree.StagingTree)
+	sm.round.block = block
+	sm.round.prepareSig = fake.Signature{}
+
+	err = sm.Finalize(types.Digest{1}, fake.Signature{})
+	require.NoError(t, err)
+
+	expected, err := VerifyLeader(vrfkeys[0], genesis.GetHash(), proof, roster.Len())
+	require.NoError(t, err)
+	require.Equal(t, expected, sm.round.leader)
+
+	// A node that restarts elects the same leader from the latest block.
+	sm.round.leader = 0
+	sm.state = NoneState
+
+	leader, err = sm.GetLeader()
+	require.NoError(t, err)
+
+	iter := roster.AddressIterator()
+	iter.Seek(int(expected))
+	require.Equal(t, iter.GetNext(), leader)
+
+	sm.blocks = badBlockStore{length: 1}
+	err = sm.electLeader(roster)
+	require.EqualError(t, err, fake.Err("failed to read latest block"))
+
+	sm.authReader = badReader
+	err = sm.electNextLeader()
+	require.EqualError(t, err, fake.Err("failed to read roster"))
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
@@ -872,10 +1088,22 @@ type badValidation struct {
 	validation.Service
 }
 
-func (v badValidation) Validate(store.Snapshot, []txn.Transaction) (validation.Result, error) {
+func (v badValidation) Validate(store.Snapshot, uint64, []txn.Transaction) (validation.Result, error) {
 	return nil, fake.GetError()
 }
 
+type badAuthorityStore struct {
+	blockstore.AuthorityStore
+}
+
+func (s badAuthorityStore) WithTx(store.Transaction) blockstore.AuthorityStore {
+	return s
+}
+
+func (badAuthorityStore) Store(uint64, authority.Authority) error {
+	return fake.GetError()
+}
+
 type badBlockStore struct {
 	blockstore.BlockStore
 	length uint64
@@ -41,7 +41,8 @@ func NewView(param ViewParam, sig crypto.Signature) View {
 }
 
 // NewViewAndSign creates a new view and uses the signer to make the signature
-// that will be verified by other participants.
+// that will be verified by other participants. The key of the signer must not
+// be reserved for another usage than the consensus.
 func NewViewAndSign(param ViewParam, signer crypto.Signer) (View, error) {
 	view := View{
 		from:   param.From,
@@ -49,6 +50,11 @@ func NewViewAndSign(param ViewParam, signer crypto.Signer) (View, error) {
 		leader: param.Leader,
 	}
 
+	err := crypto.CheckUsage(signer, crypto.ConsensusUsage)
+	if err != nil {
+		return view, xerrors.Errorf("invalid signer: %v", err)
+	}
+
 	sig, err := signer.Sign(view.bytes())
 	if err != nil {
 		return view, xerrors.Errorf("signer: %v", err)
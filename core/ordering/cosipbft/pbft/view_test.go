@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
 	"go.dedis.ch/dela/internal/testing/fake"
 )
@@ -40,6 +41,13 @@ func TestView_Verify(t *testing.T) {
 	_, err = NewViewAndSign(param, fake.NewBadSigner())
 	require.EqualError(t, err, fake.Err("signer"))
 
+	txSigner, err := crypto.NewTransactionSigner(signer)
+	require.NoError(t, err)
+
+	_, err = NewViewAndSign(param, txSigner)
+	require.EqualError(t, err,
+		"invalid signer: key reserved for transaction cannot be used for consensus")
+
 	err = view.Verify(fake.NewBadPublicKey())
 	require.EqualError(t, err, fake.Err("verify"))
 }
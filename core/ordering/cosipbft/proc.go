@@ -31,6 +31,17 @@ import (
 var (
 	keyRoster = [32]byte{}
 	keyAccess = [32]byte{1}
+	keyBudget = [32]byte{2}
+
+	// keyCommittee is the key of the committee of the current epoch, which is
+	// absent when the whole roster signs the blocks.
+	keyCommittee = [32]byte{3}
+	keyEpoch     = [32]byte{4}
+
+	// keyFingerprint is the key of the version of the fingerprint of the
+	// roster of the genesis block, which is absent for the first version so
+	// that the state of the existing chains is unchanged.
+	keyFingerprint = [32]byte{5}
 )
 
 // Processor processes the messages to run a collective signing PBFT consensus.
@@ -55,14 +66,22 @@ type processor struct {
 	genesis blockstore.GenesisStore
 	blocks  blockstore.BlockStore
 
+	authorities blockstore.AuthorityStore
+
+	// fingerprint is the version of the fingerprint of the roster of the
+	// genesis block when the node creates a chain.
+	fingerprint authority.FingerprintVersion
+
 	started chan struct{}
 }
 
 func newProcessor() *processor {
 	return &processor{
 		watcher: core.NewWatcher(),
-		context: json.NewContext(),
-		started: make(chan struct{}),
+		context:     json.NewContext(),
+		authorities: blockstore.NewAuthorityStore(),
+		fingerprint: authority.FingerprintV1,
+		started:     make(chan struct{}),
 	}
 }
 
@@ -147,7 +166,10 @@ func (h *processor) Process(req mino.Request) (serde.Message, error) {
 
 		root := msg.GetGenesis().GetRoot()
 
-		return nil, h.storeGenesis(msg.GetGenesis().GetRoster(), &root)
+		// The version is part of the state, therefore the root only matches
+		// when the node agrees with the version of the leader.
+		return nil, h.storeGenesis(msg.GetGenesis().GetRoster(),
+			msg.GetGenesis().GetFingerprintVersion(), &root)
 	case types.DoneMessage:
 		err := h.pbftsm.Finalize(msg.GetID(), msg.GetSignature())
 		if err != nil {
@@ -189,7 +211,33 @@ func (h *processor) readRoster(tree hashtree.Tree) (authority.Authority, error)
 	return roster, nil
 }
 
-func (h *processor) storeGenesis(roster authority.Authority, match *types.Digest) error {
+func (h *processor) getCurrentAuthority() (authority.Authority, error) {
+	return h.readAuthority(h.tree.Get())
+}
+
+// readAuthority returns the authority that signs the blocks, which is the
+// committee of the epoch when it is set, otherwise the whole roster.
+func (h *processor) readAuthority(tree hashtree.Tree) (authority.Authority, error) {
+	data, err := tree.Get(keyCommittee[:])
+	if err != nil {
+		return nil, xerrors.Errorf("read from tree: %v", err)
+	}
+
+	if len(data) == 0 {
+		return h.readRoster(tree)
+	}
+
+	committee, err := h.rosterFac.AuthorityOf(h.context, data)
+	if err != nil {
+		return nil, xerrors.Errorf("decode failed: %v", err)
+	}
+
+	return committee, nil
+}
+
+func (h *processor) storeGenesis(roster authority.Authority,
+	version authority.FingerprintVersion, match *types.Digest) error {
+
 	value, err := roster.Serialize(h.context)
 	if err != nil {
 		return xerrors.Errorf("failed to serialize roster: %v", err)
@@ -206,6 +254,13 @@ func (h *processor) storeGenesis(roster authority.Authority, match *types.Digest
 			return xerrors.Errorf("failed to store roster: %v", err)
 		}
 
+		if version != authority.FingerprintV1 {
+			err = snap.Set(keyFingerprint[:], encodeFingerprint(version))
+			if err != nil {
+				return xerrors.Errorf("failed to store fingerprint: %v", err)
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -219,7 +274,8 @@ func (h *processor) storeGenesis(roster authority.Authority, match *types.Digest
 		return xerrors.Errorf("mismatch tree root '%v' != '%v'", match, root)
 	}
 
-	genesis, err := types.NewGenesis(roster, types.WithGenesisRoot(root))
+	genesis, err := types.NewGenesis(roster, types.WithGenesisRoot(root),
+		types.WithGenesisFingerprint(version))
 	if err != nil {
 		return xerrors.Errorf("creating genesis: %v", err)
 	}
@@ -231,6 +287,11 @@ func (h *processor) storeGenesis(roster authority.Authority, match *types.Digest
 
 	h.tree.Set(stageTree)
 
+	err = h.authorities.Store(0, roster)
+	if err != nil {
+		return xerrors.Errorf("store authority failed: %v", err)
+	}
+
 	err = h.genesis.Set(genesis)
 	if err != nil {
 		return xerrors.Errorf("set genesis failed: %v", err)
@@ -245,9 +306,18 @@ func (h *processor) makeAccess(store store.Snapshot, roster authority.Authority)
 	creds := viewchange.NewCreds(keyAccess[:])
 
 	iter := roster.PublicKeyIterator()
-	for iter.HasNext() {
+	for i := 0; iter.HasNext(); i++ {
+		ident := iter.GetNext()
+
+		// The transactions of a member are signed by its transaction identity
+		// when it has one, as its public key is reserved for the consensus.
+		txkey, found := roster.GetTxKey(i)
+		if found {
+			ident = txkey
+		}
+
 		// Grant each member of the roster an access to change the roster.
-		err := h.access.Grant(store, creds, iter.GetNext())
+		err := h.access.Grant(store, creds, ident)
 		if err != nil {
 			return err
 		}
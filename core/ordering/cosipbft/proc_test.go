@@ -1,10 +1,12 @@
 package cosipbft
 
 import (
+	"bytes"
 	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/access"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
 	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
 	"go.dedis.ch/dela/core/ordering/cosipbft/blocksync"
@@ -125,6 +127,24 @@ func TestProcessor_GenesisMessage_Process(t *testing.T) {
 	require.EqualError(t, err, fake.Err("set genesis failed"))
 }
 
+func TestProcessor_MakeAccess(t *testing.T) {
+	calls := &fake.Call{}
+
+	proc := newProcessor()
+	proc.access = fakeAccess{calls: calls}
+
+	ca := fake.NewAuthority(2, fake.NewSigner)
+	txkey := fake.NewSigner().GetPublicKey()
+
+	ro := authority.FromAuthority(ca).WithTxKeys([]crypto.PublicKey{nil, txkey})
+
+	err := proc.makeAccess(fake.NewSnapshot(), ro)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls.Len())
+	require.Equal(t, ca.GetSigner(0).GetPublicKey(), calls.Get(0, 0).([]access.Identity)[0])
+	require.Equal(t, txkey, calls.Get(1, 0).([]access.Identity)[0])
+}
+
 func TestProcessor_DoneMessage_Process(t *testing.T) {
 	proc := newProcessor()
 	proc.pbftsm = fakeSM{}
@@ -277,6 +297,11 @@ type fakeTree struct {
 	errStage  error
 	errCommit error
 	errStore  error
+	budget    []byte
+	errBudget error
+	committee []byte
+	epoch     []byte
+	path      hashtree.Path
 }
 
 func (t fakeTree) GetRoot() []byte {
@@ -284,10 +309,22 @@ func (t fakeTree) GetRoot() []byte {
 }
 
 func (t fakeTree) GetPath(key []byte) (hashtree.Path, error) {
-	return nil, t.err
+	return t.path, t.err
 }
 
 func (t fakeTree) Get(key []byte) ([]byte, error) {
+	if bytes.Equal(key, keyBudget[:]) {
+		return t.budget, t.errBudget
+	}
+
+	if bytes.Equal(key, keyCommittee[:]) {
+		return t.committee, t.err
+	}
+
+	if bytes.Equal(key, keyEpoch[:]) {
+		return t.epoch, t.err
+	}
+
 	return []byte("[]"), t.err
 }
 
@@ -39,6 +39,12 @@ func (p Proof) GetValue() []byte {
 	return p.path.GetValue()
 }
 
+// GetBlock returns the latest block of the chain, which is the block the
+// proof is made for.
+func (p Proof) GetBlock() types.Block {
+	return p.chain.GetBlock()
+}
+
 // Verify takes the genesis block and the verifier factory to verify the chain
 // up to the latest block.
 func (p Proof) Verify(genesis types.Genesis, fac crypto.VerifierFactory) error {
@@ -28,6 +28,17 @@ func TestProof_GetValue(t *testing.T) {
 	require.Equal(t, []byte("value"), p.GetValue())
 }
 
+func TestProof_GetBlock(t *testing.T) {
+	block, err := types.NewBlock(simple.NewResult(nil), types.WithIndex(2))
+	require.NoError(t, err)
+
+	p := Proof{
+		chain: fakeChain{block: block},
+	}
+
+	require.Equal(t, block, p.GetBlock())
+}
+
 func TestProof_Verify(t *testing.T) {
 	ro := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
 
@@ -0,0 +1,589 @@
+package proto
+
+import (
+	"math"
+
+	protobuf "github.com/golang/protobuf/proto"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/validation"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/vrf"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	types.RegisterGenesisFormat(serde.FormatProtobuf, genesisFormat{})
+	types.RegisterMessageFormat(serde.FormatProtobuf, msgFormat{})
+	types.RegisterBlockFormat(serde.FormatProtobuf, blockFormat{})
+	types.RegisterLinkFormat(serde.FormatProtobuf, linkFormat{})
+	types.RegisterChainFormat(serde.FormatProtobuf, chainFormat{})
+}
+
+// Genesis is the protobuf message for a genesis block.
+type Genesis struct {
+	Roster      []byte `protobuf:"bytes,1,opt,name=roster,proto3"`
+	TreeRoot    []byte `protobuf:"bytes,2,opt,name=tree_root,json=treeRoot,proto3"`
+	Fingerprint uint32 `protobuf:"varint,3,opt,name=fingerprint,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *Genesis) Reset() { *m = Genesis{} }
+
+// String implements proto.Message.
+func (m *Genesis) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*Genesis) ProtoMessage() {}
+
+// Block is the protobuf message for a block.
+type Block struct {
+	Index    uint64 `protobuf:"varint,1,opt,name=index,proto3"`
+	TreeRoot []byte `protobuf:"bytes,2,opt,name=tree_root,json=treeRoot,proto3"`
+	Data     []byte `protobuf:"bytes,3,opt,name=data,proto3"`
+	Proof    []byte `protobuf:"bytes,4,opt,name=proof,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *Block) Reset() { *m = Block{} }
+
+// String implements proto.Message.
+func (m *Block) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*Block) ProtoMessage() {}
+
+// Link is the protobuf message for a link.
+type Link struct {
+	From             []byte `protobuf:"bytes,1,opt,name=from,proto3"`
+	To               []byte `protobuf:"bytes,2,opt,name=to,proto3"`
+	PrepareSignature []byte `protobuf:"bytes,3,opt,name=prepare_signature,json=prepareSignature,proto3"`
+	CommitSignature  []byte `protobuf:"bytes,4,opt,name=commit_signature,json=commitSignature,proto3"`
+	ChangeSet        []byte `protobuf:"bytes,5,opt,name=change_set,json=changeSet,proto3"`
+	Block            []byte `protobuf:"bytes,6,opt,name=block,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *Link) Reset() { *m = Link{} }
+
+// String implements proto.Message.
+func (m *Link) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*Link) ProtoMessage() {}
+
+// Chain is the protobuf message for a chain.
+type Chain struct {
+	Links [][]byte `protobuf:"bytes,1,rep,name=links,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *Chain) Reset() { *m = Chain{} }
+
+// String implements proto.Message.
+func (m *Chain) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*Chain) ProtoMessage() {}
+
+// GenesisMessage is the protobuf message to send a genesis block.
+type GenesisMessage struct {
+	Genesis []byte `protobuf:"bytes,1,opt,name=genesis,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *GenesisMessage) Reset() { *m = GenesisMessage{} }
+
+// String implements proto.Message.
+func (m *GenesisMessage) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*GenesisMessage) ProtoMessage() {}
+
+// BlockMessage is the protobuf message to send a block.
+type BlockMessage struct {
+	Block []byte                  `protobuf:"bytes,1,opt,name=block,proto3"`
+	Views map[string]*ViewMessage `protobuf:"bytes,2,rep,name=views,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *BlockMessage) Reset() { *m = BlockMessage{} }
+
+// String implements proto.Message.
+func (m *BlockMessage) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*BlockMessage) ProtoMessage() {}
+
+// CommitMessage is the protobuf message to send a commit request.
+type CommitMessage struct {
+	ID        []byte `protobuf:"bytes,1,opt,name=id,proto3"`
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *CommitMessage) Reset() { *m = CommitMessage{} }
+
+// String implements proto.Message.
+func (m *CommitMessage) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*CommitMessage) ProtoMessage() {}
+
+// DoneMessage is the protobuf message to send a block confirmation.
+type DoneMessage struct {
+	ID        []byte `protobuf:"bytes,1,opt,name=id,proto3"`
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *DoneMessage) Reset() { *m = DoneMessage{} }
+
+// String implements proto.Message.
+func (m *DoneMessage) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*DoneMessage) ProtoMessage() {}
+
+// ViewMessage is the protobuf message to send a view change request.
+type ViewMessage struct {
+	Leader    uint32 `protobuf:"varint,1,opt,name=leader,proto3"`
+	ID        []byte `protobuf:"bytes,2,opt,name=id,proto3"`
+	Signature []byte `protobuf:"bytes,3,opt,name=signature,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *ViewMessage) Reset() { *m = ViewMessage{} }
+
+// String implements proto.Message.
+func (m *ViewMessage) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*ViewMessage) ProtoMessage() {}
+
+// Message is the protobuf message that wraps the different kinds of
+// messages.
+type Message struct {
+	Genesis *GenesisMessage `protobuf:"bytes,1,opt,name=genesis,proto3"`
+	Block   *BlockMessage   `protobuf:"bytes,2,opt,name=block,proto3"`
+	Commit  *CommitMessage  `protobuf:"bytes,3,opt,name=commit,proto3"`
+	Done    *DoneMessage    `protobuf:"bytes,4,opt,name=done,proto3"`
+	View    *ViewMessage    `protobuf:"bytes,5,opt,name=view,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *Message) Reset() { *m = Message{} }
+
+// String implements proto.Message.
+func (m *Message) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*Message) ProtoMessage() {}
+
+// GenesisFormat is a format engine to serialize and deserialize the genesis
+// blocks.
+//
+// - implements serde.FormatEngine
+type genesisFormat struct {
+	hashFac crypto.HashFactory
+}
+
+// Encode implements serde.FormatEngine. It returns the serialized data of the
+// genesis if appropriate, otherwise it returns an error.
+func (f genesisFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	genesis, ok := msg.(types.Genesis)
+	if !ok {
+		return nil, xerrors.Errorf("invalid genesis '%T'", msg)
+	}
+
+	roster, err := genesis.GetRoster().Serialize(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to serialize roster: %v", err)
+	}
+
+	m := &Genesis{
+		Roster:   roster,
+		TreeRoot: genesis.GetRoot().Bytes(),
+	}
+
+	// The version is omitted by default so that the genesis block is decoded
+	// by the participants that only know the first one.
+	if genesis.GetFingerprintVersion() != authority.FingerprintV1 {
+		m.Fingerprint = uint32(genesis.GetFingerprintVersion())
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the genesis block if
+// appropriate, otherwise it returns an error.
+func (f genesisFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := &Genesis{}
+	err := ctx.Unmarshal(data, m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	factory := ctx.GetFactory(types.RosterKey{})
+
+	fac, ok := factory.(authority.Factory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid roster factory '%T'", factory)
+	}
+
+	roster, err := fac.AuthorityOf(ctx, m.Roster)
+	if err != nil {
+		return nil, xerrors.Errorf("authority factory failed: %v", err)
+	}
+
+	root := types.Digest{}
+	copy(root[:], m.TreeRoot)
+
+	opts := []types.GenesisOption{types.WithGenesisRoot(root)}
+
+	if m.Fingerprint != 0 {
+		opts = append(opts, types.WithGenesisFingerprint(authority.FingerprintVersion(m.Fingerprint)))
+	}
+
+	if f.hashFac != nil {
+		opts = append(opts, types.WithGenesisHashFactory(f.hashFac))
+	}
+
+	genesis, err := types.NewGenesis(roster, opts...)
+	if err != nil {
+		return nil, xerrors.Errorf("creating genesis: %v", err)
+	}
+
+	return genesis, nil
+}
+
+// BlockFormat is the format engine to serialize and deserialize the blocks.
+//
+// - implements serde.FormatEngine
+type blockFormat struct {
+	hashFac crypto.HashFactory
+}
+
+// Encode implements serde.FormatEngine. It returns the serialized data of the
+// block if appropritate, otherwise it returns an error.
+func (f blockFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	block, ok := msg.(types.Block)
+	if !ok {
+		return nil, xerrors.Errorf("invalid block '%T'", msg)
+	}
+
+	blockdata, err := block.GetData().Serialize(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to serialize data: %v", err)
+	}
+
+	m := &Block{
+		Index:    block.GetIndex(),
+		TreeRoot: block.GetTreeRoot().Bytes(),
+		Data:     blockdata,
+	}
+
+	proof, found := block.GetProof()
+	if found {
+		m.Proof, err = proof.MarshalBinary()
+		if err != nil {
+			return nil, xerrors.Errorf("failed to marshal proof: %v", err)
+		}
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the block if appropriate,
+// otherwise it returns an error.
+func (f blockFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := &Block{}
+	err := ctx.Unmarshal(data, m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	factory := ctx.GetFactory(types.DataKey{})
+
+	fac, ok := factory.(validation.ResultFactory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid data factory '%T'", factory)
+	}
+
+	blockdata, err := fac.ResultOf(ctx, m.Data)
+	if err != nil {
+		return nil, xerrors.Errorf("data factory failed: %v", err)
+	}
+
+	root := types.Digest{}
+	copy(root[:], m.TreeRoot)
+
+	opts := []types.BlockOption{
+		types.WithTreeRoot(root),
+		types.WithIndex(m.Index),
+	}
+
+	if len(m.Proof) > 0 {
+		proof, err := vrf.NewProof(m.Proof)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid proof: %v", err)
+		}
+
+		opts = append(opts, types.WithProof(proof))
+	}
+
+	if f.hashFac != nil {
+		opts = append(opts, types.WithHashFactory(f.hashFac))
+	}
+
+	block, err := types.NewBlock(blockdata, opts...)
+	if err != nil {
+		return nil, xerrors.Errorf("creating block: %v", err)
+	}
+
+	return block, nil
+}
+
+// MsgFormat is the format engine to serialize and deserialize the messages.
+//
+// - implements serde.FormatEngine
+type msgFormat struct{}
+
+// Encode implements serde.FormatEngine. It returns the serialized data of the
+// message if appropriate, otherwise it returns an error.
+func (f msgFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	m := &Message{}
+
+	switch in := msg.(type) {
+	case types.GenesisMessage:
+		genesis, err := in.GetGenesis().Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to serialize genesis: %v", err)
+		}
+
+		m.Genesis = &GenesisMessage{
+			Genesis: genesis,
+		}
+	case types.BlockMessage:
+		block, err := in.GetBlock().Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("block: %v", err)
+		}
+
+		views := make(map[string]*ViewMessage)
+		for addr, view := range in.GetViews() {
+			key, err := addr.MarshalText()
+			if err != nil {
+				return nil, xerrors.Errorf("failed to serialize address: %v", err)
+			}
+
+			rawView, err := encodeView(view, ctx)
+			if err != nil {
+				return nil, xerrors.Errorf("view: %v", err)
+			}
+
+			views[string(key)] = rawView
+		}
+
+		m.Block = &BlockMessage{
+			Block: block,
+			Views: views,
+		}
+	case types.CommitMessage:
+		sig, err := in.GetSignature().Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to serialize signature: %v", err)
+		}
+
+		m.Commit = &CommitMessage{
+			ID:        in.GetID().Bytes(),
+			Signature: sig,
+		}
+	case types.DoneMessage:
+		sig, err := in.GetSignature().Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to serialize signature: %v", err)
+		}
+
+		m.Done = &DoneMessage{
+			ID:        in.GetID().Bytes(),
+			Signature: sig,
+		}
+	case types.ViewMessage:
+		vm, err := encodeView(in, ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("view: %v", err)
+		}
+
+		m.View = vm
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+func encodeView(in types.ViewMessage, ctx serde.Context) (*ViewMessage, error) {
+	sig, err := in.GetSignature().Serialize(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to serialize signature: %v", err)
+	}
+
+	vm := &ViewMessage{
+		ID:        in.GetID().Bytes(),
+		Leader:    uint32(in.GetLeader()),
+		Signature: sig,
+	}
+
+	return vm, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the message if
+// appropriate, otherwise it returns an error.
+func (f msgFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := &Message{}
+	err := ctx.Unmarshal(data, m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	if m.Genesis != nil {
+		factory := ctx.GetFactory(types.GenesisKey{})
+		if factory == nil {
+			return nil, xerrors.New("missing genesis factory")
+		}
+
+		msg, err := factory.Deserialize(ctx, m.Genesis.Genesis)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to deserialize genesis: %v", err)
+		}
+
+		genesis, ok := msg.(types.Genesis)
+		if !ok {
+			return nil, xerrors.Errorf("invalid genesis '%T'", msg)
+		}
+
+		return types.NewGenesisMessage(genesis), nil
+	}
+
+	if m.Block != nil {
+		// 1. Decode the block.
+		factory := ctx.GetFactory(types.BlockKey{})
+		if factory == nil {
+			return nil, xerrors.New("missing block factory")
+		}
+
+		msg, err := factory.Deserialize(ctx, m.Block.Block)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to deserialize block: %v", err)
+		}
+
+		block, ok := msg.(types.Block)
+		if !ok {
+			return nil, xerrors.Errorf("invalid block '%T'", msg)
+		}
+
+		// 2. Decode the view messages if any.
+		factory = ctx.GetFactory(types.AddressKey{})
+
+		fac, ok := factory.(mino.AddressFactory)
+		if !ok {
+			return nil, xerrors.Errorf("invalid address factory '%T'", factory)
+		}
+
+		views := make(map[mino.Address]types.ViewMessage)
+		for key, rawView := range m.Block.Views {
+			addr := fac.FromText([]byte(key))
+
+			view, err := decodeView(ctx, rawView)
+			if err != nil {
+				return nil, xerrors.Errorf("view: %v", err)
+			}
+
+			views[addr] = view
+		}
+
+		return types.NewBlockMessage(block, views), nil
+	}
+
+	if m.Commit != nil {
+		sig, err := decodeSignature(ctx, m.Commit.Signature, types.AggregateKey{})
+		if err != nil {
+			return nil, xerrors.Errorf("commit failed: %v", err)
+		}
+
+		id := types.Digest{}
+		copy(id[:], m.Commit.ID)
+
+		return types.NewCommit(id, sig), nil
+	}
+
+	if m.Done != nil {
+		sig, err := decodeSignature(ctx, m.Done.Signature, types.AggregateKey{})
+		if err != nil {
+			return nil, xerrors.Errorf("done failed: %v", err)
+		}
+
+		id := types.Digest{}
+		copy(id[:], m.Done.ID)
+
+		return types.NewDone(id, sig), nil
+	}
+
+	if m.View != nil {
+		return decodeView(ctx, m.View)
+	}
+
+	return nil, xerrors.New("message is empty")
+}
+
+func decodeView(ctx serde.Context, view *ViewMessage) (types.ViewMessage, error) {
+	if view == nil {
+		return types.ViewMessage{}, xerrors.New("view is empty")
+	}
+
+	if view.Leader > math.MaxUint16 {
+		return types.ViewMessage{}, xerrors.Errorf("invalid leader index %d", view.Leader)
+	}
+
+	sig, err := decodeSignature(ctx, view.Signature, types.SignatureKey{})
+	if err != nil {
+		return types.ViewMessage{}, xerrors.Errorf("signature: %v", err)
+	}
+
+	id := types.Digest{}
+	copy(id[:], view.ID)
+
+	return types.NewViewMessage(id, uint16(view.Leader), sig), nil
+}
+
+func decodeSignature(ctx serde.Context, data []byte, key interface{}) (crypto.Signature, error) {
+	factory := ctx.GetFactory(key)
+
+	fac, ok := factory.(crypto.SignatureFactory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid signature factory '%T'", factory)
+	}
+
+	sig, err := fac.SignatureOf(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("factory failed: %v", err)
+	}
+
+	return sig, nil
+}
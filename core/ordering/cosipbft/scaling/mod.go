@@ -0,0 +1,633 @@
+// Package scaling implements a policy that proposes to expand or contract the
+// roster of a chain in order to keep a target fault tolerance.
+//
+// The policy observes which members of the roster sign the blocks, and keeps a
+// moving average of their availability. A member below the minimum
+// availability is counted as faulty. When the roster cannot tolerate the target
+// number of faults on top of the faulty members, the policy proposes to add
+// the next registered candidate. When a member can be removed while keeping the
+// target, it proposes to remove the least available one.
+//
+// The proposals are never applied automatically. An operator approves them,
+// and the roster change then goes through the view change contract, which
+// applies the access control of the chain.
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/vrf"
+	"go.dedis.ch/dela/mino"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// DefaultMinAvailability is the default ratio of the blocks that a member
+	// must sign to be counted as available. It is well below one as the
+	// collective signature completes with a threshold of the members.
+	DefaultMinAvailability = 0.5
+
+	// DefaultMinSamples is the default number of blocks observed before the
+	// availability of a member is taken into account.
+	DefaultMinSamples = 20
+
+	// DefaultSmoothing is the default weight of the latest block in the moving
+	// average of the availability.
+	DefaultSmoothing = 0.1
+
+	// DefaultSettleBlocks is the default number of blocks during which the
+	// policy waits for an approved proposal to change the roster before it
+	// proposes again.
+	DefaultSettleBlocks = 10
+)
+
+// Kind is the kind of a proposal.
+type Kind string
+
+const (
+	// ExpandKind is the kind of a proposal to add a candidate to the roster.
+	ExpandKind Kind = "expand"
+
+	// ContractKind is the kind of a proposal to remove a member from the
+	// roster.
+	ContractKind Kind = "contract"
+)
+
+// Candidate is a participant that can join the roster when it needs to expand.
+// The key of the leader election and the key of the transactions are optional.
+type Candidate struct {
+	Address   mino.Address
+	PublicKey crypto.PublicKey
+	VRFKey    vrf.PublicKey
+	TxKey     crypto.PublicKey
+}
+
+// Member is the availability of a member of the roster.
+type Member struct {
+	Address mino.Address
+
+	// Availability is the moving average of the ratio of the blocks signed by
+	// the member.
+	Availability float64
+
+	// Samples is the number of blocks observed for the member.
+	Samples int
+}
+
+// Proposal is a change of the roster waiting for the approval of an operator.
+type Proposal struct {
+	ID      uint64
+	Kind    Kind
+	Address mino.Address
+	Reason  string
+	Time    time.Time
+
+	// candidate is the participant to add when the roster expands.
+	candidate Candidate
+}
+
+// ChangeSet returns the change set that applies the proposal to the roster. It
+// returns an error if the roster has changed in a way that makes the proposal
+// irrelevant.
+func (p Proposal) ChangeSet(roster authority.Authority) (*authority.RosterChangeSet, error) {
+	_, index := roster.GetPublicKey(p.Address)
+
+	cset := authority.NewChangeSet()
+
+	switch p.Kind {
+	case ExpandKind:
+		if index >= 0 {
+			return nil, xerrors.Errorf("%v is already a member", p.Address)
+		}
+
+		c := p.candidate
+		cset.AddWithKeys(c.Address, c.PublicKey, 1, c.VRFKey, c.TxKey)
+	case ContractKind:
+		if index < 0 {
+			return nil, xerrors.Errorf("%v is not a member", p.Address)
+		}
+
+		cset.Remove(uint(index))
+	default:
+		return nil, xerrors.Errorf("unknown kind '%s'", p.Kind)
+	}
+
+	return cset, nil
+}
+
+// String implements fmt.Stringer. It returns a one-line description of the
+// proposal.
+func (p Proposal) String() string {
+	return fmt.Sprintf("#%d %s %v: %s", p.ID, p.Kind, p.Address, p.Reason)
+}
+
+// Service is the interface of the ordering service observed by the policy.
+type Service interface {
+	// GetRoster returns the current roster.
+	GetRoster() (authority.Authority, error)
+
+	// GetRosterAt returns the roster that is effective for the block at the
+	// given index.
+	GetRosterAt(index uint64) (authority.Authority, error)
+
+	// Watch returns a channel populated with the new blocks.
+	Watch(ctx context.Context) <-chan ordering.Event
+}
+
+// Option is the type of option to configure the policy.
+type Option func(*Policy)
+
+// WithMinAvailability sets the ratio of the blocks that a member must sign to
+// be counted as available.
+func WithMinAvailability(ratio float64) Option {
+	return func(p *Policy) {
+		p.minAvailability = ratio
+	}
+}
+
+// WithMinSamples sets the number of blocks observed before the availability of
+// a member is taken into account.
+func WithMinSamples(n int) Option {
+	return func(p *Policy) {
+		p.minSamples = n
+	}
+}
+
+// WithSmoothing sets the weight, between zero and one, of the latest block in
+// the moving average of the availability.
+func WithSmoothing(alpha float64) Option {
+	return func(p *Policy) {
+		p.smoothing = alpha
+	}
+}
+
+// record is the availability of a member.
+type record struct {
+	avail   float64
+	samples int
+}
+
+// Policy proposes the changes of the roster that keep the target fault
+// tolerance. Only one proposal is pending at a time, as the view change
+// contract applies one change per block.
+type Policy struct {
+	sync.Mutex
+
+	srvc            Service
+	blocks          blockstore.BlockStore
+	target          int
+	minAvailability float64
+	minSamples      int
+	smoothing       float64
+	logger          zerolog.Logger
+
+	candidates []Candidate
+	records    map[string]*record
+	pending    *Proposal
+	rejected   map[string]struct{}
+	nextID     uint64
+
+	// approved is the proposal waiting to be applied by the chain, and settle
+	// the number of blocks left before it is given up on.
+	approved *Proposal
+	settle   int
+
+	// rosterKey identifies the last roster evaluated, so that the rejected
+	// proposals are forgotten once it changes.
+	rosterKey string
+
+	closing chan struct{}
+	done    chan struct{}
+}
+
+// NewPolicy creates a new policy that keeps the roster able to tolerate the
+// target number of faulty members.
+func NewPolicy(srvc Service, blocks blockstore.BlockStore, target int, opts ...Option) *Policy {
+	p := &Policy{
+		srvc:            srvc,
+		blocks:          blocks,
+		target:          target,
+		minAvailability: DefaultMinAvailability,
+		minSamples:      DefaultMinSamples,
+		smoothing:       DefaultSmoothing,
+		logger:          dela.Logger.With().Str("service", "scaling").Logger(),
+		records:         make(map[string]*record),
+		rejected:        make(map[string]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Register adds the candidate to the set of participants that can join the
+// roster. The candidates are proposed in the order they are registered.
+func (p *Policy) Register(c Candidate) error {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, other := range p.candidates {
+		if other.Address.Equal(c.Address) {
+			return xerrors.Errorf("candidate %v already registered", c.Address)
+		}
+	}
+
+	p.candidates = append(p.candidates, c)
+
+	return nil
+}
+
+// Unregister removes the candidate with the address. It returns false if there
+// is none.
+func (p *Policy) Unregister(addr mino.Address) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	for i, c := range p.candidates {
+		if c.Address.Equal(addr) {
+			p.candidates = append(p.candidates[:i], p.candidates[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetCandidates returns the registered candidates.
+func (p *Policy) GetCandidates() []Candidate {
+	p.Lock()
+	defer p.Unlock()
+
+	return append([]Candidate{}, p.candidates...)
+}
+
+// GetMembers returns the availability of the members of the roster.
+func (p *Policy) GetMembers(roster authority.Authority) []Member {
+	p.Lock()
+	defer p.Unlock()
+
+	members := make([]Member, 0, roster.Len())
+
+	iter := roster.AddressIterator()
+	for iter.HasNext() {
+		addr := iter.GetNext()
+
+		m := Member{Address: addr}
+
+		rec, found := p.records[addr.String()]
+		if found {
+			m.Availability = rec.avail
+			m.Samples = rec.samples
+		}
+
+		members = append(members, m)
+	}
+
+	return members
+}
+
+// GetProposals returns the proposals waiting for an approval.
+func (p *Policy) GetProposals() []Proposal {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.pending == nil {
+		return nil
+	}
+
+	return []Proposal{*p.pending}
+}
+
+// Approve returns the pending proposal with the identifier so that it can be
+// submitted to the chain. The policy waits for the roster to change before it
+// proposes again.
+func (p *Policy) Approve(id uint64) (Proposal, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.pending == nil || p.pending.ID != id {
+		return Proposal{}, xerrors.Errorf("proposal #%d not found", id)
+	}
+
+	prop := *p.pending
+
+	p.pending = nil
+	p.approved = &prop
+	p.settle = DefaultSettleBlocks
+
+	return prop, nil
+}
+
+// Reject discards the pending proposal with the identifier. The same proposal
+// is not made again until the roster changes.
+func (p *Policy) Reject(id uint64) error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.pending == nil || p.pending.ID != id {
+		return xerrors.Errorf("proposal #%d not found", id)
+	}
+
+	p.rejected[proposalKey(p.pending.Kind, p.pending.Address)] = struct{}{}
+	p.pending = nil
+
+	return nil
+}
+
+// Observe updates the availability of the members of the roster with the
+// signatures of the block. A member is available if it has signed either the
+// prepare or the commit phase. The signatures that don't tell the signers are
+// ignored.
+func (p *Policy) Observe(roster authority.Authority, link types.BlockLink) {
+	prepare, ok := link.GetPrepareSignature().(signers)
+	if !ok {
+		return
+	}
+
+	commit, ok := link.GetCommitSignature().(signers)
+	if !ok {
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	current := make(map[string]struct{})
+
+	iter := roster.AddressIterator()
+	for i := 0; iter.HasNext(); i++ {
+		key := iter.GetNext().String()
+		current[key] = struct{}{}
+
+		value := 0.0
+		if prepare.HasBit(i) || commit.HasBit(i) {
+			value = 1.0
+		}
+
+		rec, found := p.records[key]
+		if !found {
+			p.records[key] = &record{avail: value, samples: 1}
+			continue
+		}
+
+		rec.avail += p.smoothing * (value - rec.avail)
+		rec.samples++
+	}
+
+	// The members that left the roster start again if they come back.
+	for key := range p.records {
+		_, found := current[key]
+		if !found {
+			delete(p.records, key)
+		}
+	}
+}
+
+// Evaluate compares the roster to the target and returns the new proposal, if
+// any. It returns false while a proposal is pending or an approved one has not
+// yet changed the roster.
+func (p *Policy) Evaluate(roster authority.Authority) (Proposal, bool) {
+	p.Lock()
+	defer p.Unlock()
+
+	key := rosterKey(roster)
+	if key != p.rosterKey {
+		p.rosterKey = key
+		p.rejected = make(map[string]struct{})
+		p.approved = nil
+	}
+
+	if p.pending != nil {
+		return Proposal{}, false
+	}
+
+	if p.approved != nil {
+		p.settle--
+		if p.settle > 0 {
+			return Proposal{}, false
+		}
+
+		p.logger.Warn().Stringer("proposal", p.approved).
+			Msg("approved proposal did not change the roster")
+
+		p.approved = nil
+	}
+
+	prop, found := p.propose(roster)
+	if !found {
+		return Proposal{}, false
+	}
+
+	p.nextID++
+	prop.ID = p.nextID
+	prop.Time = time.Now()
+
+	p.pending = &prop
+
+	p.logger.Info().Stringer("proposal", prop).Msg("new roster proposal")
+
+	return prop, true
+}
+
+// Start evaluates the roster after each new block in the background.
+func (p *Policy) Start() {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.closing != nil {
+		return
+	}
+
+	p.closing = make(chan struct{})
+	p.done = make(chan struct{})
+
+	go p.run(p.closing, p.done)
+}
+
+// Stop stops the evaluations and waits for the current one to complete.
+func (p *Policy) Stop() {
+	p.Lock()
+	closing, done := p.closing, p.done
+	p.closing = nil
+	p.Unlock()
+
+	if closing == nil {
+		return
+	}
+
+	close(closing)
+	<-done
+}
+
+func (p *Policy) run(closing, done chan struct{}) {
+	defer close(done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := p.srvc.Watch(ctx)
+
+	for {
+		select {
+		case <-closing:
+			return
+		case event, more := <-events:
+			if !more {
+				return
+			}
+
+			err := p.onBlock(event.Index)
+			if err != nil {
+				p.logger.Warn().Err(err).Msg("roster evaluation failed")
+			}
+		}
+	}
+}
+
+func (p *Policy) onBlock(index uint64) error {
+	link, err := p.blocks.GetByIndex(index)
+	if err != nil {
+		return xerrors.Errorf("block store: %v", err)
+	}
+
+	roster, err := p.srvc.GetRosterAt(index)
+	if err != nil {
+		return xerrors.Errorf("roster at %d: %v", index, err)
+	}
+
+	p.Observe(roster, link)
+
+	current, err := p.srvc.GetRoster()
+	if err != nil {
+		return xerrors.Errorf("roster: %v", err)
+	}
+
+	p.Evaluate(current)
+
+	return nil
+}
+
+// propose returns the change that brings the roster closer to the target, if
+// any. The roster expands as long as it cannot tolerate the target number of
+// faults on top of the faulty members, and otherwise contracts while it can.
+func (p *Policy) propose(roster authority.Authority) (Proposal, bool) {
+	n := roster.Len()
+
+	var members []Member
+	faulty := 0
+
+	iter := roster.AddressIterator()
+	for iter.HasNext() {
+		addr := iter.GetNext()
+
+		rec, found := p.records[addr.String()]
+		if !found || rec.samples < p.minSamples {
+			// The member is assumed to be available until enough blocks have
+			// been observed.
+			continue
+		}
+
+		members = append(members, Member{Address: addr, Availability: rec.avail})
+
+		if rec.avail < p.minAvailability {
+			faulty++
+		}
+	}
+
+	if tolerance(n, faulty) < p.target {
+		for _, c := range p.candidates {
+			_, index := roster.GetPublicKey(c.Address)
+			if index >= 0 || p.isRejected(ExpandKind, c.Address) {
+				continue
+			}
+
+			prop := Proposal{
+				Kind:    ExpandKind,
+				Address: c.Address,
+				Reason: fmt.Sprintf("%d members with %d faulty tolerate %d faults out of %d",
+					n, faulty, tolerance(n, faulty), p.target),
+				candidate: c,
+			}
+
+			return prop, true
+		}
+
+		return Proposal{}, false
+	}
+
+	// The least available members are removed first.
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].Availability < members[j].Availability
+	})
+
+	for _, m := range members {
+		if n <= 1 || p.isRejected(ContractKind, m.Address) {
+			continue
+		}
+
+		left := faulty
+		if m.Availability < p.minAvailability {
+			left--
+		}
+
+		if tolerance(n-1, left) < p.target {
+			continue
+		}
+
+		prop := Proposal{
+			Kind:    ContractKind,
+			Address: m.Address,
+			Reason: fmt.Sprintf("%d members tolerate %d faults out of %d without it "+
+				"(availability %.2f)", n-1, tolerance(n-1, left), p.target, m.Availability),
+		}
+
+		return prop, true
+	}
+
+	return Proposal{}, false
+}
+
+func (p *Policy) isRejected(kind Kind, addr mino.Address) bool {
+	_, found := p.rejected[proposalKey(kind, addr)]
+	return found
+}
+
+// signers is the interface of a collective signature that tells which members
+// have signed.
+type signers interface {
+	HasBit(index int) bool
+}
+
+// tolerance returns the number of faults that a roster of the size can still
+// tolerate on top of the faulty members.
+func tolerance(n, faulty int) int {
+	return (n-1)/3 - faulty
+}
+
+func proposalKey(kind Kind, addr mino.Address) string {
+	return string(kind) + ":" + addr.String()
+}
+
+func rosterKey(roster authority.Authority) string {
+	key := ""
+
+	iter := roster.AddressIterator()
+	for iter.HasNext() {
+		key += iter.GetNext().String() + ";"
+	}
+
+	return key
+}
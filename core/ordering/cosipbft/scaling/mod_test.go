@@ -0,0 +1,269 @@
+package scaling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestPolicy_Register(t *testing.T) {
+	p := NewPolicy(nil, nil, 1)
+
+	require.NoError(t, p.Register(makeCandidate(10)))
+	require.NoError(t, p.Register(makeCandidate(11)))
+	require.Len(t, p.GetCandidates(), 2)
+
+	err := p.Register(makeCandidate(10))
+	require.EqualError(t, err, "candidate fake.Address[10] already registered")
+
+	require.True(t, p.Unregister(fake.NewAddress(10)))
+	require.False(t, p.Unregister(fake.NewAddress(10)))
+	require.Len(t, p.GetCandidates(), 1)
+}
+
+func TestPolicy_Observe(t *testing.T) {
+	roster := makeRoster(4)
+
+	p := NewPolicy(nil, nil, 1, WithSmoothing(0.5))
+
+	p.Observe(roster, makeLink(t, 0, 1, 2))
+	p.Observe(roster, makeLink(t, 0, 1, 2, 3))
+
+	members := p.GetMembers(roster)
+	require.Len(t, members, 4)
+	require.Equal(t, 1.0, members[0].Availability)
+	require.Equal(t, 2, members[0].Samples)
+	require.Equal(t, 0.5, members[3].Availability)
+
+	// The signatures that don't tell the signers are ignored.
+	link, err := types.NewBlockLink(types.Digest{}, makeBlock(t),
+		types.WithSignatures(fake.Signature{}, fake.Signature{}))
+	require.NoError(t, err)
+
+	p.Observe(roster, link)
+	require.Equal(t, 2, p.GetMembers(roster)[0].Samples)
+
+	// The members that left the roster are forgotten.
+	p.Observe(makeRoster(2), makeLink(t, 0, 1))
+	require.Len(t, p.records, 2)
+	require.Equal(t, 0, p.GetMembers(roster)[3].Samples)
+}
+
+func TestPolicy_Expand_Evaluate(t *testing.T) {
+	roster := makeRoster(4)
+
+	p := NewPolicy(nil, nil, 1, WithMinSamples(2))
+	require.NoError(t, p.Register(makeCandidate(1)))
+	require.NoError(t, p.Register(makeCandidate(10)))
+
+	// Not enough samples yet to count the member as faulty.
+	p.Observe(roster, makeLink(t, 0, 1, 2))
+
+	_, found := p.Evaluate(roster)
+	require.False(t, found)
+
+	p.Observe(roster, makeLink(t, 0, 1, 2))
+
+	prop, found := p.Evaluate(roster)
+	require.True(t, found)
+	require.Equal(t, uint64(1), prop.ID)
+	require.Equal(t, ExpandKind, prop.Kind)
+	// The first candidate is already a member.
+	require.Equal(t, fake.NewAddress(10), prop.Address)
+	require.Equal(t, "4 members with 1 faulty tolerate 0 faults out of 1", prop.Reason)
+	require.Equal(t, []Proposal{prop}, p.GetProposals())
+
+	// Only one proposal is pending at a time.
+	_, found = p.Evaluate(roster)
+	require.False(t, found)
+
+	approved, err := p.Approve(prop.ID)
+	require.NoError(t, err)
+	require.Equal(t, prop, approved)
+	require.Empty(t, p.GetProposals())
+
+	_, err = p.Approve(prop.ID)
+	require.EqualError(t, err, "proposal #1 not found")
+
+	cset, err := approved.ChangeSet(roster)
+	require.NoError(t, err)
+	require.Equal(t, 5, roster.Apply(cset).Len())
+
+	// The policy waits for the roster to change.
+	for i := 0; i < DefaultSettleBlocks-1; i++ {
+		_, found = p.Evaluate(roster)
+		require.False(t, found)
+	}
+
+	// .. and gives up eventually.
+	prop, found = p.Evaluate(roster)
+	require.True(t, found)
+	require.Equal(t, uint64(2), prop.ID)
+}
+
+func TestPolicy_NoCandidate_Evaluate(t *testing.T) {
+	roster := makeRoster(4)
+
+	p := NewPolicy(nil, nil, 1, WithMinSamples(1))
+	p.Observe(roster, makeLink(t, 0, 1, 2))
+
+	_, found := p.Evaluate(roster)
+	require.False(t, found)
+}
+
+func TestPolicy_Contract_Evaluate(t *testing.T) {
+	roster := makeRoster(7)
+
+	p := NewPolicy(nil, nil, 1, WithMinSamples(1))
+	p.Observe(roster, makeLink(t, 0, 1, 2, 3, 4, 6))
+
+	// The faulty member is removed first.
+	prop, found := p.Evaluate(roster)
+	require.True(t, found)
+	require.Equal(t, ContractKind, prop.Kind)
+	require.Equal(t, fake.NewAddress(5), prop.Address)
+
+	cset, err := prop.ChangeSet(roster)
+	require.NoError(t, err)
+	require.Equal(t, []uint{5}, cset.GetRemoveIndices())
+
+	// A rejected proposal is not made again until the roster changes.
+	require.NoError(t, p.Reject(prop.ID))
+	require.EqualError(t, p.Reject(prop.ID), "proposal #1 not found")
+
+	// The other members are needed while the faulty one stays.
+	_, found = p.Evaluate(roster)
+	require.False(t, found)
+
+	// A roster of four members tolerates exactly one fault.
+	roster = makeRoster(4)
+	p.Observe(roster, makeLink(t, 0, 1, 2, 3))
+
+	_, found = p.Evaluate(roster)
+	require.False(t, found)
+
+	roster = makeRoster(5)
+	p.Observe(roster, makeLink(t, 0, 1, 2, 3, 4))
+
+	prop, found = p.Evaluate(roster)
+	require.True(t, found)
+	require.Equal(t, fake.NewAddress(0), prop.Address)
+}
+
+func TestProposal_ChangeSet(t *testing.T) {
+	roster := makeRoster(3)
+
+	prop := Proposal{Kind: ExpandKind, Address: fake.NewAddress(1)}
+	_, err := prop.ChangeSet(roster)
+	require.EqualError(t, err, "fake.Address[1] is already a member")
+
+	prop = Proposal{Kind: ContractKind, Address: fake.NewAddress(5)}
+	_, err = prop.ChangeSet(roster)
+	require.EqualError(t, err, "fake.Address[5] is not a member")
+
+	prop = Proposal{Kind: Kind("abc"), Address: fake.NewAddress(5)}
+	_, err = prop.ChangeSet(roster)
+	require.EqualError(t, err, "unknown kind 'abc'")
+
+	prop = Proposal{ID: 2, Kind: ContractKind, Address: fake.NewAddress(5), Reason: "test"}
+	require.Equal(t, "#2 contract fake.Address[5]: test", prop.String())
+}
+
+func TestPolicy_Start(t *testing.T) {
+	roster := makeRoster(4)
+
+	blocks := blockstore.NewInMemory()
+	require.NoError(t, blocks.Store(makeLink(t, 0, 1, 2)))
+
+	srvc := &fakeService{roster: roster, events: make(chan ordering.Event, 1)}
+
+	p := NewPolicy(srvc, blocks, 1, WithMinSamples(1))
+	require.NoError(t, p.Register(makeCandidate(10)))
+
+	p.Start()
+	// A second start is ignored.
+	p.Start()
+
+	srvc.events <- ordering.Event{Index: 0}
+
+	require.Eventually(t, func() bool {
+		return len(p.GetProposals()) == 1
+	}, time.Second, time.Millisecond)
+
+	// The evaluation fails but the policy keeps running.
+	srvc.events <- ordering.Event{Index: 5}
+
+	p.Stop()
+	p.Stop()
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeRoster(n int) authority.Authority {
+	return authority.FromAuthority(fake.NewAuthority(n, fake.NewSigner))
+}
+
+func makeCandidate(index int) Candidate {
+	return Candidate{
+		Address:   fake.NewAddress(index),
+		PublicKey: fake.PublicKey{},
+	}
+}
+
+func makeBlock(t *testing.T) types.Block {
+	block, err := types.NewBlock(simple.NewResult(nil))
+	require.NoError(t, err)
+
+	return block
+}
+
+// makeLink returns a link whose signatures are signed by the members at the
+// indices.
+func makeLink(t *testing.T, indices ...int) types.BlockLink {
+	sig := fakeSignature{bits: make(map[int]bool)}
+	for _, i := range indices {
+		sig.bits[i] = true
+	}
+
+	link, err := types.NewBlockLink(types.Digest{}, makeBlock(t),
+		types.WithSignatures(sig, sig))
+	require.NoError(t, err)
+
+	return link
+}
+
+type fakeSignature struct {
+	fake.Signature
+
+	bits map[int]bool
+}
+
+func (s fakeSignature) HasBit(index int) bool {
+	return s.bits[index]
+}
+
+type fakeService struct {
+	roster authority.Authority
+	events chan ordering.Event
+}
+
+func (s *fakeService) GetRoster() (authority.Authority, error) {
+	return s.roster, nil
+}
+
+func (s *fakeService) GetRosterAt(uint64) (authority.Authority, error) {
+	return s.roster, nil
+}
+
+func (s *fakeService) Watch(context.Context) <-chan ordering.Event {
+	return s.events
+}
@@ -0,0 +1,132 @@
+// Package controller implements a CLI controller to export the state of a node
+// and to compare the exports of two nodes.
+package controller
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/statediff"
+	"go.dedis.ch/dela/core/store/diff"
+	"golang.org/x/xerrors"
+)
+
+// NewController returns a new controller with the commands to export and
+// compare the state of the nodes.
+func NewController() node.Initializer {
+	return minimal{
+		out: os.Stdout,
+	}
+}
+
+// minimal is an initializer with the commands to export and compare the state
+// of the nodes.
+//
+// - implements node.Initializer
+type minimal struct {
+	out io.Writer
+}
+
+// SetCommands implements node.Initializer. It sets the command to export the
+// state of the node, and the one to compare two exports, which doesn't need a
+// running node.
+func (m minimal) SetCommands(builder node.Builder) {
+	cmd := builder.SetCommand("state")
+	cmd.SetDescription("Debugging of the divergence of the state")
+
+	sub := cmd.SetSubCommand("export")
+	sub.SetDescription("writes the state of the node, and the blocks that wrote it")
+	sub.SetFlags(cli.StringFlag{
+		Name:     "path",
+		Usage:    "path to the file of the export",
+		Required: true,
+	})
+	sub.SetAction(builder.MakeAction(node.Destructive(exportAction{})))
+
+	sub = cmd.SetSubCommand("diff")
+	sub.SetDescription("prints the keys that differ between two exports")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name:     "a",
+			Usage:    "path to the first export",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     "b",
+			Usage:    "path to the second export",
+			Required: true,
+		},
+	)
+	sub.SetAction(m.diff)
+}
+
+// OnStart implements node.Initializer. It does nothing.
+func (minimal) OnStart(flags cli.Flags, inj node.Injector) error {
+	return nil
+}
+
+// OnStop implements node.Initializer. It does nothing.
+func (minimal) OnStop(inj node.Injector) error {
+	return nil
+}
+
+// diff reads the two exports and prints their differences.
+func (m minimal) diff(flags cli.Flags) error {
+	a, err := statediff.Load(flags.Path("a"))
+	if err != nil {
+		return xerrors.Errorf("export A: %v", err)
+	}
+
+	b, err := statediff.Load(flags.Path("b"))
+	if err != nil {
+		return xerrors.Errorf("export B: %v", err)
+	}
+
+	statediff.Fprint(m.out, a, b)
+
+	return nil
+}
+
+// exportAction is an action to write the state of the node to a file.
+//
+// - implements node.ActionTemplate
+type exportAction struct{}
+
+// Execute implements node.ActionTemplate. It rebuilds the state from the
+// journal of the node and writes it with the blocks that last wrote the keys.
+// The export is run as an operation that reports the heights of the journal
+// read so far.
+func (exportAction) Execute(ctx node.Context) error {
+	var journal diff.Journal
+	err := ctx.Injector.Resolve(&journal)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var blocks blockstore.BlockStore
+	err = ctx.Injector.Resolve(&blocks)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	path := ctx.Flags.Path("path")
+
+	return ctx.Operations.Run(ctx, "export", func(r node.Reporter) (string, error) {
+		export, err := statediff.NewExport(journal, blocks, statediff.WithProgress(r.Report))
+		if err != nil {
+			return "", xerrors.Errorf("failed to export: %v", err)
+		}
+
+		err = export.Save(path)
+		if err != nil {
+			return "", xerrors.Errorf("failed to save: %v", err)
+		}
+
+		return fmt.Sprintf("state of height %d exported with %d keys\n",
+			export.Height, len(export.Entries)), nil
+	})
+}
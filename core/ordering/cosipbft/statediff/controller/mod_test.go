@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/statediff"
+	"go.dedis.ch/dela/core/store/diff"
+	"go.dedis.ch/dela/core/store/kv"
+)
+
+func TestMinimal_SetCommands(t *testing.T) {
+	m := NewController()
+
+	b := node.NewBuilder()
+	m.SetCommands(b)
+}
+
+func TestMinimal_OnStart(t *testing.T) {
+	err := NewController().OnStart(make(node.FlagSet), node.NewInjector())
+	require.NoError(t, err)
+}
+
+func TestMinimal_OnStop(t *testing.T) {
+	err := NewController().OnStop(node.NewInjector())
+	require.NoError(t, err)
+}
+
+func TestMinimal_Diff(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela-statediff")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	pathA := filepath.Join(dir, "a.json")
+	pathB := filepath.Join(dir, "b.json")
+
+	a := statediff.Export{Root: "aa", Entries: []statediff.Entry{{Key: "01", Value: "aa"}}}
+	require.NoError(t, a.Save(pathA))
+
+	b := statediff.Export{Root: "bb", Entries: []statediff.Entry{{Key: "01", Value: "bb"}}}
+	require.NoError(t, b.Save(pathB))
+
+	out := new(bytes.Buffer)
+	m := minimal{out: out}
+
+	err = m.diff(node.FlagSet{"a": pathA, "b": pathB})
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "key 01\n")
+	require.Contains(t, out.String(), "1 keys differ\n")
+
+	err = m.diff(node.FlagSet{"a": filepath.Join(dir, "c.json"), "b": pathB})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "export A: failed to read file: ")
+
+	err = m.diff(node.FlagSet{"a": pathA, "b": filepath.Join(dir, "c.json")})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "export B: failed to read file: ")
+}
+
+func TestExportAction_Execute(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela-statediff")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	ctx := node.Context{
+		Injector:   node.NewInjector(),
+		Flags:      node.FlagSet{"path": filepath.Join(dir, "state.json")},
+		Out:        new(bytes.Buffer),
+		Operations: node.NewOperations(),
+	}
+
+	err = exportAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'diff.Journal'")
+
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	ctx.Injector.Inject(diff.NewJournal(db))
+
+	err = exportAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'blockstore.BlockStore'")
+
+	ctx.Injector.Inject(blockstore.NewInMemory())
+
+	err = exportAction{}.Execute(ctx)
+	require.EqualError(t, err, "failed to export: journal is empty")
+}
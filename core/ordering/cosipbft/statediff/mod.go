@@ -0,0 +1,328 @@
+// Package statediff implements the export of the state of a node and the
+// comparison of two exports, to investigate why two nodes disagree on the
+// root of the state.
+//
+// An export is made of the keys and values of the state, rebuilt from the
+// journal of the changes, with the height of the block that last wrote each
+// key. The blocks of those heights, and their accepted transactions, are part
+// of the export so that a difference points at the writes that have diverged.
+// The journal must be attached to the store since the creation of the chain,
+// as it is for the nodes started with the ordering controller.
+package statediff
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/store/diff"
+	"golang.org/x/xerrors"
+)
+
+// Journal is the interface of the journal of the changes of the state.
+type Journal interface {
+	// Len returns the number of heights recorded by the journal.
+	Len() (uint64, error)
+
+	// Stream calls the function for every change between the two heights.
+	Stream(from, to uint64, fn func(diff.Change) error) error
+}
+
+// Entry is a key of the state with its value, in hexadecimal, and the height
+// that has last written it.
+type Entry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Height uint64 `json:"height"`
+}
+
+// Transaction is an accepted transaction of a block.
+type Transaction struct {
+	ID       string `json:"id"`
+	Contract string `json:"contract,omitempty"`
+}
+
+// Block is the block of a height of the journal. The block at the index i is
+// the height i+1, as the genesis is the height 0.
+type Block struct {
+	Height       uint64        `json:"height"`
+	ID           string        `json:"id"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+// Export is the state of a node at a given height. The entries are sorted by
+// key, and the blocks by height.
+type Export struct {
+	Height  uint64  `json:"height"`
+	Root    string  `json:"root"`
+	Entries []Entry `json:"entries"`
+	Blocks  []Block `json:"blocks"`
+}
+
+// ExportOption is the type of option to configure an export.
+type ExportOption func(*exportTemplate)
+
+type exportTemplate struct {
+	progress func(done, total uint64)
+}
+
+// WithProgress is an option to receive the number of heights of the journal
+// that are read out of the total.
+func WithProgress(fn func(done, total uint64)) ExportOption {
+	return func(tmpl *exportTemplate) {
+		tmpl.progress = fn
+	}
+}
+
+// NewExport rebuilds the state from the journal and returns the export of the
+// latest height.
+func NewExport(journal Journal, blocks blockstore.BlockStore, opts ...ExportOption) (Export, error) {
+	tmpl := exportTemplate{
+		progress: func(done, total uint64) {},
+	}
+
+	for _, opt := range opts {
+		opt(&tmpl)
+	}
+
+	length, err := journal.Len()
+	if err != nil {
+		return Export{}, xerrors.Errorf("journal: %v", err)
+	}
+
+	if length == 0 {
+		return Export{}, xerrors.New("journal is empty")
+	}
+
+	state := make(map[string]Entry)
+	last := uint64(0)
+
+	err = journal.Stream(0, length-1, func(change diff.Change) error {
+		if change.Height > last {
+			last = change.Height
+			tmpl.progress(last, length)
+		}
+
+		key := hex.EncodeToString(change.Key)
+
+		if change.New == nil {
+			delete(state, key)
+		} else {
+			state[key] = Entry{
+				Key:    key,
+				Value:  hex.EncodeToString(change.New),
+				Height: change.Height,
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Export{}, xerrors.Errorf("failed to read journal: %v", err)
+	}
+
+	tmpl.progress(length, length)
+
+	export := Export{
+		Height:  length - 1,
+		Entries: make([]Entry, 0, len(state)),
+		Blocks:  []Block{},
+	}
+
+	heights := make(map[uint64]struct{})
+
+	for _, entry := range state {
+		export.Entries = append(export.Entries, entry)
+		heights[entry.Height] = struct{}{}
+	}
+
+	sort.Slice(export.Entries, func(i, j int) bool {
+		return export.Entries[i].Key < export.Entries[j].Key
+	})
+
+	if export.Height > 0 {
+		link, err := blocks.GetByIndex(export.Height - 1)
+		if err != nil {
+			return Export{}, xerrors.Errorf("block at height %d: %v", export.Height, err)
+		}
+
+		root := link.GetBlock().GetTreeRoot()
+		export.Root = hex.EncodeToString(root[:])
+	}
+
+	for height := range heights {
+		if height == 0 {
+			// The genesis has no block.
+			continue
+		}
+
+		block, err := makeBlock(blocks, height)
+		if err != nil {
+			return Export{}, xerrors.Errorf("block at height %d: %v", height, err)
+		}
+
+		export.Blocks = append(export.Blocks, block)
+	}
+
+	sort.Slice(export.Blocks, func(i, j int) bool {
+		return export.Blocks[i].Height < export.Blocks[j].Height
+	})
+
+	return export, nil
+}
+
+// Load reads the export of the file.
+func Load(path string) (Export, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Export{}, xerrors.Errorf("failed to read file: %v", err)
+	}
+
+	var export Export
+
+	err = json.Unmarshal(data, &export)
+	if err != nil {
+		return Export{}, xerrors.Errorf("failed to decode: %v", err)
+	}
+
+	return export, nil
+}
+
+// Save writes the export to the file.
+func (e Export) Save(path string) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("failed to encode: %v", err)
+	}
+
+	err = ioutil.WriteFile(path, data, 0644)
+	if err != nil {
+		return xerrors.Errorf("failed to write file: %v", err)
+	}
+
+	return nil
+}
+
+// GetBlock returns the block of the height if it is in the export.
+func (e Export) GetBlock(height uint64) (Block, bool) {
+	for _, block := range e.Blocks {
+		if block.Height == height {
+			return block, true
+		}
+	}
+
+	return Block{}, false
+}
+
+// Difference is a key that has a different value in two exports. An entry is
+// nil when the key is missing in the export.
+type Difference struct {
+	Key string
+	A   *Entry
+	B   *Entry
+}
+
+// Compare returns the differences between the two exports sorted by key.
+func Compare(a, b Export) []Difference {
+	diffs := []Difference{}
+
+	i, j := 0, 0
+
+	for i < len(a.Entries) || j < len(b.Entries) {
+		switch {
+		case j >= len(b.Entries) || (i < len(a.Entries) && a.Entries[i].Key < b.Entries[j].Key):
+			diffs = append(diffs, Difference{Key: a.Entries[i].Key, A: &a.Entries[i]})
+			i++
+		case i >= len(a.Entries) || b.Entries[j].Key < a.Entries[i].Key:
+			diffs = append(diffs, Difference{Key: b.Entries[j].Key, B: &b.Entries[j]})
+			j++
+		default:
+			if a.Entries[i].Value != b.Entries[j].Value {
+				diffs = append(diffs, Difference{
+					Key: a.Entries[i].Key,
+					A:   &a.Entries[i],
+					B:   &b.Entries[j],
+				})
+			}
+
+			i++
+			j++
+		}
+	}
+
+	return diffs
+}
+
+// Fprint writes the report of the differences between the two exports.
+func Fprint(w io.Writer, a, b Export) {
+	fmt.Fprintf(w, "A: height %d, root %s, %d keys\n", a.Height, a.Root, len(a.Entries))
+	fmt.Fprintf(w, "B: height %d, root %s, %d keys\n", b.Height, b.Root, len(b.Entries))
+
+	diffs := Compare(a, b)
+
+	for _, d := range diffs {
+		fmt.Fprintf(w, "key %s\n", d.Key)
+		fprintEntry(w, "A", a, d.A)
+		fprintEntry(w, "B", b, d.B)
+	}
+
+	fmt.Fprintf(w, "%d keys differ\n", len(diffs))
+}
+
+func fprintEntry(w io.Writer, name string, export Export, entry *Entry) {
+	if entry == nil {
+		fmt.Fprintf(w, "  %s: missing\n", name)
+		return
+	}
+
+	fmt.Fprintf(w, "  %s: %s\n", name, entry.Value)
+
+	block, found := export.GetBlock(entry.Height)
+	if !found {
+		fmt.Fprintf(w, "     written at height %d\n", entry.Height)
+		return
+	}
+
+	fmt.Fprintf(w, "     written by block %s at height %d\n", block.ID, block.Height)
+
+	for _, tx := range block.Transactions {
+		fmt.Fprintf(w, "       - transaction %s %s\n", tx.ID, tx.Contract)
+	}
+}
+
+func makeBlock(blocks blockstore.BlockStore, height uint64) (Block, error) {
+	link, err := blocks.GetByIndex(height - 1)
+	if err != nil {
+		return Block{}, err
+	}
+
+	id := link.GetBlock().GetHash()
+
+	block := Block{
+		Height:       height,
+		ID:           hex.EncodeToString(id[:]),
+		Transactions: []Transaction{},
+	}
+
+	for _, res := range link.GetBlock().GetData().GetTransactionResults() {
+		accepted, _ := res.GetStatus()
+		if !accepted {
+			continue
+		}
+
+		tx := res.GetTransaction()
+
+		block.Transactions = append(block.Transactions, Transaction{
+			ID:       hex.EncodeToString(tx.GetID()),
+			Contract: string(bytes.TrimSpace(tx.GetArg(native.ContractArg))),
+		})
+	}
+
+	return block, nil
+}
@@ -0,0 +1,244 @@
+package statediff
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering/cosipbft/blockstore"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/store/diff"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"golang.org/x/xerrors"
+)
+
+func TestNewExport(t *testing.T) {
+	blocks := blockstore.NewInMemory()
+	storeLink(t, blocks, 0)
+	storeLink(t, blocks, 1)
+
+	journal := fakeJournal{
+		length: 3,
+		changes: []diff.Change{
+			{Height: 0, Key: []byte("A"), New: []byte("1")},
+			{Height: 0, Key: []byte("B"), New: []byte("1")},
+			{Height: 1, Key: []byte("C"), New: []byte("1")},
+			{Height: 1, Key: []byte("B"), Old: []byte("1")},
+			{Height: 2, Key: []byte("A"), Old: []byte("1"), New: []byte("2")},
+		},
+	}
+
+	reports := [][2]uint64{}
+	progress := WithProgress(func(done, total uint64) {
+		reports = append(reports, [2]uint64{done, total})
+	})
+
+	export, err := NewExport(journal, blocks, progress)
+	require.NoError(t, err)
+	require.Equal(t, [][2]uint64{{1, 3}, {2, 3}, {3, 3}}, reports)
+	require.Equal(t, uint64(2), export.Height)
+	require.Len(t, export.Root, 64)
+	require.Equal(t, []Entry{
+		{Key: hex.EncodeToString([]byte("A")), Value: hex.EncodeToString([]byte("2")), Height: 2},
+		{Key: hex.EncodeToString([]byte("C")), Value: hex.EncodeToString([]byte("1")), Height: 1},
+	}, export.Entries)
+	require.Len(t, export.Blocks, 2)
+	require.Equal(t, uint64(1), export.Blocks[0].Height)
+	require.Equal(t, uint64(2), export.Blocks[1].Height)
+	require.Len(t, export.Blocks[1].Transactions, 1)
+	require.Equal(t, "value", export.Blocks[1].Transactions[0].Contract)
+
+	journal.changes = journal.changes[:2]
+	journal.length = 1
+
+	export, err = NewExport(journal, blocks)
+	require.NoError(t, err)
+	require.Equal(t, "", export.Root)
+	require.Empty(t, export.Blocks)
+
+	_, err = NewExport(fakeJournal{}, blocks)
+	require.EqualError(t, err, "journal is empty")
+
+	_, err = NewExport(fakeJournal{err: xerrors.New("oops")}, blocks)
+	require.EqualError(t, err, "journal: oops")
+
+	_, err = NewExport(fakeJournal{length: 1, errStream: xerrors.New("oops")}, blocks)
+	require.EqualError(t, err, "failed to read journal: oops")
+
+	_, err = NewExport(fakeJournal{length: 4}, blocks)
+	require.EqualError(t, err, "block at height 3: block not found: no block")
+
+	journal = fakeJournal{
+		length:  2,
+		changes: []diff.Change{{Height: 1, Key: []byte("A"), New: []byte("1")}},
+	}
+
+	_, err = NewExport(journal, blockstore.NewInMemory())
+	require.EqualError(t, err, "block at height 1: block not found: no block")
+}
+
+func TestExport_SaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela-statediff")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state.json")
+
+	export := Export{
+		Height:  1,
+		Root:    "ab",
+		Entries: []Entry{{Key: "aa", Value: "bb", Height: 1}},
+		Blocks:  []Block{{Height: 1, ID: "cc", Transactions: []Transaction{{ID: "dd"}}}},
+	}
+
+	err = export.Save(path)
+	require.NoError(t, err)
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, export, loaded)
+
+	err = export.Save(filepath.Join(dir, "unknown", "state.json"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to write file: ")
+
+	_, err = Load(filepath.Join(dir, "unknown.json"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read file: ")
+
+	err = ioutil.WriteFile(path, []byte("{"), 0600)
+	require.NoError(t, err)
+
+	_, err = Load(path)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to decode: ")
+}
+
+func TestCompare(t *testing.T) {
+	a := Export{Entries: []Entry{
+		{Key: "01", Value: "aa"},
+		{Key: "02", Value: "aa"},
+		{Key: "04", Value: "aa"},
+	}}
+
+	b := Export{Entries: []Entry{
+		{Key: "02", Value: "aa"},
+		{Key: "03", Value: "aa"},
+		{Key: "04", Value: "bb"},
+		{Key: "05", Value: "bb"},
+	}}
+
+	require.Equal(t, []Difference{
+		{Key: "01", A: &a.Entries[0]},
+		{Key: "03", B: &b.Entries[1]},
+		{Key: "04", A: &a.Entries[2], B: &b.Entries[2]},
+		{Key: "05", B: &b.Entries[3]},
+	}, Compare(a, b))
+
+	require.Empty(t, Compare(a, a))
+}
+
+func TestFprint(t *testing.T) {
+	a := Export{
+		Height:  2,
+		Root:    "aa",
+		Entries: []Entry{{Key: "01", Value: "aa", Height: 2}},
+		Blocks: []Block{{
+			Height:       2,
+			ID:           "ff",
+			Transactions: []Transaction{{ID: "ee", Contract: "value"}},
+		}},
+	}
+
+	b := Export{
+		Height:  2,
+		Root:    "bb",
+		Entries: []Entry{{Key: "01", Value: "bb", Height: 1}, {Key: "02", Value: "aa"}},
+	}
+
+	out := new(bytes.Buffer)
+	Fprint(out, a, b)
+
+	require.Equal(t, "A: height 2, root aa, 1 keys\n"+
+		"B: height 2, root bb, 2 keys\n"+
+		"key 01\n"+
+		"  A: aa\n"+
+		"     written by block ff at height 2\n"+
+		"       - transaction ee value\n"+
+		"  B: bb\n"+
+		"     written at height 1\n"+
+		"key 02\n"+
+		"  A: missing\n"+
+		"  B: aa\n"+
+		"     written at height 0\n"+
+		"2 keys differ\n", out.String())
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeJournal struct {
+	length    uint64
+	changes   []diff.Change
+	err       error
+	errStream error
+}
+
+func (j fakeJournal) Len() (uint64, error) {
+	return j.length, j.err
+}
+
+func (j fakeJournal) Stream(from, to uint64, fn func(diff.Change) error) error {
+	if j.errStream != nil {
+		return j.errStream
+	}
+
+	for _, change := range j.changes {
+		if change.Height >= from && change.Height <= to {
+			err := fn(change)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// storeLink stores a block with two transactions of the value contract, the
+// second one being refused.
+func storeLink(t *testing.T, blocks blockstore.BlockStore, index uint64) {
+	results := []simple.TransactionResult{}
+
+	for i := 0; i < 2; i++ {
+		tx, err := signed.NewTransaction(index*2+uint64(i), fake.PublicKey{},
+			signed.WithArg(native.ContractArg, []byte("value")))
+		require.NoError(t, err)
+
+		results = append(results, simple.NewTransactionResult(tx, i == 0, ""))
+	}
+
+	block, err := types.NewBlock(simple.NewResult(results), types.WithIndex(index))
+	require.NoError(t, err)
+
+	prev := types.Digest{}
+	if index > 0 {
+		last, err := blocks.Last()
+		require.NoError(t, err)
+
+		prev = last.GetTo()
+	}
+
+	link, err := types.NewBlockLink(prev, block)
+	require.NoError(t, err)
+
+	require.NoError(t, blocks.Store(link))
+}
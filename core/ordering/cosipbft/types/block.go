@@ -14,6 +14,7 @@ import (
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
@@ -51,13 +52,16 @@ func (d Digest) Bytes() []byte {
 }
 
 // Genesis is the very first block of a chain. It contains the initial roster
-// and tree root.
+// and tree root, and the version of the fingerprint of the roster that defines
+// its digest.
 //
 // - implements serde.Message
 type Genesis struct {
 	digest   Digest
+	other    Digest
 	roster   authority.Authority
 	treeRoot Digest
+	version  authority.FingerprintVersion
 }
 
 type genesisTemplate struct {
@@ -75,6 +79,14 @@ func WithGenesisRoot(root Digest) GenesisOption {
 	}
 }
 
+// WithGenesisFingerprint is an option to set the version of the fingerprint of
+// the roster. The first version is used by default.
+func WithGenesisFingerprint(version authority.FingerprintVersion) GenesisOption {
+	return func(tmpl *genesisTemplate) {
+		tmpl.version = version
+	}
+}
+
 // WithGenesisHashFactory is an option to set the hash factory.
 func WithGenesisHashFactory(fac crypto.HashFactory) GenesisOption {
 	return func(tmpl *genesisTemplate) {
@@ -88,6 +100,7 @@ func NewGenesis(ro authority.Authority, opts ...GenesisOption) (Genesis, error)
 		Genesis: Genesis{
 			roster:   ro,
 			treeRoot: Digest{},
+			version:  authority.FingerprintV1,
 		},
 		hashFactory: crypto.NewSha256Factory(),
 	}
@@ -96,22 +109,57 @@ func NewGenesis(ro authority.Authority, opts ...GenesisOption) (Genesis, error)
 		opt(&tmpl)
 	}
 
-	h := tmpl.hashFactory.New()
-	err := tmpl.Fingerprint(h)
+	err := tmpl.hash(tmpl.version, &tmpl.digest)
 	if err != nil {
 		return tmpl.Genesis, xerrors.Errorf("fingerprint failed: %v", err)
 	}
 
-	copy(tmpl.digest[:], h.Sum(nil))
+	// The digest in the other version is kept so that a chain is verified
+	// during the transition from one version to the other.
+	other := authority.FingerprintV2
+	if tmpl.version == authority.FingerprintV2 {
+		other = authority.FingerprintV1
+	}
+
+	err = tmpl.hash(other, &tmpl.other)
+	if err != nil {
+		return tmpl.Genesis, xerrors.Errorf("fingerprint failed: %v", err)
+	}
 
 	return tmpl.Genesis, nil
 }
 
+func (tmpl genesisTemplate) hash(version authority.FingerprintVersion, digest *Digest) error {
+	h := tmpl.hashFactory.New()
+
+	err := tmpl.fingerprint(h, version)
+	if err != nil {
+		return err
+	}
+
+	copy(digest[:], h.Sum(nil))
+
+	return nil
+}
+
 // GetHash returns the digest of the block.
 func (g Genesis) GetHash() Digest {
 	return g.digest
 }
 
+// Match returns true when the digest is the one of the genesis block in any of
+// the versions of the fingerprint, so that a chain created by the participants
+// of a version is verified with the genesis block of the other.
+func (g Genesis) Match(digest Digest) bool {
+	return digest == g.digest || digest == g.other
+}
+
+// GetFingerprintVersion returns the version of the fingerprint of the roster
+// that defines the digest of the genesis block.
+func (g Genesis) GetFingerprintVersion() authority.FingerprintVersion {
+	return g.version
+}
+
 // GetRoster returns the roster of the genesis block.
 func (g Genesis) GetRoster() authority.Authority {
 	return g.roster
@@ -136,14 +184,19 @@ func (g Genesis) Serialize(ctx serde.Context) ([]byte, error) {
 }
 
 // Fingerprint implements serde.Fingerprinter. It deterministically writes a
-// binary representation of the genesis block into the writer.
+// binary representation of the genesis block into the writer, with the version
+// of the fingerprint of the roster of the genesis block.
 func (g Genesis) Fingerprint(w io.Writer) error {
+	return g.fingerprint(w, g.version)
+}
+
+func (g Genesis) fingerprint(w io.Writer, version authority.FingerprintVersion) error {
 	_, err := w.Write(g.treeRoot[:])
 	if err != nil {
 		return xerrors.Errorf("couldn't write root: %v", err)
 	}
 
-	err = g.roster.Fingerprint(w)
+	err = authority.Fingerprint(w, g.roster, version)
 	if err != nil {
 		return xerrors.Errorf("roster fingerprint failed: %v", err)
 	}
@@ -185,7 +238,8 @@ func (f GenesisFactory) Deserialize(ctx serde.Context, data []byte) (serde.Messa
 
 // Block is a block of a chain. It holds an index which is the height of the
 // block from the genesis block, the Merkle tree root and the validation result
-// of the transactions.
+// of the transactions. It can also hold the proof of the verifiable random
+// function of its proposer that elects the leader of the next round.
 //
 // - implements serde.Message
 type Block struct {
@@ -193,6 +247,7 @@ type Block struct {
 	index    uint64
 	data     validation.Result
 	treeRoot Digest
+	proof    vrf.Proof
 }
 
 type blockTemplate struct {
@@ -217,6 +272,14 @@ func WithTreeRoot(root Digest) BlockOption {
 	}
 }
 
+// WithProof is an option to set the proof of the election of the leader of the
+// next round.
+func WithProof(proof vrf.Proof) BlockOption {
+	return func(tmpl *blockTemplate) {
+		tmpl.proof = proof
+	}
+}
+
 // WithHashFactory is an option to set the hash factory for the block.
 func WithHashFactory(fac crypto.HashFactory) BlockOption {
 	return func(tmpl *blockTemplate) {
@@ -282,8 +345,18 @@ func (b Block) GetTreeRoot() Digest {
 	return b.treeRoot
 }
 
+// GetProof returns the proof of the election of the leader of the next round
+// and true if the block has one, otherwise it returns false.
+func (b Block) GetProof() (vrf.Proof, bool) {
+	data, _ := b.proof.MarshalBinary()
+
+	return b.proof, len(data) > 0
+}
+
 // Fingerprint implements serde.Fingerprinter. It deterministically writes a
-// binary representation of the block into the writer.
+// binary representation of the block into the writer. The proof is only
+// written when it is defined so that the digest of a block without a proof
+// stays the same.
 func (b Block) Fingerprint(w io.Writer) error {
 	buffer := make([]byte, 8)
 	binary.LittleEndian.PutUint64(buffer, b.index)
@@ -302,6 +375,19 @@ func (b Block) Fingerprint(w io.Writer) error {
 		return xerrors.Errorf("data fingerprint failed: %v", err)
 	}
 
+	proof, found := b.GetProof()
+	if found {
+		data, err := proof.MarshalBinary()
+		if err != nil {
+			return xerrors.Errorf("couldn't marshal proof: %v", err)
+		}
+
+		_, err = w.Write(data)
+		if err != nil {
+			return xerrors.Errorf("couldn't write proof: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -11,6 +11,7 @@ import (
 	"go.dedis.ch/dela/core/txn/signed"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto/vrf"
 	"go.dedis.ch/dela/internal/testing/fake"
 )
 
@@ -92,6 +93,38 @@ func TestGenesis_Fingerprint(t *testing.T) {
 	genesis.roster = badRoster{}
 	err = genesis.Fingerprint(buffer)
 	require.EqualError(t, err, fake.Err("roster fingerprint failed"))
+
+	_, err = NewGenesis(ro, WithGenesisFingerprint(authority.FingerprintVersion(3)))
+	require.EqualError(t, err, "fingerprint failed: roster fingerprint failed: "+
+		"unknown fingerprint version 3")
+
+	_, err = NewGenesis(ro, WithGenesisHashFactory(fake.NewHashFactory(fake.NewBadHashWithDelay(3))))
+	require.EqualError(t, err, fake.Err("fingerprint failed: couldn't write root"))
+}
+
+func TestGenesis_FingerprintV2(t *testing.T) {
+	ro := authority.FromAuthority(fake.NewAuthority(1, fake.NewSigner))
+
+	genesis, err := NewGenesis(ro, WithGenesisRoot(Digest{5}),
+		WithGenesisFingerprint(authority.FingerprintV2))
+	require.NoError(t, err)
+	require.Equal(t, authority.FingerprintV2, genesis.GetFingerprintVersion())
+
+	buffer := new(bytes.Buffer)
+	err = genesis.Fingerprint(buffer)
+	require.NoError(t, err)
+	require.Regexp(t, "^\x05(\x00){31}\x0e\x00\x00\x00dela:roster:v2", buffer.String())
+
+	legacy, err := NewGenesis(ro, WithGenesisRoot(Digest{5}))
+	require.NoError(t, err)
+	require.Equal(t, authority.FingerprintV1, legacy.GetFingerprintVersion())
+	require.NotEqual(t, legacy.GetHash(), genesis.GetHash())
+
+	// Both versions of the genesis block are accepted during the transition.
+	require.True(t, genesis.Match(legacy.GetHash()))
+	require.True(t, genesis.Match(genesis.GetHash()))
+	require.True(t, legacy.Match(genesis.GetHash()))
+	require.False(t, genesis.Match(Digest{}))
 }
 
 func TestGenesisFactory_Deserialize(t *testing.T) {
@@ -137,6 +170,27 @@ func TestBlock_GetTreeRoot(t *testing.T) {
 	require.Equal(t, Digest{3}, block.GetTreeRoot())
 }
 
+func TestBlock_GetProof(t *testing.T) {
+	block := Block{}
+
+	_, found := block.GetProof()
+	require.False(t, found)
+
+	proof, err := vrf.NewSigner().Prove([]byte{1})
+	require.NoError(t, err)
+
+	block, err = NewBlock(simple.NewResult(nil), WithProof(proof))
+	require.NoError(t, err)
+
+	other, found := block.GetProof()
+	require.True(t, found)
+	require.True(t, proof.Equal(other))
+
+	empty, err := NewBlock(simple.NewResult(nil))
+	require.NoError(t, err)
+	require.NotEqual(t, empty.GetHash(), block.GetHash())
+}
+
 func TestBlock_Fingerprint(t *testing.T) {
 	block := Block{
 		index:    3,
@@ -162,6 +216,14 @@ func TestBlock_Fingerprint(t *testing.T) {
 
 	_, err = NewBlock(block.data, WithHashFactory(fake.NewHashFactory(fake.NewBadHash())))
 	require.EqualError(t, err, fake.Err("fingerprint failed: couldn't write index"))
+
+	proof, err := vrf.NewSigner().Prove([]byte{1})
+	require.NoError(t, err)
+
+	block.data = simple.NewResult(nil)
+	block.proof = proof
+	err = block.Fingerprint(fake.NewBadHashWithDelay(2))
+	require.EqualError(t, err, fake.Err("couldn't write proof"))
 }
 
 func TestBlock_Serialize(t *testing.T) {
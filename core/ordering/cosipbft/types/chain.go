@@ -327,7 +327,13 @@ func (c chain) Verify(genesis Genesis, fac crypto.VerifierFactory) error {
 
 	prev := genesis.GetHash()
 
-	for _, link := range c.GetLinks() {
+	for i, link := range c.GetLinks() {
+		// The first link can point at the digest of the genesis block in any
+		// version of the fingerprint of the roster.
+		if i == 0 && genesis.Match(link.GetFrom()) {
+			prev = link.GetFrom()
+		}
+
 		// It makes sure that the chain of links is consistent.
 		if prev != link.GetFrom() {
 			return xerrors.Errorf("mismatch from: '%v' != '%v'", link.GetFrom(), prev)
@@ -7,6 +7,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/hybrid"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/serde"
 )
@@ -211,6 +213,12 @@ func TestChain_Verify(t *testing.T) {
 	err = c.Verify(genesis, fake.VerifierFactory{})
 	require.NoError(t, err)
 
+	// The chain of a genesis block in the other version of the fingerprint is
+	// accepted during the transition.
+	c = NewChain(makeLink(t, genesis.other), nil)
+	err = c.Verify(genesis, fake.VerifierFactory{})
+	require.NoError(t, err)
+
 	c = NewChain(makeLink(t, Digest{}), nil)
 	err = c.Verify(genesis, fake.VerifierFactory{})
 	require.EqualError(t, err, fmt.Sprintf("mismatch from: '00000000' != '%v'", genesis.GetHash()))
@@ -245,6 +253,41 @@ func TestChain_Verify(t *testing.T) {
 	require.EqualError(t, err, fake.Err("invalid commit signature"))
 }
 
+func TestChain_Hybrid_Verify(t *testing.T) {
+	ca := fake.NewAuthority(3, func() crypto.Signer { return hybrid.NewSigner() })
+
+	genesis, err := NewGenesis(authority.FromAuthority(ca))
+	require.NoError(t, err)
+
+	link, err := NewForwardLink(genesis.digest, Digest{1})
+	require.NoError(t, err)
+
+	prepare := makeHybridSignature(t, ca, link.GetHash().Bytes())
+
+	msg, err := prepare.MarshalBinary()
+	require.NoError(t, err)
+
+	commit := makeHybridSignature(t, ca, msg)
+
+	fl := link.(forwardLink)
+	fl.prepareSig = prepare
+	fl.commitSig = commit
+
+	fac := hybrid.NewSigner().GetVerifierFactory()
+
+	c := NewChain(blockLink{forwardLink: fl}, nil)
+	err = c.Verify(genesis, fac)
+	require.NoError(t, err)
+
+	// A link with only the classical signature is refused.
+	fl.prepareSig = prepare.(hybrid.Signature).GetClassical()
+
+	c = NewChain(blockLink{forwardLink: fl}, nil)
+	err = c.Verify(genesis, fac)
+	require.EqualError(t, err,
+		"invalid prepare signature: invalid signature type 'bls.Signature'")
+}
+
 func TestChain_Serialize(t *testing.T) {
 	chain := chain{}
 
@@ -273,6 +316,22 @@ func TestChainFactory_Deserialize(t *testing.T) {
 // -----------------------------------------------------------------------------
 // Utility functions
 
+func makeHybridSignature(t *testing.T, ca fake.CollectiveAuthority, msg []byte) crypto.Signature {
+	sigs := make([]crypto.Signature, ca.Len())
+
+	for i := range sigs {
+		sig, err := ca.GetSigner(i).Sign(msg)
+		require.NoError(t, err)
+
+		sigs[i] = sig
+	}
+
+	agg, err := hybrid.AggregateSignatures(sigs...)
+	require.NoError(t, err)
+
+	return agg
+}
+
 func makeLink(t *testing.T, from Digest) BlockLink {
 	link, err := NewForwardLink(from, Digest{}, WithSignatures(fake.Signature{}, fake.Signature{}))
 	require.NoError(t, err)
@@ -11,6 +11,7 @@ package ordering
 import (
 	"context"
 
+	"go.dedis.ch/dela/core/execution"
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/validation"
 )
@@ -30,6 +31,18 @@ type Event struct {
 	Transactions []validation.TransactionResult
 }
 
+// GetContractEvents returns the events emitted by the transactions of the
+// update, in the order of the transactions.
+func (e Event) GetContractEvents() []execution.Event {
+	var events []execution.Event
+
+	for _, res := range e.Transactions {
+		events = append(events, res.GetEvents()...)
+	}
+
+	return events
+}
+
 // Service is the interface of an ordering service. It provides the primitives
 // to order transactions from a pool.
 type Service interface {
@@ -43,6 +56,11 @@ type Service interface {
 	// accepted.
 	Watch(ctx context.Context) <-chan Event
 
+	// WatchFrom returns a channel populated with the events of the blocks from
+	// the index, followed by the events of the new blocks, so that a client
+	// can resume after a restart without missing any transaction.
+	WatchFrom(ctx context.Context, index uint64) <-chan Event
+
 	// Close closes the service and cleans the resources.
 	Close() error
 }
@@ -0,0 +1,38 @@
+package ordering
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/validation"
+)
+
+func TestEvent_GetContractEvents(t *testing.T) {
+	a := execution.NewEvent("contract", "a")
+	b := execution.NewEvent("contract", "b")
+
+	event := Event{
+		Transactions: []validation.TransactionResult{
+			fakeResult{events: []execution.Event{a}},
+			fakeResult{},
+			fakeResult{events: []execution.Event{b, a}},
+		},
+	}
+
+	require.Equal(t, []execution.Event{a, b, a}, event.GetContractEvents())
+	require.Empty(t, Event{}.GetContractEvents())
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeResult struct {
+	validation.TransactionResult
+
+	events []execution.Event
+}
+
+func (res fakeResult) GetEvents() []execution.Event {
+	return res.events
+}
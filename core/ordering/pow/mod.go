@@ -147,6 +147,34 @@ func (s *Service) Watch(ctx context.Context) <-chan ordering.Event {
 	return events
 }
 
+// WatchFrom implements ordering.Service. It returns a channel populated with
+// the events of the blocks from the index, followed by the events of the new
+// blocks.
+func (s *Service) WatchFrom(ctx context.Context, index uint64) <-chan ordering.Event {
+	live := s.Watch(ctx)
+
+	// The first epoch is the initial state, therefore the first block has the
+	// index 1.
+	if index == 0 {
+		index = 1
+	}
+
+	blocks := make([]Block, 0, len(s.epochs))
+	for _, epoch := range s.epochs[1:] {
+		blocks = append(blocks, epoch.block)
+	}
+
+	history := func(index uint64) (ordering.Event, bool) {
+		if index > uint64(len(blocks)) {
+			return ordering.Event{}, false
+		}
+
+		return makeEvent(blocks[index-1]), true
+	}
+
+	return ordering.Replay(ctx, index, live, history)
+}
+
 func (s *Service) createBlock(ctx context.Context) error {
 	// Wait for at least one transaction before creating a block.
 	txs := s.pool.Gather(ctx, pool.Config{Min: 1})
@@ -161,7 +189,7 @@ func (s *Service) createBlock(ctx context.Context) error {
 	var data validation.Result
 	newTrie, err := latestEpoch.store.Stage(func(rwt store.Snapshot) error {
 		var err error
-		data, err = s.validation.Validate(rwt, txs)
+		data, err = s.validation.Validate(rwt, uint64(len(s.epochs)), txs)
 		if err != nil {
 			return xerrors.Errorf("failed to validate: %v", err)
 		}
@@ -193,9 +221,16 @@ func (s *Service) createBlock(ctx context.Context) error {
 		s.pool.Remove(txres.GetTransaction())
 	}
 
-	s.watcher.Notify(ordering.Event{Index: block.index})
+	s.watcher.Notify(makeEvent(block))
 
 	dela.Logger.Trace().Uint64("index", block.index).Msg("block append")
 
 	return nil
 }
+
+func makeEvent(block Block) ordering.Event {
+	return ordering.Event{
+		Index:        block.index,
+		Transactions: block.data.GetTransactionResults(),
+	}
+}
@@ -68,6 +68,21 @@ func TestService_Basic(t *testing.T) {
 
 	evt = <-evts
 	require.Equal(t, uint64(2), evt.Index)
+
+	// 5. Replay the blocks and wait for the next one.
+	evts = srvc.WatchFrom(ctx, 0)
+
+	evt = <-evts
+	require.Equal(t, uint64(1), evt.Index)
+	require.Len(t, evt.Transactions, 1)
+
+	evt = <-evts
+	require.Equal(t, uint64(2), evt.Index)
+
+	require.NoError(t, pool.Add(makeTx(t, 2, signer)))
+
+	evt = <-evts
+	require.Equal(t, uint64(3), evt.Index)
 }
 
 func TestService_Listen(t *testing.T) {
@@ -174,7 +189,7 @@ type badValidation struct {
 	validation.Service
 }
 
-func (v badValidation) Validate(store.Snapshot, []txn.Transaction) (validation.Result, error) {
+func (v badValidation) Validate(store.Snapshot, uint64, []txn.Transaction) (validation.Result, error) {
 	return nil, fake.GetError()
 }
 
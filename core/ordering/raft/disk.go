@@ -0,0 +1,247 @@
+// This file contains the implementation of the persistent state of a member,
+// which is the current term and vote, the roster, the entries of the log, and
+// the index of the last entry applied to the tree.
+
+package raft
+
+import (
+	"encoding/binary"
+
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/raft/types"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/json"
+	"golang.org/x/xerrors"
+)
+
+var (
+	keyTerm    = []byte("term")
+	keyVote    = []byte("vote")
+	keyRoster  = []byte("roster")
+	keyApplied = []byte("applied")
+
+	// prefixEntry is the prefix of the keys of the entries, which are followed
+	// by the index in big endian so that a scan returns them in order.
+	prefixEntry = []byte("entry:")
+)
+
+// persistentState is the state of a member read from the disk.
+type persistentState struct {
+	term    uint64
+	vote    mino.Address
+	roster  authority.Authority
+	entries []types.Entry
+	applied uint64
+	height  uint64
+}
+
+// disk stores the state of a member in a bucket of the database.
+type disk struct {
+	db        kv.DB
+	bucket    []byte
+	context   serde.Context
+	addrFac   mino.AddressFactory
+	rosterFac authority.Factory
+	entryFac  types.EntryFactory
+}
+
+func newDisk(db kv.DB, addrFac mino.AddressFactory, rf authority.Factory, ef types.EntryFactory) disk {
+	return disk{
+		db:        db,
+		bucket:    []byte("raft"),
+		context:   serde.WithCompression(json.NewContext(), serde.FlateCompressor{}),
+		addrFac:   addrFac,
+		rosterFac: rf,
+		entryFac:  ef,
+	}
+}
+
+// load reads the state from the database, which is empty if the member has
+// never been set up.
+func (d disk) load() (persistentState, error) {
+	state := persistentState{}
+
+	err := d.db.View(func(tx kv.ReadableTx) error {
+		bucket := tx.GetBucket(d.bucket)
+		if bucket == nil {
+			return nil
+		}
+
+		value := bucket.Get(keyTerm)
+		if len(value) == 8 {
+			state.term = binary.BigEndian.Uint64(value)
+		}
+
+		value = bucket.Get(keyVote)
+		if len(value) > 0 {
+			state.vote = d.addrFac.FromText(value)
+		}
+
+		value = bucket.Get(keyApplied)
+		if len(value) == 16 {
+			state.applied = binary.BigEndian.Uint64(value[:8])
+			state.height = binary.BigEndian.Uint64(value[8:])
+		}
+
+		value = bucket.Get(keyRoster)
+		if len(value) > 0 {
+			roster, err := d.rosterFac.AuthorityOf(d.context, value)
+			if err != nil {
+				return xerrors.Errorf("malformed roster: %v", err)
+			}
+
+			state.roster = roster
+		}
+
+		return bucket.Scan(prefixEntry, func(key, value []byte) error {
+			// The entries written before the compression are read as is.
+			value, err := serde.Decompress(d.context, value)
+			if err != nil {
+				return xerrors.Errorf("malformed entry: %v", err)
+			}
+
+			entry, err := d.entryFac.EntryOf(d.context, value)
+			if err != nil {
+				return xerrors.Errorf("malformed entry: %v", err)
+			}
+
+			if entry.GetIndex() != uint64(len(state.entries))+1 {
+				return xerrors.Errorf("missing entry before %d", entry.GetIndex())
+			}
+
+			state.entries = append(state.entries, entry)
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return state, xerrors.Errorf("while reading: %v", err)
+	}
+
+	return state, nil
+}
+
+// saveTerm writes the current term and the vote of the member for it, which
+// can be nil.
+func (d disk) saveTerm(term uint64, vote mino.Address) error {
+	var text []byte
+	if vote != nil {
+		var err error
+		text, err = vote.MarshalText()
+		if err != nil {
+			return xerrors.Errorf("failed to marshal address: %v", err)
+		}
+	}
+
+	return d.update(func(bucket kv.Bucket) error {
+		value := make([]byte, 8)
+		binary.BigEndian.PutUint64(value, term)
+
+		err := bucket.Set(keyTerm, value)
+		if err != nil {
+			return err
+		}
+
+		if len(text) == 0 {
+			return bucket.Delete(keyVote)
+		}
+
+		return bucket.Set(keyVote, text)
+	})
+}
+
+// saveRoster writes the members of the committee.
+func (d disk) saveRoster(roster authority.Authority) error {
+	data, err := roster.Serialize(d.context)
+	if err != nil {
+		return xerrors.Errorf("failed to serialize roster: %v", err)
+	}
+
+	return d.update(func(bucket kv.Bucket) error {
+		return bucket.Set(keyRoster, data)
+	})
+}
+
+// saveEntries removes the entries from the index of the first one, and writes
+// the new ones.
+func (d disk) saveEntries(last uint64, entries ...types.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	values := make([][]byte, len(entries))
+	for i, entry := range entries {
+		data, err := entry.Serialize(d.context)
+		if err != nil {
+			return xerrors.Errorf("failed to serialize entry: %v", err)
+		}
+
+		data, err = serde.Compress(d.context, data)
+		if err != nil {
+			return xerrors.Errorf("failed to compress entry: %v", err)
+		}
+
+		values[i] = data
+	}
+
+	return d.update(func(bucket kv.Bucket) error {
+		for index := entries[0].GetIndex(); index <= last; index++ {
+			err := bucket.Delete(entryKey(index))
+			if err != nil {
+				return err
+			}
+		}
+
+		for i, entry := range entries {
+			err := bucket.Set(entryKey(entry.GetIndex()), values[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// saveApplied writes the index of the last entry applied to the tree and the
+// number of blocks, in the transaction that commits the tree.
+func (d disk) saveApplied(tx kv.WritableTx, applied, height uint64) error {
+	bucket, err := tx.GetBucketOrCreate(d.bucket)
+	if err != nil {
+		return xerrors.Errorf("bucket failed: %v", err)
+	}
+
+	value := make([]byte, 16)
+	binary.BigEndian.PutUint64(value[:8], applied)
+	binary.BigEndian.PutUint64(value[8:], height)
+
+	return bucket.Set(keyApplied, value)
+}
+
+func (d disk) update(fn func(kv.Bucket) error) error {
+	err := d.db.Update(func(tx kv.WritableTx) error {
+		bucket, err := tx.GetBucketOrCreate(d.bucket)
+		if err != nil {
+			return xerrors.Errorf("bucket failed: %v", err)
+		}
+
+		return fn(bucket)
+	})
+
+	if err != nil {
+		return xerrors.Errorf("while writing: %v", err)
+	}
+
+	return nil
+}
+
+func entryKey(index uint64) []byte {
+	key := make([]byte, len(prefixEntry)+8)
+	copy(key, prefixEntry)
+	binary.BigEndian.PutUint64(key[len(prefixEntry):], index)
+
+	return key
+}
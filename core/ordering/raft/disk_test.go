@@ -0,0 +1,145 @@
+package raft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/raft/types"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/core/validation"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestDisk_RoundTrip(t *testing.T) {
+	srvc, clean := makeService(t)
+	defer clean()
+
+	d := newDisk(srvc.db, fake.AddressFactory{},
+		authority.NewFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory()),
+		types.NewEntryFactory(nil))
+
+	state, err := d.load()
+	require.NoError(t, err)
+	require.Equal(t, persistentState{}, state)
+
+	roster := authority.FromAuthority(fake.NewAuthority(3, bls.Generate))
+
+	require.NoError(t, d.saveTerm(2, fake.NewAddress(1)))
+	require.NoError(t, d.saveRoster(roster))
+	require.NoError(t, d.saveEntries(0, types.NewEntry(1, 1), types.NewEntry(2, 1), types.NewEntry(3, 1)))
+	require.NoError(t, d.saveEntries(3, types.NewEntry(2, 2)))
+
+	err = srvc.db.Update(func(tx kv.WritableTx) error {
+		return d.saveApplied(tx, 1, 5)
+	})
+	require.NoError(t, err)
+
+	state, err = d.load()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), state.term)
+	require.Equal(t, fake.NewAddress(1), state.vote)
+	require.Equal(t, 3, state.roster.Len())
+	require.Equal(t, []types.Entry{types.NewEntry(1, 1), types.NewEntry(2, 2)}, state.entries)
+	require.Equal(t, uint64(1), state.applied)
+	require.Equal(t, uint64(5), state.height)
+
+	require.NoError(t, d.saveTerm(3, nil))
+
+	state, err = d.load()
+	require.NoError(t, err)
+	require.Nil(t, state.vote)
+}
+
+func TestDisk_Load(t *testing.T) {
+	srvc, clean := makeService(t)
+	defer clean()
+
+	d := newDisk(srvc.db, fake.AddressFactory{}, badRosterFac{}, types.NewEntryFactory(nil))
+
+	require.NoError(t, d.saveRoster(fakeRoster{}))
+
+	_, err := d.load()
+	require.EqualError(t, err, fake.Err("while reading: malformed roster"))
+
+	d.rosterFac = authority.NewFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+	require.NoError(t, d.saveRoster(authority.FromAuthority(fake.NewAuthority(1, bls.Generate))))
+	require.NoError(t, d.saveEntries(0, types.NewEntry(2, 1)))
+
+	_, err = d.load()
+	require.EqualError(t, err, "while reading: missing entry before 2")
+
+	require.NoError(t, d.update(func(bucket kv.Bucket) error {
+		return bucket.Set(entryKey(1), []byte(`[]`))
+	}))
+
+	_, err = d.load()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "while reading: malformed entry: ")
+
+	require.NoError(t, d.update(func(bucket kv.Bucket) error {
+		return bucket.Set(entryKey(1), []byte{0})
+	}))
+
+	_, err = d.load()
+	require.EqualError(t, err, "while reading: malformed entry: missing algorithm")
+}
+
+func TestDisk_Save(t *testing.T) {
+	d := newDisk(fake.NewBadDB(), fake.AddressFactory{}, nil, types.NewEntryFactory(nil))
+
+	err := d.saveTerm(1, fake.NewBadAddress())
+	require.EqualError(t, err, fake.Err("failed to marshal address"))
+
+	err = d.saveRoster(fakeRoster{err: fake.GetError()})
+	require.EqualError(t, err, fake.Err("failed to serialize roster"))
+
+	err = d.saveRoster(fakeRoster{})
+	require.EqualError(t, err, fake.Err("while writing: bucket failed"))
+
+	require.NoError(t, d.saveEntries(0))
+
+	entry := types.NewEntry(1, 1, types.WithData(fakeResult{err: fake.GetError()}, nil))
+
+	err = d.saveEntries(0, entry)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to serialize entry: ")
+
+	err = d.db.Update(func(tx kv.WritableTx) error {
+		return d.saveApplied(tx, 1, 1)
+	})
+	require.EqualError(t, err, fake.Err("bucket failed"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type badRosterFac struct {
+	authority.Factory
+}
+
+func (badRosterFac) AuthorityOf(serde.Context, []byte) (authority.Authority, error) {
+	return nil, fake.GetError()
+}
+
+type fakeRoster struct {
+	authority.Authority
+
+	err error
+}
+
+func (ro fakeRoster) Serialize(serde.Context) ([]byte, error) {
+	return []byte(`{}`), ro.err
+}
+
+type fakeResult struct {
+	validation.Result
+
+	err error
+}
+
+func (r fakeResult) Serialize(serde.Context) ([]byte, error) {
+	return nil, r.err
+}
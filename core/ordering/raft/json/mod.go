@@ -0,0 +1,254 @@
+package json
+
+import (
+	"encoding/json"
+
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/raft/types"
+	"go.dedis.ch/dela/core/validation"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	types.RegisterEntryFormat(serde.FormatJSON, entryFormat{})
+	types.RegisterMessageFormat(serde.FormatJSON, msgFormat{})
+}
+
+// EntryJSON is the JSON message for an entry of the log.
+type EntryJSON struct {
+	Index    uint64
+	Term     uint64
+	Data     json.RawMessage `json:",omitempty"`
+	TreeRoot []byte          `json:",omitempty"`
+}
+
+// SetupMessageJSON is the JSON message to start a new log.
+type SetupMessageJSON struct {
+	Roster json.RawMessage
+}
+
+// VoteRequestJSON is the JSON message to request a vote.
+type VoteRequestJSON struct {
+	Term      uint64
+	LastIndex uint64
+	LastTerm  uint64
+}
+
+// VoteReplyJSON is the JSON message to answer a vote request.
+type VoteReplyJSON struct {
+	Term    uint64
+	Granted bool
+}
+
+// AppendRequestJSON is the JSON message to replicate the entries of the log.
+type AppendRequestJSON struct {
+	Term      uint64
+	PrevIndex uint64
+	PrevTerm  uint64
+	Entries   []json.RawMessage
+	Commit    uint64
+}
+
+// AppendReplyJSON is the JSON message to answer an append request.
+type AppendReplyJSON struct {
+	Term    uint64
+	Success bool
+	Last    uint64
+}
+
+// MessageJSON is the JSON message that wraps the different kinds of messages.
+type MessageJSON struct {
+	Setup         *SetupMessageJSON  `json:",omitempty"`
+	VoteRequest   *VoteRequestJSON   `json:",omitempty"`
+	VoteReply     *VoteReplyJSON     `json:",omitempty"`
+	AppendRequest *AppendRequestJSON `json:",omitempty"`
+	AppendReply   *AppendReplyJSON   `json:",omitempty"`
+}
+
+// EntryFormat is the format engine to serialize and deserialize the entries.
+//
+// - implements serde.FormatEngine
+type entryFormat struct{}
+
+// Encode implements serde.FormatEngine. It returns the serialized data of the
+// entry if appropriate, otherwise it returns an error.
+func (entryFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	entry, ok := msg.(types.Entry)
+	if !ok {
+		return nil, xerrors.Errorf("invalid entry '%T'", msg)
+	}
+
+	m := EntryJSON{
+		Index:    entry.GetIndex(),
+		Term:     entry.GetTerm(),
+		TreeRoot: entry.GetTreeRoot(),
+	}
+
+	if !entry.IsEmpty() {
+		data, err := entry.GetData().Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to serialize data: %v", err)
+		}
+
+		m.Data = data
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the entry if appropriate,
+// otherwise it returns an error.
+func (entryFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := EntryJSON{}
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	if len(m.Data) == 0 {
+		return types.NewEntry(m.Index, m.Term), nil
+	}
+
+	factory := ctx.GetFactory(types.DataKey{})
+
+	fac, ok := factory.(validation.ResultFactory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid data factory '%T'", factory)
+	}
+
+	result, err := fac.ResultOf(ctx, m.Data)
+	if err != nil {
+		return nil, xerrors.Errorf("data factory failed: %v", err)
+	}
+
+	return types.NewEntry(m.Index, m.Term, types.WithData(result, m.TreeRoot)), nil
+}
+
+// MsgFormat is the format engine to serialize and deserialize the messages.
+//
+// - implements serde.FormatEngine
+type msgFormat struct{}
+
+// Encode implements serde.FormatEngine. It returns the serialized data of the
+// message if appropriate, otherwise it returns an error.
+func (msgFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	var m MessageJSON
+
+	switch in := msg.(type) {
+	case types.SetupMessage:
+		roster, err := in.GetRoster().Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to serialize roster: %v", err)
+		}
+
+		m.Setup = &SetupMessageJSON{Roster: roster}
+	case types.VoteRequest:
+		m.VoteRequest = &VoteRequestJSON{
+			Term:      in.GetTerm(),
+			LastIndex: in.GetLastIndex(),
+			LastTerm:  in.GetLastTerm(),
+		}
+	case types.VoteReply:
+		m.VoteReply = &VoteReplyJSON{
+			Term:    in.GetTerm(),
+			Granted: in.IsGranted(),
+		}
+	case types.AppendRequest:
+		entries := make([]json.RawMessage, 0, len(in.GetEntries()))
+		for _, entry := range in.GetEntries() {
+			data, err := entry.Serialize(ctx)
+			if err != nil {
+				return nil, xerrors.Errorf("failed to serialize entry: %v", err)
+			}
+
+			entries = append(entries, data)
+		}
+
+		m.AppendRequest = &AppendRequestJSON{
+			Term:      in.GetTerm(),
+			PrevIndex: in.GetPrevIndex(),
+			PrevTerm:  in.GetPrevTerm(),
+			Entries:   entries,
+			Commit:    in.GetCommit(),
+		}
+	case types.AppendReply:
+		m.AppendReply = &AppendReplyJSON{
+			Term:    in.GetTerm(),
+			Success: in.IsSuccess(),
+			Last:    in.GetLast(),
+		}
+	default:
+		return nil, xerrors.Errorf("unsupported message '%T'", msg)
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the message if
+// appropriate, otherwise it returns an error.
+func (msgFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := MessageJSON{}
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	switch {
+	case m.Setup != nil:
+		factory := ctx.GetFactory(types.RosterKey{})
+
+		fac, ok := factory.(authority.Factory)
+		if !ok {
+			return nil, xerrors.Errorf("invalid roster factory '%T'", factory)
+		}
+
+		roster, err := fac.AuthorityOf(ctx, m.Setup.Roster)
+		if err != nil {
+			return nil, xerrors.Errorf("roster factory failed: %v", err)
+		}
+
+		return types.NewSetupMessage(roster), nil
+	case m.VoteRequest != nil:
+		in := m.VoteRequest
+
+		return types.NewVoteRequest(in.Term, in.LastIndex, in.LastTerm), nil
+	case m.VoteReply != nil:
+		return types.NewVoteReply(m.VoteReply.Term, m.VoteReply.Granted), nil
+	case m.AppendRequest != nil:
+		in := m.AppendRequest
+
+		factory := ctx.GetFactory(types.EntryKey{})
+
+		fac, ok := factory.(types.EntryFactory)
+		if !ok {
+			return nil, xerrors.Errorf("invalid entry factory '%T'", factory)
+		}
+
+		entries := make([]types.Entry, len(in.Entries))
+		for i, raw := range in.Entries {
+			entries[i], err = fac.EntryOf(ctx, raw)
+			if err != nil {
+				return nil, xerrors.Errorf("entry factory failed: %v", err)
+			}
+		}
+
+		return types.NewAppendRequest(in.Term, in.PrevIndex, in.PrevTerm, entries, in.Commit), nil
+	case m.AppendReply != nil:
+		in := m.AppendReply
+
+		return types.NewAppendReply(in.Term, in.Success, in.Last), nil
+	}
+
+	return nil, xerrors.New("message is empty")
+}
@@ -0,0 +1,196 @@
+package json
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/raft/types"
+	"go.dedis.ch/dela/core/validation"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestEntryFormat_Encode(t *testing.T) {
+	format := entryFormat{}
+
+	ctx := fake.NewContext()
+
+	data, err := format.Encode(ctx, types.NewEntry(1, 2))
+	require.NoError(t, err)
+	require.Equal(t, `{"Index":1,"Term":2}`, string(data))
+
+	entry := types.NewEntry(3, 2, types.WithData(fakeResult{}, []byte{1}))
+
+	data, err = format.Encode(ctx, entry)
+	require.NoError(t, err)
+	require.Equal(t, `{"Index":3,"Term":2,"Data":{},"TreeRoot":"AQ=="}`, string(data))
+
+	_, err = format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "invalid entry 'fake.Message'")
+
+	_, err = format.Encode(fake.NewBadContext(), entry)
+	require.EqualError(t, err, fake.Err("failed to marshal"))
+
+	entry = types.NewEntry(3, 2, types.WithData(fakeResult{err: fake.GetError()}, nil))
+
+	_, err = format.Encode(ctx, entry)
+	require.EqualError(t, err, fake.Err("failed to serialize data"))
+}
+
+func TestEntryFormat_Decode(t *testing.T) {
+	format := entryFormat{}
+
+	ctx := fake.NewContext()
+	ctx = serde.WithFactory(ctx, types.DataKey{}, fakeResultFac{})
+
+	msg, err := format.Decode(ctx, []byte(`{"Index":1,"Term":2}`))
+	require.NoError(t, err)
+	require.Equal(t, types.NewEntry(1, 2), msg)
+
+	msg, err = format.Decode(ctx, []byte(`{"Index":3,"Term":2,"Data":{},"TreeRoot":"AQ=="}`))
+	require.NoError(t, err)
+	require.Equal(t, types.NewEntry(3, 2, types.WithData(fakeResult{}, []byte{1})), msg)
+
+	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("failed to unmarshal"))
+
+	badCtx := serde.WithFactory(ctx, types.DataKey{}, nil)
+	_, err = format.Decode(badCtx, []byte(`{"Data":{}}`))
+	require.EqualError(t, err, "invalid data factory '<nil>'")
+
+	badCtx = serde.WithFactory(ctx, types.DataKey{}, fakeResultFac{err: fake.GetError()})
+	_, err = format.Decode(badCtx, []byte(`{"Data":{}}`))
+	require.EqualError(t, err, fake.Err("data factory failed"))
+}
+
+func TestMsgFormat_RoundTrip(t *testing.T) {
+	format := msgFormat{}
+
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+	ctx = serde.WithFactory(ctx, types.RosterKey{}, fakeRosterFac{})
+	ctx = serde.WithFactory(ctx, types.EntryKey{}, types.NewEntryFactory(fakeResultFac{}))
+
+	msgs := []serde.Message{
+		types.NewSetupMessage(fakeRoster{}),
+		types.NewVoteRequest(3, 2, 1),
+		types.NewVoteReply(3, true),
+		types.NewAppendRequest(4, 3, 2, []types.Entry{
+			types.NewEntry(4, 4),
+			types.NewEntry(5, 4, types.WithData(fakeResult{}, []byte{1})),
+		}, 1),
+		types.NewAppendRequest(4, 3, 2, nil, 1),
+		types.NewAppendReply(2, true, 5),
+	}
+
+	for _, msg := range msgs {
+		data, err := format.Encode(ctx, msg)
+		require.NoError(t, err)
+
+		decoded, err := format.Decode(ctx, data)
+		require.NoError(t, err)
+
+		if req, ok := msg.(types.AppendRequest); ok {
+			require.Equal(t, req.GetEntries(), decoded.(types.AppendRequest).GetEntries())
+			continue
+		}
+
+		require.Equal(t, msg, decoded)
+	}
+}
+
+func TestMsgFormat_Encode(t *testing.T) {
+	format := msgFormat{}
+
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+
+	_, err := format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message 'fake.Message'")
+
+	_, err = format.Encode(fake.NewBadContext(), types.NewVoteReply(1, true))
+	require.EqualError(t, err, fake.Err("failed to marshal"))
+
+	_, err = format.Encode(ctx, types.NewSetupMessage(fakeRoster{err: fake.GetError()}))
+	require.EqualError(t, err, fake.Err("failed to serialize roster"))
+
+	entry := types.NewEntry(1, 1, types.WithData(fakeResult{err: fake.GetError()}, nil))
+
+	_, err = format.Encode(ctx, types.NewAppendRequest(1, 0, 0, []types.Entry{entry}, 0))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to serialize entry: ")
+}
+
+func TestMsgFormat_Decode(t *testing.T) {
+	format := msgFormat{}
+
+	ctx := fake.NewContext()
+
+	_, err := format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("failed to unmarshal"))
+
+	_, err = format.Decode(ctx, []byte(`{}`))
+	require.EqualError(t, err, "message is empty")
+
+	_, err = format.Decode(ctx, []byte(`{"Setup":{}}`))
+	require.EqualError(t, err, "invalid roster factory '<nil>'")
+
+	badCtx := serde.WithFactory(ctx, types.RosterKey{}, fakeRosterFac{err: fake.GetError()})
+	_, err = format.Decode(badCtx, []byte(`{"Setup":{}}`))
+	require.EqualError(t, err, fake.Err("roster factory failed"))
+
+	_, err = format.Decode(ctx, []byte(`{"AppendRequest":{}}`))
+	require.EqualError(t, err, "invalid entry factory '<nil>'")
+
+	badCtx = serde.WithFactory(ctx, types.EntryKey{}, types.NewEntryFactory(fakeResultFac{}))
+	_, err = format.Decode(badCtx, []byte(`{"AppendRequest":{"Entries":[{}]}}`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "entry factory failed: ")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeResult struct {
+	validation.Result
+
+	err error
+}
+
+func (r fakeResult) Serialize(serde.Context) ([]byte, error) {
+	return []byte(`{}`), r.err
+}
+
+type fakeResultFac struct {
+	validation.ResultFactory
+
+	err error
+}
+
+func (f fakeResultFac) ResultOf(serde.Context, []byte) (validation.Result, error) {
+	return fakeResult{}, f.err
+}
+
+type fakeRoster struct {
+	authority.Authority
+
+	err error
+}
+
+func (ro fakeRoster) Serialize(serde.Context) ([]byte, error) {
+	return []byte(`{}`), ro.err
+}
+
+func (fakeRoster) Fingerprint(io.Writer) error {
+	return nil
+}
+
+type fakeRosterFac struct {
+	authority.Factory
+
+	err error
+}
+
+func (fac fakeRosterFac) AuthorityOf(serde.Context, []byte) (authority.Authority, error) {
+	return fakeRoster{}, fac.err
+}
@@ -0,0 +1,1035 @@
+// Package raft implements an ordering service using the Raft consensus
+// algorithm, for the deployments where the members of the committee trust each
+// other.
+//
+// Raft only tolerates crash faults: a majority of the members must be online
+// to order new transactions, but a member that misbehaves can corrupt the
+// chain. In exchange, a block only needs a round-trip between the leader and a
+// majority to be committed, and the blocks are not signed.
+//
+// The members elect a leader for a term when they don't hear from the previous
+// one before a randomized timeout. The leader gathers the transactions of the
+// pool, validates them on a staged tree, and appends the result with the root
+// of the tree to its log, which it replicates to the other members. An entry
+// is committed when a majority has stored it, and each member then validates
+// the transactions again and checks that it reaches the same root before it
+// commits the tree. A new leader appends an empty entry first, so that the
+// entries of the previous terms are committed.
+//
+// The roster is fixed when the service is set up. The log is stored in the
+// database and kept in memory, as it is not compacted.
+//
+// Related Papers:
+//
+// In Search of an Understandable Consensus Algorithm (2014)
+// https://raft.github.io/raft.pdf
+package raft
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/raft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/hashtree"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/pool"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/mino"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// DefaultElectionTimeout is the default minimum amount of time a member
+	// waits for the leader before it starts an election. The actual timeout is
+	// drawn between once and twice the value.
+	DefaultElectionTimeout = time.Second
+
+	// DefaultHeartbeatInterval is the default amount of time between two
+	// rounds of replication of the leader.
+	DefaultHeartbeatInterval = 100 * time.Millisecond
+
+	// maxEntries is the maximum number of entries sent in a single request.
+	maxEntries = 64
+
+	rpcName = "raft"
+)
+
+// role is the role of a member in the current term.
+type role int
+
+const (
+	follower role = iota
+	candidate
+	leader
+)
+
+// ServiceParam is the different components to provide to the service. All the
+// fields are mandatory and it will panic if any is nil.
+type ServiceParam struct {
+	Mino          mino.Mino
+	Validation    validation.Service
+	Pool          pool.Pool
+	Tree          hashtree.Tree
+	DB            kv.DB
+	RosterFactory authority.Factory
+}
+
+// Option is the type of option to configure the service.
+type Option func(*Service)
+
+// WithElectionTimeout sets the minimum amount of time a member waits for the
+// leader before it starts an election.
+func WithElectionTimeout(d time.Duration) Option {
+	return func(s *Service) {
+		s.electionTimeout = d
+	}
+}
+
+// WithHeartbeatInterval sets the amount of time between two rounds of
+// replication of the leader. It must be well below the election timeout.
+func WithHeartbeatInterval(d time.Duration) Option {
+	return func(s *Service) {
+		s.heartbeatInterval = d
+	}
+}
+
+// Service is an ordering service using Raft for the consensus.
+//
+// - implements ordering.Service
+type Service struct {
+	sync.Mutex
+
+	me      mino.Address
+	rpc     mino.RPC
+	pool    pool.Pool
+	val     validation.Service
+	db      kv.DB
+	disk    disk
+	watcher core.Observable
+	logger  zerolog.Logger
+
+	electionTimeout   time.Duration
+	heartbeatInterval time.Duration
+
+	role    role
+	term    uint64
+	vote    mino.Address
+	leader  mino.Address
+	roster  authority.Authority
+	entries []types.Entry
+	commit  uint64
+
+	// The progress of the replication of the followers, which is only used
+	// by the leader.
+	next  map[string]uint64
+	match map[string]uint64
+
+	// The state machine, which is only changed when an entry is applied.
+	tree    hashtree.Tree
+	applied uint64
+	height  uint64
+
+	started   chan struct{}
+	heartbeat chan struct{}
+	commits   chan struct{}
+	closing   chan struct{}
+	closed    sync.WaitGroup
+}
+
+// NewService starts a new ordering service. It restores the state of the
+// member from the database and follows the log if it has been set up.
+func NewService(param ServiceParam, opts ...Option) (*Service, error) {
+	entryFac := types.NewEntryFactory(param.Validation.GetFactory())
+
+	d := newDisk(param.DB, param.Mino.GetAddressFactory(), param.RosterFactory, entryFac)
+
+	state, err := d.load()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load: %v", err)
+	}
+
+	s := &Service{
+		me:                param.Mino.GetAddress(),
+		pool:              param.Pool,
+		val:               param.Validation,
+		db:                param.DB,
+		disk:              d,
+		watcher:           core.NewWatcher(),
+		logger:            dela.Logger.With().Str("addr", param.Mino.GetAddress().String()).Logger(),
+		electionTimeout:   DefaultElectionTimeout,
+		heartbeatInterval: DefaultHeartbeatInterval,
+		term:              state.term,
+		vote:              state.vote,
+		roster:            state.roster,
+		entries:           state.entries,
+		commit:            state.applied,
+		tree:              param.Tree,
+		applied:           state.applied,
+		height:            state.height,
+		started:           make(chan struct{}),
+		heartbeat:         make(chan struct{}, 1),
+		commits:           make(chan struct{}, 1),
+		closing:           make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	fac := types.NewMessageFactory(param.RosterFactory, entryFac)
+	h := mino.NewClassifiedHandler(handler{Service: s}, mino.ClassConsensus)
+
+	s.rpc, err = param.Mino.CreateRPC(rpcName, h, fac)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create rpc: %v", err)
+	}
+
+	param.Pool.AddFilter(poolFilter{srvc: s})
+
+	if s.roster != nil {
+		close(s.started)
+	}
+
+	s.closed.Add(2)
+	go s.main()
+	go s.applyEntries()
+
+	return s, nil
+}
+
+// Setup creates a new log with the members of the authority. It sends the
+// roster to every member, including the node itself, and returns an error if
+// one of them fails.
+func (s *Service) Setup(ctx context.Context, ca crypto.CollectiveAuthority) error {
+	roster := authority.FromAuthority(ca)
+
+	resps, err := s.rpc.Call(ctx, types.NewSetupMessage(roster), roster)
+	if err != nil {
+		return xerrors.Errorf("failed to call: %v", err)
+	}
+
+	for resp := range resps {
+		_, err := resp.GetMessageOrError()
+		if err != nil {
+			return xerrors.Errorf("one request failed: %v", err)
+		}
+	}
+
+	s.logger.Info().Int("roster", roster.Len()).Msg("new log has been created")
+
+	return nil
+}
+
+// GetProof implements ordering.Service. It returns the proof of the value of
+// the key in the latest state.
+func (s *Service) GetProof(key []byte) (ordering.Proof, error) {
+	path, err := s.GetStore().(hashtree.Tree).GetPath(key)
+	if err != nil {
+		return nil, xerrors.Errorf("reading path: %v", err)
+	}
+
+	return Proof{path: path}, nil
+}
+
+// GetStore implements ordering.Service. It returns the latest state.
+func (s *Service) GetStore() store.Readable {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.tree
+}
+
+// GetRoster returns the members of the committee.
+func (s *Service) GetRoster() (authority.Authority, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.roster == nil {
+		return nil, xerrors.New("service is not set up")
+	}
+
+	return s.roster, nil
+}
+
+// GetLeader returns the leader of the current term and true, or false if it is
+// not known.
+func (s *Service) GetLeader() (mino.Address, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.leader, s.leader != nil
+}
+
+// Watch implements ordering.Service. It returns a channel populated with an
+// event for each new block. The channel must be listened at all time and the
+// context must be closed when done.
+func (s *Service) Watch(ctx context.Context) <-chan ordering.Event {
+	obs := observer{ch: make(chan ordering.Event, 1)}
+
+	s.watcher.Add(obs)
+
+	go func() {
+		<-ctx.Done()
+		s.watcher.Remove(obs)
+		close(obs.ch)
+	}()
+
+	return obs.ch
+}
+
+// WatchFrom implements ordering.Service. It returns a channel populated with
+// the events of the blocks from the index, which are read from the entries
+// applied to the state, followed by the events of the new blocks. The context
+// must be closed when done.
+func (s *Service) WatchFrom(ctx context.Context, index uint64) <-chan ordering.Event {
+	live := s.Watch(ctx)
+
+	s.Lock()
+
+	// The empty entries of the leaders don't create a block.
+	events := []ordering.Event{}
+	height := uint64(0)
+	for _, entry := range s.entries {
+		if entry.GetIndex() > s.applied {
+			break
+		}
+
+		if entry.IsEmpty() {
+			continue
+		}
+
+		if height >= index {
+			events = append(events, ordering.Event{
+				Index:        height,
+				Transactions: entry.GetData().GetTransactionResults(),
+			})
+		}
+
+		height++
+	}
+
+	s.Unlock()
+
+	history := func(i uint64) (ordering.Event, bool) {
+		if i-index >= uint64(len(events)) {
+			return ordering.Event{}, false
+		}
+
+		return events[i-index], true
+	}
+
+	return ordering.Replay(ctx, index, live, history)
+}
+
+// Close implements ordering.Service. It stops the service and waits for the
+// current operations to end.
+func (s *Service) Close() error {
+	close(s.closing)
+	s.closed.Wait()
+
+	return nil
+}
+
+func (s *Service) main() {
+	defer s.closed.Done()
+
+	select {
+	case <-s.started:
+	case <-s.closing:
+		return
+	}
+
+	roster, err := s.GetRoster()
+	if err == nil {
+		err = s.pool.SetPlayers(roster)
+		if err != nil {
+			s.logger.Warn().Err(err).Msg("failed to update the pool")
+		}
+	}
+
+	s.logger.Info().Msg("node has started following the log")
+
+	for {
+		select {
+		case <-s.closing:
+			return
+		default:
+		}
+
+		s.Lock()
+		r := s.role
+		s.Unlock()
+
+		if r == leader {
+			s.lead()
+		} else {
+			s.follow()
+		}
+	}
+}
+
+// follow waits for the messages of the leader, and starts an election if
+// none arrives before the timeout.
+func (s *Service) follow() {
+	timer := time.NewTimer(s.randomTimeout())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-s.closing:
+			return
+		case <-s.heartbeat:
+			if !timer.Stop() {
+				<-timer.C
+			}
+
+			timer.Reset(s.randomTimeout())
+		case <-timer.C:
+			s.campaign()
+			return
+		}
+	}
+}
+
+// campaign starts an election for the next term and becomes the leader if a
+// majority of the members votes for the node.
+func (s *Service) campaign() {
+	s.Lock()
+
+	s.term++
+	s.role = candidate
+	s.vote = s.me
+	s.leader = nil
+
+	term := s.term
+	lastIndex, lastTerm := s.lastIndex(), s.termAt(s.lastIndex())
+	others := s.others()
+	quorum := s.quorum()
+
+	err := s.disk.saveTerm(term, s.me)
+
+	s.Unlock()
+
+	if err != nil {
+		s.logger.Err(err).Msg("failed to save the term")
+		return
+	}
+
+	s.logger.Debug().Uint64("term", term).Msg("election has started")
+
+	votes := 1
+
+	if votes < quorum {
+		ctx, cancel := s.newContext(s.electionTimeout)
+		defer cancel()
+
+		req := types.NewVoteRequest(term, lastIndex, lastTerm)
+
+		resps, err := s.rpc.Call(ctx, req, mino.NewAddresses(others...))
+		if err != nil {
+			s.logger.Warn().Err(err).Msg("failed to request the votes")
+			return
+		}
+
+		votes += s.collectVotes(ctx, resps, quorum-votes)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.role != candidate || s.term != term || votes < quorum {
+		return
+	}
+
+	s.becomeLeader()
+}
+
+// collectVotes counts the votes granted until the number of votes needed is
+// reached or the context is done.
+func (s *Service) collectVotes(ctx context.Context, resps <-chan mino.Response, needed int) int {
+	votes := 0
+
+	for votes < needed {
+		var resp mino.Response
+		var more bool
+
+		select {
+		case <-ctx.Done():
+		case resp, more = <-resps:
+		}
+
+		if !more {
+			return votes
+		}
+
+		msg, err := resp.GetMessageOrError()
+		if err != nil {
+			s.logger.Debug().Err(err).Stringer("peer", resp.GetFrom()).Msg("vote failed")
+			continue
+		}
+
+		reply, ok := msg.(types.VoteReply)
+		if !ok {
+			continue
+		}
+
+		if reply.IsGranted() {
+			votes++
+			continue
+		}
+
+		s.Lock()
+		if reply.GetTerm() > s.term {
+			s.stepDown(reply.GetTerm())
+		}
+		s.Unlock()
+	}
+
+	return votes
+}
+
+// becomeLeader makes the node the leader of the current term, and appends an
+// empty entry to commit the entries of the previous terms. The lock must be
+// held.
+func (s *Service) becomeLeader() {
+	s.role = leader
+	s.leader = s.me
+	s.next = make(map[string]uint64)
+	s.match = make(map[string]uint64)
+
+	for _, addr := range s.others() {
+		s.next[addr.String()] = s.lastIndex() + 1
+	}
+
+	err := s.appendEntries(types.NewEntry(s.lastIndex()+1, s.term))
+	if err != nil {
+		s.logger.Err(err).Msg("failed to append the first entry")
+
+		s.role = follower
+		s.leader = nil
+
+		return
+	}
+
+	s.logger.Info().Uint64("term", s.term).Msg("node is the leader")
+}
+
+// lead replicates the log of the leader at a regular interval, and proposes a
+// new entry when the previous ones are applied, until the node is not the
+// leader anymore.
+func (s *Service) lead() {
+	ticker := time.NewTicker(s.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		s.Lock()
+		isLeader := s.role == leader
+		term := s.term
+		s.Unlock()
+
+		if !isLeader {
+			return
+		}
+
+		s.propose(term)
+		s.replicate(term)
+
+		select {
+		case <-s.closing:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// propose gathers the transactions of the pool and appends the result of their
+// validation to the log, if the previous entries are applied so that the tree
+// is the one the new entry is applied to.
+func (s *Service) propose(term uint64) {
+	s.Lock()
+	last := s.lastIndex()
+	ready := s.role == leader && s.term == term && s.applied == last
+	tree, height := s.tree, s.height
+	s.Unlock()
+
+	if !ready || s.pool.Len() == 0 {
+		return
+	}
+
+	ctx, cancel := s.newContext(s.heartbeatInterval)
+	txs := s.pool.Gather(ctx, pool.Config{Min: 1})
+	cancel()
+
+	if len(txs) == 0 {
+		return
+	}
+
+	data, staged, err := s.stage(tree, height, txs)
+	if err != nil {
+		s.logger.Err(err).Msg("failed to prepare the entry")
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.role != leader || s.term != term || s.lastIndex() != last {
+		return
+	}
+
+	entry := types.NewEntry(last+1, term, types.WithData(data, staged.GetRoot()))
+
+	err = s.appendEntries(entry)
+	if err != nil {
+		s.logger.Err(err).Msg("failed to append the entry")
+	}
+}
+
+// replicate sends the missing entries to each follower, or an empty request
+// as a heartbeat, then commits the entries stored by a majority.
+func (s *Service) replicate(term uint64) {
+	s.Lock()
+
+	reqs := make(map[mino.Address]types.AppendRequest)
+	for _, addr := range s.others() {
+		reqs[addr] = s.prepareAppend(addr)
+	}
+
+	s.Unlock()
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(reqs))
+
+	for addr, req := range reqs {
+		go func(addr mino.Address, req types.AppendRequest) {
+			defer wg.Done()
+
+			s.sendAppend(term, addr, req)
+		}(addr, req)
+	}
+
+	wg.Wait()
+
+	s.Lock()
+	s.advanceCommit()
+	s.Unlock()
+}
+
+// prepareAppend returns the request with the entries the follower is missing.
+// The lock must be held.
+func (s *Service) prepareAppend(addr mino.Address) types.AppendRequest {
+	next := s.next[addr.String()]
+	if next == 0 {
+		next = 1
+	}
+
+	prev := next - 1
+
+	end := s.lastIndex()
+	if end > prev+maxEntries {
+		end = prev + maxEntries
+	}
+
+	entries := append([]types.Entry{}, s.entries[prev:end]...)
+
+	return types.NewAppendRequest(s.term, prev, s.termAt(prev), entries, s.commit)
+}
+
+// sendAppend sends the request to the follower and updates its progress from
+// the reply.
+func (s *Service) sendAppend(term uint64, addr mino.Address, req types.AppendRequest) {
+	ctx, cancel := s.newContext(s.electionTimeout)
+	defer cancel()
+
+	resps, err := s.rpc.Call(ctx, req, mino.NewAddresses(addr))
+	if err != nil {
+		s.logger.Debug().Err(err).Stringer("peer", addr).Msg("failed to replicate")
+		return
+	}
+
+	var resp mino.Response
+	var more bool
+
+	select {
+	case <-ctx.Done():
+	case resp, more = <-resps:
+	}
+
+	if !more {
+		return
+	}
+
+	msg, err := resp.GetMessageOrError()
+	if err != nil {
+		s.logger.Debug().Err(err).Stringer("peer", addr).Msg("replication failed")
+		return
+	}
+
+	reply, ok := msg.(types.AppendReply)
+	if !ok {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if reply.GetTerm() > s.term {
+		s.stepDown(reply.GetTerm())
+		return
+	}
+
+	if s.role != leader || s.term != term {
+		return
+	}
+
+	key := addr.String()
+
+	if reply.IsSuccess() {
+		if reply.GetLast() > s.match[key] {
+			s.match[key] = reply.GetLast()
+		}
+
+		s.next[key] = reply.GetLast() + 1
+
+		return
+	}
+
+	// The follower is missing entries or has conflicting ones, so the next
+	// request starts earlier in the log.
+	next := s.next[key] - 1
+	if reply.GetLast()+1 < next {
+		next = reply.GetLast() + 1
+	}
+
+	if next < 1 {
+		next = 1
+	}
+
+	s.next[key] = next
+}
+
+// advanceCommit commits the latest entry of the current term that is stored
+// by a majority, with the entries before it. The lock must be held.
+func (s *Service) advanceCommit() {
+	if s.role != leader {
+		return
+	}
+
+	quorum := s.quorum()
+
+	for index := s.lastIndex(); index > s.commit; index-- {
+		if s.termAt(index) != s.term {
+			// The entries of the previous terms are only committed by an entry
+			// of the current term.
+			return
+		}
+
+		count := 1
+		for _, match := range s.match {
+			if match >= index {
+				count++
+			}
+		}
+
+		if count >= quorum {
+			s.setCommit(index)
+			return
+		}
+	}
+}
+
+// setCommit updates the index of the last committed entry and wakes up the
+// applier. The lock must be held.
+func (s *Service) setCommit(index uint64) {
+	if index <= s.commit {
+		return
+	}
+
+	s.commit = index
+
+	select {
+	case s.commits <- struct{}{}:
+	default:
+	}
+}
+
+// stepDown makes the node a follower, and moves to the term if it is newer
+// than the current one. The lock must be held.
+func (s *Service) stepDown(term uint64) {
+	if term < s.term {
+		return
+	}
+
+	if term > s.term {
+		s.term = term
+		s.vote = nil
+		s.leader = nil
+
+		err := s.disk.saveTerm(term, nil)
+		if err != nil {
+			s.logger.Err(err).Msg("failed to save the term")
+		}
+	}
+
+	s.role = follower
+}
+
+// appendEntries replaces the entries of the log from the index of the first
+// new entry. The lock must be held.
+func (s *Service) appendEntries(entries ...types.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	first := entries[0].GetIndex()
+	if first <= s.commit {
+		return xerrors.Errorf("entry %d is already committed", first)
+	}
+
+	err := s.disk.saveEntries(s.lastIndex(), entries...)
+	if err != nil {
+		return xerrors.Errorf("failed to save: %v", err)
+	}
+
+	s.entries = append(s.entries[:first-1], entries...)
+
+	return nil
+}
+
+// applyEntries applies the committed entries to the tree, in order, each time
+// the commit index moves forward.
+func (s *Service) applyEntries() {
+	defer s.closed.Done()
+
+	for {
+		select {
+		case <-s.closing:
+			return
+		case <-s.commits:
+		}
+
+		for {
+			s.Lock()
+			if s.applied >= s.commit {
+				s.Unlock()
+				break
+			}
+
+			entry := s.entries[s.applied]
+			tree, height := s.tree, s.height
+			s.Unlock()
+
+			err := s.apply(entry, tree, height)
+			if err != nil {
+				// The entry is applied again at the next commit.
+				s.logger.Err(err).Uint64("index", entry.GetIndex()).Msg("failed to apply")
+				break
+			}
+		}
+	}
+}
+
+// apply validates the transactions of the entry on the tree and commits it if
+// it reaches the root of the entry.
+func (s *Service) apply(entry types.Entry, tree hashtree.Tree, height uint64) error {
+	if entry.IsEmpty() {
+		err := s.db.Update(func(tx kv.WritableTx) error {
+			return s.disk.saveApplied(tx, entry.GetIndex(), height)
+		})
+		if err != nil {
+			return xerrors.Errorf("failed to save: %v", err)
+		}
+
+		s.Lock()
+		s.applied = entry.GetIndex()
+		s.Unlock()
+
+		return nil
+	}
+
+	results := entry.GetData().GetTransactionResults()
+
+	txs := make([]txn.Transaction, len(results))
+	for i, res := range results {
+		txs[i] = res.GetTransaction()
+	}
+
+	// The signatures of the transactions received from the leader are
+	// verified in a single batch.
+	err := signed.VerifyTransactions(txs)
+	if err != nil {
+		return xerrors.Errorf("invalid transactions: %v", err)
+	}
+
+	_, staged, err := s.stage(tree, height, txs)
+	if err != nil {
+		return xerrors.Errorf("failed to stage: %v", err)
+	}
+
+	if !bytes.Equal(staged.GetRoot(), entry.GetTreeRoot()) {
+		return xerrors.Errorf("mismatch tree root '%x' != '%x'",
+			staged.GetRoot(), entry.GetTreeRoot())
+	}
+
+	err = s.db.Update(func(tx kv.WritableTx) error {
+		err := staged.WithTx(tx).Commit()
+		if err != nil {
+			return xerrors.Errorf("while committing tree: %v", err)
+		}
+
+		return s.disk.saveApplied(tx, entry.GetIndex(), height+1)
+	})
+	if err != nil {
+		return xerrors.Errorf("failed to commit: %v", err)
+	}
+
+	s.Lock()
+	s.tree = staged
+	s.applied = entry.GetIndex()
+	s.height = height + 1
+	s.Unlock()
+
+	for _, tx := range txs {
+		s.pool.Remove(tx)
+	}
+
+	s.watcher.Notify(ordering.Event{
+		Index:        height,
+		Transactions: results,
+	})
+
+	s.logger.Info().Uint64("index", height).Msg("block event")
+
+	return nil
+}
+
+// stage validates the transactions on a copy of the tree.
+func (s *Service) stage(tree hashtree.Tree, index uint64,
+	txs []txn.Transaction) (validation.Result, hashtree.StagingTree, error) {
+
+	var data validation.Result
+
+	staged, err := tree.Stage(func(snap store.Snapshot) error {
+		var err error
+		data, err = s.val.Validate(snap, index, txs)
+		if err != nil {
+			return xerrors.Errorf("validation failed: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, xerrors.Errorf("staging tree failed: %v", err)
+	}
+
+	return data, staged, nil
+}
+
+// lastIndex returns the index of the last entry of the log, or zero if it is
+// empty. The lock must be held.
+func (s *Service) lastIndex() uint64 {
+	return uint64(len(s.entries))
+}
+
+// termAt returns the term of the entry at the index, or zero if it doesn't
+// exist. The lock must be held.
+func (s *Service) termAt(index uint64) uint64 {
+	if index == 0 || index > s.lastIndex() {
+		return 0
+	}
+
+	return s.entries[index-1].GetTerm()
+}
+
+// others returns the members of the roster except the node. The lock must be
+// held.
+func (s *Service) others() []mino.Address {
+	if s.roster == nil {
+		return nil
+	}
+
+	addrs := make([]mino.Address, 0, s.roster.Len())
+
+	iter := s.roster.AddressIterator()
+	for iter.HasNext() {
+		addr := iter.GetNext()
+		if !addr.Equal(s.me) {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	return addrs
+}
+
+// quorum returns the number of members that make a majority of the roster.
+// The lock must be held.
+func (s *Service) quorum() int {
+	return s.roster.Len()/2 + 1
+}
+
+func (s *Service) randomTimeout() time.Duration {
+	return s.electionTimeout + time.Duration(rand.Int63n(int64(s.electionTimeout)+1))
+}
+
+// newContext returns a context that is done after the timeout, or when the
+// service is closing.
+func (s *Service) newContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+	go func() {
+		select {
+		case <-s.closing:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// signalHeartbeat resets the election timeout of the member.
+func (s *Service) signalHeartbeat() {
+	select {
+	case s.heartbeat <- struct{}{}:
+	default:
+	}
+}
+
+type observer struct {
+	ch chan ordering.Event
+}
+
+func (obs observer) NotifyCallback(event interface{}) {
+	obs.ch <- event.(ordering.Event)
+}
+
+// poolFilter is a filter to drop the transactions that the latest state would
+// refuse, like the ones with an invalid nonce.
+//
+// - implements pool.Filter
+type poolFilter struct {
+	srvc *Service
+}
+
+// Accept implements pool.Filter. It returns an error if the transaction is not
+// acceptable.
+func (f poolFilter) Accept(tx txn.Transaction, leeway validation.Leeway) error {
+	err := f.srvc.val.Accept(f.srvc.GetStore(), tx, leeway)
+	if err != nil {
+		return xerrors.Errorf("unacceptable transaction: %v", err)
+	}
+
+	return nil
+}
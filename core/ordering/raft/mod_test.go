@@ -0,0 +1,580 @@
+package raft
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/ordering"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/raft/types"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/hashtree/binprefix"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/core/txn"
+	poolimpl "go.dedis.ch/dela/core/txn/pool/gossip"
+	"go.dedis.ch/dela/core/txn/pool/mem"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/gossip"
+	"go.dedis.ch/dela/mino/minoch"
+)
+
+// Use the value to make sure the interface is correctly implemented.
+var _ ordering.Service = (*Service)(nil)
+
+func TestService_Scenario_Basic(t *testing.T) {
+	nodes, ro, clean := makeNodes(t, 3)
+	defer clean()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := nodes[0].service.Setup(ctx, ro)
+	require.NoError(t, err)
+
+	events := nodes[2].service.Watch(ctx)
+
+	for i := 0; i < 3; i++ {
+		err = nodes[i].pool.Add(makeTx(t, uint64(i), nodes[0].signer))
+		require.NoError(t, err)
+
+		evt := waitEvent(t, events)
+		require.Equal(t, uint64(i), evt.Index)
+		require.Len(t, evt.Transactions, 1)
+	}
+
+	leader := waitLeader(t, nodes)
+
+	for _, node := range nodes {
+		addr, found := node.service.GetLeader()
+		require.True(t, found)
+		require.True(t, leader.Equal(addr))
+
+		roster, err := node.service.GetRoster()
+		require.NoError(t, err)
+		require.Equal(t, 3, roster.Len())
+	}
+
+	waitApplied(t, nodes[1].service, nodes[2].service)
+
+	events = nodes[1].service.WatchFrom(ctx, 1)
+	require.Equal(t, uint64(1), waitEvent(t, events).Index)
+	require.Equal(t, uint64(2), waitEvent(t, events).Index)
+
+	root := nodes[2].service.GetStore().(storeRoot).GetRoot()
+	require.Equal(t, root, nodes[1].service.GetStore().(storeRoot).GetRoot())
+
+	proof, err := nodes[1].service.GetProof([]byte("unknown"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("unknown"), proof.GetKey())
+	require.Equal(t, root, proof.(Proof).GetRoot())
+}
+
+func TestService_Scenario_LeaderFailure(t *testing.T) {
+	nodes, ro, clean := makeNodes(t, 3)
+	defer clean()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := nodes[0].service.Setup(ctx, ro)
+	require.NoError(t, err)
+
+	events := nodes[0].service.Watch(ctx)
+
+	err = nodes[0].pool.Add(makeTx(t, 0, nodes[0].signer))
+	require.NoError(t, err)
+
+	waitEvent(t, events)
+
+	leader := waitLeader(t, nodes)
+
+	waitApplied(t, nodes[0].service, nodes[1].service, nodes[2].service)
+
+	alive := make([]testNode, 0, 2)
+	for i, node := range nodes {
+		if node.onet.GetAddress().Equal(leader) {
+			require.NoError(t, node.service.Close())
+			nodes[i].closed = true
+		} else {
+			alive = append(alive, node)
+		}
+	}
+
+	events = alive[0].service.Watch(ctx)
+
+	err = alive[1].pool.Add(makeTx(t, 1, nodes[0].signer))
+	require.NoError(t, err)
+
+	evt := waitEvent(t, events)
+	require.Equal(t, uint64(1), evt.Index)
+
+	newLeader := waitLeader(t, alive)
+	require.False(t, newLeader.Equal(leader))
+}
+
+func TestService_Scenario_Restart(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "raft")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	signer := bls.NewSigner()
+
+	node := makeNode(t, minoch.NewManager(), db)
+
+	ro := authority.New([]mino.Address{node.onet.GetAddress()}, []crypto.PublicKey{signer.GetPublicKey()})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err = node.service.Setup(ctx, ro)
+	require.NoError(t, err)
+
+	events := node.service.Watch(ctx)
+
+	err = node.pool.Add(makeTx(t, 0, signer))
+	require.NoError(t, err)
+
+	evt := waitEvent(t, events)
+	require.Equal(t, uint64(0), evt.Index)
+
+	require.NoError(t, node.service.Close())
+
+	node = makeNode(t, minoch.NewManager(), db)
+	defer node.service.Close()
+
+	require.Len(t, node.service.entries, 2)
+	require.Equal(t, uint64(2), node.service.applied)
+	require.Equal(t, uint64(1), node.service.height)
+
+	// The blocks applied before the restart are replayed.
+	evt = waitEvent(t, node.service.WatchFrom(ctx, 0))
+	require.Equal(t, uint64(0), evt.Index)
+	require.Len(t, evt.Transactions, 1)
+
+	roster, err := node.service.GetRoster()
+	require.NoError(t, err)
+	require.Equal(t, 1, roster.Len())
+
+	leader := waitLeader(t, []testNode{node})
+	require.Equal(t, node.onet.GetAddress(), leader)
+}
+
+func TestService_New(t *testing.T) {
+	param := ServiceParam{
+		Mino:       fake.Mino{},
+		Validation: simple.NewService(native.NewExecution(), signed.NewTransactionFactory()),
+		Pool:       mem.NewPool(),
+		DB:         fake.NewInMemoryDB(),
+	}
+
+	srvc, err := NewService(param, WithElectionTimeout(time.Minute), WithHeartbeatInterval(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, srvc.electionTimeout)
+	require.Equal(t, time.Second, srvc.heartbeatInterval)
+
+	_, err = srvc.GetRoster()
+	require.EqualError(t, err, "service is not set up")
+
+	_, found := srvc.GetLeader()
+	require.False(t, found)
+
+	require.NoError(t, srvc.Close())
+
+	param.DB = fake.NewBadViewDB()
+	_, err = NewService(param)
+	require.EqualError(t, err, fake.Err("failed to load: while reading"))
+}
+
+func TestService_Setup(t *testing.T) {
+	srvc := &Service{rpc: fake.NewBadRPC()}
+
+	err := srvc.Setup(context.Background(), fake.NewAuthority(3, fake.NewSigner))
+	require.EqualError(t, err, fake.Err("failed to call"))
+
+	rpc := fake.NewRPC()
+	rpc.SendResponseWithError(fake.NewAddress(1), fake.GetError())
+	rpc.Done()
+	srvc.rpc = rpc
+
+	err = srvc.Setup(context.Background(), fake.NewAuthority(3, fake.NewSigner))
+	require.EqualError(t, err, fake.Err("one request failed"))
+}
+
+func TestHandler_ProcessVote(t *testing.T) {
+	srvc, clean := makeService(t)
+	defer clean()
+
+	h := handler{Service: srvc}
+
+	candidate := fake.NewAddress(1)
+
+	_, err := h.Process(mino.Request{Address: candidate, Message: types.NewVoteRequest(1, 0, 0)})
+	require.EqualError(t, err, "service is not set up")
+
+	srvc.roster = authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+
+	reply, err := h.Process(mino.Request{Address: candidate, Message: types.NewVoteRequest(1, 0, 0)})
+	require.NoError(t, err)
+	require.Equal(t, types.NewVoteReply(1, true), reply)
+	require.Equal(t, candidate, srvc.vote)
+
+	// Only one vote per term.
+	reply, err = h.Process(mino.Request{Address: fake.NewAddress(2), Message: types.NewVoteRequest(1, 0, 0)})
+	require.NoError(t, err)
+	require.Equal(t, types.NewVoteReply(1, false), reply)
+
+	// Candidate with an outdated log.
+	srvc.entries = []types.Entry{types.NewEntry(1, 2)}
+	reply, err = h.Process(mino.Request{Address: candidate, Message: types.NewVoteRequest(3, 5, 1)})
+	require.NoError(t, err)
+	require.Equal(t, types.NewVoteReply(3, false), reply)
+	require.Nil(t, srvc.vote)
+
+	// Old term.
+	reply, err = h.Process(mino.Request{Address: candidate, Message: types.NewVoteRequest(2, 1, 2)})
+	require.NoError(t, err)
+	require.Equal(t, types.NewVoteReply(3, false), reply)
+
+	srvc.disk.db = fake.NewBadDB()
+	_, err = h.Process(mino.Request{Address: candidate, Message: types.NewVoteRequest(3, 1, 2)})
+	require.EqualError(t, err, fake.Err("failed to save vote: while writing: bucket failed"))
+
+	_, err = h.Process(mino.Request{Message: fake.Message{}})
+	require.EqualError(t, err, "unsupported message of type 'fake.Message'")
+}
+
+func TestHandler_ProcessAppend(t *testing.T) {
+	srvc, clean := makeService(t)
+	defer clean()
+
+	h := handler{Service: srvc}
+
+	from := fake.NewAddress(1)
+
+	_, err := h.Process(mino.Request{Address: from, Message: types.NewAppendRequest(1, 0, 0, nil, 0)})
+	require.EqualError(t, err, "service is not set up")
+
+	srvc.roster = authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+
+	entries := []types.Entry{types.NewEntry(1, 1), types.NewEntry(2, 1)}
+
+	reply, err := h.Process(mino.Request{Address: from, Message: types.NewAppendRequest(1, 0, 0, entries, 1)})
+	require.NoError(t, err)
+	require.Equal(t, types.NewAppendReply(1, true, 2), reply)
+	require.Equal(t, from, srvc.leader)
+	require.Equal(t, uint64(1), srvc.commit)
+	require.Len(t, srvc.entries, 2)
+
+	// A late request doesn't truncate the log.
+	reply, err = h.Process(mino.Request{Address: from, Message: types.NewAppendRequest(1, 0, 0, entries[:1], 1)})
+	require.NoError(t, err)
+	require.Equal(t, types.NewAppendReply(1, true, 1), reply)
+	require.Len(t, srvc.entries, 2)
+
+	// Conflicting entries of a new leader.
+	entries = []types.Entry{types.NewEntry(2, 2), types.NewEntry(3, 2)}
+	reply, err = h.Process(mino.Request{Address: from, Message: types.NewAppendRequest(2, 1, 1, entries, 3)})
+	require.NoError(t, err)
+	require.Equal(t, types.NewAppendReply(2, true, 3), reply)
+	require.Equal(t, uint64(2), srvc.termAt(2))
+	require.Equal(t, uint64(3), srvc.commit)
+
+	// Missing entries.
+	reply, err = h.Process(mino.Request{Address: from, Message: types.NewAppendRequest(2, 5, 2, nil, 3)})
+	require.NoError(t, err)
+	require.Equal(t, types.NewAppendReply(2, false, 3), reply)
+
+	// Mismatch of the previous entry.
+	reply, err = h.Process(mino.Request{Address: from, Message: types.NewAppendRequest(2, 3, 1, nil, 3)})
+	require.NoError(t, err)
+	require.Equal(t, types.NewAppendReply(2, false, 2), reply)
+
+	// Old term.
+	reply, err = h.Process(mino.Request{Address: from, Message: types.NewAppendRequest(1, 3, 2, nil, 3)})
+	require.NoError(t, err)
+	require.Equal(t, types.NewAppendReply(2, false, 0), reply)
+
+	// Committed entries can't be replaced.
+	entries = []types.Entry{types.NewEntry(2, 3)}
+	_, err = h.Process(mino.Request{Address: from, Message: types.NewAppendRequest(3, 1, 1, entries, 3)})
+	require.EqualError(t, err, "failed to append: entry 2 is already committed")
+}
+
+func TestHandler_ProcessSetup(t *testing.T) {
+	srvc, clean := makeService(t)
+	defer clean()
+
+	h := handler{Service: srvc}
+
+	roster := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+
+	srvc.disk.db = fake.NewBadDB()
+	_, err := h.Process(mino.Request{Message: types.NewSetupMessage(roster)})
+	require.EqualError(t, err, fake.Err("failed to save roster: while writing: bucket failed"))
+
+	srvc.disk.db = srvc.db
+	_, err = h.Process(mino.Request{Message: types.NewSetupMessage(roster)})
+	require.NoError(t, err)
+	require.Equal(t, roster, srvc.roster)
+
+	// A second setup is ignored.
+	_, err = h.Process(mino.Request{Message: types.NewSetupMessage(roster.Take(mino.IndexFilter(0)).(authority.Authority))})
+	require.NoError(t, err)
+	require.Equal(t, 3, srvc.roster.Len())
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+const testContractName = "abc"
+
+type testExec struct{}
+
+func (testExec) Execute(store.Snapshot, execution.Step) error {
+	return nil
+}
+
+// storeRoot is the part of the tree that returns the root.
+type storeRoot interface {
+	GetRoot() []byte
+}
+
+type testNode struct {
+	onet    *minoch.Minoch
+	service *Service
+	pool    *poolimpl.Pool
+	db      kv.DB
+	dbpath  string
+	signer  crypto.Signer
+	closed  bool
+}
+
+func makeService(t *testing.T) (*Service, func()) {
+	dir, err := ioutil.TempDir(os.TempDir(), "raft")
+	require.NoError(t, err)
+
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	srvc := &Service{
+		me:        fake.NewAddress(0),
+		db:        db,
+		disk:      newDisk(db, fake.AddressFactory{}, nil, types.NewEntryFactory(nil)),
+		started:   make(chan struct{}),
+		heartbeat: make(chan struct{}, 1),
+		commits:   make(chan struct{}, 1),
+	}
+
+	clean := func() {
+		require.NoError(t, db.Close())
+		require.NoError(t, os.RemoveAll(dir))
+	}
+
+	return srvc, clean
+}
+
+func makeNode(t *testing.T, manager *minoch.Manager, db kv.DB) testNode {
+	m := minoch.MustCreate(manager, "node0")
+
+	signer := bls.NewSigner()
+
+	txFac := signed.NewTransactionFactory()
+
+	pool, err := poolimpl.NewPool(gossip.NewFlat(m, txFac))
+	require.NoError(t, err)
+
+	tree := binprefix.NewMerkleTree(db, binprefix.Nonce{})
+	require.NoError(t, tree.Load())
+
+	exec := native.NewExecution()
+	exec.Set(testContractName, testExec{})
+
+	param := ServiceParam{
+		Mino:          m,
+		Validation:    simple.NewService(exec, txFac),
+		Pool:          pool,
+		Tree:          tree,
+		DB:            db,
+		RosterFactory: authority.NewFactory(m.GetAddressFactory(), bls.NewPublicKeyFactory()),
+	}
+
+	srvc, err := NewService(param, WithElectionTimeout(100*time.Millisecond),
+		WithHeartbeatInterval(20*time.Millisecond))
+	require.NoError(t, err)
+
+	return testNode{
+		onet:    m,
+		service: srvc,
+		pool:    pool,
+		db:      db,
+		signer:  signer,
+	}
+}
+
+func makeNodes(t *testing.T, n int) ([]testNode, authority.Authority, func()) {
+	manager := minoch.NewManager()
+
+	addrs := make([]mino.Address, n)
+	pubkeys := make([]crypto.PublicKey, n)
+	nodes := make([]testNode, n)
+
+	for i := 0; i < n; i++ {
+		m := minoch.MustCreate(manager, fmt.Sprintf("node%d", i))
+
+		addrs[i] = m.GetAddress()
+
+		signer := bls.NewSigner()
+		pubkeys[i] = signer.GetPublicKey()
+
+		dir, err := ioutil.TempDir(os.TempDir(), "raft")
+		require.NoError(t, err)
+
+		db, err := kv.New(filepath.Join(dir, "test.db"))
+		require.NoError(t, err)
+
+		txFac := signed.NewTransactionFactory()
+
+		pool, err := poolimpl.NewPool(gossip.NewFlat(m, txFac))
+		require.NoError(t, err)
+
+		tree := binprefix.NewMerkleTree(db, binprefix.Nonce{})
+
+		exec := native.NewExecution()
+		exec.Set(testContractName, testExec{})
+
+		param := ServiceParam{
+			Mino:          m,
+			Validation:    simple.NewService(exec, txFac),
+			Pool:          pool,
+			Tree:          tree,
+			DB:            db,
+			RosterFactory: authority.NewFactory(m.GetAddressFactory(), bls.NewPublicKeyFactory()),
+		}
+
+		srvc, err := NewService(param, WithElectionTimeout(200*time.Millisecond),
+			WithHeartbeatInterval(20*time.Millisecond))
+		require.NoError(t, err)
+
+		nodes[i] = testNode{
+			onet:    m,
+			service: srvc,
+			pool:    pool,
+			db:      db,
+			dbpath:  dir,
+			signer:  signer,
+		}
+	}
+
+	clean := func() {
+		for _, node := range nodes {
+			if !node.closed {
+				require.NoError(t, node.service.Close())
+			}
+
+			require.NoError(t, node.db.Close())
+			require.NoError(t, os.RemoveAll(node.dbpath))
+		}
+	}
+
+	return nodes, authority.New(addrs, pubkeys), clean
+}
+
+func makeTx(t *testing.T, nonce uint64, signer crypto.Signer) txn.Transaction {
+	opts := []signed.TransactionOption{
+		signed.WithArg(native.ContractArg, []byte(testContractName)),
+	}
+
+	tx, err := signed.NewTransaction(nonce, signer.GetPublicKey(), opts...)
+	require.NoError(t, err)
+
+	require.NoError(t, tx.Sign(signer))
+
+	return tx
+}
+
+func waitEvent(t *testing.T, events <-chan ordering.Event) ordering.Event {
+	select {
+	case <-time.After(15 * time.Second):
+		t.Fatal("no event received before the timeout")
+		return ordering.Event{}
+	case evt := <-events:
+		return evt
+	}
+}
+
+// waitLeader waits until the nodes agree on a leader and returns it.
+func waitLeader(t *testing.T, nodes []testNode) mino.Address {
+	timeout := time.After(15 * time.Second)
+
+	for {
+		var leader mino.Address
+
+		agree := true
+		for _, node := range nodes {
+			addr, found := node.service.GetLeader()
+			if !found || (leader != nil && !leader.Equal(addr)) {
+				agree = false
+				break
+			}
+
+			leader = addr
+		}
+
+		if agree {
+			return leader
+		}
+
+		select {
+		case <-timeout:
+			t.Fatal("no leader before the timeout")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// waitApplied waits until the services have applied the same entries.
+func waitApplied(t *testing.T, services ...*Service) {
+	timeout := time.After(15 * time.Second)
+
+	for {
+		done := true
+		for _, srvc := range services[1:] {
+			srvc.Lock()
+			applied := srvc.applied
+			srvc.Unlock()
+
+			services[0].Lock()
+			done = done && applied == services[0].applied
+			services[0].Unlock()
+		}
+
+		if done {
+			return
+		}
+
+		select {
+		case <-timeout:
+			t.Fatal("entries not applied before the timeout")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// This file contains the implementation of the handler of the messages sent by
+// the other members of the committee.
+
+package raft
+
+import (
+	"go.dedis.ch/dela/core/ordering/raft/types"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+// handler processes the messages of the members.
+//
+// - implements mino.Handler
+type handler struct {
+	mino.UnsupportedHandler
+
+	*Service
+}
+
+// Process implements mino.Handler. It processes the message and returns the
+// reply, if any.
+func (h handler) Process(req mino.Request) (serde.Message, error) {
+	switch msg := req.Message.(type) {
+	case types.SetupMessage:
+		return nil, h.processSetup(msg)
+	case types.VoteRequest:
+		return h.processVote(req.Address, msg)
+	case types.AppendRequest:
+		return h.processAppend(req.Address, msg)
+	default:
+		return nil, xerrors.Errorf("unsupported message of type '%T'", req.Message)
+	}
+}
+
+// processSetup stores the roster and starts following the log. A member that
+// is already set up ignores the message.
+func (h handler) processSetup(msg types.SetupMessage) error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.roster != nil {
+		return nil
+	}
+
+	err := h.disk.saveRoster(msg.GetRoster())
+	if err != nil {
+		return xerrors.Errorf("failed to save roster: %v", err)
+	}
+
+	h.roster = msg.GetRoster()
+	close(h.started)
+
+	return nil
+}
+
+// processVote grants the vote of the member to the candidate if it has not
+// voted for another one in the term, and if the log of the candidate is at
+// least as up-to-date as its own.
+func (h handler) processVote(from mino.Address, req types.VoteRequest) (serde.Message, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.roster == nil {
+		return nil, xerrors.New("service is not set up")
+	}
+
+	if req.GetTerm() > h.term {
+		h.stepDown(req.GetTerm())
+	}
+
+	lastIndex := h.lastIndex()
+	lastTerm := h.termAt(lastIndex)
+
+	upToDate := req.GetLastTerm() > lastTerm ||
+		(req.GetLastTerm() == lastTerm && req.GetLastIndex() >= lastIndex)
+
+	free := h.vote == nil || h.vote.Equal(from)
+
+	if req.GetTerm() != h.term || !free || !upToDate {
+		return types.NewVoteReply(h.term, false), nil
+	}
+
+	err := h.disk.saveTerm(h.term, from)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to save vote: %v", err)
+	}
+
+	h.vote = from
+	h.signalHeartbeat()
+
+	return types.NewVoteReply(h.term, true), nil
+}
+
+// processAppend appends the entries of the leader to the log, after it
+// removes the entries that conflict with them, and commits the entries the
+// leader has committed.
+func (h handler) processAppend(from mino.Address, req types.AppendRequest) (serde.Message, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.roster == nil {
+		return nil, xerrors.New("service is not set up")
+	}
+
+	if req.GetTerm() < h.term {
+		return types.NewAppendReply(h.term, false, 0), nil
+	}
+
+	h.stepDown(req.GetTerm())
+	h.leader = from
+	h.signalHeartbeat()
+
+	prev := req.GetPrevIndex()
+
+	if prev > h.lastIndex() {
+		return types.NewAppendReply(h.term, false, h.lastIndex()), nil
+	}
+
+	if h.termAt(prev) != req.GetPrevTerm() {
+		return types.NewAppendReply(h.term, false, prev-1), nil
+	}
+
+	entries := req.GetEntries()
+
+	// Entries already in the log are skipped so that a late request doesn't
+	// truncate the entries appended after it.
+	for len(entries) > 0 {
+		index := entries[0].GetIndex()
+		if index > h.lastIndex() || h.termAt(index) != entries[0].GetTerm() {
+			break
+		}
+
+		entries = entries[1:]
+	}
+
+	err := h.appendEntries(entries...)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to append: %v", err)
+	}
+
+	last := prev + uint64(len(req.GetEntries()))
+
+	commit := req.GetCommit()
+	if commit > last {
+		commit = last
+	}
+
+	h.setCommit(commit)
+
+	return types.NewAppendReply(h.term, true, last), nil
+}
@@ -0,0 +1,32 @@
+// This file contains the implementation of a proof for this ordering service.
+
+package raft
+
+import (
+	"go.dedis.ch/dela/core/store/hashtree"
+)
+
+// Proof is the path of a key in the tree of a member. As the entries of the
+// log are not signed, it only proves the value to a client that trusts the
+// member.
+//
+// - implements ordering.Proof
+type Proof struct {
+	path hashtree.Path
+}
+
+// GetKey implements ordering.Proof. It returns the key associated to the proof.
+func (p Proof) GetKey() []byte {
+	return p.path.GetKey()
+}
+
+// GetValue implements ordering.Proof. It returns the value associated to the
+// proof if the key exists, otherwise it returns nil.
+func (p Proof) GetValue() []byte {
+	return p.path.GetValue()
+}
+
+// GetRoot returns the root of the tree the proof is made for.
+func (p Proof) GetRoot() []byte {
+	return p.path.GetRoot()
+}
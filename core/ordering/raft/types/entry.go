@@ -0,0 +1,140 @@
+// Package types implements the log entries and the network messages of the
+// raft ordering service.
+//
+// The messages are implemented in a different package to prevent cycle imports
+// when importing the serde formats.
+package types
+
+import (
+	"go.dedis.ch/dela/core/validation"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
+	"golang.org/x/xerrors"
+)
+
+var entryFormats = registry.NewSimpleRegistry()
+
+// RegisterEntryFormat registers the engine for the provided format.
+func RegisterEntryFormat(f serde.Format, e serde.FormatEngine) {
+	entryFormats.Register(f, e)
+}
+
+// Entry is an entry of the replicated log. It contains the result of the
+// validation of a batch of transactions, and the root of the tree after the
+// result is applied. An entry without data is appended by a new leader so that
+// the entries of the previous terms are committed.
+//
+// - implements serde.Message
+type Entry struct {
+	index uint64
+	term  uint64
+	data  validation.Result
+	root  []byte
+}
+
+// EntryOption is the type of option to create an entry.
+type EntryOption func(*Entry)
+
+// WithData is an option to set the result of the validation of the entry, and
+// the root of the tree once the result is applied.
+func WithData(data validation.Result, root []byte) EntryOption {
+	return func(e *Entry) {
+		e.data = data
+		e.root = root
+	}
+}
+
+// NewEntry creates a new entry at the index of the log for the term.
+func NewEntry(index, term uint64, opts ...EntryOption) Entry {
+	e := Entry{
+		index: index,
+		term:  term,
+	}
+
+	for _, opt := range opts {
+		opt(&e)
+	}
+
+	return e
+}
+
+// GetIndex returns the index of the entry in the log.
+func (e Entry) GetIndex() uint64 {
+	return e.index
+}
+
+// GetTerm returns the term of the leader that created the entry.
+func (e Entry) GetTerm() uint64 {
+	return e.term
+}
+
+// GetData returns the result of the validation of the transactions, or nil if
+// the entry is empty.
+func (e Entry) GetData() validation.Result {
+	return e.data
+}
+
+// GetTreeRoot returns the root of the tree after the data is applied.
+func (e Entry) GetTreeRoot() []byte {
+	return append([]byte{}, e.root...)
+}
+
+// IsEmpty returns true if the entry doesn't have any data.
+func (e Entry) IsEmpty() bool {
+	return e.data == nil
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// entry.
+func (e Entry) Serialize(ctx serde.Context) ([]byte, error) {
+	format := entryFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, e)
+	if err != nil {
+		return nil, xerrors.Errorf("encoding failed: %v", err)
+	}
+
+	return data, nil
+}
+
+// DataKey is the key of the factory of the validation results.
+type DataKey struct{}
+
+// EntryFactory is the factory to deserialize the entries.
+//
+// - implements serde.Factory
+type EntryFactory struct {
+	dataFac validation.ResultFactory
+}
+
+// NewEntryFactory creates a new entry factory.
+func NewEntryFactory(fac validation.ResultFactory) EntryFactory {
+	return EntryFactory{
+		dataFac: fac,
+	}
+}
+
+// Deserialize implements serde.Factory. It populates the entry if appropriate,
+// otherwise it returns an error.
+func (f EntryFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	return f.EntryOf(ctx, data)
+}
+
+// EntryOf populates the entry if appropriate, otherwise it returns an error.
+func (f EntryFactory) EntryOf(ctx serde.Context, data []byte) (Entry, error) {
+	format := entryFormats.Get(ctx.GetFormat())
+
+	ctx = serde.WithFactory(ctx, DataKey{}, f.dataFac)
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return Entry{}, xerrors.Errorf("decoding failed: %v", err)
+	}
+
+	entry, ok := msg.(Entry)
+	if !ok {
+		return Entry{}, xerrors.Errorf("invalid entry '%T'", msg)
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func init() {
+	RegisterEntryFormat(fake.GoodFormat, fake.Format{Msg: Entry{}})
+	RegisterEntryFormat(fake.BadFormat, fake.NewBadFormat())
+	RegisterEntryFormat(fake.MsgFormat, fake.NewMsgFormat())
+}
+
+func TestEntry_Getters(t *testing.T) {
+	entry := NewEntry(2, 1)
+	require.Equal(t, uint64(2), entry.GetIndex())
+	require.Equal(t, uint64(1), entry.GetTerm())
+	require.Nil(t, entry.GetData())
+	require.Empty(t, entry.GetTreeRoot())
+	require.True(t, entry.IsEmpty())
+
+	entry = NewEntry(3, 1, WithData(simple.NewResult(nil), []byte{1}))
+	require.Equal(t, simple.NewResult(nil), entry.GetData())
+	require.Equal(t, []byte{1}, entry.GetTreeRoot())
+	require.False(t, entry.IsEmpty())
+}
+
+func TestEntry_Serialize(t *testing.T) {
+	entry := NewEntry(1, 1)
+
+	data, err := entry.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = entry.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("encoding failed"))
+}
+
+func TestEntryFactory_Deserialize(t *testing.T) {
+	fac := NewEntryFactory(simple.NewResultFactory(signed.NewTransactionFactory()))
+
+	msg, err := fac.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.Equal(t, Entry{}, msg)
+
+	_, err = fac.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("decoding failed"))
+
+	_, err = fac.EntryOf(fake.NewMsgContext(), nil)
+	require.EqualError(t, err, "invalid entry 'fake.Message'")
+}
@@ -0,0 +1,264 @@
+// This file contains the implementation of the messages exchanged by the
+// members of the committee.
+
+package types
+
+import (
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
+	"golang.org/x/xerrors"
+)
+
+var msgFormats = registry.NewSimpleRegistry()
+
+// RegisterMessageFormat registers the engine for the provided format.
+func RegisterMessageFormat(f serde.Format, e serde.FormatEngine) {
+	msgFormats.Register(f, e)
+}
+
+// SetupMessage is the message sent to the members to start a new log.
+//
+// - implements serde.Message
+type SetupMessage struct {
+	roster authority.Authority
+}
+
+// NewSetupMessage creates a new setup message for the roster.
+func NewSetupMessage(roster authority.Authority) SetupMessage {
+	return SetupMessage{
+		roster: roster,
+	}
+}
+
+// GetRoster returns the members of the committee.
+func (m SetupMessage) GetRoster() authority.Authority {
+	return m.roster
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// message.
+func (m SetupMessage) Serialize(ctx serde.Context) ([]byte, error) {
+	return serialize(ctx, m)
+}
+
+// VoteRequest is the message sent by a candidate to request the vote of the
+// other members for a term.
+//
+// - implements serde.Message
+type VoteRequest struct {
+	term      uint64
+	lastIndex uint64
+	lastTerm  uint64
+}
+
+// NewVoteRequest creates a new vote request for the term with the index and
+// the term of the last entry of the log of the candidate.
+func NewVoteRequest(term, lastIndex, lastTerm uint64) VoteRequest {
+	return VoteRequest{
+		term:      term,
+		lastIndex: lastIndex,
+		lastTerm:  lastTerm,
+	}
+}
+
+// GetTerm returns the term of the election.
+func (m VoteRequest) GetTerm() uint64 {
+	return m.term
+}
+
+// GetLastIndex returns the index of the last entry of the candidate.
+func (m VoteRequest) GetLastIndex() uint64 {
+	return m.lastIndex
+}
+
+// GetLastTerm returns the term of the last entry of the candidate.
+func (m VoteRequest) GetLastTerm() uint64 {
+	return m.lastTerm
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// message.
+func (m VoteRequest) Serialize(ctx serde.Context) ([]byte, error) {
+	return serialize(ctx, m)
+}
+
+// VoteReply is the answer to a vote request.
+//
+// - implements serde.Message
+type VoteReply struct {
+	term    uint64
+	granted bool
+}
+
+// NewVoteReply creates a new reply with the current term of the member and
+// whether it grants its vote.
+func NewVoteReply(term uint64, granted bool) VoteReply {
+	return VoteReply{
+		term:    term,
+		granted: granted,
+	}
+}
+
+// GetTerm returns the current term of the member.
+func (m VoteReply) GetTerm() uint64 {
+	return m.term
+}
+
+// IsGranted returns true if the member votes for the candidate.
+func (m VoteReply) IsGranted() bool {
+	return m.granted
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// message.
+func (m VoteReply) Serialize(ctx serde.Context) ([]byte, error) {
+	return serialize(ctx, m)
+}
+
+// AppendRequest is the message sent by the leader to replicate its log. It
+// also serves as a heartbeat when it doesn't have any entry.
+//
+// - implements serde.Message
+type AppendRequest struct {
+	term      uint64
+	prevIndex uint64
+	prevTerm  uint64
+	entries   []Entry
+	commit    uint64
+}
+
+// NewAppendRequest creates a new request for the term of the leader. The
+// entries follow the entry at the previous index and term, and the commit is
+// the index of the last entry committed by the leader.
+func NewAppendRequest(term, prevIndex, prevTerm uint64, entries []Entry, commit uint64) AppendRequest {
+	return AppendRequest{
+		term:      term,
+		prevIndex: prevIndex,
+		prevTerm:  prevTerm,
+		entries:   entries,
+		commit:    commit,
+	}
+}
+
+// GetTerm returns the term of the leader.
+func (m AppendRequest) GetTerm() uint64 {
+	return m.term
+}
+
+// GetPrevIndex returns the index of the entry preceding the new ones.
+func (m AppendRequest) GetPrevIndex() uint64 {
+	return m.prevIndex
+}
+
+// GetPrevTerm returns the term of the entry preceding the new ones.
+func (m AppendRequest) GetPrevTerm() uint64 {
+	return m.prevTerm
+}
+
+// GetEntries returns the entries to append.
+func (m AppendRequest) GetEntries() []Entry {
+	return append([]Entry{}, m.entries...)
+}
+
+// GetCommit returns the index of the last entry committed by the leader.
+func (m AppendRequest) GetCommit() uint64 {
+	return m.commit
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// message.
+func (m AppendRequest) Serialize(ctx serde.Context) ([]byte, error) {
+	return serialize(ctx, m)
+}
+
+// AppendReply is the answer to an append request.
+//
+// - implements serde.Message
+type AppendReply struct {
+	term    uint64
+	success bool
+	last    uint64
+}
+
+// NewAppendReply creates a new reply with the current term of the member,
+// whether the entries have been appended, and the index of the last entry that
+// matches the log of the leader.
+func NewAppendReply(term uint64, success bool, last uint64) AppendReply {
+	return AppendReply{
+		term:    term,
+		success: success,
+		last:    last,
+	}
+}
+
+// GetTerm returns the current term of the member.
+func (m AppendReply) GetTerm() uint64 {
+	return m.term
+}
+
+// IsSuccess returns true if the entries have been appended.
+func (m AppendReply) IsSuccess() bool {
+	return m.success
+}
+
+// GetLast returns the index of the last entry of the member that matches the
+// log of the leader, or a hint of where the logs diverge on failure.
+func (m AppendReply) GetLast() uint64 {
+	return m.last
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// message.
+func (m AppendReply) Serialize(ctx serde.Context) ([]byte, error) {
+	return serialize(ctx, m)
+}
+
+func serialize(ctx serde.Context, m serde.Message) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, m)
+	if err != nil {
+		return nil, xerrors.Errorf("encoding failed: %v", err)
+	}
+
+	return data, nil
+}
+
+// RosterKey is the key of the roster factory.
+type RosterKey struct{}
+
+// EntryKey is the key of the entry factory.
+type EntryKey struct{}
+
+// MessageFactory is the factory to deserialize the messages.
+//
+// - implements serde.Factory
+type MessageFactory struct {
+	rosterFac authority.Factory
+	entryFac  EntryFactory
+}
+
+// NewMessageFactory creates a new message factory.
+func NewMessageFactory(rf authority.Factory, ef EntryFactory) MessageFactory {
+	return MessageFactory{
+		rosterFac: rf,
+		entryFac:  ef,
+	}
+}
+
+// Deserialize implements serde.Factory. It populates the message if
+// appropriate, otherwise it returns an error.
+func (f MessageFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	ctx = serde.WithFactory(ctx, RosterKey{}, f.rosterFac)
+	ctx = serde.WithFactory(ctx, EntryKey{}, f.entryFac)
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("decoding failed: %v", err)
+	}
+
+	return msg, nil
+}
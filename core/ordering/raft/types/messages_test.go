@@ -0,0 +1,82 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/serde"
+)
+
+func init() {
+	RegisterMessageFormat(fake.GoodFormat, fake.Format{Msg: VoteRequest{}})
+	RegisterMessageFormat(fake.BadFormat, fake.NewBadFormat())
+}
+
+func TestSetupMessage_GetRoster(t *testing.T) {
+	roster := authority.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+
+	msg := NewSetupMessage(roster)
+	require.Equal(t, roster, msg.GetRoster())
+}
+
+func TestVoteRequest_Getters(t *testing.T) {
+	msg := NewVoteRequest(3, 2, 1)
+	require.Equal(t, uint64(3), msg.GetTerm())
+	require.Equal(t, uint64(2), msg.GetLastIndex())
+	require.Equal(t, uint64(1), msg.GetLastTerm())
+}
+
+func TestVoteReply_Getters(t *testing.T) {
+	msg := NewVoteReply(3, true)
+	require.Equal(t, uint64(3), msg.GetTerm())
+	require.True(t, msg.IsGranted())
+}
+
+func TestAppendRequest_Getters(t *testing.T) {
+	msg := NewAppendRequest(4, 3, 2, []Entry{NewEntry(4, 4)}, 1)
+	require.Equal(t, uint64(4), msg.GetTerm())
+	require.Equal(t, uint64(3), msg.GetPrevIndex())
+	require.Equal(t, uint64(2), msg.GetPrevTerm())
+	require.Equal(t, []Entry{NewEntry(4, 4)}, msg.GetEntries())
+	require.Equal(t, uint64(1), msg.GetCommit())
+}
+
+func TestAppendReply_Getters(t *testing.T) {
+	msg := NewAppendReply(2, true, 5)
+	require.Equal(t, uint64(2), msg.GetTerm())
+	require.True(t, msg.IsSuccess())
+	require.Equal(t, uint64(5), msg.GetLast())
+}
+
+func TestMessages_Serialize(t *testing.T) {
+	msgs := []serde.Message{
+		NewSetupMessage(nil),
+		NewVoteRequest(1, 0, 0),
+		NewVoteReply(1, false),
+		NewAppendRequest(1, 0, 0, nil, 0),
+		NewAppendReply(1, false, 0),
+	}
+
+	for _, msg := range msgs {
+		data, err := msg.Serialize(fake.NewContext())
+		require.NoError(t, err)
+		require.Equal(t, fake.GetFakeFormatValue(), data)
+
+		_, err = msg.Serialize(fake.NewBadContext())
+		require.EqualError(t, err, fake.Err("encoding failed"))
+	}
+}
+
+func TestMessageFactory_Deserialize(t *testing.T) {
+	fac := NewMessageFactory(authority.NewFactory(fake.AddressFactory{},
+		fake.PublicKeyFactory{}), EntryFactory{})
+
+	msg, err := fac.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.Equal(t, VoteRequest{}, msg)
+
+	_, err = fac.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("decoding failed"))
+}
@@ -0,0 +1,84 @@
+// This file contains the implementation of the replay of the events of an
+// ordering service.
+
+package ordering
+
+import "context"
+
+// History is a function that returns the event of the block at the index and
+// true, or false when the block is not available, e.g. it does not exist yet.
+type History func(index uint64) (Event, bool)
+
+// Replay returns a channel populated with the events of the history from the
+// index, followed by the live events. The live channel must be watched before
+// the history is read so that no block is missed in between, and the events of
+// the blocks already replayed are skipped. The live events received during the
+// replay are buffered so that the service is never blocked by it. The channel
+// is closed when the context is done or when the live channel is closed.
+func Replay(ctx context.Context, from uint64, live <-chan Event, history History) <-chan Event {
+	out := make(chan Event, 1)
+
+	go func() {
+		defer close(out)
+
+		next := from
+		pending := []Event{}
+
+		for {
+			evt, found := history(next)
+			if !found {
+				break
+			}
+
+			for sent := false; !sent; {
+				select {
+				case out <- evt:
+					sent = true
+				case e, more := <-live:
+					if !more {
+						return
+					}
+
+					pending = append(pending, e)
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			next++
+		}
+
+		for {
+			var evt Event
+
+			if len(pending) > 0 {
+				evt, pending = pending[0], pending[1:]
+			} else {
+				var more bool
+
+				select {
+				case evt, more = <-live:
+					if !more {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if evt.Index < next {
+				continue
+			}
+
+			select {
+			case out <- evt:
+			case <-ctx.Done():
+				return
+			}
+
+			next = evt.Index + 1
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,136 @@
+package ordering
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	live := make(chan Event, 5)
+
+	// The block 2 is notified after the watch started but is already in the
+	// history.
+	live <- Event{Index: 2}
+	live <- Event{Index: 3}
+
+	events := Replay(ctx, 1, live, makeHistory(3))
+
+	for i := uint64(1); i < 4; i++ {
+		require.Equal(t, i, waitEvent(t, events).Index)
+	}
+
+	live <- Event{Index: 4}
+	require.Equal(t, uint64(4), waitEvent(t, events).Index)
+
+	close(live)
+	_, more := <-events
+	require.False(t, more)
+}
+
+func TestReplay_Buffered(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	live := make(chan Event)
+
+	events := Replay(ctx, 0, live, makeHistory(2))
+
+	// The live events are accepted while the history is not consumed.
+	for i := uint64(1); i < 5; i++ {
+		select {
+		case live <- Event{Index: i}:
+		case <-time.After(time.Second):
+			t.Fatal("live event blocked by the replay")
+		}
+	}
+
+	for i := uint64(0); i < 5; i++ {
+		require.Equal(t, i, waitEvent(t, events).Index)
+	}
+
+	close(live)
+	_, more := <-events
+	require.False(t, more)
+}
+
+func TestReplay_Closed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events := Replay(ctx, 0, make(chan Event), makeHistory(5))
+	require.Equal(t, uint64(0), waitEvent(t, events).Index)
+
+	cancel()
+	waitClosed(t, events)
+
+	ctx, cancel = context.WithCancel(context.Background())
+
+	events = Replay(ctx, 0, make(chan Event), makeHistory(0))
+
+	cancel()
+	waitClosed(t, events)
+
+	live := make(chan Event)
+	close(live)
+
+	events = Replay(context.Background(), 0, live, makeHistory(5))
+	waitClosed(t, events)
+
+	ctx, cancel = context.WithCancel(context.Background())
+
+	live = make(chan Event, 1)
+	live <- Event{Index: 0}
+
+	events = Replay(ctx, 0, live, makeHistory(0))
+
+	// Wait for the event to be read before the context is canceled.
+	for len(live) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	waitClosed(t, events)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeHistory(n uint64) History {
+	return func(index uint64) (Event, bool) {
+		if index >= n {
+			return Event{}, false
+		}
+
+		return Event{Index: index}, true
+	}
+}
+
+func waitEvent(t *testing.T, events <-chan Event) Event {
+	select {
+	case evt := <-events:
+		return evt
+	case <-time.After(5 * time.Second):
+		t.Fatal("event not received")
+		return Event{}
+	}
+}
+
+func waitClosed(t *testing.T, events <-chan Event) {
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case _, more := <-events:
+			if !more {
+				return
+			}
+		case <-timeout:
+			t.Fatal("channel not closed")
+		}
+	}
+}
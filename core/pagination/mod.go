@@ -0,0 +1,351 @@
+// Package pagination defines the conventions shared by the lists of the APIs.
+//
+// A list is read page by page. A request has the cursor of the last item of the
+// previous page, the maximum number of items of the page and the order of the
+// list. The cursor is opaque to the clients: the items of a chain, like the
+// transactions, have the index of their block and their position in it, the
+// blocks have their index, and the items of a set have their key.
+//
+// A full page always has the cursor of the next page, which can be empty when
+// the list has exactly that many items left.
+package pagination
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// DefaultLimit is the number of items of a page when the request does not
+	// set the limit.
+	DefaultLimit = 20
+
+	// MaxLimit is the maximum number of items of a page.
+	MaxLimit = 100
+)
+
+// Order is the order of the items of a list.
+type Order int
+
+const (
+	// Ascending lists the oldest items first.
+	Ascending Order = iota
+
+	// Descending lists the newest items first.
+	Descending
+)
+
+// ParseOrder returns the order of its text form, which is either 'asc' or
+// 'desc'. An empty text is the ascending order.
+func ParseOrder(text string) (Order, error) {
+	switch strings.ToLower(text) {
+	case "", "asc":
+		return Ascending, nil
+	case "desc":
+		return Descending, nil
+	default:
+		return Ascending, xerrors.Errorf("unknown order '%s'", text)
+	}
+}
+
+// String returns the text form of the order.
+func (o Order) String() string {
+	if o == Descending {
+		return "desc"
+	}
+
+	return "asc"
+}
+
+// Cursor is the position of an item in a chain, which is the index of the block
+// and the position of the item in the block.
+type Cursor struct {
+	Index    uint64
+	Position int
+}
+
+// ParseCursor returns the cursor of its text form.
+func ParseCursor(text string) (Cursor, error) {
+	parts := strings.Split(text, ":")
+	if len(parts) != 2 {
+		return Cursor{}, xerrors.Errorf("invalid cursor '%s'", text)
+	}
+
+	index, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return Cursor{}, xerrors.Errorf("invalid cursor '%s'", text)
+	}
+
+	pos, err := strconv.Atoi(parts[1])
+	if err != nil || pos < 0 {
+		return Cursor{}, xerrors.Errorf("invalid cursor '%s'", text)
+	}
+
+	return Cursor{Index: index, Position: pos}, nil
+}
+
+// String returns the text form of the cursor.
+func (c Cursor) String() string {
+	return strconv.FormatUint(c.Index, 10) + ":" + strconv.Itoa(c.Position)
+}
+
+// Request is the request of a page of a list.
+type Request struct {
+	// After is the cursor of the last item of the previous page, or empty for
+	// the first page.
+	After string
+
+	// Limit is the maximum number of items of the page.
+	Limit int
+
+	// Order is the order of the items.
+	Order Order
+}
+
+// NewRequest returns the request of a page after the cursor. A limit of zero is
+// the default limit.
+func NewRequest(after string, limit int, order string) (Request, error) {
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+
+	if limit < 0 || limit > MaxLimit {
+		return Request{}, xerrors.Errorf("limit must be between 1 and %d", MaxLimit)
+	}
+
+	o, err := ParseOrder(order)
+	if err != nil {
+		return Request{}, err
+	}
+
+	req := Request{
+		After: after,
+		Limit: limit,
+		Order: o,
+	}
+
+	return req, nil
+}
+
+// Page is the result of a request.
+type Page struct {
+	// Next is the cursor to request the next page, or empty when the list is
+	// exhausted.
+	Next string
+}
+
+// Walk visits the items of the page in a chain of groups, like the
+// transactions of the blocks. The size function returns the number of items
+// of the group at the index, and the visit function returns true when the item
+// is part of the list, so that the items can be filtered.
+func (r Request) Walk(groups uint64, size func(index uint64) (int, error),
+	visit func(c Cursor) (bool, error)) (Page, error) {
+
+	var start *Cursor
+
+	if r.After != "" {
+		c, err := ParseCursor(r.After)
+		if err != nil {
+			return Page{}, err
+		}
+
+		start = &c
+	}
+
+	return r.walk(groups, size, visit, start, Cursor.String)
+}
+
+// WalkIndex visits the indices of the page in a list of n items, like the
+// blocks, whose cursor is the index.
+func (r Request) WalkIndex(n uint64, visit func(index uint64) (bool, error)) (Page, error) {
+	var start *Cursor
+
+	if r.After != "" {
+		index, err := strconv.ParseUint(r.After, 10, 64)
+		if err != nil {
+			return Page{}, xerrors.Errorf("invalid cursor '%s'", r.After)
+		}
+
+		start = &Cursor{Index: index}
+	}
+
+	size := func(uint64) (int, error) {
+		return 1, nil
+	}
+
+	fn := func(c Cursor) (bool, error) {
+		return visit(c.Index)
+	}
+
+	format := func(c Cursor) string {
+		return strconv.FormatUint(c.Index, 10)
+	}
+
+	return r.walk(n, size, fn, start, format)
+}
+
+// Keys returns the page of a set of keys, whose cursor is the key.
+func (r Request) Keys(keys []string) ([]string, Page) {
+	sorted := append([]string{}, keys...)
+
+	if r.Order == Descending {
+		sort.Sort(sort.Reverse(sort.StringSlice(sorted)))
+	} else {
+		sort.Strings(sorted)
+	}
+
+	list := []string{}
+
+	for _, key := range sorted {
+		if r.After != "" && !r.isAfter(key) {
+			continue
+		}
+
+		list = append(list, key)
+
+		if len(list) == r.limit() {
+			return list, Page{Next: key}
+		}
+	}
+
+	return list, Page{}
+}
+
+func (r Request) isAfter(key string) bool {
+	if r.Order == Descending {
+		return key < r.After
+	}
+
+	return key > r.After
+}
+
+func (r Request) walk(groups uint64, size func(uint64) (int, error),
+	visit func(Cursor) (bool, error), start *Cursor, format func(Cursor) string) (Page, error) {
+
+	count := 0
+
+	// fn visits the item and returns true when the page is full.
+	fn := func(c Cursor) (bool, error) {
+		ok, err := visit(c)
+		if err != nil {
+			return false, err
+		}
+
+		if ok {
+			count++
+		}
+
+		return count >= r.limit(), nil
+	}
+
+	var err error
+	var last *Cursor
+
+	if r.Order == Descending {
+		last, err = walkDescending(groups, size, fn, start)
+	} else {
+		last, err = walkAscending(groups, size, fn, start)
+	}
+
+	if err != nil {
+		return Page{}, err
+	}
+
+	if last == nil {
+		return Page{}, nil
+	}
+
+	return Page{Next: format(*last)}, nil
+}
+
+func (r Request) limit() int {
+	if r.Limit <= 0 {
+		return DefaultLimit
+	}
+
+	return r.Limit
+}
+
+// walkAscending visits the items from the beginning of the chain, or after the
+// cursor, and returns the cursor of the item that fills the page.
+func walkAscending(groups uint64, size func(uint64) (int, error),
+	fn func(Cursor) (bool, error), start *Cursor) (*Cursor, error) {
+
+	index, pos := uint64(0), 0
+
+	if start != nil {
+		index, pos = start.Index, start.Position+1
+	}
+
+	for ; index < groups; index++ {
+		n, err := size(index)
+		if err != nil {
+			return nil, xerrors.Errorf("group %d: %v", index, err)
+		}
+
+		for ; pos < n; pos++ {
+			c := Cursor{Index: index, Position: pos}
+
+			full, err := fn(c)
+			if err != nil {
+				return nil, err
+			}
+
+			if full {
+				return &c, nil
+			}
+		}
+
+		pos = 0
+	}
+
+	return nil, nil
+}
+
+// walkDescending visits the items from the end of the chain, or before the
+// cursor, and returns the cursor of the item that fills the page.
+func walkDescending(groups uint64, size func(uint64) (int, error),
+	fn func(Cursor) (bool, error), start *Cursor) (*Cursor, error) {
+
+	// A negative position starts from the end of the group.
+	index, pos := int64(groups)-1, -1
+
+	if start != nil && start.Index < groups {
+		index, pos = int64(start.Index), start.Position-1
+
+		if pos < 0 {
+			index--
+		}
+	}
+
+	for ; index >= 0; index-- {
+		n, err := size(uint64(index))
+		if err != nil {
+			return nil, xerrors.Errorf("group %d: %v", index, err)
+		}
+
+		if pos < 0 || pos >= n {
+			pos = n - 1
+		}
+
+		for ; pos >= 0; pos-- {
+			c := Cursor{Index: uint64(index), Position: pos}
+
+			full, err := fn(c)
+			if err != nil {
+				return nil, err
+			}
+
+			if full {
+				return &c, nil
+			}
+		}
+
+		pos = -1
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,168 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestParseOrder(t *testing.T) {
+	order, err := ParseOrder("")
+	require.NoError(t, err)
+	require.Equal(t, Ascending, order)
+
+	order, err = ParseOrder("DESC")
+	require.NoError(t, err)
+	require.Equal(t, Descending, order)
+	require.Equal(t, "desc", order.String())
+	require.Equal(t, "asc", Ascending.String())
+
+	_, err = ParseOrder("random")
+	require.EqualError(t, err, "unknown order 'random'")
+}
+
+func TestCursor_String(t *testing.T) {
+	c, err := ParseCursor("3:2")
+	require.NoError(t, err)
+	require.Equal(t, Cursor{Index: 3, Position: 2}, c)
+	require.Equal(t, "3:2", c.String())
+
+	for _, text := range []string{"abc", "a:1", "1:a", "1:-1"} {
+		_, err = ParseCursor(text)
+		require.EqualError(t, err, "invalid cursor '"+text+"'")
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	req, err := NewRequest("1:2", 0, "desc")
+	require.NoError(t, err)
+	require.Equal(t, Request{After: "1:2", Limit: DefaultLimit, Order: Descending}, req)
+
+	_, err = NewRequest("", MaxLimit+1, "")
+	require.EqualError(t, err, "limit must be between 1 and 100")
+
+	_, err = NewRequest("", -1, "")
+	require.EqualError(t, err, "limit must be between 1 and 100")
+
+	_, err = NewRequest("", 1, "random")
+	require.EqualError(t, err, "unknown order 'random'")
+}
+
+func TestRequest_Walk(t *testing.T) {
+	// The chain has the groups [a b] [] [c] [d e f].
+	groups := [][]string{{"a", "b"}, {}, {"c"}, {"d", "e", "f"}}
+
+	walk := func(req Request) ([]string, string) {
+		list := []string{}
+
+		page, err := req.Walk(uint64(len(groups)), func(index uint64) (int, error) {
+			return len(groups[index]), nil
+		}, func(c Cursor) (bool, error) {
+			item := groups[c.Index][c.Position]
+			if item == "e" {
+				// Filtered out.
+				return false, nil
+			}
+
+			list = append(list, item)
+			return true, nil
+		})
+		require.NoError(t, err)
+
+		return list, page.Next
+	}
+
+	list, next := walk(Request{Limit: 2})
+	require.Equal(t, []string{"a", "b"}, list)
+	require.Equal(t, "0:1", next)
+
+	list, next = walk(Request{After: next, Limit: 2})
+	require.Equal(t, []string{"c", "d"}, list)
+	require.Equal(t, "3:0", next)
+
+	list, next = walk(Request{After: next, Limit: 2})
+	require.Equal(t, []string{"f"}, list)
+	require.Empty(t, next)
+
+	list, next = walk(Request{Limit: 3, Order: Descending})
+	require.Equal(t, []string{"f", "d", "c"}, list)
+	require.Equal(t, "2:0", next)
+
+	list, next = walk(Request{After: next, Order: Descending})
+	require.Equal(t, []string{"b", "a"}, list)
+	require.Empty(t, next)
+
+	list, _ = walk(Request{After: "9:0", Order: Descending})
+	require.Equal(t, []string{"f", "d", "c", "b", "a"}, list)
+
+	list, _ = walk(Request{After: "9:0"})
+	require.Empty(t, list)
+
+	_, err := Request{After: "abc"}.Walk(0, nil, nil)
+	require.EqualError(t, err, "invalid cursor 'abc'")
+
+	_, err = Request{}.Walk(1, func(uint64) (int, error) {
+		return 0, fake.GetError()
+	}, nil)
+	require.EqualError(t, err, fake.Err("group 0"))
+
+	_, err = Request{}.Walk(1, func(uint64) (int, error) {
+		return 1, nil
+	}, func(Cursor) (bool, error) {
+		return false, fake.GetError()
+	})
+	require.EqualError(t, err, fake.GetError().Error())
+
+	_, err = Request{Order: Descending}.Walk(1, func(uint64) (int, error) {
+		return 0, fake.GetError()
+	}, nil)
+	require.EqualError(t, err, fake.Err("group 0"))
+}
+
+func TestRequest_WalkIndex(t *testing.T) {
+	walk := func(req Request) ([]uint64, string) {
+		list := []uint64{}
+
+		page, err := req.WalkIndex(5, func(index uint64) (bool, error) {
+			list = append(list, index)
+			return true, nil
+		})
+		require.NoError(t, err)
+
+		return list, page.Next
+	}
+
+	list, next := walk(Request{After: "1", Limit: 2})
+	require.Equal(t, []uint64{2, 3}, list)
+	require.Equal(t, "3", next)
+
+	list, next = walk(Request{After: "3", Limit: 2, Order: Descending})
+	require.Equal(t, []uint64{2, 1}, list)
+	require.Equal(t, "1", next)
+
+	list, next = walk(Request{After: "1", Order: Descending})
+	require.Equal(t, []uint64{0}, list)
+	require.Empty(t, next)
+
+	_, err := Request{After: "1:0"}.WalkIndex(5, nil)
+	require.EqualError(t, err, "invalid cursor '1:0'")
+}
+
+func TestRequest_Keys(t *testing.T) {
+	keys := []string{"c", "a", "d", "b"}
+
+	list, page := Request{Limit: 3}.Keys(keys)
+	require.Equal(t, []string{"a", "b", "c"}, list)
+	require.Equal(t, "c", page.Next)
+
+	list, page = Request{After: page.Next, Limit: 3}.Keys(keys)
+	require.Equal(t, []string{"d"}, list)
+	require.Empty(t, page.Next)
+
+	list, page = Request{After: "c", Order: Descending}.Keys(keys)
+	require.Equal(t, []string{"b", "a"}, list)
+	require.Empty(t, page.Next)
+
+	require.Equal(t, []string{"c", "a", "d", "b"}, keys)
+}
@@ -0,0 +1,217 @@
+// Package diff implements a journal of the changes applied to a store so that
+// the difference between two heights can be streamed to downstream replicas,
+// like SQL mirrors or caches, without replaying the transactions.
+//
+// The journal is a store hook that records the changes of each commit within
+// the transaction of the commit. A height is the index of a commit since the
+// journal has been attached to the store. When it is attached to the tree of a
+// node since its creation, the genesis is the height 0 and the block at index i
+// is the height i+1.
+package diff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/kv"
+	"golang.org/x/xerrors"
+)
+
+var (
+	bucketName = []byte("store-diffs")
+	heightKey  = []byte("height")
+)
+
+// Change is the modification of a key at a given height. The old value is nil
+// when the key is created, and the new value is nil when the key is deleted.
+type Change struct {
+	Height uint64
+	Key    []byte
+	Old    []byte
+	New    []byte
+}
+
+// entry is the format of a change in the database.
+type entry struct {
+	Old []byte
+	New []byte
+}
+
+// Journal is a store hook that records the changes of every commit in a
+// database so that they can be streamed afterwards.
+//
+// - implements store.Hook
+type Journal struct {
+	db kv.DB
+}
+
+// NewJournal creates a new journal that uses the database to read the changes.
+// The changes are written through the transaction of the store.
+func NewJournal(db kv.DB) Journal {
+	return Journal{
+		db: db,
+	}
+}
+
+// Len returns the number of heights recorded by the journal.
+func (j Journal) Len() (uint64, error) {
+	var height uint64
+
+	err := j.db.View(func(tx kv.ReadableTx) error {
+		bucket := tx.GetBucket(bucketName)
+		if bucket != nil {
+			height = readHeight(bucket)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, xerrors.Errorf("failed to read: %v", err)
+	}
+
+	return height, nil
+}
+
+// OnWrite implements store.Hook. It records the changes of the writes at the
+// next height. The writes of a same key are merged into a single change.
+func (j Journal) OnWrite(tx store.Transaction, writes []store.Write) error {
+	wtx, ok := tx.(kv.WritableTx)
+	if !ok {
+		return xerrors.Errorf("transaction '%T' is not writable", tx)
+	}
+
+	bucket, err := wtx.GetBucketOrCreate(bucketName)
+	if err != nil {
+		return xerrors.Errorf("bucket: %v", err)
+	}
+
+	height := readHeight(bucket)
+
+	for _, change := range merge(writes) {
+		data, err := json.Marshal(entry{Old: change.Old, New: change.New})
+		if err != nil {
+			return xerrors.Errorf("failed to encode change: %v", err)
+		}
+
+		err = bucket.Set(append(makePrefix(height), change.Key...), data)
+		if err != nil {
+			return xerrors.Errorf("failed to write change: %v", err)
+		}
+	}
+
+	err = bucket.Set(heightKey, makePrefix(height+1))
+	if err != nil {
+		return xerrors.Errorf("failed to write height: %v", err)
+	}
+
+	return nil
+}
+
+// Stream calls the function for every change recorded from the height 'from'
+// to the height 'to', both included, in order of height. Applying the changes
+// to the state of the store before 'from' gives the state at 'to'. Each height
+// is read in its own transaction so that the journal is not locked for the
+// whole stream.
+func (j Journal) Stream(from, to uint64, fn func(Change) error) error {
+	if from > to {
+		return xerrors.Errorf("invalid range [%d, %d]", from, to)
+	}
+
+	length, err := j.Len()
+	if err != nil {
+		return xerrors.Errorf("journal: %v", err)
+	}
+
+	if to >= length {
+		return xerrors.Errorf("height %d is not reached: %d", to, length)
+	}
+
+	for height := from; height <= to; height++ {
+		err = j.db.View(func(tx kv.ReadableTx) error {
+			bucket := tx.GetBucket(bucketName)
+			if bucket == nil {
+				return nil
+			}
+
+			return bucket.Scan(makePrefix(height), func(key, value []byte) error {
+				var e entry
+				err := json.Unmarshal(value, &e)
+				if err != nil {
+					return xerrors.Errorf("malformed change: %v", err)
+				}
+
+				change := Change{
+					Height: height,
+					Key:    append([]byte{}, key[8:]...),
+					Old:    e.Old,
+					New:    e.New,
+				}
+
+				return fn(change)
+			})
+		})
+
+		if err != nil {
+			return xerrors.Errorf("height %d: %v", height, err)
+		}
+	}
+
+	return nil
+}
+
+// merge returns the list of changes of the writes in the order of the first
+// write of each key. The writes that cancel each other are dropped.
+func merge(writes []store.Write) []Change {
+	changes := []Change{}
+	indices := map[string]int{}
+
+	for _, w := range writes {
+		var value []byte
+		if !w.Deleted {
+			value = w.Value
+		}
+
+		index, found := indices[string(w.Key)]
+		if found {
+			changes[index].New = value
+			continue
+		}
+
+		indices[string(w.Key)] = len(changes)
+
+		changes = append(changes, Change{
+			Key: w.Key,
+			Old: w.Previous,
+			New: value,
+		})
+	}
+
+	result := changes[:0]
+	for _, change := range changes {
+		same := bytes.Equal(change.Old, change.New) && (change.Old == nil) == (change.New == nil)
+		if !same {
+			result = append(result, change)
+		}
+	}
+
+	return result
+}
+
+func readHeight(bucket kv.Bucket) uint64 {
+	data := bucket.Get(heightKey)
+	if len(data) != 8 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(data)
+}
+
+func makePrefix(height uint64) []byte {
+	prefix := make([]byte, 8)
+	binary.BigEndian.PutUint64(prefix, height)
+
+	return prefix
+}
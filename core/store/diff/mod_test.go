@@ -0,0 +1,132 @@
+package diff
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestJournal_OnWrite(t *testing.T) {
+	db, clean := makeDB(t)
+	defer clean()
+
+	journal := NewJournal(db)
+
+	length, err := journal.Len()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), length)
+
+	writes := []store.Write{
+		{Key: []byte("A"), Value: []byte("1")},
+		{Key: []byte("B"), Value: []byte("2"), Previous: []byte("1")},
+		{Key: []byte("A"), Value: []byte("3"), Previous: []byte("1")},
+		{Key: []byte("C"), Value: []byte("4")},
+		{Key: []byte("C"), Deleted: true, Previous: []byte("4")},
+	}
+
+	err = db.Update(func(tx kv.WritableTx) error {
+		return journal.OnWrite(tx, writes)
+	})
+	require.NoError(t, err)
+
+	err = db.Update(func(tx kv.WritableTx) error {
+		return journal.OnWrite(tx, nil)
+	})
+	require.NoError(t, err)
+
+	length, err = journal.Len()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), length)
+
+	err = journal.OnWrite(fakeTx{}, nil)
+	require.EqualError(t, err, "transaction 'diff.fakeTx' is not writable")
+
+	err = journal.OnWrite(badTx{}, nil)
+	require.EqualError(t, err, fake.Err("bucket"))
+}
+
+func TestJournal_Stream(t *testing.T) {
+	db, clean := makeDB(t)
+	defer clean()
+
+	journal := NewJournal(db)
+
+	commits := [][]store.Write{
+		{{Key: []byte("A"), Value: []byte("1")}},
+		{{Key: []byte("A"), Value: []byte("2"), Previous: []byte("1")}},
+		{{Key: []byte("A"), Deleted: true, Previous: []byte("2")}},
+	}
+
+	for _, writes := range commits {
+		err := db.Update(func(tx kv.WritableTx) error {
+			return journal.OnWrite(tx, writes)
+		})
+		require.NoError(t, err)
+	}
+
+	changes := []Change{}
+	err := journal.Stream(0, 2, func(change Change) error {
+		changes = append(changes, change)
+		return nil
+	})
+	require.NoError(t, err)
+
+	expected := []Change{
+		{Height: 0, Key: []byte("A"), New: []byte("1")},
+		{Height: 1, Key: []byte("A"), Old: []byte("1"), New: []byte("2")},
+		{Height: 2, Key: []byte("A"), Old: []byte("2")},
+	}
+	require.Equal(t, expected, changes)
+
+	changes = changes[:0]
+	err = journal.Stream(1, 1, func(change Change) error {
+		changes = append(changes, change)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, expected[1:2], changes)
+
+	err = journal.Stream(2, 1, nil)
+	require.EqualError(t, err, "invalid range [2, 1]")
+
+	err = journal.Stream(0, 3, nil)
+	require.EqualError(t, err, "height 3 is not reached: 3")
+
+	err = journal.Stream(0, 2, func(Change) error { return fake.GetError() })
+	require.EqualError(t, err, fake.Err("height 0"))
+
+	journal.db = fake.NewBadViewDB()
+	err = journal.Stream(0, 0, nil)
+	require.EqualError(t, err, fake.Err("journal: failed to read"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeDB(t *testing.T) (kv.DB, func()) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dela-diff")
+	require.NoError(t, err)
+
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	return db, func() { os.RemoveAll(dir) }
+}
+
+type fakeTx struct {
+	store.Transaction
+}
+
+type badTx struct {
+	kv.WritableTx
+}
+
+func (tx badTx) GetBucketOrCreate([]byte) (kv.Bucket, error) {
+	return nil, fake.GetError()
+}
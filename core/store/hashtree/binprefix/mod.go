@@ -33,8 +33,10 @@
 package binprefix
 
 import (
+	"math/big"
 	"sync"
 
+	"go.dedis.ch/dela/core/memory"
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/store/hashtree"
 	"go.dedis.ch/dela/core/store/kv"
@@ -58,16 +60,53 @@ type MerkleTree struct {
 	tx          store.Transaction
 	bucket      []byte
 	hashFactory crypto.HashFactory
+
+	// hooks are notified of the writes of a staged tree when it is committed,
+	// and writes is the list of modifications applied since it was staged.
+	hooks  []store.Hook
+	writes []store.Write
+
+	// budget accounts for the nodes kept in memory.
+	budget *memory.Budget
+}
+
+// Option is the type of option to configure the tree.
+type Option func(*MerkleTree)
+
+// WithMemoryBudget is an option to account for the nodes of the tree kept in
+// memory. When they exceed the cap of the budget after a commit, the deepest
+// ones are moved to the disk and loaded again when they are needed, which is
+// slower but bounds the memory.
+func WithMemoryBudget(b *memory.Budget) Option {
+	return func(t *MerkleTree) {
+		t.budget = b
+	}
 }
 
 // NewMerkleTree creates a new Merkle tree-based storage.
-func NewMerkleTree(db kv.DB, nonce Nonce) *MerkleTree {
-	return &MerkleTree{
+func NewMerkleTree(db kv.DB, nonce Nonce, opts ...Option) *MerkleTree {
+	t := &MerkleTree{
 		tree:        NewTree(nonce),
 		db:          db,
 		bucket:      []byte("hashtree"),
 		hashFactory: crypto.NewSha256Factory(),
 	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// AddHook adds a hook that will be notified of the writes of the staged trees
+// when they are committed. The hook is called within the database transaction
+// of the commit, which is provided as a kv.WritableTx.
+func (t *MerkleTree) AddHook(hook store.Hook) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.hooks = append(t.hooks, hook)
 }
 
 // Load tries to read the bucket and scan it for existing leafs and populate the
@@ -89,6 +128,13 @@ func (t *MerkleTree) Load() error {
 			return xerrors.Errorf("while updating: %v", err)
 		}
 
+		if t.budget != nil && bucket != nil {
+			err = t.tree.Fit(t.budget, bucket)
+			if err != nil {
+				return xerrors.Errorf("memory: %v", err)
+			}
+		}
+
 		return nil
 	})
 }
@@ -125,6 +171,56 @@ func (t *MerkleTree) GetRoot() []byte {
 	return t.tree.root.GetHash()
 }
 
+// CalculateRoot reads the leafs stored on the disk and calculates the root of
+// the tree from scratch, without relying on the digests stored alongside the
+// nodes. It can be compared to a known root to detect a corruption of the
+// disk.
+func (t *MerkleTree) CalculateRoot() ([]byte, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	tree := NewTree(t.tree.nonce)
+	tree.root = NewInteriorNode(0, big.NewInt(0))
+
+	err := t.doView(func(tx kv.ReadableTx) error {
+		bucket := tx.GetBucket(t.bucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.Scan([]byte{}, func(key, value []byte) error {
+			msg, err := tree.factory.Deserialize(tree.context, value)
+			if err != nil {
+				return xerrors.Errorf("tree node malformed: %v", err)
+			}
+
+			leaf, ok := msg.(*LeafNode)
+			if !ok {
+				// Only the leafs are needed as the other nodes are derived.
+				return nil
+			}
+
+			tree.root, err = tree.root.Insert(leaf.key, leaf.value, nil)
+			if err != nil {
+				return xerrors.Errorf("failed to insert: %v", err)
+			}
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, xerrors.Errorf("while scanning: %v", err)
+	}
+
+	err = tree.CalculateRoot(t.hashFactory, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("while calculating: %v", err)
+	}
+
+	return tree.root.GetHash(), nil
+}
+
 // GetPath implements hashtree.Tree. It returns a path to a given key that can
 // be used to prove the inclusion or the absence of a key.
 func (t *MerkleTree) GetPath(key []byte) (hashtree.Path, error) {
@@ -179,7 +275,8 @@ func (t *MerkleTree) Stage(fn func(store.Snapshot) error) (hashtree.StagingTree,
 }
 
 // Commit implements hashtree.StagingTree. It writes the leaf nodes to the disk
-// and a trade-off of other nodes.
+// and a trade-off of other nodes. The hooks are notified of the writes within
+// the same transaction.
 func (t *MerkleTree) Commit() error {
 	t.Lock()
 	defer t.Unlock()
@@ -190,7 +287,26 @@ func (t *MerkleTree) Commit() error {
 			return xerrors.Errorf("read bucket failed: %v", err)
 		}
 
-		return t.tree.Persist(bucket)
+		err = t.tree.Persist(bucket)
+		if err != nil {
+			return err
+		}
+
+		if t.budget != nil {
+			err = t.tree.Fit(t.budget, bucket)
+			if err != nil {
+				return xerrors.Errorf("memory: %v", err)
+			}
+		}
+
+		for _, hook := range t.hooks {
+			err = hook.OnWrite(tx, t.writes)
+			if err != nil {
+				return xerrors.Errorf("hook failed: %v", err)
+			}
+		}
+
+		return nil
 	})
 
 	if err != nil {
@@ -210,6 +326,9 @@ func (t *MerkleTree) WithTx(tx store.Transaction) hashtree.StagingTree {
 		tx:          tx,
 		bucket:      t.bucket,
 		hashFactory: t.hashFactory,
+		hooks:       t.hooks,
+		writes:      t.writes,
+		budget:      t.budget,
 	}
 }
 
@@ -220,6 +339,8 @@ func (t *MerkleTree) clone() *MerkleTree {
 		tx:          t.tx,
 		bucket:      t.bucket,
 		hashFactory: t.hashFactory,
+		hooks:       t.hooks,
+		budget:      t.budget,
 	}
 }
 
@@ -265,11 +386,22 @@ func (t writableMerkleTree) Set(key, value []byte) error {
 	t.Lock()
 	defer t.Unlock()
 
-	err := t.tree.Insert(key, value, t.bucket)
+	previous, err := t.tree.Search(key, nil, t.bucket)
 	if err != nil {
 		return xerrors.Errorf("couldn't insert pair: %v", err)
 	}
 
+	err = t.tree.Insert(key, value, t.bucket)
+	if err != nil {
+		return xerrors.Errorf("couldn't insert pair: %v", err)
+	}
+
+	t.writes = append(t.writes, store.Write{
+		Key:      append([]byte{}, key...),
+		Value:    append([]byte{}, value...),
+		Previous: previous,
+	})
+
 	return nil
 }
 
@@ -278,10 +410,21 @@ func (t writableMerkleTree) Delete(key []byte) error {
 	t.Lock()
 	defer t.Unlock()
 
-	err := t.tree.Delete(key, t.bucket)
+	previous, err := t.tree.Search(key, nil, t.bucket)
 	if err != nil {
 		return xerrors.Errorf("couldn't delete key: %v", err)
 	}
 
+	err = t.tree.Delete(key, t.bucket)
+	if err != nil {
+		return xerrors.Errorf("couldn't delete key: %v", err)
+	}
+
+	t.writes = append(t.writes, store.Write{
+		Key:      append([]byte{}, key...),
+		Deleted:  true,
+		Previous: previous,
+	})
+
 	return nil
 }
@@ -10,6 +10,7 @@ import (
 	"testing/quick"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/memory"
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/store/hashtree"
 	"go.dedis.ch/dela/core/store/kv"
@@ -190,6 +191,73 @@ func TestMerkleTree_Load(t *testing.T) {
 	require.EqualError(t, err, fake.Err("while updating: failed to prepare: empty node failed"))
 }
 
+func TestMerkleTree_CalculateRoot(t *testing.T) {
+	db, clean := makeDB(t)
+	defer clean()
+
+	tree := NewMerkleTree(db, Nonce{})
+	tree.tree.memDepth = 5
+
+	next, err := tree.Stage(func(snap store.Snapshot) error {
+		for i := 0; i < 100; i++ {
+			key := [MaxDepth]byte{}
+			rand.Read(key[:])
+
+			err := snap.Set(key[:], []byte{byte(i)})
+			require.NoError(t, err)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = next.Commit()
+	require.NoError(t, err)
+
+	root, err := next.(*MerkleTree).CalculateRoot()
+	require.NoError(t, err)
+	require.Equal(t, next.GetRoot(), root)
+
+	// A leaf missing from the disk must be noticed even though the digests
+	// of the other nodes are intact.
+	err = db.Update(func(tx kv.WritableTx) error {
+		bucket := tx.GetBucket(tree.bucket)
+
+		var leaf []byte
+		bucket.Scan([]byte{}, func(key, value []byte) error {
+			msg, err := NodeFactory{}.Deserialize(tree.tree.context, value)
+			require.NoError(t, err)
+
+			_, ok := msg.(*LeafNode)
+			if ok && leaf == nil {
+				leaf = append([]byte{}, key...)
+			}
+			return nil
+		})
+
+		return bucket.Delete(leaf)
+	})
+	require.NoError(t, err)
+
+	root, err = next.(*MerkleTree).CalculateRoot()
+	require.NoError(t, err)
+	require.NotEqual(t, next.GetRoot(), root)
+
+	tree = NewMerkleTree(fakeDB{}, Nonce{})
+	root, err = tree.CalculateRoot()
+	require.NoError(t, err)
+	require.NotEmpty(t, root)
+
+	tree.tx = fakeTx{bucket: &fakeBucket{errScan: fake.GetError()}}
+	_, err = tree.CalculateRoot()
+	require.EqualError(t, err, fake.Err("while scanning"))
+
+	tree.tx = nil
+	tree.hashFactory = fake.NewHashFactory(fake.NewBadHash())
+	_, err = tree.CalculateRoot()
+	require.EqualError(t, err,
+		fake.Err("while calculating: failed to prepare: empty node failed"))
+}
+
 func TestMerkleTree_Get(t *testing.T) {
 	tree := NewMerkleTree(fakeDB{}, Nonce{})
 
@@ -293,6 +361,107 @@ func TestMerkleTree_Commit(t *testing.T) {
 	require.EqualError(t, err, fake.Err("failed to persist tree: read bucket failed"))
 }
 
+func TestMerkleTree_MemoryBudget(t *testing.T) {
+	db, clean := makeDB(t)
+	defer clean()
+
+	budget := memory.NewBudget(memory.TrieModule, 100*estimatedNodeSize)
+
+	tree := NewMerkleTree(db, Nonce{}, WithMemoryBudget(budget))
+	values := map[[MaxDepth]byte][]byte{}
+
+	next, err := tree.Stage(func(snap store.Snapshot) error {
+		for i := 0; i < 500; i++ {
+			key := [MaxDepth]byte{}
+			rand.Read(key[:])
+
+			values[key] = []byte{byte(i)}
+
+			err := snap.Set(key[:], values[key])
+			require.NoError(t, err)
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, next.Commit())
+
+	usage := budget.GetUsage()
+	require.LessOrEqual(t, usage.Used, usage.Limit)
+	require.Greater(t, usage.Evictions, uint64(0))
+
+	// The nodes moved to the disk are loaded when needed.
+	for key, value := range values {
+		res, err := next.Get(key[:])
+		require.NoError(t, err)
+		require.Equal(t, value, res)
+	}
+
+	// The tree loaded from the disk fits in the budget as well.
+	tree = NewMerkleTree(db, Nonce{}, WithMemoryBudget(budget))
+	require.NoError(t, tree.Load())
+	require.LessOrEqual(t, budget.GetUsage().Used, usage.Limit)
+	require.Equal(t, next.GetRoot(), tree.GetRoot())
+}
+
+func TestMerkleTree_Hooks(t *testing.T) {
+	db, clean := makeDB(t)
+	defer clean()
+
+	tree := NewMerkleTree(db, Nonce{})
+
+	var writes []store.Write
+	tree.AddHook(store.HookFunc(func(tx store.Transaction, w []store.Write) error {
+		_, ok := tx.(kv.WritableTx)
+		require.True(t, ok)
+
+		writes = w
+		return nil
+	}))
+
+	next, err := tree.Stage(func(snap store.Snapshot) error {
+		require.NoError(t, snap.Set([]byte("ping"), []byte("pong")))
+		require.NoError(t, snap.Set([]byte("ping"), []byte("pang")))
+		require.NoError(t, snap.Delete([]byte("pong")))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Nil(t, writes)
+
+	err = db.Update(func(txn kv.WritableTx) error {
+		return next.WithTx(txn).Commit()
+	})
+	require.NoError(t, err)
+
+	expected := []store.Write{
+		{Key: []byte("ping"), Value: []byte("pong")},
+		{Key: []byte("ping"), Value: []byte("pang"), Previous: []byte("pong")},
+		{Key: []byte("pong"), Deleted: true},
+	}
+	require.Equal(t, expected, writes)
+
+	tree.AddHook(store.HookFunc(func(store.Transaction, []store.Write) error {
+		return fake.GetError()
+	}))
+
+	next, err = tree.Stage(func(snap store.Snapshot) error {
+		return snap.Set([]byte("pong"), []byte("ping"))
+	})
+	require.NoError(t, err)
+
+	err = next.Commit()
+	require.EqualError(t, err, fake.Err("failed to persist tree: hook failed"))
+
+	// The writes of the failed commit must not be persisted.
+	loaded := NewMerkleTree(db, Nonce{})
+	require.NoError(t, loaded.Load())
+
+	value, err := loaded.Get([]byte("pong"))
+	require.NoError(t, err)
+	require.Nil(t, value)
+}
+
 func TestWritableMerkleTree_Set(t *testing.T) {
 	tree := writableMerkleTree{MerkleTree: NewMerkleTree(fakeDB{}, Nonce{})}
 
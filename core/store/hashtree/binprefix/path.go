@@ -35,6 +35,25 @@ func newPath(nonce, key []byte) Path {
 	}
 }
 
+// NewPath returns the path to the key from the nonce of the tree, the value of
+// the key, or nil when the path proves its absence, and the hashes of the
+// interior nodes from the root. The root is computed from them so that a path
+// received from another node is verified against the root of a block.
+func NewPath(fac crypto.HashFactory, nonce, key, value []byte, interiors [][]byte) (Path, error) {
+	path := newPath(nonce, key)
+	path.value = value
+	path.interiors = interiors
+
+	root, err := path.computeRoot(fac)
+	if err != nil {
+		return path, xerrors.Errorf("failed to compute root: %v", err)
+	}
+
+	path.root = root
+
+	return path, nil
+}
+
 // GetKey implements hashtree.Path. It returns the key associated to the path.
 func (s Path) GetKey() []byte {
 	return s.key
@@ -45,6 +64,17 @@ func (s Path) GetValue() []byte {
 	return s.value
 }
 
+// GetNonce returns the nonce of the tree the path belongs to.
+func (s Path) GetNonce() []byte {
+	return s.nonce
+}
+
+// GetInteriors returns the hashes of the siblings of the interior nodes from
+// the root to the end of the path.
+func (s Path) GetInteriors() [][]byte {
+	return s.interiors
+}
+
 // GetRoot implements hashtree.Path. It returns the hash of the root node
 // calculated from the leaf up to the root.
 func (s Path) GetRoot() []byte {
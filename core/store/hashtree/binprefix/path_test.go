@@ -4,9 +4,45 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/internal/testing/fake"
 )
 
+func TestNewPath(t *testing.T) {
+	db, clean := makeDB(t)
+	defer clean()
+
+	tree := NewMerkleTree(db, Nonce{1})
+
+	staged, err := tree.Stage(func(snap store.Snapshot) error {
+		for _, key := range []string{"A", "B", "C"} {
+			err := snap.Set([]byte(key), []byte("value "+key))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	for _, key := range []string{"A", "B", "C"} {
+		path, err := staged.GetPath([]byte(key))
+		require.NoError(t, err)
+
+		p := path.(Path)
+
+		other, err := NewPath(crypto.NewSha256Factory(), p.GetNonce(), p.GetKey(),
+			p.GetValue(), p.GetInteriors())
+		require.NoError(t, err)
+		require.Equal(t, staged.GetRoot(), other.GetRoot())
+	}
+
+	_, err = NewPath(fake.NewHashFactory(fake.NewBadHash()), nil, []byte("A"), nil, nil)
+	require.EqualError(t, err, fake.Err("failed to compute root: while preparing: empty node failed"))
+}
+
 func TestPath_GetKey(t *testing.T) {
 	path := newPath([]byte{}, []byte("ping"))
 
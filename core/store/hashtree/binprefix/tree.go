@@ -9,6 +9,7 @@ import (
 	"math"
 	"math/big"
 
+	"go.dedis.ch/dela/core/memory"
 	"go.dedis.ch/dela/core/store/kv"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/serde"
@@ -41,6 +42,10 @@ const (
 	diskNodeType
 )
 
+// estimatedNodeSize is the estimated number of bytes used by a node in memory,
+// which includes its prefix and its digest.
+const estimatedNodeSize = 160
+
 var nodeFormats = registry.NewSimpleRegistry()
 
 // TreeNode is the interface for the different types of nodes that a Merkle tree
@@ -292,6 +297,88 @@ func (t *Tree) toDisk(depth uint16, prefix *big.Int, node TreeNode, b kv.Bucket,
 	return nil
 }
 
+// Fit accounts for the nodes kept in memory in the budget. When they exceed its
+// cap, the memory depth is lowered so that the deepest nodes are moved to the
+// disk, and they are loaded again when the tree is traversed.
+func (t *Tree) Fit(budget *memory.Budget, b kv.Bucket) error {
+	counts := t.countByDepth()
+
+	used := 0
+	for _, num := range counts {
+		used += num * estimatedNodeSize
+	}
+
+	limit := budget.GetLimit()
+	if limit <= 0 || int64(used) <= limit {
+		budget.Set(int64(used))
+		return nil
+	}
+
+	// With a memory depth of d, the nodes down to the depth d+1 stay in memory
+	// as the children of the nodes at the memory depth become disk nodes.
+	depth := 0
+	kept := counts[0] + counts[1]
+	for depth+2 < len(counts) && int64((kept+counts[depth+2])*estimatedNodeSize) <= limit {
+		kept += counts[depth+2]
+		depth++
+	}
+
+	if depth >= t.memDepth {
+		budget.Set(int64(used))
+		return nil
+	}
+
+	t.memDepth = depth
+
+	err := t.Persist(b)
+	if err != nil {
+		return xerrors.Errorf("failed to persist: %v", err)
+	}
+
+	after := 0
+	for _, num := range t.countByDepth() {
+		after += num * estimatedNodeSize
+	}
+
+	budget.Set(int64(after))
+	budget.Evict(used - after)
+
+	return nil
+}
+
+// countByDepth returns the number of nodes in memory at each depth.
+func (t *Tree) countByDepth() []int {
+	counts := []int{0, 0}
+
+	t.root.Visit(func(n TreeNode) error {
+		depth := depthOf(n)
+		for len(counts) <= depth {
+			counts = append(counts, 0)
+		}
+
+		counts[depth]++
+
+		return nil
+	})
+
+	return counts
+}
+
+func depthOf(n TreeNode) int {
+	switch node := n.(type) {
+	case *InteriorNode:
+		return int(node.depth)
+	case *EmptyNode:
+		return int(node.depth)
+	case *LeafNode:
+		return int(node.depth)
+	case *DiskNode:
+		return int(node.depth)
+	default:
+		return 0
+	}
+}
+
 // Clone returns a deep copy of the tree.
 func (t *Tree) Clone() *Tree {
 	return &Tree{
@@ -7,6 +7,7 @@ import (
 	"testing/quick"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/memory"
 	"go.dedis.ch/dela/core/store/kv"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/internal/testing/fake"
@@ -234,6 +235,58 @@ func TestTree_Persist(t *testing.T) {
 		fake.Err("visiting empty: failed to clean subtree"))
 }
 
+func TestTree_Fit(t *testing.T) {
+	bucket := &fakeBucket{}
+
+	tree := NewTree(Nonce{})
+
+	for i := 0; i <= math.MaxUint8; i++ {
+		key := []byte{byte(i)}
+
+		err := tree.Insert(key[:], key[:], bucket)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tree.CalculateRoot(crypto.NewSha256Factory(), bucket))
+	require.NoError(t, tree.Persist(bucket))
+
+	// 2^9-1 nodes with the leaves as disk nodes.
+	used := int64(511 * estimatedNodeSize)
+
+	// Without a cap, the usage is only reported.
+	budget := memory.NewBudget(memory.TrieModule, 0)
+	require.NoError(t, tree.Fit(budget, bucket))
+	require.Equal(t, used, budget.GetUsage().Used)
+
+	budget.SetLimit(used)
+	require.NoError(t, tree.Fit(budget, bucket))
+	require.Equal(t, uint64(0), budget.GetUsage().Evictions)
+
+	// The nodes down to the depth 5 fit in the budget.
+	budget.SetLimit(70 * estimatedNodeSize)
+	require.NoError(t, tree.Fit(budget, bucket))
+	require.Equal(t, 4, tree.memDepth)
+	require.Equal(t, int64(63*estimatedNodeSize), budget.GetUsage().Used)
+	require.Equal(t, uint64(used-63*estimatedNodeSize), budget.GetUsage().Evictions)
+
+	// The memory depth cannot be lowered further when the budget is too small.
+	budget.SetLimit(1)
+	require.NoError(t, tree.Fit(budget, bucket))
+	require.Equal(t, 0, tree.memDepth)
+	require.Equal(t, int64(3*estimatedNodeSize), budget.GetUsage().Used)
+
+	require.NoError(t, tree.Fit(budget, bucket))
+	require.Equal(t, 0, tree.memDepth)
+
+	tree = NewTree(Nonce{})
+	require.NoError(t, tree.Insert([]byte{1}, []byte{1}, bucket))
+	require.NoError(t, tree.Insert([]byte{2}, []byte{2}, bucket))
+
+	err := tree.Fit(budget, &fakeBucket{errScan: fake.GetError()})
+	require.EqualError(t, err,
+		fake.Err("failed to persist: visiting leaf: failed to clean subtree"))
+}
+
 func TestTree_Clone(t *testing.T) {
 	tree := NewTree(Nonce{})
 
@@ -30,7 +30,7 @@ func (m minimalController) SetCommands(builder node.Builder) {}
 // OnStart implements node.Initializer. It opens the database in a file using
 // the config path as the base.
 func (m minimalController) OnStart(flags cli.Flags, inj node.Injector) error {
-	db, err := kv.New(filepath.Join(flags.String("config"), "dela.db"))
+	db, err := kv.New(filepath.Join(flags.Path("config"), "dela.db"))
 	if err != nil {
 		return xerrors.Errorf("db: %v", err)
 	}
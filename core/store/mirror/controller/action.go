@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/store/diff"
+	"go.dedis.ch/dela/core/store/kv"
+	"go.dedis.ch/dela/core/store/mirror"
+	"golang.org/x/xerrors"
+
+	// The PostgreSQL driver is registered for the default driver name.
+	_ "github.com/lib/pq"
+)
+
+// sqlOpen is the function called to open the SQL database. It allows the tests
+// to use a fake database.
+var sqlOpen = func(driver, dsn string) (mirror.SQL, error) {
+	return sql.Open(driver, dsn)
+}
+
+// startAction is an action to start the mirror of the store.
+//
+// - implements node.ActionTemplate
+type startAction struct{}
+
+// Execute implements node.ActionTemplate. It creates the tables of the mappings
+// and starts the exporter in the background.
+func (startAction) Execute(ctx node.Context) error {
+	var journal diff.Journal
+	err := ctx.Injector.Resolve(&journal)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var db kv.DB
+	err = ctx.Injector.Resolve(&db)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	mappings, err := parseMappings(ctx.Flags.StringSlice(mapFlag))
+	if err != nil {
+		return xerrors.Errorf("invalid mappings: %v", err)
+	}
+
+	sqldb, err := sqlOpen(ctx.Flags.String(driverFlag), ctx.Flags.String(dsnFlag))
+	if err != nil {
+		return xerrors.Errorf("failed to open database: %v", err)
+	}
+
+	opts := []mirror.Option{}
+
+	interval := ctx.Flags.Duration(intervalFlag)
+	if interval > 0 {
+		opts = append(opts, mirror.WithInterval(interval))
+	}
+
+	exporter, err := mirror.NewExporter(journal, sqldb, db, mappings, opts...)
+	if err != nil {
+		return xerrors.Errorf("exporter: %v", err)
+	}
+
+	err = exporter.Init()
+	if err != nil {
+		return xerrors.Errorf("failed to init: %v", err)
+	}
+
+	ctx.Injector.Inject(exporter)
+
+	go exporter.Listen()
+
+	fmt.Fprintf(ctx.Out, "mirror started with %d table(s)", len(mappings))
+
+	return nil
+}
+
+// parseMappings parses the list of mappings in the format
+// <table>[:<type>]=<prefix>, where the type is the one of the value column.
+func parseMappings(values []string) ([]mirror.Mapping, error) {
+	mappings := make([]mirror.Mapping, len(values))
+
+	for i, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 {
+			return nil, xerrors.Errorf("expected <table>[:<type>]=<prefix>, got '%s'", value)
+		}
+
+		mapping := mirror.Mapping{
+			Table:  parts[0],
+			Prefix: []byte(parts[1]),
+		}
+
+		table := strings.SplitN(parts[0], ":", 2)
+		if len(table) == 2 {
+			typ, err := mirror.ParseColumnType(table[1])
+			if err != nil {
+				return nil, xerrors.Errorf("table '%s': %v", table[0], err)
+			}
+
+			mapping.Table = table[0]
+			mapping.ValueType = typ
+		}
+
+		mappings[i] = mapping
+	}
+
+	return mappings, nil
+}
@@ -0,0 +1,80 @@
+// Package controller implements a CLI controller to start the SQL mirror of
+// the store.
+package controller
+
+import (
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/store/mirror"
+)
+
+const (
+	driverFlag   = "driver"
+	dsnFlag      = "dsn"
+	mapFlag      = "map"
+	intervalFlag = "interval"
+)
+
+// NewController returns a new controller that provides the command to start
+// the SQL mirror.
+func NewController() node.Initializer {
+	return minimal{}
+}
+
+// minimal is an initializer with the command to start the mirror. The mirror is
+// optional and only runs once it is explicitly started.
+//
+// - implements node.Initializer
+type minimal struct{}
+
+// SetCommands implements node.Initializer. It sets the command to start the
+// mirror.
+func (minimal) SetCommands(builder node.Builder) {
+	cmd := builder.SetCommand("mirror")
+	cmd.SetDescription("Mirror the state of the store into SQL tables")
+
+	sub := cmd.SetSubCommand("start")
+	sub.SetDescription("start exporting the changes of the state")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name:  driverFlag,
+			Usage: "the name of the database/sql driver, postgres is registered",
+			Value: "postgres",
+		},
+		cli.StringFlag{
+			Name:     dsnFlag,
+			Usage:    "the data source name of the SQL database",
+			Required: true,
+		},
+		cli.StringSliceFlag{
+			Name: mapFlag,
+			Usage: "the mapping of a key prefix to a table, as <table>[:<type>]=<prefix> " +
+				"where the type of the values is bytea, text, jsonb or bigint",
+			Required: true,
+		},
+		cli.DurationFlag{
+			Name:  intervalFlag,
+			Usage: "the time between two synchronizations",
+			Value: mirror.DefaultInterval,
+		},
+	)
+
+	sub.SetAction(builder.MakeAction(node.Destructive(startAction{})))
+}
+
+// OnStart implements node.Initializer. It does nothing as the mirror is started
+// by a command.
+func (minimal) OnStart(flags cli.Flags, inj node.Injector) error {
+	return nil
+}
+
+// OnStop implements node.Initializer. It stops the mirror if it is running.
+func (minimal) OnStop(inj node.Injector) error {
+	var exporter *mirror.Exporter
+	err := inj.Resolve(&exporter)
+	if err == nil {
+		exporter.Stop()
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/store/diff"
+	"go.dedis.ch/dela/core/store/mirror"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestMinimal_SetCommands(t *testing.T) {
+	m := NewController()
+
+	b := node.NewBuilder()
+	m.SetCommands(b)
+}
+
+func TestMinimal_OnStart(t *testing.T) {
+	err := NewController().OnStart(make(node.FlagSet), node.NewInjector())
+	require.NoError(t, err)
+}
+
+func TestMinimal_OnStop(t *testing.T) {
+	exporter, err := mirror.NewExporter(nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	inj := node.NewInjector()
+	inj.Inject(exporter)
+
+	err = NewController().OnStop(inj)
+	require.NoError(t, err)
+
+	err = NewController().OnStop(node.NewInjector())
+	require.NoError(t, err)
+}
+
+func TestParseMappings(t *testing.T) {
+	mappings, err := parseMappings([]string{"values=value:", "docs:jsonb=doc:"})
+	require.NoError(t, err)
+	require.Len(t, mappings, 2)
+	require.Equal(t, "values", mappings[0].Table)
+	require.Equal(t, mirror.ColumnType(""), mappings[0].ValueType)
+	require.Equal(t, "docs", mappings[1].Table)
+	require.Equal(t, []byte("doc:"), mappings[1].Prefix)
+	require.Equal(t, mirror.JSONType, mappings[1].ValueType)
+}
+
+func TestStartAction_Execute(t *testing.T) {
+	oldOpen := sqlOpen
+	defer func() {
+		sqlOpen = oldOpen
+	}()
+
+	sqlOpen = func(driver, dsn string) (mirror.SQL, error) {
+		require.Equal(t, "postgres", driver)
+		return fakeSQL{}, nil
+	}
+
+	flags := node.FlagSet{
+		driverFlag:   "postgres",
+		dsnFlag:      "dsn",
+		mapFlag:      []interface{}{"values=value:"},
+		intervalFlag: float64(time.Hour),
+	}
+
+	db := fake.NewInMemoryDB()
+
+	out := new(bytes.Buffer)
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Flags:    flags,
+		Out:      out,
+	}
+
+	ctx.Injector.Inject(diff.NewJournal(db))
+	ctx.Injector.Inject(db)
+
+	err := startAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "mirror started with 1 table(s)", out.String())
+
+	var exporter *mirror.Exporter
+	require.NoError(t, ctx.Injector.Resolve(&exporter))
+	exporter.Stop()
+
+	flags[mapFlag] = []interface{}{"values"}
+	err = startAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"invalid mappings: expected <table>[:<type>]=<prefix>, got 'values'")
+
+	flags[mapFlag] = []interface{}{"values:xml=value:"}
+	err = startAction{}.Execute(ctx)
+	require.EqualError(t, err, "invalid mappings: table 'values': unknown column type 'xml'")
+
+	flags[mapFlag] = []interface{}{"1values=value:"}
+	err = startAction{}.Execute(ctx)
+	require.EqualError(t, err, "exporter: invalid identifier '1values'")
+
+	sqlOpen = func(driver, dsn string) (mirror.SQL, error) {
+		return nil, fake.GetError()
+	}
+	err = startAction{}.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to open database"))
+
+	ctx.Injector = node.NewInjector()
+	err = startAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for 'diff.Journal'")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeSQL struct{}
+
+func (fakeSQL) Exec(string, ...interface{}) (sql.Result, error) {
+	return nil, nil
+}
@@ -0,0 +1,350 @@
+// Package mirror implements an exporter that mirrors the state of the store
+// into SQL tables, so that applications can run rich queries without reading
+// the trie.
+//
+// The exporter consumes the stream of changes of the diff journal and applies
+// them to the tables according to a mapping of the key prefixes. The statements
+// follow the PostgreSQL dialect, and the value column of a table is typed so
+// that the values can be queried as text, JSON documents or integers.
+package mirror
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/store/diff"
+	"go.dedis.ch/dela/core/store/kv"
+	"golang.org/x/xerrors"
+)
+
+// DefaultInterval is the default time between two synchronizations.
+const DefaultInterval = 5 * time.Second
+
+var (
+	cursorBucket = []byte("sql-mirror")
+	cursorKey    = []byte("cursor")
+
+	identifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Source is the interface of the stream of changes of the store.
+type Source interface {
+	// Len returns the number of heights available.
+	Len() (uint64, error)
+
+	// Stream calls the function for each change between the two heights, both
+	// included.
+	Stream(from, to uint64, fn func(diff.Change) error) error
+}
+
+// SQL is the interface of the database the state is mirrored into. It is
+// implemented by *sql.DB.
+type SQL interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// ColumnType is the SQL type of the value column of a table, which defines how
+// the values of the store are converted.
+type ColumnType string
+
+const (
+	// ByteaType stores the values as they are.
+	ByteaType ColumnType = "bytea"
+
+	// TextType stores the values as UTF-8 strings.
+	TextType ColumnType = "text"
+
+	// JSONType stores the values as JSON documents.
+	JSONType ColumnType = "jsonb"
+
+	// BigIntType stores the values written as decimal integers.
+	BigIntType ColumnType = "bigint"
+)
+
+// convert returns the value of the store converted for the column type, or an
+// error if the value does not fit the type.
+func (t ColumnType) convert(value []byte) (interface{}, error) {
+	switch t {
+	case TextType:
+		if !utf8.Valid(value) {
+			return nil, xerrors.New("invalid UTF-8 string")
+		}
+
+		return string(value), nil
+	case JSONType:
+		if !json.Valid(value) {
+			return nil, xerrors.New("invalid JSON document")
+		}
+
+		return string(value), nil
+	case BigIntType:
+		num, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid integer: %v", err)
+		}
+
+		return num, nil
+	default:
+		return value, nil
+	}
+}
+
+// Mapping describes the table that mirrors the keys starting with a prefix.
+// The prefix is removed from the key before it is written in the table.
+type Mapping struct {
+	Prefix      []byte
+	Table       string
+	KeyColumn   string
+	ValueColumn string
+	// ValueType is the type of the value column, which is bytea by default.
+	ValueType ColumnType
+}
+
+// ParseColumnType returns the column type of the name, or an error if it is
+// not supported.
+func ParseColumnType(name string) (ColumnType, error) {
+	t := ColumnType(strings.ToLower(name))
+
+	switch t {
+	case ByteaType, TextType, JSONType, BigIntType:
+		return t, nil
+	default:
+		return "", xerrors.Errorf("unknown column type '%s'", name)
+	}
+}
+
+// Option is the type of option to set some fields of an exporter.
+type Option func(*Exporter)
+
+// WithInterval is an option to set the time between two synchronizations.
+func WithInterval(interval time.Duration) Option {
+	return func(e *Exporter) {
+		e.interval = interval
+	}
+}
+
+// Exporter mirrors the changes of the store into SQL tables. The height of the
+// last export is saved in a key/value database so that it resumes where it
+// stopped. The changes are idempotent, which means that a height interrupted
+// in the middle is exported again entirely on restart.
+type Exporter struct {
+	sync.Mutex
+
+	source   Source
+	db       SQL
+	cursor   kv.DB
+	mappings []Mapping
+	interval time.Duration
+	closing  chan struct{}
+}
+
+// NewExporter creates a new exporter that mirrors the changes of the source in
+// the SQL database according to the mappings. It returns an error if a mapping
+// uses an invalid identifier.
+func NewExporter(source Source, db SQL, cursor kv.DB, mappings []Mapping, opts ...Option) (*Exporter, error) {
+	checked := make([]Mapping, len(mappings))
+
+	for i, m := range mappings {
+		if m.KeyColumn == "" {
+			m.KeyColumn = "key"
+		}
+
+		if m.ValueColumn == "" {
+			m.ValueColumn = "value"
+		}
+
+		if m.ValueType == "" {
+			m.ValueType = ByteaType
+		}
+
+		_, err := ParseColumnType(string(m.ValueType))
+		if err != nil {
+			return nil, xerrors.Errorf("table '%s': %v", m.Table, err)
+		}
+
+		for _, name := range []string{m.Table, m.KeyColumn, m.ValueColumn} {
+			if !identifierRegex.MatchString(name) {
+				return nil, xerrors.Errorf("invalid identifier '%s'", name)
+			}
+		}
+
+		checked[i] = m
+	}
+
+	e := &Exporter{
+		source:   source,
+		db:       db,
+		cursor:   cursor,
+		mappings: checked,
+		interval: DefaultInterval,
+		closing:  make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e, nil
+}
+
+// Init creates the tables of the mappings if they do not exist.
+func (e *Exporter) Init() error {
+	for _, m := range e.mappings {
+		query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s BYTEA PRIMARY KEY, %s %s NOT NULL)",
+			m.Table, m.KeyColumn, m.ValueColumn, strings.ToUpper(string(m.ValueType)))
+
+		_, err := e.db.Exec(query)
+		if err != nil {
+			return xerrors.Errorf("failed to create table '%s': %v", m.Table, err)
+		}
+	}
+
+	return nil
+}
+
+// Sync exports the heights that are not yet mirrored. The cursor is moved
+// forward after each height.
+func (e *Exporter) Sync() error {
+	e.Lock()
+	defer e.Unlock()
+
+	next, err := e.readCursor()
+	if err != nil {
+		return xerrors.Errorf("failed to read cursor: %v", err)
+	}
+
+	length, err := e.source.Len()
+	if err != nil {
+		return xerrors.Errorf("source: %v", err)
+	}
+
+	for height := next; height < length; height++ {
+		err = e.source.Stream(height, height, e.apply)
+		if err != nil {
+			return xerrors.Errorf("failed to export height %d: %v", height, err)
+		}
+
+		err = e.writeCursor(height + 1)
+		if err != nil {
+			return xerrors.Errorf("failed to write cursor: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Listen synchronizes the tables periodically until the exporter is stopped.
+// This call is blocking.
+func (e *Exporter) Listen() {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		err := e.Sync()
+		if err != nil {
+			dela.Logger.Warn().Err(err).Msg("sql mirror failed to sync")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-e.closing:
+			return
+		}
+	}
+}
+
+// Stop stops the exporter.
+func (e *Exporter) Stop() {
+	close(e.closing)
+}
+
+// apply writes the change in the table of the mapping matching the key, if
+// any. A value that does not fit the type of the column is skipped so that it
+// does not block the mirror.
+func (e *Exporter) apply(change diff.Change) error {
+	for _, m := range e.mappings {
+		if !bytes.HasPrefix(change.Key, m.Prefix) {
+			continue
+		}
+
+		key := change.Key[len(m.Prefix):]
+
+		if change.New == nil {
+			query := fmt.Sprintf("DELETE FROM %s WHERE %s = $1", m.Table, m.KeyColumn)
+
+			_, err := e.db.Exec(query, key)
+			if err != nil {
+				return xerrors.Errorf("failed to delete: %v", err)
+			}
+
+			return nil
+		}
+
+		value, err := m.ValueType.convert(change.New)
+		if err != nil {
+			dela.Logger.Warn().Err(err).
+				Str("table", m.Table).
+				Hex("key", change.Key).
+				Msg("sql mirror skipped a value")
+
+			return nil
+		}
+
+		query := fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES ($1, $2) "+
+			"ON CONFLICT (%s) DO UPDATE SET %s = EXCLUDED.%s",
+			m.Table, m.KeyColumn, m.ValueColumn, m.KeyColumn, m.ValueColumn, m.ValueColumn)
+
+		_, err = e.db.Exec(query, key, value)
+		if err != nil {
+			return xerrors.Errorf("failed to upsert: %v", err)
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
+func (e *Exporter) readCursor() (uint64, error) {
+	var next uint64
+
+	err := e.cursor.View(func(tx kv.ReadableTx) error {
+		bucket := tx.GetBucket(cursorBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		data := bucket.Get(cursorKey)
+		if len(data) == 8 {
+			next = binary.BigEndian.Uint64(data)
+		}
+
+		return nil
+	})
+
+	return next, err
+}
+
+func (e *Exporter) writeCursor(next uint64) error {
+	return e.cursor.Update(func(tx kv.WritableTx) error {
+		bucket, err := tx.GetBucketOrCreate(cursorBucket)
+		if err != nil {
+			return err
+		}
+
+		data := make([]byte, 8)
+		binary.BigEndian.PutUint64(data, next)
+
+		return bucket.Set(cursorKey, data)
+	})
+}
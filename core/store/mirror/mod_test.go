@@ -0,0 +1,232 @@
+package mirror
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/store/diff"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestNewExporter(t *testing.T) {
+	mappings := []Mapping{{Prefix: []byte("A"), Table: "values"}}
+
+	e, err := NewExporter(fakeSource{}, &fakeSQL{}, fake.NewInMemoryDB(), mappings,
+		WithInterval(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, time.Second, e.interval)
+	require.Equal(t, "key", e.mappings[0].KeyColumn)
+	require.Equal(t, "value", e.mappings[0].ValueColumn)
+	require.Equal(t, ByteaType, e.mappings[0].ValueType)
+	require.Empty(t, mappings[0].KeyColumn)
+
+	mappings[0].ValueType = "xml"
+	_, err = NewExporter(fakeSource{}, &fakeSQL{}, fake.NewInMemoryDB(), mappings)
+	require.EqualError(t, err, "table 'values': unknown column type 'xml'")
+
+	mappings[0].ValueType = ""
+	mappings[0].Table = "values; DROP TABLE users"
+	_, err = NewExporter(fakeSource{}, &fakeSQL{}, fake.NewInMemoryDB(), mappings)
+	require.EqualError(t, err, "invalid identifier 'values; DROP TABLE users'")
+}
+
+func TestExporter_Init(t *testing.T) {
+	db := &fakeSQL{}
+	mappings := []Mapping{{Table: "values", KeyColumn: "k", ValueColumn: "v"}}
+
+	e, err := NewExporter(fakeSource{}, db, fake.NewInMemoryDB(), mappings)
+	require.NoError(t, err)
+
+	err = e.Init()
+	require.NoError(t, err)
+	require.Equal(t, []string{"CREATE TABLE IF NOT EXISTS values (k BYTEA PRIMARY KEY, v BYTEA NOT NULL)"},
+		db.queries)
+
+	db.err = fake.GetError()
+	err = e.Init()
+	require.EqualError(t, err, fake.Err("failed to create table 'values'"))
+
+	db = &fakeSQL{}
+	mappings[0].ValueType = JSONType
+
+	e, err = NewExporter(fakeSource{}, db, fake.NewInMemoryDB(), mappings)
+	require.NoError(t, err)
+
+	err = e.Init()
+	require.NoError(t, err)
+	require.Equal(t, []string{"CREATE TABLE IF NOT EXISTS values (k BYTEA PRIMARY KEY, v JSONB NOT NULL)"},
+		db.queries)
+}
+
+func TestExporter_Typed_Sync(t *testing.T) {
+	source := fakeSource{
+		changes: [][]diff.Change{
+			{
+				{Key: []byte("T1"), New: []byte("abc")},
+				{Key: []byte("T2"), New: []byte{0xff}},
+				{Key: []byte("J1"), New: []byte(`{"a":1}`)},
+				{Key: []byte("J2"), New: []byte(`{"a":`)},
+				{Key: []byte("I1"), New: []byte("42")},
+				{Key: []byte("I2"), New: []byte("a")},
+			},
+		},
+	}
+
+	db := &fakeSQL{}
+	mappings := []Mapping{
+		{Prefix: []byte("T"), Table: "texts", ValueType: TextType},
+		{Prefix: []byte("J"), Table: "docs", ValueType: JSONType},
+		{Prefix: []byte("I"), Table: "numbers", ValueType: BigIntType},
+	}
+
+	e, err := NewExporter(source, db, makeCursor(), mappings)
+	require.NoError(t, err)
+
+	// The values that do not fit the type are skipped.
+	err = e.Sync()
+	require.NoError(t, err)
+	require.Len(t, db.queries, 3)
+	require.Equal(t, []interface{}{
+		[]byte("1"), "abc",
+		[]byte("1"), `{"a":1}`,
+		[]byte("1"), int64(42),
+	}, db.args)
+}
+
+func TestParseColumnType(t *testing.T) {
+	typ, err := ParseColumnType("JSONB")
+	require.NoError(t, err)
+	require.Equal(t, JSONType, typ)
+
+	_, err = ParseColumnType("xml")
+	require.EqualError(t, err, "unknown column type 'xml'")
+}
+
+func TestExporter_Sync(t *testing.T) {
+	source := fakeSource{
+		changes: [][]diff.Change{
+			{
+				{Key: []byte("A1"), New: []byte("a")},
+				{Key: []byte("B1"), New: []byte("b")},
+			},
+			{
+				{Key: []byte("A1"), Old: []byte("a")},
+			},
+		},
+	}
+
+	db := &fakeSQL{}
+	cursor := makeCursor()
+	mappings := []Mapping{{Prefix: []byte("A"), Table: "values"}}
+
+	e, err := NewExporter(source, db, cursor, mappings)
+	require.NoError(t, err)
+
+	err = e.Sync()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"INSERT INTO values (key, value) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value",
+		"DELETE FROM values WHERE key = $1",
+	}, db.queries)
+	require.Equal(t, []interface{}{[]byte("1"), []byte("a"), []byte("1")}, db.args)
+
+	next, err := e.readCursor()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), next)
+
+	// Nothing new to export.
+	err = e.Sync()
+	require.NoError(t, err)
+	require.Len(t, db.queries, 2)
+
+	e.source = fakeSource{err: fake.GetError()}
+	err = e.Sync()
+	require.EqualError(t, err, fake.Err("source"))
+
+	e.cursor = fake.NewBadViewDB()
+	err = e.Sync()
+	require.EqualError(t, err, fake.Err("failed to read cursor"))
+
+	e.cursor = makeCursor()
+	e.source = source
+	db.err = fake.GetError()
+	err = e.Sync()
+	require.EqualError(t, err, fake.Err("failed to export height 0: failed to upsert"))
+
+	db.err = nil
+	e.cursor = fake.NewBadDB()
+	err = e.Sync()
+	require.EqualError(t, err, fake.Err("failed to write cursor"))
+}
+
+func TestExporter_Listen(t *testing.T) {
+	e, err := NewExporter(fakeSource{err: fake.GetError()}, &fakeSQL{},
+		fake.NewInMemoryDB(), nil, WithInterval(time.Millisecond))
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		e.Listen()
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	e.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("exporter did not stop")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeCursor() *fake.InMemoryDB {
+	db := fake.NewInMemoryDB()
+	db.SetBucket(cursorBucket, fake.NewBucket())
+
+	return db
+}
+
+type fakeSource struct {
+	changes [][]diff.Change
+	err     error
+}
+
+func (s fakeSource) Len() (uint64, error) {
+	return uint64(len(s.changes)), s.err
+}
+
+func (s fakeSource) Stream(from, to uint64, fn func(diff.Change) error) error {
+	for height := from; height <= to; height++ {
+		for _, change := range s.changes[height] {
+			err := fn(change)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+type fakeSQL struct {
+	queries []string
+	args    []interface{}
+	err     error
+}
+
+func (db *fakeSQL) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if db.err != nil {
+		return nil, db.err
+	}
+
+	db.queries = append(db.queries, query)
+	db.args = append(db.args, args...)
+
+	return nil, nil
+}
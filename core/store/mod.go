@@ -30,3 +30,33 @@ type Transaction interface {
 	// successfully commits.
 	OnCommit(func())
 }
+
+// Write is a single modification applied to a store. The value is nil when the
+// key is deleted, and the previous value is nil when the key did not exist.
+type Write struct {
+	Key      []byte
+	Value    []byte
+	Previous []byte
+	Deleted  bool
+}
+
+// Hook is the interface to implement to be notified of the writes of a store
+// when they are committed. It allows secondary indexes to be maintained within
+// the same transaction as the store, so that they are never out of sync.
+type Hook interface {
+	// OnWrite is called with the list of writes, in the order they have been
+	// applied, before the transaction of the store commits. The transaction is
+	// the one of the store implementation. Returning an error aborts the
+	// commit.
+	OnWrite(tx Transaction, writes []Write) error
+}
+
+// HookFunc is a function that can be used as a hook.
+//
+// - implements store.Hook
+type HookFunc func(tx Transaction, writes []Write) error
+
+// OnWrite implements store.Hook. It calls the function.
+func (fn HookFunc) OnWrite(tx Transaction, writes []Write) error {
+	return fn(tx, writes)
+}
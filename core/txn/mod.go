@@ -13,8 +13,11 @@
 package txn
 
 import (
+	"io"
+
 	"go.dedis.ch/dela/core/access"
 	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
 )
 
 // Transaction is what triggers a smart contract execution by passing it as part
@@ -58,3 +61,54 @@ type Manager interface {
 
 	Sync() error
 }
+
+// OpaqueTransaction is a transaction that could not be decoded, e.g. because it
+// uses an unknown format, and that is kept in its serialized form. It is only
+// created by a lenient decoding, therefore it has neither an identifier, a
+// nonce, an identity nor arguments.
+//
+// - implements txn.Transaction
+type OpaqueTransaction struct {
+	serde.RawMessage
+}
+
+// NewOpaqueTransaction returns an opaque transaction of the data, with the
+// error that prevented its decoding.
+func NewOpaqueTransaction(data []byte, err error) OpaqueTransaction {
+	return OpaqueTransaction{
+		RawMessage: serde.NewRawMessage(data, err),
+	}
+}
+
+// GetID implements txn.Transaction. It returns nil as the identifier cannot be
+// computed without decoding the transaction.
+func (tx OpaqueTransaction) GetID() []byte {
+	return nil
+}
+
+// GetNonce implements txn.Transaction. It returns zero.
+func (tx OpaqueTransaction) GetNonce() uint64 {
+	return 0
+}
+
+// GetIdentity implements txn.Transaction. It returns nil.
+func (tx OpaqueTransaction) GetIdentity() access.Identity {
+	return nil
+}
+
+// GetArg implements txn.Transaction. It returns nil.
+func (tx OpaqueTransaction) GetArg(key string) []byte {
+	return nil
+}
+
+// Fingerprint implements serde.Fingerprinter. It writes the serialized data of
+// the transaction, which means that the digest of a block holding an opaque
+// transaction differs from the one of the original block.
+func (tx OpaqueTransaction) Fingerprint(w io.Writer) error {
+	_, err := w.Write(tx.GetData())
+	if err != nil {
+		return xerrors.Errorf("failed to write data: %v", err)
+	}
+
+	return nil
+}
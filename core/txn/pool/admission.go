@@ -0,0 +1,105 @@
+package pool
+
+import (
+	"sync/atomic"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrTooManyPending is the error returned when a transaction is refused because
+// its identity or its source has too many pending transactions.
+var ErrTooManyPending = xerrors.New("too many pending transactions")
+
+// Rejections is the number of transactions refused by an admission policy.
+type Rejections struct {
+	// Identity is the number of transactions refused because their identity
+	// reached the limit.
+	Identity uint64
+
+	// Source is the number of transactions refused because their source
+	// reached the limit.
+	Source uint64
+}
+
+// Admission is a policy that limits the number of pending transactions per
+// identity and per source, so that a single client cannot flood the pool and
+// therefore the ordering service. The source is the address a transaction was
+// submitted from, which is unknown for the transactions of the node itself and
+// for the ones received from the other participants, so that only the limit
+// per identity applies to them. A nil policy accepts every transaction.
+type Admission struct {
+	maxIdentity int
+	maxSource   int
+
+	identityRejections uint64
+	sourceRejections   uint64
+}
+
+// AdmissionOption is the type of option to configure an admission policy.
+type AdmissionOption func(*Admission)
+
+// WithIdentityLimit is an option to set the maximum number of pending
+// transactions of an identity. Zero means no limit.
+func WithIdentityLimit(n int) AdmissionOption {
+	return func(a *Admission) {
+		a.maxIdentity = n
+	}
+}
+
+// WithSourceLimit is an option to set the maximum number of pending
+// transactions submitted from a source. Zero means no limit.
+func WithSourceLimit(n int) AdmissionOption {
+	return func(a *Admission) {
+		a.maxSource = n
+	}
+}
+
+// NewAdmission returns a new admission policy without any limit unless the
+// options set them.
+func NewAdmission(opts ...AdmissionOption) *Admission {
+	a := &Admission{}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// GetRejections returns the number of transactions refused since the policy
+// exists.
+func (a *Admission) GetRejections() Rejections {
+	if a == nil {
+		return Rejections{}
+	}
+
+	return Rejections{
+		Identity: atomic.LoadUint64(&a.identityRejections),
+		Source:   atomic.LoadUint64(&a.sourceRejections),
+	}
+}
+
+// admit returns an error that wraps ErrTooManyPending if one more transaction
+// of the identity and of the source would exceed a limit, given the number of
+// their pending transactions. An empty source is never limited.
+func (a *Admission) admit(identity int, source string, fromSource int) error {
+	if a == nil {
+		return nil
+	}
+
+	if a.maxIdentity > 0 && identity >= a.maxIdentity {
+		atomic.AddUint64(&a.identityRejections, 1)
+
+		return xerrors.Errorf("identity reached the limit of %d: %w",
+			a.maxIdentity, ErrTooManyPending)
+	}
+
+	if source != "" && a.maxSource > 0 && fromSource >= a.maxSource {
+		atomic.AddUint64(&a.sourceRejections, 1)
+
+		return xerrors.Errorf("source '%s' reached the limit of %d: %w",
+			source, a.maxSource, ErrTooManyPending)
+	}
+
+	return nil
+}
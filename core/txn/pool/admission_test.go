@@ -0,0 +1,33 @@
+package pool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestAdmission_Admit(t *testing.T) {
+	a := NewAdmission(WithIdentityLimit(2), WithSourceLimit(3))
+
+	require.NoError(t, a.admit(1, "A", 2))
+	require.NoError(t, a.admit(1, "", 5))
+
+	err := a.admit(2, "A", 0)
+	require.EqualError(t, err, "identity reached the limit of 2: too many pending transactions")
+	require.True(t, xerrors.Is(err, ErrTooManyPending))
+
+	err = a.admit(0, "A", 3)
+	require.EqualError(t, err,
+		"source 'A' reached the limit of 3: too many pending transactions")
+	require.True(t, xerrors.Is(err, ErrTooManyPending))
+
+	require.Equal(t, Rejections{Identity: 1, Source: 1}, a.GetRejections())
+
+	a = NewAdmission()
+	require.NoError(t, a.admit(100, "A", 100))
+
+	a = nil
+	require.NoError(t, a.admit(100, "A", 100))
+	require.Equal(t, Rejections{}, a.GetRejections())
+}
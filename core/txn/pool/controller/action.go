@@ -6,16 +6,23 @@
 package controller
 
 import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"sync"
 
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/dilithium"
 	"go.dedis.ch/dela/crypto/loader"
 
 	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/execution/native"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/txn/pool"
+	"go.dedis.ch/dela/core/txn/pool/submit"
 	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/mino/proxy"
 	"golang.org/x/xerrors"
 )
 
@@ -50,6 +57,15 @@ func (a *addAction) Execute(ctx node.Context) error {
 		return xerrors.Errorf("failed to get args: %v", err)
 	}
 
+	level := ctx.Flags.Int(replaceFlag)
+	if level < 0 {
+		return xerrors.Errorf("invalid replacement level '%d'", level)
+	}
+
+	if level > 0 {
+		args = append(args, pool.MakeReplacementArg(uint64(level)))
+	}
+
 	signer, err := getSigner(ctx)
 	if err != nil {
 		return xerrors.Errorf("failed to get signer: %v", err)
@@ -80,6 +96,126 @@ func (a *addAction) Execute(ctx node.Context) error {
 	return nil
 }
 
+// cancelAction describes an action to cancel a pending transaction of the
+// pool. The cancellation is signed by the author of the transaction.
+//
+// - implements node.ActionTemplate
+type cancelAction struct{}
+
+// Execute implements node.ActionTemplate. It creates and signs a cancellation
+// for the transaction then adds it to the pool.
+func (a cancelAction) Execute(ctx node.Context) error {
+	var p pool.Pool
+	err := ctx.Injector.Resolve(&p)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	id, err := hex.DecodeString(ctx.Flags.String(idFlag))
+	if err != nil {
+		return xerrors.Errorf("failed to decode id: %v", err)
+	}
+
+	if len(id) == 0 {
+		return xerrors.New("missing transaction id")
+	}
+
+	nonce := ctx.Flags.Int(nonceFlag)
+	if nonce < 0 {
+		return xerrors.Errorf("invalid nonce '%d'", nonce)
+	}
+
+	signer, err := getSigner(ctx)
+	if err != nil {
+		return xerrors.Errorf("failed to get signer: %v", err)
+	}
+
+	opt := signed.WithArg(pool.CancelArg, id)
+
+	tx, err := signed.NewTransaction(uint64(nonce), signer.GetPublicKey(), opt)
+	if err != nil {
+		return xerrors.Errorf("creating cancellation: %v", err)
+	}
+
+	err = tx.Sign(signer)
+	if err != nil {
+		return xerrors.Errorf("failed to sign: %v", err)
+	}
+
+	err = p.Add(tx)
+	if err != nil {
+		return xerrors.Errorf("failed to cancel tx: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "cancellation of %x submitted", id)
+
+	return nil
+}
+
+// registerAction is an action to register the endpoint to submit transactions
+// on the proxy.
+//
+// - implements node.ActionTemplate
+type registerAction struct{}
+
+// Execute implements node.ActionTemplate. It registers the endpoint that adds
+// the transactions served by the local policy to the pool.
+func (registerAction) Execute(ctx node.Context) error {
+	var px proxy.Proxy
+	err := ctx.Injector.Resolve(&px)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var p pool.Pool
+	err = ctx.Injector.Resolve(&p)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	var exec *native.Service
+	err = ctx.Injector.Resolve(&exec)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	path := ctx.Flags.String("path")
+
+	err = px.RegisterHandler(path, submit.NewHandler(p, signed.NewTransactionFactory(), exec))
+	if err != nil {
+		return xerrors.Errorf("failed to register handler: %v", err)
+	}
+
+	fmt.Fprintf(ctx.Out, "transactions endpoint registered on %s", path)
+
+	return nil
+}
+
+// rejectionsAction is an action to print the number of transactions refused
+// by the admission policy of the pool.
+//
+// - implements node.ActionTemplate
+type rejectionsAction struct{}
+
+// Execute implements node.ActionTemplate. It prints the rejections as a JSON
+// document.
+func (rejectionsAction) Execute(ctx node.Context) error {
+	var admission *pool.Admission
+	err := ctx.Injector.Resolve(&admission)
+	if err != nil {
+		return xerrors.Errorf("injector: %v", err)
+	}
+
+	data, err := json.MarshalIndent(admission.GetRejections(), "", "  ")
+	if err != nil {
+		return xerrors.Errorf("failed to encode: %v", err)
+	}
+
+	fmt.Fprintln(ctx.Out, string(data))
+
+	return nil
+}
+
 // getArgs extracts and parses arguments from the context.
 func getArgs(ctx node.Context) ([]txn.Arg, error) {
 	inArgs := ctx.Flags.StringSlice("args")
@@ -107,7 +243,19 @@ func getSigner(ctx node.Context) (crypto.Signer, error) {
 		return nil, xerrors.Errorf("failed to load signer: %v", err)
 	}
 
-	signer, err := bls.NewSignerFromBytes(signerdata)
+	var signer crypto.Signer
+
+	algorithm := ctx.Flags.String(algorithmFlag)
+
+	switch algorithm {
+	case "", algorithmBLS:
+		signer, err = bls.NewSignerFromBytes(signerdata)
+	case algorithmMLDSA:
+		signer, err = dilithium.NewSignerFromBytes(signerdata)
+	default:
+		return nil, xerrors.Errorf("unknown algorithm '%s'", algorithm)
+	}
+
 	if err != nil {
 		return nil, xerrors.Errorf("failed to unmarshal signer: %v", err)
 	}
@@ -1,21 +1,28 @@
 package controller
 
 import (
+	"bytes"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/execution/native"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/txn/pool"
 	"go.dedis.ch/dela/core/txn/pool/mem"
 	"go.dedis.ch/dela/core/txn/signed"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/dilithium"
 	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino/proxy"
 )
 
 func TestExecute(t *testing.T) {
@@ -43,6 +50,19 @@ func TestExecute(t *testing.T) {
 	err = action.Execute(ctx)
 	require.NoError(t, err)
 
+	// The pending transaction is replaced by a higher level.
+	ctx.Flags.(node.FlagSet)[replaceFlag] = 2
+
+	err = action.Execute(ctx)
+	require.NoError(t, err)
+
+	ctx.Flags.(node.FlagSet)["args"] = []interface{}{"1", "3"}
+
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "failed to include tx: store failed: invalid replacement: level 2 is not above 2")
+
+	delete(ctx.Flags.(node.FlagSet), replaceFlag)
+
 	ctx.Injector = node.NewInjector()
 	ctx.Injector.Inject(&badPool{})
 	err = action.Execute(ctx)
@@ -79,14 +99,176 @@ func TestExecute(t *testing.T) {
 	err = action.Execute(ctx)
 	require.EqualError(t, err, "failed to get args: number of args should be even")
 
+	ctx.Flags.(node.FlagSet)["args"] = []interface{}{}
+	ctx.Flags.(node.FlagSet)[replaceFlag] = -1
+
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "invalid replacement level '-1'")
+
+	ctx.Injector = node.NewInjector()
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'pool.Pool'")
+}
+
+func TestCancelAction_Execute(t *testing.T) {
+	out := new(bytes.Buffer)
+
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Flags:    make(node.FlagSet),
+		Out:      out,
+	}
+
+	signer := bls.NewSigner()
+
+	buf, err := signer.MarshalBinary()
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(os.TempDir(), "cancel.key.buf")
+	err = ioutil.WriteFile(keyFile, buf, os.ModePerm)
+	require.NoError(t, err)
+	defer os.RemoveAll(keyFile)
+
+	tx, err := signed.NewTransaction(2, signer.GetPublicKey())
+	require.NoError(t, err)
+
+	p := mem.NewPool()
+	require.NoError(t, p.Add(tx))
+
+	ctx.Injector.Inject(p)
+	ctx.Flags.(node.FlagSet)[idFlag] = hex.EncodeToString(tx.GetID())
+	ctx.Flags.(node.FlagSet)[nonceFlag] = 2
+	ctx.Flags.(node.FlagSet)[signerFlag] = keyFile
+
+	action := cancelAction{}
+
+	err = action.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, p.Len())
+	require.Equal(t, fmt.Sprintf("cancellation of %x submitted", tx.GetID()), out.String())
+
+	ctx.Flags.(node.FlagSet)[signerFlag] = "/not/exist"
+	err = action.Execute(ctx)
+	require.Regexp(t, "^failed to get signer: failed to load signer:", err.Error())
+
+	ctx.Flags.(node.FlagSet)[nonceFlag] = -1
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "invalid nonce '-1'")
+
+	ctx.Flags.(node.FlagSet)[idFlag] = ""
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "missing transaction id")
+
+	ctx.Flags.(node.FlagSet)[idFlag] = "zz"
+	err = action.Execute(ctx)
+	require.EqualError(t, err,
+		"failed to decode id: encoding/hex: invalid byte: U+007A 'z'")
+
+	ctx.Flags.(node.FlagSet)[idFlag] = "aa"
+	ctx.Flags.(node.FlagSet)[nonceFlag] = 0
+	ctx.Flags.(node.FlagSet)[signerFlag] = keyFile
+	ctx.Injector = node.NewInjector()
+	ctx.Injector.Inject(&badPool{})
+	err = action.Execute(ctx)
+	require.EqualError(t, err, "failed to cancel tx: "+fake.Err("failed to add"))
+
 	ctx.Injector = node.NewInjector()
 	err = action.Execute(ctx)
 	require.EqualError(t, err, "injector: couldn't find dependency for 'pool.Pool'")
 }
 
+func TestRegisterAction_Execute(t *testing.T) {
+	out := new(bytes.Buffer)
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Flags:    node.FlagSet{"path": "/transactions"},
+		Out:      out,
+	}
+
+	err := registerAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'proxy.Proxy'")
+
+	px := &fakeProxy{}
+	ctx.Injector.Inject(px)
+	err = registerAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for 'pool.Pool'")
+
+	ctx.Injector.Inject(mem.NewPool())
+	err = registerAction{}.Execute(ctx)
+	require.EqualError(t, err, "injector: couldn't find dependency for '*native.Service'")
+
+	ctx.Injector.Inject(native.NewExecution())
+	err = registerAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "/transactions", px.path)
+	require.Equal(t, "transactions endpoint registered on /transactions", out.String())
+
+	px.err = fake.GetError()
+	err = registerAction{}.Execute(ctx)
+	require.EqualError(t, err, fake.Err("failed to register handler"))
+}
+
+func TestRejectionsAction_Execute(t *testing.T) {
+	out := new(bytes.Buffer)
+	ctx := node.Context{
+		Injector: node.NewInjector(),
+		Out:      out,
+	}
+
+	err := rejectionsAction{}.Execute(ctx)
+	require.EqualError(t, err,
+		"injector: couldn't find dependency for '*pool.Admission'")
+
+	ctx.Injector.Inject(pool.NewAdmission())
+	err = rejectionsAction{}.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "{\n  \"Identity\": 0,\n  \"Source\": 0\n}\n", out.String())
+}
+
+func TestGetSigner_Algorithm(t *testing.T) {
+	flags := make(node.FlagSet)
+	ctx := node.Context{Flags: flags}
+
+	signer := dilithium.NewSigner()
+
+	buf, err := signer.MarshalBinary()
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(os.TempDir(), "mldsa.buf")
+	flags[signerFlag] = keyFile
+
+	err = ioutil.WriteFile(keyFile, buf, os.ModePerm)
+	require.NoError(t, err)
+	defer os.RemoveAll(keyFile)
+
+	flags[algorithmFlag] = algorithmMLDSA
+
+	res, err := getSigner(ctx)
+	require.NoError(t, err)
+	require.True(t, res.GetPublicKey().Equal(signer.GetPublicKey()))
+
+	flags[algorithmFlag] = "unknown"
+
+	_, err = getSigner(ctx)
+	require.EqualError(t, err, "unknown algorithm 'unknown'")
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
+type fakeProxy struct {
+	proxy.Proxy
+
+	path string
+	err  error
+}
+
+func (p *fakeProxy) RegisterHandler(path string, handler func(http.ResponseWriter, *http.Request)) error {
+	p.path = path
+
+	return p.err
+}
+
 type badPool struct {
 	pool.Pool
 }
@@ -16,6 +16,24 @@ const (
 
 	// nonceFlag is the flag name containing the nonce.
 	nonceFlag = "nonce"
+
+	// replaceFlag is the flag name containing the level of the replacement of
+	// the pending transaction with the same nonce.
+	replaceFlag = "replace"
+
+	// idFlag is the flag name containing the hex-encoded identifier of the
+	// transaction to cancel.
+	idFlag = "id"
+
+	// algorithmFlag is the flag name containing the signature algorithm of
+	// the private keyfile.
+	algorithmFlag = "algorithm"
+
+	algorithmBLS   = "bls"
+	algorithmMLDSA = "mldsa"
+
+	// defaultPath is the default path of the endpoint to submit transactions.
+	defaultPath = "/transactions"
 )
 
 type miniController struct {
@@ -31,6 +49,12 @@ func NewController() node.Initializer {
 // SetCommands implements mode.Initializer. It sets the command to interact with
 // the pool.
 func (miniController) SetCommands(builder node.Builder) {
+	algorithm := cli.StringFlag{
+		Name:  algorithmFlag,
+		Usage: "signature algorithm of the private keyfile: [bls | mldsa]",
+		Value: algorithmBLS,
+	}
+
 	cmd := builder.SetCommand("pool")
 	cmd.SetDescription("interact with the pool")
 
@@ -44,14 +68,49 @@ func (miniController) SetCommands(builder node.Builder) {
 		Usage:    "nonce to use",
 		Required: false,
 		Value:    -1,
+	}, cli.IntFlag{
+		Name: replaceFlag,
+		Usage: "level of the replacement of the pending transaction with the " +
+			"same nonce, which must be higher than the level of the pending one",
+		Value: 0,
 	}, cli.StringFlag{
 		Name:     signerFlag,
 		Usage:    "path to the private keyfile",
 		Required: true,
-	})
-	sub.SetAction(builder.MakeAction(&addAction{
+	}, algorithm)
+	sub.SetAction(builder.MakeAction(node.Destructive(&addAction{
 		client: &client{},
-	}))
+	})))
+
+	sub = cmd.SetSubCommand("cancel")
+	sub.SetDescription("cancel a pending transaction of the pool")
+	sub.SetFlags(cli.StringFlag{
+		Name:     idFlag,
+		Usage:    "hex-encoded identifier of the transaction to cancel",
+		Required: true,
+	}, cli.IntFlag{
+		Name:     nonceFlag,
+		Usage:    "nonce of the transaction to cancel",
+		Required: true,
+	}, cli.StringFlag{
+		Name:     signerFlag,
+		Usage:    "path to the private keyfile of the author",
+		Required: true,
+	}, algorithm)
+	sub.SetAction(builder.MakeAction(node.Destructive(cancelAction{})))
+
+	sub = cmd.SetSubCommand("register")
+	sub.SetDescription("register the endpoint to submit transactions on the proxy")
+	sub.SetFlags(cli.StringFlag{
+		Name:  "path",
+		Usage: "the path of the endpoint",
+		Value: defaultPath,
+	})
+	sub.SetAction(builder.MakeAction(registerAction{}))
+
+	sub = cmd.SetSubCommand("rejections")
+	sub.SetDescription("print the number of transactions refused by the admission policy")
+	sub.SetAction(builder.MakeAction(rejectionsAction{}))
 }
 
 // OnStart implements node.Initializer
@@ -15,14 +15,19 @@ func TestMiniController_Build(t *testing.T) {
 	call := &fake.Call{}
 	ctrl.SetCommands(fakeBuilder{call: call})
 
-	require.Equal(t, 7, call.Len())
+	require.Equal(t, 21, call.Len())
 	require.Equal(t, "pool", call.Get(0, 0))
 	require.Equal(t, "interact with the pool", call.Get(1, 0))
 	require.Equal(t, "add", call.Get(2, 0))
 	require.Equal(t, "add a transaction to the pool", call.Get(3, 0))
-	require.Len(t, call.Get(4, 0), 3)
-	require.IsType(t, &addAction{}, call.Get(5, 0))
+	require.Len(t, call.Get(4, 0), 5)
+	require.Equal(t, node.Destructive(&addAction{client: &client{}}), call.Get(5, 0))
 	require.Nil(t, call.Get(6, 0)) // our fake MakeAction() returns nil
+	require.Equal(t, "cancel", call.Get(7, 0))
+	require.Equal(t, "register", call.Get(12, 0))
+	require.IsType(t, registerAction{}, call.Get(15, 0))
+	require.Equal(t, "rejections", call.Get(17, 0))
+	require.IsType(t, rejectionsAction{}, call.Get(19, 0))
 }
 
 func TestMiniController_OnStart(t *testing.T) {
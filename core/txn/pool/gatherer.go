@@ -3,10 +3,13 @@ package pool
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"sort"
 	"sync"
 
+	"go.dedis.ch/dela"
 	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/memory"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/validation"
 	"golang.org/x/xerrors"
@@ -16,6 +19,14 @@ import (
 // transactions.
 const DefaultIdentitySize = 10
 
+// DefaultCancelSize is the default number of cancellations remembered by the
+// gatherer so that a transaction revoked before it arrives is still refused.
+const DefaultCancelSize = 1000
+
+// txOverhead is the estimated number of bytes used by a transaction in
+// addition to its fingerprint, like its identifier and its signature.
+const txOverhead = 256
+
 // Transactions is a sortable list of transactions.
 //
 // - implements sort.Interface
@@ -74,14 +85,20 @@ type Gatherer interface {
 	// before being accepted by the gatherer.
 	AddFilter(Filter)
 
-	// Add adds the transaction to the list of pending transactions.
+	// Add adds the transaction to the list of pending transactions, or removes
+	// the pending transaction revoked by a cancellation.
 	Add(tx txn.Transaction) error
 
+	// AddFrom adds the transaction like Add, while the source it was submitted
+	// from is accounted for by the admission policy.
+	AddFrom(tx txn.Transaction, source string) error
+
 	// Remove removes a transaction from the list of pending ones.
 	Remove(tx txn.Transaction) error
 
 	// Wait waits for a notification with sufficient transactions to return the
-	// array, or nil if the context ends.
+	// array, or for the progress function of the configuration to cut the
+	// gathering. It returns nil if the context ends.
 	Wait(ctx context.Context, cfg Config) []txn.Transaction
 
 	// Close closes current operations and cleans the resources.
@@ -91,6 +108,10 @@ type Gatherer interface {
 type item struct {
 	cfg Config
 	ch  chan []txn.Transaction
+
+	// progress holds the latest number of pending transactions, or it is nil
+	// when the configuration has no progress function.
+	progress chan int
 }
 
 // SimpleGatherer is a gatherer of transactions that will use filters to drop
@@ -102,21 +123,70 @@ type simpleGatherer struct {
 	sync.Mutex
 
 	limit      int
-	queue      []item
+	queue      []*item
 	validators []Filter
 
 	// A string key is generated for each unique identity, which will have its
 	// own list of transactions, so that a limited size can be enforced
 	// independently from each other.
 	txs map[string]transactions
+
+	// The recent cancellations are remembered in the order of arrival, so that
+	// the oldest is forgotten first when the limit is reached.
+	cancelLimit int
+	cancelled   map[string]struct{}
+	cancelOrder []string
+
+	// The memory used by the pending transactions is accounted for when a
+	// budget is set, and the transactions beyond its cap are refused.
+	budget *memory.Budget
+
+	// The pending transactions per source are counted when an admission policy
+	// is set, with the source of each of them so that the count decreases when
+	// it leaves the pool.
+	admission *Admission
+	sources   map[string]int
+	origins   map[string]string
+}
+
+// GathererOption is the type of option to configure a gatherer.
+type GathererOption func(*simpleGatherer)
+
+// WithMemoryBudget is an option to account for the memory used by the pending
+// transactions. A transaction that would exceed the cap of the budget is
+// refused with an error that wraps memory.ErrExhausted, and it can be submitted
+// again once the pool is drained.
+func WithMemoryBudget(b *memory.Budget) GathererOption {
+	return func(g *simpleGatherer) {
+		g.budget = b
+	}
+}
+
+// WithAdmission is an option to limit the number of pending transactions per
+// identity and per source. A transaction beyond a limit is refused with an
+// error that wraps ErrTooManyPending.
+func WithAdmission(a *Admission) GathererOption {
+	return func(g *simpleGatherer) {
+		g.admission = a
+	}
 }
 
 // NewSimpleGatherer creates a new gatherer.
-func NewSimpleGatherer() Gatherer {
-	return &simpleGatherer{
-		limit: DefaultIdentitySize,
-		txs:   make(map[string]transactions),
+func NewSimpleGatherer(opts ...GathererOption) Gatherer {
+	g := &simpleGatherer{
+		limit:       DefaultIdentitySize,
+		txs:         make(map[string]transactions),
+		cancelLimit: DefaultCancelSize,
+		cancelled:   make(map[string]struct{}),
+		sources:     make(map[string]int),
+		origins:     make(map[string]string),
 	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
 }
 
 // Len implements pool.Gatherer. It returns the number of transaction available
@@ -139,16 +209,25 @@ func (g *simpleGatherer) AddFilter(filter Filter) {
 }
 
 // Add implements pool.Gatherer. It adds the transaction to the set of available
-// transactions and notify the queue of the new length.
+// transactions and notify the queue of the new length. A cancellation is never
+// added but instead revokes the pending transaction it refers to, or the
+// transaction when it arrives later. A replacement takes the place of the
+// pending transaction with the same nonce if its level is higher.
 func (g *simpleGatherer) Add(tx txn.Transaction) error {
+	return g.AddFrom(tx, "")
+}
 
-	for _, val := range g.validators {
-		// Make sure the transaction is not already known, or that is not in a
-		// distant future to limit the pool storage size.
-		err := val.Accept(tx, validation.Leeway{MaxSequenceDifference: g.limit})
-		if err != nil {
-			return xerrors.Errorf("invalid transaction: %v", err)
-		}
+// AddFrom implements pool.Gatherer. It adds the transaction like Add, but it is
+// refused if its identity or its source reached the limit of the admission
+// policy. An empty source is only limited per identity.
+func (g *simpleGatherer) AddFrom(tx txn.Transaction, source string) error {
+	if IsCancellation(tx) {
+		return g.cancel(tx)
+	}
+
+	err := g.accept(tx)
+	if err != nil {
+		return xerrors.Errorf("invalid transaction: %v", err)
 	}
 
 	key, err := makeKey(tx.GetIdentity())
@@ -158,8 +237,43 @@ func (g *simpleGatherer) Add(tx txn.Transaction) error {
 
 	g.Lock()
 
+	_, found := g.cancelled[cancelKey(key, tx.GetNonce(), tx.GetID())]
+	if found {
+		g.Unlock()
+		return xerrors.Errorf("transaction %x is cancelled", tx.GetID())
+	}
+
+	err = g.replace(key, tx)
+	if err != nil {
+		g.Unlock()
+		return xerrors.Errorf("invalid replacement: %v", err)
+	}
+
+	err = g.admit(key, tx, source)
+	if err != nil {
+		g.Unlock()
+		return xerrors.Errorf("admission refused: %w", err)
+	}
+
+	size := g.sizeOf(tx)
+
+	err = g.budget.Reserve(size)
+	if err != nil {
+		g.Unlock()
+		return xerrors.Errorf("pool is full: %w", err)
+	}
+
+	num := len(g.txs[key])
 	g.txs[key] = g.txs[key].Add(tx)
 
+	if len(g.txs[key]) == num {
+		// The nonce is already used by a pending transaction.
+		g.budget.Release(size)
+	} else if source != "" {
+		g.sources[source]++
+		g.origins[cancelKey(key, tx.GetNonce(), tx.GetID())] = source
+	}
+
 	g.notify(g.calculateLength())
 
 	g.Unlock()
@@ -177,28 +291,238 @@ func (g *simpleGatherer) Remove(tx txn.Transaction) error {
 
 	g.Lock()
 
-	g.txs[key] = g.txs[key].Remove(tx)
+	g.remove(key, tx)
 
 	g.Unlock()
 
 	return nil
 }
 
+// remove removes the transaction from the pending ones of the identity, and
+// releases its memory if it was pending.
+func (g *simpleGatherer) remove(key string, tx txn.Transaction) {
+	num := len(g.txs[key])
+	g.txs[key] = g.txs[key].Remove(tx)
+
+	if len(g.txs[key]) < num {
+		g.budget.Release(g.sizeOf(tx))
+		g.untrack(cancelKey(key, tx.GetNonce(), tx.GetID()))
+		g.notify(g.calculateLength())
+	}
+}
+
+// admit returns an error if the admission policy refuses the transaction. A
+// transaction with the nonce of a pending one is not limited, as it either is
+// a duplicate or takes the place of the pending one.
+func (g *simpleGatherer) admit(key string, tx txn.Transaction, source string) error {
+	for _, pending := range g.txs[key] {
+		if pending.GetNonce() == tx.GetNonce() {
+			return nil
+		}
+	}
+
+	return g.admission.admit(len(g.txs[key]), source, g.sources[source])
+}
+
+// untrack forgets the source of the pending transaction.
+func (g *simpleGatherer) untrack(pending string) {
+	source, found := g.origins[pending]
+	if !found {
+		return
+	}
+
+	delete(g.origins, pending)
+
+	g.sources[source]--
+	if g.sources[source] <= 0 {
+		delete(g.sources, source)
+	}
+}
+
+// replace removes the pending transaction with the same nonce as the
+// replacement, and remembers it as cancelled so that it is not added back. It
+// returns an error if the level of the replacement is not higher than the one
+// of the pending transaction. Nothing happens when the transaction is not a
+// replacement, or when no transaction is pending for the nonce.
+func (g *simpleGatherer) replace(key string, tx txn.Transaction) error {
+	level, err := ReplacementLevel(tx)
+	if err != nil {
+		return err
+	}
+
+	if level == 0 {
+		return nil
+	}
+
+	for _, pending := range g.txs[key] {
+		if pending.GetNonce() != tx.GetNonce() || bytes.Equal(pending.GetID(), tx.GetID()) {
+			continue
+		}
+
+		// The level of a pending transaction has been checked when it was
+		// added.
+		prev, _ := ReplacementLevel(pending)
+		if level <= prev {
+			return xerrors.Errorf("level %d is not above %d", level, prev)
+		}
+
+		g.remember(cancelKey(key, pending.GetNonce(), pending.GetID()))
+		g.remove(key, pending)
+
+		return nil
+	}
+
+	return nil
+}
+
+// sizeOf returns the estimated number of bytes used by the transaction, or
+// zero when the memory is not accounted for.
+func (g *simpleGatherer) sizeOf(tx txn.Transaction) int {
+	if g.budget == nil {
+		return 0
+	}
+
+	counter := &byteCounter{}
+
+	// The fingerprint is only an estimate of the size, so that a failure
+	// leaves the overhead.
+	_ = tx.Fingerprint(counter)
+
+	return counter.n + txOverhead
+}
+
+// cancel removes the pending transaction revoked by the cancellation, if it
+// exists, and remembers the cancellation in case the transaction has not
+// arrived yet. The cancellation only applies to a transaction of the same
+// identity and with the same nonce, so that only the author can revoke it.
+func (g *simpleGatherer) cancel(tx txn.Transaction) error {
+	key, err := makeKey(tx.GetIdentity())
+	if err != nil {
+		return xerrors.Errorf("identity key failed: %v", err)
+	}
+
+	target := tx.GetArg(CancelArg)
+
+	g.Lock()
+	defer g.Unlock()
+
+	g.remember(cancelKey(key, tx.GetNonce(), target))
+
+	for _, pending := range g.txs[key] {
+		if pending.GetNonce() == tx.GetNonce() && bytes.Equal(pending.GetID(), target) {
+			g.remove(key, pending)
+			break
+		}
+	}
+
+	return nil
+}
+
+// remember adds the cancellation to the recent ones and forgets the oldest if
+// the limit is reached.
+func (g *simpleGatherer) remember(key string) {
+	_, found := g.cancelled[key]
+	if found {
+		return
+	}
+
+	for len(g.cancelOrder) > 0 && len(g.cancelOrder) >= g.cancelLimit {
+		delete(g.cancelled, g.cancelOrder[0])
+		g.cancelOrder = g.cancelOrder[1:]
+	}
+
+	g.cancelled[key] = struct{}{}
+	g.cancelOrder = append(g.cancelOrder, key)
+}
+
 // Wait implements pool.Gatherer. It waits for enough transactions before
-// returning the list, or it returns nil if the context ends.
+// returning the list, or it returns nil if the context ends. The transactions
+// go through the filters again, and it waits for more if too many of them are
+// dropped, unless the gathering has been cut by the progress function.
 func (g *simpleGatherer) Wait(ctx context.Context, cfg Config) []txn.Transaction {
-	ch := make(chan []txn.Transaction, 1)
+	for {
+		txs, cut := g.wait(ctx, cfg)
+		if txs == nil {
+			return nil
+		}
+
+		txs = g.revalidate(txs)
+		if len(txs) >= cfg.Min || cut {
+			return prioritize(txs, cfg.Classifier)
+		}
+	}
+}
+
+// revalidate applies the filters again to the transactions, and it removes the
+// ones that are refused from the pool. A transaction accepted long ago can be
+// invalid against the current state, e.g. when another transaction of the same
+// identity has consumed its nonce, and it would only be rejected by the block.
+func (g *simpleGatherer) revalidate(txs []txn.Transaction) []txn.Transaction {
+	if len(g.validators) == 0 {
+		return txs
+	}
+
+	valid := txs[:0]
 
+	for _, tx := range txs {
+		err := g.accept(tx)
+		if err != nil {
+			dela.Logger.Debug().
+				Hex("ID", tx.GetID()).
+				Err(err).
+				Msg("transaction dropped before proposal")
+
+			// The key of the identity cannot fail as the transaction has been
+			// added before.
+			g.Remove(tx)
+			continue
+		}
+
+		valid = append(valid, tx)
+	}
+
+	return valid
+}
+
+// accept returns an error if one of the filters refuses the transaction.
+func (g *simpleGatherer) accept(tx txn.Transaction) error {
+	for _, val := range g.validators {
+		// Make sure the transaction is not already known, or that is not in a
+		// distant future to limit the pool storage size.
+		err := val.Accept(tx, validation.Leeway{MaxSequenceDifference: g.limit})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// wait returns the pending transactions when there are enough of them, or
+// when the progress function cuts the gathering, in which case it returns
+// true. The request is removed from the queue when the context ends.
+func (g *simpleGatherer) wait(ctx context.Context, cfg Config) ([]txn.Transaction, bool) {
 	g.Lock()
 
-	if g.calculateLength() >= cfg.Min {
+	length := g.calculateLength()
+	if length >= cfg.Min {
 		txs := g.makeArray()
 		g.Unlock()
 
-		return txs
+		return txs, false
 	}
 
-	g.queue = append(g.queue, item{cfg: cfg, ch: ch})
+	it := &item{
+		cfg: cfg,
+		ch:  make(chan []txn.Transaction, 1),
+	}
+
+	if cfg.Progress != nil {
+		it.progress = make(chan int, 1)
+		it.progress <- length
+	}
+
+	g.queue = append(g.queue, it)
 
 	g.Unlock()
 
@@ -206,12 +530,50 @@ func (g *simpleGatherer) Wait(ctx context.Context, cfg Config) []txn.Transaction
 		cfg.Callback()
 	}
 
-	select {
-	case txs := <-ch:
-		return txs
-	case <-ctx.Done():
-		return nil
+	for {
+		select {
+		case txs := <-it.ch:
+			return txs, false
+		case count := <-it.progress:
+			// The function is called without the lock so that it can use the
+			// gatherer.
+			if cfg.Progress(count) {
+				return g.cut(it), true
+			}
+		case <-ctx.Done():
+			g.Lock()
+			g.dequeue(it)
+			g.Unlock()
+
+			return nil, false
+		}
+	}
+}
+
+// cut removes the request from the queue and returns the pending transactions,
+// or the ones it has been notified with in the meantime.
+func (g *simpleGatherer) cut(it *item) []txn.Transaction {
+	g.Lock()
+	defer g.Unlock()
+
+	if !g.dequeue(it) {
+		return <-it.ch
+	}
+
+	return g.makeArray()
+}
+
+// dequeue removes the request from the queue and returns true if it was still
+// waiting.
+func (g *simpleGatherer) dequeue(it *item) bool {
+	for i, other := range g.queue {
+		if other == it {
+			g.queue = append(g.queue[:i], g.queue[i+1:]...)
+			return true
+		}
 	}
+
+	return false
 }
 
 // Close implements pool.Gatherer. It closes the operations and cleans the
@@ -219,7 +581,15 @@ func (g *simpleGatherer) Wait(ctx context.Context, cfg Config) []txn.Transaction
 func (g *simpleGatherer) Close() {
 	g.Lock()
 
+	for _, list := range g.txs {
+		for _, tx := range list {
+			g.budget.Release(g.sizeOf(tx))
+		}
+	}
+
 	g.txs = make(map[string]transactions)
+	g.sources = make(map[string]int)
+	g.origins = make(map[string]string)
 
 	for _, item := range g.queue {
 		close(item.ch)
@@ -231,7 +601,8 @@ func (g *simpleGatherer) Close() {
 }
 
 // Notify triggers the elements of the queue that are waiting for at least the
-// length in parameter and remove them from the queue.
+// length in parameter and remove them from the queue. The others are notified
+// of the progress if they asked for it.
 func (g *simpleGatherer) notify(length int) {
 	// Iterating by descending order to allow the deletion of the element inside
 	// the loop.
@@ -241,6 +612,15 @@ func (g *simpleGatherer) notify(length int) {
 		if item.cfg.Min <= length {
 			item.ch <- g.makeArray()
 			g.queue = append(g.queue[:i], g.queue[i+1:]...)
+		} else if item.progress != nil {
+			// Only the latest number is kept so that the notification never
+			// blocks.
+			select {
+			case <-item.progress:
+			default:
+			}
+
+			item.progress <- length
 		}
 	}
 }
@@ -254,15 +634,71 @@ func (g *simpleGatherer) calculateLength() int {
 	return num
 }
 
+// makeArray returns the pending transactions. The identities are sorted so that
+// the order doesn't depend on the iteration of the map.
 func (g *simpleGatherer) makeArray() []txn.Transaction {
+	keys := make([]string, 0, len(g.txs))
+	for key := range g.txs {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
 	txs := make([]txn.Transaction, 0, g.calculateLength())
-	for _, list := range g.txs {
-		txs = append(txs, list...)
+	for _, key := range keys {
+		txs = append(txs, g.txs[key]...)
 	}
 
 	return txs
 }
 
+// prioritize sorts the transactions by priority class, from the highest to the
+// lowest. The transactions of an identity are in the order of their nonces,
+// therefore each of them takes the lowest class of the previous ones of the
+// identity so that it is never moved before them.
+func prioritize(txs []txn.Transaction, classifier Classifier) []txn.Transaction {
+	if classifier == nil {
+		return txs
+	}
+
+	classes := make([]Priority, len(txs))
+	lowest := make(map[string]Priority)
+
+	for i, tx := range txs {
+		classes[i] = classifier.Classify(tx)
+
+		// The key cannot fail as the transaction has been added before.
+		key, _ := makeKey(tx.GetIdentity())
+
+		prev, found := lowest[key]
+		if found && prev < classes[i] {
+			classes[i] = prev
+		}
+
+		lowest[key] = classes[i]
+	}
+
+	indices := make([]int, len(txs))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	sort.SliceStable(indices, func(i, j int) bool {
+		return classes[indices[i]] > classes[indices[j]]
+	})
+
+	sorted := make([]txn.Transaction, len(txs))
+	for i, index := range indices {
+		sorted[i] = txs[index]
+	}
+
+	return sorted
+}
+
+func cancelKey(key string, nonce uint64, id []byte) string {
+	return fmt.Sprintf("%s:%d:%x", key, nonce, id)
+}
+
 func makeKey(id access.Identity) (string, error) {
 	data, err := id.MarshalText()
 	if err != nil {
@@ -271,3 +707,16 @@ func makeKey(id access.Identity) (string, error) {
 
 	return string(data), nil
 }
+
+// byteCounter is a writer that counts the bytes written.
+//
+// - implements io.Writer
+type byteCounter struct {
+	n int
+}
+
+// Write implements io.Writer. It counts the bytes.
+func (c *byteCounter) Write(data []byte) (int, error) {
+	c.n += len(data)
+	return len(data), nil
+}
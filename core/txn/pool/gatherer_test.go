@@ -2,14 +2,18 @@ package pool
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/memory"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/internal/testing/fake"
+	"golang.org/x/xerrors"
 )
 
 func TestSimpleGatherer_Len(t *testing.T) {
@@ -74,6 +78,164 @@ func TestSimpleGatherer_Remove(t *testing.T) {
 	require.EqualError(t, err, fake.Err("identity key failed"))
 }
 
+func TestSimpleGatherer_Cancel(t *testing.T) {
+	gatherer := NewSimpleGatherer().(*simpleGatherer)
+	gatherer.AddFilter(fakeFilter{})
+
+	require.NoError(t, gatherer.Add(newTx(0, "Alice")))
+	require.NoError(t, gatherer.Add(newTx(1, "Alice")))
+	require.NoError(t, gatherer.Add(newTx(1, "Bob")))
+
+	// Identity differs from the author of the transaction.
+	cancel := fakeTx{id: 1, identity: fakeIdentity{text: "Eve"}, cancel: []byte{1}}
+
+	require.NoError(t, gatherer.Add(cancel))
+	require.Equal(t, 3, gatherer.Len())
+
+	// Nonce differs from the pending transaction.
+	cancel = fakeTx{id: 0, identity: fakeIdentity{text: "Alice"}, cancel: []byte{1}}
+	require.NoError(t, gatherer.Add(cancel))
+	require.Equal(t, 3, gatherer.Len())
+
+	cancel = fakeTx{id: 1, identity: fakeIdentity{text: "Alice"}, cancel: []byte{1}}
+	require.NoError(t, gatherer.Add(cancel))
+	require.Equal(t, 2, gatherer.Len())
+	require.Len(t, gatherer.txs["Alice"], 1)
+	require.Len(t, gatherer.txs["Bob"], 1)
+
+	// The transaction is refused if it arrives again after the cancellation.
+	err := gatherer.Add(newTx(1, "Alice"))
+	require.EqualError(t, err, "transaction 01 is cancelled")
+	require.Equal(t, 2, gatherer.Len())
+
+	// A cancellation that arrives before its target is remembered.
+	cancel = fakeTx{id: 2, identity: fakeIdentity{text: "Alice"}, cancel: []byte{2}}
+	require.NoError(t, gatherer.Add(cancel))
+
+	err = gatherer.Add(newTx(2, "Alice"))
+	require.EqualError(t, err, "transaction 02 is cancelled")
+
+	// The oldest cancellations are forgotten when the limit is reached.
+	gatherer.cancelLimit = 1
+	cancel = fakeTx{id: 3, identity: fakeIdentity{text: "Alice"}, cancel: []byte{3}}
+	require.NoError(t, gatherer.Add(cancel))
+	require.Len(t, gatherer.cancelled, 1)
+	require.NoError(t, gatherer.Add(newTx(2, "Alice")))
+
+	cancel = fakeTx{identity: fake.NewBadPublicKey(), cancel: []byte{1}}
+	err = gatherer.Add(cancel)
+	require.EqualError(t, err, fake.Err("identity key failed"))
+}
+
+func TestSimpleGatherer_Replace(t *testing.T) {
+	gatherer := NewSimpleGatherer().(*simpleGatherer)
+
+	require.NoError(t, gatherer.Add(newTx(0, "Alice")))
+	require.NoError(t, gatherer.Add(newTx(1, "Alice")))
+
+	replace := newTx(1, "Alice")
+	replace.replace = MakeReplacementArg(2).Value
+
+	require.NoError(t, gatherer.Add(replace))
+	require.Equal(t, 2, gatherer.Len())
+	require.Equal(t, replace, gatherer.txs["Alice"][1])
+
+	// The replaced transaction is refused if it arrives again.
+	err := gatherer.Add(newTx(1, "Alice"))
+	require.EqualError(t, err, "transaction 01 is cancelled")
+
+	// The same replacement is ignored like any duplicate.
+	require.NoError(t, gatherer.Add(replace))
+	require.Equal(t, 2, gatherer.Len())
+
+	lower := newTx(1, "Alice")
+	lower.replace = MakeReplacementArg(1).Value
+
+	err = gatherer.Add(lower)
+	require.EqualError(t, err, "invalid replacement: level 1 is not above 2")
+	require.Equal(t, replace, gatherer.txs["Alice"][1])
+
+	higher := newTx(1, "Alice")
+	higher.replace = MakeReplacementArg(3).Value
+
+	require.NoError(t, gatherer.Add(higher))
+	require.Equal(t, 2, gatherer.Len())
+	require.Equal(t, higher, gatherer.txs["Alice"][1])
+
+	// A replacement without a pending transaction is added as is.
+	alone := newTx(2, "Alice")
+	alone.replace = MakeReplacementArg(1).Value
+
+	require.NoError(t, gatherer.Add(alone))
+	require.Equal(t, 3, gatherer.Len())
+
+	bad := newTx(3, "Alice")
+	bad.replace = []byte{1}
+
+	err = gatherer.Add(bad)
+	require.EqualError(t, err, "invalid replacement: invalid replacement level of 1 bytes")
+}
+
+func TestReplacementLevel(t *testing.T) {
+	level, err := ReplacementLevel(newTx(0, "Alice"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), level)
+
+	tx := newTx(0, "Alice")
+	tx.replace = MakeReplacementArg(42).Value
+
+	level, err = ReplacementLevel(tx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), level)
+
+	tx.replace = make([]byte, 8)
+	_, err = ReplacementLevel(tx)
+	require.EqualError(t, err, "replacement level must be positive")
+
+	tx.replace = []byte{}
+	_, err = ReplacementLevel(tx)
+	require.EqualError(t, err, "invalid replacement level of 0 bytes")
+}
+
+func TestSimpleGatherer_Admission(t *testing.T) {
+	admission := NewAdmission(WithIdentityLimit(2), WithSourceLimit(2))
+
+	gatherer := NewSimpleGatherer(WithAdmission(admission)).(*simpleGatherer)
+
+	require.NoError(t, gatherer.AddFrom(newTx(0, "Alice"), "A"))
+	require.NoError(t, gatherer.AddFrom(newTx(1, "Alice"), "B"))
+
+	err := gatherer.AddFrom(newTx(2, "Alice"), "B")
+	require.EqualError(t, err, "admission refused: identity reached the limit of 2: "+
+		"too many pending transactions")
+
+	// A duplicate or a replacement is not limited.
+	require.NoError(t, gatherer.AddFrom(newTx(1, "Alice"), "B"))
+
+	replace := newTx(1, "Alice")
+	replace.replace = MakeReplacementArg(1).Value
+
+	require.NoError(t, gatherer.AddFrom(replace, "A"))
+	require.Equal(t, map[string]int{"A": 2}, gatherer.sources)
+
+	err = gatherer.AddFrom(newTx(0, "Bob"), "A")
+	require.EqualError(t, err, "admission refused: source 'A' reached the limit of 2: "+
+		"too many pending transactions")
+
+	// The transactions of the node and of the participants have no source.
+	require.NoError(t, gatherer.Add(newTx(0, "Bob")))
+
+	require.NoError(t, gatherer.Remove(newTx(0, "Alice")))
+	require.Equal(t, map[string]int{"A": 1}, gatherer.sources)
+	require.NoError(t, gatherer.AddFrom(newTx(1, "Bob"), "A"))
+
+	require.Equal(t, Rejections{Identity: 1, Source: 1}, admission.GetRejections())
+
+	gatherer.Close()
+	require.Empty(t, gatherer.sources)
+	require.Empty(t, gatherer.origins)
+}
+
 func TestSimpleGatherer_Wait(t *testing.T) {
 	gatherer := NewSimpleGatherer().(*simpleGatherer)
 
@@ -98,6 +260,137 @@ func TestSimpleGatherer_Wait(t *testing.T) {
 
 	txs = gatherer.Wait(ctx, Config{Min: 2})
 	require.Nil(t, txs)
+	require.Empty(t, gatherer.queue)
+}
+
+func TestSimpleGatherer_Progress_Wait(t *testing.T) {
+	gatherer := NewSimpleGatherer().(*simpleGatherer)
+
+	require.NoError(t, gatherer.Add(newTx(0, "Alice")))
+
+	counts := []int{}
+	progress := func(count int) bool {
+		counts = append(counts, count)
+
+		if count < 3 {
+			require.NoError(t, gatherer.Add(newTx(uint64(count), "Alice")))
+		}
+
+		return count >= 3
+	}
+
+	txs := gatherer.Wait(context.Background(), Config{Min: 10, Progress: progress})
+	require.Len(t, txs, 3)
+	require.Equal(t, []int{1, 2, 3}, counts)
+	require.Empty(t, gatherer.queue)
+
+	// The removals are notified too.
+	counts = counts[:0]
+	progress = func(count int) bool {
+		counts = append(counts, count)
+
+		if count == 3 {
+			require.NoError(t, gatherer.Remove(newTx(0, "Alice")))
+		}
+
+		return count < 3
+	}
+
+	txs = gatherer.Wait(context.Background(), Config{Min: 10, Progress: progress})
+	require.Len(t, txs, 2)
+	require.Equal(t, []int{3, 2}, counts)
+
+	// The transactions of a notification that arrives before the cut are
+	// returned.
+	it := &item{ch: make(chan []txn.Transaction, 1)}
+	it.ch <- txs[:1]
+	require.Len(t, gatherer.cut(it), 1)
+}
+
+func TestSimpleGatherer_Revalidate_Wait(t *testing.T) {
+	filter := &nonceFilter{}
+
+	gatherer := NewSimpleGatherer().(*simpleGatherer)
+	gatherer.AddFilter(filter)
+
+	require.NoError(t, gatherer.Add(newTx(0, "Alice")))
+	require.NoError(t, gatherer.Add(newTx(1, "Alice")))
+	require.NoError(t, gatherer.Add(newTx(2, "Alice")))
+	require.NoError(t, gatherer.Add(newTx(5, "Bob")))
+
+	// The state has moved forward since the transactions have been added.
+	filter.min = 2
+
+	txs := gatherer.Wait(context.Background(), Config{Min: 1})
+	require.Len(t, txs, 2)
+	require.Equal(t, 2, gatherer.Len())
+
+	// Every transaction is dropped, so it waits for new ones.
+	filter.min = 10
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	txs = gatherer.Wait(ctx, Config{Min: 1})
+	require.Nil(t, txs)
+	require.Equal(t, 0, gatherer.Len())
+}
+
+func TestSimpleGatherer_Priority_Wait(t *testing.T) {
+	gatherer := NewSimpleGatherer().(*simpleGatherer)
+
+	require.NoError(t, gatherer.Add(newTx(0, "Alice")))
+	require.NoError(t, gatherer.Add(newTx(1, "Alice")))
+	require.NoError(t, gatherer.Add(newTx(0, "Bob")))
+	require.NoError(t, gatherer.Add(newTx(2, "Charlie")))
+	require.NoError(t, gatherer.Add(newTx(3, "Charlie")))
+
+	// The identities are in order without a classifier.
+	txs := gatherer.Wait(context.Background(), Config{Min: 5})
+	require.Equal(t, []string{"Alice:0", "Alice:1", "Bob:0", "Charlie:2", "Charlie:3"}, toStrings(txs))
+
+	// The second transaction of Alice cannot go before the first one.
+	classifier := fakeClassifier{"Alice:1": PriorityGovernance, "Charlie:2": PriorityGovernance}
+
+	txs = gatherer.Wait(context.Background(), Config{Min: 5, Classifier: classifier})
+	require.Equal(t, []string{"Charlie:2", "Alice:0", "Alice:1", "Bob:0", "Charlie:3"}, toStrings(txs))
+}
+
+func TestSimpleGatherer_MemoryBudget(t *testing.T) {
+	size := 1 + txOverhead
+	budget := memory.NewBudget(memory.PoolModule, int64(2*size))
+
+	gatherer := NewSimpleGatherer(WithMemoryBudget(budget)).(*simpleGatherer)
+
+	require.NoError(t, gatherer.Add(newTx(0, "Alice")))
+	require.NoError(t, gatherer.Add(newTx(1, "Alice")))
+	require.Equal(t, int64(2*size), budget.GetUsage().Used)
+
+	err := gatherer.Add(newTx(2, "Alice"))
+	require.True(t, xerrors.Is(err, memory.ErrExhausted))
+	require.Equal(t, 2, gatherer.Len())
+
+	// A duplicate nonce is not counted twice.
+	gatherer.budget.SetLimit(0)
+	require.NoError(t, gatherer.Add(newTx(1, "Alice")))
+	require.Equal(t, int64(2*size), budget.GetUsage().Used)
+
+	// Only the pending transactions are released.
+	require.NoError(t, gatherer.Remove(newTx(0, "Alice")))
+	require.NoError(t, gatherer.Remove(newTx(0, "Alice")))
+	require.Equal(t, int64(size), budget.GetUsage().Used)
+
+	cancel := newTx(1, "Alice")
+	cancel.cancel = []byte{1}
+	require.NoError(t, gatherer.Add(cancel))
+	require.Equal(t, int64(0), budget.GetUsage().Used)
+
+	require.NoError(t, gatherer.Add(newTx(3, "Bob")))
+	require.NoError(t, gatherer.Add(fakeTx{id: 4, identity: fakeIdentity{text: "Bob"}, badPrint: true}))
+	require.Equal(t, int64(size+txOverhead), budget.GetUsage().Used)
+
+	gatherer.Close()
+	require.Equal(t, int64(0), budget.GetUsage().Used)
 }
 
 func TestSimpleGatherer_Close(t *testing.T) {
@@ -139,6 +432,9 @@ type fakeTx struct {
 
 	id       uint64
 	identity access.Identity
+	cancel   []byte
+	replace  []byte
+	badPrint bool
 }
 
 func newTx(nonce uint64, identity string) fakeTx {
@@ -149,7 +445,16 @@ func newTx(nonce uint64, identity string) fakeTx {
 }
 
 func (tx fakeTx) GetID() []byte {
-	return []byte{byte(tx.id)}
+	return append([]byte{byte(tx.id)}, tx.replace...)
+}
+
+func (tx fakeTx) Fingerprint(w io.Writer) error {
+	if tx.badPrint {
+		return fake.GetError()
+	}
+
+	_, err := w.Write(tx.GetID())
+	return err
 }
 
 func (tx fakeTx) GetNonce() uint64 {
@@ -160,6 +465,33 @@ func (tx fakeTx) GetIdentity() access.Identity {
 	return tx.identity
 }
 
+func (tx fakeTx) GetArg(key string) []byte {
+	if key == CancelArg {
+		return tx.cancel
+	}
+
+	if key == ReplaceArg {
+		return tx.replace
+	}
+
+	return nil
+}
+
+func toStrings(txs []txn.Transaction) []string {
+	res := make([]string, len(txs))
+	for i, tx := range txs {
+		res[i] = fmt.Sprintf("%s:%d", tx.GetIdentity().(fakeIdentity).text, tx.GetNonce())
+	}
+
+	return res
+}
+
+type fakeClassifier map[string]Priority
+
+func (c fakeClassifier) Classify(tx txn.Transaction) Priority {
+	return c[fmt.Sprintf("%s:%d", tx.GetIdentity().(fakeIdentity).text, tx.GetNonce())]
+}
+
 type fakeIdentity struct {
 	access.Identity
 	text string
@@ -178,3 +510,17 @@ func (fakeFilter) Accept(tx txn.Transaction, leeway validation.Leeway) error {
 
 	return nil
 }
+
+// nonceFilter refuses the transactions with a nonce below the minimum, like
+// the ones already consumed in the state.
+type nonceFilter struct {
+	min uint64
+}
+
+func (f *nonceFilter) Accept(tx txn.Transaction, leeway validation.Leeway) error {
+	if tx.GetNonce() < f.min {
+		return fake.GetError()
+	}
+
+	return nil
+}
@@ -1,19 +1,33 @@
 // Package gossip implements a transaction pool that is using a gossip protocol
 // to spread the transactions to other participants.
+//
+// The pool can persist the pending transactions to a key/value database, so
+// that the submissions of the clients are not lost when the node restarts. The
+// transactions are written when they are accepted, and deleted when they are
+// removed from the pool or cancelled. A transaction that has been dropped
+// otherwise, e.g. because its nonce is consumed by another transaction, is
+// deleted the next time the pool is loaded as the filters refuse it.
 package gossip
 
 import (
 	"context"
+	"encoding/binary"
 
 	"github.com/rs/zerolog"
 	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/store/kv"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/txn/pool"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/gossip"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/json"
 	"golang.org/x/xerrors"
 )
 
+// bucketName is the name of the bucket of the persisted transactions.
+var bucketName = []byte("pool")
+
 // Pool is a transaction pool that is using gossip to send the transactions to
 // the other participants.
 //
@@ -23,10 +37,16 @@ type Pool struct {
 	actor    gossip.Actor
 	gatherer pool.Gatherer
 	closing  chan struct{}
+
+	// The pending transactions are persisted when the database is set.
+	db      kv.DB
+	fac     txn.Factory
+	context serde.Context
 }
 
 // NewPool creates a new empty pool and starts to gossip incoming transaction.
-func NewPool(gossiper gossip.Gossiper) (*Pool, error) {
+// The options configure the gatherer of the transactions.
+func NewPool(gossiper gossip.Gossiper, opts ...pool.GathererOption) (*Pool, error) {
 	actor, err := gossiper.Listen()
 	if err != nil {
 		return nil, xerrors.Errorf("failed to listen: %v", err)
@@ -35,7 +55,7 @@ func NewPool(gossiper gossip.Gossiper) (*Pool, error) {
 	p := &Pool{
 		logger:   dela.Logger,
 		actor:    actor,
-		gatherer: pool.NewSimpleGatherer(),
+		gatherer: pool.NewSimpleGatherer(opts...),
 		closing:  make(chan struct{}),
 	}
 
@@ -44,6 +64,98 @@ func NewPool(gossiper gossip.Gossiper) (*Pool, error) {
 	return p, nil
 }
 
+// NewPersistentPool creates a new pool that persists the pending transactions
+// to the database. The transactions of a previous run are only added back when
+// the pool is loaded.
+func NewPersistentPool(gossiper gossip.Gossiper, db kv.DB, fac txn.Factory,
+	opts ...pool.GathererOption) (*Pool, error) {
+
+	p, err := NewPool(gossiper, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	p.db = db
+	p.fac = fac
+	p.context = json.NewContext()
+
+	return p, nil
+}
+
+// Load reads the transactions persisted by a previous run and adds them back
+// to the pool, without gossiping them again. It should be called once the
+// filters are added, so that the transactions that are no longer valid are
+// deleted instead.
+func (p *Pool) Load() error {
+	if p.db == nil {
+		return nil
+	}
+
+	keys := [][]byte{}
+	values := [][]byte{}
+
+	err := p.db.View(func(tx kv.ReadableTx) error {
+		bucket := tx.GetBucket(bucketName)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(key, value []byte) error {
+			keys = append(keys, append([]byte{}, key...))
+			values = append(values, append([]byte{}, value...))
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return xerrors.Errorf("failed to read: %v", err)
+	}
+
+	stale := [][]byte{}
+
+	// The transactions are added outside of the transaction of the database,
+	// as the filters may read it.
+	for i, value := range values {
+		tx, err := p.fac.TransactionOf(p.context, value)
+		if err == nil {
+			err = p.gatherer.Add(tx)
+		}
+
+		if err != nil {
+			p.logger.Debug().Err(err).Msg("persisted transaction dropped")
+
+			stale = append(stale, keys[i])
+		}
+	}
+
+	err = p.db.Update(func(tx kv.WritableTx) error {
+		bucket, err := tx.GetBucketOrCreate(bucketName)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range stale {
+			err = bucket.Delete(key)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return xerrors.Errorf("failed to delete stale transactions: %v", err)
+	}
+
+	p.logger.Info().
+		Int("num", len(keys)-len(stale)).
+		Msg("pending transactions loaded")
+
+	return nil
+}
+
 // SetPlayers implements pool.Pool. It sets the list of participants the
 // transactions should be gossiped to.
 func (p *Pool) SetPlayers(players mino.Players) error {
@@ -65,9 +177,20 @@ func (p *Pool) Len() int {
 // Add implements pool.Pool. It adds the transaction to the pool and gossips it
 // to other participants.
 func (p *Pool) Add(tx txn.Transaction) error {
-	err := p.gatherer.Add(tx)
+	return p.AddFrom(tx, "")
+}
+
+// AddFrom implements pool.Pool. It adds the transaction to the pool while
+// accounting for its source, and gossips it to other participants.
+func (p *Pool) AddFrom(tx txn.Transaction, source string) error {
+	err := p.gatherer.AddFrom(tx, source)
 	if err != nil {
-		return xerrors.Errorf("store failed: %v", err)
+		return xerrors.Errorf("store failed: %w", err)
+	}
+
+	err = p.persist(tx)
+	if err != nil {
+		return xerrors.Errorf("failed to persist tx: %v", err)
 	}
 
 	err = p.actor.Add(tx)
@@ -85,6 +208,11 @@ func (p *Pool) Remove(tx txn.Transaction) error {
 		return xerrors.Errorf("store failed: %v", err)
 	}
 
+	err = p.forget(tx, tx.GetID())
+	if err != nil {
+		return xerrors.Errorf("failed to forget tx: %v", err)
+	}
+
 	return nil
 }
 
@@ -115,6 +243,10 @@ func (p *Pool) listenRumors(ch <-chan gossip.Rumor) {
 			tx, ok := rumor.(txn.Transaction)
 			if ok {
 				err := p.gatherer.Add(tx)
+				if err == nil {
+					err = p.persist(tx)
+				}
+
 				if err != nil {
 					p.logger.Debug().Err(err).Msg("failed to add transaction")
 				}
@@ -124,3 +256,74 @@ func (p *Pool) listenRumors(ch <-chan gossip.Rumor) {
 		}
 	}
 }
+
+// persist writes the transaction to the database, or deletes the transaction
+// revoked by a cancellation.
+func (p *Pool) persist(tx txn.Transaction) error {
+	if p.db == nil {
+		return nil
+	}
+
+	if pool.IsCancellation(tx) {
+		return p.forget(tx, tx.GetArg(pool.CancelArg))
+	}
+
+	key, err := makeKey(tx, tx.GetID())
+	if err != nil {
+		return err
+	}
+
+	data, err := tx.Serialize(p.context)
+	if err != nil {
+		return xerrors.Errorf("failed to serialize: %v", err)
+	}
+
+	return p.db.Update(func(wtx kv.WritableTx) error {
+		bucket, err := wtx.GetBucketOrCreate(bucketName)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Set(key, data)
+	})
+}
+
+// forget deletes the transaction with the given identifier, and with the
+// identity and the nonce of the transaction, from the database.
+func (p *Pool) forget(tx txn.Transaction, id []byte) error {
+	if p.db == nil {
+		return nil
+	}
+
+	key, err := makeKey(tx, id)
+	if err != nil {
+		return err
+	}
+
+	return p.db.Update(func(wtx kv.WritableTx) error {
+		bucket, err := wtx.GetBucketOrCreate(bucketName)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Delete(key)
+	})
+}
+
+// makeKey returns the key of a transaction in the database, which is made of
+// its identity, its nonce and its identifier, so that a cancellation can only
+// delete a transaction of the same identity and nonce.
+func makeKey(tx txn.Transaction, id []byte) ([]byte, error) {
+	key, err := tx.GetIdentity().MarshalText()
+	if err != nil {
+		return nil, xerrors.Errorf("identity key failed: %v", err)
+	}
+
+	nonce := make([]byte, 8)
+	binary.BigEndian.PutUint64(nonce, tx.GetNonce())
+
+	key = append(key, nonce...)
+	key = append(key, id...)
+
+	return key, nil
+}
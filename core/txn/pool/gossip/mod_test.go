@@ -10,8 +10,10 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/store/kv"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/txn/pool"
+	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/gossip"
@@ -67,6 +69,109 @@ func TestPool_New(t *testing.T) {
 	require.EqualError(t, err, fake.Err("failed to listen"))
 }
 
+func TestPool_NewPersistent(t *testing.T) {
+	pool, err := NewPersistentPool(fakeGossiper{}, fake.NewInMemoryDB(), fakeTxFac{})
+	require.NoError(t, err)
+	require.NotNil(t, pool.db)
+	require.NoError(t, pool.Close())
+
+	_, err = NewPersistentPool(fakeGossiper{err: fake.GetError()}, fake.NewInMemoryDB(), fakeTxFac{})
+	require.EqualError(t, err, fake.Err("failed to listen"))
+}
+
+func TestPool_Load(t *testing.T) {
+	db := fake.NewInMemoryDB()
+	bucket := fake.NewBucket()
+	db.SetBucket(bucketName, bucket)
+
+	p := makePersistentPool(db)
+
+	require.NoError(t, p.Add(makeTx(0)))
+	require.NoError(t, p.Add(makeTx(1)))
+	require.NoError(t, p.Add(makeTx(2)))
+	require.NoError(t, bucket.Set([]byte("A"), []byte{}))
+
+	// The node restarts and the second transaction is not valid anymore.
+	p = makePersistentPool(db)
+	p.AddFilter(fakeFilter{nonce: 1})
+
+	err := p.Load()
+	require.NoError(t, err)
+	require.Equal(t, 2, p.Len())
+	require.NotNil(t, bucket.Get(mustKey(t, makeTx(0))))
+	require.Nil(t, bucket.Get(mustKey(t, makeTx(1))))
+	require.NotNil(t, bucket.Get(mustKey(t, makeTx(2))))
+	require.Nil(t, bucket.Get([]byte("A")))
+
+	p.db = nil
+	require.NoError(t, p.Load())
+
+	p.db = fake.NewInMemoryDB()
+	require.NoError(t, p.Load())
+
+	p.db = fake.NewBadViewDB()
+	err = p.Load()
+	require.EqualError(t, err, fake.Err("failed to read"))
+
+	db = fake.NewInMemoryDB()
+	db.SetBucket(bucketName, fake.NewBadDeleteBucket())
+	p.db = db
+	require.NoError(t, p.persist(makeTx(1)))
+
+	err = p.Load()
+	require.EqualError(t, err, fake.Err("failed to delete stale transactions"))
+
+	p.db = fake.NewBadDB()
+	err = p.Load()
+	require.EqualError(t, err, fake.Err("failed to delete stale transactions"))
+}
+
+func TestPool_Persist(t *testing.T) {
+	db := fake.NewInMemoryDB()
+	bucket := fake.NewBucket()
+	db.SetBucket(bucketName, bucket)
+
+	p := makePersistentPool(db)
+
+	require.NoError(t, p.Add(makeTx(0)))
+	require.NoError(t, p.Add(makeTx(1)))
+	require.Equal(t, []byte{0}, bucket.Get(mustKey(t, makeTx(0))))
+	require.Equal(t, []byte{1}, bucket.Get(mustKey(t, makeTx(1))))
+
+	require.NoError(t, p.Remove(makeTx(0)))
+	require.Nil(t, bucket.Get(mustKey(t, makeTx(0))))
+
+	// A cancellation deletes the transaction it revokes.
+	require.NoError(t, p.Add(fakeTx{nonce: 1, cancel: []byte{1}}))
+	require.Nil(t, bucket.Get(mustKey(t, makeTx(1))))
+	require.Equal(t, 0, p.Len())
+
+	ch := make(chan gossip.Rumor)
+	go func() {
+		ch <- makeTx(2)
+		close(p.closing)
+	}()
+
+	p.listenRumors(ch)
+	require.Equal(t, []byte{2}, bucket.Get(mustKey(t, makeTx(2))))
+
+	err := p.persist(fakeTx{nonce: 3, identity: fake.NewBadPublicKey()})
+	require.EqualError(t, err, fake.Err("identity key failed"))
+
+	err = p.persist(fakeTx{nonce: 3, badSerialize: true})
+	require.EqualError(t, err, fake.Err("failed to serialize"))
+
+	err = p.forget(fakeTx{nonce: 3, identity: fake.NewBadPublicKey()}, []byte{3})
+	require.EqualError(t, err, fake.Err("identity key failed"))
+
+	p.db = fake.NewBadDB()
+	err = p.Add(makeTx(4))
+	require.EqualError(t, err, fake.Err("failed to persist tx"))
+
+	err = p.Remove(makeTx(4))
+	require.EqualError(t, err, fake.Err("failed to forget tx"))
+}
+
 func TestPool_Len(t *testing.T) {
 	p := &Pool{
 		gatherer: pool.NewSimpleGatherer(),
@@ -203,6 +308,25 @@ func makeTx(nonce uint64) txn.Transaction {
 	return fakeTx{nonce: nonce}
 }
 
+func makePersistentPool(db kv.DB) *Pool {
+	return &Pool{
+		logger:   zerolog.Nop(),
+		actor:    fakeActor{},
+		gatherer: pool.NewSimpleGatherer(),
+		closing:  make(chan struct{}),
+		db:       db,
+		fac:      fakeTxFac{},
+		context:  fake.NewContext(),
+	}
+}
+
+func mustKey(t *testing.T, tx txn.Transaction) []byte {
+	key, err := makeKey(tx, tx.GetID())
+	require.NoError(t, err)
+
+	return key
+}
+
 func makeRoster(t *testing.T, n int) (mino.Players, []*Pool) {
 	manager := minoch.NewManager()
 
@@ -233,7 +357,10 @@ func makeRoster(t *testing.T, n int) (mino.Players, []*Pool) {
 type fakeTx struct {
 	txn.Transaction
 
-	nonce uint64
+	nonce        uint64
+	cancel       []byte
+	identity     access.Identity
+	badSerialize bool
 }
 
 func (tx fakeTx) GetNonce() uint64 {
@@ -241,6 +368,10 @@ func (tx fakeTx) GetNonce() uint64 {
 }
 
 func (tx fakeTx) GetIdentity() access.Identity {
+	if tx.identity != nil {
+		return tx.identity
+	}
+
 	return fake.PublicKey{}
 }
 
@@ -248,7 +379,19 @@ func (tx fakeTx) GetID() []byte {
 	return []byte{byte(tx.nonce)}
 }
 
+func (tx fakeTx) GetArg(key string) []byte {
+	if key == pool.CancelArg {
+		return tx.cancel
+	}
+
+	return nil
+}
+
 func (tx fakeTx) Serialize(serde.Context) ([]byte, error) {
+	if tx.badSerialize {
+		return nil, fake.GetError()
+	}
+
 	return tx.GetID(), nil
 }
 
@@ -260,6 +403,26 @@ func (fakeTxFac) Deserialize(ctx serde.Context, data []byte) (serde.Message, err
 	return fakeTx{nonce: uint64(data[0])}, nil
 }
 
+func (fakeTxFac) TransactionOf(ctx serde.Context, data []byte) (txn.Transaction, error) {
+	if len(data) == 0 {
+		return nil, fake.GetError()
+	}
+
+	return fakeTx{nonce: uint64(data[0])}, nil
+}
+
+type fakeFilter struct {
+	nonce uint64
+}
+
+func (f fakeFilter) Accept(tx txn.Transaction, leeway validation.Leeway) error {
+	if tx.GetNonce() == f.nonce {
+		return fake.GetError()
+	}
+
+	return nil
+}
+
 type fakeActor struct {
 	gossip.Actor
 	call *fake.Call
@@ -295,6 +458,10 @@ func (g badGatherer) Add(tx txn.Transaction) error {
 	return fake.GetError()
 }
 
+func (g badGatherer) AddFrom(tx txn.Transaction, source string) error {
+	return fake.GetError()
+}
+
 func (g badGatherer) Remove(tx txn.Transaction) error {
 	return fake.GetError()
 }
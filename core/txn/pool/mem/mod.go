@@ -17,10 +17,11 @@ type Pool struct {
 	gatherer pool.Gatherer
 }
 
-// NewPool creates a new service.
-func NewPool() *Pool {
+// NewPool creates a new service. The options configure the gatherer of the
+// transactions.
+func NewPool(opts ...pool.GathererOption) *Pool {
 	return &Pool{
-		gatherer: pool.NewSimpleGatherer(),
+		gatherer: pool.NewSimpleGatherer(opts...),
 	}
 }
 
@@ -38,9 +39,15 @@ func (s *Pool) AddFilter(filter pool.Filter) {
 // Add implements pool.Pool. It adds the transaction to the pool of waiting
 // transactions.
 func (s *Pool) Add(tx txn.Transaction) error {
-	err := s.gatherer.Add(tx)
+	return s.AddFrom(tx, "")
+}
+
+// AddFrom implements pool.Pool. It adds the transaction to the pool of waiting
+// transactions while accounting for its source.
+func (s *Pool) AddFrom(tx txn.Transaction, source string) error {
+	err := s.gatherer.AddFrom(tx, source)
 	if err != nil {
-		return xerrors.Errorf("store failed: %v", err)
+		return xerrors.Errorf("store failed: %w", err)
 	}
 
 	return nil
@@ -113,6 +113,10 @@ func (tx fakeTx) GetID() []byte {
 	return tx.id
 }
 
+func (tx fakeTx) GetArg(key string) []byte {
+	return nil
+}
+
 type badGatherer struct {
 	pool.Gatherer
 }
@@ -121,6 +125,10 @@ func (g badGatherer) Add(tx txn.Transaction) error {
 	return fake.GetError()
 }
 
+func (g badGatherer) AddFrom(tx txn.Transaction, source string) error {
+	return fake.GetError()
+}
+
 func (g badGatherer) Remove(tx txn.Transaction) error {
 	return fake.GetError()
 }
@@ -5,12 +5,85 @@ package pool
 
 import (
 	"context"
+	"encoding/binary"
 
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/mino"
+	"golang.org/x/xerrors"
 )
 
+// CancelArg is the argument key of a transaction that revokes a pending
+// transaction. A cancellation must be created by the same identity and with the
+// same nonce as the pending transaction, and the argument holds the identifier
+// of the transaction to revoke.
+const CancelArg = "go.dedis.ch/dela.Cancel"
+
+// IsCancellation returns true if the transaction is a cancellation of a pending
+// transaction.
+func IsCancellation(tx txn.Transaction) bool {
+	return len(tx.GetArg(CancelArg)) > 0
+}
+
+// ReplaceArg is the argument key of a transaction that replaces the pending
+// transaction of the same identity and with the same nonce. The argument holds
+// the level of the replacement as an 8-byte big-endian integer, which must be
+// strictly higher than the level of the pending transaction, zero when it is
+// not a replacement, so that a replacement cannot be reverted by replaying the
+// transactions that were replaced.
+const ReplaceArg = "go.dedis.ch/dela.Replace"
+
+// ReplacementLevel returns the level of the replacement of the transaction, or
+// zero if it is not a replacement. It returns an error if the argument is not
+// a valid level.
+func ReplacementLevel(tx txn.Transaction) (uint64, error) {
+	value := tx.GetArg(ReplaceArg)
+	if value == nil {
+		return 0, nil
+	}
+
+	if len(value) != 8 {
+		return 0, xerrors.Errorf("invalid replacement level of %d bytes", len(value))
+	}
+
+	level := binary.BigEndian.Uint64(value)
+	if level == 0 {
+		return 0, xerrors.New("replacement level must be positive")
+	}
+
+	return level, nil
+}
+
+// MakeReplacementArg returns the argument of a replacement with the given
+// level.
+func MakeReplacementArg(level uint64) txn.Arg {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, level)
+
+	return txn.Arg{Key: ReplaceArg, Value: value}
+}
+
+// Priority is the priority class of a transaction. The transactions of a higher
+// class are drained first from the pool.
+type Priority int
+
+const (
+	// PriorityUser is the class of the user transactions, which is the default
+	// one.
+	PriorityUser Priority = iota
+
+	// PriorityGovernance is the class of the transactions that govern the
+	// chain, like the roster changes, so that they are not starved behind the
+	// user transactions.
+	PriorityGovernance
+)
+
+// Classifier is the interface to implement to drain the pool by priority.
+type Classifier interface {
+	// Classify returns the priority class of the transaction.
+	Classify(tx txn.Transaction) Priority
+}
+
 // Config is the set of parameters that allows one to change the behavior of the
 // gathering process.
 type Config struct {
@@ -23,6 +96,20 @@ type Config struct {
 	// transactions to come. It allows one to take action to stop the gathering
 	// if necessary.
 	Callback func()
+
+	// Progress is a function called with the number of pending transactions
+	// while the gathering waits for the minimum, once at the beginning and then
+	// each time the number changes. The gathering stops and returns the pending
+	// transactions, even if there are less than the minimum, when it returns
+	// true, which allows one to cut a block earlier. The number can be skipped
+	// when several transactions arrive in a short time.
+	Progress func(count int) bool
+
+	// Classifier sorts the transactions by priority class, from the highest to
+	// the lowest, when it is set. A transaction is never moved before a
+	// transaction of the same identity with a lower nonce, which means it
+	// takes the lowest class of those. The order is otherwise preserved.
+	Classifier Classifier
 }
 
 // Filter is the interface to implement to validate if a transaction will be
@@ -43,9 +130,15 @@ type Pool interface {
 	// Len returns the number of transactions available in the pool.
 	Len() int
 
-	// Add adds the transaction to the pool.
+	// Add adds the transaction to the pool. If the transaction is a
+	// cancellation, the pending transaction it revokes is removed instead.
 	Add(txn.Transaction) error
 
+	// AddFrom adds the transaction to the pool like Add, while the source it
+	// was submitted from, e.g. the address of a client, is accounted for by the
+	// admission policy.
+	AddFrom(tx txn.Transaction, source string) error
+
 	// Remove removes the transaction from the pool.
 	Remove(txn.Transaction) error
 
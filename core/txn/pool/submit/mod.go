@@ -0,0 +1,117 @@
+// Package submit implements an HTTP endpoint to submit transactions to the pool
+// of a node.
+//
+// The body of a POST request is a signed transaction in the JSON format. The
+// transaction is refused if the contract it targets is not served by the local
+// policy of the node, which only applies to this endpoint and never to the
+// transactions received from the other participants, or if its arguments do not
+// comply with the schema of the contract. The transactions are added to the pool
+// with the host of the client as their source, so that the admission policy of
+// the pool can limit the pending transactions per client.
+package submit
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/pool"
+	"go.dedis.ch/dela/serde"
+	sjson "go.dedis.ch/dela/serde/json"
+	"golang.org/x/xerrors"
+)
+
+// MaxBodySize is the maximum number of bytes of a request.
+const MaxBodySize = 1 << 20
+
+// Limits are the limits of the transactions decoded by the endpoint, so that a
+// hostile client cannot force large allocations.
+var Limits = serde.Limits{
+	MaxSize:     MaxBodySize,
+	MaxElements: 1024,
+	MaxDepth:    16,
+}
+
+// Response is the JSON document returned by the endpoint.
+type Response struct {
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// NewHandler returns an HTTP handler that decodes the transactions with the
+// factory and adds them to the pool if they are served by the execution.
+func NewHandler(p pool.Pool, fac txn.Factory, exec *native.Service) func(http.ResponseWriter, *http.Request) {
+	ctx := sjson.NewContext(sjson.WithLimits(Limits))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed,
+				xerrors.Errorf("method '%s' not allowed", r.Method))
+			return
+		}
+
+		data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, xerrors.Errorf("invalid body: %v", err))
+			return
+		}
+
+		tx, err := fac.TransactionOf(ctx, data)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, xerrors.Errorf("invalid transaction: %v", err))
+			return
+		}
+
+		// A cancellation targets a transaction and not a contract, so that it
+		// is always accepted.
+		if !pool.IsCancellation(tx) {
+			err = exec.IsServed(string(tx.GetArg(native.ContractArg)))
+			if err != nil {
+				writeError(w, http.StatusForbidden, xerrors.Errorf("not served: %v", err))
+				return
+			}
+
+			err = exec.CheckArgs(tx)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, xerrors.Errorf("schema: %v", err))
+				return
+			}
+		}
+
+		err = p.AddFrom(tx, sourceOf(r))
+		if xerrors.Is(err, pool.ErrTooManyPending) {
+			writeError(w, http.StatusTooManyRequests, xerrors.Errorf("pool: %v", err))
+			return
+		}
+
+		if err != nil {
+			writeError(w, http.StatusBadRequest, xerrors.Errorf("pool: %v", err))
+			return
+		}
+
+		json.NewEncoder(w).Encode(Response{ID: hex.EncodeToString(tx.GetID())})
+	}
+}
+
+// sourceOf returns the host of the client of the request, so that the number of
+// pending transactions is limited per client rather than per connection.
+func sourceOf(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(Response{Error: err.Error()})
+}
@@ -0,0 +1,165 @@
+package submit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/execution/native"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/pool"
+	"go.dedis.ch/dela/core/txn/pool/mem"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/crypto/bls"
+	sjson "go.dedis.ch/dela/serde/json"
+)
+
+func TestHandler(t *testing.T) {
+	exec := native.NewExecution()
+	exec.Set("abc", fakeContract{})
+	exec.Set("bad", fakeContract{})
+	exec.SetPolicy(native.NewDenyList("bad"))
+	exec.SetSchema("abc", native.NewArgSchema(native.Arg{Name: "abc:value", Required: true}))
+
+	p := mem.NewPool()
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		NewHandler(p, signed.NewTransactionFactory(), exec)))
+	defer srv.Close()
+
+	signer := bls.NewSigner()
+
+	tx := makeTx(t, signer, 0, signed.WithArg(native.ContractArg, []byte("abc")),
+		signed.WithArg("abc:value", []byte("A")))
+
+	resp := post(t, srv.URL, tx)
+	require.Equal(t, http.StatusOK, resp.status)
+	require.NotEmpty(t, resp.ID)
+	require.Equal(t, 1, p.Len())
+
+	tx = makeTx(t, signer, 1, signed.WithArg(native.ContractArg, []byte("bad")))
+
+	resp = post(t, srv.URL, tx)
+	require.Equal(t, http.StatusForbidden, resp.status)
+	require.Equal(t, "not served: policy: contract 'bad' is in the deny list", resp.Error)
+
+	tx = makeTx(t, signer, 1, signed.WithArg(native.ContractArg, []byte("abc")))
+
+	resp = post(t, srv.URL, tx)
+	require.Equal(t, http.StatusBadRequest, resp.status)
+	require.Equal(t, "schema: contract 'abc': invalid arguments: 'abc:value' is missing",
+		resp.Error)
+
+	tx = makeTx(t, signer, 1, signed.WithArg(native.ContractArg, []byte("none")))
+
+	resp = post(t, srv.URL, tx)
+	require.Equal(t, http.StatusForbidden, resp.status)
+	require.Equal(t, "not served: unknown contract 'none'", resp.Error)
+
+	// A cancellation is accepted even though it doesn't target a contract.
+	tx = makeTx(t, signer, 1, signed.WithArg(pool.CancelArg, []byte{1}))
+
+	resp = post(t, srv.URL, tx)
+	require.Equal(t, http.StatusOK, resp.status)
+
+	res, err := http.Post(srv.URL, "application/json", bytes.NewBufferString("{}"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, err = http.Get(srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+
+	big := bytes.NewBuffer(make([]byte, MaxBodySize+1))
+	res, err = http.Post(srv.URL, "application/json", big)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	deep := strings.Repeat("[", Limits.MaxDepth+1)
+	resp = postData(t, srv.URL, []byte(deep))
+	require.Equal(t, http.StatusBadRequest, resp.status)
+	require.Equal(t, "invalid transaction: failed to decode: "+
+		"failed to unmarshal: depth exceeds 16: limit exceeded", resp.Error)
+}
+
+func TestHandler_Admission(t *testing.T) {
+	exec := native.NewExecution()
+	exec.Set("abc", fakeContract{})
+
+	admission := pool.NewAdmission(pool.WithSourceLimit(1))
+
+	p := mem.NewPool(pool.WithAdmission(admission))
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		NewHandler(p, signed.NewTransactionFactory(), exec)))
+	defer srv.Close()
+
+	tx := makeTx(t, bls.NewSigner(), 0, signed.WithArg(native.ContractArg, []byte("abc")))
+
+	resp := post(t, srv.URL, tx)
+	require.Equal(t, http.StatusOK, resp.status)
+
+	// The limit applies to the client, whatever the identity.
+	tx = makeTx(t, bls.NewSigner(), 0, signed.WithArg(native.ContractArg, []byte("abc")))
+
+	resp = post(t, srv.URL, tx)
+	require.Equal(t, http.StatusTooManyRequests, resp.status)
+	require.Equal(t, "pool: store failed: admission refused: source '127.0.0.1' "+
+		"reached the limit of 1: too many pending transactions", resp.Error)
+	require.Equal(t, uint64(1), admission.GetRejections().Source)
+}
+
+func TestSourceOf(t *testing.T) {
+	require.Equal(t, "127.0.0.1", sourceOf(&http.Request{RemoteAddr: "127.0.0.1:2000"}))
+	require.Equal(t, "::1", sourceOf(&http.Request{RemoteAddr: "[::1]:2000"}))
+	require.Equal(t, "pipe", sourceOf(&http.Request{RemoteAddr: "pipe"}))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type response struct {
+	Response
+
+	status int
+}
+
+func makeTx(t *testing.T, signer bls.Signer, nonce uint64, opts ...signed.TransactionOption) txn.Transaction {
+	tx, err := signed.NewTransaction(nonce, signer.GetPublicKey(), opts...)
+	require.NoError(t, err)
+
+	require.NoError(t, tx.Sign(signer))
+
+	return tx
+}
+
+func post(t *testing.T, url string, tx txn.Transaction) response {
+	data, err := tx.Serialize(sjson.NewContext())
+	require.NoError(t, err)
+
+	return postData(t, url, data)
+}
+
+func postData(t *testing.T, url string, data []byte) response {
+	res, err := http.Post(url, "application/json", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	defer res.Body.Close()
+
+	resp := response{status: res.StatusCode}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resp.Response))
+
+	return resp
+}
+
+type fakeContract struct{}
+
+func (fakeContract) Execute(store.Snapshot, execution.Step) error {
+	return nil
+}
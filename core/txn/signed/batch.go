@@ -0,0 +1,50 @@
+// This file contains the verification of the signatures of a list of
+// transactions.
+
+package signed
+
+import (
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/ed25519"
+	"golang.org/x/xerrors"
+)
+
+// VerifyTransactions verifies the signatures of the signed transactions of the
+// list that have not been verified yet. The Ed25519 signatures are verified in
+// a single batch, and the others one by one.
+func VerifyTransactions(txs []txn.Transaction) error {
+	var batch crypto.BatchVerifier = ed25519.NewBatchVerifier()
+
+	batched := make([]*Transaction, 0, len(txs))
+
+	for _, tx := range txs {
+		signedTx, ok := tx.(*Transaction)
+		if !ok || !signedTx.unverified {
+			continue
+		}
+
+		err := batch.Add(signedTx.pubkey, signedTx.hash, signedTx.sig)
+		if err == nil {
+			batched = append(batched, signedTx)
+			continue
+		}
+
+		// The signature cannot be batched, it is verified on its own.
+		err = signedTx.VerifySignature()
+		if err != nil {
+			return xerrors.Errorf("tx %#x: %v", signedTx.hash, err)
+		}
+	}
+
+	err := batch.Verify()
+	if err != nil {
+		return xerrors.Errorf("batch: %v", err)
+	}
+
+	for _, tx := range batched {
+		tx.unverified = false
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package signed
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/ed25519"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestVerifyTransactions(t *testing.T) {
+	txs := []txn.Transaction{
+		makeUncheckedTx(t, 0, ed25519.NewSigner()),
+		makeUncheckedTx(t, 1, ed25519.NewSigner()),
+		makeUncheckedTx(t, 2, bls.NewSigner()),
+		fakeTx{},
+	}
+
+	// A verified transaction is not verified again.
+	tx, err := NewTransaction(3, fake.PublicKey{}, WithSignature(fake.Signature{}))
+	require.NoError(t, err)
+
+	txs = append(txs, tx)
+
+	err = VerifyTransactions(txs)
+	require.NoError(t, err)
+
+	for _, tx := range txs[:3] {
+		require.False(t, tx.(*Transaction).unverified)
+	}
+
+	require.NoError(t, VerifyTransactions(nil))
+}
+
+func TestVerifyTransactions_Invalid(t *testing.T) {
+	signer := ed25519.NewSigner()
+
+	txs := []txn.Transaction{
+		makeUncheckedTx(t, 0, signer),
+		makeUncheckedTx(t, 1, signer),
+	}
+
+	// The signature of the first transaction is swapped to the second one.
+	txs[1].(*Transaction).sig = txs[0].(*Transaction).sig
+
+	err := VerifyTransactions(txs)
+	require.EqualError(t, err,
+		"batch: signature 1: schnorr verify failed: schnorr: invalid signature")
+	require.True(t, txs[0].(*Transaction).unverified)
+
+	badTx, err := NewTransaction(0, fake.PublicKey{}, WithUncheckedSignature(fake.Signature{}))
+	require.NoError(t, err)
+
+	badTx.pubkey = fake.NewInvalidPublicKey()
+
+	err = VerifyTransactions([]txn.Transaction{badTx})
+	require.EqualError(t, err, fake.Err(fmt.Sprintf("tx %#x: invalid signature", badTx.hash)))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeUncheckedTx(t *testing.T, nonce uint64, signer crypto.Signer) *Transaction {
+	tx, err := NewTransaction(nonce, signer.GetPublicKey())
+	require.NoError(t, err)
+
+	sig, err := signer.Sign(tx.hash)
+	require.NoError(t, err)
+
+	tx, err = NewTransaction(nonce, signer.GetPublicKey(), WithUncheckedSignature(sig))
+	require.NoError(t, err)
+
+	return tx
+}
+
+type fakeTx struct {
+	txn.Transaction
+}
@@ -12,11 +12,11 @@ import (
 	"go.dedis.ch/dela/core/ordering"
 	"go.dedis.ch/dela/core/txn/signed"
 	"go.dedis.ch/dela/core/validation"
-	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/crypto"
 )
 
 // MgrController is a CLI controller that will inject a transaction manager
-// using the signer of the collective signing component.
+// using the key of the node reserved for the transactions.
 //
 // - implements node.Initializer
 type mgrController struct{}
@@ -31,7 +31,7 @@ func NewManagerController() node.Initializer {
 func (mgrController) SetCommands(node.Builder) {}
 
 // OnStart implements node.Initializer. It creates a transaction manager using
-// the signer of the collective signing component and injects it.
+// the transaction signer of the node and injects it.
 func (mgrController) OnStart(flags cli.Flags, inj node.Injector) error {
 	var srvc ordering.Service
 	err := inj.Resolve(&srvc)
@@ -45,13 +45,13 @@ func (mgrController) OnStart(flags cli.Flags, inj node.Injector) error {
 		return err
 	}
 
-	var c cosi.CollectiveSigning
-	err = inj.Resolve(&c)
+	var signer crypto.TransactionSigner
+	err = inj.Resolve(&signer)
 	if err != nil {
 		return err
 	}
 
-	mgr := signed.NewManager(c.GetSigner(), client{
+	mgr := signed.NewManager(signer, client{
 		srvc: srvc,
 		mgr:  nonceMgr,
 	})
@@ -72,7 +72,8 @@ func (fmt txFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error)
 }
 
 // Decode implements serde.FormatEngine. It returns the transaction from the
-// JSON data if appropriate, otherwise it returns an error.
+// JSON data if appropriate, otherwise it returns an error. The signature is
+// left to the factory to verify.
 func (fmt txFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
 	m := TransactionJSON{}
 	err := ctx.Unmarshal(data, &m)
@@ -95,7 +96,7 @@ func (fmt txFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error
 		args = append(args, signed.WithArg(key, value))
 	}
 
-	args = append(args, signed.WithSignature(sig))
+	args = append(args, signed.WithUncheckedSignature(sig))
 
 	if fmt.hashFactory != nil {
 		args = append(args, signed.WithHashFactory(fmt.hashFactory))
@@ -2,12 +2,15 @@ package json
 
 import (
 	"testing"
+	"testing/quick"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/txn/signed"
 	"go.dedis.ch/dela/crypto"
+	_ "go.dedis.ch/dela/crypto/bls/json"
 	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/internal/testing/gen"
 	"go.dedis.ch/dela/serde"
 )
 
@@ -49,7 +52,9 @@ func TestTxFormat_Decode(t *testing.T) {
 
 	msg, err := format.Decode(ctx, []byte(`{"Nonce":2,"Args":{"B":"AQ=="}}`))
 	require.NoError(t, err)
-	expected := makeTx(t, 2, fake.PublicKey{}, signed.WithArg("B", []byte{1}))
+	// The signature is left to the factory to verify.
+	expected := makeTx(t, 2, fake.PublicKey{}, signed.WithArg("B", []byte{1}),
+		signed.WithUncheckedSignature(fake.Signature{}))
 	require.Equal(t, expected, msg)
 
 	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
@@ -77,6 +82,43 @@ func TestTxFormat_Decode(t *testing.T) {
 	require.EqualError(t, err, fake.Err("signature: malformed"))
 }
 
+func TestTxFormat_Quick_RoundTrip(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+	fac := signed.NewTransactionFactory()
+
+	f := func(tx gen.Transaction) bool {
+		data, err := tx.Serialize(ctx)
+		require.NoError(t, err)
+
+		msg, err := fac.Deserialize(ctx, data)
+		require.NoError(t, err)
+
+		decoded := msg.(*signed.Transaction)
+		require.Equal(t, tx.GetID(), decoded.GetID())
+		pubkey := decoded.GetIdentity().(crypto.PublicKey)
+		require.NoError(t, pubkey.Verify(decoded.GetID(), decoded.GetSignature()))
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 20})
+	require.NoError(t, err)
+}
+
+func TestTxFormat_Quick_Malformed(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+	fac := signed.NewTransactionFactory()
+
+	f := func(input gen.Malformed) bool {
+		require.NotPanics(t, func() { fac.Deserialize(ctx, input.Data) })
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 200})
+	require.NoError(t, err)
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
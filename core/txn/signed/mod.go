@@ -11,13 +11,14 @@ package signed
 import (
 	"encoding/binary"
 	"io"
-	"sort"
 
 	"go.dedis.ch/dela"
 	"go.dedis.ch/dela/core/access"
 	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/pool"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/common"
+	"go.dedis.ch/dela/internal/collection"
 	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
@@ -40,6 +41,10 @@ type Transaction struct {
 	pubkey crypto.PublicKey
 	sig    crypto.Signature
 	hash   []byte
+
+	// unverified is true when the signature has been set without being
+	// verified against the identity.
+	unverified bool
 }
 
 type template struct {
@@ -63,6 +68,17 @@ func WithArg(key string, value []byte) TransactionOption {
 func WithSignature(sig crypto.Signature) TransactionOption {
 	return func(tmpl *template) {
 		tmpl.sig = sig
+		tmpl.unverified = false
+	}
+}
+
+// WithUncheckedSignature is an option to set a signature that is not verified
+// when the transaction is created. It must be verified later, either with
+// VerifySignature or in a batch with VerifyTransactions.
+func WithUncheckedSignature(sig crypto.Signature) TransactionOption {
+	return func(tmpl *template) {
+		tmpl.sig = sig
+		tmpl.unverified = true
 	}
 }
 
@@ -97,7 +113,7 @@ func NewTransaction(nonce uint64, pk crypto.PublicKey, opts ...TransactionOption
 
 	tmpl.hash = h.Sum(nil)
 
-	if tmpl.sig != nil {
+	if tmpl.sig != nil && !tmpl.unverified {
 		err := tmpl.pubkey.Verify(tmpl.hash, tmpl.sig)
 		if err != nil {
 			return nil, xerrors.Errorf("invalid signature: %v", err)
@@ -127,14 +143,31 @@ func (t *Transaction) GetSignature() crypto.Signature {
 	return t.sig
 }
 
-// GetArgs returns the list of arguments available.
+// VerifySignature returns nil if the signature of the transaction matches its
+// identity, otherwise an error.
+func (t *Transaction) VerifySignature() error {
+	if t.sig == nil {
+		return xerrors.New("missing signature")
+	}
+
+	err := t.pubkey.Verify(t.hash, t.sig)
+	if err != nil {
+		return xerrors.Errorf("invalid signature: %v", err)
+	}
+
+	t.unverified = false
+
+	return nil
+}
+
+// GetArgs returns the sorted list of arguments available.
 func (t *Transaction) GetArgs() []string {
-	args := make([]string, 0, len(t.args))
+	args := collection.NewOrderedSet()
 	for key := range t.args {
-		args = append(args, key)
+		args.Add(key)
 	}
 
-	return args
+	return args.Items()
 }
 
 // GetArg implements txn.Transaction. It returns the value of the argument if it
@@ -143,12 +176,18 @@ func (t *Transaction) GetArg(key string) []byte {
 	return t.args[key]
 }
 
-// Sign signs the transaction and stores the signature.
+// Sign signs the transaction and stores the signature. The key of the signer
+// must not be reserved for another usage than the transactions.
 func (t *Transaction) Sign(signer crypto.Signer) error {
 	if len(t.hash) == 0 {
 		return xerrors.New("missing digest in transaction")
 	}
 
+	err := crypto.CheckUsage(signer, crypto.TransactionUsage)
+	if err != nil {
+		return xerrors.Errorf("invalid signer: %v", err)
+	}
+
 	if !signer.GetPublicKey().Equal(t.pubkey) {
 		return xerrors.New("mismatch signer and identity")
 	}
@@ -159,6 +198,7 @@ func (t *Transaction) Sign(signer crypto.Signer) error {
 	}
 
 	t.sig = sig
+	t.unverified = false
 
 	return nil
 }
@@ -174,15 +214,8 @@ func (t *Transaction) Fingerprint(w io.Writer) error {
 		return xerrors.Errorf("couldn't write nonce: %v", err)
 	}
 
-	// Sort the argument to deterministically write them to the hash.
-	args := make(sort.StringSlice, 0, len(t.args))
-	for key := range t.args {
-		args = append(args, key)
-	}
-
-	sort.Sort(args)
-
-	for _, key := range args {
+	// The arguments are sorted to deterministically write them to the hash.
+	for _, key := range t.GetArgs() {
 		_, err = w.Write(append([]byte(key), t.args[key]...))
 		if err != nil {
 			return xerrors.Errorf("couldn't write arg: %v", err)
@@ -227,14 +260,33 @@ type SignatureFac struct{}
 type TransactionFactory struct {
 	pubkeyFac common.PublicKeyFactory
 	sigFac    common.SignatureFactory
+	deferred  bool
+}
+
+// FactoryOption is the type of options to create a transaction factory.
+type FactoryOption func(*TransactionFactory)
+
+// WithDeferredVerification is an option to deserialize the transactions
+// without verifying their signatures, so that the caller can verify them in a
+// batch with VerifyTransactions.
+func WithDeferredVerification() FactoryOption {
+	return func(f *TransactionFactory) {
+		f.deferred = true
+	}
 }
 
 // NewTransactionFactory returns a new factory.
-func NewTransactionFactory() TransactionFactory {
-	return TransactionFactory{
+func NewTransactionFactory(opts ...FactoryOption) TransactionFactory {
+	f := TransactionFactory{
 		pubkeyFac: common.NewPublicKeyFactory(),
 		sigFac:    common.NewSignatureFactory(),
 	}
+
+	for _, opt := range opts {
+		opt(&f)
+	}
+
+	return f
 }
 
 // Deserialize implements serde.Factory. It populates the transaction from the
@@ -261,6 +313,13 @@ func (f TransactionFactory) TransactionOf(ctx serde.Context, data []byte) (txn.T
 		return nil, xerrors.Errorf("invalid transaction of type '%T'", msg)
 	}
 
+	if tx.unverified && !f.deferred {
+		err = tx.VerifySignature()
+		if err != nil {
+			return nil, xerrors.Errorf("failed to verify: %v", err)
+		}
+	}
+
 	return tx, nil
 }
 
@@ -297,6 +356,32 @@ func NewManager(signer crypto.Signer, client Client) *TransactionManager {
 // Make implements txn.Manager. It creates a transaction populated with the
 // arguments.
 func (mgr *TransactionManager) Make(args ...txn.Arg) (txn.Transaction, error) {
+	tx, err := mgr.create(mgr.nonce, args)
+	if err != nil {
+		return nil, err
+	}
+
+	mgr.nonce++
+
+	return tx, nil
+}
+
+// Replace returns a transaction that replaces the pending transaction with the
+// same nonce, as long as the level is higher than the one of the pending
+// transaction. The nonce of the manager is not changed.
+func (mgr *TransactionManager) Replace(nonce, level uint64, args ...txn.Arg) (txn.Transaction, error) {
+	args = append(args[:len(args):len(args)], pool.MakeReplacementArg(level))
+
+	return mgr.create(nonce, args)
+}
+
+// Cancel returns a transaction that revokes the pending transaction with the
+// nonce and the identifier. The nonce of the manager is not changed.
+func (mgr *TransactionManager) Cancel(nonce uint64, id []byte) (txn.Transaction, error) {
+	return mgr.create(nonce, []txn.Arg{{Key: pool.CancelArg, Value: id}})
+}
+
+func (mgr *TransactionManager) create(nonce uint64, args []txn.Arg) (txn.Transaction, error) {
 	opts := make([]TransactionOption, len(args), len(args)+1)
 	for i, arg := range args {
 		opts[i] = WithArg(arg.Key, arg.Value)
@@ -304,7 +389,7 @@ func (mgr *TransactionManager) Make(args ...txn.Arg) (txn.Transaction, error) {
 
 	opts = append(opts, WithHashFactory(mgr.hashFac))
 
-	tx, err := NewTransaction(mgr.nonce, mgr.signer.GetPublicKey(), opts...)
+	tx, err := NewTransaction(nonce, mgr.signer.GetPublicKey(), opts...)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to create tx: %v", err)
 	}
@@ -314,8 +399,6 @@ func (mgr *TransactionManager) Make(args ...txn.Arg) (txn.Transaction, error) {
 		return nil, xerrors.Errorf("failed to sign: %v", err)
 	}
 
-	mgr.nonce++
-
 	return tx, nil
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/core/access"
 	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/pool"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
 	"go.dedis.ch/dela/internal/testing/fake"
@@ -17,6 +18,7 @@ func init() {
 	RegisterTransactionFormat(fake.GoodFormat, fake.Format{Msg: &Transaction{}})
 	RegisterTransactionFormat(fake.BadFormat, fake.NewBadFormat())
 	RegisterTransactionFormat(serde.Format("BAD_TYPE"), fake.Format{Msg: fake.Message{}})
+	RegisterTransactionFormat(serde.Format("UNVERIFIED"), fake.Format{Msg: &Transaction{unverified: true}})
 }
 
 func TestTransaction_New(t *testing.T) {
@@ -37,6 +39,36 @@ func TestTransaction_New(t *testing.T) {
 
 	_, err = NewTransaction(1, signer.GetPublicKey(), WithSignature(tx.GetSignature()))
 	require.EqualError(t, err, "invalid signature: bls verify failed: bls: invalid signature")
+
+	tx, err = NewTransaction(1, signer.GetPublicKey(), WithUncheckedSignature(tx.GetSignature()))
+	require.NoError(t, err)
+	require.True(t, tx.unverified)
+}
+
+func TestTransaction_VerifySignature(t *testing.T) {
+	signer := bls.NewSigner()
+
+	tx, err := NewTransaction(0, signer.GetPublicKey())
+	require.NoError(t, err)
+
+	err = tx.VerifySignature()
+	require.EqualError(t, err, "missing signature")
+
+	require.NoError(t, tx.Sign(signer))
+
+	tx, err = NewTransaction(0, signer.GetPublicKey(), WithUncheckedSignature(tx.GetSignature()))
+	require.NoError(t, err)
+
+	err = tx.VerifySignature()
+	require.NoError(t, err)
+	require.False(t, tx.unverified)
+
+	tx, err = NewTransaction(1, signer.GetPublicKey(), WithUncheckedSignature(tx.GetSignature()))
+	require.NoError(t, err)
+
+	err = tx.VerifySignature()
+	require.EqualError(t, err, "invalid signature: bls verify failed: bls: invalid signature")
+	require.True(t, tx.unverified)
 }
 
 func TestTransaction_GetID(t *testing.T) {
@@ -62,12 +94,10 @@ func TestTransaction_GetIdentity(t *testing.T) {
 }
 
 func TestTransaction_GetArgs(t *testing.T) {
-	tx, err := NewTransaction(5, fake.PublicKey{}, WithArg("A", []byte{1}), WithArg("B", []byte{2}))
+	tx, err := NewTransaction(5, fake.PublicKey{}, WithArg("B", []byte{2}), WithArg("A", []byte{1}))
 	require.NoError(t, err)
 
-	args := tx.GetArgs()
-	require.Contains(t, args, "A")
-	require.Contains(t, args, "B")
+	require.Equal(t, []string{"A", "B"}, tx.GetArgs())
 }
 
 func TestTransaction_GetArg(t *testing.T) {
@@ -102,6 +132,19 @@ func TestTransaction_Sign(t *testing.T) {
 	err = tx.Sign(fake.Signer{})
 	require.EqualError(t, err, "mismatch signer and identity")
 
+	// A key reserved for the transactions is accepted, but not a key of the
+	// consensus.
+	txSigner, err := crypto.NewTransactionSigner(signer)
+	require.NoError(t, err)
+	require.NoError(t, tx.Sign(txSigner))
+
+	consensusSigner, err := crypto.NewConsensusSigner(signer)
+	require.NoError(t, err)
+
+	err = tx.Sign(consensusSigner)
+	require.EqualError(t, err,
+		"invalid signer: key reserved for consensus cannot be used for transaction")
+
 	tx.pubkey = fake.PublicKey{}
 	err = tx.Sign(fake.NewBadSigner())
 	require.EqualError(t, err, fake.Err("signer"))
@@ -154,6 +197,17 @@ func TestTransactionFactory_Deserialize(t *testing.T) {
 
 	_, err = factory.Deserialize(fake.NewContextWithFormat(serde.Format("BAD_TYPE")), nil)
 	require.EqualError(t, err, "invalid transaction of type 'fake.Message'")
+
+	_, err = factory.Deserialize(fake.NewContextWithFormat(serde.Format("UNVERIFIED")), nil)
+	require.EqualError(t, err, "failed to verify: missing signature")
+}
+
+func TestTransactionFactory_Deferred_Deserialize(t *testing.T) {
+	factory := NewTransactionFactory(WithDeferredVerification())
+
+	msg, err := factory.Deserialize(fake.NewContextWithFormat(serde.Format("UNVERIFIED")), nil)
+	require.NoError(t, err)
+	require.True(t, msg.(*Transaction).unverified)
 }
 
 func TestManager_Make(t *testing.T) {
@@ -175,6 +229,37 @@ func TestManager_Make(t *testing.T) {
 	require.EqualError(t, err, fake.Err("failed to sign: signer"))
 }
 
+func TestManager_Replace(t *testing.T) {
+	mgr := NewManager(fake.NewSigner(), nil)
+	mgr.nonce = 3
+
+	tx, err := mgr.Replace(2, 5, txn.Arg{Key: "a", Value: []byte{1}})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), tx.GetNonce())
+	require.Equal(t, []byte{1}, tx.GetArg("a"))
+	require.Equal(t, uint64(3), mgr.nonce)
+
+	level, err := pool.ReplacementLevel(tx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), level)
+
+	mgr.signer = fake.NewBadSigner()
+	_, err = mgr.Replace(2, 5)
+	require.EqualError(t, err, fake.Err("failed to sign: signer"))
+}
+
+func TestManager_Cancel(t *testing.T) {
+	mgr := NewManager(fake.NewSigner(), nil)
+	mgr.nonce = 3
+
+	tx, err := mgr.Cancel(2, []byte{0xaa})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), tx.GetNonce())
+	require.True(t, pool.IsCancellation(tx))
+	require.Equal(t, []byte{0xaa}, tx.GetArg(pool.CancelArg))
+	require.Equal(t, uint64(3), mgr.nonce)
+}
+
 func TestManager_Sync(t *testing.T) {
 	mgr := NewManager(fake.NewSigner(), fakeClient{})
 
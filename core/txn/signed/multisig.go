@@ -0,0 +1,209 @@
+// This file contains the helper to collect the signatures of a group of signers
+// over a transaction.
+
+package signed
+
+import (
+	"encoding/json"
+
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"golang.org/x/xerrors"
+)
+
+// Draft is a transaction waiting for the signatures of a group of signers. The
+// identity of the transaction is the aggregate of the BLS public keys of the
+// group, so that the assembled transaction is verified like any other.
+//
+// Every signer of the draft must sign. A k-of-n policy is applied by creating
+// the draft with the k signers chosen among the n members.
+type Draft struct {
+	tx      *Transaction
+	signers []crypto.PublicKey
+	sigs    []crypto.Signature
+}
+
+// PartialSignature is the signature of a single signer of a draft.
+type PartialSignature struct {
+	Index     int
+	Signature []byte
+}
+
+// draftJSON is the format of an exported draft.
+type draftJSON struct {
+	Nonce   uint64
+	Args    map[string][]byte
+	Signers [][]byte
+}
+
+// NewDraft creates a new draft of a transaction that must be signed by every
+// signer of the list.
+func NewDraft(nonce uint64, signers []crypto.PublicKey, opts ...TransactionOption) (*Draft, error) {
+	if len(signers) == 0 {
+		return nil, xerrors.New("no signer")
+	}
+
+	identity, err := bls.AggregatePublicKeys(signers...)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to aggregate keys: %v", err)
+	}
+
+	tx, err := NewTransaction(nonce, identity, opts...)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create transaction: %v", err)
+	}
+
+	draft := &Draft{
+		tx:      tx,
+		signers: signers,
+		sigs:    make([]crypto.Signature, len(signers)),
+	}
+
+	return draft, nil
+}
+
+// ImportDraft creates a draft from its exported form.
+func ImportDraft(data []byte) (*Draft, error) {
+	var d draftJSON
+	err := json.Unmarshal(data, &d)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	signers := make([]crypto.PublicKey, len(d.Signers))
+	for i, raw := range d.Signers {
+		signers[i], err = bls.NewPublicKey(raw)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid signer %d: %v", i, err)
+		}
+	}
+
+	opts := make([]TransactionOption, 0, len(d.Args))
+	for key, value := range d.Args {
+		opts = append(opts, WithArg(key, value))
+	}
+
+	return NewDraft(d.Nonce, signers, opts...)
+}
+
+// GetTransaction returns the unsigned transaction of the draft.
+func (d *Draft) GetTransaction() *Transaction {
+	return d.tx
+}
+
+// Export returns the unsigned payload of the draft that can be sent to the
+// signers.
+func (d *Draft) Export() ([]byte, error) {
+	signers := make([][]byte, len(d.signers))
+	for i, signer := range d.signers {
+		raw, err := signer.MarshalBinary()
+		if err != nil {
+			return nil, xerrors.Errorf("failed to marshal signer %d: %v", i, err)
+		}
+
+		signers[i] = raw
+	}
+
+	data, err := json.Marshal(draftJSON{
+		Nonce:   d.tx.nonce,
+		Args:    d.tx.args,
+		Signers: signers,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Sign returns the partial signature of the signer, which must be part of the
+// group and not be reserved for another usage than the transactions.
+func (d *Draft) Sign(signer crypto.Signer) (PartialSignature, error) {
+	err := crypto.CheckUsage(signer, crypto.TransactionUsage)
+	if err != nil {
+		return PartialSignature{}, xerrors.Errorf("invalid signer: %v", err)
+	}
+
+	index := d.indexOf(signer.GetPublicKey())
+	if index < 0 {
+		return PartialSignature{}, xerrors.New("signer is not part of the draft")
+	}
+
+	sig, err := signer.Sign(d.tx.hash)
+	if err != nil {
+		return PartialSignature{}, xerrors.Errorf("signer: %v", err)
+	}
+
+	raw, err := sig.MarshalBinary()
+	if err != nil {
+		return PartialSignature{}, xerrors.Errorf("failed to marshal signature: %v", err)
+	}
+
+	return PartialSignature{Index: index, Signature: raw}, nil
+}
+
+// Add verifies the partial signature and adds it to the draft.
+func (d *Draft) Add(partial PartialSignature) error {
+	if partial.Index < 0 || partial.Index >= len(d.signers) {
+		return xerrors.Errorf("unknown signer %d", partial.Index)
+	}
+
+	sig := bls.NewSignature(partial.Signature)
+
+	err := d.signers[partial.Index].Verify(d.tx.hash, sig)
+	if err != nil {
+		return xerrors.Errorf("invalid signature of signer %d: %v", partial.Index, err)
+	}
+
+	d.sigs[partial.Index] = sig
+
+	return nil
+}
+
+// Missing returns the public keys of the signers that have not signed yet.
+func (d *Draft) Missing() []crypto.PublicKey {
+	missing := []crypto.PublicKey{}
+	for i, sig := range d.sigs {
+		if sig == nil {
+			missing = append(missing, d.signers[i])
+		}
+	}
+
+	return missing
+}
+
+// Assemble returns the transaction signed with the aggregate of the partial
+// signatures. It returns an error if a signature is missing.
+func (d *Draft) Assemble() (*Transaction, error) {
+	missing := len(d.Missing())
+	if missing > 0 {
+		return nil, xerrors.Errorf("missing %d signature(s)", missing)
+	}
+
+	agg, err := bls.AggregateSignatures(d.sigs...)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to aggregate: %v", err)
+	}
+
+	opts := []TransactionOption{WithSignature(agg)}
+	for key, value := range d.tx.args {
+		opts = append(opts, WithArg(key, value))
+	}
+
+	tx, err := NewTransaction(d.tx.nonce, d.tx.pubkey, opts...)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to assemble: %v", err)
+	}
+
+	return tx, nil
+}
+
+func (d *Draft) indexOf(pubkey crypto.PublicKey) int {
+	for i, signer := range d.signers {
+		if signer.Equal(pubkey) {
+			return i
+		}
+	}
+
+	return -1
+}
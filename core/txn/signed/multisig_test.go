@@ -0,0 +1,85 @@
+package signed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestDraft_Workflow(t *testing.T) {
+	signers := []crypto.Signer{bls.NewSigner(), bls.NewSigner(), bls.NewSigner()}
+	pubkeys := []crypto.PublicKey{signers[0].GetPublicKey(), signers[2].GetPublicKey()}
+
+	draft, err := NewDraft(3, pubkeys, WithArg("A", []byte("B")))
+	require.NoError(t, err)
+	require.Len(t, draft.Missing(), 2)
+
+	data, err := draft.Export()
+	require.NoError(t, err)
+
+	// The signers rebuild the same transaction from the payload.
+	imported, err := ImportDraft(data)
+	require.NoError(t, err)
+	require.Equal(t, draft.GetTransaction().GetID(), imported.GetTransaction().GetID())
+
+	partial, err := imported.Sign(signers[2])
+	require.NoError(t, err)
+	require.Equal(t, 1, partial.Index)
+
+	err = draft.Add(partial)
+	require.NoError(t, err)
+	require.Equal(t, []crypto.PublicKey{pubkeys[0]}, draft.Missing())
+
+	_, err = draft.Assemble()
+	require.EqualError(t, err, "missing 1 signature(s)")
+
+	partial, err = imported.Sign(signers[0])
+	require.NoError(t, err)
+	require.NoError(t, draft.Add(partial))
+
+	tx, err := draft.Assemble()
+	require.NoError(t, err)
+	require.Equal(t, draft.GetTransaction().GetID(), tx.GetID())
+	require.Equal(t, []byte("B"), tx.GetArg("A"))
+	require.NoError(t, tx.GetIdentity().(crypto.PublicKey).Verify(tx.GetID(), tx.GetSignature()))
+
+	_, err = draft.Sign(signers[1])
+	require.EqualError(t, err, "signer is not part of the draft")
+
+	consensusSigner, err := crypto.NewConsensusSigner(signers[0].(bls.Signer))
+	require.NoError(t, err)
+
+	_, err = draft.Sign(consensusSigner)
+	require.EqualError(t, err,
+		"invalid signer: key reserved for consensus cannot be used for transaction")
+
+	err = draft.Add(PartialSignature{Index: 2})
+	require.EqualError(t, err, "unknown signer 2")
+
+	partial.Index = 1
+	err = draft.Add(partial)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid signature of signer 1: ")
+}
+
+func TestNewDraft(t *testing.T) {
+	_, err := NewDraft(0, nil)
+	require.EqualError(t, err, "no signer")
+
+	_, err = NewDraft(0, []crypto.PublicKey{fake.PublicKey{}})
+	require.EqualError(t, err,
+		"failed to aggregate keys: invalid public key type 'fake.PublicKey'")
+}
+
+func TestImportDraft(t *testing.T) {
+	_, err := ImportDraft([]byte("{"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to unmarshal: ")
+
+	_, err = ImportDraft([]byte(`{"Signers":["AAAA"]}`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid signer 0: ")
+}
@@ -0,0 +1,151 @@
+package proto
+
+import (
+	protobuf "github.com/golang/protobuf/proto"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/common"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	signed.RegisterTransactionFormat(serde.FormatProtobuf, txFormat{})
+}
+
+// Transaction is the protobuf message of a transaction.
+type Transaction struct {
+	Nonce     uint64            `protobuf:"varint,1,opt,name=nonce,proto3"`
+	Args      map[string][]byte `protobuf:"bytes,2,rep,name=args,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	PublicKey []byte            `protobuf:"bytes,3,opt,name=public_key,json=publicKey,proto3"`
+	Signature []byte            `protobuf:"bytes,4,opt,name=signature,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *Transaction) Reset() { *m = Transaction{} }
+
+// String implements proto.Message.
+func (m *Transaction) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*Transaction) ProtoMessage() {}
+
+// TxFormat is the protobuf format engine for transactions.
+//
+// - implements serde.FormatEngine
+type txFormat struct {
+	hashFactory crypto.HashFactory
+}
+
+// Encode implements serde.FormatEngine. It returns the protobuf data of the
+// provided transaction if appropriate, otherwise it returns an error.
+func (fmt txFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	tx, ok := msg.(*signed.Transaction)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message of type '%T'", msg)
+	}
+
+	if tx.GetSignature() == nil {
+		return nil, xerrors.New("signature is missing")
+	}
+
+	args := map[string][]byte{}
+	for _, arg := range tx.GetArgs() {
+		args[arg] = tx.GetArg(arg)
+	}
+
+	pubkey, err := tx.GetIdentity().Serialize(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to encode public key: %v", err)
+	}
+
+	sig, err := tx.GetSignature().Serialize(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to encode signature: %v", err)
+	}
+
+	m := &Transaction{
+		Nonce:     tx.GetNonce(),
+		Args:      args,
+		PublicKey: pubkey,
+		Signature: sig,
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It returns the transaction from the
+// protobuf data if appropriate, otherwise it returns an error. The signature
+// is left to the factory to verify.
+func (fmt txFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := &Transaction{}
+	err := ctx.Unmarshal(data, m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	pubkey, err := decodeIdentity(ctx, m.PublicKey)
+	if err != nil {
+		return nil, xerrors.Errorf("public key: %v", err)
+	}
+
+	sig, err := decodeSignature(ctx, m.Signature)
+	if err != nil {
+		return nil, xerrors.Errorf("signature: %v", err)
+	}
+
+	args := make([]signed.TransactionOption, 0, len(m.Args)+2)
+	for key, value := range m.Args {
+		args = append(args, signed.WithArg(key, value))
+	}
+
+	args = append(args, signed.WithUncheckedSignature(sig))
+
+	if fmt.hashFactory != nil {
+		args = append(args, signed.WithHashFactory(fmt.hashFactory))
+	}
+
+	tx, err := signed.NewTransaction(m.Nonce, pubkey, args...)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create tx: %v", err)
+	}
+
+	return tx, nil
+}
+
+func decodeIdentity(ctx serde.Context, data []byte) (crypto.PublicKey, error) {
+	fac := ctx.GetFactory(signed.PublicKeyFac{})
+
+	factory, ok := fac.(common.PublicKeyFactory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid factory '%T'", fac)
+	}
+
+	pubkey, err := factory.PublicKeyOf(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("malformed: %v", err)
+	}
+
+	return pubkey, nil
+}
+
+func decodeSignature(ctx serde.Context, data []byte) (crypto.Signature, error) {
+	fac := ctx.GetFactory(signed.SignatureFac{})
+
+	factory, ok := fac.(crypto.SignatureFactory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid factory '%T'", fac)
+	}
+
+	sig, err := factory.SignatureOf(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("malformed: %v", err)
+	}
+
+	return sig, nil
+}
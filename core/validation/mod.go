@@ -7,6 +7,7 @@ package validation
 
 import (
 	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution"
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/serde"
@@ -23,6 +24,10 @@ type TransactionResult interface {
 	// transaction has been accepted, otherwise false with a message to explain
 	// the reason.
 	GetStatus() (bool, string)
+
+	// GetEvents returns the events emitted by the contracts during the
+	// execution of an accepted transaction.
+	GetEvents() []execution.Event
 }
 
 // Result is the result of a validation.
@@ -63,7 +68,7 @@ type Service interface {
 	// The leeway parameter allows to reduce some constraints.
 	Accept(store.Readable, txn.Transaction, Leeway) error
 
-	// Validate takes a snapshot and a list of transactions and returns a
-	// result.
-	Validate(store.Snapshot, []txn.Transaction) (Result, error)
+	// Validate takes a snapshot, the index of the block being executed and a
+	// list of transactions and returns a result.
+	Validate(snap store.Snapshot, index uint64, txs []txn.Transaction) (Result, error)
 }
@@ -56,7 +56,7 @@ func ExampleService_Validate() {
 
 	store := newStore()
 
-	res, err := srvc.Validate(store, []txn.Transaction{txA, txB, txC})
+	res, err := srvc.Validate(store, 0, []txn.Transaction{txA, txB, txC})
 	if err != nil {
 		panic("validation failed: " + err.Error())
 	}
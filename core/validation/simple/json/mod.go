@@ -3,6 +3,7 @@ package json
 import (
 	"encoding/json"
 
+	"go.dedis.ch/dela/core/execution"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/validation/simple"
 	"go.dedis.ch/dela/serde"
@@ -19,6 +20,14 @@ type TransactionResultJSON struct {
 	Transaction json.RawMessage
 	Accepted    bool
 	Reason      string
+	Events      []EventJSON `json:",omitempty"`
+}
+
+// EventJSON is the JSON message for the events of a transaction.
+type EventJSON struct {
+	Contract   string
+	Name       string
+	Attributes []execution.Attribute `json:",omitempty"`
 }
 
 // ResultJSON is the JSON message for results.
@@ -47,6 +56,14 @@ func (f txResFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error
 		Reason:      reason,
 	}
 
+	for _, e := range txres.GetEvents() {
+		m.Events = append(m.Events, EventJSON{
+			Contract:   e.Contract,
+			Name:       e.Name,
+			Attributes: e.Attributes,
+		})
+	}
+
 	data, err := ctx.Marshal(m)
 	if err != nil {
 		return nil, err
@@ -70,11 +87,24 @@ func (f txResFormat) Decode(ctx serde.Context, data []byte) (serde.Message, erro
 	}
 
 	tx, err := fac.TransactionOf(ctx, m.Transaction)
-	if err != nil {
+	if err != nil && ctx.IsLenient() {
+		// The transaction is kept as is so that the rest of the block can
+		// still be read.
+		tx = txn.NewOpaqueTransaction(m.Transaction, err)
+	} else if err != nil {
 		return nil, err
 	}
 
-	res := simple.NewTransactionResult(tx, m.Accepted, m.Reason)
+	events := make([]execution.Event, len(m.Events))
+	for i, e := range m.Events {
+		events[i] = execution.Event{
+			Contract:   e.Contract,
+			Name:       e.Name,
+			Attributes: e.Attributes,
+		}
+	}
+
+	res := simple.NewTransactionResult(tx, m.Accepted, m.Reason, events...)
 
 	return res, nil
 }
@@ -13,6 +13,7 @@ import (
 	"go.dedis.ch/dela/core/execution"
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/pool"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/crypto"
 	"golang.org/x/xerrors"
@@ -26,15 +27,91 @@ type Service struct {
 	execution execution.Service
 	fac       validation.ResultFactory
 	hashFac   crypto.HashFactory
+	hook      BlockHook
+	limit     LimitFunc
+	cost      CostFunc
+	gasMax    uint64
+	intrinsic CostFunc
+	payer     Payer
+}
+
+// BlockHook is a function called with the snapshot at the beginning of each
+// block, before the transactions are executed. It must be deterministic as
+// every node applies it.
+type BlockHook func(snap store.Snapshot, index uint64) error
+
+// LimitFunc is a function that reads the budget of a block in the snapshot,
+// or zero when the block is not limited.
+type LimitFunc func(snap store.Readable) (uint64, error)
+
+// CostFunc is a function that returns the cost of a transaction. It must be
+// deterministic as every node computes it.
+type CostFunc func(tx txn.Transaction) uint64
+
+// Payer is the interface to implement to pay for the gas of the transactions.
+// The implementation must be deterministic as every node applies it, and it
+// must not write anything when it fails.
+type Payer interface {
+	// Check returns nil if the author of the transaction can pay for the
+	// amount of gas.
+	Check(snap store.Readable, tx txn.Transaction, gas uint64) error
+
+	// Charge debits the fee of the amount of gas from the author of the
+	// transaction.
+	Charge(snap store.Snapshot, tx txn.Transaction, gas uint64) error
+
+	// Refund credits back the fee of the amount of gas to the author of the
+	// transaction.
+	Refund(snap store.Snapshot, tx txn.Transaction, gas uint64) error
+}
+
+// Option is the type of option to create the service.
+type Option func(*Service)
+
+// WithBlockHook sets a function that updates the snapshot at the boundary of
+// each block.
+func WithBlockHook(hook BlockHook) Option {
+	return func(s *Service) {
+		s.hook = hook
+	}
+}
+
+// WithBudget sets the budget of the blocks. A block whose transactions cost
+// more than the budget read in the snapshot is refused.
+func WithBudget(limit LimitFunc, cost CostFunc) Option {
+	return func(s *Service) {
+		s.limit = limit
+		s.cost = cost
+	}
+}
+
+// WithGas sets the metering of the executions. A transaction declares the
+// maximum amount of gas it consumes, which cannot be above the given maximum,
+// or it gets the maximum. The intrinsic cost of a transaction is consumed
+// before it is executed. The fee of the limit is charged to the author before
+// the execution by the payer, if any, and the unused gas is refunded after, so
+// that a transaction pays for the gas it consumes even when it fails.
+func WithGas(max uint64, intrinsic CostFunc, payer Payer) Option {
+	return func(s *Service) {
+		s.gasMax = max
+		s.intrinsic = intrinsic
+		s.payer = payer
+	}
 }
 
 // NewService creates a new validation service.
-func NewService(exec execution.Service, f txn.Factory) Service {
-	return Service{
+func NewService(exec execution.Service, f txn.Factory, opts ...Option) Service {
+	s := Service{
 		execution: exec,
 		fac:       NewResultFactory(f),
 		hashFac:   crypto.NewSha256Factory(),
 	}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return s
 }
 
 // GetFactory implements validation.Service. It returns the result factory.
@@ -67,7 +144,8 @@ func (s Service) GetNonce(store store.Readable, ident access.Identity) (uint64,
 }
 
 // Accept implements validation.Service. It returns nil if the transaction would
-// be accepted by the service given some leeway and a snapshot of the storage.
+// be accepted by the service given some leeway and a snapshot of the storage. A
+// replacement must have a valid level.
 func (s Service) Accept(store store.Readable, tx txn.Transaction, leeway validation.Leeway) error {
 	nonce, err := s.GetNonce(store, tx.GetIdentity())
 	if err != nil {
@@ -84,15 +162,50 @@ func (s Service) Accept(store store.Readable, tx txn.Transaction, leeway validat
 		return xerrors.Errorf("nonce '%d' above the limit '%d'", tx.GetNonce(), limit)
 	}
 
+	_, err = pool.ReplacementLevel(tx)
+	if err != nil {
+		return xerrors.Errorf("invalid replacement: %v", err)
+	}
+
+	if s.gasMax == 0 {
+		return nil
+	}
+
+	gas, err := s.gasLimit(tx)
+	if err != nil {
+		return xerrors.Errorf("invalid gas: %v", err)
+	}
+
+	if s.payer != nil {
+		err = s.payer.Check(store, tx, gas)
+		if err != nil {
+			return xerrors.Errorf("cannot pay for gas: %v", err)
+		}
+	}
+
 	return nil
 }
 
 // Validate implements validation.Service. It processes the list of transactions
-// while updating the snapshot then returns a bundle of the transaction results.
-func (s Service) Validate(store store.Snapshot, txs []txn.Transaction) (validation.Result, error) {
+// of the block at the index while updating the snapshot then returns a bundle
+// of the transaction results.
+func (s Service) Validate(store store.Snapshot, index uint64, txs []txn.Transaction) (validation.Result, error) {
+	err := s.checkBudget(store, txs)
+	if err != nil {
+		return nil, xerrors.Errorf("budget: %v", err)
+	}
+
+	if s.hook != nil {
+		err := s.hook(store, index)
+		if err != nil {
+			return nil, xerrors.Errorf("block hook: %v", err)
+		}
+	}
+
 	results := make([]TransactionResult, len(txs))
 
 	step := execution.Step{
+		Index:    index,
 		Previous: make([]txn.Transaction, 0, len(txs)),
 	}
 
@@ -120,6 +233,38 @@ func (s Service) Validate(store store.Snapshot, txs []txn.Transaction) (validati
 	return res, nil
 }
 
+// checkBudget returns an error if the transactions cost more than the budget of
+// the block.
+func (s Service) checkBudget(store store.Readable, txs []txn.Transaction) error {
+	if s.limit == nil {
+		return nil
+	}
+
+	limit, err := s.limit(store)
+	if err != nil {
+		return xerrors.Errorf("failed to read limit: %v", err)
+	}
+
+	if limit == 0 {
+		return nil
+	}
+
+	total := uint64(0)
+
+	for _, tx := range txs {
+		cost := s.cost(tx)
+
+		// The sum is checked for an overflow before the limit.
+		if total+cost < total || total+cost > limit {
+			return xerrors.Errorf("block exceeds the limit of %d", limit)
+		}
+
+		total += cost
+	}
+
+	return nil
+}
+
 func (s Service) validateTx(store store.Snapshot, step execution.Step, r *TransactionResult) error {
 	expectedNonce, err := s.GetNonce(store, step.Current.GetIdentity())
 	if err != nil {
@@ -134,6 +279,32 @@ func (s Service) validateTx(store store.Snapshot, step execution.Step, r *Transa
 		return nil
 	}
 
+	// The level only matters while the transaction is pending, but a malformed
+	// one is refused like the pool does.
+	_, err = pool.ReplacementLevel(step.Current)
+	if err != nil {
+		r.reason = fmt.Sprintf("invalid replacement: %v", err)
+		r.accepted = false
+
+		return nil
+	}
+
+	if s.gasMax > 0 {
+		meter, reason, err := s.reserveGas(store, step.Current)
+		if err != nil {
+			return xerrors.Errorf("gas: %v", err)
+		}
+
+		if meter == nil {
+			r.reason = reason
+			r.accepted = false
+
+			return nil
+		}
+
+		step.Gas = meter
+	}
+
 	res, err := s.execution.Execute(store, step)
 	// if the execution fail, we don't return an error, but we take it as an
 	// invalid transaction.
@@ -143,6 +314,14 @@ func (s Service) validateTx(store store.Snapshot, step execution.Step, r *Transa
 	} else {
 		r.reason = res.Message
 		r.accepted = res.Accepted
+		r.events = res.Events
+	}
+
+	if step.Gas != nil && s.payer != nil {
+		err = s.payer.Refund(store, step.Current, step.Gas.GetLimit()-step.Gas.GetUsed())
+		if err != nil {
+			return xerrors.Errorf("failed to refund gas: %v", err)
+		}
 	}
 
 	// Update the nonce associated to the identity so that this transaction
@@ -155,6 +334,69 @@ func (s Service) validateTx(store store.Snapshot, step execution.Step, r *Transa
 	return nil
 }
 
+// reserveGas charges the fee of the gas limit of the transaction and returns
+// the meter of its execution, with the intrinsic cost already consumed. It
+// returns the reason instead when the transaction is refused, without consuming
+// its nonce, as it is not executed.
+func (s Service) reserveGas(store store.Snapshot, tx txn.Transaction) (*execution.Meter, string, error) {
+	limit, err := s.gasLimit(tx)
+	if err != nil {
+		return nil, fmt.Sprintf("invalid gas: %v", err), nil
+	}
+
+	if s.payer != nil {
+		err = s.payer.Check(store, tx, limit)
+		if err != nil {
+			return nil, fmt.Sprintf("cannot pay for gas: %v", err), nil
+		}
+
+		err = s.payer.Charge(store, tx, limit)
+		if err != nil {
+			return nil, "", xerrors.Errorf("failed to charge: %v", err)
+		}
+	}
+
+	meter := execution.NewMeter(limit)
+
+	// The limit is known to cover the intrinsic cost.
+	meter.Consume(s.intrinsicGas(tx))
+
+	return meter, "", nil
+}
+
+// gasLimit returns the gas limit of the transaction, or the maximum if it
+// doesn't declare one. It returns an error if the limit is above the maximum
+// or doesn't cover the intrinsic cost.
+func (s Service) gasLimit(tx txn.Transaction) (uint64, error) {
+	limit, found, err := execution.GasLimit(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	if !found {
+		limit = s.gasMax
+	}
+
+	if limit > s.gasMax {
+		return 0, xerrors.Errorf("limit %d above the maximum %d", limit, s.gasMax)
+	}
+
+	intrinsic := s.intrinsicGas(tx)
+	if intrinsic > limit {
+		return 0, xerrors.Errorf("intrinsic cost %d above the limit %d", intrinsic, limit)
+	}
+
+	return limit, nil
+}
+
+func (s Service) intrinsicGas(tx txn.Transaction) uint64 {
+	if s.intrinsic == nil {
+		return 0
+	}
+
+	return s.intrinsic(tx)
+}
+
 func (s Service) set(store store.Snapshot, ident access.Identity, nonce uint64) error {
 	key, err := s.keyFromIdentity(ident)
 	if err != nil {
@@ -7,6 +7,7 @@ import (
 	"go.dedis.ch/dela/core/execution"
 	"go.dedis.ch/dela/core/store"
 	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/pool"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"golang.org/x/xerrors"
@@ -74,28 +75,89 @@ func TestService_FutureNonce_Accept(t *testing.T) {
 	require.EqualError(t, err, "nonce '5' above the limit '1'")
 }
 
+func TestService_Replacement_Accept(t *testing.T) {
+	srvc := NewService(&fakeExec{}, nil)
+
+	tx := newTx()
+	tx.args = map[string][]byte{pool.ReplaceArg: pool.MakeReplacementArg(2).Value}
+
+	err := srvc.Accept(fakeSnapshot{}, tx, validation.Leeway{})
+	require.NoError(t, err)
+
+	tx.args[pool.ReplaceArg] = []byte{1}
+	err = srvc.Accept(fakeSnapshot{}, tx, validation.Leeway{})
+	require.EqualError(t, err, "invalid replacement: invalid replacement level of 1 bytes")
+}
+
 func TestService_Validate(t *testing.T) {
 	exec := &fakeExec{check: true}
 	srvc := NewService(exec, nil)
 
-	res, err := srvc.Validate(fakeSnapshot{}, []txn.Transaction{newTx(), newTx(), newTx()})
+	res, err := srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{newTx(), newTx(), newTx()})
 	require.NoError(t, err)
 	require.NotNil(t, res)
 	require.Equal(t, 3, exec.count)
 
 	tx := newTx()
 	tx.nonce = 1
-	res, err = srvc.Validate(fakeSnapshot{}, []txn.Transaction{tx})
+	res, err = srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{tx})
 	require.NoError(t, err)
 
 	status, _ := res.GetTransactionResults()[0].GetStatus()
 	require.False(t, status)
 }
 
+func TestService_Events_Validate(t *testing.T) {
+	event := execution.NewEvent("contract", "a", "key", "value")
+
+	srvc := NewService(&fakeExec{events: []execution.Event{event}}, nil)
+
+	res, err := srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{newTx()})
+	require.NoError(t, err)
+	require.Equal(t, []execution.Event{event}, res.GetTransactionResults()[0].GetEvents())
+}
+
+func TestService_Replacement_Validate(t *testing.T) {
+	exec := &fakeExec{}
+	srvc := NewService(exec, nil)
+
+	tx := newTx()
+	tx.args = map[string][]byte{pool.ReplaceArg: make([]byte, 8)}
+
+	res, err := srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{tx})
+	require.NoError(t, err)
+	require.Equal(t, 0, exec.count)
+
+	status, reason := res.GetTransactionResults()[0].GetStatus()
+	require.False(t, status)
+	require.Equal(t, "invalid replacement: replacement level must be positive", reason)
+}
+
+func TestService_Nonces_Validate(t *testing.T) {
+	srvc := NewService(&fakeExec{}, nil)
+
+	call := fake.NewCall()
+	snap := fake.NewSnapshot(fake.WithCall(call))
+
+	second := newTx()
+	second.nonce = 1
+
+	_, err := srvc.Validate(snap, 0, []txn.Transaction{newTx(), second})
+	require.NoError(t, err)
+	require.Equal(t, 1, snap.Len())
+	require.Equal(t, 4, call.Len())
+	require.Equal(t, "get", call.Get(0, 0))
+	require.Equal(t, "set", call.Get(1, 0))
+
+	nonce, err := srvc.GetNonce(snap, fake.PublicKey{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), nonce)
+}
+
 func TestService_NilIdentity_Validate(t *testing.T) {
 	srvc := NewService(&fakeExec{}, nil)
 
-	_, err := srvc.Validate(fakeSnapshot{}, []txn.Transaction{fakeTx{}})
+	_, err := srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{fakeTx{}})
 	require.EqualError(t, err, "tx 0x0a0b0c0d: nonce: missing identity in transaction")
 }
 
@@ -104,7 +166,7 @@ func TestService_FailStore_Validate(t *testing.T) {
 
 	store := fakeSnapshot{errSet: fake.GetError()}
 
-	_, err := srvc.Validate(store, []txn.Transaction{newTx()})
+	_, err := srvc.Validate(store, 0, []txn.Transaction{newTx()})
 	require.EqualError(t, err, fake.Err("tx 0x0a0b0c0d: failed to set nonce: store"))
 }
 
@@ -119,7 +181,7 @@ func TestService_FailIdentityToKey_Validate(t *testing.T) {
 func TestService_FailExecuteTx_Validate(t *testing.T) {
 	srvc := NewService(&fakeExec{err: fake.GetError()}, nil)
 
-	res, err := srvc.Validate(fakeSnapshot{}, []txn.Transaction{newTx()})
+	res, err := srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{newTx()})
 	require.NoError(t, err)
 
 	status, msg := res.GetTransactionResults()[0].GetStatus()
@@ -127,13 +189,166 @@ func TestService_FailExecuteTx_Validate(t *testing.T) {
 	require.Equal(t, fake.Err("failed to execute transaction"), msg)
 }
 
+func TestService_BlockHook_Validate(t *testing.T) {
+	var indices []uint64
+
+	srvc := NewService(&fakeExec{}, nil, WithBlockHook(func(snap store.Snapshot, index uint64) error {
+		indices = append(indices, index)
+		return nil
+	}))
+
+	_, err := srvc.Validate(fakeSnapshot{}, 5, []txn.Transaction{newTx()})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{5}, indices)
+
+	srvc = NewService(&fakeExec{}, nil, WithBlockHook(func(store.Snapshot, uint64) error {
+		return fake.GetError()
+	}))
+
+	_, err = srvc.Validate(fakeSnapshot{}, 0, nil)
+	require.EqualError(t, err, fake.Err("block hook"))
+}
+
+func TestService_Budget_Validate(t *testing.T) {
+	limit := uint64(2)
+
+	readLimit := func(store.Readable) (uint64, error) { return limit, nil }
+	cost := func(txn.Transaction) uint64 { return 1 }
+
+	srvc := NewService(&fakeExec{}, nil, WithBudget(readLimit, cost))
+
+	_, err := srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{newTx(), newTx()})
+	require.NoError(t, err)
+
+	_, err = srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{newTx(), newTx(), newTx()})
+	require.EqualError(t, err, "budget: block exceeds the limit of 2")
+
+	limit = 0
+
+	_, err = srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{newTx(), newTx(), newTx()})
+	require.NoError(t, err)
+
+	// The total of the costs overflows.
+	limit = ^uint64(0)
+	srvc.cost = func(txn.Transaction) uint64 { return limit - 1 }
+
+	_, err = srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{newTx(), newTx()})
+	require.EqualError(t, err, "budget: block exceeds the limit of 18446744073709551615")
+
+	srvc.limit = func(store.Readable) (uint64, error) { return 0, fake.GetError() }
+
+	_, err = srvc.Validate(fakeSnapshot{}, 0, nil)
+	require.EqualError(t, err, fake.Err("budget: failed to read limit"))
+}
+
+func TestService_Gas_Accept(t *testing.T) {
+	intrinsic := func(txn.Transaction) uint64 { return 10 }
+	payer := &fakePayer{}
+
+	srvc := NewService(&fakeExec{}, nil, WithGas(100, intrinsic, payer))
+
+	tx := newTx()
+	tx.args = map[string][]byte{}
+
+	err := srvc.Accept(fakeSnapshot{}, tx, validation.Leeway{})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{100}, payer.checked)
+
+	tx.args[execution.GasLimitArg] = execution.MakeGasLimitArg(200).Value
+	err = srvc.Accept(fakeSnapshot{}, tx, validation.Leeway{})
+	require.EqualError(t, err, "invalid gas: limit 200 above the maximum 100")
+
+	tx.args[execution.GasLimitArg] = execution.MakeGasLimitArg(5).Value
+	err = srvc.Accept(fakeSnapshot{}, tx, validation.Leeway{})
+	require.EqualError(t, err, "invalid gas: intrinsic cost 10 above the limit 5")
+
+	tx.args[execution.GasLimitArg] = []byte{1}
+	err = srvc.Accept(fakeSnapshot{}, tx, validation.Leeway{})
+	require.EqualError(t, err, "invalid gas: invalid gas limit of 1 bytes")
+
+	payer.errCheck = fake.GetError()
+	tx.args[execution.GasLimitArg] = execution.MakeGasLimitArg(50).Value
+	err = srvc.Accept(fakeSnapshot{}, tx, validation.Leeway{})
+	require.EqualError(t, err, fake.Err("cannot pay for gas"))
+}
+
+func TestService_Gas_Validate(t *testing.T) {
+	intrinsic := func(txn.Transaction) uint64 { return 10 }
+	payer := &fakePayer{}
+	exec := &fakeExec{gas: 20}
+
+	srvc := NewService(exec, nil, WithGas(100, intrinsic, payer))
+
+	tx := newTx()
+	tx.args = map[string][]byte{execution.GasLimitArg: execution.MakeGasLimitArg(50).Value}
+
+	res, err := srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{tx})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{50}, payer.charged)
+	require.Equal(t, []uint64{20}, payer.refunded)
+
+	status, _ := res.GetTransactionResults()[0].GetStatus()
+	require.True(t, status)
+
+	// The gas of a failed execution is paid.
+	exec.gas = 100
+	payer.charged = nil
+	payer.refunded = nil
+
+	res, err = srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{tx})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{50}, payer.charged)
+	require.Equal(t, []uint64{0}, payer.refunded)
+
+	status, reason := res.GetTransactionResults()[0].GetStatus()
+	require.False(t, status)
+	require.Equal(t, "consuming 100 unit(s) exceeds the limit of 50: out of gas", reason)
+
+	// The transaction is refused without being executed when its gas is
+	// invalid or cannot be paid.
+	exec.count = 0
+	tx.args[execution.GasLimitArg] = execution.MakeGasLimitArg(200).Value
+
+	res, err = srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{tx})
+	require.NoError(t, err)
+	require.Equal(t, 0, exec.count)
+
+	status, reason = res.GetTransactionResults()[0].GetStatus()
+	require.False(t, status)
+	require.Equal(t, "invalid gas: limit 200 above the maximum 100", reason)
+
+	payer.errCheck = fake.GetError()
+	tx.args[execution.GasLimitArg] = execution.MakeGasLimitArg(50).Value
+
+	res, err = srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{tx})
+	require.NoError(t, err)
+	require.Equal(t, 0, exec.count)
+
+	_, reason = res.GetTransactionResults()[0].GetStatus()
+	require.Equal(t, fake.Err("cannot pay for gas"), reason)
+
+	payer.errCheck = nil
+	payer.errCharge = fake.GetError()
+
+	_, err = srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{tx})
+	require.EqualError(t, err, fake.Err("tx 0x0a0b0c0d: gas: failed to charge"))
+
+	payer.errCharge = nil
+	payer.errRefund = fake.GetError()
+
+	_, err = srvc.Validate(fakeSnapshot{}, 0, []txn.Transaction{tx})
+	require.EqualError(t, err, fake.Err("tx 0x0a0b0c0d: failed to refund gas"))
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
 type fakeExec struct {
-	err   error
-	count int
-	check bool
+	err    error
+	count  int
+	check  bool
+	gas    uint64
+	events []execution.Event
 }
 
 func (e *fakeExec) Execute(store store.Snapshot, step execution.Step) (execution.Result, error) {
@@ -142,7 +357,37 @@ func (e *fakeExec) Execute(store store.Snapshot, step execution.Step) (execution
 	}
 
 	e.count++
-	return execution.Result{Accepted: true}, e.err
+
+	err := step.Gas.Consume(e.gas)
+	if err != nil {
+		return execution.Result{Message: err.Error()}, nil
+	}
+
+	return execution.Result{Accepted: true, Events: e.events}, e.err
+}
+
+type fakePayer struct {
+	checked   []uint64
+	charged   []uint64
+	refunded  []uint64
+	errCheck  error
+	errCharge error
+	errRefund error
+}
+
+func (p *fakePayer) Check(snap store.Readable, tx txn.Transaction, gas uint64) error {
+	p.checked = append(p.checked, gas)
+	return p.errCheck
+}
+
+func (p *fakePayer) Charge(snap store.Snapshot, tx txn.Transaction, gas uint64) error {
+	p.charged = append(p.charged, gas)
+	return p.errCharge
+}
+
+func (p *fakePayer) Refund(snap store.Snapshot, tx txn.Transaction, gas uint64) error {
+	p.refunded = append(p.refunded, gas)
+	return p.errRefund
 }
 
 type fakeSnapshot struct {
@@ -0,0 +1,215 @@
+package proto
+
+import (
+	protobuf "github.com/golang/protobuf/proto"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	simple.RegisterTransactionResultFormat(serde.FormatProtobuf, txResFormat{})
+	simple.RegisterResultFormat(serde.FormatProtobuf, resFormat{})
+}
+
+// TransactionResult is the protobuf message for transaction results.
+type TransactionResult struct {
+	Transaction []byte   `protobuf:"bytes,1,opt,name=transaction,proto3"`
+	Accepted    bool     `protobuf:"varint,2,opt,name=accepted,proto3"`
+	Reason      string   `protobuf:"bytes,3,opt,name=reason,proto3"`
+	Events      []*Event `protobuf:"bytes,4,rep,name=events,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *TransactionResult) Reset() { *m = TransactionResult{} }
+
+// String implements proto.Message.
+func (m *TransactionResult) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*TransactionResult) ProtoMessage() {}
+
+// Event is the protobuf message for the events of a transaction.
+type Event struct {
+	Contract   string       `protobuf:"bytes,1,opt,name=contract,proto3"`
+	Name       string       `protobuf:"bytes,2,opt,name=name,proto3"`
+	Attributes []*Attribute `protobuf:"bytes,3,rep,name=attributes,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *Event) Reset() { *m = Event{} }
+
+// String implements proto.Message.
+func (m *Event) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*Event) ProtoMessage() {}
+
+// Attribute is the protobuf message for the attributes of an event.
+type Attribute struct {
+	Key   string `protobuf:"bytes,1,opt,name=key,proto3"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *Attribute) Reset() { *m = Attribute{} }
+
+// String implements proto.Message.
+func (m *Attribute) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*Attribute) ProtoMessage() {}
+
+// Result is the protobuf message for results.
+type Result struct {
+	Results [][]byte `protobuf:"bytes,1,rep,name=results,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *Result) Reset() { *m = Result{} }
+
+// String implements proto.Message.
+func (m *Result) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*Result) ProtoMessage() {}
+
+type txResFormat struct{}
+
+func (f txResFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	txres, ok := msg.(simple.TransactionResult)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message")
+	}
+
+	tx, err := txres.GetTransaction().Serialize(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accepted, reason := txres.GetStatus()
+
+	m := &TransactionResult{
+		Transaction: tx,
+		Accepted:    accepted,
+		Reason:      reason,
+	}
+
+	for _, e := range txres.GetEvents() {
+		event := &Event{Contract: e.Contract, Name: e.Name}
+
+		for _, attr := range e.Attributes {
+			event.Attributes = append(event.Attributes, &Attribute{Key: attr.Key, Value: attr.Value})
+		}
+
+		m.Events = append(m.Events, event)
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (f txResFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := &TransactionResult{}
+	err := ctx.Unmarshal(data, m)
+	if err != nil {
+		return nil, err
+	}
+
+	factory := ctx.GetFactory(simple.TransactionKey{})
+
+	fac, ok := factory.(txn.Factory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid transaction factory")
+	}
+
+	tx, err := fac.TransactionOf(ctx, m.Transaction)
+	if err != nil && ctx.IsLenient() {
+		// The transaction is kept as is so that the rest of the block can
+		// still be read.
+		tx = txn.NewOpaqueTransaction(m.Transaction, err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	events := make([]execution.Event, len(m.Events))
+	for i, e := range m.Events {
+		events[i] = execution.Event{Contract: e.Contract, Name: e.Name}
+
+		for _, attr := range e.Attributes {
+			events[i].Attributes = append(events[i].Attributes,
+				execution.Attribute{Key: attr.Key, Value: attr.Value})
+		}
+	}
+
+	res := simple.NewTransactionResult(tx, m.Accepted, m.Reason, events...)
+
+	return res, nil
+}
+
+type resFormat struct{}
+
+func (f resFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	res, ok := msg.(simple.Result)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message")
+	}
+
+	results := res.GetTransactionResults()
+	raws := make([][]byte, len(results))
+
+	for i, res := range results {
+		buffer, err := res.Serialize(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		raws[i] = buffer
+	}
+
+	m := &Result{
+		Results: raws,
+	}
+
+	buffer, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer, nil
+}
+
+func (f resFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := &Result{}
+	err := ctx.Unmarshal(data, m)
+	if err != nil {
+		return nil, err
+	}
+
+	factory := ctx.GetFactory(simple.ResultKey{})
+
+	results := make([]simple.TransactionResult, len(m.Results))
+	for i, raw := range m.Results {
+		msg, err := factory.Deserialize(ctx, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		res, ok := msg.(simple.TransactionResult)
+		if !ok {
+			return nil, xerrors.Errorf("invalid transaction result")
+		}
+
+		results[i] = res
+	}
+
+	res := simple.NewResult(results)
+
+	return res, nil
+}
@@ -0,0 +1,62 @@
+package simple_test
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/txn"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/internal/testing/gen"
+)
+
+func TestService_Quick_Validate(t *testing.T) {
+	srvc := simple.NewService(acceptExec{}, signed.NewTransactionFactory())
+
+	f := func(batch []gen.Transaction) bool {
+		snap := fake.NewSnapshot()
+
+		txs := make([]txn.Transaction, len(batch))
+		for i, tx := range batch {
+			txs[i] = tx.Transaction
+		}
+
+		res, err := srvc.Validate(snap, 0, txs)
+		require.NoError(t, err)
+		require.Len(t, res.GetTransactionResults(), len(txs))
+
+		// A transaction is accepted only when its nonce is the next one of
+		// the identity, which is then moved forward.
+		expected := make(map[string]uint64)
+
+		for i, r := range res.GetTransactionResults() {
+			id, err := txs[i].GetIdentity().MarshalText()
+			require.NoError(t, err)
+
+			accepted, _ := r.GetStatus()
+			require.Equal(t, txs[i].GetNonce() == expected[string(id)], accepted)
+
+			if accepted {
+				expected[string(id)]++
+			}
+		}
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 20})
+	require.NoError(t, err)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type acceptExec struct{}
+
+func (acceptExec) Execute(store.Snapshot, execution.Step) (execution.Result, error) {
+	return execution.Result{Accepted: true}, nil
+}
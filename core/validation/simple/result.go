@@ -8,6 +8,7 @@ package simple
 import (
 	"io"
 
+	"go.dedis.ch/dela/core/execution"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/core/validation"
 	"go.dedis.ch/dela/serde"
@@ -38,15 +39,19 @@ type TransactionResult struct {
 	tx       txn.Transaction
 	accepted bool
 	reason   string
+	events   []execution.Event
 }
 
 // NewTransactionResult creates a new transaction result for the provided
-// transaction.
-func NewTransactionResult(tx txn.Transaction, accepted bool, reason string) TransactionResult {
+// transaction, with the events it emitted if any.
+func NewTransactionResult(tx txn.Transaction, accepted bool, reason string,
+	events ...execution.Event) TransactionResult {
+
 	return TransactionResult{
 		tx:       tx,
 		accepted: accepted,
 		reason:   reason,
+		events:   events,
 	}
 }
 
@@ -62,6 +67,12 @@ func (res TransactionResult) GetStatus() (bool, string) {
 	return res.accepted, res.reason
 }
 
+// GetEvents implements validation.TransactionResult. It returns the events
+// emitted by the transaction.
+func (res TransactionResult) GetEvents() []execution.Event {
+	return append([]execution.Event{}, res.events...)
+}
+
 // Serialize implements serde.Message. It returns the transaction result
 // serialized.
 func (res TransactionResult) Serialize(ctx serde.Context) ([]byte, error) {
@@ -146,10 +157,28 @@ func (d Result) Fingerprint(w io.Writer) error {
 			bit[0] = 1
 		}
 
+		// The flag of the events is only set when there are some, so that the
+		// digest of the results without events stays the same.
+		if len(res.events) > 0 {
+			bit[0] |= 2
+		}
+
 		_, err = w.Write(bit)
 		if err != nil {
 			return xerrors.Errorf("couldn't write accepted: %v", err)
 		}
+
+		if len(res.events) > 0 {
+			digest, err := execution.EventsDigest(res.events)
+			if err != nil {
+				return xerrors.Errorf("couldn't digest events: %v", err)
+			}
+
+			_, err = w.Write(digest)
+			if err != nil {
+				return xerrors.Errorf("couldn't write events: %v", err)
+			}
+		}
 	}
 
 	return nil
@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/core/access"
+	"go.dedis.ch/dela/core/execution"
 	"go.dedis.ch/dela/core/txn"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/internal/testing/fake"
@@ -34,6 +35,14 @@ func TestTransactionResult_GetStatus(t *testing.T) {
 	require.Equal(t, "", reason)
 }
 
+func TestTransactionResult_GetEvents(t *testing.T) {
+	res := NewTransactionResult(fakeTx{}, true, "")
+	require.Empty(t, res.GetEvents())
+
+	res = NewTransactionResult(fakeTx{}, true, "", execution.NewEvent("contract", "a"))
+	require.Equal(t, []execution.Event{execution.NewEvent("contract", "a")}, res.GetEvents())
+}
+
 func TestTransactionResult_Serialize(t *testing.T) {
 	res := NewTransactionResult(fakeTx{}, true, "")
 
@@ -79,6 +88,19 @@ func TestResult_Fingerprint(t *testing.T) {
 	err = res.Fingerprint(fake.NewBadHash())
 	require.EqualError(t, err, fake.Err("couldn't write accepted"))
 
+	// The results without events keep the same fingerprint.
+	other := new(bytes.Buffer)
+	res.txs[1].events = []execution.Event{execution.NewEvent("contract", "a")}
+	err = res.Fingerprint(other)
+	require.NoError(t, err)
+	require.NotEqual(t, buffer.Bytes(), other.Bytes())
+	require.Equal(t, buffer.Bytes()[:buffer.Len()-1], other.Bytes()[:buffer.Len()-1])
+	require.Equal(t, byte(3), other.Bytes()[buffer.Len()-1])
+	require.Len(t, other.Bytes(), buffer.Len()+32)
+
+	err = res.Fingerprint(fake.NewBadHashWithDelay(2))
+	require.EqualError(t, err, fake.Err("couldn't write events"))
+
 	res.txs[0].tx = fakeTx{err: fake.GetError()}
 	err = res.Fingerprint(buffer)
 	require.EqualError(t, err, fake.Err("couldn't fingerprint tx"))
@@ -117,6 +139,7 @@ type fakeTx struct {
 
 	nonce  uint64
 	pubkey crypto.PublicKey
+	args   map[string][]byte
 	err    error
 }
 
@@ -138,6 +161,10 @@ func (tx fakeTx) GetNonce() uint64 {
 	return tx.nonce
 }
 
+func (tx fakeTx) GetArg(key string) []byte {
+	return tx.args[key]
+}
+
 func (tx fakeTx) Fingerprint(io.Writer) error {
 	return tx.err
 }
@@ -38,6 +38,11 @@ func (h handler) Process(req mino.Request) (serde.Message, error) {
 			return nil, xerrors.Errorf("couldn't hash message: %v", err)
 		}
 
+		err = crypto.CheckUsage(h.signer, crypto.ConsensusUsage)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid signer: %v", err)
+		}
+
 		sig, err := h.signer.Sign(buf)
 		if err != nil {
 			return nil, xerrors.Errorf("couldn't sign: %v", err)
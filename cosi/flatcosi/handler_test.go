@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/mino"
@@ -59,6 +60,21 @@ func TestHandler_FailSign_Process(t *testing.T) {
 	require.EqualError(t, err, fake.Err("couldn't sign"))
 }
 
+func TestHandler_WrongUsage_Process(t *testing.T) {
+	signer, err := crypto.NewTransactionSigner(fake.NewSigner())
+	require.NoError(t, err)
+
+	h := newHandler(signer, fakeReactor{})
+
+	req := mino.Request{
+		Message: cosi.SignatureRequest{Value: fake.Message{}},
+	}
+
+	_, err = h.Process(req)
+	require.EqualError(t, err,
+		"invalid signer: key reserved for transaction cannot be used for consensus")
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
@@ -81,7 +81,10 @@ func (flat *Flat) Listen(r cosi.Reactor) (cosi.Actor, error) {
 
 	factory := cosi.NewMessageFactory(r, flat.signer.GetSignatureFactory())
 
-	actor.rpc = mino.MustCreateRPC(flat.mino, rpcName, newHandler(flat.signer, r), factory)
+	h := mino.WithPolicy(
+		mino.NewClassifiedHandler(newHandler(flat.signer, r), mino.ClassConsensus), mino.ProtocolPolicy)
+
+	actor.rpc = mino.MustCreateRPC(flat.mino, rpcName, h, factory)
 
 	return actor, nil
 }
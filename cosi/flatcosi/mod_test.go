@@ -3,12 +3,15 @@ package flatcosi
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cosi"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
 	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
 )
 
 func TestFlat_GetSigner(t *testing.T) {
@@ -64,6 +67,67 @@ func TestActor_Sign(t *testing.T) {
 	require.NotNil(t, sig)
 }
 
+func TestActor_Concurrent_Sign(t *testing.T) {
+	ca := fake.NewAuthority(3, bls.Generate)
+
+	// The players reply in the reverse order of the roster.
+	rpc := fake.NewPlayerRPC(func(addr mino.Address, req serde.Message) fake.Reply {
+		_, index := ca.GetPublicKey(addr)
+
+		sig, err := ca.GetSigner(index).Sign(testValue)
+		require.NoError(t, err)
+
+		return fake.Reply{
+			Message: cosi.SignatureResponse{Signature: sig},
+			Latency: time.Duration(ca.Len()-index) * 10 * time.Millisecond,
+		}
+	})
+
+	actor := flatActor{
+		signer:  bls.NewSigner(),
+		rpc:     rpc,
+		reactor: fakeReactor{},
+	}
+
+	sig, err := actor.Sign(context.Background(), fake.Message{}, ca)
+	require.NoError(t, err)
+	require.NotNil(t, sig)
+	require.Equal(t, 1, rpc.Calls.Len())
+}
+
+func TestActor_Timeout_Sign(t *testing.T) {
+	ca := fake.NewAuthority(3, bls.Generate)
+
+	rpc := fake.NewPlayerRPC(func(addr mino.Address, req serde.Message) fake.Reply {
+		_, index := ca.GetPublicKey(addr)
+
+		sig, err := ca.GetSigner(index).Sign(testValue)
+		require.NoError(t, err)
+
+		return fake.Reply{
+			Message: cosi.SignatureResponse{Signature: sig},
+			Drop:    index == 1,
+		}
+	})
+
+	actor := flatActor{
+		signer:  bls.NewSigner(),
+		rpc:     rpc,
+		reactor: fakeReactor{},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := actor.Sign(ctx, fake.Message{}, ca)
+	require.Error(t, err)
+	require.Regexp(t, "^couldn't verify the aggregation", err.Error())
+
+	_, err = flatActor{rpc: fake.NewBadPlayerRPC(), signer: bls.NewSigner()}.
+		Sign(ctx, fake.Message{}, ca)
+	require.EqualError(t, err, fake.Err("call aborted"))
+}
+
 func TestActor_NetworkError_Sign(t *testing.T) {
 	actor := flatActor{
 		signer:  fake.NewAggregateSigner(),
@@ -52,9 +52,10 @@ func (a thresholdActor) Sign(ctx context.Context, msg serde.Message,
 		return nil, xerrors.Errorf("couldn't react to message: %v", err)
 	}
 
-	// The aggregated signature needs to include at least a threshold number of
-	// signatures.
-	thres := a.thresholdFn.Load().(cosi.Threshold)(ca.Len())
+	// The aggregated signature needs to include the signatures of participants
+	// holding at least a threshold of the voting rights, which is a threshold
+	// number of signatures when the authority is not weighted.
+	thres := a.getThreshold(totalWeight(ca))
 
 	req := cosi.SignatureRequest{
 		Value: msg,
@@ -65,11 +66,11 @@ func (a thresholdActor) Sign(ctx context.Context, msg serde.Message,
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	go a.waitResp(errs, ca.Len()-thres, cancel)
+	go a.waitResp(errs, maxFailures(ca, thres), cancel)
 
-	count := 0
+	weight := 0
 	signature := new(types.Signature)
-	for count < thres {
+	for weight < thres {
 		addr, resp, err := rcvr.Recv(ctx)
 		if err != nil {
 			return nil, xerrors.Errorf("couldn't receive more messages: %v", err)
@@ -81,7 +82,7 @@ func (a thresholdActor) Sign(ctx context.Context, msg serde.Message,
 			if err != nil {
 				a.logger.Warn().Err(err).Msg("failed to process signature response")
 			} else {
-				count++
+				weight += weightOf(ca, index)
 			}
 		}
 	}
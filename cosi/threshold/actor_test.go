@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/cosi/threshold/types"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/internal/testing/fake"
 )
@@ -38,6 +39,36 @@ func TestActor_Sign(t *testing.T) {
 	require.NotNil(t, sig)
 }
 
+func TestActor_Weighted_Sign(t *testing.T) {
+	roster := fake.NewAuthority(3, fake.NewSigner)
+
+	recv := fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), cosi.SignatureResponse{Signature: fake.Signature{}}),
+	)
+	rpc := fake.NewStreamRPC(recv, fake.Sender{})
+
+	actor := thresholdActor{
+		Threshold: &Threshold{
+			signer: roster.GetSigner(0).(crypto.AggregateSigner),
+		},
+		rpc:     rpc,
+		reactor: fakeReactor{},
+	}
+
+	actor.SetThreshold(ByzantineThreshold)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The first participant holds enough voting rights on its own.
+	sig, err := actor.Sign(ctx, fake.Message{}, weightedAuthority{
+		CollectiveAuthority: roster,
+		weights:             []uint64{5, 1, 1},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{0}, sig.(*types.Signature).GetIndices())
+}
+
 func TestActor_BadNetwork_Sign(t *testing.T) {
 	actor := thresholdActor{
 		Threshold: &Threshold{},
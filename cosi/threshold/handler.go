@@ -10,6 +10,7 @@ import (
 	"io"
 
 	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 	"golang.org/x/xerrors"
@@ -64,6 +65,11 @@ func (h thresholdHandler) processRequest(sender mino.Sender, msg serde.Message,
 		return xerrors.Errorf("couldn't hash message: %v", err)
 	}
 
+	err = crypto.CheckUsage(h.signer, crypto.ConsensusUsage)
+	if err != nil {
+		return xerrors.Errorf("invalid signer: %v", err)
+	}
+
 	signature, err := h.signer.Sign(buffer)
 	if err != nil {
 		return xerrors.Errorf("couldn't sign: %v", err)
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/internal/testing/fake"
 )
 
@@ -90,3 +91,35 @@ func TestThresholdHandler_BadSigner_Stream(t *testing.T) {
 	require.NoError(t, err)
 	check(t)
 }
+
+func TestThresholdHandler_WrongUsage_Stream(t *testing.T) {
+	logger, check := fake.CheckLog(
+		"invalid signer: key reserved for transaction cannot be used for consensus")
+
+	handler := newHandler(
+		&Threshold{
+			logger: logger,
+		},
+		fakeReactor{},
+	)
+
+	handler.signer = transactionSigner{}
+	recv := fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), cosi.SignatureRequest{Value: fake.Message{}}),
+	)
+
+	err := handler.Stream(fake.Sender{}, recv)
+	require.NoError(t, err)
+	check(t)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type transactionSigner struct {
+	fake.Signer
+}
+
+func (transactionSigner) GetUsage() crypto.KeyUsage {
+	return crypto.TransactionUsage
+}
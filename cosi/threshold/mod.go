@@ -9,6 +9,7 @@
 package threshold
 
 import (
+	"sort"
 	"sync/atomic"
 
 	"github.com/rs/zerolog"
@@ -94,9 +95,10 @@ func (c *Threshold) GetSignatureFactory() crypto.SignatureFactory {
 }
 
 // GetVerifierFactory implements cosi.CollectiveSigning. It returns the verifier
-// factory.
+// factory. The verifiers reject the signatures that do not reach the threshold.
 func (c *Threshold) GetVerifierFactory() crypto.VerifierFactory {
-	return types.NewThresholdVerifierFactory(c.signer.GetVerifierFactory())
+	return types.NewThresholdVerifierFactory(c.signer.GetVerifierFactory(),
+		types.WithThreshold(c.getThreshold))
 }
 
 // SetThreshold implements cosi.CollectiveSigning. It sets a new threshold
@@ -109,17 +111,68 @@ func (c *Threshold) SetThreshold(fn cosi.Threshold) {
 	c.thresholdFn.Store(fn)
 }
 
+func (c *Threshold) getThreshold(n int) int {
+	return c.thresholdFn.Load().(cosi.Threshold)(n)
+}
+
 // Listen implements cosi.CollectiveSigning. It creates the rpc endpoint and
 // returns the actor that can trigger a collective signature.
 func (c *Threshold) Listen(r cosi.Reactor) (cosi.Actor, error) {
 	factory := cosi.NewMessageFactory(r, c.signer.GetSignatureFactory())
 
+	h := mino.WithPolicy(
+		mino.NewClassifiedHandler(newHandler(c, r), mino.ClassConsensus), mino.ProtocolPolicy)
+
 	actor := thresholdActor{
 		Threshold: c,
 		me:        c.mino.GetAddress(),
-		rpc:       mino.MustCreateRPC(c.mino, "cosi", newHandler(c, r), factory),
+		rpc:       mino.MustCreateRPC(c.mino, "cosi", h, factory),
 		reactor:   r,
 	}
 
 	return actor, nil
 }
+
+// weightOf returns the voting rights of the participant at the index, which is
+// one when the authority is not weighted.
+func weightOf(ca crypto.CollectiveAuthority, index int) int {
+	weighted, ok := ca.(crypto.WeightedAuthority)
+	if !ok {
+		return 1
+	}
+
+	return int(weighted.GetWeight(index))
+}
+
+// totalWeight returns the sum of the voting rights of the participants, which
+// is the number of participants when the authority is not weighted.
+func totalWeight(ca crypto.CollectiveAuthority) int {
+	weighted, ok := ca.(crypto.WeightedAuthority)
+	if !ok {
+		return ca.Len()
+	}
+
+	return int(weighted.TotalWeight())
+}
+
+// maxFailures returns the number of participants that can fail while the
+// others can still reach the threshold, which is when the participants with the
+// smallest voting rights fail first.
+func maxFailures(ca crypto.CollectiveAuthority, thres int) int {
+	weights := make([]int, ca.Len())
+	for i := range weights {
+		weights[i] = weightOf(ca, i)
+	}
+
+	sort.Ints(weights)
+
+	remaining := totalWeight(ca)
+	for i, weight := range weights {
+		remaining -= weight
+		if remaining < thres {
+			return i
+		}
+	}
+
+	return len(weights)
+}
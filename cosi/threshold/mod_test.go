@@ -2,12 +2,15 @@ package threshold
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cosi"
+	"go.dedis.ch/dela/cosi/threshold/types"
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
+	blsthreshold "go.dedis.ch/dela/crypto/bls/threshold"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/minoch"
@@ -41,6 +44,46 @@ func TestThreshold_Scenario_Basic(t *testing.T) {
 	require.NoError(t, verifier.Verify([]byte{0xff}, sig))
 }
 
+func TestThreshold_Scenario_ThresholdBLS(t *testing.T) {
+	manager := minoch.NewManager()
+
+	signers, err := blsthreshold.Deal(3, 4)
+	require.NoError(t, err)
+
+	minos := make([]mino.Mino, len(signers))
+	for i := range minos {
+		minos[i] = minoch.MustCreate(manager, fmt.Sprintf("node%d", i))
+	}
+
+	next := 0
+	ca := fake.NewAuthorityFromMino(func() crypto.Signer {
+		next++
+		return signers[next-1]
+	}, minos...)
+
+	thresholds := make([]*Threshold, len(signers))
+	actors := make([]cosi.Actor, len(signers))
+	for i, m := range minos {
+		thresholds[i] = NewThreshold(m, signers[i])
+		thresholds[i].SetThreshold(func(int) int { return 3 })
+
+		actors[i], err = thresholds[i].Listen(fakeReactor{})
+		require.NoError(t, err)
+	}
+
+	sig, err := actors[0].Sign(context.Background(), fake.Message{}, ca)
+	require.NoError(t, err)
+
+	// The aggregate is the signature of the group once a threshold of partial
+	// signatures has been combined.
+	agg := sig.(*types.Signature).GetAggregate()
+	require.NoError(t, signers[0].GetGroupKey().Verify([]byte{0xff}, agg))
+
+	verifier, err := thresholds[0].GetVerifierFactory().FromAuthority(ca)
+	require.NoError(t, err)
+	require.NoError(t, verifier.Verify([]byte{0xff}, sig))
+}
+
 func TestDefaultThreshold(t *testing.T) {
 	require.Equal(t, 2, defaultThreshold(2))
 	require.Equal(t, 5, defaultThreshold(5))
@@ -60,6 +103,23 @@ func TestByzantineThreshold(t *testing.T) {
 	require.Equal(t, 5, ByzantineThreshold(7))
 }
 
+func TestMaxFailures(t *testing.T) {
+	ca := fake.NewAuthority(4, fake.NewSigner)
+
+	require.Equal(t, 1, maxFailures(ca, 3))
+	require.Equal(t, 0, maxFailures(ca, 4))
+	require.Equal(t, 4, maxFailures(ca, 0))
+
+	weighted := weightedAuthority{
+		CollectiveAuthority: ca,
+		weights:             []uint64{1, 1, 1, 4},
+	}
+
+	require.Equal(t, 3, maxFailures(weighted, 4))
+	require.Equal(t, 2, maxFailures(weighted, 5))
+	require.Equal(t, 0, maxFailures(weighted, 7))
+}
+
 func TestThreshold_GetSigner(t *testing.T) {
 	c := &Threshold{signer: fake.NewAggregateSigner()}
 	require.NotNil(t, c.GetSigner())
@@ -108,3 +168,22 @@ type fakeReactor struct {
 func (h fakeReactor) Invoke(addr mino.Address, in serde.Message) ([]byte, error) {
 	return []byte{0xff}, h.err
 }
+
+type weightedAuthority struct {
+	fake.CollectiveAuthority
+
+	weights []uint64
+}
+
+func (ca weightedAuthority) GetWeight(index int) uint64 {
+	return ca.weights[index]
+}
+
+func (ca weightedAuthority) TotalWeight() uint64 {
+	total := uint64(0)
+	for _, w := range ca.weights {
+		total += w
+	}
+
+	return total
+}
@@ -219,37 +219,67 @@ func (f SignatureFactory) SignatureOf(ctx serde.Context, data []byte) (crypto.Si
 // - implements crypto.Verifier
 type Verifier struct {
 	pubkeys []crypto.PublicKey
-	factory crypto.VerifierFactory
+	// weights is nil when each participant has one vote.
+	weights   []uint64
+	threshold int
+	factory   crypto.VerifierFactory
 }
 
-func newVerifier(ca crypto.CollectiveAuthority, f crypto.VerifierFactory) Verifier {
+func newVerifier(ca crypto.CollectiveAuthority, f verifierFactory) Verifier {
 	pubkeys := make([]crypto.PublicKey, 0, ca.Len())
 	iter := ca.PublicKeyIterator()
 	for iter.HasNext() {
 		pubkeys = append(pubkeys, iter.GetNext())
 	}
 
-	return newVerifierArr(pubkeys, f)
+	weighted, ok := ca.(crypto.WeightedAuthority)
+	if !ok {
+		return newVerifierArr(pubkeys, f)
+	}
+
+	weights := make([]uint64, len(pubkeys))
+	for i := range weights {
+		weights[i] = weighted.GetWeight(i)
+	}
+
+	return Verifier{
+		pubkeys:   pubkeys,
+		weights:   weights,
+		threshold: f.threshold(int(weighted.TotalWeight())),
+		factory:   f.factory,
+	}
 }
 
-func newVerifierArr(pubkeys []crypto.PublicKey, f crypto.VerifierFactory) Verifier {
+func newVerifierArr(pubkeys []crypto.PublicKey, f verifierFactory) Verifier {
 	return Verifier{
-		pubkeys: pubkeys,
-		factory: f,
+		pubkeys:   pubkeys,
+		threshold: f.threshold(len(pubkeys)),
+		factory:   f.factory,
 	}
 }
 
 // Verify implements crypto.Verifier. It returns nil if the signature matches
-// the aggregate public key for the mask associated to the signature.
+// the aggregate public key for the mask associated to the signature, and if
+// the participants of the mask hold at least the threshold of voting rights.
 func (v Verifier) Verify(msg []byte, s crypto.Signature) error {
 	signature, ok := s.(*Signature)
 	if !ok {
 		return xerrors.Errorf("invalid signature type '%T' != '%T'", s, signature)
 	}
 
+	weight := 0
 	pubkeys := make([]crypto.PublicKey, 0, len(v.pubkeys))
 	for _, index := range signature.GetIndices() {
+		if index >= len(v.pubkeys) {
+			return xerrors.Errorf("index %d out of range", index)
+		}
+
 		pubkeys = append(pubkeys, v.pubkeys[index])
+		weight += v.getWeight(index)
+	}
+
+	if weight < v.threshold {
+		return xerrors.Errorf("not enough voting rights: %d < %d", weight, v.threshold)
 	}
 
 	verifier, err := v.factory.FromArray(pubkeys)
@@ -265,30 +295,66 @@ func (v Verifier) Verify(msg []byte, s crypto.Signature) error {
 	return nil
 }
 
+func (v Verifier) getWeight(index int) int {
+	if v.weights == nil {
+		return 1
+	}
+
+	return int(v.weights[index])
+}
+
+// VerifierOption is the type of option to configure the verifiers.
+type VerifierOption func(*verifierFactory)
+
+// WithThreshold sets the function that returns the voting rights that the
+// participants of a signature must hold for a total of n. By default, any
+// subset of the participants is accepted.
+func WithThreshold(fn func(n int) int) VerifierOption {
+	return func(f *verifierFactory) {
+		f.threshold = fn
+	}
+}
+
+func noThreshold(int) int {
+	return 0
+}
+
 // VerifierFactory is a factory to create a verifier from a list of
 // participants.
 type verifierFactory struct {
-	factory crypto.VerifierFactory
+	factory   crypto.VerifierFactory
+	threshold func(int) int
 }
 
 // NewThresholdVerifierFactory creates a new verifier factory from the
 // underlying verifier factory.
-func NewThresholdVerifierFactory(fac crypto.VerifierFactory) crypto.VerifierFactory {
-	return verifierFactory{
-		factory: fac,
+func NewThresholdVerifierFactory(fac crypto.VerifierFactory,
+	opts ...VerifierOption) crypto.VerifierFactory {
+
+	f := verifierFactory{
+		factory:   fac,
+		threshold: noThreshold,
+	}
+
+	for _, opt := range opts {
+		opt(&f)
 	}
+
+	return f
 }
 
 // FromAuthority implements crypto.VerifierFactory. It creates a verifier from
 // the authority so that the mask's signature will pick the participants that
-// have participated. The ordering of the authority must be the same.
+// have participated. The ordering of the authority must be the same. When the
+// authority is weighted, the threshold applies to the voting rights of the
+// participants.
 func (f verifierFactory) FromAuthority(authority crypto.CollectiveAuthority) (crypto.Verifier, error) {
-	return newVerifier(authority, f.factory), nil
+	return newVerifier(authority, f), nil
 }
 
 // FromArray implements crypto.VerifierFactory. It creates a verifier from the
 // list of public keys so that the mask's signature will pick the participants
 // that have participated. The ordering of the keys must be the same.
 func (f verifierFactory) FromArray(pubkeys []crypto.PublicKey) (crypto.Verifier, error) {
-	return newVerifierArr(pubkeys, f.factory), nil
+	return newVerifierArr(pubkeys, f), nil
 }
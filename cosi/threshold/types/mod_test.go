@@ -143,9 +143,9 @@ func TestSignatureFactory_SignatureOf(t *testing.T) {
 func TestVerifier_Verify(t *testing.T) {
 	call := &fake.Call{}
 
-	verifier := newVerifier(
-		fake.NewAuthority(3, fake.NewSigner),
-		fake.NewVerifierFactoryWithCalls(call))
+	fac := NewThresholdVerifierFactory(fake.NewVerifierFactoryWithCalls(call))
+
+	verifier := newVerifier(fake.NewAuthority(3, fake.NewSigner), fac.(verifierFactory))
 
 	err := verifier.Verify([]byte{0xff}, &Signature{mask: []byte{0x3}})
 	require.NoError(t, err)
@@ -162,6 +162,38 @@ func TestVerifier_Verify(t *testing.T) {
 	verifier.factory = fake.NewVerifierFactory(fake.NewBadVerifier())
 	err = verifier.Verify([]byte{}, &Signature{})
 	require.EqualError(t, err, fake.Err("invalid signature"))
+
+	err = verifier.Verify([]byte{}, &Signature{mask: []byte{0x8}})
+	require.EqualError(t, err, "index 3 out of range")
+}
+
+func TestVerifier_Threshold(t *testing.T) {
+	fac := NewThresholdVerifierFactory(fake.NewVerifierFactory(fake.Verifier{}),
+		WithThreshold(func(n int) int { return n - 1 }))
+
+	verifier, err := fac.FromAuthority(fake.NewAuthority(3, fake.NewSigner))
+	require.NoError(t, err)
+
+	err = verifier.Verify([]byte{}, &Signature{mask: []byte{0x3}})
+	require.NoError(t, err)
+
+	err = verifier.Verify([]byte{}, &Signature{mask: []byte{0x1}})
+	require.EqualError(t, err, "not enough voting rights: 1 < 2")
+
+	// The total weight is 6 so that the participants must hold 5 of it.
+	ca := weightedAuthority{
+		CollectiveAuthority: fake.NewAuthority(3, fake.NewSigner),
+		weights:             []uint64{1, 1, 4},
+	}
+
+	verifier, err = fac.FromAuthority(ca)
+	require.NoError(t, err)
+
+	err = verifier.Verify([]byte{}, &Signature{mask: []byte{0x5}})
+	require.NoError(t, err)
+
+	err = verifier.Verify([]byte{}, &Signature{mask: []byte{0x3}})
+	require.EqualError(t, err, "not enough voting rights: 2 < 5")
 }
 
 func TestVerifierFactory_FromArray(t *testing.T) {
@@ -175,3 +207,25 @@ func TestVerifierFactory_FromArray(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, verifier.(Verifier).pubkeys, 3)
 }
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type weightedAuthority struct {
+	fake.CollectiveAuthority
+
+	weights []uint64
+}
+
+func (ca weightedAuthority) GetWeight(index int) uint64 {
+	return ca.weights[index]
+}
+
+func (ca weightedAuthority) TotalWeight() uint64 {
+	total := uint64(0)
+	for _, w := range ca.weights {
+		total += w
+	}
+
+	return total
+}
@@ -0,0 +1,153 @@
+package bls
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+
+	"go.dedis.ch/kyber/v3"
+)
+
+// DefaultCacheSize is the number of aggregate public keys kept by the cache
+// shared by the verifier factories of the signers.
+const DefaultCacheSize = 128
+
+// defaultCache is the cache shared by the verifier factories of the signers,
+// so that the verifications against the same authority reuse the aggregate
+// public key whatever the component that verifies.
+var defaultCache = NewAggregateCache(DefaultCacheSize)
+
+// GetAggregateCache returns the cache shared by the verifier factories of the
+// signers, to read its metrics.
+func GetAggregateCache() *AggregateCache {
+	return defaultCache
+}
+
+// AggregateCache is a cache of aggregate public keys indexed by the
+// fingerprint of the set of public keys they aggregate. The least recently
+// used entry is evicted when the cache is full.
+type AggregateCache struct {
+	sync.Mutex
+
+	size      int
+	entries   map[string]*list.Element
+	order     *list.List
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type cacheEntry struct {
+	key string
+	agg kyber.Point
+}
+
+// NewAggregateCache creates a cache that holds at most size aggregate public
+// keys. A size lower than one is interpreted as one.
+func NewAggregateCache(size int) *AggregateCache {
+	if size < 1 {
+		size = 1
+	}
+
+	return &AggregateCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Len returns the number of aggregate public keys in the cache.
+func (c *AggregateCache) Len() int {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.order.Len()
+}
+
+// GetHits returns the number of aggregate public keys found in the cache.
+func (c *AggregateCache) GetHits() uint64 {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.hits
+}
+
+// GetMisses returns the number of aggregate public keys computed because they
+// were not in the cache.
+func (c *AggregateCache) GetMisses() uint64 {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.misses
+}
+
+// GetEvictions returns the number of aggregate public keys removed to make
+// room for new ones.
+func (c *AggregateCache) GetEvictions() uint64 {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.evictions
+}
+
+// GetHitRate returns the ratio of the lookups that found the aggregate public
+// key in the cache, or zero if there was none.
+func (c *AggregateCache) GetHitRate() float64 {
+	c.Lock()
+	defer c.Unlock()
+
+	total := c.hits + c.misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(c.hits) / float64(total)
+}
+
+// aggregate returns the aggregate of the points stored for the key, or
+// computes and stores it.
+func (c *AggregateCache) aggregate(key string, points []kyber.Point) kyber.Point {
+	c.Lock()
+	defer c.Unlock()
+
+	elem, found := c.entries[key]
+	if found {
+		c.hits++
+		c.order.MoveToFront(elem)
+
+		// The aggregate is shared so that a copy is returned in case the
+		// caller modifies it.
+		return elem.Value.(cacheEntry).agg.Clone()
+	}
+
+	c.misses++
+
+	agg := aggregatePoints(points)
+
+	c.entries[key] = c.order.PushFront(cacheEntry{key: key, agg: agg.Clone()})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(cacheEntry).key)
+
+		c.evictions++
+	}
+
+	return agg
+}
+
+// fingerprintPoints returns a digest of the points that identifies the set in
+// the cache. The order matters.
+func fingerprintPoints(points []kyber.Point) (string, error) {
+	h := sha256.New()
+
+	for _, point := range points {
+		_, err := point.MarshalTo(h)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return string(h.Sum(nil)), nil
+}
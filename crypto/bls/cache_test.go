@@ -0,0 +1,151 @@
+package bls
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/kyber/v3"
+	"golang.org/x/xerrors"
+)
+
+func TestAggregateCache_Aggregate(t *testing.T) {
+	cache := NewAggregateCache(2)
+
+	a := []kyber.Point{NewSigner().public, NewSigner().public}
+	b := []kyber.Point{NewSigner().public}
+	c := []kyber.Point{NewSigner().public}
+
+	require.Equal(t, 0.0, cache.GetHitRate())
+
+	agg := cache.aggregate("a", a)
+	require.True(t, aggregatePoints(a).Equal(agg))
+	require.Equal(t, uint64(1), cache.GetMisses())
+
+	// The cached aggregate is not affected by the caller.
+	agg.Null()
+
+	agg = cache.aggregate("a", nil)
+	require.True(t, aggregatePoints(a).Equal(agg))
+	require.Equal(t, uint64(1), cache.GetHits())
+
+	cache.aggregate("b", b)
+
+	// "a" is the most recently used so that "b" is evicted.
+	cache.aggregate("a", nil)
+	cache.aggregate("c", c)
+	require.Equal(t, 2, cache.Len())
+	require.Equal(t, uint64(1), cache.GetEvictions())
+
+	cache.aggregate("b", b)
+	require.Equal(t, uint64(4), cache.GetMisses())
+	require.Equal(t, uint64(2), cache.GetHits())
+	require.Equal(t, 2.0/6.0, cache.GetHitRate())
+
+	require.Equal(t, 1, NewAggregateCache(0).size)
+}
+
+func TestAggregateCache_FromAuthority(t *testing.T) {
+	cache := NewAggregateCache(DefaultCacheSize)
+	factory := NewVerifierFactory(cache)
+
+	authority := fake.NewAuthority(3, Generate)
+	ca := fakeAuthority{CollectiveAuthority: authority, fingerprint: "A"}
+
+	msg := []byte("deadbeef")
+	sig := signAll(t, authority, msg)
+
+	for i := 0; i < 3; i++ {
+		verifier, err := factory.FromAuthority(ca)
+		require.NoError(t, err)
+		require.NoError(t, verifier.Verify(msg, sig))
+	}
+
+	require.Equal(t, 1, cache.Len())
+	require.Equal(t, uint64(2), cache.GetHits())
+
+	// The authority is fingerprinted with its public keys when it doesn't
+	// provide a fingerprint.
+	for _, ca := range []crypto.CollectiveAuthority{authority, fakeAuthority{
+		CollectiveAuthority: authority,
+		err:                 xerrors.New("oops"),
+	}} {
+		verifier, err := factory.FromAuthority(ca)
+		require.NoError(t, err)
+		require.NoError(t, verifier.Verify(msg, sig))
+	}
+
+	require.Equal(t, 2, cache.Len())
+	require.Equal(t, uint64(3), cache.GetHits())
+}
+
+func TestAggregateCache_FromArray(t *testing.T) {
+	cache := NewAggregateCache(DefaultCacheSize)
+	factory := NewVerifierFactory(cache)
+
+	authority := fake.NewAuthority(3, Generate)
+
+	msg := []byte("deadbeef")
+	sig := signAll(t, authority, msg)
+
+	pubkeys := []crypto.PublicKey{}
+	iter := authority.PublicKeyIterator()
+	for iter.HasNext() {
+		pubkeys = append(pubkeys, iter.GetNext())
+	}
+
+	verifier, err := factory.FromArray(pubkeys)
+	require.NoError(t, err)
+	require.NoError(t, verifier.Verify(msg, sig))
+
+	// The order of the keys is part of the fingerprint.
+	verifier, err = factory.FromArray([]crypto.PublicKey{pubkeys[2], pubkeys[0], pubkeys[1]})
+	require.NoError(t, err)
+	require.NoError(t, verifier.Verify(msg, sig))
+
+	require.Equal(t, 2, cache.Len())
+
+	verifier, err = factory.FromArray(pubkeys[:2])
+	require.NoError(t, err)
+	require.Error(t, verifier.Verify(msg, sig))
+
+	require.Equal(t, 3, cache.Len())
+	require.Equal(t, uint64(0), cache.GetHits())
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeAuthority struct {
+	crypto.CollectiveAuthority
+
+	fingerprint string
+	err         error
+}
+
+func (ca fakeAuthority) Fingerprint(w io.Writer) error {
+	if ca.err != nil {
+		return ca.err
+	}
+
+	w.Write([]byte(ca.fingerprint))
+
+	return nil
+}
+
+func signAll(t *testing.T, authority fake.CollectiveAuthority, msg []byte) crypto.Signature {
+	sigs := []crypto.Signature{}
+	for i := 0; i < authority.Len(); i++ {
+		sig, err := authority.GetSigner(i).Sign(msg)
+		require.NoError(t, err)
+
+		sigs = append(sigs, sig)
+	}
+
+	agg, err := AggregateSignatures(sigs...)
+	require.NoError(t, err)
+
+	return agg
+}
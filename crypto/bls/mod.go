@@ -11,6 +11,7 @@ package bls
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"fmt"
 
 	"go.dedis.ch/dela/crypto"
@@ -28,6 +29,11 @@ import (
 const (
 	// Algorithm is the name of the curve used for the BLS signature.
 	Algorithm = "BLS-CURVE-BN256"
+
+	// The prefixes separate the keys of the cache computed from the
+	// fingerprint of an authority from the ones computed from the points.
+	authorityPrefix = "authority:"
+	keysPrefix      = "keys:"
 )
 
 var (
@@ -55,7 +61,8 @@ type PublicKey struct {
 }
 
 // NewPublicKey creates a new public key by unmarshaling the data into BN256
-// point.
+// point. The data must be the canonical encoding of a point of the prime order
+// subgroup.
 func NewPublicKey(data []byte) (PublicKey, error) {
 	point := suite.Point()
 	err := point.UnmarshalBinary(data)
@@ -63,6 +70,20 @@ func NewPublicKey(data []byte) (PublicKey, error) {
 		return PublicKey{}, err
 	}
 
+	err = checkCanonical(point, data)
+	if err != nil {
+		return PublicKey{}, err
+	}
+
+	// G2 has a cofactor so a point on the curve is not necessarily in the
+	// subgroup, which is checked by verifying that (q-1)*P + P is the identity.
+	check := suite.Point().Mul(suite.Scalar().SetInt64(-1), point)
+	check = check.Add(check, point)
+
+	if !check.Equal(suite.Point().Null()) {
+		return PublicKey{}, xerrors.New("point is not in the subgroup")
+	}
+
 	return PublicKey{point: point}, nil
 }
 
@@ -73,6 +94,24 @@ func NewPublicKeyFromPoint(point kyber.Point) PublicKey {
 	}
 }
 
+// AggregatePublicKeys returns the public key that verifies the aggregate of the
+// signatures of the keys. The caller is responsible for making sure that each
+// key is owned by its signer, to prevent rogue key attacks.
+func AggregatePublicKeys(keys ...crypto.PublicKey) (PublicKey, error) {
+	points := make([]kyber.Point, len(keys))
+
+	for i, key := range keys {
+		pubkey, ok := key.(PublicKey)
+		if !ok {
+			return PublicKey{}, xerrors.Errorf("invalid public key type '%T'", key)
+		}
+
+		points[i] = pubkey.point
+	}
+
+	return PublicKey{point: bls.AggregatePublicKeys(suite, points...)}, nil
+}
+
 // MarshalBinary implements encoding.BinaryMarshaler. It produces a slice of
 // bytes representing the public key.
 func (pk PublicKey) MarshalBinary() ([]byte, error) {
@@ -156,6 +195,19 @@ func NewSignature(data []byte) Signature {
 	}
 }
 
+// CheckCanonical returns nil if the signature is the canonical encoding of a
+// point on the curve, otherwise an error. G1 has a prime order so any point on
+// the curve is in the subgroup.
+func (sig Signature) CheckCanonical() error {
+	point := suite.G1().Point()
+	err := point.UnmarshalBinary(sig.data)
+	if err != nil {
+		return xerrors.Errorf("couldn't unmarshal point: %v", err)
+	}
+
+	return checkCanonical(point, sig.data)
+}
+
 // MarshalBinary implements encoding.BinaryMarshaler. It returns a slice of
 // bytes representing the signature.
 func (sig Signature) MarshalBinary() ([]byte, error) {
@@ -264,6 +316,14 @@ func (f signatureFactory) Deserialize(ctx serde.Context, data []byte) (serde.Mes
 		return nil, xerrors.Errorf("couldn't decode signature: %v", err)
 	}
 
+	sig, ok := m.(Signature)
+	if ok {
+		err = sig.CheckCanonical()
+		if err != nil {
+			return nil, xerrors.Errorf("invalid signature: %v", err)
+		}
+	}
+
 	return m, nil
 }
 
@@ -289,6 +349,14 @@ func (f signatureFactory) SignatureOf(ctx serde.Context, data []byte) (crypto.Si
 // - implements crypto.Verifier
 type blsVerifier struct {
 	points []kyber.Point
+
+	// cache is the cache of the aggregate public keys, or nil to aggregate
+	// the points for every verification.
+	cache *AggregateCache
+
+	// key is the fingerprint of the authority, or empty to use the
+	// fingerprint of the points.
+	key string
 }
 
 // NewVerifier returns a new verifier that can verify BLS signatures.
@@ -299,7 +367,7 @@ func newVerifier(points []kyber.Point) crypto.Verifier {
 // Verify implements crypto.Verifier. It returns nil if the signature matches
 // the message, or an error otherwise.
 func (v blsVerifier) Verify(msg []byte, sig crypto.Signature) error {
-	aggKey := bls.AggregatePublicKeys(suite, v.points...)
+	aggKey := v.getAggregate()
 
 	err := bls.Verify(suite, aggKey, msg, sig.(Signature).data)
 	if err != nil {
@@ -309,15 +377,48 @@ func (v blsVerifier) Verify(msg []byte, sig crypto.Signature) error {
 	return nil
 }
 
+func (v blsVerifier) getAggregate() kyber.Point {
+	if v.cache == nil {
+		return aggregatePoints(v.points)
+	}
+
+	key := v.key
+	if key == "" {
+		digest, err := fingerprintPoints(v.points)
+		if err != nil {
+			return aggregatePoints(v.points)
+		}
+
+		key = keysPrefix + digest
+	}
+
+	return v.cache.aggregate(key, v.points)
+}
+
+func aggregatePoints(points []kyber.Point) kyber.Point {
+	return bls.AggregatePublicKeys(suite, points...)
+}
+
 // verifierFactory is a factory to create verifiers from an authority or a list
-// of public keys.
+// of public keys. The verifiers share the cache of aggregate public keys if it
+// is set.
 //
 // - implements crypto.VerifierFactory
-type verifierFactory struct{}
+type verifierFactory struct {
+	cache *AggregateCache
+}
+
+// NewVerifierFactory returns a verifier factory that stores the aggregate
+// public keys in the cache, or that aggregates them for every verification if
+// the cache is nil.
+func NewVerifierFactory(cache *AggregateCache) crypto.VerifierFactory {
+	return verifierFactory{cache: cache}
+}
 
 // FromIterator implements crypto.VerifierFactory. It returns a verifier that
 // will verify the signatures collectively signed by all the signers associated
-// with the public keys.
+// with the public keys. The aggregate public key is cached with the
+// fingerprint of the authority when it provides one.
 func (v verifierFactory) FromAuthority(ca crypto.CollectiveAuthority) (crypto.Verifier, error) {
 	if ca == nil {
 		return nil, xerrors.New("authority is nil")
@@ -335,7 +436,11 @@ func (v verifierFactory) FromAuthority(ca crypto.CollectiveAuthority) (crypto.Ve
 		points = append(points, pk.point)
 	}
 
-	return newVerifier(points), nil
+	return blsVerifier{
+		points: points,
+		cache:  v.cache,
+		key:    fingerprintAuthority(ca),
+	}, nil
 }
 
 // FromArray implements crypto.VerifierFactory. It returns a verifier that will
@@ -352,7 +457,25 @@ func (v verifierFactory) FromArray(publicKeys []crypto.PublicKey) (crypto.Verifi
 		points[i] = pk.point
 	}
 
-	return newVerifier(points), nil
+	return blsVerifier{points: points, cache: v.cache}, nil
+}
+
+// fingerprintAuthority returns the key of the authority in the cache, or an
+// empty string if it cannot be fingerprinted.
+func fingerprintAuthority(ca crypto.CollectiveAuthority) string {
+	fp, ok := ca.(serde.Fingerprinter)
+	if !ok {
+		return ""
+	}
+
+	h := sha256.New()
+
+	err := fp.Fingerprint(h)
+	if err != nil {
+		return ""
+	}
+
+	return authorityPrefix + string(h.Sum(nil))
 }
 
 // Signer is the adapter of a private key from the Kyber package for the BN256
@@ -398,9 +521,10 @@ func Generate() crypto.Signer {
 }
 
 // GetVerifierFactory implements crypto.Signer. It returns the verifier factory
-// for BLS signatures.
+// for BLS signatures, which shares the cache of aggregate public keys with the
+// other signers.
 func (s Signer) GetVerifierFactory() crypto.VerifierFactory {
-	return verifierFactory{}
+	return verifierFactory{cache: defaultCache}
 }
 
 // GetPublicKeyFactory implements crypto.Signer. It returns the public key
@@ -435,9 +559,33 @@ func (s Signer) Sign(msg []byte) (crypto.Signature, error) {
 // Aggregate implements crypto.Signer. It aggregates the signatures into a
 // single one that can be verifier with the aggregated public key associated.
 func (s Signer) Aggregate(signatures ...crypto.Signature) (crypto.Signature, error) {
+	return AggregateSignatures(signatures...)
+}
+
+// Rotate implements crypto.RotatableSigner. It replaces the key pair of the
+// signer by a new random one and returns the new public key. The copies of the
+// signer keep the previous key pair.
+func (s *Signer) Rotate() (crypto.PublicKey, error) {
+	kp := key.NewKeyPair(suite)
+
+	s.private = kp.Private
+	s.public = kp.Public
+
+	return s.GetPublicKey(), nil
+}
+
+// AggregateSignatures aggregates the signatures into a single one that can be
+// verified with the aggregated public key associated. It does not require the
+// private key of a signer.
+func AggregateSignatures(signatures ...crypto.Signature) (crypto.Signature, error) {
 	buffers := make([][]byte, len(signatures))
 	for i, sig := range signatures {
-		buffers[i] = sig.(Signature).data
+		blssig, ok := sig.(Signature)
+		if !ok {
+			return nil, xerrors.Errorf("invalid signature type '%T'", sig)
+		}
+
+		buffers[i] = blssig.data
 	}
 
 	agg, err := bls.AggregateSignatures(suite, buffers...)
@@ -448,6 +596,39 @@ func (s Signer) Aggregate(signatures ...crypto.Signature) (crypto.Signature, err
 	return Signature{data: agg}, nil
 }
 
+// BatchVerify verifies an aggregated signature of distinct messages, where the
+// message at a given index is signed by the key at the same index. The
+// signatures can be aggregated with AggregateSignatures, whatever message they
+// sign. It returns nil if the signature is valid, otherwise an error.
+func BatchVerify(keys []crypto.PublicKey, msgs [][]byte, sig crypto.Signature) error {
+	if len(keys) != len(msgs) {
+		return xerrors.Errorf("mismatch keys and messages: %d != %d",
+			len(keys), len(msgs))
+	}
+
+	points := make([]kyber.Point, len(keys))
+	for i, key := range keys {
+		pubkey, ok := key.(PublicKey)
+		if !ok {
+			return xerrors.Errorf("invalid public key type '%T'", key)
+		}
+
+		points[i] = pubkey.point
+	}
+
+	signature, ok := sig.(Signature)
+	if !ok {
+		return xerrors.Errorf("invalid signature type '%T'", sig)
+	}
+
+	err := bls.BatchVerify(suite, points, msgs, signature.data)
+	if err != nil {
+		return xerrors.Errorf("bls verify failed: %v", err)
+	}
+
+	return nil
+}
+
 // MarshalBinary implements encoding.BinaryMarshaler. It returns a binary
 // representation of the signer.
 func (s Signer) MarshalBinary() ([]byte, error) {
@@ -458,3 +639,19 @@ func (s Signer) MarshalBinary() ([]byte, error) {
 
 	return data, nil
 }
+
+// checkCanonical returns an error if the data is not the encoding that the
+// point produces, as the field elements would otherwise have several
+// representations.
+func checkCanonical(point kyber.Point, data []byte) error {
+	buffer, err := point.MarshalBinary()
+	if err != nil {
+		return xerrors.Errorf("couldn't marshal point: %v", err)
+	}
+
+	if !bytes.Equal(buffer, data) {
+		return xerrors.New("encoding is not canonical")
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package bls
 
 import (
+	"math/big"
 	"testing"
 	"testing/quick"
 
@@ -15,7 +16,8 @@ func init() {
 	RegisterPublicKeyFormat(fake.GoodFormat, fake.Format{Msg: PublicKey{}})
 	RegisterPublicKeyFormat(serde.Format("BAD_TYPE"), fake.Format{Msg: fake.Message{}})
 	RegisterPublicKeyFormat(fake.BadFormat, fake.NewBadFormat())
-	RegisterSignatureFormat(fake.GoodFormat, fake.Format{Msg: Signature{}})
+	RegisterSignatureFormat(fake.GoodFormat, fake.Format{Msg: testSignature})
+	RegisterSignatureFormat("NON_CANONICAL", fake.Format{Msg: Signature{data: []byte{1}}})
 	RegisterSignatureFormat(serde.Format("BAD_TYPE"), fake.Format{Msg: fake.Message{}})
 	RegisterSignatureFormat(fake.BadFormat, fake.NewBadFormat())
 }
@@ -31,6 +33,29 @@ func TestPublicKey_New(t *testing.T) {
 
 	_, err = NewPublicKey(nil)
 	require.Error(t, err)
+
+	_, err = NewPublicKey(append(data, 0))
+	require.EqualError(t, err, "encoding is not canonical")
+}
+
+func TestAggregatePublicKeys(t *testing.T) {
+	signers := []crypto.AggregateSigner{NewSigner(), NewSigner()}
+
+	sig1, err := signers[0].Sign([]byte("deadbeef"))
+	require.NoError(t, err)
+
+	sig2, err := signers[1].Sign([]byte("deadbeef"))
+	require.NoError(t, err)
+
+	agg, err := signers[0].Aggregate(sig1, sig2)
+	require.NoError(t, err)
+
+	pubkey, err := AggregatePublicKeys(signers[0].GetPublicKey(), signers[1].GetPublicKey())
+	require.NoError(t, err)
+	require.NoError(t, pubkey.Verify([]byte("deadbeef"), agg))
+
+	_, err = AggregatePublicKeys(fake.PublicKey{})
+	require.EqualError(t, err, "invalid public key type 'fake.PublicKey'")
 }
 
 func TestPublicKey_MarshalBinary(t *testing.T) {
@@ -139,6 +164,34 @@ func TestPublicKeyFactory_FromBytes(t *testing.T) {
 	require.Contains(t, err.Error(), "failed to unmarshal key: ")
 }
 
+func TestSignature_CheckCanonical(t *testing.T) {
+	require.NoError(t, testSignature.CheckCanonical())
+
+	// The field modulus is added to the x coordinate of the signature, which
+	// gives the same point but with a different encoding.
+	modulus, ok := new(big.Int).SetString("650005496956466037327964387423599057"+
+		"42825358107623003571877145026864184071783", 10)
+	require.True(t, ok)
+
+	base, err := suite.G1().Point().Base().MarshalBinary()
+	require.NoError(t, err)
+
+	x := new(big.Int).Add(new(big.Int).SetBytes(base[:32]), modulus)
+
+	data := make([]byte, len(base))
+	copy(data[32-len(x.Bytes()):], x.Bytes())
+	copy(data[32:], base[32:])
+
+	err = NewSignature(data).CheckCanonical()
+	require.EqualError(t, err, "encoding is not canonical")
+
+	err = NewSignature(append(append([]byte{}, base...), 0)).CheckCanonical()
+	require.EqualError(t, err, "encoding is not canonical")
+
+	err = NewSignature(nil).CheckCanonical()
+	require.EqualError(t, err, "couldn't unmarshal point: bn256.G1: not enough data")
+}
+
 func TestSignature_MarshalBinary(t *testing.T) {
 	f := func(data []byte) bool {
 		sig := NewSignature(data)
@@ -191,10 +244,14 @@ func TestSignatureFactory_Deserialize(t *testing.T) {
 
 	msg, err := factory.Deserialize(fake.NewContext(), nil)
 	require.NoError(t, err)
-	require.Equal(t, Signature{}, msg)
+	require.Equal(t, testSignature, msg)
 
 	_, err = factory.Deserialize(fake.NewBadContext(), nil)
 	require.EqualError(t, err, fake.Err("couldn't decode signature"))
+
+	_, err = factory.Deserialize(fake.NewContextWithFormat("NON_CANONICAL"), nil)
+	require.EqualError(t, err,
+		"invalid signature: couldn't unmarshal point: bn256.G1: not enough data")
 }
 
 func TestSignatureFactory_SignatureOf(t *testing.T) {
@@ -202,7 +259,7 @@ func TestSignatureFactory_SignatureOf(t *testing.T) {
 
 	sig, err := factory.SignatureOf(fake.NewContext(), nil)
 	require.NoError(t, err)
-	require.Equal(t, Signature{}, sig)
+	require.Equal(t, testSignature, sig)
 
 	_, err = factory.SignatureOf(fake.NewBadContext(), nil)
 	require.EqualError(t, err, fake.Err("couldn't decode signature"))
@@ -271,6 +328,7 @@ func TestSigner_GetVerifierFactory(t *testing.T) {
 	factory := signer.GetVerifierFactory()
 	require.NotNil(t, factory)
 	require.IsType(t, verifierFactory{}, factory)
+	require.Same(t, GetAggregateCache(), factory.(verifierFactory).cache)
 }
 
 func TestSigner_GetPublicKeyFactory(t *testing.T) {
@@ -308,6 +366,29 @@ func TestSigner_Sign(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestSigner_Rotate(t *testing.T) {
+	signer := NewSigner()
+	previous := signer
+
+	pubkey, err := signer.Rotate()
+	require.NoError(t, err)
+	require.Equal(t, signer.GetPublicKey(), pubkey)
+	require.False(t, pubkey.Equal(previous.GetPublicKey()))
+
+	sig, err := signer.Sign([]byte("deadbeef"))
+	require.NoError(t, err)
+	require.NoError(t, pubkey.Verify([]byte("deadbeef"), sig))
+	require.Error(t, previous.GetPublicKey().Verify([]byte("deadbeef"), sig))
+
+	// The key can be restored after a rotation.
+	data, err := signer.MarshalBinary()
+	require.NoError(t, err)
+
+	restored, err := NewSignerFromBytes(data)
+	require.NoError(t, err)
+	require.True(t, restored.GetPublicKey().Equal(pubkey))
+}
+
 func TestSigner_Aggregate(t *testing.T) {
 	N := 3
 
@@ -336,6 +417,47 @@ func TestSigner_Aggregate(t *testing.T) {
 
 	err := quick.Check(f, nil)
 	require.NoError(t, err)
+
+	_, err = AggregateSignatures(fake.Signature{})
+	require.EqualError(t, err, "invalid signature type 'fake.Signature'")
+}
+
+func TestBatchVerify(t *testing.T) {
+	N := 3
+
+	signatures := make([]crypto.Signature, N)
+	pubkeys := make([]crypto.PublicKey, N)
+	msgs := make([][]byte, N)
+	for i := 0; i < N; i++ {
+		signer := Generate()
+		pubkeys[i] = signer.GetPublicKey()
+		msgs[i] = []byte{byte(i)}
+
+		sig, err := signer.Sign(msgs[i])
+		require.NoError(t, err)
+		signatures[i] = sig
+	}
+
+	agg, err := AggregateSignatures(signatures...)
+	require.NoError(t, err)
+
+	err = BatchVerify(pubkeys, msgs, agg)
+	require.NoError(t, err)
+
+	err = BatchVerify(pubkeys, [][]byte{{1}, {0}, {2}}, agg)
+	require.EqualError(t, err, "bls verify failed: bls: invalid signature")
+
+	err = BatchVerify(pubkeys, [][]byte{{0}, {0}, {2}}, agg)
+	require.EqualError(t, err, "bls verify failed: bls: error, messages must be distinct")
+
+	err = BatchVerify(pubkeys, msgs[:2], agg)
+	require.EqualError(t, err, "mismatch keys and messages: 3 != 2")
+
+	err = BatchVerify([]crypto.PublicKey{fake.PublicKey{}}, msgs[:1], agg)
+	require.EqualError(t, err, "invalid public key type 'fake.PublicKey'")
+
+	err = BatchVerify(pubkeys, msgs, fake.Signature{})
+	require.EqualError(t, err, "invalid signature type 'fake.Signature'")
 }
 
 func TestSigner_MarshalBinary(t *testing.T) {
@@ -377,3 +499,14 @@ type badScalar struct {
 func (s badScalar) MarshalBinary() ([]byte, error) {
 	return nil, fake.GetError()
 }
+
+var testSignature = makeSignature()
+
+func makeSignature() Signature {
+	sig, err := NewSigner().Sign([]byte("deadbeef"))
+	if err != nil {
+		panic(err)
+	}
+
+	return sig.(Signature)
+}
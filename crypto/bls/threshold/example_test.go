@@ -0,0 +1,38 @@
+package threshold
+
+import (
+	"fmt"
+)
+
+func ExampleSigner_Aggregate() {
+	signers, err := Deal(2, 3)
+	if err != nil {
+		panic("deal failed: " + err.Error())
+	}
+
+	message := []byte("42")
+
+	signatureA, err := signers[0].Sign(message)
+	if err != nil {
+		panic("signer A failed: " + err.Error())
+	}
+
+	signatureC, err := signers[2].Sign(message)
+	if err != nil {
+		panic("signer C failed: " + err.Error())
+	}
+
+	aggregate, err := signers[0].Aggregate(signatureA, signatureC)
+	if err != nil {
+		panic("aggregate failed: " + err.Error())
+	}
+
+	err = signers[1].GetGroupKey().Verify(message, aggregate)
+	if err != nil {
+		panic("invalid signature: " + err.Error())
+	}
+
+	fmt.Println("Success")
+
+	// Output: Success
+}
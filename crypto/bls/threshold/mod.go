@@ -0,0 +1,772 @@
+// Package threshold implements a t-of-n threshold variant of the BLS signature
+// scheme on the BN256 elliptic curve.
+//
+// The private key of a group is shared among n participants so that any t of
+// them can produce a signature of the group. Each participant signs with its
+// share and the partial signatures are combined with a Lagrange interpolation
+// into a regular BLS signature, which is verified with the public key of the
+// group as if it was produced by a single signer.
+//
+// The signer implements crypto.AggregateSigner so that it can be used by the
+// collective signing. The public key of a participant is its public share, so
+// that each response is verified individually, and the aggregate becomes the
+// signature of the group as soon as it includes a threshold of partial
+// signatures.
+//
+// Related Papers:
+//
+// Threshold Signatures, Multisignatures and Blind Signatures Based on the
+// Gap-Diffie-Hellman-Group Signature Scheme (2003)
+//
+package threshold
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing"
+	"go.dedis.ch/kyber/v3/share"
+
+	//lint:ignore SA1019 we need to fix this, issues opened in #166
+	kbls "go.dedis.ch/kyber/v3/sign/bls"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// Algorithm is the name of the threshold signature scheme.
+	Algorithm = "BLS-THRESHOLD-BN256"
+
+	// indexSize is the size of the index of a share in the encodings.
+	indexSize = 2
+
+	// scalarSize is the size of the private share of a signer.
+	scalarSize = 32
+)
+
+var (
+	suite = pairing.NewSuiteBn256()
+
+	pointSize = suite.G1().PointLen()
+	entrySize = indexSize + pointSize
+
+	pubkeyFormats = registry.NewSimpleRegistry()
+	sigFormats    = registry.NewSimpleRegistry()
+)
+
+// RegisterPublicKeyFormat registers the engine for the provided format.
+func RegisterPublicKeyFormat(c serde.Format, f serde.FormatEngine) {
+	pubkeyFormats.Register(c, f)
+}
+
+// RegisterSignatureFormat registers the engine for the provided format.
+func RegisterSignatureFormat(c serde.Format, f serde.FormatEngine) {
+	sigFormats.Register(c, f)
+}
+
+// PublicShare is the public key of the share of a participant, which verifies
+// its partial signatures.
+//
+// - implements crypto.PublicKey
+type PublicShare struct {
+	index int
+	key   bls.PublicKey
+}
+
+// NewPublicShare creates a public share from the index of the share followed
+// by the canonical encoding of the BLS public key.
+func NewPublicShare(data []byte) (PublicShare, error) {
+	if len(data) < indexSize {
+		return PublicShare{}, xerrors.Errorf("data too short: %d < %d", len(data), indexSize)
+	}
+
+	key, err := bls.NewPublicKey(data[indexSize:])
+	if err != nil {
+		return PublicShare{}, xerrors.Errorf("invalid public key: %v", err)
+	}
+
+	pubshare := PublicShare{
+		index: int(binary.BigEndian.Uint16(data)),
+		key:   key,
+	}
+
+	return pubshare, nil
+}
+
+// GetIndex returns the index of the share.
+func (pk PublicShare) GetIndex() int {
+	return pk.index
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns the index of
+// the share followed by the public key.
+func (pk PublicShare) MarshalBinary() ([]byte, error) {
+	data, err := pk.key.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal public key: %v", err)
+	}
+
+	buffer := make([]byte, indexSize, indexSize+len(data))
+	binary.BigEndian.PutUint16(buffer, uint16(pk.index))
+
+	return append(buffer, data...), nil
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// public share.
+func (pk PublicShare) Serialize(ctx serde.Context) ([]byte, error) {
+	format := pubkeyFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, pk)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode public key: %v", err)
+	}
+
+	return data, nil
+}
+
+// Verify implements crypto.PublicKey. It returns nil if the signature is the
+// partial signature of the share for the message, otherwise an error.
+func (pk PublicShare) Verify(msg []byte, sig crypto.Signature) error {
+	signature, ok := sig.(Signature)
+	if !ok {
+		return xerrors.Errorf("invalid signature type '%T'", sig)
+	}
+
+	if len(signature.entries) != 1 {
+		return xerrors.Errorf("expect one partial signature but got %d", len(signature.entries))
+	}
+
+	entry := signature.entries[0]
+
+	if entry.index != pk.index {
+		return xerrors.Errorf("mismatch index %d != %d", entry.index, pk.index)
+	}
+
+	err := pk.key.Verify(msg, bls.NewSignature(entry.data))
+	if err != nil {
+		return xerrors.Errorf("invalid partial signature: %v", err)
+	}
+
+	return nil
+}
+
+// Equal implements crypto.PublicKey. It returns true if the other public key
+// is the same share.
+func (pk PublicShare) Equal(other interface{}) bool {
+	pubshare, ok := other.(PublicShare)
+	if !ok {
+		return false
+	}
+
+	return pubshare.index == pk.index && pubshare.key.Equal(pk.key)
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns a text
+// representation of the public share.
+func (pk PublicShare) MarshalText() ([]byte, error) {
+	buffer, err := pk.key.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return []byte(fmt.Sprintf("tbls:%d:%x", pk.index, buffer)), nil
+}
+
+// String implements fmt.Stringer. It returns a string representation of the
+// public share.
+func (pk PublicShare) String() string {
+	return fmt.Sprintf("tbls:%d:%v", pk.index, pk.key)
+}
+
+// entry is the partial signature of the share at the index.
+type entry struct {
+	index int
+	data  []byte
+}
+
+// Signature is a set of partial signatures of distinct shares, sorted by
+// index. A signer produces a set of a single partial signature, and the sets
+// are merged until they reach the threshold and are combined into a BLS
+// signature.
+//
+// - implements crypto.Signature
+type Signature struct {
+	entries []entry
+}
+
+// NewSignature creates a set of partial signatures from the data, which is the
+// concatenation of the index of each share followed by its signature.
+func NewSignature(data []byte) (Signature, error) {
+	if len(data) == 0 || len(data)%entrySize != 0 {
+		return Signature{}, xerrors.Errorf("invalid data size %d", len(data))
+	}
+
+	entries := make([]entry, 0, len(data)/entrySize)
+
+	for len(data) > 0 {
+		e := entry{
+			index: int(binary.BigEndian.Uint16(data)),
+			data:  append([]byte{}, data[indexSize:entrySize]...),
+		}
+
+		err := bls.NewSignature(e.data).CheckCanonical()
+		if err != nil {
+			return Signature{}, xerrors.Errorf("invalid partial signature: %v", err)
+		}
+
+		entries = append(entries, e)
+		data = data[entrySize:]
+	}
+
+	sig, err := mergeEntries(entries)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	if len(sig.entries) != len(entries) {
+		return Signature{}, xerrors.New("duplicate partial signature")
+	}
+
+	return sig, nil
+}
+
+// Len returns the number of partial signatures.
+func (sig Signature) Len() int {
+	return len(sig.entries)
+}
+
+// GetIndices returns the indices of the shares that produced the partial
+// signatures.
+func (sig Signature) GetIndices() []int {
+	indices := make([]int, len(sig.entries))
+	for i, e := range sig.entries {
+		indices[i] = e.index
+	}
+
+	return indices
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns the
+// concatenation of the index of each share followed by its signature.
+func (sig Signature) MarshalBinary() ([]byte, error) {
+	buffer := make([]byte, 0, len(sig.entries)*entrySize)
+
+	for _, e := range sig.entries {
+		index := make([]byte, indexSize)
+		binary.BigEndian.PutUint16(index, uint16(e.index))
+
+		buffer = append(buffer, index...)
+		buffer = append(buffer, e.data...)
+	}
+
+	return buffer, nil
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// signature.
+func (sig Signature) Serialize(ctx serde.Context) ([]byte, error) {
+	format := sigFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, sig)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode signature: %v", err)
+	}
+
+	return data, nil
+}
+
+// Equal implements crypto.Signature. It returns true if both sets have the
+// same partial signatures.
+func (sig Signature) Equal(other crypto.Signature) bool {
+	otherSig, ok := other.(Signature)
+	if !ok || len(otherSig.entries) != len(sig.entries) {
+		return false
+	}
+
+	for i, e := range sig.entries {
+		if e.index != otherSig.entries[i].index || !bytes.Equal(e.data, otherSig.entries[i].data) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String implements fmt.Stringer. It returns a string representation of the
+// signature.
+func (sig Signature) String() string {
+	return fmt.Sprintf("tbls:%v", sig.GetIndices())
+}
+
+// Combine returns the BLS signature of the group recovered from the partial
+// signatures of the set with a Lagrange interpolation. It returns an error if
+// the set has fewer than t partial signatures.
+func Combine(t int, sig Signature) (bls.Signature, error) {
+	if len(sig.entries) < t {
+		return bls.Signature{}, xerrors.Errorf("not enough partial signatures: %d < %d",
+			len(sig.entries), t)
+	}
+
+	// Any t partial signatures are enough to recover the signature.
+	entries := sig.entries[:t]
+
+	indices := make([]int, len(entries))
+	for i, e := range entries {
+		indices[i] = e.index
+	}
+
+	combined := suite.G1().Point().Null()
+
+	for i, e := range entries {
+		point := suite.G1().Point()
+		err := point.UnmarshalBinary(e.data)
+		if err != nil {
+			return bls.Signature{}, xerrors.Errorf("couldn't unmarshal partial signature: %v", err)
+		}
+
+		point = point.Mul(lagrangeBasis(indices, i), point)
+		combined = combined.Add(combined, point)
+	}
+
+	data, err := combined.MarshalBinary()
+	if err != nil {
+		return bls.Signature{}, xerrors.Errorf("couldn't marshal signature: %v", err)
+	}
+
+	return bls.NewSignature(data), nil
+}
+
+// lagrangeBasis returns the Lagrange basis polynomial of the index at the
+// position evaluated at zero, which is the product of x_j / (x_j - x_i) for
+// every other index. A share of index i is the evaluation of the polynomial of
+// the private key at x_i = i + 1, as the private key itself is at zero.
+func lagrangeBasis(indices []int, pos int) kyber.Scalar {
+	xi := suite.G2().Scalar().SetInt64(int64(indices[pos] + 1))
+
+	num := suite.G2().Scalar().One()
+	den := suite.G2().Scalar().One()
+
+	for k, index := range indices {
+		if k == pos {
+			continue
+		}
+
+		xj := suite.G2().Scalar().SetInt64(int64(index + 1))
+
+		num = num.Mul(num, xj)
+		den = den.Mul(den, suite.G2().Scalar().Sub(xj, xi))
+	}
+
+	return num.Div(num, den)
+}
+
+// mergeEntries returns the set of the partial signatures sorted by index. A
+// partial signature of an index already in the set must be identical to be
+// merged.
+func mergeEntries(entries []entry) (Signature, error) {
+	byIndex := make(map[int]entry)
+
+	for _, e := range entries {
+		prev, found := byIndex[e.index]
+		if found && !bytes.Equal(prev.data, e.data) {
+			return Signature{}, xerrors.Errorf("conflicting partial signatures for index %d", e.index)
+		}
+
+		byIndex[e.index] = e
+	}
+
+	sig := Signature{entries: make([]entry, 0, len(byIndex))}
+	for _, e := range byIndex {
+		sig.entries = append(sig.entries, e)
+	}
+
+	sort.Slice(sig.entries, func(i, j int) bool {
+		return sig.entries[i].index < sig.entries[j].index
+	})
+
+	return sig, nil
+}
+
+// publicKeyFactory is a factory to deserialize public shares.
+//
+// - implements crypto.PublicKeyFactory
+type publicKeyFactory struct{}
+
+// NewPublicKeyFactory returns a new instance of the factory.
+func NewPublicKeyFactory() crypto.PublicKeyFactory {
+	return publicKeyFactory{}
+}
+
+// Deserialize implements serde.Factory. It returns the public share of the data
+// if appropriate, otherwise an error.
+func (f publicKeyFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	return f.PublicKeyOf(ctx, data)
+}
+
+// PublicKeyOf implements crypto.PublicKeyFactory. It returns the public share
+// of the data if appropriate, otherwise an error.
+func (f publicKeyFactory) PublicKeyOf(ctx serde.Context, data []byte) (crypto.PublicKey, error) {
+	format := pubkeyFormats.Get(ctx.GetFormat())
+
+	m, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't decode public key: %v", err)
+	}
+
+	pubkey, ok := m.(PublicShare)
+	if !ok {
+		return nil, xerrors.Errorf("invalid public key of type '%T'", m)
+	}
+
+	return pubkey, nil
+}
+
+// FromBytes implements crypto.PublicKeyFactory. It returns the public share
+// unmarshaled from the bytes.
+func (f publicKeyFactory) FromBytes(data []byte) (crypto.PublicKey, error) {
+	pubkey, err := NewPublicShare(data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal key: %v", err)
+	}
+
+	return pubkey, nil
+}
+
+// signatureFactory is a factory to deserialize the sets of partial signatures,
+// and the BLS signatures they are combined into.
+//
+// - implements crypto.SignatureFactory
+type signatureFactory struct{}
+
+// NewSignatureFactory returns a new instance of the factory.
+func NewSignatureFactory() crypto.SignatureFactory {
+	return signatureFactory{}
+}
+
+// Deserialize implements serde.Factory. It returns the signature of the data if
+// appropriate, otherwise an error.
+func (f signatureFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	return f.SignatureOf(ctx, data)
+}
+
+// SignatureOf implements crypto.SignatureFactory. It returns either a set of
+// partial signatures or a combined BLS signature from the data if appropriate,
+// otherwise an error.
+func (f signatureFactory) SignatureOf(ctx serde.Context, data []byte) (crypto.Signature, error) {
+	format := sigFormats.Get(ctx.GetFormat())
+
+	m, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't decode signature: %v", err)
+	}
+
+	switch sig := m.(type) {
+	case Signature:
+		return sig, nil
+	case bls.Signature:
+		err = sig.CheckCanonical()
+		if err != nil {
+			return nil, xerrors.Errorf("invalid signature: %v", err)
+		}
+
+		return sig, nil
+	default:
+		return nil, xerrors.Errorf("invalid signature of type '%T'", m)
+	}
+}
+
+// groupVerifier is a verifier of the signatures of a group.
+//
+// - implements crypto.Verifier
+type groupVerifier struct {
+	group     bls.PublicKey
+	threshold int
+}
+
+// Verify implements crypto.Verifier. It returns nil if the signature is a
+// signature of the group for the message, otherwise an error. A set of partial
+// signatures is combined first.
+func (v groupVerifier) Verify(msg []byte, s crypto.Signature) error {
+	var sig bls.Signature
+
+	switch signature := s.(type) {
+	case bls.Signature:
+		sig = signature
+	case Signature:
+		var err error
+		sig, err = Combine(v.threshold, signature)
+		if err != nil {
+			return xerrors.Errorf("couldn't combine: %v", err)
+		}
+	default:
+		return xerrors.Errorf("invalid signature type '%T'", s)
+	}
+
+	err := v.group.Verify(msg, sig)
+	if err != nil {
+		return xerrors.Errorf("invalid group signature: %v", err)
+	}
+
+	return nil
+}
+
+// verifierFactory is a factory to create the verifiers of the signatures of a
+// group.
+//
+// - implements crypto.VerifierFactory
+type verifierFactory struct {
+	group     bls.PublicKey
+	threshold int
+}
+
+// NewVerifierFactory returns a factory of verifiers of the signatures of the
+// group with the public key, which need a threshold of t partial signatures.
+func NewVerifierFactory(group bls.PublicKey, t int) crypto.VerifierFactory {
+	return verifierFactory{
+		group:     group,
+		threshold: t,
+	}
+}
+
+// FromAuthority implements crypto.VerifierFactory. It returns a verifier of the
+// signatures of the group, whose participants are the members of the
+// authority.
+func (f verifierFactory) FromAuthority(ca crypto.CollectiveAuthority) (crypto.Verifier, error) {
+	if ca == nil {
+		return nil, xerrors.New("authority is nil")
+	}
+
+	pubkeys := make([]crypto.PublicKey, 0, ca.Len())
+
+	iter := ca.PublicKeyIterator()
+	for iter.HasNext() {
+		pubkeys = append(pubkeys, iter.GetNext())
+	}
+
+	return f.FromArray(pubkeys)
+}
+
+// FromArray implements crypto.VerifierFactory. It returns a verifier of the
+// signatures of the group, whose participants have the public shares. The
+// signature of the group does not depend on which shares produced it.
+func (f verifierFactory) FromArray(pubkeys []crypto.PublicKey) (crypto.Verifier, error) {
+	for _, pubkey := range pubkeys {
+		_, ok := pubkey.(PublicShare)
+		if !ok {
+			return nil, xerrors.Errorf("invalid public key type: %T", pubkey)
+		}
+	}
+
+	verifier := groupVerifier{
+		group:     f.group,
+		threshold: f.threshold,
+	}
+
+	return verifier, nil
+}
+
+// Signer is a participant of a group that signs with its share of the private
+// key of the group.
+//
+// - implements crypto.AggregateSigner
+// - implements encoding.BinaryMarshaler
+type Signer struct {
+	private   *share.PriShare
+	public    *share.PubPoly
+	threshold int
+}
+
+// NewSigner creates a signer from its private share and the public polynomial
+// of the group, for which t partial signatures are combined into a signature.
+// The shares are usually distributed by a distributed key generation.
+func NewSigner(private *share.PriShare, public *share.PubPoly, t int) Signer {
+	return Signer{
+		private:   private,
+		public:    public,
+		threshold: t,
+	}
+}
+
+// Deal creates a new private key for a group of n participants and returns the
+// signers of the shares, of which t are necessary to sign. The caller is
+// trusted to distribute the shares and forget the others.
+func Deal(t, n int) ([]Signer, error) {
+	if t < 1 || t > n {
+		return nil, xerrors.Errorf("invalid threshold %d for %d participants", t, n)
+	}
+
+	private := share.NewPriPoly(suite.G2(), t, nil, suite.RandomStream())
+	public := private.Commit(suite.G2().Point().Base())
+
+	signers := make([]Signer, n)
+	for i, priShare := range private.Shares(n) {
+		signers[i] = NewSigner(priShare, public, t)
+	}
+
+	return signers, nil
+}
+
+// NewSignerFromBytes restores a signer from a marshalling.
+func NewSignerFromBytes(data []byte) (crypto.AggregateSigner, error) {
+	pointLen := suite.G2().PointLen()
+
+	if len(data) < 2*indexSize+scalarSize {
+		return nil, xerrors.Errorf("data too short: %d", len(data))
+	}
+
+	t := int(binary.BigEndian.Uint16(data))
+	index := int(binary.BigEndian.Uint16(data[indexSize:]))
+	data = data[2*indexSize:]
+
+	if len(data) != scalarSize+t*pointLen {
+		return nil, xerrors.Errorf("invalid data size %d for threshold %d",
+			len(data)+2*indexSize, t)
+	}
+
+	scalar := suite.G2().Scalar()
+	err := scalar.UnmarshalBinary(data[:scalarSize])
+	if err != nil {
+		return nil, xerrors.Errorf("while unmarshaling scalar: %v", err)
+	}
+
+	data = data[scalarSize:]
+
+	commits := make([]kyber.Point, t)
+	for i := range commits {
+		commits[i] = suite.G2().Point()
+
+		err = commits[i].UnmarshalBinary(data[i*pointLen : (i+1)*pointLen])
+		if err != nil {
+			return nil, xerrors.Errorf("while unmarshaling commit: %v", err)
+		}
+	}
+
+	private := &share.PriShare{I: index, V: scalar}
+	public := share.NewPubPoly(suite.G2(), suite.G2().Point().Base(), commits)
+
+	return NewSigner(private, public, t), nil
+}
+
+// GetThreshold returns the number of partial signatures combined into a
+// signature of the group.
+func (s Signer) GetThreshold() int {
+	return s.threshold
+}
+
+// GetGroupKey returns the public key of the group which verifies the combined
+// signatures.
+func (s Signer) GetGroupKey() bls.PublicKey {
+	return bls.NewPublicKeyFromPoint(s.public.Commit())
+}
+
+// GetPublicKeyFactory implements crypto.Signer. It returns the public key
+// factory of the public shares.
+func (s Signer) GetPublicKeyFactory() crypto.PublicKeyFactory {
+	return publicKeyFactory{}
+}
+
+// GetSignatureFactory implements crypto.Signer. It returns the signature
+// factory of the partial and the combined signatures.
+func (s Signer) GetSignatureFactory() crypto.SignatureFactory {
+	return signatureFactory{}
+}
+
+// GetVerifierFactory implements crypto.AggregateSigner. It returns the verifier
+// factory of the signatures of the group.
+func (s Signer) GetVerifierFactory() crypto.VerifierFactory {
+	return NewVerifierFactory(s.GetGroupKey(), s.threshold)
+}
+
+// GetPublicKey implements crypto.Signer. It returns the public share of the
+// signer that verifies its partial signatures.
+func (s Signer) GetPublicKey() crypto.PublicKey {
+	return PublicShare{
+		index: s.private.I,
+		key:   bls.NewPublicKeyFromPoint(s.public.Eval(s.private.I).V),
+	}
+}
+
+// Sign implements crypto.Signer. It returns the partial signature of the
+// message by the share of the signer.
+func (s Signer) Sign(msg []byte) (crypto.Signature, error) {
+	data, err := kbls.Sign(suite, s.private.V, msg)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't make bls signature: %v", err)
+	}
+
+	sig := Signature{
+		entries: []entry{{index: s.private.I, data: data}},
+	}
+
+	return sig, nil
+}
+
+// Aggregate implements crypto.AggregateSigner. It merges the sets of partial
+// signatures, and combines them into the BLS signature of the group once they
+// reach the threshold. A combined signature is returned as is as it is
+// already complete.
+func (s Signer) Aggregate(signatures ...crypto.Signature) (crypto.Signature, error) {
+	entries := make([]entry, 0, len(signatures))
+
+	for _, sig := range signatures {
+		switch signature := sig.(type) {
+		case bls.Signature:
+			return signature, nil
+		case Signature:
+			entries = append(entries, signature.entries...)
+		default:
+			return nil, xerrors.Errorf("invalid signature type '%T'", sig)
+		}
+	}
+
+	merged, err := mergeEntries(entries)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't merge: %v", err)
+	}
+
+	if merged.Len() < s.threshold {
+		return merged, nil
+	}
+
+	combined, err := Combine(s.threshold, merged)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't combine: %v", err)
+	}
+
+	return combined, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns the threshold,
+// the index and the private share of the signer, followed by the commits of
+// the public polynomial of the group.
+func (s Signer) MarshalBinary() ([]byte, error) {
+	buffer := make([]byte, 2*indexSize)
+	binary.BigEndian.PutUint16(buffer, uint16(s.threshold))
+	binary.BigEndian.PutUint16(buffer[indexSize:], uint16(s.private.I))
+
+	data, err := s.private.V.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("while marshaling scalar: %v", err)
+	}
+
+	buffer = append(buffer, data...)
+
+	_, commits := s.public.Info()
+	for _, commit := range commits {
+		data, err = commit.MarshalBinary()
+		if err != nil {
+			return nil, xerrors.Errorf("while marshaling commit: %v", err)
+		}
+
+		buffer = append(buffer, data...)
+	}
+
+	return buffer, nil
+}
@@ -0,0 +1,409 @@
+package threshold
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/serde"
+)
+
+func init() {
+	RegisterPublicKeyFormat(fake.GoodFormat, fake.Format{Msg: PublicShare{}})
+	RegisterPublicKeyFormat(serde.Format("BAD_TYPE"), fake.Format{Msg: fake.Message{}})
+	RegisterPublicKeyFormat(fake.BadFormat, fake.NewBadFormat())
+	RegisterSignatureFormat(fake.GoodFormat, fake.Format{Msg: Signature{}})
+	RegisterSignatureFormat(serde.Format("BLS"), fake.Format{Msg: bls.NewSignature([]byte{1})})
+	RegisterSignatureFormat(serde.Format("BAD_TYPE"), fake.Format{Msg: fake.Message{}})
+	RegisterSignatureFormat(fake.BadFormat, fake.NewBadFormat())
+}
+
+func TestPublicShare_New(t *testing.T) {
+	signers := mustDeal(t, 2, 3)
+
+	data, err := signers[1].GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	pubkey, err := NewPublicShare(data)
+	require.NoError(t, err)
+	require.True(t, signers[1].GetPublicKey().Equal(pubkey))
+	require.Equal(t, 1, pubkey.GetIndex())
+
+	_, err = NewPublicShare([]byte{0})
+	require.EqualError(t, err, "data too short: 1 < 2")
+
+	_, err = NewPublicShare([]byte{0, 0, 1})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid public key: ")
+}
+
+func TestPublicShare_Serialize(t *testing.T) {
+	pubkey := PublicShare{}
+
+	data, err := pubkey.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = pubkey.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode public key"))
+}
+
+func TestPublicShare_Verify(t *testing.T) {
+	signers := mustDeal(t, 2, 3)
+
+	sig, err := signers[0].Sign([]byte("deadbeef"))
+	require.NoError(t, err)
+
+	pubkey := signers[0].GetPublicKey()
+
+	err = pubkey.Verify([]byte("deadbeef"), sig)
+	require.NoError(t, err)
+
+	err = pubkey.Verify([]byte("deadbeef"), fake.Signature{})
+	require.EqualError(t, err, "invalid signature type 'fake.Signature'")
+
+	err = pubkey.Verify([]byte("deadbeef"), Signature{})
+	require.EqualError(t, err, "expect one partial signature but got 0")
+
+	err = signers[1].GetPublicKey().Verify([]byte("deadbeef"), sig)
+	require.EqualError(t, err, "mismatch index 0 != 1")
+
+	err = pubkey.Verify([]byte("abc"), sig)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid partial signature: ")
+}
+
+func TestPublicShare_Equal(t *testing.T) {
+	signers := mustDeal(t, 2, 3)
+
+	pubkey := signers[0].GetPublicKey()
+	require.True(t, pubkey.Equal(pubkey))
+	require.False(t, pubkey.Equal(signers[1].GetPublicKey()))
+	require.False(t, pubkey.Equal(fake.PublicKey{}))
+}
+
+func TestPublicShare_MarshalText(t *testing.T) {
+	signers := mustDeal(t, 2, 3)
+
+	text, err := signers[2].GetPublicKey().MarshalText()
+	require.NoError(t, err)
+	require.Regexp(t, "^tbls:2:[0-9a-f]+$", string(text))
+}
+
+func TestPublicShare_String(t *testing.T) {
+	signers := mustDeal(t, 2, 3)
+
+	str := signers[1].GetPublicKey().(PublicShare).String()
+	require.Contains(t, str, "tbls:1:")
+}
+
+func TestSignature_New(t *testing.T) {
+	signers := mustDeal(t, 2, 3)
+
+	agg := aggregatePartials(t, signers[2], signers[0])
+
+	data, err := agg.MarshalBinary()
+	require.NoError(t, err)
+
+	sig, err := NewSignature(data)
+	require.NoError(t, err)
+	require.True(t, agg.Equal(sig))
+	require.Equal(t, []int{0, 2}, sig.GetIndices())
+
+	_, err = NewSignature(nil)
+	require.EqualError(t, err, "invalid data size 0")
+
+	_, err = NewSignature(data[:entrySize+1])
+	require.EqualError(t, err, "invalid data size 67")
+
+	_, err = NewSignature(append(append([]byte{}, data[:entrySize]...), data[:entrySize]...))
+	require.EqualError(t, err, "duplicate partial signature")
+
+	bad := append([]byte{}, data[:entrySize]...)
+	bad[indexSize] = 0xff
+	_, err = NewSignature(bad)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid partial signature: ")
+}
+
+func TestSignature_Serialize(t *testing.T) {
+	sig := Signature{}
+
+	data, err := sig.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = sig.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode signature"))
+}
+
+func TestSignature_Equal(t *testing.T) {
+	signers := mustDeal(t, 3, 3)
+
+	sig := aggregatePartials(t, signers[0], signers[1])
+	require.True(t, sig.Equal(sig))
+	require.False(t, sig.Equal(aggregatePartials(t, signers[0], signers[2])))
+	require.False(t, sig.Equal(aggregatePartials(t, signers[0])))
+	require.False(t, sig.Equal(fake.Signature{}))
+}
+
+func TestSignature_String(t *testing.T) {
+	signers := mustDeal(t, 3, 3)
+
+	sig := aggregatePartials(t, signers[2], signers[0])
+	require.Equal(t, "tbls:[0 2]", sig.String())
+}
+
+func TestCombine(t *testing.T) {
+	signers := mustDeal(t, 3, 5)
+	group := signers[0].GetGroupKey()
+
+	// Any subset of at least t partial signatures recovers the same signature.
+	subsets := [][]Signer{
+		{signers[0], signers[1], signers[2]},
+		{signers[4], signers[2], signers[1]},
+		{signers[0], signers[1], signers[3], signers[4]},
+	}
+
+	var prev bls.Signature
+	for _, subset := range subsets {
+		sig, err := Combine(3, aggregatePartials(t, subset...))
+		require.NoError(t, err)
+		require.NoError(t, group.Verify([]byte("deadbeef"), sig))
+
+		if prev.Equal(bls.Signature{}) {
+			prev = sig
+		}
+
+		require.True(t, prev.Equal(sig))
+	}
+
+	_, err := Combine(3, aggregatePartials(t, signers[0], signers[1]))
+	require.EqualError(t, err, "not enough partial signatures: 2 < 3")
+
+	_, err = Combine(1, Signature{entries: []entry{{data: []byte{1}}}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "couldn't unmarshal partial signature: ")
+}
+
+func TestPublicKeyFactory_Deserialize(t *testing.T) {
+	factory := NewPublicKeyFactory()
+
+	msg, err := factory.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.Equal(t, PublicShare{}, msg)
+
+	_, err = factory.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("couldn't decode public key"))
+
+	_, err = factory.Deserialize(fake.NewContextWithFormat(serde.Format("BAD_TYPE")), nil)
+	require.EqualError(t, err, "invalid public key of type 'fake.Message'")
+}
+
+func TestPublicKeyFactory_FromBytes(t *testing.T) {
+	signers := mustDeal(t, 2, 3)
+	factory := NewPublicKeyFactory()
+
+	data, err := signers[0].GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	pubkey, err := factory.FromBytes(data)
+	require.NoError(t, err)
+	require.True(t, signers[0].GetPublicKey().Equal(pubkey))
+
+	_, err = factory.FromBytes(nil)
+	require.EqualError(t, err, "failed to unmarshal key: data too short: 0 < 2")
+}
+
+func TestSignatureFactory_Deserialize(t *testing.T) {
+	factory := NewSignatureFactory()
+
+	msg, err := factory.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.Equal(t, Signature{}, msg)
+
+	_, err = factory.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("couldn't decode signature"))
+
+	_, err = factory.Deserialize(fake.NewContextWithFormat(serde.Format("BLS")), nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid signature: ")
+
+	_, err = factory.Deserialize(fake.NewContextWithFormat(serde.Format("BAD_TYPE")), nil)
+	require.EqualError(t, err, "invalid signature of type 'fake.Message'")
+}
+
+func TestVerifierFactory_FromAuthority(t *testing.T) {
+	signers := mustDeal(t, 2, 3)
+	factory := signers[0].GetVerifierFactory()
+
+	verifier, err := factory.FromAuthority(fake.NewAuthority(3, func() crypto.Signer {
+		return signers[0]
+	}))
+	require.NoError(t, err)
+	require.NotNil(t, verifier)
+
+	_, err = factory.FromAuthority(nil)
+	require.EqualError(t, err, "authority is nil")
+
+	_, err = factory.FromAuthority(fake.NewAuthority(1, fake.NewSigner))
+	require.EqualError(t, err, "invalid public key type: fake.PublicKey")
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	signers := mustDeal(t, 2, 3)
+
+	verifier, err := signers[0].GetVerifierFactory().FromArray(nil)
+	require.NoError(t, err)
+
+	partials := aggregatePartials(t, signers[0], signers[2])
+
+	err = verifier.Verify([]byte("deadbeef"), partials)
+	require.NoError(t, err)
+
+	combined, err := Combine(2, partials)
+	require.NoError(t, err)
+
+	err = verifier.Verify([]byte("deadbeef"), combined)
+	require.NoError(t, err)
+
+	err = verifier.Verify([]byte("deadbeef"), aggregatePartials(t, signers[1]))
+	require.EqualError(t, err, "couldn't combine: not enough partial signatures: 1 < 2")
+
+	err = verifier.Verify([]byte("deadbeef"), fake.Signature{})
+	require.EqualError(t, err, "invalid signature type 'fake.Signature'")
+
+	err = verifier.Verify([]byte("abc"), combined)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid group signature: ")
+}
+
+func TestSigner_Deal(t *testing.T) {
+	signers, err := Deal(3, 5)
+	require.NoError(t, err)
+	require.Len(t, signers, 5)
+
+	for i, signer := range signers {
+		require.Equal(t, 3, signer.GetThreshold())
+		require.Equal(t, i, signer.GetPublicKey().(PublicShare).GetIndex())
+		require.True(t, signers[0].GetGroupKey().Equal(signer.GetGroupKey()))
+	}
+
+	_, err = Deal(0, 5)
+	require.EqualError(t, err, "invalid threshold 0 for 5 participants")
+
+	_, err = Deal(6, 5)
+	require.EqualError(t, err, "invalid threshold 6 for 5 participants")
+}
+
+func TestSigner_MarshalBinary(t *testing.T) {
+	signers := mustDeal(t, 3, 5)
+
+	data, err := signers[3].MarshalBinary()
+	require.NoError(t, err)
+
+	signer, err := NewSignerFromBytes(data)
+	require.NoError(t, err)
+	require.True(t, signers[3].GetPublicKey().Equal(signer.GetPublicKey()))
+	require.True(t, signers[3].GetGroupKey().Equal(signer.(Signer).GetGroupKey()))
+
+	sig, err := signer.Sign([]byte("deadbeef"))
+	require.NoError(t, err)
+	require.NoError(t, signers[3].GetPublicKey().Verify([]byte("deadbeef"), sig))
+
+	_, err = NewSignerFromBytes(nil)
+	require.EqualError(t, err, "data too short: 0")
+
+	_, err = NewSignerFromBytes(data[:len(data)-1])
+	require.EqualError(t, err, "invalid data size 419 for threshold 3")
+
+	bad := append([]byte{}, data...)
+	copy(bad[2*indexSize:], []byte{0xff, 0xff, 0xff, 0xff})
+	_, err = NewSignerFromBytes(bad)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "while unmarshaling scalar: ")
+
+	bad = append([]byte{}, data...)
+	bad[2*indexSize+scalarSize] = 0xff
+	_, err = NewSignerFromBytes(bad)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "while unmarshaling commit: ")
+}
+
+func TestSigner_Factories(t *testing.T) {
+	signers := mustDeal(t, 1, 1)
+
+	require.NotNil(t, signers[0].GetPublicKeyFactory())
+	require.NotNil(t, signers[0].GetSignatureFactory())
+	require.NotNil(t, signers[0].GetVerifierFactory())
+}
+
+func TestSigner_Aggregate(t *testing.T) {
+	signers := mustDeal(t, 3, 5)
+
+	sig0, err := signers[0].Sign([]byte("deadbeef"))
+	require.NoError(t, err)
+
+	sig1, err := signers[1].Sign([]byte("deadbeef"))
+	require.NoError(t, err)
+
+	sig3, err := signers[3].Sign([]byte("deadbeef"))
+	require.NoError(t, err)
+
+	// Below the threshold, the partial signatures are merged.
+	agg, err := signers[0].Aggregate(sig0, sig3, sig0)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 3}, agg.(Signature).GetIndices())
+
+	// At the threshold, they are combined into the signature of the group.
+	agg, err = signers[0].Aggregate(agg, sig1)
+	require.NoError(t, err)
+	require.IsType(t, bls.Signature{}, agg)
+	require.NoError(t, signers[0].GetGroupKey().Verify([]byte("deadbeef"), agg))
+
+	// A combined signature is already complete.
+	res, err := signers[0].Aggregate(agg, sig1)
+	require.NoError(t, err)
+	require.True(t, agg.Equal(res))
+
+	_, err = signers[0].Aggregate(fake.Signature{})
+	require.EqualError(t, err, "invalid signature type 'fake.Signature'")
+
+	other, err := signers[0].Sign([]byte("abc"))
+	require.NoError(t, err)
+
+	_, err = signers[0].Aggregate(sig0, other)
+	require.EqualError(t, err, "couldn't merge: conflicting partial signatures for index 0")
+
+	_, err = signers[0].Aggregate(sig3, sig1, Signature{entries: []entry{{index: 4, data: []byte{1}}}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "couldn't combine: couldn't unmarshal partial signature: ")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func mustDeal(t *testing.T, threshold, n int) []Signer {
+	signers, err := Deal(threshold, n)
+	require.NoError(t, err)
+
+	return signers
+}
+
+func aggregatePartials(t *testing.T, signers ...Signer) Signature {
+	entries := make([]entry, 0, len(signers))
+
+	for _, signer := range signers {
+		sig, err := signer.Sign([]byte("deadbeef"))
+		require.NoError(t, err)
+
+		entries = append(entries, sig.(Signature).entries...)
+	}
+
+	sig, err := mergeEntries(entries)
+	require.NoError(t, err)
+
+	return sig
+}
@@ -0,0 +1,103 @@
+package proto
+
+import (
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/bls/threshold"
+	"go.dedis.ch/dela/crypto/common/proto"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	threshold.RegisterPublicKeyFormat(serde.FormatProtobuf, pubkeyFormat{})
+	threshold.RegisterSignatureFormat(serde.FormatProtobuf, sigFormat{})
+}
+
+type pubkeyFormat struct{}
+
+func (f pubkeyFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	pubkey, ok := msg.(threshold.PublicShare)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message of type '%T'", msg)
+	}
+
+	buffer, err := pubkey.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal key: %v", err)
+	}
+
+	m := &proto.PublicKey{
+		Name: threshold.Algorithm,
+		Data: buffer,
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+func (f pubkeyFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := &proto.PublicKey{}
+	err := ctx.Unmarshal(data, m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal public key: %v", err)
+	}
+
+	pubkey, err := threshold.NewPublicShare(m.Data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't create public key: %v", err)
+	}
+
+	return pubkey, nil
+}
+
+// sigFormat encodes the sets of partial signatures, and decodes both them and
+// the BLS signatures they are combined into, which are tagged with the BLS
+// algorithm.
+type sigFormat struct{}
+
+func (f sigFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	signature, ok := msg.(threshold.Signature)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message of type '%T'", msg)
+	}
+
+	buffer, err := signature.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal signature: %v", err)
+	}
+
+	m := &proto.Signature{
+		Name: threshold.Algorithm,
+		Data: buffer,
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+func (f sigFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := &proto.Signature{}
+	err := ctx.Unmarshal(data, m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal signature: %v", err)
+	}
+
+	if m.Name == bls.Algorithm {
+		return bls.NewSignature(m.Data), nil
+	}
+
+	signature, err := threshold.NewSignature(m.Data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't create signature: %v", err)
+	}
+
+	return signature, nil
+}
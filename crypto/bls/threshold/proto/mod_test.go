@@ -0,0 +1,117 @@
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto/bls"
+	_ "go.dedis.ch/dela/crypto/bls/proto"
+	"go.dedis.ch/dela/crypto/bls/threshold"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestPubkeyFormat_Encode(t *testing.T) {
+	format := pubkeyFormat{}
+	signers := mustDeal(t)
+
+	msg := signers[0].GetPublicKey()
+
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	data, err := format.Encode(ctx, msg)
+	require.NoError(t, err)
+	require.Regexp(t, `{"Name":"BLS-THRESHOLD-BN256","Data":"[^"]+"}`, string(data))
+
+	_, err = format.Encode(fake.NewBadContext(), msg)
+	require.EqualError(t, err, fake.Err("couldn't marshal"))
+
+	_, err = format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message of type 'fake.Message'")
+}
+
+func TestPubkeyFormat_Decode(t *testing.T) {
+	format := pubkeyFormat{}
+	signers := mustDeal(t)
+
+	ctx := fake.NewContextWithFormat(serde.FormatProtobuf)
+
+	data, err := signers[1].GetPublicKey().Serialize(ctx)
+	require.NoError(t, err)
+
+	pubkey, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+	require.True(t, signers[1].GetPublicKey().Equal(pubkey))
+
+	_, err = format.Decode(ctx, []byte(`{"Data":""}`))
+	require.EqualError(t, err, "couldn't create public key: data too short: 0 < 2")
+
+	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("couldn't unmarshal public key"))
+}
+
+func TestSigFormat_Encode(t *testing.T) {
+	format := sigFormat{}
+	ctx := fake.NewContext()
+
+	signers := mustDeal(t)
+	sig, err := signers[0].Sign([]byte("hello"))
+	require.NoError(t, err)
+
+	data, err := format.Encode(ctx, sig)
+	require.NoError(t, err)
+	require.Regexp(t, `{"Name":"BLS-THRESHOLD-BN256","Data":"[^"]+"}`, string(data))
+
+	_, err = format.Encode(fake.NewBadContext(), sig)
+	require.EqualError(t, err, fake.Err("couldn't marshal"))
+
+	_, err = format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message of type 'fake.Message'")
+}
+
+func TestSigFormat_Decode(t *testing.T) {
+	format := sigFormat{}
+	ctx := fake.NewContextWithFormat(serde.FormatProtobuf)
+
+	signers := mustDeal(t)
+
+	partial, err := signers[0].Sign([]byte("hello"))
+	require.NoError(t, err)
+
+	data, err := partial.Serialize(ctx)
+	require.NoError(t, err)
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+	require.True(t, partial.Equal(msg.(threshold.Signature)))
+
+	other, err := signers[1].Sign([]byte("hello"))
+	require.NoError(t, err)
+
+	combined, err := signers[0].Aggregate(partial, other)
+	require.NoError(t, err)
+
+	data, err = combined.Serialize(ctx)
+	require.NoError(t, err)
+
+	msg, err = format.Decode(ctx, data)
+	require.NoError(t, err)
+	require.IsType(t, bls.Signature{}, msg)
+	require.True(t, combined.Equal(msg.(bls.Signature)))
+
+	_, err = format.Decode(ctx, []byte(`{"Data":""}`))
+	require.EqualError(t, err, "couldn't create signature: invalid data size 0")
+
+	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("couldn't unmarshal signature"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func mustDeal(t *testing.T) []threshold.Signer {
+	signers, err := threshold.Deal(2, 3)
+	require.NoError(t, err)
+
+	return signers
+}
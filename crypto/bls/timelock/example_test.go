@@ -0,0 +1,47 @@
+package timelock
+
+import (
+	"fmt"
+
+	"go.dedis.ch/dela/crypto/bls/threshold"
+)
+
+func ExampleDecrypt() {
+	// The roster of the beacon shares the key of the group.
+	signers, err := threshold.Deal(2, 3)
+	if err != nil {
+		panic("deal failed: " + err.Error())
+	}
+
+	group := signers[0].GetGroupKey()
+
+	ct, err := Encrypt(group, 42, []byte("sealed bid"))
+	if err != nil {
+		panic("encryption failed: " + err.Error())
+	}
+
+	// When the beacon reaches the round, a threshold of the roster signs it.
+	signatureA, err := signers[0].Sign(RoundMessage(42))
+	if err != nil {
+		panic("signer A failed: " + err.Error())
+	}
+
+	signatureC, err := signers[2].Sign(RoundMessage(42))
+	if err != nil {
+		panic("signer C failed: " + err.Error())
+	}
+
+	round, err := signers[1].Aggregate(signatureA, signatureC)
+	if err != nil {
+		panic("aggregate failed: " + err.Error())
+	}
+
+	data, err := Decrypt(group, round, ct)
+	if err != nil {
+		panic("decryption failed: " + err.Error())
+	}
+
+	fmt.Println(string(data))
+
+	// Output: sealed bid
+}
@@ -0,0 +1,230 @@
+// Package timelock implements a timed-release encryption toward the rounds of
+// a randomness beacon that signs each round with a threshold BLS key.
+//
+// The signature of a round by the group is unique, and it can't be produced
+// before a threshold of the participants sign the round. It is therefore the
+// private key of the round in the identity-based encryption of Boneh and
+// Franklin, where the identity is the round and the master public key is the
+// key of the group. Data encrypted toward a future round can be decrypted by
+// anyone once the beacon has published the signature of the round, and by no
+// one before, which is useful for sealed-bid auctions or embargoed ballots.
+//
+// The scheme encrypts a random key with the identity-based encryption, and the
+// data with AES-GCM under that key.
+//
+// Related Papers:
+//
+// Identity-Based Encryption from the Weil Pairing (2001)
+//
+// tlock: Practical Timelock Encryption from Threshold BLS (2023)
+//
+package timelock
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// roundSize is the size of the round in the encodings.
+	roundSize = 8
+
+	// domain separates the keys derived by the scheme from other usages of
+	// the pairing.
+	domain = "dela-timelock-v1"
+)
+
+var (
+	suite = pairing.NewSuiteBn256()
+
+	pointSize = suite.G2().PointLen()
+)
+
+type hashablePoint interface {
+	Hash([]byte) kyber.Point
+}
+
+// RoundMessage returns the message that the beacon signs to publish the round.
+func RoundMessage(round uint64) []byte {
+	buffer := make([]byte, roundSize)
+	binary.BigEndian.PutUint64(buffer, round)
+
+	digest := sha256.Sum256(buffer)
+
+	return digest[:]
+}
+
+// Ciphertext is data encrypted toward a round of the beacon.
+type Ciphertext struct {
+	round uint64
+	u     kyber.Point
+	data  []byte
+}
+
+// NewCiphertext returns the ciphertext of the binary encoding, or an error if
+// it is malformed.
+func NewCiphertext(data []byte) (Ciphertext, error) {
+	if len(data) < roundSize+pointSize {
+		return Ciphertext{}, xerrors.Errorf("ciphertext too short: %d", len(data))
+	}
+
+	u := suite.G2().Point()
+
+	err := u.UnmarshalBinary(data[roundSize : roundSize+pointSize])
+	if err != nil {
+		return Ciphertext{}, xerrors.Errorf("couldn't unmarshal point: %v", err)
+	}
+
+	ct := Ciphertext{
+		round: binary.BigEndian.Uint64(data[:roundSize]),
+		u:     u,
+		data:  append([]byte{}, data[roundSize+pointSize:]...),
+	}
+
+	return ct, nil
+}
+
+// GetRound returns the round of the beacon that can decrypt the data.
+func (ct Ciphertext) GetRound() uint64 {
+	return ct.round
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns the round, the
+// encapsulated key and the encrypted data, one after the other.
+func (ct Ciphertext) MarshalBinary() ([]byte, error) {
+	point, err := ct.u.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal point: %v", err)
+	}
+
+	buffer := make([]byte, roundSize, roundSize+len(point)+len(ct.data))
+	binary.BigEndian.PutUint64(buffer, ct.round)
+
+	buffer = append(buffer, point...)
+	buffer = append(buffer, ct.data...)
+
+	return buffer, nil
+}
+
+// Encrypt returns the data encrypted toward the round of the beacon with the
+// group key.
+func Encrypt(group bls.PublicKey, round uint64, data []byte) (Ciphertext, error) {
+	pub, err := toPoint(group)
+	if err != nil {
+		return Ciphertext{}, xerrors.Errorf("invalid group key: %v", err)
+	}
+
+	r := suite.G2().Scalar().Pick(suite.RandomStream())
+
+	// U = rG2 encapsulates the key e(H(round), group)^r which can only be
+	// computed again with the signature of the round.
+	u := suite.G2().Point().Mul(r, nil)
+	shared := suite.Pair(hashRound(round), suite.G2().Point().Mul(r, pub))
+
+	aead, err := makeAEAD(shared, u)
+	if err != nil {
+		return Ciphertext{}, xerrors.Errorf("couldn't make cipher: %v", err)
+	}
+
+	ct := Ciphertext{
+		round: round,
+		u:     u,
+		data:  aead.Seal(nil, make([]byte, aead.NonceSize()), data, RoundMessage(round)),
+	}
+
+	return ct, nil
+}
+
+// Decrypt returns the data of the ciphertext with the signature of its round
+// by the beacon, or an error if the signature doesn't match the round or if the
+// ciphertext has been tampered with.
+func Decrypt(group bls.PublicKey, sig crypto.Signature, ct Ciphertext) ([]byte, error) {
+	msg := RoundMessage(ct.round)
+
+	err := group.Verify(msg, sig)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid signature of round %d: %v", ct.round, err)
+	}
+
+	raw, err := sig.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal signature: %v", err)
+	}
+
+	point := suite.G1().Point()
+
+	err = point.UnmarshalBinary(raw)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal signature: %v", err)
+	}
+
+	// e(sH(round), rG2) = e(H(round), sG2)^r
+	shared := suite.Pair(point, ct.u)
+
+	aead, err := makeAEAD(shared, ct.u)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't make cipher: %v", err)
+	}
+
+	data, err := aead.Open(nil, make([]byte, aead.NonceSize()), ct.data, msg)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't open: %v", err)
+	}
+
+	return data, nil
+}
+
+func toPoint(pk bls.PublicKey) (kyber.Point, error) {
+	raw, err := pk.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	point := suite.G2().Point()
+
+	err = point.UnmarshalBinary(raw)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal: %v", err)
+	}
+
+	return point, nil
+}
+
+// hashRound returns the point of the round that the beacon signs, as the BLS
+// signature hashes a message.
+func hashRound(round uint64) kyber.Point {
+	return suite.G1().Point().(hashablePoint).Hash(RoundMessage(round))
+}
+
+// makeAEAD derives the symmetric key from the shared secret and the
+// encapsulation. Each key is used for a single encryption, hence the nonce can
+// be constant.
+func makeAEAD(shared, u kyber.Point) (cipher.AEAD, error) {
+	h := sha256.New()
+	h.Write([]byte(domain))
+
+	_, err := shared.MarshalTo(h)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't write secret: %v", err)
+	}
+
+	_, err = u.MarshalTo(h)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't write point: %v", err)
+	}
+
+	block, err := aes.NewCipher(h.Sum(nil))
+	if err != nil {
+		return nil, xerrors.Errorf("aes: %v", err)
+	}
+
+	return cipher.NewGCM(block)
+}
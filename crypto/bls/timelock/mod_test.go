@@ -0,0 +1,147 @@
+package timelock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/bls/threshold"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/kyber/v3"
+)
+
+func TestRoundMessage(t *testing.T) {
+	require.Len(t, RoundMessage(0), 32)
+	require.Equal(t, RoundMessage(42), RoundMessage(42))
+	require.NotEqual(t, RoundMessage(42), RoundMessage(43))
+}
+
+func TestEncrypt(t *testing.T) {
+	signer := bls.NewSigner()
+	group := signer.GetPublicKey().(bls.PublicKey)
+
+	ct, err := Encrypt(group, 42, []byte("sealed bid"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), ct.GetRound())
+	require.NotContains(t, string(ct.data), "sealed bid")
+
+	// The encryption is randomized.
+	ct2, err := Encrypt(group, 42, []byte("sealed bid"))
+	require.NoError(t, err)
+	require.NotEqual(t, ct.data, ct2.data)
+}
+
+func TestDecrypt(t *testing.T) {
+	signer := bls.NewSigner()
+	group := signer.GetPublicKey().(bls.PublicKey)
+
+	ct, err := Encrypt(group, 42, []byte("sealed bid"))
+	require.NoError(t, err)
+
+	sig, err := signer.Sign(RoundMessage(42))
+	require.NoError(t, err)
+
+	data, err := Decrypt(group, sig, ct)
+	require.NoError(t, err)
+	require.Equal(t, []byte("sealed bid"), data)
+
+	// The signature of another round doesn't decrypt the data.
+	other, err := signer.Sign(RoundMessage(41))
+	require.NoError(t, err)
+
+	_, err = Decrypt(group, other, ct)
+	require.EqualError(t, err,
+		"invalid signature of round 42: bls verify failed: bls: invalid signature")
+
+	_, err = Decrypt(group, fake.Signature{}, ct)
+	require.EqualError(t, err,
+		"invalid signature of round 42: invalid signature type 'fake.Signature'")
+
+	// A ciphertext moved to another round is rejected.
+	ct.round = 41
+
+	_, err = Decrypt(group, other, ct)
+	require.EqualError(t, err, "couldn't open: cipher: message authentication failed")
+}
+
+func TestDecrypt_Threshold(t *testing.T) {
+	signers, err := threshold.Deal(3, 4)
+	require.NoError(t, err)
+
+	group := signers[0].GetGroupKey()
+
+	ct, err := Encrypt(group, 7, []byte("embargoed ballot"))
+	require.NoError(t, err)
+
+	partials := []threshold.Signer{signers[0], signers[1], signers[3]}
+
+	sigs := make([]crypto.Signature, 0, len(partials))
+	for _, signer := range partials {
+		sig, err := signer.Sign(RoundMessage(7))
+		require.NoError(t, err)
+
+		sigs = append(sigs, sig)
+	}
+
+	// Below the threshold, the signature of the round is not known yet.
+	partial, err := signers[0].Aggregate(sigs[:2]...)
+	require.NoError(t, err)
+
+	_, err = Decrypt(group, partial, ct)
+	require.Error(t, err)
+
+	sig, err := signers[0].Aggregate(sigs...)
+	require.NoError(t, err)
+
+	data, err := Decrypt(group, sig, ct)
+	require.NoError(t, err)
+	require.Equal(t, []byte("embargoed ballot"), data)
+}
+
+func TestCiphertext_MarshalBinary(t *testing.T) {
+	signer := bls.NewSigner()
+	group := signer.GetPublicKey().(bls.PublicKey)
+
+	ct, err := Encrypt(group, 42, []byte("sealed bid"))
+	require.NoError(t, err)
+
+	data, err := ct.MarshalBinary()
+	require.NoError(t, err)
+	require.Len(t, data, roundSize+pointSize+len(ct.data))
+
+	ct2, err := NewCiphertext(data)
+	require.NoError(t, err)
+	require.Equal(t, ct.round, ct2.round)
+	require.True(t, ct.u.Equal(ct2.u))
+	require.Equal(t, ct.data, ct2.data)
+
+	ct.u = badPoint{}
+	_, err = ct.MarshalBinary()
+	require.EqualError(t, err, fake.Err("couldn't marshal point"))
+}
+
+func TestNewCiphertext(t *testing.T) {
+	_, err := NewCiphertext(make([]byte, roundSize))
+	require.EqualError(t, err, "ciphertext too short: 8")
+
+	data := make([]byte, roundSize+pointSize)
+	for i := range data {
+		data[i] = 0xff
+	}
+
+	_, err = NewCiphertext(data)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "couldn't unmarshal point: ")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type badPoint struct {
+	kyber.Point
+}
+
+func (badPoint) MarshalBinary() ([]byte, error) {
+	return nil, fake.GetError()
+}
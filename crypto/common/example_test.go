@@ -48,8 +48,8 @@ func ExamplePublicKeyFactory_PublicKeyOf_bls() {
 }
 
 func ExamplePublicKeyFactory_PublicKeyOf_ed25519() {
+	// Ed25519 is also registered by default
 	factory := common.NewPublicKeyFactory()
-	factory.RegisterAlgorithm(ed25519.Algorithm, ed25519.NewPublicKeyFactory())
 
 	ctx := json.NewContext()
 
@@ -8,6 +8,8 @@ package common
 import (
 	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/dilithium"
+	"go.dedis.ch/dela/crypto/ed25519"
 	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/dela/serde/registry"
 	"golang.org/x/xerrors"
@@ -61,20 +63,30 @@ type PublicKeyFactory interface {
 	PublicKeyOf(serde.Context, []byte) (crypto.PublicKey, error)
 }
 
-// PublicKeyFac is a public key factory for commonly known algorithms.
+// PublicKeyFac is a public key factory for commonly known algorithms. It can
+// be used where a single factory is expected, like a roster, to support public
+// keys of different algorithms, as it dispatches each key to the factory of the
+// algorithm it is tagged with.
 //
 // - implements common.PublicKeyFactory
+// - implements crypto.PublicKeyFactory
 type PublicKeyFac struct {
 	factories map[string]crypto.PublicKeyFactory
+	// names keeps the order of registration of the algorithms.
+	names *[]string
 }
 
 // NewPublicKeyFactory returns a new instance of the common public key factory.
+// It registers the BLS, the Ed25519 and the ML-DSA algorithms by default.
 func NewPublicKeyFactory() PublicKeyFac {
 	factory := PublicKeyFac{
 		factories: make(map[string]crypto.PublicKeyFactory),
+		names:     new([]string),
 	}
 
 	factory.RegisterAlgorithm(bls.Algorithm, bls.NewPublicKeyFactory())
+	factory.RegisterAlgorithm(ed25519.Algorithm, ed25519.NewPublicKeyFactory())
+	factory.RegisterAlgorithm(dilithium.Algorithm, dilithium.NewPublicKeyFactory())
 
 	return factory
 }
@@ -82,6 +94,11 @@ func NewPublicKeyFactory() PublicKeyFac {
 // RegisterAlgorithm registers the factory for the algorithm. It will override
 // an already existing key.
 func (f PublicKeyFac) RegisterAlgorithm(algo string, factory crypto.PublicKeyFactory) {
+	_, found := f.factories[algo]
+	if !found {
+		*f.names = append(*f.names, algo)
+	}
+
 	f.factories[algo] = factory
 }
 
@@ -119,6 +136,21 @@ func (f PublicKeyFac) PublicKeyOf(ctx serde.Context, data []byte) (crypto.Public
 	return msg.(crypto.PublicKey), nil
 }
 
+// FromBytes implements crypto.PublicKeyFactory. As the raw data of a public key
+// is not tagged with its algorithm, it tries the factories in the order of
+// registration and returns the first public key that can be unmarshaled,
+// otherwise an error.
+func (f PublicKeyFac) FromBytes(data []byte) (crypto.PublicKey, error) {
+	for _, name := range *f.names {
+		pubkey, err := f.factories[name].FromBytes(data)
+		if err == nil {
+			return pubkey, nil
+		}
+	}
+
+	return nil, xerrors.New("no algorithm matches the data")
+}
+
 // SignatureFactory is a factory for commonly known algorithms.
 //
 // - implements crypto.SignatureFactory
@@ -127,13 +159,15 @@ type SignatureFactory struct {
 }
 
 // NewSignatureFactory returns a new instance of the common signature factory.
-// It registers the BLS algorithm by default.
+// It registers the BLS, the Ed25519 and the ML-DSA algorithms by default.
 func NewSignatureFactory() SignatureFactory {
 	factory := SignatureFactory{
 		factories: make(map[string]crypto.SignatureFactory),
 	}
 
 	factory.RegisterAlgorithm(bls.Algorithm, bls.NewSignatureFactory())
+	factory.RegisterAlgorithm(ed25519.Algorithm, ed25519.NewSignatureFactory())
+	factory.RegisterAlgorithm(dilithium.Algorithm, dilithium.NewSignatureFactory())
 
 	return factory
 }
@@ -5,6 +5,8 @@ import (
 	"testing/quick"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/ed25519"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/serde"
 )
@@ -45,10 +47,17 @@ func TestPublicKeyFactory_RegisterAlgorithm(t *testing.T) {
 	factory := NewPublicKeyFactory()
 
 	// Check passive registrations.
-	require.Len(t, factory.factories, 1)
+	require.Len(t, factory.factories, 3)
+	require.Len(t, *factory.names, 3)
 
 	factory.RegisterAlgorithm(testAlgorithm, fake.PublicKeyFactory{})
-	require.Len(t, factory.factories, 2)
+	require.Len(t, factory.factories, 4)
+	require.Len(t, *factory.names, 4)
+
+	// Overriding an algorithm keeps the order of registration.
+	factory.RegisterAlgorithm(bls.Algorithm, fake.PublicKeyFactory{})
+	require.Len(t, *factory.names, 4)
+	require.Equal(t, bls.Algorithm, (*factory.names)[0])
 }
 
 func TestPublicKeyFactory_Deserialize(t *testing.T) {
@@ -82,13 +91,34 @@ func TestPublicKeyFactory_PublicKeyOf(t *testing.T) {
 	require.EqualError(t, err, fake.Err("couldn't decode algorithm"))
 }
 
+func TestPublicKeyFactory_FromBytes(t *testing.T) {
+	factory := NewPublicKeyFactory()
+
+	blsKey, err := bls.NewSigner().GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	pubkey, err := factory.FromBytes(blsKey)
+	require.NoError(t, err)
+	require.IsType(t, bls.PublicKey{}, pubkey)
+
+	edKey, err := ed25519.NewSigner().GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	pubkey, err = factory.FromBytes(edKey)
+	require.NoError(t, err)
+	require.IsType(t, ed25519.PublicKey{}, pubkey)
+
+	_, err = factory.FromBytes([]byte{1, 2, 3})
+	require.EqualError(t, err, "no algorithm matches the data")
+}
+
 func TestSignatureFactory_RegisterAlgorithm(t *testing.T) {
 	factory := NewSignatureFactory()
 
-	require.Len(t, factory.factories, 1)
+	require.Len(t, factory.factories, 3)
 
 	factory.RegisterAlgorithm("fake", fake.SignatureFactory{})
-	require.Len(t, factory.factories, 2)
+	require.Len(t, factory.factories, 4)
 }
 
 func TestSignatureFactory_Deserialize(t *testing.T) {
@@ -0,0 +1,104 @@
+// Package proto implements the protobuf format of the common crypto messages.
+//
+// The messages follow the schema defined in common.proto. As the public key
+// and the signature messages share the field of the algorithm name, they can
+// be decoded as an algorithm message to look up the right factory.
+package proto
+
+import (
+	protobuf "github.com/golang/protobuf/proto"
+	"go.dedis.ch/dela/crypto/common"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	common.RegisterAlgorithmFormat(serde.FormatProtobuf, algoFormat{})
+}
+
+// Algorithm is the common protobuf message to identify which algorithm is
+// used in a message.
+type Algorithm struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *Algorithm) Reset() { *m = Algorithm{} }
+
+// String implements proto.Message.
+func (m *Algorithm) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*Algorithm) ProtoMessage() {}
+
+// PublicKey is the common protobuf message for a public key. It contains the
+// algorithm and the data to deserialize.
+type PublicKey struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3"`
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *PublicKey) Reset() { *m = PublicKey{} }
+
+// String implements proto.Message.
+func (m *PublicKey) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*PublicKey) ProtoMessage() {}
+
+// Signature is the common protobuf message for a signature. It contains the
+// algorithm and the data to deserialize.
+type Signature struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3"`
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3"`
+}
+
+// Reset implements proto.Message.
+func (m *Signature) Reset() { *m = Signature{} }
+
+// String implements proto.Message.
+func (m *Signature) String() string { return protobuf.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message.
+func (*Signature) ProtoMessage() {}
+
+// AlgoFormat is the engine to encode and decode algorithm data in protobuf
+// format.
+//
+// - implements serde.FormatEngine
+type algoFormat struct{}
+
+// Encode implements serde.FormatEngine. It returns the protobuf
+// representation of an algorithm message.
+func (f algoFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	algo, ok := msg.(common.Algorithm)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message of type '%T'", msg)
+	}
+
+	m := &Algorithm{
+		Name: algo.GetName(),
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the algorithm message
+// from the protobuf data if appropriate, otherwise it returns an error.
+func (f algoFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := &Algorithm{}
+	err := ctx.Unmarshal(data, m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't deserialize algorithm: %v", err)
+	}
+
+	alg := common.NewAlgorithm(m.Name)
+
+	return alg, nil
+}
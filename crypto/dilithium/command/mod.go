@@ -0,0 +1,58 @@
+// Package command defines cli commands for the dilithium package.
+package command
+
+import (
+	"io/ioutil"
+	"os"
+
+	"go.dedis.ch/dela/cli"
+	"go.dedis.ch/dela/crypto/dilithium"
+)
+
+// Initializer implements the ML-DSA initializer for the crypto CLI.
+//
+// - implements cli.Initializer
+type Initializer struct {
+}
+
+// SetCommands implements cli.Initializer.
+func (i Initializer) SetCommands(provider cli.Provider) {
+	action := action{
+		printer: os.Stdout,
+
+		genSigner: dilithium.NewSigner().MarshalBinary,
+		getPubKey: getPubkey,
+		readFile:  ioutil.ReadFile,
+		saveFile:  saveToFile,
+	}
+
+	cmd := provider.SetCommand("mldsa")
+	signer := cmd.SetSubCommand("signer")
+
+	new := signer.SetSubCommand("new")
+	new.SetDescription("create a new ML-DSA signer")
+	new.SetFlags(cli.StringFlag{
+		Name:     "save",
+		Usage:    "if provided, save the signer to that file",
+		Required: false,
+	}, cli.BoolFlag{
+		Name:     "force",
+		Usage:    "in the case it saves the signer, will overwrite if needed",
+		Required: false,
+	})
+	new.SetAction(action.newSignerAction)
+
+	read := signer.SetSubCommand("read")
+	read.SetDescription("read a signer")
+	read.SetFlags(cli.StringFlag{
+		Name:     "path",
+		Usage:    "path to the signer's file",
+		Required: true,
+	}, cli.StringFlag{
+		Name:     "format",
+		Usage:    "output format: [PUBKEY | BASE64 | BASE64_PUBKEY]",
+		Value:    "PUBKEY",
+		Required: false,
+	})
+	read.SetAction(action.loadSignerAction)
+}
@@ -0,0 +1,417 @@
+// This file contains the implementation of the ML-DSA-65 signature scheme as
+// specified by FIPS 204.
+
+package dilithium
+
+import (
+	"crypto/subtle"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	k          = 6
+	l          = 5
+	d          = 13
+	eta        = 4
+	tau        = 49
+	beta       = tau * eta
+	gamma1     = 1 << 19
+	gamma1Bits = 20
+	gamma2     = (q - 1) / 32
+	omega      = 55
+	lambda     = 192
+	ctildeSize = lambda / 4
+	t1Bits     = 10
+	w1Bits     = 4
+
+	// SeedSize is the size in bytes of the seed of a private key.
+	SeedSize = 32
+
+	// PublicKeySize is the size in bytes of an encoded public key.
+	PublicKeySize = 32 + k*n*t1Bits/8
+
+	// SignatureSize is the size in bytes of an encoded signature.
+	SignatureSize = ctildeSize + l*n*gamma1Bits/8 + omega + k
+)
+
+// privateKey is the expanded private key derived from a seed.
+type privateKey struct {
+	seed [SeedSize]byte
+	key  []byte
+	tr   []byte
+	a    [k][l]poly
+	s1   [l]poly
+	s2   [k]poly
+	t0   [k]poly
+	pub  *publicKey
+}
+
+// publicKey is a decoded public key.
+type publicKey struct {
+	data []byte
+	tr   []byte
+	a    [k][l]poly
+	t1   [k]poly
+}
+
+// newPrivateKey derives the key pair from the seed. The polynomials of the
+// private key are kept in NTT representation.
+func newPrivateKey(seed []byte) (*privateKey, error) {
+	if len(seed) != SeedSize {
+		return nil, xerrors.Errorf("invalid seed size %d != %d", len(seed), SeedSize)
+	}
+
+	h := shake256(128, seed, []byte{k, l})
+	rho, rhoPrime, key := h[:32], h[32:96], h[96:]
+
+	a := expandA(rho)
+	s1, s2 := expandS(rhoPrime)
+
+	var s1Hat [l]poly
+	for i := range s1 {
+		s1Hat[i] = nttOf(s1[i])
+	}
+
+	t := mulMatrix(&a, &s1Hat)
+
+	var t1, t0 [k]poly
+	for i := range t {
+		t[i] = add(t[i], s2[i])
+
+		for j, c := range t[i] {
+			t1[i][j], t0[i][j] = power2Round(c)
+		}
+	}
+
+	pub := &publicKey{
+		data: encodePublicKey(rho, &t1),
+		a:    a,
+	}
+
+	pub.tr = shake256(64, pub.data)
+
+	for i := range t1 {
+		pub.t1[i] = scaleNTT(t1[i])
+	}
+
+	sk := &privateKey{
+		key: key,
+		tr:  pub.tr,
+		a:   a,
+		s1:  s1Hat,
+		pub: pub,
+	}
+
+	copy(sk.seed[:], seed)
+
+	for i := range s2 {
+		sk.s2[i] = nttOf(s2[i])
+		sk.t0[i] = nttOf(t0[i])
+	}
+
+	return sk, nil
+}
+
+// sign returns the signature of the message with an empty context. The
+// randomness makes the signature hedged, or deterministic when it is zero.
+func (sk *privateKey) sign(msg []byte, rnd []byte) []byte {
+	mu := shake256(64, sk.tr, []byte{0, 0}, msg)
+	rho := shake256(64, sk.key, rnd, mu)
+
+	for kappa := 0; ; kappa += l {
+		y := expandMask(rho, kappa)
+
+		var yHat [l]poly
+		for i := range y {
+			yHat[i] = nttOf(y[i])
+		}
+
+		w := mulMatrix(&sk.a, &yHat)
+
+		var w1 [k]poly
+		for i := range w {
+			for j, c := range w[i] {
+				w1[i][j], _ = decompose(c)
+			}
+		}
+
+		ctilde := shake256(ctildeSize, mu, encodeW1(&w1))
+		cHat := nttOf(sampleInBall(ctilde))
+
+		var z [l]poly
+		valid := true
+
+		for i := range z {
+			cs1 := mulNTT(cHat, sk.s1[i])
+			cs1.invNTT()
+
+			z[i] = add(y[i], cs1)
+			valid = valid && z[i].norm() < gamma1-beta
+		}
+
+		if !valid {
+			continue
+		}
+
+		var hints [k][n]bool
+		count := 0
+
+		for i := range w {
+			cs2 := mulNTT(cHat, sk.s2[i])
+			cs2.invNTT()
+
+			ct0 := mulNTT(cHat, sk.t0[i])
+			ct0.invNTT()
+
+			r := sub(w[i], cs2)
+
+			for _, c := range r {
+				_, r0 := decompose(c)
+				valid = valid && abs(r0) < gamma2-beta
+			}
+
+			valid = valid && ct0.norm() < gamma2
+
+			for j := range r {
+				hints[i][j] = makeHint(fieldSub(0, ct0[j]), fieldAdd(r[j], ct0[j]))
+				if hints[i][j] {
+					count++
+				}
+			}
+		}
+
+		if !valid || count > omega {
+			continue
+		}
+
+		return encodeSignature(ctilde, &z, &hints)
+	}
+}
+
+// verify returns nil if the signature of the message with an empty context is
+// valid for the public key, otherwise an error.
+func (pk *publicKey) verify(msg, sig []byte) error {
+	if len(sig) != SignatureSize {
+		return xerrors.Errorf("invalid signature size %d != %d", len(sig), SignatureSize)
+	}
+
+	ctilde, z, hints, err := decodeSignature(sig)
+	if err != nil {
+		return xerrors.Errorf("malformed signature: %v", err)
+	}
+
+	for i := range z {
+		if z[i].norm() >= gamma1-beta {
+			return xerrors.New("signature is out of bound")
+		}
+	}
+
+	mu := shake256(64, pk.tr, []byte{0, 0}, msg)
+	cHat := nttOf(sampleInBall(ctilde))
+
+	var zHat [l]poly
+	for i := range z {
+		zHat[i] = nttOf(z[i])
+	}
+
+	var w1 [k]poly
+
+	for i := 0; i < k; i++ {
+		var w poly
+		for j := range zHat {
+			w = add(w, mulNTT(pk.a[i][j], zHat[j]))
+		}
+
+		w = sub(w, mulNTT(cHat, pk.t1[i]))
+		w.invNTT()
+
+		for j, c := range w {
+			w1[i][j] = useHint(hints[i][j], c)
+		}
+	}
+
+	expected := shake256(ctildeSize, mu, encodeW1(&w1))
+
+	if subtle.ConstantTimeCompare(expected, ctilde) != 1 {
+		return xerrors.New("signature mismatch")
+	}
+
+	return nil
+}
+
+// newPublicKey decodes the public key.
+func newPublicKey(data []byte) (*publicKey, error) {
+	if len(data) != PublicKeySize {
+		return nil, xerrors.Errorf("invalid public key size %d != %d", len(data), PublicKeySize)
+	}
+
+	pk := &publicKey{
+		data: append([]byte{}, data...),
+		tr:   shake256(64, data),
+		a:    expandA(data[:32]),
+	}
+
+	coeffs := unpackBits(data[32:], t1Bits)
+	for i := range pk.t1 {
+		var t1 poly
+		copy(t1[:], coeffs[i*n:(i+1)*n])
+
+		pk.t1[i] = scaleNTT(t1)
+	}
+
+	return pk, nil
+}
+
+// scaleNTT returns the NTT representation of t1 multiplied by 2^d.
+func scaleNTT(t1 poly) poly {
+	for i, c := range t1 {
+		t1[i] = c << d
+	}
+
+	return nttOf(t1)
+}
+
+// power2Round splits the coefficient into its high bits and its low bits,
+// returned as an element of the field.
+func power2Round(r uint32) (uint32, uint32) {
+	r0 := int32(r & (1<<d - 1))
+	if r0 > 1<<(d-1) {
+		r0 -= 1 << d
+	}
+
+	return uint32((int32(r) - r0) >> d), modQ(int64(r0))
+}
+
+// decompose splits the coefficient into its high bits and its low bits.
+func decompose(r uint32) (uint32, int32) {
+	r0 := int32(r % (2 * gamma2))
+	if r0 > gamma2 {
+		r0 -= 2 * gamma2
+	}
+
+	if int32(r)-r0 == q-1 {
+		return 0, r0 - 1
+	}
+
+	return uint32((int32(r) - r0) / (2 * gamma2)), r0
+}
+
+// makeHint returns true if adding z to r changes its high bits.
+func makeHint(z, r uint32) bool {
+	r1, _ := decompose(r)
+	v1, _ := decompose(fieldAdd(r, z))
+
+	return r1 != v1
+}
+
+// useHint returns the high bits of r adjusted by the hint.
+func useHint(hint bool, r uint32) uint32 {
+	const m = (q - 1) / (2 * gamma2)
+
+	r1, r0 := decompose(r)
+	if !hint {
+		return r1
+	}
+
+	if r0 > 0 {
+		return (r1 + 1) % m
+	}
+
+	return (r1 + m - 1) % m
+}
+
+func encodePublicKey(rho []byte, t1 *[k]poly) []byte {
+	out := append([]byte{}, rho...)
+
+	for i := range t1 {
+		out = append(out, packBits(t1[i][:], t1Bits)...)
+	}
+
+	return out
+}
+
+func encodeW1(w1 *[k]poly) []byte {
+	out := make([]byte, 0, k*n*w1Bits/8)
+
+	for i := range w1 {
+		out = append(out, packBits(w1[i][:], w1Bits)...)
+	}
+
+	return out
+}
+
+func encodeSignature(ctilde []byte, z *[l]poly, hints *[k][n]bool) []byte {
+	out := make([]byte, 0, SignatureSize)
+	out = append(out, ctilde...)
+
+	values := make([]uint32, n)
+
+	for i := range z {
+		for j, c := range z[i] {
+			values[j] = uint32(gamma1 - centered(c))
+		}
+
+		out = append(out, packBits(values, gamma1Bits)...)
+	}
+
+	y := make([]byte, omega+k)
+	index := 0
+
+	for i := range hints {
+		for j, h := range hints[i] {
+			if h {
+				y[index] = byte(j)
+				index++
+			}
+		}
+
+		y[omega+i] = byte(index)
+	}
+
+	return append(out, y...)
+}
+
+func decodeSignature(sig []byte) ([]byte, [l]poly, [k][n]bool, error) {
+	var z [l]poly
+	var hints [k][n]bool
+
+	ctilde := sig[:ctildeSize]
+	offset := ctildeSize
+	size := n * gamma1Bits / 8
+
+	for i := range z {
+		coeffs := unpackBits(sig[offset:offset+size], gamma1Bits)
+		for j, c := range coeffs {
+			z[i][j] = modQ(gamma1 - int64(c))
+		}
+
+		offset += size
+	}
+
+	y := sig[offset:]
+	index := 0
+
+	for i := range hints {
+		end := int(y[omega+i])
+		if end < index || end > omega {
+			return nil, z, hints, xerrors.New("invalid hint count")
+		}
+
+		for first := index; index < end; index++ {
+			if index > first && y[index-1] >= y[index] {
+				return nil, z, hints, xerrors.New("hints are not sorted")
+			}
+
+			hints[i][y[index]] = true
+		}
+	}
+
+	for ; index < omega; index++ {
+		if y[index] != 0 {
+			return nil, z, hints, xerrors.New("invalid hint padding")
+		}
+	}
+
+	return ctilde, z, hints, nil
+}
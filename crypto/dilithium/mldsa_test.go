@@ -0,0 +1,99 @@
+package dilithium
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The known answers have been produced by an independent implementation of
+// ML-DSA-65 with the seed 0x00..0x1f and a deterministic signature of the
+// message "message 0".
+const (
+	katPublicKey = "d666806e11cee19a7c989f7445f90dd419cf4d2d51db8c0fdb4c0f0a542238c9"
+	katSignature = "f674e5e23c863f87a23d96019c881ce01b69b9707a7633091e50e190d453b437"
+)
+
+func TestMLDSA_KnownAnswer(t *testing.T) {
+	seed := make([]byte, SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+
+	sk, err := newPrivateKey(seed)
+	require.NoError(t, err)
+	require.Len(t, sk.pub.data, PublicKeySize)
+
+	digest := sha256.Sum256(sk.pub.data)
+	require.Equal(t, katPublicKey, hex.EncodeToString(digest[:]))
+
+	sig := sk.sign([]byte("message 0"), make([]byte, 32))
+	require.Len(t, sig, SignatureSize)
+
+	digest = sha256.Sum256(sig)
+	require.Equal(t, katSignature, hex.EncodeToString(digest[:]))
+
+	pk, err := newPublicKey(sk.pub.data)
+	require.NoError(t, err)
+	require.NoError(t, pk.verify([]byte("message 0"), sig))
+}
+
+func TestMLDSA_BadSeed(t *testing.T) {
+	_, err := newPrivateKey(nil)
+	require.EqualError(t, err, "invalid seed size 0 != 32")
+}
+
+func TestMLDSA_BadPublicKey(t *testing.T) {
+	_, err := newPublicKey([]byte{1, 2, 3})
+	require.EqualError(t, err, "invalid public key size 3 != 1952")
+}
+
+func TestMLDSA_Verify(t *testing.T) {
+	sk, err := newPrivateKey(make([]byte, SeedSize))
+	require.NoError(t, err)
+
+	msg := []byte("hello")
+	sig := sk.sign(msg, []byte("random"))
+
+	require.NoError(t, sk.pub.verify(msg, sig))
+
+	err = sk.pub.verify([]byte("world"), sig)
+	require.EqualError(t, err, "signature mismatch")
+
+	err = sk.pub.verify(msg, sig[1:])
+	require.EqualError(t, err, "invalid signature size 3308 != 3309")
+
+	bad := append([]byte{}, sig...)
+	bad[0] ^= 1
+	require.EqualError(t, sk.pub.verify(msg, bad), "signature mismatch")
+
+	// The hints must be sorted and within the bound.
+	bad = append([]byte{}, sig...)
+	bad[SignatureSize-1] = omega + 1
+	require.EqualError(t, sk.pub.verify(msg, bad), "malformed signature: invalid hint count")
+
+	bad = append([]byte{}, sig...)
+	bad[SignatureSize-k-1] = 1
+	require.Error(t, sk.pub.verify(msg, bad))
+}
+
+func TestPoly_NTT(t *testing.T) {
+	var p poly
+	for i := range p {
+		p[i] = uint32(i * 31 % q)
+	}
+
+	res := nttOf(p)
+	res.invNTT()
+	require.Equal(t, p, res)
+}
+
+func TestPackBits(t *testing.T) {
+	values := []uint32{1, 2, 3, 1023, 0, 512, 7, 9}
+
+	data := packBits(values, 10)
+	require.Len(t, data, 10)
+	require.Equal(t, values, unpackBits(data, 10))
+}
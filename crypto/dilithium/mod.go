@@ -0,0 +1,376 @@
+// Package dilithium implements the cryptographic primitives for the
+// post-quantum signature scheme ML-DSA, which is the standardized version of
+// CRYSTALS-Dilithium.
+//
+// The parameter set is ML-DSA-65 which targets the security category 3. The
+// signatures are hedged, meaning that they mix fresh randomness with the
+// private key, and they are not aggregatable, so the scheme cannot be used for
+// collective signing. The public keys and the signatures are significantly
+// larger than for the classical schemes: 1952 and 3309 bytes respectively.
+//
+// The private key is stored as the 32 bytes seed it is derived from.
+//
+// Related Papers:
+//
+// FIPS 204: Module-Lattice-Based Digital Signature Standard (2024)
+// https://doi.org/10.6028/NIST.FIPS.204
+//
+package dilithium
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// Algorithm is the name of the signature scheme.
+	Algorithm = "ML-DSA-65"
+)
+
+var (
+	pubkeyFormats = registry.NewSimpleRegistry()
+
+	sigFormats = registry.NewSimpleRegistry()
+)
+
+// RegisterPublicKeyFormat register the engine for the provided format.
+func RegisterPublicKeyFormat(format serde.Format, engine serde.FormatEngine) {
+	pubkeyFormats.Register(format, engine)
+}
+
+// RegisterSignatureFormat register the engine for the provided format.
+func RegisterSignatureFormat(format serde.Format, engine serde.FormatEngine) {
+	sigFormats.Register(format, engine)
+}
+
+// PublicKey is the public key of an ML-DSA-65 key pair.
+//
+// - implements crypto.PublicKey
+type PublicKey struct {
+	key *publicKey
+}
+
+// NewPublicKey returns a new public key from the data.
+func NewPublicKey(data []byte) (PublicKey, error) {
+	key, err := newPublicKey(data)
+	if err != nil {
+		return PublicKey{}, xerrors.Errorf("couldn't decode key: %v", err)
+	}
+
+	return PublicKey{key: key}, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It produces a slice of
+// bytes representing the public key.
+func (pk PublicKey) MarshalBinary() ([]byte, error) {
+	if pk.key == nil {
+		return nil, xerrors.New("public key is empty")
+	}
+
+	return append([]byte{}, pk.key.data...), nil
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// public key.
+func (pk PublicKey) Serialize(ctx serde.Context) ([]byte, error) {
+	format := pubkeyFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, pk)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode public key: %v", err)
+	}
+
+	return data, nil
+}
+
+// Verify implements crypto.PublicKey. It returns nil if the signature matches
+// the message for this public key.
+func (pk PublicKey) Verify(msg []byte, sig crypto.Signature) error {
+	signature, ok := sig.(Signature)
+	if !ok {
+		return xerrors.Errorf("invalid signature type '%T'", sig)
+	}
+
+	if pk.key == nil {
+		return xerrors.New("public key is empty")
+	}
+
+	err := pk.key.verify(msg, signature.data)
+	if err != nil {
+		return xerrors.Errorf("ml-dsa verify failed: %v", err)
+	}
+
+	return nil
+}
+
+// Equal implements crypto.PublicKey. It returns true if the other public key
+// is the same.
+func (pk PublicKey) Equal(other interface{}) bool {
+	pubkey, ok := other.(PublicKey)
+	if !ok {
+		return false
+	}
+
+	if pk.key == nil || pubkey.key == nil {
+		return pk.key == pubkey.key
+	}
+
+	return bytes.Equal(pk.key.data, pubkey.key.data)
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns a text
+// representation of the public key.
+func (pk PublicKey) MarshalText() ([]byte, error) {
+	buffer, err := pk.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return []byte(fmt.Sprintf("mldsa:%x", buffer)), nil
+}
+
+// String implements fmt.Stringer. It returns a string representation of the
+// public key.
+func (pk PublicKey) String() string {
+	buffer, err := pk.MarshalText()
+	if err != nil {
+		return "mldsa:malformed_key"
+	}
+
+	// Output only the prefix and 16 characters of the buffer in hexadecimal.
+	return string(buffer)[:6+16]
+}
+
+// Signature is an ML-DSA-65 signature.
+//
+// - implements crypto.Signature
+type Signature struct {
+	data []byte
+}
+
+// NewSignature returns a new signature from the data.
+func NewSignature(data []byte) Signature {
+	return Signature{
+		data: data,
+	}
+}
+
+// CheckCanonical returns nil if the signature has the correct size and a
+// strict encoding of the hints, otherwise an error. The packing of the response
+// is bijective so it does not need to be checked.
+func (sig Signature) CheckCanonical() error {
+	if len(sig.data) != SignatureSize {
+		return xerrors.Errorf("invalid signature size %d != %d", len(sig.data), SignatureSize)
+	}
+
+	_, _, _, err := decodeSignature(sig.data)
+	if err != nil {
+		return xerrors.Errorf("malformed signature: %v", err)
+	}
+
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns a slice of
+// bytes representing the signature.
+func (sig Signature) MarshalBinary() ([]byte, error) {
+	return sig.data, nil
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// signature.
+func (sig Signature) Serialize(ctx serde.Context) ([]byte, error) {
+	format := sigFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, sig)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode signature: %v", err)
+	}
+
+	return data, nil
+}
+
+// Equal implements crypto.Signature. It returns true if both signatures are the
+// same.
+func (sig Signature) Equal(other crypto.Signature) bool {
+	otherSig, ok := other.(Signature)
+	if !ok {
+		return false
+	}
+
+	return bytes.Equal(sig.data, otherSig.data)
+}
+
+// publicKeyFactory is a factory to deserialize ML-DSA-65 public keys.
+//
+// - implements crypto.PublicKeyFactory
+// - implements serde.Factory
+type publicKeyFactory struct{}
+
+// NewPublicKeyFactory returns a new instance of the factory.
+func NewPublicKeyFactory() crypto.PublicKeyFactory {
+	return publicKeyFactory{}
+}
+
+// Deserialize implements serde.Factory. It returns the public key deserialized
+// if appropriate, otherwise an error.
+func (f publicKeyFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	return f.PublicKeyOf(ctx, data)
+}
+
+// PublicKeyOf implements crypto.PublicKeyFactory. It returns the public key
+// deserialized if appropriate, otherwise an error.
+func (f publicKeyFactory) PublicKeyOf(ctx serde.Context, data []byte) (crypto.PublicKey, error) {
+	format := pubkeyFormats.Get(ctx.GetFormat())
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't decode public key: %v", err)
+	}
+
+	pubkey, ok := msg.(PublicKey)
+	if !ok {
+		return nil, xerrors.Errorf("invalid public key of type '%T'", msg)
+	}
+
+	return pubkey, nil
+}
+
+// FromBytes implements crypto.PublicKeyFactory. It returns the public key
+// unmarshaled from the bytes.
+func (f publicKeyFactory) FromBytes(data []byte) (crypto.PublicKey, error) {
+	pubkey, err := NewPublicKey(data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal the key: %v", err)
+	}
+
+	return pubkey, nil
+}
+
+// signatureFactory is a factory to deserialize ML-DSA-65 signatures.
+//
+// - implements crypto.SignatureFactory
+// - implements serde.Factory
+type signatureFactory struct{}
+
+// NewSignatureFactory returns a new instance of the factory.
+func NewSignatureFactory() crypto.SignatureFactory {
+	return signatureFactory{}
+}
+
+// Deserialize implements serde.Factory. It returns the signature associated to
+// the data if appropriate, otherwise an error.
+func (f signatureFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	return f.SignatureOf(ctx, data)
+}
+
+// SignatureOf implements crypto.SignatureFactory. It returns the signature
+// associated to the data if appropriate, otherwise an error.
+func (f signatureFactory) SignatureOf(ctx serde.Context, data []byte) (crypto.Signature, error) {
+	format := sigFormats.Get(ctx.GetFormat())
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't decode signature: %v", err)
+	}
+
+	signature, ok := msg.(Signature)
+	if !ok {
+		return nil, xerrors.Errorf("invalid signature of type '%T'", msg)
+	}
+
+	err = signature.CheckCanonical()
+	if err != nil {
+		return nil, xerrors.Errorf("invalid signature: %v", err)
+	}
+
+	return signature, nil
+}
+
+// Signer is a signer that creates ML-DSA-65 signatures.
+//
+// - implements crypto.Signer
+// - implements encoding.BinaryMarshaler
+type Signer struct {
+	key    *privateKey
+	random io.Reader
+}
+
+// NewSigner returns a new random signer.
+func NewSigner() Signer {
+	seed := make([]byte, SeedSize)
+
+	_, err := rand.Read(seed)
+	if err != nil {
+		panic(fmt.Sprintf("failed to generate the seed: %v", err))
+	}
+
+	key, err := newPrivateKey(seed)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create the signer: %v", err))
+	}
+
+	return Signer{
+		key:    key,
+		random: rand.Reader,
+	}
+}
+
+// NewSignerFromBytes restores a signer from the seed of its private key.
+func NewSignerFromBytes(data []byte) (crypto.Signer, error) {
+	key, err := newPrivateKey(data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't derive the key: %v", err)
+	}
+
+	signer := Signer{
+		key:    key,
+		random: rand.Reader,
+	}
+
+	return signer, nil
+}
+
+// GetPublicKeyFactory implements crypto.Signer. It returns the public key
+// factory for ML-DSA-65 signatures.
+func (s Signer) GetPublicKeyFactory() crypto.PublicKeyFactory {
+	return publicKeyFactory{}
+}
+
+// GetSignatureFactory implements crypto.Signer. It returns the signature
+// factory for ML-DSA-65 signatures.
+func (s Signer) GetSignatureFactory() crypto.SignatureFactory {
+	return signatureFactory{}
+}
+
+// GetPublicKey implements crypto.Signer. It returns the public key of the
+// signer that can be used to verify signatures.
+func (s Signer) GetPublicKey() crypto.PublicKey {
+	return PublicKey{key: s.key.pub}
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns the seed of the
+// private key.
+func (s Signer) MarshalBinary() ([]byte, error) {
+	return append([]byte{}, s.key.seed[:]...), nil
+}
+
+// Sign implements crypto.Signer. It signs the message in parameter and returns
+// the signature, or an error if it cannot sign.
+func (s Signer) Sign(msg []byte) (crypto.Signature, error) {
+	rnd := make([]byte, 32)
+
+	_, err := io.ReadFull(s.random, rnd)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't read randomness: %v", err)
+	}
+
+	return Signature{data: s.key.sign(msg, rnd)}, nil
+}
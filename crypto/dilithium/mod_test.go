@@ -0,0 +1,252 @@
+package dilithium
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func init() {
+	RegisterPublicKeyFormat(fake.GoodFormat, fake.Format{Msg: PublicKey{}})
+	RegisterPublicKeyFormat(fake.BadFormat, fake.NewBadFormat())
+	RegisterPublicKeyFormat("BAD_KEY", fake.Format{Msg: fake.Message{}})
+
+	RegisterSignatureFormat(fake.GoodFormat, fake.Format{Msg: testSignature})
+	RegisterSignatureFormat(fake.BadFormat, fake.NewBadFormat())
+	RegisterSignatureFormat("BAD_SIG", fake.Format{Msg: fake.Message{}})
+	RegisterSignatureFormat("NON_CANONICAL", fake.Format{Msg: Signature{}})
+}
+
+func TestPublicKey_New(t *testing.T) {
+	signer := NewSigner()
+
+	data, err := signer.GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+	require.Len(t, data, PublicKeySize)
+
+	pubkey, err := NewPublicKey(data)
+	require.NoError(t, err)
+	require.True(t, pubkey.Equal(signer.GetPublicKey()))
+
+	_, err = NewPublicKey([]byte{})
+	require.EqualError(t, err, "couldn't decode key: invalid public key size 0 != 1952")
+}
+
+func TestPublicKey_MarshalBinary(t *testing.T) {
+	_, err := PublicKey{}.MarshalBinary()
+	require.EqualError(t, err, "public key is empty")
+}
+
+func TestPublicKey_Serialize(t *testing.T) {
+	pk := PublicKey{}
+
+	data, err := pk.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = pk.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode public key"))
+}
+
+func TestPublicKey_Verify(t *testing.T) {
+	signer := NewSigner()
+
+	msg := []byte("hello")
+
+	sig, err := signer.Sign(msg)
+	require.NoError(t, err)
+
+	pk := signer.GetPublicKey()
+
+	err = pk.Verify(msg, sig)
+	require.NoError(t, err)
+
+	err = pk.Verify([]byte("world"), sig)
+	require.EqualError(t, err, "ml-dsa verify failed: signature mismatch")
+
+	err = pk.Verify(msg, fake.NewBadSignature())
+	require.EqualError(t, err, "invalid signature type 'fake.Signature'")
+
+	err = PublicKey{}.Verify(msg, sig)
+	require.EqualError(t, err, "public key is empty")
+}
+
+func TestPublicKey_Equal(t *testing.T) {
+	signer := NewSigner()
+
+	require.True(t, signer.GetPublicKey().Equal(signer.GetPublicKey()))
+	require.False(t, signer.GetPublicKey().Equal(NewSigner().GetPublicKey()))
+	require.False(t, signer.GetPublicKey().Equal(PublicKey{}))
+	require.True(t, PublicKey{}.Equal(PublicKey{}))
+	require.False(t, signer.GetPublicKey().Equal(fake.PublicKey{}))
+}
+
+func TestPublicKey_MarshalText(t *testing.T) {
+	signer := NewSigner()
+
+	text, err := signer.GetPublicKey().(PublicKey).MarshalText()
+	require.NoError(t, err)
+	require.Contains(t, string(text), "mldsa:")
+
+	_, err = PublicKey{}.MarshalText()
+	require.EqualError(t, err, "couldn't marshal: public key is empty")
+}
+
+func TestPublicKey_String(t *testing.T) {
+	signer := NewSigner()
+
+	str := signer.GetPublicKey().(PublicKey).String()
+	require.Len(t, str, 6+16)
+
+	require.Equal(t, "mldsa:malformed_key", PublicKey{}.String())
+}
+
+func TestSignature_MarshalBinary(t *testing.T) {
+	sig := NewSignature([]byte{1, 2, 3})
+
+	data, err := sig.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, data)
+}
+
+func TestSignature_CheckCanonical(t *testing.T) {
+	require.NoError(t, testSignature.CheckCanonical())
+
+	err := NewSignature(testSignature.data[1:]).CheckCanonical()
+	require.EqualError(t, err, "invalid signature size 3308 != 3309")
+
+	// A hint that is set in the padding gives a different encoding of the same
+	// signature, which must be refused.
+	data := append([]byte{}, testSignature.data...)
+	data[SignatureSize-k-1] = 1
+	err = NewSignature(data).CheckCanonical()
+	require.Error(t, err)
+	require.Regexp(t, "^malformed signature: ", err.Error())
+}
+
+func TestSignature_Serialize(t *testing.T) {
+	sig := Signature{}
+
+	data, err := sig.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = sig.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode signature"))
+}
+
+func TestSignature_Equal(t *testing.T) {
+	sig := NewSignature([]byte{1, 2, 3})
+
+	require.True(t, sig.Equal(sig))
+	require.False(t, sig.Equal(NewSignature([]byte{1})))
+	require.False(t, sig.Equal(fake.Signature{}))
+}
+
+func TestPublicKeyFactory_Deserialize(t *testing.T) {
+	factory := NewPublicKeyFactory()
+
+	msg, err := factory.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.Equal(t, PublicKey{}, msg)
+
+	_, err = factory.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("couldn't decode public key"))
+
+	_, err = factory.PublicKeyOf(fake.NewContextWithFormat("BAD_KEY"), nil)
+	require.EqualError(t, err, "invalid public key of type 'fake.Message'")
+}
+
+func TestPublicKeyFactory_FromBytes(t *testing.T) {
+	signer := NewSigner()
+
+	data, err := signer.GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	factory := NewPublicKeyFactory()
+
+	pubkey, err := factory.FromBytes(data)
+	require.NoError(t, err)
+	require.True(t, pubkey.Equal(signer.GetPublicKey()))
+
+	_, err = factory.FromBytes(nil)
+	require.EqualError(t, err,
+		"failed to unmarshal the key: couldn't decode key: invalid public key size 0 != 1952")
+}
+
+func TestSignatureFactory_Deserialize(t *testing.T) {
+	factory := NewSignatureFactory()
+
+	msg, err := factory.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.Equal(t, testSignature, msg)
+
+	_, err = factory.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("couldn't decode signature"))
+
+	_, err = factory.SignatureOf(fake.NewContextWithFormat("BAD_SIG"), nil)
+	require.EqualError(t, err, "invalid signature of type 'fake.Message'")
+
+	_, err = factory.SignatureOf(fake.NewContextWithFormat("NON_CANONICAL"), nil)
+	require.EqualError(t, err, "invalid signature: invalid signature size 0 != 3309")
+}
+
+func TestSigner_GetFactories(t *testing.T) {
+	signer := NewSigner()
+
+	require.NotNil(t, signer.GetPublicKeyFactory())
+	require.NotNil(t, signer.GetSignatureFactory())
+}
+
+func TestSigner_MarshalBinary(t *testing.T) {
+	signer := NewSigner()
+
+	data, err := signer.MarshalBinary()
+	require.NoError(t, err)
+	require.Len(t, data, SeedSize)
+
+	restored, err := NewSignerFromBytes(data)
+	require.NoError(t, err)
+	require.True(t, restored.GetPublicKey().Equal(signer.GetPublicKey()))
+
+	_, err = NewSignerFromBytes(nil)
+	require.EqualError(t, err, "couldn't derive the key: invalid seed size 0 != 32")
+}
+
+func TestSigner_Sign(t *testing.T) {
+	signer := NewSigner()
+
+	sig, err := signer.Sign([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, signer.GetPublicKey().Verify([]byte("hello"), sig))
+
+	// Signatures are hedged with fresh randomness.
+	sig2, err := signer.Sign([]byte("hello"))
+	require.NoError(t, err)
+	require.False(t, sig.Equal(sig2))
+
+	signer.random = badReader{}
+	_, err = signer.Sign([]byte("hello"))
+	require.EqualError(t, err, fake.Err("couldn't read randomness"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+var testSignature = makeSignature()
+
+func makeSignature() Signature {
+	sig, err := NewSigner().Sign([]byte("deadbeef"))
+	if err != nil {
+		panic(err)
+	}
+
+	return sig.(Signature)
+}
+
+type badReader struct{}
+
+func (badReader) Read([]byte) (int, error) {
+	return 0, fake.GetError()
+}
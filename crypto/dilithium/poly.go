@@ -0,0 +1,428 @@
+// This file contains the arithmetic of the polynomials of the ring
+// Zq[X]/(X^256+1) and the sampling of the polynomials from seeds.
+
+package dilithium
+
+import (
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// q is the prime modulus of the field.
+	q = 8380417
+
+	// n is the degree of the polynomials.
+	n = 256
+
+	// invN is the inverse of 256 modulo q, used by the inverse NTT.
+	invN = 8347681
+
+	// zeta is the 512th root of unity modulo q.
+	zeta = 1753
+
+	shake128Rate = 168
+	shake256Rate = 136
+)
+
+// zetas are the powers of the root of unity in bit-reversed order, as used by
+// the number-theoretic transform.
+var zetas = makeZetas()
+
+// poly is a polynomial with coefficients in [0, q).
+type poly [n]uint32
+
+func makeZetas() [n]uint32 {
+	var res [n]uint32
+
+	for i := range res {
+		res[i] = modPow(zeta, uint32(bitRev8(uint8(i))))
+	}
+
+	return res
+}
+
+func bitRev8(x uint8) uint8 {
+	var res uint8
+	for i := 0; i < 8; i++ {
+		res = res<<1 | x&1
+		x >>= 1
+	}
+
+	return res
+}
+
+func modPow(base, exp uint32) uint32 {
+	res := uint32(1)
+	for ; exp > 0; exp >>= 1 {
+		if exp&1 == 1 {
+			res = fieldMul(res, base)
+		}
+
+		base = fieldMul(base, base)
+	}
+
+	return res
+}
+
+// modQ returns the integer reduced in [0, q).
+func modQ(a int64) uint32 {
+	r := a % q
+	if r < 0 {
+		r += q
+	}
+
+	return uint32(r)
+}
+
+func fieldAdd(a, b uint32) uint32 {
+	r := a + b
+	if r >= q {
+		r -= q
+	}
+
+	return r
+}
+
+func fieldSub(a, b uint32) uint32 {
+	r := a + q - b
+	if r >= q {
+		r -= q
+	}
+
+	return r
+}
+
+func fieldMul(a, b uint32) uint32 {
+	return uint32(uint64(a) * uint64(b) % q)
+}
+
+// centered returns the representative of the coefficient in (-q/2, q/2].
+func centered(a uint32) int32 {
+	if a > (q-1)/2 {
+		return int32(a) - q
+	}
+
+	return int32(a)
+}
+
+func abs(a int32) int32 {
+	if a < 0 {
+		return -a
+	}
+
+	return a
+}
+
+// ntt transforms the polynomial in place into its NTT representation.
+func (p *poly) ntt() {
+	m := 0
+
+	for length := 128; length >= 1; length >>= 1 {
+		for start := 0; start < n; start += 2 * length {
+			m++
+			z := zetas[m]
+
+			for j := start; j < start+length; j++ {
+				t := fieldMul(z, p[j+length])
+				p[j+length] = fieldSub(p[j], t)
+				p[j] = fieldAdd(p[j], t)
+			}
+		}
+	}
+}
+
+// invNTT transforms the polynomial in place from its NTT representation.
+func (p *poly) invNTT() {
+	m := n
+
+	for length := 1; length < n; length <<= 1 {
+		for start := 0; start < n; start += 2 * length {
+			m--
+			z := q - zetas[m]
+
+			for j := start; j < start+length; j++ {
+				t := p[j]
+				p[j] = fieldAdd(t, p[j+length])
+				p[j+length] = fieldMul(z, fieldSub(t, p[j+length]))
+			}
+		}
+	}
+
+	for j := range p {
+		p[j] = fieldMul(p[j], invN)
+	}
+}
+
+// nttOf returns the NTT representation of the polynomial.
+func nttOf(p poly) poly {
+	p.ntt()
+	return p
+}
+
+// add returns the sum of the polynomials.
+func add(a, b poly) poly {
+	for i := range a {
+		a[i] = fieldAdd(a[i], b[i])
+	}
+
+	return a
+}
+
+// sub returns the difference of the polynomials.
+func sub(a, b poly) poly {
+	for i := range a {
+		a[i] = fieldSub(a[i], b[i])
+	}
+
+	return a
+}
+
+// mulNTT returns the product of two polynomials in NTT representation.
+func mulNTT(a, b poly) poly {
+	for i := range a {
+		a[i] = fieldMul(a[i], b[i])
+	}
+
+	return a
+}
+
+// norm returns the infinity norm of the polynomial.
+func (p poly) norm() int32 {
+	max := int32(0)
+	for _, c := range p {
+		v := abs(centered(c))
+		if v > max {
+			max = v
+		}
+	}
+
+	return max
+}
+
+// mulMatrix returns the product of the matrix and the vector, both in NTT
+// representation, as a vector in normal representation.
+func mulMatrix(a *[k][l]poly, v *[l]poly) [k]poly {
+	var res [k]poly
+
+	for i := range res {
+		for j := range v {
+			res[i] = add(res[i], mulNTT(a[i][j], v[j]))
+		}
+
+		res[i].invNTT()
+	}
+
+	return res
+}
+
+// expandA returns the matrix sampled from the seed in NTT representation.
+func expandA(rho []byte) [k][l]poly {
+	var a [k][l]poly
+
+	for r := 0; r < k; r++ {
+		for s := 0; s < l; s++ {
+			a[r][s] = rejNTTPoly(append(append([]byte{}, rho...), byte(s), byte(r)))
+		}
+	}
+
+	return a
+}
+
+// expandS returns the secret vectors sampled from the seed.
+func expandS(rho []byte) ([l]poly, [k]poly) {
+	var s1 [l]poly
+	var s2 [k]poly
+
+	for r := range s1 {
+		s1[r] = rejBoundedPoly(append(append([]byte{}, rho...), byte(r), 0))
+	}
+
+	for r := range s2 {
+		s2[r] = rejBoundedPoly(append(append([]byte{}, rho...), byte(r+l), 0))
+	}
+
+	return s1, s2
+}
+
+// expandMask returns the masking vector for the given nonce.
+func expandMask(rho []byte, kappa int) [l]poly {
+	var y [l]poly
+
+	buf := make([]byte, n*gamma1Bits/8)
+
+	for r := range y {
+		nonce := kappa + r
+
+		h := newShake256()
+		h.Write(rho)
+		h.Write([]byte{byte(nonce), byte(nonce >> 8)})
+		h.Read(buf)
+
+		coeffs := unpackBits(buf, gamma1Bits)
+		for i, c := range coeffs {
+			y[r][i] = modQ(gamma1 - int64(c))
+		}
+	}
+
+	return y
+}
+
+// rejNTTPoly samples a polynomial in NTT representation with uniform
+// coefficients.
+func rejNTTPoly(seed []byte) poly {
+	var p poly
+
+	h := newShake128()
+	h.Write(seed)
+
+	buf := make([]byte, shake128Rate)
+
+	for j := 0; j < n; {
+		h.Read(buf)
+
+		for i := 0; i+3 <= len(buf) && j < n; i += 3 {
+			z := uint32(buf[i]) | uint32(buf[i+1])<<8 | uint32(buf[i+2]&0x7f)<<16
+			if z < q {
+				p[j] = z
+				j++
+			}
+		}
+	}
+
+	return p
+}
+
+// rejBoundedPoly samples a polynomial with coefficients in [-eta, eta].
+func rejBoundedPoly(seed []byte) poly {
+	var p poly
+
+	h := newShake256()
+	h.Write(seed)
+
+	buf := make([]byte, shake256Rate)
+
+	for j := 0; j < n; {
+		h.Read(buf)
+
+		for i := 0; i < len(buf) && j < n; i++ {
+			z0 := buf[i] & 0x0f
+			z1 := buf[i] >> 4
+
+			if z0 < 2*eta+1 {
+				p[j] = modQ(eta - int64(z0))
+				j++
+			}
+
+			if z1 < 2*eta+1 && j < n {
+				p[j] = modQ(eta - int64(z1))
+				j++
+			}
+		}
+	}
+
+	return p
+}
+
+// sampleInBall returns the challenge polynomial with tau coefficients in
+// {-1, 1} and the others set to zero.
+func sampleInBall(seed []byte) poly {
+	var c poly
+
+	h := newShake256()
+	h.Write(seed)
+
+	var s [8]byte
+	h.Read(s[:])
+
+	signs := uint64(0)
+	for i := len(s) - 1; i >= 0; i-- {
+		signs = signs<<8 | uint64(s[i])
+	}
+
+	var b [1]byte
+
+	for i := n - tau; i < n; i++ {
+		h.Read(b[:])
+		for int(b[0]) > i {
+			h.Read(b[:])
+		}
+
+		j := b[0]
+
+		c[i] = c[j]
+		if signs&1 == 1 {
+			c[j] = q - 1
+		} else {
+			c[j] = 1
+		}
+
+		signs >>= 1
+	}
+
+	return c
+}
+
+// shake256 returns the output of the given size of SHAKE256 for the inputs.
+func shake256(size int, inputs ...[]byte) []byte {
+	h := newShake256()
+	for _, in := range inputs {
+		h.Write(in)
+	}
+
+	out := make([]byte, size)
+	h.Read(out)
+
+	return out
+}
+
+func newShake128() sha3.ShakeHash {
+	return sha3.NewShake128()
+}
+
+func newShake256() sha3.ShakeHash {
+	return sha3.NewShake256()
+}
+
+// packBits packs the values using the given number of bits per value in
+// little-endian order.
+func packBits(values []uint32, bits int) []byte {
+	out := make([]byte, 0, len(values)*bits/8)
+
+	acc := uint64(0)
+	size := 0
+
+	for _, v := range values {
+		acc |= uint64(v) << size
+		size += bits
+
+		for size >= 8 {
+			out = append(out, byte(acc))
+			acc >>= 8
+			size -= 8
+		}
+	}
+
+	return out
+}
+
+// unpackBits reads the values of the given number of bits from the buffer.
+func unpackBits(data []byte, bits int) []uint32 {
+	out := make([]uint32, 0, len(data)*8/bits)
+	mask := uint64(1)<<bits - 1
+
+	acc := uint64(0)
+	size := 0
+
+	for _, b := range data {
+		acc |= uint64(b) << size
+		size += 8
+
+		for size >= bits {
+			out = append(out, uint32(acc&mask))
+			acc >>= bits
+			size -= bits
+		}
+	}
+
+	return out
+}
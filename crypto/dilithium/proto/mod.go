@@ -0,0 +1,89 @@
+package proto
+
+import (
+	"go.dedis.ch/dela/crypto/common/proto"
+	"go.dedis.ch/dela/crypto/dilithium"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	dilithium.RegisterPublicKeyFormat(serde.FormatProtobuf, pubkeyFormat{})
+	dilithium.RegisterSignatureFormat(serde.FormatProtobuf, sigFormat{})
+}
+
+type pubkeyFormat struct{}
+
+func (f pubkeyFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	pubkey, ok := msg.(dilithium.PublicKey)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message of type '%T'", msg)
+	}
+
+	buffer, err := pubkey.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal key: %v", err)
+	}
+
+	m := &proto.PublicKey{
+		Name: dilithium.Algorithm,
+		Data: buffer,
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+func (f pubkeyFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := &proto.PublicKey{}
+	err := ctx.Unmarshal(data, m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal public key: %v", err)
+	}
+
+	pubkey, err := dilithium.NewPublicKey(m.Data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't create public key: %v", err)
+	}
+
+	return pubkey, nil
+}
+
+type sigFormat struct{}
+
+func (f sigFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	signature, ok := msg.(dilithium.Signature)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message of type '%T'", msg)
+	}
+
+	data, _ := signature.MarshalBinary()
+
+	m := &proto.Signature{
+		Name: dilithium.Algorithm,
+		Data: data,
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+func (f sigFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := &proto.Signature{}
+	err := ctx.Unmarshal(data, m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal signature: %v", err)
+	}
+
+	signature := dilithium.NewSignature(m.Data)
+
+	return signature, nil
+}
@@ -0,0 +1,91 @@
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto/dilithium"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestPubkeyFormat_Encode(t *testing.T) {
+	format := pubkeyFormat{}
+	signer := dilithium.NewSigner()
+
+	msg := signer.GetPublicKey()
+
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	data, err := format.Encode(ctx, msg)
+	require.NoError(t, err)
+	require.Regexp(t, `{"Name":"ML-DSA-65","Data":"[^"]+"}`, string(data))
+
+	_, err = format.Encode(fake.NewBadContext(), msg)
+	require.EqualError(t, err, fake.Err("couldn't marshal"))
+
+	_, err = format.Encode(ctx, dilithium.PublicKey{})
+	require.EqualError(t, err, "couldn't marshal key: public key is empty")
+
+	_, err = format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message of type 'fake.Message'")
+}
+
+func TestPubkeyFormat_Decode(t *testing.T) {
+	format := pubkeyFormat{}
+	signer := dilithium.NewSigner()
+
+	ctx := fake.NewContextWithFormat(serde.FormatProtobuf)
+
+	data, err := signer.GetPublicKey().Serialize(ctx)
+	require.NoError(t, err)
+
+	pubkey, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+	require.True(t, signer.GetPublicKey().Equal(pubkey.(dilithium.PublicKey)))
+
+	_, err = format.Decode(ctx, []byte(`{"Data":""}`))
+	require.EqualError(t, err,
+		"couldn't create public key: couldn't decode key: invalid public key size 0 != 1952")
+
+	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("couldn't unmarshal public key"))
+}
+
+func TestSigFormat_Encode(t *testing.T) {
+	format := sigFormat{}
+	ctx := fake.NewContext()
+
+	signer := dilithium.NewSigner()
+	sig, err := signer.Sign([]byte("hello"))
+	require.NoError(t, err)
+
+	data, err := format.Encode(ctx, sig)
+	require.NoError(t, err)
+	require.Regexp(t, `{"Name":"ML-DSA-65","Data":"[^"]+"}`, string(data))
+
+	_, err = format.Encode(fake.NewBadContext(), sig)
+	require.EqualError(t, err, fake.Err("couldn't marshal"))
+
+	_, err = format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message of type 'fake.Message'")
+}
+
+func TestSigFormat_Decode(t *testing.T) {
+	format := sigFormat{}
+	ctx := fake.NewContextWithFormat(serde.FormatProtobuf)
+
+	signer := dilithium.NewSigner()
+	sig, err := signer.Sign([]byte("hello"))
+	require.NoError(t, err)
+
+	data, err := sig.Serialize(ctx)
+	require.NoError(t, err)
+
+	msg, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+	require.True(t, sig.Equal(msg.(dilithium.Signature)))
+
+	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("couldn't unmarshal signature"))
+}
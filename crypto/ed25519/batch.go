@@ -0,0 +1,234 @@
+// This file contains the implementation of the batch verification of the
+// Schnorr signatures.
+//
+// The batch checks a random linear combination of the verification equations
+// of the signatures, sum(z_i * R_i) + sum(z_i * h_i * A_i) - sum(z_i * s_i) * B
+// = 0, with a single multi-scalar multiplication instead of two scalar
+// multiplications per signature.
+
+package ed25519
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/kyber/v3"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// coefficientSize is the size in bytes of the random coefficients of the
+	// linear combination, which bounds the probability that an invalid batch
+	// is accepted to 2^-128.
+	coefficientSize = 16
+
+	// windowSize is the number of bits of the scalars processed at each step
+	// of the multi-scalar multiplication. It must divide a byte.
+	windowSize = 4
+)
+
+// batchEntry is a signature waiting to be verified in a batch.
+type batchEntry struct {
+	pubkey PublicKey
+	msg    []byte
+	sig    Signature
+}
+
+// BatchVerifier is a verifier of many Schnorr signatures in a single
+// operation.
+//
+// The coefficients of the combination are derived from the content of the
+// batch so that every participant that verifies the same batch comes to the
+// same decision, which matters when the batch is part of a block.
+//
+// - implements crypto.BatchVerifier
+type BatchVerifier struct {
+	entries []batchEntry
+}
+
+// NewBatchVerifier returns a new empty batch.
+func NewBatchVerifier() *BatchVerifier {
+	return &BatchVerifier{}
+}
+
+// Add implements crypto.BatchVerifier. It appends the signature of the message
+// by the public key to the batch. It returns an error if the key or the
+// signature is not an Ed25519 one.
+func (b *BatchVerifier) Add(pubkey crypto.PublicKey, msg []byte, sig crypto.Signature) error {
+	pk, ok := pubkey.(PublicKey)
+	if !ok {
+		return xerrors.Errorf("invalid public key type '%T'", pubkey)
+	}
+
+	signature, ok := sig.(Signature)
+	if !ok {
+		return xerrors.Errorf("invalid signature type '%T'", sig)
+	}
+
+	b.entries = append(b.entries, batchEntry{
+		pubkey: pk,
+		msg:    msg,
+		sig:    signature,
+	})
+
+	return nil
+}
+
+// Len implements crypto.BatchVerifier. It returns the number of signatures in
+// the batch.
+func (b *BatchVerifier) Len() int {
+	return len(b.entries)
+}
+
+// Verify implements crypto.BatchVerifier. It returns nil if every signature of
+// the batch is valid. Otherwise, the signatures are verified one by one so
+// that the error tells the first invalid one.
+func (b *BatchVerifier) Verify() error {
+	if len(b.entries) == 0 {
+		return nil
+	}
+
+	err := b.verifyBatch()
+	if err == nil {
+		return nil
+	}
+
+	for i, entry := range b.entries {
+		err := entry.pubkey.Verify(entry.msg, entry.sig)
+		if err != nil {
+			return xerrors.Errorf("signature %d: %v", i, err)
+		}
+	}
+
+	return xerrors.Errorf("batch failed: %v", err)
+}
+
+func (b *BatchVerifier) verifyBatch() error {
+	pointSize := suite.PointLen()
+
+	seed := sha512.New()
+	commits := make([]kyber.Point, len(b.entries))
+	hashes := make([]kyber.Scalar, len(b.entries))
+	responses := make([]kyber.Scalar, len(b.entries))
+
+	for i, entry := range b.entries {
+		err := entry.sig.CheckCanonical()
+		if err != nil {
+			return xerrors.Errorf("signature %d: %v", i, err)
+		}
+
+		pubkey, err := entry.pubkey.MarshalBinary()
+		if err != nil {
+			return xerrors.Errorf("couldn't marshal public key: %v", err)
+		}
+
+		p, ok := entry.pubkey.point.(canonicalPoint)
+		if ok && p.HasSmallOrder() {
+			return xerrors.Errorf("public key %d has a small order", i)
+		}
+
+		commits[i] = suite.Point()
+		err = commits[i].UnmarshalBinary(entry.sig.data[:pointSize])
+		if err != nil {
+			return xerrors.Errorf("couldn't unmarshal commit: %v", err)
+		}
+
+		responses[i] = suite.Scalar()
+		err = responses[i].UnmarshalBinary(entry.sig.data[pointSize:])
+		if err != nil {
+			return xerrors.Errorf("couldn't unmarshal response: %v", err)
+		}
+
+		// The challenge is the same as in the individual verification, which
+		// is H(R || A || msg).
+		h := sha512.New()
+		h.Write(entry.sig.data[:pointSize])
+		h.Write(pubkey)
+		h.Write(entry.msg)
+		hashes[i] = suite.Scalar().SetBytes(h.Sum(nil))
+
+		// The seed of the coefficients commits to the whole batch.
+		seed.Write(entry.sig.data)
+		seed.Write(pubkey)
+		seed.Write(h.Sum(nil))
+	}
+
+	digest := seed.Sum(nil)
+
+	points := make([]kyber.Point, 0, 2*len(b.entries))
+	scalars := make([]kyber.Scalar, 0, 2*len(b.entries))
+	base := suite.Scalar().Zero()
+
+	for i, entry := range b.entries {
+		z := coefficient(digest, i)
+
+		base = base.Add(base, suite.Scalar().Mul(z, responses[i]))
+
+		points = append(points, commits[i], entry.pubkey.point)
+		scalars = append(scalars, z, suite.Scalar().Mul(z, hashes[i]))
+	}
+
+	sum := multiScalarMul(points, scalars)
+	sum = sum.Sub(sum, suite.Point().Mul(base, nil))
+
+	if !sum.Equal(suite.Point().Null()) {
+		return xerrors.New("invalid batch")
+	}
+
+	return nil
+}
+
+// coefficient returns the coefficient of the linear combination for the
+// signature at the index.
+func coefficient(seed []byte, index int) kyber.Scalar {
+	buffer := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buffer, uint64(index))
+
+	h := sha512.New()
+	h.Write(seed)
+	h.Write(buffer)
+
+	return suite.Scalar().SetBytes(h.Sum(nil)[:coefficientSize])
+}
+
+// multiScalarMul returns the sum of the points multiplied by their scalar. It
+// uses the interleaved windows of Straus so that the doublings are shared by
+// all the points.
+func multiScalarMul(points []kyber.Point, scalars []kyber.Scalar) kyber.Point {
+	tables := make([][]kyber.Point, len(points))
+	digits := make([][]byte, len(points))
+
+	for i, point := range points {
+		table := make([]kyber.Point, 1<<windowSize)
+		table[0] = suite.Point().Null()
+		for k := 1; k < len(table); k++ {
+			table[k] = suite.Point().Add(table[k-1], point)
+		}
+
+		tables[i] = table
+
+		// The scalars are encoded in little-endian order.
+		data, _ := scalars[i].MarshalBinary()
+		digits[i] = data
+	}
+
+	numWindows := suite.ScalarLen() * 8 / windowSize
+
+	acc := suite.Point().Null()
+	for w := numWindows - 1; w >= 0; w-- {
+		for k := 0; k < windowSize; k++ {
+			acc = acc.Add(acc, acc)
+		}
+
+		for i, table := range tables {
+			bit := w * windowSize
+			digit := (digits[i][bit/8] >> uint(bit%8)) & (1<<windowSize - 1)
+			if digit != 0 {
+				acc = acc.Add(acc, table[digit])
+			}
+		}
+	}
+
+	return acc
+}
@@ -0,0 +1,116 @@
+package ed25519
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/kyber/v3"
+)
+
+func TestBatchVerifier_Add(t *testing.T) {
+	batch := NewBatchVerifier()
+	signer := NewSigner()
+
+	sig, err := signer.Sign([]byte("deadbeef"))
+	require.NoError(t, err)
+
+	err = batch.Add(signer.GetPublicKey(), []byte("deadbeef"), sig)
+	require.NoError(t, err)
+	require.Equal(t, 1, batch.Len())
+
+	err = batch.Add(fake.PublicKey{}, nil, sig)
+	require.EqualError(t, err, "invalid public key type 'fake.PublicKey'")
+
+	err = batch.Add(signer.GetPublicKey(), nil, fake.Signature{})
+	require.EqualError(t, err, "invalid signature type 'fake.Signature'")
+}
+
+func TestBatchVerifier_Verify(t *testing.T) {
+	batch := NewBatchVerifier()
+	require.NoError(t, batch.Verify())
+
+	signers := []Signer{NewSigner().(Signer), NewSigner().(Signer)}
+
+	for i := 0; i < 10; i++ {
+		msg := []byte(fmt.Sprintf("message %d", i))
+
+		// The same signer signs several messages of the batch.
+		signer := signers[i%len(signers)]
+
+		sig, err := signer.Sign(msg)
+		require.NoError(t, err)
+
+		require.NoError(t, batch.Add(signer.GetPublicKey(), msg, sig))
+	}
+
+	require.NoError(t, batch.Verify())
+
+	// The verification is deterministic.
+	require.NoError(t, batch.Verify())
+}
+
+func TestBatchVerifier_InvalidSignature_Verify(t *testing.T) {
+	batch := NewBatchVerifier()
+	signer := NewSigner()
+
+	for i := 0; i < 3; i++ {
+		sig, err := signer.Sign([]byte("deadbeef"))
+		require.NoError(t, err)
+
+		require.NoError(t, batch.Add(signer.GetPublicKey(), []byte("deadbeef"), sig))
+	}
+
+	// The signature of another message is valid in form but not for the
+	// message of the batch.
+	sig, err := signer.Sign([]byte("abc"))
+	require.NoError(t, err)
+
+	batch.entries[1].sig = sig.(Signature)
+
+	err = batch.Verify()
+	require.EqualError(t, err, "signature 1: schnorr verify failed: schnorr: invalid signature")
+
+	batch.entries[1].sig = Signature{data: []byte{1, 2, 3}}
+
+	err = batch.Verify()
+	require.EqualError(t, err,
+		"signature 1: schnorr verify failed: schnorr: signature of invalid length 3 instead of 64")
+}
+
+func TestBatchVerifier_SwappedSignatures_Verify(t *testing.T) {
+	batch := NewBatchVerifier()
+	signer := NewSigner()
+
+	sigA, err := signer.Sign([]byte("A"))
+	require.NoError(t, err)
+
+	sigB, err := signer.Sign([]byte("B"))
+	require.NoError(t, err)
+
+	require.NoError(t, batch.Add(signer.GetPublicKey(), []byte("A"), sigB))
+	require.NoError(t, batch.Add(signer.GetPublicKey(), []byte("B"), sigA))
+
+	err = batch.Verify()
+	require.EqualError(t, err, "signature 0: schnorr verify failed: schnorr: invalid signature")
+}
+
+func TestMultiScalarMul(t *testing.T) {
+	sum := multiScalarMul(nil, nil)
+	require.True(t, sum.Equal(suite.Point().Null()))
+
+	points := make([]kyber.Point, 5)
+	scalars := make([]kyber.Scalar, 5)
+	expected := suite.Point().Null()
+
+	for i := range points {
+		points[i] = suite.Point().Pick(suite.RandomStream())
+		scalars[i] = suite.Scalar().Pick(suite.RandomStream())
+
+		expected = expected.Add(expected, suite.Point().Mul(scalars[i], points[i]))
+	}
+
+	sum = multiScalarMul(points, scalars)
+	require.True(t, sum.Equal(expected))
+}
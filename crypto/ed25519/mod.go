@@ -167,6 +167,40 @@ func NewSignature(data []byte) Signature {
 	}
 }
 
+// CheckCanonical returns nil if the signature is made of the canonical
+// encodings of a point that is not of small order and of a scalar reduced
+// modulo the group order, otherwise an error.
+func (sig Signature) CheckCanonical() error {
+	pointSize := suite.PointLen()
+	size := pointSize + suite.ScalarLen()
+
+	if len(sig.data) != size {
+		return xerrors.Errorf("invalid signature length %d != %d", len(sig.data), size)
+	}
+
+	point := suite.Point()
+	err := point.UnmarshalBinary(sig.data[:pointSize])
+	if err != nil {
+		return xerrors.Errorf("couldn't unmarshal point: %v", err)
+	}
+
+	p, ok := point.(canonicalPoint)
+	if ok && !p.IsCanonical(sig.data[:pointSize]) {
+		return xerrors.New("point is not canonical")
+	}
+
+	if ok && p.HasSmallOrder() {
+		return xerrors.New("point has a small order")
+	}
+
+	scalar, ok := suite.Scalar().(canonicalScalar)
+	if ok && !scalar.IsCanonical(sig.data[pointSize:]) {
+		return xerrors.New("scalar is not canonical")
+	}
+
+	return nil
+}
+
 // MarshalBinary implements encoding.BinaryMarshaler. It returns a slice of
 // bytes representing the signature.
 func (sig Signature) MarshalBinary() ([]byte, error) {
@@ -277,6 +311,11 @@ func (f signatureFactory) SignatureOf(ctx serde.Context, data []byte) (crypto.Si
 		return nil, xerrors.Errorf("invalid signature of type '%T'", msg)
 	}
 
+	err = signature.CheckCanonical()
+	if err != nil {
+		return nil, xerrors.Errorf("invalid signature: %v", err)
+	}
+
 	return signature, nil
 }
 
@@ -329,3 +368,25 @@ func (s Signer) Sign(msg []byte) (crypto.Signature, error) {
 
 	return Signature{data: sig}, nil
 }
+
+// Rotate implements crypto.RotatableSigner. It replaces the key pair of the
+// signer by a new random one and returns the new public key. The copies of the
+// signer keep the previous key pair.
+func (s *Signer) Rotate() (crypto.PublicKey, error) {
+	s.keyPair = key.NewKeyPair(suite)
+
+	return s.GetPublicKey(), nil
+}
+
+// canonicalPoint is the interface implemented by the Ed25519 points of Kyber to
+// detect malleable encodings.
+type canonicalPoint interface {
+	IsCanonical(data []byte) bool
+	HasSmallOrder() bool
+}
+
+// canonicalScalar is the interface implemented by the Ed25519 scalars of Kyber
+// to detect malleable encodings.
+type canonicalScalar interface {
+	IsCanonical(data []byte) bool
+}
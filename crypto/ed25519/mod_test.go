@@ -1,6 +1,7 @@
 package ed25519
 
 import (
+	"bytes"
 	"testing"
 	"testing/quick"
 
@@ -16,9 +17,10 @@ func init() {
 	RegisterPublicKeyFormat(fake.BadFormat, fake.NewBadFormat())
 	RegisterPublicKeyFormat("BAD_POINT", fake.Format{Msg: fake.Message{}})
 
-	RegisterSignatureFormat(fake.GoodFormat, fake.Format{Msg: Signature{}})
+	RegisterSignatureFormat(fake.GoodFormat, fake.Format{Msg: testSignature})
 	RegisterSignatureFormat(fake.BadFormat, fake.NewBadFormat())
 	RegisterSignatureFormat("BAD_SIG", fake.Format{Msg: fake.Message{}})
+	RegisterSignatureFormat("NON_CANONICAL", fake.Format{Msg: Signature{}})
 }
 
 func TestPublicKey_New(t *testing.T) {
@@ -137,6 +139,35 @@ func TestSignature_New(t *testing.T) {
 	require.Equal(t, data, sig.data)
 }
 
+func TestSignature_CheckCanonical(t *testing.T) {
+	require.NoError(t, testSignature.CheckCanonical())
+
+	err := NewSignature(nil).CheckCanonical()
+	require.EqualError(t, err, "invalid signature length 0 != 64")
+
+	data := make([]byte, 64)
+	data[0] = 2
+	err = NewSignature(data).CheckCanonical()
+	require.EqualError(t, err, "couldn't unmarshal point: invalid Ed25519 curve point")
+
+	// The encoding of p+1 is the non-canonical encoding of the identity.
+	data = append([]byte{0xee}, bytes.Repeat([]byte{0xff}, 30)...)
+	data = append(data, 0x7f)
+	data = append(data, make([]byte, 32)...)
+	err = NewSignature(data).CheckCanonical()
+	require.EqualError(t, err, "point is not canonical")
+
+	data = make([]byte, 64)
+	data[0] = 1
+	err = NewSignature(data).CheckCanonical()
+	require.EqualError(t, err, "point has a small order")
+
+	data = append([]byte{}, testSignature.data...)
+	copy(data[32:], bytes.Repeat([]byte{0xff}, 32))
+	err = NewSignature(data).CheckCanonical()
+	require.EqualError(t, err, "scalar is not canonical")
+}
+
 func TestSignature_MarshalBinary(t *testing.T) {
 	data := []byte("hello")
 	sig := NewSignature(data)
@@ -250,6 +281,10 @@ func TestSignatureFactory_SignatureOf(t *testing.T) {
 	ctx = fake.NewContextWithFormat("BAD_SIG")
 	_, err = sf.SignatureOf(ctx, nil)
 	require.EqualError(t, err, "invalid signature of type 'fake.Message'")
+
+	ctx = fake.NewContextWithFormat("NON_CANONICAL")
+	_, err = sf.SignatureOf(ctx, nil)
+	require.EqualError(t, err, "invalid signature: invalid signature length 0 != 64")
 }
 
 func TestSigner_New(t *testing.T) {
@@ -287,6 +322,21 @@ func TestSigner_GetPrivateKey(t *testing.T) {
 	require.True(t, secret.Equal(kp.Private))
 }
 
+func TestSigner_Rotate(t *testing.T) {
+	signer := NewSigner().(Signer)
+	previous := signer
+
+	pubkey, err := signer.Rotate()
+	require.NoError(t, err)
+	require.Equal(t, signer.GetPublicKey(), pubkey)
+	require.False(t, pubkey.Equal(previous.GetPublicKey()))
+
+	sig, err := signer.Sign([]byte("deadbeef"))
+	require.NoError(t, err)
+	require.NoError(t, pubkey.Verify([]byte("deadbeef"), sig))
+	require.Error(t, previous.GetPublicKey().Verify([]byte("deadbeef"), sig))
+}
+
 func TestSigner_Sign(t *testing.T) {
 	kp := key.NewKeyPair(suite)
 	signer := Signer{keyPair: kp}
@@ -311,6 +361,17 @@ func TestSigner_Sign(t *testing.T) {
 // -----------------------------------------------------------------------------
 // Utility functions
 
+var testSignature = makeSignature()
+
+func makeSignature() Signature {
+	sig, err := NewSigner().Sign([]byte("deadbeef"))
+	if err != nil {
+		panic(err)
+	}
+
+	return sig.(Signature)
+}
+
 type badPoint struct {
 	kyber.Point
 }
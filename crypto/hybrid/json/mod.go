@@ -0,0 +1,95 @@
+package json
+
+import (
+	"go.dedis.ch/dela/crypto/common/json"
+	"go.dedis.ch/dela/crypto/hybrid"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	hybrid.RegisterPublicKeyFormat(serde.FormatJSON, pubkeyFormat{})
+	hybrid.RegisterSignatureFormat(serde.FormatJSON, sigFormat{})
+}
+
+type pubkeyFormat struct{}
+
+func (f pubkeyFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	pubkey, ok := msg.(hybrid.PublicKey)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message of type '%T'", msg)
+	}
+
+	buffer, err := pubkey.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal key: %v", err)
+	}
+
+	m := json.PublicKey{
+		Algorithm: json.Algorithm{Name: hybrid.Algorithm},
+		Data:      buffer,
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+func (f pubkeyFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := json.PublicKey{}
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal public key: %v", err)
+	}
+
+	pubkey, err := hybrid.NewPublicKey(m.Data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't create public key: %v", err)
+	}
+
+	return pubkey, nil
+}
+
+type sigFormat struct{}
+
+func (f sigFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	signature, ok := msg.(hybrid.Signature)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message of type '%T'", msg)
+	}
+
+	buffer, err := signature.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal signature: %v", err)
+	}
+
+	m := json.Signature{
+		Algorithm: json.Algorithm{Name: hybrid.Algorithm},
+		Data:      buffer,
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+func (f sigFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := json.Signature{}
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal signature: %v", err)
+	}
+
+	signature, err := hybrid.NewSignature(m.Data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't create signature: %v", err)
+	}
+
+	return signature, nil
+}
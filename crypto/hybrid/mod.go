@@ -0,0 +1,693 @@
+// Package hybrid implements a signature scheme that combines the classical BLS
+// signatures with the post-quantum ML-DSA signatures.
+//
+// Every message is signed by both schemes and a signature is only valid if
+// both parts are valid, so that a signature stays unforgeable as long as one
+// of the two schemes is not broken. The BLS parts are aggregated as usual
+// whereas the ML-DSA parts cannot be, so an aggregate carries one ML-DSA
+// signature per signer, identified by the digest of its public key.
+//
+// The scheme implements crypto.AggregateSigner and can therefore be used by
+// the collective signing to produce forward links that give a long-term
+// non-repudiation of the chain.
+//
+package hybrid
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/dilithium"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// Algorithm is the name of the signature scheme.
+	Algorithm = bls.Algorithm + "+" + dilithium.Algorithm
+
+	// keyIDSize is the size of the identifier of a post-quantum public key.
+	keyIDSize = sha256.Size
+
+	entrySize = keyIDSize + dilithium.SignatureSize
+)
+
+var (
+	pubkeyFormats = registry.NewSimpleRegistry()
+	sigFormats    = registry.NewSimpleRegistry()
+)
+
+// RegisterPublicKeyFormat registers the engine for the provided format.
+func RegisterPublicKeyFormat(c serde.Format, f serde.FormatEngine) {
+	pubkeyFormats.Register(c, f)
+}
+
+// RegisterSignatureFormat registers the engine for the provided format.
+func RegisterSignatureFormat(c serde.Format, f serde.FormatEngine) {
+	sigFormats.Register(c, f)
+}
+
+// keyID is the identifier of a post-quantum public key.
+type keyID [keyIDSize]byte
+
+// PublicKey is the combination of a BLS and an ML-DSA public key.
+//
+// - implements crypto.PublicKey
+type PublicKey struct {
+	classical bls.PublicKey
+	pq        dilithium.PublicKey
+}
+
+// NewPublicKey creates a new public key from the binary representation of the
+// BLS key followed by the ML-DSA key.
+func NewPublicKey(data []byte) (PublicKey, error) {
+	if len(data) <= dilithium.PublicKeySize {
+		return PublicKey{}, xerrors.Errorf("data is too short: %d", len(data))
+	}
+
+	split := len(data) - dilithium.PublicKeySize
+
+	classical, err := bls.NewPublicKey(data[:split])
+	if err != nil {
+		return PublicKey{}, xerrors.Errorf("classical key: %v", err)
+	}
+
+	pq, err := dilithium.NewPublicKey(data[split:])
+	if err != nil {
+		return PublicKey{}, xerrors.Errorf("post-quantum key: %v", err)
+	}
+
+	pubkey := PublicKey{
+		classical: classical,
+		pq:        pq,
+	}
+
+	return pubkey, nil
+}
+
+// GetClassical returns the BLS public key.
+func (pk PublicKey) GetClassical() crypto.PublicKey {
+	return pk.classical
+}
+
+// GetPostQuantum returns the ML-DSA public key.
+func (pk PublicKey) GetPostQuantum() crypto.PublicKey {
+	return pk.pq
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns the BLS key
+// followed by the ML-DSA key.
+func (pk PublicKey) MarshalBinary() ([]byte, error) {
+	classical, err := pk.classical.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("classical key: %v", err)
+	}
+
+	pq, err := pk.pq.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("post-quantum key: %v", err)
+	}
+
+	return append(classical, pq...), nil
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// public key.
+func (pk PublicKey) Serialize(ctx serde.Context) ([]byte, error) {
+	format := pubkeyFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, pk)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode public key: %v", err)
+	}
+
+	return data, nil
+}
+
+// Verify implements crypto.PublicKey. It returns nil if the signature holds
+// both a valid BLS and a valid ML-DSA signature for the message.
+func (pk PublicKey) Verify(msg []byte, sig crypto.Signature) error {
+	verifier, err := verifierFactory{}.FromArray([]crypto.PublicKey{pk})
+	if err != nil {
+		return xerrors.Errorf("verifier: %v", err)
+	}
+
+	return verifier.Verify(msg, sig)
+}
+
+// Equal implements crypto.PublicKey. It returns true if both parts of the
+// other public key are the same.
+func (pk PublicKey) Equal(other interface{}) bool {
+	pubkey, ok := other.(PublicKey)
+	if !ok {
+		return false
+	}
+
+	return pk.classical.Equal(pubkey.classical) && pk.pq.Equal(pubkey.pq)
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns a text
+// representation of the public key.
+func (pk PublicKey) MarshalText() ([]byte, error) {
+	buffer, err := pk.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return []byte(fmt.Sprintf("hybrid:%x", buffer)), nil
+}
+
+// String implements fmt.Stringer. It returns a string representation of the
+// public key.
+func (pk PublicKey) String() string {
+	buffer, err := pk.MarshalText()
+	if err != nil {
+		return "hybrid:malformed_key"
+	}
+
+	// Output only the prefix and 16 characters of the buffer in hexadecimal.
+	return string(buffer)[:7+16]
+}
+
+func (pk PublicKey) getID() (keyID, error) {
+	var id keyID
+
+	data, err := pk.pq.MarshalBinary()
+	if err != nil {
+		return id, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	id = sha256.Sum256(data)
+
+	return id, nil
+}
+
+// entry is the post-quantum signature of one signer.
+type entry struct {
+	key keyID
+	sig dilithium.Signature
+}
+
+// Signature is the combination of a BLS signature, possibly aggregated, and
+// the ML-DSA signatures of every signer.
+//
+// - implements crypto.Signature
+type Signature struct {
+	classical crypto.Signature
+	entries   []entry
+}
+
+// NewSignature creates a new signature from its binary representation.
+func NewSignature(data []byte) (Signature, error) {
+	if len(data) < 2 {
+		return Signature{}, xerrors.New("data is too short")
+	}
+
+	size := int(binary.BigEndian.Uint16(data))
+	data = data[2:]
+
+	if len(data) < size || (len(data)-size)%entrySize != 0 {
+		return Signature{}, xerrors.Errorf("invalid length %d", len(data))
+	}
+
+	sig := Signature{
+		classical: bls.NewSignature(append([]byte{}, data[:size]...)),
+	}
+
+	for data = data[size:]; len(data) > 0; data = data[entrySize:] {
+		e := entry{
+			sig: dilithium.NewSignature(append([]byte{}, data[keyIDSize:entrySize]...)),
+		}
+
+		copy(e.key[:], data)
+
+		sig.entries = append(sig.entries, e)
+	}
+
+	return sig, nil
+}
+
+// GetClassical returns the BLS part of the signature.
+func (sig Signature) GetClassical() crypto.Signature {
+	return sig.classical
+}
+
+// Len returns the number of post-quantum signatures.
+func (sig Signature) Len() int {
+	return len(sig.entries)
+}
+
+// CheckCanonical returns nil if both parts of the signature are canonical and
+// the post-quantum signatures are sorted by public key, so that a signature has
+// a single encoding, otherwise an error.
+func (sig Signature) CheckCanonical() error {
+	classical, ok := sig.classical.(bls.Signature)
+	if !ok {
+		return xerrors.Errorf("invalid classical signature type '%T'", sig.classical)
+	}
+
+	err := classical.CheckCanonical()
+	if err != nil {
+		return xerrors.Errorf("classical signature: %v", err)
+	}
+
+	for i, e := range sig.entries {
+		if i > 0 && bytes.Compare(sig.entries[i-1].key[:], e.key[:]) >= 0 {
+			return xerrors.New("post-quantum signatures are not sorted")
+		}
+
+		err = e.sig.CheckCanonical()
+		if err != nil {
+			return xerrors.Errorf("post-quantum signature: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns the length of
+// the BLS signature, the BLS signature and then the ML-DSA signatures with the
+// identifiers of their public key.
+func (sig Signature) MarshalBinary() ([]byte, error) {
+	if sig.classical == nil {
+		return nil, xerrors.New("missing classical signature")
+	}
+
+	classical, err := sig.classical.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("classical signature: %v", err)
+	}
+
+	data := make([]byte, 2, 2+len(classical)+len(sig.entries)*entrySize)
+	binary.BigEndian.PutUint16(data, uint16(len(classical)))
+	data = append(data, classical...)
+
+	for _, e := range sig.entries {
+		pq, err := e.sig.MarshalBinary()
+		if err != nil {
+			return nil, xerrors.Errorf("post-quantum signature: %v", err)
+		}
+
+		data = append(data, e.key[:]...)
+		data = append(data, pq...)
+	}
+
+	return data, nil
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// signature.
+func (sig Signature) Serialize(ctx serde.Context) ([]byte, error) {
+	format := sigFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, sig)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode signature: %v", err)
+	}
+
+	return data, nil
+}
+
+// Equal implements crypto.Signature. It returns true if both signatures are the
+// same.
+func (sig Signature) Equal(other crypto.Signature) bool {
+	otherSig, ok := other.(Signature)
+	if !ok || len(sig.entries) != len(otherSig.entries) {
+		return false
+	}
+
+	if sig.classical == nil || otherSig.classical == nil {
+		if sig.classical != nil || otherSig.classical != nil {
+			return false
+		}
+	} else if !sig.classical.Equal(otherSig.classical) {
+		return false
+	}
+
+	for i, e := range sig.entries {
+		if e.key != otherSig.entries[i].key || !e.sig.Equal(otherSig.entries[i].sig) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String implements fmt.Stringer. It returns a string representation of the
+// signature.
+func (sig Signature) String() string {
+	return fmt.Sprintf("hybrid[%d]:%v", len(sig.entries), sig.classical)
+}
+
+// publicKeyFactory is a factory to deserialize hybrid public keys.
+//
+// - implements crypto.PublicKeyFactory
+type publicKeyFactory struct{}
+
+// NewPublicKeyFactory returns a new instance of the factory.
+func NewPublicKeyFactory() crypto.PublicKeyFactory {
+	return publicKeyFactory{}
+}
+
+// Deserialize implements serde.Factory. It returns the public key deserialized
+// if appropriate, otherwise an error.
+func (f publicKeyFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	return f.PublicKeyOf(ctx, data)
+}
+
+// PublicKeyOf implements crypto.PublicKeyFactory. It returns the public key
+// deserialized if appropriate, otherwise an error.
+func (f publicKeyFactory) PublicKeyOf(ctx serde.Context, data []byte) (crypto.PublicKey, error) {
+	format := pubkeyFormats.Get(ctx.GetFormat())
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't decode public key: %v", err)
+	}
+
+	pubkey, ok := msg.(PublicKey)
+	if !ok {
+		return nil, xerrors.Errorf("invalid public key of type '%T'", msg)
+	}
+
+	return pubkey, nil
+}
+
+// FromBytes implements crypto.PublicKeyFactory. It returns the public key
+// unmarshaled from the bytes.
+func (f publicKeyFactory) FromBytes(data []byte) (crypto.PublicKey, error) {
+	pubkey, err := NewPublicKey(data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal the key: %v", err)
+	}
+
+	return pubkey, nil
+}
+
+// signatureFactory is a factory to deserialize hybrid signatures.
+//
+// - implements crypto.SignatureFactory
+type signatureFactory struct{}
+
+// NewSignatureFactory returns a new instance of the factory.
+func NewSignatureFactory() crypto.SignatureFactory {
+	return signatureFactory{}
+}
+
+// Deserialize implements serde.Factory. It returns the signature deserialized
+// if appropriate, otherwise an error.
+func (f signatureFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	return f.SignatureOf(ctx, data)
+}
+
+// SignatureOf implements crypto.SignatureFactory. It returns the signature
+// deserialized if appropriate, otherwise an error.
+func (f signatureFactory) SignatureOf(ctx serde.Context, data []byte) (crypto.Signature, error) {
+	format := sigFormats.Get(ctx.GetFormat())
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't decode signature: %v", err)
+	}
+
+	sig, ok := msg.(Signature)
+	if !ok {
+		return nil, xerrors.Errorf("invalid signature of type '%T'", msg)
+	}
+
+	err = sig.CheckCanonical()
+	if err != nil {
+		return nil, xerrors.Errorf("invalid signature: %v", err)
+	}
+
+	return sig, nil
+}
+
+// hybridVerifier is a verifier that requires both the aggregated BLS signature
+// and the ML-DSA signature of every public key.
+//
+// - implements crypto.Verifier
+type hybridVerifier struct {
+	classical crypto.Verifier
+	keys      map[keyID]dilithium.PublicKey
+}
+
+// Verify implements crypto.Verifier. It returns nil if the signature is valid
+// for both schemes, otherwise an error.
+func (v hybridVerifier) Verify(msg []byte, s crypto.Signature) error {
+	sig, ok := s.(Signature)
+	if !ok {
+		return xerrors.Errorf("invalid signature type '%T'", s)
+	}
+
+	if sig.classical == nil {
+		return xerrors.New("missing classical signature")
+	}
+
+	err := v.classical.Verify(msg, sig.classical)
+	if err != nil {
+		return xerrors.Errorf("classical signature: %v", err)
+	}
+
+	if len(sig.entries) != len(v.keys) {
+		return xerrors.Errorf("expected %d post-quantum signatures but got %d",
+			len(v.keys), len(sig.entries))
+	}
+
+	seen := make(map[keyID]struct{}, len(sig.entries))
+
+	for _, e := range sig.entries {
+		pubkey, found := v.keys[e.key]
+		if !found {
+			return xerrors.Errorf("unknown post-quantum key %x", e.key[:8])
+		}
+
+		_, dup := seen[e.key]
+		if dup {
+			return xerrors.Errorf("duplicate post-quantum key %x", e.key[:8])
+		}
+
+		seen[e.key] = struct{}{}
+
+		err = pubkey.Verify(msg, e.sig)
+		if err != nil {
+			return xerrors.Errorf("post-quantum signature: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// verifierFactory is a factory to create hybrid verifiers.
+//
+// - implements crypto.VerifierFactory
+type verifierFactory struct{}
+
+// FromAuthority implements crypto.VerifierFactory. It returns a verifier that
+// requires the signatures of all the members of the authority.
+func (f verifierFactory) FromAuthority(ca crypto.CollectiveAuthority) (crypto.Verifier, error) {
+	if ca == nil {
+		return nil, xerrors.New("authority is nil")
+	}
+
+	pubkeys := make([]crypto.PublicKey, 0, ca.Len())
+
+	iter := ca.PublicKeyIterator()
+	for iter.HasNext() {
+		pubkeys = append(pubkeys, iter.GetNext())
+	}
+
+	return f.FromArray(pubkeys)
+}
+
+// FromArray implements crypto.VerifierFactory. It returns a verifier that
+// requires the signatures of all the public keys.
+func (f verifierFactory) FromArray(pubkeys []crypto.PublicKey) (crypto.Verifier, error) {
+	classicals := make([]crypto.PublicKey, len(pubkeys))
+	keys := make(map[keyID]dilithium.PublicKey, len(pubkeys))
+
+	for i, pubkey := range pubkeys {
+		pk, ok := pubkey.(PublicKey)
+		if !ok {
+			return nil, xerrors.Errorf("invalid public key type: %T", pubkey)
+		}
+
+		id, err := pk.getID()
+		if err != nil {
+			return nil, xerrors.Errorf("public key: %v", err)
+		}
+
+		classicals[i] = pk.classical
+		keys[id] = pk.pq
+	}
+
+	classical, err := bls.NewSigner().GetVerifierFactory().FromArray(classicals)
+	if err != nil {
+		return nil, xerrors.Errorf("classical verifier: %v", err)
+	}
+
+	verifier := hybridVerifier{
+		classical: classical,
+		keys:      keys,
+	}
+
+	return verifier, nil
+}
+
+// Signer is a signer that creates both a BLS and an ML-DSA signature for each
+// message.
+//
+// - implements crypto.AggregateSigner
+// - implements encoding.BinaryMarshaler
+type Signer struct {
+	classical bls.Signer
+	pq        dilithium.Signer
+}
+
+// NewSigner returns a new random signer.
+func NewSigner() Signer {
+	return Signer{
+		classical: bls.NewSigner(),
+		pq:        dilithium.NewSigner(),
+	}
+}
+
+// NewSignerFromBytes restores a signer from the binary representation of the
+// BLS signer followed by the seed of the ML-DSA signer.
+func NewSignerFromBytes(data []byte) (crypto.AggregateSigner, error) {
+	if len(data) <= dilithium.SeedSize {
+		return nil, xerrors.Errorf("data is too short: %d", len(data))
+	}
+
+	split := len(data) - dilithium.SeedSize
+
+	classical, err := bls.NewSignerFromBytes(data[:split])
+	if err != nil {
+		return nil, xerrors.Errorf("classical signer: %v", err)
+	}
+
+	pq, err := dilithium.NewSignerFromBytes(data[split:])
+	if err != nil {
+		return nil, xerrors.Errorf("post-quantum signer: %v", err)
+	}
+
+	signer := Signer{
+		classical: classical.(bls.Signer),
+		pq:        pq.(dilithium.Signer),
+	}
+
+	return signer, nil
+}
+
+// GetPublicKeyFactory implements crypto.Signer. It returns the public key
+// factory for hybrid signatures.
+func (s Signer) GetPublicKeyFactory() crypto.PublicKeyFactory {
+	return publicKeyFactory{}
+}
+
+// GetSignatureFactory implements crypto.Signer. It returns the signature
+// factory for hybrid signatures.
+func (s Signer) GetSignatureFactory() crypto.SignatureFactory {
+	return signatureFactory{}
+}
+
+// GetVerifierFactory implements crypto.AggregateSigner. It returns the
+// verifier factory for hybrid signatures.
+func (s Signer) GetVerifierFactory() crypto.VerifierFactory {
+	return verifierFactory{}
+}
+
+// GetPublicKey implements crypto.Signer. It returns the public key of the
+// signer that can be used to verify signatures.
+func (s Signer) GetPublicKey() crypto.PublicKey {
+	return PublicKey{
+		classical: s.classical.GetPublicKey().(bls.PublicKey),
+		pq:        s.pq.GetPublicKey().(dilithium.PublicKey),
+	}
+}
+
+// Sign implements crypto.Signer. It signs the message with both schemes.
+func (s Signer) Sign(msg []byte) (crypto.Signature, error) {
+	classical, err := s.classical.Sign(msg)
+	if err != nil {
+		return nil, xerrors.Errorf("classical signer: %v", err)
+	}
+
+	pq, err := s.pq.Sign(msg)
+	if err != nil {
+		return nil, xerrors.Errorf("post-quantum signer: %v", err)
+	}
+
+	id, err := s.GetPublicKey().(PublicKey).getID()
+	if err != nil {
+		return nil, xerrors.Errorf("public key: %v", err)
+	}
+
+	sig := Signature{
+		classical: classical,
+		entries:   []entry{{key: id, sig: pq.(dilithium.Signature)}},
+	}
+
+	return sig, nil
+}
+
+// Aggregate implements crypto.AggregateSigner. It aggregates the BLS parts of
+// the signatures and collects the ML-DSA ones.
+func (s Signer) Aggregate(signatures ...crypto.Signature) (crypto.Signature, error) {
+	return AggregateSignatures(signatures...)
+}
+
+// AggregateSignatures aggregates the signatures into a single one that can be
+// verified with the public keys of the signers. It does not require the
+// private key of a signer.
+func AggregateSignatures(signatures ...crypto.Signature) (crypto.Signature, error) {
+	classicals := make([]crypto.Signature, len(signatures))
+	agg := Signature{}
+
+	for i, sig := range signatures {
+		hsig, ok := sig.(Signature)
+		if !ok {
+			return nil, xerrors.Errorf("invalid signature type '%T'", sig)
+		}
+
+		classicals[i] = hsig.classical
+		agg.entries = append(agg.entries, hsig.entries...)
+	}
+
+	classical, err := bls.AggregateSignatures(classicals...)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't aggregate: %v", err)
+	}
+
+	// The post-quantum signatures are sorted so that the aggregate does not
+	// depend on the order of the signatures.
+	sort.Slice(agg.entries, func(i, j int) bool {
+		return bytes.Compare(agg.entries[i].key[:], agg.entries[j].key[:]) < 0
+	})
+
+	agg.classical = classical
+
+	return agg, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns the BLS signer
+// followed by the seed of the ML-DSA signer.
+func (s Signer) MarshalBinary() ([]byte, error) {
+	classical, err := s.classical.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("classical signer: %v", err)
+	}
+
+	pq, err := s.pq.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("post-quantum signer: %v", err)
+	}
+
+	return append(classical, pq...), nil
+}
@@ -0,0 +1,354 @@
+package hybrid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/crypto/dilithium"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func init() {
+	RegisterPublicKeyFormat(fake.GoodFormat, fake.Format{Msg: PublicKey{}})
+	RegisterPublicKeyFormat(fake.BadFormat, fake.NewBadFormat())
+	RegisterPublicKeyFormat("BAD_KEY", fake.Format{Msg: fake.Message{}})
+
+	RegisterSignatureFormat(fake.GoodFormat, fake.Format{Msg: testSignature})
+	RegisterSignatureFormat(fake.BadFormat, fake.NewBadFormat())
+	RegisterSignatureFormat("BAD_SIG", fake.Format{Msg: fake.Message{}})
+	RegisterSignatureFormat("NON_CANONICAL", fake.Format{Msg: Signature{}})
+}
+
+func TestPublicKey_New(t *testing.T) {
+	signer := NewSigner()
+
+	data, err := signer.GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+
+	pubkey, err := NewPublicKey(data)
+	require.NoError(t, err)
+	require.True(t, pubkey.Equal(signer.GetPublicKey()))
+	require.NotNil(t, pubkey.GetClassical())
+	require.NotNil(t, pubkey.GetPostQuantum())
+
+	_, err = NewPublicKey(data[:dilithium.PublicKeySize])
+	require.EqualError(t, err, "data is too short: 1952")
+
+	_, err = NewPublicKey(append([]byte{1}, data[len(data)-dilithium.PublicKeySize:]...))
+	require.Error(t, err)
+	require.Regexp(t, "^classical key: ", err.Error())
+}
+
+func TestPublicKey_Serialize(t *testing.T) {
+	pk := NewSigner().GetPublicKey()
+
+	data, err := pk.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = pk.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode public key"))
+}
+
+func TestPublicKey_Verify(t *testing.T) {
+	signer := NewSigner()
+
+	sig, err := signer.Sign([]byte("hello"))
+	require.NoError(t, err)
+
+	pk := signer.GetPublicKey()
+
+	err = pk.Verify([]byte("hello"), sig)
+	require.NoError(t, err)
+
+	err = pk.Verify([]byte("bye"), sig)
+	require.Error(t, err)
+	require.Regexp(t, "^classical signature: ", err.Error())
+
+	err = pk.Verify([]byte("hello"), fake.Signature{})
+	require.EqualError(t, err, "invalid signature type 'fake.Signature'")
+}
+
+func TestPublicKey_Equal(t *testing.T) {
+	signer := NewSigner()
+	pk := signer.GetPublicKey()
+
+	require.True(t, pk.Equal(pk))
+	require.False(t, pk.Equal(NewSigner().GetPublicKey()))
+	require.False(t, pk.Equal(fake.PublicKey{}))
+}
+
+func TestPublicKey_MarshalText(t *testing.T) {
+	pk := NewSigner().GetPublicKey()
+
+	text, err := pk.MarshalText()
+	require.NoError(t, err)
+	require.Regexp(t, "^hybrid:[0-9a-f]+$", string(text))
+}
+
+func TestPublicKey_String(t *testing.T) {
+	pk := NewSigner().GetPublicKey()
+
+	require.Regexp(t, "^hybrid:[0-9a-f]{16}$", pk.(PublicKey).String())
+}
+
+func TestPublicKeyFactory_PublicKeyOf(t *testing.T) {
+	factory := NewPublicKeyFactory()
+
+	msg, err := factory.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.IsType(t, PublicKey{}, msg)
+
+	_, err = factory.PublicKeyOf(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("couldn't decode public key"))
+
+	_, err = factory.PublicKeyOf(fake.NewContextWithFormat("BAD_KEY"), nil)
+	require.EqualError(t, err, "invalid public key of type 'fake.Message'")
+}
+
+func TestPublicKeyFactory_FromBytes(t *testing.T) {
+	factory := NewPublicKeyFactory()
+
+	pk := NewSigner().GetPublicKey()
+
+	data, err := pk.MarshalBinary()
+	require.NoError(t, err)
+
+	pubkey, err := factory.FromBytes(data)
+	require.NoError(t, err)
+	require.True(t, pk.Equal(pubkey))
+
+	_, err = factory.FromBytes(nil)
+	require.EqualError(t, err, "failed to unmarshal the key: data is too short: 0")
+}
+
+func TestSignature_New(t *testing.T) {
+	sig := makeAggregate(t, []byte("hello"), 2)
+
+	data, err := sig.MarshalBinary()
+	require.NoError(t, err)
+
+	res, err := NewSignature(data)
+	require.NoError(t, err)
+	require.True(t, res.Equal(sig))
+	require.Equal(t, 2, res.Len())
+	require.NotNil(t, res.GetClassical())
+
+	_, err = NewSignature(nil)
+	require.EqualError(t, err, "data is too short")
+
+	_, err = NewSignature(data[:len(data)-1])
+	require.Regexp(t, "^invalid length ", err.Error())
+
+	_, err = Signature{}.MarshalBinary()
+	require.EqualError(t, err, "missing classical signature")
+}
+
+func TestSignature_Serialize(t *testing.T) {
+	sig := Signature{}
+
+	data, err := sig.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = sig.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode signature"))
+}
+
+func TestSignature_Equal(t *testing.T) {
+	sig := makeAggregate(t, []byte("hello"), 2)
+
+	require.True(t, sig.Equal(sig))
+	require.True(t, Signature{}.Equal(Signature{}))
+	require.False(t, sig.Equal(Signature{}))
+	require.False(t, sig.Equal(Signature{classical: sig.classical}))
+	require.False(t, sig.Equal(makeAggregate(t, []byte("hello"), 2)))
+	require.False(t, sig.Equal(fake.Signature{}))
+}
+
+func TestSignature_String(t *testing.T) {
+	sig := Signature{classical: bls.NewSignature([]byte{0xaa})}
+
+	require.Equal(t, "hybrid[0]:bls:aa", sig.String())
+}
+
+func TestSignatureFactory_SignatureOf(t *testing.T) {
+	factory := NewSignatureFactory()
+
+	msg, err := factory.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.IsType(t, Signature{}, msg)
+
+	_, err = factory.SignatureOf(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("couldn't decode signature"))
+
+	_, err = factory.SignatureOf(fake.NewContextWithFormat("BAD_SIG"), nil)
+	require.EqualError(t, err, "invalid signature of type 'fake.Message'")
+
+	_, err = factory.SignatureOf(fake.NewContextWithFormat("NON_CANONICAL"), nil)
+	require.EqualError(t, err,
+		"invalid signature: invalid classical signature type '<nil>'")
+}
+
+func TestSignature_CheckCanonical(t *testing.T) {
+	agg := makeAggregate(t, []byte("hello"), 3)
+	require.NoError(t, agg.CheckCanonical())
+
+	unsorted := agg
+	unsorted.entries = []entry{agg.entries[1], agg.entries[0], agg.entries[2]}
+	err := unsorted.CheckCanonical()
+	require.EqualError(t, err, "post-quantum signatures are not sorted")
+
+	dup := agg
+	dup.entries = []entry{agg.entries[0], agg.entries[0]}
+	err = dup.CheckCanonical()
+	require.EqualError(t, err, "post-quantum signatures are not sorted")
+
+	bad := agg
+	bad.classical = bls.NewSignature(nil)
+	err = bad.CheckCanonical()
+	require.EqualError(t, err,
+		"classical signature: couldn't unmarshal point: bn256.G1: not enough data")
+
+	bad = agg
+	bad.entries = []entry{{sig: dilithium.NewSignature(nil)}}
+	err = bad.CheckCanonical()
+	require.EqualError(t, err,
+		"post-quantum signature: invalid signature size 0 != 3309")
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	msg := []byte("hello")
+
+	signers := []crypto.Signer{NewSigner(), NewSigner()}
+	pubkeys := []crypto.PublicKey{signers[0].GetPublicKey(), signers[1].GetPublicKey()}
+
+	sigs := make([]crypto.Signature, len(signers))
+	for i, signer := range signers {
+		sig, err := signer.Sign(msg)
+		require.NoError(t, err)
+
+		sigs[i] = sig
+	}
+
+	agg, err := AggregateSignatures(sigs...)
+	require.NoError(t, err)
+
+	verifier, err := verifierFactory{}.FromArray(pubkeys)
+	require.NoError(t, err)
+
+	err = verifier.Verify(msg, agg)
+	require.NoError(t, err)
+
+	err = verifier.Verify(msg, Signature{})
+	require.EqualError(t, err, "missing classical signature")
+
+	// Both parts are required even if the classical one is valid.
+	missing := agg.(Signature)
+	missing.entries = missing.entries[:1]
+
+	err = verifier.Verify(msg, missing)
+	require.EqualError(t, err, "expected 2 post-quantum signatures but got 1")
+
+	dup := agg.(Signature)
+	dup.entries = []entry{dup.entries[0], dup.entries[0]}
+
+	err = verifier.Verify(msg, dup)
+	require.Regexp(t, "^duplicate post-quantum key ", err.Error())
+
+	unknown := agg.(Signature)
+	unknown.entries = append([]entry{}, unknown.entries...)
+	unknown.entries[1].key = keyID{}
+
+	err = verifier.Verify(msg, unknown)
+	require.EqualError(t, err, "unknown post-quantum key 0000000000000000")
+
+	forged := agg.(Signature)
+	forged.entries = append([]entry{}, forged.entries...)
+	forged.entries[1].sig = forged.entries[0].sig
+
+	err = verifier.Verify(msg, forged)
+	require.Error(t, err)
+	require.Regexp(t, "^post-quantum signature: ", err.Error())
+}
+
+func TestVerifierFactory_FromAuthority(t *testing.T) {
+	factory := NewSigner().GetVerifierFactory()
+
+	verifier, err := factory.FromAuthority(fake.NewAuthority(2, generate))
+	require.NoError(t, err)
+	require.Len(t, verifier.(hybridVerifier).keys, 2)
+
+	_, err = factory.FromAuthority(nil)
+	require.EqualError(t, err, "authority is nil")
+
+	_, err = factory.FromAuthority(fake.NewAuthority(2, fake.NewSigner))
+	require.EqualError(t, err, "invalid public key type: fake.PublicKey")
+}
+
+func TestSigner_New(t *testing.T) {
+	signer := NewSigner()
+
+	data, err := signer.MarshalBinary()
+	require.NoError(t, err)
+
+	res, err := NewSignerFromBytes(data)
+	require.NoError(t, err)
+	require.True(t, res.GetPublicKey().Equal(signer.GetPublicKey()))
+	require.NotNil(t, res.GetPublicKeyFactory())
+	require.NotNil(t, res.GetSignatureFactory())
+
+	_, err = NewSignerFromBytes(data[:dilithium.SeedSize])
+	require.EqualError(t, err, "data is too short: 32")
+
+	_, err = NewSignerFromBytes(data[1:])
+	require.Error(t, err)
+	require.Regexp(t, "^classical signer: ", err.Error())
+}
+
+func TestSigner_Aggregate(t *testing.T) {
+	signer := NewSigner()
+
+	_, err := signer.Aggregate(fake.Signature{})
+	require.EqualError(t, err, "invalid signature type 'fake.Signature'")
+
+	_, err = signer.Aggregate(Signature{classical: fake.Signature{}})
+	require.EqualError(t, err,
+		"couldn't aggregate: invalid signature type 'fake.Signature'")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+var testSignature = makeSignature()
+
+func makeSignature() Signature {
+	sig, err := NewSigner().Sign([]byte("deadbeef"))
+	if err != nil {
+		panic(err)
+	}
+
+	return sig.(Signature)
+}
+
+func generate() crypto.Signer {
+	return NewSigner()
+}
+
+func makeAggregate(t *testing.T, msg []byte, n int) Signature {
+	sigs := make([]crypto.Signature, n)
+
+	for i := range sigs {
+		sig, err := NewSigner().Sign(msg)
+		require.NoError(t, err)
+
+		sigs[i] = sig
+	}
+
+	agg, err := AggregateSignatures(sigs...)
+	require.NoError(t, err)
+
+	return agg.(Signature)
+}
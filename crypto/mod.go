@@ -12,6 +12,13 @@
 // For the aggregation of those primitives, a verifier abstraction is defined to
 // provide the primitives to verify using the aggregate instead of a single one.
 //
+// A signer that supports the rotation of its key pair can replace it without
+// changing its identity in the protocols that refer to it.
+//
+// A signer can be wrapped to reserve its key for a single usage, like the
+// consensus or the transactions, so that the signing paths of the other
+// protocols refuse it.
+//
 // Documentation Last Review: 05.10.2020
 //
 package crypto
@@ -115,6 +122,22 @@ type VerifierFactory interface {
 	FromArray(keys []PublicKey) (Verifier, error)
 }
 
+// BatchVerifier provides the primitives to verify the signatures of several
+// messages in a single operation, which is faster than verifying them one by
+// one.
+type BatchVerifier interface {
+	// Add appends the signature of the message by the public key to the batch.
+	// It returns an error if the key or the signature is not supported.
+	Add(pubkey PublicKey, msg []byte, sig Signature) error
+
+	// Len returns the number of signatures in the batch.
+	Len() int
+
+	// Verify returns nil if every signature of the batch matches its message
+	// for its public key, otherwise an error.
+	Verify() error
+}
+
 // Signer provides the primitives to sign and verify signatures.
 type Signer interface {
 	// GetPublicKeyFactory returns a factory that can deserialize public keys of
@@ -133,6 +156,18 @@ type Signer interface {
 	Sign(msg []byte) (Signature, error)
 }
 
+// RotatableSigner offers the same primitives as the Signer interface but can
+// also replace its key pair. The participants only know the new public key
+// once it is announced, like with a replacement in the roster of a chain, so
+// the previous key should be kept until then.
+type RotatableSigner interface {
+	Signer
+
+	// Rotate generates a new key pair that the signer uses from now on, and
+	// returns the new public key.
+	Rotate() (PublicKey, error)
+}
+
 // AggregateSigner offers the same primitives as the Signer interface but
 // also includes a primitive to aggregate signatures into one.
 type AggregateSigner interface {
@@ -159,3 +194,17 @@ type CollectiveAuthority interface {
 	// list of public keys and is consistent with the address iterator.
 	PublicKeyIterator() PublicKeyIterator
 }
+
+// WeightedAuthority is a collective authority where each participant has voting
+// rights. An authority that does not implement it gives one vote to each
+// participant.
+type WeightedAuthority interface {
+	CollectiveAuthority
+
+	// GetWeight returns the voting rights of the participant at the given
+	// index, or zero if the index is out of range.
+	GetWeight(index int) uint64
+
+	// TotalWeight returns the sum of the voting rights of the participants.
+	TotalWeight() uint64
+}
@@ -0,0 +1,198 @@
+// This file contains the implementation of the key usages which prevent the
+// same key from signing the messages of different protocols.
+
+package crypto
+
+import "golang.org/x/xerrors"
+
+// KeyUsage is the role a key is reserved for.
+type KeyUsage int
+
+const (
+	// AnyUsage is the usage of a key that is not reserved for a role, which is
+	// accepted by every signing path.
+	AnyUsage KeyUsage = iota
+
+	// ConsensusUsage is the usage of a key that signs the messages of the
+	// consensus, like the collective signatures and the view changes.
+	ConsensusUsage
+
+	// TransportUsage is the usage of a key that bootstraps the secure channels
+	// between the participants.
+	TransportUsage
+
+	// TransactionUsage is the usage of a key that signs transactions.
+	TransactionUsage
+)
+
+// String implements fmt.Stringer. It returns a human readable name of the
+// usage.
+func (u KeyUsage) String() string {
+	switch u {
+	case AnyUsage:
+		return "any"
+	case ConsensusUsage:
+		return "consensus"
+	case TransportUsage:
+		return "transport"
+	case TransactionUsage:
+		return "transaction"
+	default:
+		return "unknown"
+	}
+}
+
+// UsageSigner is a signer whose key is reserved for a single usage.
+type UsageSigner interface {
+	Signer
+
+	// GetUsage returns the usage the key of the signer is reserved for.
+	GetUsage() KeyUsage
+}
+
+// reservedKey is a key, not necessarily a signer of this package, that is
+// reserved for a single usage.
+type reservedKey interface {
+	GetUsage() KeyUsage
+}
+
+// GetUsage returns the usage of the key if it is reserved, otherwise AnyUsage.
+// The key is usually a signer but it can be any private key that tells its
+// usage.
+func GetUsage(key interface{}) KeyUsage {
+	reserved, ok := key.(reservedKey)
+	if !ok {
+		return AnyUsage
+	}
+
+	return reserved.GetUsage()
+}
+
+// CheckUsage returns an error if the key is reserved for a usage different
+// from the expected one. A key without a usage is accepted.
+func CheckUsage(key interface{}, expected KeyUsage) error {
+	usage := GetUsage(key)
+
+	if usage != AnyUsage && usage != expected {
+		return xerrors.Errorf("key reserved for %v cannot be used for %v", usage, expected)
+	}
+
+	return nil
+}
+
+// ConsensusSigner is a signer whose key is reserved for the consensus. The
+// signer it wraps is not accessible so that the key cannot be used for another
+// usage.
+//
+// - implements crypto.AggregateSigner
+// - implements crypto.UsageSigner
+type ConsensusSigner struct {
+	reservedSigner
+}
+
+// NewConsensusSigner returns a signer that reserves the key for the consensus.
+// It returns an error if the key is already reserved for another usage.
+func NewConsensusSigner(signer AggregateSigner) (ConsensusSigner, error) {
+	err := CheckUsage(signer, ConsensusUsage)
+	if err != nil {
+		return ConsensusSigner{}, xerrors.Errorf("invalid signer: %v", err)
+	}
+
+	return ConsensusSigner{reservedSigner{signer: signer}}, nil
+}
+
+// GetUsage implements crypto.UsageSigner. It returns the consensus usage.
+func (ConsensusSigner) GetUsage() KeyUsage {
+	return ConsensusUsage
+}
+
+// GetVerifierFactory implements crypto.AggregateSigner. It returns the
+// verifier factory of the wrapped signer.
+func (s ConsensusSigner) GetVerifierFactory() VerifierFactory {
+	return s.signer.(AggregateSigner).GetVerifierFactory()
+}
+
+// Aggregate implements crypto.AggregateSigner. It aggregates the signatures
+// with the wrapped signer.
+func (s ConsensusSigner) Aggregate(signatures ...Signature) (Signature, error) {
+	return s.signer.(AggregateSigner).Aggregate(signatures...)
+}
+
+// TransportSigner is a signer whose key is reserved for the bootstrapping of
+// the secure channels. The signer it wraps is not accessible so that the key
+// cannot be used for another usage.
+//
+// - implements crypto.UsageSigner
+type TransportSigner struct {
+	reservedSigner
+}
+
+// NewTransportSigner returns a signer that reserves the key for the transport.
+// It returns an error if the key is already reserved for another usage.
+func NewTransportSigner(signer Signer) (TransportSigner, error) {
+	err := CheckUsage(signer, TransportUsage)
+	if err != nil {
+		return TransportSigner{}, xerrors.Errorf("invalid signer: %v", err)
+	}
+
+	return TransportSigner{reservedSigner{signer: signer}}, nil
+}
+
+// GetUsage implements crypto.UsageSigner. It returns the transport usage.
+func (TransportSigner) GetUsage() KeyUsage {
+	return TransportUsage
+}
+
+// TransactionSigner is a signer whose key is reserved for the transactions.
+// The signer it wraps is not accessible so that the key cannot be used for
+// another usage.
+//
+// - implements crypto.UsageSigner
+type TransactionSigner struct {
+	reservedSigner
+}
+
+// NewTransactionSigner returns a signer that reserves the key for the
+// transactions. It returns an error if the key is already reserved for another
+// usage.
+func NewTransactionSigner(signer Signer) (TransactionSigner, error) {
+	err := CheckUsage(signer, TransactionUsage)
+	if err != nil {
+		return TransactionSigner{}, xerrors.Errorf("invalid signer: %v", err)
+	}
+
+	return TransactionSigner{reservedSigner{signer: signer}}, nil
+}
+
+// GetUsage implements crypto.UsageSigner. It returns the transaction usage.
+func (TransactionSigner) GetUsage() KeyUsage {
+	return TransactionUsage
+}
+
+// reservedSigner forwards the primitives of a signer that it keeps unexported.
+type reservedSigner struct {
+	signer Signer
+}
+
+// GetPublicKeyFactory implements crypto.Signer. It returns the public key
+// factory of the wrapped signer.
+func (s reservedSigner) GetPublicKeyFactory() PublicKeyFactory {
+	return s.signer.GetPublicKeyFactory()
+}
+
+// GetSignatureFactory implements crypto.Signer. It returns the signature
+// factory of the wrapped signer.
+func (s reservedSigner) GetSignatureFactory() SignatureFactory {
+	return s.signer.GetSignatureFactory()
+}
+
+// GetPublicKey implements crypto.Signer. It returns the public key of the
+// wrapped signer.
+func (s reservedSigner) GetPublicKey() PublicKey {
+	return s.signer.GetPublicKey()
+}
+
+// Sign implements crypto.Signer. It signs the message with the wrapped signer.
+func (s reservedSigner) Sign(msg []byte) (Signature, error) {
+	return s.signer.Sign(msg)
+}
@@ -0,0 +1,137 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestKeyUsage_String(t *testing.T) {
+	require.Equal(t, "any", AnyUsage.String())
+	require.Equal(t, "consensus", ConsensusUsage.String())
+	require.Equal(t, "transport", TransportUsage.String())
+	require.Equal(t, "transaction", TransactionUsage.String())
+	require.Equal(t, "unknown", KeyUsage(42).String())
+}
+
+func TestGetUsage(t *testing.T) {
+	require.Equal(t, AnyUsage, GetUsage(fakeSigner{}))
+	require.Equal(t, ConsensusUsage, GetUsage(ConsensusSigner{}))
+	require.Equal(t, TransportUsage, GetUsage(TransportSigner{}))
+	require.Equal(t, TransactionUsage, GetUsage(TransactionSigner{}))
+}
+
+func TestCheckUsage(t *testing.T) {
+	require.NoError(t, CheckUsage(fakeSigner{}, ConsensusUsage))
+	require.NoError(t, CheckUsage(ConsensusSigner{}, ConsensusUsage))
+
+	err := CheckUsage(ConsensusSigner{}, TransactionUsage)
+	require.EqualError(t, err, "key reserved for consensus cannot be used for transaction")
+
+	err = CheckUsage(TransactionSigner{}, TransportUsage)
+	require.EqualError(t, err, "key reserved for transaction cannot be used for transport")
+}
+
+func TestConsensusSigner_New(t *testing.T) {
+	signer, err := NewConsensusSigner(fakeSigner{})
+	require.NoError(t, err)
+	require.Equal(t, fakeSigner{}, signer.signer)
+
+	// A signer can be reserved again for the same usage.
+	_, err = NewConsensusSigner(signer)
+	require.NoError(t, err)
+
+	_, err = NewConsensusSigner(fakeUsageSigner{usage: TransactionUsage})
+	require.EqualError(t, err,
+		"invalid signer: key reserved for transaction cannot be used for consensus")
+}
+
+func TestTransportSigner_New(t *testing.T) {
+	signer, err := NewTransportSigner(fakeSigner{})
+	require.NoError(t, err)
+	require.Equal(t, fakeSigner{}, signer.signer)
+
+	_, err = NewTransportSigner(ConsensusSigner{})
+	require.EqualError(t, err,
+		"invalid signer: key reserved for consensus cannot be used for transport")
+}
+
+func TestTransactionSigner_New(t *testing.T) {
+	signer, err := NewTransactionSigner(fakeSigner{})
+	require.NoError(t, err)
+	require.Equal(t, fakeSigner{}, signer.signer)
+
+	_, err = NewTransactionSigner(ConsensusSigner{})
+	require.EqualError(t, err,
+		"invalid signer: key reserved for consensus cannot be used for transaction")
+}
+
+func TestConsensusSigner_Forward(t *testing.T) {
+	signer, err := NewConsensusSigner(fakeSigner{err: xerrors.New("oops")})
+	require.NoError(t, err)
+
+	require.Nil(t, signer.GetPublicKeyFactory())
+	require.Nil(t, signer.GetSignatureFactory())
+	require.Nil(t, signer.GetVerifierFactory())
+	require.Nil(t, signer.GetPublicKey())
+
+	_, err = signer.Sign(nil)
+	require.EqualError(t, err, "oops")
+
+	_, err = signer.Aggregate()
+	require.EqualError(t, err, "oops")
+}
+
+func TestTransactionSigner_Forward(t *testing.T) {
+	signer, err := NewTransactionSigner(fakeSigner{err: xerrors.New("oops")})
+	require.NoError(t, err)
+
+	require.Nil(t, signer.GetPublicKeyFactory())
+	require.Nil(t, signer.GetSignatureFactory())
+	require.Nil(t, signer.GetPublicKey())
+
+	_, err = signer.Sign(nil)
+	require.EqualError(t, err, "oops")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeSigner struct {
+	AggregateSigner
+	err error
+}
+
+func (fakeSigner) GetPublicKeyFactory() PublicKeyFactory {
+	return nil
+}
+
+func (fakeSigner) GetSignatureFactory() SignatureFactory {
+	return nil
+}
+
+func (fakeSigner) GetVerifierFactory() VerifierFactory {
+	return nil
+}
+
+func (fakeSigner) GetPublicKey() PublicKey {
+	return nil
+}
+
+func (s fakeSigner) Sign([]byte) (Signature, error) {
+	return nil, s.err
+}
+
+func (s fakeSigner) Aggregate(...Signature) (Signature, error) {
+	return nil, s.err
+}
+
+type fakeUsageSigner struct {
+	fakeSigner
+	usage KeyUsage
+}
+
+func (s fakeUsageSigner) GetUsage() KeyUsage {
+	return s.usage
+}
@@ -0,0 +1,421 @@
+// Package vrf implements a verifiable random function over the Edwards 25519
+// elliptic curve.
+//
+// The construction is ECVRF-EDWARDS25519-SHA512-TAI as specified in RFC 9381.
+// A signer produces a proof for an input, and the proof gives a pseudo-random
+// output of 64 bytes. The output is unpredictable without the private key but
+// anyone knowing the public key can verify the proof and derive the same
+// output, which is unique for a given key and input.
+//
+// The private key is stored as the 32 bytes seed it is derived from, similarly
+// to an Ed25519 private key.
+//
+// Related Papers:
+//
+// RFC 9381: Verifiable Random Functions (VRFs) (2023)
+// https://doi.org/10.17487/RFC9381
+//
+package vrf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"io"
+
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// Algorithm is the name of the cipher suite of the function.
+	Algorithm = "ECVRF-EDWARDS25519-SHA512-TAI"
+
+	// SeedSize is the size in bytes of the seed of a private key.
+	SeedSize = 32
+
+	// PublicKeySize is the size in bytes of a public key.
+	PublicKeySize = 32
+
+	// ProofSize is the size in bytes of a proof.
+	ProofSize = 80
+
+	// OutputSize is the size in bytes of the output of the function.
+	OutputSize = 64
+
+	// suiteString is the identifier of the cipher suite in RFC 9381.
+	suiteString = 0x03
+
+	// challengeSize is the size in bytes of the challenge of a proof.
+	challengeSize = 16
+
+	// maxAttempts is the number of attempts to hash an input to the curve
+	// before giving up.
+	maxAttempts = 256
+)
+
+var (
+	suite = edwards25519.NewBlakeSHA256Ed25519()
+
+	cofactor = suite.Scalar().SetInt64(8)
+
+	// the reader can be changed for the tests.
+	randReader io.Reader = rand.Reader
+)
+
+// PublicKey is the public key of the function that verifies the proofs.
+type PublicKey struct {
+	point kyber.Point
+	data  []byte
+}
+
+// NewPublicKey returns a new public key from the data. It returns an error if
+// the point is invalid or if it has a small order.
+func NewPublicKey(data []byte) (PublicKey, error) {
+	if len(data) != PublicKeySize {
+		return PublicKey{}, xerrors.Errorf("invalid public key size %d != %d",
+			len(data), PublicKeySize)
+	}
+
+	point := suite.Point()
+	err := point.UnmarshalBinary(data)
+	if err != nil {
+		return PublicKey{}, xerrors.Errorf("couldn't unmarshal point: %v", err)
+	}
+
+	if isSmallOrder(point) {
+		return PublicKey{}, xerrors.New("point has a small order")
+	}
+
+	pk := PublicKey{
+		point: point,
+		data:  append([]byte{}, data...),
+	}
+
+	return pk, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It produces a slice of
+// bytes representing the public key.
+func (pk PublicKey) MarshalBinary() ([]byte, error) {
+	if pk.point == nil {
+		return nil, xerrors.New("public key is empty")
+	}
+
+	return append([]byte{}, pk.data...), nil
+}
+
+// Verify verifies the proof of the input and returns the output of the
+// function if it is valid, otherwise an error.
+func (pk PublicKey) Verify(alpha []byte, proof Proof) ([]byte, error) {
+	if pk.point == nil {
+		return nil, xerrors.New("public key is empty")
+	}
+
+	gamma, c, s, err := proof.decode()
+	if err != nil {
+		return nil, xerrors.Errorf("invalid proof: %v", err)
+	}
+
+	h, err := hashToCurve(pk.data, alpha)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't hash input: %v", err)
+	}
+
+	// U = s*B - c*Y
+	u := suite.Point().Sub(
+		suite.Point().Mul(s, nil),
+		suite.Point().Mul(c, pk.point),
+	)
+
+	// V = s*H - c*Gamma
+	v := suite.Point().Sub(
+		suite.Point().Mul(s, h),
+		suite.Point().Mul(c, gamma),
+	)
+
+	expected := challenge(pk.point, h, gamma, u, v)
+	actual := proof.data[PublicKeySize : PublicKeySize+challengeSize]
+
+	if subtle.ConstantTimeCompare(expected, actual) != 1 {
+		return nil, xerrors.New("mismatch challenge")
+	}
+
+	return proofToHash(gamma), nil
+}
+
+// Equal returns true if the other public key is the same.
+func (pk PublicKey) Equal(other interface{}) bool {
+	pubkey, ok := other.(PublicKey)
+	if !ok {
+		return false
+	}
+
+	return bytes.Equal(pk.data, pubkey.data)
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns a text
+// representation of the public key.
+func (pk PublicKey) MarshalText() ([]byte, error) {
+	buffer, err := pk.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return []byte(fmt.Sprintf("vrf:%x", buffer)), nil
+}
+
+// String implements fmt.Stringer. It returns a string representation of the
+// public key.
+func (pk PublicKey) String() string {
+	buffer, err := pk.MarshalText()
+	if err != nil {
+		return "vrf:malformed_point"
+	}
+
+	// Output only the prefix and 16 characters of the buffer in hexadecimal.
+	return string(buffer)[:4+16]
+}
+
+// Proof is the proof of an evaluation of the function. It contains the
+// encoded point Gamma, the challenge and the response.
+type Proof struct {
+	data []byte
+}
+
+// NewProof returns a new proof from the data.
+func NewProof(data []byte) (Proof, error) {
+	if len(data) != ProofSize {
+		return Proof{}, xerrors.Errorf("invalid proof size %d != %d", len(data), ProofSize)
+	}
+
+	return Proof{data: append([]byte{}, data...)}, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns a slice of
+// bytes representing the proof.
+func (p Proof) MarshalBinary() ([]byte, error) {
+	return append([]byte{}, p.data...), nil
+}
+
+// Hash returns the output of the function for this proof. The proof must be
+// verified beforehand as this function does not make any verification.
+func (p Proof) Hash() ([]byte, error) {
+	gamma, _, _, err := p.decode()
+	if err != nil {
+		return nil, xerrors.Errorf("invalid proof: %v", err)
+	}
+
+	return proofToHash(gamma), nil
+}
+
+// Equal returns true if both proofs are the same.
+func (p Proof) Equal(other Proof) bool {
+	return bytes.Equal(p.data, other.data)
+}
+
+func (p Proof) decode() (kyber.Point, kyber.Scalar, kyber.Scalar, error) {
+	if len(p.data) != ProofSize {
+		return nil, nil, nil, xerrors.Errorf("invalid size %d != %d", len(p.data), ProofSize)
+	}
+
+	gamma := suite.Point()
+	err := gamma.UnmarshalBinary(p.data[:PublicKeySize])
+	if err != nil {
+		return nil, nil, nil, xerrors.Errorf("couldn't unmarshal gamma: %v", err)
+	}
+
+	c := suite.Scalar().SetBytes(p.data[PublicKeySize : PublicKeySize+challengeSize])
+
+	sbuf := p.data[PublicKeySize+challengeSize:]
+
+	s := suite.Scalar()
+	err = s.UnmarshalBinary(sbuf)
+	if err != nil {
+		return nil, nil, nil, xerrors.Errorf("couldn't unmarshal scalar: %v", err)
+	}
+
+	// The response must be strictly smaller than the order of the group.
+	raw, _ := s.MarshalBinary()
+	if !bytes.Equal(raw, sbuf) {
+		return nil, nil, nil, xerrors.New("scalar is not canonical")
+	}
+
+	return gamma, c, s, nil
+}
+
+// Signer is the owner of the private key of the function which can create the
+// proofs.
+type Signer struct {
+	seed   []byte
+	secret kyber.Scalar
+	prefix []byte
+	pubkey PublicKey
+}
+
+// NewSigner returns a new signer with a random private key.
+func NewSigner() Signer {
+	seed := make([]byte, SeedSize)
+
+	_, err := io.ReadFull(randReader, seed)
+	if err != nil {
+		panic(fmt.Sprintf("failed to read random seed: %v", err))
+	}
+
+	signer, err := NewSignerFromBytes(seed)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create signer: %v", err))
+	}
+
+	return signer
+}
+
+// NewSignerFromBytes returns a new signer derived from the seed.
+func NewSignerFromBytes(seed []byte) (Signer, error) {
+	if len(seed) != SeedSize {
+		return Signer{}, xerrors.Errorf("invalid seed size %d != %d", len(seed), SeedSize)
+	}
+
+	digest := sha512.Sum512(seed)
+
+	// The private scalar is clamped as for an Ed25519 key.
+	digest[0] &= 248
+	digest[31] &= 127
+	digest[31] |= 64
+
+	secret := suite.Scalar().SetBytes(digest[:32])
+
+	data, err := suite.Point().Mul(secret, nil).MarshalBinary()
+	if err != nil {
+		return Signer{}, xerrors.Errorf("couldn't marshal public key: %v", err)
+	}
+
+	pubkey, err := NewPublicKey(data)
+	if err != nil {
+		return Signer{}, xerrors.Errorf("invalid public key: %v", err)
+	}
+
+	signer := Signer{
+		seed:   append([]byte{}, seed...),
+		secret: secret,
+		prefix: append([]byte{}, digest[32:]...),
+		pubkey: pubkey,
+	}
+
+	return signer, nil
+}
+
+// GetPublicKey returns the public key of the signer.
+func (s Signer) GetPublicKey() PublicKey {
+	return s.pubkey
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns the seed of
+// the private key.
+func (s Signer) MarshalBinary() ([]byte, error) {
+	return append([]byte{}, s.seed...), nil
+}
+
+// Prove returns the proof of the evaluation of the function for the input.
+// The proof is deterministic for a given private key and input.
+func (s Signer) Prove(alpha []byte) (Proof, error) {
+	h, err := hashToCurve(s.pubkey.data, alpha)
+	if err != nil {
+		return Proof{}, xerrors.Errorf("couldn't hash input: %v", err)
+	}
+
+	hbuf, err := h.MarshalBinary()
+	if err != nil {
+		return Proof{}, xerrors.Errorf("couldn't marshal point: %v", err)
+	}
+
+	gamma := suite.Point().Mul(s.secret, h)
+
+	// The nonce is generated as in RFC 8032 so that it is deterministic.
+	hash := sha512.New()
+	hash.Write(s.prefix)
+	hash.Write(hbuf)
+	k := suite.Scalar().SetBytes(hash.Sum(nil))
+
+	c := challenge(s.pubkey.point, h, gamma,
+		suite.Point().Mul(k, nil), suite.Point().Mul(k, h))
+
+	// s = k + c*x mod q
+	resp := suite.Scalar().Add(k, suite.Scalar().Mul(suite.Scalar().SetBytes(c), s.secret))
+
+	gbuf, err := gamma.MarshalBinary()
+	if err != nil {
+		return Proof{}, xerrors.Errorf("couldn't marshal gamma: %v", err)
+	}
+
+	sbuf, err := resp.MarshalBinary()
+	if err != nil {
+		return Proof{}, xerrors.Errorf("couldn't marshal scalar: %v", err)
+	}
+
+	data := make([]byte, 0, ProofSize)
+	data = append(data, gbuf...)
+	data = append(data, c...)
+	data = append(data, sbuf...)
+
+	return Proof{data: data}, nil
+}
+
+// hashToCurve maps the input to a point of the prime order subgroup by using
+// the try-and-increment method.
+func hashToCurve(pubkey, alpha []byte) (kyber.Point, error) {
+	hash := sha512.New()
+
+	for ctr := 0; ctr < maxAttempts; ctr++ {
+		hash.Reset()
+		hash.Write([]byte{suiteString, 0x01})
+		hash.Write(pubkey)
+		hash.Write(alpha)
+		hash.Write([]byte{byte(ctr), 0x00})
+
+		digest := hash.Sum(nil)
+
+		point := suite.Point()
+		err := point.UnmarshalBinary(digest[:32])
+		if err == nil {
+			return suite.Point().Mul(cofactor, point), nil
+		}
+	}
+
+	return nil, xerrors.Errorf("no valid point after %d attempts", maxAttempts)
+}
+
+// challenge returns the hash of the points truncated to the size of a
+// challenge.
+func challenge(points ...kyber.Point) []byte {
+	hash := sha512.New()
+	hash.Write([]byte{suiteString, 0x02})
+
+	for _, point := range points {
+		buffer, _ := point.MarshalBinary()
+		hash.Write(buffer)
+	}
+
+	hash.Write([]byte{0x00})
+
+	return hash.Sum(nil)[:challengeSize]
+}
+
+// proofToHash returns the output of the function for the point Gamma.
+func proofToHash(gamma kyber.Point) []byte {
+	buffer, _ := suite.Point().Mul(cofactor, gamma).MarshalBinary()
+
+	hash := sha512.New()
+	hash.Write([]byte{suiteString, 0x03})
+	hash.Write(buffer)
+	hash.Write([]byte{0x00})
+
+	return hash.Sum(nil)
+}
+
+func isSmallOrder(point kyber.Point) bool {
+	return suite.Point().Mul(cofactor, point).Equal(suite.Point().Null())
+}
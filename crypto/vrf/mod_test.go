@@ -0,0 +1,215 @@
+package vrf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test vector from RFC 9381, section B.3, example 16.
+const (
+	testSeed   = "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+	testPubkey = "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+	testGamma  = "8657106690b5526245a92b003bb079ccd1a92130477671f6fc01ad16f26f723f"
+	testBeta   = "90cf1df3b703cce59e2a35b925d411164068269d7b2d29f3301c03dd757876ff" +
+		"66b71dda49d2de59d03450451af026798e8f81cd2e333de5cdf4f3e140fdd8ae"
+)
+
+func TestVRF_Vector(t *testing.T) {
+	signer, err := NewSignerFromBytes(mustDecode(t, testSeed))
+	require.NoError(t, err)
+
+	data, err := signer.GetPublicKey().MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, testPubkey, hex.EncodeToString(data))
+
+	proof, err := signer.Prove(nil)
+	require.NoError(t, err)
+	require.Equal(t, testGamma, hex.EncodeToString(proof.data[:PublicKeySize]))
+
+	beta, err := signer.GetPublicKey().Verify(nil, proof)
+	require.NoError(t, err)
+	require.Equal(t, testBeta, hex.EncodeToString(beta))
+
+	hash, err := proof.Hash()
+	require.NoError(t, err)
+	require.Equal(t, beta, hash)
+}
+
+func TestPublicKey_New(t *testing.T) {
+	pk, err := NewPublicKey(mustDecode(t, testPubkey))
+	require.NoError(t, err)
+	require.NotNil(t, pk.point)
+
+	_, err = NewPublicKey(nil)
+	require.EqualError(t, err, "invalid public key size 0 != 32")
+
+	_, err = NewPublicKey(makeBadPoint())
+	require.EqualError(t, err, "couldn't unmarshal point: invalid Ed25519 curve point")
+
+	identity, err := suite.Point().Null().MarshalBinary()
+	require.NoError(t, err)
+
+	_, err = NewPublicKey(identity)
+	require.EqualError(t, err, "point has a small order")
+}
+
+func TestPublicKey_MarshalBinary(t *testing.T) {
+	pk, err := NewPublicKey(mustDecode(t, testPubkey))
+	require.NoError(t, err)
+
+	data, err := pk.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, testPubkey, hex.EncodeToString(data))
+
+	_, err = PublicKey{}.MarshalBinary()
+	require.EqualError(t, err, "public key is empty")
+}
+
+func TestPublicKey_Verify(t *testing.T) {
+	signer := NewSigner()
+
+	proof, err := signer.Prove([]byte("abc"))
+	require.NoError(t, err)
+
+	beta, err := signer.GetPublicKey().Verify([]byte("abc"), proof)
+	require.NoError(t, err)
+	require.Len(t, beta, OutputSize)
+
+	_, err = PublicKey{}.Verify([]byte("abc"), proof)
+	require.EqualError(t, err, "public key is empty")
+
+	_, err = signer.GetPublicKey().Verify([]byte("abd"), proof)
+	require.EqualError(t, err, "mismatch challenge")
+
+	_, err = NewSigner().GetPublicKey().Verify([]byte("abc"), proof)
+	require.EqualError(t, err, "mismatch challenge")
+
+	_, err = signer.GetPublicKey().Verify([]byte("abc"), Proof{})
+	require.EqualError(t, err, "invalid proof: invalid size 0 != 80")
+
+	bad := Proof{data: append([]byte{}, proof.data...)}
+	copy(bad.data[PublicKeySize+challengeSize:], bytes.Repeat([]byte{0xff}, 32))
+	_, err = signer.GetPublicKey().Verify([]byte("abc"), bad)
+	require.EqualError(t, err, "invalid proof: scalar is not canonical")
+}
+
+func TestPublicKey_Equal(t *testing.T) {
+	pk := NewSigner().GetPublicKey()
+
+	require.True(t, pk.Equal(pk))
+	require.False(t, pk.Equal(NewSigner().GetPublicKey()))
+	require.False(t, pk.Equal(nil))
+}
+
+func TestPublicKey_MarshalText(t *testing.T) {
+	pk, err := NewPublicKey(mustDecode(t, testPubkey))
+	require.NoError(t, err)
+
+	text, err := pk.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "vrf:"+testPubkey, string(text))
+
+	_, err = PublicKey{}.MarshalText()
+	require.EqualError(t, err, "couldn't marshal: public key is empty")
+}
+
+func TestPublicKey_String(t *testing.T) {
+	pk, err := NewPublicKey(mustDecode(t, testPubkey))
+	require.NoError(t, err)
+
+	require.Equal(t, "vrf:d75a980182b10ab7", pk.String())
+	require.Equal(t, "vrf:malformed_point", PublicKey{}.String())
+}
+
+func TestProof_New(t *testing.T) {
+	proof, err := NewProof(make([]byte, ProofSize))
+	require.NoError(t, err)
+	require.Len(t, proof.data, ProofSize)
+
+	_, err = NewProof(nil)
+	require.EqualError(t, err, "invalid proof size 0 != 80")
+}
+
+func TestProof_MarshalBinary(t *testing.T) {
+	proof, err := NewSigner().Prove([]byte("abc"))
+	require.NoError(t, err)
+
+	data, err := proof.MarshalBinary()
+	require.NoError(t, err)
+
+	proof2, err := NewProof(data)
+	require.NoError(t, err)
+	require.True(t, proof.Equal(proof2))
+}
+
+func TestProof_Hash(t *testing.T) {
+	data := make([]byte, ProofSize)
+	copy(data, makeBadPoint())
+
+	_, err := Proof{data: data}.Hash()
+	require.EqualError(t, err,
+		"invalid proof: couldn't unmarshal gamma: invalid Ed25519 curve point")
+}
+
+func TestSigner_New(t *testing.T) {
+	signer := NewSigner()
+	require.NotNil(t, signer.secret)
+
+	seed, err := signer.MarshalBinary()
+	require.NoError(t, err)
+
+	signer2, err := NewSignerFromBytes(seed)
+	require.NoError(t, err)
+	require.True(t, signer.GetPublicKey().Equal(signer2.GetPublicKey()))
+
+	_, err = NewSignerFromBytes(nil)
+	require.EqualError(t, err, "invalid seed size 0 != 32")
+
+	reader := randReader
+	randReader = badReader{}
+	defer func() { randReader = reader }()
+	require.Panics(t, func() { NewSigner() })
+}
+
+func TestSigner_Prove(t *testing.T) {
+	signer := NewSigner()
+
+	proof, err := signer.Prove([]byte("abc"))
+	require.NoError(t, err)
+
+	proof2, err := signer.Prove([]byte("abc"))
+	require.NoError(t, err)
+	require.True(t, proof.Equal(proof2))
+
+	proof3, err := signer.Prove([]byte("abd"))
+	require.NoError(t, err)
+	require.False(t, proof.Equal(proof3))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func mustDecode(t *testing.T, str string) []byte {
+	data, err := hex.DecodeString(str)
+	require.NoError(t, err)
+
+	return data
+}
+
+// makeBadPoint returns the encoding of a y-coordinate that does not belong to
+// the curve.
+func makeBadPoint() []byte {
+	data := make([]byte, PublicKeySize)
+	data[0] = 2
+
+	return data
+}
+
+type badReader struct{}
+
+func (badReader) Read([]byte) (int, error) {
+	return 0, bytes.ErrTooLarge
+}
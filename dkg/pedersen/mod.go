@@ -65,7 +65,7 @@ func (s *Pedersen) Listen() (dkg.Actor, error) {
 	h := NewHandler(s.privKey, s.mino.GetAddress())
 
 	a := &Actor{
-		rpc:      mino.MustCreateRPC(s.mino, "dkg", h, s.factory),
+		rpc:      mino.MustCreateRPC(s.mino, "dkg", mino.WithPolicy(h, mino.ProtocolPolicy), s.factory),
 		factory:  s.factory,
 		startRes: h.startRes,
 	}
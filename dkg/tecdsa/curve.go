@@ -0,0 +1,295 @@
+// This file contains the arithmetic of the curves and of the polynomials used
+// by the protocol.
+
+package tecdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
+	"math/big"
+	"sync"
+)
+
+var (
+	secp256k1     *koblitzCurve
+	secp256k1Once sync.Once
+)
+
+// Secp256k1 returns the curve of Bitcoin and Ethereum.
+//
+// The arithmetic is implemented with big integers in affine coordinates, which
+// is neither fast nor constant time. The standard library doesn't provide the
+// curve and the generic implementation of elliptic.CurveParams only supports
+// the curves where a = -3.
+func Secp256k1() elliptic.Curve {
+	secp256k1Once.Do(func() {
+		params := &elliptic.CurveParams{Name: "secp256k1", BitSize: 256}
+		params.P, _ = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F", 16)
+		params.N, _ = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16)
+		params.B = big.NewInt(7)
+		params.Gx, _ = new(big.Int).SetString("79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798", 16)
+		params.Gy, _ = new(big.Int).SetString("483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8", 16)
+
+		secp256k1 = &koblitzCurve{params: params}
+	})
+
+	return secp256k1
+}
+
+// koblitzCurve is a short Weierstrass curve y² = x³ + b where a = 0. The point
+// at infinity is (0, 0), as for the curves of the standard library.
+//
+// - implements elliptic.Curve
+type koblitzCurve struct {
+	params *elliptic.CurveParams
+}
+
+// Params implements elliptic.Curve. It returns the parameters of the curve.
+func (c *koblitzCurve) Params() *elliptic.CurveParams {
+	return c.params
+}
+
+// IsOnCurve implements elliptic.Curve. It returns true if the point is on the
+// curve.
+func (c *koblitzCurve) IsOnCurve(x, y *big.Int) bool {
+	p := c.params.P
+
+	if x.Sign() < 0 || x.Cmp(p) >= 0 || y.Sign() < 0 || y.Cmp(p) >= 0 {
+		return false
+	}
+
+	y2 := new(big.Int).Mul(y, y)
+	y2.Mod(y2, p)
+
+	x3 := new(big.Int).Mul(x, x)
+	x3.Mul(x3, x)
+	x3.Add(x3, c.params.B)
+	x3.Mod(x3, p)
+
+	return x3.Cmp(y2) == 0
+}
+
+// Add implements elliptic.Curve. It returns the sum of the two points.
+func (c *koblitzCurve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	if isInfinity(x1, y1) {
+		return new(big.Int).Set(x2), new(big.Int).Set(y2)
+	}
+
+	if isInfinity(x2, y2) {
+		return new(big.Int).Set(x1), new(big.Int).Set(y1)
+	}
+
+	p := c.params.P
+
+	if x1.Cmp(x2) == 0 {
+		if y1.Cmp(y2) == 0 {
+			return c.Double(x1, y1)
+		}
+
+		return new(big.Int), new(big.Int)
+	}
+
+	// λ = (y2 - y1) / (x2 - x1)
+	num := new(big.Int).Sub(y2, y1)
+	den := new(big.Int).Sub(x2, x1)
+	den.Mod(den, p)
+	den.ModInverse(den, p)
+
+	lambda := num.Mul(num, den)
+	lambda.Mod(lambda, p)
+
+	return c.finish(lambda, x1, y1, x2)
+}
+
+// Double implements elliptic.Curve. It returns twice the point.
+func (c *koblitzCurve) Double(x1, y1 *big.Int) (*big.Int, *big.Int) {
+	if isInfinity(x1, y1) || y1.Sign() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+
+	p := c.params.P
+
+	// λ = 3x² / 2y as a = 0.
+	num := new(big.Int).Mul(x1, x1)
+	num.Mul(num, big.NewInt(3))
+
+	den := new(big.Int).Lsh(y1, 1)
+	den.Mod(den, p)
+	den.ModInverse(den, p)
+
+	lambda := num.Mul(num, den)
+	lambda.Mod(lambda, p)
+
+	return c.finish(lambda, x1, y1, x1)
+}
+
+// ScalarMult implements elliptic.Curve. It returns k times the point, where k
+// is a big-endian integer.
+func (c *koblitzCurve) ScalarMult(x1, y1 *big.Int, k []byte) (*big.Int, *big.Int) {
+	x, y := new(big.Int), new(big.Int)
+
+	for _, b := range k {
+		for bit := 7; bit >= 0; bit-- {
+			x, y = c.Double(x, y)
+
+			if (b>>uint(bit))&1 == 1 {
+				x, y = c.Add(x, y, x1, y1)
+			}
+		}
+	}
+
+	return x, y
+}
+
+// ScalarBaseMult implements elliptic.Curve. It returns k times the generator,
+// where k is a big-endian integer.
+func (c *koblitzCurve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	return c.ScalarMult(c.params.Gx, c.params.Gy, k)
+}
+
+// finish returns the point (λ² - x1 - x2, λ(x1 - x3) - y1).
+func (c *koblitzCurve) finish(lambda, x1, y1, x2 *big.Int) (*big.Int, *big.Int) {
+	p := c.params.P
+
+	x3 := new(big.Int).Mul(lambda, lambda)
+	x3.Sub(x3, x1)
+	x3.Sub(x3, x2)
+	x3.Mod(x3, p)
+
+	y3 := new(big.Int).Sub(x1, x3)
+	y3.Mul(y3, lambda)
+	y3.Sub(y3, y1)
+	y3.Mod(y3, p)
+
+	return x3, y3
+}
+
+func isInfinity(x, y *big.Int) bool {
+	return x.Sign() == 0 && y.Sign() == 0
+}
+
+// polynomial is a polynomial over the scalars of a curve, defined by its
+// coefficients from the constant term.
+type polynomial []*big.Int
+
+// randomPolynomial returns a polynomial of the given degree with random
+// coefficients. The constant term is zero when the polynomial shares zero.
+func randomPolynomial(n *big.Int, degree int, zero bool, rnd io.Reader) (polynomial, error) {
+	poly := make(polynomial, degree+1)
+
+	for i := range poly {
+		k, err := rand.Int(rnd, n)
+		if err != nil {
+			return nil, err
+		}
+
+		poly[i] = k
+	}
+
+	if zero {
+		poly[0].SetInt64(0)
+	}
+
+	return poly, nil
+}
+
+// eval returns the value of the polynomial for x.
+func (poly polynomial) eval(n *big.Int, x int64) *big.Int {
+	res := new(big.Int)
+	bx := big.NewInt(x)
+
+	for i := len(poly) - 1; i >= 0; i-- {
+		res.Mul(res, bx)
+		res.Add(res, poly[i])
+		res.Mod(res, n)
+	}
+
+	return res
+}
+
+// commit returns the commitments to the coefficients of the polynomial.
+func (poly polynomial) commit(curve elliptic.Curve) [][]byte {
+	commits := make([][]byte, len(poly))
+	for i, coeff := range poly {
+		x, y := curve.ScalarBaseMult(coeff.Bytes())
+		commits[i] = elliptic.Marshal(curve, x, y)
+	}
+
+	return commits
+}
+
+// verifyShare returns true if the share for x matches the commitments of the
+// polynomial it has been evaluated from.
+func verifyShare(curve elliptic.Curve, commits [][]byte, x int64, value *big.Int) bool {
+	ex, ey := new(big.Int), new(big.Int)
+	bx := big.NewInt(x)
+	pow := big.NewInt(1)
+
+	for _, commit := range commits {
+		cx, cy := elliptic.Unmarshal(curve, commit)
+		if cx == nil {
+			return false
+		}
+
+		tx, ty := curve.ScalarMult(cx, cy, pow.Bytes())
+		ex, ey = curve.Add(ex, ey, tx, ty)
+
+		pow.Mul(pow, bx)
+		pow.Mod(pow, curve.Params().N)
+	}
+
+	vx, vy := curve.ScalarBaseMult(value.Bytes())
+
+	return vx.Cmp(ex) == 0 && vy.Cmp(ey) == 0
+}
+
+// interpolate returns the value at zero of the polynomial that goes through the
+// points.
+func interpolate(n *big.Int, xs []int64, ys []*big.Int) *big.Int {
+	res := new(big.Int)
+
+	for i, xi := range xs {
+		num := big.NewInt(1)
+		den := big.NewInt(1)
+
+		for j, xj := range xs {
+			if i == j {
+				continue
+			}
+
+			num.Mul(num, big.NewInt(xj))
+			num.Mod(num, n)
+
+			den.Mul(den, big.NewInt(xj-xi))
+			den.Mod(den, n)
+		}
+
+		term := den.ModInverse(den, n)
+		term.Mul(term, num)
+		term.Mul(term, ys[i])
+
+		res.Add(res, term)
+		res.Mod(res, n)
+	}
+
+	return res
+}
+
+// hashToInt converts a hash to an integer as in the ECDSA standard, which
+// keeps the leftmost bits up to the size of the order of the curve.
+func hashToInt(hash []byte, n *big.Int) *big.Int {
+	size := (n.BitLen() + 7) / 8
+	if len(hash) > size {
+		hash = hash[:size]
+	}
+
+	res := new(big.Int).SetBytes(hash)
+
+	excess := len(hash)*8 - n.BitLen()
+	if excess > 0 {
+		res.Rsh(res, uint(excess))
+	}
+
+	return res
+}
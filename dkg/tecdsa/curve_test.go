@@ -0,0 +1,137 @@
+package tecdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestSecp256k1_Params(t *testing.T) {
+	curve := Secp256k1()
+
+	require.Equal(t, "secp256k1", curve.Params().Name)
+	require.Equal(t, 256, curve.Params().BitSize)
+	require.True(t, curve.IsOnCurve(curve.Params().Gx, curve.Params().Gy))
+	require.Same(t, curve, Secp256k1())
+}
+
+func TestSecp256k1_IsOnCurve(t *testing.T) {
+	curve := Secp256k1()
+
+	require.False(t, curve.IsOnCurve(big.NewInt(1), big.NewInt(1)))
+	require.False(t, curve.IsOnCurve(big.NewInt(-1), big.NewInt(1)))
+	require.False(t, curve.IsOnCurve(curve.Params().P, big.NewInt(1)))
+}
+
+func TestSecp256k1_Arithmetic(t *testing.T) {
+	curve := Secp256k1()
+	gx, gy := curve.Params().Gx, curve.Params().Gy
+
+	// 2G from the test vectors of the curve.
+	x, y := curve.Double(gx, gy)
+	require.Equal(t, "c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5", x.Text(16))
+	require.True(t, curve.IsOnCurve(x, y))
+
+	x2, y2 := curve.ScalarBaseMult([]byte{2})
+	require.Equal(t, x, x2)
+	require.Equal(t, y, y2)
+
+	x3, y3 := curve.Add(x, y, gx, gy)
+	x4, y4 := curve.ScalarBaseMult([]byte{3})
+	require.Equal(t, x3, x4)
+	require.Equal(t, y3, y4)
+
+	// G + (-G) is the point at infinity.
+	negY := new(big.Int).Sub(curve.Params().P, gy)
+	x, y = curve.Add(gx, gy, gx, negY)
+	require.True(t, isInfinity(x, y))
+
+	x, y = curve.Add(x, y, gx, gy)
+	require.Equal(t, gx, x)
+	require.Equal(t, gy, y)
+
+	x, y = curve.ScalarBaseMult(curve.Params().N.Bytes())
+	require.True(t, isInfinity(x, y))
+}
+
+func TestPolynomial_Eval(t *testing.T) {
+	n := big.NewInt(11)
+
+	// 1 + 2x + 3x²
+	poly := polynomial{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+
+	require.Equal(t, int64(1), poly.eval(n, 0).Int64())
+	require.Equal(t, int64(6), poly.eval(n, 1).Int64())
+	require.Equal(t, int64(17%11), poly.eval(n, 2).Int64())
+}
+
+func TestPolynomial_Random(t *testing.T) {
+	n := elliptic.P256().Params().N
+
+	poly, err := randomPolynomial(n, 2, true, rand.Reader)
+	require.NoError(t, err)
+	require.Len(t, poly, 3)
+	require.Equal(t, 0, poly[0].Sign())
+
+	_, err = randomPolynomial(n, 2, false, badReader{})
+	require.EqualError(t, err, fake.GetError().Error())
+}
+
+func TestPolynomial_VerifyShare(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), Secp256k1()} {
+		n := curve.Params().N
+
+		poly, err := randomPolynomial(n, 2, false, rand.Reader)
+		require.NoError(t, err)
+
+		commits := poly.commit(curve)
+		require.Len(t, commits, 3)
+
+		require.True(t, verifyShare(curve, commits, 4, poly.eval(n, 4)))
+		require.False(t, verifyShare(curve, commits, 4, poly.eval(n, 5)))
+		require.False(t, verifyShare(curve, [][]byte{{1}}, 4, poly.eval(n, 4)))
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	n := elliptic.P256().Params().N
+
+	poly, err := randomPolynomial(n, 2, false, rand.Reader)
+	require.NoError(t, err)
+
+	xs := []int64{2, 4, 5}
+	ys := []*big.Int{poly.eval(n, 2), poly.eval(n, 4), poly.eval(n, 5)}
+
+	require.Equal(t, poly[0], interpolate(n, xs, ys))
+
+	// Not enough points for the degree.
+	require.NotEqual(t, poly[0], interpolate(n, xs[:2], ys[:2]))
+}
+
+func TestHashToInt(t *testing.T) {
+	n := elliptic.P256().Params().N
+
+	require.Equal(t, int64(0x0102), hashToInt([]byte{1, 2}, n).Int64())
+
+	hash := make([]byte, 64)
+	hash[0] = 0xff
+	require.Equal(t, 256, hashToInt(hash, n).BitLen())
+
+	n = elliptic.P224().Params().N
+	hash = make([]byte, 32)
+	hash[0] = 0xff
+	require.Equal(t, 224, hashToInt(hash, n).BitLen())
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type badReader struct{}
+
+func (badReader) Read([]byte) (int, error) {
+	return 0, fake.GetError()
+}
@@ -0,0 +1,564 @@
+// This file contains the implementation of the participants of the protocol.
+
+package tecdsa
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"go.dedis.ch/dela/dkg/tecdsa/types"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// dealTimeout is the maximum amount of time for a dealer to send its
+	// shares to the other participants.
+	dealTimeout = 30 * time.Second
+
+	// sessionTimeout is the amount of time after which the state of a session
+	// that has not completed is dropped.
+	sessionTimeout = 10 * time.Minute
+)
+
+// keyShare is the share of the key of a participant.
+type keyShare struct {
+	index     int
+	threshold int
+	addrs     []mino.Address
+	value     *big.Int
+	public    []byte
+}
+
+func (k *keyShare) getPublicKey(curve elliptic.Curve) *ecdsa.PublicKey {
+	x, y := elliptic.Unmarshal(curve, k.public)
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+}
+
+// received is a share received from a dealer.
+type received struct {
+	from  mino.Address
+	share types.Share
+}
+
+// session is the state of a key generation or of a signature. The shares can
+// arrive before the request of the initiator as the dealers run concurrently.
+type session struct {
+	created time.Time
+	shares  map[int]received
+
+	// dealt is true once the participant has dealt its own shares.
+	dealt bool
+
+	// threshold and addrs are the parameters of a key generation.
+	threshold int
+	addrs     []mino.Address
+	index     int
+
+	// signers are the indices of the signers of a signature.
+	signers []int
+
+	// blinding, mask and point are the shares of the blinding factor and of
+	// the mask of the signature, and the nonce point, once finalized.
+	blinding *big.Int
+	mask     *big.Int
+	point    []byte
+}
+
+// handler processes the requests of the protocol on a participant.
+//
+// - implements mino.Handler
+type handler struct {
+	mino.UnsupportedHandler
+	sync.Mutex
+
+	me        mino.Address
+	curve     elliptic.Curve
+	authorize Authorizer
+	random    io.Reader
+	rpc       mino.RPC
+	key       *keyShare
+	sessions  map[string]*session
+}
+
+func newHandler(me mino.Address, curve elliptic.Curve, fn Authorizer) *handler {
+	return &handler{
+		me:        me,
+		curve:     curve,
+		authorize: fn,
+		random:    rand.Reader,
+		sessions:  make(map[string]*session),
+	}
+}
+
+func (h *handler) setRPC(rpc mino.RPC) {
+	h.Lock()
+	h.rpc = rpc
+	h.Unlock()
+}
+
+func (h *handler) getKey() *keyShare {
+	h.Lock()
+	defer h.Unlock()
+
+	return h.key
+}
+
+// Process implements mino.Handler. It processes the requests of the initiator
+// and the shares of the dealers.
+func (h *handler) Process(req mino.Request) (serde.Message, error) {
+	switch msg := req.Message.(type) {
+	case types.Deal:
+		return h.deal(msg)
+	case types.Probe:
+		if h.getKey() == nil {
+			return nil, xerrors.New("key has not been generated")
+		}
+
+		return types.Ack{}, nil
+	case types.Nonce:
+		return h.nonce(req.Address, msg)
+	case types.Share:
+		return h.receive(req.Address, msg)
+	case types.Finalize:
+		return h.finalize(req.Address, msg)
+	case types.Sign:
+		return h.sign(req.Address, msg)
+	default:
+		return nil, xerrors.Errorf("unexpected message '%T'", req.Message)
+	}
+}
+
+// deal generates the contribution of the participant to the key, and sends the
+// shares to the other participants.
+func (h *handler) deal(msg types.Deal) (serde.Message, error) {
+	h.Lock()
+
+	if h.key != nil {
+		h.Unlock()
+		return nil, xerrors.New("key has already been generated")
+	}
+
+	addrs := msg.GetAddresses()
+
+	index := indexOf(addrs, h.me)
+	if index < 0 {
+		h.Unlock()
+		return nil, xerrors.Errorf("%v is not a participant", h.me)
+	}
+
+	err := checkThreshold(msg.GetThreshold(), len(addrs))
+	if err != nil {
+		h.Unlock()
+		return nil, xerrors.Errorf("invalid threshold: %v", err)
+	}
+
+	sess := h.getSession(msg.GetSession())
+	if sess.dealt {
+		h.Unlock()
+		return nil, xerrors.Errorf("session %s already dealt", msg.GetSession())
+	}
+
+	poly, err := randomPolynomial(h.curve.Params().N, msg.GetThreshold()-1, false, h.random)
+	if err != nil {
+		h.Unlock()
+		return nil, xerrors.Errorf("polynomial: %v", err)
+	}
+
+	sess.dealt = true
+	sess.threshold = msg.GetThreshold()
+	sess.addrs = addrs
+	sess.index = index
+
+	commits := poly.commit(h.curve)
+	n := h.curve.Params().N
+
+	shares := make([]types.Share, len(addrs))
+	for j := range addrs {
+		values := []*big.Int{poly.eval(n, int64(j+1))}
+		shares[j] = types.NewShare(msg.GetSession(), index, values, commits)
+	}
+
+	sess.shares[index] = received{from: h.me, share: shares[index]}
+
+	rpc := h.rpc
+
+	h.Unlock()
+
+	err = sendShares(rpc, addrs, index, shares)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't deal: %v", err)
+	}
+
+	return types.Ack{}, nil
+}
+
+// nonce generates the contribution of the signer to the nonce, the blinding
+// factor and the masks of a signature, and sends the shares to the other
+// signers.
+func (h *handler) nonce(from mino.Address, msg types.Nonce) (serde.Message, error) {
+	h.Lock()
+
+	key, err := h.checkInitiator(from)
+	if err != nil {
+		h.Unlock()
+		return nil, err
+	}
+
+	signers := msg.GetSigners()
+
+	err = checkSigners(key, signers)
+	if err != nil {
+		h.Unlock()
+		return nil, xerrors.Errorf("invalid signers: %v", err)
+	}
+
+	sess := h.getSession(msg.GetSession())
+	if sess.dealt {
+		h.Unlock()
+		return nil, xerrors.Errorf("session %s already dealt", msg.GetSession())
+	}
+
+	n := h.curve.Params().N
+	degree := key.threshold - 1
+
+	// The nonce and the blinding factor are of degree t-1, and the masks of
+	// their products are sharings of zero of degree 2(t-1).
+	polys := make([]polynomial, 4)
+	for i := range polys {
+		if i < 2 {
+			polys[i], err = randomPolynomial(n, degree, false, h.random)
+		} else {
+			polys[i], err = randomPolynomial(n, 2*degree, true, h.random)
+		}
+
+		if err != nil {
+			h.Unlock()
+			return nil, xerrors.Errorf("polynomial: %v", err)
+		}
+	}
+
+	sess.dealt = true
+	sess.signers = signers
+
+	commits := polys[0].commit(h.curve)
+
+	addrs := make([]mino.Address, len(signers))
+	shares := make([]types.Share, len(signers))
+	me := 0
+
+	for i, j := range signers {
+		values := make([]*big.Int, len(polys))
+		for k, poly := range polys {
+			values[k] = poly.eval(n, int64(j+1))
+		}
+
+		addrs[i] = key.addrs[j]
+		shares[i] = types.NewShare(msg.GetSession(), key.index, values, commits)
+
+		if j == key.index {
+			me = i
+		}
+	}
+
+	sess.shares[key.index] = received{from: h.me, share: shares[me]}
+
+	rpc := h.rpc
+
+	h.Unlock()
+
+	err = sendShares(rpc, addrs, me, shares)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't deal: %v", err)
+	}
+
+	return types.Ack{}, nil
+}
+
+// receive stores the share of a dealer.
+func (h *handler) receive(from mino.Address, msg types.Share) (serde.Message, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	sess := h.getSession(msg.GetSession())
+
+	_, found := sess.shares[msg.GetIndex()]
+	if found {
+		return nil, xerrors.Errorf("duplicate share of dealer %d", msg.GetIndex())
+	}
+
+	sess.shares[msg.GetIndex()] = received{from: from, share: msg}
+
+	return types.Ack{}, nil
+}
+
+// finalize combines the shares received from the dealers.
+func (h *handler) finalize(from mino.Address, msg types.Finalize) (serde.Message, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	sess, found := h.sessions[msg.GetSession()]
+	if !found || !sess.dealt {
+		return nil, xerrors.Errorf("session %s not found", msg.GetSession())
+	}
+
+	if sess.signers == nil {
+		return h.finalizeKey(msg.GetSession(), sess)
+	}
+
+	_, err := h.checkInitiator(from)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.finalizeNonce(sess)
+}
+
+// finalizeKey computes the share of the key of the participant, and returns
+// the public key.
+func (h *handler) finalizeKey(id string, sess *session) (serde.Message, error) {
+	if h.key != nil {
+		return nil, xerrors.New("key has already been generated")
+	}
+
+	dealers := make([]int, len(sess.addrs))
+	for i := range dealers {
+		dealers[i] = i
+	}
+
+	values, point, err := h.combine(sess, sess.addrs, dealers, sess.index, sess.threshold, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	h.key = &keyShare{
+		index:     sess.index,
+		threshold: sess.threshold,
+		addrs:     sess.addrs,
+		value:     values[0],
+		public:    point,
+	}
+
+	delete(h.sessions, id)
+
+	return types.NewPartial(nil, point), nil
+}
+
+// finalizeNonce computes the shares of the nonce, of the blinding factor and of
+// the masks of the signer, and returns the share of the product of the nonce
+// and the blinding factor.
+func (h *handler) finalizeNonce(sess *session) (serde.Message, error) {
+	key := h.key
+	n := h.curve.Params().N
+
+	values, point, err := h.combine(sess, key.addrs, sess.signers, key.index, key.threshold, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	// μ = k * a + z
+	mu := new(big.Int).Mul(values[0], values[1])
+	mu.Add(mu, values[2])
+	mu.Mod(mu, n)
+
+	sess.blinding = values[1]
+	sess.mask = values[3]
+	sess.point = point
+
+	return types.NewPartial(mu, point), nil
+}
+
+// sign computes the share of the signature of the hash.
+func (h *handler) sign(from mino.Address, msg types.Sign) (serde.Message, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	key, err := h.checkInitiator(from)
+	if err != nil {
+		return nil, err
+	}
+
+	sess, found := h.sessions[msg.GetSession()]
+	if !found || sess.point == nil {
+		return nil, xerrors.Errorf("session %s not found", msg.GetSession())
+	}
+
+	// The nonce must never be used for another hash, whatever the outcome.
+	delete(h.sessions, msg.GetSession())
+
+	err = h.authorize(msg.GetHash())
+	if err != nil {
+		return nil, xerrors.Errorf("unauthorized: %v", err)
+	}
+
+	n := h.curve.Params().N
+
+	mu := msg.GetMu()
+	if mu == nil || mu.Sign() == 0 || mu.Cmp(n) >= 0 {
+		return nil, xerrors.New("invalid mu")
+	}
+
+	rx, _ := elliptic.Unmarshal(h.curve, sess.point)
+	r := new(big.Int).Mod(rx, n)
+
+	// s = μ^-1 * a * (e + r*x) + w
+	s := hashToInt(msg.GetHash(), n)
+	s.Add(s, new(big.Int).Mul(r, key.value))
+	s.Mul(s, sess.blinding)
+	s.Mul(s, new(big.Int).ModInverse(mu, n))
+	s.Add(s, sess.mask)
+	s.Mod(s, n)
+
+	return types.NewPartial(s, nil), nil
+}
+
+// combine verifies the shares of the dealers and returns the sums of their
+// values, and the sum of the constant terms of the commitments.
+func (h *handler) combine(sess *session, addrs []mino.Address, dealers []int,
+	index, threshold, size int) ([]*big.Int, []byte, error) {
+
+	n := h.curve.Params().N
+
+	sums := make([]*big.Int, size)
+	for i := range sums {
+		sums[i] = new(big.Int)
+	}
+
+	px, py := new(big.Int), new(big.Int)
+
+	for _, dealer := range dealers {
+		recv, found := sess.shares[dealer]
+		if !found {
+			return nil, nil, xerrors.Errorf("missing share of %v", addrs[dealer])
+		}
+
+		if !recv.from.Equal(addrs[dealer]) {
+			return nil, nil, xerrors.Errorf("share of %v sent by %v", addrs[dealer], recv.from)
+		}
+
+		values := recv.share.GetValues()
+		commits := recv.share.GetCommits()
+
+		if len(values) != size || len(commits) != threshold {
+			return nil, nil, xerrors.Errorf("malformed share of %v", addrs[dealer])
+		}
+
+		if !verifyShare(h.curve, commits, int64(index+1), values[0]) {
+			return nil, nil, xerrors.Errorf("invalid share of %v", addrs[dealer])
+		}
+
+		for i, value := range values {
+			sums[i].Add(sums[i], value)
+			sums[i].Mod(sums[i], n)
+		}
+
+		// The commitment has been checked by the verification of the share.
+		cx, cy := elliptic.Unmarshal(h.curve, commits[0])
+		px, py = h.curve.Add(px, py, cx, cy)
+	}
+
+	if px.Sign() == 0 && py.Sign() == 0 {
+		return nil, nil, xerrors.New("point at infinity")
+	}
+
+	return sums, elliptic.Marshal(h.curve, px, py), nil
+}
+
+// checkInitiator returns the key share if the key has been generated and the
+// address is one of the participants.
+func (h *handler) checkInitiator(from mino.Address) (*keyShare, error) {
+	if h.key == nil {
+		return nil, xerrors.New("key has not been generated")
+	}
+
+	if indexOf(h.key.addrs, from) < 0 {
+		return nil, xerrors.Errorf("%v is not a participant", from)
+	}
+
+	return h.key, nil
+}
+
+// getSession returns the session of the identifier, which is created if it
+// doesn't exist yet. The sessions that have expired are dropped.
+func (h *handler) getSession(id string) *session {
+	now := time.Now()
+
+	for key, sess := range h.sessions {
+		if now.Sub(sess.created) > sessionTimeout {
+			delete(h.sessions, key)
+		}
+	}
+
+	sess, found := h.sessions[id]
+	if !found {
+		sess = &session{
+			created: now,
+			shares:  make(map[int]received),
+		}
+
+		h.sessions[id] = sess
+	}
+
+	return sess
+}
+
+func checkSigners(key *keyShare, signers []int) error {
+	if len(signers) < 2*key.threshold-1 {
+		return xerrors.Errorf("%d signers out of %d needed", len(signers), 2*key.threshold-1)
+	}
+
+	seen := make(map[int]struct{})
+	for _, index := range signers {
+		_, found := seen[index]
+		if found || index < 0 || index >= len(key.addrs) {
+			return xerrors.Errorf("invalid index %d", index)
+		}
+
+		seen[index] = struct{}{}
+	}
+
+	_, found := seen[key.index]
+	if !found {
+		return xerrors.New("participant is not a signer")
+	}
+
+	return nil
+}
+
+// sendShares sends the shares to the participants of the addresses, except to
+// the dealer itself.
+func sendShares(rpc mino.RPC, addrs []mino.Address, me int, shares []types.Share) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dealTimeout)
+	defer cancel()
+
+	for i, addr := range addrs {
+		if i == me {
+			continue
+		}
+
+		resps, err := rpc.Call(ctx, shares[i], mino.NewAddresses(addr))
+		if err != nil {
+			return xerrors.Errorf("call failed: %v", err)
+		}
+
+		resp, more := <-resps
+		if !more {
+			return xerrors.Errorf("no reply from %v", addr)
+		}
+
+		_, err = resp.GetMessageOrError()
+		if err != nil {
+			return xerrors.Errorf("%v replied: %v", addr, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package tecdsa
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/dkg/tecdsa/types"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+)
+
+func TestHandler_Process(t *testing.T) {
+	h := newHandler(fake.NewAddress(0), Secp256k1(), nil)
+
+	_, err := h.Process(mino.Request{Message: fake.Message{}})
+	require.EqualError(t, err, "unexpected message 'fake.Message'")
+
+	_, err = h.Process(mino.Request{Message: types.Probe{}})
+	require.EqualError(t, err, "key has not been generated")
+
+	h.key = &keyShare{}
+
+	resp, err := h.Process(mino.Request{Message: types.Probe{}})
+	require.NoError(t, err)
+	require.Equal(t, types.Ack{}, resp)
+}
+
+func TestHandler_Deal(t *testing.T) {
+	h := newHandler(fake.NewAddress(0), Secp256k1(), nil)
+	h.setRPC(fake.NewBadPlayerRPC())
+
+	addrs := []mino.Address{fake.NewAddress(0), fake.NewAddress(1), fake.NewAddress(2)}
+
+	_, err := h.deal(types.NewDeal("a", 2, addrs[1:]))
+	require.EqualError(t, err, "fake.Address[0] is not a participant")
+
+	_, err = h.deal(types.NewDeal("a", 3, addrs))
+	require.EqualError(t, err, "invalid threshold: 3 needs 5 signers out of 3")
+
+	h.random = badReader{}
+
+	_, err = h.deal(types.NewDeal("a", 2, addrs))
+	require.EqualError(t, err, fake.Err("polynomial"))
+
+	h.random = rand.Reader
+
+	_, err = h.deal(types.NewDeal("a", 2, addrs))
+	require.EqualError(t, err, fake.Err("couldn't deal: call failed"))
+
+	_, err = h.deal(types.NewDeal("a", 2, addrs))
+	require.EqualError(t, err, "session a already dealt")
+
+	h.key = &keyShare{}
+
+	_, err = h.deal(types.NewDeal("b", 2, addrs))
+	require.EqualError(t, err, "key has already been generated")
+}
+
+func TestHandler_Nonce(t *testing.T) {
+	h := newHandler(fake.NewAddress(0), Secp256k1(), nil)
+
+	_, err := h.nonce(fake.NewAddress(0), types.NewNonce("a", nil))
+	require.EqualError(t, err, "key has not been generated")
+
+	h.key = &keyShare{
+		index:     0,
+		threshold: 2,
+		addrs:     []mino.Address{fake.NewAddress(0), fake.NewAddress(1), fake.NewAddress(2)},
+	}
+
+	_, err = h.nonce(fake.NewAddress(5), types.NewNonce("a", nil))
+	require.EqualError(t, err, "fake.Address[5] is not a participant")
+
+	_, err = h.nonce(fake.NewAddress(1), types.NewNonce("a", []int{0, 1}))
+	require.EqualError(t, err, "invalid signers: 2 signers out of 3 needed")
+
+	_, err = h.nonce(fake.NewAddress(1), types.NewNonce("a", []int{0, 1, 1}))
+	require.EqualError(t, err, "invalid signers: invalid index 1")
+
+	_, err = h.nonce(fake.NewAddress(1), types.NewNonce("a", []int{0, 1, 3}))
+	require.EqualError(t, err, "invalid signers: invalid index 3")
+
+	h.key.index = 1
+	h.key.addrs = append(h.key.addrs, fake.NewAddress(3))
+
+	_, err = h.nonce(fake.NewAddress(1), types.NewNonce("a", []int{0, 2, 3}))
+	require.EqualError(t, err, "invalid signers: participant is not a signer")
+}
+
+func TestHandler_Receive(t *testing.T) {
+	h := newHandler(fake.NewAddress(0), Secp256k1(), nil)
+
+	share := types.NewShare("a", 1, nil, nil)
+
+	resp, err := h.Process(mino.Request{Address: fake.NewAddress(1), Message: share})
+	require.NoError(t, err)
+	require.Equal(t, types.Ack{}, resp)
+
+	_, err = h.Process(mino.Request{Address: fake.NewAddress(1), Message: share})
+	require.EqualError(t, err, "duplicate share of dealer 1")
+}
+
+func TestHandler_Finalize(t *testing.T) {
+	actors, net := makeNetwork(3, Secp256k1(), nil)
+	h := net.handlers[fake.NewAddress(0)]
+
+	_, err := h.finalize(fake.NewAddress(0), types.NewFinalize("a"))
+	require.EqualError(t, err, "session a not found")
+
+	addrs := []mino.Address{fake.NewAddress(0), fake.NewAddress(1), fake.NewAddress(2)}
+
+	// Shares are missing when only one participant has dealt.
+	_, err = h.deal(types.NewDeal("a", 2, addrs))
+	require.NoError(t, err)
+
+	_, err = h.finalize(fake.NewAddress(0), types.NewFinalize("a"))
+	require.EqualError(t, err, "missing share of fake.Address[1]")
+
+	// A share sent by another participant than the dealer is rejected.
+	h.sessions["a"].shares[1] = received{from: fake.NewAddress(2)}
+
+	_, err = h.finalize(fake.NewAddress(0), types.NewFinalize("a"))
+	require.EqualError(t, err, "share of fake.Address[1] sent by fake.Address[2]")
+
+	// A share that doesn't match the commitments is rejected.
+	share := h.sessions["a"].shares[0].share
+	h.sessions["a"].shares[1] = received{
+		from:  fake.NewAddress(1),
+		share: types.NewShare("a", 1, []*big.Int{big.NewInt(1)}, share.GetCommits()),
+	}
+
+	_, err = h.finalize(fake.NewAddress(0), types.NewFinalize("a"))
+	require.EqualError(t, err, "invalid share of fake.Address[1]")
+
+	h.sessions["a"].shares[1] = received{
+		from:  fake.NewAddress(1),
+		share: types.NewShare("a", 1, nil, nil),
+	}
+
+	_, err = h.finalize(fake.NewAddress(0), types.NewFinalize("a"))
+	require.EqualError(t, err, "malformed share of fake.Address[1]")
+
+	// A signature session can only be finalized by a participant.
+	_, err = actors[1].Setup(mino.NewAddresses(addrs...), 2)
+	require.NoError(t, err)
+
+	_, err = h.nonce(fake.NewAddress(1), types.NewNonce("b", []int{0, 1, 2}))
+	require.NoError(t, err)
+
+	_, err = h.finalize(fake.NewAddress(5), types.NewFinalize("b"))
+	require.EqualError(t, err, "fake.Address[5] is not a participant")
+}
+
+func TestHandler_Sign(t *testing.T) {
+	actors, net := makeNetwork(3, Secp256k1(), nil)
+	h := net.handlers[fake.NewAddress(0)]
+
+	addrs := []mino.Address{fake.NewAddress(0), fake.NewAddress(1), fake.NewAddress(2)}
+
+	_, err := actors[0].Setup(mino.NewAddresses(addrs...), 2)
+	require.NoError(t, err)
+
+	_, err = h.sign(fake.NewAddress(5), types.NewSign("a", nil, nil))
+	require.EqualError(t, err, "fake.Address[5] is not a participant")
+
+	_, err = h.sign(fake.NewAddress(1), types.NewSign("a", nil, nil))
+	require.EqualError(t, err, "session a not found")
+
+	for _, a := range addrs {
+		_, err = net.handlers[a].nonce(fake.NewAddress(1), types.NewNonce("a", []int{0, 1, 2}))
+		require.NoError(t, err)
+	}
+
+	_, err = h.finalize(fake.NewAddress(1), types.NewFinalize("a"))
+	require.NoError(t, err)
+
+	_, err = h.sign(fake.NewAddress(1), types.NewSign("a", big.NewInt(0), []byte{1}))
+	require.EqualError(t, err, "invalid mu")
+
+	// The nonce is dropped after the first attempt.
+	_, err = h.sign(fake.NewAddress(1), types.NewSign("a", big.NewInt(1), []byte{1}))
+	require.EqualError(t, err, "session a not found")
+}
+
+func TestHandler_GetSession(t *testing.T) {
+	h := newHandler(fake.NewAddress(0), Secp256k1(), nil)
+
+	sess := h.getSession("a")
+	require.Same(t, sess, h.getSession("a"))
+
+	sess.created = sess.created.Add(-2 * sessionTimeout)
+
+	require.NotSame(t, sess, h.getSession("a"))
+}
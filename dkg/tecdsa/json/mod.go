@@ -0,0 +1,209 @@
+package json
+
+import (
+	"math/big"
+
+	"go.dedis.ch/dela/dkg/tecdsa/types"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	types.RegisterMessageFormat(serde.FormatJSON, msgFormat{})
+}
+
+// Deal is the JSON message of a deal request.
+type Deal struct {
+	Session   string
+	Threshold int
+	Addresses [][]byte
+}
+
+// Probe is the JSON message of a probe.
+type Probe struct{}
+
+// Nonce is the JSON message of a nonce request.
+type Nonce struct {
+	Session string
+	Signers []int
+}
+
+// Share is the JSON message of a share.
+type Share struct {
+	Session string
+	Index   int
+	Values  [][]byte
+	Commits [][]byte
+}
+
+// Finalize is the JSON message of a finalize request.
+type Finalize struct {
+	Session string
+}
+
+// Sign is the JSON message of a sign request.
+type Sign struct {
+	Session string
+	Mu      []byte
+	Hash    []byte
+}
+
+// Partial is the JSON message of a partial reply.
+type Partial struct {
+	Value []byte `json:",omitempty"`
+	Point []byte `json:",omitempty"`
+}
+
+// Ack is the JSON message of an acknowledgement.
+type Ack struct{}
+
+// Message is a JSON container for the messages of the protocol.
+type Message struct {
+	Deal     *Deal     `json:",omitempty"`
+	Probe    *Probe    `json:",omitempty"`
+	Nonce    *Nonce    `json:",omitempty"`
+	Share    *Share    `json:",omitempty"`
+	Finalize *Finalize `json:",omitempty"`
+	Sign     *Sign     `json:",omitempty"`
+	Partial  *Partial  `json:",omitempty"`
+	Ack      *Ack      `json:",omitempty"`
+}
+
+// MsgFormat is the engine to encode and decode the messages of the threshold
+// ECDSA in JSON format.
+//
+// - implements serde.FormatEngine
+type msgFormat struct{}
+
+// Encode implements serde.FormatEngine. It returns the serialized data for the
+// message in JSON format.
+func (f msgFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	var m Message
+
+	switch in := msg.(type) {
+	case types.Deal:
+		addrs := make([][]byte, len(in.GetAddresses()))
+		for i, addr := range in.GetAddresses() {
+			data, err := addr.MarshalText()
+			if err != nil {
+				return nil, xerrors.Errorf("couldn't marshal address: %v", err)
+			}
+
+			addrs[i] = data
+		}
+
+		m.Deal = &Deal{
+			Session:   in.GetSession(),
+			Threshold: in.GetThreshold(),
+			Addresses: addrs,
+		}
+	case types.Probe:
+		m.Probe = &Probe{}
+	case types.Nonce:
+		m.Nonce = &Nonce{
+			Session: in.GetSession(),
+			Signers: in.GetSigners(),
+		}
+	case types.Share:
+		values := make([][]byte, len(in.GetValues()))
+		for i, value := range in.GetValues() {
+			values[i] = value.Bytes()
+		}
+
+		m.Share = &Share{
+			Session: in.GetSession(),
+			Index:   in.GetIndex(),
+			Values:  values,
+			Commits: in.GetCommits(),
+		}
+	case types.Finalize:
+		m.Finalize = &Finalize{
+			Session: in.GetSession(),
+		}
+	case types.Sign:
+		m.Sign = &Sign{
+			Session: in.GetSession(),
+			Mu:      in.GetMu().Bytes(),
+			Hash:    in.GetHash(),
+		}
+	case types.Partial:
+		partial := Partial{Point: in.GetPoint()}
+		if in.GetValue() != nil {
+			partial.Value = in.GetValue().Bytes()
+
+			// The zero value is kept apart from an absent value.
+			if len(partial.Value) == 0 {
+				partial.Value = []byte{0}
+			}
+		}
+
+		m.Partial = &partial
+	case types.Ack:
+		m.Ack = &Ack{}
+	default:
+		return nil, xerrors.Errorf("unsupported message of type '%T'", msg)
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the message from the JSON
+// data if appropriate, otherwise it returns an error.
+func (f msgFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := Message{}
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't deserialize message: %v", err)
+	}
+
+	switch {
+	case m.Deal != nil:
+		factory := ctx.GetFactory(types.AddrKey{})
+
+		fac, ok := factory.(mino.AddressFactory)
+		if !ok {
+			return nil, xerrors.Errorf("invalid factory of type '%T'", factory)
+		}
+
+		addrs := make([]mino.Address, len(m.Deal.Addresses))
+		for i, addr := range m.Deal.Addresses {
+			addrs[i] = fac.FromText(addr)
+		}
+
+		return types.NewDeal(m.Deal.Session, m.Deal.Threshold, addrs), nil
+	case m.Probe != nil:
+		return types.Probe{}, nil
+	case m.Nonce != nil:
+		return types.NewNonce(m.Nonce.Session, m.Nonce.Signers), nil
+	case m.Share != nil:
+		values := make([]*big.Int, len(m.Share.Values))
+		for i, value := range m.Share.Values {
+			values[i] = new(big.Int).SetBytes(value)
+		}
+
+		return types.NewShare(m.Share.Session, m.Share.Index, values, m.Share.Commits), nil
+	case m.Finalize != nil:
+		return types.NewFinalize(m.Finalize.Session), nil
+	case m.Sign != nil:
+		mu := new(big.Int).SetBytes(m.Sign.Mu)
+
+		return types.NewSign(m.Sign.Session, mu, m.Sign.Hash), nil
+	case m.Partial != nil:
+		var value *big.Int
+		if m.Partial.Value != nil {
+			value = new(big.Int).SetBytes(m.Partial.Value)
+		}
+
+		return types.NewPartial(value, m.Partial.Point), nil
+	case m.Ack != nil:
+		return types.Ack{}, nil
+	}
+
+	return nil, xerrors.New("message is empty")
+}
@@ -0,0 +1,114 @@
+package json
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/dkg/tecdsa/types"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestMessageFormat_Deal_Encode(t *testing.T) {
+	deal := types.NewDeal("abc", 2, []mino.Address{fake.NewAddress(0)})
+
+	format := msgFormat{}
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	data, err := format.Encode(ctx, deal)
+	require.NoError(t, err)
+	expected := `{"Deal":{"Session":"abc","Threshold":2,"Addresses":["AAAAAA=="]}}`
+	require.Equal(t, expected, string(data))
+
+	deal = types.NewDeal("abc", 2, []mino.Address{fake.NewBadAddress()})
+	_, err = format.Encode(ctx, deal)
+	require.EqualError(t, err, fake.Err("couldn't marshal address"))
+
+	_, err = format.Encode(fake.NewBadContext(), types.Ack{})
+	require.EqualError(t, err, fake.Err("couldn't marshal"))
+
+	_, err = format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message of type 'fake.Message'")
+}
+
+func TestMessageFormat_Share_Encode(t *testing.T) {
+	share := types.NewShare("abc", 1, []*big.Int{big.NewInt(1)}, [][]byte{{2}})
+
+	format := msgFormat{}
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	data, err := format.Encode(ctx, share)
+	require.NoError(t, err)
+	expected := `{"Share":{"Session":"abc","Index":1,"Values":["AQ=="],"Commits":["Ag=="]}}`
+	require.Equal(t, expected, string(data))
+}
+
+func TestMessageFormat_Partial_Encode(t *testing.T) {
+	format := msgFormat{}
+	ctx := serde.NewContext(fake.ContextEngine{})
+
+	data, err := format.Encode(ctx, types.NewPartial(big.NewInt(0), nil))
+	require.NoError(t, err)
+	require.Equal(t, `{"Partial":{"Value":"AA=="}}`, string(data))
+
+	data, err = format.Encode(ctx, types.NewPartial(nil, []byte{1}))
+	require.NoError(t, err)
+	require.Equal(t, `{"Partial":{"Point":"AQ=="}}`, string(data))
+}
+
+func TestMessageFormat_Decode(t *testing.T) {
+	format := msgFormat{}
+	ctx := serde.NewContext(fake.ContextEngine{})
+	ctx = serde.WithFactory(ctx, types.AddrKey{}, fake.AddressFactory{})
+
+	// Decode deal messages.
+	expected := types.NewDeal("abc", 2, []mino.Address{fake.NewAddress(0)})
+
+	data, err := format.Encode(ctx, expected)
+	require.NoError(t, err)
+
+	deal, err := format.Decode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, expected.GetSession(), deal.(types.Deal).GetSession())
+	require.Equal(t, expected.GetThreshold(), deal.(types.Deal).GetThreshold())
+	require.Len(t, deal.(types.Deal).GetAddresses(), 1)
+
+	badCtx := serde.WithFactory(ctx, types.AddrKey{}, nil)
+	_, err = format.Decode(badCtx, []byte(`{"Deal":{}}`))
+	require.EqualError(t, err, "invalid factory of type '<nil>'")
+
+	// Decode the other messages.
+	msgs := []serde.Message{
+		types.Probe{},
+		types.NewNonce("abc", []int{0, 1, 2}),
+		types.NewShare("abc", 1, []*big.Int{big.NewInt(1), big.NewInt(2)}, [][]byte{{3}}),
+		types.NewFinalize("abc"),
+		types.NewSign("abc", big.NewInt(5), []byte{6}),
+		types.NewPartial(big.NewInt(1), []byte{1}),
+		types.NewPartial(nil, []byte{1}),
+		types.Ack{},
+	}
+
+	for _, msg := range msgs {
+		data, err := format.Encode(ctx, msg)
+		require.NoError(t, err)
+
+		decoded, err := format.Decode(ctx, data)
+		require.NoError(t, err)
+		require.Equal(t, msg, decoded)
+	}
+
+	// The zero value is not mistaken for an absent one.
+	partial, err := format.Decode(ctx, []byte(`{"Partial":{"Value":"AA=="}}`))
+	require.NoError(t, err)
+	require.NotNil(t, partial.(types.Partial).GetValue())
+	require.Equal(t, 0, partial.(types.Partial).GetValue().Sign())
+
+	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("couldn't deserialize message"))
+
+	_, err = format.Decode(ctx, []byte(`{}`))
+	require.EqualError(t, err, "message is empty")
+}
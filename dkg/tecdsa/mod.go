@@ -0,0 +1,456 @@
+// Package tecdsa implements a threshold ECDSA so that a roster can collectively
+// control a key of an external system, like a Bitcoin or an Ethereum account,
+// without any participant ever knowing the private key.
+//
+// The key is generated with a joint Feldman verifiable secret sharing, where
+// every participant deals a random polynomial of degree t-1 and receives the
+// share of every other participant. A signature is produced with the protocol
+// of Gennaro, Jarecki, Krawczyk and Rabin for an honest majority: the signers
+// share a random nonce k and a random blinding factor a, reveal the product
+// k*a which doesn't disclose k, and each of them computes a share of
+// s = k^-1 * (e + r*x) from the product of its shares. The shares of the
+// products are masked with random sharings of zero so that they don't leak the
+// shares of the factors. The products are of degree 2(t-1), therefore a
+// signature needs 2t-1 participants online out of n, where t is the threshold.
+//
+// The shares are sent with direct calls between the participants, which relies
+// on the secure channels of the overlay. The protocol is secure against passive
+// adversaries controlling less than t participants. An active adversary can
+// make a signature fail, but the signature is verified against the public key
+// before it is returned. A participant only contributes to the signatures of
+// the hashes accepted by its authorizer, which is where the governance of the
+// roster is enforced. Without an authorizer, a participant refuses every hash.
+//
+// The signatures are normalized with a low s as required by Ethereum and by the
+// standard transactions of Bitcoin, and they come with the recovery identifier
+// of Ethereum.
+//
+// Related Papers:
+//
+// Robust Threshold DSS Signatures (1996)
+//
+// Secure Distributed Key Generation for Discrete-Log Based Cryptosystems (1999)
+//
+package tecdsa
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"time"
+
+	"go.dedis.ch/dela/dkg/tecdsa/types"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+const (
+	rpcName = "tecdsa"
+
+	setupTimeout = 5 * time.Minute
+	signTimeout  = time.Minute
+)
+
+// Authorizer is the function that a participant calls before it contributes to
+// the signature of a hash. It returns an error if the hash must not be signed.
+type Authorizer func(hash []byte) error
+
+// Option is the type of option to configure the threshold ECDSA.
+type Option func(*TECDSA)
+
+// WithCurve sets the curve of the keys. The default is secp256k1.
+func WithCurve(c elliptic.Curve) Option {
+	return func(t *TECDSA) {
+		t.curve = c
+	}
+}
+
+// WithAuthorizer sets the function that decides which hashes the participant
+// agrees to sign. By default, every hash is refused so that the key cannot be
+// used without a governance.
+func WithAuthorizer(fn Authorizer) Option {
+	return func(t *TECDSA) {
+		t.authorize = fn
+	}
+}
+
+// denyAll is the default authorizer that refuses every hash.
+func denyAll([]byte) error {
+	return xerrors.New("no authorizer")
+}
+
+// TECDSA allows one to listen for the threshold ECDSA protocol.
+type TECDSA struct {
+	mino      mino.Mino
+	factory   serde.Factory
+	curve     elliptic.Curve
+	authorize Authorizer
+}
+
+// NewTECDSA returns a new threshold ECDSA for the overlay.
+func NewTECDSA(m mino.Mino, opts ...Option) *TECDSA {
+	t := &TECDSA{
+		mino:      m,
+		factory:   types.NewMessageFactory(m.GetAddressFactory()),
+		curve:     Secp256k1(),
+		authorize: denyAll,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// Listen creates the RPC of the protocol. It must be called on each node that
+// participates.
+func (t *TECDSA) Listen() (*Actor, error) {
+	h := newHandler(t.mino.GetAddress(), t.curve, t.authorize)
+
+	rpc, err := t.mino.CreateRPC(rpcName, mino.WithPolicy(h, mino.ProtocolPolicy), t.factory)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't create rpc: %v", err)
+	}
+
+	h.setRPC(rpc)
+
+	a := &Actor{
+		rpc:     rpc,
+		handler: h,
+		curve:   t.curve,
+	}
+
+	return a, nil
+}
+
+// Signature is an ECDSA signature with the recovery identifier of the public
+// key.
+type Signature struct {
+	R *big.Int
+	S *big.Int
+	// V is the parity of the y-coordinate of the nonce point, plus two when
+	// its x-coordinate overflows the order of the curve.
+	V byte
+}
+
+// Actor allows one to generate the key of the roster and to sign with it.
+type Actor struct {
+	rpc     mino.RPC
+	handler *handler
+	curve   elliptic.Curve
+}
+
+// Setup generates the key of the participants, which is shared so that t of
+// them can recover it, and 2t-1 can sign. It must be called by only one of
+// them.
+func (a *Actor) Setup(players mino.Players, threshold int) (*ecdsa.PublicKey, error) {
+	addrs := make([]mino.Address, 0, players.Len())
+
+	iter := players.AddressIterator()
+	for iter.HasNext() {
+		addrs = append(addrs, iter.GetNext())
+	}
+
+	err := checkThreshold(threshold, len(addrs))
+	if err != nil {
+		return nil, xerrors.Errorf("invalid threshold: %v", err)
+	}
+
+	session, err := newSessionID()
+	if err != nil {
+		return nil, xerrors.Errorf("session: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), setupTimeout)
+	defer cancel()
+
+	_, err = a.call(ctx, types.NewDeal(session, threshold, addrs), addrs)
+	if err != nil {
+		return nil, xerrors.Errorf("deal failed: %v", err)
+	}
+
+	replies, err := a.call(ctx, types.NewFinalize(session), addrs)
+	if err != nil {
+		return nil, xerrors.Errorf("finalize failed: %v", err)
+	}
+
+	point, _, err := agree(replies, false)
+	if err != nil {
+		return nil, xerrors.Errorf("finalize failed: %v", err)
+	}
+
+	x, y := elliptic.Unmarshal(a.curve, point)
+	if x == nil {
+		return nil, xerrors.New("invalid public key")
+	}
+
+	return &ecdsa.PublicKey{Curve: a.curve, X: x, Y: y}, nil
+}
+
+// GetPublicKey returns the public key of the roster, or an error if the key has
+// not been generated.
+func (a *Actor) GetPublicKey() (*ecdsa.PublicKey, error) {
+	key := a.handler.getKey()
+	if key == nil {
+		return nil, xerrors.New("key has not been generated")
+	}
+
+	return key.getPublicKey(a.curve), nil
+}
+
+// Sign returns the signature of the hash by the roster. The signers are the
+// first 2t-1 participants online, and each of them must authorize the hash.
+func (a *Actor) Sign(hash []byte) (Signature, error) {
+	key := a.handler.getKey()
+	if key == nil {
+		return Signature{}, xerrors.New("key has not been generated")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), signTimeout)
+	defer cancel()
+
+	signers, err := a.probe(ctx, key)
+	if err != nil {
+		return Signature{}, xerrors.Errorf("probe failed: %v", err)
+	}
+
+	addrs := make([]mino.Address, len(signers))
+	xs := make([]int64, len(signers))
+
+	for i, index := range signers {
+		addrs[i] = key.addrs[index]
+		xs[i] = int64(index + 1)
+	}
+
+	session, err := newSessionID()
+	if err != nil {
+		return Signature{}, xerrors.Errorf("session: %v", err)
+	}
+
+	_, err = a.call(ctx, types.NewNonce(session, signers), addrs)
+	if err != nil {
+		return Signature{}, xerrors.Errorf("nonce failed: %v", err)
+	}
+
+	replies, err := a.call(ctx, types.NewFinalize(session), addrs)
+	if err != nil {
+		return Signature{}, xerrors.Errorf("finalize failed: %v", err)
+	}
+
+	point, values, err := agree(replies, true)
+	if err != nil {
+		return Signature{}, xerrors.Errorf("finalize failed: %v", err)
+	}
+
+	n := a.curve.Params().N
+
+	mu := interpolate(n, xs, values)
+	if mu.Sign() == 0 {
+		return Signature{}, xerrors.New("nonce is not invertible")
+	}
+
+	replies, err = a.call(ctx, types.NewSign(session, mu, hash), addrs)
+	if err != nil {
+		return Signature{}, xerrors.Errorf("sign failed: %v", err)
+	}
+
+	_, values, err = agree(replies, true)
+	if err != nil {
+		return Signature{}, xerrors.Errorf("sign failed: %v", err)
+	}
+
+	rx, ry := elliptic.Unmarshal(a.curve, point)
+	if rx == nil {
+		return Signature{}, xerrors.New("invalid nonce point")
+	}
+
+	sig := Signature{
+		R: new(big.Int).Mod(rx, n),
+		S: interpolate(n, xs, values),
+		V: byte(ry.Bit(0)),
+	}
+
+	if rx.Cmp(n) >= 0 {
+		sig.V |= 2
+	}
+
+	// The signature with the lower s is the canonical one.
+	if sig.S.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		sig.S.Sub(n, sig.S)
+		sig.V ^= 1
+	}
+
+	if !Verify(key.getPublicKey(a.curve), hash, sig) {
+		return Signature{}, xerrors.New("invalid signature")
+	}
+
+	return sig, nil
+}
+
+// probe returns the indices of the first 2t-1 participants online.
+func (a *Actor) probe(ctx context.Context, key *keyShare) ([]int, error) {
+	needed := 2*key.threshold - 1
+
+	resps, err := a.rpc.Call(ctx, types.Probe{}, mino.NewAddresses(key.addrs...))
+	if err != nil {
+		return nil, xerrors.Errorf("call failed: %v", err)
+	}
+
+	online := make([]bool, len(key.addrs))
+	for resp := range resps {
+		_, err := resp.GetMessageOrError()
+		if err != nil {
+			continue
+		}
+
+		index := indexOf(key.addrs, resp.GetFrom())
+		if index >= 0 {
+			online[index] = true
+		}
+	}
+
+	signers := make([]int, 0, needed)
+	for index, ok := range online {
+		if ok && len(signers) < needed {
+			signers = append(signers, index)
+		}
+	}
+
+	if len(signers) < needed {
+		return nil, xerrors.Errorf("only %d participants online out of %d needed",
+			len(signers), needed)
+	}
+
+	return signers, nil
+}
+
+// call sends the message to the participants and returns their replies in the
+// order of the addresses, or an error if any of them fails.
+func (a *Actor) call(ctx context.Context, msg serde.Message,
+	addrs []mino.Address) ([]serde.Message, error) {
+
+	resps, err := a.rpc.Call(ctx, msg, mino.NewAddresses(addrs...))
+	if err != nil {
+		return nil, xerrors.Errorf("call failed: %v", err)
+	}
+
+	replies := make([]serde.Message, len(addrs))
+
+	for resp := range resps {
+		reply, err := resp.GetMessageOrError()
+		if err != nil {
+			return nil, xerrors.Errorf("%v replied: %v", resp.GetFrom(), err)
+		}
+
+		index := indexOf(addrs, resp.GetFrom())
+		if index < 0 {
+			return nil, xerrors.Errorf("unexpected reply from %v", resp.GetFrom())
+		}
+
+		replies[index] = reply
+	}
+
+	for i, reply := range replies {
+		if reply == nil {
+			return nil, xerrors.Errorf("no reply from %v", addrs[i])
+		}
+	}
+
+	return replies, nil
+}
+
+// Verify returns true if the signature of the hash matches the public key.
+func Verify(pub *ecdsa.PublicKey, hash []byte, sig Signature) bool {
+	curve := pub.Curve
+	n := curve.Params().N
+
+	if sig.R == nil || sig.S == nil ||
+		sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || sig.R.Cmp(n) >= 0 || sig.S.Cmp(n) >= 0 {
+		return false
+	}
+
+	e := hashToInt(hash, n)
+	w := new(big.Int).ModInverse(sig.S, n)
+
+	u1 := e.Mul(e, w)
+	u1.Mod(u1, n)
+
+	u2 := w.Mul(sig.R, w)
+	u2.Mod(u2, n)
+
+	x1, y1 := curve.ScalarBaseMult(u1.Bytes())
+	x2, y2 := curve.ScalarMult(pub.X, pub.Y, u2.Bytes())
+	x, y := curve.Add(x1, y1, x2, y2)
+
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return false
+	}
+
+	return x.Mod(x, n).Cmp(sig.R) == 0
+}
+
+// agree returns the point of the partial replies, which must be the same for
+// all of them, and their values, which must be set if required.
+func agree(replies []serde.Message, withValue bool) ([]byte, []*big.Int, error) {
+	var point []byte
+
+	values := make([]*big.Int, len(replies))
+
+	for i, reply := range replies {
+		partial, ok := reply.(types.Partial)
+		if !ok {
+			return nil, nil, xerrors.Errorf("invalid reply '%T'", reply)
+		}
+
+		if i > 0 && string(point) != string(partial.GetPoint()) {
+			return nil, nil, xerrors.New("participants disagree on the point")
+		}
+
+		if withValue && partial.GetValue() == nil {
+			return nil, nil, xerrors.New("missing value")
+		}
+
+		point = partial.GetPoint()
+		values[i] = partial.GetValue()
+	}
+
+	return point, values, nil
+}
+
+func checkThreshold(threshold, n int) error {
+	if threshold < 1 {
+		return xerrors.Errorf("%d is below 1", threshold)
+	}
+
+	if 2*threshold-1 > n {
+		return xerrors.Errorf("%d needs %d signers out of %d", threshold, 2*threshold-1, n)
+	}
+
+	return nil
+}
+
+func indexOf(addrs []mino.Address, addr mino.Address) int {
+	for i, a := range addrs {
+		if a.Equal(addr) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func newSessionID() (string, error) {
+	buffer := make([]byte, 16)
+
+	_, err := rand.Read(buffer)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buffer), nil
+}
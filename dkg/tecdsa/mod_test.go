@@ -0,0 +1,330 @@
+package tecdsa
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/dkg/tecdsa/types"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minogrpc"
+	"go.dedis.ch/dela/mino/router/tree"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func TestTECDSA_Listen(t *testing.T) {
+	tecdsa := NewTECDSA(fake.Mino{}, WithCurve(elliptic.P256()))
+
+	actor, err := tecdsa.Listen()
+	require.NoError(t, err)
+	require.NotNil(t, actor)
+	require.Equal(t, elliptic.P256(), actor.curve)
+
+	_, err = actor.GetPublicKey()
+	require.EqualError(t, err, "key has not been generated")
+
+	_, err = actor.Sign([]byte{1})
+	require.EqualError(t, err, "key has not been generated")
+
+	// Every hash is refused without an authorizer.
+	require.EqualError(t, actor.handler.authorize([]byte{1}), "no authorizer")
+
+	tecdsa = NewTECDSA(fake.Mino{}, WithAuthorizer(func([]byte) error { return nil }))
+
+	actor, err = tecdsa.Listen()
+	require.NoError(t, err)
+	require.NoError(t, actor.handler.authorize([]byte{1}))
+}
+
+func TestActor_Scenario(t *testing.T) {
+	for _, curve := range []elliptic.Curve{Secp256k1(), elliptic.P256()} {
+		t.Run(curve.Params().Name, func(t *testing.T) {
+			actors, _ := makeNetwork(5, curve, nil)
+
+			pubkey, err := actors[0].Setup(fake.NewAuthority(5, fake.NewSigner), 2)
+			require.NoError(t, err)
+
+			for i, actor := range actors {
+				key, err := actor.GetPublicKey()
+				require.NoError(t, err)
+				require.Equal(t, pubkey, key)
+
+				hash := sha256.Sum256([]byte(fmt.Sprintf("message #%d", i)))
+
+				sig, err := actor.Sign(hash[:])
+				require.NoError(t, err)
+				require.True(t, Verify(pubkey, hash[:], sig))
+				require.True(t, ecdsa.Verify(pubkey, hash[:], sig.R, sig.S))
+				require.True(t, sig.S.Cmp(new(big.Int).Rsh(curve.Params().N, 1)) <= 0)
+			}
+		})
+	}
+}
+
+func TestActor_Scenario_Offline(t *testing.T) {
+	actors, network := makeNetwork(5, Secp256k1(), nil)
+
+	pubkey, err := actors[0].Setup(fake.NewAuthority(5, fake.NewSigner), 2)
+	require.NoError(t, err)
+
+	// Three signers are enough with a threshold of two.
+	network.offline[fake.NewAddress(1)] = true
+	network.offline[fake.NewAddress(3)] = true
+
+	sig, err := actors[4].Sign([]byte{1, 2, 3})
+	require.NoError(t, err)
+	require.True(t, Verify(pubkey, []byte{1, 2, 3}, sig))
+
+	network.offline[fake.NewAddress(2)] = true
+
+	_, err = actors[4].Sign([]byte{1, 2, 3})
+	require.EqualError(t, err, "probe failed: only 2 participants online out of 3 needed")
+}
+
+func TestActor_Scenario_Unauthorized(t *testing.T) {
+	refuse := func(hash []byte) error {
+		if hash[0] == 0 {
+			return fake.GetError()
+		}
+
+		return nil
+	}
+
+	actors, _ := makeNetwork(3, Secp256k1(), refuse)
+
+	_, err := actors[0].Setup(fake.NewAuthority(3, fake.NewSigner), 2)
+	require.NoError(t, err)
+
+	_, err = actors[0].Sign([]byte{0})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), fake.Err("unauthorized"))
+
+	_, err = actors[0].Sign([]byte{1})
+	require.NoError(t, err)
+}
+
+func TestActor_Scenario_DefaultAuthorizer(t *testing.T) {
+	actors, _ := makeNetwork(3, Secp256k1(), denyAll)
+
+	_, err := actors[0].Setup(fake.NewAuthority(3, fake.NewSigner), 2)
+	require.NoError(t, err)
+
+	// A single participant cannot make the group sign a hash on its own.
+	_, err = actors[0].Sign([]byte{1})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unauthorized: no authorizer")
+}
+
+func TestActor_Setup(t *testing.T) {
+	actors, _ := makeNetwork(3, Secp256k1(), nil)
+
+	authority := fake.NewAuthority(3, fake.NewSigner)
+
+	_, err := actors[0].Setup(authority, 0)
+	require.EqualError(t, err, "invalid threshold: 0 is below 1")
+
+	_, err = actors[0].Setup(authority, 3)
+	require.EqualError(t, err, "invalid threshold: 3 needs 5 signers out of 3")
+
+	_, err = actors[0].Setup(authority, 2)
+	require.NoError(t, err)
+
+	_, err = actors[0].Setup(authority, 2)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "key has already been generated")
+
+	actor := Actor{rpc: fake.NewBadPlayerRPC(), curve: Secp256k1()}
+
+	_, err = actor.Setup(authority, 2)
+	require.EqualError(t, err, fake.Err("deal failed: call failed"))
+
+	actor.rpc = fake.NewPlayerRPC(func(mino.Address, serde.Message) fake.Reply {
+		return fake.Reply{Err: fake.GetError()}
+	})
+
+	_, err = actor.Setup(authority, 2)
+	require.EqualError(t, err, fake.Err("deal failed: fake.Address[0] replied"))
+
+	actor.rpc = fake.NewPlayerRPC(func(mino.Address, serde.Message) fake.Reply {
+		return fake.Reply{Message: types.Ack{}}
+	})
+
+	_, err = actor.Setup(authority, 2)
+	require.EqualError(t, err, "finalize failed: invalid reply 'types.Ack'")
+
+	actor.rpc = fake.NewPlayerRPC(func(mino.Address, serde.Message) fake.Reply {
+		return fake.Reply{Message: types.NewPartial(nil, []byte{1})}
+	})
+
+	_, err = actor.Setup(authority, 2)
+	require.EqualError(t, err, "invalid public key")
+}
+
+func TestActor_Call(t *testing.T) {
+	addrs := []mino.Address{fake.NewAddress(0), fake.NewAddress(1)}
+
+	actor := Actor{
+		rpc: fake.NewPlayerRPC(func(addr mino.Address, _ serde.Message) fake.Reply {
+			return fake.Reply{Message: types.Ack{}, Drop: addr.Equal(fake.NewAddress(1))}
+		}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := actor.call(ctx, types.Probe{}, addrs)
+	require.EqualError(t, err, "no reply from fake.Address[1]")
+
+	actor.rpc = fake.NewPlayerRPC(func(mino.Address, serde.Message) fake.Reply {
+		return fake.Reply{Message: types.Ack{}}
+	})
+
+	_, err = actor.call(context.Background(), types.Probe{}, addrs[:1])
+	require.NoError(t, err)
+}
+
+func TestVerify(t *testing.T) {
+	pubkey := &ecdsa.PublicKey{Curve: Secp256k1()}
+	pubkey.X, pubkey.Y = pubkey.Curve.ScalarBaseMult([]byte{1})
+
+	require.False(t, Verify(pubkey, []byte{1}, Signature{}))
+	require.False(t, Verify(pubkey, []byte{1}, Signature{R: big.NewInt(0), S: big.NewInt(1)}))
+	require.False(t, Verify(pubkey, []byte{1}, Signature{R: big.NewInt(1), S: pubkey.Curve.Params().N}))
+	require.False(t, Verify(pubkey, []byte{1}, Signature{R: big.NewInt(1), S: big.NewInt(1)}))
+}
+
+func TestAgree(t *testing.T) {
+	replies := []serde.Message{
+		types.NewPartial(big.NewInt(1), []byte{1}),
+		types.NewPartial(big.NewInt(2), []byte{1}),
+	}
+
+	point, values, err := agree(replies, true)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, point)
+	require.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(2)}, values)
+
+	_, _, err = agree([]serde.Message{types.Ack{}}, false)
+	require.EqualError(t, err, "invalid reply 'types.Ack'")
+
+	replies[1] = types.NewPartial(big.NewInt(2), []byte{2})
+	_, _, err = agree(replies, true)
+	require.EqualError(t, err, "participants disagree on the point")
+
+	replies[1] = types.NewPartial(nil, []byte{1})
+	_, _, err = agree(replies, true)
+	require.EqualError(t, err, "missing value")
+}
+
+func TestIntegration_Scenario(t *testing.T) {
+	n := 3
+
+	minos := make([]*minogrpc.Minogrpc, n)
+	actors := make([]*Actor, n)
+
+	for i := range minos {
+		addr := minogrpc.ParseAddress("127.0.0.1", 0)
+
+		m, err := minogrpc.NewMinogrpc(addr, tree.NewRouter(minogrpc.NewAddressFactory()))
+		require.NoError(t, err)
+
+		defer m.GracefulStop()
+
+		minos[i] = m
+	}
+
+	hash := sha256.Sum256([]byte("Hello world"))
+
+	authorize := func(h []byte) error {
+		if !bytes.Equal(h, hash[:]) {
+			return fake.GetError()
+		}
+
+		return nil
+	}
+
+	for i, m := range minos {
+		for _, other := range minos {
+			m.GetCertificateStore().Store(other.GetAddress(), other.GetCertificate())
+		}
+
+		actor, err := NewTECDSA(m, WithAuthorizer(authorize)).Listen()
+		require.NoError(t, err)
+
+		actors[i] = actor
+	}
+
+	pubkey, err := actors[0].Setup(mino.NewAddresses(minos[0].GetAddress(),
+		minos[1].GetAddress(), minos[2].GetAddress()), 2)
+	require.NoError(t, err)
+
+	sig, err := actors[1].Sign(hash[:])
+	require.NoError(t, err)
+	require.True(t, ecdsa.Verify(pubkey, hash[:], sig.R, sig.S))
+
+	_, err = actors[1].Sign([]byte{1})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), fake.Err("unauthorized"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// network connects the handlers of fake addresses in memory.
+type network struct {
+	handlers map[mino.Address]*handler
+	offline  map[mino.Address]bool
+}
+
+// makeNetwork returns the actors of n participants that share the same
+// authorizer.
+func makeNetwork(n int, curve elliptic.Curve, fn Authorizer) ([]*Actor, *network) {
+	if fn == nil {
+		fn = func([]byte) error { return nil }
+	}
+
+	net := &network{
+		handlers: make(map[mino.Address]*handler),
+		offline:  make(map[mino.Address]bool),
+	}
+
+	actors := make([]*Actor, n)
+
+	for i := range actors {
+		addr := fake.NewAddress(i)
+
+		h := newHandler(addr, curve, fn)
+		h.setRPC(net.makeRPC(addr))
+
+		net.handlers[addr] = h
+
+		actors[i] = &Actor{
+			rpc:     h.rpc,
+			handler: h,
+			curve:   curve,
+		}
+	}
+
+	return actors, net
+}
+
+func (net *network) makeRPC(from mino.Address) mino.RPC {
+	return fake.NewPlayerRPC(func(to mino.Address, msg serde.Message) fake.Reply {
+		if net.offline[to] {
+			return fake.Reply{Err: xerrors.New("offline")}
+		}
+
+		reply, err := net.handlers[to].Process(mino.Request{Address: from, Message: msg})
+
+		return fake.Reply{Message: reply, Err: err}
+	})
+}
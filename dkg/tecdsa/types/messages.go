@@ -0,0 +1,302 @@
+// Package types implements the messages of the threshold ECDSA protocol.
+//
+// The points are encoded in the uncompressed form of SEC 1, so that the
+// messages don't depend on the curve.
+package types
+
+import (
+	"math/big"
+
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
+	"golang.org/x/xerrors"
+)
+
+var msgFormats = registry.NewSimpleRegistry()
+
+// RegisterMessageFormat registers the engine for the provided format.
+func RegisterMessageFormat(c serde.Format, f serde.FormatEngine) {
+	msgFormats.Register(c, f)
+}
+
+// Deal is the message sent by the initiator of the key generation to ask the
+// participants to deal the shares of their contribution to the key.
+//
+// - implements serde.Message
+type Deal struct {
+	session   string
+	threshold int
+	addrs     []mino.Address
+}
+
+// NewDeal creates a new deal message.
+func NewDeal(session string, threshold int, addrs []mino.Address) Deal {
+	return Deal{
+		session:   session,
+		threshold: threshold,
+		addrs:     addrs,
+	}
+}
+
+// GetSession returns the identifier of the session.
+func (d Deal) GetSession() string {
+	return d.session
+}
+
+// GetThreshold returns the number of shares needed to recover the key.
+func (d Deal) GetThreshold() int {
+	return d.threshold
+}
+
+// GetAddresses returns the addresses of the participants in the order of
+// their indices.
+func (d Deal) GetAddresses() []mino.Address {
+	return append([]mino.Address{}, d.addrs...)
+}
+
+// Serialize implements serde.Message.
+func (d Deal) Serialize(ctx serde.Context) ([]byte, error) {
+	return serialize(ctx, d)
+}
+
+// Probe is the message sent by the initiator of a signature to find the
+// participants that are online.
+//
+// - implements serde.Message
+type Probe struct{}
+
+// Serialize implements serde.Message.
+func (p Probe) Serialize(ctx serde.Context) ([]byte, error) {
+	return serialize(ctx, p)
+}
+
+// Nonce is the message sent by the initiator of a signature to ask the signers
+// to deal the shares of their contribution to the nonce.
+//
+// - implements serde.Message
+type Nonce struct {
+	session string
+	signers []int
+}
+
+// NewNonce creates a new nonce message for the signers of the indices.
+func NewNonce(session string, signers []int) Nonce {
+	return Nonce{
+		session: session,
+		signers: signers,
+	}
+}
+
+// GetSession returns the identifier of the session.
+func (n Nonce) GetSession() string {
+	return n.session
+}
+
+// GetSigners returns the indices of the signers.
+func (n Nonce) GetSigners() []int {
+	return append([]int{}, n.signers...)
+}
+
+// Serialize implements serde.Message.
+func (n Nonce) Serialize(ctx serde.Context) ([]byte, error) {
+	return serialize(ctx, n)
+}
+
+// Share is the message sent by a dealer to a participant with the evaluation
+// of its polynomials for the participant, and the commitments to the
+// coefficients of the first one.
+//
+// - implements serde.Message
+type Share struct {
+	session string
+	index   int
+	values  []*big.Int
+	commits [][]byte
+}
+
+// NewShare creates a new share message from the dealer of the index.
+func NewShare(session string, index int, values []*big.Int, commits [][]byte) Share {
+	return Share{
+		session: session,
+		index:   index,
+		values:  values,
+		commits: commits,
+	}
+}
+
+// GetSession returns the identifier of the session.
+func (s Share) GetSession() string {
+	return s.session
+}
+
+// GetIndex returns the index of the dealer.
+func (s Share) GetIndex() int {
+	return s.index
+}
+
+// GetValues returns the values of the polynomials for the participant.
+func (s Share) GetValues() []*big.Int {
+	return append([]*big.Int{}, s.values...)
+}
+
+// GetCommits returns the commitments to the coefficients of the first
+// polynomial.
+func (s Share) GetCommits() [][]byte {
+	return append([][]byte{}, s.commits...)
+}
+
+// Serialize implements serde.Message.
+func (s Share) Serialize(ctx serde.Context) ([]byte, error) {
+	return serialize(ctx, s)
+}
+
+// Finalize is the message sent by the initiator once the shares are dealt, so
+// that the participants combine them.
+//
+// - implements serde.Message
+type Finalize struct {
+	session string
+}
+
+// NewFinalize creates a new finalize message.
+func NewFinalize(session string) Finalize {
+	return Finalize{
+		session: session,
+	}
+}
+
+// GetSession returns the identifier of the session.
+func (f Finalize) GetSession() string {
+	return f.session
+}
+
+// Serialize implements serde.Message.
+func (f Finalize) Serialize(ctx serde.Context) ([]byte, error) {
+	return serialize(ctx, f)
+}
+
+// Sign is the message sent by the initiator of a signature to ask the signers
+// for their share of the signature of the hash.
+//
+// - implements serde.Message
+type Sign struct {
+	session string
+	mu      *big.Int
+	hash    []byte
+}
+
+// NewSign creates a new sign message, where mu is the product of the nonce and
+// the blinding factor.
+func NewSign(session string, mu *big.Int, hash []byte) Sign {
+	return Sign{
+		session: session,
+		mu:      mu,
+		hash:    hash,
+	}
+}
+
+// GetSession returns the identifier of the session.
+func (s Sign) GetSession() string {
+	return s.session
+}
+
+// GetMu returns the product of the nonce and the blinding factor.
+func (s Sign) GetMu() *big.Int {
+	return s.mu
+}
+
+// GetHash returns the hash to sign.
+func (s Sign) GetHash() []byte {
+	return append([]byte{}, s.hash...)
+}
+
+// Serialize implements serde.Message.
+func (s Sign) Serialize(ctx serde.Context) ([]byte, error) {
+	return serialize(ctx, s)
+}
+
+// Partial is the reply of a participant with its share of a value, and a point
+// that every participant must agree on.
+//
+// - implements serde.Message
+type Partial struct {
+	value *big.Int
+	point []byte
+}
+
+// NewPartial creates a new partial message. Either the value or the point can
+// be nil.
+func NewPartial(value *big.Int, point []byte) Partial {
+	return Partial{
+		value: value,
+		point: point,
+	}
+}
+
+// GetValue returns the share of the value, or nil.
+func (p Partial) GetValue() *big.Int {
+	return p.value
+}
+
+// GetPoint returns the point, or nil.
+func (p Partial) GetPoint() []byte {
+	return append([]byte(nil), p.point...)
+}
+
+// Serialize implements serde.Message.
+func (p Partial) Serialize(ctx serde.Context) ([]byte, error) {
+	return serialize(ctx, p)
+}
+
+// Ack is the reply of a participant that has processed a request.
+//
+// - implements serde.Message
+type Ack struct{}
+
+// Serialize implements serde.Message.
+func (a Ack) Serialize(ctx serde.Context) ([]byte, error) {
+	return serialize(ctx, a)
+}
+
+func serialize(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, msg)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't encode message: %v", err)
+	}
+
+	return data, nil
+}
+
+// AddrKey is the key for the address factory.
+type AddrKey struct{}
+
+// MessageFactory is a message factory for the messages of the protocol.
+//
+// - implements serde.Factory
+type MessageFactory struct {
+	addrFactory mino.AddressFactory
+}
+
+// NewMessageFactory returns a message factory for the protocol.
+func NewMessageFactory(f mino.AddressFactory) MessageFactory {
+	return MessageFactory{
+		addrFactory: f,
+	}
+}
+
+// Deserialize implements serde.Factory.
+func (f MessageFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	ctx = serde.WithFactory(ctx, AddrKey{}, f.addrFactory)
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't decode message: %v", err)
+	}
+
+	return msg, nil
+}
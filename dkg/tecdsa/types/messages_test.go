@@ -0,0 +1,129 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+)
+
+var testCalls = &fake.Call{}
+
+func init() {
+	RegisterMessageFormat(fake.GoodFormat, fake.Format{Msg: Ack{}, Call: testCalls})
+	RegisterMessageFormat(fake.BadFormat, fake.NewBadFormat())
+}
+
+func TestDeal_Getters(t *testing.T) {
+	deal := NewDeal("abc", 2, []mino.Address{fake.NewAddress(0), fake.NewAddress(1)})
+
+	require.Equal(t, "abc", deal.GetSession())
+	require.Equal(t, 2, deal.GetThreshold())
+	require.Len(t, deal.GetAddresses(), 2)
+}
+
+func TestDeal_Serialize(t *testing.T) {
+	testSerialize(t, Deal{})
+}
+
+func TestProbe_Serialize(t *testing.T) {
+	testSerialize(t, Probe{})
+}
+
+func TestNonce_Getters(t *testing.T) {
+	nonce := NewNonce("abc", []int{0, 2, 3})
+
+	require.Equal(t, "abc", nonce.GetSession())
+	require.Equal(t, []int{0, 2, 3}, nonce.GetSigners())
+}
+
+func TestNonce_Serialize(t *testing.T) {
+	testSerialize(t, Nonce{})
+}
+
+func TestShare_Getters(t *testing.T) {
+	share := NewShare("abc", 3, []*big.Int{big.NewInt(1)}, [][]byte{{1}, {2}})
+
+	require.Equal(t, "abc", share.GetSession())
+	require.Equal(t, 3, share.GetIndex())
+	require.Equal(t, []*big.Int{big.NewInt(1)}, share.GetValues())
+	require.Equal(t, [][]byte{{1}, {2}}, share.GetCommits())
+}
+
+func TestShare_Serialize(t *testing.T) {
+	testSerialize(t, Share{})
+}
+
+func TestFinalize_GetSession(t *testing.T) {
+	finalize := NewFinalize("abc")
+
+	require.Equal(t, "abc", finalize.GetSession())
+}
+
+func TestFinalize_Serialize(t *testing.T) {
+	testSerialize(t, Finalize{})
+}
+
+func TestSign_Getters(t *testing.T) {
+	sign := NewSign("abc", big.NewInt(5), []byte{1, 2})
+
+	require.Equal(t, "abc", sign.GetSession())
+	require.Equal(t, big.NewInt(5), sign.GetMu())
+	require.Equal(t, []byte{1, 2}, sign.GetHash())
+}
+
+func TestSign_Serialize(t *testing.T) {
+	testSerialize(t, Sign{})
+}
+
+func TestPartial_Getters(t *testing.T) {
+	partial := NewPartial(big.NewInt(5), []byte{1})
+
+	require.Equal(t, big.NewInt(5), partial.GetValue())
+	require.Equal(t, []byte{1}, partial.GetPoint())
+
+	partial = NewPartial(nil, nil)
+
+	require.Nil(t, partial.GetValue())
+	require.Nil(t, partial.GetPoint())
+}
+
+func TestPartial_Serialize(t *testing.T) {
+	testSerialize(t, Partial{})
+}
+
+func TestAck_Serialize(t *testing.T) {
+	testSerialize(t, Ack{})
+}
+
+func TestMessageFactory(t *testing.T) {
+	factory := NewMessageFactory(fake.AddressFactory{})
+
+	testCalls.Clear()
+
+	msg, err := factory.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.Equal(t, Ack{}, msg)
+
+	require.Equal(t, 1, testCalls.Len())
+	ctx := testCalls.Get(0, 0).(serde.Context)
+	require.Equal(t, fake.AddressFactory{}, ctx.GetFactory(AddrKey{}))
+
+	_, err = factory.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("couldn't decode message"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func testSerialize(t *testing.T, msg serde.Message) {
+	data, err := msg.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = msg.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("couldn't encode message"))
+}
@@ -0,0 +1,108 @@
+// Package collection implements collections whose iteration order does not
+// depend on the runtime.
+//
+// The iteration over a Go map is randomized, which is a source of
+// nondeterminism for the code that must produce the same result on every node
+// of a chain, like the application of a roster change or the execution of a
+// transaction. The collections of this package iterate over their keys in
+// increasing order instead, so that they can be used on those code paths
+// without sorting the keys on each call site.
+package collection
+
+import "sort"
+
+// OrderedMap is a map with string keys that iterates over them in increasing
+// order. The zero value is not ready to use.
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewOrderedMap creates a new empty map.
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{
+		values: make(map[string]interface{}),
+	}
+}
+
+// Len returns the number of keys in the map.
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Has returns true if the key is set in the map.
+func (m *OrderedMap) Has(key string) bool {
+	_, found := m.values[key]
+	return found
+}
+
+// Get returns the value associated to the key and true if it exists, otherwise
+// it returns false.
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	value, found := m.values[key]
+	return value, found
+}
+
+// Set associates the value to the key, and replaces the previous one if any.
+func (m *OrderedMap) Set(key string, value interface{}) {
+	_, found := m.values[key]
+	if !found {
+		m.keys = insertString(m.keys, key)
+	}
+
+	m.values[key] = value
+}
+
+// Delete removes the key from the map if it exists.
+func (m *OrderedMap) Delete(key string) {
+	_, found := m.values[key]
+	if !found {
+		return
+	}
+
+	delete(m.values, key)
+	m.keys = removeString(m.keys, key)
+}
+
+// Keys returns the sorted list of keys.
+func (m *OrderedMap) Keys() []string {
+	return append([]string{}, m.keys...)
+}
+
+// ForEach calls the function for each key in increasing order, and stops at the
+// first error which is returned.
+func (m *OrderedMap) ForEach(fn func(key string, value interface{}) error) error {
+	// The keys are copied so that the function can modify the map.
+	for _, key := range m.Keys() {
+		value, found := m.values[key]
+		if !found {
+			continue
+		}
+
+		err := fn(key, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func insertString(keys []string, key string) []string {
+	i := sort.SearchStrings(keys, key)
+
+	keys = append(keys, "")
+	copy(keys[i+1:], keys[i:])
+	keys[i] = key
+
+	return keys
+}
+
+func removeString(keys []string, key string) []string {
+	i := sort.SearchStrings(keys, key)
+	if i == len(keys) || keys[i] != key {
+		return keys
+	}
+
+	return append(keys[:i], keys[i+1:]...)
+}
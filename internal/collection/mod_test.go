@@ -0,0 +1,66 @@
+package collection
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestOrderedMap_Set(t *testing.T) {
+	m := NewOrderedMap()
+	require.Equal(t, 0, m.Len())
+
+	for _, key := range []string{"c", "a", "d", "b", "a"} {
+		m.Set(key, key+key)
+	}
+
+	require.Equal(t, 4, m.Len())
+	require.Equal(t, []string{"a", "b", "c", "d"}, m.Keys())
+
+	value, found := m.Get("a")
+	require.True(t, found)
+	require.Equal(t, "aa", value)
+	require.True(t, m.Has("d"))
+
+	_, found = m.Get("e")
+	require.False(t, found)
+	require.False(t, m.Has("e"))
+}
+
+func TestOrderedMap_Delete(t *testing.T) {
+	m := NewOrderedMap()
+	m.Set("a", 1)
+	m.Set("b", 2)
+
+	m.Delete("a")
+	m.Delete("z")
+	require.Equal(t, []string{"b"}, m.Keys())
+	require.False(t, m.Has("a"))
+
+	m.Delete("b")
+	require.Equal(t, 0, m.Len())
+}
+
+func TestOrderedMap_ForEach(t *testing.T) {
+	m := NewOrderedMap()
+	for i := 9; i >= 0; i-- {
+		m.Set(fmt.Sprintf("key%d", i), i)
+	}
+
+	values := []interface{}{}
+	err := m.ForEach(func(key string, value interface{}) error {
+		values = append(values, value)
+		// The map can be modified during the iteration.
+		m.Delete("key5")
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{0, 1, 2, 3, 4, 6, 7, 8, 9}, values)
+
+	err = m.ForEach(func(string, interface{}) error {
+		return fake.GetError()
+	})
+	require.Equal(t, fake.GetError(), err)
+}
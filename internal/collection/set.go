@@ -0,0 +1,114 @@
+// This file contains the implementation of the ordered sets.
+
+package collection
+
+import "sort"
+
+// OrderedSet is a set of strings that iterates over them in increasing order.
+// The zero value is an empty set.
+type OrderedSet struct {
+	items []string
+}
+
+// NewOrderedSet creates a new set with the given items.
+func NewOrderedSet(items ...string) *OrderedSet {
+	set := &OrderedSet{}
+
+	for _, item := range items {
+		set.Add(item)
+	}
+
+	return set
+}
+
+// Len returns the number of items in the set.
+func (s *OrderedSet) Len() int {
+	return len(s.items)
+}
+
+// Has returns true if the item is in the set.
+func (s *OrderedSet) Has(item string) bool {
+	i := sort.SearchStrings(s.items, item)
+
+	return i < len(s.items) && s.items[i] == item
+}
+
+// Add adds the item to the set if it is not already in it.
+func (s *OrderedSet) Add(item string) {
+	if !s.Has(item) {
+		s.items = insertString(s.items, item)
+	}
+}
+
+// Remove removes the item from the set if it exists.
+func (s *OrderedSet) Remove(item string) {
+	s.items = removeString(s.items, item)
+}
+
+// Items returns the sorted list of items.
+func (s *OrderedSet) Items() []string {
+	return append([]string{}, s.items...)
+}
+
+// IndexSet is a set of indices that iterates over them in increasing or
+// decreasing order. The zero value is an empty set.
+type IndexSet struct {
+	indices []uint
+}
+
+// NewIndexSet creates a new set with the given indices.
+func NewIndexSet(indices ...uint) *IndexSet {
+	set := &IndexSet{}
+
+	for _, index := range indices {
+		set.Add(index)
+	}
+
+	return set
+}
+
+// Len returns the number of indices in the set.
+func (s *IndexSet) Len() int {
+	return len(s.indices)
+}
+
+// Has returns true if the index is in the set.
+func (s *IndexSet) Has(index uint) bool {
+	i := s.search(index)
+
+	return i < len(s.indices) && s.indices[i] == index
+}
+
+// Add adds the index to the set if it is not already in it.
+func (s *IndexSet) Add(index uint) {
+	i := s.search(index)
+	if i < len(s.indices) && s.indices[i] == index {
+		return
+	}
+
+	s.indices = append(s.indices, 0)
+	copy(s.indices[i+1:], s.indices[i:])
+	s.indices[i] = index
+}
+
+// Ascending returns the list of indices in increasing order.
+func (s *IndexSet) Ascending() []uint {
+	return append([]uint{}, s.indices...)
+}
+
+// Descending returns the list of indices in decreasing order, which is the
+// order to remove them from a list without shifting the following ones.
+func (s *IndexSet) Descending() []uint {
+	indices := make([]uint, len(s.indices))
+	for i, index := range s.indices {
+		indices[len(indices)-1-i] = index
+	}
+
+	return indices
+}
+
+func (s *IndexSet) search(index uint) int {
+	return sort.Search(len(s.indices), func(i int) bool {
+		return s.indices[i] >= index
+	})
+}
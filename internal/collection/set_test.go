@@ -0,0 +1,44 @@
+package collection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedSet_Add(t *testing.T) {
+	set := NewOrderedSet("c", "a", "c")
+	require.Equal(t, 2, set.Len())
+
+	set.Add("b")
+	set.Add("a")
+	require.Equal(t, []string{"a", "b", "c"}, set.Items())
+	require.True(t, set.Has("b"))
+	require.False(t, set.Has("d"))
+
+	var empty OrderedSet
+	empty.Add("a")
+	require.Equal(t, []string{"a"}, empty.Items())
+}
+
+func TestOrderedSet_Remove(t *testing.T) {
+	set := NewOrderedSet("a", "b", "c")
+
+	set.Remove("b")
+	set.Remove("d")
+	require.Equal(t, []string{"a", "c"}, set.Items())
+}
+
+func TestIndexSet_Add(t *testing.T) {
+	set := NewIndexSet(3, 1, 3, 0)
+	require.Equal(t, 3, set.Len())
+
+	set.Add(2)
+	require.Equal(t, []uint{0, 1, 2, 3}, set.Ascending())
+	require.Equal(t, []uint{3, 2, 1, 0}, set.Descending())
+	require.True(t, set.Has(1))
+	require.False(t, set.Has(4))
+
+	var empty IndexSet
+	require.Empty(t, empty.Descending())
+}
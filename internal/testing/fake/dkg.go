@@ -0,0 +1,186 @@
+package fake
+
+import (
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/dkg"
+	"go.dedis.ch/dela/internal/testing/rng"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/suites"
+	"golang.org/x/xerrors"
+)
+
+// dkgSuite is the suite of the fake DKG, which is the same as Pedersen.
+var dkgSuite = suites.MustFind("Ed25519")
+
+// DKG is a fake implementation of a DKG.
+//
+// - implements dkg.DKG
+type DKG struct {
+	actor *DKGActor
+	err   error
+}
+
+// NewDKG returns a fake DKG that returns the actor when listening.
+func NewDKG(actor *DKGActor) DKG {
+	return DKG{actor: actor}
+}
+
+// NewBadDKG returns a fake DKG that fails to listen.
+func NewBadDKG() DKG {
+	return DKG{err: fakeErr}
+}
+
+// Listen implements dkg.DKG.
+func (d DKG) Listen() (dkg.Actor, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return d.actor, nil
+}
+
+// DKGOption is the type of option to configure a fake DKG actor.
+type DKGOption func(*DKGActor)
+
+// WithDKGPrivateKey is an option to set the private key of the actor, which is
+// used to decrypt, alongside the public key derived from it.
+func WithDKGPrivateKey(key kyber.Scalar) DKGOption {
+	return func(a *DKGActor) {
+		a.privKey = key
+		a.pubKey = dkgSuite.Point().Mul(key, nil)
+	}
+}
+
+// WithDKGPublicKey is an option to set the public key of the actor. The actor
+// cannot decrypt as it doesn't know the private key.
+func WithDKGPublicKey(key kyber.Point) DKGOption {
+	return func(a *DKGActor) {
+		a.privKey = nil
+		a.pubKey = key
+	}
+}
+
+// WithDKGCall is an option to record the calls to the actor. Each call is
+// recorded with the name of the function followed by its arguments.
+func WithDKGCall(call *Call) DKGOption {
+	return func(a *DKGActor) {
+		a.call = call
+	}
+}
+
+// DKGActor is a fake implementation of a DKG actor. It encrypts with ElGamal
+// like Pedersen does, but with a single key pair instead of the distributed
+// one. Each function can be made to fail with the corresponding error.
+//
+// - implements dkg.Actor
+type DKGActor struct {
+	privKey kyber.Scalar
+	pubKey  kyber.Point
+	call    *Call
+
+	ErrSetup   error
+	ErrPubKey  error
+	ErrEncrypt error
+	ErrDecrypt error
+	ErrReshare error
+}
+
+// NewDKGActor returns a fake actor with a random key pair.
+func NewDKGActor(opts ...DKGOption) *DKGActor {
+	a := &DKGActor{}
+
+	WithDKGPrivateKey(dkgSuite.Scalar().Pick(rng.Stream()))(a)
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// NewBadDKGActor returns a fake actor that returns an error for every
+// function.
+func NewBadDKGActor(opts ...DKGOption) *DKGActor {
+	a := NewDKGActor(opts...)
+	a.ErrSetup = fakeErr
+	a.ErrPubKey = fakeErr
+	a.ErrEncrypt = fakeErr
+	a.ErrDecrypt = fakeErr
+	a.ErrReshare = fakeErr
+
+	return a
+}
+
+// Setup implements dkg.Actor. It returns the public key.
+func (a *DKGActor) Setup(co crypto.CollectiveAuthority, threshold int) (kyber.Point, error) {
+	a.call.Add("setup", co, threshold)
+
+	if a.ErrSetup != nil {
+		return nil, a.ErrSetup
+	}
+
+	return a.pubKey, nil
+}
+
+// GetPublicKey implements dkg.Actor. It returns the public key.
+func (a *DKGActor) GetPublicKey() (kyber.Point, error) {
+	a.call.Add("getPublicKey")
+
+	if a.ErrPubKey != nil {
+		return nil, a.ErrPubKey
+	}
+
+	return a.pubKey, nil
+}
+
+// Encrypt implements dkg.Actor. It encrypts the message with the public key.
+func (a *DKGActor) Encrypt(message []byte) (K, C kyber.Point, remainder []byte, err error) {
+	a.call.Add("encrypt", message)
+
+	if a.ErrEncrypt != nil {
+		return nil, nil, nil, a.ErrEncrypt
+	}
+
+	M := dkgSuite.Point().Embed(message, rng.Stream())
+	max := dkgSuite.Point().EmbedLen()
+	if max > len(message) {
+		max = len(message)
+	}
+
+	k := dkgSuite.Scalar().Pick(rng.Stream())
+	K = dkgSuite.Point().Mul(k, nil)
+	S := dkgSuite.Point().Mul(k, a.pubKey)
+	C = S.Add(S, M)
+
+	return K, C, message[max:], nil
+}
+
+// Decrypt implements dkg.Actor. It decrypts the message with the private key.
+func (a *DKGActor) Decrypt(K, C kyber.Point) ([]byte, error) {
+	a.call.Add("decrypt", K, C)
+
+	if a.ErrDecrypt != nil {
+		return nil, a.ErrDecrypt
+	}
+
+	if a.privKey == nil {
+		return nil, xerrors.New("private key is unknown")
+	}
+
+	S := dkgSuite.Point().Mul(a.privKey, K)
+	M := dkgSuite.Point().Sub(C, S)
+
+	message, err := M.Data()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to extract data: %v", err)
+	}
+
+	return message, nil
+}
+
+// Reshare implements dkg.Actor.
+func (a *DKGActor) Reshare() error {
+	a.call.Add("reshare")
+
+	return a.ErrReshare
+}
@@ -0,0 +1,79 @@
+package fake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/rng"
+)
+
+func TestDKG_Listen(t *testing.T) {
+	actor := NewDKGActor()
+
+	a, err := NewDKG(actor).Listen()
+	require.NoError(t, err)
+	require.Equal(t, actor, a)
+
+	_, err = NewBadDKG().Listen()
+	require.EqualError(t, err, fakeErr.Error())
+}
+
+func TestDKGActor_EncryptDecrypt(t *testing.T) {
+	call := NewCall()
+	actor := NewDKGActor(WithDKGCall(call))
+
+	pubKey, err := actor.Setup(nil, 1)
+	require.NoError(t, err)
+
+	other, err := actor.GetPublicKey()
+	require.NoError(t, err)
+	require.True(t, pubKey.Equal(other))
+
+	K, C, remainder, err := actor.Encrypt([]byte("hello"))
+	require.NoError(t, err)
+	require.Empty(t, remainder)
+
+	message, err := actor.Decrypt(K, C)
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), message)
+
+	require.NoError(t, actor.Reshare())
+	require.Equal(t, 5, call.Len())
+	require.Equal(t, "encrypt", call.Get(2, 0))
+
+	actor = NewDKGActor(WithDKGPublicKey(pubKey))
+
+	K, C, _, err = actor.Encrypt([]byte("hello"))
+	require.NoError(t, err)
+
+	_, err = actor.Decrypt(K, C)
+	require.EqualError(t, err, "private key is unknown")
+}
+
+func TestDKGActor_Errors(t *testing.T) {
+	actor := NewBadDKGActor()
+
+	_, err := actor.Setup(nil, 1)
+	require.Equal(t, fakeErr, err)
+
+	_, err = actor.GetPublicKey()
+	require.Equal(t, fakeErr, err)
+
+	_, _, _, err = actor.Encrypt(nil)
+	require.Equal(t, fakeErr, err)
+
+	_, err = actor.Decrypt(nil, nil)
+	require.Equal(t, fakeErr, err)
+
+	require.Equal(t, fakeErr, actor.Reshare())
+}
+
+func TestDKGActor_Seed(t *testing.T) {
+	rng.Seed(1)
+	first := NewDKGActor()
+
+	rng.Seed(1)
+	second := NewDKGActor()
+
+	require.True(t, first.pubKey.Equal(second.pubKey))
+}
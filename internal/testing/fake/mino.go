@@ -12,11 +12,13 @@ import (
 	"io"
 	"math/big"
 	"net"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/internal/testing/rng"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
 )
@@ -35,6 +37,12 @@ func NewAddress(index int) Address {
 	return Address{index: index}
 }
 
+// NewRandomAddress returns a fake address with an index drawn from the
+// seedable source.
+func NewRandomAddress() Address {
+	return Address{index: rng.Intn(1 << 16)}
+}
+
 // NewBadAddress returns a fake address that returns an error when appropriate.
 func NewBadAddress() Address {
 	return Address{err: fakeErr}
@@ -427,6 +435,107 @@ func (rpc *RPC) Reset() {
 	rpc.msgs = make(chan mino.Response, 100)
 }
 
+// Reply is the behavior of a player of a PlayerRPC for one request.
+type Reply struct {
+	// Message is the reply sent back, unless an error is set.
+	Message serde.Message
+
+	// Err is the error sent back instead of a message.
+	Err error
+
+	// Latency is the delay before the reply is delivered. Different latencies
+	// allow the responses to arrive out of order.
+	Latency time.Duration
+
+	// Drop makes the player never reply so that the call ends only when the
+	// context is done.
+	Drop bool
+}
+
+// Responder returns the reply of a player to a request.
+type Responder func(from mino.Address, req serde.Message) Reply
+
+// PlayerRPC is a fake RPC where each player of a call replies concurrently
+// according to the responder, which allows to simulate latencies, failures and
+// missing replies. The channel of responses is closed once every player has
+// replied, or the context is done.
+//
+// - implements mino.RPC
+type PlayerRPC struct {
+	mino.RPC
+	Calls     *Call
+	responder Responder
+	err       error
+}
+
+// NewPlayerRPC returns a new fake RPC using the responder for each player.
+func NewPlayerRPC(fn Responder) *PlayerRPC {
+	return &PlayerRPC{
+		Calls:     &Call{},
+		responder: fn,
+	}
+}
+
+// NewBadPlayerRPC returns a new fake RPC that fails to start the calls.
+func NewBadPlayerRPC() *PlayerRPC {
+	rpc := NewPlayerRPC(nil)
+	rpc.err = fakeErr
+
+	return rpc
+}
+
+// Call implements mino.RPC. It asks each player for its reply in a separate
+// goroutine, and delivers it after the latency.
+func (rpc *PlayerRPC) Call(ctx context.Context,
+	m serde.Message, p mino.Players) (<-chan mino.Response, error) {
+
+	rpc.Calls.Add(ctx, m, p)
+
+	if rpc.err != nil {
+		return nil, rpc.err
+	}
+
+	out := make(chan mino.Response, p.Len())
+	wg := sync.WaitGroup{}
+
+	iter := p.AddressIterator()
+	for iter.HasNext() {
+		wg.Add(1)
+
+		go func(addr mino.Address) {
+			defer wg.Done()
+
+			reply := rpc.responder(addr, m)
+			if reply.Drop {
+				<-ctx.Done()
+				return
+			}
+
+			timer := time.NewTimer(reply.Latency)
+			defer timer.Stop()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+
+			if reply.Err != nil {
+				out <- mino.NewResponseWithError(addr, reply.Err)
+			} else {
+				out <- mino.NewResponse(addr, reply.Message)
+			}
+		}(iter.GetNext())
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out, nil
+}
+
 // Mino is a fake implementation of mino.
 //
 // - implements mino.Mino
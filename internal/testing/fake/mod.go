@@ -7,7 +7,6 @@ package fake
 
 import (
 	"bytes"
-	"crypto/rand"
 	"fmt"
 	"io"
 	"strings"
@@ -17,6 +16,7 @@ import (
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/rng"
 	"golang.org/x/xerrors"
 )
 
@@ -24,7 +24,7 @@ func init() {
 	// A random value is injected every time, so that the error is never the
 	// same and prevent hardcoded values in the tests.
 	random := make([]byte, 4)
-	rand.Read(random)
+	rng.Read(random)
 
 	fakeErr = xerrors.Errorf("fake error (%x)", random)
 }
@@ -1,17 +1,17 @@
 package fake
 
 import (
-	"crypto/rand"
 	"encoding/json"
 	"io"
 
+	"go.dedis.ch/dela/internal/testing/rng"
 	"go.dedis.ch/dela/serde"
 )
 
 func init() {
 	// A random value is injected to prevent hardcoded value in the tests.
 	fakeFmtValue = make([]byte, 8)
-	rand.Read(fakeFmtValue)
+	rng.Read(fakeFmtValue)
 }
 
 const (
@@ -39,6 +39,12 @@ type Message struct {
 	Digest []byte
 }
 
+// NewRandomMessage returns a message with a digest of the given size drawn
+// from the seedable source.
+func NewRandomMessage(size int) Message {
+	return Message{Digest: rng.Bytes(size)}
+}
+
 // Fingerprint implements serde.Fingerprinter.
 func (m Message) Fingerprint(w io.Writer) error {
 	w.Write(m.Digest)
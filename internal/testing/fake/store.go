@@ -1,46 +1,103 @@
 package fake
 
 import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
 	"go.dedis.ch/dela/core/store"
+	"go.dedis.ch/dela/core/store/hashtree"
 	"go.dedis.ch/dela/core/store/kv"
 )
 
-// InMemorySnapshot is a fake implementation of a store snapshot.
+// InMemorySnapshot is a fake implementation of a store snapshot. It holds the
+// values in a map, and can be configured to record the calls and to return
+// errors for some keys only.
 //
 // - implements store.Snapshot
 type InMemorySnapshot struct {
 	store.Snapshot
 
 	values    map[string][]byte
+	keyErrs   map[string]error
+	call      *Call
 	ErrRead   error
 	ErrWrite  error
 	ErrDelete error
 }
 
+// SnapshotOption is the type of option to configure a snapshot.
+type SnapshotOption func(*InMemorySnapshot)
+
+// WithValues is an option to populate the snapshot with initial values.
+func WithValues(values map[string][]byte) SnapshotOption {
+	return func(snap *InMemorySnapshot) {
+		for key, value := range values {
+			snap.values[key] = value
+		}
+	}
+}
+
+// WithKeyError is an option to make any operation on the key return the error.
+func WithKeyError(key []byte, err error) SnapshotOption {
+	return func(snap *InMemorySnapshot) {
+		snap.keyErrs[string(key)] = err
+	}
+}
+
+// WithCall is an option to record the calls to the snapshot. Each call is
+// recorded with the name of the operation followed by its arguments.
+func WithCall(call *Call) SnapshotOption {
+	return func(snap *InMemorySnapshot) {
+		snap.call = call
+	}
+}
+
 // NewSnapshot creates a new empty snapshot.
-func NewSnapshot() *InMemorySnapshot {
-	return &InMemorySnapshot{
-		values: make(map[string][]byte),
+func NewSnapshot(opts ...SnapshotOption) *InMemorySnapshot {
+	snap := &InMemorySnapshot{
+		values:  make(map[string][]byte),
+		keyErrs: make(map[string]error),
+	}
+
+	for _, opt := range opts {
+		opt(snap)
 	}
+
+	return snap
 }
 
 // NewBadSnapshot creates a new empty snapshot that will always return an error.
-func NewBadSnapshot() *InMemorySnapshot {
-	return &InMemorySnapshot{
-		values:    make(map[string][]byte),
-		ErrRead:   fakeErr,
-		ErrWrite:  fakeErr,
-		ErrDelete: fakeErr,
-	}
+func NewBadSnapshot(opts ...SnapshotOption) *InMemorySnapshot {
+	snap := NewSnapshot(opts...)
+	snap.ErrRead = fakeErr
+	snap.ErrWrite = fakeErr
+	snap.ErrDelete = fakeErr
+
+	return snap
 }
 
 // Get implements store.Snapshot.
 func (snap *InMemorySnapshot) Get(key []byte) ([]byte, error) {
+	snap.call.Add("get", key)
+
+	err := snap.keyErrs[string(key)]
+	if err != nil {
+		return nil, err
+	}
+
 	return snap.values[string(key)], snap.ErrRead
 }
 
 // Set implements store.Snapshot.
 func (snap *InMemorySnapshot) Set(key, value []byte) error {
+	snap.call.Add("set", key, value)
+
+	err := snap.keyErrs[string(key)]
+	if err != nil {
+		return err
+	}
+
 	snap.values[string(key)] = value
 
 	return snap.ErrWrite
@@ -48,11 +105,159 @@ func (snap *InMemorySnapshot) Set(key, value []byte) error {
 
 // Delete implements store.Snapshot.
 func (snap *InMemorySnapshot) Delete(key []byte) error {
+	snap.call.Add("delete", key)
+
+	err := snap.keyErrs[string(key)]
+	if err != nil {
+		return err
+	}
+
 	delete(snap.values, string(key))
 
 	return snap.ErrDelete
 }
 
+// Len returns the number of keys set in the snapshot.
+func (snap *InMemorySnapshot) Len() int {
+	return len(snap.values)
+}
+
+// ForEach calls the function for each pair of key and value, in the order of
+// the keys, until it returns an error.
+func (snap *InMemorySnapshot) ForEach(fn func(key, value []byte) error) error {
+	keys := make([]string, 0, len(snap.values))
+	for key := range snap.values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		err := fn([]byte(key), snap.values[key])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Tree is a fake implementation of a hash tree backed by a snapshot. The root is
+// the hash of the sorted pairs of key and value, which is enough to compare two
+// states in a test.
+//
+// - implements hashtree.Tree
+// - implements hashtree.StagingTree
+type Tree struct {
+	*InMemorySnapshot
+
+	ErrStage  error
+	ErrCommit error
+	ErrPath   error
+}
+
+// NewTree returns a new empty tree configured with the options of the
+// snapshot.
+func NewTree(opts ...SnapshotOption) *Tree {
+	return &Tree{
+		InMemorySnapshot: NewSnapshot(opts...),
+	}
+}
+
+// GetRoot implements hashtree.Tree. It returns the hash of the content.
+func (t *Tree) GetRoot() []byte {
+	h := sha256.New()
+
+	t.ForEach(func(key, value []byte) error {
+		fmt.Fprintf(h, "%d:%x:%d:%x", len(key), key, len(value), value)
+		return nil
+	})
+
+	return h.Sum(nil)
+}
+
+// GetPath implements hashtree.Tree. It returns a path to the key that is valid
+// for the current root.
+func (t *Tree) GetPath(key []byte) (hashtree.Path, error) {
+	if t.ErrPath != nil {
+		return nil, t.ErrPath
+	}
+
+	value, err := t.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return treePath{key: key, value: value, root: t.GetRoot()}, nil
+}
+
+// Stage implements hashtree.Tree. It copies the content into a new tree that
+// is passed to the callback, and returns it.
+func (t *Tree) Stage(fn func(store.Snapshot) error) (hashtree.StagingTree, error) {
+	if t.ErrStage != nil {
+		return nil, t.ErrStage
+	}
+
+	next := &Tree{
+		InMemorySnapshot: &InMemorySnapshot{
+			values:    make(map[string][]byte),
+			keyErrs:   t.keyErrs,
+			call:      t.call,
+			ErrRead:   t.ErrRead,
+			ErrWrite:  t.ErrWrite,
+			ErrDelete: t.ErrDelete,
+		},
+		ErrStage:  t.ErrStage,
+		ErrCommit: t.ErrCommit,
+		ErrPath:   t.ErrPath,
+	}
+
+	for key, value := range t.values {
+		next.values[key] = value
+	}
+
+	err := fn(next)
+	if err != nil {
+		return nil, err
+	}
+
+	return next, nil
+}
+
+// WithTx implements hashtree.StagingTree. It returns the same tree.
+func (t *Tree) WithTx(store.Transaction) hashtree.StagingTree {
+	return t
+}
+
+// Commit implements hashtree.StagingTree. It returns the commit error.
+func (t *Tree) Commit() error {
+	return t.ErrCommit
+}
+
+// treePath is the path returned by the fake tree.
+//
+// - implements hashtree.Path
+type treePath struct {
+	key   []byte
+	value []byte
+	root  []byte
+}
+
+// GetKey implements hashtree.Path.
+func (p treePath) GetKey() []byte {
+	return p.key
+}
+
+// GetValue implements hashtree.Path.
+func (p treePath) GetValue() []byte {
+	return p.value
+}
+
+// GetRoot implements hashtree.Path.
+func (p treePath) GetRoot() []byte {
+	return p.root
+}
+
 // InMemoryDB is a fake implementation of a key/value storage.
 //
 // - implements kv.DB
@@ -0,0 +1,287 @@
+// Package gen provides generators of core messages for the property-based
+// tests written with testing/quick.
+//
+// Each type implements quick.Generator so that it can be used directly as an
+// argument of the checked function. The valid messages use real BLS keys, drawn
+// from a small pool so that the same identities come back, and the fake
+// addresses. Malformed produces inputs meant to be rejected by the decoders.
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+
+	"go.dedis.ch/dela/core/execution"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	treetypes "go.dedis.ch/dela/mino/router/tree/types"
+)
+
+// NumSigners is the size of the pool of signers.
+const NumSigners = 3
+
+// maxLen is the maximum number of elements of the generated lists.
+const maxLen = 5
+
+var signers = makeSigners()
+
+// Signer returns the signer of the pool at the given index.
+func Signer(index int) crypto.Signer {
+	return signers[index%NumSigners]
+}
+
+// Roster is a generated roster of fake addresses and BLS public keys.
+//
+// - implements quick.Generator
+type Roster struct {
+	authority.Roster
+}
+
+// Generate implements quick.Generator.
+func (Roster) Generate(r *rand.Rand, size int) reflect.Value {
+	n := 1 + r.Intn(maxLen)
+
+	addrs := make([]mino.Address, n)
+	pubkeys := make([]crypto.PublicKey, n)
+
+	for i := range addrs {
+		addrs[i] = fake.NewAddress(r.Intn(1 << 16))
+		pubkeys[i] = Signer(r.Intn(NumSigners)).GetPublicKey()
+	}
+
+	return reflect.ValueOf(Roster{Roster: authority.New(addrs, pubkeys)})
+}
+
+// ChangeSet is a generated roster change set.
+//
+// - implements quick.Generator
+type ChangeSet struct {
+	*authority.RosterChangeSet
+}
+
+// Generate implements quick.Generator.
+func (ChangeSet) Generate(r *rand.Rand, size int) reflect.Value {
+	cset := authority.NewChangeSet()
+
+	for i := r.Intn(maxLen); i > 0; i-- {
+		cset.Remove(uint(r.Intn(1 << 16)))
+	}
+
+	for i := r.Intn(maxLen); i > 0; i-- {
+		cset.Add(fake.NewAddress(r.Intn(1<<16)), Signer(r.Intn(NumSigners)).GetPublicKey())
+	}
+
+	for i := r.Intn(maxLen); i > 0; i-- {
+		cset.Replace(uint(r.Intn(1<<16)), Signer(r.Intn(NumSigners)).GetPublicKey())
+	}
+
+	return reflect.ValueOf(ChangeSet{RosterChangeSet: cset})
+}
+
+// Transaction is a generated transaction signed by one of the signers of the
+// pool. The nonce is kept small so that the transactions of a batch have a
+// chance to be valid.
+//
+// - implements quick.Generator
+type Transaction struct {
+	*signed.Transaction
+}
+
+// Generate implements quick.Generator.
+func (Transaction) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(Transaction{Transaction: makeTx(r)})
+}
+
+// Block is a generated block with a result of generated transactions.
+//
+// - implements quick.Generator
+type Block struct {
+	types.Block
+}
+
+// Generate implements quick.Generator.
+func (Block) Generate(r *rand.Rand, size int) reflect.Value {
+	results := make([]simple.TransactionResult, r.Intn(maxLen))
+	for i := range results {
+		reason := ""
+		accepted := r.Intn(2) == 0
+		if !accepted {
+			reason = randString(r)
+		}
+
+		var events []execution.Event
+		if accepted {
+			events = make([]execution.Event, r.Intn(maxLen))
+			for j := range events {
+				events[j] = execution.NewEvent(randString(r), randString(r),
+					randString(r), randString(r))
+			}
+		}
+
+		results[i] = simple.NewTransactionResult(makeTx(r), accepted, reason, events...)
+	}
+
+	var root types.Digest
+	r.Read(root[:])
+
+	block, err := types.NewBlock(simple.NewResult(results),
+		types.WithIndex(uint64(r.Int63())),
+		types.WithTreeRoot(root))
+	if err != nil {
+		panic(fmt.Sprintf("failed to create block: %v", err))
+	}
+
+	return reflect.ValueOf(Block{Block: block})
+}
+
+// Packet is a generated packet of the tree router.
+//
+// - implements quick.Generator
+type Packet struct {
+	*treetypes.Packet
+}
+
+// Generate implements quick.Generator.
+func (Packet) Generate(r *rand.Rand, size int) reflect.Value {
+	dest := make([]mino.Address, r.Intn(maxLen))
+	for i := range dest {
+		dest[i] = fake.NewAddress(r.Intn(1 << 16))
+	}
+
+	msg := make([]byte, r.Intn(size+1))
+	r.Read(msg)
+
+	pkt := treetypes.NewPacket(fake.NewAddress(r.Intn(1<<16)), msg, dest...)
+
+	return reflect.ValueOf(Packet{Packet: pkt})
+}
+
+// Malformed is a generated input for the decoders. It is either random bytes,
+// a truncated JSON document, or a JSON document made of the field names of the
+// messages with values of random types.
+//
+// - implements quick.Generator
+type Malformed struct {
+	Data []byte
+}
+
+// Generate implements quick.Generator.
+func (Malformed) Generate(r *rand.Rand, size int) reflect.Value {
+	var data []byte
+
+	switch r.Intn(3) {
+	case 0:
+		data = make([]byte, r.Intn(size+1))
+		r.Read(data)
+	case 1:
+		data, _ = json.Marshal(randValue(r, 3))
+		data = data[:r.Intn(len(data)+1)]
+	default:
+		data, _ = json.Marshal(randObject(r, 3))
+	}
+
+	return reflect.ValueOf(Malformed{Data: data})
+}
+
+// fieldNames are the names of the fields of the JSON messages, used to build
+// documents that are likely to go further into the decoders.
+var fieldNames = []string{
+	"Nonce", "Args", "PublicKey", "Signature", "Index", "TreeRoot", "Data",
+	"Roster", "Addresses", "PublicKeys", "Remove", "Source", "Dest", "Message",
+	"Txs", "Accepted", "Status", "Reason", "Block", "Views", "Name",
+}
+
+func randObject(r *rand.Rand, depth int) map[string]interface{} {
+	obj := make(map[string]interface{})
+	for i := r.Intn(maxLen); i > 0; i-- {
+		obj[fieldNames[r.Intn(len(fieldNames))]] = randValue(r, depth-1)
+	}
+
+	return obj
+}
+
+func randValue(r *rand.Rand, depth int) interface{} {
+	if depth <= 0 {
+		return r.Int63()
+	}
+
+	switch r.Intn(7) {
+	case 0:
+		return nil
+	case 1:
+		return r.Intn(2) == 0
+	case 2:
+		return r.Int63() - r.Int63()
+	case 3:
+		return randString(r)
+	case 4:
+		list := make([]interface{}, r.Intn(maxLen))
+		for i := range list {
+			list[i] = randValue(r, depth-1)
+		}
+		return list
+	default:
+		return randObject(r, depth)
+	}
+}
+
+func randString(r *rand.Rand) string {
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789:._-"
+
+	buffer := make([]byte, r.Intn(16))
+	for i := range buffer {
+		buffer[i] = letters[r.Intn(len(letters))]
+	}
+
+	return string(buffer)
+}
+
+func makeTx(r *rand.Rand) *signed.Transaction {
+	signer := Signer(r.Intn(NumSigners))
+
+	opts := []signed.TransactionOption{}
+	for i := r.Intn(maxLen); i > 0; i-- {
+		value := make([]byte, 1+r.Intn(16))
+		r.Read(value)
+
+		opts = append(opts, signed.WithArg(randString(r), value))
+	}
+
+	tx, err := signed.NewTransaction(uint64(r.Intn(NumSigners)), signer.GetPublicKey(), opts...)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create tx: %v", err))
+	}
+
+	err = tx.Sign(signer)
+	if err != nil {
+		panic(fmt.Sprintf("failed to sign tx: %v", err))
+	}
+
+	return tx
+}
+
+func makeSigners() []crypto.Signer {
+	list := make([]crypto.Signer, NumSigners)
+	for i := range list {
+		// A small scalar is always valid.
+		scalar := make([]byte, 32)
+		scalar[31] = byte(i + 1)
+
+		signer, err := bls.NewSignerFromBytes(scalar)
+		if err != nil {
+			panic(fmt.Sprintf("failed to create signer: %v", err))
+		}
+
+		list[i] = signer
+	}
+
+	return list
+}
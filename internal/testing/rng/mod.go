@@ -0,0 +1,128 @@
+// Package rng provides a seedable source of randomness shared by the fakes so
+// that a test can be replayed with the exact same values.
+//
+// The seed is read from the environment variable of SeedEnv when it is set,
+// otherwise it is drawn from the clock. A test calling Setup has the seed
+// logged when it fails, which can then be exported to replay the failure.
+package rng
+
+import (
+	"crypto/cipher"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go.dedis.ch/kyber/v3/util/random"
+)
+
+// SeedEnv is the environment variable that defines the seed.
+const SeedEnv = "DELA_TEST_SEED"
+
+var (
+	lock   sync.Mutex
+	seed   int64
+	source *rand.Rand
+)
+
+func init() {
+	seed = time.Now().UnixNano()
+
+	value, err := strconv.ParseInt(os.Getenv(SeedEnv), 10, 64)
+	if err == nil {
+		seed = value
+	}
+
+	source = rand.New(rand.NewSource(seed))
+}
+
+// Setup resets the source with the seed of the environment, or a new one, and
+// makes the test log the seed when it fails. It returns the seed.
+func Setup(t *testing.T) int64 {
+	value, err := strconv.ParseInt(os.Getenv(SeedEnv), 10, 64)
+	if err != nil {
+		value = time.Now().UnixNano()
+	}
+
+	Seed(value)
+
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("replay with %s=%d", SeedEnv, value)
+		}
+	})
+
+	return value
+}
+
+// Seed resets the source with the given seed.
+func Seed(value int64) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	seed = value
+	source = rand.New(rand.NewSource(value))
+}
+
+// GetSeed returns the seed of the source.
+func GetSeed() int64 {
+	lock.Lock()
+	defer lock.Unlock()
+
+	return seed
+}
+
+// Read fills the buffer with random bytes. It never returns an error.
+func Read(buffer []byte) (int, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	return source.Read(buffer)
+}
+
+// Bytes returns a buffer of n random bytes.
+func Bytes(n int) []byte {
+	buffer := make([]byte, n)
+	Read(buffer)
+
+	return buffer
+}
+
+// Intn returns a random integer in [0, n).
+func Intn(n int) int {
+	lock.Lock()
+	defer lock.Unlock()
+
+	return source.Intn(n)
+}
+
+// Int63 returns a random non-negative integer.
+func Int63() int64 {
+	lock.Lock()
+	defer lock.Unlock()
+
+	return source.Int63()
+}
+
+// Reader returns a reader of random bytes drawn from the source.
+func Reader() Source {
+	return Source{}
+}
+
+// Stream returns a cipher stream drawn from the source, which can be used to
+// pick Kyber scalars.
+func Stream() cipher.Stream {
+	return random.New(Reader())
+}
+
+// Source is a reader of random bytes drawn from the shared source.
+//
+// - implements io.Reader
+type Source struct{}
+
+// Read implements io.Reader.
+func (Source) Read(buffer []byte) (int, error) {
+	return Read(buffer)
+}
@@ -0,0 +1,51 @@
+package rng
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3/suites"
+)
+
+func TestSeed(t *testing.T) {
+	Seed(42)
+	require.Equal(t, int64(42), GetSeed())
+
+	first := Bytes(16)
+	n := Intn(100)
+	i := Int63()
+
+	Seed(42)
+	require.Equal(t, first, Bytes(16))
+	require.Equal(t, n, Intn(100))
+	require.Equal(t, i, Int63())
+}
+
+func TestSetup(t *testing.T) {
+	os.Setenv(SeedEnv, "7")
+	defer os.Unsetenv(SeedEnv)
+
+	require.Equal(t, int64(7), Setup(t))
+	require.Equal(t, int64(7), GetSeed())
+
+	os.Setenv(SeedEnv, "abc")
+	require.NotEqual(t, int64(7), Setup(t))
+}
+
+func TestStream(t *testing.T) {
+	suite := suites.MustFind("Ed25519")
+
+	Seed(1)
+	a := suite.Scalar().Pick(Stream())
+
+	Seed(1)
+	b := suite.Scalar().Pick(Stream())
+
+	require.True(t, a.Equal(b))
+
+	buffer := make([]byte, 4)
+	n, err := Reader().Read(buffer)
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+}
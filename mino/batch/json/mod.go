@@ -0,0 +1,81 @@
+package json
+
+import (
+	"encoding/json"
+
+	"go.dedis.ch/dela/mino/batch"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	batch.RegisterMessageFormat(serde.FormatJSON, msgFormat{})
+}
+
+// BatchJSON is the JSON message of a batch.
+type BatchJSON struct {
+	Messages []json.RawMessage
+}
+
+// MsgFormat is the engine to encode and decode batches in JSON format.
+//
+// - implements serde.FormatEngine
+type msgFormat struct{}
+
+// Encode implements serde.FormatEngine. It returns the serialized data of the
+// batch if appropriate, otherwise it returns an error.
+func (f msgFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	b, ok := msg.(batch.Batch)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message '%T'", msg)
+	}
+
+	msgs := b.GetMessages()
+
+	bJSON := BatchJSON{
+		Messages: make([]json.RawMessage, len(msgs)),
+	}
+
+	for i, m := range msgs {
+		data, err := m.Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to serialize message: %v", err)
+		}
+
+		bJSON.Messages[i] = data
+	}
+
+	data, err := ctx.Marshal(bJSON)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the batch if appropriate,
+// otherwise it returns an error.
+func (f msgFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := BatchJSON{}
+
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	fac := ctx.GetFactory(batch.MsgKey{})
+	if fac == nil {
+		return nil, xerrors.New("invalid message factory '<nil>'")
+	}
+
+	msgs := make([]serde.Message, len(m.Messages))
+
+	for i, raw := range m.Messages {
+		msgs[i], err = fac.Deserialize(ctx, raw)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to deserialize message: %v", err)
+		}
+	}
+
+	return batch.NewBatch(msgs...), nil
+}
@@ -0,0 +1,51 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino/batch"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestMsgFormat_Encode(t *testing.T) {
+	format := msgFormat{}
+
+	ctx := fake.NewContext()
+
+	data, err := format.Encode(ctx, batch.NewBatch(fake.Message{}, fake.Message{}))
+	require.NoError(t, err)
+	require.Equal(t, `{"Messages":[{},{}]}`, string(data))
+
+	_, err = format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message 'fake.Message'")
+
+	_, err = format.Encode(ctx, batch.NewBatch(fake.NewBadPublicKey()))
+	require.EqualError(t, err, fake.Err("failed to serialize message"))
+
+	_, err = format.Encode(fake.NewBadContextWithDelay(1), batch.NewBatch(fake.Message{}))
+	require.EqualError(t, err, fake.Err("failed to marshal"))
+}
+
+func TestMsgFormat_Decode(t *testing.T) {
+	format := msgFormat{}
+
+	ctx := fake.NewContext()
+	ctx = serde.WithFactory(ctx, batch.MsgKey{}, fake.MessageFactory{})
+
+	msg, err := format.Decode(ctx, []byte(`{"Messages":[{},{}]}`))
+	require.NoError(t, err)
+	require.Equal(t, batch.NewBatch(fake.Message{}, fake.Message{}), msg)
+
+	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("failed to unmarshal"))
+
+	badCtx := serde.WithFactory(ctx, batch.MsgKey{}, nil)
+	_, err = format.Decode(badCtx, []byte(`{}`))
+	require.EqualError(t, err, "invalid message factory '<nil>'")
+
+	badCtx = serde.WithFactory(ctx, batch.MsgKey{}, fake.NewBadMessageFactory())
+	_, err = format.Decode(badCtx, []byte(`{"Messages":[{}]}`))
+	require.EqualError(t, err, fake.Err("failed to deserialize message"))
+}
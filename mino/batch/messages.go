@@ -0,0 +1,84 @@
+package batch
+
+import (
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
+	"golang.org/x/xerrors"
+)
+
+var msgFormats = registry.NewSimpleRegistry()
+
+// RegisterMessageFormat registers the engine for the provided format.
+func RegisterMessageFormat(f serde.Format, e serde.FormatEngine) {
+	msgFormats.Register(f, e)
+}
+
+// Batch is a group of messages sent to the same peer in a single packet.
+//
+// - implements serde.Message
+type Batch struct {
+	msgs []serde.Message
+}
+
+// NewBatch returns a new batch of the messages.
+func NewBatch(msgs ...serde.Message) Batch {
+	return Batch{
+		msgs: msgs,
+	}
+}
+
+// GetMessages returns the messages of the batch in the order they were sent.
+func (b Batch) GetMessages() []serde.Message {
+	return append([]serde.Message{}, b.msgs...)
+}
+
+// Len returns the number of messages in the batch.
+func (b Batch) Len() int {
+	return len(b.msgs)
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// batch.
+func (b Batch) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, b)
+	if err != nil {
+		return nil, xerrors.Errorf("encoding failed: %v", err)
+	}
+
+	return data, nil
+}
+
+// MsgKey is the key of the factory of the batched messages.
+type MsgKey struct{}
+
+// Factory is the factory of the batches.
+//
+// - implements serde.Factory
+type Factory struct {
+	msgFac serde.Factory
+}
+
+// NewFactory returns a factory of batches of the messages deserialized by the
+// given factory.
+func NewFactory(f serde.Factory) Factory {
+	return Factory{
+		msgFac: f,
+	}
+}
+
+// Deserialize implements serde.Factory. It populates the batch if appropriate,
+// otherwise it returns an error.
+func (f Factory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	ctx = serde.WithFactory(ctx, MsgKey{}, f.msgFac)
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("decoding failed: %v", err)
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,43 @@
+package batch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/serde"
+)
+
+func init() {
+	RegisterMessageFormat(fake.GoodFormat, fake.Format{Msg: NewBatch(fake.Message{})})
+	RegisterMessageFormat(fake.BadFormat, fake.NewBadFormat())
+}
+
+func TestBatch_Getters(t *testing.T) {
+	b := NewBatch(fake.Message{}, fake.Message{})
+
+	require.Equal(t, 2, b.Len())
+	require.Equal(t, []serde.Message{fake.Message{}, fake.Message{}}, b.GetMessages())
+}
+
+func TestBatch_Serialize(t *testing.T) {
+	b := NewBatch(fake.Message{})
+
+	data, err := b.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = b.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("encoding failed"))
+}
+
+func TestFactory_Deserialize(t *testing.T) {
+	fac := NewFactory(fake.MessageFactory{})
+
+	msg, err := fac.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.Equal(t, NewBatch(fake.Message{}), msg)
+
+	_, err = fac.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("decoding failed"))
+}
@@ -0,0 +1,378 @@
+// Package batch implements an opt-in batching of the messages sent on the
+// streams of an RPC.
+//
+// Protocols that send many small messages to the same peers pay the framing
+// and the system call overhead of each packet. The sender of this package
+// delays the messages to a peer for a short deadline, in the spirit of the
+// Nagle algorithm, and sends the messages collected during that time in a
+// single batch. A batch is sent right away when it is full. The receiver
+// unpacks the batches and returns the messages in the order they were sent.
+//
+// The counters of the batching can be collected with the metrics option to
+// measure the number of packets saved.
+//
+// The RPC must be created with the factory of this package wrapping the
+// factory of the messages so that the batches can be decoded.
+package batch
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/wrapper"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// DefaultDelay is the default amount of time a message waits for other
+	// messages to the same peer before its batch is sent.
+	DefaultDelay = 500 * time.Microsecond
+
+	// DefaultMaxMessages is the default number of messages after which a
+	// batch is sent without waiting for the deadline.
+	DefaultMaxMessages = 64
+)
+
+// Metrics are the counters of the batching. They can be shared by several
+// streams and read while the streams are running.
+type Metrics struct {
+	messages        uint64
+	batches         uint64
+	fullFlushes     uint64
+	deadlineFlushes uint64
+}
+
+// GetMessages returns the number of messages sent.
+func (m *Metrics) GetMessages() uint64 {
+	return atomic.LoadUint64(&m.messages)
+}
+
+// GetBatches returns the number of batches sent, which is the number of
+// packets for the underlying sender.
+func (m *Metrics) GetBatches() uint64 {
+	return atomic.LoadUint64(&m.batches)
+}
+
+// GetFullFlushes returns the number of batches sent because they were full.
+func (m *Metrics) GetFullFlushes() uint64 {
+	return atomic.LoadUint64(&m.fullFlushes)
+}
+
+// GetDeadlineFlushes returns the number of batches sent because of the
+// deadline.
+func (m *Metrics) GetDeadlineFlushes() uint64 {
+	return atomic.LoadUint64(&m.deadlineFlushes)
+}
+
+// Option is the type of option to configure the batched streams.
+type Option func(*template)
+
+// WithDelay sets the amount of time a message waits for other messages to the
+// same peer before its batch is sent.
+func WithDelay(d time.Duration) Option {
+	return func(tmpl *template) {
+		tmpl.delay = d
+	}
+}
+
+// WithMaxMessages sets the number of messages after which a batch is sent
+// without waiting for the deadline.
+func WithMaxMessages(n int) Option {
+	return func(tmpl *template) {
+		tmpl.maxMessages = n
+	}
+}
+
+// WithMetrics sets the counters updated by the streams.
+func WithMetrics(m *Metrics) Option {
+	return func(tmpl *template) {
+		tmpl.metrics = m
+	}
+}
+
+type template struct {
+	delay       time.Duration
+	maxMessages int
+	metrics     *Metrics
+}
+
+func newTemplate(opts []Option) template {
+	tmpl := template{
+		delay:       DefaultDelay,
+		maxMessages: DefaultMaxMessages,
+		metrics:     &Metrics{},
+	}
+
+	for _, opt := range opts {
+		opt(&tmpl)
+	}
+
+	if tmpl.maxMessages <= 0 {
+		tmpl.maxMessages = 1
+	}
+
+	return tmpl
+}
+
+// NewRPC returns a new RPC that batches the messages of the streams. The RPC to
+// wrap must be created with the handler and the factory of this package.
+func NewRPC(rpc mino.RPC, opts ...Option) wrapper.RPC {
+	return wrapper.NewRPC(rpc, codec{}, makeStream(opts))
+}
+
+// NewHandler returns a new handler so that the stream it handles batches the
+// messages.
+func NewHandler(h mino.Handler, opts ...Option) wrapper.Handler {
+	return wrapper.NewHandler(h, codec{}, makeStream(opts))
+}
+
+func makeStream(opts []Option) wrapper.StreamFunc {
+	return func(ctx context.Context,
+		out mino.Sender, in mino.Receiver) (mino.Sender, mino.Receiver) {
+
+		return Wrap(out, in, opts...)
+	}
+}
+
+// codec wraps the requests and the replies of the calls in a batch of their
+// own as there is nothing to wait for.
+//
+// - implements wrapper.Codec
+type codec struct{}
+
+// Wrap implements wrapper.Codec. It returns a batch of the single message.
+func (codec) Wrap(msg serde.Message) serde.Message {
+	return NewBatch(msg)
+}
+
+// Unwrap implements wrapper.Codec. It returns the message of a batch of a
+// single message.
+func (codec) Unwrap(msg serde.Message) serde.Message {
+	b, ok := msg.(Batch)
+	if ok && b.Len() == 1 {
+		return b.msgs[0]
+	}
+
+	return msg
+}
+
+// Wrap returns a sender that batches the messages to the same peer and a
+// receiver that unpacks the batches.
+func Wrap(out mino.Sender, in mino.Receiver, opts ...Option) (mino.Sender, mino.Receiver) {
+	tmpl := newTemplate(opts)
+
+	s := &sender{
+		out:         out,
+		delay:       tmpl.delay,
+		maxMessages: tmpl.maxMessages,
+		metrics:     tmpl.metrics,
+		peers:       make(map[string]*peer),
+	}
+
+	r := &receiver{
+		in: in,
+	}
+
+	return s, r
+}
+
+// entry is a message waiting in a batch, with the channel to report the
+// failure of the batch.
+type entry struct {
+	msg  serde.Message
+	errs chan<- error
+	wg   *sync.WaitGroup
+}
+
+// peer is the state of the batches to a peer. The batches are sent one after
+// the other so that the order of the messages is preserved.
+type peer struct {
+	to       mino.Address
+	current  []entry
+	queue    [][]entry
+	flushing bool
+	// gen is incremented every time the current batch is flushed.
+	gen uint64
+}
+
+// sender is a sender that groups the messages to the same peer.
+//
+// - implements mino.Sender
+type sender struct {
+	sync.Mutex
+
+	out         mino.Sender
+	delay       time.Duration
+	maxMessages int
+	metrics     *Metrics
+	peers       map[string]*peer
+}
+
+// Send implements mino.Sender. It adds the message to the batch of each
+// address, and populates the channel with the errors of the batches once they
+// are sent.
+func (s *sender) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	errs := make(chan error, len(addrs))
+
+	wg := &sync.WaitGroup{}
+	wg.Add(len(addrs))
+
+	s.Lock()
+
+	for _, addr := range addrs {
+		s.push(addr, entry{msg: msg, errs: errs, wg: wg})
+	}
+
+	s.Unlock()
+
+	go func() {
+		wg.Wait()
+		close(errs)
+	}()
+
+	return errs
+}
+
+func (s *sender) push(to mino.Address, e entry) {
+	key := to.String()
+
+	p, found := s.peers[key]
+	if !found {
+		p = &peer{to: to}
+		s.peers[key] = p
+	}
+
+	p.current = append(p.current, e)
+
+	if len(p.current) >= s.maxMessages {
+		atomic.AddUint64(&s.metrics.fullFlushes, 1)
+		s.flush(p)
+
+		return
+	}
+
+	if len(p.current) == 1 {
+		gen := p.gen
+
+		time.AfterFunc(s.delay, func() {
+			s.Lock()
+			defer s.Unlock()
+
+			// The batch might have been sent already because it was full, in
+			// which case the deadline belongs to a batch that is gone.
+			if p.gen == gen {
+				atomic.AddUint64(&s.metrics.deadlineFlushes, 1)
+				s.flush(p)
+			}
+		})
+	}
+}
+
+// flush moves the current batch of the peer to the queue of the batches to
+// send. It must be called with the lock.
+func (s *sender) flush(p *peer) {
+	p.queue = append(p.queue, p.current)
+	p.current = nil
+	p.gen++
+
+	if !p.flushing {
+		p.flushing = true
+		go s.drain(p)
+	}
+}
+
+func (s *sender) drain(p *peer) {
+	for {
+		s.Lock()
+
+		if len(p.queue) == 0 {
+			p.flushing = false
+
+			if len(p.current) == 0 {
+				delete(s.peers, p.to.String())
+			}
+
+			s.Unlock()
+			return
+		}
+
+		entries := p.queue[0]
+		p.queue = p.queue[1:]
+
+		s.Unlock()
+
+		s.send(p.to, entries)
+	}
+}
+
+func (s *sender) send(to mino.Address, entries []entry) {
+	msgs := make([]serde.Message, len(entries))
+	for i, e := range entries {
+		msgs[i] = e.msg
+	}
+
+	atomic.AddUint64(&s.metrics.messages, uint64(len(msgs)))
+	atomic.AddUint64(&s.metrics.batches, 1)
+
+	var err error
+	for e := range s.out.Send(NewBatch(msgs...), to) {
+		err = xerrors.Errorf("failed to send batch to %v: %v", to, e)
+	}
+
+	for _, e := range entries {
+		if err != nil {
+			e.errs <- err
+		}
+
+		e.wg.Done()
+	}
+}
+
+// receiver is a receiver that returns the messages of the batches one by one.
+//
+// - implements mino.Receiver
+type receiver struct {
+	sync.Mutex
+
+	in    mino.Receiver
+	from  mino.Address
+	queue []serde.Message
+}
+
+// Recv implements mino.Receiver. It returns the next message of the current
+// batch, or waits for the next batch.
+func (r *receiver) Recv(ctx context.Context) (mino.Address, serde.Message, error) {
+	for {
+		r.Lock()
+
+		if len(r.queue) > 0 {
+			msg := r.queue[0]
+			r.queue = r.queue[1:]
+
+			r.Unlock()
+
+			return r.from, msg, nil
+		}
+
+		r.Unlock()
+
+		from, msg, err := r.in.Recv(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		b, ok := msg.(Batch)
+		if !ok {
+			return from, msg, nil
+		}
+
+		r.Lock()
+		r.from = from
+		r.queue = b.GetMessages()
+		r.Unlock()
+	}
+}
@@ -0,0 +1,184 @@
+package batch
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestCodec(t *testing.T) {
+	msg := codec{}.Wrap(fake.Message{})
+	require.Equal(t, NewBatch(fake.Message{}), msg)
+	require.Equal(t, fake.Message{}, codec{}.Unwrap(msg))
+	require.Equal(t, fake.Message{}, codec{}.Unwrap(fake.Message{}))
+
+	batch := NewBatch(fake.Message{}, fake.Message{})
+	require.Equal(t, batch, codec{}.Unwrap(batch))
+}
+
+func TestRPC_Stream(t *testing.T) {
+	rpc := fake.NewStreamRPC(fake.NewReceiver(), fake.Sender{})
+
+	s, r, err := NewRPC(rpc, WithMaxMessages(0)).Stream(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, s.(*sender).maxMessages)
+	require.IsType(t, &receiver{}, r)
+
+	_, _, err = NewRPC(fake.NewBadRPC()).Stream(context.Background(), nil)
+	require.EqualError(t, err, fake.Err("stream failed"))
+}
+
+func TestHandler_Stream(t *testing.T) {
+	h := NewHandler(fakeHandler{}, WithDelay(time.Second))
+
+	err := h.Stream(fake.Sender{}, fake.NewReceiver())
+	require.NoError(t, err)
+}
+
+func TestSender_Deadline_Send(t *testing.T) {
+	out := &fakeSender{}
+	metrics := &Metrics{}
+
+	s, _ := Wrap(out, fake.NewReceiver(), WithDelay(20*time.Millisecond), WithMetrics(metrics))
+
+	errs1 := s.Send(makeMsg(1), fake.NewAddress(0), fake.NewAddress(1))
+	errs2 := s.Send(makeMsg(2), fake.NewAddress(0))
+
+	require.Empty(t, drain(errs1))
+	require.Empty(t, drain(errs2))
+
+	require.Len(t, out.batches, 2)
+	require.Equal(t, uint64(3), metrics.GetMessages())
+	require.Equal(t, uint64(2), metrics.GetBatches())
+	require.Equal(t, uint64(2), metrics.GetDeadlineFlushes())
+	require.Equal(t, uint64(0), metrics.GetFullFlushes())
+
+	for _, b := range out.batches {
+		if b.to.Equal(fake.NewAddress(0)) {
+			require.Equal(t, NewBatch(makeMsg(1), makeMsg(2)), b.msg)
+		} else {
+			require.Equal(t, NewBatch(makeMsg(1)), b.msg)
+		}
+	}
+}
+
+func TestSender_Full_Send(t *testing.T) {
+	out := &fakeSender{}
+	metrics := &Metrics{}
+
+	s, _ := Wrap(out, fake.NewReceiver(),
+		WithDelay(time.Hour), WithMaxMessages(2), WithMetrics(metrics))
+
+	errs := []<-chan error{}
+	for i := 0; i < 4; i++ {
+		errs = append(errs, s.Send(makeMsg(i), fake.NewAddress(0)))
+	}
+
+	for _, ch := range errs {
+		require.Empty(t, drain(ch))
+	}
+
+	// The batches are sent in order.
+	require.Len(t, out.batches, 2)
+	require.Equal(t, NewBatch(makeMsg(0), makeMsg(1)), out.batches[0].msg)
+	require.Equal(t, NewBatch(makeMsg(2), makeMsg(3)), out.batches[1].msg)
+	require.Equal(t, uint64(2), metrics.GetFullFlushes())
+	require.Equal(t, uint64(0), metrics.GetDeadlineFlushes())
+}
+
+func TestSender_BadSender_Send(t *testing.T) {
+	s, _ := Wrap(fake.NewBadSender(), fake.NewReceiver(), WithDelay(time.Millisecond))
+
+	errs := drain(s.Send(fake.Message{}, fake.NewAddress(0)))
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0], fake.Err("failed to send batch to fake.Address[0]"))
+}
+
+func TestReceiver_Recv(t *testing.T) {
+	in := fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), NewBatch(makeMsg(1), makeMsg(2))),
+		fake.NewRecvMsg(fake.NewAddress(1), NewBatch()),
+		fake.NewRecvMsg(fake.NewAddress(1), fake.Message{}),
+	)
+
+	_, r := Wrap(fake.Sender{}, in)
+
+	from, msg, err := r.Recv(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, fake.NewAddress(0), from)
+	require.Equal(t, makeMsg(1), msg)
+
+	from, msg, err = r.Recv(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, fake.NewAddress(0), from)
+	require.Equal(t, makeMsg(2), msg)
+
+	from, msg, err = r.Recv(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, fake.NewAddress(1), from)
+	require.Equal(t, fake.Message{}, msg)
+
+	_, _, err = r.Recv(context.Background())
+	require.Equal(t, io.EOF, err)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeHandler struct {
+	mino.UnsupportedHandler
+}
+
+func (h fakeHandler) Stream(out mino.Sender, in mino.Receiver) error {
+	s, ok := out.(*sender)
+	if !ok || s.delay != time.Second {
+		return fake.GetError()
+	}
+
+	return nil
+}
+
+type sentBatch struct {
+	to  mino.Address
+	msg serde.Message
+}
+
+// fakeSender records the batches sent to each address.
+type fakeSender struct {
+	sync.Mutex
+
+	batches []sentBatch
+}
+
+func (s *fakeSender) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	s.Lock()
+	for _, addr := range addrs {
+		s.batches = append(s.batches, sentBatch{to: addr, msg: msg})
+	}
+	s.Unlock()
+
+	errs := make(chan error)
+	close(errs)
+
+	return errs
+}
+
+func makeMsg(i int) fake.Message {
+	return fake.Message{Digest: []byte{byte(i)}}
+}
+
+func drain(errs <-chan error) []error {
+	list := []error{}
+	for err := range errs {
+		list = append(list, err)
+	}
+
+	return list
+}
@@ -0,0 +1,75 @@
+package mino
+
+// Class is the traffic class of the messages of an RPC. An overlay that shares
+// a connection between several RPCs sends the messages of the higher classes
+// first, so that time-critical protocols are not delayed by bulk transfers.
+type Class int
+
+const (
+	// ClassBulk is the class of the background transfers, like the
+	// synchronization of the blocks or the gossip of the transactions.
+	ClassBulk Class = iota - 1
+
+	// ClassDefault is the class of the RPCs that are not classified, which is
+	// also the zero value.
+	ClassDefault
+
+	// ClassConsensus is the class of the messages of the consensus, like the
+	// prepare and the commit phases.
+	ClassConsensus
+)
+
+// String implements fmt.Stringer. It returns a human-readable name of the
+// class.
+func (c Class) String() string {
+	switch c {
+	case ClassBulk:
+		return "bulk"
+	case ClassDefault:
+		return "default"
+	case ClassConsensus:
+		return "consensus"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassifiedHandler is a handler that defines the traffic class of the
+// messages of its RPC. The overlay reads the class when the RPC is created.
+//
+// - implements mino.Handler
+type ClassifiedHandler struct {
+	Handler
+
+	class Class
+}
+
+// NewClassifiedHandler returns a handler for the messages of the given class.
+func NewClassifiedHandler(h Handler, class Class) ClassifiedHandler {
+	return ClassifiedHandler{
+		Handler: h,
+		class:   class,
+	}
+}
+
+// GetClass returns the traffic class of the handler.
+func (h ClassifiedHandler) GetClass() Class {
+	return h.class
+}
+
+// ClassOf returns the traffic class of the handler, or the default class if it
+// is not classified.
+func ClassOf(h Handler) Class {
+	switch handler := h.(type) {
+	case ClassifiedHandler:
+		return handler.class
+	case ScopedHandler:
+		return ClassOf(handler.Handler)
+	case CompressedHandler:
+		return ClassOf(handler.Handler)
+	case PolicyHandler:
+		return ClassOf(handler.Handler)
+	default:
+		return ClassDefault
+	}
+}
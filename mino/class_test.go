@@ -0,0 +1,28 @@
+package mino
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClass_String(t *testing.T) {
+	require.Equal(t, "bulk", ClassBulk.String())
+	require.Equal(t, "default", ClassDefault.String())
+	require.Equal(t, "consensus", ClassConsensus.String())
+	require.Equal(t, "unknown", Class(42).String())
+}
+
+func TestClassifiedHandler_GetClass(t *testing.T) {
+	h := NewClassifiedHandler(UnsupportedHandler{}, ClassBulk)
+
+	require.Equal(t, ClassBulk, h.GetClass())
+}
+
+func TestClassOf(t *testing.T) {
+	h := NewClassifiedHandler(UnsupportedHandler{}, ClassConsensus)
+
+	require.Equal(t, ClassConsensus, ClassOf(h))
+	require.Equal(t, ClassConsensus, ClassOf(NewScopedHandler(h, nil)))
+	require.Equal(t, ClassDefault, ClassOf(UnsupportedHandler{}))
+}
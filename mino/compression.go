@@ -0,0 +1,58 @@
+package mino
+
+// Compression is the name of the algorithm that compresses the messages of an
+// RPC, which is worth it for the RPCs that carry large payloads, like the
+// synchronization of the blocks, when the participants are connected by WAN
+// links.
+type Compression string
+
+const (
+	// CompressionNone is the zero value which leaves the messages
+	// uncompressed.
+	CompressionNone Compression = ""
+
+	// CompressionGzip compresses the messages with gzip.
+	CompressionGzip Compression = "gzip"
+)
+
+// CompressedHandler is a handler that defines the compression of the messages
+// of its RPC. The overlay reads the compression when the RPC is created, and an
+// overlay that doesn't support the algorithm sends the messages uncompressed.
+//
+// - implements mino.Handler
+type CompressedHandler struct {
+	Handler
+
+	compression Compression
+}
+
+// WithCompression returns a handler whose RPC compresses the messages with the
+// given algorithm.
+func WithCompression(h Handler, c Compression) CompressedHandler {
+	return CompressedHandler{
+		Handler:     h,
+		compression: c,
+	}
+}
+
+// GetCompression returns the compression of the handler.
+func (h CompressedHandler) GetCompression() Compression {
+	return h.compression
+}
+
+// CompressionOf returns the compression of the handler, or none if it doesn't
+// define one.
+func CompressionOf(h Handler) Compression {
+	switch handler := h.(type) {
+	case CompressedHandler:
+		return handler.compression
+	case ClassifiedHandler:
+		return CompressionOf(handler.Handler)
+	case ScopedHandler:
+		return CompressionOf(handler.Handler)
+	case PolicyHandler:
+		return CompressionOf(handler.Handler)
+	default:
+		return CompressionNone
+	}
+}
@@ -0,0 +1,29 @@
+package mino
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressedHandler_GetCompression(t *testing.T) {
+	h := WithCompression(UnsupportedHandler{}, CompressionGzip)
+
+	require.Equal(t, CompressionGzip, h.GetCompression())
+}
+
+func TestCompressionOf(t *testing.T) {
+	h := WithCompression(UnsupportedHandler{}, CompressionGzip)
+
+	require.Equal(t, CompressionGzip, CompressionOf(h))
+	require.Equal(t, CompressionGzip, CompressionOf(NewClassifiedHandler(h, ClassBulk)))
+	require.Equal(t, CompressionGzip, CompressionOf(NewScopedHandler(h, nil)))
+	require.Equal(t, CompressionNone, CompressionOf(UnsupportedHandler{}))
+
+	// The class and the scope are found through the compressed handler.
+	scoped := NewScopedHandler(NewClassifiedHandler(UnsupportedHandler{}, ClassBulk), nil)
+	require.Equal(t, ClassBulk, ClassOf(WithCompression(scoped, CompressionGzip)))
+
+	_, ok := scopeOf(WithCompression(scoped, CompressionGzip))
+	require.True(t, ok)
+}
@@ -43,7 +43,8 @@ func NewFlat(m mino.Mino, f serde.Factory) *Flat {
 // Listen implements gossip.Gossiper. It creates the RPC and starts to listen
 // for incoming rumors while spreading its own ones.
 func (flat *Flat) Listen() (Actor, error) {
-	h := handler{Flat: flat}
+	// Rumors are spread in the background and must not delay the consensus.
+	h := mino.NewClassifiedHandler(handler{Flat: flat}, mino.ClassBulk)
 
 	actor := &flatActor{
 		logger: dela.Logger.With().Str("addr", flat.mino.GetAddress().String()).Logger(),
@@ -0,0 +1,50 @@
+package json
+
+import (
+	"go.dedis.ch/dela/mino/heartbeat"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	heartbeat.RegisterPingFormat(serde.FormatJSON, pingFormat{})
+}
+
+// PingJSON is the JSON message of a ping.
+type PingJSON struct {
+	Seq uint64
+}
+
+// PingFormat is the engine to encode and decode pings in JSON format.
+//
+// - implements serde.FormatEngine
+type pingFormat struct{}
+
+// Encode implements serde.FormatEngine. It returns the serialized data of the
+// ping if appropriate, otherwise it returns an error.
+func (f pingFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	ping, ok := msg.(heartbeat.Ping)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message '%T'", msg)
+	}
+
+	data, err := ctx.Marshal(PingJSON{Seq: ping.GetSeq()})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the ping if appropriate,
+// otherwise it returns an error.
+func (f pingFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := PingJSON{}
+
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	return heartbeat.NewPing(m.Seq), nil
+}
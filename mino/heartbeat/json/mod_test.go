@@ -0,0 +1,38 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino/heartbeat"
+)
+
+func TestPingFormat_Encode(t *testing.T) {
+	format := pingFormat{}
+
+	ctx := fake.NewContext()
+
+	data, err := format.Encode(ctx, heartbeat.NewPing(3))
+	require.NoError(t, err)
+	require.Equal(t, `{"Seq":3}`, string(data))
+
+	_, err = format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message 'fake.Message'")
+
+	_, err = format.Encode(fake.NewBadContext(), heartbeat.NewPing(0))
+	require.EqualError(t, err, fake.Err("failed to marshal"))
+}
+
+func TestPingFormat_Decode(t *testing.T) {
+	format := pingFormat{}
+
+	ctx := fake.NewContext()
+
+	msg, err := format.Decode(ctx, []byte(`{"Seq":3}`))
+	require.NoError(t, err)
+	require.Equal(t, heartbeat.NewPing(3), msg)
+
+	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("failed to unmarshal"))
+}
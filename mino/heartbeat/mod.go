@@ -0,0 +1,410 @@
+// Package heartbeat implements a service that periodically pings the known
+// peers of an overlay, and records their round-trip time and the last time
+// they answered.
+//
+// A round sends a ping to every peer with a single call, and the time at which
+// each reply arrives gives the round-trip time of the peer. A peer that doesn't
+// answer before the timeout, or answers with an error, misses the heartbeat.
+// The service implements mino.PeerStatus so that the other services, like the
+// leader election of the consensus, can prefer the responsive peers.
+package heartbeat
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// DefaultInterval is the default amount of time between two rounds.
+	DefaultInterval = 5 * time.Second
+
+	// DefaultTimeout is the default amount of time a peer has to answer a
+	// ping.
+	DefaultTimeout = 2 * time.Second
+
+	rpcName = "heartbeat"
+)
+
+var pingFormats = registry.NewSimpleRegistry()
+
+// RegisterPingFormat registers the engine for the provided format.
+func RegisterPingFormat(f serde.Format, e serde.FormatEngine) {
+	pingFormats.Register(f, e)
+}
+
+// Ping is the message sent to a peer, which replies with the same message.
+//
+// - implements serde.Message
+type Ping struct {
+	seq uint64
+}
+
+// NewPing returns a new ping with the sequence number.
+func NewPing(seq uint64) Ping {
+	return Ping{seq: seq}
+}
+
+// GetSeq returns the sequence number of the round of the ping.
+func (p Ping) GetSeq() uint64 {
+	return p.seq
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// ping.
+func (p Ping) Serialize(ctx serde.Context) ([]byte, error) {
+	format := pingFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, p)
+	if err != nil {
+		return nil, xerrors.Errorf("encoding failed: %v", err)
+	}
+
+	return data, nil
+}
+
+// PingFactory is the factory of the pings.
+//
+// - implements serde.Factory
+type PingFactory struct{}
+
+// Deserialize implements serde.Factory. It populates the ping if appropriate,
+// otherwise it returns an error.
+func (PingFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	format := pingFormats.Get(ctx.GetFormat())
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("decoding failed: %v", err)
+	}
+
+	return msg, nil
+}
+
+// Option is the type of option to configure the service.
+type Option func(*Service)
+
+// WithInterval sets the amount of time between two rounds.
+func WithInterval(d time.Duration) Option {
+	return func(s *Service) {
+		s.interval = d
+	}
+}
+
+// WithTimeout sets the amount of time a peer has to answer a ping.
+func WithTimeout(d time.Duration) Option {
+	return func(s *Service) {
+		s.timeout = d
+	}
+}
+
+// WithPeerSource sets the function that returns the peers to ping. It is
+// called before each round of the background service and replaces the list of
+// the peers.
+func WithPeerSource(fn func() ([]mino.Address, error)) Option {
+	return func(s *Service) {
+		s.source = fn
+	}
+}
+
+// Service pings the known peers at a regular interval.
+//
+// - implements mino.PeerStatus
+type Service struct {
+	sync.Mutex
+
+	rpc      mino.RPC
+	me       mino.Address
+	interval time.Duration
+	timeout  time.Duration
+	source   func() ([]mino.Address, error)
+	logger   zerolog.Logger
+
+	seq    uint64
+	peers  []mino.Address
+	status map[string]*mino.Liveness
+
+	closing chan struct{}
+	done    chan struct{}
+}
+
+// NewService creates a new heartbeat service on top of the overlay. The
+// overlay must not already have an RPC for the heartbeats.
+func NewService(m mino.Mino, opts ...Option) (*Service, error) {
+	h := mino.NewClassifiedHandler(handler{}, mino.ClassConsensus)
+
+	rpc, err := m.CreateRPC(rpcName, h, PingFactory{})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create rpc: %v", err)
+	}
+
+	s := &Service{
+		rpc:      rpc,
+		me:       m.GetAddress(),
+		interval: DefaultInterval,
+		timeout:  DefaultTimeout,
+		logger:   dela.Logger.With().Str("service", "heartbeat").Logger(),
+		status:   make(map[string]*mino.Liveness),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+// SetPeers replaces the list of the peers to ping. The address of the node is
+// ignored, and the status of the peers that are not in the list anymore is
+// forgotten.
+func (s *Service) SetPeers(addrs ...mino.Address) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.peers = s.peers[:0]
+	known := make(map[string]struct{})
+
+	for _, addr := range addrs {
+		if s.me != nil && addr.Equal(s.me) {
+			continue
+		}
+
+		key := addr.String()
+		known[key] = struct{}{}
+
+		s.peers = append(s.peers, addr)
+
+		_, found := s.status[key]
+		if !found {
+			s.status[key] = &mino.Liveness{Address: addr}
+		}
+	}
+
+	for key := range s.status {
+		_, found := known[key]
+		if !found {
+			delete(s.status, key)
+		}
+	}
+}
+
+// GetPeerStatus implements mino.PeerStatus. It returns the liveness of the
+// peer, or false if the peer is not in the list.
+func (s *Service) GetPeerStatus(addr mino.Address) (mino.Liveness, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	l, found := s.status[addr.String()]
+	if !found {
+		return mino.Liveness{}, false
+	}
+
+	return *l, true
+}
+
+// GetPeers returns the liveness of the peers in the order of the list.
+func (s *Service) GetPeers() []mino.Liveness {
+	s.Lock()
+	defer s.Unlock()
+
+	peers := make([]mino.Liveness, len(s.peers))
+	for i, addr := range s.peers {
+		peers[i] = *s.status[addr.String()]
+	}
+
+	return peers
+}
+
+// Ping performs a round of heartbeats and updates the status of the peers. It
+// returns an error if the round cannot be performed at all.
+func (s *Service) Ping(ctx context.Context) error {
+	s.Lock()
+	s.seq++
+	seq := s.seq
+	peers := append([]mino.Address{}, s.peers...)
+	s.Unlock()
+
+	if len(peers) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	resps, err := s.rpc.Call(ctx, NewPing(seq), mino.NewAddresses(peers...))
+	if err != nil {
+		s.miss(peers, nil)
+
+		return xerrors.Errorf("failed to call: %v", err)
+	}
+
+	answered := s.collect(ctx, resps, seq, start)
+
+	s.miss(peers, answered)
+
+	return nil
+}
+
+// collect updates the status of the peers that answer the ping of the round,
+// until every peer has answered or the context is done. It returns the peers
+// that have answered.
+func (s *Service) collect(ctx context.Context, resps <-chan mino.Response,
+	seq uint64, start time.Time) map[string]struct{} {
+
+	answered := make(map[string]struct{})
+
+	for {
+		var resp mino.Response
+		var more bool
+
+		select {
+		case <-ctx.Done():
+		case resp, more = <-resps:
+		}
+
+		if !more {
+			return answered
+		}
+
+		msg, err := resp.GetMessageOrError()
+		if err != nil {
+			s.logger.Debug().Err(err).Stringer("peer", resp.GetFrom()).Msg("missed heartbeat")
+			continue
+		}
+
+		ping, ok := msg.(Ping)
+		if !ok || ping.GetSeq() != seq {
+			continue
+		}
+
+		now := time.Now()
+		key := resp.GetFrom().String()
+
+		s.Lock()
+		l, found := s.status[key]
+		if found {
+			l.RTT = now.Sub(start)
+			l.LastSeen = now
+			l.Missed = 0
+
+			answered[key] = struct{}{}
+		}
+		s.Unlock()
+	}
+}
+
+// miss increases the number of missed heartbeats of the peers that have not
+// answered.
+func (s *Service) miss(peers []mino.Address, answered map[string]struct{}) {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, addr := range peers {
+		key := addr.String()
+
+		_, found := answered[key]
+		if found {
+			continue
+		}
+
+		l, found := s.status[key]
+		if found {
+			l.Missed++
+		}
+	}
+}
+
+// Start performs the rounds in the background. The first one is done
+// immediately. When a source is set, the peers are refreshed before each round.
+func (s *Service) Start() {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closing != nil {
+		return
+	}
+
+	s.closing = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go s.run(s.closing, s.done)
+}
+
+// Stop stops the rounds and waits for the current one to complete.
+func (s *Service) Stop() {
+	s.Lock()
+	closing, done := s.closing, s.done
+	s.closing = nil
+	s.Unlock()
+
+	if closing == nil {
+		return
+	}
+
+	close(closing)
+	<-done
+}
+
+func (s *Service) run(closing, done chan struct{}) {
+	defer close(done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-closing:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		if s.source != nil {
+			addrs, err := s.source()
+			if err != nil {
+				s.logger.Warn().Err(err).Msg("failed to read the peers")
+			} else {
+				s.SetPeers(addrs...)
+			}
+		}
+
+		err := s.Ping(ctx)
+		if err != nil {
+			s.logger.Warn().Err(err).Msg("heartbeat round failed")
+		}
+
+		select {
+		case <-closing:
+			return
+		case <-time.After(s.interval):
+		}
+	}
+}
+
+// handler replies to the pings.
+//
+// - implements mino.Handler
+type handler struct {
+	mino.UnsupportedHandler
+}
+
+// Process implements mino.Handler. It returns the ping so that the sender can
+// measure the round-trip time.
+func (handler) Process(req mino.Request) (serde.Message, error) {
+	ping, ok := req.Message.(Ping)
+	if !ok {
+		return nil, xerrors.Errorf("unexpected message of type '%T'", req.Message)
+	}
+
+	return ping, nil
+}
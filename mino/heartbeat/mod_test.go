@@ -0,0 +1,220 @@
+package heartbeat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+)
+
+func init() {
+	RegisterPingFormat(fake.GoodFormat, fake.Format{Msg: NewPing(1)})
+	RegisterPingFormat(fake.BadFormat, fake.NewBadFormat())
+}
+
+func TestPing_Serialize(t *testing.T) {
+	ping := NewPing(1)
+	require.Equal(t, uint64(1), ping.GetSeq())
+
+	data, err := ping.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = ping.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("encoding failed"))
+}
+
+func TestPingFactory_Deserialize(t *testing.T) {
+	fac := PingFactory{}
+
+	msg, err := fac.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.Equal(t, NewPing(1), msg)
+
+	_, err = fac.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("decoding failed"))
+}
+
+func TestService_New(t *testing.T) {
+	srvc, err := NewService(fake.Mino{}, WithInterval(time.Second), WithTimeout(time.Millisecond))
+	require.NoError(t, err)
+	require.Equal(t, time.Second, srvc.interval)
+	require.Equal(t, time.Millisecond, srvc.timeout)
+
+	_, err = NewService(badMino{})
+	require.EqualError(t, err, fake.Err("failed to create rpc"))
+}
+
+func TestService_SetPeers(t *testing.T) {
+	srvc, err := NewService(fake.Mino{})
+	require.NoError(t, err)
+
+	// The address of the node is ignored.
+	srvc.SetPeers(fake.Address{}, fake.NewAddress(1), fake.NewAddress(2))
+	require.Len(t, srvc.GetPeers(), 2)
+
+	srvc.status[fake.NewAddress(1).String()].Missed = 5
+
+	srvc.SetPeers(fake.NewAddress(1), fake.NewAddress(2))
+
+	peers := srvc.GetPeers()
+	require.Len(t, peers, 2)
+	require.Equal(t, 5, peers[0].Missed)
+	require.Equal(t, fake.NewAddress(2), peers[1].Address)
+
+	_, found := srvc.GetPeerStatus(fake.NewAddress(0))
+	require.False(t, found)
+}
+
+func TestService_Ping(t *testing.T) {
+	srvc, err := NewService(fake.Mino{}, WithTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+
+	rpc := fake.NewPlayerRPC(func(from mino.Address, req serde.Message) fake.Reply {
+		switch from.String() {
+		case fake.NewAddress(1).String():
+			return fake.Reply{Message: req, Latency: 5 * time.Millisecond}
+		case fake.NewAddress(2).String():
+			return fake.Reply{Err: fake.GetError()}
+		case fake.NewAddress(3).String():
+			return fake.Reply{Message: NewPing(0)}
+		default:
+			return fake.Reply{Drop: true}
+		}
+	})
+
+	srvc.rpc = rpc
+
+	// Nothing to ping.
+	require.NoError(t, srvc.Ping(context.Background()))
+	require.Equal(t, 0, rpc.Calls.Len())
+
+	srvc.SetPeers(fake.NewAddress(1), fake.NewAddress(2), fake.NewAddress(3), fake.NewAddress(4))
+
+	for i := 0; i < mino.MaxMissedHeartbeats; i++ {
+		require.NoError(t, srvc.Ping(context.Background()))
+	}
+
+	l, found := srvc.GetPeerStatus(fake.NewAddress(1))
+	require.True(t, found)
+	require.True(t, l.IsResponsive())
+	require.Equal(t, 0, l.Missed)
+	require.GreaterOrEqual(t, int64(l.RTT), int64(5*time.Millisecond))
+	require.False(t, l.LastSeen.IsZero())
+
+	for i := 2; i <= 4; i++ {
+		l, found = srvc.GetPeerStatus(fake.NewAddress(i))
+		require.True(t, found)
+		require.False(t, l.IsResponsive())
+		require.True(t, l.LastSeen.IsZero())
+	}
+
+	srvc.rpc = fake.NewBadPlayerRPC()
+	err = srvc.Ping(context.Background())
+	require.EqualError(t, err, fake.Err("failed to call"))
+
+	l, _ = srvc.GetPeerStatus(fake.NewAddress(1))
+	require.Equal(t, 1, l.Missed)
+
+	// A channel of responses that is never closed ends with the timeout.
+	srvc.rpc = fake.NewRPC()
+	require.NoError(t, srvc.Ping(context.Background()))
+
+	l, _ = srvc.GetPeerStatus(fake.NewAddress(1))
+	require.Equal(t, 2, l.Missed)
+}
+
+func TestService_Start(t *testing.T) {
+	srvc, err := NewService(fake.Mino{}, WithInterval(time.Millisecond))
+	require.NoError(t, err)
+
+	srvc.rpc = fake.NewPlayerRPC(func(from mino.Address, req serde.Message) fake.Reply {
+		return fake.Reply{Message: req}
+	})
+
+	srvc.SetPeers(fake.NewAddress(1))
+
+	srvc.Start()
+	// A second start is ignored.
+	srvc.Start()
+
+	require.Eventually(t, func() bool {
+		l, _ := srvc.GetPeerStatus(fake.NewAddress(1))
+		return !l.LastSeen.IsZero()
+	}, time.Second, time.Millisecond)
+
+	srvc.Stop()
+	srvc.Stop()
+}
+
+func TestService_BadRound_Start(t *testing.T) {
+	srvc, err := NewService(fake.Mino{}, WithInterval(time.Millisecond))
+	require.NoError(t, err)
+
+	srvc.rpc = fake.NewBadPlayerRPC()
+	srvc.SetPeers(fake.NewAddress(1))
+
+	srvc.Start()
+
+	// The service keeps running when a round fails.
+	require.Eventually(t, func() bool {
+		l, _ := srvc.GetPeerStatus(fake.NewAddress(1))
+		return l.Missed > 2
+	}, time.Second, time.Millisecond)
+
+	srvc.Stop()
+}
+
+func TestService_Source_Start(t *testing.T) {
+	calls := 0
+	source := func() ([]mino.Address, error) {
+		calls++
+		if calls == 1 {
+			return nil, fake.GetError()
+		}
+
+		return []mino.Address{fake.NewAddress(1), fake.NewAddress(2)}, nil
+	}
+
+	srvc, err := NewService(fake.Mino{}, WithInterval(time.Millisecond), WithPeerSource(source))
+	require.NoError(t, err)
+
+	srvc.rpc = fake.NewPlayerRPC(func(from mino.Address, req serde.Message) fake.Reply {
+		return fake.Reply{Message: req}
+	})
+
+	srvc.Start()
+
+	require.Eventually(t, func() bool {
+		l, _ := srvc.GetPeerStatus(fake.NewAddress(2))
+		return !l.LastSeen.IsZero()
+	}, time.Second, time.Millisecond)
+
+	srvc.Stop()
+}
+
+func TestHandler_Process(t *testing.T) {
+	h := handler{}
+
+	resp, err := h.Process(mino.Request{Message: NewPing(2)})
+	require.NoError(t, err)
+	require.Equal(t, NewPing(2), resp)
+
+	_, err = h.Process(mino.Request{Message: fake.Message{}})
+	require.EqualError(t, err, "unexpected message of type 'fake.Message'")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type badMino struct {
+	fake.Mino
+}
+
+func (badMino) CreateRPC(string, mino.Handler, serde.Factory) (mino.RPC, error) {
+	return nil, fake.GetError()
+}
@@ -0,0 +1,124 @@
+package mino
+
+import (
+	"sort"
+	"sync"
+)
+
+// Traffic is the amount of traffic of an RPC.
+type Traffic struct {
+	// Calls is the number of calls sent.
+	Calls uint64
+	// Streams is the number of streams opened.
+	Streams uint64
+	// MessagesIn is the number of messages received.
+	MessagesIn uint64
+	// MessagesOut is the number of messages sent.
+	MessagesOut uint64
+	// BytesIn is the number of bytes received.
+	BytesIn uint64
+	// BytesOut is the number of bytes sent.
+	BytesOut uint64
+}
+
+// Metrics are the counters of the traffic of an overlay, per URI of the RPCs.
+// The traffic that doesn't belong to an RPC, like the exchange of the
+// certificates, is counted under the empty URI. They can be read while the
+// overlay is running.
+type Metrics struct {
+	sync.Mutex
+
+	uris map[string]*Traffic
+}
+
+// NewMetrics returns new counters set to zero.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		uris: make(map[string]*Traffic),
+	}
+}
+
+// AddCall increments the number of calls sent for the URI.
+func (m *Metrics) AddCall(uri string) {
+	m.update(uri, func(t *Traffic) { t.Calls++ })
+}
+
+// AddStream increments the number of streams opened for the URI.
+func (m *Metrics) AddStream(uri string) {
+	m.update(uri, func(t *Traffic) { t.Streams++ })
+}
+
+// AddReceived counts a message of the given size received for the URI.
+func (m *Metrics) AddReceived(uri string, size int) {
+	m.update(uri, func(t *Traffic) {
+		t.MessagesIn++
+		t.BytesIn += uint64(size)
+	})
+}
+
+// AddSent counts a message of the given size sent for the URI.
+func (m *Metrics) AddSent(uri string, size int) {
+	m.update(uri, func(t *Traffic) {
+		t.MessagesOut++
+		t.BytesOut += uint64(size)
+	})
+}
+
+// GetURIs returns the URIs with some traffic in alphabetical order.
+func (m *Metrics) GetURIs() []string {
+	m.Lock()
+	defer m.Unlock()
+
+	uris := make([]string, 0, len(m.uris))
+	for uri := range m.uris {
+		uris = append(uris, uri)
+	}
+
+	sort.Strings(uris)
+
+	return uris
+}
+
+// GetTraffic returns the traffic of the URI.
+func (m *Metrics) GetTraffic(uri string) Traffic {
+	m.Lock()
+	defer m.Unlock()
+
+	t, found := m.uris[uri]
+	if !found {
+		return Traffic{}
+	}
+
+	return *t
+}
+
+// GetTotal returns the traffic of the overlay, whatever the URI.
+func (m *Metrics) GetTotal() Traffic {
+	m.Lock()
+	defer m.Unlock()
+
+	total := Traffic{}
+	for _, t := range m.uris {
+		total.Calls += t.Calls
+		total.Streams += t.Streams
+		total.MessagesIn += t.MessagesIn
+		total.MessagesOut += t.MessagesOut
+		total.BytesIn += t.BytesIn
+		total.BytesOut += t.BytesOut
+	}
+
+	return total
+}
+
+func (m *Metrics) update(uri string, fn func(*Traffic)) {
+	m.Lock()
+	defer m.Unlock()
+
+	t, found := m.uris[uri]
+	if !found {
+		t = &Traffic{}
+		m.uris[uri] = t
+	}
+
+	fn(t)
+}
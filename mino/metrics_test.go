@@ -0,0 +1,38 @@
+package mino
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_Add(t *testing.T) {
+	m := NewMetrics()
+
+	m.AddCall("/a")
+	m.AddStream("/a")
+	m.AddSent("/a", 10)
+	m.AddSent("/a", 5)
+	m.AddReceived("/b", 20)
+	m.AddReceived("", 3)
+
+	require.Equal(t, []string{"", "/a", "/b"}, m.GetURIs())
+
+	expected := Traffic{Calls: 1, Streams: 1, MessagesOut: 2, BytesOut: 15}
+	require.Equal(t, expected, m.GetTraffic("/a"))
+
+	expected = Traffic{MessagesIn: 1, BytesIn: 20}
+	require.Equal(t, expected, m.GetTraffic("/b"))
+
+	require.Equal(t, Traffic{}, m.GetTraffic("/unknown"))
+
+	expected = Traffic{
+		Calls:       1,
+		Streams:     1,
+		MessagesIn:  2,
+		MessagesOut: 2,
+		BytesIn:     23,
+		BytesOut:    15,
+	}
+	require.Equal(t, expected, m.GetTotal())
+}
@@ -103,6 +103,17 @@ func (c RPC) Call(ctx context.Context,
 				return
 			}
 
+			err = mino.CheckScope(rpc.h, c.addr)
+			if err != nil {
+				resp := mino.NewResponseWithError(
+					from,
+					xerrors.Errorf("request refused: %v", err),
+				)
+
+				out <- resp
+				return
+			}
+
 			resp, err := rpc.h.Process(req)
 			if err != nil {
 				resp := mino.NewResponseWithError(
@@ -170,7 +181,9 @@ func (c RPC) Stream(ctx context.Context, memship mino.Players) (mino.Sender, min
 				context: c.context,
 			}
 
-			err := peer.rpcs[c.path].h.Stream(s, r)
+			h := peer.rpcs[c.path].h
+
+			err := h.Stream(s, mino.ScopeReceiver(h, r))
 			if err != nil {
 				errs <- xerrors.Errorf("couldn't process: %v", err)
 			}
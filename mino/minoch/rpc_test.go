@@ -34,6 +34,34 @@ func TestRPC_Call(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestRPC_Scope_Call(t *testing.T) {
+	manager := NewManager()
+
+	mA := MustCreate(manager, "A")
+	rpcA := mino.MustCreateRPC(mA, "test", fakeHandler{}, fake.MessageFactory{})
+
+	mB := MustCreate(manager, "B")
+	scope := mino.PlayersScope(mino.NewAddresses(mB.GetAddress()))
+	h := mino.NewScopedHandler(fakeHandler{}, scope)
+	rpcB := mino.MustCreateRPC(mB, "test", h, fake.MessageFactory{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resps, err := rpcB.Call(ctx, fake.Message{}, mino.NewAddresses(mB.GetAddress()))
+	require.NoError(t, err)
+
+	err = testWait(t, resps, nil)
+	require.NoError(t, err)
+
+	resps, err = rpcA.Call(ctx, fake.Message{}, mino.NewAddresses(mB.GetAddress()))
+	require.NoError(t, err)
+
+	err = testWait(t, resps, nil)
+	require.EqualError(t, err,
+		"request refused: address A is out of the scope of the handler")
+}
+
 func TestRPC_Filter_Call(t *testing.T) {
 	manager := NewManager()
 
@@ -0,0 +1,87 @@
+// This file contains the compression of the payloads of the calls, which is
+// negotiated with the headers so that a participant only receives compressed
+// payloads when it knows how to decompress them.
+//
+// A participant announces the algorithms that it decompresses in the headers of
+// its calls and of its answers. The callee compresses its answer when the
+// caller announced the algorithm, and the caller compresses its requests to a
+// participant once an answer of that participant announced it. A participant
+// that doesn't know the header never announces anything, therefore it always
+// receives plain payloads.
+
+package minogrpc
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"google.golang.org/grpc/metadata"
+)
+
+// headerPayloadCompressionKey is the key of the header that announces the
+// algorithms of the payloads that a participant decompresses.
+const headerPayloadCompressionKey = "payloadcompression"
+
+// MaxPayloadSize is the maximum number of bytes of a payload of the overlay,
+// once decompressed, so that a small compressed payload cannot force a huge
+// allocation.
+const MaxPayloadSize = 64 * 1024 * 1024
+
+// announceCompression returns the value of the header that announces the
+// algorithms that the participant decompresses.
+func announceCompression() string {
+	algos := serde.GetAlgorithms()
+
+	values := make([]string, len(algos))
+	for i, algo := range algos {
+		values[i] = strconv.Itoa(int(algo))
+	}
+
+	return strings.Join(values, ",")
+}
+
+// acceptsCompression returns true if the headers announce the algorithm.
+func acceptsCompression(md metadata.MD, algo serde.Algorithm) bool {
+	expected := strconv.Itoa(int(algo))
+
+	for _, value := range md.Get(headerPayloadCompressionKey) {
+		for _, field := range strings.Split(value, ",") {
+			if field == expected {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// compressionTable remembers the participants that announced the algorithm of
+// the compressor of the overlay in their answers.
+type compressionTable struct {
+	sync.Mutex
+	peers map[string]bool
+}
+
+// accepts returns true if the participant announced the algorithm in its last
+// answer.
+func (t *compressionTable) accepts(addr mino.Address) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.peers[addr.String()]
+}
+
+// learn updates the table with the headers of an answer of the participant.
+func (t *compressionTable) learn(addr mino.Address, md metadata.MD, algo serde.Algorithm) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.peers == nil {
+		t.peers = make(map[string]bool)
+	}
+
+	t.peers[addr.String()] = acceptsCompression(md, algo)
+}
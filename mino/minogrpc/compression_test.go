@@ -0,0 +1,38 @@
+package minogrpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/mino/minogrpc/session"
+	"go.dedis.ch/dela/serde"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAnnounceCompression(t *testing.T) {
+	md := metadata.Pairs(headerPayloadCompressionKey, announceCompression())
+
+	require.True(t, acceptsCompression(md, serde.AlgorithmFlate))
+	require.False(t, acceptsCompression(md, 0xfe))
+	require.False(t, acceptsCompression(metadata.MD{}, serde.AlgorithmFlate))
+
+	md = metadata.Pairs(headerPayloadCompressionKey, "2,12")
+	require.True(t, acceptsCompression(md, 12))
+	require.False(t, acceptsCompression(md, 1))
+}
+
+func TestCompressionTable_Learn(t *testing.T) {
+	table := compressionTable{}
+
+	addr := session.NewAddress("A")
+	require.False(t, table.accepts(addr))
+
+	table.learn(addr, metadata.Pairs(headerPayloadCompressionKey, "1"), serde.AlgorithmFlate)
+	require.True(t, table.accepts(addr))
+	require.False(t, table.accepts(session.NewAddress("B")))
+
+	// A participant that doesn't announce the algorithm anymore, e.g. after a
+	// downgrade, receives the plain payloads again.
+	table.learn(addr, metadata.MD{}, serde.AlgorithmFlate)
+	require.False(t, table.accepts(addr))
+}
@@ -11,8 +11,14 @@ import (
 	"fmt"
 
 	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/pagination"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/minogrpc"
+	"go.dedis.ch/dela/mino/minogrpc/scores"
+	"go.dedis.ch/dela/mino/minogrpc/session"
+	"go.dedis.ch/dela/mino/minogrpc/tokens"
+	"go.dedis.ch/dela/mino/prometheus"
+	"go.dedis.ch/dela/mino/proxy"
 	"golang.org/x/xerrors"
 )
 
@@ -21,21 +27,42 @@ import (
 // - implements node.ActionTemplate
 type certAction struct{}
 
-// Execute implements node.ActionTemplate. It prints the list of certificates
-// known by the server with the address associated and the expiration date.
+// Execute implements node.ActionTemplate. It prints a page of the list of
+// certificates known by the server with the address associated and the
+// expiration date, sorted by address.
 func (a certAction) Execute(req node.Context) error {
+	request, err := pagination.NewRequest(req.Flags.String("after"),
+		req.Flags.Int("limit"), req.Flags.String("order"))
+	if err != nil {
+		return xerrors.Errorf("invalid page: %v", err)
+	}
+
 	var m minogrpc.Joinable
 
-	err := req.Injector.Resolve(&m)
+	err = req.Injector.Resolve(&m)
 	if err != nil {
 		return xerrors.Errorf("couldn't resolve: %v", err)
 	}
 
+	certs := make(map[string]*tls.Certificate)
+	keys := []string{}
+
 	m.GetCertificateStore().Range(func(addr mino.Address, cert *tls.Certificate) bool {
-		fmt.Fprintf(req.Out, "Address: %v Certificate: %v\n", addr, cert.Leaf.NotAfter)
+		certs[addr.String()] = cert
+		keys = append(keys, addr.String())
 		return true
 	})
 
+	keys, page := request.Keys(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(req.Out, "Address: %s Certificate: %v\n", key, certs[key].Leaf.NotAfter)
+	}
+
+	if page.Next != "" {
+		fmt.Fprintf(req.Out, "Next: --after %s\n", page.Next)
+	}
+
 	return nil
 }
 
@@ -45,18 +72,26 @@ func (a certAction) Execute(req node.Context) error {
 // - implements node.ActionTemplate
 type tokenAction struct{}
 
-// Execute implements node.ActionTemplate. It generates a token that will be
-// valid for the amount of time given in the request.
+// Execute implements node.ActionTemplate. It generates a token for the scope
+// that will be valid for the amount of time given in the request.
 func (a tokenAction) Execute(req node.Context) error {
 	exp := req.Flags.Duration("expiration")
 
+	scope, err := tokens.ParseScope(req.Flags.String("scope"))
+	if err != nil {
+		return xerrors.Errorf("invalid scope: %v", err)
+	}
+
 	var m minogrpc.Joinable
-	err := req.Injector.Resolve(&m)
+	err = req.Injector.Resolve(&m)
 	if err != nil {
 		return xerrors.Errorf("couldn't resolve: %v", err)
 	}
 
-	token := m.GenerateToken(exp)
+	token, err := m.GenerateToken(exp, scope)
+	if err != nil {
+		return xerrors.Errorf("couldn't generate token: %v", err)
+	}
 
 	digest, err := m.GetCertificateStore().Hash(m.GetCertificate())
 	if err != nil {
@@ -69,6 +104,28 @@ func (a tokenAction) Execute(req node.Context) error {
 	return nil
 }
 
+// RevokeAction is an action to revoke a token before its expiration.
+//
+// - implements node.ActionTemplate
+type revokeAction struct{}
+
+// Execute implements node.ActionTemplate. It revokes the token of the request,
+// so that it is refused by the following join requests.
+func (a revokeAction) Execute(req node.Context) error {
+	var m minogrpc.Joinable
+	err := req.Injector.Resolve(&m)
+	if err != nil {
+		return xerrors.Errorf("couldn't resolve: %v", err)
+	}
+
+	err = m.RevokeToken(req.Flags.String("token"))
+	if err != nil {
+		return xerrors.Errorf("couldn't revoke token: %v", err)
+	}
+
+	return nil
+}
+
 // JoinAction is an action to join a network of participants by providing a
 // valid token and the certificate hash.
 //
@@ -100,3 +157,89 @@ func (a joinAction) Execute(req node.Context) error {
 
 	return nil
 }
+
+// ScoresAction is an action to list the peers that have a penalty or a ban.
+//
+// - implements node.ActionTemplate
+type scoresAction struct{}
+
+// Execute implements node.ActionTemplate. It prints the score of the peers
+// known by the scoreboard, and the expiration of the ban if any.
+func (a scoresAction) Execute(req node.Context) error {
+	var m minogrpc.Joinable
+	err := req.Injector.Resolve(&m)
+	if err != nil {
+		return xerrors.Errorf("couldn't resolve: %v", err)
+	}
+
+	m.GetScores().Range(func(peer scores.Peer) bool {
+		fmt.Fprintf(req.Out, "Address: %v Score: %.2f Offenses: %d",
+			peer.Address, peer.Score, peer.Offenses)
+
+		if !peer.BannedUntil.IsZero() {
+			fmt.Fprintf(req.Out, " Banned until: %v", peer.BannedUntil)
+		}
+
+		fmt.Fprintln(req.Out)
+
+		return true
+	})
+
+	return nil
+}
+
+// UnbanAction is an action to lift the ban of a peer.
+//
+// - implements node.ActionTemplate
+type unbanAction struct{}
+
+// Execute implements node.ActionTemplate. It removes the peer of the address
+// in the request from the scoreboard.
+func (a unbanAction) Execute(req node.Context) error {
+	addr := session.NewAddress(req.Flags.String("address"))
+
+	var m minogrpc.Joinable
+	err := req.Injector.Resolve(&m)
+	if err != nil {
+		return xerrors.Errorf("couldn't resolve: %v", err)
+	}
+
+	m.GetScores().Unban(addr)
+
+	fmt.Fprintf(req.Out, "Peer %v has been unbanned\n", addr)
+
+	return nil
+}
+
+// MetricsAction is an action to register the collector of the traffic metrics
+// on the proxy.
+//
+// - implements node.ActionTemplate
+type metricsAction struct{}
+
+// Execute implements node.ActionTemplate. It registers the endpoint that
+// exposes the traffic metrics of the overlay to Prometheus.
+func (a metricsAction) Execute(req node.Context) error {
+	var m minogrpc.Joinable
+	err := req.Injector.Resolve(&m)
+	if err != nil {
+		return xerrors.Errorf("couldn't resolve: %v", err)
+	}
+
+	var p proxy.Proxy
+	err = req.Injector.Resolve(&p)
+	if err != nil {
+		return xerrors.Errorf("couldn't resolve: %v", err)
+	}
+
+	path := req.Flags.String("path")
+
+	err = p.RegisterHandler(path, prometheus.NewCollector(m.Metrics()).ServeHTTP)
+	if err != nil {
+		return xerrors.Errorf("failed to register handler: %v", err)
+	}
+
+	fmt.Fprintf(req.Out, "metrics endpoint registered on %s\n", path)
+
+	return nil
+}
@@ -2,9 +2,12 @@ package controller
 
 import (
 	"bytes"
+	"crypto/ed25519"
 	"crypto/tls"
 	"encoding/base64"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -12,8 +15,13 @@ import (
 	"go.dedis.ch/dela/cli"
 	"go.dedis.ch/dela/cli/node"
 	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/minogrpc"
 	"go.dedis.ch/dela/mino/minogrpc/certs"
+	"go.dedis.ch/dela/mino/minogrpc/scores"
+	"go.dedis.ch/dela/mino/minogrpc/session"
+	"go.dedis.ch/dela/mino/minogrpc/tokens"
+	"go.dedis.ch/dela/mino/proxy"
 )
 
 func TestCertAction_Execute(t *testing.T) {
@@ -22,6 +30,7 @@ func TestCertAction_Execute(t *testing.T) {
 	out := new(bytes.Buffer)
 	req := node.Context{
 		Out:      out,
+		Flags:    node.FlagSet{"limit": 2},
 		Injector: node.NewInjector(),
 	}
 
@@ -37,6 +46,28 @@ func TestCertAction_Execute(t *testing.T) {
 	expected := fmt.Sprintf("Address: fake.Address[0] Certificate: %v\n", cert.Leaf.NotAfter)
 	require.Equal(t, expected, out.String())
 
+	store.Store(fake.NewAddress(2), cert)
+	store.Store(fake.NewAddress(1), cert)
+
+	out.Reset()
+	err = action.Execute(req)
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("Address: fake.Address[0] Certificate: %v\n"+
+		"Address: fake.Address[1] Certificate: %v\n"+
+		"Next: --after fake.Address[1]\n", cert.Leaf.NotAfter, cert.Leaf.NotAfter), out.String())
+
+	req.Flags = node.FlagSet{"after": "fake.Address[1]", "order": "desc"}
+
+	out.Reset()
+	err = action.Execute(req)
+	require.NoError(t, err)
+	require.Equal(t, expected, out.String())
+
+	req.Flags = node.FlagSet{"limit": 1000}
+	err = action.Execute(req)
+	require.EqualError(t, err, "invalid page: limit must be between 1 and 100")
+
+	req.Flags = node.FlagSet{}
 	req.Injector = node.NewInjector()
 	err = action.Execute(req)
 	require.EqualError(t, err,
@@ -48,6 +79,7 @@ func TestTokenAction_Execute(t *testing.T) {
 
 	flags := make(node.FlagSet)
 	flags["expiration"] = time.Millisecond
+	flags["scope"] = "observer"
 
 	out := new(bytes.Buffer)
 	req := node.Context{
@@ -64,15 +96,52 @@ func TestTokenAction_Execute(t *testing.T) {
 	hash, err := store.Hash(cert)
 	require.NoError(t, err)
 
-	req.Injector.Inject(fakeJoinable{certs: store})
+	token := makeToken(t)
+
+	req.Injector.Inject(fakeJoinable{certs: store, token: token})
 
 	err = action.Execute(req)
 	require.NoError(t, err)
 
-	expected := fmt.Sprintf("--token abc --cert-hash %s\n",
-		base64.StdEncoding.EncodeToString(hash))
+	expected := fmt.Sprintf("--token %s --cert-hash %s\n",
+		token, base64.StdEncoding.EncodeToString(hash))
 	require.Equal(t, expected, out.String())
 
+	req.Injector.Inject(fakeJoinable{err: fake.GetError()})
+	err = action.Execute(req)
+	require.EqualError(t, err, fake.Err("couldn't generate token"))
+
+	flags["scope"] = "admin"
+	err = action.Execute(req)
+	require.EqualError(t, err, "invalid scope: unknown scope 'admin'")
+
+	flags["scope"] = "validator"
+	req.Injector = node.NewInjector()
+	err = action.Execute(req)
+	require.EqualError(t, err,
+		"couldn't resolve: couldn't find dependency for 'minogrpc.Joinable'")
+}
+
+func TestRevokeAction_Execute(t *testing.T) {
+	action := revokeAction{}
+
+	flags := make(node.FlagSet)
+	flags["token"] = "abc"
+
+	req := node.Context{
+		Flags:    flags,
+		Injector: node.NewInjector(),
+	}
+
+	req.Injector.Inject(fakeJoinable{})
+
+	err := action.Execute(req)
+	require.NoError(t, err)
+
+	req.Injector.Inject(fakeJoinable{err: fake.GetError()})
+	err = action.Execute(req)
+	require.EqualError(t, err, fake.Err("couldn't revoke token"))
+
 	req.Injector = node.NewInjector()
 	err = action.Execute(req)
 	require.EqualError(t, err,
@@ -111,13 +180,123 @@ func TestJoinAction_Execute(t *testing.T) {
 		"couldn't resolve: couldn't find dependency for 'minogrpc.Joinable'")
 }
 
+func TestScoresAction_Execute(t *testing.T) {
+	action := scoresAction{}
+
+	out := new(bytes.Buffer)
+	req := node.Context{
+		Out:      out,
+		Injector: node.NewInjector(),
+	}
+
+	board := scores.NewInMemoryBoard(scores.WithThreshold(80))
+	board.Report(session.NewAddress("A"), scores.MalformedPacket)
+
+	req.Injector.Inject(fakeJoinable{scores: board})
+
+	err := action.Execute(req)
+	require.NoError(t, err)
+	require.Regexp(t, "^Address: A Score: 90.00 Offenses: 1\n$", out.String())
+
+	board.Report(session.NewAddress("A"), scores.ProtocolViolation)
+
+	out.Reset()
+	err = action.Execute(req)
+	require.NoError(t, err)
+	require.Regexp(t, "^Address: A Score: 100.00 Offenses: 2 Banned until: .+\n$", out.String())
+
+	req.Injector = node.NewInjector()
+	err = action.Execute(req)
+	require.EqualError(t, err,
+		"couldn't resolve: couldn't find dependency for 'minogrpc.Joinable'")
+}
+
+func TestUnbanAction_Execute(t *testing.T) {
+	action := unbanAction{}
+
+	flags := make(node.FlagSet)
+	flags["address"] = "A"
+
+	out := new(bytes.Buffer)
+	req := node.Context{
+		Out:      out,
+		Flags:    flags,
+		Injector: node.NewInjector(),
+	}
+
+	board := scores.NewInMemoryBoard(scores.WithThreshold(scores.MaxScore))
+	board.Report(session.NewAddress("A"), scores.MalformedPacket)
+
+	req.Injector.Inject(fakeJoinable{scores: board})
+
+	err := action.Execute(req)
+	require.NoError(t, err)
+	require.Equal(t, "Peer A has been unbanned\n", out.String())
+	require.False(t, board.IsBanned(session.NewAddress("A")))
+
+	req.Injector = node.NewInjector()
+	err = action.Execute(req)
+	require.EqualError(t, err,
+		"couldn't resolve: couldn't find dependency for 'minogrpc.Joinable'")
+}
+
+func TestMetricsAction_Execute(t *testing.T) {
+	action := metricsAction{}
+
+	flags := make(node.FlagSet)
+	flags["path"] = "/metrics"
+
+	out := new(bytes.Buffer)
+	req := node.Context{
+		Out:      out,
+		Flags:    flags,
+		Injector: node.NewInjector(),
+	}
+
+	metrics := mino.NewMetrics()
+	metrics.AddCall("test")
+
+	px := &fakeProxy{}
+
+	req.Injector.Inject(fakeJoinable{metrics: metrics})
+	req.Injector.Inject(px)
+
+	err := action.Execute(req)
+	require.NoError(t, err)
+	require.Equal(t, "metrics endpoint registered on /metrics\n", out.String())
+	require.Equal(t, "/metrics", px.path)
+
+	rec := httptest.NewRecorder()
+	px.handler(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Contains(t, rec.Body.String(), "dela_mino_calls_total{uri=\"test\"} 1\n")
+
+	px.err = fake.GetError()
+	err = action.Execute(req)
+	require.EqualError(t, err, fake.Err("failed to register handler"))
+
+	req.Injector = node.NewInjector()
+	req.Injector.Inject(fakeJoinable{})
+
+	err = action.Execute(req)
+	require.EqualError(t, err,
+		"couldn't resolve: couldn't find dependency for 'proxy.Proxy'")
+
+	req.Injector = node.NewInjector()
+	err = action.Execute(req)
+	require.EqualError(t, err,
+		"couldn't resolve: couldn't find dependency for 'minogrpc.Joinable'")
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
 type fakeJoinable struct {
 	minogrpc.Joinable
-	certs certs.Storage
-	err   error
+	certs   certs.Storage
+	scores  scores.Board
+	metrics *mino.Metrics
+	token   tokens.Token
+	err     error
 }
 
 func (j fakeJoinable) GetCertificate() *tls.Certificate {
@@ -133,20 +312,47 @@ func (j fakeJoinable) GetCertificateStore() certs.Storage {
 	return j.certs
 }
 
-func (j fakeJoinable) GenerateToken(time.Duration) string {
-	return "abc"
+func (j fakeJoinable) GetScores() scores.Board {
+	return j.scores
+}
+
+func (j fakeJoinable) Metrics() *mino.Metrics {
+	return j.metrics
+}
+
+func (j fakeJoinable) GenerateToken(time.Duration, tokens.Scope) (tokens.Token, error) {
+	return j.token, j.err
+}
+
+func (j fakeJoinable) RevokeToken(string) error {
+	return j.err
 }
 
 func (j fakeJoinable) Join(string, string, []byte) error {
 	return j.err
 }
 
+func makeToken(t *testing.T) tokens.Token {
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	token, err := tokens.NewSignedHolder(key).Generate(time.Minute, tokens.ScopeObserver)
+	require.NoError(t, err)
+
+	return token
+}
+
 type fakeContext struct {
 	cli.Flags
 	duration time.Duration
 	str      string
 	path     string
 	num      int
+	flag     bool
+}
+
+func (ctx fakeContext) Bool(string) bool {
+	return ctx.flag
 }
 
 func (ctx fakeContext) Duration(string) time.Duration {
@@ -164,3 +370,18 @@ func (ctx fakeContext) Path(string) string {
 func (ctx fakeContext) Int(string) int {
 	return ctx.num
 }
+
+type fakeProxy struct {
+	proxy.Proxy
+
+	path    string
+	handler func(http.ResponseWriter, *http.Request)
+	err     error
+}
+
+func (p *fakeProxy) RegisterHandler(path string, h func(http.ResponseWriter, *http.Request)) error {
+	p.path = path
+	p.handler = h
+
+	return p.err
+}
@@ -15,19 +15,27 @@ import (
 	"crypto/x509"
 	"io"
 	"math"
+	"net"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"go.dedis.ch/dela"
 	"go.dedis.ch/dela/cli"
 	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/memory"
+	"go.dedis.ch/dela/core/pagination"
 	"go.dedis.ch/dela/core/store/kv"
 	"go.dedis.ch/dela/crypto/loader"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/minogrpc"
 	"go.dedis.ch/dela/mino/minogrpc/certs"
 	"go.dedis.ch/dela/mino/minogrpc/session"
+	"go.dedis.ch/dela/mino/minogrpc/tokens"
+	"go.dedis.ch/dela/mino/router"
+	"go.dedis.ch/dela/mino/router/gossip"
 	"go.dedis.ch/dela/mino/router/tree"
+	"go.dedis.ch/dela/serde"
 	"golang.org/x/xerrors"
 )
 
@@ -58,6 +66,39 @@ func (m miniController) SetCommands(builder node.Builder) {
 			Usage: "set the port to listen on",
 			Value: 2000,
 		},
+		cli.StringFlag{
+			Name: "listen",
+			Usage: "set the address to listen on as host:port, which takes " +
+				"precedence over the port, e.g. 0.0.0.0:2000",
+		},
+		cli.StringFlag{
+			Name: "public",
+			Usage: "set the address as host:port announced to the other " +
+				"participants if it differs from the listening one, or a " +
+				"comma-separated list of addresses tried in order, or " +
+				"relay://public-addr/node-id to be reached through a relay",
+		},
+		cli.IntFlag{
+			Name: "fanout",
+			Usage: "route the streams with the gossip router that pushes the " +
+				"packets to that number of peers, instead of the tree router",
+		},
+		cli.IntFlag{
+			Name: "max-connections",
+			Usage: "set the maximum number of outgoing connections open at " +
+				"the same time, or 0 for no limit",
+		},
+		cli.IntFlag{
+			Name: "max-inflight",
+			Usage: "set the maximum number of packets that a stream sends " +
+				"to the relays at the same time, or 0 for no limit",
+			Value: minogrpc.DefaultInFlightLimit,
+		},
+		cli.BoolFlag{
+			Name: "compress-payloads",
+			Usage: "compress the payloads of the calls to the participants " +
+				"that announce that they decompress them",
+		},
 	)
 
 	cmd := builder.SetCommand("minogrpc")
@@ -65,6 +106,22 @@ func (m miniController) SetCommands(builder node.Builder) {
 
 	sub := cmd.SetSubCommand("certificates")
 	sub.SetDescription("list the certificates of the server")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name:  "after",
+			Usage: "the address of the last certificate of the previous page",
+		},
+		cli.IntFlag{
+			Name:  "limit",
+			Usage: "the maximum number of certificates",
+			Value: pagination.DefaultLimit,
+		},
+		cli.StringFlag{
+			Name:  "order",
+			Usage: "the order of the addresses, either 'asc' or 'desc'",
+			Value: "asc",
+		},
+	)
 	sub.SetAction(builder.MakeAction(certAction{}))
 
 	sub = cmd.SetSubCommand("token")
@@ -75,8 +132,26 @@ func (m miniController) SetCommands(builder node.Builder) {
 			Usage: "amount of time before expiration",
 			Value: 24 * time.Hour,
 		},
+		cli.StringFlag{
+			Name: "scope",
+			Usage: "scope of the node that joins, either 'validator' to be " +
+				"known by the whole network, or 'observer' to be known only by " +
+				"this node",
+			Value: string(tokens.ScopeValidator),
+		},
+	)
+	sub.SetAction(builder.MakeAction(node.Destructive(tokenAction{})))
+
+	sub = cmd.SetSubCommand("revoke")
+	sub.SetDescription("revoke a token before its expiration")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name:     "token",
+			Usage:    "token generated by this node",
+			Required: true,
+		},
 	)
-	sub.SetAction(builder.MakeAction(tokenAction{}))
+	sub.SetAction(builder.MakeAction(node.Destructive(revokeAction{})))
 
 	sub = cmd.SetSubCommand("join")
 	sub.SetDescription("join a network of participants")
@@ -87,8 +162,9 @@ func (m miniController) SetCommands(builder node.Builder) {
 			Required: true,
 		},
 		cli.StringFlag{
-			Name:     "address",
-			Usage:    "address of the node to join",
+			Name: "address",
+			Usage: "address of the node to join as host:port, or as " +
+				"srv:<name> to try the targets of the SRV records of the name",
 			Required: true,
 		},
 		cli.StringFlag{
@@ -97,24 +173,54 @@ func (m miniController) SetCommands(builder node.Builder) {
 			Required: true,
 		},
 	)
-	sub.SetAction(builder.MakeAction(joinAction{}))
+	sub.SetAction(builder.MakeAction(node.Destructive(joinAction{})))
+
+	sub = cmd.SetSubCommand("scores")
+	sub.SetDescription("list the peers with a penalty or a ban")
+	sub.SetAction(builder.MakeAction(scoresAction{}))
+
+	sub = cmd.SetSubCommand("unban")
+	sub.SetDescription("lift the ban of a peer and restore its score")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name:     "address",
+			Usage:    "address of the peer",
+			Required: true,
+		},
+	)
+	sub.SetAction(builder.MakeAction(node.Destructive(unbanAction{})))
+
+	sub = cmd.SetSubCommand("metrics")
+	sub.SetDescription("register the endpoint of the traffic metrics for " +
+		"Prometheus on the proxy, which must be started beforehand")
+	sub.SetFlags(
+		cli.StringFlag{
+			Name:  "path",
+			Usage: "the path of the endpoint",
+			Value: "/metrics",
+		},
+	)
+	sub.SetAction(builder.MakeAction(metricsAction{}))
 }
 
 // OnStart implements node.Initializer. It starts the minogrpc instance and
 // injects it in the dependency resolver.
 func (m miniController) OnStart(ctx cli.Flags, inj node.Injector) error {
 
-	port := ctx.Int("port")
-	if port < 0 || port > math.MaxUint16 {
-		return xerrors.Errorf("invalid port value %d", port)
+	addr, err := getListenAddress(ctx)
+	if err != nil {
+		return xerrors.Errorf("listen address: %v", err)
 	}
 
-	rter := tree.NewRouter(minogrpc.NewAddressFactory())
+	var rter router.Router = tree.NewRouter(minogrpc.NewAddressFactory())
 
-	addr := minogrpc.ParseAddress("127.0.0.1", uint16(port))
+	fanout := ctx.Int("fanout")
+	if fanout > 0 {
+		rter = gossip.NewRouter(minogrpc.NewAddressFactory(), gossip.WithFanout(fanout))
+	}
 
 	var db kv.DB
-	err := inj.Resolve(&db)
+	err = inj.Resolve(&db)
 	if err != nil {
 		return xerrors.Errorf("injector: %v", err)
 	}
@@ -129,6 +235,23 @@ func (m miniController) OnStart(ctx cli.Flags, inj node.Injector) error {
 	opts := []minogrpc.Option{
 		minogrpc.WithStorage(certs),
 		minogrpc.WithCertificateKey(key, key.Public()),
+		minogrpc.WithConnectionLimit(ctx.Int("max-connections")),
+		minogrpc.WithInFlightLimit(ctx.Int("max-inflight")),
+	}
+
+	public := ctx.String("public")
+	if public != "" {
+		opts = append(opts, minogrpc.WithPublicAddress(strings.Split(public, ",")...))
+	}
+
+	if ctx.Bool("compress-payloads") {
+		opts = append(opts, minogrpc.WithPayloadCompression(serde.FlateCompressor{}))
+	}
+
+	var acc *memory.Accountant
+	err = inj.Resolve(&acc)
+	if err == nil {
+		opts = append(opts, minogrpc.WithMemoryBudget(acc.GetBudget(memory.OverlayModule)))
 	}
 
 	o, err := minogrpc.NewMinogrpc(addr, rter, opts...)
@@ -166,6 +289,27 @@ func (m miniController) OnStop(inj node.Injector) error {
 	return nil
 }
 
+// getListenAddress returns the address to listen on, which is either the
+// listen flag, or the port on the loopback interface.
+func getListenAddress(flags cli.Flags) (net.Addr, error) {
+	listen := flags.String("listen")
+	if listen != "" {
+		addr, err := net.ResolveTCPAddr("tcp", listen)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to resolve: %v", err)
+		}
+
+		return addr, nil
+	}
+
+	port := flags.Int("port")
+	if port < 0 || port > math.MaxUint16 {
+		return nil, xerrors.Errorf("invalid port value %d", port)
+	}
+
+	return minogrpc.ParseAddress("127.0.0.1", uint16(port)), nil
+}
+
 func (m miniController) getKey(flags cli.Flags) (*ecdsa.PrivateKey, error) {
 	loader := loader.NewFileLoader(filepath.Join(flags.Path("config"), certKeyName))
 
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/cli"
 	"go.dedis.ch/dela/cli/node"
+	"go.dedis.ch/dela/core/memory"
 	"go.dedis.ch/dela/core/store/kv"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/mino/minogrpc"
@@ -21,7 +22,7 @@ func TestMiniController_Build(t *testing.T) {
 	call := &fake.Call{}
 	ctrl.SetCommands(fakeBuilder{call: call})
 
-	require.Equal(t, 17, call.Len())
+	require.Equal(t, 37, call.Len())
 }
 
 func TestMiniController_OnStart(t *testing.T) {
@@ -37,8 +38,9 @@ func TestMiniController_OnStart(t *testing.T) {
 
 	injector := node.NewInjector()
 	injector.Inject(db)
+	injector.Inject(memory.NewAccountant())
 
-	err = ctrl.OnStart(fakeContext{path: dir}, injector)
+	err = ctrl.OnStart(fakeContext{path: dir, flag: true}, injector)
 	require.NoError(t, err)
 
 	var m *minogrpc.Minogrpc
@@ -51,7 +53,62 @@ func TestMiniController_InvalidPort_OnStart(t *testing.T) {
 	ctrl := NewController()
 
 	err := ctrl.OnStart(fakeContext{num: 100000}, node.NewInjector())
-	require.EqualError(t, err, "invalid port value 100000")
+	require.EqualError(t, err, "listen address: invalid port value 100000")
+}
+
+func TestMiniController_ListenAddress_OnStart(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "minogrpc")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	ctrl := NewController()
+
+	injector := node.NewInjector()
+	injector.Inject(db)
+
+	// The fake context returns the same value for both the listen and the
+	// public flags.
+	err = ctrl.OnStart(fakeContext{path: dir, str: "127.0.0.1:2111"}, injector)
+	require.NoError(t, err)
+
+	var m *minogrpc.Minogrpc
+	err = injector.Resolve(&m)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1:2111", m.GetAddress().String())
+	require.NoError(t, m.GracefulStop())
+
+	err = ctrl.OnStart(fakeContext{str: "127.0.0.1:abc"}, node.NewInjector())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "listen address: failed to resolve: ")
+}
+
+func TestMiniController_Gossip_OnStart(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "minogrpc")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	db, err := kv.New(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+
+	ctrl := NewController()
+
+	injector := node.NewInjector()
+	injector.Inject(db)
+
+	// The fake context returns the same value for the port and the fanout, but
+	// the listen address takes precedence over the port.
+	err = ctrl.OnStart(fakeContext{path: dir, str: "127.0.0.1:2112", num: 3}, injector)
+	require.NoError(t, err)
+
+	var m *minogrpc.Minogrpc
+	err = injector.Resolve(&m)
+	require.NoError(t, err)
+	require.NoError(t, m.GracefulStop())
 }
 
 func TestMiniController_MissingDB_OnStart(t *testing.T) {
@@ -0,0 +1,83 @@
+// This file contains the instrumentation of the traffic of the overlay.
+
+package minogrpc
+
+import (
+	"context"
+
+	"go.dedis.ch/dela/mino"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+)
+
+const (
+	callMethod   = "/ptypes.Overlay/Call"
+	streamMethod = "/ptypes.Overlay/Stream"
+)
+
+type rpcTagKey struct{}
+
+// rpcTag is the information of an RPC that is needed to count its messages.
+type rpcTag struct {
+	uri    string
+	method string
+}
+
+// statsHandler is a handler of the statistics of gRPC that counts the traffic
+// of the overlay, both for the server and for the clients. The messages are
+// counted for the URI found in the headers of the RPCs, which means the packets
+// of a stream forwarded to the next hop are counted for the RPC of the stream.
+//
+// - implements stats.Handler
+type statsHandler struct {
+	metrics *mino.Metrics
+}
+
+// TagRPC implements stats.Handler. It reads the URI from the headers of the RPC
+// and attaches it to the context.
+func (h statsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		md, _ = metadata.FromIncomingContext(ctx)
+	}
+
+	tag := rpcTag{
+		uri:    getOrEmpty(md, headerURIKey),
+		method: info.FullMethodName,
+	}
+
+	return context.WithValue(ctx, rpcTagKey{}, tag)
+}
+
+// HandleRPC implements stats.Handler. It counts the calls and the streams
+// opened by this node, and the messages in both directions with their size on
+// the wire.
+func (h statsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	tag, _ := ctx.Value(rpcTagKey{}).(rpcTag)
+
+	switch st := s.(type) {
+	case *stats.Begin:
+		if !st.IsClient() {
+			return
+		}
+
+		switch tag.method {
+		case callMethod:
+			h.metrics.AddCall(tag.uri)
+		case streamMethod:
+			h.metrics.AddStream(tag.uri)
+		}
+	case *stats.InPayload:
+		h.metrics.AddReceived(tag.uri, st.WireLength)
+	case *stats.OutPayload:
+		h.metrics.AddSent(tag.uri, st.WireLength)
+	}
+}
+
+// TagConn implements stats.Handler. It returns the context as is.
+func (h statsHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn implements stats.Handler. It does nothing.
+func (h statsHandler) HandleConn(context.Context, stats.ConnStats) {}
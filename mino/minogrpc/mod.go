@@ -9,6 +9,7 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"net"
@@ -19,12 +20,17 @@ import (
 
 	otgrpc "github.com/opentracing-contrib/go-grpc"
 	opentracing "github.com/opentracing/opentracing-go"
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/memory"
 	"go.dedis.ch/dela/internal/tracing"
 	"go.dedis.ch/dela/internal/traffic"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/minogrpc/certs"
 	"go.dedis.ch/dela/mino/minogrpc/ptypes"
+	"go.dedis.ch/dela/mino/minogrpc/resolver"
+	"go.dedis.ch/dela/mino/minogrpc/scores"
 	"go.dedis.ch/dela/mino/minogrpc/session"
+	"go.dedis.ch/dela/mino/minogrpc/tokens"
 	"go.dedis.ch/dela/mino/router"
 	"go.dedis.ch/dela/serde"
 	"golang.org/x/xerrors"
@@ -33,6 +39,10 @@ import (
 	"google.golang.org/grpc/metadata"
 )
 
+// DefaultInFlightLimit is the default maximum number of packets that a stream
+// sends to the relays of a participant at the same time.
+const DefaultInFlightLimit = 1000
+
 var (
 	segmentMatch = regexp.MustCompile("^[a-zA-Z0-9]+$")
 	addressFac   = session.AddressFactory{}
@@ -63,9 +73,18 @@ type Joinable interface {
 	// known peer certificate.
 	GetCertificateStore() certs.Storage
 
+	// GetScores returns the scoreboard of the peers, which can be used to
+	// report offenses detected by the protocols.
+	GetScores() scores.Board
+
 	// GenerateToken returns a token that can be provided by a distant peer to
-	// mutually share certificates with this instance.
-	GenerateToken(expiration time.Duration) string
+	// mutually share certificates with this instance. The scope defines
+	// whether the certificate of the peer is shared with the whole network, or
+	// only with this instance.
+	GenerateToken(expiration time.Duration, scope tokens.Scope) (tokens.Token, error)
+
+	// RevokeToken refuses the token from now on, even if it is not expired.
+	RevokeToken(token string) error
 
 	// Join tries to mutually share certificates of the distant address in
 	// parameter using the token as a credential. The certificate of the distant
@@ -74,6 +93,16 @@ type Joinable interface {
 	// The token and the certificate digest are provided by the distant peer
 	// over a secure channel.
 	Join(addr, token string, certHash []byte) error
+
+	// UpdateCertificate replaces the certificate of the instance, and its
+	// private key, without interrupting the active connections. The new
+	// certificate is shared with the known peers, and with the nodes that join
+	// afterwards.
+	UpdateCertificate(cert *x509.Certificate, secret interface{}) error
+
+	// Metrics returns the counters of the traffic of the instance, per URI of
+	// the RPCs.
+	Metrics() *mino.Metrics
 }
 
 // Endpoint defines the requirement of an endpoint. Since the endpoint can be
@@ -107,14 +136,22 @@ type Minogrpc struct {
 }
 
 type minoTemplate struct {
-	myAddr session.Address
-	router router.Router
-	fac    mino.AddressFactory
-	certs  certs.Storage
-	secret interface{}
-	public interface{}
-	curve  elliptic.Curve
-	random io.Reader
+	myAddr       session.Address
+	publicAddrs  []string
+	router       router.Router
+	fac          mino.AddressFactory
+	certs        certs.Storage
+	scores       scores.Board
+	resolver     resolver.Resolver
+	secret       interface{}
+	public       interface{}
+	curve        elliptic.Curve
+	random       io.Reader
+	maxConns     int
+	maxInFlight  int
+	fragmentSize int
+	budget       *memory.Budget
+	compressor   serde.Compressor
 }
 
 // Option is the type to set some fields when instantiating an overlay.
@@ -127,7 +164,39 @@ func WithStorage(certs certs.Storage) Option {
 	}
 }
 
-// WithCertificateKey is an option to set the key of the server certificate.
+// WithScores is an option to set a different scoreboard of the peers.
+func WithScores(board scores.Board) Option {
+	return func(tmpl *minoTemplate) {
+		tmpl.scores = board
+	}
+}
+
+// WithResolver is an option to set how the hosts of the addresses are resolved
+// when dialing the participants.
+func WithResolver(r resolver.Resolver) Option {
+	return func(tmpl *minoTemplate) {
+		tmpl.resolver = r
+	}
+}
+
+// WithPublicAddress is an option to set the address, as host:port, that is
+// announced to the other participants when it differs from the address the
+// server listens on, e.g. behind a load balancer or a NAT. Several addresses
+// can be announced, e.g. an internal and an external one, in which case the
+// participants try them in order.
+//
+// A node that cannot be dialed at all announces relay://public-addr/node-id,
+// where the public address is the one of a participant acting as its relay. The
+// node registers with the relay once it knows its certificate, e.g. after
+// joining it, and the participants then reach the node through the relay.
+func WithPublicAddress(addrs ...string) Option {
+	return func(tmpl *minoTemplate) {
+		tmpl.publicAddrs = addrs
+	}
+}
+
+// WithCertificateKey is an option to set the key of the server certificate. A
+// key reserved for a usage other than the transport is refused.
 func WithCertificateKey(secret, public interface{}) Option {
 	return func(tmpl *minoTemplate) {
 		tmpl.secret = secret
@@ -143,6 +212,55 @@ func WithRandom(r io.Reader) Option {
 	}
 }
 
+// WithConnectionLimit is an option to set the maximum number of outgoing
+// connections open at the same time. A call or a stream that needs a new
+// connection beyond the limit fails with an error that wraps
+// mino.ErrBackpressure. The default is zero, which means no limit.
+func WithConnectionLimit(n int) Option {
+	return func(tmpl *minoTemplate) {
+		tmpl.maxConns = n
+	}
+}
+
+// WithInFlightLimit is an option to set the maximum number of packets that a
+// stream sends to the relays of the participant at the same time. The packets
+// beyond the limit are dropped, and the senders receive an error that wraps
+// mino.ErrBackpressure. Zero means no limit.
+func WithInFlightLimit(n int) Option {
+	return func(tmpl *minoTemplate) {
+		tmpl.maxInFlight = n
+	}
+}
+
+// WithFragmentSize is an option to set the size above which the messages of a
+// stream are split into several fragments, so that a large message, e.g. a
+// block or a resharing deal, doesn't exceed the maximum size of a gRPC
+// message. Zero disables the fragmentation.
+func WithFragmentSize(size int) Option {
+	return func(tmpl *minoTemplate) {
+		tmpl.fragmentSize = size
+	}
+}
+
+// WithMemoryBudget is an option to account for the packets buffered by the
+// streams of the overlay. When the cap of the budget is reached, the incomplete
+// messages are evicted and the packets beyond it are dropped with an error that
+// wraps memory.ErrExhausted.
+func WithMemoryBudget(b *memory.Budget) Option {
+	return func(tmpl *minoTemplate) {
+		tmpl.budget = b
+	}
+}
+
+// WithPayloadCompression is an option to compress the payloads of the calls
+// with the compressor, when the participant on the other side announces that
+// it decompresses its algorithm.
+func WithPayloadCompression(c serde.Compressor) Option {
+	return func(tmpl *minoTemplate) {
+		tmpl.compressor = c
+	}
+}
+
 // NewMinogrpc creates and starts a new instance. it will try to listen for the
 // address and returns an error if it fails.
 func NewMinogrpc(addr net.Addr, router router.Router, opts ...Option) (*Minogrpc, error) {
@@ -152,18 +270,50 @@ func NewMinogrpc(addr net.Addr, router router.Router, opts ...Option) (*Minogrpc
 	}
 
 	tmpl := minoTemplate{
-		myAddr: session.NewAddress(socket.Addr().String()),
-		router: router,
-		fac:    addressFac,
-		certs:  certs.NewInMemoryStore(),
-		curve:  elliptic.P521(),
-		random: rand.Reader,
+		myAddr:   session.NewAddress(socket.Addr().String()),
+		router:   router,
+		fac:      addressFac,
+		certs:    certs.NewInMemoryStore(),
+		scores:   scores.NewInMemoryBoard(),
+		resolver: resolver.NewDNS(),
+		curve:    elliptic.P521(),
+		random:   rand.Reader,
+
+		maxInFlight:  DefaultInFlightLimit,
+		fragmentSize: session.DefaultFragmentSize,
 	}
 
 	for _, opt := range opts {
 		opt(&tmpl)
 	}
 
+	if len(tmpl.publicAddrs) > 0 {
+		for _, addr := range tmpl.publicAddrs {
+			// A node behind a relay is reached through its registration.
+			_, _, relayed := session.ParseRelay(addr)
+			if relayed {
+				continue
+			}
+
+			_, _, err = net.SplitHostPort(addr)
+			if err != nil {
+				socket.Close()
+
+				return nil, xerrors.Errorf("invalid public address: %v", err)
+			}
+
+			// The address must designate this participant only, whereas an
+			// SRV record can point to several of them.
+			if strings.HasPrefix(addr, resolver.SRVPrefix) {
+				socket.Close()
+
+				return nil, xerrors.Errorf("invalid public address: '%s' is not a host:port", addr)
+			}
+		}
+
+		tmpl.myAddr = session.NewMultiAddress(tmpl.publicAddrs...)
+	}
+
 	o, err := newOverlay(tmpl)
 	if err != nil {
 		socket.Close()
@@ -171,7 +321,17 @@ func NewMinogrpc(addr net.Addr, router router.Router, opts ...Option) (*Minogrpc
 		return nil, xerrors.Errorf("overlay: %v", err)
 	}
 
-	creds := credentials.NewServerTLSFromCert(o.GetCertificate())
+	// The peers are asked for their certificate so that a misbehaving peer is
+	// identified by the key it has proven to own. The certificate is not
+	// verified during the handshake as the certificate of a joining node is
+	// not known yet. The certificate of the server is read for every handshake
+	// as it can be updated while running.
+	creds := credentials.NewTLS(&tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return o.GetCertificate(), nil
+		},
+		ClientAuth: tls.RequestClientCert,
+	})
 	dialAddr := o.myAddr.GetDialAddress()
 	tracer, err := getTracerForAddr(dialAddr)
 	if err != nil {
@@ -180,6 +340,7 @@ func NewMinogrpc(addr net.Addr, router router.Router, opts ...Option) (*Minogrpc
 
 	server := grpc.NewServer(
 		grpc.Creds(creds),
+		grpc.StatsHandler(statsHandler{metrics: o.metrics}),
 		grpc.UnaryInterceptor(otgrpc.OpenTracingServerInterceptor(tracer, otgrpc.SpanDecorator(decorateServerTrace))),
 		grpc.StreamInterceptor(otgrpc.OpenTracingStreamServerInterceptor(tracer, otgrpc.SpanDecorator(decorateServerTrace))),
 	)
@@ -203,6 +364,8 @@ func NewMinogrpc(addr net.Addr, router router.Router, opts ...Option) (*Minogrpc
 
 	m.listen(socket)
 
+	m.listenRelays()
+
 	return m, nil
 }
 
@@ -218,14 +381,31 @@ func (m *Minogrpc) GetAddress() mino.Address {
 }
 
 // GenerateToken implements minogrpc.Joinable. It generates and returns a new
-// token that will be valid for the given amount of time.
-func (m *Minogrpc) GenerateToken(expiration time.Duration) string {
-	return m.tokens.Generate(expiration)
+// token for the scope that will be valid for the given amount of time.
+func (m *Minogrpc) GenerateToken(expiration time.Duration, scope tokens.Scope) (tokens.Token, error) {
+	token, err := m.tokens.Generate(expiration, scope)
+	if err != nil {
+		return tokens.Token{}, xerrors.Errorf("failed to generate: %v", err)
+	}
+
+	return token, nil
+}
+
+// RevokeToken implements minogrpc.Joinable. It revokes a token generated by
+// the instance.
+func (m *Minogrpc) RevokeToken(token string) error {
+	err := m.tokens.Revoke(token)
+	if err != nil {
+		return xerrors.Errorf("failed to revoke: %v", err)
+	}
+
+	return nil
 }
 
 // GracefulStop first stops the grpc server then waits for the remaining
 // handlers to close.
 func (m *Minogrpc) GracefulStop() error {
+	m.relays.Close()
 	m.server.GracefulStop()
 
 	return m.postCheckClose()
@@ -233,6 +413,7 @@ func (m *Minogrpc) GracefulStop() error {
 
 // Stop stops the server immediatly.
 func (m *Minogrpc) Stop() error {
+	m.relays.Close()
 	m.server.Stop()
 
 	return m.postCheckClose()
@@ -285,9 +466,12 @@ func (m *Minogrpc) CreateRPC(name string, h mino.Handler, f serde.Factory) (mino
 	uri := append(append([]string{}, m.segments...), name)
 
 	rpc := &RPC{
-		uri:     strings.Join(uri, "/"),
-		overlay: m.overlay,
-		factory: f,
+		uri:         strings.Join(uri, "/"),
+		overlay:     m.overlay,
+		factory:     f,
+		class:       mino.ClassOf(h),
+		compression: mino.CompressionOf(h),
+		policy:      mino.PolicyOf(h),
 	}
 
 	for _, segment := range uri {
@@ -317,6 +501,12 @@ func (m *Minogrpc) String() string {
 	return fmt.Sprintf("mino[%v]", m.overlay.myAddr)
 }
 
+// Metrics implements minogrpc.Joinable. It returns the counters of the traffic
+// of the overlay, per URI of the RPCs.
+func (m *Minogrpc) Metrics() *mino.Metrics {
+	return m.overlay.metrics
+}
+
 // GetTrafficWatcher returns the traffic watcher.
 func (m *Minogrpc) GetTrafficWatcher() traffic.Watcher {
 	return traffic.GlobalWatcher
@@ -343,6 +533,35 @@ func (m *Minogrpc) listen(socket net.Listener) {
 	<-m.started
 }
 
+// listenRelays serves the connections tunneled by the relays of the hosts of
+// the address that are reached through a relay, if any.
+func (m *Minogrpc) listenRelays() {
+	relayed := false
+	for _, host := range m.overlay.myAddr.GetDialAddresses() {
+		_, _, ok := session.ParseRelay(host)
+		relayed = relayed || ok
+	}
+
+	if !relayed {
+		return
+	}
+
+	lis := newRelayListener(m.overlay.myAddrStr)
+
+	m.closer.Add(1)
+
+	go func() {
+		defer m.closer.Done()
+
+		err := m.server.Serve(lis)
+		if err != nil {
+			dela.Logger.Err(err).Msg("relay listener failed")
+		}
+	}()
+
+	m.overlay.serveRelays(lis)
+}
+
 // decorateServerTrace adds the protocol tag and the streamID tag to a server
 // side trace.
 func decorateServerTrace(ctx context.Context, span opentracing.Span, method string,
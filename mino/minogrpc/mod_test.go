@@ -1,11 +1,15 @@
 package minogrpc
 
 import (
+	"crypto/ed25519"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/memory"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/internal/tracing"
 	"go.dedis.ch/dela/mino"
@@ -29,8 +33,60 @@ func TestMinogrpc_New(t *testing.T) {
 	cert := m.GetCertificate()
 	require.NotNil(t, cert)
 
+	require.Equal(t, DefaultInFlightLimit, m.maxInFlight)
+	require.Equal(t, 0, m.connMgr.(*connManager).limit)
+
+	<-m.started
+	require.NoError(t, m.GracefulStop())
+}
+
+func TestMinogrpc_Limits_New(t *testing.T) {
+	addr := ParseAddress("127.0.0.1", 0)
+
+	budget := memory.NewBudget(memory.OverlayModule, 40)
+
+	m, err := NewMinogrpc(addr, tree.NewRouter(addressFac),
+		WithConnectionLimit(10), WithInFlightLimit(20), WithFragmentSize(30),
+		WithMemoryBudget(budget))
+	require.NoError(t, err)
+
+	require.Equal(t, 20, m.maxInFlight)
+	require.Equal(t, 30, m.fragSize)
+	require.Same(t, budget, m.budget)
+	require.Equal(t, 10, m.connMgr.(*connManager).limit)
+
+	require.NoError(t, m.GracefulStop())
+}
+
+func TestMinogrpc_PublicAddress_New(t *testing.T) {
+	addr := ParseAddress("127.0.0.1", 3333)
+	router := tree.NewRouter(addressFac)
+
+	m, err := NewMinogrpc(addr, router, WithPublicAddress("localhost:4444"))
+	require.NoError(t, err)
+
+	require.Equal(t, "localhost:4444", m.GetAddress().String())
+	require.Equal(t, []string{"localhost"}, m.GetCertificate().Leaf.DNSNames)
+
+	<-m.started
+	require.NoError(t, m.GracefulStop())
+
+	m, err = NewMinogrpc(addr, router, WithPublicAddress("127.0.0.1:3333", "localhost:4444"))
+	require.NoError(t, err)
+
+	require.Equal(t, "127.0.0.1:3333,localhost:4444", m.GetAddress().String())
+	require.Equal(t, []string{"localhost"}, m.GetCertificate().Leaf.DNSNames)
+
 	<-m.started
 	require.NoError(t, m.GracefulStop())
+
+	_, err = NewMinogrpc(addr, router, WithPublicAddress("localhost"))
+	require.EqualError(t, err,
+		"invalid public address: address localhost: missing port in address")
+
+	_, err = NewMinogrpc(addr, router, WithPublicAddress("srv:_dela._tcp.dela"))
+	require.EqualError(t, err,
+		"invalid public address: 'srv:_dela._tcp.dela' is not a host:port")
 }
 
 func TestMinogrpc_FailGenerateKey_New(t *testing.T) {
@@ -50,6 +106,18 @@ func TestMinogrpc_FailCreateCert_New(t *testing.T) {
 	require.Contains(t, err.Error(), "overlay: certificate failed: while creating: x509: ")
 }
 
+func TestMinogrpc_ReservedKey_New(t *testing.T) {
+	addr := ParseAddress("127.0.0.1", 3333)
+	router := tree.NewRouter(addressFac)
+
+	signer, err := crypto.NewConsensusSigner(bls.NewSigner())
+	require.NoError(t, err)
+
+	_, err = NewMinogrpc(addr, router, WithCertificateKey(signer, signer.GetPublicKey()))
+	require.EqualError(t, err, "overlay: invalid certificate key: "+
+		"key reserved for consensus cannot be used for transport")
+}
+
 func TestMinogrpc_FailStoreCert_New(t *testing.T) {
 	addr := ParseAddress("127.0.0.1", 3333)
 	router := tree.NewRouter(addressFac)
@@ -114,11 +182,28 @@ func TestMinogrpc_GetAddress(t *testing.T) {
 
 func TestMinogrpc_Token(t *testing.T) {
 	minoGrpc := &Minogrpc{
-		overlay: &overlay{tokens: tokens.NewInMemoryHolder()},
+		overlay: &overlay{tokens: makeHolder(t)},
 	}
 
-	token := minoGrpc.GenerateToken(time.Minute)
-	require.True(t, minoGrpc.tokens.Verify(token))
+	token, err := minoGrpc.GenerateToken(time.Minute, tokens.ScopeObserver)
+	require.NoError(t, err)
+
+	verified, err := minoGrpc.tokens.Verify(token.String())
+	require.NoError(t, err)
+	require.Equal(t, tokens.ScopeObserver, verified.Scope)
+
+	err = minoGrpc.RevokeToken(token.String())
+	require.NoError(t, err)
+
+	_, err = minoGrpc.tokens.Verify(token.String())
+	require.Error(t, err)
+
+	_, err = minoGrpc.GenerateToken(time.Minute, tokens.Scope("admin"))
+	require.EqualError(t, err,
+		"failed to generate: invalid scope: unknown scope 'admin'")
+
+	err = minoGrpc.RevokeToken("abc")
+	require.EqualError(t, err, "failed to revoke: malformed token")
 }
 
 func TestMinogrpc_GracefulClose(t *testing.T) {
@@ -126,6 +211,7 @@ func TestMinogrpc_GracefulClose(t *testing.T) {
 		overlay: &overlay{
 			closer:  new(sync.WaitGroup),
 			connMgr: fakeConnMgr{},
+			relays:  newRelayTable(),
 		},
 		server:  grpc.NewServer(),
 		closing: make(chan error),
@@ -185,6 +271,12 @@ func TestMinogrpc_CreateRPC(t *testing.T) {
 
 	_, err = mNs.CreateRPC("name", emptyHandler{}, fake.MessageFactory{})
 	require.EqualError(t, err, "rpc 'segment/name' already exists")
+
+	h := mino.NewClassifiedHandler(emptyHandler{}, mino.ClassConsensus)
+
+	rpc, err = mNs.CreateRPC("consensus", h, fake.MessageFactory{})
+	require.NoError(t, err)
+	require.Equal(t, mino.ClassConsensus, rpc.(*RPC).class)
 }
 
 func TestMinogrpc_InvalidSegment_CreateRPC(t *testing.T) {
@@ -215,6 +307,13 @@ func TestMinogrpc_String(t *testing.T) {
 // -----------------------------------------------------------------------------
 // Utility functions
 
+func makeHolder(t *testing.T) *tokens.SignedHolder {
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	return tokens.NewSignedHolder(key)
+}
+
 type badReader struct{}
 
 func (badReader) Read([]byte) (int, error) {
@@ -3,3 +3,8 @@
 package ptypes
 
 //go:generate protoc -I ./ --go_out=plugins=grpc:./ ./overlay.proto
+
+// Version is the version of the wire format of the messages. The messages of a
+// version can be decoded by the nodes of the later versions, and the other way
+// around, as the fields are only added, and the removed ones are reserved.
+const Version = 1
@@ -0,0 +1,185 @@
+package ptypes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/stretchr/testify/require"
+)
+
+// frozenFields is the list of the fields of every message, which must either
+// exist with the same name and type, or be reserved when removed. A new field
+// is added to the list with the version that introduces it.
+var frozenFields = map[string]map[int32]string{
+	"Certificate": {
+		1: "optional bytes address",
+		2: "optional bytes value",
+	},
+	"CertificateAck": {},
+	"JoinRequest": {
+		1: "optional string token",
+		2: "optional .ptypes.Certificate certificate",
+	},
+	"JoinResponse": {
+		1: "repeated .ptypes.Certificate peers",
+	},
+	"Message": {
+		1: "optional bytes from",
+		2: "optional bytes payload",
+	},
+	"Packet": {
+		1: "optional bytes serialized",
+	},
+	"Ack": {
+		1: "repeated string errors",
+	},
+}
+
+// goldenMessages are the encodings of each version of the wire format. The
+// messages of every version must be decoded by the current one.
+var goldenMessages = map[int][]struct {
+	encoded  string
+	expected proto.Message
+}{
+	1: {
+		{"0a0e3132372e302e302e313a323030301203010203", makeCertificate()},
+		{"", &CertificateAck{}},
+		{"0a0361626312150a0e3132372e302e302e313a323030301203010203",
+			&JoinRequest{Token: "abc", Certificate: makeCertificate()}},
+		{"0a150a0e3132372e302e302e313a3230303012030102030a150a0e3132372e302e302e" +
+			"313a323030301203010203",
+			&JoinResponse{Peers: []*Certificate{makeCertificate(), makeCertificate()}}},
+		{"0a0141120142", &Message{From: []byte("A"), Payload: []byte("B")}},
+		{"0a027b7d", &Packet{Serialized: []byte("{}")}},
+		{"0a046f6f7073", &Ack{Errors: []string{"oops"}}},
+	},
+}
+
+func TestOverlay_FrozenFields(t *testing.T) {
+	file := readDescriptor(t)
+
+	require.Len(t, file.GetMessageType(), len(frozenFields))
+
+	for _, msg := range file.GetMessageType() {
+		frozen, found := frozenFields[msg.GetName()]
+		require.True(t, found, "message %s is not frozen", msg.GetName())
+
+		fields := make(map[int32]string)
+		for _, field := range msg.GetField() {
+			fields[field.GetNumber()] = describeField(field)
+		}
+
+		for number, desc := range frozen {
+			current, found := fields[number]
+			if !found {
+				require.True(t, isReserved(msg, number),
+					"field %d of %s is removed but not reserved", number, msg.GetName())
+				continue
+			}
+
+			require.Equal(t, desc, current, "field %d of %s", number, msg.GetName())
+		}
+
+		for number := range fields {
+			_, found := frozen[number]
+			require.True(t, found, "field %d of %s is not frozen", number, msg.GetName())
+		}
+	}
+}
+
+func TestOverlay_GoldenMessages(t *testing.T) {
+	_, found := goldenMessages[Version]
+	require.True(t, found, "missing golden messages of version %d", Version)
+
+	for version, golden := range goldenMessages {
+		for _, g := range golden {
+			data, err := hex.DecodeString(g.encoded)
+			require.NoError(t, err)
+
+			msg := proto.Clone(g.expected)
+			msg.Reset()
+
+			err = proto.Unmarshal(data, msg)
+			require.NoError(t, err)
+			require.True(t, proto.Equal(g.expected, msg),
+				"version %d: %T", version, g.expected)
+
+			if version == Version {
+				encoded, err := proto.Marshal(g.expected)
+				require.NoError(t, err)
+				require.Equal(t, g.encoded, hex.EncodeToString(encoded))
+			}
+		}
+	}
+}
+
+func TestOverlay_UnknownFields(t *testing.T) {
+	data, err := proto.Marshal(makeCertificate())
+	require.NoError(t, err)
+
+	// A later version can send a field which must be ignored: number 15 with
+	// the varint type, and the value 1.
+	data = append(data, 0x78, 0x01)
+
+	cert := &Certificate{}
+	err = proto.Unmarshal(data, cert)
+	require.NoError(t, err)
+	require.Equal(t, makeCertificate().GetAddress(), cert.GetAddress())
+	require.Equal(t, makeCertificate().GetValue(), cert.GetValue())
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeCertificate() *Certificate {
+	return &Certificate{
+		Address: []byte("127.0.0.1:2000"),
+		Value:   []byte{1, 2, 3},
+	}
+}
+
+func readDescriptor(t *testing.T) *descriptor.FileDescriptorProto {
+	gz := proto.FileDescriptor("overlay.proto")
+	require.NotNil(t, gz)
+
+	reader, err := gzip.NewReader(bytes.NewReader(gz))
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+
+	file := &descriptor.FileDescriptorProto{}
+	err = proto.Unmarshal(data, file)
+	require.NoError(t, err)
+
+	return file
+}
+
+// describeField returns the label, the type and the name of the field as they
+// are written in the definition.
+func describeField(field *descriptor.FieldDescriptorProto) string {
+	kind := field.GetTypeName()
+	if kind == "" {
+		kind = strings.ToLower(strings.TrimPrefix(field.GetType().String(), "TYPE_"))
+	}
+
+	label := strings.TrimPrefix(field.GetLabel().String(), "LABEL_")
+
+	return strings.ToLower(label) + " " + kind + " " + field.GetName()
+}
+
+func isReserved(msg *descriptor.DescriptorProto, number int32) bool {
+	for _, r := range msg.GetReservedRange() {
+		if number >= r.GetStart() && number < r.GetEnd() {
+			return true
+		}
+	}
+
+	return false
+}
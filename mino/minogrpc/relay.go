@@ -0,0 +1,686 @@
+// This file contains the relay mode of the overlay, for the participants that
+// cannot be dialed, e.g. behind a NAT.
+//
+// A node behind a relay announces the address relay://public-addr/node-id. It
+// keeps a stream open to the relay, which is a participant reachable at the
+// public address, to register its identifier. When a participant dials the
+// node, it opens a stream to the relay that asks for a tunnel. The relay
+// requests a new stream from the node over the registration stream, and pipes
+// the packets of both streams. The connection tunneled is end-to-end secured
+// by TLS, and the node serves it like any other connection.
+//
+// The streams of the relay use the regular Stream RPC of the overlay, and they
+// are identified by their headers, so that the wire format doesn't change.
+
+package minogrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minogrpc/certs"
+	"go.dedis.ch/dela/mino/minogrpc/ptypes"
+	"go.dedis.ch/dela/mino/minogrpc/session"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// headerRelayRegisterKey is the key of the header that registers the
+	// identifier of a node with its relay.
+	headerRelayRegisterKey = "relayregister"
+	// headerRelayTunnelKey is the key of the header of a tunnel opened by a
+	// node upon request of its relay.
+	headerRelayTunnelKey = "relaytunnel"
+	// headerRelayTargetKey is the key of the header that asks the relay for a
+	// tunnel to a node.
+	headerRelayTargetKey = "relaytarget"
+
+	// tunnelTimeout is the amount of time to wait for a node to open a tunnel
+	// requested by its relay.
+	tunnelTimeout = 10 * time.Second
+
+	// maxChunkSize is the maximum number of bytes of a packet of a tunnel.
+	maxChunkSize = 32 * 1024
+)
+
+// relayRetryDelay is the amount of time before a node registers again with its
+// relay after a failure.
+var relayRetryDelay = 5 * time.Second
+
+// relayTable is the registry of the nodes that use the overlay as their relay,
+// and of the tunnels being opened to them.
+type relayTable struct {
+	sync.Mutex
+
+	// quit is closed when the overlay stops, which closes the registrations
+	// and the tunnels.
+	quit     chan struct{}
+	quitOnce sync.Once
+
+	// wake asks a node behind a relay to register again without waiting, e.g.
+	// when it has joined the network.
+	wake chan struct{}
+
+	nodes   map[string]*relayNode
+	pending map[string]pendingTunnel
+	counter uint64
+}
+
+// relayNode is the registration stream of a node, which is used to request new
+// tunnels.
+type relayNode struct {
+	sync.Mutex
+	stream ptypes.Overlay_StreamServer
+}
+
+type pendingTunnel struct {
+	id string
+	ch chan *tunnel
+}
+
+// tunnel is a stream opened by a node upon request of the relay. It is closed
+// by closing done, which ends the RPC.
+type tunnel struct {
+	stream ptypes.Overlay_StreamServer
+	done   chan struct{}
+	once   sync.Once
+}
+
+func (t *tunnel) close() {
+	t.once.Do(func() { close(t.done) })
+}
+
+func newRelayTable() *relayTable {
+	return &relayTable{
+		quit:    make(chan struct{}),
+		wake:    make(chan struct{}, 1),
+		nodes:   make(map[string]*relayNode),
+		pending: make(map[string]pendingTunnel),
+	}
+}
+
+// Close closes the registrations and the tunnels.
+func (r *relayTable) Close() {
+	r.quitOnce.Do(func() { close(r.quit) })
+}
+
+// wakeUp wakes the registration loop up if it is waiting to retry.
+func (r *relayTable) wakeUp() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// register keeps the stream as the registration of the node until it is
+// closed, or replaced by a new registration.
+func (r *relayTable) register(id string, stream ptypes.Overlay_StreamServer) {
+	node := &relayNode{stream: stream}
+
+	r.Lock()
+	r.nodes[id] = node
+	r.Unlock()
+
+	dela.Logger.Info().Str("node", id).Msg("node registered on the relay")
+
+	closed := make(chan struct{})
+
+	go func() {
+		// The node never sends anything on the registration.
+		for {
+			_, err := stream.Recv()
+			if err != nil {
+				close(closed)
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-closed:
+	case <-r.quit:
+	}
+
+	r.Lock()
+	if r.nodes[id] == node {
+		delete(r.nodes, id)
+	}
+	r.Unlock()
+}
+
+// open requests a tunnel from the node and waits for it to be opened.
+func (r *relayTable) open(ctx context.Context, id string) (*tunnel, error) {
+	r.Lock()
+
+	node, found := r.nodes[id]
+	if !found {
+		r.Unlock()
+		return nil, xerrors.Errorf("node '%s' is not registered", id)
+	}
+
+	r.counter++
+	tid := strconv.FormatUint(r.counter, 10)
+
+	ch := make(chan *tunnel, 1)
+	r.pending[tid] = pendingTunnel{id: id, ch: ch}
+
+	r.Unlock()
+
+	node.Lock()
+	err := node.stream.Send(&ptypes.Packet{Serialized: []byte(tid)})
+	node.Unlock()
+
+	if err == nil {
+		ctx, cancel := context.WithTimeout(ctx, tunnelTimeout)
+		defer cancel()
+
+		select {
+		case t := <-ch:
+			return t, nil
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-r.quit:
+			err = xerrors.New("relay closed")
+		}
+	}
+
+	r.Lock()
+	delete(r.pending, tid)
+	r.Unlock()
+
+	// The tunnel might have been accepted in the meantime.
+	select {
+	case t := <-ch:
+		t.close()
+	default:
+	}
+
+	return nil, xerrors.Errorf("tunnel to '%s' failed: %v", id, err)
+}
+
+// accept hands the stream over to the request of the tunnel, and waits for it
+// to be closed. The tunnel must be opened by the node that has been asked.
+func (r *relayTable) accept(owner func(id string) bool, tid string,
+	stream ptypes.Overlay_StreamServer) error {
+
+	r.Lock()
+
+	p, found := r.pending[tid]
+	if !found || !owner(p.id) {
+		r.Unlock()
+		return xerrors.Errorf("unexpected tunnel '%s'", tid)
+	}
+
+	delete(r.pending, tid)
+
+	t := &tunnel{stream: stream, done: make(chan struct{})}
+	p.ch <- t
+
+	r.Unlock()
+
+	select {
+	case <-t.done:
+	case <-stream.Context().Done():
+	case <-r.quit:
+	}
+
+	t.close()
+
+	return nil
+}
+
+// relay processes the stream when it belongs to the relay mode, according to
+// its headers. It returns false if it is a regular stream.
+func (o *overlayServer) relay(stream ptypes.Overlay_StreamServer, headers metadata.MD) (bool, error) {
+	ctx := stream.Context()
+
+	id := getOrEmpty(headers, headerRelayRegisterKey)
+	if id != "" {
+		if !o.isRelayed(ctx, id) {
+			return true, xerrors.Errorf("registration of '%s' refused", id)
+		}
+
+		o.relays.register(id, stream)
+
+		return true, nil
+	}
+
+	tid := getOrEmpty(headers, headerRelayTunnelKey)
+	if tid != "" {
+		owner := func(id string) bool { return o.isRelayed(ctx, id) }
+
+		return true, o.relays.accept(owner, tid, stream)
+	}
+
+	id = getOrEmpty(headers, headerRelayTargetKey)
+	if id != "" {
+		// The relay is not open to anyone.
+		_, ok := o.authenticate(ctx)
+		if !ok {
+			return true, xerrors.New("unknown peer")
+		}
+
+		t, err := o.relays.open(ctx, id)
+		if err != nil {
+			return true, err
+		}
+
+		defer t.close()
+
+		pipe(stream, t.stream, o.relays.quit)
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// isRelayed returns true if the peer of the request is the node with the
+// identifier behind this relay.
+func (o *overlay) isRelayed(ctx context.Context, id string) bool {
+	addr, ok := o.authenticate(ctx)
+	if !ok {
+		return false
+	}
+
+	netAddr, ok := addr.(session.Address)
+	if !ok {
+		return false
+	}
+
+	for _, host := range netAddr.GetDialAddresses() {
+		public, relayID, ok := session.ParseRelay(host)
+		if ok && relayID == id && isHostOf(o.myAddr, public) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// serveRelays keeps a registration with the relay of every host of the
+// address that is reached through a relay, until the overlay stops. The
+// tunnels are served by the listener.
+func (o *overlay) serveRelays(lis *relayListener) {
+	for _, host := range o.myAddr.GetDialAddresses() {
+		public, id, ok := session.ParseRelay(host)
+		if !ok {
+			continue
+		}
+
+		o.closer.Add(1)
+
+		go func() {
+			defer o.closer.Done()
+
+			for {
+				err := o.registerRelay(public, id, lis)
+
+				select {
+				case <-o.relays.quit:
+					return
+				default:
+				}
+
+				dela.Logger.Warn().Err(err).Str("relay", public).Msg("relay registration failed")
+
+				select {
+				case <-o.relays.quit:
+					return
+				case <-o.relays.wake:
+				case <-time.After(relayRetryDelay):
+				}
+			}
+		}()
+	}
+}
+
+// registerRelay registers the identifier with the relay, and opens the tunnels
+// it requests until the registration is closed.
+func (o *overlay) registerRelay(public, id string, lis *relayListener) error {
+	peer := relayPeer(o.certs, public)
+
+	conn, err := o.connMgr.Acquire(peer)
+	if err != nil {
+		return xerrors.Errorf("failed to connect: %v", err)
+	}
+
+	defer o.connMgr.Release(peer, conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-o.relays.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	md := metadata.Pairs(headerRelayRegisterKey, id)
+
+	stream, err := ptypes.NewOverlayClient(conn).Stream(metadata.NewOutgoingContext(ctx, md))
+	if err != nil {
+		return xerrors.Errorf("failed to register: %v", err)
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return xerrors.Errorf("registration closed: %v", err)
+		}
+
+		go o.openTunnel(peer, string(req.GetSerialized()), lis)
+	}
+}
+
+// openTunnel opens the tunnel requested by the relay and hands it over to the
+// listener of the server.
+func (o *overlay) openTunnel(peer mino.Address, tid string, lis *relayListener) {
+	conn, err := o.connMgr.Acquire(peer)
+	if err != nil {
+		dela.Logger.Warn().Err(err).Msg("failed to open tunnel")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	md := metadata.Pairs(headerRelayTunnelKey, tid)
+
+	stream, err := ptypes.NewOverlayClient(conn).Stream(metadata.NewOutgoingContext(ctx, md))
+	if err != nil {
+		cancel()
+		o.connMgr.Release(peer, conn)
+
+		dela.Logger.Warn().Err(err).Msg("failed to open tunnel")
+
+		return
+	}
+
+	lis.push(newStreamConn(stream, peer.String(), func() {
+		cancel()
+		o.connMgr.Release(peer, conn)
+	}))
+}
+
+// dialRelay returns a connection to the node tunneled through its relay. The
+// relay can be the participant itself.
+func (mgr *connManager) dialRelay(ctx context.Context, public, id string) (net.Conn, error) {
+	remote := session.RelayScheme + public + "/" + id
+
+	if isHostOf(mgr.myAddr, public) {
+		t, err := mgr.relays.open(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return newStreamConn(t.stream, remote, t.close), nil
+	}
+
+	peer := relayPeer(mgr.certs, public)
+
+	conn, err := mgr.Acquire(peer)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to connect relay: %v", err)
+	}
+
+	// The tunnel outlives the context of the dial.
+	streamCtx, cancel := context.WithCancel(context.Background())
+	md := metadata.Pairs(headerRelayTargetKey, id)
+
+	stream, err := ptypes.NewOverlayClient(conn).Stream(metadata.NewOutgoingContext(streamCtx, md))
+	if err != nil {
+		cancel()
+		mgr.Release(peer, conn)
+
+		return nil, xerrors.Errorf("failed to open tunnel: %v", err)
+	}
+
+	return newStreamConn(stream, remote, func() {
+		cancel()
+		mgr.Release(peer, conn)
+	}), nil
+}
+
+// relayPeer returns the address of the participant that has the public address
+// as one of its hosts, or the public address alone if it is not known.
+func relayPeer(store certs.Storage, public string) mino.Address {
+	var peer mino.Address = session.NewAddress(public)
+
+	store.Range(func(addr mino.Address, _ *tls.Certificate) bool {
+		if isHostOf(addr, public) {
+			peer = addr
+			return false
+		}
+
+		return true
+	})
+
+	return peer
+}
+
+// isHostOf returns true if the host is one of the hosts of the address.
+func isHostOf(addr mino.Address, host string) bool {
+	netAddr, ok := addr.(session.Address)
+	if !ok {
+		return false
+	}
+
+	for _, h := range netAddr.GetDialAddresses() {
+		if h == host {
+			return true
+		}
+	}
+
+	return false
+}
+
+// packetStream is the common interface of the client and the server sides of
+// a stream.
+type packetStream interface {
+	Send(*ptypes.Packet) error
+	Recv() (*ptypes.Packet, error)
+}
+
+// pipe forwards the packets of each stream to the other until one of them is
+// closed.
+func pipe(a, b packetStream, quit <-chan struct{}) {
+	done := make(chan struct{}, 2)
+
+	forward := func(from, to packetStream) {
+		for {
+			p, err := from.Recv()
+			if err != nil {
+				break
+			}
+
+			err = to.Send(p)
+			if err != nil {
+				break
+			}
+		}
+
+		done <- struct{}{}
+	}
+
+	go forward(a, b)
+	go forward(b, a)
+
+	select {
+	case <-done:
+	case <-quit:
+	}
+}
+
+// relayAddr is the network address of a tunneled connection.
+//
+// - implements net.Addr
+type relayAddr string
+
+// Network implements net.Addr.
+func (a relayAddr) Network() string {
+	return "relay"
+}
+
+// String implements net.Addr.
+func (a relayAddr) String() string {
+	return string(a)
+}
+
+// streamConn is a connection whose bytes are tunneled in the packets of a
+// stream. The deadlines are not supported as the stream is closed by its
+// context instead.
+//
+// - implements net.Conn
+type streamConn struct {
+	sync.Mutex
+
+	stream  packetStream
+	remote  relayAddr
+	buffer  []byte
+	once    sync.Once
+	onClose func()
+}
+
+func newStreamConn(stream packetStream, remote string, onClose func()) *streamConn {
+	return &streamConn{
+		stream:  stream,
+		remote:  relayAddr(remote),
+		onClose: onClose,
+	}
+}
+
+// Read implements net.Conn. It reads the next packet of the stream when the
+// previous one has been consumed.
+func (c *streamConn) Read(b []byte) (int, error) {
+	for len(c.buffer) == 0 {
+		p, err := c.stream.Recv()
+		if err == io.EOF {
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, xerrors.Errorf("stream closed: %v", err)
+		}
+
+		c.buffer = p.GetSerialized()
+	}
+
+	n := copy(b, c.buffer)
+	c.buffer = c.buffer[n:]
+
+	return n, nil
+}
+
+// Write implements net.Conn. It sends the bytes in packets of limited size.
+func (c *streamConn) Write(b []byte) (int, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	written := 0
+
+	for written < len(b) {
+		end := written + maxChunkSize
+		if end > len(b) {
+			end = len(b)
+		}
+
+		err := c.stream.Send(&ptypes.Packet{Serialized: b[written:end]})
+		if err != nil {
+			return written, xerrors.Errorf("stream closed: %v", err)
+		}
+
+		written = end
+	}
+
+	return written, nil
+}
+
+// Close implements net.Conn. It closes the stream.
+func (c *streamConn) Close() error {
+	c.once.Do(c.onClose)
+
+	return nil
+}
+
+// LocalAddr implements net.Conn.
+func (c *streamConn) LocalAddr() net.Addr {
+	return relayAddr("local")
+}
+
+// RemoteAddr implements net.Conn.
+func (c *streamConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+// SetDeadline implements net.Conn. It does nothing.
+func (c *streamConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+// SetReadDeadline implements net.Conn. It does nothing.
+func (c *streamConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline implements net.Conn. It does nothing.
+func (c *streamConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+// relayListener is the listener of the connections tunneled by the relays.
+//
+// - implements net.Listener
+type relayListener struct {
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+	addr  net.Addr
+}
+
+func newRelayListener(addr string) *relayListener {
+	return &relayListener{
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+		addr:  relayAddr(addr),
+	}
+}
+
+// push hands the connection over to the server, or closes it if the listener
+// is closed.
+func (l *relayListener) push(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.done:
+		conn.Close()
+	}
+}
+
+// Accept implements net.Listener. It waits for the next tunnel.
+func (l *relayListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, xerrors.New("listener closed")
+	}
+}
+
+// Close implements net.Listener.
+func (l *relayListener) Close() error {
+	l.once.Do(func() { close(l.done) })
+
+	return nil
+}
+
+// Addr implements net.Listener.
+func (l *relayListener) Addr() net.Addr {
+	return l.addr
+}
@@ -0,0 +1,414 @@
+package minogrpc
+
+import (
+	"context"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minogrpc/certs"
+	"go.dedis.ch/dela/mino/minogrpc/ptypes"
+	"go.dedis.ch/dela/mino/minogrpc/resolver"
+	"go.dedis.ch/dela/mino/minogrpc/session"
+	"go.dedis.ch/dela/mino/minogrpc/tokens"
+	"go.dedis.ch/dela/mino/router/tree"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestMinogrpc_Relay_Scenario(t *testing.T) {
+	relay, err := NewMinogrpc(ParseAddress("127.0.0.1", 0), tree.NewRouter(addressFac))
+	require.NoError(t, err)
+
+	defer relay.GracefulStop()
+
+	public := session.NewRelayAddress(relay.GetAddress().String(), "home")
+
+	home, err := NewMinogrpc(ParseAddress("127.0.0.1", 0), tree.NewRouter(addressFac),
+		WithPublicAddress(public.String()))
+	require.NoError(t, err)
+
+	defer home.Stop()
+
+	require.Equal(t, public.String(), home.GetAddress().String())
+	require.Equal(t, []string{"home"}, home.GetCertificate().Leaf.DNSNames)
+
+	other, err := NewMinogrpc(ParseAddress("127.0.0.1", 0), tree.NewRouter(addressFac))
+	require.NoError(t, err)
+
+	defer other.GracefulStop()
+
+	for _, m := range []*Minogrpc{relay, home, other} {
+		for _, k := range []*Minogrpc{relay, other} {
+			m.GetCertificateStore().Store(k.GetAddress(), k.GetCertificate())
+		}
+	}
+
+	// The home node registers with its relay as soon as it has joined.
+	digest, err := relay.GetCertificateStore().Hash(relay.GetCertificate())
+	require.NoError(t, err)
+
+	token, err := relay.GenerateToken(time.Minute, tokens.ScopeValidator)
+	require.NoError(t, err)
+
+	err = home.Join(relay.GetAddress().String(), token.String(), digest)
+	require.NoError(t, err)
+
+	other.GetCertificateStore().Store(home.GetAddress(), home.GetCertificate())
+
+	call := &fake.Call{}
+
+	rpcs := make([]mino.RPC, 3)
+	for i, m := range []*Minogrpc{relay, home, other} {
+		rpcs[i] = mino.MustCreateRPC(m, "test", testHandler{call: call}, fake.MessageFactory{})
+	}
+
+	waitRegistered(t, relay, "home")
+
+	// The node is reached through the relay, either by another participant
+	// or by the relay itself.
+	for _, rpc := range []mino.RPC{rpcs[2], rpcs[0]} {
+		msgs, err := rpc.Call(context.Background(), fake.Message{},
+			mino.NewAddresses(home.GetAddress()))
+		require.NoError(t, err)
+
+		msg := <-msgs
+		require.True(t, msg.GetFrom().Equal(home.GetAddress()))
+
+		_, err = msg.GetMessageOrError()
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 2, call.Len())
+
+	// The node can still reach the other participants directly.
+	msgs, err := rpcs[1].Call(context.Background(), fake.Message{},
+		mino.NewAddresses(other.GetAddress()))
+	require.NoError(t, err)
+
+	_, err = (<-msgs).GetMessageOrError()
+	require.NoError(t, err)
+}
+
+func TestMinogrpc_Relay_New(t *testing.T) {
+	addr := ParseAddress("127.0.0.1", 0)
+
+	_, err := NewMinogrpc(addr, tree.NewRouter(addressFac),
+		WithPublicAddress("relay://127.0.0.1:2000/"))
+	require.EqualError(t, err,
+		"invalid public address: address relay://127.0.0.1:2000/: too many colons in address")
+}
+
+func TestRelayTable_Open(t *testing.T) {
+	table := newRelayTable()
+
+	_, err := table.open(context.Background(), "A")
+	require.EqualError(t, err, "node 'A' is not registered")
+
+	node := newFakePacketStream()
+	table.nodes["A"] = &relayNode{stream: node}
+
+	go func() {
+		p := <-node.out
+
+		err := table.accept(func(id string) bool { return id == "B" }, string(p.Serialized), node)
+		require.EqualError(t, err, "unexpected tunnel '1'")
+
+		err = table.accept(func(string) bool { return true }, string(p.Serialized), node)
+		require.NoError(t, err)
+	}()
+
+	tunnel, err := table.open(context.Background(), "A")
+	require.NoError(t, err)
+	require.Empty(t, table.pending)
+
+	tunnel.close()
+
+	// The node doesn't open the tunnel in time.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = table.open(ctx, "A")
+	require.EqualError(t, err, "tunnel to 'A' failed: context canceled")
+	require.Empty(t, table.pending)
+
+	<-node.out
+
+	node.err = fake.GetError()
+
+	_, err = table.open(context.Background(), "A")
+	require.EqualError(t, err, fake.Err("tunnel to 'A' failed"))
+
+	table.Close()
+	table.Close()
+
+	node.err = nil
+
+	_, err = table.open(context.Background(), "A")
+	require.EqualError(t, err, "tunnel to 'A' failed: relay closed")
+}
+
+func TestRelayTable_Register(t *testing.T) {
+	table := newRelayTable()
+
+	stream := newFakePacketStream()
+	close(stream.in)
+
+	table.register("A", stream)
+	require.Empty(t, table.nodes)
+
+	table.wakeUp()
+	table.wakeUp()
+	require.Len(t, table.wake, 1)
+}
+
+func TestOverlayServer_Relay(t *testing.T) {
+	overlay, err := newOverlay(minoTemplate{
+		myAddr:   session.NewAddress("127.0.0.1:2000"),
+		certs:    certs.NewInMemoryStore(),
+		router:   tree.NewRouter(addressFac),
+		fac:      addressFac,
+		resolver: resolver.NewDNS(),
+		curve:    elliptic.P521(),
+		random:   rand.Reader,
+	})
+	require.NoError(t, err)
+
+	srv := &overlayServer{overlay: overlay}
+
+	isRelay, err := srv.relay(fakeSrvStream{ctx: context.Background()}, metadata.MD{})
+	require.False(t, isRelay)
+	require.NoError(t, err)
+
+	isRelay, err = srv.relay(fakeSrvStream{ctx: context.Background()},
+		metadata.Pairs(headerRelayRegisterKey, "A"))
+	require.True(t, isRelay)
+	require.EqualError(t, err, "registration of 'A' refused")
+
+	isRelay, err = srv.relay(fakeSrvStream{ctx: context.Background()},
+		metadata.Pairs(headerRelayTunnelKey, "1"))
+	require.True(t, isRelay)
+	require.EqualError(t, err, "unexpected tunnel '1'")
+
+	isRelay, err = srv.relay(fakeSrvStream{ctx: context.Background()},
+		metadata.Pairs(headerRelayTargetKey, "A"))
+	require.True(t, isRelay)
+	require.EqualError(t, err, "unknown peer")
+
+	// The certificate of the node is registered under its relay address.
+	cert := makeCertificate(t, time.Now().Add(time.Hour), nil)
+	ctx := withPeer(context.Background(), cert)
+
+	overlay.certs.Store(session.NewRelayAddress("127.0.0.1:2000", "A"), cert)
+
+	require.True(t, overlay.isRelayed(ctx, "A"))
+	require.False(t, overlay.isRelayed(ctx, "B"))
+
+	isRelay, err = srv.relay(fakeSrvStream{ctx: ctx}, metadata.Pairs(headerRelayTargetKey, "B"))
+	require.True(t, isRelay)
+	require.EqualError(t, err, "node 'B' is not registered")
+
+	overlay.certs = certs.NewInMemoryStore()
+	overlay.certs.Store(session.NewRelayAddress("127.0.0.1:3000", "A"), cert)
+
+	require.False(t, overlay.isRelayed(ctx, "A"))
+}
+
+func TestRelayPeer(t *testing.T) {
+	store := certs.NewInMemoryStore()
+
+	addr := session.NewAddress("127.0.0.1:2000")
+	require.Equal(t, addr, relayPeer(store, "127.0.0.1:2000"))
+
+	known := session.NewAddress("127.0.0.1:3000,127.0.0.1:2000")
+	store.Store(fake.NewAddress(0), nil)
+	store.Store(known, nil)
+
+	require.Equal(t, known, relayPeer(store, "127.0.0.1:2000"))
+	require.False(t, isHostOf(fake.NewAddress(0), "127.0.0.1:2000"))
+}
+
+func TestPipe(t *testing.T) {
+	a := newFakePacketStream()
+	b := newFakePacketStream()
+
+	a.in <- &ptypes.Packet{Serialized: []byte("ping")}
+
+	go func() {
+		p := <-b.out
+		b.in <- &ptypes.Packet{Serialized: append(p.Serialized, '!')}
+		close(b.in)
+	}()
+
+	pipe(a, b, nil)
+
+	p := <-a.out
+	require.Equal(t, "ping!", string(p.Serialized))
+
+	quit := make(chan struct{})
+	close(quit)
+
+	pipe(newFakePacketStream(), newFakePacketStream(), quit)
+}
+
+func TestStreamConn_Read(t *testing.T) {
+	stream := newFakePacketStream()
+	conn := newStreamConn(stream, "relay://127.0.0.1:2000/A", nil)
+
+	stream.in <- &ptypes.Packet{Serialized: []byte("abc")}
+	stream.in <- &ptypes.Packet{}
+	stream.in <- &ptypes.Packet{Serialized: []byte("d")}
+
+	buffer := make([]byte, 2)
+
+	n, err := conn.Read(buffer)
+	require.NoError(t, err)
+	require.Equal(t, "ab", string(buffer[:n]))
+
+	n, err = conn.Read(buffer)
+	require.NoError(t, err)
+	require.Equal(t, "c", string(buffer[:n]))
+
+	n, err = conn.Read(buffer)
+	require.NoError(t, err)
+	require.Equal(t, "d", string(buffer[:n]))
+
+	close(stream.in)
+
+	_, err = conn.Read(buffer)
+	require.Equal(t, io.EOF, err)
+
+	stream.err = fake.GetError()
+
+	_, err = conn.Read(buffer)
+	require.EqualError(t, err, fake.Err("stream closed"))
+}
+
+func TestStreamConn_Write(t *testing.T) {
+	stream := newFakePacketStream()
+	stream.out = make(chan *ptypes.Packet, 3)
+
+	conn := newStreamConn(stream, "relay://127.0.0.1:2000/A", nil)
+
+	n, err := conn.Write(make([]byte, 2*maxChunkSize+1))
+	require.NoError(t, err)
+	require.Equal(t, 2*maxChunkSize+1, n)
+	require.Len(t, stream.out, 3)
+	require.Len(t, (<-stream.out).Serialized, maxChunkSize)
+
+	stream.err = fake.GetError()
+
+	n, err = conn.Write([]byte{1})
+	require.EqualError(t, err, fake.Err("stream closed"))
+	require.Equal(t, 0, n)
+}
+
+func TestStreamConn_Close(t *testing.T) {
+	closed := 0
+	conn := newStreamConn(nil, "relay://127.0.0.1:2000/A", func() { closed++ })
+
+	require.NoError(t, conn.Close())
+	require.NoError(t, conn.Close())
+	require.Equal(t, 1, closed)
+
+	require.Equal(t, "relay", conn.RemoteAddr().Network())
+	require.Equal(t, "relay://127.0.0.1:2000/A", conn.RemoteAddr().String())
+	require.Equal(t, "local", conn.LocalAddr().String())
+	require.NoError(t, conn.SetDeadline(time.Now()))
+	require.NoError(t, conn.SetReadDeadline(time.Now()))
+	require.NoError(t, conn.SetWriteDeadline(time.Now()))
+}
+
+func TestRelayListener_Accept(t *testing.T) {
+	lis := newRelayListener("relay://127.0.0.1:2000/A")
+	require.Equal(t, "relay://127.0.0.1:2000/A", lis.Addr().String())
+
+	conn := newStreamConn(nil, "", func() {})
+
+	go lis.push(conn)
+
+	accepted, err := lis.Accept()
+	require.NoError(t, err)
+	require.Equal(t, conn, accepted)
+
+	require.NoError(t, lis.Close())
+	require.NoError(t, lis.Close())
+
+	_, err = lis.Accept()
+	require.EqualError(t, err, "listener closed")
+
+	closed := false
+	lis.push(newStreamConn(nil, "", func() { closed = true }))
+	require.True(t, closed)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// waitRegistered waits for the node to be registered with the relay.
+func waitRegistered(t *testing.T, relay *Minogrpc, id string) {
+	timeout := time.After(10 * time.Second)
+
+	for {
+		relay.relays.Lock()
+		_, found := relay.relays.nodes[id]
+		relay.relays.Unlock()
+
+		if found {
+			return
+		}
+
+		select {
+		case <-timeout:
+			t.Fatalf("node '%s' is not registered", id)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// fakePacketStream is a stream whose packets are received from the input
+// channel and sent to the output channel.
+type fakePacketStream struct {
+	ptypes.Overlay_StreamServer
+	in  chan *ptypes.Packet
+	out chan *ptypes.Packet
+	err error
+}
+
+func newFakePacketStream() *fakePacketStream {
+	return &fakePacketStream{
+		in:  make(chan *ptypes.Packet, 10),
+		out: make(chan *ptypes.Packet, 1),
+	}
+}
+
+func (s *fakePacketStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakePacketStream) Send(p *ptypes.Packet) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.out <- p
+
+	return nil
+}
+
+func (s *fakePacketStream) Recv() (*ptypes.Packet, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	p, more := <-s.in
+	if !more {
+		return nil, io.EOF
+	}
+
+	return p, nil
+}
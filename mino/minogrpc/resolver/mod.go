@@ -0,0 +1,75 @@
+// Package resolver defines how the hosts of the minogrpc addresses are
+// resolved into the network addresses that are dialed.
+//
+// The DNS resolver accepts, on top of the host:port form, the hosts in the form
+// "srv:<name>" that are resolved with the SRV records of the name, like the
+// ones Kubernetes creates for the named ports of a service, e.g.
+// "srv:_dela._tcp.dela.default.svc.cluster.local". The DNS names of the other
+// hosts are resolved by the dialer for each connection attempt.
+package resolver
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// SRVPrefix is the prefix of the hosts resolved with SRV records.
+const SRVPrefix = "srv:"
+
+// Resolver is the interface to resolve the host of an address.
+type Resolver interface {
+	// Resolve returns the network addresses, as host:port, to dial to reach
+	// the host, in order of preference.
+	Resolve(ctx context.Context, host string) ([]string, error)
+}
+
+// lookupSRVFn is the signature of the function that looks up the SRV records.
+type lookupSRVFn func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+
+// DNS is a resolver that looks up the SRV records of the hosts with the SRV
+// prefix, and returns the other hosts as they are.
+//
+// - implements resolver.Resolver
+type DNS struct {
+	lookupSRV lookupSRVFn
+}
+
+// NewDNS returns a new resolver that uses the default resolver of the system.
+func NewDNS() DNS {
+	return DNS{
+		lookupSRV: net.DefaultResolver.LookupSRV,
+	}
+}
+
+// Resolve implements resolver.Resolver. It returns the targets of the SRV
+// records sorted by priority and randomized by weight, or the host itself
+// when it doesn't have the SRV prefix.
+func (r DNS) Resolve(ctx context.Context, host string) ([]string, error) {
+	if !strings.HasPrefix(host, SRVPrefix) {
+		return []string{host}, nil
+	}
+
+	name := strings.TrimPrefix(host, SRVPrefix)
+
+	_, records, err := r.lookupSRV(ctx, "", "", name)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to lookup SRV records: %v", err)
+	}
+
+	if len(records) == 0 {
+		return nil, xerrors.Errorf("no SRV record for '%s'", name)
+	}
+
+	addrs := make([]string, len(records))
+	for i, record := range records {
+		target := strings.TrimSuffix(record.Target, ".")
+
+		addrs[i] = net.JoinHostPort(target, strconv.Itoa(int(record.Port)))
+	}
+
+	return addrs, nil
+}
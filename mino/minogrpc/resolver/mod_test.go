@@ -0,0 +1,58 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestDNS_Resolve(t *testing.T) {
+	r := NewDNS()
+
+	addrs, err := r.Resolve(context.Background(), "127.0.0.1:2000")
+	require.NoError(t, err)
+	require.Equal(t, []string{"127.0.0.1:2000"}, addrs)
+
+	r.lookupSRV = fakeLookup{records: []*net.SRV{
+		{Target: "node-0.dela.default.svc.cluster.local.", Port: 2000},
+		{Target: "node-1.dela.default.svc.cluster.local.", Port: 2001},
+	}}.lookup
+
+	addrs, err = r.Resolve(context.Background(), "srv:_dela._tcp.dela")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"node-0.dela.default.svc.cluster.local:2000",
+		"node-1.dela.default.svc.cluster.local:2001",
+	}, addrs)
+
+	r.lookupSRV = fakeLookup{records: []*net.SRV{{Target: "::1", Port: 2000}}}.lookup
+
+	addrs, err = r.Resolve(context.Background(), "srv:_dela._tcp.dela")
+	require.NoError(t, err)
+	require.Equal(t, []string{"[::1]:2000"}, addrs)
+
+	r.lookupSRV = fakeLookup{}.lookup
+
+	_, err = r.Resolve(context.Background(), "srv:_dela._tcp.dela")
+	require.EqualError(t, err, "no SRV record for '_dela._tcp.dela'")
+
+	r.lookupSRV = fakeLookup{err: xerrors.New("oops")}.lookup
+
+	_, err = r.Resolve(context.Background(), "srv:_dela._tcp.dela")
+	require.EqualError(t, err, "failed to lookup SRV records: oops")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeLookup struct {
+	records []*net.SRV
+	err     error
+}
+
+func (l fakeLookup) lookup(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	return name, l.records, l.err
+}
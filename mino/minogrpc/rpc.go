@@ -8,6 +8,7 @@ package minogrpc
 import (
 	context "context"
 	"sync"
+	"time"
 
 	"github.com/rs/xid"
 	"go.dedis.ch/dela"
@@ -17,6 +18,7 @@ import (
 	"go.dedis.ch/dela/mino/minogrpc/session"
 	"go.dedis.ch/dela/serde"
 	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
@@ -27,12 +29,18 @@ import (
 //
 // - implements mino.RPC
 type RPC struct {
-	overlay *overlay
-	uri     string
-	factory serde.Factory
+	overlay     *overlay
+	uri         string
+	factory     serde.Factory
+	class       mino.Class
+	compression mino.Compression
+	policy      mino.Policy
 }
 
-// Call implements mino.RPC. It calls the RPC on each provided address.
+// Call implements mino.RPC. It calls the RPC on each provided address. Each
+// player is contacted according to the policy of the RPC, so that a player that
+// is unreachable or too slow is retried or given up on before the context is
+// done.
 func (rpc *RPC) Call(ctx context.Context,
 	req serde.Message, players mino.Players) (<-chan mino.Response, error) {
 
@@ -46,6 +54,11 @@ func (rpc *RPC) Call(ctx context.Context,
 		Payload: data,
 	}
 
+	compressedMsg, err := rpc.compress(sendMsg, players)
+	if err != nil {
+		return nil, xerrors.Errorf("while compressing: %v", err)
+	}
+
 	out := make(chan mino.Response, players.Len())
 
 	wg := sync.WaitGroup{}
@@ -58,32 +71,14 @@ func (rpc *RPC) Call(ctx context.Context,
 		go func() {
 			defer wg.Done()
 
-			clientConn, err := rpc.overlay.connMgr.Acquire(addr)
-			if err != nil {
-				resp := mino.NewResponseWithError(
-					addr,
-					xerrors.Errorf("failed to get client conn: %v", err),
-				)
-
-				out <- resp
-				return
+			msg := sendMsg
+			if rpc.overlay.compression.accepts(addr) {
+				msg = compressedMsg
 			}
 
-			defer rpc.overlay.connMgr.Release(addr)
-
-			cl := ptypes.NewOverlayClient(clientConn)
-
-			header := metadata.New(map[string]string{headerURIKey: rpc.uri})
-			newCtx := metadata.NewOutgoingContext(ctx, header)
-
-			callResp, err := cl.Call(newCtx, sendMsg)
+			callResp, err := rpc.call(ctx, addr, msg)
 			if err != nil {
-				resp := mino.NewResponseWithError(
-					addr,
-					xerrors.Errorf("failed to call client: %v", err),
-				)
-
-				out <- resp
+				out <- mino.NewResponseWithError(addr, err)
 				return
 			}
 
@@ -91,7 +86,14 @@ func (rpc *RPC) Call(ctx context.Context,
 				return
 			}
 
-			resp, err := rpc.factory.Deserialize(rpc.overlay.context, callResp.GetPayload())
+			payload, err := serde.Decompress(rpc.overlay.context, callResp.GetPayload())
+			if err != nil {
+				out <- mino.NewResponseWithError(addr,
+					xerrors.Errorf("couldn't decompress payload: %v", err))
+				return
+			}
+
+			resp, err := rpc.factory.Deserialize(rpc.overlay.context, payload)
 			if err != nil {
 				resp := mino.NewResponseWithError(
 					addr,
@@ -114,6 +116,108 @@ func (rpc *RPC) Call(ctx context.Context,
 	return out, nil
 }
 
+// compress returns the message with its payload compressed, or the message
+// itself when none of the players announced the algorithm of the compressor.
+// The compressed message is only sent to the players that announced it.
+func (rpc *RPC) compress(msg *ptypes.Message, players mino.Players) (*ptypes.Message, error) {
+	if rpc.overlay.compressor == nil {
+		return msg, nil
+	}
+
+	accepted := false
+
+	iter := players.AddressIterator()
+	for iter.HasNext() && !accepted {
+		accepted = rpc.overlay.compression.accepts(iter.GetNext())
+	}
+
+	if !accepted {
+		return msg, nil
+	}
+
+	ctx := serde.WithCompression(rpc.overlay.context, rpc.overlay.compressor)
+
+	payload, err := serde.Compress(ctx, msg.GetPayload())
+	if err != nil {
+		return nil, err
+	}
+
+	compressed := &ptypes.Message{
+		From:    msg.GetFrom(),
+		Payload: payload,
+	}
+
+	return compressed, nil
+}
+
+// call contacts the player until it answers, or until the policy or the
+// context stops the attempts. A failed attempt is retried after a backoff only
+// when the player is unreachable or didn't answer before the timeout.
+func (rpc *RPC) call(ctx context.Context, addr mino.Address,
+	msg *ptypes.Message) (*ptypes.Message, error) {
+
+	for attempt := 0; ; attempt++ {
+		resp, transient, err := rpc.callOnce(ctx, addr, msg)
+		if err == nil || !transient || attempt >= rpc.policy.MaxRetries {
+			return resp, err
+		}
+
+		dela.Logger.Debug().
+			Stringer("to", addr).
+			Int("attempt", attempt+1).
+			Err(err).
+			Msg("retrying call")
+
+		if !sleep(ctx, rpc.policy.Delay(attempt)) {
+			return nil, err
+		}
+	}
+}
+
+// callOnce makes a single attempt to call the player. It returns true alongside
+// the error when the failure is worth a retry.
+func (rpc *RPC) callOnce(ctx context.Context, addr mino.Address,
+	msg *ptypes.Message) (*ptypes.Message, bool, error) {
+
+	clientConn, err := rpc.overlay.connMgr.Acquire(addr)
+	if err != nil {
+		return nil, true, xerrors.Errorf("failed to get client conn: %v", err)
+	}
+
+	defer rpc.overlay.connMgr.Release(addr, clientConn)
+
+	cl := ptypes.NewOverlayClient(clientConn)
+
+	header := metadata.New(map[string]string{
+		headerURIKey:                rpc.uri,
+		headerPayloadCompressionKey: announceCompression(),
+	})
+	newCtx := metadata.NewOutgoingContext(ctx, header)
+
+	release := rpc.overlay.scheduler.Acquire(ctx, addr, rpc.class)
+	defer release()
+
+	if rpc.policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		newCtx, cancel = context.WithTimeout(newCtx, rpc.policy.Timeout)
+		defer cancel()
+	}
+
+	respHeader := metadata.MD{}
+	opts := append(session.Compress(rpc.compression), grpc.Header(&respHeader))
+
+	resp, err := cl.Call(newCtx, msg, opts...)
+	if err != nil {
+		return nil, isTransient(ctx, err), xerrors.Errorf("failed to call client: %v", err)
+	}
+
+	if rpc.overlay.compressor != nil {
+		rpc.overlay.compression.learn(addr, respHeader, rpc.overlay.compressor.GetAlgorithm())
+	}
+
+	return resp, false, nil
+}
+
 // Stream implements mino.RPC. It will open a stream to one of the addresses
 // with a bidirectional channel that will send and receive packets. The chosen
 // address will open one or several streams to the rest of the players. The
@@ -156,6 +260,10 @@ func (rpc RPC) Stream(ctx context.Context, players mino.Players) (mino.Sender, m
 		md.Append(tracing.ProtocolTag, tracing.UndefinedProtocol)
 	}
 
+	if rpc.compression != mino.CompressionNone {
+		md.Append(session.CompressionKey, string(rpc.compression))
+	}
+
 	table, err := rpc.overlay.router.New(mino.NewAddresses(), rpc.overlay.myAddr)
 	if err != nil {
 		return nil, nil, xerrors.Errorf("routing table failed: %v", err)
@@ -172,29 +280,9 @@ func (rpc RPC) Stream(ctx context.Context, players mino.Players) (mino.Sender, m
 		md.Append(headerAddressKey, string(addr))
 	}
 
-	conn, err := rpc.overlay.connMgr.Acquire(gw)
-	if err != nil {
-		return nil, nil, xerrors.Errorf("gateway connection failed: %v", err)
-	}
-
-	client := ptypes.NewOverlayClient(conn)
-
-	ctx = metadata.NewOutgoingContext(ctx, md)
-
-	stream, err := client.Stream(ctx)
-	if err != nil {
-		rpc.overlay.connMgr.Release(gw)
-
-		return nil, nil, xerrors.Errorf("failed to open stream: %v", err)
-	}
-
-	// Wait for the event from the server to tell that the stream is
-	// initialized.
-	_, err = stream.Header()
+	conn, stream, cancel, err := rpc.openStream(ctx, gw, md)
 	if err != nil {
-		rpc.overlay.connMgr.Release(gw)
-
-		return nil, nil, xerrors.Errorf("failed to receive header: %v", err)
+		return nil, nil, err
 	}
 
 	relay := session.NewRelay(stream, gw, rpc.overlay.context, conn, md)
@@ -206,6 +294,10 @@ func (rpc RPC) Stream(ctx context.Context, players mino.Players) (mino.Sender, m
 		rpc.overlay.router.GetPacketFactory(),
 		rpc.overlay.context,
 		rpc.overlay.connMgr,
+		session.WithScheduler(rpc.overlay.scheduler, rpc.class),
+		session.WithMaxInFlight(rpc.overlay.maxInFlight),
+		session.WithFragmentSize(rpc.overlay.fragSize),
+		session.WithMemoryBudget(rpc.overlay.budget),
 	)
 
 	// There is no listen for the orchestrator as we need to forward the
@@ -217,7 +309,8 @@ func (rpc RPC) Stream(ctx context.Context, players mino.Players) (mino.Sender, m
 	go func() {
 		defer func() {
 			relay.Close()
-			rpc.overlay.connMgr.Release(gw)
+			cancel()
+			rpc.overlay.connMgr.Release(gw, conn)
 			rpc.overlay.closer.Done()
 		}()
 
@@ -238,6 +331,85 @@ func (rpc RPC) Stream(ctx context.Context, players mino.Players) (mino.Sender, m
 	return sess, sess, nil
 }
 
+// openStream opens the stream to the gateway according to the policy of the
+// RPC. The timeout only bounds the opening, as the stream then lives as long as
+// the protocol. The returned function must be called when the stream is done.
+func (rpc RPC) openStream(ctx context.Context, gw mino.Address,
+	md metadata.MD) (grpc.ClientConnInterface, ptypes.Overlay_StreamClient, context.CancelFunc, error) {
+
+	for attempt := 0; ; attempt++ {
+		conn, stream, cancel, transient, err := rpc.openStreamOnce(ctx, gw, md)
+		if err == nil {
+			return conn, stream, cancel, nil
+		}
+
+		if !transient || attempt >= rpc.policy.MaxRetries {
+			return nil, nil, nil, err
+		}
+
+		dela.Logger.Debug().
+			Stringer("to", gw).
+			Int("attempt", attempt+1).
+			Err(err).
+			Msg("retrying stream")
+
+		if !sleep(ctx, rpc.policy.Delay(attempt)) {
+			return nil, nil, nil, err
+		}
+	}
+}
+
+// openStreamOnce makes a single attempt to open the stream to the gateway. It
+// returns true alongside the error when the failure is worth a retry.
+func (rpc RPC) openStreamOnce(ctx context.Context, gw mino.Address,
+	md metadata.MD) (grpc.ClientConnInterface, ptypes.Overlay_StreamClient,
+	context.CancelFunc, bool, error) {
+
+	conn, err := rpc.overlay.connMgr.Acquire(gw)
+	if err != nil {
+		return nil, nil, nil, true, xerrors.Errorf("gateway connection failed: %v", err)
+	}
+
+	client := ptypes.NewOverlayClient(conn)
+
+	streamCtx, cancel := context.WithCancel(metadata.NewOutgoingContext(ctx, md))
+
+	fail := func(format string, err error) (grpc.ClientConnInterface,
+		ptypes.Overlay_StreamClient, context.CancelFunc, bool, error) {
+
+		cancel()
+		rpc.overlay.connMgr.Release(gw, conn)
+
+		return nil, nil, nil, isTransient(ctx, err), xerrors.Errorf(format, err)
+	}
+
+	var timer *time.Timer
+	if rpc.policy.Timeout > 0 {
+		timer = time.AfterFunc(rpc.policy.Timeout, cancel)
+	}
+
+	stream, err := client.Stream(streamCtx, session.Compress(rpc.compression)...)
+	if err != nil {
+		return fail("failed to open stream: %v", err)
+	}
+
+	// Wait for the event from the server to tell that the stream is
+	// initialized.
+	_, err = stream.Header()
+	if err != nil {
+		return fail("failed to receive header: %v", err)
+	}
+
+	if timer != nil && !timer.Stop() {
+		// The timeout expired right after the header, which means the stream
+		// is already canceled.
+		return fail("failed to receive header: %v",
+			status.Error(codes.DeadlineExceeded, "timeout expired"))
+	}
+
+	return conn, stream, cancel, false, nil
+}
+
 func (rpc RPC) findGateway(players mino.Players) (mino.Address, []mino.Address) {
 	iter := players.AddressIterator()
 	addrs := make([]mino.Address, 0, players.Len())
@@ -260,3 +432,33 @@ func (rpc RPC) findGateway(players mino.Players) (mino.Address, []mino.Address)
 
 	return gw, addrs
 }
+
+// isTransient returns true if the error of an attempt is worth a retry, which
+// is the case when the distant peer is unreachable or didn't answer in time,
+// as long as the context of the caller is not done.
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep waits for the delay and returns true, or it returns false if the
+// context is done before.
+func sleep(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/internal/testing/fake"
@@ -16,7 +17,9 @@ import (
 	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/dela/serde/json"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func TestRPC_Call(t *testing.T) {
@@ -152,6 +155,89 @@ func TestRPC_FailDeserialize_Call(t *testing.T) {
 	require.EqualError(t, err, fake.Err("couldn't unmarshal payload"))
 }
 
+func TestRPC_Policy_Call(t *testing.T) {
+	calls := fake.NewCall()
+
+	rpc := &RPC{
+		factory: fake.MessageFactory{},
+		overlay: &overlay{
+			connMgr: fakeConnMgr{
+				errConn: status.Error(codes.Unavailable, "oops"),
+				calls:   calls,
+			},
+			context: json.NewContext(),
+		},
+		policy: mino.Policy{MaxRetries: 2, Backoff: time.Millisecond},
+	}
+
+	addrs := mino.NewAddresses(session.NewAddress(""))
+
+	msgs, err := rpc.Call(context.Background(), fake.Message{}, addrs)
+	require.NoError(t, err)
+
+	msg := <-msgs
+	_, err = msg.GetMessageOrError()
+	require.EqualError(t, err,
+		"failed to call client: rpc error: code = Unavailable desc = oops")
+	require.Equal(t, 6, calls.Len())
+
+	// The errors of the handler are not retried.
+	calls.Clear()
+	rpc.overlay.connMgr = fakeConnMgr{errConn: fake.GetError(), calls: calls}
+
+	msgs, err = rpc.Call(context.Background(), fake.Message{}, addrs)
+	require.NoError(t, err)
+
+	msg = <-msgs
+	_, err = msg.GetMessageOrError()
+	require.EqualError(t, err, fake.Err("failed to call client"))
+	require.Equal(t, 2, calls.Len())
+
+	// The context stops the retries.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls.Clear()
+	rpc.overlay.connMgr = fakeConnMgr{err: fake.GetError(), calls: calls}
+	rpc.policy.Backoff = time.Hour
+
+	msgs, err = rpc.Call(ctx, fake.Message{}, addrs)
+	require.NoError(t, err)
+
+	msg = <-msgs
+	_, err = msg.GetMessageOrError()
+	require.EqualError(t, err, fake.Err("failed to get client conn"))
+	require.Equal(t, 1, calls.Len())
+}
+
+func TestRPC_Timeout_Call(t *testing.T) {
+	calls := fake.NewCall()
+
+	rpc := &RPC{
+		factory: fake.MessageFactory{},
+		overlay: &overlay{
+			connMgr: fakeConnMgr{block: true, calls: calls},
+			context: json.NewContext(),
+		},
+		policy: mino.Policy{Timeout: 10 * time.Millisecond, MaxRetries: 1},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	addrs := mino.NewAddresses(session.NewAddress(""))
+
+	msgs, err := rpc.Call(ctx, fake.Message{}, addrs)
+	require.NoError(t, err)
+
+	msg := <-msgs
+	_, err = msg.GetMessageOrError()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "code = DeadlineExceeded")
+	require.Equal(t, 4, calls.Len())
+	require.NoError(t, ctx.Err())
+}
+
 func TestRPC_Stream(t *testing.T) {
 	addrs := []mino.Address{session.NewAddress("A"), session.NewAddress("B")}
 	calls := &fake.Call{}
@@ -294,6 +380,53 @@ func TestRPC_BadStream_Stream(t *testing.T) {
 	require.Equal(t, "release", calls.Get(1, 0))
 }
 
+func TestRPC_Policy_Stream(t *testing.T) {
+	calls := fake.NewCall()
+
+	rpc := &RPC{
+		overlay: &overlay{
+			router: tree.NewRouter(addressFac),
+			connMgr: fakeConnMgr{
+				errStream: status.Error(codes.Unavailable, "oops"),
+				calls:     calls,
+			},
+		},
+		policy: mino.Policy{Timeout: time.Minute, MaxRetries: 1},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addrs := mino.NewAddresses(session.NewAddress(""))
+
+	_, _, err := rpc.Stream(ctx, addrs)
+	require.EqualError(t, err,
+		"failed to receive header: rpc error: code = Unavailable desc = oops")
+	require.Equal(t, 4, calls.Len())
+	require.Equal(t, "release", calls.Get(3, 0))
+}
+
+func TestIsTransient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	require.True(t, isTransient(ctx, status.Error(codes.Unavailable, "")))
+	require.True(t, isTransient(ctx, status.Error(codes.DeadlineExceeded, "")))
+	require.False(t, isTransient(ctx, status.Error(codes.Unknown, "")))
+	require.False(t, isTransient(ctx, fake.GetError()))
+
+	cancel()
+	require.False(t, isTransient(ctx, status.Error(codes.Unavailable, "")))
+}
+
+func TestSleep(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	require.True(t, sleep(ctx, time.Millisecond))
+
+	cancel()
+	require.False(t, sleep(ctx, time.Hour))
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
@@ -344,6 +477,7 @@ type fakeConnection struct {
 	grpc.ClientConnInterface
 	resp      interface{}
 	empty     bool
+	block     bool
 	err       error
 	errStream error
 }
@@ -351,6 +485,11 @@ type fakeConnection struct {
 func (conn fakeConnection) Invoke(ctx context.Context, m string, arg interface{},
 	resp interface{}, opts ...grpc.CallOption) error {
 
+	if conn.block {
+		<-ctx.Done()
+		return status.Error(codes.DeadlineExceeded, ctx.Err().Error())
+	}
+
 	if conn.empty {
 		return conn.err
 	}
@@ -385,6 +524,7 @@ type fakeConnMgr struct {
 	session.ConnectionManager
 	resp      interface{}
 	empty     bool
+	block     bool
 	len       int
 	calls     *fake.Call
 	err       error
@@ -401,6 +541,7 @@ func (f fakeConnMgr) Acquire(addr mino.Address) (grpc.ClientConnInterface, error
 
 	conn := fakeConnection{
 		empty:     f.empty,
+		block:     f.block,
 		resp:      f.resp,
 		err:       f.errConn,
 		errStream: f.errStream,
@@ -409,7 +550,7 @@ func (f fakeConnMgr) Acquire(addr mino.Address) (grpc.ClientConnInterface, error
 	return conn, f.err
 }
 
-func (f fakeConnMgr) Release(addr mino.Address) {
+func (f fakeConnMgr) Release(addr mino.Address, conn grpc.ClientConnInterface) {
 	f.calls.Add("release", addr)
 }
 
@@ -0,0 +1,290 @@
+// Package scores defines a scoreboard that keeps track of the behaviour of the
+// peers of the overlay, and bans the ones that misbehave.
+//
+// A peer starts with the maximum score. Each offense, like a malformed packet
+// or an invalid signature, reduces the score of the peer by a penalty that
+// depends on its severity. The penalties decay over time so that a peer
+// recovers from occasional mistakes. When the score falls below the threshold,
+// the peer is banned for a period of time, after which it starts again with
+// the maximum score.
+//
+// The package also provides an in-memory implementation.
+package scores
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/mino"
+)
+
+// Offense is the type of misbehaviour that can be reported for a peer.
+type Offense int
+
+const (
+	// MalformedPacket is reported when a peer sends data that cannot be
+	// decoded.
+	MalformedPacket Offense = iota
+
+	// ProtocolViolation is reported when a peer does not follow the protocol,
+	// like a stream opened with invalid headers.
+	ProtocolViolation
+
+	// InvalidSignature is reported when a peer sends a message or a
+	// certificate with a signature that does not verify.
+	InvalidSignature
+)
+
+// String implements fmt.Stringer. It returns a human-readable name of the
+// offense.
+func (o Offense) String() string {
+	switch o {
+	case MalformedPacket:
+		return "malformed packet"
+	case ProtocolViolation:
+		return "protocol violation"
+	case InvalidSignature:
+		return "invalid signature"
+	default:
+		return "unknown offense"
+	}
+}
+
+const (
+	// MaxScore is the score of a peer without any offense.
+	MaxScore = 100.0
+
+	// DefaultThreshold is the default score below which a peer is banned.
+	DefaultThreshold = 0.0
+
+	// DefaultHalfLife is the default amount of time after which a penalty is
+	// reduced by half.
+	DefaultHalfLife = 10 * time.Minute
+
+	// DefaultBanDuration is the default amount of time a peer is banned for.
+	DefaultBanDuration = 30 * time.Minute
+)
+
+// Peer is the state of a peer in the scoreboard.
+type Peer struct {
+	Address mino.Address
+
+	// Score is the current score of the peer, between the threshold and the
+	// maximum score.
+	Score float64
+
+	// Offenses is the total number of offenses reported for the peer.
+	Offenses int
+
+	// BannedUntil is the time at which the ban of the peer expires, or the
+	// zero value if the peer is not banned.
+	BannedUntil time.Time
+}
+
+// Board is the scoreboard of the peers.
+type Board interface {
+	// Report reduces the score of the peer according to the offense. It
+	// returns true if the peer has been banned as a result.
+	Report(addr mino.Address, offense Offense) bool
+
+	// IsBanned returns true if the peer is currently banned.
+	IsBanned(addr mino.Address) bool
+
+	// Unban lifts the ban of the peer and restores its score.
+	Unban(addr mino.Address)
+
+	// Range iterates over the peers with a score below the maximum, or a ban,
+	// until the callback returns false.
+	Range(fn func(peer Peer) bool)
+}
+
+// Option is the type of option to configure the scoreboard.
+type Option func(*InMemoryBoard)
+
+// WithThreshold sets the score below which a peer is banned.
+func WithThreshold(threshold float64) Option {
+	return func(b *InMemoryBoard) {
+		b.threshold = threshold
+	}
+}
+
+// WithHalfLife sets the amount of time after which a penalty is reduced by
+// half. A zero value disables the decay.
+func WithHalfLife(d time.Duration) Option {
+	return func(b *InMemoryBoard) {
+		b.halfLife = d
+	}
+}
+
+// WithBanDuration sets the amount of time a peer is banned for.
+func WithBanDuration(d time.Duration) Option {
+	return func(b *InMemoryBoard) {
+		b.banDuration = d
+	}
+}
+
+// WithPenalty sets the penalty of an offense.
+func WithPenalty(offense Offense, penalty float64) Option {
+	return func(b *InMemoryBoard) {
+		b.penalties[offense] = penalty
+	}
+}
+
+// record is the internal state of a peer.
+type record struct {
+	addr        mino.Address
+	penalty     float64
+	updated     time.Time
+	offenses    int
+	bannedUntil time.Time
+}
+
+// InMemoryBoard is a scoreboard that keeps the scores in memory.
+//
+// - implements scores.Board
+type InMemoryBoard struct {
+	sync.Mutex
+
+	threshold   float64
+	halfLife    time.Duration
+	banDuration time.Duration
+	penalties   map[Offense]float64
+	records     map[string]*record
+	now         func() time.Time
+}
+
+// NewInMemoryBoard creates a new empty scoreboard.
+func NewInMemoryBoard(opts ...Option) *InMemoryBoard {
+	b := &InMemoryBoard{
+		threshold:   DefaultThreshold,
+		halfLife:    DefaultHalfLife,
+		banDuration: DefaultBanDuration,
+		penalties: map[Offense]float64{
+			MalformedPacket:   10,
+			ProtocolViolation: 25,
+			InvalidSignature:  50,
+		},
+		records: make(map[string]*record),
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Report implements scores.Board. It applies the penalty of the offense to the
+// peer and bans it if the score falls below the threshold.
+func (b *InMemoryBoard) Report(addr mino.Address, offense Offense) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	now := b.now()
+
+	rec, found := b.records[addr.String()]
+	if !found {
+		rec = &record{addr: addr, updated: now}
+		b.records[addr.String()] = rec
+	}
+
+	rec.offenses++
+
+	if rec.bannedUntil.After(now) {
+		return false
+	}
+
+	b.decay(rec, now)
+	rec.penalty += b.penalties[offense]
+
+	dela.Logger.Debug().
+		Stringer("peer", addr).
+		Stringer("offense", offense).
+		Float64("score", MaxScore-rec.penalty).
+		Msg("offense reported")
+
+	if MaxScore-rec.penalty >= b.threshold {
+		return false
+	}
+
+	rec.bannedUntil = now.Add(b.banDuration)
+	rec.penalty = 0
+
+	dela.Logger.Warn().
+		Stringer("peer", addr).
+		Time("until", rec.bannedUntil).
+		Msg("peer is banned")
+
+	return true
+}
+
+// IsBanned implements scores.Board. It returns true if the ban of the peer has
+// not expired yet.
+func (b *InMemoryBoard) IsBanned(addr mino.Address) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	rec, found := b.records[addr.String()]
+	if !found {
+		return false
+	}
+
+	return rec.bannedUntil.After(b.now())
+}
+
+// Unban implements scores.Board. It removes the peer from the scoreboard.
+func (b *InMemoryBoard) Unban(addr mino.Address) {
+	b.Lock()
+	delete(b.records, addr.String())
+	b.Unlock()
+}
+
+// Range implements scores.Board. It iterates over the peers and removes the
+// ones that have fully recovered.
+func (b *InMemoryBoard) Range(fn func(peer Peer) bool) {
+	b.Lock()
+	defer b.Unlock()
+
+	now := b.now()
+
+	for key, rec := range b.records {
+		b.decay(rec, now)
+
+		banned := rec.bannedUntil.After(now)
+
+		// A penalty is never exactly zero after a decay, so the peers are
+		// forgotten after their penalty is negligible.
+		if !banned && rec.penalty < 0.01 {
+			delete(b.records, key)
+			continue
+		}
+
+		peer := Peer{
+			Address:  rec.addr,
+			Score:    MaxScore - rec.penalty,
+			Offenses: rec.offenses,
+		}
+
+		if banned {
+			peer.BannedUntil = rec.bannedUntil
+		}
+
+		if !fn(peer) {
+			return
+		}
+	}
+}
+
+func (b *InMemoryBoard) decay(rec *record, now time.Time) {
+	elapsed := now.Sub(rec.updated)
+	rec.updated = now
+
+	if b.halfLife <= 0 || elapsed <= 0 {
+		return
+	}
+
+	rec.penalty *= math.Pow(0.5, float64(elapsed)/float64(b.halfLife))
+}
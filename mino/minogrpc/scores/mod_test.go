@@ -0,0 +1,137 @@
+package scores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestOffense_String(t *testing.T) {
+	require.Equal(t, "malformed packet", MalformedPacket.String())
+	require.Equal(t, "protocol violation", ProtocolViolation.String())
+	require.Equal(t, "invalid signature", InvalidSignature.String())
+	require.Equal(t, "unknown offense", Offense(-1).String())
+}
+
+func TestInMemoryBoard_Report(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+
+	board := NewInMemoryBoard(
+		WithThreshold(25),
+		WithHalfLife(0),
+		WithBanDuration(time.Minute),
+		WithPenalty(MalformedPacket, 20),
+	)
+	board.now = clock.Now
+
+	addr := fake.NewAddress(0)
+
+	require.False(t, board.Report(addr, MalformedPacket))
+	require.False(t, board.Report(addr, InvalidSignature))
+	require.False(t, board.IsBanned(addr))
+	require.False(t, board.IsBanned(fake.NewAddress(1)))
+
+	require.True(t, board.Report(addr, MalformedPacket))
+	require.True(t, board.IsBanned(addr))
+
+	// Offenses during a ban are counted but do not extend it.
+	require.False(t, board.Report(addr, InvalidSignature))
+	require.Equal(t, 4, board.records[addr.String()].offenses)
+
+	clock.now = clock.now.Add(time.Minute)
+	require.False(t, board.IsBanned(addr))
+	require.False(t, board.Report(addr, MalformedPacket))
+}
+
+func TestInMemoryBoard_Decay(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+
+	board := NewInMemoryBoard(WithThreshold(10), WithHalfLife(time.Minute))
+	board.now = clock.Now
+
+	addr := fake.NewAddress(0)
+
+	board.Report(addr, InvalidSignature)
+	require.Equal(t, 50.0, getPeer(t, board, addr).Score)
+
+	clock.now = clock.now.Add(time.Minute)
+	require.Equal(t, 75.0, getPeer(t, board, addr).Score)
+
+	// Two offenses in a row would ban the peer, but not after the decay.
+	require.False(t, board.Report(addr, InvalidSignature))
+
+	clock.now = clock.now.Add(time.Hour)
+
+	count := 0
+	board.Range(func(Peer) bool {
+		count++
+		return true
+	})
+	require.Equal(t, 0, count)
+	require.Empty(t, board.records)
+}
+
+func TestInMemoryBoard_Unban(t *testing.T) {
+	board := NewInMemoryBoard(WithThreshold(MaxScore))
+
+	addr := fake.NewAddress(0)
+
+	require.True(t, board.Report(addr, MalformedPacket))
+	require.True(t, board.IsBanned(addr))
+
+	board.Unban(addr)
+	require.False(t, board.IsBanned(addr))
+}
+
+func TestInMemoryBoard_Range(t *testing.T) {
+	board := NewInMemoryBoard(WithThreshold(80))
+
+	board.Report(fake.NewAddress(0), MalformedPacket)
+	board.Report(fake.NewAddress(1), ProtocolViolation)
+
+	peers := map[string]Peer{}
+	board.Range(func(peer Peer) bool {
+		peers[peer.Address.String()] = peer
+		return true
+	})
+
+	require.Len(t, peers, 2)
+	require.True(t, peers[fake.NewAddress(0).String()].BannedUntil.IsZero())
+	require.InDelta(t, 90.0, peers[fake.NewAddress(0).String()].Score, 0.1)
+	require.False(t, peers[fake.NewAddress(1).String()].BannedUntil.IsZero())
+	require.Equal(t, 1, peers[fake.NewAddress(1).String()].Offenses)
+
+	count := 0
+	board.Range(func(Peer) bool {
+		count++
+		return false
+	})
+	require.Equal(t, 1, count)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func getPeer(t *testing.T, board *InMemoryBoard, addr fake.Address) Peer {
+	var res *Peer
+	board.Range(func(peer Peer) bool {
+		if peer.Address.Equal(addr) {
+			res = &peer
+		}
+		return true
+	})
+
+	require.NotNil(t, res)
+
+	return *res
+}
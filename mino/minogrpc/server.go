@@ -7,8 +7,11 @@
 package minogrpc
 
 import (
+	"bytes"
 	"context"
+	stdcrypto "crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
@@ -18,10 +21,14 @@ import (
 	"time"
 
 	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/memory"
+	"go.dedis.ch/dela/crypto"
 	"go.dedis.ch/dela/internal/tracing"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/minogrpc/certs"
 	"go.dedis.ch/dela/mino/minogrpc/ptypes"
+	"go.dedis.ch/dela/mino/minogrpc/resolver"
+	"go.dedis.ch/dela/mino/minogrpc/scores"
 	"go.dedis.ch/dela/mino/minogrpc/session"
 	"go.dedis.ch/dela/mino/minogrpc/tokens"
 	"go.dedis.ch/dela/mino/router"
@@ -30,8 +37,11 @@ import (
 	"golang.org/x/xerrors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/stats"
 
 	otgrpc "github.com/opentracing-contrib/go-grpc"
 	"github.com/opentracing/opentracing-go"
@@ -51,6 +61,18 @@ const (
 	// defaultMinConnectTimeout is the minimum amount of time we are willing to
 	// wait for a grpc connection to complete
 	defaultMinConnectTimeout = 10 * time.Second
+
+	// failoverTimeout is the amount of time to wait for a host of a
+	// multi-homed address to be reachable before trying the next one.
+	failoverTimeout = 2 * time.Second
+
+	// failoverCooldown is the amount of time a host of a multi-homed address
+	// is tried last after it has failed.
+	failoverCooldown = 30 * time.Second
+
+	// resolveTimeout is the amount of time to wait for the hosts of an address
+	// to be resolved.
+	resolveTimeout = 5 * time.Second
 )
 
 var getTracerForAddr = tracing.GetTracerForAddr
@@ -63,20 +85,29 @@ type overlayServer struct {
 
 // Join implements ptypes.OverlayServer. It processes the request by checking
 // the validity of the token and if it is accepted, by sending the certificate
-// to the known peers. It finally returns the certificates to the caller.
+// to the known peers. It finally returns the certificates to the caller. A node
+// joining with an observer token is only known by this node, therefore it only
+// receives its certificate.
 func (o overlayServer) Join(ctx context.Context, req *ptypes.JoinRequest) (*ptypes.JoinResponse, error) {
 	// 1. Check validity of the token.
-	if !o.tokens.Verify(req.Token) {
-		return nil, xerrors.Errorf("token '%s' is invalid", req.Token)
+	token, err := o.tokens.Verify(req.Token)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid token: %v", err)
 	}
 
 	dela.Logger.Debug().
 		Str("from", string(req.GetCertificate().GetAddress())).
+		Str("token", token.ID).
+		Str("scope", string(token.Scope)).
 		Msg("valid token received")
 
 	// 2. Share certificates to current participants.
 	list := make(map[mino.Address][]byte)
 	o.certs.Range(func(addr mino.Address, cert *tls.Certificate) bool {
+		if token.Scope == tokens.ScopeObserver && !addr.Equal(o.myAddr) {
+			return true
+		}
+
 		list[addr] = cert.Leaf.Raw
 		return true
 	})
@@ -103,7 +134,7 @@ func (o overlayServer) Join(ctx context.Context, req *ptypes.JoinRequest) (*ptyp
 				return
 			}
 
-			defer o.connMgr.Release(to)
+			defer o.connMgr.Release(to, conn)
 
 			client := ptypes.NewOverlayClient(conn)
 
@@ -144,17 +175,25 @@ func (o overlayServer) Share(ctx context.Context, msg *ptypes.Certificate) (*pty
 	// Make sure the certificate is valid for the public key provided.
 	err = cert.CheckSignatureFrom(cert)
 	if err != nil {
+		// The peer relaying the certificate is to blame, but only when it is
+		// authenticated as the address in the message can be anything.
+		o.report(ctx, scores.InvalidSignature)
+
 		return nil, xerrors.Errorf("invalid certificate signature: %v", err)
 	}
 
-	hostname, err := from.GetHostname()
+	hostnames, err := from.GetHostnames()
 	if err != nil {
 		return nil, xerrors.Errorf("malformed address: %v", err)
 	}
 
-	err = cert.VerifyHostname(hostname)
-	if err != nil {
-		return nil, xerrors.Errorf("invalid hostname: %v", err)
+	// Every advertised host must be covered by the certificate so that the
+	// connection can fail over to any of them.
+	for _, hostname := range hostnames {
+		err = cert.VerifyHostname(hostname)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid hostname: %v", err)
+		}
 	}
 
 	o.certs.Store(from, &tls.Certificate{
@@ -177,12 +216,35 @@ func (o overlayServer) Call(ctx context.Context, msg *ptypes.Message) (*ptypes.M
 		return nil, xerrors.Errorf("handler '%s' is not registered", uri)
 	}
 
-	message, err := endpoint.Factory.Deserialize(o.context, msg.GetPayload())
+	banned, ok := o.isBanned(ctx)
+	if ok {
+		return nil, xerrors.Errorf("request refused: peer %v is banned", banned)
+	}
+
+	from := o.addrFactory.FromText(msg.GetFrom())
+
+	// The answer announces the algorithms so that the caller compresses its
+	// next requests. It fails only outside of a gRPC call.
+	grpc.SetHeader(ctx, metadata.Pairs(headerPayloadCompressionKey, announceCompression()))
+
+	payload, err := serde.Decompress(o.context, msg.GetPayload())
 	if err != nil {
+		o.report(ctx, scores.MalformedPacket)
+
+		return nil, xerrors.Errorf("couldn't decompress message: %v", err)
+	}
+
+	message, err := endpoint.Factory.Deserialize(o.context, payload)
+	if err != nil {
+		o.report(ctx, scores.MalformedPacket)
+
 		return nil, xerrors.Errorf("couldn't deserialize message: %v", err)
 	}
 
-	from := o.addrFactory.FromText(msg.GetFrom())
+	err = mino.CheckScope(endpoint.Handler, from)
+	if err != nil {
+		return nil, xerrors.Errorf("request refused: %v", err)
+	}
 
 	req := mino.Request{
 		Address: from,
@@ -203,6 +265,15 @@ func (o overlayServer) Call(ctx context.Context, msg *ptypes.Message) (*ptypes.M
 		return nil, xerrors.Errorf("couldn't serialize result: %v", err)
 	}
 
+	headers, _ := metadata.FromIncomingContext(ctx)
+
+	if o.compressor != nil && acceptsCompression(headers, o.compressor.GetAlgorithm()) {
+		res, err = serde.Compress(serde.WithCompression(o.context, o.compressor), res)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't compress result: %v", err)
+		}
+	}
+
 	return &ptypes.Message{Payload: res}, nil
 }
 
@@ -228,13 +299,34 @@ func (o *overlayServer) Stream(stream ptypes.Overlay_StreamServer) error {
 		return xerrors.New("missing headers")
 	}
 
+	uri, streamID, gateway, protocol := readHeaders(headers)
+
+	banned, ok := o.isBanned(stream.Context())
+	if ok {
+		return xerrors.Errorf("stream refused: peer %v is banned", banned)
+	}
+
+	isRelay, err := o.relay(stream, headers)
+	if isRelay {
+		return err
+	}
+
+	gatewayAddr := o.addrFactory.FromText([]byte(gateway))
+
 	table, isRoot, err := o.tableFromHeaders(headers)
 	if err != nil {
+		// Only the handshake comes from the peer, while the failure of the
+		// root is local.
+		if !isRoot {
+			o.report(stream.Context(), scores.ProtocolViolation)
+		}
+
 		return xerrors.Errorf("routing table: %v", err)
 	}
 
-	uri, streamID, gateway, protocol := readHeaders(headers)
 	if streamID == "" {
+		o.report(stream.Context(), scores.ProtocolViolation)
+
 		return xerrors.New("unexpected empty stream ID")
 	}
 
@@ -250,6 +342,12 @@ func (o *overlayServer) Stream(stream ptypes.Overlay_StreamServer) error {
 		tracing.ProtocolTag, protocol,
 	)
 
+	// The relays of the stream use the compression of the orchestrator.
+	compression := getOrEmpty(headers, session.CompressionKey)
+	if compression != "" {
+		md.Set(session.CompressionKey, compression)
+	}
+
 	// This lock will make sure that a session is ready before any message
 	// forwarded will be received.
 	endpoint.Lock()
@@ -263,13 +361,15 @@ func (o *overlayServer) Stream(stream ptypes.Overlay_StreamServer) error {
 			o.router.GetPacketFactory(),
 			o.context,
 			o.connMgr,
+			session.WithScheduler(o.scheduler, mino.ClassOf(endpoint.Handler)),
+			session.WithMaxInFlight(o.maxInFlight),
+			session.WithFragmentSize(o.fragSize),
+			session.WithMemoryBudget(o.budget),
 		)
 
 		endpoint.streams[streamID] = sess
 	}
 
-	gatewayAddr := o.addrFactory.FromText([]byte(gateway))
-
 	var relay session.Relay
 	var conn grpc.ClientConnInterface
 	if isRoot {
@@ -288,7 +388,7 @@ func (o *overlayServer) Stream(stream ptypes.Overlay_StreamServer) error {
 			return xerrors.Errorf("gateway connection failed: %v", err)
 		}
 
-		defer o.connMgr.Release(gatewayAddr)
+		defer o.connMgr.Release(gatewayAddr, conn)
 
 		relay = session.NewRelay(stream, gatewayAddr, o.context, conn, md)
 	}
@@ -317,7 +417,7 @@ func (o *overlayServer) Stream(stream ptypes.Overlay_StreamServer) error {
 		return xerrors.Errorf("failed to send header: %v", err)
 	}
 
-	err = endpoint.Handler.Stream(sess, sess)
+	err = endpoint.Handler.Stream(sess, mino.ScopeReceiver(endpoint.Handler, sess))
 	if err != nil {
 		return xerrors.Errorf("handler failed to process: %v", err)
 	}
@@ -392,6 +492,13 @@ func (o *overlayServer) Forward(ctx context.Context, p *ptypes.Packet) (*ptypes.
 
 	uri, streamID, gateway, _ := readHeaders(headers)
 
+	banned, ok := o.isBanned(ctx)
+	if ok {
+		return nil, xerrors.Errorf("packet dropped: peer %v is banned", banned)
+	}
+
+	from := o.addrFactory.FromText([]byte(gateway))
+
 	endpoint, found := o.endpoints[uri]
 	if !found {
 		return nil, xerrors.Errorf("handler '%s' is not registered", uri)
@@ -405,9 +512,19 @@ func (o *overlayServer) Forward(ctx context.Context, p *ptypes.Packet) (*ptypes.
 		return nil, xerrors.Errorf("no stream '%s' found", streamID)
 	}
 
-	from := o.addrFactory.FromText([]byte(gateway))
+	ack, err := sess.RecvPacket(from, p)
+	if err != nil {
+		// The packet is decoded again only when it has been refused, to find
+		// out if the peer is to blame.
+		_, errPkt := o.router.GetPacketFactory().PacketOf(o.context, p.GetSerialized())
+		if errPkt != nil {
+			o.report(ctx, scores.MalformedPacket)
+		}
+
+		return nil, err
+	}
 
-	return sess.RecvPacket(from, p)
+	return ack, nil
 }
 
 type overlay struct {
@@ -419,11 +536,23 @@ type overlay struct {
 	router      router.Router
 	connMgr     session.ConnectionManager
 	addrFactory mino.AddressFactory
+	scores      scores.Board
+	resolver    resolver.Resolver
+	scheduler   *session.Scheduler
+	metrics     *mino.Metrics
+	maxInFlight int
+	fragSize    int
+	budget      *memory.Budget
+	relays      *relayTable
+	compressor  serde.Compressor
+	compression compressionTable
 
 	// secret and public are the key pair that has generated the server
-	// certificate.
-	secret interface{}
-	public interface{}
+	// certificate. The lock protects them, and the certificate of the server,
+	// as they can be replaced while the server is running.
+	certLock sync.Mutex
+	secret   interface{}
+	public   interface{}
 
 	// Keep a text marshalled value for the overlay address so that it's not
 	// calculated for each request.
@@ -434,6 +563,13 @@ func newOverlay(tmpl minoTemplate) (*overlay, error) {
 	// session.Address never returns an error
 	myAddrBuf, _ := tmpl.myAddr.MarshalText()
 
+	// The key of the certificate bootstraps the secure channels, therefore a
+	// key reserved for another usage is refused.
+	err := crypto.CheckUsage(tmpl.secret, crypto.TransportUsage)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid certificate key: %v", err)
+	}
+
 	if tmpl.secret == nil || tmpl.public == nil {
 		priv, err := ecdsa.GenerateKey(tmpl.curve, tmpl.random)
 		if err != nil {
@@ -444,16 +580,38 @@ func newOverlay(tmpl minoTemplate) (*overlay, error) {
 		tmpl.public = priv.Public()
 	}
 
+	// The tokens are signed by a key of the instance, therefore they are no
+	// longer valid after a restart.
+	_, tokenKey, err := ed25519.GenerateKey(tmpl.random)
+	if err != nil {
+		return nil, xerrors.Errorf("token key: %v", err)
+	}
+
+	metrics := mino.NewMetrics()
+
+	connMgr := newConnManager(tmpl.myAddr, tmpl.certs, tmpl.resolver)
+	connMgr.stats = statsHandler{metrics: metrics}
+	connMgr.limit = tmpl.maxConns
+
 	o := &overlay{
 		closer:      new(sync.WaitGroup),
-		context:     json.NewContext(),
+		context:     json.NewContext(json.WithLimits(serde.Limits{MaxSize: MaxPayloadSize})),
 		myAddr:      tmpl.myAddr,
 		myAddrStr:   string(myAddrBuf),
-		tokens:      tokens.NewInMemoryHolder(),
+		tokens:      tokens.NewSignedHolder(tokenKey),
 		certs:       tmpl.certs,
 		router:      tmpl.router,
-		connMgr:     newConnManager(tmpl.myAddr, tmpl.certs),
+		connMgr:     connMgr,
 		addrFactory: tmpl.fac,
+		scores:      tmpl.scores,
+		resolver:    tmpl.resolver,
+		scheduler:   session.NewScheduler(session.DefaultMaxDelay),
+		metrics:     metrics,
+		maxInFlight: tmpl.maxInFlight,
+		fragSize:    tmpl.fragmentSize,
+		budget:      tmpl.budget,
+		relays:      connMgr.relays,
+		compressor:  tmpl.compressor,
 		secret:      tmpl.secret,
 		public:      tmpl.public,
 	}
@@ -476,6 +634,9 @@ func newOverlay(tmpl minoTemplate) (*overlay, error) {
 // GetCertificate returns the certificate of the overlay with its private key
 // set.
 func (o *overlay) GetCertificate() *tls.Certificate {
+	o.certLock.Lock()
+	defer o.certLock.Unlock()
+
 	me, err := o.certs.Load(o.myAddr)
 	if err != nil {
 		// An error when getting the certificate of the server is caused by the
@@ -494,21 +655,260 @@ func (o *overlay) GetCertificate() *tls.Certificate {
 	return me
 }
 
+// UpdateCertificate replaces the certificate of the overlay and its private
+// key. The connections already opened are kept, whereas the new ones use the
+// new certificate, which is shared with the known peers so that they accept it.
+// The certificate is used even if some peers could not be reached, in which
+// case it can be shared again by calling the function one more time.
+//
+// The certificate must be self-signed by the key, and it must be valid for
+// every host of the address. The key is not persisted, which is the
+// responsibility of the caller when the storage of the certificates is.
+func (o *overlay) UpdateCertificate(cert *x509.Certificate, secret interface{}) error {
+	err := crypto.CheckUsage(secret, crypto.TransportUsage)
+	if err != nil {
+		return xerrors.Errorf("invalid certificate key: %v", err)
+	}
+
+	err = o.checkCertificate(cert, secret)
+	if err != nil {
+		return xerrors.Errorf("invalid certificate: %v", err)
+	}
+
+	o.certLock.Lock()
+
+	err = o.certs.Store(o.myAddr, &tls.Certificate{
+		Certificate: [][]byte{cert.Raw},
+		PrivateKey:  secret,
+		Leaf:        cert,
+	})
+	if err == nil {
+		o.secret = secret
+		o.public = cert.PublicKey
+	}
+
+	o.certLock.Unlock()
+
+	if err != nil {
+		return xerrors.Errorf("while storing: %v", err)
+	}
+
+	err = o.shareCertificate(cert)
+	if err != nil {
+		return xerrors.Errorf("failed to share certificate: %v", err)
+	}
+
+	return nil
+}
+
+// checkCertificate returns an error if the certificate cannot be used by the
+// server with the key.
+func (o *overlay) checkCertificate(cert *x509.Certificate, secret interface{}) error {
+	err := cert.CheckSignatureFrom(cert)
+	if err != nil {
+		return xerrors.Errorf("not self-signed: %v", err)
+	}
+
+	now := time.Now()
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return xerrors.New("expired or not yet valid")
+	}
+
+	key, ok := secret.(interface{ Public() stdcrypto.PublicKey })
+	if !ok {
+		return xerrors.Errorf("unsupported key '%T'", secret)
+	}
+
+	public, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return xerrors.Errorf("couldn't marshal public key: %v", err)
+	}
+
+	if !bytes.Equal(public, cert.RawSubjectPublicKeyInfo) {
+		return xerrors.New("mismatching key")
+	}
+
+	hostnames, err := o.myAddr.GetHostnames()
+	if err != nil {
+		return xerrors.Errorf("error retrieving hostname: %v", err)
+	}
+
+	for _, hostname := range hostnames {
+		err = cert.VerifyHostname(hostname)
+		if err != nil {
+			return xerrors.Errorf("invalid hostname: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// shareCertificate sends the certificate of the overlay to the known peers. It
+// tries every peer, and returns the last error if any has failed.
+func (o *overlay) shareCertificate(cert *x509.Certificate) error {
+	peers := []mino.Address{}
+
+	o.certs.Range(func(addr mino.Address, _ *tls.Certificate) bool {
+		if !addr.Equal(o.myAddr) {
+			peers = append(peers, addr)
+		}
+
+		return true
+	})
+
+	msg := &ptypes.Certificate{
+		Address: []byte(o.myAddrStr),
+		Value:   cert.Raw,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	res := make(chan error, len(peers))
+
+	for _, to := range peers {
+		go func(to mino.Address) {
+			conn, err := o.connMgr.Acquire(to)
+			if err != nil {
+				res <- xerrors.Errorf("couldn't open connection: %v", err)
+				return
+			}
+
+			defer o.connMgr.Release(to, conn)
+
+			_, err = ptypes.NewOverlayClient(conn).Share(ctx, msg)
+			if err != nil {
+				res <- xerrors.Errorf("couldn't call share: %v", err)
+				return
+			}
+
+			res <- nil
+		}(to)
+	}
+
+	var lastErr error
+	failures := 0
+
+	for range peers {
+		err := <-res
+		if err != nil {
+			dela.Logger.Warn().Err(err).Msg("certificate not shared")
+
+			lastErr = err
+			failures++
+		}
+	}
+
+	if lastErr != nil {
+		return xerrors.Errorf("%d of %d peers failed: %v", failures, len(peers), lastErr)
+	}
+
+	return nil
+}
+
 // GetCertificateStore returns the certificate store.
 func (o *overlay) GetCertificateStore() certs.Storage {
 	return o.certs
 }
 
+// GetScores returns the scoreboard of the peers.
+func (o *overlay) GetScores() scores.Board {
+	return o.scores
+}
+
+// isBanned returns the address of the peer of the request and true if it is
+// banned. The orchestrator and the follower addresses of a host share the same
+// score.
+func (o *overlay) isBanned(ctx context.Context) (mino.Address, bool) {
+	if o.scores == nil {
+		return nil, false
+	}
+
+	addr, ok := o.authenticate(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	return addr, o.scores.IsBanned(peerOf(addr))
+}
+
+// report reports the offense of the peer of the request. Nothing is reported
+// when the peer is not authenticated, as the offense could be blamed on anyone.
+func (o *overlay) report(ctx context.Context, offense scores.Offense) {
+	if o.scores == nil {
+		return
+	}
+
+	addr, ok := o.authenticate(ctx)
+	if !ok {
+		dela.Logger.Debug().
+			Stringer("offense", offense).
+			Msg("offense of an unauthenticated peer ignored")
+
+		return
+	}
+
+	o.scores.Report(peerOf(addr), offense)
+}
+
+// authenticate returns the address of the peer of the request, which is found
+// by the certificate presented during the TLS handshake. The identity claimed
+// by the messages and the headers is never trusted as it is controlled by the
+// peer. It returns false if the peer has not presented a known certificate.
+func (o *overlay) authenticate(ctx context.Context) (mino.Address, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.PeerCertificates) == 0 {
+		return nil, false
+	}
+
+	leaf := info.State.PeerCertificates[0]
+
+	var found mino.Address
+
+	o.certs.Range(func(addr mino.Address, cert *tls.Certificate) bool {
+		if cert.Leaf != nil && cert.Leaf.Equal(leaf) {
+			found = addr
+			return false
+		}
+
+		return true
+	})
+
+	return found, found != nil
+}
+
+// resolve returns the address of the host. The hosts of an SRV record are
+// tried in order like the ones of a multi-homed address.
+func (o *overlay) resolve(host string) (session.Address, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	hosts, err := o.resolver.Resolve(ctx, host)
+	if err != nil {
+		return session.Address{}, err
+	}
+
+	return session.NewMultiAddress(hosts...), nil
+}
+
 // Join sends a join request to a distant node with token generated beforehands
 // by the later.
 func (o *overlay) Join(addr, token string, certHash []byte) error {
-	target := session.NewAddress(addr)
+	target, err := o.resolve(addr)
+	if err != nil {
+		return xerrors.Errorf("couldn't resolve address: %v", err)
+	}
 
 	meCert := o.GetCertificate()
 
 	// Fetch the certificate of the node we want to join. The hash is used to
 	// ensure that we get the right certificate.
-	err := o.certs.Fetch(target, certHash)
+	err = o.certs.Fetch(target, certHash)
 	if err != nil {
 		return xerrors.Errorf("couldn't fetch distant certificate: %v", err)
 	}
@@ -518,7 +918,7 @@ func (o *overlay) Join(addr, token string, certHash []byte) error {
 		return xerrors.Errorf("couldn't open connection: %v", err)
 	}
 
-	defer o.connMgr.Release(target)
+	defer o.connMgr.Release(target, conn)
 
 	client := ptypes.NewOverlayClient(conn)
 
@@ -551,23 +951,50 @@ func (o *overlay) Join(addr, token string, certHash []byte) error {
 		o.certs.Store(from, &tls.Certificate{Leaf: leaf})
 	}
 
+	// The relay, if any, is probably known now.
+	o.relays.wakeUp()
+
 	return nil
 }
 
 func (o *overlay) makeCertificate() error {
-	hostname, err := o.myAddr.GetHostname()
+	hostnames, err := o.myAddr.GetHostnames()
 	if err != nil {
 		return xerrors.Errorf("error retrieving hostname: %v", err)
 	}
 
-	ipAddrs, err := net.LookupIP(hostname)
-	if err != nil {
-		return xerrors.Errorf("error resolving IP: %v", err)
+	hosts := o.myAddr.GetDialAddresses()
+
+	var ipAddrs []net.IP
+	var dnsNames []string
+
+	for i, hostname := range hostnames {
+		// A node behind a relay has no public IP, therefore it is verified
+		// by its identifier.
+		_, _, relayed := session.ParseRelay(hosts[i])
+		if relayed {
+			dnsNames = append(dnsNames, hostname)
+			continue
+		}
+
+		ips, err := net.LookupIP(hostname)
+		if err != nil {
+			return xerrors.Errorf("error resolving IP: %v", err)
+		}
+
+		ipAddrs = append(ipAddrs, ips...)
+
+		// A public address can be a DNS name which must be part of the
+		// certificate so that the peers can verify it.
+		if net.ParseIP(hostname) == nil {
+			dnsNames = append(dnsNames, hostname)
+		}
 	}
 
 	tmpl := &x509.Certificate{
 		SerialNumber: big.NewInt(1),
 		IPAddresses:  ipAddrs,
+		DNSNames:     dnsNames,
 		NotBefore:    time.Now(),
 		NotAfter:     time.Now().Add(certificateDuration),
 
@@ -610,16 +1037,43 @@ type connManager struct {
 	sync.Mutex
 	certs    certs.Storage
 	myAddr   mino.Address
-	counters map[mino.Address]int
-	conns    map[mino.Address]*grpc.ClientConn
+	resolver resolver.Resolver
+	// conns is the connection currently used for an address.
+	conns map[mino.Address]*grpc.ClientConn
+	// counters is the number of users of a connection. A connection replaced
+	// after a failover is closed only once it has been released by all of them.
+	counters map[*grpc.ClientConn]int
+	// dialing is closed when the connection being dialed for an address is
+	// ready, so that an address is dialed only once at a time.
+	dialing map[mino.Address]chan struct{}
+	// targets is the host dialed for the connection of a multi-homed address,
+	// or of an address whose host resolves into others.
+	targets map[mino.Address]string
+	// failures is the last time a host of a multi-homed address was found
+	// unreachable.
+	failures map[string]time.Time
+	// stats is the handler that counts the traffic of the connections, if
+	// any.
+	stats stats.Handler
+	// limit is the maximum number of connections open at the same time, or
+	// zero for no limit.
+	limit int
+	// relays is the registry of the nodes behind this participant acting as
+	// their relay.
+	relays *relayTable
 }
 
-func newConnManager(myAddr mino.Address, certs certs.Storage) *connManager {
+func newConnManager(myAddr mino.Address, certs certs.Storage, r resolver.Resolver) *connManager {
 	return &connManager{
 		certs:    certs,
 		myAddr:   myAddr,
-		counters: make(map[mino.Address]int),
+		resolver: r,
 		conns:    make(map[mino.Address]*grpc.ClientConn),
+		counters: make(map[*grpc.ClientConn]int),
+		dialing:  make(map[mino.Address]chan struct{}),
+		targets:  make(map[mino.Address]string),
+		failures: make(map[string]time.Time),
+		relays:   newRelayTable(),
 	}
 }
 
@@ -633,35 +1087,235 @@ func (mgr *connManager) Len() int {
 }
 
 // Acquire implements session.ConnectionManager. It either dials to open the
-// connection or returns an existing one for the address.
+// connection or returns an existing one for the address. The connection to a
+// multi-homed address fails over to the next host when the current one is
+// unhealthy, and the hosts are resolved again. The dial happens without the
+// lock so that the connections to the other addresses are not delayed. It
+// returns an error that wraps mino.ErrBackpressure when a new connection would
+// exceed the limit.
 func (mgr *connManager) Acquire(to mino.Address) (grpc.ClientConnInterface, error) {
-	mgr.Lock()
-	defer mgr.Unlock()
+	for {
+		mgr.Lock()
 
-	conn, ok := mgr.conns[to]
-	if ok {
-		mgr.counters[to]++
-		return conn, nil
+		conn, found := mgr.conns[to]
+		if found && mgr.isHealthy(to, conn) {
+			mgr.counters[conn]++
+			mgr.Unlock()
+
+			return conn, nil
+		}
+
+		wait, dialing := mgr.dialing[to]
+		if dialing {
+			mgr.Unlock()
+
+			// Another user is opening the connection, which is either used
+			// when ready or dialed again if it has failed.
+			<-wait
+			continue
+		}
+
+		// A connection that fails over replaces the current one, therefore it
+		// doesn't count toward the limit.
+		if !found && mgr.limit > 0 && len(mgr.conns)+len(mgr.dialing) >= mgr.limit {
+			mgr.Unlock()
+
+			return nil, xerrors.Errorf("limit of %d connections reached: %w",
+				mgr.limit, mino.ErrBackpressure)
+		}
+
+		done := make(chan struct{})
+		mgr.dialing[to] = done
+
+		mgr.Unlock()
+
+		newConn, host, err := mgr.open(to)
+
+		mgr.Lock()
+
+		delete(mgr.dialing, to)
+		close(done)
+
+		if err != nil {
+			mgr.Unlock()
+			return nil, err
+		}
+
+		if found {
+			// The unhealthy connection is replaced for the new users, but it
+			// is closed only when the current ones have released it.
+			delete(mgr.conns, to)
+			delete(mgr.targets, to)
+		}
+
+		mgr.conns[to] = newConn
+		mgr.counters[newConn] = 1
+
+		if host != "" {
+			mgr.targets[to] = host
+		}
+
+		mgr.Unlock()
+
+		return newConn, nil
 	}
+}
 
+// open dials a connection to the address and returns the host that has been
+// reached for a multi-homed address.
+func (mgr *connManager) open(to mino.Address) (*grpc.ClientConn, string, error) {
 	ta, err := mgr.getTransportCredential(to)
 	if err != nil {
-		return nil, xerrors.Errorf("failed to retrieve transport credential: %v", err)
+		return nil, "", xerrors.Errorf("failed to retrieve transport credential: %v", err)
 	}
 
 	netAddr, ok := to.(session.Address)
 	if !ok {
-		return nil, xerrors.Errorf("invalid address type '%T'", to)
+		return nil, "", xerrors.Errorf("invalid address type '%T'", to)
 	}
 
-	// Connecting using TLS and the distant server certificate as the root.
-	addr := netAddr.GetDialAddress()
+	conn, host, err := mgr.dial(netAddr, ta)
+	if err != nil {
+		return nil, "", xerrors.Errorf("failed to dial: %v", err)
+	}
+
+	return conn, host, nil
+}
+
+// isHealthy returns false when the connection of a multi-homed address has
+// failed, so that another host can be tried. It must be called with the lock.
+func (mgr *connManager) isHealthy(to mino.Address, conn *grpc.ClientConn) bool {
+	target, ok := mgr.targets[to]
+	if !ok {
+		return true
+	}
+
+	state := conn.GetState()
+	if state != connectivity.TransientFailure && state != connectivity.Shutdown {
+		return true
+	}
+
+	mgr.failures[target] = time.Now()
+
+	dela.Logger.Warn().
+		Stringer("to", to).
+		Str("host", target).
+		Msg("connection unhealthy, failing over")
+
+	return false
+}
+
+// dial opens a connection to the address. The hosts of a multi-homed address
+// are resolved and tried in order, starting with the ones that have not failed
+// recently, and the first one that can be reached is used. It returns the host
+// of the connection for a multi-homed address. It must be called without the
+// lock.
+func (mgr *connManager) dial(to session.Address,
+	ta credentials.TransportCredentials) (*grpc.ClientConn, string, error) {
+
+	hosts, err := mgr.resolve(to)
+	if err != nil {
+		return nil, "", xerrors.Errorf("failed to resolve: %v", err)
+	}
+
+	if len(hosts) == 1 && hosts[0] == to.GetDialAddress() {
+		conn, err := mgr.dialHost(hosts[0], ta)
+		return conn, "", err
+	}
+
+	mgr.Lock()
+	hosts = mgr.sortByHealth(hosts)
+	mgr.Unlock()
+
+	var lastErr error
+
+	for _, host := range hosts {
+		conn, err := mgr.dialHost(host, ta, grpc.WithBlock())
+
+		mgr.Lock()
+
+		if err != nil {
+			mgr.failures[host] = time.Now()
+			mgr.Unlock()
+
+			lastErr = err
+
+			continue
+		}
+
+		delete(mgr.failures, host)
+		mgr.Unlock()
+
+		return conn, host, nil
+	}
+
+	return nil, "", xerrors.Errorf("no host reachable: %v", lastErr)
+}
+
+// resolve returns the network addresses of the hosts of the address in order.
+// A host that cannot be resolved is skipped as long as another one is.
+func (mgr *connManager) resolve(to session.Address) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	var addrs []string
+	var lastErr error
+
+	for _, host := range to.GetDialAddresses() {
+		// The relay is resolved when the tunnel is opened.
+		_, _, relayed := session.ParseRelay(host)
+		if relayed {
+			addrs = append(addrs, host)
+			continue
+		}
+
+		resolved, err := mgr.resolver.Resolve(ctx, host)
+		if err != nil {
+			dela.Logger.Warn().Err(err).Str("host", host).Msg("host not resolved")
+
+			lastErr = err
+
+			continue
+		}
+
+		addrs = append(addrs, resolved...)
+	}
+
+	if len(addrs) == 0 {
+		return nil, xerrors.Errorf("no host resolved: %v", lastErr)
+	}
+
+	return addrs, nil
+}
+
+// sortByHealth returns the hosts in order, but the ones that have failed during
+// the cooldown are moved to the end. It must be called with the lock.
+func (mgr *connManager) sortByHealth(hosts []string) []string {
+	healthy := make([]string, 0, len(hosts))
+	unhealthy := make([]string, 0, len(hosts))
+
+	for _, host := range hosts {
+		at, found := mgr.failures[host]
+		if found && time.Since(at) < failoverCooldown {
+			unhealthy = append(unhealthy, host)
+		} else {
+			healthy = append(healthy, host)
+		}
+	}
+
+	return append(healthy, unhealthy...)
+}
+
+func (mgr *connManager) dialHost(addr string,
+	ta credentials.TransportCredentials, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+
 	tracer, err := getTracerForAddr(addr)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to get tracer for addr %s: %v", addr, err)
 	}
 
-	conn, err = grpc.Dial(addr,
+	// Connecting using TLS and the distant server certificate as the root.
+	opts = append(opts,
 		grpc.WithTransportCredentials(ta),
 		grpc.WithConnectParams(grpc.ConnectParams{
 			Backoff:           backoff.DefaultConfig,
@@ -674,12 +1328,32 @@ func (mgr *connManager) Acquire(to mino.Address) (grpc.ClientConnInterface, erro
 			otgrpc.OpenTracingStreamClientInterceptor(tracer, otgrpc.SpanDecorator(decorateClientTrace)),
 		),
 	)
-	if err != nil {
-		return nil, xerrors.Errorf("failed to dial: %v", err)
+
+	if mgr.stats != nil {
+		opts = append(opts, grpc.WithStatsHandler(mgr.stats))
+	}
+
+	public, id, relayed := session.ParseRelay(addr)
+	if relayed {
+		// The connection is tunneled through the relay, and the certificate
+		// of the node is verified against its identifier.
+		opts = append(opts,
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return mgr.dialRelay(ctx, public, id)
+			}),
+			grpc.WithAuthority(id),
+		)
+
+		addr = "passthrough:///" + addr
 	}
 
-	mgr.conns[to] = conn
-	mgr.counters[to] = 1
+	ctx, cancel := context.WithTimeout(context.Background(), failoverTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, addr, opts...)
+	if err != nil {
+		return nil, err
+	}
 
 	return conn, nil
 }
@@ -712,33 +1386,53 @@ func (mgr *connManager) getTransportCredential(addr mino.Address) (credentials.T
 	return ta, nil
 }
 
-// Release implements session.ConnectionManager. It closes the connection to the
-// address if appropriate.
-func (mgr *connManager) Release(to mino.Address) {
+// Release implements session.ConnectionManager. It closes the connection if
+// the user was the last one. The connection is not necessarily the current one
+// of the address if it has been replaced in the meantime.
+func (mgr *connManager) Release(to mino.Address, c grpc.ClientConnInterface) {
+	conn, ok := c.(*grpc.ClientConn)
+	if !ok {
+		return
+	}
+
 	mgr.Lock()
 	defer mgr.Unlock()
 
-	count, ok := mgr.counters[to]
-	if ok {
-		if count <= 1 {
-			delete(mgr.counters, to)
+	count, ok := mgr.counters[conn]
+	if !ok {
+		return
+	}
 
-			conn := mgr.conns[to]
-			delete(mgr.conns, to)
+	if count > 1 {
+		mgr.counters[conn]--
+		return
+	}
 
-			err := conn.Close()
-			dela.Logger.Trace().
-				Err(err).
-				Stringer("to", to).
-				Stringer("from", mgr.myAddr).
-				Int("length", len(mgr.conns)).
-				Msg("connection closed")
+	delete(mgr.counters, conn)
 
-			return
-		}
+	if mgr.conns[to] == conn {
+		delete(mgr.conns, to)
+		delete(mgr.targets, to)
+	}
 
-		mgr.counters[to]--
+	err := conn.Close()
+	dela.Logger.Trace().
+		Err(err).
+		Stringer("to", to).
+		Stringer("from", mgr.myAddr).
+		Int("length", len(mgr.conns)).
+		Msg("connection closed")
+}
+
+// peerOf returns the follower address of the host when the address is an
+// orchestrator.
+func peerOf(addr mino.Address) mino.Address {
+	a, ok := addr.(session.Address)
+	if ok {
+		return session.NewMultiAddress(a.GetDialAddresses()...)
 	}
+
+	return addr
 }
 
 func readHeaders(md metadata.MD) (uri string, streamID string, gw string, protocol string) {
@@ -1,30 +1,44 @@
 package minogrpc
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"io"
+	"math/big"
 	"net"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/crypto"
+	"go.dedis.ch/dela/crypto/bls"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/internal/tracing"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/minogrpc/certs"
 	"go.dedis.ch/dela/mino/minogrpc/ptypes"
+	"go.dedis.ch/dela/mino/minogrpc/resolver"
+	"go.dedis.ch/dela/mino/minogrpc/scores"
 	"go.dedis.ch/dela/mino/minogrpc/session"
 	"go.dedis.ch/dela/mino/minogrpc/tokens"
 	"go.dedis.ch/dela/mino/router"
 	"go.dedis.ch/dela/mino/router/tree"
 	"go.dedis.ch/dela/serde"
 	"go.dedis.ch/dela/serde/json"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 )
 
 func TestIntegration_Scenario_Stream(t *testing.T) {
@@ -107,6 +121,300 @@ func TestIntegration_Scenario_Call(t *testing.T) {
 	}
 }
 
+func TestIntegration_Scenario_Compression(t *testing.T) {
+	comp := &countingCompressor{Compressor: encoding.GetCompressor("gzip")}
+	encoding.RegisterCompressor(comp)
+
+	mm, rpcs := makeInstances(t, 5, nil)
+
+	compressed := make([]mino.RPC, len(mm))
+	for i, m := range mm {
+		h := mino.WithCompression(testHandler{call: &fake.Call{}}, mino.Compression(comp.Name()))
+		compressed[i] = mino.MustCreateRPC(m, "compressed", h, fake.MessageFactory{})
+	}
+
+	defer func() {
+		for _, m := range mm {
+			require.NoError(t, m.(*Minogrpc).GracefulStop())
+		}
+	}()
+
+	authority := fake.NewAuthorityFromMino(fake.NewSigner, mm...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The requests and the replies are compressed.
+	resps, err := compressed[0].Call(ctx, fake.Message{}, authority)
+	require.NoError(t, err)
+
+	for resp := range resps {
+		_, err := resp.GetMessageOrError()
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int32(10), atomic.LoadInt32(&comp.compressed))
+	require.Equal(t, int32(10), atomic.LoadInt32(&comp.decompressed))
+
+	// The orchestrator and the relays of a stream compress the packets.
+	atomic.StoreInt32(&comp.compressed, 0)
+
+	out, in, err := compressed[0].Stream(ctx, authority)
+	require.NoError(t, err)
+
+	iter := authority.AddressIterator()
+	for iter.HasNext() {
+		err := <-out.Send(fake.Message{}, iter.GetNext())
+		require.NoError(t, err)
+
+		_, _, err = in.Recv(ctx)
+		require.NoError(t, err)
+	}
+
+	require.Greater(t, atomic.LoadInt32(&comp.compressed), int32(0))
+
+	// The messages of the other RPCs are left uncompressed.
+	atomic.StoreInt32(&comp.compressed, 0)
+
+	resps, err = rpcs[0].Call(ctx, fake.Message{}, authority)
+	require.NoError(t, err)
+
+	for resp := range resps {
+		_, err := resp.GetMessageOrError()
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int32(0), atomic.LoadInt32(&comp.compressed))
+}
+
+func TestIntegration_Scenario_PayloadCompression(t *testing.T) {
+	comp := &countingSerdeCompressor{}
+	serde.RegisterCompressor(comp)
+
+	// Only the first participant compresses its payloads, but both of them
+	// decompress the payloads of the other.
+	m1, err := NewMinogrpc(ParseAddress("127.0.0.1", 0), tree.NewRouter(addressFac),
+		WithPayloadCompression(comp))
+	require.NoError(t, err)
+
+	defer m1.GracefulStop()
+
+	m2, err := NewMinogrpc(ParseAddress("127.0.0.1", 0), tree.NewRouter(addressFac))
+	require.NoError(t, err)
+
+	defer m2.GracefulStop()
+
+	m1.GetCertificateStore().Store(m2.GetAddress(), m2.GetCertificate())
+	m2.GetCertificateStore().Store(m1.GetAddress(), m1.GetCertificate())
+
+	rpc1 := mino.MustCreateRPC(m1, "blobs", testHandler{}, blobFactory{})
+	rpc2 := mino.MustCreateRPC(m2, "blobs", testHandler{}, blobFactory{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	blob := blobMessage(bytes.Repeat([]byte("A"), 10*serde.CompressionThreshold))
+
+	call := func(rpc mino.RPC, to mino.Address) {
+		resps, err := rpc.Call(ctx, blob, mino.NewAddresses(to))
+		require.NoError(t, err)
+
+		resp := <-resps
+		msg, err := resp.GetMessageOrError()
+		require.NoError(t, err)
+		require.Equal(t, blob, msg)
+	}
+
+	// The first request is plain as the algorithm is not yet known to be
+	// supported, and the answer is plain as the callee doesn't compress.
+	call(rpc1, m2.GetAddress())
+	require.Equal(t, int32(0), atomic.LoadInt32(&comp.compressed))
+	require.Equal(t, int32(0), atomic.LoadInt32(&comp.decompressed))
+
+	// The answer has announced the algorithm, therefore the next request is
+	// compressed.
+	call(rpc1, m2.GetAddress())
+	require.Equal(t, int32(1), atomic.LoadInt32(&comp.compressed))
+	require.Equal(t, int32(1), atomic.LoadInt32(&comp.decompressed))
+
+	// The caller announces the algorithm, therefore the answer is compressed.
+	call(rpc2, m1.GetAddress())
+	require.Equal(t, int32(2), atomic.LoadInt32(&comp.compressed))
+	require.Equal(t, int32(2), atomic.LoadInt32(&comp.decompressed))
+}
+
+func TestIntegration_Scenario_Fragments(t *testing.T) {
+	mm, _ := makeInstances(t, 4, nil)
+
+	rpcs := make([]mino.RPC, len(mm))
+	for i, m := range mm {
+		rpcs[i] = mino.MustCreateRPC(m, "blobs", testHandler{}, blobFactory{})
+	}
+
+	defer func() {
+		for _, m := range mm {
+			require.NoError(t, m.(*Minogrpc).GracefulStop())
+		}
+	}()
+
+	authority := fake.NewAuthorityFromMino(fake.NewSigner, mm...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// The message is larger than the maximum size of a gRPC message, which
+	// is 4MB by default.
+	blob := blobMessage(make([]byte, 5<<20))
+	_, err := rand.Read(blob)
+	require.NoError(t, err)
+
+	out, in, err := rpcs[0].Stream(ctx, authority)
+	require.NoError(t, err)
+
+	iter := authority.AddressIterator()
+	for iter.HasNext() {
+		to := iter.GetNext()
+
+		err := <-out.Send(blob, to)
+		require.NoError(t, err)
+
+		from, msg, err := in.Recv(ctx)
+		require.NoError(t, err)
+		require.True(t, to.Equal(from))
+		require.Equal(t, blob, msg)
+	}
+}
+
+func TestIntegration_Scenario_Metrics(t *testing.T) {
+	mm, rpcs := makeInstances(t, 3, nil)
+
+	defer func() {
+		for _, m := range mm {
+			require.NoError(t, m.(*Minogrpc).GracefulStop())
+		}
+	}()
+
+	authority := fake.NewAuthorityFromMino(fake.NewSigner, mm...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resps, err := rpcs[0].Call(ctx, fake.Message{}, authority)
+	require.NoError(t, err)
+
+	for resp := range resps {
+		_, err := resp.GetMessageOrError()
+		require.NoError(t, err)
+	}
+
+	// The orchestrator sends a request to each participant, including itself,
+	// and receives their replies.
+	metrics := mm[0].(*Minogrpc).Metrics()
+
+	traffic := metrics.GetTraffic("test")
+	require.Equal(t, uint64(3), traffic.Calls)
+	require.Equal(t, uint64(4), traffic.MessagesIn)
+	require.Equal(t, uint64(4), traffic.MessagesOut)
+	require.Greater(t, traffic.BytesIn, uint64(0))
+	require.Greater(t, traffic.BytesOut, uint64(0))
+
+	traffic = mm[1].(*Minogrpc).Metrics().GetTraffic("test")
+	require.Equal(t, uint64(0), traffic.Calls)
+	require.Equal(t, uint64(1), traffic.MessagesIn)
+	require.Equal(t, uint64(1), traffic.MessagesOut)
+
+	out, in, err := rpcs[0].Stream(ctx, authority)
+	require.NoError(t, err)
+
+	iter := authority.AddressIterator()
+	for iter.HasNext() {
+		err := <-out.Send(fake.Message{}, iter.GetNext())
+		require.NoError(t, err)
+
+		_, _, err = in.Recv(ctx)
+		require.NoError(t, err)
+	}
+
+	traffic = metrics.GetTraffic("test")
+	require.GreaterOrEqual(t, traffic.Streams, uint64(1))
+	require.Greater(t, traffic.MessagesOut, uint64(4))
+
+	require.Equal(t, []string{"test"}, metrics.GetURIs())
+}
+
+func TestIntegration_Scenario_UpdateCertificate(t *testing.T) {
+	mm, rpcs := makeInstances(t, 3, nil)
+
+	echo := mino.MustCreateRPC(mm[0], "echo", echoHandler{}, fake.MessageFactory{})
+	for _, m := range mm[1:] {
+		mino.MustCreateRPC(m, "echo", echoHandler{}, fake.MessageFactory{})
+	}
+
+	authority := fake.NewAuthorityFromMino(fake.NewSigner, mm...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sender, recv, err := echo.Stream(ctx, authority)
+	require.NoError(t, err)
+
+	exchange := func() {
+		iter := authority.AddressIterator()
+		for iter.HasNext() {
+			to := iter.GetNext()
+			err := <-sender.Send(fake.Message{}, to)
+			require.NoError(t, err)
+
+			from, _, err := recv.Recv(context.Background())
+			require.NoError(t, err)
+			require.True(t, to.Equal(from))
+		}
+	}
+
+	exchange()
+
+	m := mm[1].(*Minogrpc)
+	cert := fake.MakeCertificate(t, 1, net.IPv4(127, 0, 0, 1))
+
+	err = m.UpdateCertificate(cert.Leaf, cert.PrivateKey)
+	require.NoError(t, err)
+
+	// The active stream is not interrupted.
+	exchange()
+
+	for _, other := range mm {
+		shared, err := other.(*Minogrpc).GetCertificateStore().Load(m.GetAddress())
+		require.NoError(t, err)
+		require.True(t, cert.Leaf.Equal(shared.Leaf))
+	}
+
+	cancel()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The new connections are opened with the new certificate.
+	resps, err := rpcs[0].Call(ctx, fake.Message{}, authority)
+	require.NoError(t, err)
+
+	for resp := range resps {
+		_, err := resp.GetMessageOrError()
+		require.NoError(t, err)
+	}
+
+	resps, err = rpcs[1].Call(ctx, fake.Message{}, authority)
+	require.NoError(t, err)
+
+	for resp := range resps {
+		_, err := resp.GetMessageOrError()
+		require.NoError(t, err)
+	}
+
+	for _, m := range mm {
+		require.NoError(t, m.(*Minogrpc).GracefulStop())
+	}
+}
+
 func TestMinogrpc_Scenario_Failures(t *testing.T) {
 	srvs, rpcs := makeInstances(t, 14, nil)
 	defer func() {
@@ -192,11 +500,12 @@ func TestOverlayServer_Join(t *testing.T) {
 	overlay := &overlayServer{overlay: o}
 
 	cert := overlay.GetCertificate()
-	token := overlay.tokens.Generate(time.Hour)
+	token, err := overlay.tokens.Generate(time.Hour, tokens.ScopeValidator)
+	require.NoError(t, err)
 
 	ctx := context.Background()
 	req := &ptypes.JoinRequest{
-		Token: token,
+		Token: token.String(),
 		Certificate: &ptypes.Certificate{
 			Address: []byte{},
 			Value:   cert.Leaf.Raw,
@@ -208,10 +517,49 @@ func TestOverlayServer_Join(t *testing.T) {
 	require.NotNil(t, resp)
 }
 
+func TestOverlayServer_Observer_Join(t *testing.T) {
+	o, err := newOverlay(minoTemplate{
+		myAddr: session.NewAddress("127.0.0.1:0"),
+		certs:  certs.NewInMemoryStore(),
+		router: tree.NewRouter(addressFac),
+		curve:  elliptic.P521(),
+		random: rand.Reader,
+	})
+	require.NoError(t, err)
+
+	calls := fake.NewCall()
+	o.connMgr = fakeConnMgr{calls: calls}
+
+	o.certs.Store(session.NewAddress("B"), fake.MakeCertificate(t, 0))
+
+	overlay := &overlayServer{overlay: o}
+
+	cert := overlay.GetCertificate()
+
+	token, err := overlay.tokens.Generate(time.Hour, tokens.ScopeObserver)
+	require.NoError(t, err)
+
+	req := &ptypes.JoinRequest{
+		Token: token.String(),
+		Certificate: &ptypes.Certificate{
+			Address: []byte{},
+			Value:   cert.Leaf.Raw,
+		},
+	}
+
+	// Only the node that generated the token knows the observer.
+	resp, err := overlay.Join(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, resp.Peers, 1)
+	require.Equal(t, []byte(o.myAddrStr), resp.Peers[0].Address)
+	require.Equal(t, 2, calls.Len())
+	require.Equal(t, o.myAddr, calls.Get(0, 1))
+}
+
 func TestOverlayJoin_InvalidToken_Join(t *testing.T) {
 	overlay := overlayServer{
 		overlay: &overlay{
-			tokens: tokens.NewInMemoryHolder(),
+			tokens: makeHolder(t),
 		},
 	}
 
@@ -221,34 +569,35 @@ func TestOverlayJoin_InvalidToken_Join(t *testing.T) {
 	req := &ptypes.JoinRequest{Token: "abc"}
 
 	_, err := overlay.Join(ctx, req)
-	require.EqualError(t, err, "token 'abc' is invalid")
+	require.EqualError(t, err, "invalid token: malformed token")
 }
 
 func TestOverlayJoin_BadAddress_Join(t *testing.T) {
 	overlay := overlayServer{
 		overlay: &overlay{
-			tokens: tokens.NewInMemoryHolder(),
+			tokens: makeHolder(t),
 			certs:  certs.NewInMemoryStore(),
 		},
 	}
 
 	overlay.certs.Store(fake.NewBadAddress(), fake.MakeCertificate(t, 0))
 
-	token := overlay.tokens.Generate(time.Hour)
+	token, err := overlay.tokens.Generate(time.Hour, tokens.ScopeValidator)
+	require.NoError(t, err)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	req := &ptypes.JoinRequest{Token: token}
+	req := &ptypes.JoinRequest{Token: token.String()}
 
-	_, err := overlay.Join(ctx, req)
+	_, err = overlay.Join(ctx, req)
 	require.EqualError(t, err, fake.Err("couldn't marshal address"))
 }
 
 func TestOverlayJoin_BadNetwork_Join(t *testing.T) {
 	overlay := overlayServer{
 		overlay: &overlay{
-			tokens:  tokens.NewInMemoryHolder(),
+			tokens:  makeHolder(t),
 			certs:   certs.NewInMemoryStore(),
 			connMgr: fakeConnMgr{err: fake.GetError()},
 		},
@@ -256,14 +605,15 @@ func TestOverlayJoin_BadNetwork_Join(t *testing.T) {
 
 	overlay.certs.Store(session.NewAddress(""), fake.MakeCertificate(t, 0))
 
-	token := overlay.tokens.Generate(time.Hour)
+	token, err := overlay.tokens.Generate(time.Hour, tokens.ScopeValidator)
+	require.NoError(t, err)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	req := &ptypes.JoinRequest{Token: token}
+	req := &ptypes.JoinRequest{Token: token.String()}
 
-	_, err := overlay.Join(ctx, req)
+	_, err = overlay.Join(ctx, req)
 	require.EqualError(t, err,
 		fake.Err("failed to share certificate: couldn't open connection"))
 }
@@ -271,7 +621,7 @@ func TestOverlayJoin_BadNetwork_Join(t *testing.T) {
 func TestOverlayJoin_BadConn_Join(t *testing.T) {
 	overlay := overlayServer{
 		overlay: &overlay{
-			tokens:  tokens.NewInMemoryHolder(),
+			tokens:  makeHolder(t),
 			certs:   certs.NewInMemoryStore(),
 			connMgr: fakeConnMgr{errConn: fake.GetError()},
 		},
@@ -279,14 +629,15 @@ func TestOverlayJoin_BadConn_Join(t *testing.T) {
 
 	overlay.certs.Store(session.NewAddress(""), fake.MakeCertificate(t, 0))
 
-	token := overlay.tokens.Generate(time.Hour)
+	token, err := overlay.tokens.Generate(time.Hour, tokens.ScopeValidator)
+	require.NoError(t, err)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	req := &ptypes.JoinRequest{Token: token}
+	req := &ptypes.JoinRequest{Token: token.String()}
 
-	_, err := overlay.Join(ctx, req)
+	_, err = overlay.Join(ctx, req)
 	require.EqualError(t, err, fake.Err("failed to share certificate: couldn't call share"))
 }
 
@@ -326,6 +677,43 @@ func TestOverlayServer_Share(t *testing.T) {
 		"couldn't parse certificate: x509: malformed certificate")
 }
 
+func TestOverlayServer_InvalidSignature_Share(t *testing.T) {
+	board := scores.NewInMemoryBoard()
+
+	relay := fake.MakeCertificate(t, 1)
+
+	store := certs.NewInMemoryStore()
+	store.Store(session.NewAddress("A"), relay)
+
+	overlay := overlayServer{
+		overlay: &overlay{
+			certs:       store,
+			addrFactory: addressFac,
+			scores:      board,
+		},
+	}
+
+	from, err := session.NewAddress("B").MarshalText()
+	require.NoError(t, err)
+
+	raw := append([]byte{}, fake.MakeCertificate(t, 1).Leaf.Raw...)
+	raw[len(raw)-1] ^= 0xff
+
+	req := &ptypes.Certificate{Address: from, Value: raw}
+
+	_, err = overlay.Share(context.Background(), req)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid certificate signature: ")
+	require.Empty(t, getScores(board))
+
+	_, err = overlay.Share(withPeer(context.Background(), relay), req)
+	require.Error(t, err)
+
+	peers := getScores(board)
+	require.Len(t, peers, 1)
+	require.Equal(t, session.NewAddress("A"), peers[0].Address)
+}
+
 func TestOverlayServer_Call(t *testing.T) {
 	overlay := overlayServer{
 		overlay: &overlay{
@@ -354,6 +742,41 @@ func TestOverlayServer_Call(t *testing.T) {
 	require.Nil(t, resp.GetPayload())
 }
 
+func TestOverlayServer_Scope_Call(t *testing.T) {
+	scope := func(addr mino.Address) bool {
+		return addr.Equal(session.NewAddress("A"))
+	}
+
+	overlay := overlayServer{
+		overlay: &overlay{
+			context:     json.NewContext(),
+			addrFactory: addressFac,
+		},
+		endpoints: map[string]*Endpoint{
+			"test": {
+				Handler: mino.NewScopedHandler(testHandler{}, scope),
+				Factory: fake.MessageFactory{},
+			},
+		},
+	}
+
+	ctx := makeCtx(headerURIKey, "test")
+
+	from, err := session.NewAddress("A").MarshalText()
+	require.NoError(t, err)
+
+	resp, err := overlay.Call(ctx, &ptypes.Message{From: from, Payload: []byte(`{}`)})
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{}`), resp.GetPayload())
+
+	from, err = session.NewAddress("B").MarshalText()
+	require.NoError(t, err)
+
+	_, err = overlay.Call(ctx, &ptypes.Message{From: from, Payload: []byte(`{}`)})
+	require.EqualError(t, err,
+		"request refused: address B is out of the scope of the handler")
+}
+
 func TestOverlayServer_UnknownHandler_Call(t *testing.T) {
 	overlay := overlayServer{
 		endpoints: make(map[string]*Endpoint),
@@ -372,17 +795,130 @@ func TestOverlayServer_UnknownHandler_Call(t *testing.T) {
 }
 
 func TestOverlayServer_BadHandlerFactory_Call(t *testing.T) {
+	board := scores.NewInMemoryBoard()
+
+	cert := fake.MakeCertificate(t, 1)
+
+	store := certs.NewInMemoryStore()
+	store.Store(session.NewAddress("A"), cert)
+
 	overlay := overlayServer{
-		overlay: &overlay{},
+		overlay: &overlay{
+			addrFactory: addressFac,
+			certs:       store,
+			scores:      board,
+		},
 		endpoints: map[string]*Endpoint{
 			"test": {Handler: testHandler{}, Factory: fake.NewBadMessageFactory()},
 		},
 	}
 
+	// The address claimed by the message is not trusted.
+	from, err := session.NewAddress("B").MarshalText()
+	require.NoError(t, err)
+
 	ctx := makeCtx(headerURIKey, "test")
 
-	_, err := overlay.Call(ctx, &ptypes.Message{Payload: []byte(``)})
+	_, err = overlay.Call(ctx, &ptypes.Message{From: from, Payload: []byte(``)})
+	require.EqualError(t, err, fake.Err("couldn't deserialize message"))
+	require.Empty(t, getScores(board))
+
+	ctx = withPeer(ctx, fake.MakeCertificate(t, 1))
+
+	_, err = overlay.Call(ctx, &ptypes.Message{From: from, Payload: []byte(``)})
+	require.EqualError(t, err, fake.Err("couldn't deserialize message"))
+	require.Empty(t, getScores(board))
+
+	// The offense is blamed on the owner of the certificate.
+	ctx = withPeer(ctx, cert)
+
+	_, err = overlay.Call(ctx, &ptypes.Message{From: from, Payload: []byte(``)})
 	require.EqualError(t, err, fake.Err("couldn't deserialize message"))
+
+	peers := getScores(board)
+	require.Len(t, peers, 1)
+	require.Equal(t, session.NewAddress("A"), peers[0].Address)
+	require.InDelta(t, scores.MaxScore-10, peers[0].Score, 0.1)
+}
+
+func TestOverlayServer_Banned_Call(t *testing.T) {
+	board := scores.NewInMemoryBoard(scores.WithThreshold(scores.MaxScore))
+	board.Report(session.NewAddress("A"), scores.MalformedPacket)
+
+	cert := fake.MakeCertificate(t, 1)
+
+	store := certs.NewInMemoryStore()
+	store.Store(session.NewAddress("A"), cert)
+
+	overlay := overlayServer{
+		overlay: &overlay{
+			closer:      &sync.WaitGroup{},
+			addrFactory: addressFac,
+			certs:       store,
+			scores:      board,
+		},
+		endpoints: map[string]*Endpoint{
+			"test": {Handler: testHandler{}, Factory: fake.MessageFactory{}},
+		},
+	}
+
+	// The peer is banned whatever the address it claims.
+	from, err := session.NewAddress("B").MarshalText()
+	require.NoError(t, err)
+
+	ctx := withPeer(makeCtx(headerURIKey, "test"), cert)
+
+	_, err = overlay.Call(ctx, &ptypes.Message{From: from})
+	require.EqualError(t, err, "request refused: peer A is banned")
+
+	ctx = withPeer(makeCtx(headerGatewayKey, string(from)), cert)
+
+	err = overlay.Stream(&fakeSrvStream{ctx: ctx})
+	require.EqualError(t, err, "stream refused: peer A is banned")
+
+	_, err = overlay.Forward(ctx, &ptypes.Packet{})
+	require.EqualError(t, err, "packet dropped: peer A is banned")
+}
+
+func TestOverlayServer_Compressed_Call(t *testing.T) {
+	overlay := overlayServer{
+		overlay: &overlay{
+			addrFactory: addressFac,
+			context:     json.NewContext(json.WithLimits(serde.Limits{MaxSize: 4096})),
+			compressor:  serde.FlateCompressor{},
+		},
+		endpoints: map[string]*Endpoint{
+			"test": {Handler: testHandler{}, Factory: blobFactory{}},
+		},
+	}
+
+	blob := bytes.Repeat([]byte("A"), 4096)
+
+	compressed, err := serde.Compress(serde.WithCompression(overlay.context,
+		serde.FlateCompressor{}), blob)
+	require.NoError(t, err)
+
+	// The result is left as is when the caller doesn't announce the algorithm.
+	ctx := makeCtx(headerURIKey, "test")
+
+	resp, err := overlay.Call(ctx, &ptypes.Message{Payload: compressed})
+	require.NoError(t, err)
+	require.Equal(t, blob, resp.GetPayload())
+
+	ctx = makeCtx(headerURIKey, "test", headerPayloadCompressionKey, announceCompression())
+
+	resp, err = overlay.Call(ctx, &ptypes.Message{Payload: compressed})
+	require.NoError(t, err)
+	require.Equal(t, compressed, resp.GetPayload())
+
+	// A payload that expands beyond the limits is refused.
+	compressed, err = serde.Compress(serde.WithCompression(overlay.context,
+		serde.FlateCompressor{}), append(blob, 'A'))
+	require.NoError(t, err)
+
+	_, err = overlay.Call(ctx, &ptypes.Message{Payload: compressed})
+	require.EqualError(t, err, "couldn't decompress message: "+
+		"failed to read: size exceeds 4096: limit exceeded")
 }
 
 func TestOverlayServer_BadHandler_Call(t *testing.T) {
@@ -707,12 +1243,13 @@ func TestOverlay_Panic2_GetCertificate(t *testing.T) {
 
 func TestOverlay_Join(t *testing.T) {
 	overlay, err := newOverlay(minoTemplate{
-		myAddr: session.NewAddress("127.0.0.1:0"),
-		certs:  certs.NewInMemoryStore(),
-		router: tree.NewRouter(addressFac),
-		fac:    addressFac,
-		curve:  elliptic.P521(),
-		random: rand.Reader,
+		myAddr:   session.NewAddress("127.0.0.1:0"),
+		certs:    certs.NewInMemoryStore(),
+		router:   tree.NewRouter(addressFac),
+		fac:      addressFac,
+		resolver: resolver.NewDNS(),
+		curve:    elliptic.P521(),
+		random:   rand.Reader,
 	})
 	require.NoError(t, err)
 
@@ -744,6 +1281,88 @@ func TestOverlay_Join(t *testing.T) {
 	err = overlay.Join("", "", nil)
 	require.EqualError(t, err,
 		"couldn't parse certificate: x509: malformed certificate")
+
+	overlay.resolver = fakeResolver{err: fake.GetError()}
+	err = overlay.Join("srv:_dela._tcp.dela", "", nil)
+	require.EqualError(t, err, fake.Err("couldn't resolve address"))
+}
+
+func TestOverlay_UpdateCertificate(t *testing.T) {
+	overlay, err := newOverlay(minoTemplate{
+		myAddr:   session.NewAddress("127.0.0.1:0"),
+		certs:    certs.NewInMemoryStore(),
+		router:   tree.NewRouter(addressFac),
+		fac:      addressFac,
+		resolver: resolver.NewDNS(),
+		curve:    elliptic.P521(),
+		random:   rand.Reader,
+	})
+	require.NoError(t, err)
+
+	overlay.connMgr = fakeConnMgr{}
+
+	cert := fake.MakeCertificate(t, 1, net.IPv4(127, 0, 0, 1))
+
+	err = overlay.UpdateCertificate(cert.Leaf, cert.PrivateKey)
+	require.NoError(t, err)
+	require.Equal(t, cert.Leaf, overlay.GetCertificate().Leaf)
+	require.Equal(t, cert.PrivateKey, overlay.GetCertificate().PrivateKey)
+
+	signer, err := crypto.NewConsensusSigner(bls.NewSigner())
+	require.NoError(t, err)
+
+	err = overlay.UpdateCertificate(cert.Leaf, signer)
+	require.EqualError(t, err, "invalid certificate key: "+
+		"key reserved for consensus cannot be used for transport")
+
+	other := fake.MakeCertificate(t, 1, net.IPv4(127, 0, 0, 1))
+
+	err = overlay.UpdateCertificate(cert.Leaf, other.PrivateKey)
+	require.EqualError(t, err, "invalid certificate: mismatching key")
+
+	err = overlay.UpdateCertificate(cert.Leaf, "abc")
+	require.EqualError(t, err, "invalid certificate: unsupported key 'string'")
+
+	err = overlay.UpdateCertificate(other.Leaf, other.PrivateKey)
+	require.NoError(t, err)
+
+	signed := makeCertificate(t, time.Now().Add(time.Hour), other)
+
+	err = overlay.UpdateCertificate(signed.Leaf, signed.PrivateKey)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid certificate: not self-signed: ")
+
+	expired := makeCertificate(t, time.Now().Add(-time.Hour), nil)
+
+	err = overlay.UpdateCertificate(expired.Leaf, expired.PrivateKey)
+	require.EqualError(t, err, "invalid certificate: expired or not yet valid")
+
+	cert = fake.MakeCertificate(t, 1)
+
+	err = overlay.UpdateCertificate(cert.Leaf, cert.PrivateKey)
+	require.EqualError(t, err, "invalid certificate: invalid hostname: "+
+		"x509: cannot validate certificate for 127.0.0.1 because it doesn't contain any IP SANs")
+
+	cert = fake.MakeCertificate(t, 1, net.IPv4(127, 0, 0, 1))
+
+	overlay.certs.Store(session.NewAddress("127.0.0.1:1"), other)
+	overlay.connMgr = fakeConnMgr{err: fake.GetError()}
+
+	err = overlay.UpdateCertificate(cert.Leaf, cert.PrivateKey)
+	require.EqualError(t, err, fake.Err("failed to share certificate: "+
+		"1 of 1 peers failed: couldn't open connection"))
+	require.Equal(t, cert.Leaf, overlay.GetCertificate().Leaf)
+
+	overlay.connMgr = fakeConnMgr{errConn: fake.GetError()}
+
+	err = overlay.UpdateCertificate(cert.Leaf, cert.PrivateKey)
+	require.EqualError(t, err, fake.Err("failed to share certificate: "+
+		"1 of 1 peers failed: couldn't call share"))
+
+	overlay.certs = fakeCerts{errStore: fake.GetError()}
+
+	err = overlay.UpdateCertificate(cert.Leaf, cert.PrivateKey)
+	require.EqualError(t, err, fake.Err("while storing"))
 }
 
 func TestConnManager_Acquire(t *testing.T) {
@@ -754,7 +1373,7 @@ func TestConnManager_Acquire(t *testing.T) {
 
 	defer dst.GracefulStop()
 
-	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore())
+	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore(), resolver.NewDNS())
 
 	certs := mgr.certs
 	certs.Store(mgr.myAddr, &tls.Certificate{})
@@ -767,16 +1386,190 @@ func TestConnManager_Acquire(t *testing.T) {
 	_, err = mgr.Acquire(dst.GetAddress())
 	require.NoError(t, err)
 	require.Len(t, mgr.conns, 1)
-	require.Equal(t, 2, mgr.counters[dst.GetAddress()])
+	require.Equal(t, 2, mgr.counters[conn.(*grpc.ClientConn)])
 
-	mgr.Release(dst.GetAddress())
-	mgr.Release(dst.GetAddress())
+	mgr.Release(dst.GetAddress(), conn)
+	mgr.Release(dst.GetAddress(), conn)
 	require.Len(t, mgr.conns, 0)
-	require.Equal(t, 0, mgr.counters[dst.GetAddress()])
+	require.Empty(t, mgr.counters)
+
+	// Unknown connections are ignored.
+	mgr.Release(dst.GetAddress(), conn)
+	mgr.Release(dst.GetAddress(), fakeConnection{})
+}
+
+func TestConnManager_Limit_Acquire(t *testing.T) {
+	addr := ParseAddress("127.0.0.1", 0)
+
+	dst1, err := NewMinogrpc(addr, nil)
+	require.NoError(t, err)
+
+	defer dst1.GracefulStop()
+
+	dst2, err := NewMinogrpc(addr, nil)
+	require.NoError(t, err)
+
+	defer dst2.GracefulStop()
+
+	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore(), resolver.NewDNS())
+	mgr.limit = 1
+
+	certs := mgr.certs
+	certs.Store(mgr.myAddr, &tls.Certificate{})
+	certs.Store(dst1.GetAddress(), dst1.GetCertificate())
+	certs.Store(dst2.GetAddress(), dst2.GetCertificate())
+
+	conn, err := mgr.Acquire(dst1.GetAddress())
+	require.NoError(t, err)
+
+	// The open connection is shared beyond the limit.
+	_, err = mgr.Acquire(dst1.GetAddress())
+	require.NoError(t, err)
+
+	_, err = mgr.Acquire(dst2.GetAddress())
+	require.EqualError(t, err, "limit of 1 connections reached: too many messages in flight")
+	require.True(t, xerrors.Is(err, mino.ErrBackpressure))
+
+	mgr.Release(dst1.GetAddress(), conn)
+	mgr.Release(dst1.GetAddress(), conn)
+
+	conn, err = mgr.Acquire(dst2.GetAddress())
+	require.NoError(t, err)
+
+	mgr.Release(dst2.GetAddress(), conn)
+}
+
+func TestConnManager_Failover_Acquire(t *testing.T) {
+	addr := ParseAddress("127.0.0.1", 0)
+
+	dst, err := NewMinogrpc(addr, nil)
+	require.NoError(t, err)
+
+	defer dst.GracefulStop()
+
+	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore(), resolver.NewDNS())
+
+	// The first host is unreachable, so the connection must fail over to the
+	// second one.
+	host := dst.GetAddress().(session.Address).GetDialAddress()
+	to := session.NewMultiAddress("127.0.0.1:1", host)
+
+	certs := mgr.certs
+	certs.Store(mgr.myAddr, &tls.Certificate{})
+	certs.Store(to, dst.GetCertificate())
+
+	conn, err := mgr.Acquire(to)
+	require.NoError(t, err)
+	require.NotNil(t, conn)
+	require.Equal(t, host, mgr.targets[to])
+	require.Contains(t, mgr.failures, "127.0.0.1:1")
+
+	_, err = mgr.Acquire(to)
+	require.NoError(t, err)
+	require.Equal(t, 2, mgr.counters[conn.(*grpc.ClientConn)])
+
+	mgr.Release(to, conn)
+	mgr.Release(to, conn)
+	require.Empty(t, mgr.conns)
+	require.Empty(t, mgr.targets)
+}
+
+func TestConnManager_Replace_Acquire(t *testing.T) {
+	addr := ParseAddress("127.0.0.1", 0)
+
+	dst, err := NewMinogrpc(addr, nil)
+	require.NoError(t, err)
+
+	defer dst.GracefulStop()
+
+	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore(), resolver.NewDNS())
+
+	host := dst.GetAddress().(session.Address).GetDialAddress()
+	to := session.NewMultiAddress(host, "127.0.0.1:1")
+
+	certs := mgr.certs
+	certs.Store(mgr.myAddr, &tls.Certificate{})
+	certs.Store(to, dst.GetCertificate())
+
+	old, err := mgr.Acquire(to)
+	require.NoError(t, err)
+
+	// The connection is made unhealthy so that it is replaced, while its user
+	// still holds it.
+	old.(*grpc.ClientConn).Close()
+
+	conn, err := mgr.Acquire(to)
+	require.NoError(t, err)
+	require.NotEqual(t, old, conn)
+	require.Len(t, mgr.conns, 1)
+	require.Len(t, mgr.counters, 2)
+
+	mgr.Release(to, old)
+	require.Len(t, mgr.conns, 1)
+	require.Len(t, mgr.counters, 1)
+
+	mgr.Release(to, conn)
+	require.Empty(t, mgr.conns)
+	require.Empty(t, mgr.counters)
+}
+
+func TestConnManager_Resolve_Acquire(t *testing.T) {
+	addr := ParseAddress("127.0.0.1", 0)
+
+	dst, err := NewMinogrpc(addr, nil)
+	require.NoError(t, err)
+
+	defer dst.GracefulStop()
+
+	host := dst.GetAddress().(session.Address).GetDialAddress()
+
+	r := fakeResolver{hosts: map[string][]string{
+		"srv:_dela._tcp.dela": {"127.0.0.1:1", host},
+	}}
+
+	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore(), r)
+
+	to := session.NewAddress("srv:_dela._tcp.dela")
+
+	certs := mgr.certs
+	certs.Store(mgr.myAddr, &tls.Certificate{})
+	certs.Store(to, dst.GetCertificate())
+
+	conn, err := mgr.Acquire(to)
+	require.NoError(t, err)
+	require.Equal(t, host, mgr.targets[to])
+	require.Contains(t, mgr.failures, "127.0.0.1:1")
+
+	mgr.Release(to, conn)
+
+	// A host that cannot be resolved is skipped.
+	to = session.NewMultiAddress("srv:_unknown._tcp.dela", host)
+	certs.Store(to, dst.GetCertificate())
+
+	conn, err = mgr.Acquire(to)
+	require.NoError(t, err)
+	require.Equal(t, host, mgr.targets[to])
+
+	mgr.Release(to, conn)
+
+	mgr.resolver = fakeResolver{err: fake.GetError()}
+
+	_, err = mgr.Acquire(to)
+	require.EqualError(t, err,
+		fake.Err("failed to dial: failed to resolve: no host resolved"))
+}
+
+func TestConnManager_SortByHealth(t *testing.T) {
+	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore(), resolver.NewDNS())
+
+	mgr.failures["A"] = time.Now()
+	mgr.failures["C"] = time.Now().Add(-failoverCooldown)
+
+	require.Equal(t, []string{"B", "C", "A"}, mgr.sortByHealth([]string{"A", "B", "C"}))
 }
 
 func TestConnManager_FailLoadDistantCert_Acquire(t *testing.T) {
-	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore())
+	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore(), resolver.NewDNS())
 	mgr.certs = fakeCerts{errLoad: fake.GetError()}
 
 	_, err := mgr.Acquire(fake.NewAddress(0))
@@ -784,14 +1577,14 @@ func TestConnManager_FailLoadDistantCert_Acquire(t *testing.T) {
 }
 
 func TestConnManager_MissingCert_Acquire(t *testing.T) {
-	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore())
+	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore(), resolver.NewDNS())
 
 	_, err := mgr.Acquire(fake.NewAddress(1))
 	require.EqualError(t, err, "failed to retrieve transport credential: certificate for 'fake.Address[1]' not found")
 }
 
 func TestConnManager_FailLoadOwnCert_Acquire(t *testing.T) {
-	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore())
+	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore(), resolver.NewDNS())
 	mgr.certs = fakeCerts{
 		errLoad: fake.GetError(),
 		counter: fake.NewCounter(1),
@@ -802,7 +1595,7 @@ func TestConnManager_FailLoadOwnCert_Acquire(t *testing.T) {
 }
 
 func TestConnManager_MissingOwnCert_Acquire(t *testing.T) {
-	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore())
+	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore(), resolver.NewDNS())
 	mgr.certs.Store(fake.NewAddress(1), fake.MakeCertificate(t, 0))
 
 	_, err := mgr.Acquire(fake.NewAddress(1))
@@ -810,7 +1603,7 @@ func TestConnManager_MissingOwnCert_Acquire(t *testing.T) {
 }
 
 func TestConnManager_BadAddress_Acquire(t *testing.T) {
-	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore())
+	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore(), resolver.NewDNS())
 	mgr.certs.Store(fake.NewAddress(0), fake.MakeCertificate(t, 0))
 
 	_, err := mgr.Acquire(mgr.myAddr)
@@ -825,7 +1618,7 @@ func TestConnManager_BadTracer_Acquire(t *testing.T) {
 
 	defer dst.GracefulStop()
 
-	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore())
+	mgr := newConnManager(fake.NewAddress(0), certs.NewInMemoryStore(), resolver.NewDNS())
 
 	getTracerForAddr = fake.GetTracerForAddrWithError
 
@@ -838,7 +1631,7 @@ func TestConnManager_BadTracer_Acquire(t *testing.T) {
 	require.EqualError(
 		t,
 		err,
-		fmt.Sprintf("failed to get tracer for addr %s: %s", dst.GetAddress(), fake.GetError().Error()),
+		fmt.Sprintf("failed to dial: failed to get tracer for addr %s: %s", dst.GetAddress(), fake.GetError().Error()),
 	)
 
 	getTracerForAddr = tracing.GetTracerForAddr
@@ -870,6 +1663,40 @@ func makeInstances(t *testing.T, n int, call *fake.Call) ([]mino.Mino, []mino.RP
 	return mm, rpcs
 }
 
+// makeCertificate returns a certificate for the local host, signed by the
+// parent or self-signed when it is nil, that expires at the given time.
+func makeCertificate(t *testing.T, notAfter time.Time, parent *tls.Certificate) *tls.Certificate {
+	priv, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             notAfter.Add(-time.Hour),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	issuer, key := tmpl, interface{}(priv)
+	if parent != nil {
+		issuer, key = parent.Leaf, parent.PrivateKey
+	}
+
+	buf, err := x509.CreateCertificate(rand.Reader, tmpl, issuer, &priv.PublicKey, key)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(buf)
+	require.NoError(t, err)
+
+	return &tls.Certificate{
+		Certificate: [][]byte{buf},
+		PrivateKey:  priv,
+		Leaf:        leaf,
+	}
+}
+
 func checkError(t *testing.T, err error, mm ...mino.Mino) {
 	require.Error(t, err)
 
@@ -882,6 +1709,29 @@ func checkError(t *testing.T, err error, mm ...mino.Mino) {
 	t.Fatal("unexpected error", err)
 }
 
+// withPeer returns a context with the certificate presented by the peer during
+// the TLS handshake.
+func withPeer(ctx context.Context, cert *tls.Certificate) context.Context {
+	info := credentials.TLSInfo{
+		State: tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert.Leaf},
+		},
+	}
+
+	return peer.NewContext(ctx, &peer.Peer{AuthInfo: info})
+}
+
+func getScores(board scores.Board) []scores.Peer {
+	var peers []scores.Peer
+
+	board.Range(func(p scores.Peer) bool {
+		peers = append(peers, p)
+		return true
+	})
+
+	return peers
+}
+
 func makeCtx(kv ...string) context.Context {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -889,6 +1739,33 @@ func makeCtx(kv ...string) context.Context {
 	return metadata.NewIncomingContext(ctx, metadata.Pairs(kv...))
 }
 
+// countingCompressor is a compressor that counts the messages it compresses
+// and decompresses.
+//
+// - implements encoding.Compressor
+type countingCompressor struct {
+	encoding.Compressor
+
+	compressed   int32
+	decompressed int32
+}
+
+func (c *countingCompressor) Name() string {
+	return "counting"
+}
+
+func (c *countingCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	atomic.AddInt32(&c.compressed, 1)
+
+	return c.Compressor.Compress(w)
+}
+
+func (c *countingCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	atomic.AddInt32(&c.decompressed, 1)
+
+	return c.Compressor.Decompress(r)
+}
+
 type testHandler struct {
 	mino.UnsupportedHandler
 	call *fake.Call
@@ -922,6 +1799,71 @@ func (h testHandler) Process(req mino.Request) (serde.Message, error) {
 	return req.Message, nil
 }
 
+// blobMessage is a message that is serialized into its raw content.
+//
+// - implements serde.Message
+type blobMessage []byte
+
+func (m blobMessage) Serialize(serde.Context) ([]byte, error) {
+	return m, nil
+}
+
+// countingSerdeCompressor is a DEFLATE compressor with its own algorithm that
+// counts the payloads it compresses and decompresses.
+//
+// - implements serde.Compressor
+type countingSerdeCompressor struct {
+	serde.FlateCompressor
+
+	compressed   int32
+	decompressed int32
+}
+
+func (c *countingSerdeCompressor) GetAlgorithm() serde.Algorithm {
+	return 0xf0
+}
+
+func (c *countingSerdeCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	atomic.AddInt32(&c.compressed, 1)
+
+	return c.FlateCompressor.NewWriter(w)
+}
+
+func (c *countingSerdeCompressor) NewReader(r io.Reader) io.ReadCloser {
+	atomic.AddInt32(&c.decompressed, 1)
+
+	return c.FlateCompressor.NewReader(r)
+}
+
+// blobFactory deserializes the blob messages.
+//
+// - implements serde.Factory
+type blobFactory struct{}
+
+func (blobFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	return blobMessage(data), nil
+}
+
+// echoHandler is a handler that sends back the messages of a stream until it is
+// closed.
+type echoHandler struct {
+	mino.UnsupportedHandler
+}
+
+func (echoHandler) Stream(out mino.Sender, in mino.Receiver) error {
+	for {
+		from, msg, err := in.Recv(context.Background())
+		if err != nil {
+			return err
+		}
+
+		err = <-out.Send(msg, from)
+		if err != nil {
+			return err
+		}
+	}
+}
+
 type emptyHandler struct {
 	mino.UnsupportedHandler
 }
@@ -948,6 +1890,24 @@ func (s fakeSrvStream) Recv() (*ptypes.Packet, error) {
 	return nil, s.err
 }
 
+type fakeResolver struct {
+	hosts map[string][]string
+	err   error
+}
+
+func (r fakeResolver) Resolve(ctx context.Context, host string) ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	hosts, found := r.hosts[host]
+	if !found {
+		return []string{host}, nil
+	}
+
+	return hosts, nil
+}
+
 type fakeCerts struct {
 	certs.Storage
 	err      error
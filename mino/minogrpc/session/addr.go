@@ -7,7 +7,10 @@ package session
 
 import (
 	"fmt"
+	"net"
 	"net/url"
+	"regexp"
+	"strings"
 
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/serde"
@@ -17,8 +20,20 @@ import (
 const (
 	orchestratorCode = "O"
 	followerCode     = "F"
+
+	// hostSeparator separates the hosts of a participant that advertises
+	// several addresses.
+	hostSeparator = ","
+
+	// RelayScheme is the prefix of the hosts of the participants that are
+	// reached through a relay, as relay://public-addr/node-id.
+	RelayScheme = "relay://"
 )
 
+// relayIDMatch defines the accepted identifiers of the nodes behind a relay,
+// which are used as the hostname of their certificate.
+var relayIDMatch = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9-]{0,62}$`)
+
 // Address is a representation of the network Address of a participant. The
 // overlay implementation requires a difference between an orchestrator and its
 // source address, where the former initiates a protocol and the later
@@ -47,20 +62,77 @@ func NewAddress(host string) Address {
 	return Address{host: host}
 }
 
+// NewMultiAddress creates a new address of a participant that can be reached
+// with several hosts, e.g. an internal and an external one. The hosts are tried
+// in order when dialing the participant.
+func NewMultiAddress(hosts ...string) Address {
+	return Address{host: strings.Join(hosts, hostSeparator)}
+}
+
+// NewRelayAddress creates a new address of a participant that cannot be dialed,
+// e.g. behind a NAT, and that is reached through the relay of the public
+// address, where it is registered with the identifier.
+func NewRelayAddress(public, id string) Address {
+	return Address{host: fmt.Sprintf("%s%s/%s", RelayScheme, public, id)}
+}
+
+// ParseRelay returns the public address of the relay and the identifier of the
+// node when the host is reached through a relay, otherwise false.
+func ParseRelay(host string) (public string, id string, ok bool) {
+	if !strings.HasPrefix(host, RelayScheme) {
+		return "", "", false
+	}
+
+	rest := strings.TrimPrefix(host, RelayScheme)
+
+	sep := strings.LastIndex(rest, "/")
+	if sep < 0 {
+		return "", "", false
+	}
+
+	public, id = rest[:sep], rest[sep+1:]
+
+	_, _, err := net.SplitHostPort(public)
+	if err != nil || !relayIDMatch.MatchString(id) {
+		return "", "", false
+	}
+
+	return public, id, true
+}
+
 // GetDialAddress returns a string formatted to be understood by grpc.Dial()
-// functions.
+// functions. It is the first host when the address has several of them.
 func (a Address) GetDialAddress() string {
-	return a.host
+	return a.GetDialAddresses()[0]
 }
 
-// GetHostname parses the address to extract the hostname.
+// GetDialAddresses returns the hosts of the address in order of preference.
+func (a Address) GetDialAddresses() []string {
+	return strings.Split(a.host, hostSeparator)
+}
+
+// GetHostname parses the address to extract the hostname. It is the hostname
+// of the first host when the address has several of them.
 func (a Address) GetHostname() (string, error) {
-	url, err := url.Parse(fmt.Sprintf("//%s", a.host))
-	if err != nil {
-		return "", xerrors.Errorf("malformed address: %v", err)
+	return parseHostname(a.GetDialAddress())
+}
+
+// GetHostnames parses the address to extract the hostname of every host. The
+// hostname of a host reached through a relay is the identifier of the node.
+func (a Address) GetHostnames() ([]string, error) {
+	hosts := a.GetDialAddresses()
+	hostnames := make([]string, len(hosts))
+
+	for i, host := range hosts {
+		hostname, err := parseHostname(host)
+		if err != nil {
+			return nil, err
+		}
+
+		hostnames[i] = hostname
 	}
 
-	return url.Hostname(), nil
+	return hostnames, nil
 }
 
 // Equal implements mino.Address. It returns true if both addresses are exactly
@@ -163,3 +235,21 @@ func (f AddressFactory) FromText(text []byte) mino.Address {
 		orchestrator: str[0] == orchestratorCode[0],
 	}
 }
+
+func parseHostname(host string) (string, error) {
+	if strings.HasPrefix(host, RelayScheme) {
+		_, id, ok := ParseRelay(host)
+		if !ok {
+			return "", xerrors.Errorf("malformed relay address '%s'", host)
+		}
+
+		return id, nil
+	}
+
+	url, err := url.Parse(fmt.Sprintf("//%s", host))
+	if err != nil {
+		return "", xerrors.Errorf("malformed address: %v", err)
+	}
+
+	return url.Hostname(), nil
+}
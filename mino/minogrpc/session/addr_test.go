@@ -31,6 +31,72 @@ func TestAddress_GetHostname(t *testing.T) {
 	require.EqualError(t, err, "malformed address: parse \"//\\x00\": net/url: invalid control character in URL")
 }
 
+func TestAddress_MultiHomed(t *testing.T) {
+	addr := NewMultiAddress("10.0.0.1:2000", "example.com:2000")
+
+	require.Equal(t, "10.0.0.1:2000", addr.GetDialAddress())
+	require.Equal(t, []string{"10.0.0.1:2000", "example.com:2000"}, addr.GetDialAddresses())
+	require.Equal(t, "10.0.0.1:2000,example.com:2000", addr.String())
+
+	hostname, err := addr.GetHostname()
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.1", hostname)
+
+	hostnames, err := addr.GetHostnames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.1", "example.com"}, hostnames)
+
+	// The list of hosts is preserved by the text format.
+	data, err := addr.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, addr, AddressFactory{}.FromText(data))
+
+	addr = NewMultiAddress("127.0.0.1:2000", "\x00")
+	_, err = addr.GetHostnames()
+	require.EqualError(t, err, "malformed address: parse \"//\\x00\": net/url: invalid control character in URL")
+}
+
+func TestAddress_Relay(t *testing.T) {
+	addr := NewRelayAddress("example.com:2000", "home-1")
+
+	require.Equal(t, "relay://example.com:2000/home-1", addr.GetDialAddress())
+
+	public, id, ok := ParseRelay(addr.GetDialAddress())
+	require.True(t, ok)
+	require.Equal(t, "example.com:2000", public)
+	require.Equal(t, "home-1", id)
+
+	hostname, err := addr.GetHostname()
+	require.NoError(t, err)
+	require.Equal(t, "home-1", hostname)
+
+	data, err := addr.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, addr, AddressFactory{}.FromText(data))
+
+	// A node can be reached directly by some and through the relay by others.
+	addr = NewMultiAddress("10.0.0.1:2000", "relay://example.com:2000/home-1")
+
+	hostnames, err := addr.GetHostnames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.1", "home-1"}, hostnames)
+
+	_, _, ok = ParseRelay("example.com:2000")
+	require.False(t, ok)
+
+	_, _, ok = ParseRelay("relay://example.com:2000")
+	require.False(t, ok)
+
+	_, _, ok = ParseRelay("relay://example.com/home-1")
+	require.False(t, ok)
+
+	_, _, ok = ParseRelay("relay://example.com:2000/home.1")
+	require.False(t, ok)
+
+	_, err = NewAddress("relay://example.com").GetHostname()
+	require.EqualError(t, err, "malformed relay address 'relay://example.com'")
+}
+
 func TestAddress_Equal(t *testing.T) {
 	addr := NewAddress("127.0.0.1:2000")
 	require.True(t, addr.Equal(addr))
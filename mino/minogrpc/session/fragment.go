@@ -0,0 +1,220 @@
+// This file contains the fragmentation of the large messages of a session.
+//
+// A message larger than the fragment size is split into several fragments that
+// are sent as independent messages, so that each packet stays below the maximum
+// size of a gRPC message. The receiver reassembles the fragments before the
+// message is deserialized, whatever the order they arrive in.
+
+package session
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"go.dedis.ch/dela/core/memory"
+	"golang.org/x/xerrors"
+)
+
+// DefaultFragmentSize is the default size above which a message is fragmented.
+// It leaves room for the encoding of the packets below the default maximum
+// size of a gRPC message, which is 4MB.
+const DefaultFragmentSize = 2 << 20
+
+const (
+	// maxFragments is the maximum number of fragments of a message, which
+	// bounds the memory used by a partial message.
+	maxFragments = 1 << 10
+
+	// maxPartials is the maximum number of messages being reassembled at the
+	// same time by a session. The oldest is dropped beyond the limit.
+	maxPartials = 16
+)
+
+// fragmentMagic prefixes the fragments. A serialized message never starts
+// with a null byte.
+var fragmentMagic = []byte("\x00frg")
+
+// fragmentHeaderLen is the length of the magic, the identifier, the index and
+// the total number of fragments.
+var fragmentHeaderLen = len(fragmentMagic) + 8 + 4 + 4
+
+// fragment is a part of a message.
+type fragment struct {
+	id    uint64
+	index uint32
+	total uint32
+	data  []byte
+}
+
+// makeFragments splits the data into fragments of at most the size, each of
+// them encoded with the header.
+func makeFragments(id uint64, data []byte, size int) [][]byte {
+	total := (len(data) + size - 1) / size
+	frags := make([][]byte, total)
+
+	for i := range frags {
+		end := (i + 1) * size
+		if end > len(data) {
+			end = len(data)
+		}
+
+		buffer := make([]byte, fragmentHeaderLen, fragmentHeaderLen+end-i*size)
+		copy(buffer, fragmentMagic)
+
+		offset := len(fragmentMagic)
+		binary.BigEndian.PutUint64(buffer[offset:], id)
+		binary.BigEndian.PutUint32(buffer[offset+8:], uint32(i))
+		binary.BigEndian.PutUint32(buffer[offset+12:], uint32(total))
+
+		frags[i] = append(buffer, data[i*size:end]...)
+	}
+
+	return frags
+}
+
+// parseFragment returns the fragment encoded in the data, or false if the data
+// is not a fragment.
+func parseFragment(data []byte) (fragment, bool, error) {
+	if !bytes.HasPrefix(data, fragmentMagic) {
+		return fragment{}, false, nil
+	}
+
+	if len(data) < fragmentHeaderLen {
+		return fragment{}, true, xerrors.New("fragment is too short")
+	}
+
+	offset := len(fragmentMagic)
+
+	f := fragment{
+		id:    binary.BigEndian.Uint64(data[offset:]),
+		index: binary.BigEndian.Uint32(data[offset+8:]),
+		total: binary.BigEndian.Uint32(data[offset+12:]),
+		data:  data[fragmentHeaderLen:],
+	}
+
+	if f.total == 0 || f.total > maxFragments {
+		return f, true, xerrors.Errorf("invalid number of fragments: %d", f.total)
+	}
+
+	if f.index >= f.total {
+		return f, true, xerrors.Errorf("fragment index %d out of %d", f.index, f.total)
+	}
+
+	return f, true, nil
+}
+
+// partial is a message waiting for the rest of its fragments.
+type partial struct {
+	parts    [][]byte
+	received int
+	size     int
+}
+
+// reassembler collects the fragments of the messages until they are complete.
+// When a budget is set, the oldest incomplete messages are evicted to make room
+// for a new fragment that would exceed its cap.
+type reassembler struct {
+	sync.Mutex
+	partials map[string]*partial
+	order    []string
+	budget   *memory.Budget
+}
+
+func newReassembler() *reassembler {
+	return &reassembler{
+		partials: make(map[string]*partial),
+	}
+}
+
+// Add adds the fragment sent by the source, and it returns the message when
+// all of its fragments have arrived.
+func (r *reassembler) Add(source string, f fragment) ([]byte, bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	key := fmt.Sprintf("%s:%d", source, f.id)
+
+	p, found := r.partials[key]
+	if !found {
+		p = &partial{parts: make([][]byte, f.total)}
+
+		r.partials[key] = p
+		r.order = append(r.order, key)
+
+		for len(r.order) > maxPartials {
+			r.remove(r.order[0])
+		}
+	}
+
+	if len(p.parts) != int(f.total) {
+		r.remove(key)
+
+		return nil, false, xerrors.Errorf("mismatching number of fragments: %d != %d",
+			f.total, len(p.parts))
+	}
+
+	if p.parts[f.index] == nil {
+		err := r.reserve(key, len(f.data))
+		if err != nil {
+			r.remove(key)
+
+			return nil, false, xerrors.Errorf("message dropped: %w", err)
+		}
+
+		p.parts[f.index] = f.data
+		p.received++
+		p.size += len(f.data)
+	}
+
+	if p.received < len(p.parts) {
+		return nil, false, nil
+	}
+
+	r.remove(key)
+
+	return bytes.Join(p.parts, nil), true, nil
+}
+
+// reserve accounts for the size of a new fragment of the message, and it
+// evicts the oldest other messages while the budget is exhausted.
+func (r *reassembler) reserve(key string, size int) error {
+	for {
+		err := r.budget.Reserve(size)
+		if err == nil {
+			return nil
+		}
+
+		oldest := ""
+		for _, k := range r.order {
+			if k != key {
+				oldest = k
+				break
+			}
+		}
+
+		if oldest == "" {
+			return err
+		}
+
+		r.budget.Evict(r.partials[oldest].size)
+		r.remove(oldest)
+	}
+}
+
+func (r *reassembler) remove(key string) {
+	p, found := r.partials[key]
+	if found {
+		r.budget.Release(p.size)
+	}
+
+	delete(r.partials, key)
+
+	for i, k := range r.order {
+		if k == key {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package session
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/memory"
+	"golang.org/x/xerrors"
+)
+
+func TestMakeFragments(t *testing.T) {
+	data := []byte("abcdefghij")
+
+	frags := makeFragments(42, data, 4)
+	require.Len(t, frags, 3)
+
+	var parts [][]byte
+
+	for i, buffer := range frags {
+		f, ok, err := parseFragment(buffer)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, uint64(42), f.id)
+		require.Equal(t, uint32(i), f.index)
+		require.Equal(t, uint32(3), f.total)
+
+		parts = append(parts, f.data)
+	}
+
+	require.Equal(t, data, bytes.Join(parts, nil))
+
+	require.Len(t, makeFragments(1, data, 5), 2)
+}
+
+func TestParseFragment(t *testing.T) {
+	_, ok, err := parseFragment([]byte(`{}`))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, ok, err = parseFragment(fragmentMagic)
+	require.EqualError(t, err, "fragment is too short")
+	require.True(t, ok)
+
+	buffer := makeFragments(1, []byte("abc"), 1)[0]
+
+	// Total number of fragments is the last field of the header.
+	buffer[fragmentHeaderLen-1] = 0
+	_, _, err = parseFragment(buffer)
+	require.EqualError(t, err, "invalid number of fragments: 0")
+
+	buffer[fragmentHeaderLen-3] = 0xff
+	_, _, err = parseFragment(buffer)
+	require.EqualError(t, err, "invalid number of fragments: 16711680")
+
+	buffer = makeFragments(1, []byte("abc"), 1)[0]
+	buffer[fragmentHeaderLen-5] = 3
+	_, _, err = parseFragment(buffer)
+	require.EqualError(t, err, "fragment index 3 out of 3")
+}
+
+func TestReassembler_Add(t *testing.T) {
+	r := newReassembler()
+
+	data, done, err := r.Add("A", fragment{id: 1, index: 1, total: 2, data: []byte("b")})
+	require.NoError(t, err)
+	require.False(t, done)
+	require.Nil(t, data)
+
+	// A duplicate fragment is ignored.
+	_, done, err = r.Add("A", fragment{id: 1, index: 1, total: 2, data: []byte("b")})
+	require.NoError(t, err)
+	require.False(t, done)
+
+	// The same identifier from another source is another message.
+	_, done, err = r.Add("B", fragment{id: 1, index: 0, total: 2, data: []byte("x")})
+	require.NoError(t, err)
+	require.False(t, done)
+
+	data, done, err = r.Add("A", fragment{id: 1, index: 0, total: 2, data: []byte("a")})
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Equal(t, []byte("ab"), data)
+	require.Len(t, r.partials, 1)
+	require.Equal(t, []string{"B:1"}, r.order)
+
+	_, _, err = r.Add("B", fragment{id: 1, index: 0, total: 3})
+	require.EqualError(t, err, "mismatching number of fragments: 3 != 2")
+	require.Empty(t, r.partials)
+	require.Empty(t, r.order)
+}
+
+func TestReassembler_Evict(t *testing.T) {
+	r := newReassembler()
+
+	for i := 0; i < maxPartials+1; i++ {
+		_, _, err := r.Add("A", fragment{id: uint64(i), total: 2})
+		require.NoError(t, err)
+	}
+
+	require.Len(t, r.partials, maxPartials)
+	require.Len(t, r.order, maxPartials)
+	require.NotContains(t, r.partials, "A:0")
+}
+
+func TestReassembler_MemoryBudget(t *testing.T) {
+	r := newReassembler()
+	r.budget = memory.NewBudget(memory.OverlayModule, 4)
+
+	_, _, err := r.Add("A", fragment{id: 1, index: 0, total: 2, data: []byte("ab")})
+	require.NoError(t, err)
+
+	_, _, err = r.Add("B", fragment{id: 1, index: 0, total: 2, data: []byte("cd")})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), r.budget.GetUsage().Used)
+
+	// The oldest message is evicted to make room for the new fragment.
+	_, _, err = r.Add("C", fragment{id: 1, index: 0, total: 2, data: []byte("ef")})
+	require.NoError(t, err)
+	require.NotContains(t, r.partials, "A:1")
+	require.Equal(t, uint64(2), r.budget.GetUsage().Evictions)
+
+	// The fragments of a message evict the other messages, even the newer ones.
+	data, done, err := r.Add("B", fragment{id: 1, index: 1, total: 2, data: []byte("gh")})
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Equal(t, []byte("cdgh"), data)
+	require.Empty(t, r.partials)
+	require.Equal(t, int64(0), r.budget.GetUsage().Used)
+	require.Equal(t, uint64(4), r.budget.GetUsage().Evictions)
+
+	// A message that doesn't fit alone is dropped.
+	_, _, err = r.Add("C", fragment{id: 1, index: 1, total: 2, data: []byte("ijklm")})
+	require.True(t, xerrors.Is(err, memory.ErrExhausted))
+	require.EqualError(t, err, "message dropped: overlay needs 5 bytes with 0 out of 4 used: "+
+		"memory budget exhausted")
+	require.Empty(t, r.partials)
+	require.Equal(t, int64(0), r.budget.GetUsage().Used)
+}
@@ -23,9 +23,11 @@ import (
 	"io/ioutil"
 	"os"
 	"sync"
+	"sync/atomic"
 
 	"github.com/rs/zerolog"
 	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/core/memory"
 	"go.dedis.ch/dela/internal/traffic"
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/minogrpc/ptypes"
@@ -34,6 +36,9 @@ import (
 	"golang.org/x/xerrors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	// Registers the gzip compressor so that every node supports it.
+	_ "google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
@@ -41,12 +46,38 @@ import (
 // HandshakeKey is the key to the handshake store in the headers.
 const HandshakeKey = "handshake"
 
+// CompressionKey is the key to the compression of the messages of a stream in
+// the headers. The orchestrator announces the compression of its RPC and the
+// relays of the stream use the same one.
+const CompressionKey = "compression"
+
+// Compress returns the options of a call to compress the messages with the
+// algorithm. It returns no option if the algorithm is not supported by the
+// node, which means the messages are sent uncompressed.
+func Compress(c mino.Compression) []grpc.CallOption {
+	if c == mino.CompressionNone || encoding.GetCompressor(string(c)) == nil {
+		return nil
+	}
+
+	return []grpc.CallOption{grpc.UseCompressor(string(c))}
+}
+
+// compressionOf returns the compression announced in the headers, or none.
+func compressionOf(md metadata.MD) mino.Compression {
+	values := md.Get(CompressionKey)
+	if len(values) == 0 {
+		return mino.CompressionNone
+	}
+
+	return mino.Compression(values[0])
+}
+
 // ConnectionManager is an interface required by the session to open and release
 // connections to the relays.
 type ConnectionManager interface {
 	Len() int
 	Acquire(mino.Address) (grpc.ClientConnInterface, error)
-	Release(mino.Address)
+	Release(mino.Address, grpc.ClientConnInterface)
 }
 
 // Session is an interface for a stream session that allows to send messages to
@@ -118,6 +149,20 @@ type session struct {
 	relays  map[mino.Address]Relay
 	connMgr ConnectionManager
 	traffic *traffic.Traffic
+	sched   *Scheduler
+	class   mino.Class
+	// inflight holds a token for each packet being sent to a relay, when the
+	// number of packets in flight is limited.
+	inflight chan struct{}
+
+	// The messages larger than the fragment size are sent in several
+	// fragments, which are identified by a counter.
+	fragmentSize int
+	fragmentID   uint64
+	fragments    *reassembler
+
+	// budget accounts for the packets buffered by the session.
+	budget *memory.Budget
 
 	parents map[mino.Address]parent
 	// A read-write lock is used there as there are much more read requests than
@@ -125,6 +170,52 @@ type session struct {
 	parentsLock sync.RWMutex
 }
 
+// Option is the type of option to configure a session.
+type Option func(*session)
+
+// WithScheduler is an option to order the packets sent by the session with the
+// scheduler, according to the traffic class of the session.
+func WithScheduler(sched *Scheduler, class mino.Class) Option {
+	return func(s *session) {
+		s.sched = sched
+		s.class = class
+	}
+}
+
+// WithMaxInFlight is an option to limit the number of packets that the session
+// sends to its relays at the same time. A packet beyond the limit is dropped
+// with an error that wraps mino.ErrBackpressure, so that a slow peer doesn't
+// make the pending packets grow without bound. A limit of zero means no limit.
+func WithMaxInFlight(n int) Option {
+	return func(s *session) {
+		if n > 0 {
+			s.inflight = make(chan struct{}, n)
+		} else {
+			s.inflight = nil
+		}
+	}
+}
+
+// WithFragmentSize is an option to split the messages larger than the size into
+// several fragments, so that the packets don't exceed the maximum size of a
+// gRPC message. A size of zero disables the fragmentation.
+func WithFragmentSize(size int) Option {
+	return func(s *session) {
+		s.fragmentSize = size
+	}
+}
+
+// WithMemoryBudget is an option to account for the packets buffered by the
+// session, which are the packets waiting to be received and the fragments of
+// the incomplete messages. When the cap of the budget is reached, the oldest
+// incomplete messages are evicted, and a packet that still doesn't fit is
+// dropped with an error that wraps memory.ErrExhausted.
+func WithMemoryBudget(b *memory.Budget) Option {
+	return func(s *session) {
+		s.budget = b
+	}
+}
+
 // NewSession creates a new session for the provided parent relay.
 func NewSession(
 	md metadata.MD,
@@ -133,7 +224,10 @@ func NewSession(
 	pktFac router.PacketFactory,
 	ctx serde.Context,
 	connMgr ConnectionManager,
+	opts ...Option,
 ) Session {
+	queue := newNonBlockingQueue()
+
 	sess := &session{
 		logger:  dela.Logger.With().Str("addr", me.String()).Logger(),
 		md:      md,
@@ -142,12 +236,22 @@ func NewSession(
 		msgFac:  msgFac,
 		pktFac:  pktFac,
 		context: ctx,
-		queue:   newNonBlockingQueue(),
+		queue:   queue,
 		relays:  make(map[mino.Address]Relay),
 		connMgr: connMgr,
 		parents: make(map[mino.Address]parent),
+
+		fragmentSize: DefaultFragmentSize,
+		fragments:    newReassembler(),
 	}
 
+	for _, opt := range opts {
+		opt(sess)
+	}
+
+	queue.budget = sess.budget
+	sess.fragments.budget = sess.budget
+
 	switch os.Getenv(traffic.EnvVariable) {
 	case "log":
 		sess.traffic = traffic.NewTraffic(me, ioutil.Discard)
@@ -255,7 +359,9 @@ func (s *session) RecvPacket(from mino.Address, p *ptypes.Packet) (*ptypes.Ack,
 }
 
 // Send implements mino.Sender. It sends the message to the provided addresses
-// through the relays or the parent.
+// through the relays or the parent. A message larger than the fragment size is
+// sent in several fragments, and the sending stops at the first fragment that
+// fails.
 func (s *session) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
 	errs := make(chan error, len(addrs)+1)
 
@@ -275,51 +381,111 @@ func (s *session) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
 			return
 		}
 
-		s.parentsLock.RLock()
-		defer s.parentsLock.RUnlock()
+		if s.fragmentSize <= 0 || len(data) <= s.fragmentSize {
+			s.sendData(addrs, data, errs)
+			return
+		}
+
+		id := atomic.AddUint64(&s.fragmentID, 1)
+
+		for _, frag := range makeFragments(id, data, s.fragmentSize) {
+			fragErrs := make(chan error, cap(errs))
+
+			s.sendData(addrs, frag, fragErrs)
+			close(fragErrs)
 
-		for _, parent := range s.parents {
-			packet := parent.table.Make(s.me, addrs, data)
+			failed := false
+			for err := range fragErrs {
+				errs <- err
+				failed = true
+			}
 
-			sent := s.sendPacket(parent, packet, errs)
-			if sent {
+			if failed {
 				return
 			}
 		}
-
-		errs <- xerrors.New("packet ignored")
 	}()
 
 	return errs
 }
 
+// sendData sends the data to the addresses through the first parent that
+// accepts the packet.
+func (s *session) sendData(addrs []mino.Address, data []byte, errs chan error) {
+	s.parentsLock.RLock()
+	defer s.parentsLock.RUnlock()
+
+	for _, parent := range s.parents {
+		packet := parent.table.Make(s.me, addrs, data)
+
+		sent := s.sendPacket(parent, packet, errs)
+		if sent {
+			return
+		}
+	}
+
+	errs <- xerrors.New("packet ignored")
+}
+
 // Recv implements mino.Receiver. It waits for a message to arrive and returns
 // it, or returns an error if something wrong happens. The context can cancel
-// the blocking call.
+// the blocking call. The fragments of a message are collected until the
+// message is complete.
 func (s *session) Recv(ctx context.Context) (mino.Address, serde.Message, error) {
-	select {
-	case <-ctx.Done():
-		return nil, nil, ctx.Err()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
 
-	case err := <-s.errs:
-		if err != nil {
-			return nil, nil, xerrors.Errorf("stream closed unexpectedly: %v", err)
-		}
+		case err := <-s.errs:
+			if err != nil {
+				return nil, nil, xerrors.Errorf("stream closed unexpectedly: %v", err)
+			}
 
-		return nil, nil, io.EOF
+			return nil, nil, io.EOF
 
-	case packet := <-s.queue.Channel():
-		msg, err := s.msgFac.Deserialize(s.context, packet.GetMessage())
-		if err != nil {
-			return nil, nil, xerrors.Errorf("message: %v", err)
+		case packet := <-s.queue.Channel():
+			data, complete, err := s.reassemble(packet)
+			if err != nil {
+				return nil, nil, xerrors.Errorf("fragment: %v", err)
+			}
+
+			if !complete {
+				continue
+			}
+
+			msg, err := s.msgFac.Deserialize(s.context, data)
+			if err != nil {
+				return nil, nil, xerrors.Errorf("message: %v", err)
+			}
+
+			// The source address is wrapped so that an orchestrator will look
+			// like its actual source address to the caller.
+			from := newWrapAddress(packet.GetSource())
+
+			return from, msg, nil
 		}
+	}
+}
+
+// reassemble returns the message of the packet, or false if the packet is a
+// fragment of a message that is not complete yet.
+func (s *session) reassemble(packet router.Packet) ([]byte, bool, error) {
+	frag, ok, err := parseFragment(packet.GetMessage())
+	if err != nil {
+		return nil, false, err
+	}
 
-		// The source address is wrapped so that an orchestrator will look like
-		// its actual source address to the caller.
-		from := newWrapAddress(packet.GetSource())
+	if !ok {
+		return packet.GetMessage(), true, nil
+	}
 
-		return from, msg, nil
+	source, err := packet.GetSource().MarshalText()
+	if err != nil {
+		return nil, false, xerrors.Errorf("failed to marshal source: %v", err)
 	}
+
+	return s.fragments.Add(string(source), frag)
 }
 
 func (s *session) sendPacket(p parent, pkt router.Packet, errs chan error) bool {
@@ -362,6 +528,11 @@ func (s *session) sendTo(p parent, to mino.Address, pkt router.Packet, errs chan
 		relay = p.relay
 	} else {
 		relay, err = s.setupRelay(p, to)
+		if xerrors.Is(err, mino.ErrBackpressure) {
+			// The peer is not unreachable, therefore the routes are kept.
+			errs <- xerrors.Errorf("%v dropped the packet: %w", s.me, err)
+			return
+		}
 		if err != nil {
 			s.logger.Warn().Err(err).Stringer("to", to).Msg("failed to setup relay")
 
@@ -372,11 +543,22 @@ func (s *session) sendTo(p parent, to mino.Address, pkt router.Packet, errs chan
 		}
 	}
 
+	if !s.acquireSlot() {
+		errs <- xerrors.Errorf("%v dropped the packet to %v: %w",
+			s.me, relay.GetDistantAddress(), mino.ErrBackpressure)
+		return
+	}
+
+	defer s.releaseSlot()
+
 	ctx := p.relay.Stream().Context()
 
 	s.traffic.LogSend(ctx, relay.GetDistantAddress(), pkt)
 
+	release := s.sched.Acquire(ctx, relay.GetDistantAddress(), s.class)
 	ack, err := relay.Send(ctx, pkt)
+	release()
+
 	if to == nil && err != nil {
 		// The parent relay is unavailable which means the session will
 		// eventually close.
@@ -404,6 +586,28 @@ func (s *session) sendTo(p parent, to mino.Address, pkt router.Packet, errs chan
 	}
 }
 
+// acquireSlot returns true if the packet can be sent without exceeding the
+// limit of packets in flight, in which case the slot must be released after the
+// packet is sent.
+func (s *session) acquireSlot() bool {
+	if s.inflight == nil {
+		return true
+	}
+
+	select {
+	case s.inflight <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *session) releaseSlot() {
+	if s.inflight != nil {
+		<-s.inflight
+	}
+}
+
 func (s *session) setupRelay(p parent, addr mino.Address) (Relay, error) {
 	s.Lock()
 	defer s.Unlock()
@@ -422,7 +626,7 @@ func (s *session) setupRelay(p parent, addr mino.Address) (Relay, error) {
 	// 1. Acquire a connection to the distant peer.
 	conn, err := s.connMgr.Acquire(addr)
 	if err != nil {
-		return nil, xerrors.Errorf("failed to dial: %v", err)
+		return nil, xerrors.Errorf("failed to dial: %w", err)
 	}
 
 	md := s.md.Copy()
@@ -432,9 +636,11 @@ func (s *session) setupRelay(p parent, addr mino.Address) (Relay, error) {
 
 	cl := ptypes.NewOverlayClient(conn)
 
-	stream, err := cl.Stream(ctx, grpc.WaitForReady(false))
+	opts := append(Compress(compressionOf(s.md)), grpc.WaitForReady(false))
+
+	stream, err := cl.Stream(ctx, opts...)
 	if err != nil {
-		s.connMgr.Release(addr)
+		s.connMgr.Release(addr, conn)
 		return nil, xerrors.Errorf("client: %v", err)
 	}
 
@@ -442,7 +648,7 @@ func (s *session) setupRelay(p parent, addr mino.Address) (Relay, error) {
 	// session at the other end.
 	_, err = stream.Header()
 	if err != nil {
-		s.connMgr.Release(addr)
+		s.connMgr.Release(addr, conn)
 		return nil, xerrors.Errorf("failed to receive header: %v", err)
 	}
 
@@ -461,7 +667,7 @@ func (s *session) setupRelay(p parent, addr mino.Address) (Relay, error) {
 			newRelay.Close()
 
 			// Let the manager know it can close the connection if necessary.
-			s.connMgr.Release(addr)
+			s.connMgr.Release(addr, conn)
 
 			s.Done()
 
@@ -577,7 +783,8 @@ func (r *unicastRelay) Send(ctx context.Context, p router.Packet) (*ptypes.Ack,
 
 	ctx = metadata.NewOutgoingContext(ctx, r.md)
 
-	ack, err := client.Forward(ctx, &ptypes.Packet{Serialized: data})
+	ack, err := client.Forward(ctx, &ptypes.Packet{Serialized: data},
+		Compress(compressionOf(r.md))...)
 	if err != nil {
 		return nil, xerrors.Errorf("client: %w", err)
 	}
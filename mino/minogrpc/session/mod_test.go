@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/memory"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/internal/traffic"
 	"go.dedis.ch/dela/mino"
@@ -21,6 +22,19 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+func TestCompress(t *testing.T) {
+	require.Len(t, Compress(mino.CompressionGzip), 1)
+	require.Nil(t, Compress(mino.CompressionNone))
+	require.Nil(t, Compress(mino.Compression("unknown")))
+}
+
+func TestCompressionOf(t *testing.T) {
+	md := metadata.Pairs(CompressionKey, "gzip")
+	require.Equal(t, mino.CompressionGzip, compressionOf(md))
+
+	require.Equal(t, mino.CompressionNone, compressionOf(metadata.MD{}))
+}
+
 func TestSession_New(t *testing.T) {
 	curr := os.Getenv(traffic.EnvVariable)
 	defer os.Setenv(traffic.EnvVariable, curr)
@@ -36,6 +50,22 @@ func TestSession_New(t *testing.T) {
 	os.Unsetenv(traffic.EnvVariable)
 	sess = NewSession(nil, fake.NewAddress(999), nil, nil, fake.NewContext(), nil)
 	require.Nil(t, sess.(*session).traffic)
+	require.Nil(t, sess.(*session).inflight)
+
+	sess = NewSession(nil, fake.NewAddress(999), nil, nil, fake.NewContext(), nil,
+		WithMaxInFlight(5))
+	require.Equal(t, 5, cap(sess.(*session).inflight))
+
+	sess = NewSession(nil, fake.NewAddress(999), nil, nil, fake.NewContext(), nil,
+		WithMaxInFlight(5), WithMaxInFlight(0))
+	require.Nil(t, sess.(*session).inflight)
+
+	budget := memory.NewBudget(memory.OverlayModule, 10)
+
+	sess = NewSession(nil, fake.NewAddress(999), nil, nil, fake.NewContext(), nil,
+		WithMemoryBudget(budget))
+	require.Same(t, budget, sess.(*session).queue.(*NonBlockingQueue).budget)
+	require.Same(t, budget, sess.(*session).fragments.budget)
 }
 
 func TestSession_getNumParents(t *testing.T) {
@@ -234,6 +264,87 @@ func TestSession_Send(t *testing.T) {
 	require.EqualError(t, <-errs, "packet ignored")
 }
 
+func TestSession_Send_Fragments(t *testing.T) {
+	stream := &fakeStream{calls: &fake.Call{}}
+	key := fake.NewAddress(123)
+
+	sess := &session{
+		me:      fake.NewAddress(600),
+		context: fake.NewContext(),
+		queue:   newNonBlockingQueue(),
+		relays:  make(map[mino.Address]Relay),
+		parents: map[mino.Address]parent{
+			key: {
+				relay: &streamRelay{stream: stream},
+				table: fakeTable{},
+			},
+		},
+		fragmentSize: 1,
+	}
+
+	// The message is serialized into two bytes, thus two fragments.
+	errs := sess.Send(fake.Message{}, newWrapAddress(fake.NewAddress(0)))
+	require.NoError(t, <-errs)
+	require.Equal(t, 2, stream.calls.Len())
+
+	// The sending stops at the first fragment that fails.
+	sess.parents[key] = parent{
+		relay: &streamRelay{stream: stream},
+		table: fakeTable{err: fake.GetError()},
+	}
+	errs = sess.Send(fake.Message{})
+	require.EqualError(t, <-errs, fake.Err("no route to fake.Address[400]"))
+	require.NoError(t, <-errs)
+	require.Equal(t, 3, stream.calls.Len())
+}
+
+func TestSession_Send_Backpressure(t *testing.T) {
+	key := fake.NewAddress(123)
+
+	sess := &session{
+		me:       fake.NewAddress(600),
+		context:  fake.NewContext(),
+		queue:    newNonBlockingQueue(),
+		relays:   make(map[mino.Address]Relay),
+		inflight: make(chan struct{}, 1),
+		parents: map[mino.Address]parent{
+			key: {
+				relay: &streamRelay{stream: &fakeStream{}},
+				table: fakeTable{route: fake.NewAddress(0), errFail: fake.GetError()},
+			},
+		},
+	}
+
+	sess.relays[fake.NewAddress(0)] = NewStreamRelay(fake.NewAddress(0), &fakeStream{}, sess.context)
+
+	// A packet is already in flight.
+	sess.inflight <- struct{}{}
+
+	errs := sess.Send(fake.Message{}, fake.NewAddress(0))
+	err := <-errs
+	require.EqualError(t, err, "fake.Address[600] dropped the packet to "+
+		"fake.Address[0]: too many messages in flight")
+	require.True(t, xerrors.Is(err, mino.ErrBackpressure))
+	require.NoError(t, <-errs)
+
+	<-sess.inflight
+
+	errs = sess.Send(fake.Message{}, fake.NewAddress(0))
+	require.NoError(t, <-errs)
+	require.Len(t, sess.inflight, 0)
+
+	// The routes are kept when the connection manager is at its limit.
+	delete(sess.relays, fake.NewAddress(0))
+	sess.connMgr = fakeConnMgr{err: xerrors.Errorf("oops: %w", mino.ErrBackpressure)}
+
+	errs = sess.Send(fake.Message{}, fake.NewAddress(0))
+	err = <-errs
+	require.EqualError(t, err, "fake.Address[600] dropped the packet: "+
+		"failed to dial: oops: too many messages in flight")
+	require.True(t, xerrors.Is(err, mino.ErrBackpressure))
+	require.NoError(t, <-errs)
+}
+
 func TestSession_SetupRelay(t *testing.T) {
 	sess := &session{
 		connMgr: fakeConnMgr{},
@@ -315,6 +426,31 @@ func TestSession_Recv(t *testing.T) {
 	require.Equal(t, context.Canceled, err)
 }
 
+func TestSession_Recv_Fragments(t *testing.T) {
+	sess := &session{
+		queue:     newNonBlockingQueue(),
+		msgFac:    fake.MessageFactory{},
+		errs:      make(chan error, 1),
+		fragments: newReassembler(),
+	}
+
+	frags := makeFragments(1, []byte(`{}`), 1)
+
+	// The fragments are reassembled whatever the order they arrive in.
+	sess.queue.Push(fakePkt{msg: frags[1]})
+	sess.queue.Push(fakePkt{msg: frags[0]})
+
+	from, msg, err := sess.Recv(context.Background())
+	require.NoError(t, err)
+	require.True(t, from.Equal(fake.NewAddress(700)))
+	require.Equal(t, fake.Message{}, msg)
+	require.Empty(t, sess.fragments.partials)
+
+	sess.queue.Push(fakePkt{msg: fragmentMagic})
+	_, _, err = sess.Recv(context.Background())
+	require.EqualError(t, err, "fragment: fragment is too short")
+}
+
 func TestSession_OnFailure(t *testing.T) {
 	sess := &session{
 		queue: newNonBlockingQueue(),
@@ -434,6 +570,7 @@ type fakePkt struct {
 	router.Packet
 	dest  mino.Address
 	empty bool
+	msg   []byte
 	err   error
 }
 
@@ -450,6 +587,10 @@ func (p fakePkt) GetDestination() []mino.Address {
 }
 
 func (p fakePkt) GetMessage() []byte {
+	if p.msg != nil {
+		return p.msg
+	}
+
 	return []byte(`{}`)
 }
 
@@ -543,7 +684,7 @@ func (mgr fakeConnMgr) Acquire(mino.Address) (grpc.ClientConnInterface, error) {
 	return conn, mgr.err
 }
 
-func (mgr fakeConnMgr) Release(mino.Address) {}
+func (mgr fakeConnMgr) Release(mino.Address, grpc.ClientConnInterface) {}
 
 type fakeConnection struct {
 	grpc.ClientConnInterface
@@ -9,6 +9,7 @@ import (
 	"math"
 	"sync"
 
+	"go.dedis.ch/dela/core/memory"
 	"go.dedis.ch/dela/mino/router"
 	"golang.org/x/xerrors"
 )
@@ -36,6 +37,9 @@ type NonBlockingQueue struct {
 	limit   float64
 	running bool
 	ch      chan router.Packet
+
+	// budget accounts for the messages of the packets waiting in the buffer.
+	budget *memory.Budget
 }
 
 func newNonBlockingQueue() *NonBlockingQueue {
@@ -72,6 +76,12 @@ func (q *NonBlockingQueue) Push(msg router.Packet) error {
 			}
 		}
 
+		err := q.budget.Reserve(len(msg.GetMessage()))
+		if err != nil {
+			q.Unlock()
+			return xerrors.Errorf("queue is full: %w", err)
+		}
+
 		q.buffer = append(q.buffer, msg)
 
 		if !q.running {
@@ -101,6 +111,8 @@ func (q *NonBlockingQueue) pushAndWait() {
 
 		q.Unlock()
 
+		q.budget.Release(len(msg.GetMessage()))
+
 		// Wait for the channel to be available to writings.
 		q.ch <- msg
 	}
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/memory"
+	"golang.org/x/xerrors"
 )
 
 func TestNonBlockingQueue_Push(t *testing.T) {
@@ -46,6 +48,30 @@ func TestNonBlockingQueue_Push(t *testing.T) {
 	require.Equal(t, 0, cap(queue.buffer))
 }
 
+func TestNonBlockingQueue_MemoryBudget(t *testing.T) {
+	queue := newNonBlockingQueue()
+	queue.budget = memory.NewBudget(memory.OverlayModule, 5)
+	queue.running = true
+
+	// The packet sent to the channel is not buffered.
+	require.NoError(t, queue.Push(fakePkt{msg: []byte("abc")}))
+	require.NoError(t, queue.Push(fakePkt{msg: []byte("abc")}))
+	require.Equal(t, int64(3), queue.budget.GetUsage().Used)
+
+	err := queue.Push(fakePkt{msg: []byte("abc")})
+	require.True(t, xerrors.Is(err, memory.ErrExhausted))
+	require.Len(t, queue.buffer, 1)
+
+	go queue.pushAndWait()
+
+	waitPkt(t, queue)
+	waitPkt(t, queue)
+
+	require.Eventually(t, func() bool {
+		return queue.budget.GetUsage().Used == 0
+	}, time.Second, time.Millisecond)
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
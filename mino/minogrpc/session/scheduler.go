@@ -0,0 +1,134 @@
+// This file contains the implementation of the scheduler that orders the
+// packets sent to a peer according to their traffic class.
+
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.dedis.ch/dela/mino"
+)
+
+// DefaultMaxDelay is the default maximum amount of time a packet waits for the
+// packets of higher classes to be sent.
+const DefaultMaxDelay = 500 * time.Millisecond
+
+// Scheduler orders the packets sent to the same peer so that the packets of a
+// class wait while packets of a higher class are in flight to that peer. The
+// packets of the highest class never wait, which means a chain of relays
+// cannot deadlock. A packet waits at most for the maximum delay so that the
+// lower classes are not starved.
+type Scheduler struct {
+	sync.Mutex
+
+	maxDelay time.Duration
+	peers    map[string]*peerTraffic
+}
+
+// peerTraffic is the traffic in flight to a peer.
+type peerTraffic struct {
+	inflight map[mino.Class]int
+	// wake is closed when a packet to the peer has been sent.
+	wake chan struct{}
+}
+
+// NewScheduler creates a new scheduler with the maximum delay of a packet.
+func NewScheduler(maxDelay time.Duration) *Scheduler {
+	return &Scheduler{
+		maxDelay: maxDelay,
+		peers:    make(map[string]*peerTraffic),
+	}
+}
+
+// Acquire waits for the packets of higher classes to the peer to be sent, and
+// returns a function to call once the packet has been sent. It stops waiting
+// when the maximum delay is reached or the context is done, so that the send
+// itself reports the failure. A nil scheduler never waits.
+func (s *Scheduler) Acquire(ctx context.Context, to mino.Address, class mino.Class) func() {
+	if s == nil {
+		return func() {}
+	}
+
+	key := to.String()
+
+	timer := time.NewTimer(s.maxDelay)
+	defer timer.Stop()
+
+	s.Lock()
+
+	for waiting := true; waiting && s.isBusy(key, class); {
+		wake := s.peers[key].wake
+
+		s.Unlock()
+
+		select {
+		case <-wake:
+		case <-timer.C:
+			waiting = false
+		case <-ctx.Done():
+			waiting = false
+		}
+
+		s.Lock()
+	}
+
+	peer := s.getPeer(key)
+	peer.inflight[class]++
+
+	s.Unlock()
+
+	return func() {
+		s.release(key, class)
+	}
+}
+
+func (s *Scheduler) isBusy(key string, class mino.Class) bool {
+	peer, found := s.peers[key]
+	if !found {
+		return false
+	}
+
+	for c, n := range peer.inflight {
+		if c > class && n > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Scheduler) getPeer(key string) *peerTraffic {
+	peer, found := s.peers[key]
+	if !found {
+		peer = &peerTraffic{
+			inflight: make(map[mino.Class]int),
+			wake:     make(chan struct{}),
+		}
+
+		s.peers[key] = peer
+	}
+
+	return peer
+}
+
+func (s *Scheduler) release(key string, class mino.Class) {
+	s.Lock()
+	defer s.Unlock()
+
+	peer := s.peers[key]
+
+	peer.inflight[class]--
+	if peer.inflight[class] == 0 {
+		delete(peer.inflight, class)
+	}
+
+	// Every waiting packet is woken up to check again if it can be sent.
+	close(peer.wake)
+	peer.wake = make(chan struct{})
+
+	if len(peer.inflight) == 0 {
+		delete(s.peers, key)
+	}
+}
@@ -0,0 +1,69 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+)
+
+func TestScheduler_Acquire(t *testing.T) {
+	sched := NewScheduler(time.Hour)
+
+	to := fake.NewAddress(0)
+
+	releaseConsensus := sched.Acquire(context.Background(), to, mino.ClassConsensus)
+
+	// The highest class and the other peers are never delayed.
+	sched.Acquire(context.Background(), to, mino.ClassConsensus)()
+	sched.Acquire(context.Background(), fake.NewAddress(1), mino.ClassBulk)()
+
+	done := make(chan struct{})
+	go func() {
+		sched.Acquire(context.Background(), to, mino.ClassBulk)()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("bulk packet should wait")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	releaseConsensus()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("bulk packet should be sent")
+	}
+
+	require.Empty(t, sched.peers)
+}
+
+func TestScheduler_MaxDelay_Acquire(t *testing.T) {
+	sched := NewScheduler(10 * time.Millisecond)
+
+	to := fake.NewAddress(0)
+
+	release := sched.Acquire(context.Background(), to, mino.ClassDefault)
+	defer release()
+
+	sched.Acquire(context.Background(), to, mino.ClassBulk)()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sched = NewScheduler(time.Hour)
+	sched.Acquire(ctx, to, mino.ClassConsensus)
+	sched.Acquire(ctx, to, mino.ClassDefault)()
+}
+
+func TestScheduler_Nil_Acquire(t *testing.T) {
+	var sched *Scheduler
+
+	sched.Acquire(context.Background(), fake.NewAddress(0), mino.ClassBulk)()
+}
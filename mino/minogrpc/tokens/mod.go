@@ -1,67 +1,220 @@
 // Package tokens defines a token holder to generate and validate access tokens.
 //
-// The package also provides an in-memory implementation.
+// A token is signed by the node that generates it, and it carries its
+// expiration and the scope of the node that joins with it, so that the holder
+// only needs to remember the tokens that are revoked before they expire.
 //
 // Documentation Last Review: 07.10.2020
 //
 package tokens
 
 import (
+	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
 	"sync"
 	"time"
+
+	"golang.org/x/xerrors"
 )
 
+// Scope is the role granted to the node that joins with a token.
+type Scope string
+
+const (
+	// ScopeValidator grants the node to be known by the whole network, so
+	// that it can later become a member of the roster.
+	ScopeValidator Scope = "validator"
+
+	// ScopeObserver grants the node to be known only by the node that
+	// generated the token, for instance to follow the chain.
+	ScopeObserver Scope = "observer"
+)
+
+// ParseScope returns the scope of the name, or an error if it is unknown.
+func ParseScope(name string) (Scope, error) {
+	switch Scope(name) {
+	case ScopeValidator, ScopeObserver:
+		return Scope(name), nil
+	default:
+		return "", xerrors.Errorf("unknown scope '%s'", name)
+	}
+}
+
+// Token is an access token signed by the node that generated it.
+type Token struct {
+	ID     string
+	Scope  Scope
+	Expiry time.Time
+
+	encoded string
+}
+
+// String implements fmt.Stringer. It returns the encoded token that is shared
+// with the node that joins.
+func (t Token) String() string {
+	return t.encoded
+}
+
 // Holder is a store for access tokens.
 type Holder interface {
-	// Generate creates a new token that is valid for the provided amount of
-	// time.
-	Generate(expiration time.Duration) string
+	// Generate creates a new token for the scope that is valid for the
+	// provided amount of time.
+	Generate(expiration time.Duration, scope Scope) (Token, error)
 
-	// Verify checks that the given token exists and is still valid.
-	Verify(token string) bool
+	// Verify checks that the given token is authentic, not expired and not
+	// revoked, and it returns its content.
+	Verify(token string) (Token, error)
+
+	// Revoke invalidates the token before its expiration.
+	Revoke(token string) error
+}
+
+// claims is the signed content of a token.
+type claims struct {
+	ID     string
+	Scope  Scope
+	Expiry int64
 }
 
-// InMemoryHolder stores access token in memory.
+// SignedHolder generates tokens signed by its key, and it keeps the revoked
+// ones in memory.
 //
 // - implements tokens.Holder
-type InMemoryHolder struct {
+type SignedHolder struct {
 	sync.Mutex
-	tokens map[string]time.Time
+	key     ed25519.PrivateKey
+	revoked map[string]struct{}
 }
 
-// NewInMemoryHolder creates a new empty token holder.
-func NewInMemoryHolder() *InMemoryHolder {
-	return &InMemoryHolder{
-		tokens: make(map[string]time.Time),
+// NewSignedHolder creates a new token holder that signs the tokens with the
+// key.
+func NewSignedHolder(key ed25519.PrivateKey) *SignedHolder {
+	return &SignedHolder{
+		key:     key,
+		revoked: make(map[string]struct{}),
 	}
 }
 
-// Generate implements tokens.Holder. It generates a token that will expire
-// after a given amount of time.
-func (holder *InMemoryHolder) Generate(expiration time.Duration) string {
+// Generate implements tokens.Holder. It generates a token for the scope that
+// will expire after a given amount of time.
+func (holder *SignedHolder) Generate(expiration time.Duration, scope Scope) (Token, error) {
+	_, err := ParseScope(string(scope))
+	if err != nil {
+		return Token{}, xerrors.Errorf("invalid scope: %v", err)
+	}
+
 	buffer := make([]byte, 16)
-	rand.Read(buffer)
 
-	str := base64.StdEncoding.EncodeToString(buffer)
+	_, err = rand.Read(buffer)
+	if err != nil {
+		return Token{}, xerrors.Errorf("failed to generate id: %v", err)
+	}
+
+	c := claims{
+		ID:     hex.EncodeToString(buffer),
+		Scope:  scope,
+		Expiry: time.Now().Add(expiration).UnixNano(),
+	}
+
+	// The claims only contain plain types.
+	payload, _ := json.Marshal(c)
+
+	signature := ed25519.Sign(holder.key, payload)
+
+	token := Token{
+		ID:     c.ID,
+		Scope:  c.Scope,
+		Expiry: time.Unix(0, c.Expiry),
+		encoded: base64.RawURLEncoding.EncodeToString(payload) + "." +
+			base64.RawURLEncoding.EncodeToString(signature),
+	}
+
+	return token, nil
+}
+
+// Verify implements tokens.Holder. It returns the content of the token if it
+// is valid, otherwise an error explaining why it is not.
+func (holder *SignedHolder) Verify(token string) (Token, error) {
+	t, err := holder.parse(token)
+	if err != nil {
+		return Token{}, err
+	}
+
+	if !t.Expiry.After(time.Now()) {
+		return Token{}, xerrors.Errorf("token '%s' expired", t.ID)
+	}
 
 	holder.Lock()
-	holder.tokens[str] = time.Now().Add(expiration)
+	_, revoked := holder.revoked[t.ID]
 	holder.Unlock()
 
-	return str
+	if revoked {
+		return Token{}, xerrors.Errorf("token '%s' is revoked", t.ID)
+	}
+
+	return t, nil
 }
 
-// Verify implements tokens.Holder. It returns true if the token is valid.
-func (holder *InMemoryHolder) Verify(token string) bool {
+// Revoke implements tokens.Holder. It refuses the token from now on, or it
+// returns an error if the token has not been generated by the holder.
+func (holder *SignedHolder) Revoke(token string) error {
+	t, err := holder.parse(token)
+	if err != nil {
+		return err
+	}
+
 	holder.Lock()
-	defer holder.Unlock()
+	holder.revoked[t.ID] = struct{}{}
+	holder.Unlock()
+
+	return nil
+}
+
+// parse decodes the token and checks its signature.
+func (holder *SignedHolder) parse(token string) (Token, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return Token{}, xerrors.New("malformed token")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return Token{}, xerrors.Errorf("malformed payload: %v", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return Token{}, xerrors.Errorf("malformed signature: %v", err)
+	}
+
+	pubkey := holder.key.Public().(ed25519.PublicKey)
+
+	if !ed25519.Verify(pubkey, payload, signature) {
+		return Token{}, xerrors.New("invalid signature")
+	}
+
+	var c claims
+
+	err = json.Unmarshal(payload, &c)
+	if err != nil {
+		return Token{}, xerrors.Errorf("malformed payload: %v", err)
+	}
+
+	scope, err := ParseScope(string(c.Scope))
+	if err != nil {
+		return Token{}, xerrors.Errorf("invalid scope: %v", err)
+	}
 
-	deadline, ok := holder.tokens[token]
-	if !ok {
-		return false
+	t := Token{
+		ID:      c.ID,
+		Scope:   scope,
+		Expiry:  time.Unix(0, c.Expiry),
+		encoded: token,
 	}
 
-	return deadline.After(time.Now())
+	return t, nil
 }
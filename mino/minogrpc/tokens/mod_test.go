@@ -1,32 +1,126 @@
 package tokens
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 )
 
-func TestInMemoryHolder_Generate(t *testing.T) {
-	holder := NewInMemoryHolder()
+func TestParseScope(t *testing.T) {
+	scope, err := ParseScope("observer")
+	require.NoError(t, err)
+	require.Equal(t, ScopeObserver, scope)
 
-	token := holder.Generate(time.Minute)
-	require.NotEmpty(t, token)
-	require.Len(t, holder.tokens, 1)
-	require.True(t, time.Now().Before(holder.tokens[token]))
-	require.True(t, time.Now().Add(time.Minute+1).After(holder.tokens[token]))
+	scope, err = ParseScope("validator")
+	require.NoError(t, err)
+	require.Equal(t, ScopeValidator, scope)
 
-	holder.Generate(time.Millisecond)
-	require.Len(t, holder.tokens, 2)
+	_, err = ParseScope("admin")
+	require.EqualError(t, err, "unknown scope 'admin'")
 }
 
-func TestInMemoryHolder_Verify(t *testing.T) {
-	holder := NewInMemoryHolder()
+func TestSignedHolder_Generate(t *testing.T) {
+	holder := NewSignedHolder(makeKey(t))
 
-	holder.tokens["abc"] = time.Now().Add(time.Minute)
-	holder.tokens["def"] = time.Now()
+	token, err := holder.Generate(time.Minute, ScopeObserver)
+	require.NoError(t, err)
+	require.Len(t, token.ID, 32)
+	require.Equal(t, ScopeObserver, token.Scope)
+	require.True(t, time.Now().Before(token.Expiry))
+	require.True(t, time.Now().Add(time.Minute+1).After(token.Expiry))
+	require.NotEmpty(t, token.String())
 
-	require.True(t, holder.Verify("abc"))
-	require.False(t, holder.Verify("abcd"))
-	require.False(t, holder.Verify("def"))
+	other, err := holder.Generate(time.Minute, ScopeObserver)
+	require.NoError(t, err)
+	require.NotEqual(t, token.ID, other.ID)
+
+	_, err = holder.Generate(time.Minute, Scope("admin"))
+	require.EqualError(t, err, "invalid scope: unknown scope 'admin'")
+}
+
+func TestSignedHolder_Verify(t *testing.T) {
+	holder := NewSignedHolder(makeKey(t))
+
+	token, err := holder.Generate(time.Minute, ScopeValidator)
+	require.NoError(t, err)
+
+	verified, err := holder.Verify(token.String())
+	require.NoError(t, err)
+	require.Equal(t, token, verified)
+
+	expired, err := holder.Generate(-time.Second, ScopeValidator)
+	require.NoError(t, err)
+
+	_, err = holder.Verify(expired.String())
+	require.EqualError(t, err, "token '"+expired.ID+"' expired")
+
+	// A token generated by another holder is refused.
+	other, err := NewSignedHolder(makeKey(t)).Generate(time.Minute, ScopeValidator)
+	require.NoError(t, err)
+
+	_, err = holder.Verify(other.String())
+	require.EqualError(t, err, "invalid signature")
+
+	_, err = holder.Verify("abc")
+	require.EqualError(t, err, "malformed token")
+
+	_, err = holder.Verify("#.abc")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "malformed payload: ")
+
+	_, err = holder.Verify("abc.#")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "malformed signature: ")
+
+	_, err = holder.Verify(sign(holder, "{"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "malformed payload: ")
+
+	_, err = holder.Verify(sign(holder, `{"Scope":"admin"}`))
+	require.EqualError(t, err, "invalid scope: unknown scope 'admin'")
+}
+
+func TestSignedHolder_Revoke(t *testing.T) {
+	holder := NewSignedHolder(makeKey(t))
+
+	token, err := holder.Generate(time.Minute, ScopeValidator)
+	require.NoError(t, err)
+
+	other, err := holder.Generate(time.Minute, ScopeValidator)
+	require.NoError(t, err)
+
+	err = holder.Revoke(token.String())
+	require.NoError(t, err)
+
+	_, err = holder.Verify(token.String())
+	require.EqualError(t, err, "token '"+token.ID+"' is revoked")
+
+	_, err = holder.Verify(other.String())
+	require.NoError(t, err)
+
+	err = holder.Revoke("abc")
+	require.EqualError(t, err, "malformed token")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeKey(t *testing.T) ed25519.PrivateKey {
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	return key
+}
+
+func sign(holder *SignedHolder, payload string) string {
+	signature := ed25519.Sign(holder.key, []byte(payload))
+
+	return strings.Join([]string{
+		base64.RawURLEncoding.EncodeToString([]byte(payload)),
+		base64.RawURLEncoding.EncodeToString(signature),
+	}, ".")
 }
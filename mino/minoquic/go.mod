@@ -0,0 +1,28 @@
+module go.dedis.ch/dela/mino/minoquic
+
+go 1.24
+
+require (
+	github.com/quic-go/quic-go v0.59.1
+	github.com/rs/xid v1.2.1
+	github.com/stretchr/testify v1.11.1
+	go.dedis.ch/dela v0.0.0
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/opentracing/opentracing-go v1.2.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/rs/zerolog v1.19.0 // indirect
+	go.dedis.ch/fixbuf v1.0.3 // indirect
+	go.dedis.ch/kyber/v3 v3.0.13 // indirect
+	go.etcd.io/bbolt v1.3.5 // indirect
+	golang.org/x/crypto v0.41.0 // indirect
+	golang.org/x/net v0.43.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace go.dedis.ch/dela => ../..
@@ -0,0 +1,673 @@
+// Package minoquic is an implementation of Mino over QUIC connections.
+//
+// A node listens on a single UDP socket which is used to accept the
+// connections of the other participants and to dial them. A connection to a
+// participant is kept open and every call or stream of an RPC opens its own
+// QUIC stream, therefore a slow call doesn't block the others and the
+// messages of a protocol are not stuck behind a lost packet of another one.
+//
+// The first frame of a QUIC stream tells the path of the RPC and whether it is
+// a call or a stream. A call is answered by a single frame, while a stream
+// keeps the QUIC stream open and carries the packets of the router in both
+// directions. The orchestrator of a stream only opens a QUIC stream to one of
+// the players, the gateway, and the packets are then routed among the players
+// with the routing tables of the router, like minogrpc does.
+//
+// The frames are JSON documents prefixed with their length:
+//
+//	{"kind":"call","path":"/a/b/rpc","payload":{...}}
+//	{"kind":"reply","payload":{...}}
+//
+// The connections use TLS 1.3 with self-signed certificates that are pinned:
+// a node only accepts the connection of a participant whose certificate is in
+// its certificate store, and it only talks to a participant that presents the
+// certificate stored for its address. The certificates are therefore exchanged
+// beforehand, for instance with GetCertificate and the Store function of the
+// certificate store of the other participants. The address of the caller of
+// an RPC is the one of its certificate.
+//
+// The package is a module of its own as the QUIC stack requires a more recent
+// version of Go than the rest of the project.
+package minoquic
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minogrpc/certs"
+	"go.dedis.ch/dela/mino/router"
+	"go.dedis.ch/dela/serde"
+	sjson "go.dedis.ch/dela/serde/json"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// protocol is the application protocol negotiated by TLS.
+	protocol = "dela-mino"
+
+	// MaxFrameSize is the maximum number of bytes of a frame, so that a
+	// participant cannot force a huge allocation.
+	MaxFrameSize = 64 * 1024 * 1024
+
+	defaultTimeout = 10 * time.Second
+
+	certificateDuration = time.Hour * 24 * 180
+
+	maxStreams = 1000
+
+	kindCall   = "call"
+	kindStream = "stream"
+	kindReady  = "ready"
+	kindReply  = "reply"
+	kindPacket = "packet"
+	kindError  = "error"
+)
+
+// frame is the JSON document written on a QUIC stream.
+type frame struct {
+	Kind         string          `json:"kind"`
+	Path         string          `json:"path,omitempty"`
+	ID           string          `json:"id,omitempty"`
+	Orchestrator bool            `json:"orchestrator,omitempty"`
+	Players      []string        `json:"players,omitempty"`
+	Handshake    json.RawMessage `json:"handshake,omitempty"`
+	Payload      json.RawMessage `json:"payload,omitempty"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// Option is the type of option to configure the instance.
+type Option func(*template)
+
+type template struct {
+	public  string
+	timeout time.Duration
+	cert    *tls.Certificate
+	certs   certs.Storage
+}
+
+// WithPublicAddress sets the host and port that the other participants use to
+// reach the node, when it is different from the address it listens on.
+func WithPublicAddress(host string) Option {
+	return func(tmpl *template) {
+		tmpl.public = host
+	}
+}
+
+// WithTimeout sets the amount of time to wait for a connection to a
+// participant to be opened, or for a stream to be accepted.
+func WithTimeout(timeout time.Duration) Option {
+	return func(tmpl *template) {
+		tmpl.timeout = timeout
+	}
+}
+
+// WithCertificate sets the certificate of the node. A self-signed certificate
+// is generated otherwise.
+func WithCertificate(cert *tls.Certificate) Option {
+	return func(tmpl *template) {
+		tmpl.cert = cert
+	}
+}
+
+// WithStorage sets the store of the certificates of the participants. The
+// certificates are only kept in memory otherwise.
+func WithStorage(store certs.Storage) Option {
+	return func(tmpl *template) {
+		tmpl.certs = store
+	}
+}
+
+// Minoquic is an implementation of the Mino interface using QUIC connections.
+//
+// - implements mino.Mino
+type Minoquic struct {
+	*overlay
+
+	segments []string
+}
+
+type overlay struct {
+	sync.Mutex
+
+	addr      address
+	transport *quic.Transport
+	listener  *quic.Listener
+	config    *quic.Config
+	cert      *tls.Certificate
+	certs     certs.Storage
+	router    router.Router
+	rpcs      map[string]*RPC
+	context   serde.Context
+	addrFac   mino.AddressFactory
+	timeout   time.Duration
+
+	connsLock sync.Mutex
+	conns     map[string]*quic.Conn
+	accepted  map[*quic.Conn]struct{}
+}
+
+// NewMinoquic creates a new instance that listens on the address, e.g.
+// 127.0.0.1:2000, and routes the packets of the streams with the router. The
+// server is started right away.
+func NewMinoquic(listen string, rt router.Router, opts ...Option) (*Minoquic, error) {
+	tmpl := template{
+		timeout: defaultTimeout,
+		certs:   certs.NewInMemoryStore(),
+	}
+
+	for _, opt := range opts {
+		opt(&tmpl)
+	}
+
+	udpAddr, err := net.ResolveUDPAddr("udp", listen)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid address: %v", err)
+	}
+
+	udpConn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to listen: %v", err)
+	}
+
+	if tmpl.public == "" {
+		tmpl.public = udpConn.LocalAddr().String()
+	}
+
+	if tmpl.cert == nil {
+		tmpl.cert, err = makeCertificate()
+		if err != nil {
+			udpConn.Close()
+			return nil, xerrors.Errorf("certificate failed: %v", err)
+		}
+	}
+
+	o := &overlay{
+		addr:      address{host: tmpl.public},
+		transport: &quic.Transport{Conn: udpConn},
+		config: &quic.Config{
+			MaxIncomingStreams: maxStreams,
+			KeepAlivePeriod:    tmpl.timeout / 2,
+		},
+		cert:     tmpl.cert,
+		certs:    tmpl.certs,
+		router:   rt,
+		rpcs:     make(map[string]*RPC),
+		context:  sjson.NewContext(sjson.WithLimits(serde.Limits{MaxSize: MaxFrameSize})),
+		addrFac:  AddressFactory{},
+		timeout:  tmpl.timeout,
+		conns:    make(map[string]*quic.Conn),
+		accepted: make(map[*quic.Conn]struct{}),
+	}
+
+	err = o.certs.Store(o.addr, o.cert)
+	if err != nil {
+		o.transport.Close()
+		return nil, xerrors.Errorf("failed to store certificate: %v", err)
+	}
+
+	o.listener, err = o.transport.Listen(o.serverTLS(), o.config)
+	if err != nil {
+		o.transport.Close()
+		return nil, xerrors.Errorf("failed to listen: %v", err)
+	}
+
+	go o.accept()
+
+	dela.Logger.Info().Stringer("address", o.addr).Msg("quic server started")
+
+	return &Minoquic{overlay: o}, nil
+}
+
+// GetAddressFactory implements mino.Mino. It returns the address factory.
+func (m *Minoquic) GetAddressFactory() mino.AddressFactory {
+	return m.addrFac
+}
+
+// GetAddress implements mino.Mino. It returns the address that other
+// participants should use to contact this instance.
+func (m *Minoquic) GetAddress() mino.Address {
+	return m.addr
+}
+
+// GetCertificate returns the certificate of the instance, which the other
+// participants must store for its address.
+func (m *Minoquic) GetCertificate() *tls.Certificate {
+	return m.cert
+}
+
+// GetCertificateStore returns the store of the certificates of the
+// participants that the instance trusts.
+func (m *Minoquic) GetCertificateStore() certs.Storage {
+	return m.certs
+}
+
+// WithSegment implements mino.Mino. It returns a new mino instance that will
+// have its URI path extended with the provided segment.
+func (m *Minoquic) WithSegment(segment string) mino.Mino {
+	segments := append(append([]string{}, m.segments...), segment)
+
+	return &Minoquic{
+		overlay:  m.overlay,
+		segments: segments,
+	}
+}
+
+// CreateRPC implements mino.Mino. It creates an RPC that can send to and
+// receive from the unique path.
+func (m *Minoquic) CreateRPC(name string, h mino.Handler, f serde.Factory) (mino.RPC, error) {
+	path := "/" + strings.Join(append(append([]string{}, m.segments...), name), "/")
+
+	rpc := &RPC{
+		overlay:  m.overlay,
+		path:     path,
+		handler:  h,
+		factory:  f,
+		sessions: make(map[string]*session),
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	_, found := m.rpcs[path]
+	if found {
+		return nil, xerrors.Errorf("rpc '%s' already exists", path)
+	}
+
+	m.rpcs[path] = rpc
+
+	return rpc, nil
+}
+
+// Stop closes the connections and the socket of the instance. The streams are
+// closed on the other participants when they learn about it.
+func (m *Minoquic) Stop() error {
+	m.listener.Close()
+
+	m.connsLock.Lock()
+
+	for _, conn := range m.conns {
+		conn.CloseWithError(0, "stopping")
+	}
+
+	for conn := range m.accepted {
+		conn.CloseWithError(0, "stopping")
+	}
+
+	m.connsLock.Unlock()
+
+	err := m.transport.Close()
+	if err != nil {
+		return xerrors.Errorf("failed to close transport: %v", err)
+	}
+
+	return nil
+}
+
+// accept accepts the connections of the participants until the listener is
+// closed.
+func (o *overlay) accept() {
+	for {
+		conn, err := o.listener.Accept(context.Background())
+		if err != nil {
+			dela.Logger.Debug().Err(err).Msg("quic listener closed")
+			return
+		}
+
+		go o.serve(conn)
+	}
+}
+
+// serve accepts the streams of a connection until it is closed.
+func (o *overlay) serve(conn *quic.Conn) {
+	o.connsLock.Lock()
+	o.accepted[conn] = struct{}{}
+	o.connsLock.Unlock()
+
+	defer func() {
+		o.connsLock.Lock()
+		delete(o.accepted, conn)
+		o.connsLock.Unlock()
+	}()
+
+	// The certificate has been verified during the handshake, which means the
+	// participant is known.
+	from, err := o.authenticate(conn.ConnectionState().TLS.PeerCertificates)
+	if err != nil {
+		conn.CloseWithError(0, err.Error())
+		return
+	}
+
+	for {
+		stream, err := conn.AcceptStream(context.Background())
+		if err != nil {
+			return
+		}
+
+		go o.handle(from, stream)
+	}
+}
+
+// handle reads the first frame of a stream and runs the call or the stream of
+// the RPC.
+func (o *overlay) handle(from address, stream *quic.Stream) {
+	var f frame
+
+	stream.SetReadDeadline(time.Now().Add(o.timeout))
+
+	err := readFrame(stream, &f)
+	if err != nil {
+		dela.Logger.Debug().Err(err).Stringer("from", from).Msg("stream closed")
+		stream.CancelRead(0)
+		stream.Close()
+		return
+	}
+
+	stream.SetReadDeadline(time.Time{})
+
+	o.Lock()
+	rpc, found := o.rpcs[f.Path]
+	o.Unlock()
+
+	if !found {
+		sendError(stream, xerrors.Errorf("unknown rpc '%s'", f.Path))
+		stream.Close()
+		return
+	}
+
+	switch f.Kind {
+	case kindCall:
+		defer stream.Close()
+
+		reply, err := rpc.process(from, f.Payload)
+		if err != nil {
+			sendError(stream, err)
+			return
+		}
+
+		err = writeFrame(stream, frame{Kind: kindReply, Payload: reply})
+		if err != nil {
+			dela.Logger.Debug().Err(err).Msg("failed to reply")
+		}
+	case kindStream:
+		if f.Orchestrator {
+			from.orchestrator = true
+		}
+
+		err = rpc.join(newRelay(from, stream), f)
+		if err != nil {
+			sendError(stream, err)
+			stream.Close()
+		}
+	default:
+		sendError(stream, xerrors.Errorf("unknown kind '%s'", f.Kind))
+		stream.Close()
+	}
+}
+
+// connect returns the connection to the participant, which is opened if
+// necessary.
+func (o *overlay) connect(ctx context.Context, addr mino.Address) (*quic.Conn, error) {
+	to, ok := addr.(address)
+	if !ok {
+		return nil, xerrors.Errorf("invalid address type '%T'", addr)
+	}
+
+	o.connsLock.Lock()
+	defer o.connsLock.Unlock()
+
+	conn := o.conns[to.host]
+	if conn != nil && conn.Context().Err() == nil {
+		return conn, nil
+	}
+
+	udpAddr, err := net.ResolveUDPAddr("udp", to.host)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid address: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	conn, err = o.transport.Dial(ctx, udpAddr, o.clientTLS(address{host: to.host}), o.config)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to dial: %v", err)
+	}
+
+	o.conns[to.host] = conn
+
+	return conn, nil
+}
+
+// open opens a stream to the participant and writes the first frame.
+func (o *overlay) open(ctx context.Context, addr mino.Address, f frame) (*quic.Stream, error) {
+	conn, err := o.connect(ctx, addr)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to connect: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to open stream: %v", err)
+	}
+
+	err = writeFrame(stream, f)
+	if err != nil {
+		stream.CancelWrite(0)
+		stream.CancelRead(0)
+		return nil, xerrors.Errorf("failed to send: %v", err)
+	}
+
+	return stream, nil
+}
+
+// serverTLS returns the configuration of the connections accepted by the
+// node, which requires the certificate of the participant to be known.
+func (o *overlay) serverTLS() *tls.Config {
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		NextProtos:   []string{protocol},
+		Certificates: []tls.Certificate{*o.cert},
+		ClientAuth:   tls.RequireAnyClientCert,
+		VerifyPeerCertificate: func(raw [][]byte, _ [][]*x509.Certificate) error {
+			_, err := o.lookup(raw)
+			return err
+		},
+	}
+}
+
+// clientTLS returns the configuration of a connection to the participant,
+// which must present the certificate stored for its address.
+func (o *overlay) clientTLS(to address) *tls.Config {
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		NextProtos:   []string{protocol},
+		Certificates: []tls.Certificate{*o.cert},
+		// The certificates are self-signed and therefore pinned by the
+		// verification below instead of a chain of trust.
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(raw [][]byte, _ [][]*x509.Certificate) error {
+			return o.verify(to, raw)
+		},
+	}
+}
+
+// authenticate returns the address of the participant that presented the
+// certificates.
+func (o *overlay) authenticate(peers []*x509.Certificate) (address, error) {
+	raw := make([][]byte, len(peers))
+	for i, peer := range peers {
+		raw[i] = peer.Raw
+	}
+
+	return o.lookup(raw)
+}
+
+// lookup returns the address of the certificate in the store, or an error if
+// it is unknown.
+func (o *overlay) lookup(raw [][]byte) (address, error) {
+	// The certificate of a node is self-signed and thus a chain of length 1.
+	if len(raw) != 1 {
+		return address{}, xerrors.Errorf("expect exactly one certificate but found %d", len(raw))
+	}
+
+	var from address
+	var found bool
+
+	err := o.certs.Range(func(addr mino.Address, cert *tls.Certificate) bool {
+		a, ok := addr.(address)
+		if ok && !a.orchestrator && sameCertificate(cert, raw[0]) {
+			from = a
+			found = true
+		}
+
+		return !found
+	})
+	if err != nil {
+		return address{}, xerrors.Errorf("while reading store: %v", err)
+	}
+
+	if !found {
+		return address{}, xerrors.New("certificate is unknown")
+	}
+
+	return from, nil
+}
+
+// verify returns nil if the certificate is the one stored for the address.
+func (o *overlay) verify(to address, raw [][]byte) error {
+	if len(raw) != 1 {
+		return xerrors.Errorf("expect exactly one certificate but found %d", len(raw))
+	}
+
+	cert, err := o.certs.Load(to)
+	if err != nil {
+		return xerrors.Errorf("while loading certificate: %v", err)
+	}
+
+	if cert == nil {
+		return xerrors.Errorf("certificate of %v is unknown", to)
+	}
+
+	if !sameCertificate(cert, raw[0]) {
+		return xerrors.Errorf("certificate of %v does not match", to)
+	}
+
+	return nil
+}
+
+func sameCertificate(cert *tls.Certificate, raw []byte) bool {
+	if cert.Leaf != nil {
+		return string(cert.Leaf.Raw) == string(raw)
+	}
+
+	return len(cert.Certificate) > 0 && string(cert.Certificate[0]) == string(raw)
+}
+
+// makeCertificate generates a self-signed certificate with a new key.
+func makeCertificate() (*tls.Certificate, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't generate the key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(certificateDuration),
+
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+
+	buf, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		return nil, xerrors.Errorf("while creating: %v", err)
+	}
+
+	leaf, err := x509.ParseCertificate(buf)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't parse the certificate: %v", err)
+	}
+
+	cert := &tls.Certificate{
+		Certificate: [][]byte{buf},
+		PrivateKey:  priv,
+		Leaf:        leaf,
+	}
+
+	return cert, nil
+}
+
+// writeFrame writes the length of the frame followed by its JSON document.
+func writeFrame(w io.Writer, f frame) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	buf := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[4:], data)
+
+	_, err = w.Write(buf)
+	if err != nil {
+		return xerrors.Errorf("failed to write: %v", err)
+	}
+
+	return nil
+}
+
+// readFrame reads a frame written by writeFrame.
+func readFrame(r io.Reader, f *frame) error {
+	var header [4]byte
+
+	_, err := io.ReadFull(r, header[:])
+	if err != nil {
+		return err
+	}
+
+	size := binary.BigEndian.Uint32(header[:])
+	if size > MaxFrameSize {
+		return xerrors.Errorf("frame of %d bytes exceeds %d", size, MaxFrameSize)
+	}
+
+	data := make([]byte, size)
+
+	_, err = io.ReadFull(r, data)
+	if err != nil {
+		return xerrors.Errorf("failed to read: %v", err)
+	}
+
+	err = json.Unmarshal(data, f)
+	if err != nil {
+		return xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	return nil
+}
+
+func sendError(w io.Writer, err error) {
+	err = writeFrame(w, frame{Kind: kindError, Error: err.Error()})
+	if err != nil {
+		dela.Logger.Debug().Err(err).Msg("failed to send the error")
+	}
+}
@@ -0,0 +1,141 @@
+package minoquic
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minogrpc/certs"
+	"go.dedis.ch/dela/mino/router/tree"
+)
+
+func TestMinoquic_New(t *testing.T) {
+	m, err := NewMinoquic("127.0.0.1:0", tree.NewRouter(AddressFactory{}))
+	require.NoError(t, err)
+
+	defer m.Stop()
+
+	require.Equal(t, AddressFactory{}, m.GetAddressFactory())
+	require.Equal(t, m.transport.Conn.LocalAddr().String(), m.GetAddress().String())
+
+	cert, err := m.GetCertificateStore().Load(m.GetAddress())
+	require.NoError(t, err)
+	require.Equal(t, m.GetCertificate(), cert)
+
+	store := certs.NewInMemoryStore()
+
+	m2, err := NewMinoquic("127.0.0.1:0", tree.NewRouter(AddressFactory{}),
+		WithPublicAddress("node.example.com:2000"),
+		WithCertificate(m.GetCertificate()),
+		WithStorage(store))
+	require.NoError(t, err)
+
+	defer m2.Stop()
+
+	require.Equal(t, NewAddress("node.example.com:2000"), m2.GetAddress())
+	require.Equal(t, m.GetCertificate(), m2.GetCertificate())
+	require.Equal(t, store, m2.GetCertificateStore())
+
+	_, err = NewMinoquic("127.0.0.1:-1", tree.NewRouter(AddressFactory{}))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid address: ")
+}
+
+func TestMinoquic_CreateRPC(t *testing.T) {
+	m, err := NewMinoquic("127.0.0.1:0", tree.NewRouter(AddressFactory{}))
+	require.NoError(t, err)
+
+	defer m.Stop()
+
+	rpc, err := m.WithSegment("a").WithSegment("b").CreateRPC("test",
+		mino.UnsupportedHandler{}, fake.MessageFactory{})
+	require.NoError(t, err)
+	require.Equal(t, "/a/b/test", rpc.(*RPC).path)
+
+	_, err = m.CreateRPC("test", mino.UnsupportedHandler{}, fake.MessageFactory{})
+	require.NoError(t, err)
+
+	_, err = m.WithSegment("a").WithSegment("b").CreateRPC("test",
+		mino.UnsupportedHandler{}, fake.MessageFactory{})
+	require.EqualError(t, err, "rpc '/a/b/test' already exists")
+}
+
+func TestMinoquic_UnknownCertificate(t *testing.T) {
+	minos, rpcs, stop := makeInstances(t, 2)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stranger, err := NewMinoquic("127.0.0.1:0", tree.NewRouter(AddressFactory{}),
+		WithTimeout(time.Second))
+	require.NoError(t, err)
+
+	defer stranger.Stop()
+
+	rpc, err := stranger.CreateRPC("test", testHandler{}, testFactory{})
+	require.NoError(t, err)
+
+	// The stranger doesn't know the certificate of the participant.
+	resps, err := rpc.Call(ctx, testMessage{}, mino.NewAddresses(minos[0].GetAddress()))
+	require.NoError(t, err)
+
+	err = waitError(t, resps)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "certificate of "+minos[0].GetAddress().String()+" is unknown")
+
+	// The participant doesn't know the certificate of the stranger, which is
+	// therefore refused at the end of the handshake.
+	err = stranger.GetCertificateStore().Store(minos[0].GetAddress(), minos[0].GetCertificate())
+	require.NoError(t, err)
+
+	resps, err = rpc.Call(ctx, testMessage{}, mino.NewAddresses(minos[0].GetAddress()))
+	require.NoError(t, err)
+
+	err = waitError(t, resps)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "tls: bad certificate")
+
+	// A participant that presents a different certificate for a known address
+	// is refused.
+	err = minos[0].GetCertificateStore().Store(minos[1].GetAddress(), stranger.GetCertificate())
+	require.NoError(t, err)
+
+	resps, err = rpcs[0].Call(ctx, testMessage{}, mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	err = waitError(t, resps)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "certificate of "+minos[1].GetAddress().String()+" does not match")
+}
+
+func TestFrame_Write(t *testing.T) {
+	buffer := new(bytes.Buffer)
+
+	err := writeFrame(buffer, frame{Kind: kindCall, Path: "/test", Payload: []byte(`{}`)})
+	require.NoError(t, err)
+
+	var f frame
+	err = readFrame(buffer, &f)
+	require.NoError(t, err)
+	require.Equal(t, frame{Kind: kindCall, Path: "/test", Payload: []byte(`{}`)}, f)
+
+	err = writeFrame(fake.NewBadHash(), frame{})
+	require.EqualError(t, err, fake.Err("failed to write"))
+}
+
+func TestFrame_Read(t *testing.T) {
+	err := readFrame(bytes.NewBuffer([]byte{0xff, 0xff, 0xff, 0xff}), &frame{})
+	require.EqualError(t, err, "frame of 4294967295 bytes exceeds 67108864")
+
+	err = readFrame(bytes.NewBuffer([]byte{0, 0, 0, 2, '{'}), &frame{})
+	require.EqualError(t, err, "failed to read: unexpected EOF")
+
+	err = readFrame(bytes.NewBuffer([]byte{0, 0, 0, 2, '{', '{'}), &frame{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to unmarshal: ")
+}
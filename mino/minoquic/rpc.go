@@ -0,0 +1,350 @@
+// This file contains the implementation of the RPC over QUIC connections.
+
+package minoquic
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/rs/xid"
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/router"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+// RPC is an RPC of the instance, which calls the handler of the same path on
+// the other participants.
+//
+// - implements mino.RPC
+type RPC struct {
+	sync.Mutex
+
+	overlay  *overlay
+	path     string
+	handler  mino.Handler
+	factory  serde.Factory
+	sessions map[string]*session
+}
+
+// Call implements mino.RPC. It opens a stream to each player to send the
+// request, which is closed after the reply.
+func (rpc *RPC) Call(ctx context.Context,
+	req serde.Message, players mino.Players) (<-chan mino.Response, error) {
+
+	data, err := req.Serialize(rpc.overlay.context)
+	if err != nil {
+		return nil, xerrors.Errorf("while serializing: %v", err)
+	}
+
+	request := frame{
+		Kind:    kindCall,
+		Path:    rpc.path,
+		Payload: data,
+	}
+
+	out := make(chan mino.Response, players.Len())
+
+	wg := sync.WaitGroup{}
+	wg.Add(players.Len())
+
+	iter := players.AddressIterator()
+	for iter.HasNext() {
+		go func(addr mino.Address) {
+			defer wg.Done()
+
+			resp := rpc.call(ctx, addr, request)
+			if resp != nil {
+				out <- resp
+			}
+		}(iter.GetNext())
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out, nil
+}
+
+// call sends the request to the participant and returns its response, or nil
+// if the handler has nothing to reply.
+func (rpc *RPC) call(ctx context.Context, addr mino.Address, request frame) mino.Response {
+	stream, err := rpc.overlay.open(ctx, addr, request)
+	if err != nil {
+		return mino.NewResponseWithError(addr, err)
+	}
+
+	defer stream.CancelRead(0)
+
+	// The request is complete, which lets the participant know that no more
+	// frames are coming.
+	stream.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		// The stream is cancelled to interrupt the reading of the reply when
+		// the context is done.
+		select {
+		case <-ctx.Done():
+			stream.CancelRead(0)
+		case <-done:
+		}
+	}()
+
+	var reply frame
+
+	err = readFrame(stream, &reply)
+	if ctx.Err() != nil {
+		return mino.NewResponseWithError(addr, ctx.Err())
+	}
+
+	if err != nil {
+		return mino.NewResponseWithError(addr, xerrors.Errorf("failed to receive: %v", err))
+	}
+
+	if reply.Kind == kindError {
+		return mino.NewResponseWithError(addr, xerrors.Errorf("remote: %s", reply.Error))
+	}
+
+	if len(reply.Payload) == 0 {
+		return nil
+	}
+
+	msg, err := rpc.factory.Deserialize(rpc.overlay.context, reply.Payload)
+	if err != nil {
+		return mino.NewResponseWithError(addr,
+			xerrors.Errorf("couldn't unmarshal payload: %v", err))
+	}
+
+	return mino.NewResponse(addr, msg)
+}
+
+// process runs the handler for the request of a call and returns the payload
+// of the reply, which is nil when the handler has nothing to reply.
+func (rpc *RPC) process(from mino.Address, payload []byte) (json.RawMessage, error) {
+	msg, err := rpc.factory.Deserialize(rpc.overlay.context, payload)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't deserialize: %v", err)
+	}
+
+	err = mino.CheckScope(rpc.handler, from)
+	if err != nil {
+		return nil, xerrors.Errorf("request refused: %v", err)
+	}
+
+	resp, err := rpc.handler.Process(mino.Request{Address: from, Message: msg})
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't process request: %v", err)
+	}
+
+	if resp == nil {
+		return nil, nil
+	}
+
+	data, err := resp.Serialize(rpc.overlay.context)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't serialize reply: %v", err)
+	}
+
+	return data, nil
+}
+
+// Stream implements mino.RPC. It opens a stream to the gateway, which is the
+// instance itself when it is one of the players, or the first player
+// otherwise. The gateway is the root of the routing tables of the players.
+func (rpc *RPC) Stream(ctx context.Context, players mino.Players) (mino.Sender, mino.Receiver, error) {
+	if players == nil || players.Len() == 0 {
+		return nil, nil, xerrors.New("empty list of addresses")
+	}
+
+	orchestrator := address{orchestrator: true, host: rpc.overlay.addr.host}
+
+	table, err := rpc.overlay.router.New(mino.NewAddresses(), orchestrator)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("routing table failed: %v", err)
+	}
+
+	gw, others := rpc.findGateway(players)
+
+	open := frame{
+		Kind:         kindStream,
+		Path:         rpc.path,
+		ID:           xid.New().String(),
+		Orchestrator: true,
+		Players:      make([]string, len(others)),
+	}
+
+	for i, addr := range others {
+		open.Players[i] = marshalAddress(addr)
+	}
+
+	stream, err := rpc.overlay.open(ctx, gw, open)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to open stream to %v: %v", gw, err)
+	}
+
+	r := newRelay(gw, stream)
+
+	err = waitReady(r, rpc.overlay.timeout)
+	if err != nil {
+		r.close()
+		return nil, nil, xerrors.Errorf("stream to %v refused: %v", gw, err)
+	}
+
+	sess := newSession(rpc, open.ID, orchestrator)
+
+	// The orchestrator has no routing table of its own, therefore everything
+	// goes to the gateway.
+	sess.addParent(r, table)
+
+	go func() {
+		err := sess.listen(r)
+		if err != nil {
+			dela.Logger.Debug().Err(err).Stringer("gateway", gw).Msg("stream to root closed")
+		}
+
+		sess.close()
+	}()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			sess.close()
+		case <-sess.done:
+		}
+	}()
+
+	return sess, sess, nil
+}
+
+// join registers the relay as a parent of the session of the stream, and runs
+// the handler when the session is new. It returns when the relay is closed.
+func (rpc *RPC) join(r *relay, f frame) error {
+	if f.ID == "" {
+		return xerrors.New("unexpected empty stream ID")
+	}
+
+	table, err := rpc.tableOf(f)
+	if err != nil {
+		return xerrors.Errorf("routing table: %v", err)
+	}
+
+	rpc.Lock()
+
+	sess, found := rpc.sessions[f.ID]
+	if !found {
+		sess = newSession(rpc, f.ID, rpc.overlay.addr)
+		rpc.sessions[f.ID] = sess
+	}
+
+	// The parent is registered before the confirmation so that the packets
+	// sent right after are routed.
+	sess.addParent(r, table)
+
+	rpc.Unlock()
+
+	err = r.send(frame{Kind: kindReady})
+	if err != nil {
+		rpc.leave(sess, r)
+		return xerrors.Errorf("failed to confirm: %v", err)
+	}
+
+	if !found {
+		go func() {
+			err := rpc.handler.Stream(sess, mino.ScopeReceiver(rpc.handler, sess))
+			if err != nil {
+				dela.Logger.Warn().Err(err).Str("path", rpc.path).Msg("handler failed to process")
+			}
+		}()
+	}
+
+	err = sess.listen(r)
+	if err != nil {
+		dela.Logger.Debug().Err(err).Stringer("from", r.addr).Msg("parent closed")
+	}
+
+	rpc.leave(sess, r)
+
+	return nil
+}
+
+// leave removes the relay from the parents of the session, which is closed
+// when it has no parent left.
+func (rpc *RPC) leave(sess *session, r *relay) {
+	r.close()
+
+	rpc.Lock()
+	defer rpc.Unlock()
+
+	if sess.removeParent(r) > 0 {
+		return
+	}
+
+	if rpc.sessions[sess.id] == sess {
+		delete(rpc.sessions, sess.id)
+	}
+
+	sess.close()
+}
+
+// tableOf returns the routing table of a stream, which is created from the
+// players when it comes from the orchestrator, or from the handshake of the
+// parent otherwise.
+func (rpc *RPC) tableOf(f frame) (router.RoutingTable, error) {
+	if f.Orchestrator {
+		addrs := make([]mino.Address, len(f.Players))
+		for i, text := range f.Players {
+			addrs[i] = rpc.overlay.addrFac.FromText([]byte(text))
+		}
+
+		table, err := rpc.overlay.router.New(mino.NewAddresses(addrs...), rpc.overlay.addr)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to create: %v", err)
+		}
+
+		return table, nil
+	}
+
+	hs, err := rpc.overlay.router.GetHandshakeFactory().HandshakeOf(rpc.overlay.context, f.Handshake)
+	if err != nil {
+		return nil, xerrors.Errorf("malformed handshake: %v", err)
+	}
+
+	table, err := rpc.overlay.router.GenerateTableFrom(hs)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to generate: %v", err)
+	}
+
+	return table, nil
+}
+
+// findGateway returns the address of the gateway of a stream and the other
+// players.
+func (rpc *RPC) findGateway(players mino.Players) (mino.Address, []mino.Address) {
+	iter := players.AddressIterator()
+	addrs := make([]mino.Address, 0, players.Len())
+
+	var gw mino.Address
+
+	for iter.HasNext() {
+		addr := iter.GetNext()
+
+		if addr.Equal(rpc.overlay.addr) {
+			gw = addr
+		} else {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	if gw == nil {
+		return addrs[0], addrs[1:]
+	}
+
+	return gw, addrs
+}
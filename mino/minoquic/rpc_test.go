@@ -0,0 +1,429 @@
+package minoquic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/router/tree"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func TestRPC_Call(t *testing.T) {
+	minos, rpcs, stop := makeInstances(t, 3)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	players := mino.NewAddresses(minos[1].GetAddress(), minos[2].GetAddress())
+
+	resps, err := rpcs[0].Call(ctx, testMessage{Value: "hi"}, players)
+	require.NoError(t, err)
+
+	values := []string{}
+	for resp := range resps {
+		msg, err := resp.GetMessageOrError()
+		require.NoError(t, err)
+
+		values = append(values, msg.(testMessage).Value)
+	}
+
+	// The address of the caller is the one of its certificate.
+	caller := minos[0].GetAddress().String()
+	require.ElementsMatch(t, []string{"hi,B," + caller, "hi,C," + caller}, values)
+
+	// A handler without a reply doesn't produce a response.
+	resps, err = rpcs[0].Call(ctx, testMessage{Value: "silent"}, players)
+	require.NoError(t, err)
+
+	_, more := <-resps
+	require.False(t, more)
+}
+
+func TestRPC_Failures_Call(t *testing.T) {
+	minos, rpcs, stop := makeInstances(t, 2)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resps, err := rpcs[0].Call(ctx, testMessage{Value: "oops"},
+		mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	err = waitError(t, resps)
+	require.EqualError(t, err, "remote: couldn't process request: oops")
+
+	scope := mino.PlayersScope(mino.NewAddresses(minos[1].GetAddress()))
+
+	_, err = minos[1].CreateRPC("scoped",
+		mino.NewScopedHandler(testHandler{}, scope), testFactory{})
+	require.NoError(t, err)
+
+	rpc, err := minos[0].CreateRPC("scoped", testHandler{}, testFactory{})
+	require.NoError(t, err)
+
+	resps, err = rpc.Call(ctx, testMessage{}, mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	err = waitError(t, resps)
+	require.EqualError(t, err, "remote: request refused: address "+
+		minos[0].GetAddress().String()+" is out of the scope of the handler")
+
+	resps, err = rpcs[0].Call(ctx, testMessage{}, mino.NewAddresses(fake.NewAddress(0)))
+	require.NoError(t, err)
+
+	err = waitError(t, resps)
+	require.EqualError(t, err, "failed to connect: invalid address type 'fake.Address'")
+
+	unknown, err := minos[0].CreateRPC("unknown", testHandler{}, testFactory{})
+	require.NoError(t, err)
+
+	resps, err = unknown.Call(ctx, testMessage{}, mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	err = waitError(t, resps)
+	require.EqualError(t, err, "remote: unknown rpc '/unknown'")
+
+	rpcs[0].factory = fake.NewBadMessageFactory()
+
+	resps, err = rpcs[0].Call(ctx, testMessage{}, mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	err = waitError(t, resps)
+	require.EqualError(t, err, fake.Err("couldn't unmarshal payload"))
+
+	rpcs[0].overlay = &overlay{context: fake.NewBadContext()}
+
+	_, err = rpcs[0].Call(ctx, testMessage{}, mino.NewAddresses())
+	require.EqualError(t, err, fake.Err("while serializing"))
+}
+
+func TestRPC_Canceled_Call(t *testing.T) {
+	minos, rpcs, stop := makeInstances(t, 2)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	resps, err := rpcs[0].Call(ctx, testMessage{Value: "slow"},
+		mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	err = waitError(t, resps)
+	require.Equal(t, context.Canceled, err)
+}
+
+func TestRPC_Stream(t *testing.T) {
+	minos, rpcs, stop := makeInstances(t, 3)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	players := mino.NewAddresses(minos[1].GetAddress(), minos[2].GetAddress())
+
+	out, in, err := rpcs[0].Stream(ctx, players)
+	require.NoError(t, err)
+
+	orchestrator := marshalAddress(address{orchestrator: true, host: minos[0].addr.host})
+
+	// The message goes from B to C, and C sends it back to the orchestrator.
+	msg := testMessage{
+		Value: "hi",
+		Route: []string{marshalAddress(minos[2].addr), orchestrator},
+	}
+
+	err = <-out.Send(msg, minos[1].GetAddress())
+	require.NoError(t, err)
+
+	from, reply, err := in.Recv(ctx)
+	require.NoError(t, err)
+	require.Equal(t, minos[2].GetAddress(), from)
+	require.Equal(t, "hi,B,C", reply.(testMessage).Value)
+
+	err = <-out.Send(testMessage{Value: "hey"}, minos[2].GetAddress())
+	require.NoError(t, err)
+
+	from, reply, err = in.Recv(ctx)
+	require.NoError(t, err)
+	require.Equal(t, minos[2].GetAddress(), from)
+	require.Equal(t, "hey,C", reply.(testMessage).Value)
+
+	sess := &session{context: fake.NewBadContext()}
+
+	err = <-sess.Send(testMessage{}, minos[1].GetAddress())
+	require.EqualError(t, err, fake.Err("failed to serialize msg"))
+
+	cancel()
+
+	_, _, err = in.Recv(context.Background())
+	require.Equal(t, io.EOF, err)
+
+	err = <-out.Send(testMessage{}, minos[1].GetAddress())
+	require.EqualError(t, err, fmt.Sprintf("session %v is closed",
+		address{orchestrator: true, host: minos[0].addr.host}))
+}
+
+func TestRPC_Scenario_Stream(t *testing.T) {
+	minos, rpcs, stop := makeInstances(t, 12)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addrs := make([]mino.Address, len(minos))
+	for i, m := range minos {
+		addrs[i] = m.GetAddress()
+	}
+
+	// The orchestrator is one of the players and therefore the root of the
+	// tree, which has a height of 3 by default so that some of the players are
+	// only reached through the others.
+	out, in, err := rpcs[0].Stream(ctx, mino.NewAddresses(addrs...))
+	require.NoError(t, err)
+
+	err = <-out.Send(testMessage{Value: "ping"}, addrs...)
+	require.NoError(t, err)
+
+	values := make([]string, 0, len(minos))
+
+	for range minos {
+		from, msg, err := in.Recv(ctx)
+		require.NoError(t, err)
+
+		value := msg.(testMessage).Value
+		require.True(t, strings.HasSuffix(value, ","+string(rune('A'+indexOf(addrs, from)))))
+
+		values = append(values, value)
+	}
+
+	require.Len(t, values, len(minos))
+
+	// A message goes from one leaf of the tree to the other before it comes
+	// back to the orchestrator.
+	orchestrator := marshalAddress(address{orchestrator: true, host: minos[0].addr.host})
+
+	msg := testMessage{
+		Value: "hi",
+		Route: []string{marshalAddress(minos[11].addr), orchestrator},
+	}
+
+	err = <-out.Send(msg, addrs[10])
+	require.NoError(t, err)
+
+	from, reply, err := in.Recv(ctx)
+	require.NoError(t, err)
+	require.Equal(t, addrs[11], from)
+	require.Equal(t, "hi,K,L", reply.(testMessage).Value)
+}
+
+func TestRPC_Unreachable_Stream(t *testing.T) {
+	minos, rpcs, stop := makeInstances(t, 8)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addrs := make([]mino.Address, len(minos))
+	for i, m := range minos {
+		addrs[i] = m.GetAddress()
+	}
+
+	minos[1].Stop()
+
+	out, in, err := rpcs[0].Stream(ctx, mino.NewAddresses(addrs...))
+	require.NoError(t, err)
+
+	// The routes are corrected so that the participants reached through the
+	// stopped one still receive the message.
+	err = <-out.Send(testMessage{Value: "ping"}, append(addrs[:1:1], addrs[2:]...)...)
+	require.NoError(t, err)
+
+	senders := map[string]struct{}{}
+
+	for range addrs[1:] {
+		from, _, err := in.Recv(ctx)
+		require.NoError(t, err)
+
+		senders[from.String()] = struct{}{}
+	}
+
+	require.Len(t, senders, len(addrs)-1)
+	require.NotContains(t, senders, addrs[1].String())
+}
+
+func TestRPC_Failures_Stream(t *testing.T) {
+	minos, rpcs, stop := makeInstances(t, 2)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, _, err := rpcs[0].Stream(ctx, mino.NewAddresses())
+	require.EqualError(t, err, "empty list of addresses")
+
+	_, _, err = rpcs[0].Stream(ctx, mino.NewAddresses(fake.NewAddress(0)))
+	require.EqualError(t, err, "failed to open stream to fake.Address[0]: "+
+		"failed to connect: invalid address type 'fake.Address'")
+
+	unknown, err := minos[0].CreateRPC("unknown", testHandler{}, testFactory{})
+	require.NoError(t, err)
+
+	_, _, err = unknown.Stream(ctx, mino.NewAddresses(minos[1].GetAddress()))
+	require.EqualError(t, err, fmt.Sprintf("stream to %v refused: remote: unknown rpc '/unknown'",
+		minos[1].GetAddress()))
+
+	_, in, err := rpcs[0].Stream(ctx, mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	timeout, cancelTimeout := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancelTimeout()
+
+	_, _, err = in.Recv(timeout)
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	rpcs[0].factory = fake.NewBadMessageFactory()
+
+	out, in, err := rpcs[0].Stream(ctx, mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	err = <-out.Send(testMessage{}, minos[1].GetAddress())
+	require.NoError(t, err)
+
+	_, _, err = in.Recv(ctx)
+	require.EqualError(t, err, fake.Err("couldn't deserialize"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// makeInstances creates the instances and stores the certificate of each of
+// them in the store of the others.
+func makeInstances(t *testing.T, n int) ([]*Minoquic, []*RPC, func()) {
+	minos := make([]*Minoquic, n)
+	rpcs := make([]*RPC, n)
+
+	for i := range minos {
+		m, err := NewMinoquic("127.0.0.1:0", tree.NewRouter(AddressFactory{}),
+			WithTimeout(time.Second))
+		require.NoError(t, err)
+
+		rpc, err := m.CreateRPC("test", testHandler{name: string(rune('A' + i))}, testFactory{})
+		require.NoError(t, err)
+
+		minos[i] = m
+		rpcs[i] = rpc.(*RPC)
+	}
+
+	for _, m := range minos {
+		for _, other := range minos {
+			err := m.GetCertificateStore().Store(other.GetAddress(), other.GetCertificate())
+			require.NoError(t, err)
+		}
+	}
+
+	return minos, rpcs, func() {
+		for _, m := range minos {
+			m.Stop()
+		}
+	}
+}
+
+func indexOf(addrs []mino.Address, addr mino.Address) int {
+	for i, a := range addrs {
+		if a.Equal(addr) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func waitError(t *testing.T, resps <-chan mino.Response) error {
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("a response is expected")
+		return nil
+	case resp := <-resps:
+		_, err := resp.GetMessageOrError()
+		return err
+	}
+}
+
+type testMessage struct {
+	Value string   `json:"value"`
+	Route []string `json:"route,omitempty"`
+}
+
+func (m testMessage) Serialize(ctx serde.Context) ([]byte, error) {
+	return ctx.Marshal(m)
+}
+
+type testFactory struct{}
+
+func (testFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	var msg testMessage
+	err := ctx.Unmarshal(data, &msg)
+
+	return msg, err
+}
+
+// testHandler appends its name to the value of the messages. A stream message
+// is sent to the first address of the route, or back to the sender when the
+// route is empty.
+type testHandler struct {
+	name string
+}
+
+func (h testHandler) Process(req mino.Request) (serde.Message, error) {
+	msg := req.Message.(testMessage)
+
+	switch msg.Value {
+	case "oops":
+		return nil, xerrors.New("oops")
+	case "silent":
+		return nil, nil
+	case "slow":
+		time.Sleep(time.Second)
+	}
+
+	return testMessage{Value: strings.Join([]string{msg.Value, h.name, req.Address.String()}, ",")}, nil
+}
+
+func (h testHandler) Stream(out mino.Sender, in mino.Receiver) error {
+	for {
+		from, msg, err := in.Recv(context.Background())
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		m := msg.(testMessage)
+
+		to := from
+		if len(m.Route) > 0 {
+			to = AddressFactory{}.FromText([]byte(m.Route[0]))
+			m.Route = m.Route[1:]
+		}
+
+		m.Value += "," + h.name
+
+		err = <-out.Send(m, to)
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,462 @@
+// This file contains the session of a stream, which routes the packets among
+// the participants with the routing tables of the router. A participant has a
+// parent, which is the participant that opened the stream to it, and opens
+// relays to the participants that the routing table gives for the
+// destinations of a packet.
+
+package minoquic
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/router"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+// queueSize is the number of packets waiting to be received before the new
+// ones are dropped.
+const queueSize = 1000
+
+// relay is a QUIC stream to another participant of a session.
+type relay struct {
+	sync.Mutex
+
+	addr   mino.Address
+	stream *quic.Stream
+}
+
+func newRelay(addr mino.Address, stream *quic.Stream) *relay {
+	return &relay{
+		addr:   addr,
+		stream: stream,
+	}
+}
+
+func (r *relay) send(f frame) error {
+	r.Lock()
+	defer r.Unlock()
+
+	return writeFrame(r.stream, f)
+}
+
+func (r *relay) receive(f *frame) error {
+	return readFrame(r.stream, f)
+}
+
+// close interrupts both directions of the stream, so that the participant on
+// the other side learns about it when it reads or writes.
+func (r *relay) close() {
+	r.stream.CancelRead(0)
+	r.stream.CancelWrite(0)
+}
+
+// parent is a relay to a participant closer to the orchestrator, with the
+// routing table that the session uses for the packets received from it.
+type parent struct {
+	relay *relay
+	table router.RoutingTable
+}
+
+// session is the state of a stream on a participant.
+//
+// - implements mino.Sender
+// - implements mino.Receiver
+type session struct {
+	sync.Mutex
+	once sync.Once
+
+	rpc     *RPC
+	id      string
+	me      mino.Address
+	relays  map[mino.Address]*relay
+	queue   chan router.Packet
+	done    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	context serde.Context
+
+	parentsLock sync.RWMutex
+	parents     map[*relay]parent
+}
+
+func newSession(rpc *RPC, id string, me mino.Address) *session {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &session{
+		rpc:     rpc,
+		id:      id,
+		me:      me,
+		relays:  make(map[mino.Address]*relay),
+		queue:   make(chan router.Packet, queueSize),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+		context: rpc.overlay.context,
+		parents: make(map[*relay]parent),
+	}
+}
+
+// addParent registers the relay as a parent of the session.
+func (s *session) addParent(r *relay, table router.RoutingTable) {
+	s.parentsLock.Lock()
+	s.parents[r] = parent{relay: r, table: table}
+	s.parentsLock.Unlock()
+}
+
+// removeParent removes the relay from the parents and returns the number of
+// parents left.
+func (s *session) removeParent(r *relay) int {
+	s.parentsLock.Lock()
+	defer s.parentsLock.Unlock()
+
+	delete(s.parents, r)
+
+	return len(s.parents)
+}
+
+// listen reads the packets of the relay until it is closed.
+func (s *session) listen(r *relay) error {
+	for {
+		var f frame
+
+		err := r.receive(&f)
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return xerrors.Errorf("failed to receive: %v", err)
+		}
+
+		switch f.Kind {
+		case kindPacket:
+			s.recvPacket(r, f.Payload)
+		case kindError:
+			return xerrors.Errorf("remote: %s", f.Error)
+		}
+	}
+}
+
+// recvPacket routes a packet received from the relay, to the session itself
+// or to the next participants.
+func (s *session) recvPacket(from *relay, data []byte) {
+	pkt, err := s.rpc.overlay.router.GetPacketFactory().PacketOf(s.context, data)
+	if err != nil {
+		dela.Logger.Warn().Err(err).Stringer("from", from.addr).Msg("packet malformed")
+		return
+	}
+
+	s.parentsLock.RLock()
+	defer s.parentsLock.RUnlock()
+
+	// Try to send the packet to each parent until one works.
+	for _, p := range s.parents {
+		sent, errs := s.sendPacket(p, pkt, from)
+
+		for _, err := range errs {
+			dela.Logger.Warn().Err(err).Stringer("from", from.addr).Msg("failed to route packet")
+		}
+
+		if sent {
+			return
+		}
+	}
+
+	dela.Logger.Warn().
+		Stringer("from", from.addr).
+		Msgf("packet is dropped (tried %d parent-s)", len(s.parents))
+}
+
+// Send implements mino.Sender. It sends the message to the addresses through
+// the relays or the parent, and returns a channel with the errors, which is
+// already closed.
+func (s *session) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	errs := s.send(msg, addrs)
+
+	out := make(chan error, len(errs))
+	for _, err := range errs {
+		out <- err
+	}
+
+	close(out)
+
+	return out
+}
+
+func (s *session) send(msg serde.Message, addrs []mino.Address) []error {
+	data, err := msg.Serialize(s.context)
+	if err != nil {
+		return []error{xerrors.Errorf("failed to serialize msg: %v", err)}
+	}
+
+	select {
+	case <-s.done:
+		return []error{xerrors.Errorf("session %v is closed", s.me)}
+	default:
+	}
+
+	s.parentsLock.RLock()
+	defer s.parentsLock.RUnlock()
+
+	for _, p := range s.parents {
+		pkt := p.table.Make(s.me, addrs, data)
+
+		sent, errs := s.sendPacket(p, pkt, nil)
+		if sent {
+			return errs
+		}
+	}
+
+	return []error{xerrors.New("packet ignored")}
+}
+
+// Recv implements mino.Receiver. It waits for a message of the stream, and
+// returns io.EOF when the stream is closed.
+func (s *session) Recv(ctx context.Context) (mino.Address, serde.Message, error) {
+	select {
+	case pkt := <-s.queue:
+		return s.deliver(pkt)
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case <-s.done:
+		// The packets that arrived before the end of the stream are still
+		// delivered.
+		select {
+		case pkt := <-s.queue:
+			return s.deliver(pkt)
+		default:
+			return nil, nil, io.EOF
+		}
+	}
+}
+
+func (s *session) deliver(pkt router.Packet) (mino.Address, serde.Message, error) {
+	msg, err := s.rpc.factory.Deserialize(s.context, pkt.GetMessage())
+	if err != nil {
+		return nil, nil, xerrors.Errorf("couldn't deserialize: %v", err)
+	}
+
+	return pkt.GetSource(), msg, nil
+}
+
+// sendPacket delivers the packet to the session if it is one of the
+// destinations, and forwards it to the routes of the table of the parent. It
+// returns false if the packet is neither for the session nor routed.
+func (s *session) sendPacket(p parent, pkt router.Packet, from *relay) (bool, []error) {
+	var errs []error
+
+	me := pkt.Slice(s.me)
+	if me != nil {
+		select {
+		case s.queue <- me:
+		default:
+			errs = append(errs, xerrors.Errorf("%v dropped the packet: queue is full", s.me))
+		}
+	}
+
+	routes, voids := p.table.Forward(pkt)
+	for addr, void := range voids {
+		errs = append(errs, xerrors.Errorf("no route to %v: %v", addr, void.Error))
+	}
+
+	if len(routes) == 0 && len(voids) == 0 {
+		return me != nil, errs
+	}
+
+	for addr, packet := range routes {
+		errs = append(errs, s.sendTo(p, addr, packet, from)...)
+	}
+
+	return true, errs
+}
+
+func (s *session) sendTo(p parent, to mino.Address, pkt router.Packet, from *relay) []error {
+	if to == nil {
+		if p.relay == from {
+			// The packet comes from the parent, which means nobody on the
+			// path knows the destinations.
+			errs := make([]error, 0, len(pkt.GetDestination()))
+			for _, addr := range pkt.GetDestination() {
+				errs = append(errs, xerrors.Errorf("no route to %v", addr))
+			}
+
+			return errs
+		}
+
+		err := s.write(p.relay, pkt)
+		if err != nil {
+			return []error{xerrors.Errorf("session %v is closing: %v", s.me, err)}
+		}
+
+		return nil
+	}
+
+	r, err := s.setupRelay(p, to)
+	if err != nil {
+		dela.Logger.Warn().Err(err).Stringer("to", to).Msg("failed to setup relay")
+
+		// Try to open a different relay.
+		return s.onFailure(p, to, pkt, from)
+	}
+
+	err = s.write(r, pkt)
+	if err != nil {
+		dela.Logger.Warn().Err(err).Stringer("to", to).Msg("relay failed to send")
+
+		s.dropRelay(to, r)
+
+		// Try to send the packet through a different route.
+		return s.onFailure(p, to, pkt, from)
+	}
+
+	return nil
+}
+
+func (s *session) write(r *relay, pkt router.Packet) error {
+	data, err := pkt.Serialize(s.context)
+	if err != nil {
+		return xerrors.Errorf("failed to serialize packet: %v", err)
+	}
+
+	return r.send(frame{Kind: kindPacket, Payload: data})
+}
+
+// setupRelay returns the relay to the participant, which is opened with the
+// handshake of the table of the parent if necessary.
+func (s *session) setupRelay(p parent, addr mino.Address) (*relay, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	select {
+	case <-s.done:
+		return nil, xerrors.Errorf("session %v is closed", s.me)
+	default:
+	}
+
+	r, found := s.relays[addr]
+	if found {
+		return r, nil
+	}
+
+	hs, err := p.table.PrepareHandshakeFor(addr).Serialize(s.context)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to serialize handshake: %v", err)
+	}
+
+	open := frame{
+		Kind:      kindStream,
+		Path:      s.rpc.path,
+		ID:        s.id,
+		Handshake: hs,
+	}
+
+	stream, err := s.rpc.overlay.open(s.ctx, addr, open)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to open relay: %v", err)
+	}
+
+	r = newRelay(addr, stream)
+
+	err = waitReady(r, s.rpc.overlay.timeout)
+	if err != nil {
+		r.close()
+		return nil, xerrors.Errorf("relay refused: %v", err)
+	}
+
+	s.relays[addr] = r
+
+	go func() {
+		err := s.listen(r)
+
+		s.dropRelay(addr, r)
+
+		select {
+		case <-s.done:
+		default:
+			if err != nil {
+				dela.Logger.Warn().Err(err).Stringer("to", addr).Msg("relay closed unexpectedly")
+
+				// The relay has lost the connection, therefore the address
+				// is announced as unreachable.
+				p.table.OnFailure(addr)
+			}
+		}
+	}()
+
+	return r, nil
+}
+
+func (s *session) dropRelay(addr mino.Address, r *relay) {
+	s.Lock()
+	if s.relays[addr] == r {
+		delete(s.relays, addr)
+	}
+	s.Unlock()
+
+	r.close()
+}
+
+func (s *session) onFailure(p parent, gateway mino.Address, pkt router.Packet, from *relay) []error {
+	err := p.table.OnFailure(gateway)
+	if err != nil {
+		return []error{xerrors.Errorf("no route to %v: %v", gateway, err)}
+	}
+
+	// Retry to send the packet after the announcement of a link failure. This
+	// recursive call will eventually end by either a success, or a total
+	// failure to send the packet.
+	_, errs := s.sendPacket(p, pkt, from)
+
+	return errs
+}
+
+// close closes the relays of the session, which closes the session of the
+// participants that are only reached through it.
+func (s *session) close() {
+	s.once.Do(func() {
+		close(s.done)
+		s.cancel()
+
+		s.Lock()
+		for _, r := range s.relays {
+			r.close()
+		}
+		s.Unlock()
+
+		s.parentsLock.RLock()
+		for r := range s.parents {
+			r.close()
+		}
+		s.parentsLock.RUnlock()
+	})
+}
+
+// waitReady waits for the participant to confirm that the stream is
+// registered in its session.
+func waitReady(r *relay, timeout time.Duration) error {
+	r.stream.SetReadDeadline(time.Now().Add(timeout))
+	defer r.stream.SetReadDeadline(time.Time{})
+
+	var f frame
+
+	err := r.receive(&f)
+	if err != nil {
+		return xerrors.Errorf("failed to receive: %v", err)
+	}
+
+	switch f.Kind {
+	case kindReady:
+		return nil
+	case kindError:
+		return xerrors.Errorf("remote: %s", f.Error)
+	default:
+		return xerrors.Errorf("unexpected frame '%s'", f.Kind)
+	}
+}
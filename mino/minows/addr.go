@@ -0,0 +1,81 @@
+// This file implements the address for minows.
+
+package minows
+
+import (
+	"fmt"
+
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+)
+
+const (
+	orchestratorCode = "O"
+	followerCode     = "F"
+)
+
+// address is the host and port of a participant. The orchestrator of a stream
+// is different from the address of the same host so that the messages sent
+// back to it are delivered to the stream instead of the handler.
+//
+// - implements mino.Address
+type address struct {
+	orchestrator bool
+	host         string
+}
+
+// NewAddress creates a new address of the host.
+func NewAddress(host string) mino.Address {
+	return address{host: host}
+}
+
+// Equal implements mino.Address. It returns true if both addresses are exactly
+// similar, in the sense that an orchestrator won't match a follower address
+// with the same host.
+func (a address) Equal(other mino.Address) bool {
+	addr, ok := other.(address)
+	return ok && addr == a
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns the text format of
+// the address that can later be deserialized.
+func (a address) MarshalText() ([]byte, error) {
+	data := []byte(followerCode)
+	if a.orchestrator {
+		data = []byte(orchestratorCode)
+	}
+
+	return append(data, []byte(a.host)...), nil
+}
+
+// String implements fmt.Stringer. It returns a string representation of the
+// address.
+func (a address) String() string {
+	if a.orchestrator {
+		return fmt.Sprintf("Orchestrator:%s", a.host)
+	}
+
+	return a.host
+}
+
+// AddressFactory is a factory to deserialize the addresses of minows.
+//
+// - implements mino.AddressFactory
+type AddressFactory struct {
+	serde.Factory
+}
+
+// FromText implements mino.AddressFactory. It returns an instance of an
+// address from a byte slice.
+func (f AddressFactory) FromText(text []byte) mino.Address {
+	str := string(text)
+
+	if len(str) == 0 {
+		return address{}
+	}
+
+	return address{
+		host:         str[1:],
+		orchestrator: str[0] == orchestratorCode[0],
+	}
+}
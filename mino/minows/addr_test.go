@@ -0,0 +1,43 @@
+package minows
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestAddress_Equal(t *testing.T) {
+	addr := NewAddress("127.0.0.1:2000")
+
+	require.True(t, addr.Equal(addr))
+	require.True(t, addr.Equal(NewAddress("127.0.0.1:2000")))
+	require.False(t, addr.Equal(NewAddress("127.0.0.1:2001")))
+	require.False(t, addr.Equal(address{orchestrator: true, host: "127.0.0.1:2000"}))
+	require.False(t, addr.Equal(fake.NewAddress(0)))
+}
+
+func TestAddress_MarshalText(t *testing.T) {
+	text, err := NewAddress("127.0.0.1:2000").MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "F127.0.0.1:2000", string(text))
+
+	text, err = address{orchestrator: true, host: "127.0.0.1:2000"}.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "O127.0.0.1:2000", string(text))
+}
+
+func TestAddress_String(t *testing.T) {
+	require.Equal(t, "127.0.0.1:2000", NewAddress("127.0.0.1:2000").String())
+	require.Equal(t, "Orchestrator:127.0.0.1:2000",
+		address{orchestrator: true, host: "127.0.0.1:2000"}.String())
+}
+
+func TestAddressFactory_FromText(t *testing.T) {
+	fac := AddressFactory{}
+
+	require.Equal(t, NewAddress("127.0.0.1:2000"), fac.FromText([]byte("F127.0.0.1:2000")))
+	require.Equal(t, address{orchestrator: true, host: "127.0.0.1:2000"},
+		fac.FromText([]byte("O127.0.0.1:2000")))
+	require.Equal(t, address{}, fac.FromText(nil))
+}
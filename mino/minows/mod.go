@@ -0,0 +1,357 @@
+// Package minows is an implementation of Mino over WebSocket connections, so
+// that light clients running in a browser can call the RPCs of the nodes
+// without a gRPC-web proxy.
+//
+// A node accepts the connections on the /mino path. The caller of an RPC opens
+// a connection to every player and writes a first frame with the path of the
+// RPC and whether it is a call or a stream. A call is answered by a single
+// frame, while a stream keeps the connection open and the frames carry the
+// messages in both directions. The orchestrator of a stream is the only one
+// connected to every player, therefore it relays the messages that a player
+// sends to another one.
+//
+// The frames are JSON documents so that a browser needs nothing more than the
+// WebSocket API to talk to a node:
+//
+//  {"kind":"call","path":"/proof","from":"Fclient","payload":{...}}
+//  {"kind":"reply","payload":{...}}
+//
+// A browser can't accept connections, which means it can call the RPCs and
+// orchestrate streams but can't be one of the players. The address of the
+// caller is declared by the frames and is not authenticated by the transport,
+// as opposed to minogrpc which relies on the certificates of the nodes. A
+// handler that must know who is calling needs a signed message, like a
+// transaction. The connections are not encrypted either, and the nodes are
+// expected to be behind a proxy that terminates TLS when they are reached from
+// the Internet.
+package minows
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	sjson "go.dedis.ch/dela/serde/json"
+	"golang.org/x/net/websocket"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// endpoint is the path of the HTTP server that accepts the connections.
+	endpoint = "/mino"
+
+	defaultTimeout = 10 * time.Second
+
+	kindCall    = "call"
+	kindStream  = "stream"
+	kindReply   = "reply"
+	kindMessage = "message"
+	kindError   = "error"
+)
+
+// frame is the JSON document of a WebSocket message.
+type frame struct {
+	Kind    string          `json:"kind"`
+	Path    string          `json:"path,omitempty"`
+	From    string          `json:"from,omitempty"`
+	To      string          `json:"to,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// Option is the type of option to configure the instance.
+type Option func(*template)
+
+type template struct {
+	public  string
+	origins []string
+	timeout time.Duration
+}
+
+// WithPublicAddress sets the host and port that the other participants use to
+// reach the node, when it is different from the address it listens on.
+func WithPublicAddress(host string) Option {
+	return func(tmpl *template) {
+		tmpl.public = host
+	}
+}
+
+// WithOrigins sets the origins of the browsers allowed to connect, in addition
+// to the origin of the node itself. The wildcard '*' allows any origin.
+func WithOrigins(origins ...string) Option {
+	return func(tmpl *template) {
+		tmpl.origins = append(tmpl.origins, origins...)
+	}
+}
+
+// WithTimeout sets the amount of time to wait for a connection to a
+// participant to be opened.
+func WithTimeout(timeout time.Duration) Option {
+	return func(tmpl *template) {
+		tmpl.timeout = timeout
+	}
+}
+
+// Minows is an implementation of the Mino interface using WebSocket
+// connections.
+//
+// - implements mino.Mino
+type Minows struct {
+	*overlay
+
+	segments []string
+}
+
+type overlay struct {
+	sync.Mutex
+
+	addr     address
+	listener net.Listener
+	server   *http.Server
+	rpcs     map[string]*RPC
+	context  serde.Context
+	addrFac  mino.AddressFactory
+	origins  []string
+	timeout  time.Duration
+}
+
+// NewMinows creates a new instance that listens on the address, e.g.
+// 127.0.0.1:2000. The server is started right away.
+func NewMinows(listen string, opts ...Option) (*Minows, error) {
+	tmpl := template{
+		timeout: defaultTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(&tmpl)
+	}
+
+	lis, err := net.Listen("tcp", listen)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to listen: %v", err)
+	}
+
+	if tmpl.public == "" {
+		tmpl.public = lis.Addr().String()
+	}
+
+	o := &overlay{
+		addr:     address{host: tmpl.public},
+		listener: lis,
+		rpcs:     make(map[string]*RPC),
+		context:  sjson.NewContext(),
+		addrFac:  AddressFactory{},
+		origins:  tmpl.origins,
+		timeout:  tmpl.timeout,
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(endpoint, websocket.Server{
+		Handshake: o.handshake,
+		Handler:   o.handle,
+	})
+
+	o.server = &http.Server{Handler: mux}
+
+	go func() {
+		err := o.server.Serve(lis)
+		if err != http.ErrServerClosed {
+			dela.Logger.Err(err).Msg("websocket server stopped")
+		}
+	}()
+
+	dela.Logger.Info().Stringer("address", o.addr).Msg("websocket server started")
+
+	return &Minows{overlay: o}, nil
+}
+
+// GetAddressFactory implements mino.Mino. It returns the address factory.
+func (m *Minows) GetAddressFactory() mino.AddressFactory {
+	return m.addrFac
+}
+
+// GetAddress implements mino.Mino. It returns the address that other
+// participants should use to contact this instance.
+func (m *Minows) GetAddress() mino.Address {
+	return m.addr
+}
+
+// WithSegment implements mino.Mino. It returns a new mino instance that will
+// have its URI path extended with the provided segment.
+func (m *Minows) WithSegment(segment string) mino.Mino {
+	segments := append(append([]string{}, m.segments...), segment)
+
+	return &Minows{
+		overlay:  m.overlay,
+		segments: segments,
+	}
+}
+
+// CreateRPC implements mino.Mino. It creates an RPC that can send to and
+// receive from the unique path.
+func (m *Minows) CreateRPC(name string, h mino.Handler, f serde.Factory) (mino.RPC, error) {
+	path := "/" + strings.Join(append(append([]string{}, m.segments...), name), "/")
+
+	rpc := &RPC{
+		overlay: m.overlay,
+		path:    path,
+		handler: h,
+		factory: f,
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	_, found := m.rpcs[path]
+	if found {
+		return nil, xerrors.Errorf("rpc '%s' already exists", path)
+	}
+
+	m.rpcs[path] = rpc
+
+	return rpc, nil
+}
+
+// Stop stops the server. The connections of the streams already opened are
+// closed by their orchestrator.
+func (m *Minows) Stop() error {
+	err := m.server.Close()
+	if err != nil {
+		return xerrors.Errorf("failed to stop server: %v", err)
+	}
+
+	return nil
+}
+
+// handshake accepts the connections without an origin, which are not from a
+// browser, or from the origin of the node or one of the allowed origins.
+func (o *overlay) handshake(cfg *websocket.Config, r *http.Request) error {
+	origin, err := websocket.Origin(cfg, r)
+	if err != nil {
+		return err
+	}
+
+	if origin == nil || origin.Host == r.Host {
+		return nil
+	}
+
+	value := origin.Scheme + "://" + origin.Host
+
+	for _, allowed := range o.origins {
+		if allowed == "*" || allowed == value {
+			return nil
+		}
+	}
+
+	return xerrors.Errorf("origin '%s' not allowed", value)
+}
+
+// handle reads the first frame of a connection and runs the call or the
+// stream of the RPC.
+func (o *overlay) handle(ws *websocket.Conn) {
+	defer ws.Close()
+
+	c := &conn{ws: ws}
+
+	var f frame
+
+	err := c.receive(&f)
+	if err != nil {
+		dela.Logger.Debug().Err(err).Msg("websocket connection closed")
+		return
+	}
+
+	o.Lock()
+	rpc, found := o.rpcs[f.Path]
+	o.Unlock()
+
+	if !found {
+		c.sendError(xerrors.Errorf("unknown rpc '%s'", f.Path))
+		return
+	}
+
+	from := o.addrFac.FromText([]byte(f.From))
+
+	switch f.Kind {
+	case kindCall:
+		reply, err := rpc.process(from, f.Payload)
+		if err != nil {
+			c.sendError(err)
+			return
+		}
+
+		err = c.send(frame{Kind: kindReply, Payload: reply})
+		if err != nil {
+			dela.Logger.Debug().Err(err).Msg("failed to reply")
+		}
+	case kindStream:
+		err = rpc.serve(c)
+		if err != nil {
+			c.sendError(err)
+		}
+	default:
+		c.sendError(xerrors.Errorf("unknown kind '%s'", f.Kind))
+	}
+}
+
+// dial opens a connection to the participant.
+func (o *overlay) dial(addr mino.Address) (*conn, error) {
+	a, ok := addr.(address)
+	if !ok {
+		return nil, xerrors.Errorf("invalid address type '%T'", addr)
+	}
+
+	location := url.URL{Scheme: "ws", Host: a.host, Path: endpoint}
+	origin := url.URL{Scheme: "http", Host: a.host}
+
+	cfg, err := websocket.NewConfig(location.String(), origin.String())
+	if err != nil {
+		return nil, xerrors.Errorf("invalid location: %v", err)
+	}
+
+	cfg.Dialer = &net.Dialer{Timeout: o.timeout}
+
+	ws, err := websocket.DialConfig(cfg)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to dial: %v", err)
+	}
+
+	return &conn{ws: ws}, nil
+}
+
+// conn is a WebSocket connection that can be written by several goroutines.
+// The frames are read by a single one.
+type conn struct {
+	sync.Mutex
+
+	ws *websocket.Conn
+}
+
+func (c *conn) send(f frame) error {
+	c.Lock()
+	defer c.Unlock()
+
+	return websocket.JSON.Send(c.ws, f)
+}
+
+func (c *conn) sendError(err error) {
+	err = c.send(frame{Kind: kindError, Error: err.Error()})
+	if err != nil {
+		dela.Logger.Debug().Err(err).Msg("failed to send the error")
+	}
+}
+
+func (c *conn) receive(f *frame) error {
+	return websocket.JSON.Receive(c.ws, f)
+}
+
+func (c *conn) close() error {
+	return c.ws.Close()
+}
@@ -0,0 +1,127 @@
+package minows
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"golang.org/x/net/websocket"
+)
+
+func TestMinows_New(t *testing.T) {
+	m, err := NewMinows("127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer m.Stop()
+
+	require.Equal(t, AddressFactory{}, m.GetAddressFactory())
+	require.Equal(t, m.listener.Addr().String(), m.GetAddress().String())
+
+	m2, err := NewMinows("127.0.0.1:0", WithPublicAddress("node.example.com:2000"))
+	require.NoError(t, err)
+
+	defer m2.Stop()
+
+	require.Equal(t, NewAddress("node.example.com:2000"), m2.GetAddress())
+
+	_, err = NewMinows("127.0.0.1:-1")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to listen: ")
+}
+
+func TestMinows_CreateRPC(t *testing.T) {
+	m, err := NewMinows("127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer m.Stop()
+
+	rpc, err := m.WithSegment("a").WithSegment("b").CreateRPC("test",
+		mino.UnsupportedHandler{}, fake.MessageFactory{})
+	require.NoError(t, err)
+	require.Equal(t, "/a/b/test", rpc.(*RPC).path)
+
+	_, err = m.CreateRPC("test", mino.UnsupportedHandler{}, fake.MessageFactory{})
+	require.NoError(t, err)
+
+	_, err = m.WithSegment("a").WithSegment("b").CreateRPC("test",
+		mino.UnsupportedHandler{}, fake.MessageFactory{})
+	require.EqualError(t, err, "rpc '/a/b/test' already exists")
+}
+
+func TestMinows_Origins(t *testing.T) {
+	m, err := NewMinows("127.0.0.1:0", WithOrigins("http://dashboard"))
+	require.NoError(t, err)
+
+	defer m.Stop()
+
+	location := "ws://" + m.GetAddress().String() + endpoint
+
+	ws, err := websocket.Dial(location, "", "http://"+m.GetAddress().String())
+	require.NoError(t, err)
+	ws.Close()
+
+	ws, err = websocket.Dial(location, "", "http://dashboard")
+	require.NoError(t, err)
+	ws.Close()
+
+	_, err = websocket.Dial(location, "", "http://evil")
+	require.EqualError(t, err, "websocket.Dial "+location+": bad status")
+}
+
+// This test plays the part of a browser that writes the frames by hand.
+func TestMinows_Browser(t *testing.T) {
+	m, err := NewMinows("127.0.0.1:0", WithOrigins("*"))
+	require.NoError(t, err)
+
+	defer m.Stop()
+
+	mino.MustCreateRPC(m.WithSegment("proofs"), "get", testHandler{name: "A"}, testFactory{})
+
+	location := "ws://" + m.GetAddress().String() + endpoint
+
+	ws, err := websocket.Dial(location, "", "http://wallet.example.com")
+	require.NoError(t, err)
+
+	err = websocket.Message.Send(ws,
+		`{"kind":"call","path":"/proofs/get","from":"Fbrowser","payload":{"value":"hi"}}`)
+	require.NoError(t, err)
+
+	var reply string
+	require.NoError(t, websocket.Message.Receive(ws, &reply))
+	require.Equal(t, `{"kind":"reply","payload":{"value":"hi,A,browser"}}`, reply)
+
+	ws.Close()
+
+	ws, err = websocket.Dial(location, "", "http://wallet.example.com")
+	require.NoError(t, err)
+
+	err = websocket.Message.Send(ws, `{"kind":"stream","path":"/proofs/get","from":"Obrowser"}`)
+	require.NoError(t, err)
+
+	err = websocket.Message.Send(ws,
+		`{"kind":"message","from":"Obrowser","to":"F`+m.GetAddress().String()+`",`+
+			`"payload":{"value":"hi"}}`)
+	require.NoError(t, err)
+
+	require.NoError(t, websocket.Message.Receive(ws, &reply))
+	require.Equal(t, `{"kind":"message","from":"F`+m.GetAddress().String()+`",`+
+		`"to":"Obrowser","payload":{"value":"hi,A"}}`, reply)
+
+	ws.Close()
+
+	for _, request := range []string{
+		`{"kind":"call","path":"/unknown"}`,
+		`{"kind":"unknown","path":"/proofs/get"}`,
+		`{"kind":"call","path":"/proofs/get","payload":{"value":1}}`,
+	} {
+		ws, err = websocket.Dial(location, "", "http://wallet.example.com")
+		require.NoError(t, err)
+
+		require.NoError(t, websocket.Message.Send(ws, request))
+		require.NoError(t, websocket.Message.Receive(ws, &reply))
+		require.Contains(t, reply, `"kind":"error"`)
+
+		ws.Close()
+	}
+}
@@ -0,0 +1,435 @@
+// This file contains the implementation of the RPC over WebSocket connections.
+
+package minows
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+// RPC is an RPC of the instance, which calls the handler of the same path on
+// the other participants.
+//
+// - implements mino.RPC
+type RPC struct {
+	overlay *overlay
+	path    string
+	handler mino.Handler
+	factory serde.Factory
+}
+
+// Call implements mino.RPC. It opens a connection to each player to send the
+// request, which is closed after the reply.
+func (rpc *RPC) Call(ctx context.Context,
+	req serde.Message, players mino.Players) (<-chan mino.Response, error) {
+
+	data, err := req.Serialize(rpc.overlay.context)
+	if err != nil {
+		return nil, xerrors.Errorf("while serializing: %v", err)
+	}
+
+	request := frame{
+		Kind:    kindCall,
+		Path:    rpc.path,
+		From:    marshalAddress(rpc.overlay.addr),
+		Payload: data,
+	}
+
+	out := make(chan mino.Response, players.Len())
+
+	wg := sync.WaitGroup{}
+	wg.Add(players.Len())
+
+	iter := players.AddressIterator()
+	for iter.HasNext() {
+		go func(addr mino.Address) {
+			defer wg.Done()
+
+			resp := rpc.call(ctx, addr, request)
+			if resp != nil {
+				out <- resp
+			}
+		}(iter.GetNext())
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out, nil
+}
+
+// call sends the request to the participant and returns its response, or nil
+// if the handler has nothing to reply.
+func (rpc *RPC) call(ctx context.Context, addr mino.Address, request frame) mino.Response {
+	c, err := rpc.overlay.dial(addr)
+	if err != nil {
+		return mino.NewResponseWithError(addr, xerrors.Errorf("failed to connect: %v", err))
+	}
+
+	defer c.close()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		// The connection is closed to interrupt the reading of the reply when
+		// the context is done.
+		select {
+		case <-ctx.Done():
+			c.close()
+		case <-done:
+		}
+	}()
+
+	err = c.send(request)
+	if err != nil {
+		return mino.NewResponseWithError(addr, xerrors.Errorf("failed to send: %v", err))
+	}
+
+	var reply frame
+
+	err = c.receive(&reply)
+	if ctx.Err() != nil {
+		return mino.NewResponseWithError(addr, ctx.Err())
+	}
+
+	if err != nil {
+		return mino.NewResponseWithError(addr, xerrors.Errorf("failed to receive: %v", err))
+	}
+
+	if reply.Kind == kindError {
+		return mino.NewResponseWithError(addr, xerrors.Errorf("remote: %s", reply.Error))
+	}
+
+	if len(reply.Payload) == 0 {
+		return nil
+	}
+
+	msg, err := rpc.factory.Deserialize(rpc.overlay.context, reply.Payload)
+	if err != nil {
+		return mino.NewResponseWithError(addr,
+			xerrors.Errorf("couldn't unmarshal payload: %v", err))
+	}
+
+	return mino.NewResponse(addr, msg)
+}
+
+// process runs the handler for the request of a call and returns the payload
+// of the reply, which is nil when the handler has nothing to reply.
+func (rpc *RPC) process(from mino.Address, payload []byte) (json.RawMessage, error) {
+	msg, err := rpc.factory.Deserialize(rpc.overlay.context, payload)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't deserialize: %v", err)
+	}
+
+	err = mino.CheckScope(rpc.handler, from)
+	if err != nil {
+		return nil, xerrors.Errorf("request refused: %v", err)
+	}
+
+	resp, err := rpc.handler.Process(mino.Request{Address: from, Message: msg})
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't process request: %v", err)
+	}
+
+	if resp == nil {
+		return nil, nil
+	}
+
+	data, err := resp.Serialize(rpc.overlay.context)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't serialize reply: %v", err)
+	}
+
+	return data, nil
+}
+
+// Stream implements mino.RPC. It opens a connection to each player, which
+// stays open until the context is done. The messages between two players are
+// relayed by the orchestrator.
+func (rpc *RPC) Stream(ctx context.Context, players mino.Players) (mino.Sender, mino.Receiver, error) {
+	s := &stream{
+		overlay:      rpc.overlay,
+		orchestrator: address{orchestrator: true, host: rpc.overlay.addr.host},
+		conns:        make(map[address]*conn),
+		in:           make(chan frame, 100),
+		errs:         make(chan error, players.Len()),
+		done:         make(chan struct{}),
+	}
+
+	open := frame{
+		Kind: kindStream,
+		Path: rpc.path,
+		From: marshalAddress(s.orchestrator),
+	}
+
+	iter := players.AddressIterator()
+	for iter.HasNext() {
+		addr := iter.GetNext()
+
+		c, err := rpc.overlay.dial(addr)
+		if err != nil {
+			s.close()
+			return nil, nil, xerrors.Errorf("failed to connect to %v: %v", addr, err)
+		}
+
+		s.conns[addr.(address)] = c
+
+		err = c.send(open)
+		if err != nil {
+			s.close()
+			return nil, nil, xerrors.Errorf("failed to open stream to %v: %v", addr, err)
+		}
+	}
+
+	for addr, c := range s.conns {
+		go s.relay(addr, c)
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.close()
+		case <-s.done:
+		}
+	}()
+
+	sender := sender{
+		from:    s.orchestrator,
+		context: rpc.overlay.context,
+		write:   s.write,
+	}
+
+	receiver := receiver{
+		context: rpc.overlay.context,
+		factory: rpc.factory,
+		addrFac: rpc.overlay.addrFac,
+		in:      s.in,
+		errs:    s.errs,
+		done:    s.done,
+	}
+
+	return sender, receiver, nil
+}
+
+// serve runs the handler for a stream opened by an orchestrator, until the
+// handler returns or the connection is closed.
+func (rpc *RPC) serve(c *conn) error {
+	in := make(chan frame, 100)
+	done := make(chan struct{})
+	stop := make(chan struct{})
+
+	defer close(stop)
+
+	go func() {
+		defer close(done)
+
+		for {
+			var f frame
+
+			err := c.receive(&f)
+			if err != nil {
+				return
+			}
+
+			if f.Kind != kindMessage {
+				continue
+			}
+
+			select {
+			case in <- f:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	sender := sender{
+		from:    rpc.overlay.addr,
+		context: rpc.overlay.context,
+		write: func(to address, f frame) error {
+			// Everything goes through the orchestrator which relays the
+			// messages to the other players.
+			return c.send(f)
+		},
+	}
+
+	receiver := receiver{
+		context: rpc.overlay.context,
+		factory: rpc.factory,
+		addrFac: rpc.overlay.addrFac,
+		in:      in,
+		done:    done,
+	}
+
+	err := rpc.handler.Stream(sender, mino.ScopeReceiver(rpc.handler, receiver))
+	if err != nil {
+		return xerrors.Errorf("couldn't process: %v", err)
+	}
+
+	return nil
+}
+
+// stream is the state of the orchestrator of a stream.
+type stream struct {
+	sync.Once
+
+	overlay      *overlay
+	orchestrator address
+	conns        map[address]*conn
+	in           chan frame
+	errs         chan error
+	done         chan struct{}
+}
+
+// write sends the frame to the player, if it is part of the stream.
+func (s *stream) write(to address, f frame) error {
+	c, found := s.conns[to]
+	if !found {
+		return xerrors.Errorf("%v is not a player", to)
+	}
+
+	return c.send(f)
+}
+
+// relay reads the frames of the player and delivers them to the orchestrator
+// or to the other players.
+func (s *stream) relay(from address, c *conn) {
+	for {
+		var f frame
+
+		err := c.receive(&f)
+		if err != nil {
+			// Either the orchestrator is done or the handler of the player
+			// has returned.
+			return
+		}
+
+		switch f.Kind {
+		case kindMessage:
+			// A player can only speak for itself.
+			f.From = marshalAddress(from)
+
+			to := s.overlay.addrFac.FromText([]byte(f.To)).(address)
+			if to == s.orchestrator {
+				select {
+				case s.in <- f:
+				case <-s.done:
+					return
+				}
+
+				continue
+			}
+
+			err = s.write(to, f)
+			if err != nil {
+				dela.Logger.Warn().Err(err).Stringer("from", from).Msg("failed to relay")
+			}
+		case kindError:
+			select {
+			case s.errs <- xerrors.Errorf("%v: %s", from, f.Error):
+			default:
+			}
+		}
+	}
+}
+
+func (s *stream) close() {
+	s.Do(func() {
+		close(s.done)
+
+		for _, c := range s.conns {
+			c.close()
+		}
+	})
+}
+
+// sender sends the messages of a stream.
+//
+// - implements mino.Sender
+type sender struct {
+	from    address
+	context serde.Context
+	write   func(to address, f frame) error
+}
+
+// Send implements mino.Sender. It sends the message to each address and
+// returns a channel with the errors, which is already closed.
+func (s sender) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	errs := make(chan error, len(addrs)+1)
+	defer close(errs)
+
+	data, err := msg.Serialize(s.context)
+	if err != nil {
+		errs <- xerrors.Errorf("couldn't marshal message: %v", err)
+		return errs
+	}
+
+	for _, addr := range addrs {
+		to, ok := addr.(address)
+		if !ok {
+			errs <- xerrors.Errorf("invalid address type '%T'", addr)
+			continue
+		}
+
+		err := s.write(to, frame{
+			Kind:    kindMessage,
+			From:    marshalAddress(s.from),
+			To:      marshalAddress(to),
+			Payload: data,
+		})
+		if err != nil {
+			errs <- xerrors.Errorf("couldn't send to %v: %v", to, err)
+		}
+	}
+
+	return errs
+}
+
+// receiver receives the messages of a stream.
+//
+// - implements mino.Receiver
+type receiver struct {
+	context serde.Context
+	factory serde.Factory
+	addrFac mino.AddressFactory
+	in      <-chan frame
+	errs    <-chan error
+	done    <-chan struct{}
+}
+
+// Recv implements mino.Receiver. It waits for a message of the stream, and
+// returns io.EOF when the stream is closed.
+func (r receiver) Recv(ctx context.Context) (mino.Address, serde.Message, error) {
+	select {
+	case f := <-r.in:
+		msg, err := r.factory.Deserialize(r.context, f.Payload)
+		if err != nil {
+			return nil, nil, xerrors.Errorf("couldn't deserialize: %v", err)
+		}
+
+		return r.addrFac.FromText([]byte(f.From)), msg, nil
+	case err := <-r.errs:
+		return nil, nil, err
+	case <-r.done:
+		return nil, nil, io.EOF
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+func marshalAddress(addr address) string {
+	// The marshaling of an address never fails.
+	text, _ := addr.MarshalText()
+
+	return string(text)
+}
@@ -0,0 +1,318 @@
+package minows
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func TestRPC_Call(t *testing.T) {
+	minos, rpcs, stop := makeInstances(t, 3)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	players := mino.NewAddresses(minos[1].GetAddress(), minos[2].GetAddress())
+
+	resps, err := rpcs[0].Call(ctx, testMessage{Value: "hi"}, players)
+	require.NoError(t, err)
+
+	values := []string{}
+	for resp := range resps {
+		msg, err := resp.GetMessageOrError()
+		require.NoError(t, err)
+
+		values = append(values, msg.(testMessage).Value)
+	}
+
+	caller := minos[0].GetAddress().String()
+	require.ElementsMatch(t, []string{"hi,B," + caller, "hi,C," + caller}, values)
+
+	// A handler without a reply doesn't produce a response.
+	resps, err = rpcs[0].Call(ctx, testMessage{Value: "silent"}, players)
+	require.NoError(t, err)
+
+	_, more := <-resps
+	require.False(t, more)
+}
+
+func TestRPC_Failures_Call(t *testing.T) {
+	minos, rpcs, stop := makeInstances(t, 2)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resps, err := rpcs[0].Call(ctx, testMessage{Value: "oops"},
+		mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	err = waitError(t, resps)
+	require.EqualError(t, err, "remote: couldn't process request: oops")
+
+	scope := mino.PlayersScope(mino.NewAddresses(minos[1].GetAddress()))
+
+	_, err = minos[1].CreateRPC("scoped",
+		mino.NewScopedHandler(testHandler{}, scope), testFactory{})
+	require.NoError(t, err)
+
+	rpc, err := minos[0].CreateRPC("scoped", testHandler{}, testFactory{})
+	require.NoError(t, err)
+
+	resps, err = rpc.Call(ctx, testMessage{}, mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	err = waitError(t, resps)
+	require.EqualError(t, err, "remote: request refused: address "+
+		minos[0].GetAddress().String()+" is out of the scope of the handler")
+
+	resps, err = rpcs[0].Call(ctx, testMessage{}, mino.NewAddresses(fake.NewAddress(0)))
+	require.NoError(t, err)
+
+	err = waitError(t, resps)
+	require.EqualError(t, err, "failed to connect: invalid address type 'fake.Address'")
+
+	unknown, err := minos[0].CreateRPC("unknown", testHandler{}, testFactory{})
+	require.NoError(t, err)
+
+	resps, err = unknown.Call(ctx, testMessage{}, mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	err = waitError(t, resps)
+	require.EqualError(t, err, "remote: unknown rpc '/unknown'")
+
+	rpcs[0].factory = fake.NewBadMessageFactory()
+
+	resps, err = rpcs[0].Call(ctx, testMessage{}, mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	err = waitError(t, resps)
+	require.EqualError(t, err, fake.Err("couldn't unmarshal payload"))
+
+	rpcs[0].overlay = &overlay{context: fake.NewBadContext()}
+
+	_, err = rpcs[0].Call(ctx, testMessage{}, mino.NewAddresses())
+	require.EqualError(t, err, fake.Err("while serializing"))
+}
+
+func TestRPC_Canceled_Call(t *testing.T) {
+	minos, rpcs, stop := makeInstances(t, 2)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	resps, err := rpcs[0].Call(ctx, testMessage{Value: "slow"},
+		mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	cancel()
+
+	err = waitError(t, resps)
+	require.Equal(t, context.Canceled, err)
+}
+
+func TestRPC_Stream(t *testing.T) {
+	minos, rpcs, stop := makeInstances(t, 3)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	players := mino.NewAddresses(minos[1].GetAddress(), minos[2].GetAddress())
+
+	out, in, err := rpcs[0].Stream(ctx, players)
+	require.NoError(t, err)
+
+	orchestrator := marshalAddress(address{orchestrator: true, host: minos[0].addr.host})
+
+	// The message goes from B to C through the orchestrator, and C sends it
+	// back to the orchestrator.
+	msg := testMessage{
+		Value: "hi",
+		Route: []string{marshalAddress(minos[2].addr), orchestrator},
+	}
+
+	err = <-out.Send(msg, minos[1].GetAddress())
+	require.NoError(t, err)
+
+	from, reply, err := in.Recv(ctx)
+	require.NoError(t, err)
+	require.Equal(t, minos[2].GetAddress(), from)
+	require.Equal(t, "hi,B,C", reply.(testMessage).Value)
+
+	err = <-out.Send(testMessage{Value: "hey"}, minos[2].GetAddress())
+	require.NoError(t, err)
+
+	from, reply, err = in.Recv(ctx)
+	require.NoError(t, err)
+	require.Equal(t, minos[2].GetAddress(), from)
+	require.Equal(t, "hey,C", reply.(testMessage).Value)
+
+	err = <-out.Send(testMessage{}, fake.NewAddress(0))
+	require.EqualError(t, err, "invalid address type 'fake.Address'")
+
+	err = <-out.Send(testMessage{}, NewAddress("127.0.0.1:1"))
+	require.EqualError(t, err, "couldn't send to 127.0.0.1:1: 127.0.0.1:1 is not a player")
+
+	err = <-sender{context: fake.NewBadContext()}.Send(testMessage{}, minos[1].GetAddress())
+	require.EqualError(t, err, fake.Err("couldn't marshal message"))
+
+	// The error of the handler of a player is reported to the orchestrator.
+	err = <-out.Send(testMessage{Value: "oops"}, minos[1].GetAddress())
+	require.NoError(t, err)
+
+	_, _, err = in.Recv(ctx)
+	require.EqualError(t, err, minos[1].GetAddress().String()+": couldn't process: oops")
+
+	cancel()
+
+	_, _, err = in.Recv(context.Background())
+	require.Equal(t, io.EOF, err)
+}
+
+func TestRPC_Failures_Stream(t *testing.T) {
+	minos, rpcs, stop := makeInstances(t, 2)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, _, err := rpcs[0].Stream(ctx, mino.NewAddresses(minos[1].GetAddress(), fake.NewAddress(0)))
+	require.EqualError(t, err,
+		"failed to connect to fake.Address[0]: invalid address type 'fake.Address'")
+
+	_, in, err := rpcs[0].Stream(ctx, mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	timeout, cancelTimeout := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancelTimeout()
+
+	_, _, err = in.Recv(timeout)
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	rpcs[0].factory = fake.NewBadMessageFactory()
+
+	out, in, err := rpcs[0].Stream(ctx, mino.NewAddresses(minos[1].GetAddress()))
+	require.NoError(t, err)
+
+	err = <-out.Send(testMessage{}, minos[1].GetAddress())
+	require.NoError(t, err)
+
+	_, _, err = in.Recv(ctx)
+	require.EqualError(t, err, fake.Err("couldn't deserialize"))
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeInstances(t *testing.T, n int) ([]*Minows, []*RPC, func()) {
+	minos := make([]*Minows, n)
+	rpcs := make([]*RPC, n)
+
+	for i := range minos {
+		m, err := NewMinows("127.0.0.1:0", WithTimeout(time.Second))
+		require.NoError(t, err)
+
+		rpc, err := m.CreateRPC("test", testHandler{name: string(rune('A' + i))}, testFactory{})
+		require.NoError(t, err)
+
+		minos[i] = m
+		rpcs[i] = rpc.(*RPC)
+	}
+
+	return minos, rpcs, func() {
+		for _, m := range minos {
+			m.Stop()
+		}
+	}
+}
+
+func waitError(t *testing.T, resps <-chan mino.Response) error {
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("a response is expected")
+		return nil
+	case resp := <-resps:
+		_, err := resp.GetMessageOrError()
+		return err
+	}
+}
+
+type testMessage struct {
+	Value string   `json:"value"`
+	Route []string `json:"route,omitempty"`
+}
+
+func (m testMessage) Serialize(ctx serde.Context) ([]byte, error) {
+	return ctx.Marshal(m)
+}
+
+type testFactory struct{}
+
+func (testFactory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	var msg testMessage
+	err := ctx.Unmarshal(data, &msg)
+
+	return msg, err
+}
+
+// testHandler appends its name to the value of the messages. A stream message
+// is sent to the first address of the route, or back to the sender when the
+// route is empty.
+type testHandler struct {
+	name string
+}
+
+func (h testHandler) Process(req mino.Request) (serde.Message, error) {
+	msg := req.Message.(testMessage)
+
+	switch msg.Value {
+	case "oops":
+		return nil, xerrors.New("oops")
+	case "silent":
+		return nil, nil
+	case "slow":
+		time.Sleep(time.Second)
+	}
+
+	return testMessage{Value: strings.Join([]string{msg.Value, h.name, req.Address.String()}, ",")}, nil
+}
+
+func (h testHandler) Stream(out mino.Sender, in mino.Receiver) error {
+	for {
+		from, msg, err := in.Recv(context.Background())
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		m := msg.(testMessage)
+		if m.Value == "oops" {
+			return xerrors.New("oops")
+		}
+
+		to := from
+		if len(m.Route) > 0 {
+			to = AddressFactory{}.FromText([]byte(m.Route[0]))
+			m.Route = m.Route[1:]
+		}
+
+		m.Value += "," + h.name
+
+		err = <-out.Send(m, to)
+		if err != nil {
+			return err
+		}
+	}
+}
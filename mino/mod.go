@@ -17,6 +17,11 @@ import (
 	"go.dedis.ch/dela/serde"
 )
 
+// ErrBackpressure is the error of a message that has not been sent because
+// the overlay has reached a limit, e.g. because a peer is too slow to drain the
+// messages. The caller is expected to slow down before it sends more.
+var ErrBackpressure = errors.New("too many messages in flight")
+
 // Mino is an abstraction of a overlay network. It provides primitives to send
 // messages to a set of participants.
 //
@@ -129,7 +134,8 @@ type Sender interface {
 	// Send sends the message to all the addresses. It returns a channel that
 	// will be populated with errors coming from the network layer if the
 	// message cannot be sent. The channel must be closed after the message has
-	// been sent or failed to be sent.
+	// been sent or failed to be sent. An error that wraps ErrBackpressure
+	// means the message has been dropped because of a limit of the overlay.
 	Send(msg serde.Message, addrs ...Address) <-chan error
 }
 
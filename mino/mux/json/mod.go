@@ -0,0 +1,102 @@
+package json
+
+import (
+	"encoding/json"
+
+	"go.dedis.ch/dela/mino/mux"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	mux.RegisterMessageFormat(serde.FormatJSON, msgFormat{})
+}
+
+// FrameJSON is the JSON message of a frame.
+type FrameJSON struct {
+	Channel uint32
+	Message json.RawMessage
+}
+
+// CreditJSON is the JSON message of a credit.
+type CreditJSON struct {
+	Channel uint32
+	Amount  uint32
+}
+
+// MessageJSON is a JSON container to differentiate the messages of a
+// multiplexed stream.
+type MessageJSON struct {
+	Frame  *FrameJSON  `json:",omitempty"`
+	Credit *CreditJSON `json:",omitempty"`
+}
+
+// MsgFormat is the engine to encode and decode the frames and the credits in
+// JSON format.
+//
+// - implements serde.FormatEngine
+type msgFormat struct{}
+
+// Encode implements serde.FormatEngine. It returns the serialized data of the
+// message if appropriate, otherwise it returns an error.
+func (f msgFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	m := MessageJSON{}
+
+	switch message := msg.(type) {
+	case mux.Frame:
+		inner, err := message.GetMessage().Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to serialize message: %v", err)
+		}
+
+		m.Frame = &FrameJSON{
+			Channel: uint32(message.GetChannel()),
+			Message: inner,
+		}
+	case mux.Credit:
+		m.Credit = &CreditJSON{
+			Channel: uint32(message.GetChannel()),
+			Amount:  message.GetAmount(),
+		}
+	default:
+		return nil, xerrors.Errorf("unsupported message '%T'", msg)
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the message if
+// appropriate, otherwise it returns an error.
+func (f msgFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := MessageJSON{}
+
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	if m.Frame != nil {
+		fac := ctx.GetFactory(mux.MsgKey{})
+		if fac == nil {
+			return nil, xerrors.New("invalid message factory '<nil>'")
+		}
+
+		inner, err := fac.Deserialize(ctx, m.Frame.Message)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to deserialize message: %v", err)
+		}
+
+		return mux.NewFrame(mux.ChannelID(m.Frame.Channel), inner), nil
+	}
+
+	if m.Credit != nil {
+		return mux.NewCredit(mux.ChannelID(m.Credit.Channel), m.Credit.Amount), nil
+	}
+
+	return nil, xerrors.New("message is empty")
+}
@@ -0,0 +1,62 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino/mux"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestMsgFormat_Encode(t *testing.T) {
+	format := msgFormat{}
+
+	ctx := fake.NewContext()
+
+	data, err := format.Encode(ctx, mux.NewFrame(3, fake.Message{}))
+	require.NoError(t, err)
+	require.Equal(t, `{"Frame":{"Channel":3,"Message":{}}}`, string(data))
+
+	data, err = format.Encode(ctx, mux.NewCredit(3, 5))
+	require.NoError(t, err)
+	require.Equal(t, `{"Credit":{"Channel":3,"Amount":5}}`, string(data))
+
+	_, err = format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message 'fake.Message'")
+
+	_, err = format.Encode(ctx, mux.NewFrame(0, fake.NewBadPublicKey()))
+	require.EqualError(t, err, fake.Err("failed to serialize message"))
+
+	_, err = format.Encode(fake.NewBadContext(), mux.NewCredit(0, 1))
+	require.EqualError(t, err, fake.Err("failed to marshal"))
+}
+
+func TestMsgFormat_Decode(t *testing.T) {
+	format := msgFormat{}
+
+	ctx := fake.NewContext()
+	ctx = serde.WithFactory(ctx, mux.MsgKey{}, fake.MessageFactory{})
+
+	msg, err := format.Decode(ctx, []byte(`{"Frame":{"Channel":3,"Message":{}}}`))
+	require.NoError(t, err)
+	require.Equal(t, mux.NewFrame(3, fake.Message{}), msg)
+
+	msg, err = format.Decode(ctx, []byte(`{"Credit":{"Channel":3,"Amount":5}}`))
+	require.NoError(t, err)
+	require.Equal(t, mux.NewCredit(3, 5), msg)
+
+	_, err = format.Decode(ctx, []byte(`{}`))
+	require.EqualError(t, err, "message is empty")
+
+	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("failed to unmarshal"))
+
+	badCtx := serde.WithFactory(ctx, mux.MsgKey{}, nil)
+	_, err = format.Decode(badCtx, []byte(`{"Frame":{}}`))
+	require.EqualError(t, err, "invalid message factory '<nil>'")
+
+	badCtx = serde.WithFactory(ctx, mux.MsgKey{}, fake.NewBadMessageFactory())
+	_, err = format.Decode(badCtx, []byte(`{"Frame":{}}`))
+	require.EqualError(t, err, fake.Err("failed to deserialize message"))
+}
@@ -0,0 +1,130 @@
+package mux
+
+import (
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
+	"golang.org/x/xerrors"
+)
+
+var msgFormats = registry.NewSimpleRegistry()
+
+// RegisterMessageFormat registers the engine for the provided format.
+func RegisterMessageFormat(f serde.Format, e serde.FormatEngine) {
+	msgFormats.Register(f, e)
+}
+
+// ChannelID is the identifier of a logical channel within a stream.
+type ChannelID uint32
+
+// Frame is the wrapper of a message sent on a logical channel.
+//
+// - implements serde.Message
+type Frame struct {
+	channel ChannelID
+	msg     serde.Message
+}
+
+// NewFrame returns a new frame of the channel.
+func NewFrame(channel ChannelID, msg serde.Message) Frame {
+	return Frame{
+		channel: channel,
+		msg:     msg,
+	}
+}
+
+// GetChannel returns the identifier of the channel of the frame.
+func (f Frame) GetChannel() ChannelID {
+	return f.channel
+}
+
+// GetMessage returns the wrapped message.
+func (f Frame) GetMessage() serde.Message {
+	return f.msg
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// frame.
+func (f Frame) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, f)
+	if err != nil {
+		return nil, xerrors.Errorf("encoding failed: %v", err)
+	}
+
+	return data, nil
+}
+
+// Credit is the message sent back by a recipient to allow the sender to send
+// more frames on a channel.
+//
+// - implements serde.Message
+type Credit struct {
+	channel ChannelID
+	amount  uint32
+}
+
+// NewCredit returns a new credit of the given amount of frames for the
+// channel.
+func NewCredit(channel ChannelID, amount uint32) Credit {
+	return Credit{
+		channel: channel,
+		amount:  amount,
+	}
+}
+
+// GetChannel returns the identifier of the channel of the credit.
+func (c Credit) GetChannel() ChannelID {
+	return c.channel
+}
+
+// GetAmount returns the number of frames granted.
+func (c Credit) GetAmount() uint32 {
+	return c.amount
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// credit.
+func (c Credit) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, c)
+	if err != nil {
+		return nil, xerrors.Errorf("encoding failed: %v", err)
+	}
+
+	return data, nil
+}
+
+// MsgKey is the key of the factory of the wrapped messages.
+type MsgKey struct{}
+
+// Factory is the factory of the frames and the credits.
+//
+// - implements serde.Factory
+type Factory struct {
+	msgFac serde.Factory
+}
+
+// NewFactory returns a factory of frames that wraps the messages deserialized
+// by the given factory.
+func NewFactory(f serde.Factory) Factory {
+	return Factory{
+		msgFac: f,
+	}
+}
+
+// Deserialize implements serde.Factory. It populates the frame or the credit if
+// appropriate, otherwise it returns an error.
+func (f Factory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	ctx = serde.WithFactory(ctx, MsgKey{}, f.msgFac)
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("decoding failed: %v", err)
+	}
+
+	return msg, nil
+}
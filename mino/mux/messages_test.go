@@ -0,0 +1,60 @@
+package mux
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func init() {
+	RegisterMessageFormat(fake.GoodFormat, fake.Format{Msg: NewCredit(1, 2)})
+	RegisterMessageFormat(fake.BadFormat, fake.NewBadFormat())
+}
+
+func TestFrame_Getters(t *testing.T) {
+	frame := NewFrame(2, fake.Message{})
+
+	require.Equal(t, ChannelID(2), frame.GetChannel())
+	require.Equal(t, fake.Message{}, frame.GetMessage())
+}
+
+func TestFrame_Serialize(t *testing.T) {
+	frame := NewFrame(1, fake.Message{})
+
+	data, err := frame.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = frame.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("encoding failed"))
+}
+
+func TestCredit_Getters(t *testing.T) {
+	credit := NewCredit(2, 3)
+
+	require.Equal(t, ChannelID(2), credit.GetChannel())
+	require.Equal(t, uint32(3), credit.GetAmount())
+}
+
+func TestCredit_Serialize(t *testing.T) {
+	credit := NewCredit(1, 2)
+
+	data, err := credit.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = credit.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("encoding failed"))
+}
+
+func TestFactory_Deserialize(t *testing.T) {
+	fac := NewFactory(fake.MessageFactory{})
+
+	msg, err := fac.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.Equal(t, NewCredit(1, 2), msg)
+
+	_, err = fac.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("decoding failed"))
+}
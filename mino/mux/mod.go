@@ -0,0 +1,421 @@
+// Package mux implements the multiplexing of logical channels within a single
+// stream of an RPC.
+//
+// Protocols that need several concurrent conversations with the same players
+// can open one stream and use a channel per conversation instead of opening
+// multiple streams. Each message is sent in a frame that carries the
+// identifier of its channel, and the receiving side dispatches the frames to
+// the channels.
+//
+// The flow control is independent for each channel and each peer. A sender
+// can have at most a window of frames not yet consumed by the recipient, which
+// returns credits as the frames are read. A channel that is not read therefore
+// only blocks the senders of that channel.
+//
+// The RPC must be created with the factory of this package wrapping the
+// factory of the messages so that the frames and the credits can be decoded.
+package mux
+
+import (
+	"context"
+	"sync"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/wrapper"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+// DefaultWindow is the default number of frames that can be sent on a channel
+// to a peer before it returns credits.
+const DefaultWindow = 64
+
+// Option is the type of option to configure the multiplexed streams.
+type Option func(*template)
+
+// WithWindow sets the number of frames that can be sent on a channel to a peer
+// before it returns credits. The peers of a stream are expected to use the
+// same window.
+func WithWindow(n uint32) Option {
+	return func(tmpl *template) {
+		tmpl.window = n
+	}
+}
+
+type template struct {
+	window uint32
+}
+
+func newTemplate(opts []Option) template {
+	tmpl := template{
+		window: DefaultWindow,
+	}
+
+	for _, opt := range opts {
+		opt(&tmpl)
+	}
+
+	if tmpl.window == 0 {
+		tmpl.window = 1
+	}
+
+	return tmpl
+}
+
+// RPC is a wrapper around an RPC that opens multiplexed streams.
+type RPC struct {
+	rpc  mino.RPC
+	opts []Option
+}
+
+// NewRPC returns a new multiplexed RPC. The RPC to wrap must be created with
+// the handler and the factory of this package.
+func NewRPC(rpc mino.RPC, opts ...Option) RPC {
+	return RPC{
+		rpc:  rpc,
+		opts: opts,
+	}
+}
+
+// Call sends the request to the players on the default channel and returns the
+// replies.
+func (rpc RPC) Call(ctx context.Context,
+	req serde.Message, players mino.Players) (<-chan mino.Response, error) {
+
+	return wrapper.Call(ctx, rpc.rpc, codec{}, req, players)
+}
+
+// Stream opens a stream with the players and returns the multiplexer of its
+// channels.
+func (rpc RPC) Stream(ctx context.Context, players mino.Players) (*Mux, error) {
+	out, in, err := rpc.rpc.Stream(ctx, players)
+	if err != nil {
+		return nil, xerrors.Errorf("stream failed: %v", err)
+	}
+
+	return Open(ctx, out, in, rpc.opts...), nil
+}
+
+// StreamFunc is the function that handles a multiplexed stream.
+type StreamFunc func(m *Mux) error
+
+// Handler is a wrapper around a handler so that the streams are multiplexed.
+//
+// - implements mino.Handler
+type Handler struct {
+	mino.Handler
+
+	stream StreamFunc
+	opts   []Option
+}
+
+// NewHandler returns a new multiplexed handler. The requests of the calls are
+// processed by the handler, while the streams are handled by the function.
+func NewHandler(h mino.Handler, fn StreamFunc, opts ...Option) Handler {
+	return Handler{
+		Handler: h,
+		stream:  fn,
+		opts:    opts,
+	}
+}
+
+// Process implements mino.Handler. It unwraps the request and wraps the reply.
+func (h Handler) Process(req mino.Request) (serde.Message, error) {
+	return wrapper.Process(h.Handler, codec{}, req)
+}
+
+// codec wraps the requests and the replies of the calls in frames of the
+// default channel.
+//
+// - implements wrapper.Codec
+type codec struct{}
+
+// Wrap implements wrapper.Codec. It returns a frame of the default channel.
+func (codec) Wrap(msg serde.Message) serde.Message {
+	return NewFrame(0, msg)
+}
+
+// Unwrap implements wrapper.Codec. It returns the message of the frame.
+func (codec) Unwrap(msg serde.Message) serde.Message {
+	frame, ok := msg.(Frame)
+	if ok {
+		return frame.msg
+	}
+
+	return msg
+}
+
+// Stream implements mino.Handler. It calls the stream function with the
+// multiplexer of the stream, which is read until the stream is closed.
+func (h Handler) Stream(out mino.Sender, in mino.Receiver) error {
+	return h.stream(Open(context.Background(), out, in, h.opts...))
+}
+
+// peerChannel is the key of the flow control of a channel for a peer.
+type peerChannel struct {
+	peer    string
+	channel ChannelID
+}
+
+// Mux is the multiplexer of the logical channels of a stream. The underlying
+// receiver is read in the background until the context is done or the stream
+// is closed.
+type Mux struct {
+	sync.Mutex
+
+	cond     *sync.Cond
+	out      mino.Sender
+	window   uint32
+	channels map[ChannelID]*Channel
+	// inflight is the number of frames sent without credits in return.
+	inflight map[peerChannel]uint32
+	err      error
+}
+
+// Open returns the multiplexer of the stream made of the sender and the
+// receiver.
+func Open(ctx context.Context, out mino.Sender, in mino.Receiver, opts ...Option) *Mux {
+	tmpl := newTemplate(opts)
+
+	m := &Mux{
+		out:      out,
+		window:   tmpl.window,
+		channels: make(map[ChannelID]*Channel),
+		inflight: make(map[peerChannel]uint32),
+	}
+
+	m.cond = sync.NewCond(&m.Mutex)
+
+	go m.listen(ctx, in)
+
+	return m
+}
+
+// Channel returns the logical channel with the identifier, which is created
+// if necessary.
+func (m *Mux) Channel(id ChannelID) *Channel {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.getChannel(id)
+}
+
+func (m *Mux) getChannel(id ChannelID) *Channel {
+	ch, found := m.channels[id]
+	if found {
+		return ch
+	}
+
+	ch = &Channel{
+		id:       id,
+		mux:      m,
+		notify:   make(chan struct{}, 1),
+		consumed: make(map[string]uint32),
+	}
+
+	if m.err != nil {
+		ch.queue = []item{{err: m.err}}
+	}
+
+	m.channels[id] = ch
+
+	return ch
+}
+
+// acquire waits for the channel to have room for a frame to the peer.
+func (m *Mux) acquire(id ChannelID, to mino.Address) error {
+	key := peerChannel{peer: to.String(), channel: id}
+
+	m.Lock()
+	defer m.Unlock()
+
+	for m.err == nil && m.inflight[key] >= m.window {
+		m.cond.Wait()
+	}
+
+	if m.err != nil {
+		return xerrors.Errorf("stream closed: %v", m.err)
+	}
+
+	m.inflight[key]++
+
+	return nil
+}
+
+func (m *Mux) release(from mino.Address, credit Credit) {
+	key := peerChannel{peer: from.String(), channel: credit.channel}
+
+	m.Lock()
+	defer m.Unlock()
+
+	if credit.amount >= m.inflight[key] {
+		delete(m.inflight, key)
+	} else {
+		m.inflight[key] -= credit.amount
+	}
+
+	m.cond.Broadcast()
+}
+
+func (m *Mux) listen(ctx context.Context, in mino.Receiver) {
+	for {
+		from, msg, err := in.Recv(ctx)
+		if err != nil {
+			m.close(err)
+			return
+		}
+
+		switch message := msg.(type) {
+		case Credit:
+			m.release(from, message)
+		case Frame:
+			m.Channel(message.channel).push(item{from: from, msg: message.msg})
+		default:
+			dela.Logger.Warn().
+				Stringer("from", from).
+				Msgf("ignoring unexpected message of type '%T'", msg)
+		}
+	}
+}
+
+func (m *Mux) close(err error) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.err = err
+
+	for _, ch := range m.channels {
+		ch.push(item{err: err})
+	}
+
+	m.cond.Broadcast()
+}
+
+// item is a message, or an error, waiting to be read from a channel.
+type item struct {
+	from mino.Address
+	msg  serde.Message
+	err  error
+}
+
+// Channel is a logical channel of a multiplexed stream.
+//
+// - implements mino.Sender
+// - implements mino.Receiver
+type Channel struct {
+	sync.Mutex
+
+	id     ChannelID
+	mux    *Mux
+	queue  []item
+	notify chan struct{}
+	// consumed is the number of frames read per peer since the last credit.
+	consumed map[string]uint32
+}
+
+// GetID returns the identifier of the channel.
+func (c *Channel) GetID() ChannelID {
+	return c.id
+}
+
+// Send implements mino.Sender. It sends the message on the channel to each
+// address, and blocks while the window of a recipient is full.
+func (c *Channel) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	errs := make(chan error, len(addrs))
+
+	frame := NewFrame(c.id, msg)
+
+	chans := []<-chan error{}
+	for _, addr := range addrs {
+		err := c.mux.acquire(c.id, addr)
+		if err != nil {
+			errs <- xerrors.Errorf("channel %d: %v", c.id, err)
+			continue
+		}
+
+		chans = append(chans, c.mux.out.Send(frame, addr))
+	}
+
+	go func() {
+		defer close(errs)
+
+		for _, ch := range chans {
+			for err := range ch {
+				errs <- err
+			}
+		}
+	}()
+
+	return errs
+}
+
+// Recv implements mino.Receiver. It returns the next message of the channel, or
+// the error that closed the stream.
+func (c *Channel) Recv(ctx context.Context) (mino.Address, serde.Message, error) {
+	for {
+		c.Lock()
+
+		if len(c.queue) > 0 {
+			next := c.queue[0]
+
+			// The error is kept so that the next calls return it too.
+			if next.err == nil {
+				c.queue = c.queue[1:]
+				c.consume(next.from)
+			}
+
+			c.Unlock()
+
+			return next.from, next.msg, next.err
+		}
+
+		c.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-c.notify:
+		}
+	}
+}
+
+// consume accounts a frame read from the peer and returns credits when half of
+// the window has been consumed.
+func (c *Channel) consume(from mino.Address) {
+	key := from.String()
+
+	c.consumed[key]++
+
+	threshold := c.mux.window / 2
+	if threshold == 0 {
+		threshold = 1
+	}
+
+	if c.consumed[key] < threshold {
+		return
+	}
+
+	credit := NewCredit(c.id, c.consumed[key])
+	delete(c.consumed, key)
+
+	go func() {
+		for err := range c.mux.out.Send(credit, from) {
+			dela.Logger.Warn().Err(err).
+				Stringer("to", from).
+				Uint32("channel", uint32(c.id)).
+				Msg("failed to send credit")
+		}
+	}()
+}
+
+func (c *Channel) push(it item) {
+	c.Lock()
+	c.queue = append(c.queue, it)
+	c.Unlock()
+
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
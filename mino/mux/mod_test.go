@@ -0,0 +1,195 @@
+package mux
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestCodec(t *testing.T) {
+	msg := codec{}.Wrap(fake.Message{})
+	require.Equal(t, NewFrame(0, fake.Message{}), msg)
+	require.Equal(t, fake.Message{}, codec{}.Unwrap(msg))
+	require.Equal(t, fake.Message{}, codec{}.Unwrap(fake.Message{}))
+}
+
+func TestRPC_Stream(t *testing.T) {
+	rpc := fake.NewStreamRPC(fake.NewReceiver(), fake.Sender{})
+
+	m, err := NewRPC(rpc, WithWindow(5)).Stream(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, uint32(5), m.window)
+
+	_, err = NewRPC(fake.NewBadRPC()).Stream(context.Background(), nil)
+	require.EqualError(t, err, fake.Err("stream failed"))
+}
+
+func TestHandler_Stream(t *testing.T) {
+	fn := func(m *Mux) error {
+		require.Equal(t, uint32(3), m.window)
+		return fake.GetError()
+	}
+
+	h := NewHandler(mino.UnsupportedHandler{}, fn, WithWindow(3))
+
+	err := h.Stream(fake.Sender{}, fake.NewReceiver())
+	require.Equal(t, fake.GetError(), err)
+}
+
+func TestMux_Channels(t *testing.T) {
+	a, b := makePair()
+
+	errs := a.mux.Channel(1).Send(fake.NewRandomMessage(1), b.addr)
+	require.Empty(t, drain(errs))
+
+	errs = a.mux.Channel(2).Send(fake.NewRandomMessage(1), b.addr)
+	require.Empty(t, drain(errs))
+
+	// The second channel is read first without waiting for the first one.
+	ch := b.mux.Channel(2)
+	require.Equal(t, ChannelID(2), ch.GetID())
+
+	from, msg, err := ch.Recv(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, a.addr, from)
+	require.Equal(t, a.out.sent[1].(Frame).msg, msg)
+
+	_, msg, err = b.mux.Channel(1).Recv(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, a.out.sent[0].(Frame).msg, msg)
+}
+
+func TestMux_FlowControl(t *testing.T) {
+	a, b := makePair(WithWindow(2))
+
+	ch := a.mux.Channel(1)
+
+	require.Empty(t, drain(ch.Send(fake.Message{}, b.addr)))
+	require.Empty(t, drain(ch.Send(fake.Message{}, b.addr)))
+
+	done := make(chan struct{})
+	go func() {
+		ch.Send(fake.Message{}, b.addr)
+		close(done)
+	}()
+
+	// Another channel is not blocked by the window of the first one.
+	require.Empty(t, drain(a.mux.Channel(2).Send(fake.Message{}, b.addr)))
+
+	select {
+	case <-done:
+		t.Fatal("send should be blocked")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	_, _, err := b.mux.Channel(1).Recv(context.Background())
+	require.NoError(t, err)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("send should be unblocked by the credit")
+	}
+}
+
+func TestMux_Closed(t *testing.T) {
+	in := fake.NewReceiver(fake.NewRecvMsg(fake.NewAddress(0), fake.Message{}))
+
+	m := Open(context.Background(), fake.Sender{}, in)
+
+	_, _, err := m.Channel(1).Recv(context.Background())
+	require.Equal(t, io.EOF, err)
+
+	_, _, err = m.Channel(1).Recv(context.Background())
+	require.Equal(t, io.EOF, err)
+
+	_, _, err = m.Channel(2).Recv(context.Background())
+	require.Equal(t, io.EOF, err)
+
+	errs := drain(m.Channel(1).Send(fake.Message{}, fake.NewAddress(0)))
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0], "channel 1: stream closed: EOF")
+
+	m = Open(context.Background(), fake.Sender{}, fake.NewBlockingReceiver())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err = m.Channel(1).Recv(ctx)
+	require.Equal(t, context.Canceled, err)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+// pipe is a sender that pushes the messages to the channel of the peer.
+type pipe struct {
+	sync.Mutex
+
+	from mino.Address
+	ch   chan fake.ReceiverMessage
+	sent []serde.Message
+}
+
+func (p *pipe) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	p.Lock()
+	p.sent = append(p.sent, msg)
+	p.Unlock()
+
+	p.ch <- fake.NewRecvMsg(p.from, msg)
+
+	errs := make(chan error)
+	close(errs)
+
+	return errs
+}
+
+type chanReceiver chan fake.ReceiverMessage
+
+func (r chanReceiver) Recv(ctx context.Context) (mino.Address, serde.Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case m := <-r:
+		return m.Address, m.Message, nil
+	}
+}
+
+type peer struct {
+	addr mino.Address
+	out  *pipe
+	mux  *Mux
+}
+
+// makePair returns two peers connected to each other.
+func makePair(opts ...Option) (peer, peer) {
+	chA := make(chan fake.ReceiverMessage, 10)
+	chB := make(chan fake.ReceiverMessage, 10)
+
+	a := peer{addr: fake.NewAddress(0)}
+	b := peer{addr: fake.NewAddress(1)}
+
+	a.out = &pipe{from: a.addr, ch: chB}
+	a.mux = Open(context.Background(), a.out, chanReceiver(chA), opts...)
+
+	b.out = &pipe{from: b.addr, ch: chA}
+	b.mux = Open(context.Background(), b.out, chanReceiver(chB), opts...)
+
+	return a, b
+}
+
+func drain(errs <-chan error) []error {
+	list := []error{}
+	for err := range errs {
+		list = append(list, err)
+	}
+
+	return list
+}
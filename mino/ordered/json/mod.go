@@ -0,0 +1,73 @@
+package json
+
+import (
+	"encoding/json"
+
+	"go.dedis.ch/dela/mino/ordered"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	ordered.RegisterMessageFormat(serde.FormatJSON, msgFormat{})
+}
+
+// MessageJSON is the JSON message of an ordered message.
+type MessageJSON struct {
+	Seq     uint64
+	Message json.RawMessage
+}
+
+// MsgFormat is the engine to encode and decode ordered messages in JSON format.
+//
+// - implements serde.FormatEngine
+type msgFormat struct{}
+
+// Encode implements serde.FormatEngine. It returns the serialized data of the
+// message if appropriate, otherwise it returns an error.
+func (f msgFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	m, ok := msg.(ordered.Message)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message '%T'", msg)
+	}
+
+	inner, err := m.GetMessage().Serialize(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to serialize message: %v", err)
+	}
+
+	mJSON := MessageJSON{
+		Seq:     m.GetSeq(),
+		Message: inner,
+	}
+
+	data, err := ctx.Marshal(mJSON)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the message if
+// appropriate, otherwise it returns an error.
+func (f msgFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := MessageJSON{}
+
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	fac := ctx.GetFactory(ordered.MsgKey{})
+	if fac == nil {
+		return nil, xerrors.New("invalid message factory '<nil>'")
+	}
+
+	inner, err := fac.Deserialize(ctx, m.Message)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to deserialize message: %v", err)
+	}
+
+	return ordered.NewMessage(m.Seq, inner), nil
+}
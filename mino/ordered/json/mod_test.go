@@ -0,0 +1,51 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino/ordered"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestMsgFormat_Encode(t *testing.T) {
+	format := msgFormat{}
+
+	ctx := fake.NewContext()
+
+	data, err := format.Encode(ctx, ordered.NewMessage(3, fake.Message{}))
+	require.NoError(t, err)
+	require.Equal(t, `{"Seq":3,"Message":{}}`, string(data))
+
+	_, err = format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message 'fake.Message'")
+
+	_, err = format.Encode(ctx, ordered.NewMessage(0, fake.NewBadPublicKey()))
+	require.EqualError(t, err, fake.Err("failed to serialize message"))
+
+	_, err = format.Encode(fake.NewBadContextWithDelay(1), ordered.NewMessage(0, fake.Message{}))
+	require.EqualError(t, err, fake.Err("failed to marshal"))
+}
+
+func TestMsgFormat_Decode(t *testing.T) {
+	format := msgFormat{}
+
+	ctx := fake.NewContext()
+	ctx = serde.WithFactory(ctx, ordered.MsgKey{}, fake.MessageFactory{})
+
+	msg, err := format.Decode(ctx, []byte(`{"Seq":3,"Message":{}}`))
+	require.NoError(t, err)
+	require.Equal(t, ordered.NewMessage(3, fake.Message{}), msg)
+
+	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("failed to unmarshal"))
+
+	badCtx := serde.WithFactory(ctx, ordered.MsgKey{}, nil)
+	_, err = format.Decode(badCtx, []byte(`{}`))
+	require.EqualError(t, err, "invalid message factory '<nil>'")
+
+	badCtx = serde.WithFactory(ctx, ordered.MsgKey{}, fake.NewBadMessageFactory())
+	_, err = format.Decode(badCtx, []byte(`{}`))
+	require.EqualError(t, err, fake.Err("failed to deserialize message"))
+}
@@ -0,0 +1,355 @@
+// Package ordered implements an opt-in ordered delivery for the streams of an
+// RPC.
+//
+// The messages of a stream can be routed through different paths, and
+// therefore arrive in a different order than they were sent. Protocols that
+// assume FIFO channels can wrap the RPC and its handler so that each message
+// is sent with a sequence number specific to the pair of sender and recipient.
+// The receiver keeps a reordering buffer per sender and only delivers a
+// message when all the previous ones have been delivered. A sender that leaves
+// a gap longer than the buffer has the missing messages skipped, so that it
+// never blocks the stream of the others.
+//
+// The RPC must be created with the factory of this package wrapping the
+// factory of the messages so that the sequence numbers can be decoded.
+package ordered
+
+import (
+	"context"
+	"sync"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/wrapper"
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
+	"golang.org/x/xerrors"
+)
+
+// DefaultBufferSize is the default maximum number of messages waiting in the
+// reordering buffer of a sender.
+const DefaultBufferSize = 1000
+
+var msgFormats = registry.NewSimpleRegistry()
+
+// RegisterMessageFormat registers the engine for the provided format.
+func RegisterMessageFormat(f serde.Format, e serde.FormatEngine) {
+	msgFormats.Register(f, e)
+}
+
+// Message is the wrapper of a message that is sent with its sequence number. A
+// sequence number of zero means the message is not ordered, which is the case
+// for the requests and the replies of a call.
+//
+// - implements serde.Message
+type Message struct {
+	seq uint64
+	msg serde.Message
+}
+
+// NewMessage returns a new message with the sequence number.
+func NewMessage(seq uint64, msg serde.Message) Message {
+	return Message{
+		seq: seq,
+		msg: msg,
+	}
+}
+
+// GetSeq returns the sequence number of the message.
+func (m Message) GetSeq() uint64 {
+	return m.seq
+}
+
+// GetMessage returns the wrapped message.
+func (m Message) GetMessage() serde.Message {
+	return m.msg
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// message.
+func (m Message) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, m)
+	if err != nil {
+		return nil, xerrors.Errorf("encoding failed: %v", err)
+	}
+
+	return data, nil
+}
+
+// MsgKey is the key of the factory of the wrapped messages.
+type MsgKey struct{}
+
+// Factory is the factory of the ordered messages.
+//
+// - implements serde.Factory
+type Factory struct {
+	msgFac serde.Factory
+}
+
+// NewFactory returns a factory of ordered messages that wraps the messages
+// deserialized by the given factory.
+func NewFactory(f serde.Factory) Factory {
+	return Factory{
+		msgFac: f,
+	}
+}
+
+// Deserialize implements serde.Factory. It populates the message if
+// appropriate, otherwise it returns an error.
+func (f Factory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	ctx = serde.WithFactory(ctx, MsgKey{}, f.msgFac)
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("decoding failed: %v", err)
+	}
+
+	return msg, nil
+}
+
+// Option is the type of option to configure the ordered streams.
+type Option func(*template)
+
+// WithBufferSize sets the maximum number of messages that can wait in the
+// reordering buffer of a single sender. When it is exceeded, the messages
+// missing from that sender are skipped.
+func WithBufferSize(size int) Option {
+	return func(tmpl *template) {
+		tmpl.bufferSize = size
+	}
+}
+
+type template struct {
+	bufferSize int
+}
+
+func newTemplate(opts []Option) template {
+	tmpl := template{
+		bufferSize: DefaultBufferSize,
+	}
+
+	for _, opt := range opts {
+		opt(&tmpl)
+	}
+
+	return tmpl
+}
+
+// NewRPC returns a new RPC that delivers the messages of the streams in the
+// order they were sent. The RPC to wrap must be created with the handler and
+// the factory of this package.
+func NewRPC(rpc mino.RPC, opts ...Option) wrapper.RPC {
+	return wrapper.NewRPC(rpc, codec{}, makeStream(opts))
+}
+
+// NewHandler returns a new handler so that the stream it handles delivers the
+// messages in order.
+func NewHandler(h mino.Handler, opts ...Option) wrapper.Handler {
+	return wrapper.NewHandler(h, codec{}, makeStream(opts))
+}
+
+func makeStream(opts []Option) wrapper.StreamFunc {
+	return func(ctx context.Context,
+		out mino.Sender, in mino.Receiver) (mino.Sender, mino.Receiver) {
+
+		return Wrap(out, in, opts...)
+	}
+}
+
+// codec wraps the requests and the replies of the calls without a sequence
+// number.
+//
+// - implements wrapper.Codec
+type codec struct{}
+
+// Wrap implements wrapper.Codec. It returns the message without a sequence
+// number.
+func (codec) Wrap(msg serde.Message) serde.Message {
+	return NewMessage(0, msg)
+}
+
+// Unwrap implements wrapper.Codec. It returns the wrapped message.
+func (codec) Unwrap(msg serde.Message) serde.Message {
+	m, ok := msg.(Message)
+	if ok {
+		return m.msg
+	}
+
+	return msg
+}
+
+// Wrap returns a sender that attaches the sequence numbers to the messages
+// and a receiver that delivers them in order.
+func Wrap(out mino.Sender, in mino.Receiver, opts ...Option) (mino.Sender, mino.Receiver) {
+	tmpl := newTemplate(opts)
+
+	s := &sender{
+		out:  out,
+		seqs: make(map[string]uint64),
+	}
+
+	r := &receiver{
+		in:         in,
+		bufferSize: tmpl.bufferSize,
+		next:       make(map[string]uint64),
+		pending:    make(map[string]map[uint64]serde.Message),
+	}
+
+	return s, r
+}
+
+// sender is a sender that sends the messages with a sequence number for each
+// recipient. As the sequence numbers differ, a message is sent individually to
+// each address.
+//
+// - implements mino.Sender
+type sender struct {
+	sync.Mutex
+
+	out  mino.Sender
+	seqs map[string]uint64
+}
+
+// Send implements mino.Sender. It sends the message to each address with the
+// next sequence number of the address.
+func (s *sender) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	errs := make(chan error, len(addrs))
+
+	s.Lock()
+
+	chans := make([]<-chan error, len(addrs))
+	for i, addr := range addrs {
+		s.seqs[addr.String()]++
+
+		chans[i] = s.out.Send(NewMessage(s.seqs[addr.String()], msg), addr)
+	}
+
+	s.Unlock()
+
+	go func() {
+		defer close(errs)
+
+		for _, ch := range chans {
+			for err := range ch {
+				errs <- err
+			}
+		}
+	}()
+
+	return errs
+}
+
+// envelope is a message waiting to be delivered.
+type envelope struct {
+	from mino.Address
+	msg  serde.Message
+}
+
+// receiver is a receiver that delivers the messages of each sender in the
+// order of the sequence numbers.
+//
+// - implements mino.Receiver
+type receiver struct {
+	sync.Mutex
+
+	in         mino.Receiver
+	bufferSize int
+	// next is the sequence number of the next message to deliver per sender.
+	next    map[string]uint64
+	pending map[string]map[uint64]serde.Message
+	ready   []envelope
+}
+
+// Recv implements mino.Receiver. It returns the next message of one of the
+// senders, waiting for the missing messages if necessary. A message without a
+// sequence number is delivered as is.
+func (r *receiver) Recv(ctx context.Context) (mino.Address, serde.Message, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	for {
+		if len(r.ready) > 0 {
+			env := r.ready[0]
+			r.ready = r.ready[1:]
+
+			return env.from, env.msg, nil
+		}
+
+		from, msg, err := r.in.Recv(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		m, ok := msg.(Message)
+		if !ok || m.seq == 0 {
+			return from, codec{}.Unwrap(msg), nil
+		}
+
+		r.push(from, m)
+	}
+}
+
+func (r *receiver) push(from mino.Address, m Message) {
+	key := from.String()
+
+	next, found := r.next[key]
+	if !found {
+		next = 1
+	}
+
+	if m.seq < next {
+		dela.Logger.Warn().
+			Stringer("from", from).
+			Uint64("seq", m.seq).
+			Msg("duplicate message ignored")
+
+		return
+	}
+
+	pending := r.pending[key]
+	if pending == nil {
+		pending = make(map[uint64]serde.Message)
+		r.pending[key] = pending
+	}
+
+	if m.seq > next && len(pending) >= r.bufferSize {
+		// The sender left a gap that is not filled in time. The missing
+		// messages are considered lost, so that the buffer stays bounded
+		// without failing the stream of the other senders. They are dropped
+		// as duplicates if they arrive later.
+		lowest := m.seq
+		for seq := range pending {
+			if seq < lowest {
+				lowest = seq
+			}
+		}
+
+		dela.Logger.Warn().
+			Stringer("from", from).
+			Uint64("from_seq", next).
+			Uint64("to_seq", lowest).
+			Msg("reordering buffer is full: skipping missing messages")
+
+		next = lowest
+	}
+
+	pending[m.seq] = m.msg
+
+	for {
+		msg, found := pending[next]
+		if !found {
+			break
+		}
+
+		delete(pending, next)
+		next++
+
+		r.ready = append(r.ready, envelope{from: from, msg: msg})
+	}
+
+	r.next[key] = next
+}
@@ -0,0 +1,213 @@
+package ordered
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+)
+
+func init() {
+	RegisterMessageFormat(fake.GoodFormat, fake.Format{Msg: NewMessage(1, fake.Message{})})
+	RegisterMessageFormat(fake.BadFormat, fake.NewBadFormat())
+}
+
+func TestMessage_Getters(t *testing.T) {
+	msg := NewMessage(2, fake.Message{})
+
+	require.Equal(t, uint64(2), msg.GetSeq())
+	require.Equal(t, fake.Message{}, msg.GetMessage())
+}
+
+func TestMessage_Serialize(t *testing.T) {
+	msg := NewMessage(1, fake.Message{})
+
+	data, err := msg.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = msg.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("encoding failed"))
+}
+
+func TestFactory_Deserialize(t *testing.T) {
+	fac := NewFactory(fake.MessageFactory{})
+
+	msg, err := fac.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.Equal(t, NewMessage(1, fake.Message{}), msg)
+
+	_, err = fac.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("decoding failed"))
+}
+
+func TestCodec(t *testing.T) {
+	msg := codec{}.Wrap(fake.Message{})
+	require.Equal(t, NewMessage(0, fake.Message{}), msg)
+	require.Equal(t, fake.Message{}, codec{}.Unwrap(msg))
+	require.Equal(t, fake.Message{}, codec{}.Unwrap(fake.Message{}))
+}
+
+func TestRPC_Stream(t *testing.T) {
+	rpc := fake.NewStreamRPC(fake.NewReceiver(), fake.Sender{})
+
+	s, r, err := NewRPC(rpc, WithBufferSize(5)).Stream(context.Background(), nil)
+	require.NoError(t, err)
+	require.IsType(t, &sender{}, s)
+	require.Equal(t, 5, r.(*receiver).bufferSize)
+
+	_, _, err = NewRPC(fake.NewBadRPC()).Stream(context.Background(), nil)
+	require.EqualError(t, err, fake.Err("stream failed"))
+}
+
+func TestHandler_Stream(t *testing.T) {
+	h := NewHandler(fakeHandler{}, WithBufferSize(5))
+
+	err := h.Stream(fake.Sender{}, fake.NewReceiver())
+	require.NoError(t, err)
+}
+
+func TestSender_Send(t *testing.T) {
+	out := &fakeSender{}
+	s, _ := Wrap(out, nil)
+
+	errs := s.Send(fake.Message{}, fake.NewAddress(0), fake.NewAddress(1))
+	require.Empty(t, drain(errs))
+
+	errs = s.Send(fake.Message{}, fake.NewAddress(1))
+	require.Empty(t, drain(errs))
+
+	require.Equal(t, []Message{
+		NewMessage(1, fake.Message{}),
+		NewMessage(1, fake.Message{}),
+		NewMessage(2, fake.Message{}),
+	}, out.msgs)
+
+	s, _ = Wrap(&fakeSender{err: fake.GetError()}, nil)
+	errs = s.Send(fake.Message{}, fake.NewAddress(0), fake.NewAddress(1))
+	require.Len(t, drain(errs), 2)
+}
+
+func TestReceiver_Recv(t *testing.T) {
+	a := fake.NewAddress(0)
+	b := fake.NewAddress(1)
+
+	in := fake.NewReceiver(
+		fake.NewRecvMsg(a, NewMessage(2, fake.NewRandomMessage(1))),
+		fake.NewRecvMsg(b, NewMessage(2, fake.Message{})),
+		fake.NewRecvMsg(a, NewMessage(1, fake.NewRandomMessage(1))),
+		fake.NewRecvMsg(b, NewMessage(0, fake.Message{})),
+		fake.NewRecvMsg(a, NewMessage(1, fake.Message{})),
+		fake.NewRecvMsg(a, NewMessage(3, fake.NewRandomMessage(1))),
+		fake.NewRecvMsg(b, fake.Message{}),
+	)
+
+	_, r := Wrap(nil, in)
+
+	expected := []fake.ReceiverMessage{
+		fake.NewRecvMsg(a, in.Msgs[2].Message.(Message).msg),
+		fake.NewRecvMsg(a, in.Msgs[0].Message.(Message).msg),
+		fake.NewRecvMsg(b, fake.Message{}),
+		fake.NewRecvMsg(a, in.Msgs[5].Message.(Message).msg),
+		fake.NewRecvMsg(b, fake.Message{}),
+	}
+
+	for _, e := range expected {
+		from, msg, err := r.Recv(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, e.Address, from)
+		require.Equal(t, e.Message, msg)
+	}
+
+	_, _, err := r.Recv(context.Background())
+	require.Equal(t, io.EOF, err)
+}
+
+func TestReceiver_BufferFull_Recv(t *testing.T) {
+	a := fake.NewAddress(0)
+	b := fake.NewAddress(1)
+
+	in := fake.NewReceiver(
+		fake.NewRecvMsg(a, NewMessage(2, fake.NewRandomMessage(1))),
+		fake.NewRecvMsg(a, NewMessage(3, fake.NewRandomMessage(1))),
+		fake.NewRecvMsg(b, NewMessage(1, fake.Message{})),
+		// The message skipped is dropped when it arrives late.
+		fake.NewRecvMsg(a, NewMessage(1, fake.Message{})),
+		fake.NewRecvMsg(a, NewMessage(4, fake.Message{})),
+	)
+
+	_, r := Wrap(nil, in, WithBufferSize(1))
+
+	expected := []fake.ReceiverMessage{
+		fake.NewRecvMsg(a, in.Msgs[0].Message.(Message).msg),
+		fake.NewRecvMsg(a, in.Msgs[1].Message.(Message).msg),
+		fake.NewRecvMsg(b, fake.Message{}),
+		fake.NewRecvMsg(a, fake.Message{}),
+	}
+
+	for _, e := range expected {
+		from, msg, err := r.Recv(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, e.Address, from)
+		require.Equal(t, e.Message, msg)
+	}
+
+	_, _, err := r.Recv(context.Background())
+	require.Equal(t, io.EOF, err)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeHandler struct {
+	mino.UnsupportedHandler
+}
+
+func (h fakeHandler) Stream(out mino.Sender, in mino.Receiver) error {
+	_, ok := out.(*sender)
+	if !ok {
+		return fake.GetError()
+	}
+
+	if in.(*receiver).bufferSize != 5 {
+		return fake.GetError()
+	}
+
+	return nil
+}
+
+type fakeSender struct {
+	sync.Mutex
+
+	msgs []Message
+	err  error
+}
+
+func (s *fakeSender) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	s.Lock()
+	s.msgs = append(s.msgs, msg.(Message))
+	s.Unlock()
+
+	errs := make(chan error, 1)
+	if s.err != nil {
+		errs <- s.err
+	}
+
+	close(errs)
+
+	return errs
+}
+
+func drain(errs <-chan error) []error {
+	list := []error{}
+	for err := range errs {
+		list = append(list, err)
+	}
+
+	return list
+}
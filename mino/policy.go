@@ -0,0 +1,100 @@
+package mino
+
+import "time"
+
+// Policy defines how an overlay contacts the players of an RPC and when it
+// gives up on a player. The zero value makes a single attempt bounded only by
+// the context of the call.
+type Policy struct {
+	// Timeout bounds each attempt to contact a player. Zero means that the
+	// attempts are only bounded by the context.
+	Timeout time.Duration
+
+	// MaxRetries is the number of attempts made after the first one when a
+	// player is unreachable or doesn't answer before the timeout. The errors
+	// returned by the handler of the player are never retried.
+	MaxRetries int
+
+	// Backoff is the delay before the first retry, which doubles after each
+	// new failure.
+	Backoff time.Duration
+
+	// MaxBackoff caps the delay between two attempts. Zero means no cap.
+	MaxBackoff time.Duration
+}
+
+// Delay returns the time to wait before the retry following the given failed
+// attempt, starting at zero.
+func (p Policy) Delay(attempt int) time.Duration {
+	delay := p.Backoff
+
+	for i := 0; i < attempt && delay > 0; i++ {
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			break
+		}
+
+		if delay > time.Duration(1<<62) {
+			// The next step would overflow.
+			break
+		}
+
+		delay *= 2
+	}
+
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+
+	return delay
+}
+
+// ProtocolPolicy is the policy shared by the consensus and the distributed key
+// generation. An unreachable player is retried a few times with a short
+// backoff, while the protocol still bounds the whole call with its context.
+var ProtocolPolicy = Policy{
+	MaxRetries: 3,
+	Backoff:    100 * time.Millisecond,
+	MaxBackoff: time.Second,
+}
+
+// PolicyHandler is a handler that defines the failure policy of its RPC. The
+// overlay reads the policy when the RPC is created and applies it every time a
+// player is contacted.
+//
+// - implements mino.Handler
+type PolicyHandler struct {
+	Handler
+
+	policy Policy
+}
+
+// WithPolicy returns a handler whose RPC contacts the players according to the
+// policy.
+func WithPolicy(h Handler, p Policy) PolicyHandler {
+	return PolicyHandler{
+		Handler: h,
+		policy:  p,
+	}
+}
+
+// GetPolicy returns the failure policy of the handler.
+func (h PolicyHandler) GetPolicy() Policy {
+	return h.policy
+}
+
+// PolicyOf returns the failure policy of the handler, or the zero policy if it
+// doesn't define one.
+func PolicyOf(h Handler) Policy {
+	switch handler := h.(type) {
+	case PolicyHandler:
+		return handler.policy
+	case ClassifiedHandler:
+		return PolicyOf(handler.Handler)
+	case CompressedHandler:
+		return PolicyOf(handler.Handler)
+	case ScopedHandler:
+		return PolicyOf(handler.Handler)
+	default:
+		return Policy{}
+	}
+}
@@ -0,0 +1,55 @@
+package mino
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Delay(t *testing.T) {
+	p := Policy{Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+
+	require.Equal(t, 10*time.Millisecond, p.Delay(0))
+	require.Equal(t, 20*time.Millisecond, p.Delay(1))
+	require.Equal(t, 40*time.Millisecond, p.Delay(2))
+	require.Equal(t, 50*time.Millisecond, p.Delay(3))
+	require.Equal(t, 50*time.Millisecond, p.Delay(100))
+
+	p.MaxBackoff = 0
+	require.Equal(t, 80*time.Millisecond, p.Delay(3))
+	require.Greater(t, int64(p.Delay(math.MaxInt32)), int64(0))
+
+	require.Equal(t, time.Duration(0), Policy{}.Delay(5))
+}
+
+func TestPolicyHandler_GetPolicy(t *testing.T) {
+	p := Policy{Timeout: time.Second, MaxRetries: 3}
+
+	h := WithPolicy(UnsupportedHandler{}, p)
+
+	require.Equal(t, p, h.GetPolicy())
+}
+
+func TestPolicyOf(t *testing.T) {
+	p := Policy{Timeout: time.Second}
+
+	h := WithPolicy(UnsupportedHandler{}, p)
+
+	require.Equal(t, p, PolicyOf(h))
+	require.Equal(t, p, PolicyOf(NewClassifiedHandler(h, ClassBulk)))
+	require.Equal(t, p, PolicyOf(WithCompression(h, CompressionGzip)))
+	require.Equal(t, p, PolicyOf(NewScopedHandler(h, nil)))
+	require.Equal(t, Policy{}, PolicyOf(UnsupportedHandler{}))
+
+	// The other properties are found through the policy handler.
+	scoped := NewScopedHandler(NewClassifiedHandler(UnsupportedHandler{}, ClassBulk), nil)
+	compressed := WithCompression(scoped, CompressionGzip)
+
+	require.Equal(t, ClassBulk, ClassOf(WithPolicy(compressed, p)))
+	require.Equal(t, CompressionGzip, CompressionOf(WithPolicy(compressed, p)))
+
+	_, ok := scopeOf(WithPolicy(compressed, p))
+	require.True(t, ok)
+}
@@ -0,0 +1,141 @@
+// Package prometheus implements a collector that exposes the traffic metrics of
+// a mino instance to Prometheus.
+//
+// The collector is an HTTP handler that writes the counters in the text format
+// of the exposition, which Prometheus can scrape directly. Each counter is
+// labelled with the URI of the RPC, so that the traffic can be monitored per
+// protocol. The traffic that doesn't belong to an RPC has an empty URI.
+package prometheus
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/mino"
+)
+
+// DefaultNamespace is the prefix of the names of the metrics.
+const DefaultNamespace = "dela_mino"
+
+// contentType is the content type of the text format of the exposition.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// family is a family of counters with the same name.
+type family struct {
+	name string
+	help string
+	get  func(mino.Traffic) uint64
+}
+
+var families = []family{
+	{
+		name: "calls_total",
+		help: "Number of calls sent.",
+		get:  func(t mino.Traffic) uint64 { return t.Calls },
+	},
+	{
+		name: "streams_total",
+		help: "Number of streams opened.",
+		get:  func(t mino.Traffic) uint64 { return t.Streams },
+	},
+	{
+		name: "messages_received_total",
+		help: "Number of messages received.",
+		get:  func(t mino.Traffic) uint64 { return t.MessagesIn },
+	},
+	{
+		name: "messages_sent_total",
+		help: "Number of messages sent.",
+		get:  func(t mino.Traffic) uint64 { return t.MessagesOut },
+	},
+	{
+		name: "received_bytes_total",
+		help: "Number of bytes received.",
+		get:  func(t mino.Traffic) uint64 { return t.BytesIn },
+	},
+	{
+		name: "sent_bytes_total",
+		help: "Number of bytes sent.",
+		get:  func(t mino.Traffic) uint64 { return t.BytesOut },
+	},
+}
+
+// Option is the type of option to configure the collector.
+type Option func(*Collector)
+
+// WithNamespace sets the prefix of the names of the metrics.
+func WithNamespace(ns string) Option {
+	return func(c *Collector) {
+		c.namespace = ns
+	}
+}
+
+// Collector exposes the counters of a mino instance.
+//
+// - implements http.Handler
+type Collector struct {
+	metrics   *mino.Metrics
+	namespace string
+}
+
+// NewCollector returns a new collector for the metrics.
+func NewCollector(metrics *mino.Metrics, opts ...Option) Collector {
+	c := Collector{
+		metrics:   metrics,
+		namespace: DefaultNamespace,
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
+}
+
+// Collect writes the current value of the counters to the writer in the text
+// format of the exposition.
+func (c Collector) Collect(w io.Writer) error {
+	uris := c.metrics.GetURIs()
+
+	traffic := make([]mino.Traffic, len(uris))
+	for i, uri := range uris {
+		traffic[i] = c.metrics.GetTraffic(uri)
+	}
+
+	for _, f := range families {
+		name := f.name
+		if c.namespace != "" {
+			name = c.namespace + "_" + name
+		}
+
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, f.help, name)
+		if err != nil {
+			return err
+		}
+
+		for i, uri := range uris {
+			_, err = fmt.Fprintf(w, "%s{uri=\"%s\"} %d\n",
+				name, labelEscaper.Replace(uri), f.get(traffic[i]))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP implements http.Handler. It replies with the counters.
+func (c Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+
+	err := c.Collect(w)
+	if err != nil {
+		dela.Logger.Warn().Err(err).Msg("failed to write the metrics")
+	}
+}
@@ -0,0 +1,65 @@
+package prometheus
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+)
+
+func TestCollector_Collect(t *testing.T) {
+	metrics := mino.NewMetrics()
+	metrics.AddCall("/a")
+	metrics.AddSent("/a", 10)
+	metrics.AddReceived("x\"y", 20)
+
+	c := NewCollector(metrics, WithNamespace("test"))
+
+	out := new(bytes.Buffer)
+	err := c.Collect(out)
+	require.NoError(t, err)
+	require.Contains(t, out.String(),
+		"# HELP test_calls_total Number of calls sent.\n# TYPE test_calls_total counter\n")
+	require.Contains(t, out.String(), "test_calls_total{uri=\"/a\"} 1\n")
+	require.Contains(t, out.String(), "test_calls_total{uri=\"x\\\"y\"} 0\n")
+	require.Contains(t, out.String(), "test_sent_bytes_total{uri=\"/a\"} 10\n")
+	require.Contains(t, out.String(), "test_received_bytes_total{uri=\"x\\\"y\"} 20\n")
+	require.Contains(t, out.String(), "test_messages_received_total{uri=\"x\\\"y\"} 1\n")
+
+	c = NewCollector(metrics, WithNamespace(""))
+
+	out.Reset()
+	err = c.Collect(out)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "\nstreams_total{uri=\"/a\"} 0\n")
+
+	err = c.Collect(badWriter{})
+	require.EqualError(t, err, fake.GetError().Error())
+}
+
+func TestCollector_ServeHTTP(t *testing.T) {
+	metrics := mino.NewMetrics()
+	metrics.AddStream("/a")
+
+	c := NewCollector(metrics)
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, contentType, rec.Header().Get("Content-Type"))
+	require.Contains(t, rec.Body.String(), "dela_mino_streams_total{uri=\"/a\"} 1\n")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type badWriter struct{}
+
+func (badWriter) Write([]byte) (int, error) {
+	return 0, fake.GetError()
+}
@@ -31,7 +31,7 @@ func (m minimal) SetCommands(builder node.Builder) {
 		Usage:    "the address of the http client",
 		Value:    defaultAddr,
 	})
-	sub.SetAction(builder.MakeAction(startAction{}))
+	sub.SetAction(builder.MakeAction(node.Destructive(startAction{})))
 }
 
 // OnStart implements node.Initializer. It creates, starts, and registers a
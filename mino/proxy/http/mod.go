@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
@@ -17,6 +18,9 @@ type key int
 
 const (
 	requestIDKey key = 0
+
+	// RoutesPath is the path of the endpoint that lists the registered routes.
+	RoutesPath = "/routes"
 )
 
 var (
@@ -48,7 +52,10 @@ func NewHTTP(listenAddr string) proxy.Proxy {
 		return fmt.Sprintf("%d", time.Now().UnixNano())
 	}
 
-	mux := http.NewServeMux()
+	mux := proxy.NewMux()
+
+	// The path is free as the router is empty.
+	mux.RegisterHandler(RoutesPath, routesHandler(mux))
 
 	return &HTTP{
 		mux: mux,
@@ -66,7 +73,7 @@ func NewHTTP(listenAddr string) proxy.Proxy {
 //
 // - implements proxy.Proxy
 type HTTP struct {
-	mux        *http.ServeMux
+	mux        *proxy.Mux
 	server     *http.Server
 	logger     zerolog.Logger
 	listenAddr string
@@ -137,11 +144,40 @@ func (h HTTP) GetAddr() net.Addr {
 	return h.ln.Addr()
 }
 
-// RegisterHandler implements proxy.Proxy
+// RegisterHandler implements proxy.Router. It registers the handler at the
+// root of the proxy.
 func (h HTTP) RegisterHandler(path string, handler func(http.ResponseWriter,
-	*http.Request)) {
+	*http.Request)) error {
+
+	return h.mux.RegisterHandler(path, handler)
+}
+
+// Namespace implements proxy.Router.
+func (h HTTP) Namespace(prefix string, middlewares ...proxy.Middleware) (proxy.Router, error) {
+	return h.mux.Namespace(prefix, middlewares...)
+}
+
+// GetRoutes implements proxy.Proxy.
+func (h HTTP) GetRoutes() []proxy.Route {
+	return h.mux.GetRoutes()
+}
+
+// routesHandler returns a handler that writes the registered routes in JSON.
+func routesHandler(mux *proxy.Mux) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "only GET is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
 
-	h.mux.HandleFunc(path, handler)
+		err := json.NewEncoder(w).Encode(mux.GetRoutes())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to encode routes: %v", err),
+				http.StatusInternalServerError)
+		}
+	}
 }
 
 // logging is a utility function that logs the http server events
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/mino/proxy"
+	"golang.org/x/xerrors"
 )
 
 func TestInit(t *testing.T) {
@@ -33,7 +36,8 @@ func TestHTTP_Listen(t *testing.T) {
 
 	defer proxy.Stop()
 
-	proxy.RegisterHandler("/fake", fakeHandler)
+	err := proxy.RegisterHandler("/fake", fakeHandler)
+	require.NoError(t, err)
 
 	res, err := http.Get("http://127.0.0.1:2010/fake")
 	require.NoError(t, err)
@@ -97,6 +101,41 @@ func TestGetAddr_Nil(t *testing.T) {
 	require.Nil(t, proxy.GetAddr())
 }
 
+func TestHTTP_Routes(t *testing.T) {
+	px := NewHTTP("127.0.0.1:2010")
+
+	ns, err := px.Namespace("/pool")
+	require.NoError(t, err)
+	require.NoError(t, ns.RegisterHandler("/add", fakeHandler))
+
+	err = px.RegisterHandler("/pool/add", fakeHandler)
+	require.True(t, xerrors.Is(err, proxy.ErrConflict))
+
+	err = px.RegisterHandler(RoutesPath, fakeHandler)
+	require.True(t, xerrors.Is(err, proxy.ErrConflict))
+
+	require.Equal(t, []proxy.Route{
+		{Path: "/pool/add", Namespace: "/pool"},
+		{Path: "/routes"},
+	}, px.GetRoutes())
+
+	handler := px.(*HTTP).server.Handler
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RoutesPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.JSONEq(t, `[{"path":"/pool/add","namespace":"/pool"},`+
+		`{"path":"/routes","namespace":""}]`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, RoutesPath, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
 func fakeHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("hello"))
 }
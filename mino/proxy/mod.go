@@ -8,6 +8,8 @@ import (
 // Proxy defines the primitives to implement an http client that handles
 // client side requests
 type Proxy interface {
+	Router
+
 	// Listen starts the proxy server. This call is assumed to be blocking
 	Listen()
 
@@ -20,6 +22,18 @@ type Proxy interface {
 	// connection hasn't been created.
 	GetAddr() net.Addr
 
-	// RegisterHandler registers a new handler
-	RegisterHandler(path string, handler func(http.ResponseWriter, *http.Request))
+	// GetRoutes returns the routes registered on the proxy.
+	GetRoutes() []Route
+}
+
+// Router defines the primitives to register the handlers of a module.
+type Router interface {
+	// RegisterHandler registers a new handler. It returns an error if the path
+	// is already registered or belongs to another namespace.
+	RegisterHandler(path string, handler func(http.ResponseWriter, *http.Request)) error
+
+	// Namespace returns a router that registers the handlers under the prefix
+	// and wraps them with the middlewares. It returns an error if the prefix
+	// overlaps with another namespace or a registered path.
+	Namespace(prefix string, middlewares ...Middleware) (Router, error)
 }
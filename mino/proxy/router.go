@@ -0,0 +1,205 @@
+// This file contains the implementation of a router that namespaces the
+// handlers of the modules.
+//
+// A module registers its handlers under a prefix, like /pool, so that the
+// paths of the modules don't collide. A registration that would shadow or
+// replace the route of another module is refused instead of silently routing
+// the requests to one of them.
+
+package proxy
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrConflict is the error returned when a route or a namespace overlaps with
+// another one.
+var ErrConflict = xerrors.New("conflict")
+
+// Middleware is a function that wraps the handlers of a namespace.
+type Middleware func(http.Handler) http.Handler
+
+// Route is the description of a registered handler.
+type Route struct {
+	// Path is the full path of the handler.
+	Path string `json:"path"`
+
+	// Namespace is the prefix of the namespace of the handler, or empty if it
+	// is registered at the root.
+	Namespace string `json:"namespace"`
+}
+
+// Mux is a router that dispatches the requests to the handlers of the
+// namespaces. It detects the conflicts when a handler is registered, so that
+// it never panics like the standard multiplexer.
+//
+// - implements proxy.Router
+// - implements http.Handler
+type Mux struct {
+	sync.Mutex
+
+	mux        *http.ServeMux
+	root       *namespace
+	namespaces map[string]*namespace
+	routes     map[string]Route
+}
+
+// NewMux creates a new empty router.
+func NewMux() *Mux {
+	m := &Mux{
+		mux:        http.NewServeMux(),
+		namespaces: make(map[string]*namespace),
+		routes:     make(map[string]Route),
+	}
+
+	m.root = &namespace{mux: m}
+
+	return m
+}
+
+// RegisterHandler implements proxy.Router. It registers the handler at the
+// root of the router.
+func (m *Mux) RegisterHandler(path string, handler func(http.ResponseWriter, *http.Request)) error {
+	return m.root.RegisterHandler(path, handler)
+}
+
+// Namespace implements proxy.Router. It creates a namespace at the root of the
+// router.
+func (m *Mux) Namespace(prefix string, middlewares ...Middleware) (Router, error) {
+	return m.root.Namespace(prefix, middlewares...)
+}
+
+// GetRoutes returns the registered routes sorted by path.
+func (m *Mux) GetRoutes() []Route {
+	m.Lock()
+	defer m.Unlock()
+
+	routes := make([]Route, 0, len(m.routes))
+	for _, route := range m.routes {
+		routes = append(routes, route)
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Path < routes[j].Path
+	})
+
+	return routes
+}
+
+// ServeHTTP implements http.Handler. It dispatches the request to the handler
+// with the longest matching path.
+func (m *Mux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mux.ServeHTTP(w, r)
+}
+
+// namespace is a group of handlers under a common prefix and wrapped by the
+// same middlewares.
+//
+// - implements proxy.Router
+type namespace struct {
+	mux         *Mux
+	parent      *namespace
+	prefix      string
+	middlewares []Middleware
+}
+
+// RegisterHandler implements proxy.Router. It registers the handler at the
+// path relative to the prefix of the namespace.
+func (ns *namespace) RegisterHandler(path string, handler func(http.ResponseWriter, *http.Request)) error {
+	if handler == nil {
+		return xerrors.Errorf("missing handler for '%s'", path)
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		return xerrors.Errorf("invalid path '%s'", path)
+	}
+
+	full := ns.prefix + path
+
+	ns.mux.Lock()
+	defer ns.mux.Unlock()
+
+	_, found := ns.mux.routes[full]
+	if found {
+		return xerrors.Errorf("route '%s' already registered: %w", full, ErrConflict)
+	}
+
+	for prefix := range ns.mux.namespaces {
+		if isUnder(full, prefix) && !ns.inherits(prefix) {
+			return xerrors.Errorf("route '%s' is in namespace '%s': %w", full, prefix, ErrConflict)
+		}
+	}
+
+	var h http.Handler = http.HandlerFunc(handler)
+	for i := len(ns.middlewares) - 1; i >= 0; i-- {
+		h = ns.middlewares[i](h)
+	}
+
+	ns.mux.mux.Handle(full, h)
+	ns.mux.routes[full] = Route{Path: full, Namespace: ns.prefix}
+
+	return nil
+}
+
+// Namespace implements proxy.Router. It creates a namespace nested in this one
+// whose handlers are wrapped by the middlewares of this namespace first, then
+// by the given ones.
+func (ns *namespace) Namespace(prefix string, middlewares ...Middleware) (Router, error) {
+	if !strings.HasPrefix(prefix, "/") || strings.HasSuffix(prefix, "/") {
+		return nil, xerrors.Errorf("invalid prefix '%s'", prefix)
+	}
+
+	full := ns.prefix + prefix
+
+	ns.mux.Lock()
+	defer ns.mux.Unlock()
+
+	for other := range ns.mux.namespaces {
+		if ns.inherits(other) {
+			continue
+		}
+
+		if isUnder(full, other) || isUnder(other, full) {
+			return nil, xerrors.Errorf("namespace '%s' overlaps '%s': %w", full, other, ErrConflict)
+		}
+	}
+
+	for path := range ns.mux.routes {
+		if isUnder(path, full) {
+			return nil, xerrors.Errorf("namespace '%s' shadows route '%s': %w", full, path, ErrConflict)
+		}
+	}
+
+	child := &namespace{
+		mux:         ns.mux,
+		parent:      ns,
+		prefix:      full,
+		middlewares: append(append([]Middleware{}, ns.middlewares...), middlewares...),
+	}
+
+	ns.mux.namespaces[full] = child
+
+	return child, nil
+}
+
+// inherits returns true if the prefix is the one of the namespace or one of
+// its parents.
+func (ns *namespace) inherits(prefix string) bool {
+	for curr := ns; curr != nil; curr = curr.parent {
+		if curr.prefix == prefix {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isUnder returns true if the path is the prefix or one of its sub-paths.
+func isUnder(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
@@ -0,0 +1,123 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestMux_RegisterHandler(t *testing.T) {
+	mux := NewMux()
+
+	require.NoError(t, mux.RegisterHandler("/a", makeHandler("a")))
+	require.NoError(t, mux.RegisterHandler("/b/", makeHandler("b")))
+
+	require.Equal(t, "a", serve(mux, "/a"))
+	require.Equal(t, "b", serve(mux, "/b/c"))
+
+	err := mux.RegisterHandler("/a", makeHandler("c"))
+	require.EqualError(t, err, "route '/a' already registered: conflict")
+	require.True(t, xerrors.Is(err, ErrConflict))
+
+	err = mux.RegisterHandler("a", makeHandler("c"))
+	require.EqualError(t, err, "invalid path 'a'")
+
+	err = mux.RegisterHandler("/c", nil)
+	require.EqualError(t, err, "missing handler for '/c'")
+
+	require.Equal(t, "a", serve(mux, "/a"))
+}
+
+func TestMux_Namespace(t *testing.T) {
+	mux := NewMux()
+
+	pool, err := mux.Namespace("/pool", makeMiddleware("1"), makeMiddleware("2"))
+	require.NoError(t, err)
+
+	require.NoError(t, pool.RegisterHandler("/add", makeHandler("add")))
+	require.Equal(t, "12add", serve(mux, "/pool/add"))
+
+	// The handlers of a nested namespace are wrapped by the middlewares of its
+	// parents first.
+	admin, err := pool.Namespace("/admin", makeMiddleware("3"))
+	require.NoError(t, err)
+
+	require.NoError(t, admin.RegisterHandler("/cancel", makeHandler("cancel")))
+	require.Equal(t, "123cancel", serve(mux, "/pool/admin/cancel"))
+
+	// The middlewares of a namespace don't leak to the parent.
+	require.NoError(t, pool.RegisterHandler("/list", makeHandler("list")))
+	require.Equal(t, "12list", serve(mux, "/pool/list"))
+
+	require.Equal(t, []Route{
+		{Path: "/pool/add", Namespace: "/pool"},
+		{Path: "/pool/admin/cancel", Namespace: "/pool/admin"},
+		{Path: "/pool/list", Namespace: "/pool"},
+	}, mux.GetRoutes())
+}
+
+func TestMux_Namespace_Conflicts(t *testing.T) {
+	mux := NewMux()
+
+	require.NoError(t, mux.RegisterHandler("/metrics", makeHandler("metrics")))
+
+	pool, err := mux.Namespace("/pool")
+	require.NoError(t, err)
+
+	admin, err := pool.Namespace("/admin")
+	require.NoError(t, err)
+
+	_, err = mux.Namespace("/pool")
+	require.EqualError(t, err, "namespace '/pool' overlaps '/pool': conflict")
+	require.True(t, xerrors.Is(err, ErrConflict))
+
+	_, err = mux.Namespace("/pool/other")
+	require.EqualError(t, err, "namespace '/pool/other' overlaps '/pool': conflict")
+
+	_, err = admin.Namespace("/pool")
+	require.NoError(t, err)
+
+	_, err = mux.Namespace("/metrics")
+	require.EqualError(t, err, "namespace '/metrics' shadows route '/metrics': conflict")
+
+	err = mux.RegisterHandler("/pool/add", makeHandler("add"))
+	require.EqualError(t, err, "route '/pool/add' is in namespace '/pool': conflict")
+
+	err = pool.RegisterHandler("/admin/add", makeHandler("add"))
+	require.EqualError(t, err,
+		"route '/pool/admin/add' is in namespace '/pool/admin': conflict")
+
+	_, err = mux.Namespace("pool")
+	require.EqualError(t, err, "invalid prefix 'pool'")
+
+	_, err = mux.Namespace("/pool/")
+	require.EqualError(t, err, "invalid prefix '/pool/'")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeHandler(name string) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(name))
+	}
+}
+
+func makeMiddleware(name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(name))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func serve(h http.Handler, path string) string {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return rec.Body.String()
+}
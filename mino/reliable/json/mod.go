@@ -0,0 +1,100 @@
+package json
+
+import (
+	"encoding/json"
+
+	"go.dedis.ch/dela/mino/reliable"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	reliable.RegisterMessageFormat(serde.FormatJSON, msgFormat{})
+}
+
+// PacketJSON is the JSON message of a packet.
+type PacketJSON struct {
+	ID      uint64
+	Message json.RawMessage
+}
+
+// AckJSON is the JSON message of an acknowledgment.
+type AckJSON struct {
+	ID uint64
+}
+
+// MessageJSON is a JSON container to differentiate the messages of a reliable
+// stream.
+type MessageJSON struct {
+	Packet *PacketJSON `json:",omitempty"`
+	Ack    *AckJSON    `json:",omitempty"`
+}
+
+// MsgFormat is the engine to encode and decode the packets and the
+// acknowledgments in JSON format.
+//
+// - implements serde.FormatEngine
+type msgFormat struct{}
+
+// Encode implements serde.FormatEngine. It returns the serialized data of the
+// message if appropriate, otherwise it returns an error.
+func (f msgFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	m := MessageJSON{}
+
+	switch message := msg.(type) {
+	case reliable.Packet:
+		inner, err := message.GetMessage().Serialize(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to serialize message: %v", err)
+		}
+
+		m.Packet = &PacketJSON{
+			ID:      message.GetID(),
+			Message: inner,
+		}
+	case reliable.Ack:
+		m.Ack = &AckJSON{
+			ID: message.GetID(),
+		}
+	default:
+		return nil, xerrors.Errorf("unsupported message '%T'", msg)
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the message if
+// appropriate, otherwise it returns an error.
+func (f msgFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := MessageJSON{}
+
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	if m.Packet != nil {
+		fac := ctx.GetFactory(reliable.MsgKey{})
+		if fac == nil {
+			return nil, xerrors.New("invalid message factory '<nil>'")
+		}
+
+		inner, err := fac.Deserialize(ctx, m.Packet.Message)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to deserialize message: %v", err)
+		}
+
+		return reliable.NewPacket(m.Packet.ID, inner), nil
+	}
+
+	if m.Ack != nil {
+		return reliable.NewAck(m.Ack.ID), nil
+	}
+
+	return nil, xerrors.New("message is empty")
+}
@@ -0,0 +1,62 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino/reliable"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestMsgFormat_Encode(t *testing.T) {
+	format := msgFormat{}
+
+	ctx := fake.NewContext()
+
+	data, err := format.Encode(ctx, reliable.NewPacket(3, fake.Message{}))
+	require.NoError(t, err)
+	require.Equal(t, `{"Packet":{"ID":3,"Message":{}}}`, string(data))
+
+	data, err = format.Encode(ctx, reliable.NewAck(3))
+	require.NoError(t, err)
+	require.Equal(t, `{"Ack":{"ID":3}}`, string(data))
+
+	_, err = format.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message 'fake.Message'")
+
+	_, err = format.Encode(ctx, reliable.NewPacket(0, fake.NewBadPublicKey()))
+	require.EqualError(t, err, fake.Err("failed to serialize message"))
+
+	_, err = format.Encode(fake.NewBadContext(), reliable.NewAck(0))
+	require.EqualError(t, err, fake.Err("failed to marshal"))
+}
+
+func TestMsgFormat_Decode(t *testing.T) {
+	format := msgFormat{}
+
+	ctx := fake.NewContext()
+	ctx = serde.WithFactory(ctx, reliable.MsgKey{}, fake.MessageFactory{})
+
+	msg, err := format.Decode(ctx, []byte(`{"Packet":{"ID":3,"Message":{}}}`))
+	require.NoError(t, err)
+	require.Equal(t, reliable.NewPacket(3, fake.Message{}), msg)
+
+	msg, err = format.Decode(ctx, []byte(`{"Ack":{"ID":3}}`))
+	require.NoError(t, err)
+	require.Equal(t, reliable.NewAck(3), msg)
+
+	_, err = format.Decode(ctx, []byte(`{}`))
+	require.EqualError(t, err, "message is empty")
+
+	_, err = format.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("failed to unmarshal"))
+
+	badCtx := serde.WithFactory(ctx, reliable.MsgKey{}, nil)
+	_, err = format.Decode(badCtx, []byte(`{"Packet":{}}`))
+	require.EqualError(t, err, "invalid message factory '<nil>'")
+
+	badCtx = serde.WithFactory(ctx, reliable.MsgKey{}, fake.NewBadMessageFactory())
+	_, err = format.Decode(badCtx, []byte(`{"Packet":{}}`))
+	require.EqualError(t, err, fake.Err("failed to deserialize message"))
+}
@@ -0,0 +1,121 @@
+package reliable
+
+import (
+	"go.dedis.ch/dela/serde"
+	"go.dedis.ch/dela/serde/registry"
+	"golang.org/x/xerrors"
+)
+
+var msgFormats = registry.NewSimpleRegistry()
+
+// RegisterMessageFormat registers the engine for the provided format.
+func RegisterMessageFormat(f serde.Format, e serde.FormatEngine) {
+	msgFormats.Register(f, e)
+}
+
+// Packet is the wrapper of a message that must be acknowledged by the
+// recipient. An identifier of zero means no acknowledgment is expected, which
+// is the case for the requests and the replies of a call.
+//
+// - implements serde.Message
+type Packet struct {
+	id  uint64
+	msg serde.Message
+}
+
+// NewPacket returns a new packet with the identifier.
+func NewPacket(id uint64, msg serde.Message) Packet {
+	return Packet{
+		id:  id,
+		msg: msg,
+	}
+}
+
+// GetID returns the identifier of the packet.
+func (p Packet) GetID() uint64 {
+	return p.id
+}
+
+// GetMessage returns the wrapped message.
+func (p Packet) GetMessage() serde.Message {
+	return p.msg
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// packet.
+func (p Packet) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, p)
+	if err != nil {
+		return nil, xerrors.Errorf("encoding failed: %v", err)
+	}
+
+	return data, nil
+}
+
+// Ack is the message sent back by the recipient of a packet to confirm its
+// receipt.
+//
+// - implements serde.Message
+type Ack struct {
+	id uint64
+}
+
+// NewAck returns a new acknowledgment of the packet with the identifier.
+func NewAck(id uint64) Ack {
+	return Ack{
+		id: id,
+	}
+}
+
+// GetID returns the identifier of the packet acknowledged.
+func (a Ack) GetID() uint64 {
+	return a.id
+}
+
+// Serialize implements serde.Message. It returns the serialized data of the
+// acknowledgment.
+func (a Ack) Serialize(ctx serde.Context) ([]byte, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	data, err := format.Encode(ctx, a)
+	if err != nil {
+		return nil, xerrors.Errorf("encoding failed: %v", err)
+	}
+
+	return data, nil
+}
+
+// MsgKey is the key of the factory of the wrapped messages.
+type MsgKey struct{}
+
+// Factory is the factory of the packets and the acknowledgments.
+//
+// - implements serde.Factory
+type Factory struct {
+	msgFac serde.Factory
+}
+
+// NewFactory returns a factory of packets that wraps the messages deserialized
+// by the given factory.
+func NewFactory(f serde.Factory) Factory {
+	return Factory{
+		msgFac: f,
+	}
+}
+
+// Deserialize implements serde.Factory. It populates the packet or the
+// acknowledgment if appropriate, otherwise it returns an error.
+func (f Factory) Deserialize(ctx serde.Context, data []byte) (serde.Message, error) {
+	format := msgFormats.Get(ctx.GetFormat())
+
+	ctx = serde.WithFactory(ctx, MsgKey{}, f.msgFac)
+
+	msg, err := format.Decode(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("decoding failed: %v", err)
+	}
+
+	return msg, nil
+}
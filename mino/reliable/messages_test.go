@@ -0,0 +1,57 @@
+package reliable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func init() {
+	RegisterMessageFormat(fake.GoodFormat, fake.Format{Msg: NewAck(1)})
+	RegisterMessageFormat(fake.BadFormat, fake.NewBadFormat())
+}
+
+func TestPacket_Getters(t *testing.T) {
+	p := NewPacket(2, fake.Message{})
+
+	require.Equal(t, uint64(2), p.GetID())
+	require.Equal(t, fake.Message{}, p.GetMessage())
+}
+
+func TestPacket_Serialize(t *testing.T) {
+	p := NewPacket(1, fake.Message{})
+
+	data, err := p.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = p.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("encoding failed"))
+}
+
+func TestAck_Getters(t *testing.T) {
+	require.Equal(t, uint64(2), NewAck(2).GetID())
+}
+
+func TestAck_Serialize(t *testing.T) {
+	ack := NewAck(1)
+
+	data, err := ack.Serialize(fake.NewContext())
+	require.NoError(t, err)
+	require.Equal(t, fake.GetFakeFormatValue(), data)
+
+	_, err = ack.Serialize(fake.NewBadContext())
+	require.EqualError(t, err, fake.Err("encoding failed"))
+}
+
+func TestFactory_Deserialize(t *testing.T) {
+	fac := NewFactory(fake.MessageFactory{})
+
+	msg, err := fac.Deserialize(fake.NewContext(), nil)
+	require.NoError(t, err)
+	require.Equal(t, NewAck(1), msg)
+
+	_, err = fac.Deserialize(fake.NewBadContext(), nil)
+	require.EqualError(t, err, fake.Err("decoding failed"))
+}
@@ -0,0 +1,409 @@
+// Package reliable implements an at-least-once delivery for the streams of an
+// RPC.
+//
+// The sender of a stream only learns about the failures of the network layer,
+// but it has no confirmation that a message reached its recipient. The
+// wrappers of this package attach an identifier to each message that the
+// recipient acknowledges as soon as it is received. The sender retries for
+// each recipient that did not acknowledge in time, up to the number of retries
+// of the policy, and reports the delivery status of each recipient.
+//
+// As a message is sent again when the acknowledgment is lost, the recipient
+// can receive the same message more than once.
+//
+// The RPC must be created with the factory of this package wrapping the
+// factory of the messages so that the packets and the acknowledgments can be
+// decoded.
+package reliable
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/wrapper"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// DefaultRetries is the default number of times a message is sent again
+	// to a recipient that did not acknowledge it.
+	DefaultRetries = 3
+
+	// DefaultTimeout is the default time to wait for an acknowledgment before
+	// the message is sent again.
+	DefaultTimeout = 2 * time.Second
+)
+
+// Status is the delivery status of a message for a recipient.
+type Status struct {
+	// To is the address of the recipient.
+	To mino.Address
+
+	// Attempts is the number of times the message has been sent.
+	Attempts int
+
+	// Err is nil when the recipient has acknowledged the message, otherwise it
+	// is the reason of the last failure.
+	Err error
+}
+
+// Sender is a sender that exposes the delivery status of a message for each
+// recipient.
+type Sender interface {
+	mino.Sender
+
+	// SendWithStatus sends the message to the addresses and returns a channel
+	// populated with the status of each recipient once the message is either
+	// acknowledged or the retries are exhausted. The channel is closed after
+	// the status of every recipient has been reported.
+	SendWithStatus(msg serde.Message, addrs ...mino.Address) <-chan Status
+}
+
+// Option is the type of option to configure the reliable streams.
+type Option func(*template)
+
+// WithRetries sets the maximum number of times a message is sent again to a
+// recipient.
+func WithRetries(n int) Option {
+	return func(tmpl *template) {
+		tmpl.retries = n
+	}
+}
+
+// WithTimeout sets the time to wait for an acknowledgment before the message
+// is sent again.
+func WithTimeout(d time.Duration) Option {
+	return func(tmpl *template) {
+		tmpl.timeout = d
+	}
+}
+
+type template struct {
+	retries int
+	timeout time.Duration
+}
+
+func newTemplate(opts []Option) template {
+	tmpl := template{
+		retries: DefaultRetries,
+		timeout: DefaultTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(&tmpl)
+	}
+
+	return tmpl
+}
+
+// NewRPC returns a new RPC that delivers the messages of the streams at least
+// once. The RPC to wrap must be created with the handler and the factory of
+// this package.
+func NewRPC(rpc mino.RPC, opts ...Option) wrapper.RPC {
+	return wrapper.NewRPC(rpc, codec{}, makeStream(opts))
+}
+
+// NewHandler returns a new handler so that the stream it handles delivers the
+// messages at least once. The receiver is read until the stream is closed.
+func NewHandler(h mino.Handler, opts ...Option) wrapper.Handler {
+	return wrapper.NewHandler(h, codec{}, makeStream(opts))
+}
+
+func makeStream(opts []Option) wrapper.StreamFunc {
+	return func(ctx context.Context,
+		out mino.Sender, in mino.Receiver) (mino.Sender, mino.Receiver) {
+
+		return Wrap(ctx, out, in, opts...)
+	}
+}
+
+// codec wraps the requests and the replies of the calls in packets that do
+// not expect an acknowledgment, as the replies already confirm the receipt.
+//
+// - implements wrapper.Codec
+type codec struct{}
+
+// Wrap implements wrapper.Codec. It returns a packet without identifier.
+func (codec) Wrap(msg serde.Message) serde.Message {
+	return NewPacket(0, msg)
+}
+
+// Unwrap implements wrapper.Codec. It returns the message of the packet.
+func (codec) Unwrap(msg serde.Message) serde.Message {
+	p, ok := msg.(Packet)
+	if ok {
+		return p.msg
+	}
+
+	return msg
+}
+
+// Wrap returns a sender that retries until the messages are acknowledged and a
+// receiver that acknowledges the messages it receives. The underlying receiver
+// is read in the background so that the acknowledgments are processed even if
+// the receiver is not, until the context is done or the stream is closed.
+func Wrap(ctx context.Context, out mino.Sender, in mino.Receiver,
+	opts ...Option) (Sender, mino.Receiver) {
+
+	tmpl := newTemplate(opts)
+
+	s := &sender{
+		out:     out,
+		retries: tmpl.retries,
+		timeout: tmpl.timeout,
+		pending: make(map[delivery]chan struct{}),
+	}
+
+	r := &receiver{
+		out:    out,
+		sender: s,
+		notify: make(chan struct{}, 1),
+	}
+
+	go r.listen(ctx, in)
+
+	return s, r
+}
+
+// delivery is the key of a message waiting for the acknowledgment of a
+// recipient. The identifiers are only unique for a sender, hence an
+// acknowledgment is accepted only from the recipient of the message.
+type delivery struct {
+	peer string
+	id   uint64
+}
+
+// sender is a sender that sends the messages to each recipient until it is
+// acknowledged, or the retries are exhausted.
+//
+// - implements reliable.Sender
+type sender struct {
+	sync.Mutex
+
+	out     mino.Sender
+	retries int
+	timeout time.Duration
+	counter uint64
+	pending map[delivery]chan struct{}
+}
+
+// Send implements mino.Sender. It sends the message to the addresses and
+// populates the channel with an error for each recipient that did not
+// acknowledge the message.
+func (s *sender) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	errs := make(chan error, len(addrs))
+
+	statuses := s.SendWithStatus(msg, addrs...)
+
+	go func() {
+		defer close(errs)
+
+		for status := range statuses {
+			if status.Err != nil {
+				errs <- xerrors.Errorf("couldn't deliver to %v after %d attempt(s): %v",
+					status.To, status.Attempts, status.Err)
+			}
+		}
+	}()
+
+	return errs
+}
+
+// SendWithStatus implements reliable.Sender. It sends the message to each
+// address with its own identifier and reports the delivery status.
+func (s *sender) SendWithStatus(msg serde.Message, addrs ...mino.Address) <-chan Status {
+	statuses := make(chan Status, len(addrs))
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(addrs))
+
+	for _, addr := range addrs {
+		id, acked := s.register(addr)
+
+		go func(addr mino.Address) {
+			defer wg.Done()
+
+			statuses <- s.deliver(id, acked, msg, addr)
+		}(addr)
+	}
+
+	go func() {
+		wg.Wait()
+		close(statuses)
+	}()
+
+	return statuses
+}
+
+func (s *sender) deliver(id uint64, acked <-chan struct{},
+	msg serde.Message, to mino.Address) Status {
+
+	defer s.unregister(to, id)
+
+	status := Status{To: to}
+
+	for status.Attempts <= s.retries {
+		status.Attempts++
+
+		status.Err = nil
+		for err := range s.out.Send(NewPacket(id, msg), to) {
+			status.Err = xerrors.Errorf("failed to send: %v", err)
+		}
+
+		timer := time.NewTimer(s.timeout)
+
+		select {
+		case <-acked:
+			timer.Stop()
+
+			status.Err = nil
+
+			return status
+		case <-timer.C:
+			if status.Err == nil {
+				status.Err = xerrors.Errorf("no ack after %v", s.timeout)
+			}
+		}
+	}
+
+	return status
+}
+
+func (s *sender) register(to mino.Address) (uint64, <-chan struct{}) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.counter++
+
+	acked := make(chan struct{})
+	s.pending[delivery{peer: to.String(), id: s.counter}] = acked
+
+	return s.counter, acked
+}
+
+func (s *sender) unregister(to mino.Address, id uint64) {
+	s.Lock()
+	delete(s.pending, delivery{peer: to.String(), id: id})
+	s.Unlock()
+}
+
+// ack closes the channel of the message with the identifier sent to the
+// address. An acknowledgment from another peer than the recipient is ignored.
+func (s *sender) ack(from mino.Address, id uint64) {
+	key := delivery{peer: from.String(), id: id}
+
+	s.Lock()
+	defer s.Unlock()
+
+	acked, found := s.pending[key]
+	if !found {
+		// Either a late acknowledgment of a message sent again, or one from a
+		// peer that did not receive the message.
+		dela.Logger.Debug().
+			Stringer("from", from).
+			Uint64("id", id).
+			Msg("ignoring unexpected ack")
+
+		return
+	}
+
+	close(acked)
+	delete(s.pending, key)
+}
+
+// item is a message, or an error, waiting to be returned by the receiver.
+type item struct {
+	from mino.Address
+	msg  serde.Message
+	err  error
+}
+
+// receiver is a receiver that acknowledges the packets it receives and
+// forwards the acknowledgments to the sender.
+//
+// - implements mino.Receiver
+type receiver struct {
+	sync.Mutex
+
+	out    mino.Sender
+	sender *sender
+	queue  []item
+	notify chan struct{}
+}
+
+// Recv implements mino.Receiver. It returns the next message received, or the
+// error that closed the stream.
+func (r *receiver) Recv(ctx context.Context) (mino.Address, serde.Message, error) {
+	for {
+		r.Lock()
+
+		if len(r.queue) > 0 {
+			next := r.queue[0]
+
+			// The error is kept so that the next calls return it too.
+			if next.err == nil {
+				r.queue = r.queue[1:]
+			}
+
+			r.Unlock()
+
+			return next.from, next.msg, next.err
+		}
+
+		r.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-r.notify:
+		}
+	}
+}
+
+func (r *receiver) listen(ctx context.Context, in mino.Receiver) {
+	for {
+		from, msg, err := in.Recv(ctx)
+		if err != nil {
+			r.push(item{err: err})
+			return
+		}
+
+		switch m := msg.(type) {
+		case Ack:
+			r.sender.ack(from, m.id)
+			continue
+		case Packet:
+			if m.id != 0 {
+				go r.acknowledge(m.id, from)
+			}
+
+			msg = m.msg
+		}
+
+		r.push(item{from: from, msg: msg})
+	}
+}
+
+func (r *receiver) acknowledge(id uint64, to mino.Address) {
+	for err := range r.out.Send(NewAck(id), to) {
+		dela.Logger.Warn().Err(err).
+			Stringer("to", to).
+			Uint64("id", id).
+			Msg("failed to send ack")
+	}
+}
+
+func (r *receiver) push(it item) {
+	r.Lock()
+	r.queue = append(r.queue, it)
+	r.Unlock()
+
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
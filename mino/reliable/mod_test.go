@@ -0,0 +1,241 @@
+package reliable
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestCodec(t *testing.T) {
+	msg := codec{}.Wrap(fake.Message{})
+	require.Equal(t, NewPacket(0, fake.Message{}), msg)
+	require.Equal(t, fake.Message{}, codec{}.Unwrap(msg))
+	require.Equal(t, fake.Message{}, codec{}.Unwrap(fake.Message{}))
+}
+
+func TestRPC_Stream(t *testing.T) {
+	rpc := fake.NewStreamRPC(fake.NewReceiver(), fake.Sender{})
+
+	s, r, err := NewRPC(rpc, WithRetries(1)).Stream(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, s.(*sender).retries)
+	require.IsType(t, &receiver{}, r)
+
+	_, _, err = NewRPC(fake.NewBadRPC()).Stream(context.Background(), nil)
+	require.EqualError(t, err, fake.Err("stream failed"))
+}
+
+func TestHandler_Stream(t *testing.T) {
+	h := NewHandler(fakeHandler{}, WithTimeout(time.Second))
+
+	err := h.Stream(fake.Sender{}, fake.NewReceiver())
+	require.NoError(t, err)
+}
+
+func TestStream_Deliver(t *testing.T) {
+	a, b := makePair(0, WithTimeout(time.Second))
+
+	statuses := a.out.SendWithStatus(fake.Message{}, b.addr)
+
+	from, msg, err := b.in.Recv(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, a.addr, from)
+	require.Equal(t, fake.Message{}, msg)
+
+	status := <-statuses
+	require.NoError(t, status.Err)
+	require.Equal(t, b.addr, status.To)
+	require.Equal(t, 1, status.Attempts)
+
+	_, more := <-statuses
+	require.False(t, more)
+}
+
+func TestStream_Retry_Deliver(t *testing.T) {
+	a, b := makePair(2, WithTimeout(10*time.Millisecond))
+
+	errs := a.out.Send(fake.Message{}, b.addr)
+
+	_, msg, err := b.in.Recv(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, fake.Message{}, msg)
+
+	require.Empty(t, drain(errs))
+}
+
+func TestStream_NoAck_Deliver(t *testing.T) {
+	link := make(chan fake.ReceiverMessage, 10)
+	out := &pipe{from: fake.NewAddress(0), ch: link}
+
+	s, _ := Wrap(context.Background(), out, fake.NewBlockingReceiver(),
+		WithRetries(2), WithTimeout(time.Millisecond))
+
+	status := <-s.SendWithStatus(fake.Message{}, fake.NewAddress(1))
+	require.EqualError(t, status.Err, "no ack after 1ms")
+	require.Equal(t, 3, status.Attempts)
+	require.Len(t, link, 3)
+
+	errs := drain(s.Send(fake.Message{}, fake.NewAddress(1)))
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0],
+		"couldn't deliver to fake.Address[1] after 3 attempt(s): no ack after 1ms")
+}
+
+func TestSender_Ack(t *testing.T) {
+	s := &sender{pending: make(map[delivery]chan struct{})}
+
+	id, acked := s.register(fake.NewAddress(1))
+
+	// An acknowledgment from another peer must not confirm the delivery.
+	s.ack(fake.NewAddress(2), id)
+	require.Len(t, s.pending, 1)
+
+	select {
+	case <-acked:
+		t.Fatal("unexpected ack")
+	default:
+	}
+
+	s.ack(fake.NewAddress(1), id)
+	require.Empty(t, s.pending)
+
+	_, more := <-acked
+	require.False(t, more)
+
+	// A late acknowledgment is ignored.
+	s.ack(fake.NewAddress(1), id)
+}
+
+func TestStream_BadSender_Deliver(t *testing.T) {
+	s, _ := Wrap(context.Background(), fake.NewBadSender(), fake.NewBlockingReceiver(),
+		WithRetries(0), WithTimeout(time.Millisecond))
+
+	status := <-s.SendWithStatus(fake.Message{}, fake.NewAddress(1))
+	require.EqualError(t, status.Err, fake.Err("failed to send"))
+	require.Equal(t, 1, status.Attempts)
+}
+
+func TestReceiver_Recv(t *testing.T) {
+	in := fake.NewReceiver(
+		fake.NewRecvMsg(fake.NewAddress(0), fake.Message{}),
+		fake.NewRecvMsg(fake.NewAddress(0), NewAck(42)),
+		fake.NewRecvMsg(fake.NewAddress(0), NewPacket(0, fake.Message{})),
+	)
+
+	_, r := Wrap(context.Background(), fake.Sender{}, in)
+
+	for i := 0; i < 2; i++ {
+		from, msg, err := r.Recv(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, fake.NewAddress(0), from)
+		require.Equal(t, fake.Message{}, msg)
+	}
+
+	_, _, err := r.Recv(context.Background())
+	require.Equal(t, io.EOF, err)
+
+	_, _, err = r.Recv(context.Background())
+	require.Equal(t, io.EOF, err)
+
+	_, r = Wrap(context.Background(), fake.Sender{}, fake.NewBlockingReceiver())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err = r.Recv(ctx)
+	require.Equal(t, context.Canceled, err)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeHandler struct {
+	mino.UnsupportedHandler
+}
+
+func (h fakeHandler) Stream(out mino.Sender, in mino.Receiver) error {
+	s, ok := out.(*sender)
+	if !ok || s.timeout != time.Second {
+		return fake.GetError()
+	}
+
+	return nil
+}
+
+// pipe is a sender that pushes the messages to the channel of the peer, and
+// drops a number of packets before.
+type pipe struct {
+	sync.Mutex
+
+	from mino.Address
+	ch   chan fake.ReceiverMessage
+	drop int
+}
+
+func (p *pipe) Send(msg serde.Message, addrs ...mino.Address) <-chan error {
+	p.Lock()
+	defer p.Unlock()
+
+	_, isPacket := msg.(Packet)
+	if isPacket && p.drop > 0 {
+		p.drop--
+	} else {
+		p.ch <- fake.NewRecvMsg(p.from, msg)
+	}
+
+	errs := make(chan error)
+	close(errs)
+
+	return errs
+}
+
+type chanReceiver chan fake.ReceiverMessage
+
+func (r chanReceiver) Recv(ctx context.Context) (mino.Address, serde.Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case m := <-r:
+		return m.Address, m.Message, nil
+	}
+}
+
+type peer struct {
+	addr mino.Address
+	out  Sender
+	in   mino.Receiver
+}
+
+// makePair returns two peers connected to each other. The first peer drops the
+// given number of packets.
+func makePair(drop int, opts ...Option) (peer, peer) {
+	chA := make(chan fake.ReceiverMessage, 10)
+	chB := make(chan fake.ReceiverMessage, 10)
+
+	a := peer{addr: fake.NewAddress(0)}
+	b := peer{addr: fake.NewAddress(1)}
+
+	a.out, a.in = Wrap(context.Background(), &pipe{from: a.addr, ch: chB, drop: drop},
+		chanReceiver(chA), opts...)
+
+	b.out, b.in = Wrap(context.Background(), &pipe{from: b.addr, ch: chA},
+		chanReceiver(chB), opts...)
+
+	return a, b
+}
+
+func drain(errs <-chan error) []error {
+	list := []error{}
+	for err := range errs {
+		list = append(list, err)
+	}
+
+	return list
+}
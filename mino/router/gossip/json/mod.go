@@ -0,0 +1,78 @@
+package json
+
+import (
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/router/gossip/types"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	types.RegisterHandshakeFormat(serde.FormatJSON, hsFormat{})
+}
+
+// HandshakeJSON is the JSON message for the handshake.
+type HandshakeJSON struct {
+	Fanout    int
+	Addresses [][]byte
+}
+
+// hsFormat is the format engine to encode and decode handshake messages.
+//
+// - implements serde.FormatEngine
+type hsFormat struct{}
+
+// Encode implements serde.FormatEngine. It returns the serialized data for the
+// handshake if appropriate, otherwise it returns an error.
+func (hsFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, error) {
+	hs, ok := msg.(types.Handshake)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message '%T'", msg)
+	}
+
+	addrs := make([][]byte, len(hs.GetAddresses()))
+	for i, addr := range hs.GetAddresses() {
+		raw, err := addr.MarshalText()
+		if err != nil {
+			return nil, xerrors.Errorf("failed to marshal address: %v", err)
+		}
+
+		addrs[i] = raw
+	}
+
+	m := HandshakeJSON{
+		Fanout:    hs.GetFanout(),
+		Addresses: addrs,
+	}
+
+	data, err := ctx.Marshal(m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal handshake: %v", err)
+	}
+
+	return data, nil
+}
+
+// Decode implements serde.FormatEngine. It populates the handshake if
+// appropriate, otherwise it returns an error.
+func (hsFormat) Decode(ctx serde.Context, data []byte) (serde.Message, error) {
+	m := HandshakeJSON{}
+	err := ctx.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	fac := ctx.GetFactory(types.AddrKey{})
+
+	factory, ok := fac.(mino.AddressFactory)
+	if !ok {
+		return nil, xerrors.Errorf("invalid address factory '%T'", fac)
+	}
+
+	addrs := make([]mino.Address, len(m.Addresses))
+	for i, raw := range m.Addresses {
+		addrs[i] = factory.FromText(raw)
+	}
+
+	return types.NewHandshake(m.Fanout, addrs...), nil
+}
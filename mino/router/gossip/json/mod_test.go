@@ -0,0 +1,48 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino/router/gossip/types"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestHandshakeFormat_Encode(t *testing.T) {
+	fmt := hsFormat{}
+
+	ctx := fake.NewContext()
+	hs := types.NewHandshake(3, fake.NewAddress(1))
+
+	data, err := fmt.Encode(ctx, hs)
+	require.NoError(t, err)
+	require.Equal(t, `{"Fanout":3,"Addresses":["AQAAAA=="]}`, string(data))
+
+	_, err = fmt.Encode(ctx, fake.Message{})
+	require.EqualError(t, err, "unsupported message 'fake.Message'")
+
+	_, err = fmt.Encode(ctx, types.NewHandshake(1, fake.NewBadAddress()))
+	require.EqualError(t, err, fake.Err("failed to marshal address"))
+
+	_, err = fmt.Encode(fake.NewBadContext(), hs)
+	require.EqualError(t, err, fake.Err("failed to marshal handshake"))
+}
+
+func TestHandshakeFormat_Decode(t *testing.T) {
+	fmt := hsFormat{}
+
+	ctx := fake.NewContext()
+	ctx = serde.WithFactory(ctx, types.AddrKey{}, fake.AddressFactory{})
+
+	msg, err := fmt.Decode(ctx, []byte(`{"Fanout":3,"Addresses":["AQAAAA=="]}`))
+	require.NoError(t, err)
+	require.Equal(t, types.NewHandshake(3, fake.NewAddress(1)), msg)
+
+	_, err = fmt.Decode(fake.NewBadContext(), []byte(`{}`))
+	require.EqualError(t, err, fake.Err("failed to unmarshal"))
+
+	badCtx := serde.WithFactory(ctx, types.AddrKey{}, nil)
+	_, err = fmt.Decode(badCtx, []byte(`{}`))
+	require.EqualError(t, err, "invalid address factory '<nil>'")
+}
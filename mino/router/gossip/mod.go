@@ -0,0 +1,359 @@
+// Package gossip is an implementation of a routing algorithm based on epidemic
+// dissemination.
+//
+// A node that has a packet to route pushes it to a small number of peers, the
+// fanout, and hands each of them a random share of the participants that are
+// still to be reached. Every peer does the same with its own share, so that the
+// packet spreads like an epidemic and reaches N participants in about
+// log_fanout(N) hops. The shares don't overlap, which means a participant
+// receives a packet only once.
+//
+// As opposed to the tree router, which gives up when a participant close to the
+// leaves is unreachable, a node that fails to reach one of its peers elects a
+// random member of the share to take its place, until the share is exhausted.
+// A failure therefore only costs the unreachable participant, and because the
+// shares are drawn again for every stream, a faulty participant is not always
+// on the path to the same ones.
+package gossip
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/router"
+	"go.dedis.ch/dela/mino/router/gossip/types"
+	treetypes "go.dedis.ch/dela/mino/router/tree/types"
+	"golang.org/x/xerrors"
+)
+
+const defaultFanout = 3
+
+// Option is the type of option to configure the router.
+type Option func(*Router)
+
+// WithFanout sets the number of peers a node pushes the packets to.
+func WithFanout(n int) Option {
+	return func(r *Router) {
+		r.fanout = n
+	}
+}
+
+// WithSeed sets the seed of the draws of the shares, so that the same
+// participants produce the same shares. The shares are otherwise drawn at
+// random, which is preferred outside of the tests and of the investigations as
+// a faulty participant would always be on the path to the same ones.
+func WithSeed(seed []byte) Option {
+	return func(r *Router) {
+		r.seed = seed
+	}
+}
+
+// Router is an implementation of a router producing routes with an epidemic
+// dissemination.
+//
+// - implements router.Router
+type Router struct {
+	fanout    int
+	seed      []byte
+	packetFac router.PacketFactory
+	hsFac     router.HandshakeFactory
+}
+
+// NewRouter returns a new router.
+func NewRouter(f mino.AddressFactory, opts ...Option) Router {
+	r := Router{
+		fanout:    defaultFanout,
+		packetFac: treetypes.NewPacketFactory(f),
+		hsFac:     types.NewHandshakeFactory(f),
+	}
+
+	for _, opt := range opts {
+		opt(&r)
+	}
+
+	return r
+}
+
+// GetPacketFactory implements router.Router. It returns the packet factory.
+func (r Router) GetPacketFactory() router.PacketFactory {
+	return r.packetFac
+}
+
+// GetHandshakeFactory implements router.Router. It returns the handshake
+// factory.
+func (r Router) GetHandshakeFactory() router.HandshakeFactory {
+	return r.hsFac
+}
+
+// New implements router.Router. It creates the routing table for the node that
+// is booting the protocol, which is responsible for all the players.
+func (r Router) New(players mino.Players, me mino.Address) (router.RoutingTable, error) {
+	addrs := make([]mino.Address, 0, players.Len())
+	iter := players.AddressIterator()
+	for iter.HasNext() {
+		addrs = append(addrs, iter.GetNext())
+	}
+
+	table := NewTable(r.fanout, addrs)
+	table.seed = r.seed
+
+	return table, nil
+}
+
+// GenerateTableFrom implements router.Router. It creates the routing table for
+// the share of participants received in the handshake.
+func (r Router) GenerateTableFrom(h router.Handshake) (router.RoutingTable, error) {
+	hs, ok := h.(types.Handshake)
+	if !ok {
+		return nil, xerrors.Errorf("invalid handshake '%T'", h)
+	}
+
+	table := NewTable(hs.GetFanout(), hs.GetAddresses())
+	table.seed = r.seed
+
+	return table, nil
+}
+
+// Table is a routing table that pushes the packets to a few peers, each of them
+// being responsible for a share of the participants.
+//
+// - implements router.RoutingTable
+type Table struct {
+	sync.Mutex
+
+	fanout   int
+	peers    map[mino.Address]addrSet
+	expected addrSet
+	offline  addrSet
+	random   *rand.Rand
+	seed     []byte
+	draws    uint64
+}
+
+// NewTable creates a new routing table for the given addresses.
+func NewTable(fanout int, expected []mino.Address) *Table {
+	// A node needs at least one peer to spread the packets.
+	if fanout < 1 {
+		fanout = 1
+	}
+
+	set := make(addrSet)
+	for _, addr := range expected {
+		set[addr] = struct{}{}
+	}
+
+	return &Table{
+		fanout:   fanout,
+		peers:    make(map[mino.Address]addrSet),
+		expected: set,
+		offline:  make(addrSet),
+		random:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Make implements router.RoutingTable. It creates a packet with the source
+// address, the destination addresses and the payload.
+func (t *Table) Make(src mino.Address, to []mino.Address, msg []byte) router.Packet {
+	return treetypes.NewPacket(src, msg, to...)
+}
+
+// PrepareHandshakeFor implements router.RoutingTable. It creates a handshake
+// message with the share of the peer, which will generate its own routing table
+// from it.
+func (t *Table) PrepareHandshakeFor(to mino.Address) router.Handshake {
+	t.Lock()
+	defer t.Unlock()
+
+	share := t.peers[to]
+
+	addrs := make([]mino.Address, 0, len(share))
+	for addr := range share {
+		addrs = append(addrs, addr)
+	}
+
+	return types.NewHandshake(t.fanout, addrs...)
+}
+
+// Forward implements router.RoutingTable. It takes a packet and split it into
+// the different routes it should be forwarded to. A destination that is not
+// part of the participants of the table is routed to the parent.
+func (t *Table) Forward(packet router.Packet) (router.Routes, router.Voids) {
+	routes := make(router.Routes)
+	voids := make(router.Voids)
+
+	t.Lock()
+	defer t.Unlock()
+
+	for _, dest := range packet.GetDestination() {
+		if t.offline.search(dest) {
+			voids[dest] = router.Void{Error: xerrors.New("address is unreachable")}
+			continue
+		}
+
+		gateway := t.getRoute(dest)
+
+		p, ok := routes[gateway]
+		if !ok {
+			p = fork(packet)
+			routes[gateway] = p
+		}
+
+		p.(*treetypes.Packet).Add(dest)
+	}
+
+	return routes, voids
+}
+
+// OnFailure implements router.RoutingTable. It marks the address as unreachable
+// and, if it is one of the peers, it elects a member of its share to take its
+// place.
+func (t *Table) OnFailure(to mino.Address) error {
+	t.Lock()
+	defer t.Unlock()
+
+	if !t.expected.search(to) && t.searchPeer(to) == nil {
+		return xerrors.Errorf("address %v is not routed by the table", to)
+	}
+
+	t.offline[to] = struct{}{}
+	delete(t.expected, to)
+
+	share, found := t.peers[to]
+	if !found {
+		return nil
+	}
+
+	delete(t.peers, to)
+
+	next := t.pick(share, 1)
+	if len(next) == 0 {
+		return nil
+	}
+
+	delete(share, next[0])
+	t.peers[next[0]] = share
+
+	return nil
+}
+
+// getRoute returns the peer that the packet should be pushed to in order to
+// reach the address, or nil if it must go to the parent. The first time an
+// expected address is requested, it becomes a peer and it receives a share of
+// the participants.
+func (t *Table) getRoute(to mino.Address) mino.Address {
+	peer := t.searchPeer(to)
+	if peer != nil {
+		return peer
+	}
+
+	if !t.expected.search(to) {
+		return nil
+	}
+
+	delete(t.expected, to)
+
+	// The participants left are spread evenly over the free slots, so that the
+	// last peer takes all of them.
+	slots := t.fanout - len(t.peers)
+	if slots < 1 {
+		slots = 1
+	}
+
+	size := int(math.Ceil(float64(len(t.expected)) / float64(slots)))
+
+	share := make(addrSet)
+	for _, addr := range t.pick(t.expected, size) {
+		share[addr] = struct{}{}
+		delete(t.expected, addr)
+	}
+
+	t.peers[to] = share
+
+	return to
+}
+
+// searchPeer returns the peer that is responsible for the address, or nil if
+// none is.
+func (t *Table) searchPeer(to mino.Address) mino.Address {
+	for peer, share := range t.peers {
+		if peer.Equal(to) || share.search(to) {
+			return peer
+		}
+	}
+
+	return nil
+}
+
+// pick returns up to n addresses of the set drawn at random, or with the seed
+// of the table when it has one.
+func (t *Table) pick(set addrSet, n int) []mino.Address {
+	addrs := make([]mino.Address, 0, len(set))
+	for addr := range set {
+		addrs = append(addrs, addr)
+	}
+
+	if t.seed != nil {
+		return t.sample(addrs, n)
+	}
+
+	t.random.Shuffle(len(addrs), func(i, j int) {
+		addrs[i], addrs[j] = addrs[j], addrs[i]
+	})
+
+	if n < len(addrs) {
+		addrs = addrs[:n]
+	}
+
+	return addrs
+}
+
+// sample returns up to n addresses drawn with the seed of the table and the
+// number of draws so far. The addresses are sorted beforehand so that the
+// result doesn't depend on the order of the set.
+func (t *Table) sample(addrs []mino.Address, n int) []mino.Address {
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].String() < addrs[j].String()
+	})
+
+	seed := make([]byte, len(t.seed)+8)
+	copy(seed, t.seed)
+	binary.LittleEndian.PutUint64(seed[len(t.seed):], t.draws)
+
+	t.draws++
+
+	weights := make([]uint64, len(addrs))
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	picked := make([]mino.Address, 0, n)
+	for _, index := range mino.SampleIndices(seed, weights, n) {
+		picked = append(picked, addrs[index])
+	}
+
+	return picked
+}
+
+// addrSet is a set of unique addresses.
+type addrSet map[mino.Address]struct{}
+
+func (set addrSet) search(to mino.Address) bool {
+	_, found := set[to]
+	return found
+}
+
+// fork returns a packet without destination for the message of the packet. A
+// packet keeps its encoded payload so that it is forwarded as is.
+func fork(packet router.Packet) *treetypes.Packet {
+	pkt, ok := packet.(*treetypes.Packet)
+	if ok {
+		return pkt.Fork()
+	}
+
+	return treetypes.NewPacket(packet.GetSource(), packet.GetMessage())
+}
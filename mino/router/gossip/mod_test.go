@@ -0,0 +1,355 @@
+package gossip
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/mino/minogrpc"
+	minoRouter "go.dedis.ch/dela/mino/router"
+	"go.dedis.ch/dela/mino/router/gossip/types"
+	treetypes "go.dedis.ch/dela/mino/router/tree/types"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestRouter_GetPacketFactory(t *testing.T) {
+	router := NewRouter(fake.AddressFactory{})
+
+	require.NotNil(t, router.GetPacketFactory())
+}
+
+func TestRouter_GetHandshakeFactory(t *testing.T) {
+	router := NewRouter(fake.AddressFactory{})
+
+	require.NotNil(t, router.GetHandshakeFactory())
+}
+
+func TestRouter_New(t *testing.T) {
+	router := NewRouter(fake.AddressFactory{}, WithFanout(5))
+
+	table, err := router.New(mino.NewAddresses(makeAddrs(10)...), fake.NewAddress(0))
+	require.NoError(t, err)
+	require.Equal(t, 5, table.(*Table).fanout)
+	require.Len(t, table.(*Table).expected, 10)
+	require.Nil(t, table.(*Table).seed)
+
+	router = NewRouter(fake.AddressFactory{}, WithSeed([]byte{1}))
+
+	table, err = router.New(mino.NewAddresses(makeAddrs(10)...), fake.NewAddress(0))
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, table.(*Table).seed)
+}
+
+func TestRouter_GenerateTableFrom(t *testing.T) {
+	router := NewRouter(fake.AddressFactory{})
+
+	table, err := router.GenerateTableFrom(types.NewHandshake(2, makeAddrs(5)...))
+	require.NoError(t, err)
+	require.Equal(t, 2, table.(*Table).fanout)
+	require.Len(t, table.(*Table).expected, 5)
+
+	router = NewRouter(fake.AddressFactory{}, WithSeed([]byte{1}))
+
+	table, err = router.GenerateTableFrom(types.NewHandshake(2, makeAddrs(5)...))
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, table.(*Table).seed)
+
+	_, err = router.GenerateTableFrom(fakeHandshake{})
+	require.EqualError(t, err, "invalid handshake 'gossip.fakeHandshake'")
+}
+
+func TestTable_New(t *testing.T) {
+	table := NewTable(0, nil)
+	require.Equal(t, 1, table.fanout)
+}
+
+func TestTable_Make(t *testing.T) {
+	table := NewTable(3, makeAddrs(5))
+
+	pkt := table.Make(fake.NewAddress(0), makeAddrs(3), []byte{1, 2, 3})
+	require.Equal(t, fake.NewAddress(0), pkt.GetSource())
+	require.Len(t, pkt.GetDestination(), 3)
+	require.Equal(t, []byte{1, 2, 3}, pkt.GetMessage())
+}
+
+func TestTable_Forward(t *testing.T) {
+	table := NewTable(3, makeAddrs(20))
+
+	pkt := treetypes.NewPacket(fake.NewAddress(0), []byte{1, 2, 3}, makeAddrs(20)...)
+
+	routes, voids := table.Forward(pkt)
+	require.Empty(t, voids)
+	require.Len(t, routes, 3)
+	require.Empty(t, table.expected)
+
+	// Every participant is reached exactly once, either as a peer or through
+	// the share of a peer.
+	seen := make(map[mino.Address]int)
+	for peer, route := range routes {
+		hs := table.PrepareHandshakeFor(peer).(types.Handshake)
+		require.Equal(t, 3, hs.GetFanout())
+
+		for _, addr := range append(hs.GetAddresses(), peer) {
+			seen[addr]++
+		}
+
+		require.Len(t, route.GetDestination(), len(hs.GetAddresses())+1)
+	}
+
+	require.Len(t, seen, 20)
+	for _, n := range seen {
+		require.Equal(t, 1, n)
+	}
+
+	// The routes stay the same for the next packets.
+	routes2, _ := table.Forward(pkt)
+	for peer := range routes2 {
+		require.Contains(t, routes, peer)
+	}
+
+	// An unknown address goes to the parent.
+	routes, voids = table.Forward(treetypes.NewPacket(fake.NewAddress(0), nil, fake.NewAddress(100)))
+	require.Empty(t, voids)
+	require.Len(t, routes, 1)
+	require.NotNil(t, routes[nil])
+
+	table.offline[fake.NewAddress(1)] = struct{}{}
+	_, voids = table.Forward(pkt)
+	require.Len(t, voids, 1)
+	require.EqualError(t, voids[fake.NewAddress(1)].Error, "address is unreachable")
+}
+
+func TestTable_Seed_Forward(t *testing.T) {
+	shares := func(seed []byte) map[mino.Address][]mino.Address {
+		table := NewTable(3, makeAddrs(20))
+		table.seed = seed
+
+		pkt := treetypes.NewPacket(fake.NewAddress(0), nil, makeAddrs(20)...)
+		routes, _ := table.Forward(pkt)
+
+		res := make(map[mino.Address][]mino.Address)
+		for peer := range routes {
+			addrs := table.PrepareHandshakeFor(peer).(types.Handshake).GetAddresses()
+			sort.Slice(addrs, func(i, j int) bool {
+				return addrs[i].String() < addrs[j].String()
+			})
+
+			res[peer] = addrs
+		}
+
+		require.Len(t, res, 3)
+
+		return res
+	}
+
+	// The same seed produces the same shares.
+	require.Equal(t, shares([]byte{1}), shares([]byte{1}))
+	require.NotEqual(t, shares([]byte{1}), shares([]byte{2}))
+}
+
+func TestTable_Payload_Forward(t *testing.T) {
+	table := NewTable(3, makeAddrs(20))
+
+	pl := fakePayload{}
+	pkt := treetypes.NewPacketWithPayload(fake.NewAddress(0), pl, makeAddrs(20)...)
+
+	routes, _ := table.Forward(pkt)
+	require.Len(t, routes, 3)
+
+	// The payload is forwarded without being decoded.
+	for _, route := range routes {
+		require.Equal(t, pl, route.(*treetypes.Packet).GetPayload())
+	}
+
+	routes, _ = table.Forward(fakePacket{dest: makeAddrs(20)})
+	require.Len(t, routes, 3)
+	for _, route := range routes {
+		require.Nil(t, route.(*treetypes.Packet).GetPayload())
+		require.Equal(t, []byte{1, 2, 3}, route.GetMessage())
+	}
+}
+
+func TestTable_OnFailure(t *testing.T) {
+	table := NewTable(2, makeAddrs(10))
+
+	routes, _ := table.Forward(treetypes.NewPacket(fake.NewAddress(0), nil, makeAddrs(10)...))
+	require.Len(t, routes, 2)
+
+	peer := fake.NewAddress(0)
+	share := table.peers[peer]
+	require.Len(t, share, 5)
+
+	// A member of the share takes the place of the peer.
+	err := table.OnFailure(peer)
+	require.NoError(t, err)
+	require.Contains(t, table.offline, peer)
+	require.NotContains(t, table.peers, peer)
+	require.Len(t, table.peers, 2)
+
+	routes, voids := table.Forward(treetypes.NewPacket(fake.NewAddress(0), nil, makeAddrs(10)...))
+	require.Len(t, voids, 1)
+	require.Len(t, routes, 2)
+
+	n := 0
+	for _, route := range routes {
+		n += len(route.GetDestination())
+	}
+	require.Equal(t, 9, n)
+
+	// A peer without a share is simply unreachable.
+	table = NewTable(1, makeAddrs(1))
+	table.Forward(treetypes.NewPacket(fake.NewAddress(0), nil, fake.NewAddress(0)))
+
+	err = table.OnFailure(fake.NewAddress(0))
+	require.NoError(t, err)
+	require.Empty(t, table.peers)
+
+	// An address not yet requested is marked as unreachable.
+	table = NewTable(1, makeAddrs(2))
+	err = table.OnFailure(fake.NewAddress(1))
+	require.NoError(t, err)
+	require.Contains(t, table.offline, fake.NewAddress(1))
+	require.NotContains(t, table.expected, fake.NewAddress(1))
+
+	err = table.OnFailure(fake.NewAddress(5))
+	require.EqualError(t, err, "address fake.Address[5] is not routed by the table")
+}
+
+// This test makes sure the packets of a stream reach the participants that are
+// online even if some of the others, possibly peers of the first hops, fail.
+func TestIntegration_Scenario_Stream(t *testing.T) {
+	n := 12
+
+	mm := make([]*minogrpc.Minogrpc, n)
+	rpcs := make([]mino.RPC, n)
+
+	for i := range mm {
+		addr := minogrpc.ParseAddress("127.0.0.1", 0)
+
+		m, err := minogrpc.NewMinogrpc(addr, NewRouter(minogrpc.NewAddressFactory(), WithFanout(2)))
+		require.NoError(t, err)
+
+		mm[i] = m
+		rpcs[i] = mino.MustCreateRPC(m, "test", echoHandler{}, fake.MessageFactory{})
+
+		for _, k := range mm[:i] {
+			m.GetCertificateStore().Store(k.GetAddress(), k.GetCertificate())
+			k.GetCertificateStore().Store(m.GetAddress(), m.GetCertificate())
+		}
+	}
+
+	defer func() {
+		for _, m := range mm[:n-3] {
+			m.GracefulStop()
+		}
+	}()
+
+	// The first participant is the root and the three last ones are offline.
+	for _, m := range mm[n-3:] {
+		require.NoError(t, m.GracefulStop())
+	}
+
+	addrs := make([]mino.Address, n)
+	for i, m := range mm {
+		addrs[i] = m.GetAddress()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sender, recv, err := rpcs[0].Stream(ctx, mino.NewAddresses(addrs...))
+	require.NoError(t, err)
+
+	errs := sender.Send(fake.Message{}, addrs[1:]...)
+
+	numErrs := 0
+	for err := range errs {
+		require.Contains(t, err.Error(), "address is unreachable")
+		numErrs++
+	}
+
+	require.Equal(t, 3, numErrs)
+
+	replies := make(map[string]struct{})
+	for len(replies) < n-4 {
+		from, _, err := recv.Recv(ctx)
+		require.NoError(t, err)
+
+		replies[from.String()] = struct{}{}
+	}
+
+	for _, addr := range addrs[1 : n-3] {
+		require.Contains(t, replies, addr.String())
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+func makeAddrs(n int) []mino.Address {
+	addrs := make([]mino.Address, n)
+	for i := range addrs {
+		addrs[i] = fake.NewAddress(i)
+	}
+
+	return addrs
+}
+
+type fakeHandshake struct {
+	minoRouter.Handshake
+}
+
+type fakePayload struct{}
+
+func (fakePayload) GetFormat() serde.Format {
+	return fake.GoodFormat
+}
+
+func (fakePayload) GetEncoded() []byte {
+	return nil
+}
+
+func (fakePayload) Decode() ([]byte, error) {
+	return nil, fake.GetError()
+}
+
+type fakePacket struct {
+	minoRouter.Packet
+
+	dest []mino.Address
+}
+
+func (p fakePacket) GetSource() mino.Address {
+	return fake.NewAddress(0)
+}
+
+func (p fakePacket) GetDestination() []mino.Address {
+	return p.dest
+}
+
+func (p fakePacket) GetMessage() []byte {
+	return []byte{1, 2, 3}
+}
+
+// echoHandler sends the messages of a stream back to their sender.
+type echoHandler struct {
+	mino.UnsupportedHandler
+}
+
+func (echoHandler) Stream(out mino.Sender, in mino.Receiver) error {
+	for {
+		from, msg, err := in.Recv(context.Background())
+		if err != nil {
+			return nil
+		}
+
+		err = <-out.Send(msg, from)
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,14 @@
+// Package types implements the handshake message for the gossip routing
+// algorithm. The packets are the ones of the tree router so that both routers
+// share the same packet format.
+//
+// The messages have been implemented in this isolated package so that it does
+// not create cycle imports when importing the serde formats.
+package types
+
+import "go.dedis.ch/dela/serde"
+
+// RegisterHandshakeFormat registers the engine for the provided format.
+func RegisterHandshakeFormat(f serde.Format, e serde.FormatEngine) {
+	hsFormats.Register(f, e)
+}
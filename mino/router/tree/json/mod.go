@@ -1,6 +1,8 @@
 package json
 
 import (
+	"encoding/json"
+
 	"go.dedis.ch/dela/mino"
 	"go.dedis.ch/dela/mino/router/tree/types"
 	"go.dedis.ch/dela/serde"
@@ -12,11 +14,12 @@ func init() {
 	types.RegisterHandshakeFormat(serde.FormatJSON, hsFormat{})
 }
 
-// PacketJSON describes a JSON formatted packet
+// PacketJSON describes a JSON formatted packet. The message is kept raw so
+// that a relay can forward it without decoding it.
 type PacketJSON struct {
 	Source  []byte
 	Dest    [][]byte
-	Message []byte
+	Message json.RawMessage
 }
 
 // HandshakeJSON is the JSON message for the handshake.
@@ -54,10 +57,15 @@ func (f packetFormat) Encode(ctx serde.Context, msg serde.Message) ([]byte, erro
 		dest[i] = addBuf
 	}
 
+	message, err := encodeMessage(packet)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to encode message: %v", err)
+	}
+
 	p := PacketJSON{
 		Source:  source,
 		Dest:    dest,
-		Message: packet.GetMessage(),
+		Message: message,
 	}
 
 	data, err := ctx.Marshal(p)
@@ -92,11 +100,55 @@ func (f packetFormat) Decode(ctx serde.Context, data []byte) (serde.Message, err
 		dest[i] = fac.FromText(buf)
 	}
 
-	packet := types.NewPacket(source, p.Message, dest...)
+	if len(p.Message) == 0 || string(p.Message) == "null" {
+		return types.NewPacket(source, nil, dest...), nil
+	}
+
+	packet := types.NewPacketWithPayload(source, payload(p.Message), dest...)
 
 	return packet, nil
 }
 
+// encodeMessage returns the JSON encoding of the message of the packet. A
+// payload already in JSON is returned untouched.
+func encodeMessage(packet *types.Packet) (json.RawMessage, error) {
+	pl := packet.GetPayload()
+	if pl != nil && pl.GetFormat() == serde.FormatJSON {
+		return pl.GetEncoded(), nil
+	}
+
+	return json.Marshal(packet.GetMessage())
+}
+
+// payload is the message of a packet as it has been received, which is a JSON
+// string of the message encoded in base64.
+//
+// - implements types.Payload
+type payload []byte
+
+// GetFormat implements types.Payload. It returns the JSON format.
+func (pl payload) GetFormat() serde.Format {
+	return serde.FormatJSON
+}
+
+// GetEncoded implements types.Payload. It returns the JSON string.
+func (pl payload) GetEncoded() []byte {
+	return pl
+}
+
+// Decode implements types.Payload. It returns the message decoded from the
+// JSON string, or an error if it is malformed.
+func (pl payload) Decode() ([]byte, error) {
+	var msg []byte
+
+	err := json.Unmarshal(pl, &msg)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to unmarshal message: %v", err)
+	}
+
+	return msg, nil
+}
+
 // HandshakeFormat is the format engine to encode and decode handshake messages.
 //
 // - implements serde.FormatEngine
@@ -2,9 +2,11 @@ package json
 
 import (
 	"testing"
+	"testing/quick"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/internal/testing/gen"
 	"go.dedis.ch/dela/mino/router/tree/types"
 	"go.dedis.ch/dela/serde"
 )
@@ -30,12 +32,19 @@ func TestPacketFormat_Encode(t *testing.T) {
 
 	_, err = fmt.Encode(fake.NewBadContext(), pkt)
 	require.EqualError(t, err, fake.Err("failed to marshal packet"))
+
+	// The payload of a decoded packet is embedded as is.
+	pkt = types.NewPacketWithPayload(fake.NewAddress(0), payload(`"ZGF0YQ=="`), fake.NewAddress(1))
+
+	data, err = fmt.Encode(ctx, pkt)
+	require.NoError(t, err)
+	require.Equal(t, `{"Source":"AAAAAA==","Dest":["AQAAAA=="],"Message":"ZGF0YQ=="}`, string(data))
 }
 
 func TestPacketFormat_Decode(t *testing.T) {
 	fmt := packetFormat{}
 
-	pkt := types.NewPacket(fake.NewAddress(0), []byte{}, fake.NewAddress(1))
+	pkt := types.NewPacketWithPayload(fake.NewAddress(0), payload(`""`), fake.NewAddress(1))
 
 	ctx := fake.NewContext()
 	ctx = serde.WithFactory(ctx, types.AddrKey{}, fake.AddressFactory{})
@@ -43,6 +52,11 @@ func TestPacketFormat_Decode(t *testing.T) {
 	msg, err := fmt.Decode(ctx, []byte(`{"Message":"","Dest":["AQAAAA=="]}`))
 	require.NoError(t, err)
 	require.Equal(t, pkt, msg)
+	require.Equal(t, []byte{}, msg.(*types.Packet).GetMessage())
+
+	msg, err = fmt.Decode(ctx, []byte(`{"Dest":["AQAAAA=="]}`))
+	require.NoError(t, err)
+	require.Equal(t, types.NewPacket(fake.NewAddress(0), nil, fake.NewAddress(1)), msg)
 
 	_, err = fmt.Decode(fake.NewBadContext(), []byte(`{}`))
 	require.EqualError(t, err, fake.Err("failed to unmarshal packet"))
@@ -52,6 +66,59 @@ func TestPacketFormat_Decode(t *testing.T) {
 	require.EqualError(t, err, "invalid address factory '<nil>'")
 }
 
+func TestPayload_Decode(t *testing.T) {
+	pl := payload(`"ZGF0YQ=="`)
+
+	require.Equal(t, serde.FormatJSON, pl.GetFormat())
+	require.Equal(t, []byte(`"ZGF0YQ=="`), pl.GetEncoded())
+
+	msg, err := pl.Decode()
+	require.NoError(t, err)
+	require.Equal(t, []byte("data"), msg)
+
+	_, err = payload(`123`).Decode()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to unmarshal message: ")
+}
+
+func TestPacketFormat_Quick_RoundTrip(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+	fac := types.NewPacketFactory(fake.AddressFactory{})
+
+	f := func(pkt gen.Packet) bool {
+		data, err := pkt.Serialize(ctx)
+		require.NoError(t, err)
+
+		decoded, err := fac.PacketOf(ctx, data)
+		require.NoError(t, err)
+		require.Equal(t, pkt.GetSource(), decoded.GetSource())
+		require.Len(t, decoded.GetDestination(), len(pkt.GetDestination()))
+
+		again, err := decoded.Serialize(ctx)
+		require.NoError(t, err)
+		require.Equal(t, string(data), string(again))
+
+		return true
+	}
+
+	err := quick.Check(f, nil)
+	require.NoError(t, err)
+}
+
+func TestPacketFormat_Quick_Malformed(t *testing.T) {
+	ctx := fake.NewContextWithFormat(serde.FormatJSON)
+	fac := types.NewPacketFactory(fake.AddressFactory{})
+
+	f := func(input gen.Malformed) bool {
+		require.NotPanics(t, func() { fac.PacketOf(ctx, input.Data) })
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 200})
+	require.NoError(t, err)
+}
+
 func TestHandshakeFormat_Encode(t *testing.T) {
 	fmt := hsFormat{}
 
@@ -122,7 +122,7 @@ func (t Table) Forward(packet router.Packet) (router.Routes, router.Voids) {
 
 		p, ok := routes[gateway]
 		if !ok {
-			p = types.NewPacket(packet.GetSource(), packet.GetMessage())
+			p = fork(packet)
 			routes[gateway] = p
 		}
 
@@ -146,3 +146,14 @@ func (t Table) OnFailure(to mino.Address) error {
 
 	return nil
 }
+
+// fork returns a packet without destination for the message of the packet. A
+// tree packet keeps its encoded payload so that it is forwarded as is.
+func fork(packet router.Packet) *types.Packet {
+	pkt, ok := packet.(*types.Packet)
+	if ok {
+		return pkt.Fork()
+	}
+
+	return types.NewPacket(packet.GetSource(), packet.GetMessage())
+}
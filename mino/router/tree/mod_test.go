@@ -9,6 +9,7 @@ import (
 	"go.dedis.ch/dela/mino"
 	minoRouter "go.dedis.ch/dela/mino/router"
 	"go.dedis.ch/dela/mino/router/tree/types"
+	"go.dedis.ch/dela/serde"
 )
 
 func TestRouter_GetPacketFactory(t *testing.T) {
@@ -88,6 +89,28 @@ func TestTable_Forward(t *testing.T) {
 	require.Len(t, routes, 5)
 }
 
+func TestTable_Payload_Forward(t *testing.T) {
+	table := NewTable(3, makeAddrs(20))
+
+	pl := fakePayload{}
+	pkt := types.NewPacketWithPayload(fake.NewAddress(0), pl, makeAddrs(20)...)
+
+	routes, _ := table.Forward(pkt)
+	require.Len(t, routes, 5)
+
+	// The payload is forwarded without being decoded.
+	for _, route := range routes {
+		require.Equal(t, pl, route.(*types.Packet).GetPayload())
+	}
+
+	routes, _ = table.Forward(fakePacket{dest: makeAddrs(20)})
+	require.Len(t, routes, 5)
+	for _, route := range routes {
+		require.Nil(t, route.(*types.Packet).GetPayload())
+		require.Equal(t, []byte{1, 2, 3}, route.GetMessage())
+	}
+}
+
 func TestTable_OnFailure(t *testing.T) {
 	table := NewTable(1, makeAddrs(5))
 	err := table.OnFailure(fake.NewAddress(3))
@@ -110,3 +133,35 @@ func makeAddrs(n int) []mino.Address {
 
 	return addrs
 }
+
+type fakePayload struct{}
+
+func (fakePayload) GetFormat() serde.Format {
+	return fake.GoodFormat
+}
+
+func (fakePayload) GetEncoded() []byte {
+	return nil
+}
+
+func (fakePayload) Decode() ([]byte, error) {
+	return nil, fake.GetError()
+}
+
+type fakePacket struct {
+	minoRouter.Packet
+
+	dest []mino.Address
+}
+
+func (p fakePacket) GetSource() mino.Address {
+	return fake.NewAddress(0)
+}
+
+func (p fakePacket) GetDestination() []mino.Address {
+	return p.dest
+}
+
+func (p fakePacket) GetMessage() []byte {
+	return []byte{1, 2, 3}
+}
@@ -15,13 +15,28 @@ import (
 
 var packetFormat = registry.NewSimpleRegistry()
 
+// Payload is the message of a packet kept in the encoding of the format that
+// has decoded the packet. A relay forwards the payload untouched and only the
+// recipients pay the cost of decoding it.
+type Payload interface {
+	// GetFormat returns the format of the encoding.
+	GetFormat() serde.Format
+
+	// GetEncoded returns the message as encoded by the format.
+	GetEncoded() []byte
+
+	// Decode returns the message.
+	Decode() ([]byte, error)
+}
+
 // Packet describes a tree routing packet
 //
 // - implements router.Packet
 type Packet struct {
-	src  mino.Address
-	dest []mino.Address
-	msg  []byte
+	src     mino.Address
+	dest    []mino.Address
+	msg     []byte
+	payload Payload
 }
 
 // NewPacket creates a new packet.
@@ -33,6 +48,16 @@ func NewPacket(src mino.Address, msg []byte, dest ...mino.Address) *Packet {
 	}
 }
 
+// NewPacketWithPayload creates a new packet with a message that is decoded
+// only when it is read.
+func NewPacketWithPayload(src mino.Address, payload Payload, dest ...mino.Address) *Packet {
+	return &Packet{
+		src:     src,
+		dest:    dest,
+		payload: payload,
+	}
+}
+
 // GetSource implements router.Packet. It returns the source address of the
 // packet.
 func (p *Packet) GetSource() mino.Address {
@@ -46,11 +71,37 @@ func (p *Packet) GetDestination() []mino.Address {
 }
 
 // GetMessage implements router.Packet. It returns the byte buffer of the
-// message.
+// message. A payload that cannot be decoded returns an empty message, which
+// the recipient will fail to deserialize.
 func (p *Packet) GetMessage() []byte {
+	if p.payload != nil {
+		msg, err := p.payload.Decode()
+		if err != nil {
+			return nil
+		}
+
+		return msg
+	}
+
 	return append([]byte{}, p.msg...)
 }
 
+// GetPayload returns the encoded message of the packet if it has been decoded
+// by a format, otherwise it returns nil.
+func (p *Packet) GetPayload() Payload {
+	return p.payload
+}
+
+// Fork returns a packet with the same source and message, but without any
+// destination. The payload is shared so that it is not decoded.
+func (p *Packet) Fork() *Packet {
+	return &Packet{
+		src:     p.src,
+		msg:     p.msg,
+		payload: p.payload,
+	}
+}
+
 // Add appends the address to the destination list, only if it does not exist
 // already.
 func (p *Packet) Add(to mino.Address) {
@@ -82,9 +133,10 @@ func (p *Packet) Slice(addr mino.Address) router.Packet {
 	}
 
 	return &Packet{
-		src:  p.src,
-		dest: []mino.Address{addr},
-		msg:  p.msg,
+		src:     p.src,
+		dest:    []mino.Address{addr},
+		msg:     p.msg,
+		payload: p.payload,
 	}
 }
 
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/dela/internal/testing/fake"
 	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
 )
 
 func init() {
@@ -32,6 +33,29 @@ func TestPacket_GetMessage(t *testing.T) {
 	require.Equal(t, []byte{1, 2, 3}, pkt.GetMessage())
 }
 
+func TestPacket_Payload_GetMessage(t *testing.T) {
+	pkt := NewPacketWithPayload(fake.NewAddress(0), fakePayload{msg: []byte{1, 2, 3}})
+
+	require.Equal(t, []byte{1, 2, 3}, pkt.GetMessage())
+	require.Equal(t, fakePayload{msg: []byte{1, 2, 3}}, pkt.GetPayload())
+
+	pkt = NewPacketWithPayload(fake.NewAddress(0), fakePayload{err: fake.GetError()})
+	require.Nil(t, pkt.GetMessage())
+}
+
+func TestPacket_Fork(t *testing.T) {
+	pl := fakePayload{msg: []byte{0xaa}}
+	pkt := NewPacketWithPayload(fake.NewAddress(0), pl, makeAddrs(3)...)
+
+	fork := pkt.Fork()
+	require.Equal(t, fake.NewAddress(0), fork.GetSource())
+	require.Empty(t, fork.GetDestination())
+	require.Equal(t, pl, fork.GetPayload())
+
+	fork.Add(fake.NewAddress(5))
+	require.Len(t, pkt.GetDestination(), 3)
+}
+
 func TestPacket_Add(t *testing.T) {
 	pkt := NewPacket(fake.NewAddress(0), nil)
 	require.Len(t, pkt.dest, 0)
@@ -57,6 +81,15 @@ func TestPacket_Slice(t *testing.T) {
 	require.Len(t, pkt.dest, 9)
 }
 
+func TestPacket_Payload_Slice(t *testing.T) {
+	pl := fakePayload{msg: []byte{0xaa}}
+	pkt := NewPacketWithPayload(fake.NewAddress(0), pl, makeAddrs(2)...)
+
+	newPkt := pkt.Slice(fake.NewAddress(1))
+	require.Equal(t, pl, newPkt.(*Packet).GetPayload())
+	require.Equal(t, []byte{0xaa}, newPkt.GetMessage())
+}
+
 func TestPacket_Serialize(t *testing.T) {
 	pkt := NewPacket(fake.NewAddress(0), nil)
 
@@ -81,3 +114,23 @@ func TestPacketFactory_Deserialize(t *testing.T) {
 	_, err = fac.Deserialize(fake.NewMsgContext(), nil)
 	require.EqualError(t, err, "invalid packet 'fake.Message'")
 }
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakePayload struct {
+	msg []byte
+	err error
+}
+
+func (pl fakePayload) GetFormat() serde.Format {
+	return fake.GoodFormat
+}
+
+func (pl fakePayload) GetEncoded() []byte {
+	return pl.msg
+}
+
+func (pl fakePayload) Decode() ([]byte, error) {
+	return pl.msg, pl.err
+}
@@ -0,0 +1,96 @@
+package mino
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+)
+
+// WeightedPlayers is a set of players where each of them has a weight, for
+// instance its voting rights.
+type WeightedPlayers interface {
+	Players
+
+	// GetWeight returns the weight of the player at the given index.
+	GetWeight(index int) uint64
+}
+
+// Sample returns a subset of size players drawn at random with the seed, or
+// all the players if there are not enough of them. The players of a
+// WeightedPlayers set are drawn with a probability proportional to their
+// weight, otherwise they are drawn uniformly. The same seed always produces
+// the same subset, so that anyone can reproduce a sampling from the seed.
+func Sample(players Players, seed []byte, size int) Players {
+	indices := SampleIndices(seed, GetWeights(players), size)
+
+	return players.Take(ListFilter(indices))
+}
+
+// GetWeights returns the weight of each of the players, which is one for all
+// of them if the set is not weighted.
+func GetWeights(players Players) []uint64 {
+	weights := make([]uint64, players.Len())
+
+	weighted, ok := players.(WeightedPlayers)
+
+	for i := range weights {
+		weights[i] = 1
+
+		if ok {
+			weights[i] = weighted.GetWeight(i)
+		}
+	}
+
+	return weights
+}
+
+// SampleIndices returns the sorted indices of size elements drawn without
+// replacement with the seed. An element is drawn with a probability
+// proportional to its weight, and an element with a weight of zero is never
+// drawn, which means that fewer indices are returned when not enough elements
+// have a weight.
+//
+// Each draw computes the hash of the seed and the number of the draw, and
+// selects the element that covers the hash modulo the total weight of the
+// elements left. It only uses integers so that the result is the same on every
+// platform.
+func SampleIndices(seed []byte, weights []uint64, size int) []int {
+	var total uint64
+	for _, w := range weights {
+		total += w
+	}
+
+	drawn := make([]bool, len(weights))
+	indices := []int{}
+
+	buffer := make([]byte, 8)
+
+	for draw := 0; draw < size && total > 0; draw++ {
+		binary.LittleEndian.PutUint64(buffer, uint64(draw))
+
+		h := sha256.New()
+		h.Write(seed)
+		h.Write(buffer)
+
+		target := binary.LittleEndian.Uint64(h.Sum(nil)) % total
+
+		for i, w := range weights {
+			if drawn[i] || w == 0 {
+				continue
+			}
+
+			if target < w {
+				drawn[i] = true
+				indices = append(indices, i)
+				total -= w
+				break
+			}
+
+			target -= w
+		}
+	}
+
+	sort.Ints(indices)
+
+	return indices
+}
@@ -0,0 +1,88 @@
+package mino
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSample(t *testing.T) {
+	players := fakePlayers{weights: []uint64{1, 1, 1, 1, 1}}
+
+	subset := Sample(players, []byte{1}, 3)
+	require.Equal(t, 3, subset.Len())
+	require.Equal(t, subset, Sample(players, []byte{1}, 3))
+
+	require.Equal(t, players, Sample(players, []byte{1}, 5))
+	require.Equal(t, players, Sample(players, []byte{1}, 10))
+
+	weighted := fakeWeightedPlayers{fakePlayers: fakePlayers{weights: []uint64{0, 2, 0, 3}}}
+	require.Equal(t, fakePlayers{weights: []uint64{2, 3}}, Sample(weighted, []byte{1}, 4))
+}
+
+func TestGetWeights(t *testing.T) {
+	require.Equal(t, []uint64{1, 1}, GetWeights(fakePlayers{weights: []uint64{2, 3}}))
+
+	weighted := fakeWeightedPlayers{fakePlayers: fakePlayers{weights: []uint64{2, 3}}}
+	require.Equal(t, []uint64{2, 3}, GetWeights(weighted))
+}
+
+func TestSampleIndices(t *testing.T) {
+	weights := []uint64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+
+	indices := SampleIndices([]byte{1}, weights, 4)
+	require.Len(t, indices, 4)
+	require.IsIncreasing(t, indices)
+	require.Equal(t, indices, SampleIndices([]byte{1}, weights, 4))
+	require.NotEqual(t, indices, SampleIndices([]byte{2}, weights, 4))
+
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, SampleIndices([]byte{1}, weights, 20))
+	require.Equal(t, []int{}, SampleIndices([]byte{1}, weights, 0))
+	require.Equal(t, []int{}, SampleIndices([]byte{1}, nil, 3))
+
+	// The elements without weight are never drawn.
+	require.Equal(t, []int{1, 3}, SampleIndices([]byte{1}, []uint64{0, 1, 0, 1}, 3))
+
+	// The draws are proportional to the weights.
+	counts := make([]int, 2)
+	for i := 0; i < 1000; i++ {
+		seed := []byte{byte(i), byte(i >> 8)}
+
+		counts[SampleIndices(seed, []uint64{1, 9}, 1)[0]]++
+	}
+
+	require.Less(t, counts[0], 200)
+	require.Greater(t, counts[1], 800)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakePlayers struct {
+	Players
+
+	weights []uint64
+}
+
+func (p fakePlayers) Take(updaters ...FilterUpdater) Players {
+	filter := ApplyFilters(updaters)
+
+	weights := make([]uint64, len(filter.Indices))
+	for i, k := range filter.Indices {
+		weights[i] = p.weights[k]
+	}
+
+	return fakePlayers{weights: weights}
+}
+
+func (p fakePlayers) Len() int {
+	return len(p.weights)
+}
+
+type fakeWeightedPlayers struct {
+	fakePlayers
+}
+
+func (p fakeWeightedPlayers) GetWeight(index int) uint64 {
+	return p.weights[index]
+}
@@ -0,0 +1,139 @@
+package mino
+
+import (
+	"context"
+
+	"go.dedis.ch/dela"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+// Scope returns true when the address is allowed to contact a handler.
+type Scope func(addr Address) bool
+
+// PlayersScope returns a scope that only accepts the addresses of the players.
+func PlayersScope(players Players) Scope {
+	return func(addr Address) bool {
+		iter := players.AddressIterator()
+		for iter.HasNext() {
+			if addr.Equal(iter.GetNext()) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// DynamicScope returns a scope that accepts the addresses of the players
+// returned by the function at the time of the check, like the current roster
+// of a chain.
+func DynamicScope(fn func() (Players, error)) Scope {
+	return func(addr Address) bool {
+		players, err := fn()
+		if err != nil {
+			dela.Logger.Warn().Err(err).Msg("failed to get the players of the scope")
+			return false
+		}
+
+		return PlayersScope(players)(addr)
+	}
+}
+
+// ScopedHandler is a handler that only accepts the messages coming from the
+// addresses of its scope. The overlay enforces the scope before the handler is
+// invoked, so that the handler doesn't need to check the senders itself.
+//
+// - implements mino.Handler
+type ScopedHandler struct {
+	Handler
+
+	scope Scope
+}
+
+// NewScopedHandler returns a handler that only accepts the messages from the
+// scope.
+func NewScopedHandler(h Handler, scope Scope) ScopedHandler {
+	return ScopedHandler{
+		Handler: h,
+		scope:   scope,
+	}
+}
+
+// Accept returns true if the address is in the scope of the handler.
+func (h ScopedHandler) Accept(addr Address) bool {
+	return addr != nil && h.scope(addr)
+}
+
+// CheckScope returns an error if the handler is scoped and the address is not
+// part of the scope, otherwise it returns nil. It is used by the overlays
+// before a request is processed.
+func CheckScope(h Handler, from Address) error {
+	scoped, ok := scopeOf(h)
+	if !ok || scoped.Accept(from) {
+		return nil
+	}
+
+	return xerrors.Errorf("address %v is out of the scope of the handler", from)
+}
+
+// ScopeReceiver returns a receiver that drops the messages coming from
+// addresses outside of the scope of the handler, if it is scoped. It is used by
+// the overlays before a stream is handled.
+func ScopeReceiver(h Handler, in Receiver) Receiver {
+	scoped, ok := scopeOf(h)
+	if !ok {
+		return in
+	}
+
+	return scopedReceiver{
+		Receiver: in,
+		handler:  scoped,
+	}
+}
+
+// scopeOf returns the scoped handler, if any, including when it is wrapped by
+// a classified, a compressed or a policy handler.
+func scopeOf(h Handler) (ScopedHandler, bool) {
+	switch handler := h.(type) {
+	case ScopedHandler:
+		return handler, true
+	case ClassifiedHandler:
+		return scopeOf(handler.Handler)
+	case CompressedHandler:
+		return scopeOf(handler.Handler)
+	case PolicyHandler:
+		return scopeOf(handler.Handler)
+	default:
+		return ScopedHandler{}, false
+	}
+}
+
+// scopedReceiver is a receiver that filters the messages according to the
+// scope of a handler.
+//
+// - implements mino.Receiver
+type scopedReceiver struct {
+	Receiver
+
+	handler ScopedHandler
+}
+
+// Recv implements mino.Receiver. It returns the next message coming from an
+// address of the scope.
+func (r scopedReceiver) Recv(ctx context.Context) (Address, serde.Message, error) {
+	for {
+		from, msg, err := r.Receiver.Recv(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if r.handler.Accept(from) {
+			return from, msg, nil
+		}
+
+		dela.Logger.Warn().
+			Stringer("from", from).
+			Msg("message out of the scope of the handler is dropped")
+	}
+}
@@ -0,0 +1,112 @@
+package mino
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func TestPlayersScope(t *testing.T) {
+	scope := PlayersScope(NewAddresses(scopeAddr{id: "A"}, scopeAddr{id: "B"}))
+
+	require.True(t, scope(scopeAddr{id: "A"}))
+	require.True(t, scope(scopeAddr{id: "B"}))
+	require.False(t, scope(scopeAddr{id: "C"}))
+}
+
+func TestDynamicScope(t *testing.T) {
+	players := NewAddresses(scopeAddr{id: "A"})
+
+	scope := DynamicScope(func() (Players, error) { return players, nil })
+	require.True(t, scope(scopeAddr{id: "A"}))
+
+	players = NewAddresses(scopeAddr{id: "B"})
+	require.False(t, scope(scopeAddr{id: "A"}))
+
+	scope = DynamicScope(func() (Players, error) { return nil, xerrors.New("oops") })
+	require.False(t, scope(scopeAddr{id: "A"}))
+}
+
+func TestScopedHandler_Accept(t *testing.T) {
+	h := NewScopedHandler(UnsupportedHandler{}, PlayersScope(NewAddresses(scopeAddr{id: "A"})))
+
+	require.True(t, h.Accept(scopeAddr{id: "A"}))
+	require.False(t, h.Accept(scopeAddr{id: "B"}))
+	require.False(t, h.Accept(nil))
+}
+
+func TestCheckScope(t *testing.T) {
+	h := NewScopedHandler(UnsupportedHandler{}, PlayersScope(NewAddresses(scopeAddr{id: "A"})))
+
+	require.NoError(t, CheckScope(h, scopeAddr{id: "A"}))
+	require.NoError(t, CheckScope(UnsupportedHandler{}, scopeAddr{id: "B"}))
+
+	err := CheckScope(h, scopeAddr{id: "B"})
+	require.EqualError(t, err, "address B is out of the scope of the handler")
+
+	err = CheckScope(NewClassifiedHandler(h, ClassBulk), scopeAddr{id: "B"})
+	require.EqualError(t, err, "address B is out of the scope of the handler")
+}
+
+func TestScopeReceiver_Recv(t *testing.T) {
+	in := &fakeReceiver{
+		msgs: []fakeRecvMsg{
+			{from: scopeAddr{id: "B"}},
+			{from: scopeAddr{id: "A"}},
+		},
+	}
+
+	require.Equal(t, in, ScopeReceiver(UnsupportedHandler{}, in))
+
+	h := NewScopedHandler(UnsupportedHandler{}, PlayersScope(NewAddresses(scopeAddr{id: "A"})))
+
+	r := ScopeReceiver(h, in)
+
+	from, _, err := r.Recv(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, scopeAddr{id: "A"}, from)
+
+	_, _, err = r.Recv(context.Background())
+	require.EqualError(t, err, "no more messages")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type scopeAddr struct {
+	Address
+
+	id string
+}
+
+func (a scopeAddr) Equal(other Address) bool {
+	addr, ok := other.(scopeAddr)
+	return ok && addr.id == a.id
+}
+
+func (a scopeAddr) String() string {
+	return a.id
+}
+
+type fakeRecvMsg struct {
+	from Address
+	msg  serde.Message
+}
+
+type fakeReceiver struct {
+	msgs []fakeRecvMsg
+}
+
+func (r *fakeReceiver) Recv(context.Context) (Address, serde.Message, error) {
+	if len(r.msgs) == 0 {
+		return nil, nil, xerrors.New("no more messages")
+	}
+
+	next := r.msgs[0]
+	r.msgs = r.msgs[1:]
+
+	return next.from, next.msg, nil
+}
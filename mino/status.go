@@ -0,0 +1,48 @@
+package mino
+
+import "time"
+
+// MaxMissedHeartbeats is the number of consecutive heartbeats that a peer can
+// miss before it is considered unresponsive.
+const MaxMissedHeartbeats = 2
+
+// Liveness is the state of a peer observed by the heartbeats of the overlay.
+type Liveness struct {
+	Address Address
+
+	// RTT is the round-trip time of the latest heartbeat answered by the peer.
+	RTT time.Duration
+
+	// LastSeen is the time of the latest heartbeat answered by the peer, or
+	// the zero value if it has never answered.
+	LastSeen time.Time
+
+	// Missed is the number of consecutive heartbeats that the peer has not
+	// answered.
+	Missed int
+}
+
+// IsResponsive returns true if the peer has answered one of the latest
+// heartbeats.
+func (l Liveness) IsResponsive() bool {
+	return l.Missed < MaxMissedHeartbeats
+}
+
+// PeerStatus is the interface to read the liveness of the peers of the
+// overlay.
+type PeerStatus interface {
+	// GetPeerStatus returns the liveness of the peer and true, or false if the
+	// peer is not monitored.
+	GetPeerStatus(addr Address) (Liveness, bool)
+}
+
+// IsResponsive returns true if the peer is responsive according to the status,
+// or if the status doesn't monitor the peer.
+func IsResponsive(status PeerStatus, addr Address) bool {
+	l, found := status.GetPeerStatus(addr)
+	if !found {
+		return true
+	}
+
+	return l.IsResponsive()
+}
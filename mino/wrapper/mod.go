@@ -0,0 +1,155 @@
+// Package wrapper implements the skeleton shared by the RPCs that wrap the
+// messages of another RPC in an envelope of their own, like the ordered, the
+// reliable, the batched or the multiplexed streams.
+//
+// The requests and the replies of a call are wrapped and unwrapped by a codec,
+// while the sender and the receiver of a stream are wrapped by a function
+// specific to the protocol, on both the side opening the stream and the side
+// handling it.
+package wrapper
+
+import (
+	"context"
+
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+// Codec wraps the messages of a call in the envelope of a protocol, and
+// unwraps them on the other side.
+type Codec interface {
+	// Wrap returns the message in an envelope.
+	Wrap(msg serde.Message) serde.Message
+
+	// Unwrap returns the message of the envelope, or the message itself when
+	// it is not wrapped.
+	Unwrap(msg serde.Message) serde.Message
+}
+
+// StreamFunc is the function that wraps the sender and the receiver of a
+// stream.
+type StreamFunc func(ctx context.Context,
+	out mino.Sender, in mino.Receiver) (mino.Sender, mino.Receiver)
+
+// RPC is a wrapper around an RPC that wraps the messages of the calls with the
+// codec and the streams with the function.
+//
+// - implements mino.RPC
+type RPC struct {
+	rpc    mino.RPC
+	codec  Codec
+	stream StreamFunc
+}
+
+// NewRPC returns a new RPC wrapping the one given in parameter.
+func NewRPC(rpc mino.RPC, codec Codec, fn StreamFunc) RPC {
+	return RPC{
+		rpc:    rpc,
+		codec:  codec,
+		stream: fn,
+	}
+}
+
+// Call implements mino.RPC. It sends the wrapped request and unwraps the
+// replies.
+func (rpc RPC) Call(ctx context.Context,
+	req serde.Message, players mino.Players) (<-chan mino.Response, error) {
+
+	return Call(ctx, rpc.rpc, rpc.codec, req, players)
+}
+
+// Stream implements mino.RPC. It opens a stream and wraps its sender and its
+// receiver.
+func (rpc RPC) Stream(ctx context.Context,
+	players mino.Players) (mino.Sender, mino.Receiver, error) {
+
+	out, in, err := rpc.rpc.Stream(ctx, players)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("stream failed: %v", err)
+	}
+
+	out, in = rpc.stream(ctx, out, in)
+
+	return out, in, nil
+}
+
+// Handler is a wrapper around a handler that unwraps the requests and wraps
+// the replies with the codec, and wraps the streams with the function.
+//
+// - implements mino.Handler
+type Handler struct {
+	mino.Handler
+
+	codec  Codec
+	stream StreamFunc
+}
+
+// NewHandler returns a new handler wrapping the one given in parameter.
+func NewHandler(h mino.Handler, codec Codec, fn StreamFunc) Handler {
+	return Handler{
+		Handler: h,
+		codec:   codec,
+		stream:  fn,
+	}
+}
+
+// Process implements mino.Handler. It unwraps the request and wraps the reply.
+func (h Handler) Process(req mino.Request) (serde.Message, error) {
+	return Process(h.Handler, h.codec, req)
+}
+
+// Stream implements mino.Handler. It calls the handler with the wrapped sender
+// and receiver. The context of the wrappers is never done as the stream ends
+// when the receiver is closed.
+func (h Handler) Stream(out mino.Sender, in mino.Receiver) error {
+	out, in = h.stream(context.Background(), out, in)
+
+	return h.Handler.Stream(out, in)
+}
+
+// Call sends the request wrapped by the codec to the players and returns the
+// unwrapped replies.
+func Call(ctx context.Context, rpc mino.RPC, codec Codec,
+	req serde.Message, players mino.Players) (<-chan mino.Response, error) {
+
+	resps, err := rpc.Call(ctx, codec.Wrap(req), players)
+	if err != nil {
+		return nil, xerrors.Errorf("call failed: %v", err)
+	}
+
+	out := make(chan mino.Response, players.Len())
+
+	go func() {
+		defer close(out)
+
+		for resp := range resps {
+			msg, err := resp.GetMessageOrError()
+			if err != nil {
+				out <- resp
+				continue
+			}
+
+			out <- mino.NewResponse(resp.GetFrom(), codec.Unwrap(msg))
+		}
+	}()
+
+	return out, nil
+}
+
+// Process unwraps the request with the codec, processes it with the handler
+// and returns the wrapped reply, if any.
+func Process(h mino.Handler, codec Codec, req mino.Request) (serde.Message, error) {
+	req.Message = codec.Unwrap(req.Message)
+
+	resp, err := h.Process(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp == nil {
+		return nil, nil
+	}
+
+	return codec.Wrap(resp), nil
+}
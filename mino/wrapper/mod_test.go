@@ -0,0 +1,139 @@
+package wrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestRPC_Call(t *testing.T) {
+	rpc := fake.NewRPC()
+	rpc.SendResponse(fake.NewAddress(0), envelope{msg: fake.Message{}})
+	rpc.SendResponseWithError(fake.NewAddress(1), fake.GetError())
+	rpc.Done()
+
+	players := fake.NewAuthority(2, fake.NewSigner)
+
+	resps, err := NewRPC(rpc, fakeCodec{}, nil).Call(context.Background(), fake.Message{}, players)
+	require.NoError(t, err)
+	require.Equal(t, envelope{msg: fake.Message{}}, rpc.Calls.Get(0, 1))
+
+	resp := <-resps
+	msg, err := resp.GetMessageOrError()
+	require.NoError(t, err)
+	require.Equal(t, fake.Message{}, msg)
+
+	resp = <-resps
+	_, err = resp.GetMessageOrError()
+	require.Equal(t, fake.GetError(), err)
+
+	_, more := <-resps
+	require.False(t, more)
+
+	rpc2 := NewRPC(fake.NewBadRPC(), fakeCodec{}, nil)
+	_, err = rpc2.Call(context.Background(), fake.Message{}, players)
+	require.EqualError(t, err, fake.Err("call failed"))
+}
+
+func TestRPC_Stream(t *testing.T) {
+	rpc := fake.NewStreamRPC(fake.NewReceiver(), fake.Sender{})
+
+	s, r, err := NewRPC(rpc, fakeCodec{}, fakeStream).Stream(context.Background(), nil)
+	require.NoError(t, err)
+	require.IsType(t, wrappedSender{}, s)
+	require.IsType(t, wrappedReceiver{}, r)
+
+	_, _, err = NewRPC(fake.NewBadRPC(), fakeCodec{}, fakeStream).Stream(context.Background(), nil)
+	require.EqualError(t, err, fake.Err("stream failed"))
+}
+
+func TestHandler_Process(t *testing.T) {
+	h := NewHandler(fakeHandler{resp: fake.Message{}}, fakeCodec{}, nil)
+
+	resp, err := h.Process(mino.Request{Message: envelope{msg: fake.Message{}}})
+	require.NoError(t, err)
+	require.Equal(t, envelope{msg: fake.Message{}}, resp)
+
+	h = NewHandler(fakeHandler{}, fakeCodec{}, nil)
+	resp, err = h.Process(mino.Request{Message: fake.Message{}})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	h = NewHandler(fakeHandler{err: fake.GetError()}, fakeCodec{}, nil)
+	_, err = h.Process(mino.Request{})
+	require.Equal(t, fake.GetError(), err)
+}
+
+func TestHandler_Stream(t *testing.T) {
+	h := NewHandler(fakeHandler{}, fakeCodec{}, fakeStream)
+
+	err := h.Stream(fake.Sender{}, fake.NewReceiver())
+	require.NoError(t, err)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type envelope struct {
+	fake.Message
+
+	msg serde.Message
+}
+
+type fakeCodec struct{}
+
+func (fakeCodec) Wrap(msg serde.Message) serde.Message {
+	return envelope{msg: msg}
+}
+
+func (fakeCodec) Unwrap(msg serde.Message) serde.Message {
+	env, ok := msg.(envelope)
+	if ok {
+		return env.msg
+	}
+
+	return msg
+}
+
+type wrappedSender struct {
+	mino.Sender
+}
+
+type wrappedReceiver struct {
+	mino.Receiver
+}
+
+func fakeStream(ctx context.Context,
+	out mino.Sender, in mino.Receiver) (mino.Sender, mino.Receiver) {
+
+	return wrappedSender{Sender: out}, wrappedReceiver{Receiver: in}
+}
+
+type fakeHandler struct {
+	mino.UnsupportedHandler
+
+	resp serde.Message
+	err  error
+}
+
+func (h fakeHandler) Process(req mino.Request) (serde.Message, error) {
+	return h.resp, h.err
+}
+
+func (h fakeHandler) Stream(out mino.Sender, in mino.Receiver) error {
+	_, ok := out.(wrappedSender)
+	if !ok {
+		return fake.GetError()
+	}
+
+	_, ok = in.(wrappedReceiver)
+	if !ok {
+		return fake.GetError()
+	}
+
+	return nil
+}
@@ -0,0 +1,234 @@
+package serde
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// Algorithm is the identifier of a compression algorithm. It is written in the
+// header of a compressed payload so that the reader knows how to decompress it.
+type Algorithm byte
+
+const (
+	// AlgorithmFlate is the identifier of the DEFLATE compression (RFC 1951).
+	AlgorithmFlate Algorithm = 1
+)
+
+// CompressionThreshold is the minimum size in bytes of a payload to be
+// compressed, as the smaller ones would barely shrink.
+const CompressionThreshold = 512
+
+// compressionMarker is the first byte of the header of a compressed payload. A
+// payload of the JSON, XML or Protocol Buffers formats never starts with it,
+// which means that a compressed payload is distinguished from a plain one.
+const compressionMarker = 0x00
+
+// Compressor is the interface to implement a compression algorithm.
+type Compressor interface {
+	// GetAlgorithm returns the identifier of the algorithm.
+	GetAlgorithm() Algorithm
+
+	// NewWriter returns a writer that compresses the data into the writer. The
+	// writer must be closed to flush the data.
+	NewWriter(w io.Writer) io.WriteCloser
+
+	// NewReader returns a reader of the data decompressed from the reader.
+	NewReader(r io.Reader) io.ReadCloser
+}
+
+var compressors = struct {
+	sync.Mutex
+	algorithms map[Algorithm]Compressor
+}{
+	algorithms: map[Algorithm]Compressor{
+		AlgorithmFlate: FlateCompressor{},
+	},
+}
+
+// RegisterCompressor registers the compressor so that the payloads compressed
+// with its algorithm can be decompressed.
+func RegisterCompressor(c Compressor) {
+	compressors.Lock()
+	compressors.algorithms[c.GetAlgorithm()] = c
+	compressors.Unlock()
+}
+
+// GetAlgorithms returns the sorted identifiers of the algorithms that can be
+// decompressed.
+func GetAlgorithms() []Algorithm {
+	compressors.Lock()
+	defer compressors.Unlock()
+
+	algos := make([]Algorithm, 0, len(compressors.algorithms))
+	for algo := range compressors.algorithms {
+		algos = append(algos, algo)
+	}
+
+	sort.Slice(algos, func(i, j int) bool { return algos[i] < algos[j] })
+
+	return algos
+}
+
+func getCompressor(algo Algorithm) Compressor {
+	compressors.Lock()
+	defer compressors.Unlock()
+
+	return compressors.algorithms[algo]
+}
+
+// WithCompression returns a context that compresses the payloads with the
+// compressor. The decompression doesn't depend on the context, as the
+// algorithm is read from the header of the payload.
+func WithCompression(ctx Context, c Compressor) Context {
+	ctx.compressor = c
+
+	return ctx
+}
+
+// GetCompressor returns the compressor of the context, or nil if the payloads
+// are not compressed.
+func (ctx Context) GetCompressor() Compressor {
+	return ctx.compressor
+}
+
+// Compress returns the data compressed with the compressor of the context,
+// prefixed by a header with the algorithm. The data is returned as is when the
+// context has no compressor, when it is smaller than the threshold, or when
+// the compression doesn't reduce its size.
+func Compress(ctx Context, data []byte) ([]byte, error) {
+	if ctx.compressor == nil || len(data) < CompressionThreshold {
+		return data, nil
+	}
+
+	buffer := bytes.NewBuffer([]byte{compressionMarker, byte(ctx.compressor.GetAlgorithm())})
+
+	w := ctx.compressor.NewWriter(buffer)
+
+	_, err := w.Write(data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to write: %v", err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to close: %v", err)
+	}
+
+	if buffer.Len() >= len(data) {
+		return data, nil
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Decompress returns the data decompressed with the algorithm of its header,
+// or the data as is when it is not compressed. The decompressed data is bounded
+// by the maximum size of the limits of the context, so that a small payload
+// cannot expand to a huge allocation.
+func Decompress(ctx Context, data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] != compressionMarker {
+		return data, nil
+	}
+
+	if len(data) < 2 {
+		return nil, xerrors.New("missing algorithm")
+	}
+
+	c := getCompressor(Algorithm(data[1]))
+	if c == nil {
+		return nil, xerrors.Errorf("unknown algorithm %d", data[1])
+	}
+
+	r := c.NewReader(bytes.NewReader(data[2:]))
+	defer r.Close()
+
+	res, err := readAll(r, ctx.GetLimits())
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read: %w", err)
+	}
+
+	return res, nil
+}
+
+// compressWriter returns a writer that compresses the stream after the header
+// of the algorithm of the context. It returns the writer itself when the
+// context has no compressor.
+func compressWriter(ctx Context, w io.Writer) (io.WriteCloser, error) {
+	if ctx.compressor == nil {
+		return nopWriteCloser{Writer: w}, nil
+	}
+
+	_, err := w.Write([]byte{compressionMarker, byte(ctx.compressor.GetAlgorithm())})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to write header: %v", err)
+	}
+
+	return ctx.compressor.NewWriter(w), nil
+}
+
+// decompressReader returns a reader that decompresses the stream according to
+// its header, or the reader itself when the context has no compressor. A
+// stream is not self-describing like a payload, therefore both sides must
+// agree on the compression.
+func decompressReader(ctx Context, r io.Reader) (io.ReadCloser, error) {
+	if ctx.compressor == nil {
+		return ioutil.NopCloser(r), nil
+	}
+
+	header := make([]byte, 2)
+
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read header: %v", err)
+	}
+
+	if header[0] != compressionMarker {
+		return nil, xerrors.Errorf("invalid header marker %d", header[0])
+	}
+
+	c := getCompressor(Algorithm(header[1]))
+	if c == nil {
+		return nil, xerrors.Errorf("unknown algorithm %d", header[1])
+	}
+
+	return c.NewReader(r), nil
+}
+
+// FlateCompressor is a compressor that uses the DEFLATE algorithm.
+//
+// - implements serde.Compressor
+type FlateCompressor struct{}
+
+// GetAlgorithm implements serde.Compressor. It returns the identifier of the
+// DEFLATE algorithm.
+func (FlateCompressor) GetAlgorithm() Algorithm {
+	return AlgorithmFlate
+}
+
+// NewWriter implements serde.Compressor. It returns a DEFLATE writer with the
+// default level of compression.
+func (FlateCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	// The error only happens with an invalid level.
+	fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+
+	return fw
+}
+
+// NewReader implements serde.Compressor. It returns a DEFLATE reader.
+func (FlateCompressor) NewReader(r io.Reader) io.ReadCloser {
+	return flate.NewReader(r)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
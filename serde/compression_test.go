@@ -0,0 +1,196 @@
+package serde
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterCompressor(t *testing.T) {
+	RegisterCompressor(fakeCompressor{algo: 0xff})
+	defer func() {
+		compressors.Lock()
+		delete(compressors.algorithms, 0xff)
+		compressors.Unlock()
+	}()
+
+	require.Equal(t, fakeCompressor{algo: 0xff}, getCompressor(0xff))
+	require.Equal(t, []Algorithm{AlgorithmFlate, 0xff}, GetAlgorithms())
+	require.Equal(t, FlateCompressor{}, getCompressor(AlgorithmFlate))
+	require.Nil(t, getCompressor(0xfe))
+}
+
+func TestWithCompression(t *testing.T) {
+	ctx := NewContext(fakeEngine{})
+	require.Nil(t, ctx.GetCompressor())
+
+	ctx2 := WithCompression(ctx, FlateCompressor{})
+	require.Nil(t, ctx.GetCompressor())
+	require.Equal(t, FlateCompressor{}, ctx2.GetCompressor())
+}
+
+func TestCompress(t *testing.T) {
+	ctx := WithCompression(NewContext(fakeEngine{}), FlateCompressor{})
+
+	data := []byte(strings.Repeat(`{"A":"B"}`, 100))
+
+	compressed, err := Compress(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, byte(AlgorithmFlate)}, compressed[:2])
+	require.Less(t, len(compressed), len(data))
+
+	res, err := Decompress(ctx, compressed)
+	require.NoError(t, err)
+	require.Equal(t, data, res)
+
+	// The small payloads and the ones that don't shrink are left as is.
+	res, err = Compress(ctx, []byte(`{}`))
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{}`), res)
+
+	random := make([]byte, CompressionThreshold)
+	_, err = rand.Read(random)
+	require.NoError(t, err)
+
+	res, err = Compress(ctx, random)
+	require.NoError(t, err)
+	require.Equal(t, random, res)
+
+	res, err = Compress(NewContext(fakeEngine{}), data)
+	require.NoError(t, err)
+	require.Equal(t, data, res)
+
+	ctx = WithCompression(ctx, fakeCompressor{errWrite: errors.New("oops")})
+	_, err = Compress(ctx, data)
+	require.EqualError(t, err, "failed to write: oops")
+
+	ctx = WithCompression(ctx, fakeCompressor{errClose: errors.New("oops")})
+	_, err = Compress(ctx, data)
+	require.EqualError(t, err, "failed to close: oops")
+}
+
+func TestDecompress(t *testing.T) {
+	ctx := NewContext(fakeEngine{})
+
+	res, err := Decompress(ctx, []byte(`{}`))
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{}`), res)
+
+	res, err = Decompress(ctx, nil)
+	require.NoError(t, err)
+	require.Nil(t, res)
+
+	_, err = Decompress(ctx, []byte{0})
+	require.EqualError(t, err, "missing algorithm")
+
+	_, err = Decompress(ctx, []byte{0, 0xfe})
+	require.EqualError(t, err, "unknown algorithm 254")
+
+	_, err = Decompress(ctx, []byte{0, byte(AlgorithmFlate), 0xff})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read: ")
+}
+
+func TestDecompress_Limits(t *testing.T) {
+	data := make([]byte, 1<<20)
+
+	compressed, err := Compress(WithCompression(NewContext(fakeEngine{}), FlateCompressor{}), data)
+	require.NoError(t, err)
+	require.Less(t, len(compressed), 4096)
+
+	ctx := NewContext(fakeLimitedEngine{limits: Limits{MaxSize: len(data)}})
+
+	res, err := Decompress(ctx, compressed)
+	require.NoError(t, err)
+	require.Equal(t, data, res)
+
+	// A small payload that expands beyond the maximum size is refused.
+	ctx = NewContext(fakeLimitedEngine{limits: Limits{MaxSize: len(data) - 1}})
+
+	_, err = Decompress(ctx, compressed)
+	require.True(t, errors.Is(err, ErrLimitExceeded))
+	require.EqualError(t, err, "failed to read: size exceeds 1048575: limit exceeded")
+}
+
+func TestCompression_Stream(t *testing.T) {
+	ctx := WithCompression(NewContext(fakeEngine{}), FlateCompressor{})
+
+	msg := fakeStreamMessage{values: make([]int, 1000)}
+
+	buffer := new(bytes.Buffer)
+	require.NoError(t, Encode(ctx, msg, buffer))
+	require.Equal(t, []byte{0, byte(AlgorithmFlate)}, buffer.Bytes()[:2])
+	require.Less(t, buffer.Len(), 1000)
+
+	res, err := Decode(ctx, fakeStreamFactory{}, buffer)
+	require.NoError(t, err)
+	require.Equal(t, msg, res)
+
+	buffer.Reset()
+	require.NoError(t, Encode(ctx, fakeMessage{}, buffer))
+
+	res, err = Decode(ctx, fakeMessageFactory{}, buffer)
+	require.NoError(t, err)
+	require.Equal(t, fakeMessage{}, res)
+
+	err = Encode(ctx, fakeMessage{}, badWriter{})
+	require.EqualError(t, err, "failed to compress: failed to write header: oops")
+
+	err = Encode(WithCompression(ctx, fakeCompressor{errClose: errors.New("oops")}), fakeMessage{}, buffer)
+	require.EqualError(t, err, "failed to flush: oops")
+
+	_, err = Decode(ctx, fakeMessageFactory{}, bytes.NewBuffer([]byte{0}))
+	require.EqualError(t, err, "failed to decompress: failed to read header: unexpected EOF")
+
+	_, err = Decode(ctx, fakeMessageFactory{}, bytes.NewBufferString("message"))
+	require.EqualError(t, err, "failed to decompress: invalid header marker 109")
+
+	_, err = Decode(ctx, fakeMessageFactory{}, bytes.NewBuffer([]byte{0, 0xfe}))
+	require.EqualError(t, err, "failed to decompress: unknown algorithm 254")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeCompressor struct {
+	algo     Algorithm
+	errWrite error
+	errClose error
+}
+
+func (c fakeCompressor) GetAlgorithm() Algorithm {
+	return c.algo
+}
+
+func (c fakeCompressor) NewWriter(w io.Writer) io.WriteCloser {
+	return fakeWriteCloser{Writer: w, errWrite: c.errWrite, errClose: c.errClose}
+}
+
+func (c fakeCompressor) NewReader(r io.Reader) io.ReadCloser {
+	return ioutil.NopCloser(r)
+}
+
+type fakeWriteCloser struct {
+	io.Writer
+
+	errWrite error
+	errClose error
+}
+
+func (w fakeWriteCloser) Write(data []byte) (int, error) {
+	if w.errWrite != nil {
+		return 0, w.errWrite
+	}
+
+	return w.Writer.Write(data)
+}
+
+func (w fakeWriteCloser) Close() error {
+	return w.errClose
+}
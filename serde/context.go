@@ -22,7 +22,9 @@ type ContextEngine interface {
 type Context struct {
 	ContextEngine
 
-	factories map[interface{}]Factory
+	factories  map[interface{}]Factory
+	compressor Compressor
+	lenient    bool
 }
 
 // NewContext returns a new empty context.
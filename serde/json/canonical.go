@@ -0,0 +1,294 @@
+// This file contains the canonicalization of a JSON document according to the
+// JSON Canonicalization Scheme (RFC 8785).
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// Canonicalize returns the canonical form of the JSON document according to
+// RFC 8785, so that two documents with the same content produce the same
+// bytes. The members of the objects are sorted by the UTF-16 code units of
+// their name, the whitespaces are removed, and the strings and the numbers are
+// written like ECMAScript does. A document with a duplicated name in an object,
+// with a number out of the range of a double, or with malformed UTF-8 is
+// refused.
+func Canonicalize(data []byte) ([]byte, error) {
+	// The decoder would otherwise replace the invalid sequences silently.
+	if !utf8.Valid(data) {
+		return nil, xerrors.New("invalid document: malformed UTF-8")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	buffer := new(bytes.Buffer)
+
+	err := writeValue(dec, buffer)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid document: %v", err)
+	}
+
+	_, err = dec.Token()
+	if err != io.EOF {
+		return nil, xerrors.New("invalid document: unexpected data after the value")
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func writeValue(dec *json.Decoder, buffer *bytes.Buffer) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '{' {
+			return writeObject(dec, buffer)
+		}
+
+		return writeArray(dec, buffer)
+	case string:
+		writeString(buffer, value)
+	case json.Number:
+		num, err := formatNumber(value)
+		if err != nil {
+			return err
+		}
+
+		buffer.WriteString(num)
+	case bool:
+		buffer.WriteString(strconv.FormatBool(value))
+	default:
+		buffer.WriteString("null")
+	}
+
+	return nil
+}
+
+type member struct {
+	name  string
+	units []uint16
+	value []byte
+}
+
+func writeObject(dec *json.Decoder, buffer *bytes.Buffer) error {
+	members := []member{}
+	names := make(map[string]struct{})
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		// The decoder makes sure that a name is a string.
+		name := token.(string)
+
+		_, found := names[name]
+		if found {
+			return xerrors.Errorf("duplicated name '%s'", name)
+		}
+
+		names[name] = struct{}{}
+
+		value := new(bytes.Buffer)
+
+		err = writeValue(dec, value)
+		if err != nil {
+			return err
+		}
+
+		members = append(members, member{
+			name:  name,
+			units: utf16.Encode([]rune(name)),
+			value: value.Bytes(),
+		})
+	}
+
+	// Consume the closing delimiter.
+	_, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return lessUnits(members[i].units, members[j].units)
+	})
+
+	buffer.WriteByte('{')
+
+	for i, m := range members {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		writeString(buffer, m.name)
+		buffer.WriteByte(':')
+		buffer.Write(m.value)
+	}
+
+	buffer.WriteByte('}')
+
+	return nil
+}
+
+func writeArray(dec *json.Decoder, buffer *bytes.Buffer) error {
+	buffer.WriteByte('[')
+
+	for i := 0; dec.More(); i++ {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		err := writeValue(dec, buffer)
+		if err != nil {
+			return err
+		}
+	}
+
+	buffer.WriteByte(']')
+
+	// Consume the closing delimiter.
+	_, err := dec.Token()
+
+	return err
+}
+
+// writeString writes the string with only the escapes required by JSON. The
+// control characters without a short escape are written with lowercase
+// hexadecimal digits.
+func writeString(buffer *bytes.Buffer, str string) {
+	buffer.WriteByte('"')
+
+	for _, r := range str {
+		switch r {
+		case '"':
+			buffer.WriteString(`\"`)
+		case '\\':
+			buffer.WriteString(`\\`)
+		case '\b':
+			buffer.WriteString(`\b`)
+		case '\f':
+			buffer.WriteString(`\f`)
+		case '\n':
+			buffer.WriteString(`\n`)
+		case '\r':
+			buffer.WriteString(`\r`)
+		case '\t':
+			buffer.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				buffer.WriteString(`\u00`)
+				buffer.WriteString(strconv.FormatInt(int64(r>>4), 16))
+				buffer.WriteString(strconv.FormatInt(int64(r&0xf), 16))
+			} else {
+				buffer.WriteRune(r)
+			}
+		}
+	}
+
+	buffer.WriteByte('"')
+}
+
+// formatNumber returns the number as ECMAScript writes a double, that is the
+// shortest digits that identify the double, in a decimal notation when the
+// exponent is in [-7, 21[, otherwise in a scientific one.
+func formatNumber(num json.Number) (string, error) {
+	value, err := strconv.ParseFloat(string(num), 64)
+	if err != nil || math.IsInf(value, 0) {
+		return "", xerrors.Errorf("number '%s' is out of range", num)
+	}
+
+	if value == 0 {
+		// Negative zero is written as zero.
+		return "0", nil
+	}
+
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	// The format is d.ddde±dd with the shortest digits.
+	str := strconv.FormatFloat(value, 'e', -1, 64)
+
+	index := strings.IndexByte(str, 'e')
+	digits := strings.Replace(str[:index], ".", "", 1)
+
+	// The exponent is always a valid integer.
+	exp, _ := strconv.Atoi(str[index+1:])
+
+	k := len(digits)
+	n := exp + 1
+
+	switch {
+	case k <= n && n <= 21:
+		str = digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		str = digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		str = "0." + strings.Repeat("0", -n) + digits
+	default:
+		str = digits[:1]
+		if k > 1 {
+			str += "." + digits[1:]
+		}
+
+		if n-1 >= 0 {
+			str += "e+" + strconv.Itoa(n-1)
+		} else {
+			str += "e-" + strconv.Itoa(1-n)
+		}
+	}
+
+	return sign + str, nil
+}
+
+func lessUnits(a, b []uint16) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+
+	return len(a) < len(b)
+}
+
+// canonicalEncoder is an encoder that writes each value in its canonical form,
+// followed by a new line.
+//
+// - implements serde.Encoder
+type canonicalEncoder struct {
+	engine jsonEngine
+	w      io.Writer
+}
+
+// Encode implements serde.Encoder. It writes the canonical form of the value.
+func (e canonicalEncoder) Encode(value interface{}) error {
+	data, err := e.engine.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(append(data, '\n'))
+	if err != nil {
+		return xerrors.Errorf("failed to write: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/internal/testing/fake"
+)
+
+func TestCanonicalize(t *testing.T) {
+	// Example of the section 3.2.2 of RFC 8785.
+	input := `{
+		"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+		"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+		"literals": [null, true, false]
+	}`
+
+	data, err := Canonicalize([]byte(input))
+	require.NoError(t, err)
+	require.Equal(t, `{"literals":[null,true,false],`+
+		`"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],`+
+		`"string":"€$\u000f\nA'B\"\\\\\"/"}`, string(data))
+
+	// Example of the section 3.2.3 of RFC 8785, where the names are sorted by
+	// their UTF-16 code units.
+	input = `{
+		"\u20ac": "Euro Sign",
+		"\r": "Carriage Return",
+		"\ufb33": "Hebrew Letter Dalet With Dagesh",
+		"1": "One",
+		"\ud83d\ude00": "Emoji: Grinning Face",
+		"\u0080": "Control",
+		"\u00f6": "Latin Small Letter O With Diaeresis"
+	}`
+
+	data, err = Canonicalize([]byte(input))
+	require.NoError(t, err)
+	require.Equal(t, "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\","+
+		"\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\","+
+		"\"\U0001F600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		string(data))
+
+	data, err = Canonicalize([]byte(` { "b" : [ ], "a" : { "d" : { } , "c" : "<&>" } } `))
+	require.NoError(t, err)
+	require.Equal(t, `{"a":{"c":"<&>","d":{}},"b":[]}`, string(data))
+
+	data, err = Canonicalize([]byte(`{"ab":"\b\f\t\u001F\u0000","a":0}`))
+	require.NoError(t, err)
+	require.Equal(t, `{"a":0,"ab":"\b\f\t\u001f\u0000"}`, string(data))
+
+	_, err = Canonicalize([]byte(`{"a":1,"a":2}`))
+	require.EqualError(t, err, "invalid document: duplicated name 'a'")
+
+	_, err = Canonicalize([]byte(`[1e400]`))
+	require.EqualError(t, err, "invalid document: number '1e400' is out of range")
+
+	_, err = Canonicalize([]byte("\"\xff\""))
+	require.EqualError(t, err, "invalid document: malformed UTF-8")
+
+	_, err = Canonicalize([]byte(`{"a":1} {}`))
+	require.EqualError(t, err, "invalid document: unexpected data after the value")
+
+	_, err = Canonicalize([]byte(`{"a":[1,}`))
+	require.EqualError(t, err, "invalid document: invalid character ',' looking for beginning of value")
+
+	_, err = Canonicalize([]byte(`{"a":1`))
+	require.EqualError(t, err, "invalid document: unexpected end of JSON input")
+
+	_, err = Canonicalize([]byte(`[1`))
+	require.EqualError(t, err, "invalid document: unexpected end of JSON input")
+
+	_, err = Canonicalize([]byte(`{"a":`))
+	require.EqualError(t, err, "invalid document: EOF")
+
+	_, err = Canonicalize([]byte(`{1:2}`))
+	require.EqualError(t, err, "invalid document: object member name must be a string")
+}
+
+func TestFormatNumber(t *testing.T) {
+	// Examples of the appendix B of RFC 8785.
+	numbers := map[uint64]string{
+		0x0000000000000000: "0",
+		0x8000000000000000: "0",
+		0x0000000000000001: "5e-324",
+		0x8000000000000001: "-5e-324",
+		0x7fefffffffffffff: "1.7976931348623157e+308",
+		0xffefffffffffffff: "-1.7976931348623157e+308",
+		0x4340000000000000: "9007199254740992",
+		0xc340000000000000: "-9007199254740992",
+		0x4430000000000000: "295147905179352830000",
+		0x44b52d02c7e14af5: "9.999999999999997e+22",
+		0x44b52d02c7e14af6: "1e+23",
+		0x44b52d02c7e14af7: "1.0000000000000001e+23",
+		0x444b1ae4d6e2ef4e: "999999999999999700000",
+		0x444b1ae4d6e2ef4f: "999999999999999900000",
+		0x444b1ae4d6e2ef50: "1e+21",
+		0x3eb0c6f7a0b5ed8c: "9.999999999999997e-7",
+		0x3eb0c6f7a0b5ed8d: "0.000001",
+		0x41b3de4355555553: "333333333.3333332",
+		0x41b3de4355555554: "333333333.33333325",
+		0x41b3de4355555555: "333333333.3333333",
+		0x41b3de4355555556: "333333333.3333334",
+		0x41b3de4355555557: "333333333.33333343",
+		0xbecbf647612f3696: "-0.0000033333333333333333",
+		0x43143ff3c1cb0959: "1424953923781206.2",
+	}
+
+	for bits, expected := range numbers {
+		value := math.Float64frombits(bits)
+		num := json.Number(strconv.FormatFloat(value, 'g', -1, 64))
+
+		str, err := formatNumber(num)
+		require.NoError(t, err)
+		require.Equal(t, expected, str, "%#x", bits)
+	}
+}
+
+func TestJSONEngine_Canonical_Marshal(t *testing.T) {
+	ctx := NewContext(WithCanonical())
+
+	data, err := ctx.Marshal(map[string]interface{}{"b": 1.5e30, "a": "<&>"})
+	require.NoError(t, err)
+	require.Equal(t, `{"a":"<&>","b":1.5e+30}`, string(data))
+
+	_, err = ctx.Marshal(make(chan int))
+	require.EqualError(t, err, "json: unsupported type: chan int")
+}
+
+func TestJSONEngine_Canonical_NewEncoder(t *testing.T) {
+	ctx := NewContext(WithCanonical())
+
+	buffer := new(bytes.Buffer)
+
+	enc := ctx.NewEncoder(buffer)
+	require.NoError(t, enc.Encode(map[string]interface{}{"B": "<", "A": 1}))
+	require.Equal(t, `{"A":1,"B":"<"}`+"\n", buffer.String())
+
+	err := enc.Encode(make(chan int))
+	require.EqualError(t, err, "json: unsupported type: chan int")
+
+	enc = ctx.NewEncoder(fake.NewBadHash())
+	err = enc.Encode(1)
+	require.EqualError(t, err, fake.Err("failed to write"))
+}
@@ -0,0 +1,151 @@
+// This file contains the enforcement of the limits of the decoded documents.
+
+package json
+
+import (
+	"encoding/json"
+	"io"
+
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+// checkLimits returns an error that wraps serde.ErrLimitExceeded if the
+// document is larger, has more elements in an array or an object, or is more
+// nested than the limits allow. The document is scanned without being parsed,
+// so that a malformed one is refused later by the parser.
+func checkLimits(data []byte, limits serde.Limits) error {
+	err := limits.CheckSize(len(data))
+	if err != nil {
+		return err
+	}
+
+	if limits.MaxElements <= 0 && limits.MaxDepth <= 0 {
+		return nil
+	}
+
+	// The number of separators of each of the enclosing arrays and objects.
+	separators := []int{}
+	inString := false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		if inString {
+			switch c {
+			case '\\':
+				// The escaped character can't end the string.
+				i++
+			case '"':
+				inString = false
+			}
+
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '[', '{':
+			separators = append(separators, 0)
+
+			if limits.MaxDepth > 0 && len(separators) > limits.MaxDepth {
+				return xerrors.Errorf("depth exceeds %d: %w",
+					limits.MaxDepth, serde.ErrLimitExceeded)
+			}
+		case ']', '}':
+			if len(separators) > 0 {
+				separators = separators[:len(separators)-1]
+			}
+		case ',':
+			if len(separators) == 0 {
+				continue
+			}
+
+			separators[len(separators)-1]++
+
+			if limits.MaxElements > 0 && separators[len(separators)-1] >= limits.MaxElements {
+				return xerrors.Errorf("number of elements exceeds %d: %w",
+					limits.MaxElements, serde.ErrLimitExceeded)
+			}
+		}
+	}
+
+	return nil
+}
+
+// limitedDecoder is a decoder that enforces the limits on each value of the
+// stream.
+//
+// - implements serde.Decoder
+type limitedDecoder struct {
+	dec    *json.Decoder
+	r      *limitedReader
+	limits serde.Limits
+}
+
+func newLimitedDecoder(r io.Reader, limits serde.Limits) limitedDecoder {
+	lr := &limitedReader{r: r, limits: limits}
+
+	return limitedDecoder{
+		dec:    json.NewDecoder(lr),
+		r:      lr,
+		limits: limits,
+	}
+}
+
+// Decode implements serde.Decoder. It reads the next value of the stream and
+// checks it against the limits before populating the value.
+func (d limitedDecoder) Decode(value interface{}) error {
+	d.r.reset()
+
+	var raw json.RawMessage
+
+	err := d.dec.Decode(&raw)
+	if err != nil {
+		return err
+	}
+
+	err = checkLimits(raw, d.limits)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, value)
+}
+
+// limitedReader is a reader that fails when too many bytes are read for a
+// single value. The decoder reads ahead, therefore a value can use the bytes
+// read for the previous one, which means that the size of a value is only
+// bounded by twice the maximum.
+type limitedReader struct {
+	r      io.Reader
+	limits serde.Limits
+	read   int
+}
+
+func (r *limitedReader) reset() {
+	r.read = 0
+}
+
+// Read implements io.Reader. It reads from the underlying reader, or returns
+// an error if the maximum size is reached.
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.limits.MaxSize <= 0 {
+		return r.r.Read(p)
+	}
+
+	remaining := r.limits.MaxSize - r.read
+	if remaining <= 0 {
+		return 0, xerrors.Errorf("size exceeds %d: %w", r.limits.MaxSize, serde.ErrLimitExceeded)
+	}
+
+	if len(p) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := r.r.Read(p)
+	r.read += n
+
+	return n, err
+}
@@ -0,0 +1,78 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+)
+
+func TestCheckLimits(t *testing.T) {
+	limits := serde.Limits{MaxSize: 64, MaxElements: 3, MaxDepth: 2}
+
+	require.NoError(t, checkLimits([]byte(`[1,2,3]`), limits))
+	require.NoError(t, checkLimits([]byte(`{"a":[1,2,3],"b":{},"c":"[[[,,,"}`), limits))
+	require.NoError(t, checkLimits([]byte(`"\"[[[,,,"`), limits))
+	require.NoError(t, checkLimits([]byte(`],,,,`), limits))
+	require.NoError(t, checkLimits([]byte(`[[[[1,2,3,4]]]]`), serde.Limits{}))
+
+	err := checkLimits([]byte(`[1,2,3,4]`), limits)
+	require.EqualError(t, err, "number of elements exceeds 3: limit exceeded")
+	require.True(t, xerrors.Is(err, serde.ErrLimitExceeded))
+
+	err = checkLimits([]byte(`{"a":1,"b":2,"c":3,"d":4}`), limits)
+	require.EqualError(t, err, "number of elements exceeds 3: limit exceeded")
+
+	err = checkLimits([]byte(`[[{}]]`), limits)
+	require.EqualError(t, err, "depth exceeds 2: limit exceeded")
+
+	err = checkLimits([]byte(strings.Repeat(" ", 65)), limits)
+	require.EqualError(t, err, "size 65 exceeds 64: limit exceeded")
+}
+
+func TestJSONEngine_Limits_Unmarshal(t *testing.T) {
+	ctx := NewContext(WithLimits(serde.Limits{MaxElements: 2}))
+	require.Equal(t, serde.Limits{MaxElements: 2}, ctx.GetLimits())
+
+	var values []int
+	require.NoError(t, ctx.Unmarshal([]byte(`[1,2]`), &values))
+	require.Equal(t, []int{1, 2}, values)
+
+	err := ctx.Unmarshal([]byte(`[1,2,3]`), &values)
+	require.EqualError(t, err, "number of elements exceeds 2: limit exceeded")
+}
+
+func TestJSONEngine_Limits_NewDecoder(t *testing.T) {
+	ctx := NewContext(WithLimits(serde.Limits{MaxSize: 8, MaxDepth: 1}))
+
+	dec := ctx.NewDecoder(strings.NewReader(`[1,2] "A" [[1]] 1`))
+	require.IsType(t, limitedDecoder{}, dec)
+
+	var values []int
+	require.NoError(t, dec.Decode(&values))
+	require.Equal(t, []int{1, 2}, values)
+
+	var str string
+	require.NoError(t, dec.Decode(&str))
+	require.Equal(t, "A", str)
+
+	err := dec.Decode(&values)
+	require.EqualError(t, err, "depth exceeds 1: limit exceeded")
+
+	dec = ctx.NewDecoder(strings.NewReader(`"` + strings.Repeat("A", 20) + `"`))
+
+	err = dec.Decode(&str)
+	require.EqualError(t, err, "size exceeds 8: limit exceeded")
+	require.True(t, xerrors.Is(err, serde.ErrLimitExceeded))
+
+	dec = ctx.NewDecoder(new(bytes.Buffer))
+	require.Equal(t, io.EOF, dec.Decode(&str))
+
+	// Without limits, the decoder of the standard library is used.
+	require.IsType(t, &json.Decoder{}, NewContext().NewDecoder(new(bytes.Buffer)))
+}
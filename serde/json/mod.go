@@ -6,6 +6,7 @@ package json
 
 import (
 	"encoding/json"
+	"io"
 
 	// Static registration of the JSON formats. By having them here, it ensures
 	// that an import of the JSON context engine will import the definitions.
@@ -13,25 +14,67 @@ import (
 	_ "go.dedis.ch/dela/core/ordering/cosipbft/authority/json"
 	_ "go.dedis.ch/dela/core/ordering/cosipbft/blocksync/json"
 	_ "go.dedis.ch/dela/core/ordering/cosipbft/json"
+	_ "go.dedis.ch/dela/core/ordering/raft/json"
 	_ "go.dedis.ch/dela/core/txn/signed/json"
 	_ "go.dedis.ch/dela/core/validation/simple/json"
 	_ "go.dedis.ch/dela/cosi/json"
 	_ "go.dedis.ch/dela/cosi/threshold/json"
 	_ "go.dedis.ch/dela/crypto/bls/json"
+	_ "go.dedis.ch/dela/crypto/bls/threshold/json"
+	_ "go.dedis.ch/dela/crypto/dilithium/json"
 	_ "go.dedis.ch/dela/crypto/ed25519/json"
+	_ "go.dedis.ch/dela/crypto/hybrid/json"
 	_ "go.dedis.ch/dela/dkg/pedersen/json"
+	_ "go.dedis.ch/dela/dkg/tecdsa/json"
+	_ "go.dedis.ch/dela/mino/batch/json"
+	_ "go.dedis.ch/dela/mino/heartbeat/json"
+	_ "go.dedis.ch/dela/mino/mux/json"
+	_ "go.dedis.ch/dela/mino/ordered/json"
+	_ "go.dedis.ch/dela/mino/reliable/json"
+	_ "go.dedis.ch/dela/mino/router/gossip/json"
 	_ "go.dedis.ch/dela/mino/router/tree/json"
 	"go.dedis.ch/dela/serde"
 )
 
+// Option is the type of option to set some fields of the engine.
+type Option func(*jsonEngine)
+
+// WithCanonical is an option to marshal the messages in their canonical form
+// according to RFC 8785, so that a signature over the bytes of a message can
+// be verified by a system that canonicalizes the same document.
+func WithCanonical() Option {
+	return func(e *jsonEngine) {
+		e.canonical = true
+	}
+}
+
+// WithLimits is an option to refuse the documents that exceed the limits when
+// they are unmarshaled or decoded from a stream, before they are parsed.
+func WithLimits(limits serde.Limits) Option {
+	return func(e *jsonEngine) {
+		e.limits = limits
+	}
+}
+
 // JSONEngine is a context engine to marshal and unmarshal in JSON format.
 //
 // - implements serde.ContextEngine
-type jsonEngine struct{}
+// - implements serde.StreamEngine
+// - implements serde.LimitedEngine
+type jsonEngine struct {
+	canonical bool
+	limits    serde.Limits
+}
 
 // NewContext returns a JSON context.
-func NewContext() serde.Context {
-	return serde.NewContext(jsonEngine{})
+func NewContext(opts ...Option) serde.Context {
+	engine := jsonEngine{}
+
+	for _, opt := range opts {
+		opt(&engine)
+	}
+
+	return serde.NewContext(engine)
 }
 
 // GetFormat implements serde.FormatEngine. It returns the JSON format name.
@@ -40,13 +83,51 @@ func (ctx jsonEngine) GetFormat() serde.Format {
 }
 
 // Marshal implements serde.FormatEngine. It returns the bytes of the message
-// marshaled in JSON format.
+// marshaled in JSON format, in its canonical form if the option is set.
 func (ctx jsonEngine) Marshal(m interface{}) ([]byte, error) {
-	return json.Marshal(m)
+	data, err := json.Marshal(m)
+	if err != nil || !ctx.canonical {
+		return data, err
+	}
+
+	return Canonicalize(data)
+}
+
+// GetLimits implements serde.LimitedEngine. It returns the limits of the
+// documents.
+func (ctx jsonEngine) GetLimits() serde.Limits {
+	return ctx.limits
 }
 
 // Unmarshal implements serde.FormatEngine. It populates the message using the
-// JSON format definition.
+// JSON format definition, if the document is within the limits.
 func (ctx jsonEngine) Unmarshal(data []byte, m interface{}) error {
+	err := checkLimits(data, ctx.limits)
+	if err != nil {
+		return err
+	}
+
 	return json.Unmarshal(data, m)
 }
+
+// NewEncoder implements serde.StreamEngine. It returns an encoder of the values
+// into the writer using the JSON encoding. Each value is written in its
+// canonical form if the option is set.
+func (ctx jsonEngine) NewEncoder(w io.Writer) serde.Encoder {
+	if ctx.canonical {
+		return canonicalEncoder{engine: ctx, w: w}
+	}
+
+	return json.NewEncoder(w)
+}
+
+// NewDecoder implements serde.StreamEngine. It returns a decoder of the values
+// from the reader using the JSON encoding. Each value is checked against the
+// limits when they are set.
+func (ctx jsonEngine) NewDecoder(r io.Reader) serde.Decoder {
+	if ctx.limits != (serde.Limits{}) {
+		return newLimitedDecoder(r, ctx.limits)
+	}
+
+	return json.NewDecoder(r)
+}
@@ -1,6 +1,9 @@
 package json
 
 import (
+	"bytes"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -36,6 +39,32 @@ func TestJSONEngine_Unmarshal(t *testing.T) {
 	require.EqualError(t, err, "unexpected end of JSON input")
 }
 
+func TestJSONEngine_NewEncoder(t *testing.T) {
+	ctx := NewContext()
+
+	buffer := new(bytes.Buffer)
+
+	enc := ctx.NewEncoder(buffer)
+	require.NoError(t, enc.Encode(map[string]interface{}{"A": "B"}))
+	require.NoError(t, enc.Encode(map[string]interface{}{"A": "B"}))
+	require.Equal(t, strings.Repeat(`{"A":"B"}`+"\n", 2), buffer.String())
+}
+
+func TestJSONEngine_NewDecoder(t *testing.T) {
+	ctx := NewContext()
+
+	dec := ctx.NewDecoder(strings.NewReader(strings.Repeat(`{"A":"B"}`+"\n", 2)))
+
+	for i := 0; i < 2; i++ {
+		var m interface{}
+		require.NoError(t, dec.Decode(&m))
+		require.Equal(t, map[string]interface{}{"A": "B"}, m)
+	}
+
+	var m interface{}
+	require.Equal(t, io.EOF, dec.Decode(&m))
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
@@ -0,0 +1,52 @@
+package serde
+
+// WithLenient returns a context that tolerates the sub-messages that cannot be
+// decoded, e.g. a transaction of an unknown format in a block, so that the
+// message is still available to a reader like an explorer. A format that
+// supports it replaces such a sub-message by a raw message instead of failing
+// the entire decoding.
+func WithLenient(ctx Context) Context {
+	ctx.lenient = true
+
+	return ctx
+}
+
+// IsLenient returns true if the sub-messages that cannot be decoded should be
+// replaced by raw messages.
+func (ctx Context) IsLenient() bool {
+	return ctx.lenient
+}
+
+// RawMessage is a message kept in its serialized form because it could not be
+// decoded. It is serialized back to the exact same data.
+//
+// - implements serde.Message
+type RawMessage struct {
+	data []byte
+	err  error
+}
+
+// NewRawMessage returns a raw message of the data, with the error that
+// prevented its decoding.
+func NewRawMessage(data []byte, err error) RawMessage {
+	return RawMessage{
+		data: data,
+		err:  err,
+	}
+}
+
+// GetData returns the serialized data of the message.
+func (m RawMessage) GetData() []byte {
+	return m.data
+}
+
+// GetError returns the error that prevented the decoding of the message.
+func (m RawMessage) GetError() error {
+	return m.err
+}
+
+// Serialize implements serde.Message. It returns the data as is, regardless of
+// the format of the context.
+func (m RawMessage) Serialize(Context) ([]byte, error) {
+	return m.data, nil
+}
@@ -0,0 +1,27 @@
+package serde
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLenient(t *testing.T) {
+	ctx := NewContext(fakeEngine{})
+	require.False(t, ctx.IsLenient())
+
+	ctx2 := WithLenient(ctx)
+	require.False(t, ctx.IsLenient())
+	require.True(t, ctx2.IsLenient())
+}
+
+func TestRawMessage(t *testing.T) {
+	msg := NewRawMessage([]byte(`{"A":1}`), errors.New("oops"))
+	require.Equal(t, []byte(`{"A":1}`), msg.GetData())
+	require.EqualError(t, msg.GetError(), "oops")
+
+	data, err := msg.Serialize(NewContext(fakeEngine{}))
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"A":1}`), data)
+}
@@ -0,0 +1,75 @@
+package serde
+
+import (
+	"io"
+	"io/ioutil"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrLimitExceeded is the error returned when a message exceeds one of the
+// limits of the context.
+var ErrLimitExceeded = xerrors.New("limit exceeded")
+
+// Limits are the bounds of the messages that a context decodes, so that a
+// hostile input cannot force huge allocations before the messages are
+// validated. A zero value means no limit.
+type Limits struct {
+	// MaxSize is the maximum number of bytes of a message, or of a value of a
+	// stream.
+	MaxSize int
+
+	// MaxElements is the maximum number of elements of an array, or of members
+	// of an object.
+	MaxElements int
+
+	// MaxDepth is the maximum number of nested arrays and objects.
+	MaxDepth int
+}
+
+// CheckSize returns an error that wraps ErrLimitExceeded if the size is above
+// the maximum.
+func (l Limits) CheckSize(size int) error {
+	if l.MaxSize > 0 && size > l.MaxSize {
+		return xerrors.Errorf("size %d exceeds %d: %w", size, l.MaxSize, ErrLimitExceeded)
+	}
+
+	return nil
+}
+
+// LimitedEngine is the interface that a context engine implements when it
+// enforces limits on the messages it decodes.
+type LimitedEngine interface {
+	// GetLimits returns the limits of the engine.
+	GetLimits() Limits
+}
+
+// GetLimits returns the limits of the engine of the context, or no limit when
+// the engine doesn't enforce any.
+func (ctx Context) GetLimits() Limits {
+	engine, ok := ctx.ContextEngine.(LimitedEngine)
+	if !ok {
+		return Limits{}
+	}
+
+	return engine.GetLimits()
+}
+
+// readAll reads the reader until the end, or returns an error as soon as the
+// data exceeds the maximum size.
+func readAll(r io.Reader, limits Limits) ([]byte, error) {
+	if limits.MaxSize <= 0 {
+		return ioutil.ReadAll(r)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(r, int64(limits.MaxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > limits.MaxSize {
+		return nil, xerrors.Errorf("size exceeds %d: %w", limits.MaxSize, ErrLimitExceeded)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,77 @@
+package serde
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestLimits_CheckSize(t *testing.T) {
+	limits := Limits{MaxSize: 2}
+
+	require.NoError(t, limits.CheckSize(2))
+
+	err := limits.CheckSize(3)
+	require.EqualError(t, err, "size 3 exceeds 2: limit exceeded")
+	require.True(t, xerrors.Is(err, ErrLimitExceeded))
+
+	require.NoError(t, Limits{}.CheckSize(1<<30))
+}
+
+func TestContext_GetLimits(t *testing.T) {
+	ctx := NewContext(fakeEngine{})
+	require.Equal(t, Limits{}, ctx.GetLimits())
+
+	ctx = NewContext(fakeLimitedEngine{limits: Limits{MaxDepth: 1}})
+	require.Equal(t, Limits{MaxDepth: 1}, ctx.GetLimits())
+}
+
+func TestLimits_Decode(t *testing.T) {
+	ctx := NewContext(fakeLimitedEngine{limits: Limits{MaxSize: 7}})
+
+	msg, err := Decode(ctx, fakeMessageFactory{}, bytes.NewBufferString("message"))
+	require.NoError(t, err)
+	require.Equal(t, fakeMessage{}, msg)
+
+	_, err = Decode(ctx, fakeMessageFactory{}, bytes.NewBufferString("message!"))
+	require.EqualError(t, err, "failed to read: size exceeds 7: limit exceeded")
+	require.True(t, xerrors.Is(err, ErrLimitExceeded))
+
+	_, err = Decode(ctx, fakeMessageFactory{}, badReader{})
+	require.EqualError(t, err, "failed to read: oops")
+}
+
+func TestLimits_FrameDecoder(t *testing.T) {
+	ctx := NewContext(fakeLimitedEngine{limits: Limits{MaxSize: 3}})
+
+	dec := ctx.NewDecoder(bytes.NewBufferString("\x03\x00\x00\x00\"A\"\xff\xff\xff\xff"))
+
+	var str string
+	require.NoError(t, dec.Decode(&str))
+	require.Equal(t, "A", str)
+
+	err := dec.Decode(&str)
+	require.EqualError(t, err, "value of 4294967295 bytes exceeds 3: limit exceeded")
+	require.True(t, xerrors.Is(err, ErrLimitExceeded))
+
+	// The frames without a limit are only bounded by the length.
+	dec = NewContext(fakeEngine{}).NewDecoder(strings.NewReader("\x04\x00\x00\x00\"AB\""))
+	require.NoError(t, dec.Decode(&str))
+	require.Equal(t, "AB", str)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeLimitedEngine struct {
+	fakeEngine
+
+	limits Limits
+}
+
+func (e fakeLimitedEngine) GetLimits() Limits {
+	return e.limits
+}
@@ -7,6 +7,15 @@
 // The deserialization works in a similar fashion but through the Factory
 // interface.
 //
+// A message that is too large to fit in memory implements the StreamMessage
+// interface instead, so that it is written into a stream piece by piece, and
+// its factory the StreamFactory interface. The Encode and Decode functions
+// pick the streaming when it is available.
+//
+// A context can also compress the payloads with a Compressor, in which case the
+// Compress and Decompress functions apply around the serialization. A lenient
+// context keeps the sub-messages that cannot be decoded as raw messages.
+//
 // See dela/serde/registry for more advanced control of the formats.
 //
 // Documentation Last Review: 07.10.2020
@@ -24,6 +33,9 @@ const (
 
 	// FormatXML is the identifier for XML formats.
 	FormatXML Format = "XML"
+
+	// FormatProtobuf is the identifier for Protocol Buffers formats.
+	FormatProtobuf Format = "PROTOBUF"
 )
 
 // Message is the interface that a message must implement.
@@ -38,6 +50,26 @@ type Factory interface {
 	Deserialize(ctx Context, data []byte) (Message, error)
 }
 
+// StreamMessage is the interface that a message implements when it is too
+// large to be serialized as a single slice of bytes, e.g. a snapshot of the
+// state.
+type StreamMessage interface {
+	Message
+
+	// SerializeTo writes the serialized message into the writer, by complying
+	// to the context format.
+	SerializeTo(ctx Context, w io.Writer) error
+}
+
+// StreamFactory is the interface that the factory of a stream message
+// implements.
+type StreamFactory interface {
+	Factory
+
+	// DeserializeFrom deserializes the message read from the reader.
+	DeserializeFrom(ctx Context, r io.Reader) (Message, error)
+}
+
 // FormatEngine is the interface that a format implementation must implement.
 type FormatEngine interface {
 	// Encode marshals the message according to the format definition.
@@ -47,6 +79,20 @@ type FormatEngine interface {
 	Decode(ctx Context, data []byte) (Message, error)
 }
 
+// StreamFormatEngine is the interface that a format implementation of a stream
+// message implements.
+type StreamFormatEngine interface {
+	FormatEngine
+
+	// EncodeTo writes the message into the writer according to the format
+	// definition.
+	EncodeTo(ctx Context, message Message, w io.Writer) error
+
+	// DecodeFrom reads a message from the reader according to the format
+	// definition.
+	DecodeFrom(ctx Context, r io.Reader) (Message, error)
+}
+
 // Fingerprinter is an interface to fingerprint an object.
 type Fingerprinter interface {
 	// Fingerprint writes a deterministic binary representation of the object
@@ -0,0 +1,66 @@
+// Package proto implements the context engine for the Protocol Buffers format.
+//
+// The messages of the formats are described by the schemas of the proto
+// subpackages so that clients written in other languages can generate their
+// own bindings. The engine only accepts messages implementing proto.Message.
+//
+package proto
+
+import (
+	protobuf "github.com/golang/protobuf/proto"
+	"go.dedis.ch/dela/serde"
+	"golang.org/x/xerrors"
+
+	// Static registration of the protobuf formats. By having them here, it
+	// ensures that an import of the protobuf context engine will import the
+	// definitions.
+	_ "go.dedis.ch/dela/core/ordering/cosipbft/authority/proto"
+	_ "go.dedis.ch/dela/core/ordering/cosipbft/proto"
+	_ "go.dedis.ch/dela/core/txn/signed/proto"
+	_ "go.dedis.ch/dela/core/validation/simple/proto"
+	_ "go.dedis.ch/dela/cosi/threshold/proto"
+	_ "go.dedis.ch/dela/crypto/bls/proto"
+	_ "go.dedis.ch/dela/crypto/bls/threshold/proto"
+	_ "go.dedis.ch/dela/crypto/common/proto"
+	_ "go.dedis.ch/dela/crypto/dilithium/proto"
+	_ "go.dedis.ch/dela/crypto/hybrid/proto"
+)
+
+// protoEngine is a context engine to marshal and unmarshal in the Protocol
+// Buffers wire format.
+//
+// - implements serde.ContextEngine
+type protoEngine struct{}
+
+// NewContext returns a protobuf context.
+func NewContext() serde.Context {
+	return serde.NewContext(protoEngine{})
+}
+
+// GetFormat implements serde.ContextEngine. It returns the protobuf format
+// name.
+func (protoEngine) GetFormat() serde.Format {
+	return serde.FormatProtobuf
+}
+
+// Marshal implements serde.ContextEngine. It returns the bytes of the message
+// encoded in the protobuf wire format.
+func (protoEngine) Marshal(m interface{}) ([]byte, error) {
+	msg, ok := m.(protobuf.Message)
+	if !ok {
+		return nil, xerrors.Errorf("unsupported message of type '%T'", m)
+	}
+
+	return protobuf.Marshal(msg)
+}
+
+// Unmarshal implements serde.ContextEngine. It populates the message from the
+// data in the protobuf wire format.
+func (protoEngine) Unmarshal(data []byte, m interface{}) error {
+	msg, ok := m.(protobuf.Message)
+	if !ok {
+		return xerrors.Errorf("unsupported message of type '%T'", m)
+	}
+
+	return protobuf.Unmarshal(data, msg)
+}
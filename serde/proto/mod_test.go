@@ -0,0 +1,189 @@
+package proto
+
+import (
+	"testing"
+	"testing/quick"
+
+	protobuf "github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/dela/core/ordering/cosipbft/authority"
+	"go.dedis.ch/dela/core/ordering/cosipbft/types"
+	"go.dedis.ch/dela/core/txn/signed"
+	"go.dedis.ch/dela/core/validation/simple"
+	"go.dedis.ch/dela/crypto/bls"
+	"go.dedis.ch/dela/internal/testing/fake"
+	"go.dedis.ch/dela/internal/testing/gen"
+	"go.dedis.ch/dela/mino"
+	"go.dedis.ch/dela/serde"
+)
+
+func TestProtoEngine_GetFormat(t *testing.T) {
+	ctx := NewContext()
+	require.Equal(t, serde.FormatProtobuf, ctx.GetFormat())
+}
+
+func TestProtoEngine_Marshal(t *testing.T) {
+	ctx := NewContext()
+
+	data, err := ctx.Marshal(&testMessage{Value: 42})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x8, 42}, data)
+
+	_, err = ctx.Marshal(struct{}{})
+	require.EqualError(t, err, "unsupported message of type 'struct {}'")
+}
+
+func TestProtoEngine_Unmarshal(t *testing.T) {
+	ctx := NewContext()
+
+	m := &testMessage{}
+	err := ctx.Unmarshal([]byte{0x8, 42}, m)
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), m.Value)
+
+	err = ctx.Unmarshal([]byte{0x8}, m)
+	require.Error(t, err)
+
+	var v interface{}
+	err = ctx.Unmarshal(nil, &v)
+	require.EqualError(t, err, "unsupported message of type '*interface {}'")
+}
+
+func TestProtoEngine_Roster_RoundTrip(t *testing.T) {
+	ctx := NewContext()
+	fac := authority.NewFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+
+	f := func(roster gen.Roster) bool {
+		data, err := roster.Serialize(ctx)
+		require.NoError(t, err)
+
+		decoded, err := fac.AuthorityOf(ctx, data)
+		require.NoError(t, err)
+		require.Equal(t, roster.Len(), decoded.Len())
+
+		again, err := decoded.Serialize(ctx)
+		require.NoError(t, err)
+		require.Equal(t, data, again)
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 20})
+	require.NoError(t, err)
+}
+
+func TestProtoEngine_ChangeSet_RoundTrip(t *testing.T) {
+	ctx := NewContext()
+	fac := authority.NewChangeSetFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+
+	f := func(cset gen.ChangeSet) bool {
+		data, err := cset.Serialize(ctx)
+		require.NoError(t, err)
+
+		decoded, err := fac.ChangeSetOf(ctx, data)
+		require.NoError(t, err)
+		require.Equal(t, cset.NumChanges(), decoded.NumChanges())
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 20})
+	require.NoError(t, err)
+}
+
+func TestProtoEngine_Block_RoundTrip(t *testing.T) {
+	ctx := NewContext()
+	fac := types.NewBlockFactory(simple.NewResultFactory(signed.NewTransactionFactory()))
+
+	f := func(block gen.Block) bool {
+		data, err := block.Serialize(ctx)
+		require.NoError(t, err)
+
+		msg, err := fac.Deserialize(ctx, data)
+		require.NoError(t, err)
+
+		decoded := msg.(types.Block)
+		require.Equal(t, block.GetHash(), decoded.GetHash())
+		require.Equal(t, block.GetIndex(), decoded.GetIndex())
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 10})
+	require.NoError(t, err)
+}
+
+func TestProtoEngine_Message_RoundTrip(t *testing.T) {
+	ctx := NewContext()
+
+	csFac := authority.NewChangeSetFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+	blockFac := types.NewBlockFactory(simple.NewResultFactory(signed.NewTransactionFactory()))
+
+	fac := types.NewMessageFactory(
+		types.NewGenesisFactory(authority.NewFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())),
+		blockFac,
+		fake.AddressFactory{},
+		bls.NewSignatureFactory(),
+		csFac,
+	)
+
+	sig, err := gen.Signer(0).Sign([]byte("hello"))
+	require.NoError(t, err)
+
+	block, err := types.NewBlock(simple.NewResult(nil), types.WithIndex(3))
+	require.NoError(t, err)
+
+	views := map[mino.Address]types.ViewMessage{
+		fake.NewAddress(1): types.NewViewMessage(types.Digest{1}, 2, sig),
+	}
+
+	msgs := []serde.Message{
+		types.NewBlockMessage(block, views),
+		types.NewCommit(types.Digest{2}, sig),
+		types.NewDone(types.Digest{3}, sig),
+		types.NewViewMessage(types.Digest{4}, 5, sig),
+	}
+
+	for _, msg := range msgs {
+		data, err := msg.Serialize(ctx)
+		require.NoError(t, err)
+
+		decoded, err := fac.Deserialize(ctx, data)
+		require.NoError(t, err)
+		require.IsType(t, msg, decoded)
+
+		again, err := decoded.Serialize(ctx)
+		require.NoError(t, err)
+		require.Equal(t, data, again)
+	}
+
+	_, err = fac.Deserialize(ctx, []byte{})
+	require.EqualError(t, err, "decoding failed: message is empty")
+}
+
+func TestProtoEngine_Quick_Malformed(t *testing.T) {
+	ctx := NewContext()
+	rosterFac := authority.NewFactory(fake.AddressFactory{}, bls.NewPublicKeyFactory())
+	blockFac := types.NewBlockFactory(simple.NewResultFactory(signed.NewTransactionFactory()))
+
+	f := func(input gen.Malformed) bool {
+		require.NotPanics(t, func() { rosterFac.AuthorityOf(ctx, input.Data) })
+		require.NotPanics(t, func() { blockFac.Deserialize(ctx, input.Data) })
+
+		return true
+	}
+
+	err := quick.Check(f, &quick.Config{MaxCount: 200})
+	require.NoError(t, err)
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type testMessage struct {
+	Value uint64 `protobuf:"varint,1,opt,name=value,proto3"`
+}
+
+func (m *testMessage) Reset()         { *m = testMessage{} }
+func (m *testMessage) String() string { return protobuf.CompactTextString(m) }
+func (*testMessage) ProtoMessage()    {}
@@ -0,0 +1,208 @@
+package serde
+
+import (
+	"encoding/binary"
+	"io"
+
+	"golang.org/x/xerrors"
+)
+
+// Encoder is the interface to write a sequence of values into a stream, so
+// that a large message is written piece by piece.
+type Encoder interface {
+	// Encode writes the value into the stream according to the format of the
+	// context.
+	Encode(value interface{}) error
+}
+
+// Decoder is the interface to read a sequence of values from a stream.
+type Decoder interface {
+	// Decode populates the value with the next one of the stream. It returns
+	// io.EOF when the stream has no more values.
+	Decode(value interface{}) error
+}
+
+// StreamEngine is the interface that a context engine implements when its
+// format is written directly into a stream.
+type StreamEngine interface {
+	// NewEncoder returns an encoder of the values into the writer.
+	NewEncoder(w io.Writer) Encoder
+
+	// NewDecoder returns a decoder of the values from the reader.
+	NewDecoder(r io.Reader) Decoder
+}
+
+// NewEncoder returns an encoder of the values into the writer, according to
+// the format of the context. When the engine doesn't support the streams, each
+// value is marshaled and prefixed with its length.
+func (ctx Context) NewEncoder(w io.Writer) Encoder {
+	engine, ok := ctx.ContextEngine.(StreamEngine)
+	if ok {
+		return engine.NewEncoder(w)
+	}
+
+	return frameEncoder{
+		engine: ctx.ContextEngine,
+		w:      w,
+	}
+}
+
+// NewDecoder returns a decoder of the values from the reader, according to the
+// format of the context. It expects the values to be prefixed with their
+// length when the engine doesn't support the streams.
+func (ctx Context) NewDecoder(r io.Reader) Decoder {
+	engine, ok := ctx.ContextEngine.(StreamEngine)
+	if ok {
+		return engine.NewDecoder(r)
+	}
+
+	return frameDecoder{
+		engine: ctx.ContextEngine,
+		limits: ctx.GetLimits(),
+		r:      r,
+	}
+}
+
+// Encode writes the message into the writer. A stream message is written
+// piece by piece, otherwise the message is serialized as a whole. The stream
+// is compressed when the context has a compressor.
+func Encode(ctx Context, msg Message, w io.Writer) error {
+	cw, err := compressWriter(ctx, w)
+	if err != nil {
+		return xerrors.Errorf("failed to compress: %v", err)
+	}
+
+	smsg, ok := msg.(StreamMessage)
+	if ok {
+		err = smsg.SerializeTo(ctx, cw)
+		if err != nil {
+			return xerrors.Errorf("failed to serialize: %v", err)
+		}
+	} else {
+		data, err := msg.Serialize(ctx)
+		if err != nil {
+			return xerrors.Errorf("failed to serialize: %v", err)
+		}
+
+		_, err = cw.Write(data)
+		if err != nil {
+			return xerrors.Errorf("failed to write: %v", err)
+		}
+	}
+
+	err = cw.Close()
+	if err != nil {
+		return xerrors.Errorf("failed to flush: %v", err)
+	}
+
+	return nil
+}
+
+// Decode reads a message from the reader with the factory. A stream factory
+// reads the message piece by piece, otherwise the reader is read until the end
+// to deserialize the message as a whole. The stream is decompressed when the
+// context has a compressor.
+func Decode(ctx Context, f Factory, r io.Reader) (Message, error) {
+	cr, err := decompressReader(ctx, r)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decompress: %v", err)
+	}
+
+	defer cr.Close()
+
+	sf, ok := f.(StreamFactory)
+	if ok {
+		msg, err := sf.DeserializeFrom(ctx, cr)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to deserialize: %v", err)
+		}
+
+		return msg, nil
+	}
+
+	data, err := readAll(cr, ctx.GetLimits())
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read: %w", err)
+	}
+
+	msg, err := f.Deserialize(ctx, data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to deserialize: %v", err)
+	}
+
+	return msg, nil
+}
+
+// frameEncoder is an encoder that prefixes each value with its length.
+//
+// - implements serde.Encoder
+type frameEncoder struct {
+	engine ContextEngine
+	w      io.Writer
+}
+
+// Encode implements serde.Encoder. It writes the length of the value, followed
+// by the value.
+func (e frameEncoder) Encode(value interface{}) error {
+	data, err := e.engine.Marshal(value)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal: %v", err)
+	}
+
+	header := make([]byte, 4)
+	binary.LittleEndian.PutUint32(header, uint32(len(data)))
+
+	_, err = e.w.Write(append(header, data...))
+	if err != nil {
+		return xerrors.Errorf("failed to write: %v", err)
+	}
+
+	return nil
+}
+
+// frameDecoder is a decoder of the values prefixed with their length.
+//
+// - implements serde.Decoder
+type frameDecoder struct {
+	engine ContextEngine
+	limits Limits
+	r      io.Reader
+}
+
+// Decode implements serde.Decoder. It reads the length of the next value, and
+// then the value. It returns io.EOF if the stream ends before a value. The
+// length is checked against the limits before the value is allocated.
+func (d frameDecoder) Decode(value interface{}) error {
+	header := make([]byte, 4)
+
+	_, err := io.ReadFull(d.r, header)
+	if err == io.EOF {
+		return err
+	}
+	if err != nil {
+		return xerrors.Errorf("failed to read length: %v", err)
+	}
+
+	length := binary.LittleEndian.Uint32(header)
+
+	// The length is compared before the conversion so that it can't overflow
+	// on a 32-bit architecture.
+	if d.limits.MaxSize > 0 && uint64(length) > uint64(d.limits.MaxSize) {
+		return xerrors.Errorf("value of %d bytes exceeds %d: %w",
+			length, d.limits.MaxSize, ErrLimitExceeded)
+	}
+
+	data := make([]byte, length)
+
+	_, err = io.ReadFull(d.r, data)
+	if err != nil {
+		return xerrors.Errorf("failed to read value: %v", err)
+	}
+
+	err = d.engine.Unmarshal(data, value)
+	if err != nil {
+		return xerrors.Errorf("failed to unmarshal: %v", err)
+	}
+
+	return nil
+}
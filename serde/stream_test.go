@@ -0,0 +1,240 @@
+package serde
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext_NewEncoder(t *testing.T) {
+	ctx := NewContext(fakeEngine{})
+
+	buffer := new(bytes.Buffer)
+
+	enc := ctx.NewEncoder(buffer)
+	require.IsType(t, frameEncoder{}, enc)
+
+	require.NoError(t, enc.Encode(1))
+	require.NoError(t, enc.Encode("A"))
+	require.Equal(t, "\x01\x00\x00\x001\x03\x00\x00\x00\"A\"", buffer.String())
+
+	ctx = NewContext(fakeStreamEngine{})
+	require.IsType(t, json.NewEncoder(buffer), ctx.NewEncoder(buffer))
+}
+
+func TestContext_NewDecoder(t *testing.T) {
+	ctx := NewContext(fakeEngine{})
+
+	buffer := bytes.NewBufferString("\x01\x00\x00\x001\x03\x00\x00\x00\"A\"")
+
+	dec := ctx.NewDecoder(buffer)
+	require.IsType(t, frameDecoder{}, dec)
+
+	var num int
+	require.NoError(t, dec.Decode(&num))
+	require.Equal(t, 1, num)
+
+	var str string
+	require.NoError(t, dec.Decode(&str))
+	require.Equal(t, "A", str)
+
+	require.Equal(t, io.EOF, dec.Decode(&str))
+
+	ctx = NewContext(fakeStreamEngine{})
+	require.IsType(t, json.NewDecoder(buffer), ctx.NewDecoder(buffer))
+}
+
+func TestFrameEncoder_Encode(t *testing.T) {
+	enc := frameEncoder{engine: fakeEngine{}, w: badWriter{}}
+
+	err := enc.Encode(1)
+	require.EqualError(t, err, "failed to write: oops")
+
+	enc.engine = fakeEngine{err: errors.New("oops")}
+	err = enc.Encode(1)
+	require.EqualError(t, err, "failed to marshal: oops")
+}
+
+func TestFrameDecoder_Decode(t *testing.T) {
+	var value int
+
+	dec := frameDecoder{engine: fakeEngine{}, r: bytes.NewBufferString("\x01")}
+	err := dec.Decode(&value)
+	require.EqualError(t, err, "failed to read length: unexpected EOF")
+
+	dec.r = bytes.NewBufferString("\x02\x00\x00\x001")
+	err = dec.Decode(&value)
+	require.EqualError(t, err, "failed to read value: unexpected EOF")
+
+	dec.r = bytes.NewBufferString("\x01\x00\x00\x00A")
+	err = dec.Decode(&value)
+	require.EqualError(t, err, "failed to unmarshal: invalid character 'A' looking for beginning of value")
+}
+
+func TestEncode(t *testing.T) {
+	ctx := NewContext(fakeEngine{})
+
+	buffer := new(bytes.Buffer)
+
+	err := Encode(ctx, fakeStreamMessage{values: []int{1, 2}}, buffer)
+	require.NoError(t, err)
+	require.Equal(t, "\x01\x00\x00\x002\x01\x00\x00\x001\x01\x00\x00\x002", buffer.String())
+
+	buffer.Reset()
+	err = Encode(ctx, fakeMessage{}, buffer)
+	require.NoError(t, err)
+	require.Equal(t, "message", buffer.String())
+
+	err = Encode(ctx, fakeStreamMessage{values: []int{1}}, badWriter{})
+	require.EqualError(t, err, "failed to serialize: failed to write: oops")
+
+	err = Encode(ctx, fakeMessage{}, badWriter{})
+	require.EqualError(t, err, "failed to write: oops")
+
+	err = Encode(ctx, fakeMessage{err: errors.New("oops")}, buffer)
+	require.EqualError(t, err, "failed to serialize: oops")
+}
+
+func TestDecode(t *testing.T) {
+	ctx := NewContext(fakeEngine{})
+
+	msg := fakeStreamMessage{values: []int{1, 2, 3}}
+
+	buffer := new(bytes.Buffer)
+	require.NoError(t, Encode(ctx, msg, buffer))
+
+	res, err := Decode(ctx, fakeStreamFactory{}, buffer)
+	require.NoError(t, err)
+	require.Equal(t, msg, res)
+
+	_, err = Decode(ctx, fakeStreamFactory{}, bytes.NewBufferString("\x01"))
+	require.EqualError(t, err, "failed to deserialize: failed to read length: unexpected EOF")
+
+	res, err = Decode(ctx, fakeMessageFactory{}, bytes.NewBufferString("message"))
+	require.NoError(t, err)
+	require.Equal(t, fakeMessage{}, res)
+
+	_, err = Decode(ctx, fakeMessageFactory{}, badReader{})
+	require.EqualError(t, err, "failed to read: oops")
+
+	_, err = Decode(ctx, fakeMessageFactory{}, bytes.NewBufferString("A"))
+	require.EqualError(t, err, "failed to deserialize: unexpected data")
+}
+
+// -----------------------------------------------------------------------------
+// Utility functions
+
+type fakeEngine struct {
+	ContextEngine
+
+	err error
+}
+
+func (e fakeEngine) Marshal(value interface{}) ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	return json.Marshal(value)
+}
+
+func (e fakeEngine) Unmarshal(data []byte, value interface{}) error {
+	return json.Unmarshal(data, value)
+}
+
+type fakeStreamEngine struct {
+	fakeEngine
+}
+
+func (fakeStreamEngine) NewEncoder(w io.Writer) Encoder {
+	return json.NewEncoder(w)
+}
+
+func (fakeStreamEngine) NewDecoder(r io.Reader) Decoder {
+	return json.NewDecoder(r)
+}
+
+type fakeMessage struct {
+	err error
+}
+
+func (m fakeMessage) Serialize(Context) ([]byte, error) {
+	return []byte("message"), m.err
+}
+
+type fakeMessageFactory struct{}
+
+func (fakeMessageFactory) Deserialize(ctx Context, data []byte) (Message, error) {
+	if string(data) != "message" {
+		return nil, errors.New("unexpected data")
+	}
+
+	return fakeMessage{}, nil
+}
+
+// fakeStreamMessage is written as the number of values followed by each of
+// them, so that the values are never in a single slice of bytes.
+type fakeStreamMessage struct {
+	Message
+
+	values []int
+}
+
+func (m fakeStreamMessage) SerializeTo(ctx Context, w io.Writer) error {
+	enc := ctx.NewEncoder(w)
+
+	err := enc.Encode(len(m.values))
+	if err != nil {
+		return err
+	}
+
+	for _, value := range m.values {
+		err = enc.Encode(value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type fakeStreamFactory struct {
+	Factory
+}
+
+func (fakeStreamFactory) DeserializeFrom(ctx Context, r io.Reader) (Message, error) {
+	dec := ctx.NewDecoder(r)
+
+	var n int
+	err := dec.Decode(&n)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := fakeStreamMessage{values: make([]int, n)}
+
+	for i := range msg.values {
+		err = dec.Decode(&msg.values[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return msg, nil
+}
+
+type badWriter struct{}
+
+func (badWriter) Write([]byte) (int, error) {
+	return 0, errors.New("oops")
+}
+
+type badReader struct{}
+
+func (badReader) Read([]byte) (int, error) {
+	return 0, errors.New("oops")
+}
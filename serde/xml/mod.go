@@ -6,6 +6,7 @@ package xml
 
 import (
 	"encoding/xml"
+	"io"
 
 	"go.dedis.ch/dela/serde"
 )
@@ -13,6 +14,7 @@ import (
 // xmlEngine is a context engine that uses the XML encoding. See encoding/xml.
 //
 // - implements serde.ContextEngine
+// - implements serde.StreamEngine
 type xmlEngine struct{}
 
 // NewContext returns a new serde context that is using the XML encoding.
@@ -37,3 +39,15 @@ func (xmlEngine) Marshal(m interface{}) ([]byte, error) {
 func (xmlEngine) Unmarshal(data []byte, m interface{}) error {
 	return xml.Unmarshal(data, m)
 }
+
+// NewEncoder implements serde.StreamEngine. It returns an encoder of the values
+// into the writer using the XML encoding.
+func (xmlEngine) NewEncoder(w io.Writer) serde.Encoder {
+	return xml.NewEncoder(w)
+}
+
+// NewDecoder implements serde.StreamEngine. It returns a decoder of the values
+// from the reader using the XML encoding.
+func (xmlEngine) NewDecoder(r io.Reader) serde.Decoder {
+	return xml.NewDecoder(r)
+}
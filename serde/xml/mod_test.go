@@ -1,6 +1,9 @@
 package xml
 
 import (
+	"bytes"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -31,6 +34,32 @@ func TestXMLEngine_Unmarshal(t *testing.T) {
 	require.Equal(t, 42, m.Value)
 }
 
+func TestXMLEngine_NewEncoder(t *testing.T) {
+	ctx := NewContext()
+
+	buffer := new(bytes.Buffer)
+
+	enc := ctx.NewEncoder(buffer)
+	require.NoError(t, enc.Encode(testMessage{Value: 42}))
+	require.NoError(t, enc.Encode(testMessage{Value: 42}))
+	require.Equal(t, strings.Repeat("<testMessage><Value>42</Value></testMessage>", 2), buffer.String())
+}
+
+func TestXMLEngine_NewDecoder(t *testing.T) {
+	ctx := NewContext()
+
+	dec := ctx.NewDecoder(strings.NewReader(strings.Repeat("<testMessage><Value>42</Value></testMessage>", 2)))
+
+	for i := 0; i < 2; i++ {
+		var m testMessage
+		require.NoError(t, dec.Decode(&m))
+		require.Equal(t, testMessage{Value: 42}, m)
+	}
+
+	var m testMessage
+	require.Equal(t, io.EOF, dec.Decode(&m))
+}
+
 // -----------------------------------------------------------------------------
 // Utility functions
 
@@ -41,6 +41,7 @@ import (
 	"go.dedis.ch/dela/mino/minogrpc"
 	"go.dedis.ch/dela/mino/minogrpc/certs"
 	"go.dedis.ch/dela/mino/minogrpc/session"
+	"go.dedis.ch/dela/mino/minogrpc/tokens"
 	"go.dedis.ch/dela/mino/router/tree"
 	"go.dedis.ch/dela/serde/json"
 	"golang.org/x/xerrors"
@@ -198,7 +199,8 @@ func (c cosiDelaNode) Setup(delas ...dela) {
 	require.True(c.t, ok)
 
 	addrStr := c.onet.GetAddress().String()
-	token := joinable.GenerateToken(time.Hour)
+	token, err := joinable.GenerateToken(time.Hour, tokens.ScopeValidator)
+	require.NoError(c.t, err)
 
 	certHash, err := joinable.GetCertificateStore().Hash(joinable.GetCertificate())
 	require.NoError(c.t, err)
@@ -207,7 +209,7 @@ func (c cosiDelaNode) Setup(delas ...dela) {
 		otherJoinable, ok := dela.GetMino().(minogrpc.Joinable)
 		require.True(c.t, ok)
 
-		err = otherJoinable.Join(addrStr, token, certHash)
+		err = otherJoinable.Join(addrStr, token.String(), certHash)
 		require.NoError(c.t, err)
 	}
 